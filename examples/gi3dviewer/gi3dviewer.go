@@ -71,7 +71,7 @@ func mainrun() {
 	objgp := gi3d.AddNewGroup(sc, sc, "obj-gp")
 
 	curFn := ""
-	exts := ".obj,.dae,.gltf"
+	exts := ".obj,.dae,.gltf,.glb"
 
 	tbar.AddAction(gi.ActOpts{Label: "Open...", Icon: "file-open", Tooltip: "Open a 3D object file for viewing."}, win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 		giv.FileViewDialog(vp, curFn, exts, giv.DlgOpts{Title: "Open 3D Object", Prompt: "Open a 3D object file for viewing."}, nil,
@@ -0,0 +1,152 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"html"
+	"strings"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/ki/bitflag"
+	"github.com/goki/ki/ki"
+)
+
+// AccessEnabled determines whether accessibility information (roles, names,
+// values and focus of widgets) is provided to window drivers that bridge to
+// the platform accessibility API (oswin.AccessWindow), so that gi apps can
+// be used with screen readers.  The information is only computed while an
+// assistive technology is active for the window.  Currently only AT-SPI on
+// Linux is bridged (see oswin.AccessWindow).
+var AccessEnabled = true
+
+// Accessible is the interface for widgets that describe themselves for
+// assistive technologies such as screen readers.  Widgets that do not
+// implement it are treated as plain containers of their children.  The
+// role, name and description can also be set with the "access-role" (e.g.,
+// "RoleButton" or "Button"), "access-name" and "access-desc" properties,
+// which override those set by AccessInfo.
+type Accessible interface {
+	// AccessInfo sets the role, name, value and any other states of given
+	// accessible element for this widget -- the ID, Bounds, Desc (from the
+	// Tooltip) and focus, disabled and selected states have already been
+	// set, and the children are added afterward.
+	AccessInfo(an *oswin.AccessNode)
+}
+
+// AccessText returns the plain text of given label text, which can contain
+// HTML formatting, for use as an accessible name
+func AccessText(txt string) string {
+	if !strings.ContainsAny(txt, "<&") {
+		return strings.TrimSpace(txt)
+	}
+	var b strings.Builder
+	intag := false
+	for _, r := range txt {
+		switch {
+		case r == '<':
+			intag = true
+		case r == '>' && intag:
+			intag = false
+		case !intag:
+			b.WriteRune(r)
+		}
+	}
+	return strings.TrimSpace(html.UnescapeString(b.String()))
+}
+
+// AccessNodes returns the accessible elements for given node and its
+// children, or nil if it is invisible.  Plain containers without a role or
+// name are omitted, and their children are returned in their place, as are
+// elements that have not been laid out (empty bounds).
+func AccessNodes(k ki.Ki) []*oswin.AccessNode {
+	nii, nb := KiToNode2D(k)
+	if nii == nil || nb.IsInvisible() {
+		return nil
+	}
+	an := &oswin.AccessNode{ID: nb.PathUnique(), Bounds: nb.WinBBox}
+	an.SetState(nb.CanFocus(), oswin.StateFocusable)
+	an.SetState(nb.HasFocus(), oswin.StateFocused)
+	an.SetState(nb.IsInactive(), oswin.StateDisabled)
+	an.SetState(nb.IsSelected(), oswin.StateSelected)
+	if wb := nii.AsWidget(); wb != nil {
+		an.Desc = AccessText(wb.Tooltip)
+	}
+	if acc, ok := nii.(Accessible); ok {
+		acc.AccessInfo(an)
+	}
+	if rl, ok := nb.Prop("access-role").(string); ok {
+		if !strings.HasPrefix(rl, "Role") {
+			rl = "Role" + rl
+		}
+		an.Role.FromString(rl)
+	}
+	if nm, ok := nb.Prop("access-name").(string); ok {
+		an.Name = nm
+	}
+	if ds, ok := nb.Prop("access-desc").(string); ok {
+		an.Desc = ds
+	}
+	for _, kid := range *k.Children() {
+		an.Kids = append(an.Kids, AccessNodes(kid)...)
+	}
+	if (an.Role == oswin.RoleNone && an.Name == "") || (an.Bounds.Empty() && len(an.Kids) == 0) {
+		return an.Kids
+	}
+	return []*oswin.AccessNode{an}
+}
+
+// AccessTree returns the tree of accessible elements for the window,
+// including the current popup (e.g., menu), if any
+func (w *Window) AccessTree() *oswin.AccessNode {
+	rl := oswin.RoleWindow
+	if bitflag.Has(w.OSWin.Flags(), int(oswin.Dialog)) {
+		rl = oswin.RoleDialog
+	}
+	root := &oswin.AccessNode{ID: w.Nm, Role: rl, Name: w.Title, Bounds: w.Viewport.WinBBox}
+	root.Kids = AccessNodes(w.Viewport.This())
+	if pop := w.CurPopup(); pop != nil && !w.CurPopupIsTooltip() {
+		pkids := AccessNodes(pop)
+		if vp, ok := pop.(*Viewport2D); ok && vp.IsMenu() && len(pkids) > 0 {
+			men := &oswin.AccessNode{ID: vp.PathUnique(), Role: oswin.RoleMenu, Bounds: vp.WinBBox, Kids: pkids}
+			pkids = []*oswin.AccessNode{men}
+		}
+		root.Kids = append(root.Kids, pkids...)
+	}
+	return root
+}
+
+// AccessUpdate provides the current accessibility tree and focus to the
+// window driver, if it supports it, and an assistive technology is active
+// -- only changes are sent.  Called automatically after each Publish.
+func (w *Window) AccessUpdate() {
+	aw, ok := w.OSWin.(oswin.AccessWindow)
+	if !ok || !AccessEnabled || !aw.AccessActive() {
+		return
+	}
+	tree := w.AccessTree()
+	if !tree.Equal(w.accessTree) {
+		w.accessTree = tree
+		aw.SetAccessTree(tree)
+	}
+	foc := ""
+	if fk := w.EventMgr.CurFocus(); fk != nil {
+		if _, nb := KiToNode2D(fk); nb != nil {
+			foc = nb.PathUnique()
+		}
+	}
+	if foc != w.accessFocus {
+		w.accessFocus = foc
+		aw.AccessFocus(foc)
+	}
+}
+
+// AccessAnnounce asks for given message to be announced to the user by any
+// active assistive technology (e.g., spoken by a screen reader) -- if
+// assertive, it interrupts any current speech, otherwise it is queued.
+func (w *Window) AccessAnnounce(msg string, assertive bool) {
+	if aw, ok := w.OSWin.(oswin.AccessWindow); ok && AccessEnabled && aw.AccessActive() {
+		aw.AccessAnnounce(msg, assertive)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"log"
 
 	"github.com/goki/gi/gist"
+	"github.com/goki/gi/oswin"
 	"github.com/goki/gi/units"
 	"github.com/goki/ki/ki"
 	"github.com/goki/ki/kit"
@@ -39,6 +40,15 @@ func (ac *Action) CopyFieldsFrom(frm interface{}) {
 	ac.Data = fr.Data
 }
 
+// AccessInfo describes the action for assistive technologies -- it is a
+// menu item within a menu or menu bar, and a button otherwise
+func (ac *Action) AccessInfo(an *oswin.AccessNode) {
+	ac.ButtonBase.AccessInfo(an)
+	if _, inbar := ac.Par.(*MenuBar); inbar || (ac.Viewport != nil && ac.Viewport.IsMenu()) {
+		an.Role = oswin.RoleMenuItem
+	}
+}
+
 func (ac *Action) Disconnect() {
 	ac.ButtonBase.Disconnect()
 	ac.ActionSig.DisconnectAll()
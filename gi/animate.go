@@ -0,0 +1,682 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+)
+
+// AnimationFPS is the number of frames per second at which running
+// animations are updated
+var AnimationFPS = 60
+
+// AnimationDur is the default duration of animations, e.g., for FadeIn
+var AnimationDur = 250 * time.Millisecond
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Easing
+
+// EasingFunc maps the linear progress of a tween, from 0 to 1, into the
+// progress of the animated value, which starts at 0 and ends at 1 but can
+// go outside of that range in between (e.g., EaseOutBack)
+type EasingFunc func(t float32) float32
+
+// EaseLinear changes at a constant rate
+func EaseLinear(t float32) float32 {
+	return t
+}
+
+// EaseInQuad starts slowly and accelerates
+func EaseInQuad(t float32) float32 {
+	return t * t
+}
+
+// EaseOutQuad starts quickly and decelerates
+func EaseOutQuad(t float32) float32 {
+	return t * (2 - t)
+}
+
+// EaseInOutQuad accelerates and then decelerates
+func EaseInOutQuad(t float32) float32 {
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	return -1 + (4-2*t)*t
+}
+
+// EaseInCubic starts slowly and accelerates, more strongly than EaseInQuad
+func EaseInCubic(t float32) float32 {
+	return t * t * t
+}
+
+// EaseOutCubic starts quickly and decelerates, more strongly than EaseOutQuad
+func EaseOutCubic(t float32) float32 {
+	t--
+	return t*t*t + 1
+}
+
+// EaseInOutCubic accelerates and then decelerates, more strongly than
+// EaseInOutQuad
+func EaseInOutCubic(t float32) float32 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	t = 2*t - 2
+	return 0.5*t*t*t + 1
+}
+
+// EaseInOutSine accelerates and then decelerates along a sine curve
+func EaseInOutSine(t float32) float32 {
+	return 0.5 * (1 - mat32.Cos(mat32.Pi*t))
+}
+
+// EaseOutBack overshoots the end value and then settles back onto it
+func EaseOutBack(t float32) float32 {
+	const c1 = 1.70158
+	const c3 = c1 + 1
+	t--
+	return 1 + c3*t*t*t + c1*t*t
+}
+
+// EaseOutBounce bounces against the end value, like a dropped ball
+func EaseOutBounce(t float32) float32 {
+	const n1 = 7.5625
+	const d1 = 2.75
+	switch {
+	case t < 1/d1:
+		return n1 * t * t
+	case t < 2/d1:
+		t -= 1.5 / d1
+		return n1*t*t + 0.75
+	case t < 2.5/d1:
+		t -= 2.25 / d1
+		return n1*t*t + 0.9375
+	default:
+		t -= 2.625 / d1
+		return n1*t*t + 0.984375
+	}
+}
+
+// EasingDefault is the easing function used for tweens that do not specify one
+var EasingDefault EasingFunc = EaseInOutQuad
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Tween
+
+// Tween animates a value over time, from the value it has when the tween
+// starts to a given end value -- see TweenFloat, TweenColor, TweenVec2,
+// TweenProp etc for standard ones, which are combined into an Animation to
+// run them.  All of the updating of values takes place in the event loop of
+// the window, so there are no concurrency issues.
+type Tween struct {
+	Node     ki.Ki           `desc:"node that the animated value belongs to -- it is re-rendered after each change, if non-nil"`
+	Duration time.Duration   `desc:"how long the tween takes, after its delay"`
+	Delay    time.Duration   `desc:"delay from the start of its step in the animation until the tween starts"`
+	Easing   EasingFunc      `desc:"easing function -- nil = EasingDefault"`
+	Layout   bool            `desc:"changes to the value affect the layout of the node -- its parent layout is re-laid out after each change, otherwise just the node is re-rendered"`
+	Restyle  bool            `desc:"changes to the value affect the style of the node (e.g., style properties) -- it is re-styled when re-rendered"`
+	Begin    func()          `desc:"called when the tween starts, to record the starting value"`
+	Set      func(t float32) `desc:"sets the value for given eased progress, from 0 at the start to 1 at the end"`
+}
+
+// SetEasing sets the easing function, returning the tween for chaining
+func (tw *Tween) SetEasing(ease EasingFunc) *Tween {
+	tw.Easing = ease
+	return tw
+}
+
+// SetDelay sets the delay, returning the tween for chaining
+func (tw *Tween) SetDelay(delay time.Duration) *Tween {
+	tw.Delay = delay
+	return tw
+}
+
+// SetLayout sets whether changes to the value affect layout, returning the
+// tween for chaining
+func (tw *Tween) SetLayout(layout bool) *Tween {
+	tw.Layout = layout
+	return tw
+}
+
+// End returns the time from the start of its step at which the tween is done
+func (tw *Tween) End() time.Duration {
+	return tw.Delay + tw.Duration
+}
+
+// Progress returns the eased progress of the tween at given time since the
+// start of its step, and whether it has started
+func (tw *Tween) Progress(since time.Duration) (float32, bool) {
+	if since < tw.Delay {
+		return 0, false
+	}
+	if tw.Duration <= 0 || since >= tw.End() {
+		return 1, true
+	}
+	t := float32(since-tw.Delay) / float32(tw.Duration)
+	ease := tw.Easing
+	if ease == nil {
+		ease = EasingDefault
+	}
+	return ease(t), true
+}
+
+// TweenFunc returns a tween that calls given function with the eased
+// progress, from 0 at the start to 1 at the end, over given duration --
+// for animating anything
+func TweenFunc(node ki.Ki, dur time.Duration, fun func(t float32)) *Tween {
+	return &Tween{Node: node, Duration: dur, Set: fun}
+}
+
+// TweenFloat returns a tween that animates given float32 value of given node
+// to given end value over given duration
+func TweenFloat(node ki.Ki, val *float32, to float32, dur time.Duration) *Tween {
+	var from float32
+	tw := &Tween{Node: node, Duration: dur}
+	tw.Begin = func() { from = *val }
+	tw.Set = func(t float32) { *val = from + t*(to-from) }
+	return tw
+}
+
+// TweenVec2 returns a tween that animates given position (or size etc) of
+// given node to given end value over given duration
+func TweenVec2(node ki.Ki, val *mat32.Vec2, to mat32.Vec2, dur time.Duration) *Tween {
+	var from mat32.Vec2
+	tw := &Tween{Node: node, Duration: dur}
+	tw.Begin = func() { from = *val }
+	tw.Set = func(t float32) { *val = from.Add(to.Sub(from).MulScalar(t)) }
+	return tw
+}
+
+// TweenColor returns a tween that animates given color of given node to
+// given end color over given duration
+func TweenColor(node ki.Ki, val *gist.Color, to gist.Color, dur time.Duration) *Tween {
+	var from gist.Color
+	tw := &Tween{Node: node, Duration: dur}
+	tw.Begin = func() { from = *val }
+	tw.Set = func(t float32) { *val = from.Blend(100*t, to) }
+	return tw
+}
+
+// TweenProp returns a tween that animates given style property of given node
+// to given end value over given duration -- the property can be a number
+// (e.g., "opacity"), a units.Value or string size (e.g., "width", "height"),
+// or a color (e.g., "background-color"), and starts from its current value
+// (or the end value if not set or of a different kind).  Changing a size
+// re-lays out the parent layout, so this can be used for expanding and
+// collapsing, and other layout transitions.
+func TweenProp(node ki.Ki, prop string, to interface{}, dur time.Duration) *Tween {
+	tw := &Tween{Node: node, Duration: dur, Restyle: true}
+	switch tv := to.(type) {
+	case units.Value, string:
+		if ts, ok := tv.(string); ok && ts == "" {
+			return tw
+		}
+		if ts, ok := tv.(string); ok && !strings.ContainsAny(ts[:1], "0123456789.-+") {
+			var toc gist.Color
+			if err := toc.SetString(ts, nil); err != nil {
+				log.Printf("gi.TweenProp: property %v end value: %v is not a size or color\n", prop, to)
+				return tw
+			}
+			return tweenPropColor(tw, prop, toc)
+		}
+		var tou units.Value
+		tou.SetIFace(to, prop)
+		var from units.Value
+		tw.Begin = func() {
+			from = tou
+			if fv := node.Prop(prop); fv != nil {
+				var fu units.Value
+				if fu.SetIFace(fv, prop) == nil && fu.Un == tou.Un {
+					from = fu
+				}
+			}
+		}
+		tw.Set = func(t float32) {
+			node.SetProp(prop, units.NewValue(from.Val+t*(tou.Val-from.Val), tou.Un))
+		}
+		tw.Layout = true
+	case gist.Color:
+		return tweenPropColor(tw, prop, tv)
+	default:
+		tof, ok := kit.ToFloat32(to)
+		if !ok {
+			log.Printf("gi.TweenProp: property %v end value: %v is not a number, size or color\n", prop, to)
+			return tw
+		}
+		var from float32
+		tw.Begin = func() {
+			from = tof
+			if fv := node.Prop(prop); fv != nil {
+				if ff, ok := kit.ToFloat32(fv); ok {
+					from = ff
+				}
+			}
+		}
+		tw.Set = func(t float32) {
+			node.SetProp(prop, from+t*(tof-from))
+		}
+	}
+	return tw
+}
+
+// tweenPropColor sets given tween to animate given color property
+func tweenPropColor(tw *Tween, prop string, to gist.Color) *Tween {
+	node := tw.Node
+	var from gist.Color
+	tw.Begin = func() {
+		from = to
+		if fv := node.Prop(prop); fv != nil {
+			var fc gist.Color
+			if fc.SetIFace(fv, nil, prop) == nil {
+				from = fc
+			}
+		}
+	}
+	tw.Set = func(t float32) {
+		node.SetProp(prop, from.Blend(100*t, to))
+	}
+	return tw
+}
+
+// Pause returns a tween that does nothing for given duration, for use as a
+// step in an Animation
+func Pause(dur time.Duration) *Tween {
+	return &Tween{Duration: dur}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Animation
+
+// Animation is a sequence of steps, each of which runs a set of tweens at the
+// same time, with the next step starting when all of the tweens in the
+// previous one are done.  Build one with NewAnimation, With and Then, and
+// run it with Start, which returns immediately -- it can be canceled at any
+// point with Cancel, or jumped to its end with Finish.
+type Animation struct {
+	Steps    [][]*Tween          `desc:"steps of the animation, each with tweens that run at the same time"`
+	DoneFunc func(an *Animation) `desc:"function called when the animation is done, or canceled"`
+	step     int                 // current step
+	start    time.Time           // start time of current step
+	begun    bool                // current step has begun
+	finish   bool                // jump to the end at next update
+	state    animStates          // current state
+}
+
+// animStates are the states of an Animation
+type animStates int32
+
+const (
+	animReady animStates = iota
+	animRunning
+	animDone
+	animCanceled
+)
+
+// NewAnimation returns a new animation whose first step runs given tweens
+func NewAnimation(tweens ...*Tween) *Animation {
+	an := &Animation{}
+	return an.Then(tweens...)
+}
+
+// With adds given tweens to the last step of the animation, to run at the
+// same time as the others in that step
+func (an *Animation) With(tweens ...*Tween) *Animation {
+	sz := len(an.Steps)
+	if sz == 0 {
+		return an.Then(tweens...)
+	}
+	an.Steps[sz-1] = append(an.Steps[sz-1], tweens...)
+	return an
+}
+
+// Then adds a new step to the animation, with given tweens, which runs after
+// the previous steps are done
+func (an *Animation) Then(tweens ...*Tween) *Animation {
+	an.Steps = append(an.Steps, tweens)
+	return an
+}
+
+// ThenPause adds a new step that just waits for given duration
+func (an *Animation) ThenPause(dur time.Duration) *Animation {
+	return an.Then(Pause(dur))
+}
+
+// OnDone sets the function to call when the animation is done, or canceled
+func (an *Animation) OnDone(fun func(an *Animation)) *Animation {
+	an.DoneFunc = fun
+	return an
+}
+
+// Start starts running the animation in given window, returning the
+// animation
+func (an *Animation) Start(win *Window) *Animation {
+	win.Animator.Start(an)
+	return an
+}
+
+// IsRunning returns true if the animation has been started and is not yet
+// done or canceled
+func (an *Animation) IsRunning() bool {
+	return an.state == animRunning
+}
+
+// IsDone returns true if the animation ran to its end
+func (an *Animation) IsDone() bool {
+	return an.state == animDone
+}
+
+// IsCanceled returns true if the animation was canceled
+func (an *Animation) IsCanceled() bool {
+	return an.state == animCanceled
+}
+
+// Cancel stops the animation, leaving all values where they are.  It takes
+// effect at the next update of the window's Animator, and should be called
+// from the event loop of the window (e.g., in response to an event).
+func (an *Animation) Cancel() {
+	if an.state == animReady || an.state == animRunning {
+		an.state = animCanceled
+	}
+}
+
+// Finish ends the animation at its next update, with all remaining tweens
+// set to their end values
+func (an *Animation) Finish() {
+	if an.state == animReady || an.state == animRunning {
+		an.finish = true
+	}
+}
+
+// HasNode returns true if any of the tweens of the animation animate given node
+func (an *Animation) HasNode(node ki.Ki) bool {
+	for _, st := range an.Steps {
+		for _, tw := range st {
+			if tw.Node == node {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// update updates the animation to given time, calling given function for
+// each tween whose value was set -- returns true when done
+func (an *Animation) update(now time.Time, upfun func(tw *Tween)) bool {
+	finish := an.finish
+	for an.step < len(an.Steps) {
+		st := an.Steps[an.step]
+		if !an.begun {
+			an.begun = true
+			if an.start.IsZero() {
+				an.start = now
+			}
+			for _, tw := range st {
+				if tw.Begin != nil {
+					tw.Begin()
+				}
+			}
+		}
+		since := now.Sub(an.start)
+		if finish {
+			since = 1<<63 - 1
+		}
+		var end time.Duration
+		for _, tw := range st {
+			if e := tw.End(); e > end {
+				end = e
+			}
+			t, ok := tw.Progress(since)
+			if !ok || tw.Set == nil {
+				continue
+			}
+			tw.Set(t)
+			upfun(tw)
+		}
+		if since < end {
+			return false
+		}
+		an.step++
+		an.begun = false
+		if !finish {
+			an.start = an.start.Add(end)
+		}
+	}
+	return true
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Animator
+
+// Animator runs the animations of a window, updating them at AnimationFPS
+// frames per second while any are running.  Each update happens in the
+// event loop of the window, via a custom event, and all of the nodes that
+// were changed are re-rendered in one window update.
+type Animator struct {
+	Win     *Window      `desc:"window that we animate"`
+	Anims   []*Animation `desc:"running animations"`
+	Mu      sync.Mutex   `desc:"mutex protecting the running animations"`
+	ticker  *time.Ticker
+	pending bool // a tick event has been sent but not yet processed
+}
+
+// animTick is the data of the custom event that updates animations
+type animTick struct{}
+
+// Start starts running given animation
+func (am *Animator) Start(an *Animation) {
+	if an.state == animRunning {
+		return
+	}
+	an.state = animRunning
+	an.step = 0
+	an.begun = false
+	an.finish = false
+	an.start = time.Time{}
+	am.Mu.Lock()
+	am.Anims = append(am.Anims, an)
+	if am.ticker == nil && AnimationFPS > 0 {
+		am.ticker = time.NewTicker(time.Second / time.Duration(AnimationFPS))
+		go am.run(am.ticker)
+	}
+	am.Mu.Unlock()
+}
+
+// IsAnimating returns true if any animations are running
+func (am *Animator) IsAnimating() bool {
+	am.Mu.Lock()
+	defer am.Mu.Unlock()
+	return len(am.Anims) > 0
+}
+
+// CancelNode cancels all of the running animations that animate given node
+// -- e.g., before starting a new animation of the same values
+func (am *Animator) CancelNode(node ki.Ki) {
+	am.Mu.Lock()
+	defer am.Mu.Unlock()
+	for _, an := range am.Anims {
+		if an.HasNode(node) {
+			an.Cancel()
+		}
+	}
+}
+
+// CancelAll cancels all of the running animations
+func (am *Animator) CancelAll() {
+	am.Mu.Lock()
+	defer am.Mu.Unlock()
+	for _, an := range am.Anims {
+		an.Cancel()
+	}
+}
+
+// run sends tick events to the window while there are animations running
+func (am *Animator) run(tick *time.Ticker) {
+	for range tick.C {
+		am.Mu.Lock()
+		if am.ticker != tick {
+			am.Mu.Unlock()
+			return
+		}
+		if len(am.Anims) == 0 || am.Win == nil || am.Win.IsClosed() {
+			am.ticker.Stop()
+			am.ticker = nil
+			am.Mu.Unlock()
+			return
+		}
+		send := !am.pending
+		am.pending = true
+		am.Mu.Unlock()
+		if send {
+			am.Win.SendCustomEvent(animTick{})
+		}
+	}
+}
+
+// Tick updates all of the running animations to the current time, and
+// re-renders the nodes that changed -- called in the event loop of the
+// window when a tick event arrives.
+func (am *Animator) Tick() {
+	now := time.Now()
+	am.Mu.Lock()
+	am.pending = false
+	anims := make([]*Animation, len(am.Anims))
+	copy(anims, am.Anims)
+	am.Mu.Unlock()
+
+	var nodes, restyles, layouts []ki.Ki
+	addUnique := func(ks []ki.Ki, k ki.Ki) []ki.Ki {
+		for _, ok := range ks {
+			if ok == k {
+				return ks
+			}
+		}
+		return append(ks, k)
+	}
+	upfun := func(tw *Tween) {
+		if tw.Node == nil || tw.Node.This() == nil || tw.Node.IsDeleted() || tw.Node.IsDestroyed() {
+			return
+		}
+		if tw.Layout {
+			if nii, ok := tw.Node.(Node2D); ok {
+				if ly := nii.AsWidget().ParentLayout(); ly != nil {
+					layouts = addUnique(layouts, ly.This())
+					return
+				}
+			}
+		}
+		if tw.Restyle {
+			restyles = addUnique(restyles, tw.Node)
+		} else {
+			nodes = addUnique(nodes, tw.Node)
+		}
+	}
+
+	var done []*Animation
+	for _, an := range anims {
+		if an.state == animRunning && !an.update(now, upfun) {
+			continue
+		}
+		if an.state == animRunning {
+			an.state = animDone
+		}
+		done = append(done, an)
+	}
+	if len(done) > 0 {
+		am.Mu.Lock()
+		for _, dn := range done {
+			for i, an := range am.Anims {
+				if an == dn {
+					am.Anims = append(am.Anims[:i], am.Anims[i+1:]...)
+					break
+				}
+			}
+		}
+		am.Mu.Unlock()
+	}
+
+	if len(nodes) > 0 || len(restyles) > 0 || len(layouts) > 0 {
+		wupdt := am.Win.UpdateStart()
+		for _, k := range append(layouts, restyles...) {
+			if _, nb := KiToNode2D(k); nb != nil {
+				nb.SetFullReRender()
+			}
+			k.UpdateSig()
+		}
+		for _, k := range nodes {
+			k.UpdateSig()
+		}
+		am.Win.UpdateEnd(wupdt)
+	}
+	for _, an := range done {
+		if an.DoneFunc != nil {
+			an.DoneFunc(an)
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Standard animations
+
+// FadeIn animates the opacity of given widget from 0 to 1, over given
+// duration (0 = AnimationDur), returning the started animation
+func FadeIn(node Node2D, dur time.Duration) *Animation {
+	return animateOpacity(node, 0, 1, dur)
+}
+
+// FadeOut animates the opacity of given widget from its current value to 0,
+// over given duration (0 = AnimationDur), returning the started animation
+func FadeOut(node Node2D, dur time.Duration) *Animation {
+	return animateOpacity(node, -1, 0, dur)
+}
+
+// animateOpacity animates the opacity of given node, from given value (-1
+// = current), to given value
+func animateOpacity(node Node2D, from, to float32, dur time.Duration) *Animation {
+	win := node.AsNode2D().ParentWindow()
+	if win == nil {
+		return nil
+	}
+	if dur == 0 {
+		dur = AnimationDur
+	}
+	win.Animator.CancelNode(node.This())
+	if from < 0 && node.Prop("opacity") == nil {
+		from = 1
+	}
+	if from >= 0 {
+		node.SetProp("opacity", from)
+	}
+	return NewAnimation(TweenProp(node.This(), "opacity", to, dur)).Start(win)
+}
+
+// AnimateProps animates given style properties of given widget to the given
+// values, over given duration (0 = AnimationDur), returning the started
+// animation -- see TweenProp for the kinds of properties that can be
+// animated, e.g., "height" for expanding and collapsing.  Any running
+// animations of the widget are canceled first.
+func AnimateProps(node Node2D, props ki.Props, dur time.Duration) *Animation {
+	win := node.AsNode2D().ParentWindow()
+	if win == nil {
+		return nil
+	}
+	if dur == 0 {
+		dur = AnimationDur
+	}
+	win.Animator.CancelNode(node.This())
+	an := NewAnimation()
+	for key, val := range props {
+		an.With(TweenProp(node.This(), key, val, dur))
+	}
+	return an.Start(win)
+}
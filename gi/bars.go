@@ -39,6 +39,11 @@ func (mb *MenuBar) CopyFieldsFrom(frm interface{}) {
 	mb.MainMenu = fr.MainMenu
 }
 
+// AccessInfo describes the menu bar for assistive technologies
+func (mb *MenuBar) AccessInfo(an *oswin.AccessNode) {
+	an.Role = oswin.RoleMenuBar
+}
+
 var MenuBarProps = ki.Props{
 	"EnumType:Flag":    KiT_NodeFlags,
 	"padding":          units.NewPx(2),
@@ -172,7 +177,8 @@ func (m *MenuBar) FindActionByName(name string) (*Action, bool) {
 
 // ConfigMenus configures Action items as children of MenuBar with the given
 // names, which function as the main menu panels for the menu bar (File, Edit,
-// etc).  Access the resulting menus as .ChildByName("name").(*Action).
+// etc).  Access the resulting menus as .ChildByName("name").(*Action) --
+// the names are translated into the current locale for the menu text.
 func (mb *MenuBar) ConfigMenus(menus []string) {
 	if mb == nil {
 		return
@@ -191,7 +197,7 @@ func (mb *MenuBar) ConfigMenus(menus []string) {
 		mi := mb.Kids[i]
 		if mi.TypeEmbeds(KiT_Action) {
 			ac := mi.Embed(KiT_Action).(*Action)
-			ac.SetText(m)
+			ac.SetText(T(m))
 			ac.SetAsMenu()
 		}
 	}
@@ -323,6 +329,11 @@ func (tb *ToolBar) CopyFieldsFrom(frm interface{}) {
 	tb.Layout.CopyFieldsFrom(&fr.Layout)
 }
 
+// AccessInfo describes the toolbar for assistive technologies
+func (tb *ToolBar) AccessInfo(an *oswin.AccessNode) {
+	an.Role = oswin.RoleToolBar
+}
+
 var ToolBarProps = ki.Props{
 	"EnumType:Flag":    KiT_NodeFlags,
 	"padding":          units.NewPx(2),
@@ -346,9 +357,9 @@ func (tb *ToolBar) AddAction(opts ActOpts, sigTo ki.Ki, fun ki.RecvFunc) *Action
 		nm = opts.Icon
 	}
 	ac := AddNewAction(tb, nm)
-	ac.Text = opts.Label
+	ac.Text = T(opts.Label)
 	ac.Icon = IconName(opts.Icon)
-	ac.Tooltip = opts.Tooltip
+	ac.Tooltip = T(opts.Tooltip)
 	ac.Shortcut = key.Chord(opts.Shortcut).OSShortcut()
 	if opts.ShortcutKey != KeyFunNil {
 		ac.Shortcut = ShortcutForFun(opts.ShortcutKey)
@@ -17,6 +17,8 @@ import (
 	"github.com/anthonynsimon/bild/clone"
 	"github.com/anthonynsimon/bild/transform"
 	"github.com/goki/gi/gist"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mimedata"
 	"github.com/goki/gi/units"
 	"github.com/goki/ki/ki"
 	"github.com/goki/ki/kit"
@@ -53,6 +55,11 @@ func (bm *Bitmap) CopyFieldsFrom(frm interface{}) {
 	bm.Filename = fr.Filename
 }
 
+// AccessInfo describes the bitmap for assistive technologies
+func (bm *Bitmap) AccessInfo(an *oswin.AccessNode) {
+	an.Role = oswin.RoleImage
+}
+
 // Resize resizes bitmap to given size
 func (bm *Bitmap) Resize(nwsz image.Point) {
 	if nwsz.X == 0 || nwsz.Y == 0 {
@@ -126,6 +133,59 @@ func (bm *Bitmap) GrabRenderFrom(nii Node2D) {
 	}
 }
 
+// CopyImage copies the bitmap image to the clipboard, as a PNG image
+func (bm *Bitmap) CopyImage() {
+	win := bm.ParentWindow()
+	if bm.Pixels == nil || win == nil {
+		return
+	}
+	md, err := mimedata.NewImage(bm.Pixels)
+	if err != nil {
+		log.Printf("gi.Bitmap.CopyImage: %v\n", err)
+		return
+	}
+	oswin.TheApp.ClipBoard(win.OSWin).Write(md)
+}
+
+// PasteImage sets the bitmap image from the image on the clipboard, if
+// any (e.g., a screenshot), at the actual size of the image -- does nothing
+// if the bitmap is inactive
+func (bm *Bitmap) PasteImage() {
+	win := bm.ParentWindow()
+	if bm.IsInactive() || win == nil {
+		return
+	}
+	img := oswin.TheApp.ClipBoard(win.OSWin).Read([]string{mimedata.ImagePNG}).Image()
+	if img == nil {
+		return
+	}
+	bm.Filename = ""
+	bm.SetImage(img, 0, 0)
+	bm.LayoutToImgSize()
+	bm.SetFullReRender()
+}
+
+func (bm *Bitmap) MakeContextMenu(m *Menu) {
+	ac := m.AddAction(ActOpts{Label: "Copy Image"},
+		bm.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			bmm := recv.Embed(KiT_Bitmap).(*Bitmap)
+			bmm.CopyImage()
+		})
+	ac.SetActiveState(bm.Pixels != nil)
+	if !bm.IsInactive() {
+		m.AddAction(ActOpts{Label: "Paste Image"},
+			bm.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				bmm := recv.Embed(KiT_Bitmap).(*Bitmap)
+				bmm.PasteImage()
+			})
+	}
+	bm.WidgetBase.MakeContextMenu(m)
+}
+
+func (bm *Bitmap) ConnectEvents2D() {
+	bm.WidgetMouseEvents(false, true)
+}
+
 func (bm *Bitmap) DrawIntoViewport(parVp *Viewport2D) {
 	if bm.Pixels == nil {
 		return
@@ -146,6 +206,9 @@ func (bm *Bitmap) DrawIntoViewport(parVp *Viewport2D) {
 		r = nr
 	}
 	draw.Draw(parVp.Pixels, r, bm.Pixels, sp, draw.Over)
+	if parVp.Render.PDF != nil {
+		parVp.Render.PDF.DrawImage(r, bm.Pixels, mat32.Translate2D(float32(pos.X), float32(pos.Y)))
+	}
 }
 
 func (bm *Bitmap) Render2D() {
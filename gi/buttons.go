@@ -66,6 +66,20 @@ func (bb *ButtonBase) Disconnect() {
 	bb.ButtonSig.DisconnectAll()
 }
 
+// AccessInfo describes the button for assistive technologies
+func (bb *ButtonBase) AccessInfo(an *oswin.AccessNode) {
+	an.Role = oswin.RoleButton
+	an.Name = AccessText(bb.Text)
+	if an.Name == "" {
+		an.Name = string(bb.Icon)
+	}
+	if bb.IsCheckable() {
+		an.SetState(true, oswin.StateCheckable)
+		an.SetState(bb.IsChecked(), oswin.StateChecked)
+	}
+	an.SetState(bb.HasMenu(), oswin.StateHasPopup)
+}
+
 // ButtonFlags extend NodeBase NodeFlags to hold button state
 type ButtonFlags int
 
@@ -639,6 +653,7 @@ func (bb *ButtonBase) StyleButton() {
 		for i := 0; i < int(ButtonStatesN); i++ {
 			bb.StateStyles[i].CopyFrom(&bb.Sty)
 			bb.StateStyles[i].SetStyleProps(parSty, bb.StyleProps(ButtonSelectors[i]), bb.Viewport)
+			ApplyAppStyles(bb.This().(Node2D), bb.Viewport, &bb.StateStyles[i], parSty, ButtonSelectors[i])
 			if clsp != nil {
 				if stclsp, ok := ki.SubProps(clsp, ButtonSelectors[i]); ok {
 					bb.StateStyles[i].SetStyleProps(parSty, stclsp, bb.Viewport)
@@ -660,6 +675,10 @@ func (bb *ButtonBase) Style2D() {
 	bb.StyleButton()
 
 	bb.StyMu.Lock()
+	TouchTargetStyle(&bb.Sty)
+	for i := range bb.StateStyles {
+		TouchTargetStyle(&bb.StateStyles[i])
+	}
 	bb.LayState.SetFromStyle(&bb.Sty.Layout) // also does reset
 	bb.StyMu.Unlock()
 	bb.This().(ButtonWidget).ConfigParts()
@@ -835,6 +854,14 @@ func (cb *CheckBox) CopyFieldsFrom(frm interface{}) {
 	cb.IconOff = fr.IconOff
 }
 
+// AccessInfo describes the checkbox for assistive technologies
+func (cb *CheckBox) AccessInfo(an *oswin.AccessNode) {
+	cb.ButtonBase.AccessInfo(an)
+	an.Role = oswin.RoleCheckBox
+	an.SetState(true, oswin.StateCheckable)
+	an.SetState(cb.IsChecked(), oswin.StateChecked)
+}
+
 var CheckBoxProps = ki.Props{
 	"EnumType:Flag":    KiT_ButtonFlags,
 	"icon":             "checked-box",
@@ -0,0 +1,238 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/pi/complete"
+)
+
+// PaletteCmd is a command that can be run from the command palette -- see
+// CmdPalette
+type PaletteCmd struct {
+	Name     string    `desc:"name of the command, shown and searched in the palette -- actions in menus are named by their menu path, e.g., File: Save As..."`
+	Desc     string    `desc:"description of the command, shown as its tooltip"`
+	Shortcut key.Chord `desc:"keyboard shortcut for the command, if any"`
+	Fun      func()    `desc:"function that runs the command"`
+}
+
+// AppCmds are the commands registered by the app with AddAppCmd, which are
+// listed in the command palette of every window, along with the actions in
+// its menus and toolbars
+var AppCmds []*PaletteCmd
+
+// CmdPaletteMax is the maximum number of matching commands shown at once in
+// the command palette
+var CmdPaletteMax = 30
+
+// AddAppCmd registers a command of the app for the command palette,
+// replacing any existing one of the same name -- this is for commands that
+// are not otherwise available as actions in the menus or toolbars
+func AddAppCmd(name, desc string, fun func()) *PaletteCmd {
+	for _, pc := range AppCmds {
+		if pc.Name == name {
+			pc.Desc = desc
+			pc.Fun = fun
+			return pc
+		}
+	}
+	pc := &PaletteCmd{Name: name, Desc: desc, Fun: fun}
+	AppCmds = append(AppCmds, pc)
+	return pc
+}
+
+// PaletteCmds returns the commands for the command palette of the window:
+// the AppCmds, followed by the active actions in its main menu, named by
+// their menu path, and in its toolbars
+func (w *Window) PaletteCmds() []*PaletteCmd {
+	var cmds []*PaletteCmd
+	has := map[string]bool{}
+	add := func(pc *PaletteCmd) {
+		if pc.Name == "" || has[pc.Name] {
+			return
+		}
+		has[pc.Name] = true
+		cmds = append(cmds, pc)
+	}
+	for _, pc := range AppCmds {
+		add(pc)
+	}
+	if w.MainMenu != nil {
+		for _, k := range w.MainMenu.Kids {
+			if ac, ok := k.(*Action); ok {
+				ac.paletteCmds(ac.Text, add)
+			}
+		}
+	}
+	w.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		tb, ok := k.(*ToolBar)
+		if !ok {
+			return ki.Continue
+		}
+		for _, tk := range tb.Kids {
+			if ac, ok := tk.(*Action); ok {
+				nm := ac.Text
+				if nm == "" {
+					nm = ac.Tooltip
+				}
+				ac.paletteCmds(nm, add)
+			}
+		}
+		return ki.Break // done with this toolbar
+	})
+	return cmds
+}
+
+// paletteCmds adds the command for this action, named by given path, or the
+// commands for the items of its menu, if it has one
+func (ac *Action) paletteCmds(path string, add func(pc *PaletteCmd)) {
+	if ac.MakeMenuFunc != nil {
+		ac.MakeMenuFunc(ac.This(), &ac.Menu)
+	}
+	if ac.UpdateFunc != nil {
+		ac.UpdateFunc(ac)
+	}
+	if len(ac.Menu) == 0 {
+		if ac.IsInactive() {
+			return
+		}
+		add(&PaletteCmd{Name: path, Desc: ac.Tooltip, Shortcut: ac.Shortcut, Fun: ac.Trigger})
+		return
+	}
+	for _, k := range ac.Menu {
+		if sac, ok := k.(*Action); ok && sac.Text != "" {
+			sac.paletteCmds(path+": "+sac.Text, add)
+		}
+	}
+}
+
+// cmdPalette is the state of an open command palette
+type cmdPalette struct {
+	dlg     *Dialog
+	search  *TextField
+	list    *Frame
+	cmds    []*PaletteCmd
+	matches []*PaletteCmd
+	sel     int
+}
+
+// CmdPalette opens the command palette for the given window: a dialog that
+// lists its PaletteCmds, filtered and ranked by fuzzy matching against the
+// text typed into its search field.  The up and down keys move the
+// selection among the matches, and Enter or a click runs the command, after
+// closing the palette.  It is opened in any window by KeyFunCmdPalette.
+func CmdPalette(win *Window) *Dialog {
+	cp := &cmdPalette{cmds: win.PaletteCmds()}
+	dlg := NewStdDialog(DlgOpts{Title: "Command Palette"}, NoOk, NoCancel)
+	dlg.Modal = true
+	cp.dlg = dlg
+
+	frame := dlg.Frame()
+	cp.search = AddNewTextField(frame, "search")
+	cp.search.Placeholder = "type to search commands"
+	cp.search.SetStretchMaxWidth()
+	cp.search.SetMinPrefWidth(units.NewCh(60))
+	cp.search.TextFieldSig.Connect(dlg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		switch TextFieldSignals(sig) {
+		case TextFieldInsert, TextFieldBackspace, TextFieldDelete, TextFieldCleared:
+			cp.filter()
+		}
+	})
+	cp.list = AddNewFrame(frame, "cmds", LayoutVert)
+	cp.list.SetStretchMax()
+	cp.filter()
+
+	dlg.UpdateEndNoSig(true)
+	dlg.Open(0, 0, win.Viewport, func() {
+		dlg.Win.EventMgr.ConnectEvent(dlg.This(), oswin.KeyChordEvent, HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+			kt := d.(*key.ChordEvent)
+			switch KeyFun(kt.Chord()) {
+			case KeyFunMoveUp:
+				kt.SetProcessed()
+				cp.selectIdx(cp.sel - 1)
+			case KeyFunMoveDown:
+				kt.SetProcessed()
+				cp.selectIdx(cp.sel + 1)
+			case KeyFunEnter, KeyFunAccept:
+				kt.SetProcessed()
+				cp.run(cp.sel)
+			}
+		})
+	})
+	return dlg
+}
+
+// filter updates the list of matching commands for the current search text
+func (cp *cmdPalette) filter() {
+	seed := strings.TrimSpace(string(cp.search.EditTxt))
+	bynm := make(map[string]*PaletteCmd, len(cp.cmds))
+	comps := make(complete.Completions, len(cp.cmds))
+	for i, pc := range cp.cmds {
+		bynm[pc.Name] = pc
+		comps[i] = complete.Completion{Text: pc.Name}
+	}
+	if seed != "" {
+		comps = CompleteFuzzyRank(comps, seed)
+	}
+	if len(comps) > CmdPaletteMax {
+		comps = comps[:CmdPaletteMax]
+	}
+	cp.matches = cp.matches[:0]
+	config := kit.TypeAndNameList{}
+	for i, cm := range comps {
+		cp.matches = append(cp.matches, bynm[cm.Text])
+		config.Add(KiT_Action, "cmd-"+strconv.Itoa(i))
+	}
+	cp.sel = 0
+	mods, updt := cp.list.ConfigChildren(config, ki.UniqueNames)
+	if !mods {
+		updt = cp.list.UpdateStart()
+	}
+	for i, pc := range cp.matches {
+		ac := cp.list.Child(i).(*Action)
+		ac.SetAsMenu() // shows the shortcut
+		ac.Data = i
+		ac.Shortcut = pc.Shortcut
+		ac.Tooltip = pc.Desc
+		ac.SetSelectedState(i == cp.sel)
+		ac.ActionSig.ConnectOnly(cp.dlg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			cp.run(data.(int))
+		})
+		ac.SetText(pc.Name)
+	}
+	cp.list.UpdateEnd(updt)
+}
+
+// selectIdx selects the matching command at given index
+func (cp *cmdPalette) selectIdx(idx int) {
+	if idx < 0 || idx >= len(cp.matches) {
+		return
+	}
+	updt := cp.list.UpdateStart()
+	cp.sel = idx
+	for i, k := range cp.list.Kids {
+		k.(*Action).SetSelectedState(i == idx)
+	}
+	cp.list.UpdateEnd(updt)
+}
+
+// run closes the palette and runs the matching command at given index
+func (cp *cmdPalette) run(idx int) {
+	if idx < 0 || idx >= len(cp.matches) {
+		return
+	}
+	pc := cp.matches[idx]
+	cp.dlg.Accept()
+	if pc.Fun != nil {
+		pc.Fun()
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/goki/gi/gist"
+	"github.com/goki/gi/oswin"
 	"github.com/goki/gi/units"
 	"github.com/goki/ki/ints"
 	"github.com/goki/ki/ki"
@@ -54,6 +55,17 @@ func (cb *ComboBox) Disconnect() {
 	cb.ComboSig.DisconnectAll()
 }
 
+// AccessInfo describes the combobox for assistive technologies -- the
+// current item is its value
+func (cb *ComboBox) AccessInfo(an *oswin.AccessNode) {
+	cb.ButtonBase.AccessInfo(an)
+	an.Role = oswin.RoleComboBox
+	an.Name = ""
+	an.Value = AccessText(cb.Text)
+	an.SetState(cb.Editable, oswin.StateEditable)
+	an.SetState(true, oswin.StateHasPopup)
+}
+
 var ComboBoxProps = ki.Props{
 	"EnumType:Flag":    KiT_ButtonFlags,
 	"border-width":     units.NewPx(1),
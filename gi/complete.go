@@ -6,8 +6,11 @@ package gi
 
 import (
 	"image"
+	"sort"
+	"strings"
 	"sync"
 	"time"
+	"unicode"
 
 	"github.com/goki/ki/ki"
 	"github.com/goki/ki/kit"
@@ -74,6 +77,11 @@ var CompleteWaitMSec = 500
 // CompleteMaxItems is the max number of items to display in completer popup
 var CompleteMaxItems = 25
 
+// CompleteDocChars is the max number of characters of the documentation
+// (Desc) for each completion to show after its label in the popup menu --
+// the full documentation is shown as a tooltip -- 0 = none
+var CompleteDocChars = 40
+
 // IsAboutToShow returns true if the DelayTimer is started for
 // preparing to show a completion.  note: don't really need to lock
 func (c *Complete) IsAboutToShow() bool {
@@ -150,12 +158,16 @@ func (c *Complete) ShowNow(text string, posLn, posCh int, vp *Viewport2D, pt ima
 		if cmp.Label != "" {
 			text = cmp.Label
 		}
+		if doc := CompleteDocSnippet(cmp.Desc); doc != "" {
+			text += "  — " + doc
+		}
 		icon := cmp.Icon
-		m.AddAction(ActOpts{Icon: icon, Label: text, Data: cmp.Text},
+		ac := m.AddAction(ActOpts{Icon: icon, Label: text, Data: cmp.Text},
 			c, func(recv, send ki.Ki, sig int64, data interface{}) {
 				cc := recv.Embed(KiT_Complete).(*Complete)
 				cc.Complete(data.(string))
 			})
+		ac.Tooltip = cmp.Desc
 	}
 	c.Vp = vp
 	pvp := PopupMenu(m, pt.X, pt.Y, vp, "tf-completion-menu")
@@ -222,7 +234,9 @@ func (c *Complete) KeyInput(kf KeyFuns) bool { // true - caller should set key p
 				c.Complete(c.Completions[0].Text)
 			} else { // try to extend the seed
 				s := complete.ExtendSeed(c.Completions, c.Seed)
-				c.CompleteSig.Emit(c.This(), int64(CompleteExtend), s)
+				if strings.HasPrefix(s, c.Seed) { // fuzzy matches may not share the seed
+					c.CompleteSig.Emit(c.This(), int64(CompleteExtend), s)
+				}
 			}
 			return true
 		}
@@ -257,3 +271,134 @@ func CompleteEditText(text string, cp int, completion string, seed string) (ed c
 	ed.NewText = completion
 	return ed
 }
+
+// CompleteDocSnippet returns the first line of given documentation,
+// truncated to CompleteDocChars
+func CompleteDocSnippet(doc string) string {
+	if CompleteDocChars <= 0 {
+		return ""
+	}
+	doc = strings.TrimSpace(doc)
+	if nl := strings.IndexByte(doc, '\n'); nl >= 0 {
+		doc = strings.TrimSpace(doc[:nl])
+	}
+	rn := []rune(doc)
+	if len(rn) > CompleteDocChars {
+		return string(rn[:CompleteDocChars-1]) + "…"
+	}
+	return doc
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+// Fuzzy matching
+
+// CompleteSourceFunc returns all of the candidate completions for given
+// text up to the cursor, without filtering for the seed -- use with
+// CompleteFuzzy to get a complete.MatchFunc that ranks the candidates by
+// fuzzy matching against the seed.
+type CompleteSourceFunc func(data interface{}, text string, posLn, posCh int) complete.Completions
+
+// CompleteFuzzy returns a complete.MatchFunc that gets candidates from
+// given source and ranks them by fuzzy matching against the seed, which
+// is the identifier at the end of the text (see CompleteSeedIdent),
+// returning at most CompleteMaxItems of the best matches.
+func CompleteFuzzy(src CompleteSourceFunc) complete.MatchFunc {
+	return func(data interface{}, text string, posLn, posCh int) (md complete.Matches) {
+		md.Seed = CompleteSeedIdent(text)
+		md.Matches = CompleteFuzzyRank(src(data, text, posLn, posCh), md.Seed)
+		if len(md.Matches) > CompleteMaxItems {
+			md.Matches = md.Matches[:CompleteMaxItems]
+		}
+		return md
+	}
+}
+
+// CompleteEditWord is a complete.EditFunc that replaces the whole word at
+// the cursor with the completion, using complete.EditWord
+func CompleteEditWord(data interface{}, text string, cursorPos int, comp complete.Completion, seed string) (ed complete.Edit) {
+	return complete.EditWord(text, cursorPos, comp.Text, seed)
+}
+
+// CompleteSeedIdent returns the identifier (letters, digits and _) at the
+// end of given text, for use as a completion seed
+func CompleteSeedIdent(text string) string {
+	rn := []rune(text)
+	st := len(rn)
+	for st > 0 && (unicode.IsLetter(rn[st-1]) || unicode.IsDigit(rn[st-1]) || rn[st-1] == '_') {
+		st--
+	}
+	return string(rn[st:])
+}
+
+// CompleteFuzzyScore returns a score for how well given seed matches text,
+// and false if it does not match at all.  All of the seed characters must
+// appear in order in the text, ignoring case -- higher scores are given for
+// matches at the start of the text, at the start of words (after _ or
+// other punctuation, or at a lower-to-upper case transition), for runs of
+// consecutive characters, and for characters matching in case.
+func CompleteFuzzyScore(text, seed string) (int, bool) {
+	if seed == "" {
+		return 0, true
+	}
+	tr := []rune(text)
+	sr := []rune(seed)
+	score := 0
+	si := 0
+	prev := -2 // index of previous matched char
+	for ti, r := range tr {
+		if si == len(sr) {
+			break
+		}
+		if unicode.ToLower(r) != unicode.ToLower(sr[si]) {
+			continue
+		}
+		score++
+		if r == sr[si] {
+			score++
+		}
+		switch {
+		case ti == 0:
+			score += 8
+		case !unicode.IsLetter(tr[ti-1]) && !unicode.IsDigit(tr[ti-1]):
+			score += 6
+		case unicode.IsLower(tr[ti-1]) && unicode.IsUpper(r):
+			score += 6
+		}
+		if ti == prev+1 {
+			score += 4
+		}
+		prev = ti
+		si++
+	}
+	if si < len(sr) {
+		return 0, false
+	}
+	score -= (len(tr) - len(sr)) / 4 // slight preference for shorter
+	return score, true
+}
+
+// CompleteFuzzyRank returns the completions that match given seed, sorted
+// by descending CompleteFuzzyScore, and by text for equal scores
+func CompleteFuzzyRank(comps complete.Completions, seed string) complete.Completions {
+	type scored struct {
+		cmp   complete.Completion
+		score int
+	}
+	scs := make([]scored, 0, len(comps))
+	for _, cm := range comps {
+		if sc, ok := CompleteFuzzyScore(cm.Text, seed); ok {
+			scs = append(scs, scored{cm, sc})
+		}
+	}
+	sort.SliceStable(scs, func(i, j int) bool {
+		if scs[i].score != scs[j].score {
+			return scs[i].score > scs[j].score
+		}
+		return scs[i].cmp.Text < scs[j].cmp.Text
+	})
+	rc := make(complete.Completions, len(scs))
+	for i := range scs {
+		rc[i] = scs[i].cmp
+	}
+	return rc
+}
@@ -0,0 +1,563 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"fmt"
+	"image"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ints"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// DefaultDateFormat is the format of the date in a DatePicker without a
+// Format, as in time.Format
+var DefaultDateFormat = "2006-01-02"
+
+// CalendarFirstWeekday is the day that the weeks start with in the Calendar
+var CalendarFirstWeekday = time.Sunday
+
+// TimeFormatHasDate returns true if given time.Format layout shows the
+// year, month or day
+func TimeFormatHasDate(format string) bool {
+	for _, el := range []string{"2006", "06", "Jan", "01", "02", "_2", "Mon"} {
+		if strings.Contains(format, el) {
+			return true
+		}
+	}
+	return false
+}
+
+// TimeFormatHasClock returns true if given time.Format layout shows the
+// time of day
+func TimeFormatHasClock(format string) bool {
+	for _, el := range []string{"15", "03", "04", "PM", "pm"} {
+		if strings.Contains(format, el) {
+			return true
+		}
+	}
+	return false
+}
+
+// TimeFormatHasSeconds returns true if given time.Format layout shows the
+// seconds
+func TimeFormatHasSeconds(format string) bool {
+	return strings.Contains(format, "05")
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+// Calendar
+
+// Calendar shows the days of a month in a grid, with one row per week, for
+// choosing a date -- the previous and next months are shown with the
+// buttons at the top.  It is shown in a popup by the DatePicker, and can
+// also be used on its own -- call SetDate to configure it.
+type Calendar struct {
+	Frame
+	Date        time.Time `desc:"the selected date -- only its year, month and day are shown"`
+	Month       time.Time `desc:"the first day of the month that is shown"`
+	CalendarSig ki.Signal `copy:"-" json:"-" xml:"-" view:"-" desc:"signal for calendar -- has no signal types, just emitted with the chosen date as data when the user chooses a date"`
+}
+
+var KiT_Calendar = kit.Types.AddType(&Calendar{}, CalendarProps)
+
+// AddNewCalendar adds a new calendar to given parent node, with given name.
+func AddNewCalendar(parent ki.Ki, name string) *Calendar {
+	return parent.AddNewChild(KiT_Calendar, name).(*Calendar)
+}
+
+func (cal *Calendar) Disconnect() {
+	cal.Frame.Disconnect()
+	cal.CalendarSig.DisconnectAll()
+}
+
+var CalendarProps = ki.Props{
+	"EnumType:Flag":    KiT_NodeFlags,
+	"background-color": &Prefs.Colors.Background,
+	"color":            &Prefs.Colors.Font,
+	"border-width":     units.NewPx(0),
+	"margin":           units.NewPx(0),
+	"padding":          units.NewPx(2),
+}
+
+// SetDate sets the selected date, and shows its month
+func (cal *Calendar) SetDate(date time.Time) {
+	cal.Date = date
+	cal.Month = time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	cal.Config()
+}
+
+// SetMonth shows the month of given date, keeping the selected date
+func (cal *Calendar) SetMonth(date time.Time) {
+	cal.Month = time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	cal.Config()
+}
+
+// NavMonth shows the month that is given number of months after (+) or
+// before (-) the current one -- if the calendar is in a popup, it is popped
+// up again, as menu popups are closed when clicked
+func (cal *Calendar) NavMonth(delta int) {
+	cal.SetMonth(cal.Month.AddDate(0, delta, 0))
+	if cal.Viewport == nil || !cal.Viewport.IsMenu() {
+		return
+	}
+	pvp := cal.Viewport
+	win := cal.ParentWindow()
+	if win == nil {
+		return
+	}
+	pos := pvp.Geom.Pos
+	win.ClosePopup(pvp.This())
+	PopupCalendar(cal, pos.X, pos.Y, win.Viewport)
+}
+
+// SelectDateAction selects given date, as chosen by the user, emitting
+// the CalendarSig
+func (cal *Calendar) SelectDateAction(date time.Time) {
+	cal.Date = date
+	cal.Config()
+	cal.CalendarSig.Emit(cal.This(), 0, date)
+}
+
+// FirstShownDay returns the first day shown in the grid, which is the first
+// day of the week of the first of the month
+func (cal *Calendar) FirstShownDay() time.Time {
+	off := (int(cal.Month.Weekday()) - int(CalendarFirstWeekday) + 7) % 7
+	return cal.Month.AddDate(0, 0, -off)
+}
+
+// Config configures the calendar for the current Month and Date
+func (cal *Calendar) Config() {
+	if cal.Month.IsZero() {
+		if cal.Date.IsZero() {
+			cal.Date = time.Now()
+		}
+		cal.Month = time.Date(cal.Date.Year(), cal.Date.Month(), 1, 0, 0, 0, 0, cal.Date.Location())
+	}
+	cal.Lay = LayoutVert
+	config := kit.TypeAndNameList{}
+	config.Add(KiT_Layout, "header")
+	config.Add(KiT_Layout, "days")
+	mods, updt := cal.ConfigChildren(config, ki.UniqueNames)
+	if !mods {
+		updt = cal.UpdateStart()
+	}
+	cal.ConfigHeader()
+	cal.ConfigDays()
+	cal.SetFullReRender()
+	cal.UpdateEnd(updt)
+}
+
+// Header returns the layout with the month and the navigation buttons
+func (cal *Calendar) Header() *Layout {
+	return cal.ChildByName("header", 0).(*Layout)
+}
+
+// Days returns the grid of the weekday names and the days
+func (cal *Calendar) Days() *Layout {
+	return cal.ChildByName("days", 1).(*Layout)
+}
+
+// ConfigHeader configures the header with the month and year, and the
+// buttons for the previous and next months
+func (cal *Calendar) ConfigHeader() {
+	hd := cal.Header()
+	hd.Lay = LayoutHoriz
+	hd.SetStretchMaxWidth()
+	config := kit.TypeAndNameList{}
+	config.Add(KiT_Action, "prev")
+	config.Add(KiT_Stretch, "str-prev")
+	config.Add(KiT_Label, "month")
+	config.Add(KiT_Stretch, "str-next")
+	config.Add(KiT_Action, "next")
+	mods, updt := hd.ConfigChildren(config, ki.UniqueNames)
+	if mods {
+		for i, nm := range []string{"prev", "next"} {
+			ac := hd.ChildByName(nm, i*4).(*Action)
+			ac.Icon = IconName("wedge-left")
+			ac.Tooltip = T("Previous month")
+			delta := -1
+			if nm == "next" {
+				ac.Icon = IconName("wedge-right")
+				ac.Tooltip = T("Next month")
+				delta = 1
+			}
+			ac.SetProp("no-focus", true)
+			ac.ActionSig.ConnectOnly(cal.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				calr := recv.Embed(KiT_Calendar).(*Calendar)
+				calr.NavMonth(delta)
+			})
+		}
+		lb := hd.ChildByName("month", 2).(*Label)
+		lb.SetProp("font-weight", gist.WeightBold)
+		lb.SetProp("vertical-align", gist.AlignMiddle)
+		hd.UpdateEnd(updt)
+	}
+	lb := hd.ChildByName("month", 2).(*Label)
+	lb.SetText(fmt.Sprintf("%s %d", T(cal.Month.Month().String()), cal.Month.Year()))
+}
+
+// ConfigDays configures the grid with the names of the weekdays, and six
+// weeks of days starting with the week of the first of the Month
+func (cal *Calendar) ConfigDays() {
+	dg := cal.Days()
+	dg.Lay = LayoutGrid
+	dg.SetProp("columns", 7)
+	dg.SetProp("spacing", units.NewPx(1))
+	config := kit.TypeAndNameList{}
+	for i := 0; i < 7; i++ {
+		config.Add(KiT_Label, "wd-"+strconv.Itoa(i))
+	}
+	for i := 0; i < 42; i++ {
+		config.Add(KiT_Action, "day-"+strconv.Itoa(i))
+	}
+	mods, updt := dg.ConfigChildren(config, ki.UniqueNames)
+	if mods {
+		for i := 0; i < 7; i++ {
+			lb := dg.Child(i).(*Label)
+			wd := time.Weekday((int(CalendarFirstWeekday) + i) % 7)
+			lb.SetText(T(wd.String()[:2]))
+			lb.SetProp("font-size", "x-small")
+			lb.SetProp("text-align", gist.AlignCenter)
+			lb.SetProp("horizontal-align", gist.AlignCenter)
+		}
+		for i := 0; i < 42; i++ {
+			ac := dg.Child(7 + i).(*Action)
+			ac.Class = "menu-action"
+			ac.SetProp("horizontal-align", gist.AlignCenter)
+			ac.SetProp("min-width", units.NewEm(2))
+			ac.ActionSig.ConnectOnly(cal.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				calr := recv.Embed(KiT_Calendar).(*Calendar)
+				calr.SelectDateAction(data.(time.Time))
+			})
+		}
+		dg.UpdateEnd(updt)
+	}
+	ty, tm, td := time.Now().Date()
+	sy, sm, sd := cal.Date.Date()
+	day := cal.FirstShownDay()
+	for i := 0; i < 42; i++ {
+		ac := dg.Child(7 + i).(*Action)
+		y, m, d := day.Date()
+		ac.Data = day
+		ac.SetText(strconv.Itoa(d))
+		ac.Tooltip = day.Format(DefaultDateFormat)
+		if m != cal.Month.Month() {
+			ac.SetProp("color", "highlight-50")
+		} else {
+			ac.DeleteProp("color")
+		}
+		if y == ty && m == tm && d == td {
+			ac.SetProp("font-weight", gist.WeightBold)
+		} else {
+			ac.DeleteProp("font-weight")
+		}
+		ac.SetSelectedState(y == sy && m == sm && d == sd)
+		day = day.AddDate(0, 0, 1)
+	}
+}
+
+// PopupCalendar pops up given calendar at given location in the window of
+// given viewport, as a menu popup that is closed when a date is chosen
+func PopupCalendar(cal *Calendar, x, y int, parVp *Viewport2D) *Viewport2D {
+	win := parVp.Win
+	mainVp := win.Viewport
+	if !cal.HasChildren() {
+		cal.Config()
+	}
+
+	pvp := &Viewport2D{}
+	pvp.InitName(pvp, cal.Nm+"Popup")
+	pvp.Win = win
+	updt := pvp.UpdateStart()
+	pvp.SetProp("color", &Prefs.Colors.Font)
+	pvp.Fill = true
+	pvp.SetFlag(int(VpFlagPopup))
+	pvp.SetFlag(int(VpFlagMenu))
+
+	pvp.Geom.Pos = image.Point{x, y}
+	// note: not setting VpFlagPopupDestroyAll -- we keep the calendar intact,
+	// for popping it up again when the month is changed
+	frame := AddNewFrame(pvp, "Frame", LayoutVert)
+	frame.SetProps(MenuFrameProps, ki.NoUpdate)
+	frame.AddChild(cal.This())
+	var focus ki.Ki
+	for _, dk := range *cal.Days().Children() {
+		if ac, ok := dk.(*Action); ok && ac.IsSelected() {
+			focus = ac.This()
+		}
+	}
+	frame.Init2DTree()
+	frame.Style2DTree()                                    // sufficient to get sizes
+	frame.LayState.Alloc.Size = mainVp.LayState.Alloc.Size // give it the whole vp initially
+	frame.Size2DTree(0)                                    // collect sizes
+	pvp.Win = nil
+	vpsz := frame.LayState.Size.Pref.Min(mainVp.LayState.Alloc.Size.MulScalar(.9)).ToPoint()
+	x = ints.MaxInt(0, x)
+	y = ints.MaxInt(0, y)
+	x = ints.MinInt(x, mainVp.Geom.Size.X-vpsz.X) // fit
+	y = ints.MinInt(y, mainVp.Geom.Size.Y-vpsz.Y) // fit
+	pvp.Resize(vpsz)
+	pvp.Geom.Pos = image.Point{x, y}
+	pvp.UpdateEndNoSig(updt)
+	win.SetNextPopup(pvp.This(), focus)
+	return pvp
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+// DatePicker
+
+// DatePicker combines a TextField for entering a date, in its Format, with
+// a button that pops up a Calendar for choosing it -- all configured
+// within the Parts of the widget.  The time of day of the Date is kept when
+// a date is chosen from the calendar.
+type DatePicker struct {
+	PartsWidgetBase
+	Date          time.Time `xml:"date" desc:"current date"`
+	Format        string    `xml:"format" desc:"prop = format -- format of the date in the text field, as in time.Format -- blank defaults to DefaultDateFormat -- can include the time of day"`
+	DatePickerSig ki.Signal `copy:"-" json:"-" xml:"-" view:"-" desc:"signal for date picker -- has no signal types, just emitted when the date changes"`
+	Cal           *Calendar `copy:"-" json:"-" xml:"-" view:"-" desc:"the calendar shown in the popup -- made when first popped up"`
+}
+
+var KiT_DatePicker = kit.Types.AddType(&DatePicker{}, DatePickerProps)
+
+// AddNewDatePicker adds a new date picker to given parent node, with given name.
+func AddNewDatePicker(parent ki.Ki, name string) *DatePicker {
+	return parent.AddNewChild(KiT_DatePicker, name).(*DatePicker)
+}
+
+func (dp *DatePicker) CopyFieldsFrom(frm interface{}) {
+	fr := frm.(*DatePicker)
+	dp.PartsWidgetBase.CopyFieldsFrom(&fr.PartsWidgetBase)
+	dp.Date = fr.Date
+	dp.Format = fr.Format
+}
+
+func (dp *DatePicker) Disconnect() {
+	dp.PartsWidgetBase.Disconnect()
+	dp.DatePickerSig.DisconnectAll()
+}
+
+// AccessInfo describes the date picker for assistive technologies -- its
+// text field and button are parts and not described separately
+func (dp *DatePicker) AccessInfo(an *oswin.AccessNode) {
+	an.Role = oswin.RoleTextField
+	an.Value = dp.DateString()
+}
+
+var DatePickerProps = ki.Props{
+	"EnumType:Flag": KiT_NodeFlags,
+	"#text-field": ki.Props{
+		"margin":    units.NewPx(2),
+		"padding":   units.NewPx(2),
+		"clear-act": false,
+	},
+	"#popup": ki.Props{
+		"max-width":      units.NewEx(2),
+		"max-height":     units.NewEx(2),
+		"margin":         units.NewPx(1),
+		"padding":        units.NewPx(0),
+		"vertical-align": gist.AlignMiddle,
+		"fill":           &Prefs.Colors.Icon,
+		"stroke":         &Prefs.Colors.Font,
+	},
+}
+
+// FormatOrDefault returns the Format, or DefaultDateFormat if it is blank
+func (dp *DatePicker) FormatOrDefault() string {
+	if dp.Format == "" {
+		return DefaultDateFormat
+	}
+	return dp.Format
+}
+
+// DateString returns the Date in the Format
+func (dp *DatePicker) DateString() string {
+	return dp.Date.Format(dp.FormatOrDefault())
+}
+
+// SetDate sets the date, and updates the display
+func (dp *DatePicker) SetDate(date time.Time) {
+	updt := dp.UpdateStart()
+	dp.Date = date
+	dp.UpdateEnd(updt)
+}
+
+// SetDateAction calls SetDate and also emits the signal
+func (dp *DatePicker) SetDateAction(date time.Time) {
+	dp.SetDate(date)
+	dp.ConfigPartsIfNeeded()
+	dp.DatePickerSig.Emit(dp.This(), 0, dp.Date)
+}
+
+// ParseDate parses given text in the Format -- if the Format has no time
+// of day, that of the current Date is kept
+func (dp *DatePicker) ParseDate(txt string) (time.Time, error) {
+	format := dp.FormatOrDefault()
+	loc := dp.Date.Location()
+	nd, err := time.ParseInLocation(format, strings.TrimSpace(txt), loc)
+	if err != nil {
+		return nd, err
+	}
+	if !TimeFormatHasClock(format) {
+		h, m, s := dp.Date.Clock()
+		nd = time.Date(nd.Year(), nd.Month(), nd.Day(), h, m, s, dp.Date.Nanosecond(), loc)
+	}
+	return nd, nil
+}
+
+// OpenCalendar pops up the Calendar below the date picker, for choosing
+// the date
+func (dp *DatePicker) OpenCalendar() {
+	if dp.Viewport == nil || dp.Viewport.Win == nil {
+		return
+	}
+	if dp.Cal == nil {
+		dp.Cal = &Calendar{}
+		dp.Cal.InitName(dp.Cal, dp.Nm+"-calendar")
+		dp.Cal.CalendarSig.Connect(dp.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dpr := recv.Embed(KiT_DatePicker).(*DatePicker)
+			d := data.(time.Time)
+			h, m, s := dpr.Date.Clock()
+			dpr.SetDateAction(time.Date(d.Year(), d.Month(), d.Day(), h, m, s, dpr.Date.Nanosecond(), dpr.Date.Location()))
+		})
+	}
+	dt := dp.Date
+	if dt.IsZero() {
+		dt = time.Now()
+	}
+	dp.Cal.SetDate(dt)
+	dp.BBoxMu.RLock()
+	pos := image.Point{dp.WinBBox.Min.X, dp.WinBBox.Max.Y}
+	if pos.X == 0 && pos.Y == 0 { // offscreen
+		pos = image.Point{dp.ObjBBox.Min.X, dp.ObjBBox.Max.Y}
+	}
+	dp.BBoxMu.RUnlock()
+	PopupCalendar(dp.Cal, pos.X, pos.Y, dp.Viewport)
+}
+
+func (dp *DatePicker) ConfigParts() {
+	dp.Parts.Lay = LayoutHoriz
+	dp.Parts.SetProp("vertical-align", gist.AlignMiddle)
+	config := kit.TypeAndNameList{}
+	config.Add(KiT_TextField, "text-field")
+	if !dp.IsInactive() {
+		config.Add(KiT_Action, "popup")
+	}
+	mods, updt := dp.Parts.ConfigChildren(config, ki.NonUniqueNames)
+	if mods || gist.RebuildDefaultStyles {
+		tf := dp.Parts.ChildByName("text-field", 0).(*TextField)
+		tf.SetFlagState(dp.IsInactive(), int(Inactive))
+		tf.SetProp("clear-act", false)
+		tf.SetProp("min-width", units.NewCh(float32(len(dp.FormatOrDefault())+2)))
+		dp.StylePart(Node2D(tf))
+		tf.Txt = dp.DateString()
+		if !dp.IsInactive() {
+			tf.TextFieldSig.ConnectOnly(dp.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				if sig == int64(TextFieldDone) || sig == int64(TextFieldDeFocused) {
+					dpr := recv.Embed(KiT_DatePicker).(*DatePicker)
+					tf := send.(*TextField)
+					nd, err := dpr.ParseDate(tf.Text())
+					if err != nil {
+						log.Println(err)
+						tf.SetText(dpr.DateString())
+						return
+					}
+					if !nd.Equal(dpr.Date) {
+						dpr.SetDateAction(nd)
+					}
+				}
+			})
+			pu := dp.Parts.ChildByName("popup", 1).(*Action)
+			pu.Icon = IconName("wedge-down")
+			pu.Tooltip = T("Choose the date from a calendar")
+			pu.SetProp("no-focus", true)
+			dp.StylePart(Node2D(pu))
+			pu.ActionSig.ConnectOnly(dp.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				dpr := recv.Embed(KiT_DatePicker).(*DatePicker)
+				dpr.OpenCalendar()
+			})
+		}
+		dp.UpdateEnd(updt)
+	}
+}
+
+func (dp *DatePicker) ConfigPartsIfNeeded() {
+	if !dp.Parts.HasChildren() {
+		dp.ConfigParts()
+	}
+	tf := dp.Parts.ChildByName("text-field", 0).(*TextField)
+	txt := dp.DateString()
+	if tf.Txt != txt {
+		tf.SetText(txt)
+	}
+}
+
+func (dp *DatePicker) Init2D() {
+	dp.Init2DWidget()
+	dp.ConfigParts()
+}
+
+// StyleFromProps styles DatePicker-specific fields from ki.Prop properties
+// doesn't support inherit or default
+func (dp *DatePicker) StyleFromProps(props ki.Props, vp *Viewport2D) {
+	if fv, ok := props["format"]; ok {
+		dp.Format = kit.ToString(fv)
+	}
+}
+
+func (dp *DatePicker) Style2D() {
+	dp.StyMu.Lock()
+	dp.Style2DWidget()
+	dp.StyleFromProps(dp.Props, dp.Viewport)
+	dp.LayState.SetFromStyle(&dp.Sty.Layout) // also does reset
+	dp.StyMu.Unlock()
+	dp.ConfigParts()
+}
+
+func (dp *DatePicker) Size2D(iter int) {
+	dp.Size2DParts(iter)
+}
+
+func (dp *DatePicker) Layout2D(parBBox image.Rectangle, iter int) bool {
+	dp.ConfigPartsIfNeeded()
+	dp.Layout2DBase(parBBox, true, iter) // init style
+	dp.Layout2DParts(parBBox, iter)
+	return dp.Layout2DChildren(iter)
+}
+
+func (dp *DatePicker) Render2D() {
+	if dp.FullReRenderIfNeeded() {
+		return
+	}
+	if dp.PushBounds() {
+		dp.This().(Node2D).ConnectEvents2D()
+		dp.ConfigPartsIfNeeded()
+		dp.Render2DChildren()
+		dp.Render2DParts()
+		dp.PopBounds()
+	} else {
+		dp.DisconnectAllEvents(RegPri)
+	}
+}
+
+func (dp *DatePicker) ConnectEvents2D() {
+	dp.HoverTooltipEvent()
+}
+
+func (dp *DatePicker) HasFocus2D() bool {
+	if dp.IsInactive() {
+		return false
+	}
+	return dp.ContainsFocus() // needed for getting key events
+}
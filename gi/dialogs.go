@@ -216,11 +216,16 @@ func (dlg *Dialog) Close() {
 	}
 }
 
-// Accept accepts the dialog, activated by the default Ok button
+// Accept accepts the dialog, activated by the default Ok button -- does
+// nothing if the Ok button is inactive, e.g., while the values in the
+// dialog are not valid
 func (dlg *Dialog) Accept() {
 	if dlg == nil {
 		return
 	}
+	if okb := dlg.OkButton(); okb != nil && okb.IsInactive() {
+		return
+	}
 	dlg.State = DialogAccepted
 	if dlg.SigVal >= 0 {
 		dlg.DialogSig.Emit(dlg.This(), dlg.SigVal, nil)
@@ -290,8 +295,10 @@ func (dlg *Dialog) Frame() *Frame {
 	return dlg.Child(0).(*Frame)
 }
 
-// SetTitle sets the title and adds a Label named "title" to the given frame layout if passed
+// SetTitle sets the title and adds a Label named "title" to the given frame
+// layout if passed -- the title is translated into the current locale (see T)
 func (dlg *Dialog) SetTitle(title string, frame *Frame) *Label {
+	title = T(title)
 	dlg.Title = title
 	if frame != nil {
 		lab := AddNewLabel(frame, "title", title)
@@ -311,8 +318,10 @@ func (dlg *Dialog) TitleWidget(frame *Frame) (*Label, int) {
 }
 
 // SetPrompt sets the prompt and adds a Label named "prompt" to the given
-// frame layout if passed
+// frame layout if passed -- the prompt is translated into the current
+// locale (see T)
 func (dlg *Dialog) SetPrompt(prompt string, frame *Frame) *Label {
+	prompt = T(prompt)
 	dlg.Prompt = prompt
 	if frame != nil {
 		lab := AddNewLabel(frame, "prompt", prompt)
@@ -360,6 +369,26 @@ func (dlg *Dialog) ButtonBox(frame *Frame) (*Layout, int) {
 	return frame.Child(idx).(*Layout), idx
 }
 
+// OkButton returns the Ok button of the dialog, if it has one -- nil otherwise
+func (dlg *Dialog) OkButton() *Button {
+	if !dlg.HasChildren() {
+		return nil
+	}
+	frame, ok := dlg.Child(0).(*Frame)
+	if !ok {
+		return nil
+	}
+	bb, _ := dlg.ButtonBox(frame)
+	if bb == nil {
+		return nil
+	}
+	okb := bb.ChildByName("ok", 0)
+	if okb == nil {
+		return nil
+	}
+	return okb.Embed(KiT_Button).(*Button)
+}
+
 // Dialog Ok, Cancel options
 const (
 	AddOk     = true
@@ -395,7 +424,7 @@ func (dlg *Dialog) StdButtonConfig(stretch, ok, cancel bool) kit.TypeAndNameList
 func (dlg *Dialog) StdButtonConnect(ok, cancel bool, bb *Layout) {
 	if ok {
 		okb := bb.ChildByName("ok", 0).Embed(KiT_Button).(*Button)
-		okb.SetText("Ok")
+		okb.SetText(T("Ok"))
 		okb.ButtonSig.Connect(dlg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 			if sig == int64(ButtonClicked) {
 				dlg := recv.Embed(KiT_Dialog).(*Dialog)
@@ -405,7 +434,7 @@ func (dlg *Dialog) StdButtonConnect(ok, cancel bool, bb *Layout) {
 	}
 	if cancel {
 		canb := bb.ChildByName("cancel", 0).Embed(KiT_Button).(*Button)
-		canb.SetText("Cancel")
+		canb.SetText(T("Cancel"))
 		canb.ButtonSig.Connect(dlg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 			if sig == int64(ButtonClicked) {
 				dlg := recv.Embed(KiT_Dialog).(*Dialog)
@@ -529,7 +558,7 @@ func ChoiceDialog(avp *Viewport2D, opts DlgOpts, choices []string, recv ki.Ki, f
 		chnm := strcase.ToKebab(ch)
 		b := AddNewButton(bb, chnm)
 		b.SetProp("__cdSigVal", int64(i))
-		b.SetText(ch)
+		b.SetText(T(ch))
 		if chnm == "cancel" {
 			b.ButtonSig.Connect(dlg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 				if sig == int64(ButtonClicked) {
@@ -570,7 +599,7 @@ func NewKiDialog(avp *Viewport2D, iface reflect.Type, opts DlgOpts, recv ki.Ki,
 	nrow := frame.InsertNewChild(KiT_Layout, prIdx+2, "n-row").(*Layout)
 	nrow.Lay = LayoutHoriz
 
-	AddNewLabel(nrow, "n-label", "Number:  ")
+	AddNewLabel(nrow, "n-label", T("Number:")+"  ")
 
 	nsb := AddNewSpinBox(nrow, "n-field")
 	nsb.Defaults()
@@ -584,7 +613,7 @@ func NewKiDialog(avp *Viewport2D, iface reflect.Type, opts DlgOpts, recv ki.Ki,
 	trow := frame.InsertNewChild(KiT_Layout, prIdx+4, "t-row").(*Layout)
 	trow.Lay = LayoutHoriz
 
-	AddNewLabel(trow, "t-label", "Type:    ")
+	AddNewLabel(trow, "t-label", T("Type:")+"    ")
 
 	typs := AddNewComboBox(trow, "types")
 	typs.ItemsFromTypes(kit.Types.AllImplementersOf(iface, false), true, true, 50)
@@ -0,0 +1,975 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"reflect"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/dnd"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  DockLayout
+
+// DockSides are the places where a panel can be docked, relative to a group
+// of panels, or to the whole DockArea
+type DockSides int32
+
+const (
+	// DockCenter adds the panel as another tab in the group
+	DockCenter DockSides = iota
+
+	// DockLeft splits the space, with the panel on the left
+	DockLeft
+
+	// DockRight splits the space, with the panel on the right
+	DockRight
+
+	// DockTop splits the space, with the panel on the top
+	DockTop
+
+	// DockBottom splits the space, with the panel on the bottom
+	DockBottom
+
+	DockSidesN
+)
+
+//go:generate stringer -type=DockSides
+
+var KiT_DockSides = kit.Enums.AddEnumAltLower(DockSidesN, kit.NotBitFlag, nil, "Dock")
+
+func (ev DockSides) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *DockSides) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// DockLayout describes the arrangement of docked panels: either a split of
+// the space among the Kids, or, if there are no Kids, a group of panels
+// shown as tabs
+type DockLayout struct {
+	Dim    mat32.Dims   `desc:"for a split, the dimension along which the space is split"`
+	Splits []float32    `desc:"for a split, the proportion of the space of each of the Kids"`
+	Kids   []DockLayout `desc:"for a split, the arrangements of each part of the space -- if empty, this is a group of panels"`
+	Panels []string     `desc:"for a group, the names of the panels, in tab order"`
+	Sel    int          `desc:"for a group, the index of the selected panel"`
+}
+
+// IsGroup returns true if this is a group of panels, not a split
+func (dl *DockLayout) IsGroup() bool {
+	return len(dl.Kids) == 0
+}
+
+// IsEmpty returns true if this is a group without any panels
+func (dl *DockLayout) IsEmpty() bool {
+	return dl.IsGroup() && len(dl.Panels) == 0
+}
+
+// GroupOf returns the group containing the panel of given name, or nil
+func (dl *DockLayout) GroupOf(name string) *DockLayout {
+	if dl.IsGroup() {
+		for _, pn := range dl.Panels {
+			if pn == name {
+				return dl
+			}
+		}
+		return nil
+	}
+	for i := range dl.Kids {
+		if gp := dl.Kids[i].GroupOf(name); gp != nil {
+			return gp
+		}
+	}
+	return nil
+}
+
+// FirstGroup returns the first group, in depth-first order
+func (dl *DockLayout) FirstGroup() *DockLayout {
+	if dl.IsGroup() {
+		return dl
+	}
+	return dl.Kids[0].FirstGroup()
+}
+
+// AllPanels returns the names of all the panels in the layout
+func (dl *DockLayout) AllPanels() []string {
+	if dl.IsGroup() {
+		return dl.Panels
+	}
+	var pns []string
+	for i := range dl.Kids {
+		pns = append(pns, dl.Kids[i].AllPanels()...)
+	}
+	return pns
+}
+
+// Remove removes the panel of given name, and prunes the layout -- returns
+// false if not found
+func (dl *DockLayout) Remove(name string) bool {
+	gp := dl.GroupOf(name)
+	if gp == nil {
+		return false
+	}
+	for i, pn := range gp.Panels {
+		if pn == name {
+			gp.Panels = append(gp.Panels[:i], gp.Panels[i+1:]...)
+			if gp.Sel >= i && gp.Sel > 0 {
+				gp.Sel--
+			}
+			break
+		}
+	}
+	dl.Prune()
+	return true
+}
+
+// Keep removes the panels for which keep returns false, and prunes the
+// layout
+func (dl *DockLayout) Keep(keep func(name string) bool) {
+	if dl.IsGroup() {
+		pns := make([]string, 0, len(dl.Panels))
+		for _, pn := range dl.Panels {
+			if keep(pn) {
+				pns = append(pns, pn)
+			}
+		}
+		dl.Panels = pns
+	} else {
+		for i := range dl.Kids {
+			dl.Kids[i].Keep(keep)
+		}
+	}
+	dl.Prune()
+}
+
+// Prune removes groups without any panels from splits, and replaces splits
+// with only one part by that part
+func (dl *DockLayout) Prune() {
+	if dl.IsGroup() {
+		if dl.Sel >= len(dl.Panels) || dl.Sel < 0 {
+			dl.Sel = 0
+		}
+		return
+	}
+	if len(dl.Splits) != len(dl.Kids) {
+		dl.Splits = nil
+	}
+	kids := dl.Kids[:0]
+	var splits []float32
+	for i := range dl.Kids {
+		dl.Kids[i].Prune()
+		if dl.Kids[i].IsEmpty() {
+			continue
+		}
+		kids = append(kids, dl.Kids[i])
+		if dl.Splits != nil {
+			splits = append(splits, dl.Splits[i])
+		}
+	}
+	switch len(kids) {
+	case 0:
+		*dl = DockLayout{}
+	case 1:
+		*dl = kids[0]
+	default:
+		dl.Kids = kids
+		dl.Splits = splits
+	}
+}
+
+// Insert inserts the panel of given name relative to the group containing
+// the target panel, or the whole layout if target is empty -- DockCenter
+// adds it to the group (or the first group, for the whole layout), and the
+// other sides split the space, with frac of it for the new panel -- returns
+// false if the target is not found
+func (dl *DockLayout) Insert(name, target string, side DockSides, frac float32) bool {
+	nd := dl
+	if target != "" {
+		nd = dl.GroupOf(target)
+		if nd == nil {
+			return false
+		}
+	}
+	if side == DockCenter || nd.IsEmpty() {
+		gp := nd.FirstGroup()
+		gp.Panels = append(gp.Panels, name)
+		gp.Sel = len(gp.Panels) - 1
+		return true
+	}
+	dim := mat32.X
+	if side == DockTop || side == DockBottom {
+		dim = mat32.Y
+	}
+	first := side == DockLeft || side == DockTop
+	ng := DockLayout{Panels: []string{name}}
+	if !nd.IsGroup() && nd.Dim == dim && len(nd.Splits) == len(nd.Kids) {
+		// add as another part of the existing split
+		for i := range nd.Splits {
+			nd.Splits[i] *= 1 - frac
+		}
+		if first {
+			nd.Kids = append([]DockLayout{ng}, nd.Kids...)
+			nd.Splits = append([]float32{frac}, nd.Splits...)
+		} else {
+			nd.Kids = append(nd.Kids, ng)
+			nd.Splits = append(nd.Splits, frac)
+		}
+		return true
+	}
+	old := *nd
+	if first {
+		*nd = DockLayout{Dim: dim, Kids: []DockLayout{ng, old}, Splits: []float32{frac, 1 - frac}}
+	} else {
+		*nd = DockLayout{Dim: dim, Kids: []DockLayout{old, ng}, Splits: []float32{1 - frac, frac}}
+	}
+	return true
+}
+
+// DockAreaLayout is the full layout of the panels of a DockArea, which can
+// be saved and restored, e.g., in the preferences
+type DockAreaLayout struct {
+	Root   DockLayout   `desc:"the arrangement of the panels docked in the area"`
+	Floats []DockLayout `desc:"groups of panels floating in their own windows"`
+	Hidden []string     `desc:"panels that have been closed"`
+}
+
+// Open opens the layout from a JSON-formatted file
+func (dal *DockAreaLayout) Open(filename FileName) error {
+	b, err := ioutil.ReadFile(string(filename))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dal)
+}
+
+// Save saves the layout to a JSON-formatted file
+func (dal *DockAreaLayout) Save(filename FileName) error {
+	b, err := json.MarshalIndent(dal, "", "  ")
+	if err != nil {
+		log.Println(err) // unlikely
+		return err
+	}
+	err = ioutil.WriteFile(string(filename), b, 0644)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  DockArea
+
+// DockArea manages a set of panels (any widgets, identified by their name,
+// which is also shown in their tab) that the user can arrange: panels are
+// shown in groups of tabs, in regions of space split by SplitViews, and
+// dragging a tab onto a group docks the panel at that edge of the group, or
+// in the middle, as another tab in the group.  The context menu of a tab
+// can dock the panel at the edges of the whole area, float it into its own
+// window, or close it.  The arrangement is described by a DockAreaLayout,
+// which can be saved and restored, e.g., with SavePrefs and OpenPrefs --
+// typically the main widget of a window, e.g., for IDE-like apps.
+type DockArea struct {
+	Layout
+	Panels   map[string]Node2D `json:"-" xml:"-" view:"-" desc:"all the panels, by name, whether docked, floating or closed"`
+	Hidden   []string          `desc:"names of the panels that have been closed -- ShowPanel shows them again"`
+	Floats   []*DockGroup      `json:"-" xml:"-" view:"-" desc:"groups of panels floating in their own windows"`
+	AutoSave bool              `desc:"save the layout to the preferences, with SavePrefs, whenever it changes"`
+	DockSig  ki.Signal         `copy:"-" json:"-" xml:"-" view:"-" desc:"signal for changes to the docking of panels -- see DockSignals for the types"`
+}
+
+var KiT_DockArea = kit.Types.AddType(&DockArea{}, DockAreaProps)
+
+// AddNewDockArea adds a new dockarea to given parent node, with given name.
+func AddNewDockArea(parent ki.Ki, name string) *DockArea {
+	return parent.AddNewChild(KiT_DockArea, name).(*DockArea)
+}
+
+func (da *DockArea) Disconnect() {
+	da.Layout.Disconnect()
+	da.DockSig.DisconnectAll()
+}
+
+var DockAreaProps = ki.Props{
+	"EnumType:Flag": KiT_NodeFlags,
+	"max-width":     -1,
+	"max-height":    -1,
+}
+
+// DockSignals are signals that the DockArea can send
+type DockSignals int64
+
+const (
+	// DockLayoutChanged indicates that the layout of the panels has changed
+	// -- data is nil
+	DockLayoutChanged DockSignals = iota
+
+	// DockPanelClosed indicates that a panel was closed -- data is the name
+	// of the panel
+	DockPanelClosed
+
+	DockSignalsN
+)
+
+//go:generate stringer -type=DockSignals
+
+// DockMimeType is the mime type of the data for dragging panels, which is
+// the name of the panel
+const DockMimeType = "application/x-gogi-dock-panel"
+
+// DockSideFrac is the proportion of the space given to a panel docked at
+// the edge of the whole DockArea -- panels docked at the edge of a group
+// get half of the space of the group
+var DockSideFrac = float32(0.25)
+
+// AddPanel adds given widget, which must not have a parent, as a panel,
+// docked relative to the group containing the target panel, or the whole
+// area if target is empty -- its name identifies it, and is shown in its tab
+func (da *DockArea) AddPanel(widg Node2D, target string, side DockSides) {
+	if da.Panels == nil {
+		da.Panels = make(map[string]Node2D)
+	}
+	da.Panels[widg.Name()] = widg
+	lay := da.CurLayout()
+	da.insertPanel(&lay, widg.Name(), target, side)
+	da.ApplyLayout(&lay)
+}
+
+// AddNewPanel adds a new widget of given type as a panel with given name
+// (see AddPanel), returning the widget
+func (da *DockArea) AddNewPanel(typ reflect.Type, name, target string, side DockSides) Node2D {
+	widg := ki.NewOfType(typ).(Node2D)
+	widg.InitName(widg, name)
+	da.AddPanel(widg, target, side)
+	return widg
+}
+
+// Panel returns the panel of given name, or nil if none
+func (da *DockArea) Panel(name string) Node2D {
+	return da.Panels[name]
+}
+
+// insertPanel inserts the panel into the layout, at the area level if the
+// target is not found
+func (da *DockArea) insertPanel(lay *DockAreaLayout, name, target string, side DockSides) {
+	frac := DockSideFrac
+	if target != "" {
+		frac = 0.5
+	}
+	if !lay.Root.Insert(name, target, side, frac) {
+		lay.Root.Insert(name, "", side, DockSideFrac)
+	}
+}
+
+// removePanel removes the panel from everywhere in the layout
+func (lay *DockAreaLayout) removePanel(name string) {
+	lay.Root.Remove(name)
+	fls := lay.Floats[:0]
+	for i := range lay.Floats {
+		lay.Floats[i].Remove(name)
+		if !lay.Floats[i].IsEmpty() {
+			fls = append(fls, lay.Floats[i])
+		}
+	}
+	lay.Floats = fls
+	for i, pn := range lay.Hidden {
+		if pn == name {
+			lay.Hidden = append(lay.Hidden[:i], lay.Hidden[i+1:]...)
+			break
+		}
+	}
+}
+
+// DockPanel docks the panel of given name relative to the group containing
+// the target panel, or the whole area if target is empty, moving it from
+// wherever it is -- this is what happens when a tab is dropped on a group
+func (da *DockArea) DockPanel(name, target string, side DockSides) {
+	if _, has := da.Panels[name]; !has || name == target {
+		return
+	}
+	lay := da.CurLayout()
+	lay.removePanel(name)
+	da.insertPanel(&lay, name, target, side)
+	da.ApplyLayout(&lay)
+}
+
+// FloatPanel moves the panel of given name into its own window
+func (da *DockArea) FloatPanel(name string) {
+	if _, has := da.Panels[name]; !has {
+		return
+	}
+	lay := da.CurLayout()
+	lay.removePanel(name)
+	lay.Floats = append(lay.Floats, DockLayout{Panels: []string{name}})
+	da.ApplyLayout(&lay)
+}
+
+// ClosePanel closes (hides) the panel of given name -- ShowPanel shows it
+// again -- emits DockPanelClosed
+func (da *DockArea) ClosePanel(name string) {
+	if _, has := da.Panels[name]; !has {
+		return
+	}
+	lay := da.CurLayout()
+	lay.removePanel(name)
+	lay.Hidden = append(lay.Hidden, name)
+	da.ApplyLayout(&lay)
+	da.DockSig.Emit(da.This(), int64(DockPanelClosed), name)
+}
+
+// ShowPanel shows the panel of given name, docking it in the first group if
+// it has been closed, and selects its tab
+func (da *DockArea) ShowPanel(name string) {
+	if _, has := da.Panels[name]; !has {
+		return
+	}
+	if da.PanelIsHidden(name) {
+		da.DockPanel(name, "", DockCenter)
+		return
+	}
+	if dg := da.GroupOf(name); dg != nil {
+		dg.SelectTabByName(name)
+		if win := dg.ParentWindow(); win != nil && win.OSWin != nil {
+			win.OSWin.Raise()
+		}
+	}
+}
+
+// PanelIsHidden returns true if the panel of given name has been closed
+func (da *DockArea) PanelIsHidden(name string) bool {
+	for _, pn := range da.Hidden {
+		if pn == name {
+			return true
+		}
+	}
+	return false
+}
+
+// AllGroups returns all the groups of panels, docked and floating
+func (da *DockArea) AllGroups() []*DockGroup {
+	var gps []*DockGroup
+	da.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		if dg, ok := k.(*DockGroup); ok {
+			gps = append(gps, dg)
+			return ki.Break
+		}
+		return ki.Continue
+	})
+	return append(gps, da.Floats...)
+}
+
+// GroupOf returns the group containing the panel of given name, or nil if
+// it is closed
+func (da *DockArea) GroupOf(name string) *DockGroup {
+	for _, dg := range da.AllGroups() {
+		if _, err := dg.TabIndexByName(name); err == nil {
+			return dg
+		}
+	}
+	return nil
+}
+
+// dockLayoutOf returns the layout of given widget within the area
+func dockLayoutOf(k ki.Ki) DockLayout {
+	switch nd := k.(type) {
+	case *DockGroup:
+		return nd.DockLayout()
+	case *SplitView:
+		dl := DockLayout{Dim: nd.Dim}
+		mat32.CopyFloat32s(&dl.Splits, nd.Splits)
+		for _, kid := range nd.Kids {
+			dl.Kids = append(dl.Kids, dockLayoutOf(kid))
+		}
+		dl.Prune()
+		return dl
+	}
+	return DockLayout{}
+}
+
+// CurLayout returns the current layout of the panels
+func (da *DockArea) CurLayout() DockAreaLayout {
+	var lay DockAreaLayout
+	if da.HasChildren() {
+		lay.Root = dockLayoutOf(da.Child(0))
+	}
+	for _, dg := range da.Floats {
+		if fl := dg.DockLayout(); !fl.IsEmpty() {
+			lay.Floats = append(lay.Floats, fl)
+		}
+	}
+	lay.Hidden = append(lay.Hidden, da.Hidden...)
+	return lay
+}
+
+// ApplyLayout arranges the panels according to given layout -- panels that
+// are not in the layout are docked in the first group, and names in the
+// layout that are not panels are ignored -- emits DockLayoutChanged
+func (da *DockArea) ApplyLayout(lay *DockAreaLayout) {
+	placed := make(map[string]bool, len(da.Panels))
+	keep := func(name string) bool {
+		if _, has := da.Panels[name]; !has || placed[name] {
+			return false
+		}
+		placed[name] = true
+		return true
+	}
+	nlay := DockAreaLayout{Root: lay.Root}
+	nlay.Root.Keep(keep)
+	for _, fl := range lay.Floats {
+		fl.Keep(keep)
+		if !fl.IsEmpty() {
+			nlay.Floats = append(nlay.Floats, fl)
+		}
+	}
+	for _, pn := range lay.Hidden {
+		if keep(pn) {
+			nlay.Hidden = append(nlay.Hidden, pn)
+		}
+	}
+	for pn := range da.Panels { // new panels
+		if !placed[pn] {
+			nlay.Root.Insert(pn, "", DockCenter, 0)
+		}
+	}
+
+	updt := da.UpdateStart()
+	da.SetFullReRender()
+	// detach the panels so they are not destroyed along with their groups
+	for _, widg := range da.Panels {
+		if par := widg.Parent(); par != nil {
+			par.DeleteChild(widg, ki.NoDestroyKids)
+		}
+	}
+	fls := da.Floats
+	da.Floats = nil
+	for _, dg := range fls {
+		if win := dg.ParentWindow(); win != nil {
+			win.Close()
+		}
+	}
+	da.Lay = LayoutVert
+	da.DeleteChildren(ki.DestroyKids)
+	idx := 0
+	da.ConfigLayout(da.This(), &nlay.Root, &idx)
+	for i := range nlay.Floats {
+		da.NewFloat(&nlay.Floats[i])
+	}
+	da.Hidden = nlay.Hidden
+	da.UpdateEnd(updt)
+	da.LayoutChanged()
+}
+
+// LayoutChanged saves the layout if AutoSave, and emits DockLayoutChanged
+func (da *DockArea) LayoutChanged() {
+	if da.AutoSave {
+		da.SavePrefs()
+	}
+	da.DockSig.Emit(da.This(), int64(DockLayoutChanged), nil)
+}
+
+// ConfigLayout adds the widgets for given layout to given parent, with idx
+// for unique names
+func (da *DockArea) ConfigLayout(par ki.Ki, dl *DockLayout, idx *int) {
+	nm := fmt.Sprintf("dock-%d", *idx)
+	*idx++
+	if dl.IsGroup() {
+		dg := AddNewDockGroup(par, nm)
+		dg.ConfigGroup(da, dl)
+		return
+	}
+	sv := AddNewSplitView(par, nm)
+	sv.Dim = dl.Dim
+	for i := range dl.Kids {
+		da.ConfigLayout(sv, &dl.Kids[i], idx)
+	}
+	if len(dl.Splits) == len(dl.Kids) {
+		sv.SetSplitsList(dl.Splits)
+	}
+}
+
+// NewFloat makes a new window for the floating group of panels with given
+// layout
+func (da *DockArea) NewFloat(dl *DockLayout) *DockGroup {
+	pn := dl.Panels[0]
+	width, height := 400, 300
+	if widg := da.Panels[pn]; widg != nil {
+		if sz := widg.AsNode2D().BBox.Size(); sz.X > 0 && sz.Y > 0 {
+			width, height = sz.X, sz.Y
+		}
+	}
+	win := NewMainWindow("dock-float-"+pn, pn, width, height)
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+	mfr := win.SetMainFrame()
+	dg := AddNewDockGroup(mfr, "dock-float")
+	dg.ConfigGroup(da, dl)
+	da.Floats = append(da.Floats, dg)
+	win.SetCloseCleanFunc(func(w *Window) {
+		da.FloatClosed(dg)
+	})
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+	return dg
+}
+
+// FloatClosed is called when the window of a floating group is closed by
+// the user -- its panels are closed (hidden)
+func (da *DockArea) FloatClosed(dg *DockGroup) {
+	fi := -1
+	for i, fl := range da.Floats {
+		if fl == dg {
+			fi = i
+		}
+	}
+	if fi < 0 { // closed by ApplyLayout
+		return
+	}
+	da.Floats = append(da.Floats[:fi], da.Floats[fi+1:]...)
+	var pns []string
+	for _, widg := range dg.Frame().Kids {
+		pns = append(pns, widg.Name())
+	}
+	for _, pn := range pns {
+		dg.Frame().DeleteChild(da.Panels[pn], ki.NoDestroyKids)
+		da.Hidden = append(da.Hidden, pn)
+	}
+	da.LayoutChanged()
+	for _, pn := range pns {
+		da.DockSig.Emit(da.This(), int64(DockPanelClosed), pn)
+	}
+}
+
+// PrefsFileName returns the name of the file in the app preferences
+// directory that the layout is saved to -- based on the name of the area
+func (da *DockArea) PrefsFileName() FileName {
+	return FileName(filepath.Join(oswin.TheApp.AppPrefsDir(), "dock_"+da.Nm+".json"))
+}
+
+// SavePrefs saves the current layout to the app preferences directory --
+// see PrefsFileName
+func (da *DockArea) SavePrefs() error {
+	lay := da.CurLayout()
+	return lay.Save(da.PrefsFileName())
+}
+
+// OpenPrefs opens the layout from the app preferences directory, and
+// applies it -- see PrefsFileName -- typically called after adding all the
+// panels
+func (da *DockArea) OpenPrefs() error {
+	var lay DockAreaLayout
+	if err := lay.Open(da.PrefsFileName()); err != nil {
+		return err
+	}
+	da.ApplyLayout(&lay)
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  DockGroup
+
+// DockGroup is a group of panels in a DockArea, shown as tabs -- tabs can
+// be dragged onto other groups to dock the panel there
+type DockGroup struct {
+	TabView
+	Area     *DockArea `json:"-" xml:"-" view:"-" desc:"the area that the panels belong to"`
+	DropSide DockSides `json:"-" xml:"-" view:"-" desc:"where a panel being dragged over the group would be docked -- DockSidesN if none"`
+}
+
+var KiT_DockGroup = kit.Types.AddType(&DockGroup{}, DockGroupProps)
+
+// AddNewDockGroup adds a new dockgroup to given parent node, with given name.
+func AddNewDockGroup(parent ki.Ki, name string) *DockGroup {
+	return parent.AddNewChild(KiT_DockGroup, name).(*DockGroup)
+}
+
+var DockGroupProps = ki.Props{
+	"EnumType:Flag":    KiT_NodeFlags,
+	"border-color":     &Prefs.Colors.Border,
+	"border-width":     0,
+	"background-color": &Prefs.Colors.Background,
+	"color":            &Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
+
+// ConfigGroup adds the panels of given group layout, for given area
+func (dg *DockGroup) ConfigGroup(da *DockArea, dl *DockLayout) {
+	dg.Area = da
+	dg.DropSide = DockSidesN
+	dg.NoDeleteTabs = true
+	for _, pn := range dl.Panels {
+		dg.AddTab(da.Panels[pn], pn)
+	}
+	dg.SelectTabIndex(dl.Sel)
+}
+
+// DockLayout returns the layout of the group
+func (dg *DockGroup) DockLayout() DockLayout {
+	dl := DockLayout{}
+	for _, widg := range dg.Frame().Kids {
+		dl.Panels = append(dl.Panels, widg.Name())
+	}
+	if _, idx, ok := dg.CurTab(); ok {
+		dl.Sel = idx
+	}
+	return dl
+}
+
+// IsFloat returns true if the group is floating in its own window
+func (dg *DockGroup) IsFloat() bool {
+	if dg.Area == nil {
+		return false
+	}
+	for _, fl := range dg.Area.Floats {
+		if fl == dg {
+			return true
+		}
+	}
+	return false
+}
+
+// TabAt returns the name of the panel whose tab is at given window
+// position, or "" if none
+func (dg *DockGroup) TabAt(pos image.Point) string {
+	idx := dg.TabIndexAt(pos)
+	if idx < 0 {
+		return ""
+	}
+	return dg.TabName(idx)
+}
+
+// SideAt returns where a panel dropped at given window position would be
+// docked: at the edge of the group if within a quarter of its size from the
+// edge, else in the center
+func (dg *DockGroup) SideAt(pos image.Point) DockSides {
+	if dg.Tabs().PosInWinBBox(pos) {
+		return DockCenter
+	}
+	bb := dg.WinBBox
+	sz := bb.Size()
+	if sz.X <= 0 || sz.Y <= 0 {
+		return DockCenter
+	}
+	fx := float32(pos.X-bb.Min.X) / float32(sz.X)
+	fy := float32(pos.Y-bb.Min.Y) / float32(sz.Y)
+	dist := []float32{0.25, fx, 1 - fx, fy, 1 - fy}
+	side := DockCenter
+	for s := DockLeft; s < DockSidesN; s++ {
+		if dist[s] < dist[side] {
+			side = s
+		}
+	}
+	return side
+}
+
+// DropRegion returns the region of the group, in window coordinates, that
+// a panel docked at given side would occupy
+func (dg *DockGroup) DropRegion(side DockSides) image.Rectangle {
+	bb := dg.WinBBox
+	cx := (bb.Min.X + bb.Max.X) / 2
+	cy := (bb.Min.Y + bb.Max.Y) / 2
+	switch side {
+	case DockLeft:
+		bb.Max.X = cx
+	case DockRight:
+		bb.Min.X = cx
+	case DockTop:
+		bb.Max.Y = cy
+	case DockBottom:
+		bb.Min.Y = cy
+	}
+	return bb
+}
+
+// SetDropSide sets the DropSide, re-rendering if it changes
+func (dg *DockGroup) SetDropSide(side DockSides) {
+	if dg.DropSide == side {
+		return
+	}
+	dg.DropSide = side
+	dg.SetFullReRender()
+	dg.UpdateSig()
+}
+
+// DragStart starts dragging the panel of given name
+func (dg *DockGroup) DragStart(name string) {
+	_, tab, ok := dg.TabAtIndex(dg.tabIndex(name))
+	if !ok {
+		return
+	}
+	sp := &Sprite{}
+	sp.GrabRenderFrom(tab)
+	ImageClearer(sp.Pixels, 50.0)
+	tab.SetButtonState(ButtonActive) // it is not released on the tab
+	dg.ParentWindow().StartDragNDrop(dg.This(), mimedata.NewMime(DockMimeType, []byte(name)), sp)
+}
+
+func (dg *DockGroup) tabIndex(name string) int {
+	idx, err := dg.TabIndexByName(name)
+	if err != nil {
+		return -1
+	}
+	return idx
+}
+
+// Drop docks the dragged panel according to the drop position -- a panel
+// dropped on a tab of its own group is moved to that position in the tabs
+func (dg *DockGroup) Drop(de *dnd.Event) {
+	name := string(de.Data.TypeData(DockMimeType))
+	side := dg.SideAt(de.Where)
+	dg.DropSide = DockSidesN
+	if win := dg.ParentWindow(); win != nil {
+		win.ClearDragNDrop()
+	}
+	target := ""
+	for _, pn := range dg.DockLayout().Panels {
+		if pn != name && target == "" {
+			target = pn
+		}
+	}
+	if from, err := dg.TabIndexByName(name); err == nil && side == DockCenter {
+		to := dg.TabIndexAt(de.Where)
+		if to >= 0 && to != from {
+			dg.MoveTabAction(from, to)
+			dg.Area.LayoutChanged()
+			return
+		}
+		target = ""
+	}
+	if target == "" { // nothing to do
+		dg.SetFullReRender()
+		dg.UpdateSig()
+		return
+	}
+	dg.Area.DockPanel(name, target, side)
+}
+
+// MakeTabMenu makes the context menu for the tab of panel of given name
+func (dg *DockGroup) MakeTabMenu(name string, m *Menu) {
+	if dg.IsFloat() {
+		m.AddAction(ActOpts{Label: "Dock", Data: name}, dg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dgg := recv.Embed(KiT_DockGroup).(*DockGroup)
+			dgg.Area.DockPanel(data.(string), "", DockCenter)
+		})
+	} else {
+		m.AddAction(ActOpts{Label: "Float", Data: name}, dg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dgg := recv.Embed(KiT_DockGroup).(*DockGroup)
+			dgg.Area.FloatPanel(data.(string))
+		})
+	}
+	for _, side := range []DockSides{DockLeft, DockRight, DockTop, DockBottom} {
+		m.AddAction(ActOpts{Label: "Dock " + side.String()[4:], Data: side}, dg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dgg := recv.Embed(KiT_DockGroup).(*DockGroup)
+			dgg.Area.DockPanel(name, "", data.(DockSides))
+		})
+	}
+	m.AddSeparator("sep-close")
+	m.AddAction(ActOpts{Label: "Close", Data: name}, dg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		dgg := recv.Embed(KiT_DockGroup).(*DockGroup)
+		dgg.Area.ClosePanel(data.(string))
+	})
+}
+
+// DockEvents connects the events for dragging tabs and tab context menus --
+// at HiPri, so that drops anywhere in the group are docking drops
+func (dg *DockGroup) DockEvents() {
+	dg.ConnectEvent(oswin.DNDEvent, HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		de := d.(*dnd.Event)
+		dgg := recv.Embed(KiT_DockGroup).(*DockGroup)
+		if dgg.Area == nil {
+			return
+		}
+		switch de.Action {
+		case dnd.Start:
+			if name := dgg.TabAt(de.Where); name != "" {
+				de.SetProcessed()
+				dgg.DragStart(name)
+			}
+		case dnd.DropOnTarget:
+			if de.Data.HasType(DockMimeType) {
+				de.SetProcessed()
+				dgg.Drop(de)
+			}
+		}
+	})
+	dg.ConnectEvent(oswin.DNDMoveEvent, HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		de := d.(*dnd.MoveEvent)
+		dgg := recv.Embed(KiT_DockGroup).(*DockGroup)
+		win := dgg.ParentWindow()
+		if dgg.Area == nil || win == nil || !win.EventMgr.DNDData.HasType(DockMimeType) {
+			return
+		}
+		dgg.SetDropSide(dgg.SideAt(de.Where))
+	})
+	dg.ConnectEvent(oswin.DNDFocusEvent, HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		de := d.(*dnd.FocusEvent)
+		dgg := recv.Embed(KiT_DockGroup).(*DockGroup)
+		if de.Action == dnd.Exit {
+			dgg.SetDropSide(DockSidesN)
+		}
+	})
+	dg.ConnectEvent(oswin.MouseEvent, HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.Event)
+		dgg := recv.Embed(KiT_DockGroup).(*DockGroup)
+		if dgg.Area == nil || me.Button != mouse.Right || me.Action != mouse.Release {
+			return
+		}
+		name := dgg.TabAt(me.Pos())
+		if name == "" {
+			return
+		}
+		me.SetProcessed()
+		var men Menu
+		dgg.MakeTabMenu(name, &men)
+		PopupMenu(men, me.Pos().X, me.Pos().Y, dgg.ViewportSafe(), name)
+	})
+}
+
+// RenderDropRegion renders the region where a panel being dragged would be
+// docked
+func (dg *DockGroup) RenderDropRegion() {
+	if dg.DropSide >= DockSidesN {
+		return
+	}
+	rs, _, st := dg.RenderLock()
+	defer dg.RenderUnlock(rs)
+	bb := dg.DropRegion(dg.DropSide).Sub(dg.WinBBox.Min).Add(dg.VpBBox.Min)
+	clr := Prefs.Colors.Select
+	if clr.IsNil() {
+		clr = st.Font.Color
+	}
+	// blend over the panels, so they are still visible
+	draw.Draw(rs.Image, rs.Bounds.Intersect(bb), &image.Uniform{clr.Clearer(60)}, image.ZP, draw.Over)
+}
+
+func (dg *DockGroup) Render2D() {
+	if dg.FullReRenderIfNeeded() {
+		return
+	}
+	if dg.PushBounds() {
+		dg.This().(Node2D).ConnectEvents2D()
+		dg.RenderScrolls()
+		dg.Render2DChildren()
+		dg.RenderTabSeps()
+		dg.RenderDropRegion()
+		dg.PopBounds()
+	} else {
+		dg.DisconnectAllEvents(AllPris)
+	}
+}
+
+func (dg *DockGroup) ConnectEvents2D() {
+	dg.TabView.ConnectEvents2D()
+	dg.DockEvents()
+}
@@ -0,0 +1,366 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"io/ioutil"
+	"log"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/ime"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/oswin/stylus"
+	"github.com/goki/gi/oswin/touch"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// Event recording and playback supports end-to-end tests of GUI apps: the
+// input events of a window are recorded with Window.StartRecording while
+// using the app, and saved to a file, which a test then plays back with
+// Play against the app (e.g., running with the offscreen driver), checking
+// the resulting state of the widgets with the Assert* functions, e.g.:
+//
+// 	rec, err := gi.OpenEventRecording("testdata/edit.json")
+// 	...
+// 	if err := rec.Play(win, 0); err != nil {
+// 		t.Fatal(err)
+// 	}
+// 	gi.AssertField(t, win, "main/mvlay/name", "Txt", "hello")
+//
+// Positions are in window pixels, so the window is set to the recorded
+// size before playing, and the app must have the same layout as when it
+// was recorded.  Only the events of the given window are recorded, not
+// those of other windows, such as dialogs.
+
+// EventRecordTypes are the types of events that are recorded by an
+// EventRecording, by their oswin.EventType -- these are the input events
+// of the driver.  Events that are generated by the Window from them, e.g.,
+// mouse.FocusEvent and mouse.HoverEvent, are not recorded, and are
+// generated again when playing.
+var EventRecordTypes = map[oswin.EventType]reflect.Type{
+	oswin.MouseEvent:       reflect.TypeOf(mouse.Event{}),
+	oswin.MouseMoveEvent:   reflect.TypeOf(mouse.MoveEvent{}),
+	oswin.MouseDragEvent:   reflect.TypeOf(mouse.DragEvent{}),
+	oswin.MouseScrollEvent: reflect.TypeOf(mouse.ScrollEvent{}),
+	oswin.KeyEvent:         reflect.TypeOf(key.Event{}),
+	oswin.KeyChordEvent:    reflect.TypeOf(key.ChordEvent{}),
+	oswin.TouchEvent:       reflect.TypeOf(touch.Event{}),
+	oswin.MagnifyEvent:     reflect.TypeOf(touch.MagnifyEvent{}),
+	oswin.PanEvent:         reflect.TypeOf(touch.PanEvent{}),
+	oswin.LongPressEvent:   reflect.TypeOf(touch.LongPressEvent{}),
+	oswin.StylusEvent:      reflect.TypeOf(stylus.Event{}),
+	oswin.IMEEvent:         reflect.TypeOf(ime.Event{}),
+}
+
+// EventPlayTimeout is the maximum time that Play waits for an event to be
+// processed by the window, before giving up with an error
+var EventPlayTimeout = 10 * time.Second
+
+// RecordedEvent is an event in an EventRecording
+type RecordedEvent struct {
+	Time  time.Duration   `desc:"time of the event since the start of the recording"`
+	Type  oswin.EventType `desc:"type of the event, which determines the type of Event, as in EventRecordTypes"`
+	Event oswin.Event     `desc:"the event, with positions in window pixels"`
+}
+
+// recordedEventJSON is the JSON form of a RecordedEvent, with the Type as
+// a string, and the Event decoded according to it
+type recordedEventJSON struct {
+	Time  time.Duration
+	Type  string
+	Event json.RawMessage
+}
+
+// MarshalJSON encodes the event with its Type as a string
+func (re *RecordedEvent) MarshalJSON() ([]byte, error) {
+	ev, err := json.Marshal(re.Event)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&recordedEventJSON{Time: re.Time, Type: re.Type.String(), Event: ev})
+}
+
+// UnmarshalJSON decodes the event into the type in EventRecordTypes for
+// its Type
+func (re *RecordedEvent) UnmarshalJSON(b []byte) error {
+	rj := recordedEventJSON{}
+	if err := json.Unmarshal(b, &rj); err != nil {
+		return err
+	}
+	if err := re.Type.FromString(rj.Type); err != nil {
+		return err
+	}
+	typ, ok := EventRecordTypes[re.Type]
+	if !ok {
+		return fmt.Errorf("gi.RecordedEvent: events of type %v are not recorded", re.Type)
+	}
+	ev := reflect.New(typ)
+	if err := json.Unmarshal(rj.Event, ev.Interface()); err != nil {
+		return err
+	}
+	re.Time = rj.Time
+	re.Event = ev.Interface().(oswin.Event)
+	return nil
+}
+
+// copyEvent returns a new copy of given event, which must be a pointer
+func copyEvent(evi oswin.Event) oswin.Event {
+	ev := reflect.ValueOf(evi).Elem()
+	cp := reflect.New(ev.Type())
+	cp.Elem().Set(ev)
+	return cp.Interface().(oswin.Event)
+}
+
+// EventRecording is a recording of the input events of a window, which can
+// be saved to a file, and played back to a window for testing -- see
+// Window.StartRecording
+type EventRecording struct {
+	Size   image.Point      `desc:"size of the window in pixels when it was recorded"`
+	Events []*RecordedEvent `desc:"the events, in the order received by the window"`
+	start  time.Time
+	skip   map[oswin.Event]struct{}
+	mu     sync.Mutex
+}
+
+// Record adds given event to the recording, with the time since the start
+// of the recording, if it is of one of the EventRecordTypes -- a copy is
+// recorded, so it must be called before the event is processed.
+func (er *EventRecording) Record(evi oswin.Event) {
+	et := evi.Type()
+	if _, ok := EventRecordTypes[et]; !ok {
+		return
+	}
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	if _, skip := er.skip[evi]; skip {
+		delete(er.skip, evi)
+		return
+	}
+	er.Events = append(er.Events, &RecordedEvent{Time: time.Since(er.start), Type: et, Event: copyEvent(evi)})
+}
+
+// Skip marks given event as not to be recorded, for events that the window
+// sends to itself in response to other events, which are generated again
+// when playing, e.g., for Window.LongPressClick
+func (er *EventRecording) Skip(evi oswin.Event) {
+	er.mu.Lock()
+	if er.skip == nil {
+		er.skip = make(map[oswin.Event]struct{})
+	}
+	er.skip[evi] = struct{}{}
+	er.mu.Unlock()
+}
+
+// Save saves the recording to given file, in JSON format
+func (er *EventRecording) Save(filename string) error {
+	er.mu.Lock()
+	b, err := json.MarshalIndent(er, "", "  ")
+	er.mu.Unlock()
+	if err == nil {
+		err = ioutil.WriteFile(filename, b, 0644)
+	}
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// OpenEventRecording opens a recording saved with Save
+func OpenEventRecording(filename string) (*EventRecording, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	er := &EventRecording{}
+	if err := json.Unmarshal(b, er); err != nil {
+		err = fmt.Errorf("gi.OpenEventRecording: %v: %v", filename, err)
+		log.Println(err)
+		return nil, err
+	}
+	return er, nil
+}
+
+// eventPlaySync is the data of the custom event that Play sends after each
+// event, which closes the channel when the window processes it
+type eventPlaySync chan struct{}
+
+// Play plays back the recorded events to given window, after setting it to
+// the recorded size and raising it to get the focus.  Each event is sent
+// when the previous one has been processed by the window, so the results
+// are the same each time, independent of the speed of the app.  A speed of
+// 1 keeps the recorded timing of the events, e.g., for hover events
+// (tooltips), 2 plays twice as fast, and 0 sends each event as soon as the
+// previous one has been processed.  Returns when all the events have been
+// processed -- it must not be called in the event loop of the window, e.g.,
+// in a signal handler, but rather in a separate goroutine, e.g., that of a
+// test.
+func (er *EventRecording) Play(win *Window, speed float64) error {
+	if win.IsClosed() {
+		return fmt.Errorf("gi.EventRecording.Play: window %v is closed", win.Nm)
+	}
+	if er.Size != (image.Point{}) && win.OSWin.Size() != er.Size {
+		win.OSWin.SetPixSize(er.Size)
+	}
+	if !win.HasFlag(int(WinFlagGotFocus)) {
+		win.OSWin.Raise()
+	}
+	if err := er.playSync(win); err != nil {
+		return err
+	}
+	for i := 0; i < 100 && !win.HasFlag(int(WinFlagGotFocus)); i++ { // focus can take a while on some platforms
+		time.Sleep(10 * time.Millisecond)
+	}
+	er.mu.Lock()
+	evs := make([]*RecordedEvent, len(er.Events))
+	copy(evs, er.Events)
+	er.mu.Unlock()
+	start := time.Now()
+	for _, re := range evs {
+		if speed > 0 {
+			if wait := time.Duration(float64(re.Time)/speed) - time.Since(start); wait > 0 {
+				time.Sleep(wait)
+			}
+		}
+		evi := copyEvent(re.Event)
+		evi.Init()
+		win.OSWin.Send(evi)
+		if err := er.playSync(win); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// playSync waits for the window to process the events sent to it so far
+func (er *EventRecording) playSync(win *Window) error {
+	done := make(eventPlaySync)
+	win.SendCustomEvent(done)
+	select {
+	case <-done:
+		return nil
+	case <-time.After(EventPlayTimeout):
+		err := fmt.Errorf("gi.EventRecording.Play: window %v did not process events within %v", win.Nm, EventPlayTimeout)
+		log.Println(err)
+		return err
+	}
+}
+
+// StartRecording starts recording the input events of the window, in a new
+// EventRecording that is returned, and also by StopRecording -- it can be
+// called from any goroutine
+func (w *Window) StartRecording() *EventRecording {
+	er := &EventRecording{Size: w.OSWin.Size(), start: time.Now()}
+	w.RecMu.Lock()
+	w.Recording = er
+	w.RecMu.Unlock()
+	return er
+}
+
+// StopRecording stops recording the input events of the window, returning
+// the recording, or nil if it was not recording -- it can be called from
+// any goroutine
+func (w *Window) StopRecording() *EventRecording {
+	w.RecMu.Lock()
+	er := w.Recording
+	w.Recording = nil
+	w.RecMu.Unlock()
+	return er
+}
+
+// CurRecording returns the current recording of the input events of the
+// window, or nil if it is not recording, under the RecMu lock
+func (w *Window) CurRecording() *EventRecording {
+	w.RecMu.Lock()
+	defer w.RecMu.Unlock()
+	return w.Recording
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//    Assertions
+
+// TestingT is the interface of testing.T (and testing.B) used by the
+// Assert* functions for checking the state of widgets in tests, e.g.,
+// after playing an EventRecording -- they report failures with Errorf, so
+// the test continues, and return false.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// AssertNode returns the node at given path below given root (e.g., a
+// Window), as in FindPathUnique, reporting an error if there is none
+func AssertNode(t TestingT, root ki.Ki, path string) ki.Ki {
+	t.Helper()
+	k := root.FindPathUnique(path)
+	if k == nil {
+		t.Errorf("gi.AssertNode: no node at path %v in %v", path, root.Path())
+	}
+	return k
+}
+
+// AssertField checks that the field of given name of the node at given path
+// below given root has given value, comparing their string values, so the
+// names of enums can be used, e.g., "Checked" for a ButtonStates field
+func AssertField(t TestingT, root ki.Ki, path, field string, want interface{}) bool {
+	t.Helper()
+	k := AssertNode(t, root, path)
+	if k == nil {
+		return false
+	}
+	fv := kit.FlatFieldValueByName(k, field)
+	if !fv.IsValid() {
+		t.Errorf("gi.AssertField: %v has no field named %v", k.Path(), field)
+		return false
+	}
+	got := kit.ToString(fv.Interface())
+	if got != kit.ToString(want) {
+		t.Errorf("gi.AssertField: %v of %v is %q, want %q", field, k.Path(), got, kit.ToString(want))
+		return false
+	}
+	return true
+}
+
+// AssertFlag checks that given flag of the node at given path below given
+// root is set or not, e.g., Selected, or Inactive
+func AssertFlag(t TestingT, root ki.Ki, path string, flag NodeFlags, want bool) bool {
+	t.Helper()
+	k := AssertNode(t, root, path)
+	if k == nil {
+		return false
+	}
+	if got := k.HasFlag(int(flag)); got != want {
+		t.Errorf("gi.AssertFlag: %v flag of %v is %v, want %v", flag, k.Path(), got, want)
+		return false
+	}
+	return true
+}
+
+// AssertFocus checks that the node at given path below the window has the
+// keyboard focus
+func AssertFocus(t TestingT, win *Window, path string) bool {
+	t.Helper()
+	k := AssertNode(t, win, path)
+	if k == nil {
+		return false
+	}
+	if foc := win.EventMgr.CurFocus(); foc != k {
+		fnm := "nothing"
+		if foc != nil {
+			fnm = foc.Path()
+		}
+		t.Errorf("gi.AssertFocus: %v has the focus, want %v", fnm, k.Path())
+		return false
+	}
+	return true
+}
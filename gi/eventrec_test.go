@@ -0,0 +1,146 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi_test
+
+import (
+	"fmt"
+	"image"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin/driver/offscreen"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+)
+
+// recTestWidgets has the widgets of the window of TestEventRecording
+type recTestWidgets struct {
+	cb     *gi.CheckBox
+	bt     *gi.Button
+	tf     *gi.TextField
+	clicks int
+}
+
+// newRecTestWindow returns a new window with a checkbox, a button counting
+// its clicks, and a text field
+func newRecTestWindow(name string) (*gi.Window, *recTestWidgets) {
+	tw := &recTestWidgets{}
+	win := newTestWindow(name, func(mfr *gi.Frame) {
+		tw.cb = gi.AddNewCheckBox(mfr, "cb")
+		tw.cb.SetText("check")
+		tw.bt = gi.AddNewButton(mfr, "bt")
+		tw.bt.SetText("click")
+		tw.bt.ButtonSig.Connect(mfr.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig == int64(gi.ButtonClicked) {
+				tw.clicks++
+			}
+		})
+		tw.tf = gi.AddNewTextField(mfr, "tf")
+		tw.tf.SetMinPrefWidth(units.NewCh(20))
+	})
+	return win, tw
+}
+
+// center returns the center of the widget in window pixels
+func center(wi gi.Node2D) image.Point {
+	bb := wi.AsNode2D().WinBBox
+	return bb.Min.Add(bb.Size().Div(2))
+}
+
+// winPath returns the path of given node below the window, for the Assert
+// functions
+func winPath(win *gi.Window, k ki.Ki) string {
+	return strings.TrimPrefix(k.Path(), win.Path()+"/")
+}
+
+// recordingT records the errors reported by the Assert functions
+type recordingT struct {
+	errs []string
+}
+
+func (rt *recordingT) Helper() {}
+
+func (rt *recordingT) Errorf(format string, args ...interface{}) {
+	rt.errs = append(rt.errs, fmt.Sprintf(format, args...))
+}
+
+func TestEventRecording(t *testing.T) {
+	win, tw := newRecTestWindow("event-rec")
+	rec := win.StartRecording()
+	offscreen.MouseClick(win.OSWin, center(tw.cb), mouse.Left)
+	offscreen.MouseClick(win.OSWin, center(tw.bt), mouse.Left)
+	offscreen.MouseClick(win.OSWin, center(tw.tf), mouse.Left)
+	offscreen.TypeText(win.OSWin, "Hi")
+	offscreen.KeyChord(win.OSWin, "ReturnEnter")
+	waitIdle(win)
+	if got := win.StopRecording(); got != rec {
+		t.Errorf("StopRecording returned %p, not the recording %p", got, rec)
+	}
+	if !tw.cb.IsChecked() || tw.clicks != 1 || tw.tf.Txt != "Hi" {
+		t.Fatalf("recorded events were not processed: checked: %v, clicks: %d, text: %q", tw.cb.IsChecked(), tw.clicks, tw.tf.Txt)
+	}
+	win.Close()
+
+	dir, err := ioutil.TempDir("", "gi-event-rec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fnm := filepath.Join(dir, "rec.json")
+	if err := rec.Save(fnm); err != nil {
+		t.Fatal(err)
+	}
+	prec, err := gi.OpenEventRecording(fnm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prec.Events) != len(rec.Events) || prec.Size != rec.Size {
+		t.Fatalf("opened recording has %d events of size %v, saved %d of size %v", len(prec.Events), prec.Size, len(rec.Events), rec.Size)
+	}
+
+	pwin, ptw := newRecTestWindow("event-play")
+	defer pwin.Close()
+	if err := prec.Play(pwin, 0); err != nil {
+		t.Fatal(err)
+	}
+	waitIdle(pwin)
+	if ptw.clicks != 1 {
+		t.Errorf("button clicked %d times, want 1", ptw.clicks)
+	}
+	cbp, btp, tfp := winPath(pwin, ptw.cb), winPath(pwin, ptw.bt), winPath(pwin, ptw.tf)
+	if gi.AssertNode(t, pwin, tfp) != ptw.tf {
+		t.Errorf("AssertNode did not return the text field at %v", tfp)
+	}
+	gi.AssertField(t, pwin, tfp, "Txt", "Hi")
+	gi.AssertFlag(t, pwin, cbp, gi.NodeFlags(gi.ButtonFlagChecked), true)
+	gi.AssertFlag(t, pwin, btp, gi.NodeFlags(gi.ButtonFlagChecked), false)
+	gi.AssertFocus(t, pwin, cbp) // enter in the text field moves the focus on, to the first widget
+
+	// the assertions report failures
+	rt := &recordingT{}
+	if gi.AssertNode(rt, pwin, "no/such/node") != nil {
+		t.Errorf("AssertNode found a missing node")
+	}
+	if gi.AssertField(rt, pwin, tfp, "Txt", "Ho") {
+		t.Errorf("AssertField passed with the wrong value")
+	}
+	if gi.AssertField(rt, pwin, tfp, "NoField", "Hi") {
+		t.Errorf("AssertField passed with a missing field")
+	}
+	if gi.AssertFlag(rt, pwin, cbp, gi.NodeFlags(gi.ButtonFlagChecked), false) {
+		t.Errorf("AssertFlag passed with the wrong state")
+	}
+	if gi.AssertFocus(rt, pwin, btp) {
+		t.Errorf("AssertFocus passed for a node without the focus")
+	}
+	if len(rt.errs) != 5 {
+		t.Errorf("assertions reported %d errors, want 5: %q", len(rt.errs), rt.errs)
+	}
+}
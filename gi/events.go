@@ -79,6 +79,8 @@ type EventMgr struct {
 	LastMousePos    image.Point                             `desc:"Last mouse position from most recent Mouse events"`
 	LagSkipDeltaPos image.Point                             `desc:"change in position accumulated from skipped-over laggy mouse move events"`
 	LagLastSkipped  bool                                    `desc:"true if last event was skipped due to lag"`
+	TraceNode       ki.Ki                                   `desc:"node whose events are traced by TraceFunc, e.g., to log the routing of its events in the GoGi editor"`
+	TraceFunc       EventTraceFunc                          `desc:"if set, is called for each event that is sent to TraceNode, or that another node took before TraceNode got it"`
 	startDrag       *mouse.DragEvent
 	dragStarted     bool
 	startDND        *mouse.DragEvent
@@ -93,6 +95,12 @@ type EventMgr struct {
 	dndHoverTimer   *time.Timer
 }
 
+// EventTraceFunc is called for each event that is sent to the EventMgr
+// TraceNode, with the priority of its connection, and the node that
+// processed the event: TraceNode itself, another node at the same priority
+// that took the event before TraceNode got it, or nil if not processed
+type EventTraceFunc func(evi oswin.Event, pri EventPris, by ki.Ki)
+
 // WinEventRecv is used to hold info about widgets receiving event signals to
 // given function, used for sorting and delayed sending.
 type WinEventRecv struct {
@@ -214,7 +222,7 @@ func (em *EventMgr) SendEventSignal(evi oswin.Event, popup bool) {
 			return rvs[i].Data > rvs[j].Data
 		})
 
-		for _, rr := range rvs {
+		for i, rr := range rvs {
 			switch evi.(type) {
 			case *mouse.DragEvent:
 				if em.Dragging == nil {
@@ -224,7 +232,22 @@ func (em *EventMgr) SendEventSignal(evi oswin.Event, popup bool) {
 			em.EventMu.Unlock()
 			rr.Call(send, int64(et), evi) // could call further event loops..
 			em.EventMu.Lock()
+			if em.TraceFunc != nil && rr.Recv == em.TraceNode {
+				var by ki.Ki
+				if evi.IsProcessed() {
+					by = rr.Recv
+				}
+				em.traceEvent(evi, pri, by)
+			}
 			if pri != LowRawPri && evi.IsProcessed() { // someone took care of it
+				if em.TraceFunc != nil && rr.Recv != em.TraceNode {
+					for _, tr := range rvs[i+1:] {
+						if tr.Recv == em.TraceNode {
+							em.traceEvent(evi, pri, rr.Recv)
+							break
+						}
+					}
+				}
 				switch evi.(type) { // only grab events if processed
 				case *mouse.DragEvent:
 					if em.Dragging == nil {
@@ -250,6 +273,15 @@ func (em *EventMgr) SendEventSignal(evi oswin.Event, popup bool) {
 	em.EventMu.Unlock()
 }
 
+// traceEvent calls TraceFunc, outside of the EventMu lock, which is held
+// when called
+func (em *EventMgr) traceEvent(evi oswin.Event, pri EventPris, by ki.Ki) {
+	fun := em.TraceFunc
+	em.EventMu.Unlock()
+	fun(evi, pri, by)
+	em.EventMu.Lock()
+}
+
 // SendEventSignalFunc is the inner loop of the SendEventSignal -- needed to deal with
 // map iterator locking logic in a cleaner way.  Returns true to continue, false to break
 func (em *EventMgr) SendEventSignalFunc(evi oswin.Event, popup bool, rvs *WinEventRecvList, recv ki.Ki, fun ki.RecvFunc) bool {
@@ -348,6 +380,9 @@ func (em *EventMgr) SendSig(recv, sender ki.Ki, evi oswin.Event) {
 // MouseEvents processes mouse drag and move events
 func (em *EventMgr) MouseEvents(evi oswin.Event) {
 	et := evi.Type()
+	if et == oswin.StylusEvent { // comes along with emulated mouse events
+		return
+	}
 	if et == oswin.MouseDragEvent {
 		em.MouseDragEvents(evi)
 	} else if et != oswin.KeyEvent { // allow modifier keypress
@@ -376,6 +411,9 @@ func (em *EventMgr) MouseEvents(evi oswin.Event) {
 // MouseEventReset resets state for "catch" events (Dragging, Scrolling)
 func (em *EventMgr) MouseEventReset(evi oswin.Event) {
 	et := evi.Type()
+	if et == oswin.StylusEvent {
+		return
+	}
 	if em.Dragging != nil && et != oswin.MouseDragEvent {
 		em.Dragging.ClearFlag(int(NodeDragging))
 		em.Dragging = nil
@@ -0,0 +1,71 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"time"
+
+	"github.com/goki/ki/bitflag"
+	"github.com/goki/ki/kit"
+)
+
+// FileWatchOps are the kinds of changes to a watched file, as bit flags in
+// the Ops of a FileWatchEvent
+type FileWatchOps int32
+
+const (
+	// FileWatchCreate means the file was created
+	FileWatchCreate FileWatchOps = iota
+
+	// FileWatchWrite means the contents of the file were written
+	FileWatchWrite
+
+	// FileWatchRemove means the file was removed
+	FileWatchRemove
+
+	// FileWatchRename means the file was renamed, to another name
+	FileWatchRename
+
+	// FileWatchChmod means the permissions of the file were changed
+	FileWatchChmod
+
+	FileWatchOpsN
+)
+
+//go:generate stringer -type=FileWatchOps
+
+var KiT_FileWatchOps = kit.Enums.AddEnumAltLower(FileWatchOpsN, kit.BitFlag, nil, "FileWatch")
+
+func (ev FileWatchOps) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *FileWatchOps) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// FileWatchDelay is the time that the FileWatcher waits after the last
+// change to a file before delivering its events, so that the many changes
+// made when a file is saved are delivered together
+var FileWatchDelay = 250 * time.Millisecond
+
+// FileWatchEvent is a change to a watched file, or to a file in a watched
+// directory, made since the last event delivered for it
+type FileWatchEvent struct {
+	Path string `desc:"absolute path of the file that changed"`
+	Ops  int64  `desc:"bit flags of the FileWatchOps of the changes"`
+}
+
+// HasOp returns true if the event includes given kind of change
+func (ev *FileWatchEvent) HasOp(op FileWatchOps) bool {
+	return bitflag.Has(ev.Ops, int(op))
+}
+
+// FileWatchFunc is called with the events for a watched file or directory
+type FileWatchFunc func(evs []FileWatchEvent)
+
+// TheFileWatcher is the FileWatcher used by all windows
+var TheFileWatcher FileWatcher
+
+// fileWatchEvent is sent to a window to deliver events in its event loop
+type fileWatchEvent struct {
+	fun FileWatchFunc
+	evs []FileWatchEvent
+}
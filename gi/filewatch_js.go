@@ -0,0 +1,34 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build js
+
+package gi
+
+import "github.com/goki/ki/ki"
+
+// FileWatcher does not watch files on js, where there is no notification of
+// file changes, and the files are only changed by the app itself -- its
+// methods do nothing, so no events are ever delivered.  Use TheFileWatcher.
+type FileWatcher struct {
+}
+
+// Watch does nothing on js -- see FileWatcher
+func (fw *FileWatcher) Watch(path string, isDir bool, recv ki.Ki, fun FileWatchFunc) error {
+	return nil
+}
+
+// Unwatch does nothing on js -- see FileWatcher
+func (fw *FileWatcher) Unwatch(path string, recv ki.Ki) {
+}
+
+// UnwatchAll does nothing on js -- see FileWatcher
+func (fw *FileWatcher) UnwatchAll(recv ki.Ki) {
+}
+
+// IsWatching returns false on js, where nothing is watched -- see
+// FileWatcher
+func (fw *FileWatcher) IsWatching(path string, recv ki.Ki) bool {
+	return false
+}
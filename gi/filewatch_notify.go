@@ -0,0 +1,247 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !js
+
+package gi
+
+import (
+	"log"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/goki/ki/bitflag"
+	"github.com/goki/ki/ki"
+)
+
+// FileWatcher watches files and directories for changes made outside of the
+// app, e.g., by another editor or by a version control command, for the
+// views and buffers of the files, which are then updated or reloaded.  It
+// is cross-platform, using fsnotify, and watches the directory of each
+// watched file, so that files that are replaced when they are saved remain
+// watched.  Use TheFileWatcher.
+type FileWatcher struct {
+	watch *fsnotify.Watcher
+	dirs  map[string]int // number of subs watching each directory
+	subs  []*fileWatchSub
+	pend  map[string]int64 // ops of pending events, by path
+	timer *time.Timer
+	mu    sync.Mutex
+}
+
+// fileWatchSub is a subscription to the changes of a file or directory
+type fileWatchSub struct {
+	path string
+	dir  bool // path is a directory, whose files are watched too
+	recv ki.Ki
+	fun  FileWatchFunc
+}
+
+// fileWatchWindow returns the window in whose event loop the events for
+// given receiver are delivered: its ParentWindow, e.g., for Node2D nodes, if
+// it has that method -- nil if none
+func fileWatchWindow(recv ki.Ki) *Window {
+	if pw, ok := recv.(interface{ ParentWindow() *Window }); ok {
+		if win := pw.ParentWindow(); win != nil && !win.IsClosed() {
+			return win
+		}
+	}
+	return nil
+}
+
+// Watch starts watching the file, or the directory if isDir, at given path,
+// including the files directly within a directory, for given receiver,
+// replacing any function it already has for that path.  The function is
+// called with the changes after FileWatchDelay, in the event loop of the
+// ParentWindow of the receiver, if it has one, or else on the goroutine of
+// the watcher.  The receiver is unsubscribed when it is destroyed.
+func (fw *FileWatcher) Watch(path string, isDir bool, recv ki.Ki, fun FileWatchFunc) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	for _, sb := range fw.subs {
+		if sb.path == path && sb.recv == recv {
+			sb.fun = fun
+			return nil
+		}
+	}
+	if fw.watch == nil {
+		fw.watch, err = fsnotify.NewWatcher()
+		if err != nil {
+			log.Printf("gi.FileWatcher: could not start watching files: %v\n", err)
+			fw.watch = nil
+			return err
+		}
+		fw.dirs = make(map[string]int)
+		fw.pend = make(map[string]int64)
+		go fw.run(fw.watch)
+	}
+	dir := path
+	if !isDir {
+		dir = filepath.Dir(path)
+	}
+	if fw.dirs[dir] == 0 {
+		err = fw.watch.Add(dir)
+		if err != nil {
+			return err
+		}
+	}
+	fw.dirs[dir]++
+	fw.subs = append(fw.subs, &fileWatchSub{path: path, dir: isDir, recv: recv, fun: fun})
+	return nil
+}
+
+// Unwatch stops watching the file or directory at given path for given
+// receiver
+func (fw *FileWatcher) Unwatch(path string, recv ki.Ki) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return
+	}
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	for i := len(fw.subs) - 1; i >= 0; i-- {
+		sb := fw.subs[i]
+		if sb.path == path && sb.recv == recv {
+			fw.removeSub(i)
+		}
+	}
+}
+
+// UnwatchAll stops watching all the files and directories for given
+// receiver
+func (fw *FileWatcher) UnwatchAll(recv ki.Ki) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	for i := len(fw.subs) - 1; i >= 0; i-- {
+		if fw.subs[i].recv == recv {
+			fw.removeSub(i)
+		}
+	}
+}
+
+// IsWatching returns true if the file or directory at given path is being
+// watched for given receiver
+func (fw *FileWatcher) IsWatching(path string, recv ki.Ki) bool {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	for _, sb := range fw.subs {
+		if sb.path == path && sb.recv == recv {
+			return true
+		}
+	}
+	return false
+}
+
+// removeSub removes the subscription at given index, and stops watching its
+// directory if no others are -- must be called under mutex
+func (fw *FileWatcher) removeSub(i int) {
+	sb := fw.subs[i]
+	fw.subs = append(fw.subs[:i], fw.subs[i+1:]...)
+	dir := sb.path
+	if !sb.dir {
+		dir = filepath.Dir(sb.path)
+	}
+	fw.dirs[dir]--
+	if fw.dirs[dir] <= 0 {
+		delete(fw.dirs, dir)
+		fw.watch.Remove(dir) // error if already removed with the directory
+	}
+}
+
+// run receives the changes from given watcher, and delivers them after
+// FileWatchDelay -- runs on its own goroutine
+func (fw *FileWatcher) run(w *fsnotify.Watcher) {
+	for {
+		select {
+		case ev, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			fw.mu.Lock()
+			fw.pend[filepath.Clean(ev.Name)] |= fileWatchOps(ev.Op)
+			if fw.timer == nil {
+				fw.timer = time.AfterFunc(FileWatchDelay, fw.deliver)
+			} else {
+				fw.timer.Reset(FileWatchDelay)
+			}
+			fw.mu.Unlock()
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("gi.FileWatcher: %v\n", err)
+		}
+	}
+}
+
+// fileWatchOps returns the FileWatchOps bit flags of given fsnotify op
+func fileWatchOps(op fsnotify.Op) int64 {
+	var ops int64
+	if op&fsnotify.Create != 0 {
+		bitflag.Set(&ops, int(FileWatchCreate))
+	}
+	if op&fsnotify.Write != 0 {
+		bitflag.Set(&ops, int(FileWatchWrite))
+	}
+	if op&fsnotify.Remove != 0 {
+		bitflag.Set(&ops, int(FileWatchRemove))
+	}
+	if op&fsnotify.Rename != 0 {
+		bitflag.Set(&ops, int(FileWatchRename))
+	}
+	if op&fsnotify.Chmod != 0 {
+		bitflag.Set(&ops, int(FileWatchChmod))
+	}
+	return ops
+}
+
+// deliver delivers the pending events to the subscriptions that watch them
+func (fw *FileWatcher) deliver() {
+	fw.mu.Lock()
+	pend := fw.pend
+	fw.pend = make(map[string]int64)
+	paths := make([]string, 0, len(pend))
+	for path := range pend {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var fes []fileWatchEvent
+	var recvs []ki.Ki
+	for i := len(fw.subs) - 1; i >= 0; i-- {
+		sb := fw.subs[i]
+		if sb.recv.This() == nil { // destroyed
+			fw.removeSub(i)
+			continue
+		}
+		var evs []FileWatchEvent
+		for _, path := range paths {
+			if path == sb.path || (sb.dir && filepath.Dir(path) == sb.path) {
+				evs = append(evs, FileWatchEvent{Path: path, Ops: pend[path]})
+			}
+		}
+		if len(evs) > 0 {
+			fes = append(fes, fileWatchEvent{fun: sb.fun, evs: evs})
+			recvs = append(recvs, sb.recv)
+		}
+	}
+	fw.mu.Unlock()
+	for i, fe := range fes {
+		if win := fileWatchWindow(recvs[i]); win != nil {
+			win.SendCustomEvent(fe)
+		} else {
+			fe.fun(fe.evs)
+		}
+	}
+}
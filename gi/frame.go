@@ -96,7 +96,7 @@ func (fr *Frame) FrameStdRender() {
 		pc.FillStrokeClear(rs)
 	}
 
-	if fr.Lay == LayoutGrid && fr.Stripes != NoStripes {
+	if (fr.Lay == LayoutGrid || fr.Lay == LayoutGridTemplate) && fr.Stripes != NoStripes {
 		fr.RenderStripes()
 	}
 
@@ -0,0 +1,588 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/goki/gi/girl"
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+)
+
+// FramePhases are the phases of updating the widgets of a window for a
+// frame, for which the FrameProfiler records the time spent
+type FramePhases int32
+
+const (
+	// FrameStyle is styling the nodes, in Style2D
+	FrameStyle FramePhases = iota
+
+	// FrameSize is computing the sizes of the nodes, in Size2D
+	FrameSize
+
+	// FrameLayout is laying out the nodes, in Layout2D
+	FrameLayout
+
+	// FrameRender is rendering the nodes, in Render2D
+	FrameRender
+
+	// FramePublish is uploading the window texture and publishing it to
+	// the window -- this is only recorded for the frame as a whole, not for
+	// nodes or viewports
+	FramePublish
+
+	FramePhasesN
+)
+
+//go:generate stringer -type=FramePhases
+
+var KiT_FramePhases = kit.Enums.AddEnum(FramePhasesN, kit.NotBitFlag, nil)
+
+var (
+	// FrameBudget is the time budget of a frame, e.g., 16ms for 60 frames
+	// per second -- frames that take longer are highlighted in the HUD
+	FrameBudget = 16 * time.Millisecond
+
+	// FrameProfMax is the number of frames kept by a FrameProfiler
+	FrameProfMax = 120
+
+	// FrameHUDNodes is the number of the slowest nodes shown in the HUD
+	FrameHUDNodes = 5
+
+	// FrameHUDSpriteName is the name of the window sprite of the HUD
+	FrameHUDSpriteName = "gi.Window.FrameHUD"
+)
+
+// frameProfWins is the number of windows being profiled, to quickly skip
+// profiling when there are none
+var frameProfWins int32
+
+// NodeFrameStats are the times spent on a node in a frame
+type NodeFrameStats struct {
+	Node  ki.Ki                       `desc:"the node"`
+	Path  string                      `desc:"path of the node"`
+	Self  [FramePhasesN]time.Duration `desc:"time spent on the node itself in each phase, excluding its children"`
+	Total [FramePhasesN]time.Duration `desc:"time spent on the subtree of the node in each phase, including its children"`
+	Count [FramePhasesN]int           `desc:"number of times the node was processed in each phase -- more than one means redundant updates"`
+}
+
+// SelfTime returns the time spent on the node itself in all phases
+func (ns *NodeFrameStats) SelfTime() time.Duration {
+	var t time.Duration
+	for _, d := range ns.Self {
+		t += d
+	}
+	return t
+}
+
+// TotalTime returns the time spent on the subtree of the node in all phases
+func (ns *NodeFrameStats) TotalTime() time.Duration {
+	var t time.Duration
+	for _, d := range ns.Total {
+		t += d
+	}
+	return t
+}
+
+// VpFrameStats are the stats of a viewport in a frame
+type VpFrameStats struct {
+	Vp         *Viewport2D                 `desc:"the viewport"`
+	Path       string                      `desc:"path of the viewport"`
+	Phases     [FramePhasesN]time.Duration `desc:"time spent on the nodes of the viewport in each phase, excluding those of viewports within it"`
+	Nodes      [FramePhasesN]int           `desc:"number of nodes of the viewport processed in each phase"`
+	Allocs     uint64                      `desc:"number of heap allocations while updating the viewport, including viewports within it -- as this is measured for the program as a whole, it includes the allocations of other goroutines at the same time"`
+	AllocBytes uint64                      `desc:"number of bytes of heap allocations while updating the viewport, as for Allocs"`
+}
+
+// vpAllocs records the allocations of a viewport during an update, which
+// can span frames if the window is published during the update
+type vpAllocs struct {
+	depth    int
+	mallocs0 uint64
+	bytes0   uint64
+}
+
+// Time returns the time spent on the nodes of the viewport in all phases
+func (vs *VpFrameStats) Time() time.Duration {
+	var t time.Duration
+	for _, d := range vs.Phases {
+		t += d
+	}
+	return t
+}
+
+// FrameStats are the stats of a frame of a window, from the first update
+// after the previous publish until the end of the publish of the frame
+type FrameStats struct {
+	Start      time.Time                   `desc:"time when the first update of the frame started"`
+	Time       time.Duration               `desc:"time from the start of the first update of the frame to the end of publishing it"`
+	Phases     [FramePhasesN]time.Duration `desc:"time spent in each phase, for all viewports"`
+	Nodes      [FramePhasesN]int           `desc:"number of nodes processed in each phase, for all viewports"`
+	Allocs     uint64                      `desc:"number of heap allocations during the frame, by all goroutines"`
+	AllocBytes uint64                      `desc:"number of bytes of heap allocations during the frame, by all goroutines"`
+	Vps        []*VpFrameStats             `desc:"stats of each viewport that was updated, slowest first"`
+	NodeStats  []*NodeFrameStats           `desc:"stats of each node that was updated, with the slowest (by SelfTime) first"`
+	mallocs0   uint64
+	bytes0     uint64
+	vps        map[*Viewport2D]*VpFrameStats
+	nodes      map[ki.Ki]*NodeFrameStats
+}
+
+// TopNodes returns the given number of slowest nodes, by the time spent on
+// the node itself if self is true, or on its subtree otherwise
+func (fs *FrameStats) TopNodes(n int, self bool) []*NodeFrameStats {
+	nss := make([]*NodeFrameStats, len(fs.NodeStats))
+	copy(nss, fs.NodeStats)
+	if !self {
+		sort.SliceStable(nss, func(i, j int) bool {
+			return nss[i].TotalTime() > nss[j].TotalTime()
+		})
+	}
+	if n < len(nss) {
+		nss = nss[:n]
+	}
+	return nss
+}
+
+// frameMs returns given duration in milliseconds, for reports
+func frameMs(d time.Duration) string {
+	return fmt.Sprintf("%.2fms", float64(d)/float64(time.Millisecond))
+}
+
+// String returns a report of the stats, with the slowest viewports and
+// FrameHUDNodes nodes, as shown in the HUD
+func (fs *FrameStats) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "frame: %v  budget: %v  allocs: %v (%v KB)\n", frameMs(fs.Time), frameMs(FrameBudget), fs.Allocs, fs.AllocBytes/1024)
+	for ph := FrameStyle; ph < FramePhasesN; ph++ {
+		nm := strings.ToLower(strings.TrimPrefix(ph.String(), "Frame"))
+		fmt.Fprintf(&b, "%s: %v", nm, frameMs(fs.Phases[ph]))
+		if ph != FramePublish {
+			fmt.Fprintf(&b, " (%d)", fs.Nodes[ph])
+		}
+		if ph < FramePhasesN-1 {
+			b.WriteString("  ")
+		}
+	}
+	b.WriteString("\n")
+	for _, vs := range fs.Vps {
+		fmt.Fprintf(&b, "vp: %v  %v  %d nodes  allocs: %v (%v KB)\n", frameMs(vs.Time()), vs.Path, vs.Nodes[FrameRender], vs.Allocs, vs.AllocBytes/1024)
+	}
+	if len(fs.NodeStats) > 0 {
+		b.WriteString("slowest: self  subtree  node\n")
+	}
+	for _, ns := range fs.TopNodes(FrameHUDNodes, true) {
+		fmt.Fprintf(&b, "  %v  %v  %v\n", frameMs(ns.SelfTime()), frameMs(ns.TotalTime()), ns.Path)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// node returns the stats for given node, making them if needed -- must be
+// called under the FrameProfiler mutex
+func (fs *FrameStats) node(k ki.Ki) *NodeFrameStats {
+	ns, ok := fs.nodes[k]
+	if !ok {
+		ns = &NodeFrameStats{Node: k}
+		fs.nodes[k] = ns
+	}
+	return ns
+}
+
+// vp returns the stats for given viewport, making them if needed -- must
+// be called under the FrameProfiler mutex
+func (fs *FrameStats) vp(vp *Viewport2D) *VpFrameStats {
+	vs, ok := fs.vps[vp]
+	if !ok {
+		vs = &VpFrameStats{Vp: vp}
+		fs.vps[vp] = vs
+	}
+	return vs
+}
+
+// frameNodeVp returns the viewport of given node, which is the node itself
+// for a top-level viewport
+func frameNodeVp(k ki.Ki) *Viewport2D {
+	nii, ni := KiToNode2D(k)
+	if ni == nil {
+		return nil
+	}
+	if ni.Viewport != nil {
+		return ni.Viewport
+	}
+	return nii.AsViewport2D()
+}
+
+// finish computes the times of the nodes and viewports at the end of the
+// frame -- the times of nodes are measured either for the node itself
+// (style and size, which are done for each node in turn) or for its subtree
+// (layout and render, which are done recursively), and the others are
+// computed from them
+func (fs *FrameStats) finish() {
+	for _, ns := range fs.nodes {
+		for ph := FrameStyle; ph <= FrameSize; ph++ {
+			ns.Total[ph] += ns.Self[ph]
+			if ns.Self[ph] == 0 {
+				continue
+			}
+			for p := ns.Node.Parent(); p != nil; p = p.Parent() {
+				ps, ok := fs.nodes[p]
+				if !ok {
+					break
+				}
+				ps.Total[ph] += ns.Self[ph]
+			}
+		}
+	}
+	for _, ns := range fs.nodes {
+		for ph := FrameLayout; ph <= FrameRender; ph++ {
+			ns.Self[ph] += ns.Total[ph]
+			if ns.Total[ph] == 0 || ns.Node.Parent() == nil {
+				continue
+			}
+			if ps, ok := fs.nodes[ns.Node.Parent()]; ok {
+				ps.Self[ph] -= ns.Total[ph]
+			}
+		}
+	}
+	fs.NodeStats = make([]*NodeFrameStats, 0, len(fs.nodes))
+	for k, ns := range fs.nodes {
+		for ph := FrameLayout; ph <= FrameRender; ph++ {
+			if ns.Self[ph] < 0 { // child also processed separately from the parent
+				ns.Self[ph] = 0
+			}
+		}
+		if !k.IsDestroyed() {
+			ns.Path = k.Path()
+		}
+		vp := frameNodeVp(k)
+		if vp != nil {
+			vs := fs.vp(vp)
+			for ph := FrameStyle; ph < FramePublish; ph++ {
+				vs.Phases[ph] += ns.Self[ph]
+				fs.Phases[ph] += ns.Self[ph]
+				if ns.Count[ph] > 0 {
+					vs.Nodes[ph]++
+					fs.Nodes[ph]++
+				}
+			}
+		}
+		fs.NodeStats = append(fs.NodeStats, ns)
+	}
+	sort.Slice(fs.NodeStats, func(i, j int) bool {
+		return fs.NodeStats[i].SelfTime() > fs.NodeStats[j].SelfTime()
+	})
+	fs.Vps = make([]*VpFrameStats, 0, len(fs.vps))
+	for vp, vs := range fs.vps {
+		if !vp.IsDestroyed() {
+			vs.Path = vp.Path()
+		}
+		fs.Vps = append(fs.Vps, vs)
+	}
+	sort.Slice(fs.Vps, func(i, j int) bool {
+		return fs.Vps[i].Time() > fs.Vps[j].Time()
+	})
+	fs.nodes = nil
+	fs.vps = nil
+}
+
+// FrameProfiler records the stats of the frames of a window, for finding
+// which widgets take the most time to update -- see
+// Window.StartFrameProfile.  The times of the nodes are recorded by
+// Style2DTree, Size2DTree, Layout2DTree, Render2DTree, Layout2DChildren and
+// Render2DChildren, and widgets that process their children in other ways
+// can record them using FrameProf, Begin, EndSelf and EndTotal.
+type FrameProfiler struct {
+	Frames   []*FrameStats `desc:"the stats of the last FrameProfMax frames, oldest first"`
+	HUD      bool          `desc:"show the stats of the last frame in a HUD overlay in the window"`
+	FrameSig ki.Signal     `desc:"signal sent with the *FrameStats of each frame when it has been published, with the window as the sender"`
+	cur      *FrameStats
+	hudFrame *FrameStats
+	vpAllocs map[*Viewport2D]*vpAllocs
+	mu       sync.Mutex
+}
+
+// FrameProf returns the FrameProfiler of the window of the node if it is
+// being profiled, and nil otherwise -- the FrameProfiler methods can be
+// called on nil, and do nothing
+func (nb *Node2DBase) FrameProf() *FrameProfiler {
+	if atomic.LoadInt32(&frameProfWins) == 0 {
+		return nil
+	}
+	win := nb.ParentWindow()
+	if win == nil {
+		return nil
+	}
+	return win.FrameProf
+}
+
+// Begin returns the start time for EndSelf or EndTotal
+func (fp *FrameProfiler) Begin() time.Time {
+	if fp == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// curFrame returns the current frame, starting it if needed -- must be
+// called under the mutex
+func (fp *FrameProfiler) curFrame(st time.Time) *FrameStats {
+	if fp.cur == nil {
+		ms := runtime.MemStats{}
+		runtime.ReadMemStats(&ms)
+		fp.cur = &FrameStats{Start: st, mallocs0: ms.Mallocs, bytes0: ms.TotalAlloc, vps: make(map[*Viewport2D]*VpFrameStats), nodes: make(map[ki.Ki]*NodeFrameStats)}
+	}
+	return fp.cur
+}
+
+// EndSelf records the time since given Begin time as spent on the node
+// itself in given phase, e.g., for styling each node of a tree in turn
+func (fp *FrameProfiler) EndSelf(k ki.Ki, phase FramePhases, st time.Time) {
+	if fp == nil {
+		return
+	}
+	d := time.Since(st)
+	fp.mu.Lock()
+	ns := fp.curFrame(st).node(k)
+	ns.Self[phase] += d
+	ns.Count[phase]++
+	fp.mu.Unlock()
+}
+
+// EndTotal records the time since given Begin time as spent on the subtree
+// of the node in given phase, e.g., for rendering a node and its children
+func (fp *FrameProfiler) EndTotal(k ki.Ki, phase FramePhases, st time.Time) {
+	if fp == nil {
+		return
+	}
+	d := time.Since(st)
+	fp.mu.Lock()
+	ns := fp.curFrame(st).node(k)
+	ns.Total[phase] += d
+	ns.Count[phase]++
+	fp.mu.Unlock()
+}
+
+// VpBegin starts recording the allocations of given viewport while it is
+// updated, until VpEnd
+func (fp *FrameProfiler) VpBegin(vp *Viewport2D) {
+	if fp == nil {
+		return
+	}
+	fp.mu.Lock()
+	fp.curFrame(time.Now()).vp(vp)
+	if fp.vpAllocs == nil {
+		fp.vpAllocs = make(map[*Viewport2D]*vpAllocs)
+	}
+	va, ok := fp.vpAllocs[vp]
+	if !ok {
+		va = &vpAllocs{}
+		fp.vpAllocs[vp] = va
+	}
+	va.depth++
+	if va.depth == 1 {
+		ms := runtime.MemStats{}
+		runtime.ReadMemStats(&ms)
+		va.mallocs0, va.bytes0 = ms.Mallocs, ms.TotalAlloc
+	}
+	fp.mu.Unlock()
+}
+
+// VpEnd ends recording the allocations of given viewport, see VpBegin --
+// they are added to the current frame, which can be later than the one in
+// which the update started, if the window was published during the update
+func (fp *FrameProfiler) VpEnd(vp *Viewport2D) {
+	if fp == nil {
+		return
+	}
+	fp.mu.Lock()
+	if va, ok := fp.vpAllocs[vp]; ok {
+		va.depth--
+		if va.depth == 0 {
+			ms := runtime.MemStats{}
+			runtime.ReadMemStats(&ms)
+			vs := fp.curFrame(time.Now()).vp(vp)
+			vs.Allocs += ms.Mallocs - va.mallocs0
+			vs.AllocBytes += ms.TotalAlloc - va.bytes0
+			delete(fp.vpAllocs, vp)
+		}
+	}
+	fp.mu.Unlock()
+}
+
+// EndFrame ends the current frame, after publishing the window, which
+// started at given Begin time, and sends it to FrameSig
+func (fp *FrameProfiler) EndFrame(w *Window, pubSt time.Time) {
+	if fp == nil {
+		return
+	}
+	fp.mu.Lock()
+	fs := fp.curFrame(pubSt)
+	fp.cur = nil
+	ms := runtime.MemStats{}
+	runtime.ReadMemStats(&ms)
+	fs.Allocs = ms.Mallocs - fs.mallocs0
+	fs.AllocBytes = ms.TotalAlloc - fs.bytes0
+	fs.finish()
+	fs.Phases[FramePublish] = time.Since(pubSt)
+	fs.Time = time.Since(fs.Start)
+	fp.Frames = append(fp.Frames, fs)
+	if over := len(fp.Frames) - FrameProfMax; over > 0 {
+		fp.Frames = fp.Frames[over:]
+	}
+	fp.mu.Unlock()
+	fp.FrameSig.Emit(w.This(), 0, fs)
+}
+
+// LastFrame returns the stats of the last frame, or nil if none yet
+func (fp *FrameProfiler) LastFrame() *FrameStats {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+	if len(fp.Frames) == 0 {
+		return nil
+	}
+	return fp.Frames[len(fp.Frames)-1]
+}
+
+// MaxFrame returns the stats of the slowest of the frames, or nil if none
+func (fp *FrameProfiler) MaxFrame() *FrameStats {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+	var mf *FrameStats
+	for _, fs := range fp.Frames {
+		if mf == nil || fs.Time > mf.Time {
+			mf = fs
+		}
+	}
+	return mf
+}
+
+// AvgTime returns the average time of the frames, and the number of frames
+func (fp *FrameProfiler) AvgTime() (time.Duration, int) {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+	n := len(fp.Frames)
+	if n == 0 {
+		return 0, 0
+	}
+	var t time.Duration
+	for _, fs := range fp.Frames {
+		t += fs.Time
+	}
+	return t / time.Duration(n), n
+}
+
+// StartFrameProfile starts recording the stats of the frames of the
+// window, in the FrameProfiler that is returned, showing them in a HUD
+// overlay if hud is true.  Profiling adds a small overhead to the updates,
+// and reading the allocation stats briefly stops the program, so it should
+// only be used for development.
+func (w *Window) StartFrameProfile(hud bool) *FrameProfiler {
+	if w.FrameProf == nil {
+		w.FrameProf = &FrameProfiler{}
+		atomic.AddInt32(&frameProfWins, 1)
+	}
+	w.FrameProf.HUD = hud
+	return w.FrameProf
+}
+
+// StopFrameProfile stops recording the stats of the frames of the window,
+// and removes the HUD
+func (w *Window) StopFrameProfile() {
+	if w.FrameProf == nil {
+		return
+	}
+	w.FrameProf = nil
+	atomic.AddInt32(&frameProfWins, -1)
+	if w.DeleteSprite(FrameHUDSpriteName) {
+		w.RenderOverlays()
+	}
+}
+
+// ToggleFrameProfileHUD starts profiling the frames of the window with the
+// HUD, or stops it -- this is done with Control+Alt+T
+func (w *Window) ToggleFrameProfileHUD() {
+	if w.FrameProf != nil {
+		w.StopFrameProfile()
+	} else {
+		w.StartFrameProfile(true)
+		w.Viewport.SetNeedsFullRender()
+	}
+}
+
+// RenderFrameHUD renders the stats of the last frame in the HUD sprite at
+// the top right of the window -- called at the start of Publish, so it is
+// published along with the frame
+func (w *Window) RenderFrameHUD() {
+	fp := w.FrameProf
+	fs := fp.LastFrame()
+	if fs == nil || fs == fp.hudFrame {
+		return
+	}
+	fp.hudFrame = fs
+	avg, n := fp.AvgTime()
+	lines := []string{fmt.Sprintf("last %d frames: avg: %v  max: %v", n, frameMs(avg), frameMs(fp.MaxFrame().Time))}
+	lines = append(lines, strings.Split(fs.String(), "\n")...)
+
+	fsty := gist.Font{}
+	fsty.Defaults()
+	fsty.Family = string(Prefs.MonoFont)
+	fsty.Size = units.NewPt(9)
+	fsty.Color.SetColor(color.RGBA{0xe0, 0xe0, 0xe0, 0xff})
+	ctxt := w.Viewport.Sty.UnContext
+	girl.OpenFont(&fsty, &ctxt)
+	tsty := gist.Text{}
+	tsty.Defaults()
+	trs := make([]girl.Text, len(lines))
+	var sz mat32.Vec2
+	for i, ln := range lines {
+		trs[i].SetString(ln, &fsty, &ctxt, &tsty, true, 0, 1)
+		sz.X = mat32.Max(sz.X, trs[i].Size.X)
+		sz.Y += trs[i].Size.Y
+	}
+	pad := float32(4)
+	isz := sz.AddScalar(2 * pad).ToPointCeil()
+	wsz := w.OSWin.Size()
+	pos := image.Point{X: wsz.X - isz.X, Y: 0}
+	if pos.X < 0 {
+		pos.X = 0
+	}
+
+	w.DeleteSprite(FrameHUDSpriteName)
+	sp := w.AddNewSprite(FrameHUDSpriteName, isz, pos)
+	bg := color.RGBA{0, 0, 0, 0xc0}
+	if fs.Time > FrameBudget {
+		bg = color.RGBA{0x80, 0, 0, 0xc0} // premultiplied
+	}
+	draw.Draw(sp.Pixels, sp.Pixels.Bounds(), &image.Uniform{bg}, image.ZP, draw.Src)
+	rs := &girl.State{}
+	rs.Init(isz.X, isz.Y, sp.Pixels)
+	rs.PushBounds(sp.Pixels.Bounds())
+	rs.Lock()
+	y := pad
+	for i := range trs {
+		trs[i].RenderTopPos(rs, mat32.Vec2{pad, y})
+		y += trs[i].Size.Y
+	}
+	rs.Unlock()
+	rs.PopBounds()
+	w.ActivateSprite(FrameHUDSpriteName)
+
+	updt := w.UpdateStart() // the overlays are published with the frame
+	w.RenderOverlays()
+	w.UpdateEndNoSig(updt)
+}
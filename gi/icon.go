@@ -167,6 +167,17 @@ type IconMgr interface {
 	// IconList returns the list of available icon names, optionally sorted
 	// alphabetically (otherwise in map-random order)
 	IconList(alphaSort bool) []IconName
+
+	// OpenIconPack opens a pack of .svg icons from the given directory, e.g.,
+	// Material or FontAwesome icons, whose icons are then available with the
+	// name of the pack, e.g., fa:github -- if recolor, their colors are
+	// removed, so that they are drawn in the colors of the theme
+	OpenIconPack(name, path string, recolor bool) error
+
+	// OpenIconPackFromAssetDir opens a pack of .svg icons from embedded assets,
+	// using Asset and AssetDir funcs as generated by e.g., go-bindata -- see
+	// OpenIconPack
+	OpenIconPackFromAssetDir(name, path string, recolor bool, dirFunc func(path string) ([]string, error), assetFunc func(name string) ([]byte, error)) error
 }
 
 // TheIconMgr is set by loading the gi/svg package -- all final users must
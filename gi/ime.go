@@ -0,0 +1,95 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"image"
+
+	"github.com/goki/gi/girl"
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/oswin/ime"
+	"github.com/goki/mat32"
+)
+
+// IMEPreedit is the state of input method (IME) composition in a text
+// editing widget (e.g., for CJK input): the composing (preedit) text is
+// displayed at the text cursor, underlined, until it is committed and
+// inserted into the edited text -- see oswin/ime.
+type IMEPreedit struct {
+	Text   []rune    `desc:"current composing text, which is not yet part of the edited text"`
+	Cursor int       `desc:"position of the cursor within the composing Text, in runes"`
+	Render girl.Text `desc:"render of the composing text"`
+}
+
+// IsActive returns true if there is composing text
+func (ip *IMEPreedit) IsActive() bool {
+	return len(ip.Text) > 0
+}
+
+// SetText sets the composing text and cursor position within it, from an
+// ime.Preedit event -- empty text ends the composition
+func (ip *IMEPreedit) SetText(txt string, cursor int) {
+	ip.Text = []rune(txt)
+	if cursor < 0 || cursor > len(ip.Text) {
+		cursor = len(ip.Text)
+	}
+	ip.Cursor = cursor
+	ip.Render.Spans = nil // needs Layout
+}
+
+// Clear ends the composition
+func (ip *IMEPreedit) Clear() {
+	ip.SetText("", 0)
+}
+
+// Layout lays out the composing text in given style, with an underline --
+// must be called before CursorOff and RenderAt
+func (ip *IMEPreedit) Layout(sty *gist.Style) {
+	if !ip.IsActive() {
+		return
+	}
+	fs := sty.Font
+	fs.SetDeco(gist.DecoUnderline)
+	ip.Render.SetRunes(ip.Text, &fs, &sty.UnContext, &sty.Text, true, 0, 0)
+}
+
+// CursorOff returns the horizontal offset of the cursor within the
+// composing text, from its start
+func (ip *IMEPreedit) CursorOff() float32 {
+	if !ip.IsActive() || len(ip.Render.Spans) == 0 {
+		return 0
+	}
+	pos, _, _, _ := ip.Render.CaretRelPos(ip.Cursor)
+	return pos.X
+}
+
+// RenderAt renders the composing text at given upper-left position, over a
+// box filled with the background color of given style, of given height
+func (ip *IMEPreedit) RenderAt(rs *girl.State, pos mat32.Vec2, sty *gist.Style, ht float32) {
+	if !ip.IsActive() || len(ip.Render.Spans) == 0 {
+		return
+	}
+	pc := &rs.Paint
+	pc.FillBox(rs, pos, mat32.NewVec2(ip.Render.Size.X, ht), &sty.Font.BgColor)
+	ip.Render.RenderTopPos(rs, pos)
+}
+
+// IMEUpdate tells the input method of given window, if its driver supports
+// it (ime.Window), whether text input is active, and if so, the bounding
+// box of the text cursor, in window pixels, for positioning the IME
+// composition and candidate windows
+func IMEUpdate(win *Window, active bool, caret image.Rectangle) {
+	if win == nil || win.OSWin == nil {
+		return
+	}
+	iw, ok := win.OSWin.(ime.Window)
+	if !ok {
+		return
+	}
+	iw.SetIMEActive(active)
+	if active {
+		iw.SetIMECaret(caret)
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/oswin"
+)
+
+// SingleInstanceFileName is the base name of the socket in the app
+// preferences directory that the first instance of a single-instance app
+// listens on -- see SingleInstance
+var SingleInstanceFileName = "instance.sock"
+
+// SingleInstanceLockMSec is how long an instance waits for the lock file
+// held by another one that is starting at the same time -- see
+// SingleInstance
+var SingleInstanceLockMSec = 10000
+
+// SingleInstanceWaitMSec is how long the first instance waits for a main
+// window to be opened, for files forwarded to it by another instance that
+// was started before it had one
+var SingleInstanceWaitMSec = 10000
+
+// instanceMsg is the message sent by a later instance to the first one
+type instanceMsg struct {
+	Files []string `desc:"absolute paths of the files to open"`
+}
+
+// instanceEvent is the data of the custom event that opens the forwarded
+// files in the window event loop
+type instanceEvent struct {
+	files []string
+}
+
+var (
+	// instanceMu protects the instance state
+	instanceMu sync.Mutex
+
+	// instanceLn is the listener of the first instance, nil if not listening
+	instanceLn net.Listener
+
+	// instanceOpen is the function that opens the forwarded files
+	instanceOpen func(win *Window, files []string)
+)
+
+// SingleInstancePath returns the path of the socket that the first instance
+// of the app listens on -- see SingleInstance
+func SingleInstancePath() string {
+	return filepath.Join(oswin.TheApp.AppPrefsDir(), SingleInstanceFileName)
+}
+
+// SingleInstance makes the app run as a single instance, as is standard for
+// document-based editors: if another instance of the app is already
+// running for the user, given files (typically the file arguments on the
+// command line) are forwarded to it, and true is returned, in which case
+// the app should just exit.  Otherwise, this becomes the first instance,
+// which listens for files from later ones, and false is returned -- when
+// they arrive, the most recently focused main window is raised, and open is
+// called in its event loop with them, to open them (e.g., in new windows,
+// or tabs of the given one).  Relative paths are made absolute before they
+// are forwarded, as the working directory of the other instance may differ.
+//
+// Must be called after SetAppName, as the instances find each other by a
+// (unix domain) socket in the app preferences directory (see
+// SingleInstancePath), and before opening any windows.  The socket is
+// closed when the last main window is closed, or by StopSingleInstance.
+// Instances that start at the same time are serialized by a lock file next
+// to the socket, held while connecting to the first instance or becoming
+// it, so only one of them can become the first instance.
+//
+// On Windows, unix domain sockets need Windows 10 version 1803 or later --
+// on earlier versions, the socket cannot be created, and each instance runs
+// separately (as if SingleInstance were not called).  On js, each page
+// always runs separately.
+func SingleInstance(files []string, open func(win *Window, files []string)) bool {
+	path := SingleInstancePath()
+	abs := make([]string, len(files))
+	for i, fn := range files {
+		if afn, err := filepath.Abs(fn); err == nil {
+			fn = afn
+		}
+		abs[i] = fn
+	}
+	unlock, err := lockInstance(path)
+	if err != nil {
+		log.Printf("gi.SingleInstance: could not lock: %v, running as separate instance\n", err)
+		return false
+	}
+	defer unlock()
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err == nil {
+		if sendInstanceFiles(conn, abs) {
+			return true
+		}
+		log.Printf("gi.SingleInstance: running instance did not accept files, running as separate instance\n")
+		return false
+	}
+	if instanceRefused(err) {
+		// no instance is listening: the socket is left over from one that
+		// ended without closing it -- with the lock held, no other instance
+		// can be listening on it or starting to
+		os.Remove(path)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		log.Printf("gi.SingleInstance: could not listen on: %v: %v, running as separate instance\n", path, err)
+		return false
+	}
+	instanceMu.Lock()
+	instanceLn = ln
+	instanceOpen = open
+	instanceMu.Unlock()
+	go listenInstance(ln)
+	return false
+}
+
+// errInstanceLocked is returned by tryLockInstance when the lock file is
+// held by another instance
+var errInstanceLocked = errors.New("lock file held by another instance")
+
+// lockInstance locks the lock file of the socket at given path, waiting up
+// to SingleInstanceLockMSec for another instance holding it, and returns
+// the function to unlock it.  The lock is held by the open file, so it is
+// released by the system if the instance ends without unlocking it.
+func lockInstance(path string) (func(), error) {
+	lck := path + ".lock"
+	wait := time.Duration(SingleInstanceLockMSec) * time.Millisecond
+	for st := time.Now(); ; {
+		unlock, err := tryLockInstance(lck)
+		if err != errInstanceLocked || time.Since(st) > wait {
+			return unlock, err
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// StopSingleInstance stops listening for files from other instances, so
+// that the next instance to start becomes the first one
+func StopSingleInstance() {
+	instanceMu.Lock()
+	ln := instanceLn
+	instanceLn = nil
+	instanceMu.Unlock()
+	if ln != nil {
+		ln.Close() // also removes the socket
+	}
+}
+
+// IsSingleInstance returns true if this is the first instance of a
+// single-instance app, listening for files from other instances
+func IsSingleInstance() bool {
+	instanceMu.Lock()
+	defer instanceMu.Unlock()
+	return instanceLn != nil
+}
+
+// sendInstanceFiles sends given files to the instance on the other end of
+// given connection, returning false if that fails
+func sendInstanceFiles(conn net.Conn, files []string) bool {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if err := json.NewEncoder(conn).Encode(instanceMsg{Files: files}); err != nil {
+		log.Printf("gi.SingleInstance: error sending files to running instance: %v\n", err)
+		return false
+	}
+	// wait for the first instance to close the connection, so the files
+	// are received before this one exits
+	var b [1]byte
+	conn.Read(b[:])
+	return true
+}
+
+// listenInstance accepts connections from later instances on given
+// listener, until it is closed
+func listenInstance(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go recvInstanceFiles(conn)
+	}
+}
+
+// recvInstanceFiles receives the files sent on given connection, and sends
+// them to the event loop of the window to open them in
+func recvInstanceFiles(conn net.Conn) {
+	var msg instanceMsg
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	err := json.NewDecoder(conn).Decode(&msg)
+	conn.Close()
+	if err != nil {
+		log.Printf("gi.SingleInstance: error receiving files from other instance: %v\n", err)
+		return
+	}
+	wait := time.Duration(SingleInstanceWaitMSec) * time.Millisecond
+	for st := time.Now(); ; {
+		if win := instanceWindow(); win != nil {
+			win.SendCustomEvent(instanceEvent{files: msg.Files})
+			return
+		}
+		if time.Since(st) > wait {
+			log.Printf("gi.SingleInstance: no window to open files from other instance in: %v\n", msg.Files)
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// instanceWindow returns the most recently focused main window, which
+// opens the files from other instances, or nil if there is none
+func instanceWindow() *Window {
+	WindowGlobalMu.Lock()
+	fws := make([]string, len(FocusWindows))
+	copy(fws, FocusWindows)
+	WindowGlobalMu.Unlock()
+	for _, fw := range fws {
+		if win, ok := MainWindows.FindName(fw); ok && !win.IsClosed() {
+			return win
+		}
+	}
+	if MainWindows.Len() > 0 {
+		if win := MainWindows.Win(0); win != nil && !win.IsClosed() {
+			return win
+		}
+	}
+	return nil
+}
+
+// process raises given window and opens the files in it, in its event loop
+func (ie *instanceEvent) process(win *Window) {
+	win.OSWin.Raise()
+	instanceMu.Lock()
+	open := instanceOpen
+	instanceMu.Unlock()
+	if open != nil && len(ie.files) > 0 {
+		open(win, ie.files)
+	}
+}
+
+// instanceWindowClosed stops listening for other instances when the last
+// main window is closed
+func instanceWindowClosed(win *Window) {
+	if !IsSingleInstance() {
+		return
+	}
+	if _, ok := MainWindows.FindName(win.Nm); !ok {
+		return
+	}
+	if oswin.TheApp.IsQuitting() || MainWindows.Len() <= 1 {
+		StopSingleInstance()
+	}
+}
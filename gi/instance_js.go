@@ -0,0 +1,20 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build js
+
+package gi
+
+import "errors"
+
+// tryLockInstance returns an error on js, where there are no unix domain
+// sockets for the instances to find each other, so each runs separately
+func tryLockInstance(lck string) (func(), error) {
+	return nil, errors.New("single instance mode is not supported on js")
+}
+
+// instanceRefused returns false on js -- see tryLockInstance
+func instanceRefused(err error) bool {
+	return false
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows,!js
+
+package gi
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// tryLockInstance takes an exclusive flock on given lock file, returning
+// errInstanceLocked if another instance holds it -- the file is left in
+// place, as removing it would race with another instance opening it
+func tryLockInstance(lck string) (func(), error) {
+	f, err := os.OpenFile(lck, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if err == syscall.EWOULDBLOCK {
+			return nil, errInstanceLocked
+		}
+		return nil, err
+	}
+	return func() { f.Close() }, nil
+}
+
+// instanceRefused returns true if given dial error means that nothing is
+// listening on the socket, which is thus stale
+func instanceRefused(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package gi
+
+import (
+	"errors"
+	"syscall"
+)
+
+const (
+	errSharingViolation = syscall.Errno(32)    // ERROR_SHARING_VIOLATION
+	errWSAConnRefused   = syscall.Errno(10061) // WSAECONNREFUSED
+)
+
+// tryLockInstance opens given lock file without sharing, returning
+// errInstanceLocked if another instance has it open
+func tryLockInstance(lck string) (func(), error) {
+	p, err := syscall.UTF16PtrFromString(lck)
+	if err != nil {
+		return nil, err
+	}
+	h, err := syscall.CreateFile(p, syscall.GENERIC_READ|syscall.GENERIC_WRITE, 0, nil, syscall.OPEN_ALWAYS, syscall.FILE_ATTRIBUTE_NORMAL, 0)
+	if err != nil {
+		if err == errSharingViolation {
+			return nil, errInstanceLocked
+		}
+		return nil, err
+	}
+	return func() { syscall.CloseHandle(h) }, nil
+}
+
+// instanceRefused returns true if given dial error means that nothing is
+// listening on the socket, which is thus stale
+func instanceRefused(err error) bool {
+	return errors.Is(err, errWSAConnRefused) || errors.Is(err, syscall.ECONNREFUSED)
+}
@@ -5,6 +5,7 @@
 package gi
 
 import (
+	"image"
 	"reflect"
 
 	"github.com/goki/gi/oswin/dnd"
@@ -120,3 +121,14 @@ type DragNDropper interface {
 	// will be nil.
 	DropExternal(md mimedata.Mimes, mod dnd.DropMods)
 }
+
+// FileDropper is the interface for widgets that accept files dropped onto
+// them from the OS (e.g., Finder / Explorer) -- the Window calls DropFiles
+// on the deepest visible widget under the drop position that implements it,
+// if the drop event was not otherwise processed
+type FileDropper interface {
+	// DropFiles is called with the full paths of the dropped files, the
+	// drop position in window coordinates, and the modifier action
+	// (dnd.DropCopy by default) -- returns true if the files were accepted
+	DropFiles(files []string, pos image.Point, mod dnd.DropMods) bool
+}
@@ -0,0 +1,420 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// KeyCmd is a command of the app that is triggered by a key chord: an
+// action with a shortcut, in a menu or toolbar, which is registered in
+// AppKeyCmds when its shortcut is added to a window.  The user can remap the
+// chord, including to a multi-key sequence of chords separated by spaces,
+// e.g., "Control+X Control+S".
+type KeyCmd struct {
+	Name  string    `inactive:"+" width:"20" desc:"name of the command -- the label of its action"`
+	Chord key.Chord `desc:"key chord set by the user, overriding the standard one -- type the chord, or several in quick succession for a multi-key sequence -- empty to use the standard one"`
+	Std   key.Chord `inactive:"+" desc:"standard key chord, as set by the app"`
+	Desc  string    `inactive:"+" desc:"description of the command"`
+}
+
+// Active returns the key chord that triggers the command: the one set by the
+// user, or else the standard one
+func (kc *KeyCmd) Active() key.Chord {
+	if kc.Chord != "" {
+		return kc.Chord
+	}
+	return kc.Std
+}
+
+// KeyCmds is a registry of the commands of the app that are triggered by key
+// chords -- see AppKeyCmds
+type KeyCmds []*KeyCmd
+
+var KiT_KeyCmds = kit.Types.AddType(&KeyCmds{}, KeyCmdsProps)
+
+// AppKeyCmds is the registry of the commands of this app that are triggered
+// by key chords -- the chords set by the user are saved in the app
+// preferences directory, along with AppKeyMap, and loaded at startup
+var AppKeyCmds KeyCmds
+
+// AppKeyMap overrides the ActiveKeyMap for this app: a chord (or multi-key
+// sequence) in this map triggers the given key function instead, or none if
+// it is KeyFunNil -- saved with AppKeyCmds
+var AppKeyMap KeyMap
+
+// AppKeyCmdsChanged is used to update giv.KeyCmdsView toolbars via
+// following menu, toolbar props update methods
+var AppKeyCmdsChanged = false
+
+// PrefsKeyCmdsFileName is the name of the preferences file in the app prefs
+// directory for saving / loading the AppKeyCmds chords and AppKeyMap
+var PrefsKeyCmdsFileName = "key_cmds_prefs.json"
+
+// CmdByName returns the command of given name and its index, or nil, -1 if
+// not found
+func (kc *KeyCmds) CmdByName(name string) (*KeyCmd, int) {
+	for i, cmd := range *kc {
+		if cmd.Name == name {
+			return cmd, i
+		}
+	}
+	return nil, -1
+}
+
+// Register registers the command of given name, with its description and
+// standard key chord, and returns the chord that triggers it, which is the
+// one set by the user, if any -- called by Window.AddShortcut
+func (kc *KeyCmds) Register(name, desc string, std key.Chord) key.Chord {
+	cmd, _ := kc.CmdByName(name)
+	if cmd == nil {
+		cmd = &KeyCmd{Name: name}
+		*kc = append(*kc, cmd)
+	}
+	if cmd.Chord == "" || std != cmd.Chord { // not the user chord, re-added
+		cmd.Std = std
+	}
+	if desc != "" {
+		cmd.Desc = desc
+	}
+	return cmd.Active()
+}
+
+// SetChord sets the key chord of the command of given name, overriding the
+// standard one -- returns false if not found
+func (kc *KeyCmds) SetChord(name string, chord key.Chord) bool {
+	cmd, _ := kc.CmdByName(name)
+	if cmd == nil {
+		return false
+	}
+	cmd.Chord = chord
+	AppKeyCmdsChanged = true
+	return true
+}
+
+// RevertToStd reverts all the commands to their standard key chords, and
+// clears the AppKeyMap overrides
+func (kc *KeyCmds) RevertToStd() {
+	for _, cmd := range *kc {
+		cmd.Chord = ""
+	}
+	AppKeyMap = nil
+	AppKeyCmdsChanged = true
+}
+
+// keyCmdsPrefs is the format of the file that AppKeyCmds are saved to
+type keyCmdsPrefs struct {
+	Chords map[string]key.Chord
+	KeyMap KeyMap
+}
+
+// OpenJSON opens the key chords of the commands set by the user, and the
+// AppKeyMap, from a JSON-formatted file -- commands that are not registered
+// yet get their chords when they are
+func (kc *KeyCmds) OpenJSON(filename FileName) error {
+	b, err := ioutil.ReadFile(string(filename))
+	if err != nil {
+		return err
+	}
+	var kp keyCmdsPrefs
+	if err = json.Unmarshal(b, &kp); err != nil {
+		log.Println(err)
+		return err
+	}
+	for _, cmd := range *kc {
+		cmd.Chord = ""
+	}
+	names := make([]string, 0, len(kp.Chords))
+	for nm := range kp.Chords {
+		names = append(names, nm)
+	}
+	sort.Strings(names)
+	for _, nm := range names {
+		cmd, _ := kc.CmdByName(nm)
+		if cmd == nil {
+			cmd = &KeyCmd{Name: nm}
+			*kc = append(*kc, cmd)
+		}
+		cmd.Chord = kp.Chords[nm]
+	}
+	AppKeyMap = kp.KeyMap
+	return nil
+}
+
+// SaveJSON saves the key chords of the commands set by the user, and the
+// AppKeyMap, to a JSON-formatted file
+func (kc *KeyCmds) SaveJSON(filename FileName) error {
+	kp := keyCmdsPrefs{Chords: make(map[string]key.Chord), KeyMap: AppKeyMap}
+	for _, cmd := range *kc {
+		if cmd.Chord != "" {
+			kp.Chords[cmd.Name] = cmd.Chord
+		}
+	}
+	b, err := json.MarshalIndent(kp, "", "  ")
+	if err != nil {
+		log.Println(err) // unlikely
+		return err
+	}
+	err = ioutil.WriteFile(string(filename), b, 0644)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// OpenPrefs opens the commands from the app prefs directory, using
+// PrefsKeyCmdsFileName
+func (kc *KeyCmds) OpenPrefs() error {
+	pdir := oswin.TheApp.AppPrefsDir()
+	pnm := filepath.Join(pdir, PrefsKeyCmdsFileName)
+	AppKeyCmdsChanged = false
+	return kc.OpenJSON(FileName(pnm))
+}
+
+// SavePrefs saves the commands to the app prefs directory, using
+// PrefsKeyCmdsFileName, and updates the shortcuts of the open windows to
+// the new chords
+func (kc *KeyCmds) SavePrefs() error {
+	pdir := oswin.TheApp.AppPrefsDir()
+	pnm := filepath.Join(pdir, PrefsKeyCmdsFileName)
+	AppKeyCmdsChanged = false
+	kc.UpdateWindows()
+	return kc.SaveJSON(FileName(pnm))
+}
+
+// UpdateWindows updates the shortcuts of all the open windows to the current
+// chords of the commands
+func (kc *KeyCmds) UpdateWindows() {
+	for i := 0; i < AllWindows.Len(); i++ {
+		win := AllWindows.Win(i)
+		if win == nil || len(win.Shortcuts) == 0 {
+			continue
+		}
+		acts := make([]*Action, 0, len(win.Shortcuts))
+		for ch, act := range win.Shortcuts {
+			acts = append(acts, act)
+			delete(win.Shortcuts, ch)
+		}
+		for _, act := range acts {
+			if cmd, _ := kc.CmdByName(act.KeyCmdName()); cmd != nil {
+				win.AddShortcut(cmd.Active(), act)
+			}
+		}
+	}
+}
+
+// KeyCmdName returns the name of the action as a command in AppKeyCmds: its
+// label, or else its name
+func (ac *Action) KeyCmdName() string {
+	if ac.Text != "" {
+		return ac.Text
+	}
+	return ac.Nm
+}
+
+// Conflicts returns descriptions of the conflicts among the key chords of the
+// commands, the AppKeyMap and the ActiveKeyMap: chords that trigger more
+// than one thing, and chords that are also the start of a multi-key sequence,
+// which thus can never be completed.  Key functions for menu shortcuts
+// (KeyFunMenu*) are the standard chords of the commands, and do not conflict
+// with them.
+func (kc *KeyCmds) Conflicts() []string {
+	binds := make(map[key.Chord][]string)
+	if ActiveKeyMap != nil {
+		for ch, kf := range *ActiveKeyMap {
+			if _, over := AppKeyMap[ch]; !over {
+				binds[ch] = append(binds[ch], kf.String())
+			}
+		}
+	}
+	for ch, kf := range AppKeyMap {
+		if kf != KeyFunNil {
+			binds[ch] = append(binds[ch], kf.String())
+		}
+	}
+	for _, cmd := range *kc {
+		ch := cmd.Active()
+		if ch == "" {
+			continue
+		}
+		ch = ch.OSShortcut()
+		bs := binds[ch]
+		for i := len(bs) - 1; i >= 0; i-- {
+			if strings.HasPrefix(bs[i], "KeyFunMenu") {
+				bs = append(bs[:i], bs[i+1:]...)
+			}
+		}
+		binds[ch] = append(bs, "command: "+cmd.Name)
+	}
+	var cfs []string
+	for ch, bs := range binds {
+		if len(bs) > 1 {
+			cfs = append(cfs, fmt.Sprintf("%v triggers: %v", ch, strings.Join(bs, ", ")))
+		}
+		seq := KeySeqChords(ch)
+		for i := 1; i < len(seq); i++ {
+			pre := key.Chord(strings.Join(KeySeqStrings(seq[:i]), " "))
+			if pbs, has := binds[pre]; has && len(pbs) > 0 {
+				cfs = append(cfs, fmt.Sprintf("%v triggers: %v, and starts %v, for: %v", pre, strings.Join(pbs, ", "), ch, strings.Join(bs, ", ")))
+			}
+		}
+	}
+	sort.Strings(cfs)
+	return cfs
+}
+
+// ShowConflicts shows the conflicts among the key chords in a dialog -- see
+// Conflicts
+func (kc *KeyCmds) ShowConflicts() {
+	cfs := kc.Conflicts()
+	prompt := "There are no conflicts among the key chords"
+	if len(cfs) > 0 {
+		prompt = strings.Join(cfs, "<br>\n")
+	}
+	PromptDialog(nil, DlgOpts{Title: "Key Chord Conflicts", Prompt: prompt}, AddOk, NoCancel, nil, nil)
+}
+
+// ViewStd shows the key maps that the AppKeyMap overrides, for reference
+func (kc *KeyCmds) ViewStd() {
+	TheViewIFace.KeyMapsView(&AvailKeyMaps)
+}
+
+/////////////////////////////////////////////////////////////////////////////////
+// Multi-key sequences
+
+// KeySeqChords returns the chords of a multi-key sequence, which are
+// separated by spaces -- a regular chord returns just itself
+func KeySeqChords(seq key.Chord) []key.Chord {
+	fs := strings.Fields(string(seq))
+	chs := make([]key.Chord, len(fs))
+	for i, f := range fs {
+		chs[i] = key.Chord(f)
+	}
+	return chs
+}
+
+// KeySeqStrings returns the chords as strings
+func KeySeqStrings(chs []key.Chord) []string {
+	ss := make([]string, len(chs))
+	for i, ch := range chs {
+		ss[i] = string(ch)
+	}
+	return ss
+}
+
+// IsKeySeqPrefix returns true if given chord (or sequence of chords) starts a
+// longer multi-key sequence that triggers a key function in the AppKeyMap or
+// ActiveKeyMap, or a shortcut in the given window (can be nil), so further
+// chords are needed to complete it
+func IsKeySeqPrefix(pre key.Chord, win *Window) bool {
+	ps := string(pre) + " "
+	for ch, kf := range AppKeyMap {
+		if kf != KeyFunNil && strings.HasPrefix(string(ch), ps) {
+			return true
+		}
+	}
+	if ActiveKeyMap != nil {
+		for ch := range *ActiveKeyMap {
+			if strings.HasPrefix(string(ch), ps) {
+				return true
+			}
+		}
+	}
+	if win != nil {
+		for ch := range win.Shortcuts {
+			if strings.HasPrefix(string(ch), ps) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// KeyChordGrabber is implemented by widgets that take every key chord as it
+// is typed, e.g., to edit key chords, so multi-key sequences are not gathered
+// by the window while they have the focus
+type KeyChordGrabber interface {
+	// GrabsKeyChords returns true if the widget currently takes all chords
+	GrabsKeyChords() bool
+}
+
+// KeyCmdsProps define the ToolBar and MenuBar for TableView of KeyCmds, e.g., giv.KeyCmdsView
+var KeyCmdsProps = ki.Props{
+	"MainMenu": ki.PropSlice{
+		{"AppMenu", ki.BlankProp{}},
+		{"File", ki.PropSlice{
+			{"OpenPrefs", ki.Props{}},
+			{"SavePrefs", ki.Props{
+				"shortcut": KeyFunMenuSave,
+				"updtfunc": func(kci interface{}, act *Action) {
+					act.SetActiveState(AppKeyCmdsChanged)
+				},
+			}},
+			{"sep-file", ki.BlankProp{}},
+			{"OpenJSON", ki.Props{
+				"label":    "Open from file",
+				"desc":     "You can save and open key chords to / from files to share, experiment, transfer, etc",
+				"shortcut": KeyFunMenuOpen,
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{
+						"ext": ".json",
+					}},
+				},
+			}},
+			{"SaveJSON", ki.Props{
+				"label":    "Save to file",
+				"desc":     "You can save and open key chords to / from files to share, experiment, transfer, etc",
+				"shortcut": KeyFunMenuSaveAs,
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{
+						"ext": ".json",
+					}},
+				},
+			}},
+			{"RevertToStd", ki.Props{
+				"desc":    "This reverts all the commands to their standard key chords, as set by the app, and clears the key function overrides for this app.  <b>Your current edits will be lost if you proceed!</b>  Continue?",
+				"confirm": true,
+			}},
+		}},
+		{"Edit", "Copy Cut Paste Dupe"},
+		{"Window", "Windows"},
+	},
+	"ToolBar": ki.PropSlice{
+		{"SavePrefs", ki.Props{
+			"desc": "saves the key chords to the app prefs directory, in file key_cmds_prefs.json, which is loaded automatically at startup",
+			"icon": "file-save",
+			"updtfunc": func(kci interface{}, act *Action) {
+				act.SetActiveState(AppKeyCmdsChanged)
+			},
+		}},
+		{"sep-file", ki.BlankProp{}},
+		{"ShowConflicts", ki.Props{
+			"label": "Conflicts",
+			"icon":  "search",
+			"desc":  "shows the key chords that trigger more than one thing, or start a multi-key sequence",
+		}},
+		{"ViewStd", ki.Props{
+			"label": "Key Maps",
+			"desc":  "shows the key maps of the key functions that are used by all apps, which can be overridden for this app",
+		}},
+		{"sep-std", ki.BlankProp{}},
+		{"RevertToStd", ki.Props{
+			"icon":    "update",
+			"desc":    "This reverts all the commands to their standard key chords, as set by the app, and clears the key function overrides for this app.  <b>Your current edits will be lost if you proceed!</b>  Continue?",
+			"confirm": true,
+		}},
+	},
+}
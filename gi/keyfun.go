@@ -89,6 +89,11 @@ const (
 	KeyFunWinClose
 	KeyFunWinSnapshot
 	KeyFunGoGiEditor
+	KeyFunFold       // fold code region at cursor
+	KeyFunUnfold     // unfold code region at cursor
+	KeyFunFoldCycle  // cycle fold state: folded, children folded, all unfolded
+	KeyFunSelectNext // adds a cursor selecting the next occurrence of the selected text
+	KeyFunCmdPalette // opens the command palette of the window
 	// Below are menu specific functions -- use these as shortcuts for menu actions
 	// allows uniqueness of mapping and easy customization of all key actions
 	KeyFunMenuNew
@@ -114,7 +119,10 @@ func (kf *KeyFuns) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(
 
 // KeyMap is a map between a key sequence (chord) and a specific KeyFun
 // function.  This mapping must be unique, in that each chord has unique
-// KeyFun, but multiple chords can trigger the same function.
+// KeyFun, but multiple chords can trigger the same function.  A chord can
+// also be a multi-key sequence of chords separated by spaces, e.g.,
+// "Control+X Control+S", in which case the first chords of the sequence
+// should not be mapped themselves -- see KeyCmds.Conflicts.
 type KeyMap map[key.Chord]KeyFuns
 
 // ActiveKeyMap points to the active map -- users can set this to an
@@ -155,11 +163,15 @@ func SetActiveKeyMapName(mapnm KeyMapName) {
 }
 
 // KeyFun translates chord into keyboard function -- use oswin key.Chord
-// to get chord
+// to get chord -- the AppKeyMap overrides the ActiveKeyMap
 func KeyFun(chord key.Chord) KeyFuns {
 	kf := KeyFunNil
 	if chord != "" {
-		kf = (*ActiveKeyMap)[chord]
+		if akf, ok := AppKeyMap[chord]; ok {
+			kf = akf
+		} else {
+			kf = (*ActiveKeyMap)[chord]
+		}
 		if KeyEventTrace {
 			fmt.Printf("gi.KeyFun chord: %v = %v\n", chord, kf)
 		}
@@ -607,6 +619,11 @@ var StdKeyMaps = KeyMaps{
 		"Alt+Meta+S":              KeyFunMenuSaveAlt,
 		"Shift+Meta+W":            KeyFunMenuCloseAlt1,
 		"Alt+Meta+W":              KeyFunMenuCloseAlt2,
+		"Shift+Meta+{":            KeyFunFold,
+		"Shift+Meta+}":            KeyFunUnfold,
+		"Shift+Meta+|":            KeyFunFoldCycle,
+		"Meta+D":                  KeyFunSelectNext,
+		"Shift+Meta+P":            KeyFunCmdPalette,
 	}},
 	{"MacEmacs", "Mac with emacs-style navigation -- emacs wins in conflicts", KeyMap{
 		"UpArrow":                 KeyFunMoveUp,
@@ -741,6 +758,11 @@ var StdKeyMaps = KeyMaps{
 		"Alt+Meta+S":              KeyFunMenuSaveAlt,
 		"Shift+Meta+W":            KeyFunMenuCloseAlt1,
 		"Alt+Meta+W":              KeyFunMenuCloseAlt2,
+		"Shift+Meta+{":            KeyFunFold,
+		"Shift+Meta+}":            KeyFunUnfold,
+		"Shift+Meta+|":            KeyFunFoldCycle,
+		"Meta+D":                  KeyFunSelectNext,
+		"Shift+Meta+P":            KeyFunCmdPalette,
 	}},
 	{"LinuxEmacs", "Linux with emacs-style navigation -- emacs wins in conflicts", KeyMap{
 		"UpArrow":                 KeyFunMoveUp,
@@ -860,6 +882,11 @@ var StdKeyMaps = KeyMaps{
 		"Control+Alt+S":           KeyFunMenuSaveAlt,
 		"Shift+Alt+W":             KeyFunMenuCloseAlt1,
 		"Control+Alt+W":           KeyFunMenuCloseAlt2,
+		"Shift+Control+{":         KeyFunFold,
+		"Shift+Control+}":         KeyFunUnfold,
+		"Shift+Control+|":         KeyFunFoldCycle,
+		"Shift+Control+D":         KeyFunSelectNext,
+		"Shift+Alt+P":             KeyFunCmdPalette,
 	}},
 	{"LinuxStd", "Standard Linux KeyMap", KeyMap{
 		"UpArrow":                 KeyFunMoveUp,
@@ -918,7 +945,6 @@ var StdKeyMaps = KeyMaps{
 		"Shift+Control++":         KeyFunZoomIn,
 		"Control+-":               KeyFunZoomOut,
 		"Shift+Control+_":         KeyFunZoomOut,
-		"Shift+Control+P":         KeyFunPrefs,
 		"Control+Alt+P":           KeyFunPrefs,
 		"F5":                      KeyFunRefresh,
 		"Control+L":               KeyFunRecenter,
@@ -948,6 +974,11 @@ var StdKeyMaps = KeyMaps{
 		"Control+Alt+S":           KeyFunMenuSaveAlt,
 		"Shift+Control+W":         KeyFunMenuCloseAlt1,
 		"Control+Alt+W":           KeyFunMenuCloseAlt2,
+		"Shift+Control+{":         KeyFunFold,
+		"Shift+Control+}":         KeyFunUnfold,
+		"Shift+Control+|":         KeyFunFoldCycle,
+		"Control+D":               KeyFunSelectNext,
+		"Shift+Control+P":         KeyFunCmdPalette,
 	}},
 	{"WindowsStd", "Standard Windows KeyMap", KeyMap{
 		"UpArrow":                 KeyFunMoveUp,
@@ -1006,7 +1037,6 @@ var StdKeyMaps = KeyMaps{
 		"Shift+Control++":         KeyFunZoomIn,
 		"Control+-":               KeyFunZoomOut,
 		"Shift+Control+_":         KeyFunZoomOut,
-		"Shift+Control+P":         KeyFunPrefs,
 		"Control+Alt+P":           KeyFunPrefs,
 		"F5":                      KeyFunRefresh,
 		"Control+L":               KeyFunRecenter,
@@ -1036,6 +1066,11 @@ var StdKeyMaps = KeyMaps{
 		"Control+Alt+S":           KeyFunMenuSaveAlt,
 		"Shift+Control+W":         KeyFunMenuCloseAlt1,
 		"Control+Alt+W":           KeyFunMenuCloseAlt2,
+		"Shift+Control+{":         KeyFunFold,
+		"Shift+Control+}":         KeyFunUnfold,
+		"Shift+Control+|":         KeyFunFoldCycle,
+		"Control+D":               KeyFunSelectNext,
+		"Shift+Control+P":         KeyFunCmdPalette,
 	}},
 	{"ChromeStd", "Standard chrome-browser and linux-under-chrome bindings", KeyMap{
 		"UpArrow":                 KeyFunMoveUp,
@@ -1094,7 +1129,6 @@ var StdKeyMaps = KeyMaps{
 		"Shift+Control++":         KeyFunZoomIn,
 		"Control+-":               KeyFunZoomOut,
 		"Shift+Control+_":         KeyFunZoomOut,
-		"Shift+Control+P":         KeyFunPrefs,
 		"Control+Alt+P":           KeyFunPrefs,
 		"F5":                      KeyFunRefresh,
 		"Control+L":               KeyFunRecenter,
@@ -1124,5 +1158,10 @@ var StdKeyMaps = KeyMaps{
 		"Control+Alt+S":           KeyFunMenuSaveAlt,
 		"Shift+Control+W":         KeyFunMenuCloseAlt1,
 		"Control+Alt+W":           KeyFunMenuCloseAlt2,
+		"Shift+Control+{":         KeyFunFold,
+		"Shift+Control+}":         KeyFunUnfold,
+		"Shift+Control+|":         KeyFunFoldCycle,
+		"Control+D":               KeyFunSelectNext,
+		"Shift+Control+P":         KeyFunCmdPalette,
 	}},
 }
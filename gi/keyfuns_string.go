@@ -68,23 +68,28 @@ func _() {
 	_ = x[KeyFunWinClose-52]
 	_ = x[KeyFunWinSnapshot-53]
 	_ = x[KeyFunGoGiEditor-54]
-	_ = x[KeyFunMenuNew-55]
-	_ = x[KeyFunMenuNewAlt1-56]
-	_ = x[KeyFunMenuNewAlt2-57]
-	_ = x[KeyFunMenuOpen-58]
-	_ = x[KeyFunMenuOpenAlt1-59]
-	_ = x[KeyFunMenuOpenAlt2-60]
-	_ = x[KeyFunMenuSave-61]
-	_ = x[KeyFunMenuSaveAs-62]
-	_ = x[KeyFunMenuSaveAlt-63]
-	_ = x[KeyFunMenuCloseAlt1-64]
-	_ = x[KeyFunMenuCloseAlt2-65]
-	_ = x[KeyFunsN-66]
+	_ = x[KeyFunFold-55]
+	_ = x[KeyFunUnfold-56]
+	_ = x[KeyFunFoldCycle-57]
+	_ = x[KeyFunSelectNext-58]
+	_ = x[KeyFunCmdPalette-59]
+	_ = x[KeyFunMenuNew-60]
+	_ = x[KeyFunMenuNewAlt1-61]
+	_ = x[KeyFunMenuNewAlt2-62]
+	_ = x[KeyFunMenuOpen-63]
+	_ = x[KeyFunMenuOpenAlt1-64]
+	_ = x[KeyFunMenuOpenAlt2-65]
+	_ = x[KeyFunMenuSave-66]
+	_ = x[KeyFunMenuSaveAs-67]
+	_ = x[KeyFunMenuSaveAlt-68]
+	_ = x[KeyFunMenuCloseAlt1-69]
+	_ = x[KeyFunMenuCloseAlt2-70]
+	_ = x[KeyFunsN-71]
 }
 
-const _KeyFuns_name = "KeyFunNilKeyFunMoveUpKeyFunMoveDownKeyFunMoveRightKeyFunMoveLeftKeyFunPageUpKeyFunPageDownKeyFunHomeKeyFunEndKeyFunDocHomeKeyFunDocEndKeyFunWordRightKeyFunWordLeftKeyFunFocusNextKeyFunFocusPrevKeyFunEnterKeyFunAcceptKeyFunCancelSelectKeyFunSelectModeKeyFunSelectAllKeyFunAbortKeyFunCopyKeyFunCutKeyFunPasteKeyFunPasteHistKeyFunBackspaceKeyFunBackspaceWordKeyFunDeleteKeyFunDeleteWordKeyFunKillKeyFunDuplicateKeyFunTransposeKeyFunTransposeWordKeyFunUndoKeyFunRedoKeyFunInsertKeyFunInsertAfterKeyFunZoomOutKeyFunZoomInKeyFunPrefsKeyFunRefreshKeyFunRecenterKeyFunCompleteKeyFunLookupKeyFunSearchKeyFunFindKeyFunReplaceKeyFunJumpKeyFunHistPrevKeyFunHistNextKeyFunMenuKeyFunWinFocusNextKeyFunWinCloseKeyFunWinSnapshotKeyFunGoGiEditorKeyFunMenuNewKeyFunMenuNewAlt1KeyFunMenuNewAlt2KeyFunMenuOpenKeyFunMenuOpenAlt1KeyFunMenuOpenAlt2KeyFunMenuSaveKeyFunMenuSaveAsKeyFunMenuSaveAltKeyFunMenuCloseAlt1KeyFunMenuCloseAlt2KeyFunsN"
+const _KeyFuns_name = "KeyFunNilKeyFunMoveUpKeyFunMoveDownKeyFunMoveRightKeyFunMoveLeftKeyFunPageUpKeyFunPageDownKeyFunHomeKeyFunEndKeyFunDocHomeKeyFunDocEndKeyFunWordRightKeyFunWordLeftKeyFunFocusNextKeyFunFocusPrevKeyFunEnterKeyFunAcceptKeyFunCancelSelectKeyFunSelectModeKeyFunSelectAllKeyFunAbortKeyFunCopyKeyFunCutKeyFunPasteKeyFunPasteHistKeyFunBackspaceKeyFunBackspaceWordKeyFunDeleteKeyFunDeleteWordKeyFunKillKeyFunDuplicateKeyFunTransposeKeyFunTransposeWordKeyFunUndoKeyFunRedoKeyFunInsertKeyFunInsertAfterKeyFunZoomOutKeyFunZoomInKeyFunPrefsKeyFunRefreshKeyFunRecenterKeyFunCompleteKeyFunLookupKeyFunSearchKeyFunFindKeyFunReplaceKeyFunJumpKeyFunHistPrevKeyFunHistNextKeyFunMenuKeyFunWinFocusNextKeyFunWinCloseKeyFunWinSnapshotKeyFunGoGiEditorKeyFunFoldKeyFunUnfoldKeyFunFoldCycleKeyFunSelectNextKeyFunCmdPaletteKeyFunMenuNewKeyFunMenuNewAlt1KeyFunMenuNewAlt2KeyFunMenuOpenKeyFunMenuOpenAlt1KeyFunMenuOpenAlt2KeyFunMenuSaveKeyFunMenuSaveAsKeyFunMenuSaveAltKeyFunMenuCloseAlt1KeyFunMenuCloseAlt2KeyFunsN"
 
-var _KeyFuns_index = [...]uint16{0, 9, 21, 35, 50, 64, 76, 90, 100, 109, 122, 134, 149, 163, 178, 193, 204, 216, 234, 250, 265, 276, 286, 295, 306, 321, 336, 355, 367, 383, 393, 408, 423, 442, 452, 462, 474, 491, 504, 516, 527, 540, 554, 568, 580, 592, 602, 615, 625, 639, 653, 663, 681, 695, 712, 728, 741, 758, 775, 789, 807, 825, 839, 855, 872, 891, 910, 918}
+var _KeyFuns_index = [...]uint16{0, 9, 21, 35, 50, 64, 76, 90, 100, 109, 122, 134, 149, 163, 178, 193, 204, 216, 234, 250, 265, 276, 286, 295, 306, 321, 336, 355, 367, 383, 393, 408, 423, 442, 452, 462, 474, 491, 504, 516, 527, 540, 554, 568, 580, 592, 602, 615, 625, 639, 653, 663, 681, 695, 712, 728, 738, 750, 765, 781, 797, 810, 827, 844, 858, 876, 894, 908, 924, 941, 960, 979, 987}
 
 func (i KeyFuns) String() string {
 	if i < 0 || i >= KeyFuns(len(_KeyFuns_index)-1) {
@@ -24,15 +24,19 @@ import (
 
 // Label is a widget for rendering text labels -- supports full widget model
 // including box rendering, and full HTML styling, including links -- LinkSig
-// emits link with data of URL -- opens default browser if nobody receiving
-// signal.  The default white-space option is 'pre' -- set to 'normal' or
-// other options to get word-wrapping etc.
+// emits LabelLinkClicked with data of URL -- opens default browser if nobody
+// receiving signal.  In addition to inline styling, the HTML can include
+// inline images (<img>), nested lists (<ul>, <ol>), simple tables (<table>)
+// and syntax-highlighted code (<code lang="go">) -- see girl.SetHTMLNoPre --
+// making a Label a lightweight rich-text display.  The default white-space
+// option is 'pre' -- set to 'normal' or other options to get word-wrapping
+// etc (lists and tables require 'normal').
 type Label struct {
 	WidgetBase
 	Text        string                   `xml:"text" desc:"label to display"`
 	Selectable  bool                     `desc:"is this label selectable? if so, it will change background color in response to selection events and update selection state on mouse clicks"`
 	Redrawable  bool                     `desc:"is this label going to be redrawn frequently without an overall full re-render?  if so, you need to set this flag to avoid weird overlapping rendering results from antialiasing.  Also, if the label will change dynamically, this must be set to true, otherwise labels will illegibly overlay on top of each other."`
-	LinkSig     ki.Signal                `copy:"-" json:"-" xml:"-" view:"-" desc:"signal for clicking on a link -- data is a string of the URL -- if nobody receiving this signal, calls TextLinkHandler then URLHandler"`
+	LinkSig     ki.Signal                `copy:"-" json:"-" xml:"-" view:"-" desc:"signal for clicking on a link -- sends LabelLinkClicked with data of a string of the URL -- if nobody receiving this signal, calls TextLinkHandler then URLHandler"`
 	StateStyles [LabelStatesN]gist.Style `copy:"-" json:"-" xml:"-" desc:"styles for different states of label"`
 	Render      girl.Text                `copy:"-" xml:"-" json:"-" desc:"render data for text label"`
 	RenderPos   mat32.Vec2               `copy:"-" xml:"-" json:"-" desc:"position offset of start of text rendering, from last render -- AllocPos plus alignment factors for center, right etc."`
@@ -61,6 +65,12 @@ func (lb *Label) Disconnect() {
 	lb.LinkSig.DisconnectAll()
 }
 
+// AccessInfo describes the label for assistive technologies
+func (lb *Label) AccessInfo(an *oswin.AccessNode) {
+	an.Role = oswin.RoleLabel
+	an.Name = AccessText(lb.Text)
+}
+
 var LabelProps = ki.Props{
 	"EnumType:Flag":    KiT_NodeFlags,
 	"white-space":      gist.WhiteSpacePre, // no wrap, use spaces unless otherwise specified!
@@ -104,6 +114,19 @@ var KiT_LabelStates = kit.Enums.AddEnumAltLower(LabelStatesN, kit.NotBitFlag, gi
 func (ev LabelStates) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
 func (ev *LabelStates) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
 
+// LabelSignals are signals that labels can send
+type LabelSignals int64
+
+const (
+	// LabelLinkClicked is sent on LinkSig when a link in the label is
+	// clicked -- data is the URL of the link
+	LabelLinkClicked LabelSignals = iota
+
+	LabelSignalsN
+)
+
+//go:generate stringer -type=LabelSignals
+
 // LabelSelectors are Style selector names for the different states:
 var LabelSelectors = []string{":active", ":inactive", ":selected"}
 
@@ -183,7 +206,7 @@ func (lb *Label) OpenLink(tl *girl.TextLink) {
 		}
 		return
 	}
-	lb.LinkSig.Emit(lb.This(), 0, tl.URL) // todo: could potentially signal different target=_blank kinds of options here with the sig
+	lb.LinkSig.Emit(lb.This(), int64(LabelLinkClicked), tl.URL) // todo: could potentially signal different target=_blank kinds of options here with the sig
 }
 
 func (lb *Label) HoverEvent() {
@@ -314,6 +337,7 @@ func (lb *Label) StyleLabel() {
 		for i := 0; i < int(LabelStatesN); i++ {
 			lb.StateStyles[i].CopyFrom(&lb.Sty)
 			lb.StateStyles[i].SetStyleProps(parSty, lb.StyleProps(LabelSelectors[i]), lb.Viewport)
+			ApplyAppStyles(lb.This().(Node2D), lb.Viewport, &lb.StateStyles[i], parSty, LabelSelectors[i])
 			lb.StateStyles[i].CopyUnitContext(&lb.Sty.UnContext)
 		}
 	}
@@ -18,6 +18,7 @@ import (
 	"github.com/goki/gi/oswin/dnd"
 	"github.com/goki/gi/oswin/key"
 	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/oswin/touch"
 	"github.com/goki/gi/units"
 	"github.com/goki/ki/ints"
 	"github.com/goki/ki/ki"
@@ -137,6 +138,7 @@ type Layout struct {
 	Scrolls       [2]*ScrollBar       `copy:"-" json:"-" xml:"-" desc:"scroll bars -- we fully manage them as needed"`
 	GridSize      image.Point         `copy:"-" json:"-" xml:"-" desc:"computed size of a grid layout based on all the constraints -- computed during Size2D pass"`
 	GridData      [RowColN][]GridData `copy:"-" json:"-" xml:"-" desc:"grid data for rows in [0] and cols in [1]"`
+	GridPlaces    []image.Rectangle   `copy:"-" json:"-" xml:"-" desc:"for grid template layout, the cells occupied by each child, in grid coordinates (X = col, Y = row) -- computed during Size2D pass"`
 	FlowBreaks    []int               `copy:"-" json:"-" xml:"-" desc:"line breaks for flow layout"`
 	NeedsRedo     bool                `copy:"-" json:"-" xml:"-" desc:"true if this layout got a redo = true on previous iteration -- otherwise it just skips any re-layout on subsequent iteration"`
 	FocusName     string              `copy:"-" json:"-" xml:"-" desc:"accumulated name to search for when keys are typed"`
@@ -144,6 +146,7 @@ type Layout struct {
 	FocusNameLast ki.Ki               `copy:"-" json:"-" xml:"-" desc:"last element focused on -- used as a starting point if name is the same"`
 	ScrollsOff    bool                `copy:"-" json:"-" xml:"-" desc:"scrollbars have been manually turned off due to layout being invisible -- must be reactivated when re-visible"`
 	ScrollSig     ki.Signal           `copy:"-" json:"-" xml:"-" view:"-" desc:"signal for layout scrolling -- sends signal whenever layout is scrolled due to user input -- signal type is dimension (mat32.X or Y) and data is new position (not delta)"`
+	Touch         TouchScroll         `copy:"-" json:"-" xml:"-" view:"-" desc:"state of dragging the layout to scroll it on a touch screen -- see TouchUI"`
 }
 
 var KiT_Layout = kit.Types.AddType(&Layout{}, LayoutProps)
@@ -185,8 +188,8 @@ const (
 	// LayoutGrid arranges items according to a regular grid
 	LayoutGrid
 
-	// todo: add LayoutGridIrreg that deals with irregular grids with spans etc -- keep
-	// the basic grid for fully regular cases -- need high performance for large grids
+	// note: see LayoutGridTemplate for irregular grids with spans etc -- the basic
+	// grid is kept for fully regular cases -- need high performance for large grids
 
 	// LayoutHorizFlow arranges items horizontally across a row, overflowing
 	// vertically as needed.  Ballpark target width or height props should be set
@@ -207,6 +210,24 @@ const (
 	// parent wants to take over the job of the layout
 	LayoutNil
 
+	// LayoutFlexHoriz arranges items horizontally across a row, using CSS
+	// flexbox semantics: each item starts at its flex-basis size, and then
+	// grows into any extra space according to its flex-grow, or shrinks if
+	// there is not enough space according to its flex-shrink.
+	LayoutFlexHoriz
+
+	// LayoutFlexVert arranges items vertically in a column, using CSS flexbox
+	// semantics -- see LayoutFlexHoriz.
+	LayoutFlexVert
+
+	// LayoutGridTemplate arranges items in a grid according to CSS-grid-like
+	// grid-template-columns and grid-template-rows style properties, where
+	// each column or row (track) is a fixed size, auto-sized to fit its
+	// contents, or gets a fraction (fr) of the remaining space.  Items are
+	// placed in order in the next free cell, or at their row, col styles, and
+	// can span multiple cells with row-span and col-span.
+	LayoutGridTemplate
+
 	LayoutsN
 )
 
@@ -239,7 +260,7 @@ var LayoutDefault Layout
 // SumDim returns whether we sum up elements along given dimension?  else use
 // max for shared dimension.
 func (ly *Layout) SumDim(d mat32.Dims) bool {
-	if (d == mat32.X && (ly.Lay == LayoutHoriz || ly.Lay == LayoutHorizFlow)) || (d == mat32.Y && (ly.Lay == LayoutVert || ly.Lay == LayoutVertFlow)) {
+	if (d == mat32.X && (ly.Lay == LayoutHoriz || ly.Lay == LayoutHorizFlow || ly.Lay == LayoutFlexHoriz)) || (d == mat32.Y && (ly.Lay == LayoutVert || ly.Lay == LayoutVertFlow || ly.Lay == LayoutFlexVert)) {
 		return true
 	}
 	return false
@@ -247,7 +268,7 @@ func (ly *Layout) SumDim(d mat32.Dims) bool {
 
 // SummedDim returns the dimension along which layout is summing.
 func (ly *Layout) SummedDim() mat32.Dims {
-	if ly.Lay == LayoutHoriz || ly.Lay == LayoutHorizFlow {
+	if ly.Lay == LayoutHoriz || ly.Lay == LayoutHorizFlow || ly.Lay == LayoutFlexHoriz {
 		return mat32.X
 	}
 	return mat32.Y
@@ -1429,6 +1450,28 @@ func (ly *Layout) ScrollDelta(me *mouse.ScrollEvent) {
 	}
 }
 
+// PanDelta processes a two-finger pan gesture on a touch screen, scrolling
+// so that the content moves along with the fingers.  The event is only
+// processed if there is scrolling in each dimension it moved in --
+// otherwise the consumed dimension is reset to 0 and the event is left
+// unprocessed, so a higher level can scroll in the other one.
+func (ly *Layout) PanDelta(pe *touch.PanEvent) {
+	if pe.Action != touch.Move {
+		return
+	}
+	if ly.HasScroll[mat32.X] && pe.Delta.X != 0 {
+		ly.ScrollActionDelta(mat32.X, float32(-pe.Delta.X))
+		pe.Delta.X = 0
+	}
+	if ly.HasScroll[mat32.Y] && pe.Delta.Y != 0 {
+		ly.ScrollActionDelta(mat32.Y, float32(-pe.Delta.Y))
+		pe.Delta.Y = 0
+	}
+	if pe.Delta == image.ZP {
+		pe.SetProcessed()
+	}
+}
+
 // render the children
 func (ly *Layout) Render2DChildren() {
 	if ly.Lay == LayoutStacked {
@@ -1443,13 +1486,16 @@ func (ly *Layout) Render2DChildren() {
 		}
 		// note: all nodes need to render to disconnect b/c of invisible
 	}
+	fp := ly.FrameProf()
 	for _, kid := range ly.Kids {
 		if kid == nil {
 			continue
 		}
 		nii, _ := KiToNode2D(kid)
 		if nii != nil {
+			st := fp.Begin()
 			nii.Render2D()
+			fp.EndTotal(kid, FrameRender, st)
 		}
 	}
 }
@@ -1785,7 +1831,7 @@ func (ly *Layout) LayoutKeys(kt *key.ChordEvent) {
 		fmt.Printf("Layout KeyInput: %v\n", ly.PathUnique())
 	}
 	kf := KeyFun(kt.Chord())
-	if ly.Lay == LayoutHoriz || ly.Lay == LayoutGrid || ly.Lay == LayoutHorizFlow {
+	if ly.Lay == LayoutHoriz || ly.Lay == LayoutGrid || ly.Lay == LayoutHorizFlow || ly.Lay == LayoutFlexHoriz || ly.Lay == LayoutGridTemplate {
 		switch kf {
 		case KeyFunMoveRight:
 			if ly.FocusNextChild(false) { // allow higher layers to try..
@@ -1799,7 +1845,7 @@ func (ly *Layout) LayoutKeys(kt *key.ChordEvent) {
 			return
 		}
 	}
-	if ly.Lay == LayoutVert || ly.Lay == LayoutGrid || ly.Lay == LayoutVertFlow {
+	if ly.Lay == LayoutVert || ly.Lay == LayoutGrid || ly.Lay == LayoutVertFlow || ly.Lay == LayoutFlexVert || ly.Lay == LayoutGridTemplate {
 		switch kf {
 		case KeyFunMoveDown:
 			if ly.FocusNextChild(true) {
@@ -1937,6 +1983,14 @@ func (ly *Layout) LayoutScrollEvents() {
 		li := recv.Embed(KiT_Layout).(*Layout)
 		li.ScrollDelta(me)
 	})
+	ly.ConnectEvent(oswin.PanEvent, LowPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		pe := d.(*touch.PanEvent)
+		li := recv.Embed(KiT_Layout).(*Layout)
+		li.PanDelta(pe)
+	})
+	if TouchUI {
+		ly.TouchScrollEvents()
+	}
 	// HiPri to do it first so others can be in view etc -- does NOT consume event!
 	ly.ConnectEvent(oswin.DNDMoveEvent, HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
 		me := d.(*dnd.MoveEvent)
@@ -2061,6 +2115,10 @@ func (ly *Layout) Size2D(iter int) {
 		ly.GatherSizesFlow(iter)
 	case LayoutGrid:
 		ly.GatherSizesGrid()
+	case LayoutFlexHoriz, LayoutFlexVert:
+		ly.GatherSizesFlex()
+	case LayoutGridTemplate:
+		ly.GatherSizesGridTemplate()
 	default:
 		ly.GatherSizes()
 	}
@@ -2091,6 +2149,14 @@ func (ly *Layout) Layout2D(parBBox image.Rectangle, iter int) bool {
 		redo = ly.LayoutFlow(mat32.X, iter)
 	case LayoutVertFlow:
 		redo = ly.LayoutFlow(mat32.Y, iter)
+	case LayoutFlexHoriz:
+		ly.LayoutFlex(mat32.X)
+		ly.LayoutSharedDim(mat32.Y)
+	case LayoutFlexVert:
+		ly.LayoutFlex(mat32.Y)
+		ly.LayoutSharedDim(mat32.X)
+	case LayoutGridTemplate:
+		ly.LayoutGridTemplate()
 	case LayoutNil:
 		// nothing
 	}
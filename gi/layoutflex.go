@@ -0,0 +1,681 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/ki/ints"
+	"github.com/goki/mat32"
+)
+
+// This file has the constraint-based layouts: LayoutFlexHoriz, LayoutFlexVert
+// which implement CSS flexbox grow / shrink / basis semantics along the main
+// dimension, and LayoutGridTemplate which implements CSS grid row / column
+// templates with fixed, auto and fr (fraction) tracks, and row / col spans.
+
+////////////////////////////////////////////////////////////////////////////////////////
+//     Flex
+
+// flexItem has the flex layout data for one child
+type flexItem struct {
+	ni     *WidgetBase
+	basis  float32 // flex basis = hypothetical size before growing / shrinking
+	need   float32 // minimum size
+	max    float32 // maximum size, if > 0
+	grow   float32
+	shrink float32
+	size   float32 // final size
+	frozen bool    // size is final
+}
+
+// FlexItem returns the flex basis, minimum (need) and maximum sizes of given
+// child along given (main) dimension of a flex layout, along with its
+// flex-grow and flex-shrink factors.  The basis is the flex-basis style if
+// set, else the preferred size, and the need is the basis if it does not
+// shrink.
+func FlexItem(ni *WidgetBase, dim mat32.Dims) (basis, need, max, grow, shrink float32) {
+	ni.StyMu.RLock()
+	lst := &ni.Sty.Layout
+	basis = lst.FlexBasis.Dots
+	grow = lst.FlexGrow
+	shrink = lst.FlexShrink
+	ni.StyMu.RUnlock()
+	need = ni.LayState.Size.Need.Dim(dim)
+	max = ni.LayState.Size.Max.Dim(dim)
+	if basis <= 0 {
+		basis = ni.LayState.Size.Pref.Dim(dim)
+	}
+	if max > 0 {
+		basis = mat32.Min(basis, max)
+	}
+	basis = mat32.Max(basis, need)
+	if shrink <= 0 {
+		need = basis
+	}
+	return
+}
+
+// GatherSizesFlex is size first pass: gather the size information from the
+// children, flex version: along the main dimension, the need is the sum of
+// the minimum sizes the children can shrink to, and the pref is the sum of
+// their flex basis sizes
+func (ly *Layout) GatherSizesFlex() {
+	sz := len(ly.Kids)
+	if sz == 0 {
+		return
+	}
+	dim := ly.SummedDim()
+	odim := mat32.OtherDim(dim)
+
+	var sumNeed, sumPref, maxNeed, maxPref float32
+	for _, c := range ly.Kids {
+		if c == nil {
+			continue
+		}
+		ni := c.(Node2D).AsWidget()
+		if ni == nil {
+			continue
+		}
+		ni.LayState.UpdateSizes()
+		basis, need, _, _, _ := FlexItem(ni, dim)
+		sumNeed += need
+		sumPref += basis
+		maxNeed = mat32.Max(maxNeed, ni.LayState.Size.Need.Dim(odim))
+		maxPref = mat32.Max(maxPref, ni.LayState.Size.Pref.Dim(odim))
+		if Layout2DTrace {
+			fmt.Printf("Size:   %v Flex Child: %v, need: %v, basis: %v\n", ly.PathUnique(), ni.UniqueNm, need, basis)
+		}
+	}
+
+	prefSizing := false
+	mvp := ly.ViewportSafe()
+	if mvp != nil && mvp.HasFlag(int(VpFlagPrefSizing)) {
+		prefSizing = ly.Sty.Layout.Overflow == gist.OverflowScroll // special case
+	}
+
+	for _, d := range []mat32.Dims{dim, odim} {
+		pref := ly.LayState.Size.Pref.Dim(d)
+		if prefSizing || pref == 0 {
+			if d == dim {
+				ly.LayState.Size.Need.SetMaxDim(d, sumNeed)
+				ly.LayState.Size.Pref.SetMaxDim(d, sumPref)
+			} else {
+				ly.LayState.Size.Need.SetMaxDim(d, maxNeed)
+				ly.LayState.Size.Pref.SetMaxDim(d, maxPref)
+			}
+		} else { // use target size from style
+			ly.LayState.Size.Need.SetDim(d, pref)
+		}
+	}
+
+	spc := ly.BoxSpace()
+	ly.LayState.Size.Need.SetAddScalar(2.0 * spc)
+	ly.LayState.Size.Pref.SetAddScalar(2.0 * spc)
+
+	elspc := float32(0.0)
+	if sz >= 2 {
+		elspc = float32(sz-1) * ly.Spacing.Dots
+	}
+	ly.LayState.Size.Need.SetAddDim(dim, elspc)
+	ly.LayState.Size.Pref.SetAddDim(dim, elspc)
+
+	ly.LayState.UpdateSizes() // enforce max and normal ordering, etc
+	if Layout2DTrace {
+		fmt.Printf("Size:   %v gather sizes flex need: %v, pref: %v, elspc: %v\n", ly.PathUnique(), ly.LayState.Size.Need, ly.LayState.Size.Pref, elspc)
+	}
+}
+
+// LayoutFlex lays out all children along given (main) dim, according to
+// the flexbox algorithm: each child starts at its flex basis size, and then
+// any extra space is distributed according to flex-grow, or any shortfall is
+// taken according to flex-shrink (weighted by the basis), respecting the
+// min and max sizes of each child.  Any remaining extra space is distributed
+// according to the alignment of the layout along dim (start, center, end,
+// justify = space between, or space around).  Use LayoutSharedDim for the
+// other dim.
+func (ly *Layout) LayoutFlex(dim mat32.Dims) {
+	sz := len(ly.Kids)
+	if sz == 0 {
+		return
+	}
+
+	elspc := float32(sz-1) * ly.Spacing.Dots
+	al := ly.Sty.Layout.AlignDim(dim)
+	spc := ly.BoxSpace()
+	avail := ly.LayState.Alloc.Size.Dim(dim) - (2.0*spc + elspc)
+
+	items := make([]flexItem, 0, sz)
+	sumBasis := float32(0)
+	for _, c := range ly.Kids {
+		if c == nil {
+			continue
+		}
+		ni := c.(Node2D).AsWidget()
+		if ni == nil {
+			continue
+		}
+		it := flexItem{ni: ni}
+		it.basis, it.need, it.max, it.grow, it.shrink = FlexItem(ni, dim)
+		it.size = it.basis
+		sumBasis += it.basis
+		items = append(items, it)
+	}
+
+	growing := avail > sumBasis
+	// resolve the flexible sizes: items that hit their min or max are frozen
+	// at that size, and the remaining free space redistributed to the others
+	for iter := 0; iter <= len(items); iter++ {
+		free := avail
+		sumf := float32(0)
+		for i := range items {
+			it := &items[i]
+			if it.frozen {
+				free -= it.size
+				continue
+			}
+			free -= it.basis
+			if growing {
+				sumf += it.grow
+			} else {
+				sumf += it.shrink * it.basis
+			}
+		}
+		if sumf == 0 || mat32.Abs(free) < 0.01 {
+			break
+		}
+		clamped := false
+		for i := range items {
+			it := &items[i]
+			if it.frozen {
+				continue
+			}
+			if growing {
+				it.size = it.basis + free*it.grow/sumf
+			} else {
+				it.size = it.basis + free*it.shrink*it.basis/sumf
+			}
+			if it.max > 0 && it.size > it.max {
+				it.size = it.max
+				it.frozen = true
+				clamped = true
+			} else if it.size < it.need {
+				it.size = it.need
+				it.frozen = true
+				clamped = true
+			}
+		}
+		if !clamped {
+			break
+		}
+		for i := range items { // unclamped items are recomputed
+			if !items[i].frozen {
+				items[i].size = items[i].basis
+			}
+		}
+	}
+
+	used := float32(0)
+	for i := range items {
+		used += items[i].size
+	}
+	extra := mat32.Max(avail-used, 0)
+
+	pos := spc
+	between := float32(0)
+	switch {
+	case gist.IsAlignMiddle(al):
+		pos += 0.5 * extra
+	case gist.IsAlignEnd(al):
+		pos += extra
+	case al == gist.AlignJustify:
+		if len(items) > 1 {
+			between = extra / float32(len(items)-1)
+		}
+	case al == gist.AlignSpaceAround:
+		between = extra / float32(len(items))
+		pos += 0.5 * between
+	}
+
+	if Layout2DTrace {
+		fmt.Printf("Layout: %v Flex dim %v, avail: %v basis: %v used: %v extra: %v\n", ly.PathUnique(), dim, avail, sumBasis, used, extra)
+	}
+
+	for i := range items {
+		it := &items[i]
+		it.ni.LayState.Alloc.Size.SetDim(dim, it.size)
+		it.ni.LayState.Alloc.PosRel.SetDim(dim, pos)
+		if Layout2DTrace {
+			fmt.Printf("Layout: %v Flex Child: %v, pos: %v, size: %v, basis: %v\n", ly.PathUnique(), it.ni.UniqueNm, pos, it.size, it.basis)
+		}
+		pos += it.size + ly.Spacing.Dots + between
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//     Grid Template
+
+// GridTrack returns the grid template track for given row or col index --
+// tracks beyond those specified in the template are auto
+func (ly *Layout) GridTrack(rowcol RowCol, idx int) gist.GridTrack {
+	tracks := ly.Sty.Layout.GridTemplateRows
+	if rowcol == Col {
+		tracks = ly.Sty.Layout.GridTemplateColumns
+	}
+	if idx < len(tracks) {
+		return tracks[idx]
+	}
+	return gist.GridTrack{Auto: true}
+}
+
+// PlaceGridTemplate places the children within the cells of a grid template
+// layout, setting GridPlaces and GridSize: children with a row and / or col
+// style (> 0, as in LayoutGrid) are placed there, and the others are placed
+// in order in the next free cells, spanning row-span and col-span cells.
+func (ly *Layout) PlaceGridTemplate() {
+	cols := len(ly.Sty.Layout.GridTemplateColumns)
+	if cols == 0 {
+		cols = ly.Sty.Layout.Columns
+	}
+	if cols == 0 {
+		cols = ints.MaxInt(int(mat32.Sqrt(float32(len(ly.Kids)))), 1)
+	}
+	var occ [][]bool // [row][col] cells that are occupied
+	isFree := func(r image.Rectangle) bool {
+		if r.Max.X > cols {
+			return false
+		}
+		for y := r.Min.Y; y < r.Max.Y && y < len(occ); y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				if occ[y][x] {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	ly.GridPlaces = ly.GridPlaces[:0]
+	rows := len(ly.Sty.Layout.GridTemplateRows)
+	cur := image.Point{}
+	for _, c := range ly.Kids {
+		if c == nil {
+			ly.GridPlaces = append(ly.GridPlaces, image.Rectangle{})
+			continue
+		}
+		ni := c.(Node2D).AsWidget()
+		if ni == nil {
+			ly.GridPlaces = append(ly.GridPlaces, image.Rectangle{})
+			continue
+		}
+		ni.StyMu.RLock()
+		lst := ni.Sty.Layout
+		ni.StyMu.RUnlock()
+		span := image.Point{ints.MinInt(ints.MaxInt(lst.ColSpan, 1), cols), ints.MaxInt(lst.RowSpan, 1)}
+		var r image.Rectangle
+		switch {
+		case lst.Col > 0 && lst.Row > 0:
+			r = image.Rectangle{Min: image.Point{ints.MinInt(lst.Col, cols-span.X), lst.Row}}
+		case lst.Row > 0: // first free col in given row
+			r = image.Rectangle{Min: image.Point{0, lst.Row}}
+			for ; r.Min.X+span.X <= cols; r.Min.X++ {
+				if isFree(image.Rectangle{Min: r.Min, Max: r.Min.Add(span)}) {
+					break
+				}
+			}
+		default: // next free cell (in given col)
+			p := cur
+			if lst.Col > 0 {
+				p.X = ints.MinInt(lst.Col, cols-span.X)
+				if p.X < cur.X {
+					p.Y++
+				}
+			}
+			for {
+				if p.X+span.X > cols {
+					p.X = 0
+					p.Y++
+				}
+				if isFree(image.Rectangle{Min: p, Max: p.Add(span)}) {
+					break
+				}
+				if lst.Col > 0 {
+					p.Y++
+				} else {
+					p.X++
+				}
+			}
+			r.Min = p
+			cur = image.Point{p.X + span.X, p.Y}
+		}
+		r.Max = r.Min.Add(span)
+		for len(occ) < r.Max.Y {
+			occ = append(occ, make([]bool, cols))
+		}
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				occ[y][x] = true
+			}
+		}
+		ly.GridPlaces = append(ly.GridPlaces, r)
+		rows = ints.MaxInt(rows, r.Max.Y)
+	}
+	ly.GridSize = image.Point{cols, rows}
+}
+
+// GatherSizesGridTemplate is size first pass: gather the size information
+// from the children, grid template version: fixed tracks have their
+// specified size, and auto and fr tracks get the max size of the children
+// within them, with children spanning multiple tracks distributing any
+// additional size they need across their non-fixed tracks.
+func (ly *Layout) GatherSizesGridTemplate() {
+	if len(ly.Kids) == 0 {
+		return
+	}
+	ly.PlaceGridTemplate()
+	gsz := [RowColN]int{ly.GridSize.Y, ly.GridSize.X}
+	dims := [RowColN]mat32.Dims{mat32.Y, mat32.X}
+	for rc := Row; rc < RowColN; rc++ {
+		if len(ly.GridData[rc]) != gsz[rc] {
+			ly.GridData[rc] = make([]GridData, gsz[rc])
+		}
+		for i := range ly.GridData[rc] {
+			gd := &ly.GridData[rc][i]
+			*gd = GridData{}
+			tr := ly.GridTrack(rc, i)
+			if !tr.Auto && tr.Fr == 0 {
+				gd.SizeNeed = tr.Size.Dots
+				gd.SizePref = tr.Size.Dots
+				gd.SizeMax = tr.Size.Dots
+			}
+		}
+	}
+
+	// first single-track children, then spanning ones
+	for pass := 0; pass < 2; pass++ {
+		for i, c := range ly.Kids {
+			if c == nil {
+				continue
+			}
+			ni := c.(Node2D).AsWidget()
+			if ni == nil {
+				continue
+			}
+			if pass == 0 {
+				ni.LayState.UpdateSizes()
+			}
+			pl := ly.GridPlaces[i]
+			for rc := Row; rc < RowColN; rc++ {
+				dim := dims[rc]
+				st, ed := pl.Min.Y, pl.Max.Y
+				if rc == Col {
+					st, ed = pl.Min.X, pl.Max.X
+				}
+				if (ed-st > 1) != (pass == 1) {
+					continue
+				}
+				ly.GridTemplateContrib(rc, st, ed, ni.LayState.Size.Need.Dim(dim), ni.LayState.Size.Pref.Dim(dim), ni.LayState.Size.Max.Dim(dim))
+			}
+		}
+	}
+
+	prefSizing := false
+	mvp := ly.ViewportSafe()
+	if mvp != nil && mvp.HasFlag(int(VpFlagPrefSizing)) {
+		prefSizing = ly.Sty.Layout.Overflow == gist.OverflowScroll // special case
+	}
+
+	for rc := Row; rc < RowColN; rc++ {
+		dim := dims[rc]
+		var sumNeed, sumPref float32
+		for _, gd := range ly.GridData[rc] {
+			sumNeed += gd.SizeNeed
+			sumPref += gd.SizePref
+		}
+		if ly.LayState.Size.Pref.Dim(dim) == 0 || prefSizing {
+			ly.LayState.Size.Need.SetDim(dim, mat32.Max(ly.LayState.Size.Need.Dim(dim), sumNeed))
+			ly.LayState.Size.Pref.SetDim(dim, mat32.Max(ly.LayState.Size.Pref.Dim(dim), sumPref))
+		} else { // use target size from style otherwise
+			ly.LayState.Size.Need.SetDim(dim, ly.LayState.Size.Pref.Dim(dim))
+		}
+		elspc := float32(ints.MaxInt(gsz[rc]-1, 0)) * ly.Spacing.Dots
+		ly.LayState.Size.Need.SetAddDim(dim, elspc)
+		ly.LayState.Size.Pref.SetAddDim(dim, elspc)
+	}
+
+	spc := ly.BoxSpace()
+	ly.LayState.Size.Need.SetAddScalar(2.0 * spc)
+	ly.LayState.Size.Pref.SetAddScalar(2.0 * spc)
+
+	ly.LayState.UpdateSizes() // enforce max and normal ordering, etc
+	if Layout2DTrace {
+		fmt.Printf("Size:   %v gather sizes grid template: %v need: %v, pref: %v\n", ly.PathUnique(), ly.GridSize, ly.LayState.Size.Need, ly.LayState.Size.Pref)
+	}
+}
+
+// GridTemplateContrib adds the size constraints of a child occupying tracks
+// st to ed (exclusive) to the grid data for given row or col
+func (ly *Layout) GridTemplateContrib(rowcol RowCol, st, ed int, need, pref, max float32) {
+	gds := ly.GridData[rowcol]
+	if ed-st == 1 {
+		tr := ly.GridTrack(rowcol, st)
+		if !tr.Auto && tr.Fr == 0 {
+			return
+		}
+		gd := &gds[st]
+		mat32.SetMax(&gd.SizeNeed, need)
+		mat32.SetMax(&gd.SizePref, pref)
+		if gd.SizeMax >= 0 {
+			if max < 0 { // stretch
+				gd.SizeMax = -1
+			} else {
+				mat32.SetMax(&gd.SizeMax, max)
+			}
+		}
+		return
+	}
+	// spanning: distribute any shortfall evenly across the non-fixed tracks
+	elspc := float32(ed-st-1) * ly.Spacing.Dots
+	var curNeed, curPref float32
+	var flex []int
+	for i := st; i < ed; i++ {
+		curNeed += gds[i].SizeNeed
+		curPref += gds[i].SizePref
+		tr := ly.GridTrack(rowcol, i)
+		if tr.Auto || tr.Fr > 0 {
+			flex = append(flex, i)
+		}
+	}
+	if len(flex) == 0 {
+		return
+	}
+	nf := float32(len(flex))
+	dneed := (need - elspc - curNeed) / nf
+	dpref := (pref - elspc - curPref) / nf
+	for _, i := range flex {
+		gd := &gds[i]
+		if dneed > 0 {
+			gd.SizeNeed += dneed
+		}
+		if dpref > 0 {
+			gd.SizePref += dpref
+		}
+		gd.SizePref = mat32.Max(gd.SizePref, gd.SizeNeed)
+	}
+}
+
+// LayoutGridTemplateDim lays out the grid template tracks along given row or
+// col dimension: fixed tracks get their size, auto tracks their preferred
+// size (or needed size if there isn't room), and fr tracks divide up the
+// remaining space in proportion to their fractions (but no less than they
+// need).  If there are no fr tracks, any extra space stretches the auto
+// tracks that contain stretchy children, or otherwise positions the tracks
+// according to the alignment of the layout along dim.
+func (ly *Layout) LayoutGridTemplateDim(rowcol RowCol, dim mat32.Dims) {
+	gds := ly.GridData[rowcol]
+	sz := len(gds)
+	if sz == 0 {
+		return
+	}
+	elspc := float32(sz-1) * ly.Spacing.Dots
+	al := ly.Sty.Layout.AlignDim(dim)
+	spc := ly.BoxSpace()
+	avail := ly.LayState.Alloc.Size.Dim(dim) - (2.0*spc + elspc)
+
+	tracks := make([]gist.GridTrack, sz)
+	var sumBase, sumPref, frTot float32
+	for i := range gds {
+		tracks[i] = ly.GridTrack(rowcol, i)
+		gd := &gds[i]
+		switch {
+		case tracks[i].Fr > 0:
+			gd.AllocSize = gd.SizeNeed
+			frTot += tracks[i].Fr
+			sumPref += gd.SizeNeed
+		case tracks[i].Auto:
+			gd.AllocSize = gd.SizeNeed
+			sumPref += gd.SizePref
+		default:
+			gd.AllocSize = gd.SizeNeed
+			sumPref += gd.SizeNeed
+		}
+		sumBase += gd.AllocSize
+	}
+	if sumPref <= avail { // room for auto tracks to have their pref
+		for i := range gds {
+			if tracks[i].Auto {
+				gds[i].AllocSize = gds[i].SizePref
+			}
+		}
+		sumBase = sumPref
+	}
+	extra := mat32.Max(avail-sumBase, 0)
+
+	if frTot > 0 {
+		// each fr track gets its fraction of the free space, but fr tracks
+		// that need more than that are frozen at their need
+		free := extra
+		for i := range gds {
+			if tracks[i].Fr > 0 {
+				free += gds[i].AllocSize // fr tracks share all the space they use
+			}
+		}
+		frozen := make([]bool, sz)
+		for iter := 0; iter <= sz; iter++ {
+			clamped := false
+			for i := range gds {
+				if tracks[i].Fr == 0 || frozen[i] {
+					continue
+				}
+				share := free * tracks[i].Fr / frTot
+				if share < gds[i].SizeNeed {
+					frozen[i] = true
+					free -= gds[i].SizeNeed
+					frTot -= tracks[i].Fr
+					clamped = true
+				}
+			}
+			if !clamped || frTot <= 0 {
+				break
+			}
+		}
+		for i := range gds {
+			if tracks[i].Fr > 0 && !frozen[i] {
+				gds[i].AllocSize = mat32.Max(free*tracks[i].Fr/frTot, gds[i].SizeNeed)
+			}
+		}
+		extra = 0
+	} else if extra > 0 {
+		nstretch := 0
+		for i := range gds {
+			if tracks[i].Auto && gds[i].SizeMax < 0 {
+				nstretch++
+			}
+		}
+		if nstretch > 0 {
+			for i := range gds {
+				if tracks[i].Auto && gds[i].SizeMax < 0 {
+					gds[i].AllocSize += extra / float32(nstretch)
+				}
+			}
+			extra = 0
+		}
+	}
+
+	pos := spc
+	between := float32(0)
+	switch {
+	case gist.IsAlignMiddle(al):
+		pos += 0.5 * extra
+	case gist.IsAlignEnd(al):
+		pos += extra
+	case al == gist.AlignJustify:
+		if sz > 1 {
+			between = extra / float32(sz-1)
+		}
+	case al == gist.AlignSpaceAround:
+		between = extra / float32(sz)
+		pos += 0.5 * between
+	}
+
+	for i := range gds {
+		gd := &gds[i]
+		gd.AllocPosRel = pos
+		if Layout2DTrace {
+			fmt.Printf("Grid Template %v: %v track: %v pos: %v, size: %v\n", rowcol, i, tracks[i], pos, gd.AllocSize)
+		}
+		pos += gd.AllocSize + ly.Spacing.Dots + between
+	}
+}
+
+// LayoutGridTemplate manages overall grid template layout of children
+func (ly *Layout) LayoutGridTemplate() {
+	if len(ly.Kids) == 0 {
+		return
+	}
+	if len(ly.GridPlaces) != len(ly.Kids) {
+		ly.GatherSizesGridTemplate()
+	}
+
+	ly.LayoutGridTemplateDim(Row, mat32.Y)
+	ly.LayoutGridTemplateDim(Col, mat32.X)
+
+	for i, c := range ly.Kids {
+		if c == nil {
+			continue
+		}
+		ni := c.(Node2D).AsWidget()
+		if ni == nil {
+			continue
+		}
+		pl := ly.GridPlaces[i]
+		ni.StyMu.RLock()
+		lst := ni.Sty.Layout
+		ni.StyMu.RUnlock()
+		for rc := Row; rc < RowColN; rc++ {
+			dim := mat32.Y
+			st, ed := pl.Min.Y, pl.Max.Y
+			if rc == Col {
+				dim = mat32.X
+				st, ed = pl.Min.X, pl.Max.X
+			}
+			gds := ly.GridData[rc]
+			cpos := gds[st].AllocPosRel
+			avail := gds[ed-1].AllocPosRel + gds[ed-1].AllocSize - cpos
+			al := lst.AlignDim(dim)
+			pref := ni.LayState.Size.Pref.Dim(dim)
+			need := ni.LayState.Size.Need.Dim(dim)
+			max := ni.LayState.Size.Max.Dim(dim)
+			pos, size := ly.LayoutSharedDimImpl(avail, need, pref, max, 0, al)
+			ni.LayState.Alloc.Size.SetDim(dim, size)
+			ni.LayState.Alloc.PosRel.SetDim(dim, pos+cpos)
+		}
+		if Layout2DTrace {
+			fmt.Printf("Layout: %v grid template cells: %v pos: %v size: %v\n", ly.PathUnique(), pl, ni.LayState.Alloc.PosRel, ni.LayState.Alloc.Size)
+		}
+	}
+}
@@ -20,12 +20,15 @@ func _() {
 	_ = x[LayoutVertFlow-4]
 	_ = x[LayoutStacked-5]
 	_ = x[LayoutNil-6]
-	_ = x[LayoutsN-7]
+	_ = x[LayoutFlexHoriz-7]
+	_ = x[LayoutFlexVert-8]
+	_ = x[LayoutGridTemplate-9]
+	_ = x[LayoutsN-10]
 }
 
-const _Layouts_name = "LayoutHorizLayoutVertLayoutGridLayoutHorizFlowLayoutVertFlowLayoutStackedLayoutNilLayoutsN"
+const _Layouts_name = "LayoutHorizLayoutVertLayoutGridLayoutHorizFlowLayoutVertFlowLayoutStackedLayoutNilLayoutFlexHorizLayoutFlexVertLayoutGridTemplateLayoutsN"
 
-var _Layouts_index = [...]uint8{0, 11, 21, 31, 46, 60, 73, 82, 90}
+var _Layouts_index = [...]uint8{0, 11, 21, 31, 46, 60, 73, 82, 97, 111, 129, 137}
 
 func (i Layouts) String() string {
 	if i < 0 || i >= Layouts(len(_Layouts_index)-1) {
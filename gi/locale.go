@@ -0,0 +1,439 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/goki/ki/kit"
+)
+
+// Localization of the user-facing strings in the built-in dialogs, menus,
+// and standard labels is done using message catalogs: a Locale maps each
+// message ID -- which is just the English text of the message, e.g., "Ok",
+// "Cancel", "Close Without Saving?" -- to its translation, with the plural
+// forms of the language for messages that depend on a count.  Any message
+// not found in the current locale is shown as its ID, so a partial catalog
+// is fine.  Apps can use T, Tf and TN for their own strings as well.
+//
+// Add catalogs with AddLocale or OpenLocaleJSON, and call SetLocale at the
+// start of the app, before any windows are opened: menus and dialogs are
+// translated as they are configured, so existing ones are not updated.
+
+// PluralForms are the CLDR plural categories, which select the form of a
+// message depending on a count -- each language uses a subset of these.
+type PluralForms int32
+
+const (
+	// PluralZero is used for 0 in e.g., Arabic
+	PluralZero PluralForms = iota
+
+	// PluralOne is used for 1 in most languages, and e.g., 21, 31 in Russian
+	PluralOne
+
+	// PluralTwo is used for 2 in e.g., Arabic
+	PluralTwo
+
+	// PluralFew is used for e.g., 2-4 in Czech, Polish and Russian
+	PluralFew
+
+	// PluralMany is used for e.g., 5-20 in Polish and Russian
+	PluralMany
+
+	// PluralOther is the general form, used for all counts in languages
+	// without plurals (e.g., Chinese, Japanese) -- every message has it
+	PluralOther
+
+	PluralFormsN
+)
+
+//go:generate stringer -type=PluralForms
+
+var KiT_PluralForms = kit.Enums.AddEnumAltLower(PluralFormsN, kit.NotBitFlag, nil, "Plural")
+
+func (ev PluralForms) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *PluralForms) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// Message is one translated message in a Locale, with the text for each of
+// the plural forms used by the language -- Other is used for messages
+// without a count, and for any other form that is empty.
+type Message struct {
+	ID    string `desc:"message ID, which is the English text of the message"`
+	Zero  string `desc:"text for the zero plural form"`
+	One   string `desc:"text for the one (singular) plural form"`
+	Two   string `desc:"text for the two (dual) plural form"`
+	Few   string `desc:"text for the few plural form"`
+	Many  string `desc:"text for the many plural form"`
+	Other string `desc:"text for the other plural form, and for messages without a count"`
+}
+
+// Form returns the text for given plural form, falling back on Other
+func (ms *Message) Form(pf PluralForms) string {
+	txt := ""
+	switch pf {
+	case PluralZero:
+		txt = ms.Zero
+	case PluralOne:
+		txt = ms.One
+	case PluralTwo:
+		txt = ms.Two
+	case PluralFew:
+		txt = ms.Few
+	case PluralMany:
+		txt = ms.Many
+	}
+	if txt == "" {
+		txt = ms.Other
+	}
+	return txt
+}
+
+// UnmarshalJSON reads a message either as a plain string, for messages
+// without plural forms, or as an object with fields named by the plural
+// forms: {"one": "%d fichier", "other": "%d fichiers"}
+func (ms *Message) UnmarshalJSON(b []byte) error {
+	var str string
+	if err := json.Unmarshal(b, &str); err == nil {
+		ms.Other = str
+		return nil
+	}
+	var fm map[string]string
+	if err := json.Unmarshal(b, &fm); err != nil {
+		return err
+	}
+	for k, v := range fm {
+		switch strings.ToLower(k) {
+		case "zero":
+			ms.Zero = v
+		case "one":
+			ms.One = v
+		case "two":
+			ms.Two = v
+		case "few":
+			ms.Few = v
+		case "many":
+			ms.Many = v
+		case "other":
+			ms.Other = v
+		default:
+			return fmt.Errorf("gi.Message: %v is not a plural form", k)
+		}
+	}
+	return nil
+}
+
+// PluralFunc returns the plural form to use for given count
+type PluralFunc func(n int) PluralForms
+
+// Locale is a message catalog for one language, identified by its BCP 47
+// tag (e.g., "fr", "pt-BR")
+type Locale struct {
+	Tag      string              `desc:"BCP 47 language tag, e.g., fr, pt-BR"`
+	Plural   PluralFunc          `json:"-" xml:"-" desc:"plural rule for the language -- if nil, PluralRule for the Tag is used"`
+	Messages map[string]*Message `desc:"translated messages, by ID"`
+}
+
+// NewLocale returns a new empty Locale for given tag, with the built-in
+// plural rule for its language
+func NewLocale(tag string) *Locale {
+	return &Locale{Tag: tag, Plural: PluralRule(tag), Messages: make(map[string]*Message)}
+}
+
+// Add adds a message without plural forms
+func (lc *Locale) Add(id, txt string) {
+	lc.Messages[id] = &Message{ID: id, Other: txt}
+}
+
+// AddMessage adds given message, under its ID
+func (lc *Locale) AddMessage(ms *Message) {
+	lc.Messages[ms.ID] = ms
+}
+
+// PluralForm returns the plural form of the language for given count
+func (lc *Locale) PluralForm(n int) PluralForms {
+	if lc.Plural == nil {
+		lc.Plural = PluralRule(lc.Tag)
+	}
+	return lc.Plural(n)
+}
+
+// T returns the translation of given message ID, or the ID itself if it is
+// not in the catalog
+func (lc *Locale) T(id string) string {
+	if ms, ok := lc.Messages[id]; ok && ms.Other != "" {
+		return ms.Other
+	}
+	return id
+}
+
+// TN returns the translation of given message ID in the plural form for
+// count n, or the ID itself if it is not in the catalog
+func (lc *Locale) TN(id string, n int) string {
+	if ms, ok := lc.Messages[id]; ok {
+		if txt := ms.Form(lc.PluralForm(n)); txt != "" {
+			return txt
+		}
+	}
+	return id
+}
+
+// OpenJSON opens messages from a JSON file, adding to any existing ones --
+// see LoadJSON for the format
+func (lc *Locale) OpenJSON(filename string) error {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	return lc.LoadJSON(b)
+}
+
+// LoadJSON loads messages from JSON bytes, adding to any existing ones:
+// an object mapping each message ID to either its translation, or to an
+// object with the translation for each plural form, e.g.:
+// {"Cancel": "Annuler", "%d files": {"one": "%d fichier", "other": "%d fichiers"}}
+func (lc *Locale) LoadJSON(b []byte) error {
+	var msgs map[string]*Message
+	if err := json.Unmarshal(b, &msgs); err != nil {
+		return err
+	}
+	if lc.Messages == nil {
+		lc.Messages = make(map[string]*Message, len(msgs))
+	}
+	for id, ms := range msgs {
+		ms.ID = id
+		lc.Messages[id] = ms
+	}
+	return nil
+}
+
+const (
+	// DefaultLocaleTag is the tag of the built-in messages, which are their IDs
+	DefaultLocaleTag = "en"
+)
+
+// Locales are the available message catalogs, by tag -- use AddLocale to add
+var Locales = map[string]*Locale{
+	DefaultLocaleTag: NewLocale(DefaultLocaleTag),
+}
+
+// CurLocale is the current locale used for translating messages, set by
+// SetLocale
+var CurLocale = Locales[DefaultLocaleTag]
+
+// AddLocale adds given locale to Locales, merging its messages into any
+// existing locale with the same tag
+func AddLocale(lc *Locale) {
+	if ex, ok := Locales[lc.Tag]; ok && ex != lc {
+		for id, ms := range lc.Messages {
+			ex.Messages[id] = ms
+		}
+		return
+	}
+	Locales[lc.Tag] = lc
+}
+
+// OpenLocaleJSON opens a message catalog for given tag from a JSON file
+// (see Locale.LoadJSON for the format), and adds it to Locales
+func OpenLocaleJSON(tag, filename string) error {
+	lc := NewLocale(tag)
+	if err := lc.OpenJSON(filename); err != nil {
+		return err
+	}
+	AddLocale(lc)
+	return nil
+}
+
+// SetLocale sets the current locale to the one in Locales for given tag
+// -- if not found, the language alone is tried, e.g., "fr" for "fr-CA".  An
+// empty tag uses the OS environment (LC_ALL, LC_MESSAGES, LANG).  Returns
+// an error, and sets the default locale, if there is no catalog for it.
+func SetLocale(tag string) error {
+	if tag == "" {
+		tag = EnvLocaleTag()
+	}
+	tag = strings.Replace(tag, "_", "-", -1)
+	if lc, ok := Locales[tag]; ok {
+		CurLocale = lc
+		return nil
+	}
+	lang := LocaleLang(tag)
+	for t, lc := range Locales {
+		if strings.EqualFold(t, tag) || strings.EqualFold(t, lang) {
+			CurLocale = lc
+			return nil
+		}
+	}
+	CurLocale = Locales[DefaultLocaleTag]
+	if lang == DefaultLocaleTag {
+		return nil
+	}
+	return fmt.Errorf("gi.SetLocale: no message catalog for locale: %v", tag)
+}
+
+// EnvLocaleTag returns the locale tag from the OS environment variables
+// LC_ALL, LC_MESSAGES or LANG (e.g., "fr_CA.UTF-8" -> "fr-CA") -- returns
+// DefaultLocaleTag if not set or "C" / "POSIX"
+func EnvLocaleTag() string {
+	for _, ev := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		val := os.Getenv(ev)
+		if val == "" {
+			continue
+		}
+		if ci := strings.IndexAny(val, ".@"); ci >= 0 {
+			val = val[:ci]
+		}
+		if val == "" || val == "C" || val == "POSIX" {
+			return DefaultLocaleTag
+		}
+		return strings.Replace(val, "_", "-", -1)
+	}
+	return DefaultLocaleTag
+}
+
+// LocaleLang returns the lower-case language part of given tag, e.g.,
+// "pt" for "pt-BR"
+func LocaleLang(tag string) string {
+	if ci := strings.IndexAny(tag, "-_"); ci >= 0 {
+		tag = tag[:ci]
+	}
+	return strings.ToLower(tag)
+}
+
+// T returns the translation of given message ID in the current locale, or
+// the ID itself if there is none.  The ID is the English text.
+func T(id string) string {
+	if CurLocale == nil || id == "" {
+		return id
+	}
+	return CurLocale.T(id)
+}
+
+// Tf returns the translation of given message ID, formatted with the
+// given args using fmt.Sprintf
+func Tf(id string, args ...interface{}) string {
+	return fmt.Sprintf(T(id), args...)
+}
+
+// TN returns the translation of given message ID in the plural form for
+// count n in the current locale, formatted with the given args using
+// fmt.Sprintf (typically including n).  If there is no translation, the
+// English plural rule is applied to the ID, which can give both forms
+// separated by a | e.g., "%d file|%d files".
+func TN(id string, n int, args ...interface{}) string {
+	txt := id
+	if CurLocale != nil {
+		txt = CurLocale.TN(id, n)
+	}
+	if txt == id {
+		if bi := strings.Index(id, "|"); bi >= 0 {
+			if n == 1 {
+				txt = id[:bi]
+			} else {
+				txt = id[bi+1:]
+			}
+		}
+	}
+	if len(args) == 0 {
+		return txt
+	}
+	return fmt.Sprintf(txt, args...)
+}
+
+// PluralRule returns the built-in plural rule for the language of given
+// tag -- the English rule (one for 1, other otherwise) is used for
+// languages not otherwise listed.
+func PluralRule(tag string) PluralFunc {
+	switch LocaleLang(tag) {
+	case "ja", "zh", "ko", "vi", "th", "id", "ms", "tr":
+		return pluralNone
+	case "fr", "hy", "kab":
+		return pluralFrench
+	case "pt":
+		if strings.EqualFold(tag, "pt-PT") {
+			return pluralEnglish
+		}
+		return pluralFrench
+	case "ru", "uk", "be", "sr", "hr", "bs":
+		return pluralRussian
+	case "pl":
+		return pluralPolish
+	case "cs", "sk":
+		return pluralCzech
+	case "ar":
+		return pluralArabic
+	}
+	return pluralEnglish
+}
+
+func pluralNone(n int) PluralForms {
+	return PluralOther
+}
+
+func pluralEnglish(n int) PluralForms {
+	if n == 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+func pluralFrench(n int) PluralForms {
+	if n == 0 || n == 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+func pluralRussian(n int) PluralForms {
+	n10, n100 := n%10, n%100
+	switch {
+	case n10 == 1 && n100 != 11:
+		return PluralOne
+	case n10 >= 2 && n10 <= 4 && (n100 < 12 || n100 > 14):
+		return PluralFew
+	}
+	return PluralMany
+}
+
+func pluralPolish(n int) PluralForms {
+	n10, n100 := n%10, n%100
+	switch {
+	case n == 1:
+		return PluralOne
+	case n10 >= 2 && n10 <= 4 && (n100 < 12 || n100 > 14):
+		return PluralFew
+	}
+	return PluralMany
+}
+
+func pluralCzech(n int) PluralForms {
+	switch {
+	case n == 1:
+		return PluralOne
+	case n >= 2 && n <= 4:
+		return PluralFew
+	}
+	return PluralOther
+}
+
+func pluralArabic(n int) PluralForms {
+	n100 := n % 100
+	switch {
+	case n == 0:
+		return PluralZero
+	case n == 1:
+		return PluralOne
+	case n == 2:
+		return PluralTwo
+	case n100 >= 3 && n100 <= 10:
+		return PluralFew
+	case n100 >= 11:
+		return PluralMany
+	}
+	return PluralOther
+}
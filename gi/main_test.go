@@ -0,0 +1,50 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi_test
+
+import (
+	"image"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/goki/gi/gi"
+	_ "github.com/goki/gi/giv" // sets gi.TheViewIFace
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/driver/offscreen"
+	_ "github.com/goki/gi/svg" // sets gi.TheIconMgr
+)
+
+// TestMain runs the tests within the offscreen driver, so they can open
+// windows without a display
+func TestMain(m *testing.M) {
+	code := 0
+	offscreen.Main(func(app oswin.App) {
+		code = m.Run()
+	})
+	os.Exit(code)
+}
+
+// newTestWindow returns a new offscreen window, with the widgets added to
+// its main frame by given config function, once it has been rendered and
+// has the focus, so it gets input events
+func newTestWindow(name string, config func(mfr *gi.Frame)) *gi.Window {
+	win := gi.NewMainWindow(name, name, 400, 300)
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+	mfr := win.SetMainFrame()
+	config(mfr)
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+	win.OSWin.Raise()
+	waitIdle(win)
+	return win
+}
+
+// waitIdle waits until given window has stopped updating, and returns its
+// last frame
+func waitIdle(win *gi.Window) *image.RGBA {
+	return offscreen.WaitIdle(win.OSWin, 200*time.Millisecond, 5*time.Second)
+}
@@ -0,0 +1,453 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// media contains the decoding of animated images and video frames, for
+// playback in a MediaView
+
+// MediaDecoder decodes the frames of an animated image or video for playback
+// in a MediaView.  Decoders for the animated GIF and PNG (APNG) formats are
+// built in, and others, e.g., for video using external libraries, can be
+// added with AddMediaDecoder.  Frames are retrieved by time, so decoders can
+// decode on demand, seeking as needed.
+type MediaDecoder interface {
+	// Size returns the size of the frames
+	Size() image.Point
+
+	// Duration returns the total duration of the media -- 0 for a still image
+	Duration() time.Duration
+
+	// NFrames returns the number of frames, or 0 if not known
+	NFrames() int
+
+	// FrameStart returns the time at which the frame of given index starts
+	FrameStart(idx int) time.Duration
+
+	// FrameAt returns the index and image of the frame to show at given
+	// time from the start (clamped to the duration) -- the image may be
+	// re-used by the decoder for subsequent frames, so it must be copied
+	// to retain it.
+	FrameAt(pos time.Duration) (int, image.Image, error)
+
+	// NPlays returns the number of times the media is meant to be played,
+	// 0 = repeat forever
+	NPlays() int
+
+	// Close releases any resources held by the decoder
+	Close() error
+}
+
+// MediaDecoderFunc returns a MediaDecoder for the media in given file
+type MediaDecoderFunc func(filename string) (MediaDecoder, error)
+
+// MediaDecoders are the functions to open media files, by lower-case file
+// extension including the dot -- use AddMediaDecoder to add to it
+var MediaDecoders = map[string]MediaDecoderFunc{
+	".gif":  OpenGIFMedia,
+	".png":  OpenAPNGMedia,
+	".apng": OpenAPNGMedia,
+}
+
+// AddMediaDecoder adds a function to open media files with given file
+// extension (e.g., ".mp4"), replacing any existing one
+func AddMediaDecoder(ext string, fun MediaDecoderFunc) {
+	MediaDecoders[strings.ToLower(ext)] = fun
+}
+
+// OpenMedia opens given media file, using the MediaDecoders function for
+// its file extension
+func OpenMedia(filename string) (MediaDecoder, error) {
+	ext := strings.ToLower(filepath.Ext(filename))
+	fun, ok := MediaDecoders[ext]
+	if !ok {
+		return nil, fmt.Errorf("gi.OpenMedia: no decoder for files of type: %v, file: %v", ext, filename)
+	}
+	return fun(filename)
+}
+
+// MediaMinFrameDelay is the shortest delay between frames of animated
+// images -- shorter ones are set to MediaDefFrameDelay, as web browsers do,
+// because many images rely on that
+var MediaMinFrameDelay = 20 * time.Millisecond
+
+// MediaDefFrameDelay is the delay used for frames of animated images whose
+// delay is less than MediaMinFrameDelay
+var MediaDefFrameDelay = 100 * time.Millisecond
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  MediaFrames
+
+// MediaFrames is a MediaDecoder with all of the frames decoded in memory,
+// as is done for animated images
+type MediaFrames struct {
+	Frames []*image.RGBA   `desc:"the fully composited frames"`
+	Delays []time.Duration `desc:"how long each frame is shown"`
+	Plays  int             `desc:"number of times to play, 0 = repeat forever"`
+	Starts []time.Duration `desc:"start time of each frame -- computed by SetFrames"`
+}
+
+// NewMediaFrames returns new MediaFrames with given frames, delays, and
+// number of plays (0 = repeat forever)
+func NewMediaFrames(frames []*image.RGBA, delays []time.Duration, plays int) *MediaFrames {
+	mf := &MediaFrames{Plays: plays}
+	mf.SetFrames(frames, delays)
+	return mf
+}
+
+// SetFrames sets the frames and their delays, and computes their start times
+func (mf *MediaFrames) SetFrames(frames []*image.RGBA, delays []time.Duration) {
+	mf.Frames = frames
+	mf.Delays = delays
+	mf.Starts = make([]time.Duration, len(frames))
+	var st time.Duration
+	for i := range frames {
+		mf.Starts[i] = st
+		if i < len(delays) {
+			st += delays[i]
+		}
+	}
+}
+
+func (mf *MediaFrames) Size() image.Point {
+	if len(mf.Frames) == 0 {
+		return image.Point{}
+	}
+	return mf.Frames[0].Bounds().Size()
+}
+
+func (mf *MediaFrames) Duration() time.Duration {
+	n := len(mf.Frames)
+	if n < 2 {
+		return 0
+	}
+	return mf.Starts[n-1] + mf.Delays[n-1]
+}
+
+func (mf *MediaFrames) NFrames() int {
+	return len(mf.Frames)
+}
+
+func (mf *MediaFrames) FrameStart(idx int) time.Duration {
+	if idx < 0 || idx >= len(mf.Starts) {
+		return 0
+	}
+	return mf.Starts[idx]
+}
+
+func (mf *MediaFrames) FrameAt(pos time.Duration) (int, image.Image, error) {
+	n := len(mf.Frames)
+	if n == 0 {
+		return -1, nil, errors.New("gi.MediaFrames: no frames")
+	}
+	idx := sort.Search(n, func(i int) bool { return mf.Starts[i] > pos }) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return idx, mf.Frames[idx], nil
+}
+
+func (mf *MediaFrames) NPlays() int {
+	return mf.Plays
+}
+
+func (mf *MediaFrames) Close() error {
+	return nil
+}
+
+// frameDelay returns the delay for a frame of an animated image, applying
+// MediaMinFrameDelay
+func frameDelay(d time.Duration) time.Duration {
+	if d < MediaMinFrameDelay {
+		return MediaDefFrameDelay
+	}
+	return d
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  GIF
+
+// OpenGIFMedia opens an animated GIF file for playback in a MediaView
+func OpenGIFMedia(filename string) (MediaDecoder, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return DecodeGIFFrames(f)
+}
+
+// DecodeGIFFrames decodes all of the frames of an animated GIF, compositing
+// each one according to the disposal of the previous frames
+func DecodeGIFFrames(r io.Reader) (*MediaFrames, error) {
+	g, err := gif.DecodeAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(g.Image) == 0 {
+		return nil, errors.New("gi.DecodeGIFFrames: no frames in image")
+	}
+	bounds := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	if bounds.Empty() {
+		for _, fr := range g.Image {
+			bounds = bounds.Union(fr.Bounds())
+		}
+	}
+	canvas := image.NewRGBA(bounds)
+	frames := make([]*image.RGBA, len(g.Image))
+	delays := make([]time.Duration, len(g.Image))
+	var prev *image.RGBA
+	for i, fr := range g.Image {
+		disp := byte(gif.DisposalNone)
+		if i < len(g.Disposal) {
+			disp = g.Disposal[i]
+		}
+		if disp == gif.DisposalPrevious {
+			prev = cloneRGBA(canvas)
+		}
+		draw.Draw(canvas, fr.Bounds(), fr, fr.Bounds().Min, draw.Over)
+		frames[i] = cloneRGBA(canvas)
+		if i < len(g.Delay) {
+			delays[i] = frameDelay(time.Duration(g.Delay[i]) * 10 * time.Millisecond)
+		} else {
+			delays[i] = MediaDefFrameDelay
+		}
+		switch disp {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, fr.Bounds(), image.Transparent, image.ZP, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = prev
+		}
+	}
+	plays := 0 // LoopCount 0 = forever, -1 = once, n = n+1 times
+	switch {
+	case g.LoopCount < 0:
+		plays = 1
+	case g.LoopCount > 0:
+		plays = g.LoopCount + 1
+	}
+	return NewMediaFrames(frames, delays, plays), nil
+}
+
+// cloneRGBA returns a copy of given image
+func cloneRGBA(img *image.RGBA) *image.RGBA {
+	cp := image.NewRGBA(img.Bounds())
+	copy(cp.Pix, img.Pix)
+	return cp
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  APNG
+
+// OpenAPNGMedia opens an animated PNG (APNG) file for playback in a
+// MediaView -- a regular PNG file is shown as a still image
+func OpenAPNGMedia(filename string) (MediaDecoder, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return DecodeAPNGFrames(f)
+}
+
+// pngSig is the signature at the start of all PNG files
+var pngSig = []byte("\x89PNG\r\n\x1a\n")
+
+// apngFrame is the frame control info and image data of an APNG frame
+type apngFrame struct {
+	width, height, x, y int
+	delayNum, delayDen  uint16
+	dispose, blend      byte
+	data                [][]byte
+}
+
+// APNG frame dispose and blend ops
+const (
+	apngDisposeNone       = 0
+	apngDisposeBackground = 1
+	apngDisposePrevious   = 2
+	apngBlendSource       = 0
+)
+
+// DecodeAPNGFrames decodes all of the frames of an animated PNG (APNG),
+// compositing each one according to its blend and dispose ops.  A regular
+// PNG file results in a single frame.
+func DecodeAPNGFrames(r io.Reader) (*MediaFrames, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(b, pngSig) {
+		return nil, errors.New("gi.DecodeAPNGFrames: not a PNG file")
+	}
+	var ihdr []byte
+	var shared [][]byte // raw chunks needed to decode each frame, e.g., PLTE, tRNS
+	var frames []*apngFrame
+	var cur *apngFrame
+	animated := false
+	plays := 0
+	seenIDAT := false
+	for p := len(pngSig); p+12 <= len(b); {
+		n := int(binary.BigEndian.Uint32(b[p:]))
+		if n < 0 || p+12+n > len(b) {
+			return nil, errors.New("gi.DecodeAPNGFrames: truncated chunk")
+		}
+		typ := string(b[p+4 : p+8])
+		data := b[p+8 : p+8+n]
+		raw := b[p : p+12+n]
+		p += 12 + n
+		switch typ {
+		case "IHDR":
+			if n != 13 {
+				return nil, errors.New("gi.DecodeAPNGFrames: invalid IHDR chunk")
+			}
+			ihdr = data
+		case "acTL":
+			if n != 8 {
+				return nil, errors.New("gi.DecodeAPNGFrames: invalid acTL chunk")
+			}
+			animated = true
+			plays = int(binary.BigEndian.Uint32(data[4:]))
+		case "fcTL":
+			if n != 26 {
+				return nil, errors.New("gi.DecodeAPNGFrames: invalid fcTL chunk")
+			}
+			cur = &apngFrame{
+				width:    int(binary.BigEndian.Uint32(data[4:])),
+				height:   int(binary.BigEndian.Uint32(data[8:])),
+				x:        int(binary.BigEndian.Uint32(data[12:])),
+				y:        int(binary.BigEndian.Uint32(data[16:])),
+				delayNum: binary.BigEndian.Uint16(data[20:]),
+				delayDen: binary.BigEndian.Uint16(data[22:]),
+				dispose:  data[24],
+				blend:    data[25],
+			}
+			frames = append(frames, cur)
+		case "IDAT":
+			seenIDAT = true
+			if cur != nil { // default image is the first frame
+				cur.data = append(cur.data, data)
+			}
+		case "fdAT":
+			if cur != nil && n > 4 {
+				cur.data = append(cur.data, data[4:])
+			}
+		case "IEND":
+			p = len(b)
+		default:
+			if !seenIDAT {
+				shared = append(shared, raw)
+			}
+		}
+	}
+	if ihdr == nil {
+		return nil, errors.New("gi.DecodeAPNGFrames: missing IHDR chunk")
+	}
+	if !animated || len(frames) == 0 {
+		img, err := png.Decode(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		return NewMediaFrames([]*image.RGBA{ImageToRGBA(img)}, []time.Duration{0}, 1), nil
+	}
+	width := int(binary.BigEndian.Uint32(ihdr))
+	height := int(binary.BigEndian.Uint32(ihdr[4:]))
+	canvas := image.NewRGBA(image.Rect(0, 0, width, height))
+	var imgs []*image.RGBA
+	var delays []time.Duration
+	for i, fr := range frames {
+		if len(fr.data) == 0 {
+			continue
+		}
+		r := image.Rect(fr.x, fr.y, fr.x+fr.width, fr.y+fr.height)
+		if r.Empty() || !r.In(canvas.Bounds()) {
+			return nil, fmt.Errorf("gi.DecodeAPNGFrames: frame %d region: %v outside of image", i, r)
+		}
+		img, err := png.Decode(bytes.NewReader(apngFramePNG(ihdr, shared, fr)))
+		if err != nil {
+			return nil, fmt.Errorf("gi.DecodeAPNGFrames: frame %d: %v", i, err)
+		}
+		disp := fr.dispose
+		if disp == apngDisposePrevious && len(imgs) == 0 {
+			disp = apngDisposeBackground
+		}
+		var prev *image.RGBA
+		if disp == apngDisposePrevious {
+			prev = cloneRGBA(canvas)
+		}
+		op := draw.Over
+		if fr.blend == apngBlendSource {
+			op = draw.Src
+		}
+		draw.Draw(canvas, r, img, img.Bounds().Min, op)
+		imgs = append(imgs, cloneRGBA(canvas))
+		den := time.Duration(fr.delayDen)
+		if den == 0 {
+			den = 100
+		}
+		delays = append(delays, frameDelay(time.Duration(fr.delayNum)*time.Second/den))
+		switch disp {
+		case apngDisposeBackground:
+			draw.Draw(canvas, r, image.Transparent, image.ZP, draw.Src)
+		case apngDisposePrevious:
+			canvas = prev
+		}
+	}
+	if len(imgs) == 0 {
+		return nil, errors.New("gi.DecodeAPNGFrames: no frames in image")
+	}
+	return NewMediaFrames(imgs, delays, plays), nil
+}
+
+// apngFramePNG returns a PNG file with the image of given frame, which has
+// the IHDR of the full image with the size of the frame, and given shared
+// chunks (palette etc)
+func apngFramePNG(ihdr []byte, shared [][]byte, fr *apngFrame) []byte {
+	var buf bytes.Buffer
+	buf.Write(pngSig)
+	hdr := make([]byte, len(ihdr))
+	copy(hdr, ihdr)
+	binary.BigEndian.PutUint32(hdr, uint32(fr.width))
+	binary.BigEndian.PutUint32(hdr[4:], uint32(fr.height))
+	writePNGChunk(&buf, "IHDR", hdr)
+	for _, ch := range shared {
+		buf.Write(ch)
+	}
+	writePNGChunk(&buf, "IDAT", bytes.Join(fr.data, nil))
+	writePNGChunk(&buf, "IEND", nil)
+	return buf.Bytes()
+}
+
+// writePNGChunk writes a PNG chunk of given type and data to given buffer
+func writePNGChunk(buf *bytes.Buffer, typ string, data []byte) {
+	var b4 [4]byte
+	binary.BigEndian.PutUint32(b4[:], uint32(len(data)))
+	buf.Write(b4[:])
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(typ))
+	crc.Write(data)
+	buf.WriteString(typ)
+	buf.Write(data)
+	binary.BigEndian.PutUint32(b4[:], crc.Sum32())
+	buf.Write(b4[:])
+}
@@ -0,0 +1,460 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  MediaView
+
+// MediaView plays animated images (GIF, APNG) and video, decoded by a
+// MediaDecoder (see AddMediaDecoder for adding decoders for other formats),
+// showing the frames in a Bitmap, with controls below it to play / pause
+// and seek.  Playback is driven by the Animator of the window, so the frames
+// are updated in the event loop, in sync with rendering.  The MediaViewSig
+// signal is emitted for each new frame shown, and for changes in playback.
+type MediaView struct {
+	Frame
+	Filename     FileName      `desc:"file name of the media -- set by OpenMedia"`
+	Decoder      MediaDecoder  `copy:"-" json:"-" xml:"-" view:"-" desc:"the decoder of the frames of the media"`
+	Pos          time.Duration `inactive:"+" desc:"current playback position, from the start"`
+	FrameIdx     int           `inactive:"+" desc:"index of the frame being shown, -1 if none"`
+	Playing      bool          `inactive:"+" desc:"media is playing"`
+	Loop         bool          `desc:"repeat playing from the start when the end is reached -- set by SetDecoder according to the media (e.g., the loop count of a GIF)"`
+	Speed        float32       `desc:"playback speed multiplier -- 0 = 1 = normal speed"`
+	AutoPlay     bool          `xml:"auto-play" desc:"prop = auto-play -- start playing when the media is first shown"`
+	NoControls   bool          `xml:"no-controls" desc:"prop = no-controls -- do not show the play / pause and seek controls"`
+	MediaViewSig ki.Signal     `copy:"-" json:"-" xml:"-" view:"-" desc:"signal for media view -- see MediaViewSignals for the types"`
+	anim         *Animation    // animation that drives playback
+	playStart    time.Time     // time at which playback started from playFrom
+	playFrom     time.Duration // position at which playback started
+	autoPlayed   bool          // AutoPlay has been done for the current media
+}
+
+var KiT_MediaView = kit.Types.AddType(&MediaView{}, MediaViewProps)
+
+// AddNewMediaView adds a new media view to given parent node, with given name.
+func AddNewMediaView(parent ki.Ki, name string) *MediaView {
+	return parent.AddNewChild(KiT_MediaView, name).(*MediaView)
+}
+
+func (mv *MediaView) CopyFieldsFrom(frm interface{}) {
+	fr := frm.(*MediaView)
+	mv.Frame.CopyFieldsFrom(&fr.Frame)
+	mv.Filename = fr.Filename
+	mv.Loop = fr.Loop
+	mv.Speed = fr.Speed
+	mv.AutoPlay = fr.AutoPlay
+	mv.NoControls = fr.NoControls
+}
+
+func (mv *MediaView) Disconnect() {
+	mv.Pause()
+	mv.Frame.Disconnect()
+	mv.MediaViewSig.DisconnectAll()
+}
+
+// AccessInfo describes the media view for assistive technologies
+func (mv *MediaView) AccessInfo(an *oswin.AccessNode) {
+	an.Role = oswin.RoleImage
+	an.Value = mv.TimeString()
+}
+
+// MediaViewSignals are signals that the MediaView can send
+type MediaViewSignals int64
+
+const (
+	// MediaViewFrame indicates that a new frame is being shown -- data is
+	// the index of the frame, and the image is in the Bitmap
+	MediaViewFrame MediaViewSignals = iota
+
+	// MediaViewPlaying indicates that playback has started -- data is the
+	// position
+	MediaViewPlaying
+
+	// MediaViewPaused indicates that playback was paused -- data is the
+	// position
+	MediaViewPaused
+
+	// MediaViewEnded indicates that playback reached the end, without
+	// looping -- data is the position
+	MediaViewEnded
+
+	MediaViewSignalsN
+)
+
+//go:generate stringer -type=MediaViewSignals
+
+var MediaViewProps = ki.Props{
+	"EnumType:Flag":    KiT_NodeFlags,
+	"background-color": &Prefs.Colors.Background,
+	"color":            &Prefs.Colors.Font,
+	"border-width":     units.NewPx(0),
+	"margin":           units.NewPx(0),
+	"padding":          units.NewPx(2),
+	"#controls": ki.Props{
+		"vertical-align": gist.AlignMiddle,
+		"margin":         units.NewPx(0),
+		"padding":        units.NewPx(2),
+		"spacing":        units.NewPx(4),
+	},
+	"#time": ki.Props{
+		"vertical-align": gist.AlignMiddle,
+		"white-space":    gist.WhiteSpaceNowrap,
+	},
+	"ToolBar": ki.PropSlice{
+		{"OpenMedia", ki.Props{
+			"desc": "Open an animated image or video",
+			"icon": "file-open",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"default-field": "Filename",
+					"ext":           ".gif,.png,.apng",
+				}},
+			},
+		}},
+		{"sep-play", ki.BlankProp{}},
+		{"Play", ki.Props{
+			"icon": "play",
+		}},
+		{"Pause", ki.Props{
+			"icon": "stop",
+		}},
+	},
+}
+
+// Bitmap returns the bitmap that shows the frames
+func (mv *MediaView) Bitmap() *Bitmap {
+	mv.InitMediaView()
+	return mv.Child(0).(*Bitmap)
+}
+
+// Controls returns the layout of the play / pause and seek controls
+func (mv *MediaView) Controls() *Layout {
+	mv.InitMediaView()
+	return mv.Child(1).(*Layout)
+}
+
+// InitMediaView creates the bitmap and controls, if not yet done
+func (mv *MediaView) InitMediaView() {
+	if len(mv.Kids) != 0 {
+		return
+	}
+	updt := mv.UpdateStart()
+	mv.Lay = LayoutVert
+	mv.FrameIdx = -1
+	AddNewBitmap(mv, "image")
+	ctrls := AddNewLayout(mv, "controls", LayoutHoriz)
+	ctrls.SetStretchMaxWidth()
+
+	play := AddNewAction(ctrls, "play")
+	play.SetIcon("play")
+	play.Tooltip = T("Play")
+	play.ActionSig.ConnectOnly(mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		mvv := recv.Embed(KiT_MediaView).(*MediaView)
+		mvv.TogglePlay()
+	})
+
+	seek := AddNewSlider(ctrls, "seek")
+	seek.Defaults()
+	seek.Tracking = true
+	seek.SetStretchMaxWidth()
+	seek.SetMinPrefWidth(units.NewEm(10))
+	seek.SliderSig.ConnectOnly(mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig != int64(SliderValueChanged) {
+			return
+		}
+		mvv := recv.Embed(KiT_MediaView).(*MediaView)
+		mvv.Seek(time.Duration(float64(data.(float32)) * float64(time.Second)))
+	})
+
+	AddNewLabel(ctrls, "time", "")
+	mv.UpdateEnd(updt)
+}
+
+// OpenMedia opens given media file for playback, using the MediaDecoders
+// function for its file extension
+func (mv *MediaView) OpenMedia(filename FileName) error {
+	dec, err := OpenMedia(string(filename))
+	if err != nil {
+		log.Printf("gi.MediaView.OpenMedia -- could not open file: %v, err: %v\n", filename, err)
+		return err
+	}
+	mv.Filename = filename
+	mv.SetDecoder(dec)
+	return nil
+}
+
+// SetDecoder sets the decoder of the media to play, e.g., for a decoder of
+// media that is not in a file.  Any playback is stopped, the previous
+// decoder is closed, and the first frame is shown.
+func (mv *MediaView) SetDecoder(dec MediaDecoder) {
+	mv.Pause()
+	if mv.Decoder != nil && mv.Decoder != dec {
+		mv.Decoder.Close()
+	}
+	updt := mv.UpdateStart()
+	mv.Decoder = dec
+	mv.Pos = 0
+	mv.FrameIdx = -1
+	mv.Loop = dec.NPlays() != 1
+	mv.autoPlayed = false
+	bm := mv.Bitmap()
+	bm.Resize(dec.Size())
+	bm.LayoutToImgSize()
+	mv.ShowFrame()
+	mv.SetFullReRender()
+	mv.UpdateEnd(updt)
+}
+
+// Duration returns the duration of the media, 0 if none
+func (mv *MediaView) Duration() time.Duration {
+	if mv.Decoder == nil {
+		return 0
+	}
+	return mv.Decoder.Duration()
+}
+
+// FormatMediaTime returns given time as minutes and seconds, with tenths
+func FormatMediaTime(d time.Duration) string {
+	d = d.Round(100 * time.Millisecond)
+	m := d / time.Minute
+	s := float64(d-m*time.Minute) / float64(time.Second)
+	return fmt.Sprintf("%d:%04.1f", m, s)
+}
+
+// TimeString returns the current position and duration, as shown
+func (mv *MediaView) TimeString() string {
+	return FormatMediaTime(mv.Pos) + " / " + FormatMediaTime(mv.Duration())
+}
+
+// ShowFrame shows the frame at the current position, if it is not already
+// shown, emitting the MediaViewFrame signal
+func (mv *MediaView) ShowFrame() {
+	if mv.Decoder == nil {
+		return
+	}
+	idx, img, err := mv.Decoder.FrameAt(mv.Pos)
+	if err != nil {
+		log.Printf("gi.MediaView.ShowFrame: %v\n", err)
+		return
+	}
+	if idx == mv.FrameIdx {
+		return
+	}
+	updt := mv.UpdateStart()
+	mv.FrameIdx = idx
+	mv.Bitmap().SetImage(img, 0, 0)
+	mv.UpdateControls()
+	mv.UpdateEnd(updt)
+	mv.MediaViewSig.Emit(mv.This(), int64(MediaViewFrame), idx)
+}
+
+// UpdateControls updates the controls to the current playback state
+func (mv *MediaView) UpdateControls() {
+	ctrls := mv.Controls()
+	ctrls.SetInvisibleState(mv.NoControls)
+	if play, ok := ctrls.ChildByName("play", 0).(*Action); ok {
+		if mv.Playing {
+			play.SetIcon("stop")
+			play.Tooltip = T("Pause")
+		} else {
+			play.SetIcon("play")
+			play.Tooltip = T("Play")
+		}
+		play.SetActiveState(mv.Duration() > 0)
+	}
+	if seek, ok := ctrls.ChildByName("seek", 1).(*Slider); ok {
+		seek.Max = float32(mv.Duration().Seconds())
+		if seek.Max <= 0 {
+			seek.Max = 1
+		}
+		seek.SetValue(float32(mv.Pos.Seconds()))
+		seek.SetActiveState(mv.Duration() > 0)
+	}
+	if tm, ok := ctrls.ChildByName("time", 2).(*Label); ok {
+		tm.SetText(mv.TimeString())
+	}
+}
+
+// Play starts playing the media from the current position, or from the
+// start if it is at the end -- the MediaView must be in a window
+func (mv *MediaView) Play() {
+	dur := mv.Duration()
+	if mv.Playing || dur == 0 {
+		return
+	}
+	win := mv.ParentWindow()
+	if win == nil {
+		return
+	}
+	if mv.Pos >= dur {
+		mv.Pos = 0
+	}
+	mv.Playing = true
+	mv.playFrom = mv.Pos
+	tw := TweenFunc(nil, time.Duration(1<<62), func(t float32) {
+		mv.PlayTick()
+	})
+	tw.Begin = func() {
+		mv.playStart = time.Now()
+	}
+	mv.anim = NewAnimation(tw).Start(win)
+	updt := mv.UpdateStart()
+	mv.UpdateControls()
+	mv.UpdateEnd(updt)
+	mv.MediaViewSig.Emit(mv.This(), int64(MediaViewPlaying), mv.Pos)
+}
+
+// Pause stops playing the media, staying at the current position
+func (mv *MediaView) Pause() {
+	if !mv.stop() {
+		return
+	}
+	mv.MediaViewSig.Emit(mv.This(), int64(MediaViewPaused), mv.Pos)
+}
+
+// TogglePlay pauses if playing, and plays otherwise
+func (mv *MediaView) TogglePlay() {
+	if mv.Playing {
+		mv.Pause()
+	} else {
+		mv.Play()
+	}
+}
+
+// stop stops the animation that drives playback, returning false if
+// not playing
+func (mv *MediaView) stop() bool {
+	if !mv.Playing {
+		return false
+	}
+	mv.Playing = false
+	if mv.anim != nil {
+		mv.anim.Cancel()
+		mv.anim = nil
+	}
+	if mv.This() != nil && !mv.IsDeleted() && !mv.IsDestroyed() {
+		updt := mv.UpdateStart()
+		mv.UpdateControls()
+		mv.UpdateEnd(updt)
+	}
+	return true
+}
+
+// Seek sets the playback position to given time from the start, showing the
+// frame at that time -- playback continues from there if playing
+func (mv *MediaView) Seek(pos time.Duration) {
+	dur := mv.Duration()
+	if pos > dur {
+		pos = dur
+	}
+	if pos < 0 {
+		pos = 0
+	}
+	mv.Pos = pos
+	if mv.Playing {
+		mv.playFrom = pos
+		mv.playStart = time.Now()
+	}
+	updt := mv.UpdateStart()
+	mv.ShowFrame()
+	mv.UpdateControls()
+	mv.UpdateEnd(updt)
+}
+
+// StepFrame pauses, and shows the frame given number of frames (+ or -)
+// from the current one
+func (mv *MediaView) StepFrame(steps int) {
+	if mv.Decoder == nil {
+		return
+	}
+	mv.Pause()
+	idx := mv.FrameIdx + steps
+	if nf := mv.Decoder.NFrames(); nf > 0 && idx >= nf {
+		idx = nf - 1
+	}
+	if idx < 0 {
+		idx = 0
+	}
+	mv.Seek(mv.Decoder.FrameStart(idx))
+}
+
+// PlayTick updates the position according to the time since playback
+// started, and shows the frame for it -- called by the animation that drives
+// playback, in the event loop of the window
+func (mv *MediaView) PlayTick() {
+	if !mv.Playing || mv.This() == nil || mv.IsDeleted() || mv.IsDestroyed() {
+		mv.stop()
+		return
+	}
+	dur := mv.Duration()
+	speed := mv.Speed
+	if speed <= 0 {
+		speed = 1
+	}
+	pos := mv.playFrom + time.Duration(float64(time.Since(mv.playStart))*float64(speed))
+	ended := false
+	if pos >= dur {
+		if mv.Loop && dur > 0 {
+			pos %= dur
+		} else {
+			pos = dur
+			ended = true
+		}
+	}
+	mv.Pos = pos
+	updt := mv.UpdateStart()
+	mv.ShowFrame()
+	if ended {
+		mv.stop()
+	}
+	mv.UpdateEnd(updt)
+	if ended {
+		mv.MediaViewSig.Emit(mv.This(), int64(MediaViewEnded), mv.Pos)
+	}
+}
+
+// StyleFromProps styles MediaView-specific fields from ki.Prop properties
+// doesn't support inherit or default
+func (mv *MediaView) StyleFromProps(props ki.Props, vp *Viewport2D) {
+	if bv, ok := props["auto-play"]; ok {
+		if b, ok := kit.ToBool(bv); ok {
+			mv.AutoPlay = b
+		}
+	}
+	if bv, ok := props["no-controls"]; ok {
+		if b, ok := kit.ToBool(bv); ok {
+			mv.NoControls = b
+		}
+	}
+}
+
+func (mv *MediaView) Style2D() {
+	mv.InitMediaView()
+	mv.StyMu.Lock()
+	mv.StyleFromProps(mv.Props, mv.Viewport)
+	mv.StyMu.Unlock()
+	mv.Controls().SetInvisibleState(mv.NoControls)
+	mv.Frame.Style2D()
+}
+
+func (mv *MediaView) Render2D() {
+	if mv.AutoPlay && !mv.autoPlayed && mv.Decoder != nil {
+		mv.autoPlayed = true
+		mv.Play()
+	}
+	mv.Frame.Render2D()
+}
@@ -54,7 +54,9 @@ type ActOpts struct {
 	UpdateFunc  func(act *Action)
 }
 
-// SetAction sets properties of given action
+// SetAction sets properties of given action -- the Label is translated
+// into the current locale (see T), and the untranslated Label is used for
+// the name if no Name is given
 func (m *Menu) SetAction(ac *Action, opts ActOpts, sigTo ki.Ki, fun ki.RecvFunc) {
 	nm := opts.Name
 	if nm == "" {
@@ -64,8 +66,8 @@ func (m *Menu) SetAction(ac *Action, opts ActOpts, sigTo ki.Ki, fun ki.RecvFunc)
 		nm = opts.Icon
 	}
 	ac.InitName(ac, nm)
-	ac.Text = opts.Label
-	ac.Tooltip = opts.Tooltip
+	ac.Text = T(opts.Label)
+	ac.Tooltip = T(opts.Tooltip)
 	ac.Icon = IconName(opts.Icon)
 	ac.Shortcut = key.Chord(opts.Shortcut).OSShortcut()
 	if opts.ShortcutKey != KeyFunNil {
@@ -245,6 +247,35 @@ func (m *Menu) AddCopyCutPaste(win *Window) {
 	})
 }
 
+// AddUndoRedo adds Undo and Redo actions for the edits in the views of given
+// window, which are labeled with the edit, e.g., Undo Delete Row, and
+// inactive when there is nothing to undo or redo.
+func (m *Menu) AddUndoRedo(win *Window) {
+	for _, redo := range []bool{false, true} {
+		redo := redo
+		kf, lbl := KeyFunUndo, "Undo"
+		if redo {
+			kf, lbl = KeyFunRedo, "Redo"
+		}
+		m.AddAction(ActOpts{Label: T(lbl), ShortcutKey: kf,
+			UpdateFunc: func(ac *Action) {
+				elbl := ""
+				if TheViewIFace != nil {
+					elbl = TheViewIFace.WindowUndoLabel(win, redo)
+				}
+				if elbl == "" {
+					ac.SetText(T(lbl))
+				} else {
+					ac.SetText(Tf(lbl+" %v", elbl))
+				}
+				ac.SetInactiveState(elbl == "")
+			}}, win, func(recv, send ki.Ki, sig int64, data interface{}) {
+			ww := recv.Embed(KiT_Window).(*Window)
+			TheViewIFace.WindowUndo(ww, redo)
+		})
+	}
+}
+
 // AddCopyCutPasteDupe adds a Copy, Cut, Paste, and Duplicate actions that
 // just emit the corresponding keyboard shortcut.  Paste is automatically
 // enabled by clipboard having something in it.
@@ -274,7 +305,7 @@ func (m *Menu) AddAppMenu(win *Window) {
 
 // AddStdAppMenu adds a standard set of menu items for application-level control.
 func (m *Menu) AddStdAppMenu(win *Window) {
-	aboutitle := "About " + oswin.TheApp.Name()
+	aboutitle := Tf("About %v", oswin.TheApp.Name())
 	m.AddAction(ActOpts{Label: aboutitle},
 		win, func(recv, send ki.Ki, sig int64, data interface{}) {
 			ww := recv.Embed(KiT_Window).(*Window)
@@ -284,6 +315,10 @@ func (m *Menu) AddStdAppMenu(win *Window) {
 		win, func(recv, send ki.Ki, sig int64, data interface{}) {
 			TheViewIFace.PrefsView(&Prefs)
 		})
+	m.AddAction(ActOpts{Label: "Keyboard Shortcuts..."},
+		win, func(recv, send ki.Ki, sig int64, data interface{}) {
+			TheViewIFace.KeyCmdsView(&AppKeyCmds)
+		})
 	m.AddSeparator("sepq")
 	m.AddAction(ActOpts{Label: "Quit", Shortcut: "Command+Q"},
 		win, func(recv, send ki.Ki, sig int64, data interface{}) {
@@ -404,6 +404,29 @@ func (nb *NodeBase) FirstContainingPoint(pt image.Point, leavesOnly bool) ki.Ki
 	return rval
 }
 
+// DeepestContainingPoint finds the deepest visible 2D node whose WinBBox
+// contains the given point -- nil if none.  Only the children of nodes
+// containing the point are considered, so it is the node that is shown at
+// that point, e.g., for inspecting the widget under the mouse.
+func (nb *NodeBase) DeepestContainingPoint(pt image.Point) ki.Ki {
+	var rval ki.Ki
+	nb.FuncDownMeFirst(0, nb.This(), func(k ki.Ki, level int, d interface{}) bool {
+		if k == nb.This() {
+			return ki.Continue
+		}
+		_, ni := KiToNode2D(k)
+		if ni == nil || ni.IsDeleted() || ni.IsDestroyed() || ni.IsInvisible() {
+			return ki.Break
+		}
+		if !ni.PosInWinBBox(pt) {
+			return ki.Break
+		}
+		rval = ni.This()
+		return ki.Continue
+	})
+	return rval
+}
+
 // standard css properties on nodes apply, including visible, etc.
 
 // see node2d.go for 2d node
@@ -641,12 +641,17 @@ func (nb *Node2DBase) DisconnectEvent(et oswin.EventType, pri EventPris) {
 // DisconnectAllEvents disconnects node from all window events -- typically
 // disconnect when not visible -- pri is priority -- pass AllPris for all priorities.
 // This goes down the entire tree from this node on down, as typically everything under
-// will not get an explicit disconnect call because no further updating will happen
+// will not get an explicit disconnect call because no further updating will happen.
+// Nodes that are only skipped by a re-render of a dirty region of the viewport
+// (see OutsideDirty) stay connected, as they are still shown.
 func (nb *Node2DBase) DisconnectAllEvents(pri EventPris) {
 	em := nb.EventMgr2D()
 	if em == nil {
 		return
 	}
+	if nb.OutsideDirty() {
+		return
+	}
 	nb.FuncDownMeFirst(0, nb.This(), func(k ki.Ki, level int, d interface{}) bool {
 		_, ni := KiToNode2D(k)
 		if ni == nil || ni.IsDeleted() || ni.IsDestroyed() {
@@ -658,6 +663,18 @@ func (nb *Node2DBase) DisconnectAllEvents(pri EventPris) {
 	})
 }
 
+// OutsideDirty returns true if the viewport is re-rendering a dirty region
+// (see Viewport2D.ReRender2DDirty) that this visible node is outside of, so
+// it is not rendered, but is still shown as it was
+func (nb *Node2DBase) OutsideDirty() bool {
+	mvp := nb.ViewportSafe()
+	if mvp == nil || nb.VpBBox.Empty() {
+		return false
+	}
+	_, ok := mvp.DirtyBounds(nb.VpBBox)
+	return !ok
+}
+
 // ConnectToViewport connects the node's update signal to the viewport as
 // a receiver, so that when the node is updated, it triggers the viewport to
 // re-render it -- this is automatically called in PushBounds, and
@@ -783,13 +800,16 @@ func (nb *Node2DBase) Style2DTree() {
 	}
 	// fmt.Printf("\n\n###################################\n%v\n", string(debug.Stack()))
 	pr := prof.Start("Node2D.Style2DTree." + nb.Type().Name())
+	fp := nb.FrameProf()
 	nb.FuncDownMeFirst(0, nb.This(), func(k ki.Ki, level int, d interface{}) bool {
 		nii, ni := KiToNode2D(k)
 		if nii == nil || ni.IsDeleted() || ni.IsDestroyed() {
 			return ki.Break
 		}
 		// ppr := prof.Start("Style2DTree:" + nii.Type().Name())
+		st := fp.Begin()
 		nii.Style2D()
+		fp.EndSelf(k, FrameStyle, st)
 		// ppr.End()
 		return ki.Continue
 	})
@@ -802,6 +822,7 @@ func (nb *Node2DBase) Size2DTree(iter int) {
 		return
 	}
 	pr := prof.Start("Node2D.Size2DTree." + nb.Type().Name())
+	fp := nb.FrameProf()
 	nb.FuncDownMeLast(0, nb.This(),
 		func(k ki.Ki, level int, d interface{}) bool { // tests whether to process node
 			nii, ni := KiToNode2D(k)
@@ -818,7 +839,9 @@ func (nb *Node2DBase) Size2DTree(iter int) {
 			if ni == nil || ni.IsDeleted() || ni.IsDestroyed() {
 				return ki.Break
 			}
+			st := fp.Begin()
 			nii.Size2D(iter)
+			fp.EndSelf(k, FrameSize, st)
 			return ki.Continue
 		})
 	pr.End()
@@ -838,7 +861,10 @@ func (nb *Node2DBase) Layout2DTree() {
 		parBBox = pni.ChildrenBBox2D()
 	}
 	nbi := nb.This().(Node2D)
+	fp := nb.FrameProf()
+	st := fp.Begin()
 	redo := nbi.Layout2D(parBBox, 0) // important to use interface version to get interface!
+	fp.EndTotal(nb.This(), FrameLayout, st)
 	if redo {
 		if Layout2DTrace {
 			fmt.Printf("Layout: ----------  Redo: %v ----------- \n", nbi.PathUnique())
@@ -851,7 +877,9 @@ func (nb *Node2DBase) Layout2DTree() {
 		} else {
 			nb.Size2DTree(1)
 		}
+		st = fp.Begin()
 		nbi.Layout2D(parBBox, 1) // todo: multiple iters?
+		fp.EndTotal(nb.This(), FrameLayout, st)
 	}
 	pr.End()
 }
@@ -868,7 +896,10 @@ func (nb *Node2DBase) Render2DTree() {
 		return
 	}
 	// pr := prof.Start("Node2D.Render2DTree." + nb.Type().Name())
+	fp := nb.FrameProf()
+	st := fp.Begin()
 	nb.This().(Node2D).Render2D() // important to use interface version to get interface!
+	fp.EndTotal(nb.This(), FrameRender, st)
 	// pr.End()
 }
 
@@ -879,12 +910,15 @@ func (nb *Node2DBase) Render2DTree() {
 func (nb *Node2DBase) Layout2DChildren(iter int) bool {
 	redo := false
 	cbb := nb.This().(Node2D).ChildrenBBox2D()
+	fp := nb.FrameProf()
 	for _, kid := range nb.Kids {
 		nii, _ := KiToNode2D(kid)
 		if nii != nil {
+			st := fp.Begin()
 			if nii.Layout2D(cbb, iter) {
 				redo = true
 			}
+			fp.EndTotal(kid, FrameLayout, st)
 		}
 	}
 	return redo
@@ -904,10 +938,13 @@ func (nb *Node2DBase) Move2DChildren(delta image.Point) {
 
 // Render2DChildren renders all of node's children -- default call at end of Render2D()
 func (nb *Node2DBase) Render2DChildren() {
+	fp := nb.FrameProf()
 	for _, kid := range nb.Kids {
 		nii, _ := KiToNode2D(kid)
 		if nii != nil {
+			st := fp.Begin()
 			nii.Render2D()
+			fp.EndTotal(kid, FrameRender, st)
 		}
 	}
 }
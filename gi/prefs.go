@@ -38,6 +38,7 @@ type Preferences struct {
 	ScreenPrefs          map[string]ScreenPrefs `desc:"screen-specific preferences -- will override overall defaults if set"`
 	Colors               ColorPrefs             `desc:"active color preferences"`
 	ColorSchemes         map[string]*ColorPrefs `desc:"named color schemes -- has Light and Dark schemes by default"`
+	FollowOSTheme        bool                   `desc:"automatically use the Light or Dark color scheme to match the light or dark appearance of the operating system, switching whenever it changes -- choosing LightMode or DarkMode against the OS appearance turns this off"`
 	Params               ParamPrefs             `view:"inline" desc:"parameters controlling GUI behavior"`
 	Editor               EditorPrefs            `view:"inline" desc:"editor preferences -- for TextView etc"`
 	KeyMap               KeyMapName             `desc:"select the active keymap from list of available keymaps -- see Edit KeyMaps for editing / saving / loading that list"`
@@ -68,6 +69,7 @@ func (pf *Preferences) Defaults() {
 	pf.LogicalDPIScale = 1.0
 	pf.Colors.Defaults()
 	pf.ColorSchemes = DefaultColorSchemes()
+	pf.FollowOSTheme = true
 	pf.Params.Defaults()
 	pf.Editor.Defaults()
 	pf.FavPaths.SetToDefaults()
@@ -159,6 +161,9 @@ func (pf *Preferences) LightMode() {
 		return
 	}
 	pf.Colors = *lc
+	if oswin.TheApp.IsDark() {
+		pf.FollowOSTheme = false
+	}
 	pf.Save()
 	pf.UpdateAll()
 }
@@ -171,10 +176,46 @@ func (pf *Preferences) DarkMode() {
 		return
 	}
 	pf.Colors = *lc
+	if !oswin.TheApp.IsDark() {
+		pf.FollowOSTheme = false
+	}
 	pf.Save()
 	pf.UpdateAll()
 }
 
+// ApplyOSTheme sets the colors to the Light or Dark ColorSchemes to match the
+// OS appearance, if FollowOSTheme is set and they do not already match,
+// returning true if they were changed -- UpdateAll must then be called for
+// open windows.  If the highlighting style was changed from that of the
+// previous scheme, it is switched to its pair in histyle.StylePairs, if it
+// has one, so that the custom choice is kept.
+func (pf *Preferences) ApplyOSTheme() bool {
+	if !pf.FollowOSTheme {
+		return false
+	}
+	dark := oswin.TheApp.IsDark()
+	if dark == pf.IsDarkMode() {
+		return false
+	}
+	nm, onm := "Light", "Dark"
+	if dark {
+		nm, onm = onm, nm
+	}
+	cs, ok := pf.ColorSchemes[nm]
+	if !ok {
+		log.Printf("%v ColorScheme not found\n", nm)
+		return false
+	}
+	cur := pf.Colors.HiStyle
+	pf.Colors = *cs
+	if ocs, ok := pf.ColorSchemes[onm]; !ok || cur != ocs.HiStyle {
+		if hsty := TheViewIFace.HiStylePair(cur, dark); hsty != cur {
+			pf.Colors.HiStyle = hsty
+		}
+	}
+	return true
+}
+
 // Apply preferences to all the relevant settings.
 func (pf *Preferences) Apply() {
 	np := len(pf.FavPaths)
@@ -241,22 +282,7 @@ func (pf *Preferences) ApplyDPI() {
 func (pf *Preferences) UpdateAll() {
 	ZoomFactor = 1 // reset so saved dpi is used
 	pf.Apply()
-
-	gist.RebuildDefaultStyles = true
-	gist.ColorSpecCache = nil
-	gist.StyleTemplates = nil
-	// for _, w := range AllWindows {  // no need and just messes stuff up!
-	// 	w.SetSize(w.OSWin.Size())
-	// }
-	// needs another pass through to get it right..
-	for _, w := range AllWindows {
-		w.FullReRender()
-	}
-	gist.RebuildDefaultStyles = false
-	// and another without rebuilding?  yep all are required
-	for _, w := range AllWindows {
-		w.FullReRender()
-	}
+	RestyleAll()
 }
 
 // ScreenInfo returns screen info for all screens on the console.
@@ -332,6 +358,12 @@ func (pf *Preferences) EditDebug() {
 	TheViewIFace.PrefsDbgView(&PrefsDbg)
 }
 
+// EditSettings opens the SettingsView editor of the settings registered by
+// packages and the app -- see RegisterSettings
+func (pf *Preferences) EditSettings() {
+	TheViewIFace.SettingsView(&Settings)
+}
+
 // UpdateUser gets the user info from the OS
 func (pf *Preferences) UpdateUser() {
 	usr, err := user.Current()
@@ -408,11 +440,11 @@ var PreferencesProps = ki.Props{
 		}},
 		{"sep-color", ki.BlankProp{}},
 		{"LightMode", ki.Props{
-			"desc": "Set color mode to Light mode as defined in ColorSchemes -- automatically does Save and UpdateAll ",
+			"desc": "Set color mode to Light mode as defined in ColorSchemes -- automatically does Save and UpdateAll -- turns off FollowOSTheme if the OS appearance is dark",
 			"icon": "color",
 		}},
 		{"DarkMode", ki.Props{
-			"desc": "Set color mode to Dark mode as defined in ColorSchemes -- automatically does Save and UpdateAll",
+			"desc": "Set color mode to Dark mode as defined in ColorSchemes -- automatically does Save and UpdateAll -- turns off FollowOSTheme if the OS appearance is light",
 			"icon": "color",
 		}},
 		{"sep-scrn", ki.BlankProp{}},
@@ -452,6 +484,10 @@ var PreferencesProps = ki.Props{
 			"icon": "file-binary",
 			"desc": "Opens the PrefsDbgView editor to control debugging parameters. These are not saved -- only set dynamically during running.",
 		}},
+		{"EditSettings", ki.Props{
+			"icon": "file-binary",
+			"desc": "Opens the SettingsView editor of the settings registered by packages and the app, which are saved automatically in their own files.",
+		}},
 	},
 }
 
@@ -615,12 +651,15 @@ type ScreenPrefs struct {
 
 // ParamPrefs contains misc parameters controlling GUI behavior.
 type ParamPrefs struct {
-	DoubleClickMSec  int     `min:"100" step:"50" desc:"the maximum time interval in msec between button press events to count as a double-click"`
-	ScrollWheelSpeed float32 `min:"0.01" step:"1" desc:"how fast the scroll wheel moves -- typically pixels per wheel step but units can be arbitrary.  It is generally impossible to standardize speed and variable across devices, and we don't have access to the system settings, so unfortunately you have to set it here."`
-	LocalMainMenu    bool    `desc:"controls whether the main menu is displayed locally at top of each window, in addition to global menu at the top of the screen.  Mac native apps do not do this, but OTOH it makes things more consistent with other platforms, and with larger screens, it can be convenient to have access to all the menu items right there."`
-	BigFileSize      int     `def:"10000000" desc:"the limit of file size, above which user will be prompted before opening / copying, etc."`
-	SavedPathsMax    int     `desc:"maximum number of saved paths to save in FileView"`
-	Smooth3D         bool    `desc:"turn on smoothing in 3D rendering -- this should be on by default but if you get an error telling you to turn it off, then do so (because your hardware can't handle it)"`
+	DoubleClickMSec   int     `min:"100" step:"50" desc:"the maximum time interval in msec between button press events to count as a double-click"`
+	ScrollWheelSpeed  float32 `min:"0.01" step:"1" desc:"how fast the scroll wheel moves -- typically pixels per wheel step but units can be arbitrary.  It is generally impossible to standardize speed and variable across devices, and we don't have access to the system settings, so unfortunately you have to set it here."`
+	LocalMainMenu     bool    `desc:"controls whether the main menu is displayed locally at top of each window, in addition to global menu at the top of the screen.  Mac native apps do not do this, but OTOH it makes things more consistent with other platforms, and with larger screens, it can be convenient to have access to all the menu items right there."`
+	BigFileSize       int     `def:"10000000" desc:"the limit of file size, above which user will be prompted before opening / copying, etc."`
+	SavedPathsMax     int     `desc:"maximum number of saved paths to save in FileView"`
+	RecentFilesMax    int     `desc:"maximum number of recent files to save, shown in the sidebar of FileView"`
+	RecentColorsMax   int     `desc:"maximum number of recently-used colors to save, shown in the ColorPicker"`
+	Smooth3D          bool    `desc:"turn on smoothing in 3D rendering -- this should be on by default but if you get an error telling you to turn it off, then do so (because your hardware can't handle it)"`
+	NativeFileDialogs bool    `desc:"use the platform-native file dialogs (e.g., for Open and Save As), where available, instead of the FileView dialog -- on Linux these require the zenity or kdialog command"`
 }
 
 func (pf *ParamPrefs) Defaults() {
@@ -629,7 +668,10 @@ func (pf *ParamPrefs) Defaults() {
 	pf.LocalMainMenu = true // much better
 	pf.BigFileSize = 10000000
 	pf.SavedPathsMax = 50
+	pf.RecentFilesMax = 20
+	pf.RecentColorsMax = 16
 	pf.Smooth3D = true
+	pf.NativeFileDialogs = true
 }
 
 // User basic user information that might be needed for different apps
@@ -644,26 +686,65 @@ type User struct {
 // EditorPrefs contains editor preferences.  It can also be set
 // from ki.Props style properties.
 type EditorPrefs struct {
-	TabSize      int  `xml:"tab-size" desc:"size of a tab, in chars -- also determines indent level for space indent"`
-	SpaceIndent  bool `xml:"space-indent" desc:"use spaces for indentation, otherwise tabs"`
-	WordWrap     bool `xml:"word-wrap" desc:"wrap lines at word boundaries -- otherwise long lines scroll off the end"`
-	LineNos      bool `xml:"line-nos" desc:"show line numbers"`
-	Completion   bool `xml:"completion" desc:"use the completion system to suggest options while typing"`
-	SpellCorrect bool `xml:"spell-correct" desc:"suggest corrections for unknown words while typing"`
-	AutoIndent   bool `xml:"auto-indent" desc:"automatically indent lines when enter, tab, }, etc pressed"`
-	EmacsUndo    bool `xml:"emacs-undo" desc:"use emacs-style undo, where after a non-undo command, all the current undo actions are added to the undo stack, such that a subsequent undo is actually a redo"`
-	DepthColor   bool `xml:"depth-color" desc:"colorize the background according to nesting depth"`
+	TabSize      int       `xml:"tab-size" desc:"size of a tab, in chars -- also determines indent level for space indent"`
+	SpaceIndent  bool      `xml:"space-indent" desc:"use spaces for indentation, otherwise tabs"`
+	WordWrap     bool      `xml:"word-wrap" desc:"wrap lines at word boundaries -- otherwise long lines scroll off the end"`
+	WrapIndent   bool      `xml:"wrap-indent" desc:"indent the continuation lines of wrapped lines to match the indentation of the line"`
+	WrapHang     int       `xml:"wrap-hang" desc:"additional hanging indent for the continuation lines of wrapped lines, in chars"`
+	ElasticTabs  bool      `xml:"elastic-tabs" desc:"align the tab-separated text of adjacent lines in columns (elastic tabstops), instead of using fixed tab stops -- lines that are wrapped are not aligned"`
+	EditorConfig bool      `xml:"editor-config" desc:"use the indentation settings from .editorconfig files in the directory of a file or its parents (see editorconfig.org)"`
+	LineNos      bool      `xml:"line-nos" desc:"show line numbers"`
+	Completion   bool      `xml:"completion" desc:"use the completion system to suggest options while typing"`
+	SpellCorrect bool      `xml:"spell-correct" desc:"suggest corrections for unknown words while typing"`
+	AutoIndent   bool      `xml:"auto-indent" desc:"automatically indent lines when enter, tab, }, etc pressed"`
+	EmacsUndo    bool      `xml:"emacs-undo" desc:"use emacs-style undo, where after a non-undo command, all the current undo actions are added to the undo stack, such that a subsequent undo is actually a redo"`
+	DepthColor   bool      `xml:"depth-color" desc:"colorize the background according to nesting depth"`
+	CodeFolding  bool      `xml:"code-folding" desc:"show fold markers next to line numbers for regions of code (based on braces or indentation) that can be folded (collapsed) to hide their contents"`
+	RecoverySecs int       `xml:"recovery-secs" desc:"interval in seconds for saving a crash-recovery journal of unsaved changes to files, in the app prefs directory -- set to 0 to turn off"`
+	EditMode     EditModes `xml:"edit-mode" desc:"editing mode for TextView keys: Std uses the active KeyMap, Vim is a modal subset of vim (normal, insert, visual), and Emacs uses emacs bindings regardless of the active KeyMap -- individual views can override"`
 }
 
+// EditModes are the editing modes for TextView, determining how keys are
+// interpreted
+type EditModes int32
+
+const (
+	// EditModeDefault uses the EditMode from the editor Prefs -- as the
+	// Prefs setting, it is the same as EditModeStd
+	EditModeDefault EditModes = iota
+
+	// EditModeStd uses the key functions from the active KeyMap
+	EditModeStd
+
+	// EditModeVim is a modal subset of vim, with normal, insert and visual modes
+	EditModeVim
+
+	// EditModeEmacs uses emacs bindings, regardless of the active KeyMap
+	EditModeEmacs
+
+	EditModesN
+)
+
+//go:generate stringer -type=EditModes
+
+var KiT_EditModes = kit.Enums.AddEnumAltLower(EditModesN, kit.NotBitFlag, gist.StylePropProps, "EditMode")
+
+func (ev EditModes) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *EditModes) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
 // Defaults are the defaults for EditorPrefs
 func (pf *EditorPrefs) Defaults() {
 	pf.TabSize = 4
 	pf.WordWrap = true
+	pf.WrapIndent = true
+	pf.EditorConfig = true
 	pf.LineNos = true
 	pf.Completion = true
 	pf.SpellCorrect = true
 	pf.AutoIndent = true
 	pf.DepthColor = true
+	pf.CodeFolding = true
+	pf.RecoverySecs = 30
 }
 
 // StyleFromProps styles Slider-specific fields from ki.Prop properties
@@ -689,6 +770,22 @@ func (pf *EditorPrefs) StyleFromProps(props ki.Props) {
 			if iv, ok := kit.ToBool(val); ok {
 				pf.WordWrap = iv
 			}
+		case "wrap-indent":
+			if iv, ok := kit.ToBool(val); ok {
+				pf.WrapIndent = iv
+			}
+		case "wrap-hang":
+			if iv, ok := kit.ToInt(val); ok {
+				pf.WrapHang = int(iv)
+			}
+		case "elastic-tabs":
+			if iv, ok := kit.ToBool(val); ok {
+				pf.ElasticTabs = iv
+			}
+		case "editor-config":
+			if iv, ok := kit.ToBool(val); ok {
+				pf.EditorConfig = iv
+			}
 		case "line-nos":
 			if iv, ok := kit.ToBool(val); ok {
 				pf.LineNos = iv
@@ -713,6 +810,21 @@ func (pf *EditorPrefs) StyleFromProps(props ki.Props) {
 			if iv, ok := kit.ToBool(val); ok {
 				pf.DepthColor = iv
 			}
+		case "code-folding":
+			if iv, ok := kit.ToBool(val); ok {
+				pf.CodeFolding = iv
+			}
+		case "recovery-secs":
+			if iv, ok := kit.ToInt(val); ok {
+				pf.RecoverySecs = int(iv)
+			}
+		case "edit-mode":
+			switch vt := val.(type) {
+			case string:
+				pf.EditMode.FromString(vt)
+			case EditModes:
+				pf.EditMode = vt
+			}
 		}
 	}
 }
@@ -834,6 +946,93 @@ func OpenPaths() {
 	StringsAddExtras((*[]string)(&SavedPaths), SavedPathsExtras)
 }
 
+// RecentFiles are the files most recently chosen in FileView dialogs, most
+// recent first, which are shown in its sidebar
+var RecentFiles FilePaths
+
+// RecentFilesFileName is the name of the recent files file in GoGi prefs directory
+var RecentFilesFileName = "recent_files.json"
+
+// SaveRecentFiles saves the active RecentFiles to prefs dir
+func SaveRecentFiles() {
+	pdir := oswin.TheApp.GoGiPrefsDir()
+	pnm := filepath.Join(pdir, RecentFilesFileName)
+	RecentFiles.SaveJSON(pnm)
+}
+
+// OpenRecentFiles loads the active RecentFiles from prefs dir
+func OpenRecentFiles() {
+	pdir := oswin.TheApp.GoGiPrefsDir()
+	pnm := filepath.Join(pdir, RecentFilesFileName)
+	RecentFiles.OpenJSON(pnm)
+}
+
+//////////////////////////////////////////////////////////////////
+//  ColorList
+
+// ColorList is a list of colors, e.g., the RecentColors
+type ColorList []gist.Color
+
+// Open colors from a JSON-formatted file.
+func (cl *ColorList) OpenJSON(filename string) error {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, cl)
+}
+
+// Save colors to a JSON-formatted file.
+func (cl *ColorList) SaveJSON(filename string) error {
+	b, err := json.MarshalIndent(cl, "", "  ")
+	if err != nil {
+		log.Println(err) // unlikely
+		return err
+	}
+	err = ioutil.WriteFile(filename, b, 0644)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// AddColor inserts a color to the list (at the start), subject to max
+// length -- if the color is already on the list then it is moved to the start.
+func (cl *ColorList) AddColor(clr gist.Color, max int) {
+	for i, c := range *cl {
+		if c == clr {
+			copy((*cl)[1:i+1], (*cl)[0:i])
+			(*cl)[0] = clr
+			return
+		}
+	}
+	*cl = append(ColorList{clr}, *cl...)
+	if len(*cl) > max {
+		*cl = (*cl)[:max]
+	}
+}
+
+// RecentColors are the colors most recently chosen in ColorPicker dialogs,
+// most recent first
+var RecentColors ColorList
+
+// RecentColorsFileName is the name of the recent colors file in GoGi prefs directory
+var RecentColorsFileName = "recent_colors.json"
+
+// SaveRecentColors saves the active RecentColors to prefs dir
+func SaveRecentColors() {
+	pdir := oswin.TheApp.GoGiPrefsDir()
+	pnm := filepath.Join(pdir, RecentColorsFileName)
+	RecentColors.SaveJSON(pnm)
+}
+
+// OpenRecentColors loads the active RecentColors from prefs dir
+func OpenRecentColors() {
+	pdir := oswin.TheApp.GoGiPrefsDir()
+	pnm := filepath.Join(pdir, RecentColorsFileName)
+	RecentColors.OpenJSON(pnm)
+}
+
 //////////////////////////////////////////////////////////////////
 //  PrefsDetailed
 
@@ -0,0 +1,298 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/goki/gi/girl"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+)
+
+// PageSizes are standard paper sizes for printing
+type PageSizes int32
+
+const (
+	// PageLetter is US Letter, 8.5 x 11 in
+	PageLetter PageSizes = iota
+
+	// PageLegal is US Legal, 8.5 x 14 in
+	PageLegal
+
+	// PageTabloid is US Tabloid, 11 x 17 in
+	PageTabloid
+
+	// PageA3 is ISO A3, 297 x 420 mm
+	PageA3
+
+	// PageA4 is ISO A4, 210 x 297 mm
+	PageA4
+
+	// PageA5 is ISO A5, 148 x 210 mm
+	PageA5
+
+	PageSizesN
+)
+
+//go:generate stringer -type=PageSizes
+
+var KiT_PageSizes = kit.Enums.AddEnumAltLower(PageSizesN, kit.NotBitFlag, nil, "Page")
+
+func (ev PageSizes) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *PageSizes) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// PageSizePoints are the sizes of each of the PageSizes, in points (1/72 inch), portrait
+var PageSizePoints = map[PageSizes]mat32.Vec2{
+	PageLetter:  {X: 612, Y: 792},
+	PageLegal:   {X: 612, Y: 1008},
+	PageTabloid: {X: 792, Y: 1224},
+	PageA3:      {X: 841.89, Y: 1190.55},
+	PageA4:      {X: 595.28, Y: 841.89},
+	PageA5:      {X: 419.53, Y: 595.28},
+}
+
+// PrintSettings are the settings for printing, or exporting to PDF
+type PrintSettings struct {
+	PageSize  PageSizes `desc:"size of the paper"`
+	Landscape bool      `desc:"print in landscape orientation, with the long side of the paper horizontal"`
+	Margin    float32   `min:"0" step:"1" desc:"margin around each edge of the page, in millimeters"`
+	FitWidth  bool      `desc:"scale the drawing to fit the width of the page, instead of using Scale"`
+	Scale     float32   `min:"0.1" step:"0.1" desc:"scaling of the drawing relative to its physical size on the screen, if not FitWidth"`
+	Printer   string    `desc:"name of the printer to send to, for SendToPrinter -- blank uses the default printer"`
+}
+
+// Defaults sets default print settings
+func (ps *PrintSettings) Defaults() {
+	ps.PageSize = PageLetter
+	ps.Margin = 15
+	ps.FitWidth = true
+	ps.Scale = 1
+}
+
+// PageSizePoints returns the size of the page in points, taking into
+// account the orientation
+func (ps *PrintSettings) PageSizePoints() mat32.Vec2 {
+	sz := PageSizePoints[ps.PageSize]
+	if ps.Landscape {
+		sz.X, sz.Y = sz.Y, sz.X
+	}
+	return sz
+}
+
+// MarginPoints returns the margin in points
+func (ps *PrintSettings) MarginPoints() float32 {
+	return ps.Margin * units.PtPerInch / units.MmPerInch
+}
+
+// WritePDF writes given node and all of its children as vector graphics to
+// a PDF document laid out on pages according to given print settings (nil =
+// defaults), e.g., for printing.  If the node is itself a Viewport2D (e.g., a
+// Window's main viewport or an SVG drawing), all of it is written, otherwise
+// the region of its viewport that it occupies.  The node is re-rendered to
+// record the drawing.  See Viewport2D.CaptureImage for when to call.
+func WritePDF(node Node2D, w io.Writer, ps *PrintSettings) error {
+	if ps == nil {
+		ps = &PrintSettings{}
+		ps.Defaults()
+	}
+	vp := node.AsViewport2D()
+	var bb image.Rectangle
+	if vp != nil {
+		bb = vp.Pixels.Bounds()
+	} else {
+		nb := node.AsNode2D()
+		vp = nb.ViewportSafe()
+		if vp == nil || vp.Pixels == nil {
+			return errors.New("gi.WritePDF: node is not in a rendered viewport")
+		}
+		nb.BBoxMu.RLock()
+		bb = nb.VpBBox
+		nb.BBoxMu.RUnlock()
+	}
+	if bb.Empty() {
+		return errors.New("gi.WritePDF: node is not visible")
+	}
+	pd := girl.NewPDF(bb.Size())
+	pd.PushOrigin(bb.Min.Mul(-1), bb)
+	vp.Render.PDF = pd
+	if node.AsViewport2D() != nil {
+		vp.FullRender2DTree()
+	} else {
+		node.AsNode2D().FullRender2DTree()
+	}
+	vp.Render.PDF = nil
+	pd.PopOrigin()
+
+	psz := ps.PageSizePoints()
+	mrg := ps.MarginPoints()
+	dpi := float32(96)
+	if vp.Win != nil {
+		dpi = vp.Win.LogicalDPI()
+	}
+	scale := mat32.Max(ps.Scale, 0.01) * 72 / dpi
+	if ps.FitWidth {
+		scale = (psz.X - 2*mrg) / float32(bb.Dx())
+	}
+	return pd.Write(w, psz, mrg, scale)
+}
+
+// SavePDF saves given node and all of its children as vector graphics to a
+// PDF file, according to given print settings (nil = defaults) -- see
+// WritePDF for details.
+func SavePDF(node Node2D, filename string, ps *PrintSettings) error {
+	fp, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	bw := bufio.NewWriter(fp)
+	err = WritePDF(node, bw, ps)
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// SendToPrinter sends given PDF file to given printer (blank = default
+// printer), using the lp command, which is available on Linux and MacOS --
+// on Windows an error is returned and the PDF file must be printed by the
+// user.
+func SendToPrinter(filename, printer string) error {
+	if runtime.GOOS == "windows" {
+		return errors.New("gi.SendToPrinter: printing is not supported on windows -- open and print the PDF file: " + filename)
+	}
+	args := []string{}
+	if printer != "" {
+		args = append(args, "-d", printer)
+	}
+	args = append(args, filename)
+	out, err := exec.Command("lp", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("gi.SendToPrinter: error: %v: %v", err, string(out))
+	}
+	return nil
+}
+
+// PrintDialog opens a dialog for printing given node (see WritePDF), with
+// given initial settings (nil = defaults) and PDF file name -- the settings
+// are updated from the dialog when it is accepted, and the PDF file is saved,
+// and also sent to the printer if print is true, otherwise it is just
+// exported as a PDF.  Optionally connects to given signal receiving
+// object and function for dialog signals (nil to ignore).
+func PrintDialog(avp *Viewport2D, node Node2D, ps *PrintSettings, filename string, print bool, opts DlgOpts, recv ki.Ki, fun ki.RecvFunc) *Dialog {
+	if ps == nil {
+		ps = &PrintSettings{}
+		ps.Defaults()
+	}
+	if opts.Title == "" {
+		if print {
+			opts.Title = "Print"
+		} else {
+			opts.Title = "Export to PDF"
+		}
+	}
+	dlg := NewStdDialog(opts, AddOk, AddCancel)
+	dlg.Modal = true
+
+	frame := dlg.Frame()
+	_, prIdx := dlg.PromptWidget(frame)
+
+	grid := frame.InsertNewChild(KiT_Layout, prIdx+1, "print-grid").(*Layout)
+	grid.Lay = LayoutGrid
+	grid.SetProp("columns", 2)
+	grid.SetProp("spacing", units.NewEx(1))
+
+	AddNewLabel(grid, "page-size-lbl", T("Page size:"))
+	pcb := AddNewComboBox(grid, "page-size")
+	pcb.ItemsFromEnum(KiT_PageSizes, false, 0)
+	pcb.SetCurIndex(int(ps.PageSize))
+
+	AddNewLabel(grid, "landscape-lbl", T("Landscape:"))
+	lcb := AddNewCheckBox(grid, "landscape")
+	lcb.SetChecked(ps.Landscape)
+
+	AddNewLabel(grid, "margin-lbl", T("Margin (mm):"))
+	msb := AddNewSpinBox(grid, "margin")
+	msb.Defaults()
+	msb.SetMin(0)
+	msb.Step = 1
+	msb.SetValue(ps.Margin)
+
+	AddNewLabel(grid, "fit-width-lbl", T("Fit width:"))
+	fcb := AddNewCheckBox(grid, "fit-width")
+	fcb.SetChecked(ps.FitWidth)
+
+	AddNewLabel(grid, "scale-lbl", T("Scale:"))
+	ssb := AddNewSpinBox(grid, "scale")
+	ssb.Defaults()
+	ssb.SetMin(0.1)
+	ssb.Step = 0.1
+	ssb.SetValue(ps.Scale)
+
+	if print {
+		AddNewLabel(grid, "printer-lbl", T("Printer:"))
+		ptf := AddNewTextField(grid, "printer")
+		ptf.Placeholder = "default printer"
+		ptf.SetText(ps.Printer)
+		ptf.SetMinPrefWidth(units.NewCh(30))
+	}
+
+	AddNewLabel(grid, "file-lbl", T("PDF file:"))
+	ftf := AddNewTextField(grid, "file")
+	ftf.SetText(filename)
+	ftf.SetMinPrefWidth(units.NewCh(40))
+
+	dlg.DialogSig.Connect(dlg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig != int64(DialogAccepted) {
+			return
+		}
+		ddlg := send.Embed(KiT_Dialog).(*Dialog)
+		fnm := PrintDialogValues(ddlg, ps)
+		if err := SavePDF(node, fnm, ps); err != nil {
+			PromptDialog(avp, DlgOpts{Title: "PDF Export Error", Prompt: err.Error()}, AddOk, NoCancel, nil, nil)
+			return
+		}
+		if print {
+			if err := SendToPrinter(fnm, ps.Printer); err != nil {
+				PromptDialog(avp, DlgOpts{Title: "Print Error", Prompt: err.Error()}, AddOk, NoCancel, nil, nil)
+			}
+		}
+	})
+	if recv != nil && fun != nil {
+		dlg.DialogSig.Connect(recv, fun)
+	}
+	dlg.UpdateEndNoSig(true)
+	dlg.Open(0, 0, avp, nil)
+	return dlg
+}
+
+// PrintDialogValues updates given settings from the values the user set in
+// a PrintDialog, and returns the PDF file name.
+func PrintDialogValues(dlg *Dialog, ps *PrintSettings) string {
+	frame := dlg.Frame()
+	grid := frame.ChildByName("print-grid", 0).(*Layout)
+	pcb := grid.ChildByName("page-size", 0).(*ComboBox)
+	if pcb.CurIndex >= 0 {
+		ps.PageSize = PageSizes(pcb.CurIndex)
+	}
+	ps.Landscape = grid.ChildByName("landscape", 0).(*CheckBox).IsChecked()
+	ps.Margin = grid.ChildByName("margin", 0).(*SpinBox).Value
+	ps.FitWidth = grid.ChildByName("fit-width", 0).(*CheckBox).IsChecked()
+	ps.Scale = grid.ChildByName("scale", 0).(*SpinBox).Value
+	if ptf, ok := grid.ChildByName("printer", 0).(*TextField); ok {
+		ps.Printer = ptf.Text()
+	}
+	return grid.ChildByName("file", 0).(*TextField).Text()
+}
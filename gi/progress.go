@@ -0,0 +1,326 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  ProgressBar
+
+// ProgressBar shows the progress of an operation as the filled proportion of
+// a bar -- it is an inactive ScrollBar whose thumb is the part that is done.
+// It is shown in the dialog of RunProgress, and can be put in a status bar
+// of a window, as the ProgressOpts.Bar.
+type ProgressBar struct {
+	ScrollBar
+}
+
+var KiT_ProgressBar = kit.Types.AddType(&ProgressBar{}, ProgressBarProps)
+
+// AddNewProgressBar adds a new progress bar to given parent node, with given name.
+func AddNewProgressBar(parent ki.Ki, name string) *ProgressBar {
+	pb := parent.AddNewChild(KiT_ProgressBar, name).(*ProgressBar)
+	pb.Defaults()
+	return pb
+}
+
+func (pb *ProgressBar) CopyFieldsFrom(frm interface{}) {
+	fr := frm.(*ProgressBar)
+	pb.ScrollBar.CopyFieldsFrom(&fr.ScrollBar)
+}
+
+var ProgressBarProps = ki.Props{
+	"EnumType:Flag":    KiT_NodeFlags,
+	"border-width":     units.NewPx(1),
+	"border-radius":    units.NewPx(4),
+	"border-color":     &Prefs.Colors.Border,
+	"padding":          units.NewPx(0),
+	"margin":           units.NewPx(2),
+	"min-width":        units.NewEm(20),
+	"width":            units.NewEm(20),
+	"height":           units.NewEx(1.5),
+	"background-color": &Prefs.Colors.Control,
+	"color":            &Prefs.Colors.Font,
+	SliderSelectors[SliderValue]: ki.Props{
+		"border-color":     &Prefs.Colors.Icon,
+		"background-color": &Prefs.Colors.Icon,
+	},
+	SliderSelectors[SliderBox]: ki.Props{
+		"border-color":     &Prefs.Colors.Background,
+		"background-color": &Prefs.Colors.Background,
+	},
+}
+
+func (pb *ProgressBar) Defaults() {
+	pb.ScrollBar.Defaults()
+	pb.Dim = mat32.X
+	pb.Value = 0
+	pb.ThumbVal = 0
+	pb.SetInactive()
+}
+
+// SetProgress sets the proportion of the operation that is done, between 0
+// and 1, and updates the bar
+func (pb *ProgressBar) SetProgress(prop float32) {
+	pb.SetThumbValue(prop * pb.Max)
+}
+
+// Progress returns the proportion of the operation that is done, between 0
+// and 1
+func (pb *ProgressBar) Progress() float32 {
+	return pb.ThumbVal / pb.Max
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Progress
+
+// ProgressDelay is the default time that an operation run with RunProgress
+// can take before its progress is shown, so that quick operations do not
+// flash a dialog
+var ProgressDelay = 500 * time.Millisecond
+
+// ProgressOpts are the options for an operation run with RunProgress
+type ProgressOpts struct {
+	Title    string        `desc:"title of the progress dialog, describing the operation"`
+	Max      int           `desc:"total number of steps of the operation, for showing its progress -- 0 if not known in advance, in which case it can be set later with SetMax"`
+	NoCancel bool          `desc:"the user cannot cancel the operation from the progress dialog"`
+	Delay    time.Duration `desc:"time the operation can take before its progress is shown -- 0 for ProgressDelay, and negative to show it right away"`
+	Bar      *ProgressBar  `desc:"if set, the progress is shown in this bar, e.g., in a status bar of the window, instead of in a dialog -- the operation can then only be canceled by the app, with Cancel"`
+}
+
+// Progress is an operation that runs on its own goroutine, started by
+// RunProgress, with its progress shown in a dialog or a ProgressBar of its
+// window.  Its methods can be called from any goroutine: the operation
+// reports its progress with Step, SetMsg etc, and does any updates of the
+// GUI with Do, which all run in the event loop of the window, so they do
+// not race with its rendering.  The operation must check Canceled, or its
+// Ctx, regularly, and return when the user cancels it.
+type Progress struct {
+	ProgressOpts
+	Win  *Window         `desc:"window that shows the progress, in whose event loop the updates of the GUI are done"`
+	Ctx  context.Context `desc:"context of the operation, which is canceled when the user cancels it, its parent context is canceled, or its window is closed -- pass it on to any functions that take a context"`
+	Err  error           `desc:"error returned by the operation, once it is done"`
+	mu   sync.Mutex      // protects the state below, set by the operation and read in the event loop
+	cncl context.CancelFunc
+	cur  int
+	max  int
+	msg  string
+	funs []func() // pending functions from Do
+	pend bool     // an update event is pending
+	show bool     // the delay has passed
+	done bool     // the operation has returned
+	cncd bool     // the operation was canceled before it returned
+	dfun func(p *Progress)
+	dlg  *Dialog
+	lbl  *Label
+	bar  *ProgressBar
+}
+
+// RunProgress runs the given function on a new goroutine, as an operation
+// whose progress is shown in the given window, after the Delay of the
+// options: in a dialog, where the user can cancel it, or in the Bar of the
+// options.  The context of the operation is derived from the given parent
+// context, which can be nil.  The done function, if non-nil, is called in
+// the event loop of the window when the operation returns, with its Err set,
+// and can update the GUI with the results.
+func RunProgress(ctx context.Context, win *Window, opts ProgressOpts, fun func(p *Progress) error, done func(p *Progress)) *Progress {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	p := &Progress{ProgressOpts: opts, Win: win, max: opts.Max, dfun: done}
+	p.Ctx, p.cncl = context.WithCancel(ctx)
+	delay := opts.Delay
+	if delay == 0 {
+		delay = ProgressDelay
+	}
+	if delay < 0 {
+		p.show = true
+		p.update()
+	} else {
+		time.AfterFunc(delay, func() {
+			p.mu.Lock()
+			p.show = true
+			p.mu.Unlock()
+			p.update()
+		})
+	}
+	go func() {
+		err := fun(p)
+		p.mu.Lock()
+		p.Err = err
+		p.done = true
+		p.cncd = p.Ctx.Err() != nil
+		p.mu.Unlock()
+		p.cncl() // releases the context
+		p.update()
+	}()
+	return p
+}
+
+// Step adds given number of steps to those done by the operation
+func (p *Progress) Step(n int) {
+	p.mu.Lock()
+	p.cur += n
+	p.mu.Unlock()
+	p.update()
+}
+
+// SetCur sets the number of steps done by the operation
+func (p *Progress) SetCur(cur int) {
+	p.mu.Lock()
+	p.cur = cur
+	p.mu.Unlock()
+	p.update()
+}
+
+// SetMax sets the total number of steps of the operation
+func (p *Progress) SetMax(max int) {
+	p.mu.Lock()
+	p.max = max
+	p.mu.Unlock()
+	p.update()
+}
+
+// SetMsg sets the message that describes what the operation is doing
+func (p *Progress) SetMsg(msg string) {
+	p.mu.Lock()
+	p.msg = msg
+	p.mu.Unlock()
+	p.update()
+}
+
+// Do runs the given function in the event loop of the window, for updating
+// the GUI from the operation -- it does not wait for the function to run
+func (p *Progress) Do(fun func()) {
+	p.mu.Lock()
+	p.funs = append(p.funs, fun)
+	p.mu.Unlock()
+	p.update()
+}
+
+// Cancel cancels the operation, by canceling its Ctx
+func (p *Progress) Cancel() {
+	p.cncl()
+}
+
+// Canceled returns true if the operation has been canceled -- once it is
+// done, this is whether it was canceled before it returned
+func (p *Progress) Canceled() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.done {
+		return p.cncd
+	}
+	return p.Ctx.Err() != nil
+}
+
+// IsDone returns true if the operation has returned
+func (p *Progress) IsDone() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.done
+}
+
+// update requests an update of the GUI in the event loop of the window,
+// unless one is already pending
+func (p *Progress) update() {
+	if p.Win.IsClosed() {
+		p.Cancel()
+		return
+	}
+	p.mu.Lock()
+	if p.pend {
+		p.mu.Unlock()
+		return
+	}
+	p.pend = true
+	p.mu.Unlock()
+	p.Win.SendCustomEvent(p)
+}
+
+// updateGUI updates the GUI for the current state of the operation -- called
+// in the event loop of the window
+func (p *Progress) updateGUI() {
+	p.mu.Lock()
+	p.pend = false
+	cur, max, msg, show, done := p.cur, p.max, p.msg, p.show, p.done
+	funs := p.funs
+	p.funs = nil
+	dfun := p.dfun
+	if done {
+		p.dfun = nil
+	}
+	p.mu.Unlock()
+
+	for _, fun := range funs {
+		fun()
+	}
+	var prog float32
+	if max > 0 {
+		prog = mat32.Min(float32(cur)/float32(max), 1)
+	}
+	switch {
+	case done:
+		if p.Bar != nil {
+			p.Bar.SetProgress(0)
+			p.Bar.Tooltip = ""
+		}
+		if p.dlg != nil {
+			p.dlg.Close()
+			p.dlg = nil
+		}
+		if dfun != nil {
+			dfun(p)
+		}
+	case !show:
+	case p.Bar != nil:
+		p.Bar.Tooltip = msg
+		p.Bar.SetProgress(prog)
+	case p.dlg == nil:
+		if p.bar == nil { // not yet opened, vs. canceled
+			p.openDialog(msg, prog)
+		}
+	default:
+		updt := p.dlg.UpdateStart()
+		p.lbl.SetText(msg)
+		p.bar.SetProgress(prog)
+		p.dlg.UpdateEnd(updt)
+	}
+}
+
+// openDialog opens the progress dialog
+func (p *Progress) openDialog(msg string, prog float32) {
+	dlg := NewStdDialog(DlgOpts{Title: p.Title}, NoOk, !p.NoCancel)
+	dlg.Modal = true
+	frame := dlg.Frame()
+	_, prIdx := dlg.PromptWidget(frame)
+	p.lbl = frame.InsertNewChild(KiT_Label, prIdx+1, "msg").(*Label)
+	p.lbl.SetProp("white-space", gist.WhiteSpaceNormal)
+	p.lbl.SetProp("width", units.NewEm(20))
+	p.lbl.SetText(msg)
+	p.bar = frame.InsertNewChild(KiT_ProgressBar, prIdx+2, "bar").(*ProgressBar)
+	p.bar.Defaults()
+	p.bar.SetStretchMaxWidth()
+	p.bar.SetProgress(prog)
+	dlg.DialogSig.Connect(p.Win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(DialogCanceled) {
+			p.dlg = nil
+			p.Cancel()
+		}
+	})
+	p.dlg = dlg
+	dlg.UpdateEndNoSig(true)
+	dlg.Open(0, 0, p.Win.Viewport, nil)
+}
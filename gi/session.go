@@ -0,0 +1,224 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/ki/ki"
+)
+
+// WindowSession records the state of a main window, for restoring it in a
+// later session of the app
+type WindowSession struct {
+	Name   string               `desc:"name of the window"`
+	Title  string               `desc:"title of the window"`
+	Geom   WindowGeom           `desc:"position and size of the window, in the same units as WinGeomPrefs"`
+	Splits map[string][]float32 `desc:"splits of each SplitView in the window, by its path from the window"`
+	Docs   []string             `desc:"documents open in the window, as returned by the docs function registered with AddSessionWindow"`
+}
+
+// Session records the main windows of the app, so they can be reopened
+// where the user left off -- see SaveSession and RestoreSession
+type Session struct {
+	Windows []WindowSession `desc:"the main windows, in the order they were opened"`
+}
+
+// Open opens the session from a JSON-formatted file
+func (ss *Session) Open(filename FileName) error {
+	b, err := ioutil.ReadFile(string(filename))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, ss)
+}
+
+// Save saves the session to a JSON-formatted file
+func (ss *Session) Save(filename FileName) error {
+	b, err := json.MarshalIndent(ss, "", "  ")
+	if err != nil {
+		log.Println(err) // unlikely
+		return err
+	}
+	err = ioutil.WriteFile(string(filename), b, 0644)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// SessionWindowFuncs are the functions for saving and restoring the main
+// windows of a given name in the session -- see AddSessionWindow
+type SessionWindowFuncs struct {
+	Docs func(win *Window) []string      `desc:"returns the documents open in the window -- can be nil"`
+	Open func(ws *WindowSession) *Window `desc:"makes a new window, opening the documents of the saved session state, and starts its event loop"`
+}
+
+// SessionWindows are the functions for saving and restoring the main windows
+// in the session, by window name -- only windows registered here are saved
+var SessionWindows = map[string]SessionWindowFuncs{}
+
+// SessionAutoSave saves the session to the app preferences directory when
+// the app quits, or its last main window is closed -- see SaveSession
+var SessionAutoSave = false
+
+// SessionFileName is the base name of the session file in the app
+// preferences directory
+var SessionFileName = "session"
+
+// sessionMu protects sessionSaved
+var sessionMu sync.Mutex
+
+// sessionSaved is set once the session is saved while quitting, as the
+// windows close one by one
+var sessionSaved bool
+
+// restoringWin is the session state of the window being restored, used by
+// NewMainWindow for its geometry
+var restoringWin *WindowSession
+
+// AddSessionWindow registers the functions for saving and restoring the main
+// windows of given name in the session (only the part of the name prior to
+// any colon is used, as in WinGeomPrefs) -- docs returns the documents open
+// in a window, and open makes a new window for the saved state, typically by
+// calling the same function that the app uses to make the window
+// interactively, opening the saved documents, and starting its event loop
+func AddSessionWindow(name string, docs func(win *Window) []string, open func(ws *WindowSession) *Window) {
+	SessionWindows[winClassName(name)] = SessionWindowFuncs{Docs: docs, Open: open}
+}
+
+// winClassName returns the part of window name prior to colon -- that is the
+// general "class" of window
+func winClassName(name string) string {
+	if ci := strings.Index(name, ":"); ci > 0 {
+		return name[:ci]
+	}
+	return name
+}
+
+// SessionPrefsFileName returns the name of the file in the app preferences
+// directory that the session is saved to
+func SessionPrefsFileName() FileName {
+	return FileName(filepath.Join(oswin.TheApp.AppPrefsDir(), SessionFileName+".json"))
+}
+
+// RecordSession records the state of given window
+func (win *Window) RecordSession() WindowSession {
+	ws := WindowSession{Name: win.Nm, Title: win.Title, Geom: win.geom}
+	if fn, ok := SessionWindows[winClassName(win.Nm)]; ok && fn.Docs != nil {
+		ws.Docs = fn.Docs(win)
+	}
+	win.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		sv, ok := k.(*SplitView)
+		if !ok || len(sv.Splits) == 0 {
+			return ki.Continue
+		}
+		if ws.Splits == nil {
+			ws.Splits = make(map[string][]float32)
+		}
+		ws.Splits[sv.PathFromUnique(win.This())] = append([]float32{}, sv.Splits...)
+		return ki.Continue
+	})
+	return ws
+}
+
+// ApplySession applies the splits of the saved window state to the
+// SplitViews of the window
+func (win *Window) ApplySession(ws *WindowSession) {
+	for path, sp := range ws.Splits {
+		k, err := win.FindPathUniqueTry(path)
+		if err != nil {
+			continue
+		}
+		if sv, ok := k.(*SplitView); ok {
+			sv.SetSplitsAction(sp...)
+		}
+	}
+}
+
+// CurSession returns the current session, recording each of the main
+// windows that is registered with AddSessionWindow
+func CurSession() Session {
+	var ss Session
+	for i := 0; i < MainWindows.Len(); i++ {
+		win := MainWindows.Win(i)
+		if win == nil {
+			continue
+		}
+		if _, ok := SessionWindows[winClassName(win.Nm)]; !ok {
+			continue
+		}
+		ss.Windows = append(ss.Windows, win.RecordSession())
+	}
+	return ss
+}
+
+// SaveSession saves the current session to the app preferences directory --
+// see SessionPrefsFileName -- called automatically if SessionAutoSave
+func SaveSession() error {
+	ss := CurSession()
+	return ss.Save(SessionPrefsFileName())
+}
+
+// RestoreSession reopens the main windows saved in the last session, where
+// the user left off, and returns them -- typically called at startup, after
+// registering the windows with AddSessionWindow, and the app opens its usual
+// windows if none are returned
+func RestoreSession() ([]*Window, error) {
+	var ss Session
+	if err := ss.Open(SessionPrefsFileName()); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var wins []*Window
+	for i := range ss.Windows {
+		ws := &ss.Windows[i]
+		fn, ok := SessionWindows[winClassName(ws.Name)]
+		if !ok || fn.Open == nil {
+			continue
+		}
+		restoringWin = ws
+		win := fn.Open(ws)
+		restoringWin = nil
+		if win == nil {
+			continue
+		}
+		win.ApplySession(ws)
+		wins = append(wins, win)
+	}
+	return wins, nil
+}
+
+// sessionWindowClosed saves the session, if SessionAutoSave, when the last
+// main window is closed, or the first one while quitting, before any of them
+// are gone -- other windows closed by the user are not saved
+func sessionWindowClosed(win *Window) {
+	if !SessionAutoSave {
+		return
+	}
+	if _, ok := MainWindows.FindName(win.Nm); !ok {
+		return
+	}
+	quitting := oswin.TheApp.IsQuitting()
+	if !quitting && MainWindows.Len() > 1 {
+		return
+	}
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+	if sessionSaved {
+		return
+	}
+	sessionSaved = quitting
+	SaveSession()
+}
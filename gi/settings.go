@@ -0,0 +1,396 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// SettingsFilePrefix is the prefix of the name of the file that each
+// settings section is saved in, followed by the name of the section and
+// .json -- see SettingsSection.FileName
+var SettingsFilePrefix = "settings-"
+
+// SettingsMigrateFunc upgrades the values of a settings section saved in
+// version from, to version from+1, given the values as decoded from JSON
+// into a generic map, which it modifies in place (e.g., renaming,
+// converting, or deleting fields)
+type SettingsMigrateFunc func(from int, vals map[string]interface{}) error
+
+// SettingsSection is a typed, versioned section of the settings of the
+// app, registered by a package or the app with RegisterSettings, which is
+// saved in its own JSON file, and shown in its own tab of the SettingsView.
+// If the settings struct has a Defaults() method, it is called to set the
+// default values when the section is registered, and by ResetDefaults, and
+// if it has an Apply() method, it is called whenever the values are opened
+// or changed, to apply them.
+type SettingsSection struct {
+	Name       string                      `desc:"name of the section, which must be unique, and is used in the name of its file"`
+	Label      string                      `desc:"label of the tab of the section in the SettingsView -- Name is used if empty"`
+	Version    int                         `desc:"current version of the settings struct -- increment it when fields are changed in a way that requires migrating saved settings, and add a Migration from the previous version"`
+	Value      interface{}                 `desc:"pointer to the settings struct"`
+	GoGi       bool                        `desc:"save in the GoGi prefs directory, shared by all GoGi apps, instead of the app prefs directory"`
+	Migrations map[int]SettingsMigrateFunc `json:"-" xml:"-" desc:"functions that upgrade the saved settings, by the version they upgrade from"`
+	Changed    bool                        `json:"-" xml:"-" desc:"the values have changed since they were last opened or saved"`
+}
+
+// settingsFile is the format of the file of a settings section
+type settingsFile struct {
+	Version  int             `desc:"version of the settings struct that was saved"`
+	Settings json.RawMessage `desc:"the values of the settings struct"`
+}
+
+// Title returns the label of the section, or its name if it has none
+func (ss *SettingsSection) Title() string {
+	if ss.Label != "" {
+		return ss.Label
+	}
+	return ss.Name
+}
+
+// AddMigration adds the function that upgrades the saved settings from
+// given version to the next one
+func (ss *SettingsSection) AddMigration(from int, fun SettingsMigrateFunc) {
+	if ss.Migrations == nil {
+		ss.Migrations = make(map[int]SettingsMigrateFunc)
+	}
+	ss.Migrations[from] = fun
+}
+
+// FileName returns the path of the file the section is saved in
+func (ss *SettingsSection) FileName() string {
+	pdir := oswin.TheApp.AppPrefsDir()
+	if ss.GoGi {
+		pdir = oswin.TheApp.GoGiPrefsDir()
+	}
+	return filepath.Join(pdir, SettingsFilePrefix+ss.Name+".json")
+}
+
+// Defaults sets the values to their defaults, by calling the Defaults
+// method of the settings struct, if it has one
+func (ss *SettingsSection) Defaults() {
+	if df, ok := ss.Value.(interface{ Defaults() }); ok {
+		df.Defaults()
+	}
+}
+
+// Apply applies the values, by calling the Apply method of the settings
+// struct, if it has one
+func (ss *SettingsSection) Apply() {
+	if ap, ok := ss.Value.(interface{ Apply() }); ok {
+		ap.Apply()
+	}
+}
+
+// Open opens the values from the file of the section, migrating them if
+// they were saved in an earlier version, in which case they are saved
+// again in the current version.  It is not an error for the file to not
+// exist, in which case the values are left as they are.
+func (ss *SettingsSection) Open() error {
+	b, err := ioutil.ReadFile(ss.FileName())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	var sf settingsFile
+	if err = json.Unmarshal(b, &sf); err != nil {
+		return fmt.Errorf("gi.Settings: section %v: %v", ss.Name, err)
+	}
+	vals := []byte(sf.Settings)
+	migrated := false
+	if sf.Version < ss.Version {
+		vals, err = ss.Migrate(sf.Version, vals)
+		if err != nil {
+			return err
+		}
+		migrated = true
+	} else if sf.Version > ss.Version {
+		log.Printf("gi.Settings: section %v was saved in version %v, newer than the current version %v -- fields that are not known are ignored\n", ss.Name, sf.Version, ss.Version)
+	}
+	if len(vals) > 0 {
+		if err = json.Unmarshal(vals, ss.Value); err != nil {
+			return fmt.Errorf("gi.Settings: section %v: %v", ss.Name, err)
+		}
+	}
+	ss.Changed = false
+	ss.Apply()
+	Settings.emit(SettingsOpened, ss)
+	if migrated {
+		return ss.Save()
+	}
+	return nil
+}
+
+// Migrate upgrades given values of the section saved in given version to
+// the current version, by each of the Migrations in turn -- versions with
+// no Migration are left as they are
+func (ss *SettingsSection) Migrate(from int, vals []byte) ([]byte, error) {
+	m := make(map[string]interface{})
+	if len(vals) > 0 {
+		if err := json.Unmarshal(vals, &m); err != nil {
+			return vals, fmt.Errorf("gi.Settings: section %v: %v", ss.Name, err)
+		}
+	}
+	for v := from; v < ss.Version; v++ {
+		fun, ok := ss.Migrations[v]
+		if !ok {
+			continue
+		}
+		if err := fun(v, m); err != nil {
+			return vals, fmt.Errorf("gi.Settings: section %v: migrating from version %v: %v", ss.Name, v, err)
+		}
+	}
+	return json.Marshal(m)
+}
+
+// Save saves the values to the file of the section, in the current version
+func (ss *SettingsSection) Save() error {
+	vals, err := json.Marshal(ss.Value)
+	if err != nil {
+		log.Println(err) // unlikely
+		return err
+	}
+	b, err := json.MarshalIndent(settingsFile{Version: ss.Version, Settings: vals}, "", "  ")
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	err = ioutil.WriteFile(ss.FileName(), b, 0644)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	ss.Changed = false
+	Settings.emit(SettingsSaved, ss)
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  SettingsRegistry
+
+// SettingsRegistry is the registry of the settings sections of the app --
+// there is one, Settings, to which sections are added by RegisterSettings
+type SettingsRegistry struct {
+	ki.Node
+	Sections    []*SettingsSection `desc:"the sections, in the order they were registered, which is the order of the tabs in the SettingsView"`
+	SettingsSig ki.Signal          `json:"-" xml:"-" view:"-" desc:"signal for settings -- see SettingsSignals for the types -- data is the *SettingsSection"`
+	mu          sync.Mutex
+}
+
+var KiT_SettingsRegistry = kit.Types.AddType(&SettingsRegistry{}, SettingsRegistryProps)
+
+// Settings is the registry of the settings sections of the app
+var Settings = SettingsRegistry{}
+
+// SettingsSignals are signals that are sent by the SettingsRegistry, with
+// the *SettingsSection as data
+type SettingsSignals int64
+
+const (
+	// SettingsOpened means the values of the section were opened from its file
+	SettingsOpened SettingsSignals = iota
+
+	// SettingsChanged means the values of the section were changed, e.g.,
+	// by editing them in the SettingsView
+	SettingsChanged
+
+	// SettingsSaved means the values of the section were saved to its file
+	SettingsSaved
+
+	// SettingsReset means the values of the section were reset to their defaults
+	SettingsReset
+
+	SettingsSignalsN
+)
+
+//go:generate stringer -type=SettingsSignals
+
+// RegisterSettings registers a settings section of given name and version,
+// for given pointer to the settings struct, whose default values are set
+// -- typically called in an init function of the package or app that owns
+// the settings.  The values are opened by Settings.OpenAll, which the app
+// calls after SetAppName.
+func RegisterSettings(name string, version int, val interface{}) *SettingsSection {
+	ss := &SettingsSection{Name: name, Version: version, Value: val}
+	ss.Defaults()
+	Settings.Add(ss)
+	return ss
+}
+
+// Add adds given section to the registry, replacing any existing one of
+// the same name
+func (sr *SettingsRegistry) Add(ss *SettingsSection) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if sr.This() == nil {
+		sr.InitName(sr, "settings")
+	}
+	for i, es := range sr.Sections {
+		if es.Name == ss.Name {
+			sr.Sections[i] = ss
+			return
+		}
+	}
+	sr.Sections = append(sr.Sections, ss)
+}
+
+// Section returns the section of given name, and false if there is none
+func (sr *SettingsRegistry) Section(name string) (*SettingsSection, bool) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	for _, ss := range sr.Sections {
+		if ss.Name == name {
+			return ss, true
+		}
+	}
+	return nil, false
+}
+
+// sections returns a copy of the list of sections
+func (sr *SettingsRegistry) sections() []*SettingsSection {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sects := make([]*SettingsSection, len(sr.Sections))
+	copy(sects, sr.Sections)
+	return sects
+}
+
+// OpenAll opens all the sections from their files -- returns the first
+// error, after trying to open all of them
+func (sr *SettingsRegistry) OpenAll() error {
+	var rerr error
+	for _, ss := range sr.sections() {
+		if err := ss.Open(); err != nil {
+			log.Println(err)
+			if rerr == nil {
+				rerr = err
+			}
+		}
+	}
+	return rerr
+}
+
+// SaveAll saves all the sections that have changed to their files --
+// returns the first error, after trying to save all of them
+func (sr *SettingsRegistry) SaveAll() error {
+	var rerr error
+	for _, ss := range sr.sections() {
+		if !ss.Changed {
+			continue
+		}
+		if err := ss.Save(); err != nil && rerr == nil {
+			rerr = err
+		}
+	}
+	return rerr
+}
+
+// ResetDefaults resets all the sections to their default values, which are
+// applied, but not saved until SaveAll
+func (sr *SettingsRegistry) ResetDefaults() {
+	for _, ss := range sr.sections() {
+		ss.Defaults()
+		ss.Changed = true
+		ss.Apply()
+		sr.emit(SettingsReset, ss)
+	}
+}
+
+// SetChanged marks given section as changed, applies its values, and sends
+// the SettingsChanged signal -- called by the SettingsView when the values
+// are edited, and should be called when they are changed by the app
+func (sr *SettingsRegistry) SetChanged(ss *SettingsSection) {
+	ss.Changed = true
+	ss.Apply()
+	sr.emit(SettingsChanged, ss)
+}
+
+// HasChanged returns true if any of the sections have changed since they
+// were last opened or saved
+func (sr *SettingsRegistry) HasChanged() bool {
+	for _, ss := range sr.sections() {
+		if ss.Changed {
+			return true
+		}
+	}
+	return false
+}
+
+// Edit opens the SettingsView editor of all the sections
+func (sr *SettingsRegistry) Edit() {
+	TheViewIFace.SettingsView(sr)
+}
+
+// emit sends given signal for given section
+func (sr *SettingsRegistry) emit(sig SettingsSignals, ss *SettingsSection) {
+	if sr.This() == nil {
+		return
+	}
+	sr.SettingsSig.Emit(sr.This(), int64(sig), ss)
+}
+
+// SettingsRegistryProps define the ToolBar and MenuBar for the
+// SettingsView
+var SettingsRegistryProps = ki.Props{
+	"MainMenu": ki.PropSlice{
+		{"AppMenu", ki.BlankProp{}},
+		{"File", ki.PropSlice{
+			{"OpenAll", ki.Props{
+				"label":    "Revert",
+				"shortcut": KeyFunMenuOpen,
+			}},
+			{"SaveAll", ki.Props{
+				"label":    "Save",
+				"shortcut": KeyFunMenuSave,
+				"updtfunc": func(sri interface{}, act *Action) {
+					sr := sri.(*SettingsRegistry)
+					act.SetActiveState(sr.HasChanged())
+				},
+			}},
+			{"sep-def", ki.BlankProp{}},
+			{"ResetDefaults", ki.Props{
+				"confirm": true,
+			}},
+			{"sep-close", ki.BlankProp{}},
+			{"Close Window", ki.BlankProp{}},
+		}},
+		{"Edit", "Copy Cut Paste"},
+		{"Window", "Windows"},
+	},
+	"ToolBar": ki.PropSlice{
+		{"SaveAll", ki.Props{
+			"label": "Save",
+			"desc":  "Saves the settings that have changed, which are auto-loaded at startup.",
+			"icon":  "file-save",
+			"updtfunc": func(sri interface{}, act *Action) {
+				sr := sri.(*SettingsRegistry)
+				act.SetActiveStateUpdt(sr.HasChanged())
+			},
+		}},
+		{"OpenAll", ki.Props{
+			"label": "Revert",
+			"desc":  "Reopens the saved settings, discarding any changes.",
+			"icon":  "file-open",
+		}},
+		{"sep-def", ki.BlankProp{}},
+		{"ResetDefaults", ki.Props{
+			"desc":    "Resets all the settings to their default values -- they are not saved until you Save.",
+			"icon":    "reset",
+			"confirm": true,
+		}},
+	},
+}
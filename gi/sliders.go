@@ -56,7 +56,11 @@ type SliderBase struct {
 	Dim         mat32.Dims                `desc:"dimension along which the slider slides"`
 	Tracking    bool                      `xml:"tracking" desc:"if true, will send continuous updates of value changes as user moves the slider -- otherwise only at the end -- see TrackThr for a threshold on amount of change"`
 	TrackThr    float32                   `xml:"track-thr" desc:"threshold for amount of change in scroll value before emitting a signal in Tracking mode"`
-	Snap        bool                      `xml:"snap" desc:"snap the values to Step size increments"`
+	Snap        bool                      `xml:"snap" desc:"snap the values to Step size increments -- on the Scale, e.g., to decades for a log scale with Step = 1"`
+	Scale       ValueScales               `xml:"scale" desc:"scale of the values, along which the slider moves linearly -- the Step sizes are on this scale, e.g., in decades for a log scale -- the scale prop can also be the name of a custom mapping in ValueMappings"`
+	Mapping     ValueMapping              `copy:"-" json:"-" xml:"-" view:"-" desc:"custom mapping of the values for ScaleCustom"`
+	Unit        string                    `xml:"unit" desc:"unit of the values, e.g., s or Hz, shown after the value, e.g., in the description for assistive technologies"`
+	SIPrefix    bool                      `xml:"si-prefix" desc:"show the value with the SI prefix of its magnitude, e.g., 10ms for 0.01 in s"`
 	Off         bool                      `desc:"can turn off e.g., scrollbar rendering with this flag -- just prevents rendering"`
 	State       SliderStates              `json:"-" xml:"-" desc:"state of slider"`
 	StateStyles [SliderStatesN]gist.Style `copy:"-" json:"-" xml:"-" desc:"styles for different states of the slider, one for each state -- everything inherits from the base Style which is styled first according to the user-set styles, and then subsequent style settings can override that"`
@@ -90,6 +94,10 @@ func (sb *SliderBase) CopyFieldsFrom(frm interface{}) {
 	sb.Tracking = fr.Tracking
 	sb.TrackThr = fr.TrackThr
 	sb.Snap = fr.Snap
+	sb.Scale = fr.Scale
+	sb.Mapping = fr.Mapping
+	sb.Unit = fr.Unit
+	sb.SIPrefix = fr.SIPrefix
 	sb.Off = fr.Off
 }
 
@@ -98,6 +106,15 @@ func (sb *SliderBase) Disconnect() {
 	sb.SliderSig.DisconnectAll()
 }
 
+// AccessInfo describes the slider for assistive technologies
+func (sb *SliderBase) AccessInfo(an *oswin.AccessNode) {
+	an.Role = oswin.RoleSlider
+	an.Value = sb.ValueString()
+	an.Min = float64(sb.Min)
+	an.Max = float64(sb.Max)
+	an.Step = float64(sb.Step)
+}
+
 // SliderSignals are signals that sliders can send
 type SliderSignals int64
 
@@ -166,11 +183,45 @@ func (sb *SliderBase) Defaults() { // todo: should just get these from props
 // SnapValue snaps the value to step sizes if snap option is set
 func (sb *SliderBase) SnapValue() {
 	if sb.Snap {
-		sb.Value = mat32.IntMultiple(sb.Value, sb.Step)
+		vm := sb.Mapper()
+		sb.Value = vm.FromScale(mat32.IntMultiple(vm.ToScale(sb.Value), sb.Step))
 		sb.Value = mat32.Truncate(sb.Value, sb.Prec)
 	}
 }
 
+// Mapper returns the ValueMapping of the Scale of the values
+func (sb *SliderBase) Mapper() ValueMapping {
+	return ValueMapper(sb.Scale, sb.Mapping)
+}
+
+// ValueString returns the Value, with 4 significant digits, and its Unit
+func (sb *SliderBase) ValueString() string {
+	return FormatValueUnit(sb.Value, "%.4g", sb.Unit, sb.SIPrefix)
+}
+
+// ValueFromNorm returns the value at given normalized position [0..1] along
+// the Scale from Min to Max
+func (sb *SliderBase) ValueFromNorm(norm float32) float32 {
+	vm := sb.Mapper()
+	smin := vm.ToScale(sb.Min)
+	return vm.FromScale(smin + (vm.ToScale(sb.Max)-smin)*norm)
+}
+
+// NormFromValue returns the normalized position [0..1] along the Scale from
+// Min to Max of given value
+func (sb *SliderBase) NormFromValue(val float32) float32 {
+	vm := sb.Mapper()
+	smin := vm.ToScale(sb.Min)
+	return (vm.ToScale(val) - smin) / (vm.ToScale(sb.Max) - smin)
+}
+
+// StepValue returns the value given number of steps (+ or -) of given size
+// from the current value, on the Scale
+func (sb *SliderBase) StepValue(steps, size float32) float32 {
+	vm := sb.Mapper()
+	return mat32.Truncate(vm.FromScale(vm.ToScale(sb.Value)+steps*size), sb.Prec)
+}
+
 // SetSliderState sets the slider state to given state, updates style
 func (sb *SliderBase) SetSliderState(state SliderStates) {
 	if state == SliderActive && sb.HasFocus() {
@@ -272,7 +323,11 @@ func (sb *SliderBase) SetSliderPos(pos float32) {
 		}
 	}
 	sb.Pos = mat32.Max(0, sb.Pos)
-	sb.Value = mat32.Truncate(sb.Min+(sb.Max-sb.Min)*(sb.Pos/effSz), sb.Prec)
+	if sb.ValThumb {
+		sb.Value = mat32.Truncate(sb.Min+(sb.Max-sb.Min)*(sb.Pos/effSz), sb.Prec)
+	} else {
+		sb.Value = mat32.Truncate(sb.ValueFromNorm(sb.Pos/effSz), sb.Prec)
+	}
 	sb.Value = mat32.Clamp(sb.Value, sb.Min, sb.Max)
 	if sb.ValThumb {
 		sb.Value = mat32.Min(sb.Value, sb.Max-sb.ThumbVal)
@@ -308,7 +363,11 @@ func (sb *SliderBase) UpdatePosFromValue() {
 			effSz -= 0.5 // rounding errors
 		}
 	}
-	sb.Pos = effSz * (sb.Value - sb.Min) / (sb.Max - sb.Min)
+	if sb.ValThumb {
+		sb.Pos = effSz * (sb.Value - sb.Min) / (sb.Max - sb.Min)
+	} else {
+		sb.Pos = effSz * sb.NormFromValue(sb.Value)
+	}
 }
 
 // SetValue sets the value and updates the slider position, but does not
@@ -365,25 +424,25 @@ func (sb *SliderBase) KeyInput(kt *key.ChordEvent) {
 	kf := KeyFun(kt.Chord())
 	switch kf {
 	case KeyFunMoveUp:
-		sb.SetValueAction(sb.Value - sb.Step)
+		sb.SetValueAction(sb.StepValue(-1, sb.Step))
 		kt.SetProcessed()
 	case KeyFunMoveLeft:
-		sb.SetValueAction(sb.Value - sb.Step)
+		sb.SetValueAction(sb.StepValue(-1, sb.Step))
 		kt.SetProcessed()
 	case KeyFunMoveDown:
-		sb.SetValueAction(sb.Value + sb.Step)
+		sb.SetValueAction(sb.StepValue(1, sb.Step))
 		kt.SetProcessed()
 	case KeyFunMoveRight:
-		sb.SetValueAction(sb.Value + sb.Step)
+		sb.SetValueAction(sb.StepValue(1, sb.Step))
 		kt.SetProcessed()
 	case KeyFunPageUp:
-		sb.SetValueAction(sb.Value - sb.PageStep)
+		sb.SetValueAction(sb.StepValue(-1, sb.PageStep))
 		kt.SetProcessed()
 	// case KeyFunPageLeft:
 	// 	sb.SetValueAction(sb.Value - sb.PageStep)
 	// 	kt.SetProcessed()
 	case KeyFunPageDown:
-		sb.SetValueAction(sb.Value + sb.PageStep)
+		sb.SetValueAction(sb.StepValue(1, sb.PageStep))
 		kt.SetProcessed()
 	// case KeyFunPageRight:
 	// 	sb.SetValueAction(sb.Value + sb.PageStep)
@@ -602,6 +661,18 @@ func (sr *SliderBase) StyleFromProps(props ki.Props, vp *Viewport2D) {
 			if bv, ok := kit.ToBool(val); ok {
 				sr.Snap = bv
 			}
+		case "scale":
+			if sc, ok := val.(ValueScales); ok {
+				sr.Scale = sc
+			} else if sc, vm, ok := ScaleFromName(kit.ToString(val)); ok {
+				sr.Scale, sr.Mapping = sc, vm
+			}
+		case "unit":
+			sr.Unit = kit.ToString(val)
+		case "si-prefix":
+			if bv, ok := kit.ToBool(val); ok {
+				sr.SIPrefix = bv
+			}
 		}
 	}
 }
@@ -620,6 +691,7 @@ func (sr *SliderBase) StyleSlider() {
 	for i := 0; i < int(SliderStatesN); i++ {
 		sr.StateStyles[i].CopyFrom(&sr.Sty)
 		sr.StateStyles[i].SetStyleProps(pst, sr.StyleProps(SliderSelectors[i]), sr.Viewport)
+		ApplyAppStyles(sr.This().(Node2D), sr.Viewport, &sr.StateStyles[i], pst, SliderSelectors[i])
 		sr.StateStyles[i].CopyUnitContext(&sr.Sty.UnContext)
 	}
 	sr.StyleFromProps(sr.Props, sr.Viewport)         // does all the min / max / step etc
@@ -717,6 +789,10 @@ func (sr *Slider) Style2D() {
 	sr.SetCanFocusIfActive()
 	sr.StyleSlider()
 	sr.StyMu.Lock()
+	TouchTargetStyle(&sr.Sty)
+	for i := range sr.StateStyles {
+		TouchTargetStyle(&sr.StateStyles[i])
+	}
 	sr.LayState.SetFromStyle(&sr.Sty.Layout) // also does reset
 	sr.StyMu.Unlock()
 	sr.ConfigParts()
@@ -856,6 +932,12 @@ func (sb *ScrollBar) CopyFieldsFrom(frm interface{}) {
 	sb.SliderBase.CopyFieldsFrom(&fr.SliderBase)
 }
 
+// AccessInfo describes the scrollbar for assistive technologies
+func (sb *ScrollBar) AccessInfo(an *oswin.AccessNode) {
+	sb.SliderBase.AccessInfo(an)
+	an.Role = oswin.RoleScrollBar
+}
+
 var ScrollBarProps = ki.Props{
 	"EnumType:Flag":    KiT_NodeFlags,
 	"border-width":     units.NewPx(1),
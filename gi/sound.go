@@ -0,0 +1,62 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/audio"
+)
+
+// ErrNoAudio is returned when the platform does not support audio output
+var ErrNoAudio = errors.New("gi: audio output is not supported on this platform")
+
+// OpenAudio opens a new audio output stream for samples of given format,
+// if the platform supports audio output -- see the oswin/audio package
+func OpenAudio(format audio.Format) (audio.Stream, error) {
+	ap, ok := oswin.TheApp.(oswin.AudioPlayer)
+	if !ok {
+		return nil, ErrNoAudio
+	}
+	return ap.OpenAudio(format)
+}
+
+// PlaySamples plays given interleaved samples of given format, e.g., an
+// alert sound, returning once playing has started -- the stream is closed
+// when they have been played
+func PlaySamples(format audio.Format, samples []int16) error {
+	st, err := OpenAudio(format)
+	if err != nil {
+		return err
+	}
+	go func() {
+		if _, err := st.Write(samples); err != nil {
+			log.Printf("gi.PlaySamples: %v\n", err)
+		}
+		st.Drain()
+		st.Close()
+	}()
+	return nil
+}
+
+// PlaySound plays the WAV sound file of given name, e.g., an alert sound,
+// returning once playing has started
+func PlaySound(filename string) error {
+	format, samples, err := audio.OpenWAV(filename)
+	if err != nil {
+		log.Printf("gi.PlaySound: %v\n", err)
+		return err
+	}
+	return PlaySamples(format, samples)
+}
+
+// Beep plays a short alert tone
+func Beep() error {
+	format := audio.Format{SampleRate: 44100, Channels: 1}
+	return PlaySamples(format, audio.Tone(format, 880, 150*time.Millisecond, 0.5))
+}
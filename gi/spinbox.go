@@ -26,18 +26,23 @@ import (
 // decrementing values -- all configured within the Parts of the widget
 type SpinBox struct {
 	PartsWidgetBase
-	Value      float32   `xml:"value" desc:"current value"`
-	HasMin     bool      `xml:"has-min" desc:"is there a minimum value to enforce"`
-	Min        float32   `xml:"min" desc:"minimum value in range"`
-	HasMax     bool      `xml:"has-max" desc:"is there a maximumvalue to enforce"`
-	Max        float32   `xml:"max" desc:"maximum value in range"`
-	Step       float32   `xml:"step" desc:"smallest step size to increment"`
-	PageStep   float32   `xml:"pagestep" desc:"larger PageUp / Dn step size"`
-	Prec       int       `desc:"specifies the precision of decimal places (total, not after the decimal point) to use in representing the number -- this helps to truncate small weird floating point values in the nether regions"`
-	Format     string    `xml:"format" desc:"prop = format -- format string for printing the value -- blank defaults to %g.  If decimal based (ends in d, b, c, o, O, q, x, X, or U) then value is converted to decimal prior to printing"`
-	UpIcon     IconName  `view:"show-name" desc:"icon to use for up button -- defaults to wedge-up"`
-	DownIcon   IconName  `view:"show-name" desc:"icon to use for down button -- defaults to wedge-down"`
-	SpinBoxSig ki.Signal `copy:"-" json:"-" xml:"-" view:"-" desc:"signal for spin box -- has no signal types, just emitted when the value changes"`
+	Value      float32      `xml:"value" desc:"current value"`
+	HasMin     bool         `xml:"has-min" desc:"is there a minimum value to enforce"`
+	Min        float32      `xml:"min" desc:"minimum value in range"`
+	HasMax     bool         `xml:"has-max" desc:"is there a maximumvalue to enforce"`
+	Max        float32      `xml:"max" desc:"maximum value in range"`
+	Step       float32      `xml:"step" desc:"smallest step size to increment"`
+	PageStep   float32      `xml:"pagestep" desc:"larger PageUp / Dn step size"`
+	Prec       int          `desc:"specifies the precision of decimal places (total, not after the decimal point) to use in representing the number -- this helps to truncate small weird floating point values in the nether regions"`
+	Format     string       `xml:"format" desc:"prop = format -- format string for printing the value -- blank defaults to %g.  If decimal based (ends in d, b, c, o, O, q, x, X, or U) then value is converted to decimal prior to printing"`
+	Scale      ValueScales  `xml:"scale" desc:"prop = scale -- scale of the values, on which the up / down buttons step linearly -- the Step sizes are on this scale, e.g., in decades for a log scale, where each step multiplies the value -- the scale prop can also be the name of a custom mapping in ValueMappings"`
+	Mapping    ValueMapping `copy:"-" json:"-" xml:"-" view:"-" desc:"custom mapping of the values for ScaleCustom"`
+	Unit       string       `xml:"unit" desc:"prop = unit -- unit of the value, e.g., s or Hz, shown after it, and accepted after a value that is entered, with an optional SI prefix, e.g., 10ms"`
+	SIPrefix   bool         `xml:"si-prefix" desc:"prop = si-prefix -- show the value with the SI prefix of its magnitude, e.g., 10ms for 0.01 in s -- SI prefixes are always accepted for values that are entered"`
+	Snap       bool         `xml:"snap" desc:"prop = snap -- snap values that are entered to Step size increments, on the Scale, as the up / down buttons always do"`
+	UpIcon     IconName     `view:"show-name" desc:"icon to use for up button -- defaults to wedge-up"`
+	DownIcon   IconName     `view:"show-name" desc:"icon to use for down button -- defaults to wedge-down"`
+	SpinBoxSig ki.Signal    `copy:"-" json:"-" xml:"-" view:"-" desc:"signal for spin box -- has no signal types, just emitted when the value changes"`
 }
 
 var KiT_SpinBox = kit.Types.AddType(&SpinBox{}, SpinBoxProps)
@@ -58,6 +63,11 @@ func (sb *SpinBox) CopyFieldsFrom(frm interface{}) {
 	sb.Step = fr.Step
 	sb.PageStep = fr.PageStep
 	sb.Prec = fr.Prec
+	sb.Scale = fr.Scale
+	sb.Mapping = fr.Mapping
+	sb.Unit = fr.Unit
+	sb.SIPrefix = fr.SIPrefix
+	sb.Snap = fr.Snap
 	sb.UpIcon = fr.UpIcon
 	sb.DownIcon = fr.DownIcon
 }
@@ -67,6 +77,20 @@ func (sb *SpinBox) Disconnect() {
 	sb.SpinBoxSig.DisconnectAll()
 }
 
+// AccessInfo describes the spinbox for assistive technologies -- its
+// text field and buttons are parts and not described separately
+func (sb *SpinBox) AccessInfo(an *oswin.AccessNode) {
+	an.Role = oswin.RoleSpinBox
+	an.Value = sb.ValToString(sb.Value)
+	if sb.HasMin {
+		an.Min = float64(sb.Min)
+	}
+	if sb.HasMax {
+		an.Max = float64(sb.Max)
+	}
+	an.Step = float64(sb.Step)
+}
+
 var SpinBoxProps = ki.Props{
 	"EnumType:Flag": KiT_NodeFlags,
 	"#buttons": ki.Props{
@@ -157,9 +181,22 @@ func (sb *SpinBox) SetValueAction(val float32) {
 
 // IncrValue increments the value by given number of steps (+ or -), and enforces it to be an even multiple of the step size (snap-to-value), and emits the signal
 func (sb *SpinBox) IncrValue(steps float32) {
-	val := sb.Value + steps*sb.Step
+	vm := sb.Mapper()
+	val := vm.ToScale(sb.Value) + steps*sb.Step
 	val = mat32.IntMultiple(val, sb.Step)
-	sb.SetValueAction(val)
+	sb.SetValueAction(vm.FromScale(val))
+}
+
+// Mapper returns the ValueMapping of the Scale of the values
+func (sb *SpinBox) Mapper() ValueMapping {
+	return ValueMapper(sb.Scale, sb.Mapping)
+}
+
+// SnapValue returns given value snapped to an even multiple of the step
+// size, on the Scale
+func (sb *SpinBox) SnapValue(val float32) float32 {
+	vm := sb.Mapper()
+	return vm.FromScale(mat32.IntMultiple(vm.ToScale(val), sb.Step))
 }
 
 func (sb *SpinBox) ConfigParts() {
@@ -239,8 +276,11 @@ func (sb *SpinBox) ConfigParts() {
 				if sig == int64(TextFieldDone) || sig == int64(TextFieldDeFocused) {
 					sbb := recv.Embed(KiT_SpinBox).(*SpinBox)
 					tf := send.(*TextField)
-					vl, err := sb.StringToVal(tf.Text())
+					vl, err := sbb.StringToVal(tf.Text())
 					if err == nil {
+						if sbb.Snap {
+							vl = sbb.SnapValue(vl)
+						}
 						sbb.SetValueAction(vl)
 					}
 				}
@@ -265,6 +305,9 @@ func (sb *SpinBox) FormatIsInt() bool {
 
 // ValToString converts the value to the string representation thereof
 func (sb *SpinBox) ValToString(val float32) string {
+	if sb.Unit != "" || sb.SIPrefix {
+		return FormatValueUnit(val, sb.Format, sb.Unit, sb.SIPrefix)
+	}
 	if sb.Format == "" {
 		return fmt.Sprintf("%g", val)
 	}
@@ -274,7 +317,8 @@ func (sb *SpinBox) ValToString(val float32) string {
 	return fmt.Sprintf(sb.Format, val)
 }
 
-// StringToVal converts the string field back to float value
+// StringToVal converts the string field back to float value -- values with
+// an SI prefix and the Unit, e.g., 10ms or 3.5k, are also accepted
 func (sb *SpinBox) StringToVal(str string) (float32, error) {
 	var fval float32
 	var err error
@@ -288,6 +332,9 @@ func (sb *SpinBox) StringToVal(str string) (float32, error) {
 		fval = float32(fv)
 	}
 	if err != nil {
+		if uv, uerr := ParseValueUnit(str, sb.Unit); uerr == nil {
+			return uv, nil
+		}
 		log.Println(err)
 	}
 	return fval, err
@@ -383,6 +430,22 @@ func (sb *SpinBox) StyleFromProps(props ki.Props, vp *Viewport2D) {
 			}
 		case "format":
 			sb.Format = kit.ToString(val)
+		case "scale":
+			if sc, ok := val.(ValueScales); ok {
+				sb.Scale = sc
+			} else if sc, vm, ok := ScaleFromName(kit.ToString(val)); ok {
+				sb.Scale, sb.Mapping = sc, vm
+			}
+		case "unit":
+			sb.Unit = kit.ToString(val)
+		case "si-prefix":
+			if bv, ok := kit.ToBool(val); ok {
+				sb.SIPrefix = bv
+			}
+		case "snap":
+			if bv, ok := kit.ToBool(val); ok {
+				sb.Snap = bv
+			}
 		}
 	}
 }
@@ -614,6 +614,12 @@ func (sr *Splitter) SplitView() *SplitView {
 	return svi.(*SplitView)
 }
 
+// AccessInfo describes the splitter for assistive technologies
+func (sr *Splitter) AccessInfo(an *oswin.AccessNode) {
+	sr.SliderBase.AccessInfo(an)
+	an.Role = oswin.RoleSplitter
+}
+
 func (sr *Splitter) MouseEvent() {
 	sr.ConnectEvent(oswin.MouseEvent, RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
 		me := d.(*mouse.Event)
@@ -0,0 +1,367 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aymerick/douceur/css"
+	"github.com/aymerick/douceur/parser"
+	"github.com/goki/gi/gist"
+	"github.com/goki/ki/ki"
+)
+
+// AppStyles are the app-wide style sheets that are applied to all widgets
+// in all windows, in order (later sheets override earlier ones for rules of
+// the same specificity).  Rules are applied after the default styles for
+// each type, and before the properties set on each node, so they can be
+// used to define shareable themes instead of setting props on individual
+// nodes.  Use AddAppStyles or SetAppStyles to update all open windows.
+var AppStyles []*StyleRules
+
+// AddAppStyles adds given style sheet to the end of AppStyles, and re-styles
+// all open windows
+func AddAppStyles(sr *StyleRules) {
+	AppStyles = append(AppStyles, sr)
+	UpdateAppStyles()
+}
+
+// SetAppStyles sets the AppStyles to given style sheets, and re-styles all
+// open windows -- call with no args to remove all app styles
+func SetAppStyles(srs ...*StyleRules) {
+	AppStyles = srs
+	UpdateAppStyles()
+}
+
+// UpdateAppStyles re-styles all open windows, e.g., after changing
+// AppStyles or the rules within them
+func UpdateAppStyles() {
+	RestyleAll()
+}
+
+// StyleStateAliases maps alternative names for widget state selectors to
+// the ones used by the widgets (see e.g., ButtonSelectors)
+var StyleStateAliases = map[string]string{
+	":disabled": ":inactive",
+	":enabled":  ":active",
+	":normal":   ":active",
+	":pressed":  ":down",
+	":checked":  ":selected",
+}
+
+// ApplyAppStyles applies the properties of all the AppStyles rules that match
+// given node in given state to given style, in order of specificity.  state
+// is the widget state selector (e.g., ":hover", see ButtonSelectors), or ""
+// for the base style.  For a state style, the matching base rules are
+// applied again (before the state rules), so they take precedence over the
+// type's default state styles, except for properties set on the node itself.
+// parSty is the parent style, for inherited values.
+func ApplyAppStyles(node Node2D, vp *Viewport2D, st *gist.Style, parSty *gist.Style, state string) {
+	if len(AppStyles) == 0 {
+		return
+	}
+	if al, ok := StyleStateAliases[state]; ok {
+		state = al
+	}
+	var rules []*StyleRule
+	for _, sr := range AppStyles {
+		rules = sr.MatchRules(node, "", rules)
+		if state != "" {
+			rules = sr.MatchRules(node, state, rules)
+		}
+	}
+	if len(rules) == 0 {
+		return
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Spec < rules[j].Spec
+	})
+	nprops := *node.Properties()
+	for _, r := range rules {
+		props := r.Props
+		if state != "" && r.State == "" && len(nprops) > 0 {
+			props = make(ki.Props, len(r.Props))
+			for k, v := range r.Props {
+				if _, has := nprops[k]; !has {
+					props[k] = v
+				}
+			}
+		}
+		st.SetStyleProps(parSty, props, vp)
+	}
+}
+
+// StyleRules is a cascading style sheet of rules, each of which applies a
+// set of style properties to the widgets matching its selector -- see
+// AppStyles.  Rules can be specified in CSS format (ParseCSS, OpenCSS), or
+// as a ki.Props map of selectors to ki.Props of properties (SetProps).
+//
+// Selectors are as in CSS, e.g., "button", ".myclass", "#myname",
+// "button:hover", "toolbar action:inactive", "*": each part is a widget
+// type name (matches that type and any type that embeds it), .class,
+// #name (all lower case), and the final part can have a widget state
+// (:active = normal, :inactive (or :disabled), :hover, :focus, :down,
+// :selected, etc, depending on the widget -- see e.g., ButtonSelectors).
+// Parts separated by spaces must match ancestors of the widget, as in CSS
+// descendant selectors, and multiple selectors can be separated by commas.
+type StyleRules struct {
+	Name  string       `desc:"name of this style sheet"`
+	Rules []*StyleRule `desc:"the rules, in order"`
+}
+
+// StyleRule is one rule within StyleRules
+type StyleRule struct {
+	Selector string     `desc:"the selector for this rule, as specified"`
+	Sels     []StyleSel `desc:"the parsed parts of the selector, from outermost ancestor to the widget itself"`
+	State    string     `desc:"widget state selector, e.g., :hover -- empty for base style"`
+	Spec     int        `desc:"specificity of the selector, as in CSS: 100 per name, 10 per class and state, 1 per type"`
+	Props    ki.Props   `desc:"the style properties"`
+}
+
+// StyleSel is one part of a selector, matching one widget
+type StyleSel struct {
+	Type    string   `desc:"lower-case type name, empty for any"`
+	Classes []string `desc:"classes, all of which the widget must have"`
+	Name    string   `desc:"lower-case name of the widget, empty for any"`
+}
+
+// NewStyleRules returns a new empty style sheet with given name
+func NewStyleRules(name string) *StyleRules {
+	return &StyleRules{Name: name}
+}
+
+// AddRule adds a rule with given selector, which can be a comma-separated
+// list, and properties, returning an error if the selector is invalid
+func (sr *StyleRules) AddRule(selector string, props ki.Props) error {
+	for _, sel := range strings.Split(selector, ",") {
+		sel = strings.TrimSpace(sel)
+		if sel == "" {
+			continue
+		}
+		r, err := ParseStyleRule(sel)
+		if err != nil {
+			return err
+		}
+		r.Props = props
+		sr.Rules = append(sr.Rules, r)
+	}
+	return nil
+}
+
+// SetProps adds rules from given ki.Props map, where each key is a selector
+// and its value is a ki.Props of style properties.  Map keys are sorted so
+// the order is predictable -- use specificity or multiple calls if order
+// matters.
+func (sr *StyleRules) SetProps(pr ki.Props) error {
+	keys := make([]string, 0, len(pr))
+	for k := range pr {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		sp, ok := pr[k].(ki.Props)
+		if !ok {
+			return fmt.Errorf("gi.StyleRules SetProps: value for selector: %v is not a ki.Props", k)
+		}
+		if err := sr.AddRule(k, sp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseCSS adds the rules from given CSS style sheet text
+func (sr *StyleRules) ParseCSS(str string) error {
+	pss, err := parser.Parse(str)
+	if err != nil {
+		return fmt.Errorf("gi.StyleRules ParseCSS parser error: %v", err)
+	}
+	for _, r := range pss.Rules {
+		if r.Kind == css.AtRule {
+			continue // not supported
+		}
+		sp := make(ki.Props, len(r.Declarations))
+		for _, de := range r.Declarations {
+			sp[de.Property] = de.Value
+		}
+		for _, sel := range r.Selectors {
+			if err := sr.AddRule(sel, sp); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// OpenCSS adds the rules from given CSS style sheet file
+func (sr *StyleRules) OpenCSS(filename string) error {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	return sr.ParseCSS(string(b))
+}
+
+// CSS returns the rules in CSS format
+func (sr *StyleRules) CSS() string {
+	var b strings.Builder
+	for _, r := range sr.Rules {
+		b.WriteString(r.Selector + " {\n")
+		keys := make([]string, 0, len(r.Props))
+		for k := range r.Props {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			b.WriteString(fmt.Sprintf("\t%s: %v;\n", k, r.Props[k]))
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+// MatchRules appends the rules that match given node and state to given
+// list, returning the updated list
+func (sr *StyleRules) MatchRules(node Node2D, state string, rules []*StyleRule) []*StyleRule {
+	for _, r := range sr.Rules {
+		if r.State == state && r.Matches(node) {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// ParseStyleRule parses given single selector into a new rule
+func ParseStyleRule(selector string) (*StyleRule, error) {
+	r := &StyleRule{Selector: selector}
+	parts := strings.Fields(strings.ToLower(selector))
+	for i, pt := range parts {
+		if ci := strings.Index(pt, ":"); ci >= 0 {
+			if i != len(parts)-1 {
+				return nil, fmt.Errorf("gi.ParseStyleRule: state can only be on the last part of selector: %v", selector)
+			}
+			r.State = pt[ci:]
+			if al, ok := StyleStateAliases[r.State]; ok {
+				r.State = al
+			}
+			r.Spec += 10
+			pt = pt[:ci]
+		}
+		var sel StyleSel
+		for len(pt) > 0 {
+			end := strings.IndexAny(pt[1:], ".#") + 1
+			if end == 0 {
+				end = len(pt)
+			}
+			tok := pt[:end]
+			pt = pt[end:]
+			switch tok[0] {
+			case '.':
+				sel.Classes = append(sel.Classes, tok[1:])
+				r.Spec += 10
+			case '#':
+				sel.Name = tok[1:]
+				r.Spec += 100
+			default:
+				if tok != "*" {
+					sel.Type = tok
+					r.Spec++
+				}
+			}
+		}
+		r.Sels = append(r.Sels, sel)
+	}
+	if len(r.Sels) == 0 {
+		return nil, fmt.Errorf("gi.ParseStyleRule: empty selector")
+	}
+	return r, nil
+}
+
+// Matches returns true if the rule selector matches given node (ignoring
+// the state)
+func (r *StyleRule) Matches(node Node2D) bool {
+	ns := len(r.Sels)
+	if !r.Sels[ns-1].Matches(node) {
+		return false
+	}
+	si := ns - 2
+	for k := node.Parent(); k != nil && si >= 0; k = k.Parent() {
+		nii, ok := k.(Node2D)
+		if !ok {
+			break
+		}
+		if r.Sels[si].Matches(nii) {
+			si--
+		}
+	}
+	return si < 0
+}
+
+// Matches returns true if the selector part matches given node
+func (sl *StyleSel) Matches(node Node2D) bool {
+	if sl.Name != "" && strings.ToLower(node.Name()) != sl.Name {
+		return false
+	}
+	if len(sl.Classes) > 0 {
+		ncls := strings.Fields(strings.ToLower(node.AsNode2D().Class))
+		for _, cl := range sl.Classes {
+			has := false
+			for _, ncl := range ncls {
+				if ncl == cl {
+					has = true
+					break
+				}
+			}
+			if !has {
+				return false
+			}
+		}
+	}
+	if sl.Type != "" && !TypeNameEmbeds(node.Type(), sl.Type) {
+		return false
+	}
+	return true
+}
+
+var (
+	typeEmbedsMu    sync.Mutex
+	typeEmbedsCache = map[reflect.Type]map[string]bool{}
+)
+
+// TypeNameEmbeds returns true if given type has given lower-case type name,
+// or embeds a type with that name
+func TypeNameEmbeds(typ reflect.Type, nm string) bool {
+	typeEmbedsMu.Lock()
+	defer typeEmbedsMu.Unlock()
+	nms, ok := typeEmbedsCache[typ]
+	if !ok {
+		nms = make(map[string]bool)
+		var addNames func(t reflect.Type)
+		addNames = func(t reflect.Type) {
+			for t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			nms[strings.ToLower(t.Name())] = true
+			if t.Kind() != reflect.Struct {
+				return
+			}
+			for i := 0; i < t.NumField(); i++ {
+				if f := t.Field(i); f.Anonymous {
+					addNames(f.Type)
+				}
+			}
+		}
+		addNames(typ)
+		typeEmbedsCache[typ] = nms
+	}
+	return nms[nm]
+}
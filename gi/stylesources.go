@@ -0,0 +1,313 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// StyleSources are the sources of the value of a style property of a
+// widget, in the order in which they are applied by Style2DWidget, so later
+// ones take precedence over earlier ones
+type StyleSources int32
+
+const (
+	// StyleSrcDefault means the property is not set, so it has the default
+	// value of the Style
+	StyleSrcDefault StyleSources = iota
+
+	// StyleSrcInherited means the property is not set on the widget, and is
+	// inherited from a parent (for font and text properties, see
+	// gist.StyleInheritedProps)
+	StyleSrcInherited
+
+	// StyleSrcType means the property is set in the default props of the
+	// type of the widget, or of its parent widget for parts (#partname)
+	StyleSrcType
+
+	// StyleSrcApp means the property is set by a rule of the AppStyles
+	StyleSrcApp
+
+	// StyleSrcProp means the property is set in the props of the widget
+	StyleSrcProp
+
+	// StyleSrcClass means the property is set for a class of the widget in
+	// the props of its type (.class)
+	StyleSrcClass
+
+	// StyleSrcCSS means the property is set by the CSS of the widget or its
+	// parents, for its type, class or name
+	StyleSrcCSS
+
+	StyleSourcesN
+)
+
+//go:generate stringer -type=StyleSources
+
+var KiT_StyleSources = kit.Enums.AddEnum(StyleSourcesN, kit.NotBitFlag, nil)
+
+// StyleSource is the source of the value of a style property of a widget
+type StyleSource struct {
+	Key    string       `desc:"style property key, e.g., font-size"`
+	Value  string       `desc:"current value of the property in the style of the widget"`
+	Source StyleSources `desc:"source of the value"`
+	SetTo  string       `desc:"value that the property is set to by the source, e.g., inherit, or 2em -- empty for StyleSrcDefault"`
+	From   string       `desc:"where the value is set: the selector of the app style rule or CSS, the class, or the path of the parent it is inherited from"`
+}
+
+// styleFuncMaps returns the maps of the functions for styling each part of
+// the Style, by style property key
+func styleFuncMaps() []map[string]gist.StyleFunc {
+	return []map[string]gist.StyleFunc{gist.StyleStyleFuncs, gist.StyleLayoutFuncs, gist.StyleFontFuncs, gist.StyleTextFuncs, gist.StyleBorderFuncs, gist.StyleOutlineFuncs, gist.StyleShadowFuncs}
+}
+
+// StyleKeys returns all the style property keys, sorted
+func StyleKeys() []string {
+	var keys []string
+	for _, fm := range styleFuncMaps() {
+		for key := range fm {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WidgetStyleSources returns the source of each style property of given
+// widget, for all StyleKeys -- for properties set by more than one source,
+// it is the one that takes precedence.  Only the base style is considered,
+// not the styles for states such as :hover.  This is used for debugging styles,
+// e.g., in the GoGi editor.  Returns nil for nodes that are not widgets.
+func WidgetStyleSources(node Node2D) []StyleSource {
+	wb := node.AsWidget()
+	if wb == nil {
+		return nil
+	}
+	keys := StyleKeys()
+	wb.StyMu.RLock()
+	vals := make(map[string]string, len(keys))
+	styleValues(reflect.ValueOf(&wb.Sty).Elem(), "", vals)
+	wb.StyMu.RUnlock()
+	srcs := make([]StyleSource, len(keys))
+	for i, key := range keys {
+		srcs[i] = widgetStyleSource(wb, key)
+		srcs[i].Value = vals[key]
+	}
+	return srcs
+}
+
+// styleValues adds the values of the fields of given style struct to vals,
+// by their style property keys, which are the xml tags of the fields, with
+// the tags of the structs they are in as a prefix, e.g., border-width
+func styleValues(sv reflect.Value, prefix string, vals map[string]string) {
+	typ := sv.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" { // unexported
+			continue
+		}
+		tag := f.Tag.Get("xml")
+		if tag == "-" {
+			continue
+		}
+		key := prefix
+		switch {
+		case tag == "":
+		case prefix == "":
+			key = tag
+		case strings.HasPrefix(tag, "."):
+			key = prefix + tag
+		default:
+			key = prefix + "-" + tag
+		}
+		fv := sv.Field(i)
+		if tag != "" {
+			if styleKeyFunc(key) {
+				vals[key] = styleString(fv.Interface())
+				continue
+			}
+		}
+		if f.Type.Kind() == reflect.Struct {
+			styleValues(fv, key, vals)
+		}
+	}
+}
+
+// styleString returns a string for given style value, using the String
+// method of its pointer type if it has one, e.g., for units.Value
+func styleString(val interface{}) string {
+	switch v := val.(type) {
+	case gist.ColorSpec:
+		return styleString(&v)
+	case *gist.ColorSpec:
+		if v.Source == gist.SolidColor {
+			return v.Color.String()
+		}
+		return v.Source.String()
+	case fmt.Stringer:
+		return v.String()
+	}
+	if val != nil {
+		pv := reflect.New(reflect.TypeOf(val))
+		pv.Elem().Set(reflect.ValueOf(val))
+		if str, ok := pv.Interface().(fmt.Stringer); ok {
+			return str.String()
+		}
+	}
+	return kit.ToString(val)
+}
+
+// styleKeyFunc returns true if there is a function for styling given key
+func styleKeyFunc(key string) bool {
+	for _, fm := range styleFuncMaps() {
+		if _, has := fm[key]; has {
+			return true
+		}
+	}
+	return false
+}
+
+// widgetStyleSource returns the source of given style property of given
+// widget, in the reverse of the order of Style2DWidget and StylePart
+func widgetStyleSource(wb *WidgetBase, key string) StyleSource {
+	ss := StyleSource{Key: key}
+	set := func(src StyleSources, val interface{}, from string) bool {
+		ss.Source = src
+		ss.SetTo = styleString(val)
+		ss.From = from
+		return true
+	}
+	if got := styleCSSSource(wb, key, set); got {
+		return ss
+	}
+	tprops := kit.Types.Properties(wb.Type(), false)
+	if tprops != nil && wb.Class != "" {
+		kit.TypesMu.RLock()
+		got := false
+		for _, cl := range strings.Split(strings.ToLower(wb.Class), " ") {
+			clsty := "." + strings.TrimSpace(cl)
+			if sp, ok := ki.SubProps(*tprops, clsty); ok {
+				if val, has := sp[key]; has {
+					got = set(StyleSrcClass, val, clsty)
+				}
+			}
+		}
+		kit.TypesMu.RUnlock()
+		if got {
+			return ss
+		}
+	}
+	if val, has := wb.Props[key]; has {
+		set(StyleSrcProp, val, "")
+		return ss
+	}
+	if got := styleAppSource(wb, key, set); got {
+		return ss
+	}
+	if par := wb.Par; par != nil && par.Name() == "parts" && par.Parent() != nil { // part of a widget
+		owner := par.Parent()
+		if oprops := kit.Types.Properties(owner.Type(), false); oprops != nil {
+			stynm := "#" + strings.ToLower(wb.Nm)
+			kit.TypesMu.RLock()
+			sp, ok := ki.SubProps(*oprops, stynm)
+			kit.TypesMu.RUnlock()
+			if ok {
+				if val, has := sp[key]; has {
+					set(StyleSrcType, val, owner.Type().Name()+" "+stynm)
+					return ss
+				}
+			}
+		}
+	}
+	if tprops != nil {
+		if val, has := kit.TypeProp(*tprops, key); has {
+			set(StyleSrcType, val, wb.Type().Name())
+			return ss
+		}
+	}
+	if gist.StyleInheritedProps[key] {
+		for par := wb.Par; par != nil; par = par.Parent() {
+			pgi, _ := KiToNode2D(par)
+			if pgi == nil {
+				break
+			}
+			pwb := pgi.AsWidget()
+			if pwb == nil {
+				continue
+			}
+			pss := widgetStyleSource(pwb, key)
+			if pss.Source == StyleSrcInherited {
+				ss.Source = StyleSrcInherited
+				ss.SetTo = pss.SetTo
+				ss.From = pss.From
+				return ss
+			}
+			if pss.Source != StyleSrcDefault {
+				set(StyleSrcInherited, pss.SetTo, par.Path())
+				return ss
+			}
+		}
+	}
+	return ss
+}
+
+// styleCSSSource calls set with the value of given key in the CSS of given
+// widget, as applied by StyleCSS, returning true if found
+func styleCSSSource(wb *WidgetBase, key string, set func(src StyleSources, val interface{}, from string) bool) bool {
+	if len(wb.CSSAgg) == 0 {
+		return false
+	}
+	sels := []string{strings.ToLower(wb.Type().Name())}
+	for _, cl := range strings.Split(strings.ToLower(wb.Class), " ") {
+		if cl = strings.TrimSpace(cl); cl != "" {
+			sels = append(sels, "."+cl)
+		}
+	}
+	sels = append(sels, "#"+strings.ToLower(wb.Nm))
+	got := false
+	for _, sel := range sels {
+		pp, ok := wb.CSSAgg[sel]
+		if !ok {
+			continue
+		}
+		if pmap, ok := pp.(ki.Props); ok {
+			if val, has := pmap[key]; has {
+				got = set(StyleSrcCSS, val, sel)
+			}
+		}
+	}
+	return got
+}
+
+// styleAppSource calls set with the value of given key in the last rule of
+// the AppStyles that matches given widget, in the order applied by
+// ApplyAppStyles, returning true if found
+func styleAppSource(wb *WidgetBase, key string, set func(src StyleSources, val interface{}, from string) bool) bool {
+	if len(AppStyles) == 0 {
+		return false
+	}
+	gii, _ := wb.This().(Node2D)
+	var rules []*StyleRule
+	for _, sr := range AppStyles {
+		rules = sr.MatchRules(gii, "", rules)
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Spec < rules[j].Spec
+	})
+	got := false
+	for _, r := range rules {
+		if val, has := r.Props[key]; has {
+			got = set(StyleSrcApp, val, r.Selector)
+		}
+	}
+	return got
+}
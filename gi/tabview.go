@@ -6,14 +6,20 @@ package gi
 
 import (
 	"fmt"
+	"image"
 	"log"
 	"reflect"
 	"sync"
 
 	"github.com/goki/gi/gist"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/dnd"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/gi/oswin/mouse"
 	"github.com/goki/gi/units"
 	"github.com/goki/ki/ki"
 	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
 )
 
 // TabView switches among child widgets via tabs.  The selected widget gets
@@ -25,12 +31,15 @@ import (
 // max stretch and a set preferred size, so it expands.
 type TabView struct {
 	Layout
-	MaxChars     int          `desc:"maximum number of characters to include in tab label -- elides labels that are longer than that"`
-	TabViewSig   ki.Signal    `copy:"-" json:"-" xml:"-" desc:"signal for tab widget -- see TabViewSignals for the types"`
-	NewTabButton bool         `desc:"show a new tab button at right of list of tabs"`
-	NoDeleteTabs bool         `desc:"if true, tabs are not user-deleteable"`
-	NewTabType   reflect.Type `desc:"type of widget to create in a new tab via new tab button -- Frame by default"`
-	Mu           sync.Mutex   `copy:"-" json:"-" xml:"-" view:"-" desc:"mutex protecting updates to tabs -- tabs can be driven programmatically and via user input so need extra protection"`
+	MaxChars      int          `desc:"maximum number of characters to include in tab label -- elides labels that are longer than that"`
+	TabViewSig    ki.Signal    `copy:"-" json:"-" xml:"-" desc:"signal for tab widget -- see TabViewSignals for the types"`
+	NewTabButton  bool         `desc:"show a new tab button at right of list of tabs"`
+	NoDeleteTabs  bool         `desc:"if true, tabs are not user-deleteable"`
+	NoReorderTabs bool         `desc:"if true, tabs cannot be reordered by dragging them"`
+	SingleRow     bool         `desc:"show the tabs in a single row, with a menu button for selecting the tabs that do not fit, instead of flowing them onto more rows -- must be set before adding tabs"`
+	NewTabType    reflect.Type `desc:"type of widget to create in a new tab via new tab button -- Frame by default"`
+	Mu            sync.Mutex   `copy:"-" json:"-" xml:"-" view:"-" desc:"mutex protecting updates to tabs -- tabs can be driven programmatically and via user input so need extra protection"`
+	closeVetoed   bool         `desc:"set by VetoClose during TabCloseRequested"`
 }
 
 var KiT_TabView = kit.Types.AddType(&TabView{}, TabViewProps)
@@ -45,6 +54,8 @@ func (tv *TabView) CopyFieldsFrom(frm interface{}) {
 	tv.Layout.CopyFieldsFrom(&fr.Layout)
 	tv.MaxChars = fr.MaxChars
 	tv.NewTabButton = fr.NewTabButton
+	tv.NoReorderTabs = fr.NoReorderTabs
+	tv.SingleRow = fr.SingleRow
 	tv.NewTabType = fr.NewTabType
 }
 
@@ -352,19 +363,188 @@ func (tv *TabView) DeleteTabIndexAction(idx int) {
 	}
 }
 
+// CloseTabIndexAction is called when the user closes the tab at given
+// index, with its close button or by middle-clicking it: emits
+// TabCloseRequested with the index, and then deletes the tab with
+// DeleteTabIndexAction, unless a receiver of the signal called VetoClose,
+// e.g., to ask the user about saving a modified document first
+func (tv *TabView) CloseTabIndexAction(idx int) {
+	tv.closeVetoed = false
+	tv.TabViewSig.Emit(tv.This(), int64(TabCloseRequested), idx)
+	if tv.closeVetoed {
+		tv.closeVetoed = false
+		return
+	}
+	tv.DeleteTabIndexAction(idx)
+}
+
+// VetoClose prevents the tab from being closed, when called by a receiver
+// of the TabCloseRequested signal
+func (tv *TabView) VetoClose() {
+	tv.closeVetoed = true
+}
+
+// TabModifiedMark is shown before the label of tabs that are marked as
+// modified with SetTabModified
+var TabModifiedMark = "* "
+
+// SetTabModified marks the tab at given index as modified (or not), e.g.,
+// for a document with unsaved changes -- modified tabs show TabModifiedMark
+// before their label
+func (tv *TabView) SetTabModified(idx int, mod bool) {
+	_, tab, ok := tv.TabAtIndex(idx)
+	if !ok || tab.Modified == mod {
+		return
+	}
+	tab.Modified = mod
+	updt := tv.UpdateStart()
+	tv.SetFullReRender()
+	if mod {
+		tab.SetText(TabModifiedMark + tab.Nm)
+	} else {
+		tab.SetText(tab.Nm)
+	}
+	tv.UpdateEnd(updt)
+}
+
+// TabModified returns true if the tab at given index is marked as modified
+func (tv *TabView) TabModified(idx int) bool {
+	_, tab, ok := tv.TabAtIndex(idx)
+	if !ok {
+		return false
+	}
+	return tab.Modified
+}
+
+// MoveTab moves the tab at given index to another index, keeping the same
+// tab selected -- returns false if either index is out of range
+func (tv *TabView) MoveTab(from, to int) bool {
+	sz := tv.NTabs()
+	if from < 0 || from >= sz || to < 0 || to >= sz {
+		return false
+	}
+	if from == to {
+		return true
+	}
+	tv.Mu.Lock()
+	fr := tv.Frame()
+	tb := tv.Tabs()
+	updt := tv.UpdateStart()
+	tv.SetFullReRender()
+	sel := fr.StackTop
+	switch {
+	case sel == from:
+		sel = to
+	case from < sel && to >= sel:
+		sel--
+	case from > sel && to <= sel:
+		sel++
+	}
+	fr.MoveChild(from, to)
+	tb.MoveChild(from, to)
+	fr.StackTop = sel
+	tv.RenumberTabs()
+	tv.Mu.Unlock()
+	tv.UpdateEnd(updt)
+	return true
+}
+
+// MoveTabAction moves the tab at given index to another index and emits
+// TabMoved signal with the new index -- this is what is called when a tab is
+// dragged to a new position
+func (tv *TabView) MoveTabAction(from, to int) {
+	if from != to && tv.MoveTab(from, to) {
+		tv.TabViewSig.Emit(tv.This(), int64(TabMoved), to)
+	}
+}
+
+// TabIndexAt returns the index of the tab whose button is at given window
+// position, or -1 if none
+func (tv *TabView) TabIndexAt(pos image.Point) int {
+	tb := tv.Tabs()
+	for i := 0; i < tv.NTabs(); i++ {
+		_, ni := KiToNode2D(tb.Child(i))
+		if ni != nil && ni.IsVisible() && ni.PosInWinBBox(pos) {
+			return i
+		}
+	}
+	return -1
+}
+
+// TabMimeType is the mime type of the data for dragging tabs to reorder
+// them, which is the name of the tab
+const TabMimeType = "application/x-gogi-tab"
+
+// TabDragStart starts dragging the tab at given index
+func (tv *TabView) TabDragStart(idx int) {
+	_, tab, ok := tv.TabAtIndex(idx)
+	if !ok {
+		return
+	}
+	sp := &Sprite{}
+	sp.GrabRenderFrom(tab)
+	ImageClearer(sp.Pixels, 50.0)
+	tab.SetButtonState(ButtonActive) // it is not released on the tab
+	tv.ParentWindow().StartDragNDrop(tv.This(), mimedata.NewMime(TabMimeType, []byte(tab.Nm)), sp)
+}
+
+// TabDrop moves a tab dragged from this tabview to the position where it
+// was dropped -- returns false if it is not from this tabview
+func (tv *TabView) TabDrop(de *dnd.Event) bool {
+	win := tv.ParentWindow()
+	if win == nil || win.EventMgr.DNDSource != tv.This() || !de.Data.HasType(TabMimeType) {
+		return false
+	}
+	from, err := tv.TabIndexByName(string(de.Data.TypeData(TabMimeType)))
+	if err != nil {
+		return false
+	}
+	to := tv.TabIndexAt(de.Where)
+	if to < 0 {
+		if !tv.Tabs().PosInWinBBox(de.Where) {
+			return false
+		}
+		to = tv.NTabs() - 1 // after the last tab
+	}
+	de.SetProcessed()
+	win.ClearDragNDrop()
+	tv.MoveTabAction(from, to)
+	return true
+}
+
+// TabDNDEvents connects the drag-n-drop events for reordering the tabs
+func (tv *TabView) TabDNDEvents() {
+	tv.ConnectEvent(oswin.DNDEvent, RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		de := d.(*dnd.Event)
+		tvv := recv.Embed(KiT_TabView).(*TabView)
+		if tvv.NoReorderTabs {
+			return
+		}
+		switch de.Action {
+		case dnd.Start:
+			if idx := tvv.TabIndexAt(de.Where); idx >= 0 {
+				de.SetProcessed()
+				tvv.TabDragStart(idx)
+			}
+		case dnd.DropOnTarget:
+			tvv.TabDrop(de)
+		}
+	})
+}
+
 // ConfigNewTabButton configures the new tab + button at end of list of tabs
 func (tv *TabView) ConfigNewTabButton() bool {
 	sz := tv.NTabs()
 	tb := tv.Tabs()
-	ntb := len(tb.Kids)
+	ni, has := tb.Children().IndexByName("new-tab", sz)
 	if tv.NewTabButton {
-		if ntb == sz+1 {
+		if has {
 			return false
 		}
 		if tv.NewTabType == nil {
 			tv.NewTabType = KiT_Frame
 		}
-		tab := tb.InsertNewChild(KiT_Action, ntb, "new-tab").(*Action)
+		tab := tb.InsertNewChild(KiT_Action, sz, "new-tab").(*Action)
 		tab.Data = -1
 		tab.SetIcon("plus")
 		tab.ActionSig.ConnectOnly(tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
@@ -374,12 +554,132 @@ func (tv *TabView) ConfigNewTabButton() bool {
 		})
 		return true
 	} else {
-		if ntb == sz {
+		if !has {
 			return false
 		}
-		tb.DeleteChildAtIndex(ntb-1, ki.DestroyKids) // always destroy -- we manage
+		tb.DeleteChildAtIndex(ni, ki.DestroyKids) // always destroy -- we manage
+		return true
+	}
+}
+
+// ConfigTabsMenu configures the menu button at the end of the list of tabs,
+// for selecting the tabs that do not fit in a SingleRow
+func (tv *TabView) ConfigTabsMenu() bool {
+	tb := tv.Tabs()
+	mi, has := tb.Children().IndexByName("tabs-menu", tv.NTabs())
+	if tv.SingleRow == has {
+		return false
+	}
+	if !tv.SingleRow {
+		tb.DeleteChildAtIndex(mi, ki.DestroyKids)
+		tb.SetProp("spacing", units.NewPx(4))
 		return true
 	}
+	tb.SetProp("spacing", units.NewPx(0)) // hidden tabs would still be spaced
+	mb := AddNewAction(tb, "tabs-menu")
+	mb.Data = -1
+	mb.SetIcon("wedge-down")
+	mb.Tooltip = "all tabs"
+	mb.MakeMenuFunc = func(obj ki.Ki, m *Menu) {
+		tvv := obj.Parent().Parent().Embed(KiT_TabView).(*TabView)
+		*m = make(Menu, 0, tvv.NTabs())
+		tvv.MakeTabsMenu(m)
+	}
+	return true
+}
+
+// MakeTabsMenu adds an action for selecting each tab to given menu
+func (tv *TabView) MakeTabsMenu(m *Menu) {
+	tb := tv.Tabs()
+	for i := 0; i < tv.NTabs(); i++ {
+		tab := tb.Child(i).Embed(KiT_TabButton).(*TabButton)
+		m.AddAction(ActOpts{Label: tab.Text, Data: i}, tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			tvv := recv.Embed(KiT_TabView).(*TabView)
+			tvv.SelectTabIndexAction(data.(int))
+		})
+	}
+}
+
+// LayoutSingleRow hides the tabs that do not fit in a SingleRow, keeping
+// the selected tab shown, and shows the tabs menu if any are hidden --
+// called prior to laying out the tabs, when their sizes are known
+func (tv *TabView) LayoutSingleRow() {
+	if !tv.SingleRow {
+		return
+	}
+	tb := tv.Tabs()
+	tb.Lay = LayoutHoriz
+	sz := tv.NTabs()
+	avail := tv.LayState.Alloc.Size.X - 2*tv.BoxSpace() - 2*tb.BoxSpace()
+	var mb *WidgetBase
+	for _, k := range tb.Kids[sz:] {
+		wb := k.(Node2D).AsWidget()
+		if k.Name() == "tabs-menu" {
+			mb = wb
+			continue
+		}
+		avail -= mat32.Max(wb.LayState.Size.Need.X, wb.LayState.Size.Pref.X)
+	}
+	total := float32(0)
+	for i := 0; i < sz; i++ {
+		total += tb.Child(i).Embed(KiT_TabButton).(*TabButton).natWidth()
+	}
+	first, last := 0, sz-1
+	if total > avail && mb != nil {
+		if mb.IsInvisible() {
+			mb.ClearInvisible()
+			mb.LayState.SetFromStyle(&mb.Sty.Layout)
+			mb.Size2D(0)
+		}
+		avail -= mat32.Max(mb.LayState.Size.Need.X, mb.LayState.Size.Pref.X)
+		sel := tv.Frame().StackTop
+		if sel < 0 || sel >= sz {
+			sel = 0
+		}
+		width := func(i int) float32 {
+			return tb.Child(i).Embed(KiT_TabButton).(*TabButton).natWidth()
+		}
+		w := float32(0)
+		last = -1
+		for last+1 < sz && w+width(last+1) <= avail {
+			last++
+			w += width(last)
+		}
+		for last < sel { // shift the shown tabs to include the selected one
+			last++
+			w += width(last)
+			for w > avail && first < last {
+				w -= width(first)
+				first++
+			}
+		}
+	} else if mb != nil {
+		mb.SetInvisible()
+		mb.LayState.Size.Need.X = 0
+		mb.LayState.Size.Pref.X = 0
+	}
+	for i := 0; i < sz; i++ {
+		tab := tb.Child(i).Embed(KiT_TabButton).(*TabButton)
+		if i >= first && i <= last {
+			tab.ClearInvisible()
+			tab.LayState.Size.Need.X = tab.natNeed.X
+			tab.LayState.Size.Pref.X = tab.natPref.X
+			tab.LayState.UpdateSizes()
+		} else {
+			tab.SetInvisible()
+			tab.LayState.Size.Need.X = 0
+			tab.LayState.Size.Pref.X = 0
+		}
+	}
+	// sizes of the tabs frame along the row, as in GatherSizes
+	need, pref := 2*tb.BoxSpace(), 2*tb.BoxSpace()
+	for _, k := range tb.Kids {
+		wb := k.(Node2D).AsWidget()
+		need += wb.LayState.Size.Need.X
+		pref += wb.LayState.Size.Pref.X
+	}
+	tb.LayState.Size.Need.X = need
+	tb.LayState.Size.Pref.X = pref
 }
 
 // TabViewSignals are signals that the TabView can send
@@ -395,6 +695,15 @@ const (
 	// TabDeleted indicates tab was deleted -- data is the tab name
 	TabDeleted
 
+	// TabCloseRequested indicates that the user asked to close a tab, with
+	// its close button or by middle-clicking it -- data is the tab index --
+	// the tab is then deleted unless a receiver calls VetoClose
+	TabCloseRequested
+
+	// TabMoved indicates tab was dragged to a new position -- data is the
+	// new tab index
+	TabMoved
+
 	TabViewSignalsN
 )
 
@@ -435,6 +744,7 @@ func (tv *TabView) InitTabView() {
 	frame.SetReRenderAnchor()
 
 	tv.ConfigNewTabButton()
+	tv.ConfigTabsMenu()
 
 	tv.UpdateEnd(updt)
 }
@@ -495,6 +805,9 @@ func (tv *TabView) RenderTabSeps() {
 	for i := 1; i < sz; i++ {
 		tb := tbs.Child(i).(Node2D)
 		ni := tb.AsWidget()
+		if ni.IsInvisible() {
+			continue
+		}
 
 		pos := ni.LayState.Alloc.Pos
 		sz := ni.LayState.Alloc.Size.AddScalar(-2.0 * st.Layout.Margin.Dots)
@@ -518,6 +831,16 @@ func (tv *TabView) Render2D() {
 	}
 }
 
+func (tv *TabView) Layout2D(parBBox image.Rectangle, iter int) bool {
+	tv.LayoutSingleRow()
+	return tv.Layout.Layout2D(parBBox, iter)
+}
+
+func (tv *TabView) ConnectEvents2D() {
+	tv.Layout.ConnectEvents2D()
+	tv.TabDNDEvents()
+}
+
 ////////////////////////////////////////////////////////////////////////////////////////
 // TabButton
 
@@ -525,7 +848,10 @@ func (tv *TabView) Render2D() {
 // icon is used for close icon.
 type TabButton struct {
 	Action
-	NoDelete bool `desc:"if true, this tab does not have the delete button avail"`
+	NoDelete bool       `desc:"if true, this tab does not have the delete button avail"`
+	Modified bool       `desc:"if true, the contents of this tab have been modified -- see TabView.SetTabModified"`
+	natNeed  mat32.Vec2 `desc:"needed size, prior to hiding the tab in a TabView SingleRow"`
+	natPref  mat32.Vec2 `desc:"preferred size, prior to hiding the tab in a TabView SingleRow"`
 }
 
 var KiT_TabButton = kit.Types.AddType(&TabButton{}, TabButtonProps)
@@ -606,6 +932,47 @@ func (tb *TabButton) TabView() *TabView {
 	return tv.Embed(KiT_TabView).(*TabView)
 }
 
+// AccessInfo describes the tab for assistive technologies
+func (tb *TabButton) AccessInfo(an *oswin.AccessNode) {
+	tb.ButtonBase.AccessInfo(an)
+	an.Role = oswin.RoleTab
+}
+
+func (tb *TabButton) Size2D(iter int) {
+	tb.Action.Size2D(iter)
+	tb.LayState.UpdateSizes() // includes min-width
+	tb.natNeed = tb.LayState.Size.Need
+	tb.natPref = tb.LayState.Size.Pref
+}
+
+// natWidth returns the width of the tab prior to hiding it
+func (tb *TabButton) natWidth() float32 {
+	return mat32.Max(tb.natNeed.X, tb.natPref.X)
+}
+
+// TabMouseEvent closes the tab when it is middle-clicked
+func (tb *TabButton) TabMouseEvent() {
+	tb.ConnectEvent(oswin.MouseEvent, HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.Event)
+		tbb := recv.Embed(KiT_TabButton).(*TabButton)
+		if me.Button != mouse.Middle || tbb.NoDelete {
+			return
+		}
+		me.SetProcessed()
+		if me.Action != mouse.Release {
+			return
+		}
+		if tvv := tbb.TabView(); tvv != nil {
+			tvv.CloseTabIndexAction(tbb.Data.(int))
+		}
+	})
+}
+
+func (tb *TabButton) ConnectEvents2D() {
+	tb.Action.ConnectEvents2D()
+	tb.TabMouseEvent()
+}
+
 func (tb *TabButton) ConfigParts() {
 	tb.Parts.SetProp("overflow", gist.OverflowHidden) // no scrollbars!
 	if !tb.NoDelete {
@@ -639,7 +1006,7 @@ func (tb *TabButton) ConfigPartsDeleteButton() {
 			tvv := tb.TabView()
 			if tvv != nil {
 				if tbb.IsSelected() { // only process delete when already selected
-					tvv.DeleteTabIndexAction(tabIdx)
+					tvv.CloseTabIndexAction(tabIdx)
 				} else {
 					tvv.SelectTabIndexAction(tabIdx) // otherwise select
 				}
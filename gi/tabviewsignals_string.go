@@ -16,12 +16,14 @@ func _() {
 	_ = x[TabSelected-0]
 	_ = x[TabAdded-1]
 	_ = x[TabDeleted-2]
-	_ = x[TabViewSignalsN-3]
+	_ = x[TabCloseRequested-3]
+	_ = x[TabMoved-4]
+	_ = x[TabViewSignalsN-5]
 }
 
-const _TabViewSignals_name = "TabSelectedTabAddedTabDeletedTabViewSignalsN"
+const _TabViewSignals_name = "TabSelectedTabAddedTabDeletedTabCloseRequestedTabMovedTabViewSignalsN"
 
-var _TabViewSignals_index = [...]uint8{0, 11, 19, 29, 44}
+var _TabViewSignals_index = [...]uint8{0, 11, 19, 29, 46, 54, 69}
 
 func (i TabViewSignals) String() string {
 	if i < 0 || i >= TabViewSignals(len(_TabViewSignals_index)-1) {
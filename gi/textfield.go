@@ -18,6 +18,7 @@ import (
 	"github.com/goki/gi/gist"
 	"github.com/goki/gi/oswin"
 	"github.com/goki/gi/oswin/cursor"
+	"github.com/goki/gi/oswin/ime"
 	"github.com/goki/gi/oswin/key"
 	"github.com/goki/gi/oswin/mimedata"
 	"github.com/goki/gi/oswin/mouse"
@@ -62,6 +63,7 @@ type TextField struct {
 	TextFieldSig ki.Signal                    `copy:"-" json:"-" xml:"-" view:"-" desc:"signal for line edit -- see TextFieldSignals for the types"`
 	RenderAll    girl.Text                    `copy:"-" json:"-" xml:"-" desc:"render version of entire text, for sizing"`
 	RenderVis    girl.Text                    `copy:"-" json:"-" xml:"-" desc:"render version of just visible text"`
+	IME          IMEPreedit                   `copy:"-" json:"-" xml:"-" view:"-" desc:"input method (IME) composition state -- the composing text is displayed at the cursor"`
 	StateStyles  [TextFieldStatesN]gist.Style `copy:"-" json:"-" xml:"-" desc:"normal style and focus style"`
 	FontHeight   float32                      `copy:"-" json:"-" xml:"-" desc:"font height, cached during styling"`
 	BlinkOn      bool                         `copy:"-" json:"-" xml:"-" desc:"oscillates between on and off for blinking"`
@@ -93,6 +95,19 @@ func (tf *TextField) Disconnect() {
 	tf.TextFieldSig.DisconnectAll()
 }
 
+// AccessInfo describes the text field for assistive technologies -- the
+// value is concealed if NoEcho is set
+func (tf *TextField) AccessInfo(an *oswin.AccessNode) {
+	an.Role = oswin.RoleTextField
+	an.Name = tf.Placeholder
+	if tf.NoEcho {
+		an.Value = strings.Repeat("•", len(tf.EditTxt))
+	} else {
+		an.Value = string(tf.EditTxt)
+	}
+	an.SetState(!tf.IsInactive(), oswin.StateEditable)
+}
+
 var TextFieldProps = ki.Props{
 	"EnumType:Flag":    KiT_NodeFlags,
 	"border-width":     units.NewPx(1),
@@ -686,6 +701,13 @@ func (tf *TextField) SetCompleter(data interface{}, matchFun complete.MatchFunc,
 	})
 }
 
+// SetCompleteSource sets a completer that gets candidates from given
+// source function, and ranks them by fuzzy matching against the
+// identifier before the cursor (see CompleteFuzzy)
+func (tf *TextField) SetCompleteSource(data interface{}, src CompleteSourceFunc) {
+	tf.SetCompleter(data, CompleteFuzzy(src), CompleteEditWord)
+}
+
 // OfferComplete pops up a menu of possible completions
 func (tf *TextField) OfferComplete(forceComplete bool) {
 	if tf.Complete == nil {
@@ -767,10 +789,35 @@ func (tf *TextField) CharStartPos(charidx int, wincoords bool) mat32.Vec2 {
 		pos = pos.Add(mat32.NewVec2FmPoint(mvp.WinBBox.Min))
 		mvp.BBoxMu.RUnlock()
 	}
+	if sr := tf.VisBidi(); sr != nil && charidx >= tf.StartPos && charidx <= tf.EndPos {
+		return mat32.Vec2{pos.X + sr.CaretPosLR(charidx-tf.StartPos).X, pos.Y}
+	}
 	cpos := tf.TextWidth(tf.StartPos, charidx)
 	return mat32.Vec2{pos.X + cpos, pos.Y}
 }
 
+// VisBidi returns the rendered span of the visible text if it contains
+// bidirectional (e.g., Arabic or Hebrew) text, which has been reordered for
+// display, so positions within it must be computed from the span -- nil
+// otherwise
+func (tf *TextField) VisBidi() *girl.Span {
+	if len(tf.RenderVis.Spans) != 1 || tf.NoEcho {
+		return nil
+	}
+	sr := &tf.RenderVis.Spans[0]
+	if sr.BidiLevels == nil || len(sr.Text) != tf.EndPos-tf.StartPos {
+		return nil
+	}
+	return sr
+}
+
+// IsRTL returns true if the text has right-to-left paragraph direction
+// (e.g., starts with Arabic or Hebrew text), in which case the left and
+// right arrow keys move the cursor in the opposite logical direction
+func (tf *TextField) IsRTL() bool {
+	return !tf.NoEcho && girl.BidiOn && girl.ParaRTL(tf.EditTxt, &tf.Sty.Text)
+}
+
 // TextFieldBlinkMu is mutex protecting TextFieldBlink updating and access
 var TextFieldBlinkMu sync.Mutex
 
@@ -895,7 +942,10 @@ func (tf *TextField) RenderCursor(on bool) {
 	} else {
 		win.InactivateSprite(sp.Name)
 	}
-	sp.Geom.Pos = tf.CharStartPos(tf.CursorPos, true).ToPointFloor()
+	cpos := tf.CharStartPos(tf.CursorPos, true)
+	cpos.X += tf.IME.CursorOff()
+	sp.Geom.Pos = cpos.ToPointFloor()
+	IMEUpdate(win, !tf.IsInactive(), image.Rectangle{Min: sp.Geom.Pos, Max: sp.Geom.Pos.Add(sp.Geom.Size)})
 	win.RenderOverlays() // needs an explicit call!
 	win.UpdateSig()      // publish
 }
@@ -951,11 +1001,18 @@ func (tf *TextField) RenderSelect() {
 		return
 	}
 
-	spos := tf.CharStartPos(effst, false)
-
 	rs := &tf.Viewport.Render
 	pc := &rs.Paint
 	st := &tf.StateStyles[TextFieldSel]
+	if sr := tf.VisBidi(); sr != nil { // selection can be in multiple pieces
+		spos := tf.CharStartPos(tf.StartPos, false)
+		for _, bx := range sr.RangeBoxesLR(effst-tf.StartPos, effed-tf.StartPos) {
+			pc.FillBox(rs, mat32.Vec2{spos.X + bx[0], spos.Y}, mat32.Vec2{bx[1] - bx[0], tf.FontHeight}, &st.Font.BgColor)
+		}
+		return
+	}
+
+	spos := tf.CharStartPos(effst, false)
 	tsz := tf.TextWidth(effst, effed)
 	pc.FillBox(rs, spos, mat32.Vec2{tsz, tf.FontHeight}, &st.Font.BgColor)
 }
@@ -1056,6 +1113,10 @@ func (tf *TextField) PixelToCursor(pixOff float32) int {
 	spc := st.BoxSpace()
 	px := pixOff - spc
 
+	if sr := tf.VisBidi(); sr != nil {
+		return tf.StartPos + sr.CaretAtPosLR(px)
+	}
+
 	if px <= 0 {
 		return tf.StartPos
 	}
@@ -1146,11 +1207,19 @@ func (tf *TextField) KeyInput(kt *key.ChordEvent) {
 	switch kf {
 	case KeyFunMoveRight:
 		kt.SetProcessed()
-		tf.CursorForward(1)
+		if tf.IsRTL() {
+			tf.CursorBackward(1)
+		} else {
+			tf.CursorForward(1)
+		}
 		tf.OfferComplete(dontForce)
 	case KeyFunMoveLeft:
 		kt.SetProcessed()
-		tf.CursorBackward(1)
+		if tf.IsRTL() {
+			tf.CursorForward(1)
+		} else {
+			tf.CursorBackward(1)
+		}
 		tf.OfferComplete(dontForce)
 	case KeyFunHome:
 		kt.SetProcessed()
@@ -1344,6 +1413,37 @@ func (tf *TextField) TextFieldEvents() {
 	tf.MouseEvent()
 	tf.MouseFocusEvent()
 	tf.KeyChordEvent()
+	tf.IMEEvent()
+}
+
+// IMEEvent handles input method (IME) composition events
+func (tf *TextField) IMEEvent() {
+	tf.ConnectEvent(oswin.IMEEvent, RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		tff := recv.Embed(KiT_TextField).(*TextField)
+		tff.IMEInput(d.(*ime.Event))
+	})
+}
+
+// IMEInput handles an input method composition event: the composing text
+// is displayed at the cursor until it is committed, when it is inserted
+func (tf *TextField) IMEInput(e *ime.Event) {
+	if tf.IsInactive() {
+		return
+	}
+	e.SetProcessed()
+	switch e.Action {
+	case ime.Preedit:
+		tf.IME.SetText(e.Text, e.Cursor)
+		tf.UpdateSig()
+	case ime.Commit:
+		tf.IME.Clear()
+		if e.Text != "" {
+			tf.InsertAtCursor(e.Text)
+			tf.OfferComplete(dontForce)
+		} else {
+			tf.UpdateSig()
+		}
+	}
 }
 
 func (tf *TextField) ConfigParts() {
@@ -1403,6 +1503,7 @@ func (tf *TextField) StyleTextField() {
 		for i := 0; i < int(TextFieldStatesN); i++ {
 			tf.StateStyles[i].CopyFrom(&tf.Sty)
 			tf.StateStyles[i].SetStyleProps(pst, tf.StyleProps(TextFieldSelectors[i]), tf.Viewport)
+			ApplyAppStyles(tf.This().(Node2D), tf.Viewport, &tf.StateStyles[i], pst, TextFieldSelectors[i])
 			StyleCSS(tf.This().(Node2D), tf.Viewport, &tf.StateStyles[i], tf.CSSAgg, TextFieldSelectors[i])
 			tf.StateStyles[i].CopyUnitContext(&tf.Sty.UnContext)
 		}
@@ -1425,6 +1526,10 @@ func (tf *TextField) StyleTextField() {
 func (tf *TextField) Style2D() {
 	tf.StyleTextField()
 	tf.StyMu.Lock()
+	TouchTargetStyle(&tf.Sty)
+	for i := range tf.StateStyles {
+		TouchTargetStyle(&tf.StateStyles[i])
+	}
 	tf.LayState.SetFromStyle(&tf.Sty.Layout) // also does reset
 	tf.StyMu.Unlock()
 }
@@ -1437,6 +1542,10 @@ func (tf *TextField) UpdateRenderAll() bool {
 		txt = concealDots(len(tf.EditTxt))
 	}
 	tf.RenderAll.SetRunes(txt, &st.Font, &st.UnContext, &st.Text, true, 0, 0)
+	if sr := &tf.RenderAll.Spans[0]; sr.BidiLevels != nil {
+		// keep logical positions for widths -- RenderVis has display positions
+		sr.SetRunePosLR(st.Text.LetterSpacing.Dots, st.Text.WordSpacing.Dots, st.Font.Face.Metrics.Ch, st.Text.TabSize)
+	}
 	return true
 }
 
@@ -1505,19 +1614,22 @@ func (tf *TextField) RenderTextField() {
 	girl.OpenFont(&st.Font, &st.UnContext)
 	tf.RenderStdBox(st)
 	cur := tf.EditTxt[tf.StartPos:tf.EndPos]
-	tf.RenderSelect()
 	pos := tf.LayState.Alloc.Pos.AddScalar(st.BoxSpace())
+	// visible text is laid out before the selection, which depends on it
 	if len(tf.EditTxt) == 0 && len(tf.Placeholder) > 0 {
 		st.Font.Color = st.Font.Color.Highlight(50)
 		tf.RenderVis.SetString(tf.Placeholder, &st.Font, &st.UnContext, &st.Text, true, 0, 0)
-		tf.RenderVis.RenderTopPos(rs, pos)
-
 	} else {
 		if tf.NoEcho {
 			cur = concealDots(len(cur))
 		}
 		tf.RenderVis.SetRunes(cur, &st.Font, &st.UnContext, &st.Text, true, 0, 0)
-		tf.RenderVis.RenderTopPos(rs, pos)
+	}
+	tf.RenderSelect()
+	tf.RenderVis.RenderTopPos(rs, pos)
+	if tf.IME.IsActive() {
+		tf.IME.Layout(st)
+		tf.IME.RenderAt(rs, tf.CharStartPos(tf.CursorPos, false), st, tf.FontHeight)
 	}
 }
 
@@ -1554,6 +1666,8 @@ func (tf *TextField) FocusChanged2D(change FocusChanges) {
 	switch change {
 	case FocusLost:
 		tf.ClearFlag(int(TextFieldFocusActive))
+		tf.IME.Clear()
+		IMEUpdate(tf.ParentWindow(), false, image.Rectangle{})
 		tf.EditDone()
 		tf.UpdateSig()
 	case FocusGot:
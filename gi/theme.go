@@ -0,0 +1,84 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"fmt"
+
+	"github.com/goki/gi/gist"
+)
+
+// Theme is a named combination of colors and app-wide style sheets that can
+// be switched at runtime using SetTheme, taking effect immediately in all
+// open windows.
+type Theme struct {
+	Name   string        `desc:"name of the theme"`
+	Colors *ColorPrefs   `desc:"colors used in the default styles, replacing Prefs.Colors -- if nil, current colors are kept"`
+	Styles []*StyleRules `desc:"app-wide style sheets, replacing AppStyles -- if nil, current AppStyles are kept"`
+}
+
+// Themes are the named themes available for SetThemeName, in addition to
+// the Prefs.ColorSchemes (e.g., Light, Dark)
+var Themes = map[string]*Theme{}
+
+// CurTheme is the theme most recently set by SetTheme, if any
+var CurTheme *Theme
+
+// AddTheme adds given theme to Themes, under its Name
+func AddTheme(th *Theme) {
+	Themes[th.Name] = th
+}
+
+// SetTheme sets the colors and app-wide style sheets of given theme, and
+// re-styles all open windows so the change takes effect immediately.
+// Prefs.FollowOSTheme is turned off, so that the theme is not replaced
+// when the OS appearance changes -- the theme is not saved in the prefs.
+func SetTheme(th *Theme) {
+	if th.Colors != nil {
+		Prefs.Colors = *th.Colors
+		if Prefs.Colors.HiStyle != "" {
+			TheViewIFace.SetHiStyleDefault(Prefs.Colors.HiStyle)
+		}
+		Prefs.FollowOSTheme = false
+	}
+	if th.Styles != nil {
+		AppStyles = th.Styles
+	}
+	CurTheme = th
+	RestyleAll()
+}
+
+// SetThemeName sets the theme of given name, from Themes or else the color
+// scheme of that name in Prefs.ColorSchemes -- see SetTheme
+func SetThemeName(name string) error {
+	if th, ok := Themes[name]; ok {
+		SetTheme(th)
+		return nil
+	}
+	if cs, ok := Prefs.ColorSchemes[name]; ok {
+		SetTheme(&Theme{Name: name, Colors: cs})
+		return nil
+	}
+	return fmt.Errorf("gi.SetThemeName: theme named: %v not found", name)
+}
+
+// RestyleAll re-resolves all style properties and re-renders all open
+// windows -- the cached default styles and colors are rebuilt from the
+// current Prefs.Colors and AppStyles.
+func RestyleAll() {
+	gist.RebuildDefaultStyles = true
+	gist.ColorSpecCache = nil
+	gist.StyleTemplatesMu.Lock()
+	gist.StyleTemplates = nil
+	gist.StyleTemplatesMu.Unlock()
+	for _, w := range AllWindows {
+		w.FullReRender()
+	}
+	gist.RebuildDefaultStyles = false
+	// and another without rebuilding -- required to get it right
+	for _, w := range AllWindows {
+		w.FullReRender()
+	}
+}
@@ -0,0 +1,479 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"image"
+	"time"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////
+// TimePicker
+
+// TimePicker has SpinBoxes for the hour, minute and optionally the second
+// of a time of day -- all configured within the Parts of the widget.  The
+// date of the Time is kept.
+type TimePicker struct {
+	PartsWidgetBase
+	Time          time.Time `xml:"time" desc:"current time -- only the time of day is edited"`
+	ShowSecs      bool      `xml:"show-secs" desc:"prop = show-secs -- show and edit the seconds"`
+	TimePickerSig ki.Signal `copy:"-" json:"-" xml:"-" view:"-" desc:"signal for time picker -- has no signal types, just emitted when the time changes"`
+}
+
+var KiT_TimePicker = kit.Types.AddType(&TimePicker{}, TimePickerProps)
+
+// AddNewTimePicker adds a new time picker to given parent node, with given name.
+func AddNewTimePicker(parent ki.Ki, name string) *TimePicker {
+	return parent.AddNewChild(KiT_TimePicker, name).(*TimePicker)
+}
+
+func (tp *TimePicker) CopyFieldsFrom(frm interface{}) {
+	fr := frm.(*TimePicker)
+	tp.PartsWidgetBase.CopyFieldsFrom(&fr.PartsWidgetBase)
+	tp.Time = fr.Time
+	tp.ShowSecs = fr.ShowSecs
+}
+
+func (tp *TimePicker) Disconnect() {
+	tp.PartsWidgetBase.Disconnect()
+	tp.TimePickerSig.DisconnectAll()
+}
+
+// AccessInfo describes the time picker for assistive technologies
+func (tp *TimePicker) AccessInfo(an *oswin.AccessNode) {
+	an.Role = oswin.RoleGroup
+	an.Value = tp.TimeString()
+}
+
+var TimePickerProps = ki.Props{
+	"EnumType:Flag": KiT_NodeFlags,
+	"#sep-minute": ki.Props{
+		"vertical-align": gist.AlignMiddle,
+		"margin":         units.NewPx(0),
+		"padding":        units.NewPx(0),
+	},
+	"#sep-second": ki.Props{
+		"vertical-align": gist.AlignMiddle,
+		"margin":         units.NewPx(0),
+		"padding":        units.NewPx(0),
+	},
+}
+
+// TimeFormat returns the time.Format layout of the time shown
+func (tp *TimePicker) TimeFormat() string {
+	if tp.ShowSecs {
+		return "15:04:05"
+	}
+	return "15:04"
+}
+
+// TimeString returns the time of day, as shown
+func (tp *TimePicker) TimeString() string {
+	return tp.Time.Format(tp.TimeFormat())
+}
+
+// SetTime sets the time, and updates the display
+func (tp *TimePicker) SetTime(tm time.Time) {
+	updt := tp.UpdateStart()
+	tp.Time = tm
+	tp.UpdateEnd(updt)
+}
+
+// SetClockAction sets the time of day, keeping the date, updates the
+// display and emits the signal
+func (tp *TimePicker) SetClockAction(hour, min, sec int) {
+	tm := tp.Time
+	tp.SetTime(time.Date(tm.Year(), tm.Month(), tm.Day(), hour, min, sec, 0, tm.Location()))
+	tp.ConfigPartsIfNeeded()
+	tp.TimePickerSig.Emit(tp.This(), 0, tp.Time)
+}
+
+// timeSpinNames are the names of the SpinBoxes, and their max values
+var timeSpinNames = []string{"hour", "minute", "second"}
+var timeSpinMaxs = []float32{23, 59, 59}
+
+func (tp *TimePicker) ConfigParts() {
+	tp.Parts.Lay = LayoutHoriz
+	tp.Parts.SetProp("vertical-align", gist.AlignMiddle)
+	config := kit.TypeAndNameList{}
+	config.Add(KiT_SpinBox, "hour")
+	config.Add(KiT_Label, "sep-minute")
+	config.Add(KiT_SpinBox, "minute")
+	if tp.ShowSecs {
+		config.Add(KiT_Label, "sep-second")
+		config.Add(KiT_SpinBox, "second")
+	}
+	mods, updt := tp.Parts.ConfigChildren(config, ki.NonUniqueNames)
+	if mods || gist.RebuildDefaultStyles {
+		for _, ptk := range *tp.Parts.Children() {
+			switch pt := ptk.(type) {
+			case *Label:
+				pt.Text = ":"
+				tp.StylePart(Node2D(pt))
+			case *SpinBox:
+				tp.ConfigSpinBox(pt)
+			}
+		}
+		tp.UpdateEnd(updt)
+	}
+}
+
+// ConfigSpinBox configures the SpinBox of the hour, minute or second
+func (tp *TimePicker) ConfigSpinBox(sb *SpinBox) {
+	mx := float32(59)
+	for i, nm := range timeSpinNames {
+		if sb.Nm == nm {
+			mx = timeSpinMaxs[i]
+		}
+	}
+	sb.Defaults()
+	sb.Step = 1
+	sb.PageStep = 10
+	sb.Format = "%d"
+	sb.SetMinMax(true, 0, true, mx)
+	sb.SetFlagState(tp.IsInactive(), int(Inactive))
+	sb.ConfigParts()
+	if tf, ok := sb.Parts.ChildByName("text-field", 0).(*TextField); ok {
+		tf.SetProp("min-width", units.NewCh(3))
+		tf.SetProp("width", units.NewCh(3))
+	}
+	tp.StylePart(Node2D(sb))
+	sb.SpinBoxSig.ConnectOnly(tp.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		tpr := recv.Embed(KiT_TimePicker).(*TimePicker)
+		h, m, s := tpr.Time.Clock()
+		vl := int(send.(*SpinBox).Value)
+		switch send.Name() {
+		case "hour":
+			h = vl
+		case "minute":
+			m = vl
+		case "second":
+			s = vl
+		}
+		tpr.SetClockAction(h, m, s)
+	})
+}
+
+func (tp *TimePicker) ConfigPartsIfNeeded() {
+	if !tp.Parts.HasChildren() {
+		tp.ConfigParts()
+	}
+	h, m, s := tp.Time.Clock()
+	vals := []int{h, m, s}
+	for i, nm := range timeSpinNames {
+		if sb, ok := tp.Parts.ChildByName(nm, i*2).(*SpinBox); ok {
+			if int(sb.Value) != vals[i] {
+				sb.SetValue(float32(vals[i]))
+			}
+		}
+	}
+}
+
+func (tp *TimePicker) Init2D() {
+	tp.Init2DWidget()
+	tp.ConfigParts()
+}
+
+// StyleFromProps styles TimePicker-specific fields from ki.Prop properties
+// doesn't support inherit or default
+func (tp *TimePicker) StyleFromProps(props ki.Props, vp *Viewport2D) {
+	if bv, ok := props["show-secs"]; ok {
+		if b, ok := kit.ToBool(bv); ok {
+			tp.ShowSecs = b
+		}
+	}
+}
+
+func (tp *TimePicker) Style2D() {
+	tp.StyMu.Lock()
+	tp.Style2DWidget()
+	tp.StyleFromProps(tp.Props, tp.Viewport)
+	tp.LayState.SetFromStyle(&tp.Sty.Layout) // also does reset
+	tp.StyMu.Unlock()
+	tp.ConfigParts()
+}
+
+func (tp *TimePicker) Size2D(iter int) {
+	tp.Size2DParts(iter)
+}
+
+func (tp *TimePicker) Layout2D(parBBox image.Rectangle, iter int) bool {
+	tp.ConfigPartsIfNeeded()
+	tp.Layout2DBase(parBBox, true, iter) // init style
+	tp.Layout2DParts(parBBox, iter)
+	return tp.Layout2DChildren(iter)
+}
+
+func (tp *TimePicker) Render2D() {
+	if tp.FullReRenderIfNeeded() {
+		return
+	}
+	if tp.PushBounds() {
+		tp.This().(Node2D).ConnectEvents2D()
+		tp.ConfigPartsIfNeeded()
+		tp.Render2DChildren()
+		tp.Render2DParts()
+		tp.PopBounds()
+	} else {
+		tp.DisconnectAllEvents(RegPri)
+	}
+}
+
+func (tp *TimePicker) ConnectEvents2D() {
+	tp.HoverTooltipEvent()
+}
+
+func (tp *TimePicker) HasFocus2D() bool {
+	if tp.IsInactive() {
+		return false
+	}
+	return tp.ContainsFocus() // needed for getting key events
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+// DurationField
+
+// DurationUnit is a unit for showing a time.Duration in a DurationField
+type DurationUnit struct {
+	Name string        `desc:"name of the unit, as used by time.Duration String, e.g., ms"`
+	Dur  time.Duration `desc:"duration of one unit"`
+}
+
+// DurationUnits are the units that can be chosen in a DurationField, in
+// increasing order of duration
+var DurationUnits = []DurationUnit{
+	{"ns", time.Nanosecond},
+	{"µs", time.Microsecond},
+	{"ms", time.Millisecond},
+	{"s", time.Second},
+	{"m", time.Minute},
+	{"h", time.Hour},
+}
+
+// DurationUnitByName returns the index in DurationUnits of the unit of
+// given name -- us is accepted for µs -- -1 if not found
+func DurationUnitByName(name string) int {
+	if name == "us" {
+		name = "µs"
+	}
+	for i, du := range DurationUnits {
+		if du.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// DurationUnitFor returns the index in DurationUnits of the largest unit
+// in which given duration is at least 1, e.g., ms for 150ms -- seconds for 0
+func DurationUnitFor(d time.Duration) int {
+	if d == 0 {
+		return DurationUnitByName("s")
+	}
+	if d < 0 {
+		d = -d
+	}
+	for i := len(DurationUnits) - 1; i > 0; i-- {
+		if d >= DurationUnits[i].Dur {
+			return i
+		}
+	}
+	return 0
+}
+
+// DurationField has a SpinBox for the value of a time.Duration, in a unit
+// that is chosen with a ComboBox next to it -- all configured within the
+// Parts of the widget.
+type DurationField struct {
+	PartsWidgetBase
+	Duration         time.Duration `xml:"duration" desc:"current duration"`
+	Unit             string        `xml:"unit" desc:"prop = unit -- name of the unit in DurationUnits in which the duration is shown, e.g., ms -- blank uses the largest unit in which the duration is at least 1"`
+	DurationFieldSig ki.Signal     `copy:"-" json:"-" xml:"-" view:"-" desc:"signal for duration field -- has no signal types, just emitted when the duration changes"`
+}
+
+var KiT_DurationField = kit.Types.AddType(&DurationField{}, DurationFieldProps)
+
+// AddNewDurationField adds a new duration field to given parent node, with given name.
+func AddNewDurationField(parent ki.Ki, name string) *DurationField {
+	return parent.AddNewChild(KiT_DurationField, name).(*DurationField)
+}
+
+func (df *DurationField) CopyFieldsFrom(frm interface{}) {
+	fr := frm.(*DurationField)
+	df.PartsWidgetBase.CopyFieldsFrom(&fr.PartsWidgetBase)
+	df.Duration = fr.Duration
+	df.Unit = fr.Unit
+}
+
+func (df *DurationField) Disconnect() {
+	df.PartsWidgetBase.Disconnect()
+	df.DurationFieldSig.DisconnectAll()
+}
+
+// AccessInfo describes the duration field for assistive technologies
+func (df *DurationField) AccessInfo(an *oswin.AccessNode) {
+	an.Role = oswin.RoleSpinBox
+	an.Value = df.Duration.String()
+}
+
+var DurationFieldProps = ki.Props{
+	"EnumType:Flag": KiT_NodeFlags,
+}
+
+// UnitIndex returns the index in DurationUnits of the unit in which the
+// duration is shown
+func (df *DurationField) UnitIndex() int {
+	if df.Unit != "" {
+		if ui := DurationUnitByName(df.Unit); ui >= 0 {
+			return ui
+		}
+	}
+	return DurationUnitFor(df.Duration)
+}
+
+// UnitValue returns the duration in the unit in which it is shown
+func (df *DurationField) UnitValue() float32 {
+	return float32(float64(df.Duration) / float64(DurationUnits[df.UnitIndex()].Dur))
+}
+
+// SetDuration sets the duration, and updates the display
+func (df *DurationField) SetDuration(d time.Duration) {
+	updt := df.UpdateStart()
+	df.Duration = d
+	df.UpdateEnd(updt)
+}
+
+// SetDurationAction calls SetDuration and also emits the signal
+func (df *DurationField) SetDurationAction(d time.Duration) {
+	df.SetDuration(d)
+	df.ConfigPartsIfNeeded()
+	df.DurationFieldSig.Emit(df.This(), 0, df.Duration)
+}
+
+// SetUnit sets the unit in which the duration is shown, by name -- the
+// duration itself is not changed
+func (df *DurationField) SetUnit(unit string) {
+	updt := df.UpdateStart()
+	df.Unit = unit
+	df.ConfigPartsIfNeeded()
+	df.UpdateEnd(updt)
+}
+
+func (df *DurationField) ConfigParts() {
+	df.Parts.Lay = LayoutHoriz
+	df.Parts.SetProp("vertical-align", gist.AlignMiddle)
+	config := kit.TypeAndNameList{}
+	config.Add(KiT_SpinBox, "value")
+	config.Add(KiT_ComboBox, "unit")
+	mods, updt := df.Parts.ConfigChildren(config, ki.NonUniqueNames)
+	if mods || gist.RebuildDefaultStyles {
+		sb := df.Parts.ChildByName("value", 0).(*SpinBox)
+		sb.Defaults()
+		sb.Step = 1
+		sb.PageStep = 10
+		sb.SetFlagState(df.IsInactive(), int(Inactive))
+		df.StylePart(Node2D(sb))
+		sb.SpinBoxSig.ConnectOnly(df.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dfr := recv.Embed(KiT_DurationField).(*DurationField)
+			ud := DurationUnits[dfr.UnitIndex()].Dur
+			dfr.Unit = DurationUnits[dfr.UnitIndex()].Name // keep the unit as entered
+			dfr.SetDurationAction(time.Duration(float64(send.(*SpinBox).Value) * float64(ud)))
+		})
+		cb := df.Parts.ChildByName("unit", 1).(*ComboBox)
+		unms := make([]string, len(DurationUnits))
+		for i, du := range DurationUnits {
+			unms[i] = du.Name
+		}
+		cb.ItemsFromStringList(unms, false, 0)
+		cb.SetFlagState(df.IsInactive(), int(Inactive))
+		df.StylePart(Node2D(cb))
+		cb.ComboSig.ConnectOnly(df.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dfr := recv.Embed(KiT_DurationField).(*DurationField)
+			dfr.SetUnit(DurationUnits[sig].Name)
+		})
+		df.UpdateEnd(updt)
+	}
+}
+
+func (df *DurationField) ConfigPartsIfNeeded() {
+	if !df.Parts.HasChildren() {
+		df.ConfigParts()
+	}
+	ui := df.UnitIndex()
+	cb := df.Parts.ChildByName("unit", 1).(*ComboBox)
+	if cb.CurIndex != ui {
+		cb.SetCurIndex(ui)
+	}
+	sb := df.Parts.ChildByName("value", 0).(*SpinBox)
+	if vl := df.UnitValue(); sb.Value != vl {
+		sb.SetValue(vl)
+	}
+}
+
+func (df *DurationField) Init2D() {
+	df.Init2DWidget()
+	df.ConfigParts()
+}
+
+// StyleFromProps styles DurationField-specific fields from ki.Prop properties
+// doesn't support inherit or default
+func (df *DurationField) StyleFromProps(props ki.Props, vp *Viewport2D) {
+	if uv, ok := props["unit"]; ok {
+		df.Unit = kit.ToString(uv)
+	}
+}
+
+func (df *DurationField) Style2D() {
+	df.StyMu.Lock()
+	df.Style2DWidget()
+	df.StyleFromProps(df.Props, df.Viewport)
+	df.LayState.SetFromStyle(&df.Sty.Layout) // also does reset
+	df.StyMu.Unlock()
+	df.ConfigParts()
+	df.ConfigPartsIfNeeded() // unit text is needed for sizing
+}
+
+func (df *DurationField) Size2D(iter int) {
+	df.Size2DParts(iter)
+}
+
+func (df *DurationField) Layout2D(parBBox image.Rectangle, iter int) bool {
+	df.ConfigPartsIfNeeded()
+	df.Layout2DBase(parBBox, true, iter) // init style
+	df.Layout2DParts(parBBox, iter)
+	return df.Layout2DChildren(iter)
+}
+
+func (df *DurationField) Render2D() {
+	if df.FullReRenderIfNeeded() {
+		return
+	}
+	if df.PushBounds() {
+		df.This().(Node2D).ConnectEvents2D()
+		df.ConfigPartsIfNeeded()
+		df.Render2DChildren()
+		df.Render2DParts()
+		df.PopBounds()
+	} else {
+		df.DisconnectAllEvents(RegPri)
+	}
+}
+
+func (df *DurationField) ConnectEvents2D() {
+	df.HoverTooltipEvent()
+}
+
+func (df *DurationField) HasFocus2D() bool {
+	if df.IsInactive() {
+		return false
+	}
+	return df.ContainsFocus() // needed for getting key events
+}
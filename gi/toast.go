@@ -0,0 +1,258 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"fmt"
+	"image"
+	"time"
+	"unicode/utf8"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ints"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// ToastSeverity is the severity of a toast notification, which determines
+// its color
+type ToastSeverity int32
+
+const (
+	// ToastInfo is for general information
+	ToastInfo ToastSeverity = iota
+
+	// ToastSuccess is for an operation that completed successfully
+	ToastSuccess
+
+	// ToastWarning is for something the user should look into
+	ToastWarning
+
+	// ToastError is for an operation that failed
+	ToastError
+
+	ToastSeverityN
+)
+
+//go:generate stringer -type=ToastSeverity
+
+var KiT_ToastSeverity = kit.Enums.AddEnumAltLower(ToastSeverityN, kit.NotBitFlag, nil, "Toast")
+
+func (ev ToastSeverity) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *ToastSeverity) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// ToastColors are the border colors of toasts for each severity
+var ToastColors = [ToastSeverityN]string{"#2196F3", "#4CAF50", "#FF9800", "#F44336"}
+
+// ToastTimeout is the default time until a toast is dismissed automatically
+var ToastTimeout = 5 * time.Second
+
+// ToastNativeNotify shows each toast also as a native notification of the
+// OS when its window is not in focus, if the oswin driver supports it --
+// see oswin.Notifier
+var ToastNativeNotify = true
+
+// ToastFrameProps are the style properties of the frame of a toast
+var ToastFrameProps = ki.Props{
+	"border-width": units.NewPx(2),
+	"border-style": gist.BorderSolid,
+	"padding":      units.NewPx(6),
+	"margin":       units.NewPx(2),
+	"spacing":      units.NewEx(1),
+}
+
+// ToastOpts are the options for a toast notification -- see NewToast
+type ToastOpts struct {
+	Severity  ToastSeverity `desc:"severity of the toast, which determines its color"`
+	Timeout   time.Duration `desc:"time until the toast is dismissed automatically -- 0 for ToastTimeout, and negative for it to stay until it is closed"`
+	Action    string        `desc:"label of an optional action button"`
+	ActionFun func()        `desc:"function called when the action button is clicked, after the toast is dismissed"`
+}
+
+// Toast is a transient, non-modal notification shown in the bottom right
+// corner of a window, above any earlier toasts that are still showing.  It is
+// rendered as a sprite on the overlay of the window, so it does not disturb
+// the focus or any popups, and is dismissed by its close button, its action
+// button, or its timeout.
+type Toast struct {
+	ToastOpts
+	Message string  `desc:"message shown in the toast"`
+	Win     *Window `desc:"window that the toast is shown in"`
+	vp      *toastViewport
+	sprite  *Sprite
+	timer   *time.Timer
+}
+
+// toastViewport is the viewport of a toast, which is rendered into its
+// sprite, instead of being uploaded to the window
+type toastViewport struct {
+	Viewport2D
+}
+
+func (vp *toastViewport) VpUploadAll()                                   {}
+func (vp *toastViewport) VpUploadVp()                                    {}
+func (vp *toastViewport) VpUploadRegion(vpBBox, winBBox image.Rectangle) {}
+
+// toastEvent is the data of the custom event that shows or dismisses a
+// toast, in the event loop of its window
+type toastEvent struct {
+	toast *Toast
+	show  bool
+}
+
+// NewToast shows a toast notification with given message and options in
+// the given window, and returns it -- it can be called from any goroutine
+func NewToast(win *Window, msg string, opts ToastOpts) *Toast {
+	ts := &Toast{ToastOpts: opts, Message: msg, Win: win}
+	win.SendCustomEvent(toastEvent{toast: ts, show: true})
+	return ts
+}
+
+// Dismiss removes the toast from its window -- it can be called from any
+// goroutine
+func (ts *Toast) Dismiss() {
+	if ts.Win.IsClosed() {
+		return
+	}
+	ts.Win.SendCustomEvent(toastEvent{toast: ts, show: false})
+}
+
+// spriteName returns the name of the sprite of the toast
+func (ts *Toast) spriteName() string {
+	return fmt.Sprintf("toast-%p", ts)
+}
+
+// render renders the toast into its sprite, and adds that to the window
+func (ts *Toast) render() {
+	w := ts.Win
+	vp := &toastViewport{}
+	vp.InitName(vp, "toast")
+	vp.Win = w
+	vp.Fill = true
+	vp.SetProp("color", &Prefs.Colors.Font)
+	vp.SetProp("background-color", &Prefs.Colors.Background)
+	frame := AddNewFrame(vp, "frame", LayoutHoriz)
+	frame.SetProps(ToastFrameProps, ki.NoUpdate)
+	frame.SetProp("border-color", ToastColors[ts.Severity])
+	lbl := AddNewLabel(frame, "msg", ts.Message)
+	lbl.SetProp("vertical-align", gist.AlignMiddle)
+	if utf8.RuneCountInString(ts.Message) > 40 {
+		lbl.SetProp("width", units.NewCh(40))
+		lbl.SetProp("white-space", gist.WhiteSpaceNormal)
+	}
+	if ts.Action != "" {
+		act := AddNewAction(frame, "action")
+		act.SetText(ts.Action)
+	}
+	cls := AddNewAction(frame, "close")
+	cls.SetIcon("close")
+	sz := vp.PrefSize(w.Viewport.Geom.Size)
+	vp.Resize(sz)
+	vp.FullRender2DTree()
+	ts.vp = vp
+	ts.sprite = &Sprite{Name: ts.spriteName(), Pixels: vp.Pixels}
+	ts.sprite.Geom.Size = sz
+	w.AddSprite(ts.sprite)
+}
+
+// show renders the toast and adds it to its window, starting its timeout
+func (ts *Toast) show() {
+	w := ts.Win
+	if !w.IsVisible() {
+		return
+	}
+	ts.render()
+	w.toasts = append(w.toasts, ts)
+	w.LayoutToasts()
+	if ts.Timeout >= 0 {
+		to := ts.Timeout
+		if to == 0 {
+			to = ToastTimeout
+		}
+		ts.timer = time.AfterFunc(to, ts.Dismiss)
+	}
+	if ToastNativeNotify && !w.HasFlag(int(WinFlagGotFocus)) {
+		if nt, ok := oswin.TheApp.(oswin.Notifier); ok {
+			nt.Notify(w.Title, ts.Message)
+		}
+	}
+}
+
+// remove removes the toast from its window
+func (ts *Toast) remove() {
+	if ts.timer != nil {
+		ts.timer.Stop()
+	}
+	w := ts.Win
+	for i, t := range w.toasts {
+		if t == ts {
+			w.toasts = append(w.toasts[:i], w.toasts[i+1:]...)
+			w.DeleteSprite(ts.spriteName())
+			w.LayoutToasts()
+			if len(w.toasts) == 0 { // no sprites left to trigger a publish
+				w.Publish()
+			}
+			return
+		}
+	}
+}
+
+// click handles a click on the toast at given point within it
+func (ts *Toast) click(pt image.Point) {
+	frame := ts.vp.Child(0).(*Frame)
+	for _, k := range frame.Kids {
+		ac, ok := k.(*Action)
+		if !ok {
+			continue
+		}
+		ac.BBoxMu.RLock()
+		in := pt.In(ac.VpBBox)
+		ac.BBoxMu.RUnlock()
+		if !in {
+			continue
+		}
+		ts.remove()
+		if ac.Nm == "action" && ts.ActionFun != nil {
+			ts.ActionFun()
+		}
+		return
+	}
+}
+
+// LayoutToasts positions the toasts of the window in its bottom right
+// corner, with the newest at the bottom, and renders them
+func (w *Window) LayoutToasts() {
+	spc := int(w.LogicalDPI() / 12)
+	wsz := w.Viewport.Geom.Size
+	y := wsz.Y - spc
+	for i := len(w.toasts) - 1; i >= 0; i-- {
+		sp := w.toasts[i].sprite
+		y -= sp.Geom.Size.Y
+		sp.Geom.Pos = image.Point{ints.MaxInt(0, wsz.X-sp.Geom.Size.X-spc), ints.MaxInt(0, y)}
+		y -= spc
+		w.ActivateSprite(sp.Name)
+	}
+	w.RenderOverlays()
+}
+
+// toastMouseEvent handles a mouse button event on any of the toasts of the
+// window -- returns true if the event was on a toast
+func (w *Window) toastMouseEvent(me *mouse.Event) bool {
+	for _, ts := range w.toasts {
+		r := image.Rectangle{Min: ts.sprite.Geom.Pos, Max: ts.sprite.Geom.Pos.Add(ts.sprite.Geom.Size)}
+		if !me.Where.In(r) {
+			continue
+		}
+		me.SetProcessed()
+		if me.Action == mouse.Release && me.Button == mouse.Left {
+			ts.click(me.Where.Sub(r.Min))
+		}
+		return true
+	}
+	return false
+}
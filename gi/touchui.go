@@ -0,0 +1,242 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"image"
+	"time"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/oswin/touch"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/mat32"
+)
+
+// TouchUI turns on the touch-first variants of the widgets, for use with a
+// finger on a touch screen instead of a mouse: buttons, text fields and
+// sliders are at least TouchTargetSize in each dimension, and scrolling
+// layouts can be dragged to scroll, continuing with a decelerating fling
+// when released while moving.  It is turned on automatically by Init on
+// mobile platforms (see oswin.Platforms.IsMobile), and must be set before
+// any windows are made.
+var TouchUI = false
+
+// TouchTargetSize is the minimum width and height of the widgets that can
+// be tapped, when TouchUI is on -- 48dp is about 9mm, the recommended
+// minimum size for a fingertip on Android and iOS
+var TouchTargetSize = units.NewDp(48)
+
+// TouchFlingDecel is the deceleration of the scrolling of a layout after it
+// is flung, in dots per second squared
+var TouchFlingDecel = float32(4000)
+
+// TouchFlingMinSpeed is the minimum speed of a drag, in dots per second,
+// for a layout to continue scrolling when it is released
+var TouchFlingMinSpeed = float32(200)
+
+// TouchFlingMaxSpeed is the maximum speed of a fling, in dots per second
+var TouchFlingMaxSpeed = float32(8000)
+
+// TouchFlingStopMSec is the number of msec that the touch must have been
+// held still before it is released for there to be no fling
+var TouchFlingStopMSec = 100
+
+// InitTouchUI turns on TouchUI on mobile platforms, where dialogs are also
+// shown as popups in the window, as there is only one window on the screen
+// -- called by Init
+func InitTouchUI() {
+	if oswin.TheApp == nil || !oswin.TheApp.Platform().IsMobile() {
+		return
+	}
+	TouchUI = true
+	DialogsSepWindow = false
+}
+
+// TouchTargetStyle raises the min-width and min-height of given style to
+// TouchTargetSize if TouchUI is on, for widgets that can be tapped -- must
+// be called after the style has been set and its units converted to dots
+func TouchTargetStyle(st *gist.Style) {
+	if !TouchUI {
+		return
+	}
+	sz := TouchTargetSize
+	sz.ToDots(&st.UnContext)
+	ls := &st.Layout
+	if ls.MinWidth.Dots < sz.Dots {
+		ls.MinWidth = sz
+	}
+	if ls.MinHeight.Dots < sz.Dots {
+		ls.MinHeight = sz
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Layout touch scrolling
+
+// TouchScroll is the state of dragging a Layout with a finger to scroll it,
+// and of the fling that continues the scrolling after it is released
+type TouchScroll struct {
+	Pressed  bool        `desc:"the mouse (finger) was pressed in the layout, at Start"`
+	Start    image.Point `desc:"position where the mouse was pressed"`
+	Dragging bool        `desc:"the layout is being dragged to scroll it"`
+	Last     time.Time   `desc:"time of the last drag event"`
+	Vel      mat32.Vec2  `desc:"current velocity of the drag, in dots per second, smoothed over recent drag events"`
+	Fling    *Animation  `desc:"running fling animation, if any"`
+}
+
+// TouchScrollEvents registers the events for dragging and flinging the
+// layout to scroll it when TouchUI is on -- called by LayoutScrollEvents.
+// Dragging is LowPri, so that widgets that handle drags (e.g., sliders)
+// get them first, and presses and releases are HiPri and not processed
+// (except for a press that stops a fling), so the layout sees them before
+// the widgets it contains.
+func (ly *Layout) TouchScrollEvents() {
+	ly.ConnectEvent(oswin.MouseEvent, HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.Event)
+		li := recv.Embed(KiT_Layout).(*Layout)
+		li.TouchScrollMouse(me)
+	})
+	ly.ConnectEvent(oswin.MouseDragEvent, LowPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.DragEvent)
+		li := recv.Embed(KiT_Layout).(*Layout)
+		li.TouchScrollDrag(me)
+	})
+}
+
+// TouchScrollMouse processes a mouse press or release for touch scrolling:
+// a press stops any fling, and is processed if there was one, so that
+// tapping a moving list does not activate what is under the finger, and a
+// release ends dragging, starting a fling if the finger was still moving
+func (ly *Layout) TouchScrollMouse(me *mouse.Event) {
+	if me.Button != mouse.Left {
+		return
+	}
+	ts := &ly.Touch
+	switch me.Action {
+	case mouse.Press, mouse.DoubleClick:
+		ts.Pressed = true
+		ts.Start = me.Where
+		ts.Dragging = false
+		ts.Vel = mat32.Vec2Zero
+		if ts.Fling != nil && ts.Fling.IsRunning() {
+			ts.Fling.Cancel()
+			ts.Fling = nil
+			me.SetProcessed()
+		}
+	case mouse.Release:
+		ts.Pressed = false
+		if !ts.Dragging {
+			return
+		}
+		ts.Dragging = false
+		if time.Since(ts.Last) < time.Duration(TouchFlingStopMSec)*time.Millisecond {
+			ly.TouchFling(ts.Vel)
+		}
+	}
+}
+
+// TouchScrollDrag processes a mouse drag for touch scrolling: once the
+// drag has moved beyond touch.GestureSlop from where it was pressed, in a
+// direction in which the layout can scroll, the layout is scrolled so that
+// its content moves with the finger, and any button that was pressed is
+// released without being clicked.  Otherwise the event is left for any
+// enclosing layout.
+func (ly *Layout) TouchScrollDrag(me *mouse.DragEvent) {
+	ts := &ly.Touch
+	if !ts.Pressed || !(ly.HasScroll[mat32.X] || ly.HasScroll[mat32.Y]) {
+		return
+	}
+	now := time.Now()
+	if !ts.Dragging {
+		mv := me.Where.Sub(ts.Start)
+		if mat32.NewVec2(float32(mv.X), float32(mv.Y)).Length() < touch.GestureSlop {
+			return
+		}
+		dim := mat32.Y
+		if mat32.Abs(float32(mv.X)) > mat32.Abs(float32(mv.Y)) {
+			dim = mat32.X
+		}
+		if !ly.HasScroll[dim] {
+			return
+		}
+		ts.Dragging = true
+		ts.Last = now
+		ly.TouchCancelPress()
+	}
+	me.SetProcessed()
+	del := me.Where.Sub(me.From)
+	if dt := float32(now.Sub(ts.Last).Seconds()); dt > 0 {
+		vel := mat32.NewVec2(float32(del.X), float32(del.Y)).DivScalar(dt)
+		ts.Vel = vel.MulScalar(0.8).Add(ts.Vel.MulScalar(0.2))
+	}
+	ts.Last = now
+	if ly.HasScroll[mat32.X] && del.X != 0 {
+		ly.ScrollActionDelta(mat32.X, float32(-del.X))
+	}
+	if ly.HasScroll[mat32.Y] && del.Y != 0 {
+		ly.ScrollActionDelta(mat32.Y, float32(-del.Y))
+	}
+}
+
+// TouchCancelPress releases any button in the layout that was pressed by
+// the touch that is now dragging it, without it being clicked
+func (ly *Layout) TouchCancelPress() {
+	ly.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		bw, ok := k.(ButtonWidget)
+		if !ok {
+			return ki.Continue
+		}
+		bb := bw.AsButtonBase()
+		if bb.State == ButtonDown {
+			updt := bb.UpdateStart()
+			bb.SetButtonState(ButtonActive)
+			bb.UpdateEnd(updt)
+		}
+		return ki.Break // no buttons within buttons
+	})
+}
+
+// TouchFling continues scrolling the layout after a drag is released at
+// given velocity, in dots per second, decelerating at TouchFlingDecel
+// until it stops -- it is stopped by the next press in the layout
+func (ly *Layout) TouchFling(vel mat32.Vec2) {
+	ts := &ly.Touch
+	for d := mat32.X; d <= mat32.Y; d++ {
+		if !ly.HasScroll[d] {
+			vel.SetDim(d, 0)
+		}
+	}
+	speed := vel.Length()
+	if speed < TouchFlingMinSpeed || TouchFlingDecel <= 0 {
+		return
+	}
+	if speed > TouchFlingMaxSpeed {
+		vel = vel.MulScalar(TouchFlingMaxSpeed / speed)
+		speed = TouchFlingMaxSpeed
+	}
+	win := ly.ParentWindow()
+	if win == nil {
+		return
+	}
+	// constant deceleration over dur covers speed * dur / 2, along EaseOutQuad
+	dsec := speed / TouchFlingDecel
+	dist := vel.MulScalar(dsec / 2)
+	var prev mat32.Vec2
+	tw := TweenFunc(nil, time.Duration(dsec*float32(time.Second)), func(t float32) {
+		cur := dist.MulScalar(t)
+		del := cur.Sub(prev)
+		prev = cur
+		if del.X != 0 {
+			ly.ScrollActionDelta(mat32.X, -del.X)
+		}
+		if del.Y != 0 {
+			ly.ScrollActionDelta(mat32.Y, -del.Y)
+		}
+	}).SetEasing(EaseOutQuad)
+	ts.Fling = NewAnimation(tw).Start(win)
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"errors"
+	"image"
+
+	"github.com/goki/gi/oswin"
+)
+
+// ErrNoTray is returned when the platform does not support system tray icons
+var ErrNoTray = errors.New("gi: the system tray is not supported on this platform")
+
+// TrayIcon is an icon of the app in the system tray, e.g., for an app that
+// keeps running in the background with its windows hidden (see
+// Window.Hide) -- its menu is a Menu of Actions, like the context menus of
+// widgets, and its events are processed in the event loop of its window,
+// typically the main window of the app.
+type TrayIcon struct {
+	Win       *Window            `desc:"window in whose event loop the events of the icon are processed"`
+	Menu      Menu               `desc:"menu of the icon -- only Actions and Separators are shown, and sub-menus are not supported -- call UpdateMenu after changing it"`
+	ClickFunc func(ti *TrayIcon) `desc:"function called when the icon is clicked -- if nil, Win is raised, showing it if it is hidden or minimized"`
+	OSIcon    oswin.TrayIcon     `desc:"the icon of the OS"`
+	acts      []*Action          // action for each item of the menu of OSIcon
+}
+
+// trayEvent is the data of the custom event that processes an event of a
+// tray icon, or of the dock menu with given actions if ti is nil, in the
+// window event loop
+type trayEvent struct {
+	ti   *TrayIcon
+	acts []*Action
+	ev   oswin.TrayEvents
+	item int
+}
+
+// NewTrayIcon adds a new icon to the system tray, with given image (which
+// should be small and square, e.g., 32x32) and tooltip, whose events are
+// processed in the event loop of given window -- returns ErrNoTray if the
+// platform has no system tray, or an error if it could not be shown.
+func NewTrayIcon(win *Window, icon image.Image, tooltip string) (*TrayIcon, error) {
+	st, ok := oswin.TheApp.(oswin.SystemTray)
+	if !ok {
+		return nil, ErrNoTray
+	}
+	osi, err := st.NewTrayIcon(icon, tooltip)
+	if err != nil {
+		return nil, err
+	}
+	ti := &TrayIcon{Win: win, OSIcon: osi}
+	osi.SetEventFunc(func(osi oswin.TrayIcon, ev oswin.TrayEvents, item int) {
+		ti.Win.SendCustomEvent(trayEvent{ti: ti, ev: ev, item: item})
+	})
+	return ti, nil
+}
+
+// SetIcon sets the image of the icon
+func (ti *TrayIcon) SetIcon(icon image.Image) {
+	ti.OSIcon.SetIcon(icon)
+}
+
+// SetTooltip sets the tooltip shown when hovering over the icon
+func (ti *TrayIcon) SetTooltip(tip string) {
+	ti.OSIcon.SetTooltip(tip)
+}
+
+// SetMenu sets the menu of the icon
+func (ti *TrayIcon) SetMenu(m Menu) {
+	ti.Menu = m
+	ti.UpdateMenu()
+}
+
+// UpdateMenu updates the menu of the icon from Menu, calling the update
+// functions of its actions to update their active state
+func (ti *TrayIcon) UpdateMenu() {
+	ti.Menu.UpdateActions()
+	var items []oswin.TrayMenuItem
+	items, ti.acts = trayMenuItems(ti.Menu)
+	ti.OSIcon.SetMenu(items)
+}
+
+// Close removes the icon from the system tray
+func (ti *TrayIcon) Close() {
+	ti.OSIcon.Close()
+}
+
+// trayMenuItems returns the menu items for the OS, and the corresponding
+// actions, for given menu
+func trayMenuItems(m Menu) ([]oswin.TrayMenuItem, []*Action) {
+	var items []oswin.TrayMenuItem
+	var acts []*Action
+	for _, mi := range m {
+		if mi.TypeEmbeds(KiT_Action) {
+			ac := mi.Embed(KiT_Action).(*Action)
+			items = append(items, oswin.TrayMenuItem{Label: ac.Text, Inactive: ac.IsInactive() || len(ac.Menu) > 0})
+			acts = append(acts, ac)
+		} else if _, ok := mi.(*Separator); ok {
+			items = append(items, oswin.TrayMenuItem{})
+			acts = append(acts, nil)
+		}
+	}
+	return items, acts
+}
+
+// process processes the event, in the window event loop
+func (te *trayEvent) process() {
+	if te.ev == oswin.TrayMenuSelected {
+		acts := te.acts
+		if te.ti != nil {
+			acts = te.ti.acts
+		}
+		if te.item >= 0 && te.item < len(acts) && acts[te.item] != nil {
+			acts[te.item].Trigger()
+		}
+		return
+	}
+	if te.ti == nil {
+		return
+	}
+	if te.ti.ClickFunc != nil {
+		te.ti.ClickFunc(te.ti)
+	} else {
+		te.ti.Win.Raise()
+	}
+}
+
+// SetDockBadge sets the badge shown on the icon of the app in the dock,
+// e.g., a count of unread messages -- "" for no badge -- does nothing if
+// the platform has no dock (currently only the Mac has one)
+func SetDockBadge(badge string) {
+	if di, ok := oswin.TheApp.(oswin.DockIcon); ok {
+		di.SetDockBadge(badge)
+	}
+}
+
+// SetDockMenu sets the menu of the icon of the app in the dock, shown
+// above the standard items of the dock, whose actions are triggered in the
+// event loop of given window -- only Actions and Separators are shown --
+// does nothing if the platform has no dock (currently only the Mac has
+// one)
+func SetDockMenu(win *Window, m Menu) {
+	di, ok := oswin.TheApp.(oswin.DockIcon)
+	if !ok {
+		return
+	}
+	m.UpdateActions()
+	items, acts := trayMenuItems(m)
+	di.SetDockMenu(items, func(item int) {
+		win.SendCustomEvent(trayEvent{acts: acts, ev: oswin.TrayMenuSelected, item: item})
+	})
+}
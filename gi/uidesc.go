@@ -0,0 +1,289 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// UINode is a declarative description of a widget and its children, which
+// can be read from JSON or XML, and built into a widget tree with Build,
+// separating the definition of a UI from its logic, and allowing it to be
+// loaded at runtime.  The handlers of signals are given by name, and
+// registered from Go code with AddUIHandler.
+//
+// In JSON, each node is an object with the fields of UINode, e.g.:
+// 	{"Type": "Frame", "Name": "main", "Props": {"lay": "vert"}, "Kids": [
+// 		{"Type": "Label", "Name": "title", "Fields": {"Text": "Hello"}},
+// 		{"Type": "Button", "Name": "ok", "Fields": {"Text": "OK", "ButtonSig": "ok-clicked"}}]}
+//
+// In XML, each node is an element named by its Type, with a name attribute
+// for its Name, attributes starting with an upper-case letter for Fields,
+// other attributes for Props, and child elements for Kids, e.g.:
+// 	<Frame name="main" lay="vert">
+// 		<Label name="title" Text="Hello"/>
+// 		<Button name="ok" Text="OK" ButtonSig="ok-clicked"/>
+// 	</Frame>
+type UINode struct {
+	Type   string                 `desc:"type of the widget, as registered in kit.Types, e.g., gi.Button -- the gi package can be omitted, e.g., Button"`
+	Name   string                 `desc:"name of the widget, which should be unique among its siblings, to find it after building"`
+	Fields map[string]interface{} `desc:"values of exported fields of the widget, by field name, e.g., Text, converted to the type of the field, with enums set from their names -- the value of a ki.Signal field, e.g., ButtonSig, is the name of the handler to connect to it"`
+	Props  ki.Props               `desc:"style and other properties of the widget, e.g., lay for the layout of a Frame, or max-width"`
+	Kids   []*UINode              `desc:"child widgets"`
+}
+
+var (
+	// UIHandlers are the handlers of signals that can be connected by name
+	// in a UINode, added with AddUIHandler
+	UIHandlers = map[string]ki.RecvFunc{}
+
+	// UIHandlersMu protects UIHandlers
+	UIHandlersMu sync.RWMutex
+)
+
+// AddUIHandler adds a handler of signals that can be connected by given
+// name in a UINode -- the handler is called with the root widget built from
+// the UINode as the receiver, and the widget emitting the signal as the
+// sender, e.g., the Button for a ButtonSig.
+func AddUIHandler(name string, fun ki.RecvFunc) {
+	UIHandlersMu.Lock()
+	UIHandlers[name] = fun
+	UIHandlersMu.Unlock()
+}
+
+// UIHandler returns the handler of given name, and false if it has not
+// been added
+func UIHandler(name string) (ki.RecvFunc, bool) {
+	UIHandlersMu.RLock()
+	defer UIHandlersMu.RUnlock()
+	fun, ok := UIHandlers[name]
+	return fun, ok
+}
+
+// LoadUI reads the UI description in given file, as JSON if it has a
+// .json extension, and XML otherwise, and builds it as the last child of
+// given parent, returning the root widget -- all errors are logged and
+// also returned, in which case nothing is added to the parent.
+func LoadUI(parent ki.Ki, filename string) (ki.Ki, error) {
+	un, err := OpenUI(filename)
+	if err != nil {
+		return nil, err
+	}
+	return un.Build(parent)
+}
+
+// OpenUI reads the UI description in given file, as JSON if it has a
+// .json extension, and XML otherwise
+func OpenUI(filename string) (*UINode, error) {
+	fp, err := os.Open(filename)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer fp.Close()
+	if strings.ToLower(filepath.Ext(filename)) == ".json" {
+		return ReadUIJSON(fp)
+	}
+	return ReadUIXML(fp)
+}
+
+// ReadUIJSON reads a JSON UI description from given reader
+func ReadUIJSON(reader io.Reader) (*UINode, error) {
+	un := &UINode{}
+	err := json.NewDecoder(reader).Decode(un)
+	if err != nil {
+		err = fmt.Errorf("gi.ReadUIJSON: %v", err)
+		log.Println(err)
+		return nil, err
+	}
+	return un, nil
+}
+
+// ReadUIXML reads an XML UI description from given reader
+func ReadUIXML(reader io.Reader) (*UINode, error) {
+	decoder := xml.NewDecoder(reader)
+	var root *UINode
+	var stack []*UINode
+	for {
+		t, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			err = fmt.Errorf("gi.ReadUIXML: %v", err)
+			log.Println(err)
+			return nil, err
+		}
+		switch se := t.(type) {
+		case xml.StartElement:
+			un := &UINode{Type: se.Name.Local}
+			for _, attr := range se.Attr {
+				an := attr.Name.Local
+				r, _ := utf8.DecodeRuneInString(an)
+				switch {
+				case an == "name":
+					un.Name = attr.Value
+				case unicode.IsUpper(r):
+					if un.Fields == nil {
+						un.Fields = make(map[string]interface{})
+					}
+					un.Fields[an] = attr.Value
+				default:
+					if un.Props == nil {
+						un.Props = make(ki.Props)
+					}
+					un.Props[an] = attr.Value
+				}
+			}
+			switch {
+			case len(stack) > 0:
+				par := stack[len(stack)-1]
+				par.Kids = append(par.Kids, un)
+			case root == nil:
+				root = un
+			default:
+				err := fmt.Errorf("gi.ReadUIXML: more than one root element: %v", un.Type)
+				log.Println(err)
+				return nil, err
+			}
+			stack = append(stack, un)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+	if root == nil {
+		err := fmt.Errorf("gi.ReadUIXML: no elements")
+		log.Println(err)
+		return nil, err
+	}
+	return root, nil
+}
+
+// uiSigConn is a signal connection to make once the tree is built
+type uiSigConn struct {
+	sig *ki.Signal
+	fun ki.RecvFunc
+}
+
+// Build builds the widget tree described by the UINode, adding it as the
+// last child of given parent (if non-nil), within an UpdateStart / End of
+// the parent, and connecting the handlers of signals -- returns the root
+// widget, from which the others can be found by name, e.g., with
+// ChildByName or FindPathUnique.  All errors are logged and also returned,
+// in which case nothing is added to the parent.
+func (un *UINode) Build(parent ki.Ki) (ki.Ki, error) {
+	var conns []uiSigConn
+	k, err := un.build(&conns)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	for _, sc := range conns {
+		sc.sig.Connect(k, sc.fun)
+	}
+	if parent != nil {
+		updt := parent.UpdateStart()
+		parent.AddChild(k)
+		parent.UpdateEnd(updt)
+	}
+	return k, nil
+}
+
+// uiType returns the type of given name, trying the gi package for names
+// without a package, or nil if it is not a registered Ki type
+func uiType(nm string) reflect.Type {
+	typ := kit.Types.Type(nm)
+	if typ == nil && !strings.Contains(nm, ".") {
+		typ = kit.Types.Type("gi." + nm)
+	}
+	if typ == nil || !ki.IsKi(typ) {
+		return nil
+	}
+	return typ
+}
+
+// uiSetEnum sets given enum field from the name of a value, returning false
+// if the field is not an enum, or the string is a number
+func uiSetEnum(fv reflect.Value, str string) (bool, error) {
+	if _, isNum := kit.ToInt(str); isNum {
+		return false, nil
+	}
+	pv := kit.PtrValue(fv)
+	if kit.Enums.TypeRegistered(fv.Type()) {
+		return true, kit.Enums.SetAnyEnumValueFromString(pv, str)
+	}
+	if fs, ok := pv.Interface().(interface{ FromString(s string) error }); ok {
+		return true, fs.FromString(str)
+	}
+	return false, nil
+}
+
+// build makes the widget for the node and its children, adding the
+// signal connections to conns
+func (un *UINode) build(conns *[]uiSigConn) (ki.Ki, error) {
+	typ := uiType(un.Type)
+	if typ == nil {
+		return nil, fmt.Errorf("gi.UINode: type %q is not a registered Ki type", un.Type)
+	}
+	k := ki.NewOfType(typ)
+	nm := un.Name
+	if nm == "" {
+		nm = strings.ToLower(typ.Name())
+	}
+	k.InitName(k, nm)
+	for fnm, val := range un.Fields {
+		fv := kit.FlatFieldValueByName(k, fnm)
+		if !fv.IsValid() {
+			return nil, fmt.Errorf("gi.UINode: %v has no field named %v", un.Type, fnm)
+		}
+		if fv.Type() == ki.KiT_Signal {
+			hnm := kit.ToString(val)
+			fun, ok := UIHandler(hnm)
+			if !ok {
+				return nil, fmt.Errorf("gi.UINode: handler %q for %v of %v has not been added with AddUIHandler", hnm, fnm, nm)
+			}
+			*conns = append(*conns, uiSigConn{sig: kit.PtrValue(fv).Interface().(*ki.Signal), fun: fun})
+			continue
+		}
+		if str, ok := val.(string); ok {
+			if isEnum, err := uiSetEnum(fv, str); isEnum {
+				if err != nil {
+					return nil, fmt.Errorf("gi.UINode: field %v of %v: %v", fnm, nm, err)
+				}
+				continue
+			}
+		}
+		if err := k.SetField(fnm, val); err != nil {
+			return nil, err
+		}
+	}
+	for key, val := range un.Props {
+		k.SetProp(key, val)
+	}
+	for _, kd := range un.Kids {
+		kk, err := kd.build(conns)
+		if err != nil {
+			return nil, err
+		}
+		k.AddChild(kk)
+	}
+	return k, nil
+}
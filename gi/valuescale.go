@@ -0,0 +1,180 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chewxy/math32"
+	"github.com/goki/gi/gist"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+)
+
+// ValueScales are the scales of the values of a SpinBox or Slider: the
+// steps of a SpinBox, and the positions of a Slider, are linear in the
+// scaled value, so that e.g., on a log scale each step multiplies the value
+// by the same factor, and each decade takes the same length of a slider.
+type ValueScales int32
+
+const (
+	// ScaleLinear is the standard linear scale of values
+	ScaleLinear ValueScales = iota
+
+	// ScaleLog is a logarithmic (base 10) scale, where steps are in decades
+	// -- the values, including the Min, must be greater than 0
+	ScaleLog
+
+	// ScaleCustom uses a custom ValueMapping, e.g., from ValueMappings
+	ScaleCustom
+
+	ValueScalesN
+)
+
+//go:generate stringer -type=ValueScales
+
+var KiT_ValueScales = kit.Enums.AddEnumAltLower(ValueScalesN, kit.NotBitFlag, gist.StylePropProps, "Scale")
+
+func (ev ValueScales) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *ValueScales) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// ValueMapping maps values to the scale on which they are stepped and
+// positioned, and back -- the scaled values must increase with the values
+type ValueMapping interface {
+	// ToScale returns the scaled value of given value
+	ToScale(val float32) float32
+
+	// FromScale returns the value of given scaled value
+	FromScale(sval float32) float32
+}
+
+// LinearMapping is the identity ValueMapping of ScaleLinear
+type LinearMapping struct{}
+
+func (lm LinearMapping) ToScale(val float32) float32    { return val }
+func (lm LinearMapping) FromScale(sval float32) float32 { return sval }
+
+// LogMapping is the base 10 logarithmic ValueMapping of ScaleLog -- values
+// at or below 0 are mapped as the smallest positive value
+type LogMapping struct{}
+
+func (lm LogMapping) ToScale(val float32) float32 {
+	return math32.Log10(math32.Max(val, math32.SmallestNonzeroFloat32))
+}
+
+func (lm LogMapping) FromScale(sval float32) float32 { return math32.Pow(10, sval) }
+
+// FuncMapping is a ValueMapping with the mapping functions given as fields
+type FuncMapping struct {
+	To   func(val float32) float32
+	From func(sval float32) float32
+}
+
+func (fm *FuncMapping) ToScale(val float32) float32    { return fm.To(val) }
+func (fm *FuncMapping) FromScale(sval float32) float32 { return fm.From(sval) }
+
+// ValueMappings are custom mappings of values, by name, which can be used
+// as a scale in the scale prop of SpinBox and Slider, and in the scale tag of
+// number fields in views, e.g., scale:"sqrt"
+var ValueMappings = map[string]ValueMapping{
+	"sqrt": &FuncMapping{
+		To:   func(val float32) float32 { return math32.Sqrt(math32.Max(val, 0)) },
+		From: func(sval float32) float32 { return sval * sval },
+	},
+}
+
+// ValueMapper returns the ValueMapping for given scale, using given custom
+// mapping for ScaleCustom -- linear if it is nil
+func ValueMapper(scale ValueScales, custom ValueMapping) ValueMapping {
+	switch {
+	case scale == ScaleLog:
+		return LogMapping{}
+	case scale == ScaleCustom && custom != nil:
+		return custom
+	}
+	return LinearMapping{}
+}
+
+// ScaleFromName returns the scale, and the custom mapping, for given name
+// of a scale: linear, log, or a name in ValueMappings -- ok is false if the
+// name is unknown, in which case the scale is linear
+func ScaleFromName(name string) (scale ValueScales, custom ValueMapping, ok bool) {
+	name = strings.TrimSpace(name)
+	if vm, has := ValueMappings[name]; has {
+		return ScaleCustom, vm, true
+	}
+	if err := kit.Enums.SetAnyEnumIfaceFromString(&scale, name); err == nil && scale != ScaleCustom {
+		return scale, nil, true
+	}
+	return ScaleLinear, nil, false
+}
+
+// SIPrefixes are the SI prefixes used for formatting and parsing values with
+// units, with their multipliers -- u is accepted for µ when parsing
+var SIPrefixes = []struct {
+	Prefix string
+	Mult   float64
+}{
+	{"p", 1e-12}, {"n", 1e-9}, {"µ", 1e-6}, {"m", 1e-3}, {"", 1},
+	{"k", 1e3}, {"M", 1e6}, {"G", 1e9}, {"T", 1e12},
+}
+
+// FormatValueUnit formats given value in given fmt.Sprintf format -- %g if
+// empty -- followed by given unit, e.g., 2.5s -- if siPrefix is set, the
+// value is shown with the SI prefix of its magnitude, e.g., 10ms for 0.01
+// -- for integer formats, e.g., %d, the value is converted to an integer.
+func FormatValueUnit(val float32, format, unit string, siPrefix bool) string {
+	pfx := ""
+	if siPrefix && val != 0 {
+		av := math32.Abs(val)
+		for i := len(SIPrefixes) - 1; i >= 0; i-- {
+			sp := SIPrefixes[i]
+			if float64(av) >= sp.Mult || i == 0 {
+				val = float32(float64(val) / sp.Mult)
+				pfx = sp.Prefix
+				break
+			}
+		}
+	}
+	if format == "" {
+		return fmt.Sprintf("%g", val) + pfx + unit
+	}
+	switch format[len(format)-1] {
+	case 'd', 'b', 'c', 'o', 'O', 'q', 'x', 'X', 'U':
+		return fmt.Sprintf(format, int64(mat32.Round(val))) + pfx + unit
+	}
+	return fmt.Sprintf(format, val) + pfx + unit
+}
+
+// ParseValueUnit parses a value with an optional SI prefix and unit, as
+// formatted by FormatValueUnit, e.g., 10ms, 3.5k, or 2 kHz for unit Hz --
+// the unit is optional.
+func ParseValueUnit(str, unit string) (float32, error) {
+	s := strings.TrimSpace(str)
+	if unit != "" {
+		s = strings.TrimSpace(strings.TrimSuffix(s, unit))
+	}
+	mult := 1.0
+	for _, sp := range SIPrefixes {
+		if sp.Prefix == "" {
+			continue
+		}
+		if strings.HasSuffix(s, sp.Prefix) {
+			s, mult = strings.TrimSuffix(s, sp.Prefix), sp.Mult
+			break
+		}
+		if sp.Prefix == "µ" && strings.HasSuffix(s, "u") {
+			s, mult = strings.TrimSuffix(s, "u"), sp.Mult
+			break
+		}
+	}
+	fv, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, fmt.Errorf("gi.ParseValueUnit: could not parse value %q: %v", str, err)
+	}
+	return float32(fv * mult), nil
+}
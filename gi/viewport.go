@@ -79,20 +79,32 @@ type Viewport interface {
 // with a convenience forwarding of the Paint methods operating on the current Paint
 type Viewport2D struct {
 	WidgetBase
-	Fill         bool         `desc:"fill the viewport with background-color from style"`
-	Geom         Geom2DInt    `desc:"Viewport-level viewbox within any parent Viewport2D"`
-	Render       girl.State   `copy:"-" json:"-" xml:"-" view:"-" desc:"render state for rendering"`
-	Pixels       *image.RGBA  `copy:"-" json:"-" xml:"-" view:"-" desc:"live pixels that we render into"`
-	Win          *Window      `copy:"-" json:"-" xml:"-" desc:"our parent window that we render into"`
-	CurStyleNode Node2D       `copy:"-" json:"-" xml:"-" view:"-" desc:"CurStyleNode2D is always set to the current node that is being styled used for finding url references -- only active during a Style pass"`
-	CurColor     gist.Color   `copy:"-" json:"-" xml:"-" view:"-" desc:"CurColor is automatically updated from the Color setting of a Style and accessible as a color name in any other style as currentcolor use accessor routines for concurrent-safe access"`
-	UpdtMu       sync.Mutex   `copy:"-" json:"-" xml:"-" view:"-" desc:"UpdtMu is mutex for viewport updates"`
-	UpdtStack    []Node2D     `copy:"-" json:"-" xml:"-" view:"-" desc:"stack of nodes requring basic updating"`
-	ReStack      []Node2D     `copy:"-" json:"-" xml:"-" view:"-" desc:"stack of nodes requiring a ReRender (i.e., anchors)"`
-	StackMu      sync.Mutex   `copy:"-" json:"-" xml:"-" view:"-" desc:"StackMu is mutex for adding to UpdtStack"`
-	StyleMu      sync.RWMutex `copy:"-" json:"-" xml:"-" view:"-" desc:"StyleMu is RW mutex protecting access to Style-related global vars"`
+	Fill         bool            `desc:"fill the viewport with background-color from style"`
+	Geom         Geom2DInt       `desc:"Viewport-level viewbox within any parent Viewport2D"`
+	Render       girl.State      `copy:"-" json:"-" xml:"-" view:"-" desc:"render state for rendering"`
+	Pixels       *image.RGBA     `copy:"-" json:"-" xml:"-" view:"-" desc:"live pixels that we render into"`
+	Win          *Window         `copy:"-" json:"-" xml:"-" desc:"our parent window that we render into"`
+	CurStyleNode Node2D          `copy:"-" json:"-" xml:"-" view:"-" desc:"CurStyleNode2D is always set to the current node that is being styled used for finding url references -- only active during a Style pass"`
+	CurColor     gist.Color      `copy:"-" json:"-" xml:"-" view:"-" desc:"CurColor is automatically updated from the Color setting of a Style and accessible as a color name in any other style as currentcolor use accessor routines for concurrent-safe access"`
+	UpdtMu       sync.Mutex      `copy:"-" json:"-" xml:"-" view:"-" desc:"UpdtMu is mutex for viewport updates"`
+	UpdtStack    []Node2D        `copy:"-" json:"-" xml:"-" view:"-" desc:"stack of nodes requring basic updating"`
+	ReStack      []Node2D        `copy:"-" json:"-" xml:"-" view:"-" desc:"stack of nodes requiring a ReRender (i.e., anchors)"`
+	StackMu      sync.Mutex      `copy:"-" json:"-" xml:"-" view:"-" desc:"StackMu is mutex for adding to UpdtStack"`
+	StyleMu      sync.RWMutex    `copy:"-" json:"-" xml:"-" view:"-" desc:"StyleMu is RW mutex protecting access to Style-related global vars"`
+	Dirty        image.Rectangle `copy:"-" json:"-" xml:"-" view:"-" desc:"dirty region of the viewport that is currently being re-rendered (see ReRender2DDirty) -- when non-empty, only the nodes that overlap it are rendered, clipped to it"`
 }
 
+// VpDirtyRender determines whether top-level viewports update nodes that
+// have changed (without structural changes) by re-rendering their regions
+// (the dirty regions) of the entire tree, clipped to those regions, so that
+// everything behind and on top of the updated nodes is rendered correctly,
+// instead of re-rendering each updated node on its own
+var VpDirtyRender = true
+
+// VpDirtyMax is the maximum number of separate dirty regions re-rendered
+// in one update -- beyond this they are merged into their union
+var VpDirtyMax = 8
+
 var KiT_Viewport2D = kit.Types.AddType(&Viewport2D{}, Viewport2DProps)
 
 var Viewport2DProps = ki.Props{
@@ -115,12 +127,14 @@ func NewViewport2D(width, height int) *Viewport2D {
 	vp := &Viewport2D{
 		Geom: Geom2DInt{Size: sz},
 	}
-	vp.Pixels = image.NewRGBA(image.Rectangle{Max: sz})
+	vp.Pixels = girl.GetRGBA(sz)
 	vp.Render.Init(width, height, vp.Pixels)
 	return vp
 }
 
-// Resize resizes the viewport, creating a new image -- updates Geom Size
+// Resize resizes the viewport, creating a new image -- updates Geom Size.
+// The images are pooled, so the previous image is reused by later resizes
+// of this or other viewports, and must not be retained by others.
 func (vp *Viewport2D) Resize(nwsz image.Point) {
 	if nwsz.X == 0 || nwsz.Y == 0 {
 		return
@@ -135,9 +149,10 @@ func (vp *Viewport2D) Resize(nwsz image.Point) {
 		}
 	}
 	if vp.Pixels != nil {
+		girl.PutRGBA(vp.Pixels)
 		vp.Pixels = nil
 	}
-	vp.Pixels = image.NewRGBA(image.Rectangle{Max: nwsz})
+	vp.Pixels = girl.GetRGBA(nwsz)
 	vp.Render.Init(nwsz.X, nwsz.Y, vp.Pixels)
 	vp.Geom.Size = nwsz // make sure
 	// fmt.Printf("vp %v resized to: %v, bounds: %v\n", vp.PathUnique(), nwsz, vp.Pixels.Bounds())
@@ -411,6 +426,52 @@ func (vp *Viewport2D) ReRender2DAnchor(gni Node2D) {
 	vp.This().(Viewport).VpUploadRegion(pw.VpBBox, wbb)
 }
 
+// ReRender2DDirty re-renders given dirty regions of the viewport: for each
+// region, the entire tree is rendered with the Dirty region set, so only
+// the nodes that overlap it are rendered, clipped to it, and then just that
+// region is uploaded to the window texture using Window.UploadVpRegion call.
+// This should be covered by an outer UpdateStart / End bracket on Window to drive
+// publishing changes.
+func (vp *Viewport2D) ReRender2DDirty(dirty []image.Rectangle) {
+	if !vp.This().(Viewport).VpIsVisible() {
+		return
+	}
+	for _, r := range dirty {
+		r = r.Intersect(vp.Pixels.Bounds())
+		if r.Empty() {
+			continue
+		}
+		if Render2DTrace {
+			fmt.Printf("Render: vp dirty re-render: %v region: %v\n", vp.PathUnique(), r)
+		}
+		vp.Dirty = r
+		if vp.PushBounds() {
+			if vp.Fill {
+				vp.FillViewport()
+			}
+			vp.Render2DChildren()
+			vp.PopBounds()
+		}
+		vp.Dirty = image.ZR
+		vp.BBoxMu.RLock()
+		wbb := r.Add(vp.WinBBox.Min)
+		vp.BBoxMu.RUnlock()
+		vp.This().(Viewport).VpUploadRegion(r, wbb)
+	}
+}
+
+// DirtyBounds returns the render bounds for a node with given viewport
+// bounding box -- if a Dirty region is being re-rendered, it is clipped to
+// that, and false is returned if the node does not overlap it at all, so
+// it need not be rendered
+func (vp *Viewport2D) DirtyBounds(bb image.Rectangle) (image.Rectangle, bool) {
+	if vp.Dirty.Empty() {
+		return bb, true
+	}
+	bb = bb.Intersect(vp.Dirty)
+	return bb, !bb.Empty()
+}
+
 // Delete this popup viewport -- has already been disconnected from window
 // events and parent is nil -- called by window when a popup is deleted -- it
 // destroys the vp and its main layout, see VpFlagPopupDestroyAll for whether
@@ -429,6 +490,17 @@ func (vp *Viewport2D) DeletePopup() {
 		}
 	}
 	vp.This().Destroy() // nuke everything else in us
+	vp.ReleasePixels()
+}
+
+// ReleasePixels releases the Pixels image of the viewport for reuse by
+// other viewports, when it is no longer used -- it is called when popups
+// are deleted, and can be called for other viewports that are destroyed
+func (vp *Viewport2D) ReleasePixels() {
+	vp.UpdtMu.Lock()
+	girl.PutRGBA(vp.Pixels)
+	vp.Pixels = nil
+	vp.UpdtMu.Unlock()
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////
@@ -563,6 +635,9 @@ func (vp *Viewport2D) FullRender2DTree() {
 	if Render2DTrace {
 		fmt.Printf("Render: %v doing full render\n", vp.PathUnique())
 	}
+	fp := vp.FrameProf()
+	fp.VpBegin(vp)
+	defer fp.VpEnd(vp)
 	vp.WidgetBase.FullRender2DTree()
 	vp.ClearFlag(int(VpFlagDoingFullRender))
 }
@@ -586,10 +661,23 @@ func (vp *Viewport2D) PushBounds() bool {
 			// fmt.Printf("not rendering vp %v bc empty winbox -- ours: %v par: %v\n", vp.Nm, vp.WinBBox, vp.Viewport.WinBBox)
 			return false
 		}
+		if _, ok := vp.Viewport.DirtyBounds(vp.VpBBox); !ok {
+			return false
+		}
 	}
 	rs := &vp.Render
 	bb := vp.Pixels.Bounds() // our bounds.. not vp.VpBBox)
+	bb, _ = vp.DirtyBounds(bb)
 	rs.PushBounds(bb)
+	if vp.Viewport != nil && vp.Viewport.Render.PDF != nil { // record in place into parent's pdf
+		rs.PDF = vp.Viewport.Render.PDF
+		r := vp.Geom.Bounds()
+		if vp.Par != nil {
+			pni, _ := KiToNode2D(vp.Par)
+			r = r.Intersect(pni.ChildrenBBox2D())
+		}
+		rs.PDF.PushOrigin(vp.Geom.Pos, r)
+	}
 	if Render2DTrace {
 		fmt.Printf("Render: %v at %v\n", vp.PathUnique(), bb)
 	}
@@ -599,6 +687,10 @@ func (vp *Viewport2D) PushBounds() bool {
 func (vp *Viewport2D) PopBounds() {
 	rs := &vp.Render
 	rs.PopBounds()
+	if vp.Viewport != nil && rs.PDF != nil && rs.PDF == vp.Viewport.Render.PDF {
+		rs.PDF.PopOrigin()
+		rs.PDF = nil
+	}
 }
 
 func (vp *Viewport2D) Move2D(delta image.Point, parBBox image.Rectangle) {
@@ -824,6 +916,9 @@ func (vp *Viewport2D) UnblockUpdates() {
 func (vp *Viewport2D) UpdateNodes() {
 	vp.UpdtMu.Lock()
 	vp.SetFlag(int(VpFlagUpdatingNode))
+	fp := vp.FrameProf()
+	fp.VpBegin(vp)
+	defer fp.VpEnd(vp)
 	tn := vp.TopNode2D()
 	if tn != nil && tn != vp.This().(Node) {
 		wupdt := tn.UpdateStart()
@@ -855,6 +950,13 @@ func (vp *Viewport2D) UpdateNodes() {
 			vp.ReRender2DAnchor(nii)
 			continue
 		}
+		if len(vp.UpdtStack) > 0 && VpDirtyRender && vp.Viewport == nil {
+			nodes := vp.UpdtStack
+			vp.UpdtStack = nil
+			vp.StackMu.Unlock()
+			vp.UpdateDirty(nodes)
+			continue
+		}
 		if len(vp.UpdtStack) > 0 {
 			nii := vp.UpdtStack[0]
 			vp.UpdtStack = vp.UpdtStack[1:]
@@ -882,6 +984,31 @@ func (vp *Viewport2D) UpdateNode(nii Node2D) {
 	}
 }
 
+// UpdateDirty is called under UpdtMu lock to update given nodes together
+// (see VpDirtyRender): those that upload directly to the window do so, and
+// the regions of the rest are re-rendered as dirty regions (see
+// ReRender2DDirty), with overlapping regions merged
+func (vp *Viewport2D) UpdateDirty(nodes []Node2D) {
+	var dirty []image.Rectangle
+	for _, nii := range nodes {
+		if nii.DirectWinUpload() {
+			if Update2DTrace {
+				fmt.Printf("Update: Viewport2D: %v DirectWinUpload on %v\n", vp.PathUnique(), nii.PathUnique())
+			}
+			continue
+		}
+		if Update2DTrace {
+			fmt.Printf("Update: Viewport2D: %v dirty ReRender2D on %v\n", vp.PathUnique(), nii.PathUnique())
+		}
+		gn := nii.AsNode2D()
+		gn.BBoxMu.RLock()
+		bb := gn.VpBBox
+		gn.BBoxMu.RUnlock()
+		dirty = MergeRect(dirty, bb, VpDirtyMax)
+	}
+	vp.ReRender2DDirty(dirty)
+}
+
 //////////////////////////////////////////////////////////////////////////////////
 //  Style state
 
@@ -951,3 +1078,43 @@ func (vp *Viewport2D) SavePNG(path string) error {
 func (vp *Viewport2D) EncodePNG(w io.Writer) error {
 	return png.Encode(w, vp.Pixels)
 }
+
+// CaptureImage returns a copy of the current rendered image of the
+// viewport, e.g., to save as a PNG file (see SaveImage).  To get a
+// consistent image, call it from within event processing for the window,
+// e.g., in an Action callback, when the viewport is not being rendered.
+func (vp *Viewport2D) CaptureImage() *image.RGBA {
+	if vp == nil || vp.Pixels == nil {
+		return nil
+	}
+	img := image.NewRGBA(image.Rectangle{Max: vp.Pixels.Bounds().Size()})
+	draw.Draw(img, img.Bounds(), vp.Pixels, vp.Pixels.Bounds().Min, draw.Src)
+	return img
+}
+
+// GrabWidgetImage returns a copy of the rendered image of given node and
+// all of its children.  If the node is itself a Viewport2D (e.g., an SVG
+// drawing), its entire image is returned, otherwise the region of its
+// viewport that it occupies, clipped to what is currently visible -- nil
+// is returned if it is not visible at all.  See Viewport2D.CaptureImage
+// for when to call.
+func GrabWidgetImage(node Node2D) *image.RGBA {
+	if vp := node.AsViewport2D(); vp != nil {
+		return vp.CaptureImage()
+	}
+	nb := node.AsNode2D()
+	vp := nb.ViewportSafe()
+	if vp == nil || vp.Pixels == nil {
+		return nil
+	}
+	nb.BBoxMu.RLock()
+	bb := nb.VpBBox
+	nb.BBoxMu.RUnlock()
+	bb = bb.Intersect(vp.Pixels.Bounds())
+	if bb.Empty() {
+		return nil
+	}
+	img := image.NewRGBA(image.Rectangle{Max: bb.Size()})
+	draw.Draw(img, img.Bounds(), vp.Pixels, bb.Min, draw.Src)
+	return img
+}
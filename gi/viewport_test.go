@@ -0,0 +1,62 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin/driver/offscreen"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/ki/ki"
+)
+
+func TestViewportDirtyRender(t *testing.T) {
+	var lb1, lb2 *gi.Label
+	var bt *gi.Button
+	clicks := 0
+	win := newTestWindow("dirty-render", func(mfr *gi.Frame) {
+		lb1 = gi.AddNewLabel(mfr, "lb1", "first label")
+		lb2 = gi.AddNewLabel(mfr, "lb2", "second label")
+		bt = gi.AddNewButton(mfr, "bt")
+		bt.SetText("click")
+		bt.ButtonSig.Connect(mfr.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig == int64(gi.ButtonClicked) {
+				clicks++
+			}
+		})
+	})
+	defer win.Close()
+
+	lb1.SetText("first lab")
+	dirty := waitIdle(win)
+	dmg := offscreen.LastDamage(win.OSWin)
+	if len(dmg) == 0 {
+		t.Fatalf("updating a label published the whole window")
+	}
+	for _, r := range dmg {
+		if !r.In(lb1.WinBBox) {
+			t.Errorf("damage region: %v not in updated label: %v", r, lb1.WinBBox)
+		}
+		if r.Overlaps(lb2.WinBBox) {
+			t.Errorf("damage region: %v overlaps other label: %v", r, lb2.WinBBox)
+		}
+	}
+
+	// widgets outside of the dirty region still get events
+	bb := bt.WinBBox
+	offscreen.MouseClick(win.OSWin, bb.Min.Add(bb.Size().Div(2)), mouse.Left)
+	dirty = waitIdle(win)
+	if clicks != 1 {
+		t.Errorf("button outside of the dirty region clicked %d times, want 1", clicks)
+	}
+
+	win.FullReRender()
+	full := waitIdle(win)
+	if !bytes.Equal(dirty.Pix, full.Pix) {
+		t.Errorf("dirty region re-render differs from full re-render")
+	}
+}
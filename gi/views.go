@@ -31,6 +31,10 @@ type ViewIFace interface {
 	// KeyMapsView opens an interactive view of KeyMaps object
 	KeyMapsView(maps *KeyMaps)
 
+	// KeyCmdsView opens an interactive view of KeyCmds object, for remapping
+	// the key chords of the app
+	KeyCmdsView(cmds *KeyCmds)
+
 	// PrefsDetView opens an interactive view of given detailed preferences object
 	PrefsDetView(prefs *PrefsDetailed)
 
@@ -40,6 +44,10 @@ type ViewIFace interface {
 	// SetHiStyleDefault sets the current default histyle.StyleDefault
 	SetHiStyleDefault(hsty HiStyleName)
 
+	// HiStylePair returns the dark (or light) highlighting style paired with
+	// given one -- see histyle.StylePairs
+	HiStylePair(hsty HiStyleName, dark bool) HiStyleName
+
 	// HiStyleInit initializes the histyle package -- called during overall gi init.
 	HiStyleInit()
 
@@ -51,6 +59,18 @@ type ViewIFace interface {
 
 	// PrefsDbgView opens an interactive view of given debugging preferences object
 	PrefsDbgView(prefs *PrefsDebug)
+
+	// SettingsView opens an interactive view of the registered settings
+	// sections, in tabs
+	SettingsView(reg *SettingsRegistry)
+
+	// WindowUndo undoes, or redoes if redo, the last edit in the views of
+	// given window, on its undo stack -- returns false if there is none
+	WindowUndo(win *Window, redo bool) bool
+
+	// WindowUndoLabel returns the label of the edit that WindowUndo would
+	// undo, or redo if redo, e.g., Delete Row -- "" if there is none
+	WindowUndoLabel(win *Window, redo bool) string
 }
 
 // TheViewIFace is the implementation of the interface, defined in giv package
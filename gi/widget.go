@@ -194,6 +194,7 @@ func (wb *WidgetBase) Style2DWidget() {
 	}
 	styprops := *wb.Properties()
 	parSty := wb.ParentStyle()
+	ApplyAppStyles(gii, wb.Viewport, &wb.Sty, parSty, "")
 	wb.Sty.SetStyleProps(parSty, styprops, wb.Viewport)
 
 	// look for class-specific style sheets among defaults -- have to do these
@@ -469,8 +470,12 @@ func (wb *WidgetBase) PushBounds() bool {
 		return false
 	}
 	mvp := wb.ViewportSafe()
+	bb, ok := mvp.DirtyBounds(wb.VpBBox)
+	if !ok { // outside of the dirty region being re-rendered
+		return false
+	}
 	rs := &mvp.Render
-	rs.PushBounds(wb.VpBBox)
+	rs.PushBounds(bb)
 	wb.ConnectToViewport()
 	if Render2DTrace {
 		fmt.Printf("Render: %v at %v\n", wb.PathUnique(), wb.VpBBox)
@@ -25,6 +25,7 @@ import (
 	"github.com/goki/gi/oswin/key"
 	"github.com/goki/gi/oswin/mimedata"
 	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/oswin/touch"
 	"github.com/goki/gi/oswin/window"
 	"github.com/goki/ki/bitflag"
 	"github.com/goki/ki/ki"
@@ -92,6 +93,16 @@ var KeyEventTrace = false
 // can be set in PrefsDebug from prefs gui
 var EventTrace = false
 
+// WinDamageRegions determines whether windows only publish the regions
+// that have changed since the last publish (e.g., a blinking cursor or hover
+// highlight), for window drivers that support it (oswin.RegionPublisher),
+// instead of publishing the entire window every time
+var WinDamageRegions = true
+
+// WinDamageMax is the maximum number of separate damage regions tracked
+// between publishes -- beyond this they are merged into their union
+var WinDamageMax = 16
+
 // WinNewCloseTime records last time a new window was opened or another
 // closed -- used to trigger updating of Window menus on each window.
 var WinNewCloseTime time.Time
@@ -134,6 +145,10 @@ var WindowOpenTimer time.Time
 // * Finally if there are any overlays (sprites), then we need a separate
 //   transparent texture, OverTex, which critically allows WinTex to remain
 //   intact while overlays are updated.
+// * Each upload to WinTex (and sprite update in OverTex) records the Damage
+//   region it updated, and Publish only copies those regions to the window
+//   if the driver supports it (oswin.RegionPublisher), so that small updates
+//   (e.g., a blinking cursor) are cheap.
 type Window struct {
 	NodeBase
 	Title             string            `desc:"displayed name of window, for window manager etc -- window object name is the internal handle and is used for tracking property info etc"`
@@ -155,6 +170,19 @@ type Window struct {
 	PopupFocus        ki.Ki             `json:"-" xml:"-" desc:"node to focus on when next popup is activated -- use SetNextPopup"`
 	DelPopup          ki.Ki             `json:"-" xml:"-" desc:"this popup will be popped at the end of the current event cycle -- use SetDelPopup"`
 	PopMu             sync.RWMutex      `json:"-" xml:"-" view:"-" desc:"read-write mutex that protects popup updating and access"`
+	Animator          Animator          `json:"-" xml:"-" view:"-" desc:"runs the animations of nodes in this window"`
+	Damage            []image.Rectangle `json:"-" xml:"-" view:"-" desc:"regions of the window texture that have been updated since the last publish (damage regions), in window coordinates -- only these are published if DamageAll is false"`
+	DamageAll         bool              `json:"-" xml:"-" view:"-" desc:"the entire window has been updated since the last publish, so it must all be published"`
+	Inspect           InspectFunc       `json:"-" xml:"-" view:"-" desc:"if set, is called with each event before it is sent to the widgets -- used by the GoGi editor to inspect widgets by hovering over and clicking on them"`
+	Recording         *EventRecording   `json:"-" xml:"-" view:"-" desc:"if set, the input events of the window are recorded in it, for playing back in tests -- see StartRecording -- must be accessed under RecMu, e.g., with CurRecording"`
+	RecMu             sync.Mutex        `json:"-" xml:"-" view:"-" desc:"mutex that protects the Recording, which is set outside of the event loop"`
+	FrameProf         *FrameProfiler    `json:"-" xml:"-" view:"-" desc:"if set, records the stats of the frames of the window -- see StartFrameProfile"`
+	spriteRects       []image.Rectangle // regions of OverTex drawn with sprites
+	accessTree        *oswin.AccessNode // last accessibility tree sent to the driver
+	accessFocus       string            // id of last accessibility focus sent to the driver
+	geom              WindowGeom        // last recorded geometry, for the session
+	keySeq            key.Chord         // chords typed so far of a multi-key sequence
+	toasts            []*Toast          // toasts showing in the window, oldest first
 	lastWinMenuUpdate time.Time
 	// below are internal vars used during the event loop
 	delPop        bool
@@ -164,6 +192,11 @@ type Window struct {
 
 var KiT_Window = kit.Types.AddType(&Window{}, WindowProps)
 
+// InspectFunc is a function that inspects the events of a window before they
+// are sent to the widgets, returning true if the event was consumed, so it
+// is not sent
+type InspectFunc func(evi oswin.Event) bool
+
 var WindowProps = ki.Props{
 	"EnumType:Flag": KiT_WinFlags,
 }
@@ -309,6 +342,8 @@ func NewWindow(name, title string, opts *oswin.NewWindowOptions) *Window {
 	win := &Window{}
 	win.InitName(win, name)
 	win.EventMgr.Master = win
+	win.Animator.Win = win
+	win.DamageAll = true
 	win.Title = title
 	win.SetOnlySelfUpdate() // has its own PublishImage update logic
 	var err error
@@ -333,12 +368,19 @@ func NewMainWindow(name, title string, width, height int) *Window {
 		Title: title, Size: image.Point{width, height}, StdPixels: true,
 	}
 	wgp := WinGeomPrefs.Pref(name, nil)
+	restoring := false
+	if ws := restoringWin; ws != nil && ws.Name == name && ws.Geom.SX > 0 {
+		wg := ws.Geom // geometry of the window in the session takes precedence
+		wg.FitInSize(oswin.TheApp.Screen(0).Geometry.Size())
+		wgp = &wg
+		restoring = true
+	}
 	if wgp != nil {
 		opts.Size = wgp.Size()
 		opts.Pos = wgp.Pos()
 		opts.StdPixels = false
 		// fmt.Printf("got prefs for %v: size: %v pos: %v\n", name, opts.Size, opts.Pos)
-		if _, found := AllWindows.FindName(name); found { // offset from existing
+		if _, found := AllWindows.FindName(name); found && !restoring { // offset from existing
 			opts.Pos.X += 20
 			opts.Pos.Y += 20
 		}
@@ -687,10 +729,37 @@ func (w *Window) Resized(sz image.Point) {
 	}
 	w.OverTex = nil // dynamically allocated when needed
 	w.ClearFlag(int(WinFlagOverTexActive))
+	w.spriteRects = nil
+	w.DamageAll = true
 	w.Viewport.Resize(sz)
 	WinGeomPrefs.RecordPref(w)
 	w.UpMu.Unlock()
 	w.FullReRender()
+	if len(w.toasts) > 0 {
+		w.LayoutToasts()
+	}
+}
+
+// ScaleChanged is called when the window has moved to a screen with a
+// different DPI or device pixel ratio (scale): the logical DPI of the screens
+// is updated from their physical DPI, and the window is re-styled and
+// re-rendered at the new scale, with the current size.
+func (w *Window) ScaleChanged() {
+	if !w.IsVisible() {
+		return
+	}
+	if WinEventTrace {
+		fmt.Printf("Win: %v ScaleChanged to LogicalDPI: %v\n", w.Nm, w.LogicalDPI())
+	}
+	Prefs.ApplyDPI()
+	w.FocusInactivate()
+	w.InactivateAllSprites()
+	if w.Viewport.Geom.Size != w.OSWin.Size() {
+		w.Resized(w.OSWin.Size())
+		return
+	}
+	WinGeomPrefs.RecordPref(w)
+	w.FullReRender()
 }
 
 // Raise requests that the window be at the top of the stack of windows,
@@ -706,6 +775,13 @@ func (w *Window) Minimize() {
 	w.OSWin.Minimize()
 }
 
+// Hide hides the window entirely, including from the taskbar or dock,
+// e.g., for minimizing to the system tray (see TrayIcon) -- Raise shows
+// it again.
+func (w *Window) Hide() {
+	w.OSWin.Hide()
+}
+
 // Close closes the window -- this is not a request -- it means:
 // definitely close it -- flags window as such -- check IsClosing()
 func (w *Window) Close() {
@@ -726,6 +802,8 @@ func (w *Window) CloseReq() {
 
 // Closed frees any resources after the window has been closed.
 func (w *Window) Closed() {
+	sessionWindowClosed(w)
+	instanceWindowClosed(w)
 	w.UpMu.Lock()
 	AllWindows.Delete(w)
 	MainWindows.Delete(w)
@@ -840,11 +918,14 @@ func Init() {
 		PrefsDet.Defaults()
 		PrefsDbg.Connect()
 		Prefs.Open()
+		Prefs.ApplyOSTheme()
 		Prefs.Apply()
+		InitTouchUI()
 		oswin.InitScreenLogicalDPIFunc = Prefs.ApplyDPI // called when screens are initialized
 		TheViewIFace.HiStyleInit()
 		WinGeomPrefs.NeedToReload() // gets time stamp associated with open, so it doesn't re-open
 		WinGeomPrefs.Open()
+		AppKeyCmds.OpenPrefs()
 	}
 }
 
@@ -970,6 +1051,7 @@ func (w *Window) UploadVpRegion(vp *Viewport2D, vpBBox, winBBox image.Rectangle)
 	if err != nil {
 		log.Println(err)
 	}
+	w.AddDamage(image.Rectangle{Min: winBBox.Min, Max: winBBox.Min.Add(vpBBox.Size())})
 	// pr.End()
 	w.ClearWinUpdating()
 	w.UpMu.Unlock()
@@ -993,6 +1075,7 @@ func (w *Window) UploadVp(vp *Viewport2D, offset image.Point) {
 		fmt.Printf("Win: %v uploading Vp %v, image bound: %v, wintex bounds: %v\n", w.PathUnique(), vp.PathUnique(), vp.Pixels.Bounds(), w.OSWin.WinTex().Bounds())
 	}
 	w.OSWin.SetWinTexSubImage(offset, vp.Pixels, vp.Pixels.Bounds())
+	w.AddDamage(vp.Pixels.Bounds().Sub(vp.Pixels.Bounds().Min).Add(offset))
 	// pr.End()
 	w.ClearWinUpdating()
 	w.ClearFlag(int(WinFlagPublishFullReRender))
@@ -1008,6 +1091,7 @@ func (w *Window) DirectUploads() {
 			continue
 		}
 		du.DirectWinUpload() // upload directly to WinTex
+		w.AddDamageNode(du)
 	}
 }
 
@@ -1023,6 +1107,7 @@ func (w *Window) DirectUpdate(du Node2D) {
 	}
 	if len(w.PopupStack) == 0 && w.Popup == nil {
 		du.DirectWinUpload() // upload directly to WinTex
+		w.AddDamageNode(du)
 		w.UpMu.Unlock()
 		return
 	}
@@ -1049,6 +1134,7 @@ func (w *Window) UploadAllViewports() {
 		fmt.Printf("Win: %v uploading full Vp, image bound: %v, wintex bounds: %v updt: %v\n", w.PathUnique(), w.Viewport.Pixels.Bounds(), w.OSWin.WinTex().Bounds(), updt)
 	}
 	w.OSWin.SetWinTexSubImage(image.ZP, w.Viewport.Pixels, w.Viewport.Pixels.Bounds())
+	w.DamageAll = true
 	// next any direct uploaders
 	w.DirectUploads()
 	// then all the current popups
@@ -1103,7 +1189,9 @@ func (w *Window) ClearWinUpdating() {
 }
 
 // Publish does the final step of updating of the window based on the current
-// texture (and overlay texture if active)
+// texture (and overlay texture if active) -- if WinDamageRegions is on and
+// the window driver supports it, only the Damage regions updated since the
+// last publish are published, otherwise the entire window is.
 func (w *Window) Publish() {
 	if !w.IsVisible() || w.OSWin.IsMinimized() {
 		if WinEventTrace {
@@ -1111,6 +1199,11 @@ func (w *Window) Publish() {
 		}
 		return
 	}
+	fp := w.FrameProf
+	if fp != nil && fp.HUD {
+		w.RenderFrameHUD() // renders overlays, so must be done before locking
+	}
+	pst := fp.Begin()
 	w.UpMu.Lock()       // block all updates while we publish
 	if !w.IsVisible() { // could have closed while we waited for lock
 		if WinEventTrace {
@@ -1137,18 +1230,105 @@ func (w *Window) Publish() {
 	// pr := prof.Start("win.Publish")
 	wt := w.OSWin.WinTex()
 	if wt != nil {
-		w.OSWin.Copy(image.ZP, wt, wt.Bounds(), oswin.Src, nil)
-		if w.OverTex != nil && w.HasFlag(int(WinFlagOverTexActive)) {
-			w.OSWin.Copy(image.ZP, w.OverTex, w.OverTex.Bounds(), oswin.Over, nil)
-		}
-		w.OSWin.Publish()
-		if Render2DTrace {
-			fmt.Printf("Win %v did publish\n", w.Nm)
+		overAct := w.OverTex != nil && w.HasFlag(int(WinFlagOverTexActive))
+		rp, hasRp := w.OSWin.(oswin.RegionPublisher)
+		if WinDamageRegions && hasRp && !w.DamageAll {
+			for _, r := range w.Damage {
+				w.OSWin.Copy(r.Min, wt, r, oswin.Src, nil)
+				if overAct {
+					w.OSWin.Copy(r.Min, w.OverTex, r, oswin.Over, nil)
+				}
+			}
+			rp.PublishRegions(w.Damage)
+			if Render2DTrace {
+				fmt.Printf("Win %v did publish of regions: %v\n", w.Nm, w.Damage)
+			}
+		} else {
+			w.OSWin.Copy(image.ZP, wt, wt.Bounds(), oswin.Src, nil)
+			if overAct {
+				w.OSWin.Copy(image.ZP, w.OverTex, w.OverTex.Bounds(), oswin.Over, nil)
+			}
+			w.OSWin.Publish()
+			if Render2DTrace {
+				fmt.Printf("Win %v did publish\n", w.Nm)
+			}
 		}
+		w.Damage = nil
+		w.DamageAll = false
 	}
 	// pr.End()
 	w.ClearWinUpdating()
 	w.UpMu.Unlock()
+	fp.EndFrame(w, pst)
+	w.AccessUpdate()
+}
+
+// PublishAll publishes the entire window, regardless of the Damage regions --
+// e.g., when the window system has lost the window contents
+func (w *Window) PublishAll() {
+	w.UpMu.Lock()
+	w.DamageAll = true
+	w.UpMu.Unlock()
+	w.Publish()
+}
+
+// AddDamage records that given region of the window texture (in window
+// coordinates) has been updated, and must be published -- must be called
+// under UpMu.Lock().  Overlapping regions are merged, and if there are more
+// than WinDamageMax regions, they are merged into their union.
+func (w *Window) AddDamage(r image.Rectangle) {
+	if w.DamageAll {
+		return
+	}
+	r = r.Intersect(w.OSWin.WinTex().Bounds())
+	w.Damage = MergeRect(w.Damage, r, WinDamageMax)
+}
+
+// MergeRect adds given rectangle to given list of non-overlapping
+// rectangles, merging it with any that it overlaps, and returns the
+// updated list -- if there are then more than max of them, they are all
+// merged into their union.  Empty rectangles are ignored.
+func MergeRect(rects []image.Rectangle, r image.Rectangle, max int) []image.Rectangle {
+	if r.Empty() {
+		return rects
+	}
+	for {
+		merged := false
+		for i, dr := range rects {
+			if dr.Overlaps(r) {
+				r = r.Union(dr)
+				rects = append(rects[:i], rects[i+1:]...)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			break
+		}
+	}
+	rects = append(rects, r)
+	if len(rects) > max {
+		u := rects[0]
+		for _, dr := range rects[1:] {
+			u = u.Union(dr)
+		}
+		rects = []image.Rectangle{u}
+	}
+	return rects
+}
+
+// AddDamageNode records the window bounding box of given node as a damage
+// region (see AddDamage) -- must be called under UpMu.Lock()
+func (w *Window) AddDamageNode(gn Node2D) {
+	nb := gn.AsNode2D()
+	if nb == nil {
+		w.DamageAll = true
+		return
+	}
+	nb.BBoxMu.RLock()
+	wbb := nb.WinBBox
+	nb.BBoxMu.RUnlock()
+	w.AddDamage(wbb)
 }
 
 // SignalWindowPublish is the signal receiver function that publishes the
@@ -1214,7 +1394,13 @@ func (w *Window) RenderOverlays() {
 		return
 	}
 	if w.ActiveSprites == 0 || len(w.Sprites) == 0 {
+		w.UpMu.Lock()
 		w.ClearFlag(int(WinFlagOverTexActive))
+		for _, r := range w.spriteRects { // sprites need to be removed from window
+			w.AddDamage(r)
+		}
+		w.spriteRects = nil
+		w.UpMu.Unlock()
 		return
 	}
 	w.UpMu.Lock()
@@ -1224,17 +1410,34 @@ func (w *Window) RenderOverlays() {
 	}
 	w.SetFlag(int(WinFlagOverTexActive))
 	updt := w.UpdateStart()
-	w.MakeOverTex()                 // ensures correct size
+	if w.MakeOverTex() || !WinDamageRegions { // ensures correct size
+		w.spriteRects = nil
+		w.DamageAll = true
+	}
+	prvRects := w.spriteRects
 	oswin.TheApp.RunOnMain(func() { // clear the texture
 		if w.OSWin.Activate() {
-			w.OverTex.Fill(w.OverTex.Bounds(), color.Transparent, draw.Src)
+			if w.DamageAll {
+				w.OverTex.Fill(w.OverTex.Bounds(), color.Transparent, draw.Src)
+			} else { // only where sprites were drawn before
+				for _, r := range prvRects {
+					w.OverTex.Fill(r, color.Transparent, draw.Src)
+				}
+			}
 		}
 	})
+	for _, r := range prvRects {
+		w.AddDamage(r)
+	}
+	w.spriteRects = nil
 	for _, sp := range w.Sprites {
 		if !sp.On {
 			continue
 		}
 		w.RenderSprite(sp)
+		r := sp.Pixels.Bounds().Sub(sp.Pixels.Bounds().Min).Add(sp.Geom.Pos)
+		w.spriteRects = append(w.spriteRects, r)
+		w.AddDamage(r)
 	}
 	w.ClearFlag(int(WinFlagPublishFullReRender))
 	w.UpMu.Unlock()
@@ -1486,6 +1689,9 @@ func (w *Window) ProcessEvent(evi oswin.Event) {
 		fmt.Printf("Win: %v got out-of-range event: %v\n", w.Nm, et)
 		return
 	}
+	if rec := w.CurRecording(); rec != nil {
+		rec.Record(evi)
+	}
 
 	{ // popup delete check
 		w.PopMu.RLock()
@@ -1531,6 +1737,11 @@ func (w *Window) ProcessEvent(evi oswin.Event) {
 		return
 	}
 
+	if w.Inspect != nil && w.Inspect(evi) {
+		w.EventMgr.MouseEventReset(evi)
+		return
+	}
+
 	////////////////////////////////////////////////////////////////////////////
 	// Send Events to Widgets
 
@@ -1571,6 +1782,12 @@ func (w *Window) ProcessEvent(evi oswin.Event) {
 			if keyDelPop {
 				w.delPop = true
 			}
+		case *dnd.Event:
+			if e.HasFiles() {
+				w.DropFilesEvent(e)
+			}
+		case *touch.LongPressEvent:
+			w.LongPressClick(e)
 		}
 	}
 
@@ -1583,7 +1800,8 @@ func (w *Window) ProcessEvent(evi oswin.Event) {
 		cpop := w.CurPopup()
 		if cpop != nil && !w.delPop {
 			if PopupIsTooltip(cpop) {
-				if et != oswin.MouseMoveEvent {
+				// stylus events come along with emulated mouse events
+				if et != oswin.MouseMoveEvent && et != oswin.StylusEvent {
 					w.delPop = true
 				}
 			} else if me, ok := evi.(*mouse.Event); ok {
@@ -1627,7 +1845,7 @@ func (w *Window) FilterEvent(evi oswin.Event) bool {
 		if WinEventTrace {
 			fmt.Printf("Win: %v skipping paint after resize\n", w.Nm)
 		}
-		w.Publish() // this is essential on mac for any paint event
+		w.PublishAll() // this is essential on mac for any paint event
 		w.SetFlag(int(WinFlagGotPaint))
 		return false // X11 always sends a paint after a resize -- we just use resize
 	}
@@ -1661,11 +1879,65 @@ func (w *Window) FilterEvent(evi oswin.Event) bool {
 	return w.EventMgr.FilterLaggyEvents(evi)
 }
 
+// LongPressClick sends a right mouse press and release at the position of
+// a long press that no widget has processed, so that it opens context
+// menus, as on touch screens generally
+func (w *Window) LongPressClick(e *touch.LongPressEvent) {
+	for _, act := range []mouse.Actions{mouse.Press, mouse.Release} {
+		me := &mouse.Event{Where: e.Where, Button: mouse.Right, Action: act}
+		me.Init()
+		if rec := w.CurRecording(); rec != nil {
+			rec.Skip(me)
+		}
+		w.OSWin.Send(me)
+	}
+}
+
 // HiProrityEvents processes High-priority events for Window.
 // Window gets first crack at these events, and handles window-specific ones
 // returns true if processing should continue and false if was handled
 func (w *Window) HiPriorityEvents(evi oswin.Event) bool {
 	switch e := evi.(type) {
+	case *oswin.CustomEvent:
+		if _, ok := e.Data.(animTick); ok {
+			w.Animator.Tick()
+			e.SetProcessed()
+			return false
+		}
+		if te, ok := e.Data.(toastEvent); ok {
+			if te.show {
+				te.toast.show()
+			} else {
+				te.toast.remove()
+			}
+			e.SetProcessed()
+			return false
+		}
+		if p, ok := e.Data.(*Progress); ok {
+			p.updateGUI()
+			e.SetProcessed()
+			return false
+		}
+		if fe, ok := e.Data.(fileWatchEvent); ok {
+			fe.fun(fe.evs)
+			e.SetProcessed()
+			return false
+		}
+		if te, ok := e.Data.(trayEvent); ok {
+			te.process()
+			e.SetProcessed()
+			return false
+		}
+		if ie, ok := e.Data.(instanceEvent); ok {
+			ie.process(w)
+			e.SetProcessed()
+			return false
+		}
+		if ps, ok := e.Data.(eventPlaySync); ok {
+			close(ps)
+			e.SetProcessed()
+			return false
+		}
 	case *window.Event:
 		switch e.Action {
 		// case window.Resize: // note: already handled earlier in lag process
@@ -1691,7 +1963,7 @@ func (w *Window) HiPriorityEvents(evi oswin.Event) bool {
 				}
 				w.SendShowEvent() // happens AFTER full render
 			}
-			w.Publish()
+			w.PublishAll()
 		case window.Move:
 			e.SetProcessed()
 			if w.HasFlag(int(WinFlagGotPaint)) { // moves before paint are not accurate on X11
@@ -1726,6 +1998,12 @@ func (w *Window) HiPriorityEvents(evi oswin.Event) bool {
 				Prefs.ApplyDPI()
 				Prefs.UpdateAll()
 			}
+		case window.ThemeChanged:
+			if Prefs.ApplyOSTheme() {
+				Prefs.UpdateAll()
+			}
+		case window.ScaleChange:
+			w.ScaleChanged()
 		}
 		return false // don't do anything else!
 	case *mouse.DragEvent:
@@ -1737,6 +2015,9 @@ func (w *Window) HiPriorityEvents(evi oswin.Event) bool {
 			}
 		}
 	case *mouse.Event:
+		if w.toastMouseEvent(e) {
+			return false
+		}
 		if w.EventMgr.DNDStage == DNDStarted && e.Action == mouse.Release {
 			w.DNDDropEvent(e)
 		}
@@ -1831,11 +2112,15 @@ func (w *Window) IsInScope(k ki.Ki, popup bool) bool {
 	return !popup
 }
 
-// AddShortcut adds given shortcut to given action.
+// AddShortcut adds given shortcut to given action -- the action is
+// registered in AppKeyCmds, and the chord set by the user for it, if any, is
+// used instead, and shown as the Shortcut of the action.
 func (w *Window) AddShortcut(chord key.Chord, act *Action) {
 	if chord == "" {
 		return
 	}
+	chord = AppKeyCmds.Register(act.KeyCmdName(), act.Tooltip, chord)
+	act.Shortcut = chord
 	if w.Shortcuts == nil {
 		w.Shortcuts = make(Shortcuts, 100)
 	}
@@ -2118,6 +2403,15 @@ func (w *Window) KeyChordEventHiPri(e *key.ChordEvent) bool {
 	if e.IsProcessed() {
 		return false
 	}
+	if kg, ok := w.EventMgr.CurFocus().(KeyChordGrabber); !ok || !kg.GrabsKeyChords() {
+		e.Prefix = w.keySeq
+		w.keySeq = ""
+		if cs := e.Chord(); IsKeySeqPrefix(cs, w) { // wait for the rest of the sequence
+			w.keySeq = cs
+			e.SetProcessed()
+			return false
+		}
+	}
 	cs := e.Chord()
 	kf := KeyFun(cs)
 	cpop := w.CurPopup()
@@ -2181,6 +2475,15 @@ func (w *Window) KeyChordEventLowPri(e *key.ChordEvent) bool {
 	case KeyFunWinFocusNext:
 		e.SetProcessed()
 		AllWindows.FocusNext()
+	case KeyFunCmdPalette:
+		if _, isdlg := w.Viewport.This().(*Dialog); !isdlg {
+			e.SetProcessed()
+			CmdPalette(w)
+		}
+	case KeyFunUndo, KeyFunRedo: // not handled by the focus, e.g., a TextView
+		if TheViewIFace != nil && TheViewIFace.WindowUndo(w, kf == KeyFunRedo) {
+			e.SetProcessed()
+		}
 	}
 	switch cs { // some other random special codes, during dev..
 	case "Control+Alt+R":
@@ -2192,6 +2495,9 @@ func (w *Window) KeyChordEventLowPri(e *key.ChordEvent) bool {
 	case "Control+Alt+H":
 		w.BenchmarkReRender()
 		e.SetProcessed()
+	case "Control+Alt+T":
+		w.ToggleFrameProfileHUD()
+		e.SetProcessed()
 	}
 	// fmt.Printf("key chord: rune: %v Chord: %v\n", e.Rune, e.Chord())
 	return delPop
@@ -2285,6 +2591,35 @@ func (w *Window) StartDragNDrop(src ki.Ki, data mimedata.Mimes, sp *Sprite) {
 	w.RenderOverlays()
 }
 
+// FileDropperAt returns the deepest visible widget in the main viewport at
+// given window position that implements the FileDropper interface, or nil
+func (w *Window) FileDropperAt(pos image.Point) FileDropper {
+	var fd FileDropper
+	w.Viewport.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		_, ni := KiToNode2D(k)
+		if ni == nil || ni.IsInvisible() || !ni.PosInWinBBox(pos) {
+			return ki.Break
+		}
+		if kfd, ok := k.(FileDropper); ok {
+			fd = kfd
+		}
+		return ki.Continue
+	})
+	return fd
+}
+
+// DropFilesEvent handles the drop of files from the OS, calling DropFiles on
+// the FileDropper at the drop position, if any.
+func (w *Window) DropFilesEvent(e *dnd.Event) {
+	fd := w.FileDropperAt(e.Where)
+	if fd == nil {
+		return
+	}
+	if fd.DropFiles(e.Files, e.Where, e.Mod) {
+		e.SetProcessed()
+	}
+}
+
 // DNDMoveEvent handles drag-n-drop move events.
 func (w *Window) DNDMoveEvent(e *mouse.DragEvent) {
 	sp, ok := w.SpriteByName(DNDSpriteName)
@@ -253,6 +253,7 @@ func (wg *WindowGeomPrefs) RecordPref(win *Window) {
 	wgr := WindowGeom{DPI: win.LogicalDPI(), DPR: sc.DevicePixelRatio}
 	wgr.SetPos(win.OSWin.Position())
 	wgr.SetSize(wsz)
+	win.geom = wgr // also recorded for the session
 
 	wg.LockFile() // not going to change our behavior if we can't lock!
 	if wg.NeedToReload() {
@@ -0,0 +1,307 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"fmt"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+)
+
+// WizardErrColor is the color of the error shown for a step of a Wizard
+// whose values are not valid
+var WizardErrColor = "#F44336"
+
+// WizardStep is one step of a Wizard, with its own widgets, which are made
+// once when the wizard is opened, so they keep their values when the user
+// goes back and forth between the steps
+type WizardStep struct {
+	Name     string                        `desc:"name of the step, which is the key of its value in the Values of the wizard"`
+	Title    string                        `desc:"title of the step, shown above its widgets"`
+	Prompt   string                        `desc:"optional more detailed description of the step, shown below the title"`
+	Config   func(wz *Wizard, lay *Layout) `json:"-" xml:"-" desc:"adds the widgets of the step to its (vertical) layout"`
+	Validate func(wz *Wizard) error        `json:"-" xml:"-" desc:"optional function returning an error if the values of the step are not valid, which is shown below the widgets, and keeps the user from going on to the next step"`
+	Value    func(wz *Wizard) interface{}  `json:"-" xml:"-" desc:"optional function returning the value collected by the step, for the Values of the wizard"`
+	Lay      *Layout                       `json:"-" xml:"-" desc:"the layout of the widgets of the step, made when the wizard is opened"`
+}
+
+// Wizard is a modal dialog that takes the user through an ordered sequence
+// of steps, e.g., for setting up an app: Next (or Finish on the last step)
+// is only active while the values of the current step are valid, and Back
+// returns to the previous step, with its values as they were left.  When
+// the user finishes, the DialogSig of the dialog is sent DialogAccepted,
+// with the Values collected by the steps as data (a
+// map[string]interface{} by step name), and DialogCanceled if canceled.
+// The widgets of the steps should call UpdateValid when their values
+// change, so Next is updated.
+type Wizard struct {
+	Opts  DlgOpts       `desc:"options for the dialog -- the title and prompt are shown above all the steps"`
+	Steps []*WizardStep `desc:"the steps, in order"`
+	Cur   int           `desc:"index of the current step"`
+	Dlg   *Dialog       `json:"-" xml:"-" desc:"the dialog, when open"`
+	Err   error         `json:"-" xml:"-" desc:"the error of the current step, from its Validate function, if not valid"`
+	vals  map[string]interface{}
+}
+
+// NewWizard returns a new wizard with given dialog options and steps --
+// more steps can be added with AddStep before it is opened
+func NewWizard(opts DlgOpts, steps ...*WizardStep) *Wizard {
+	return &Wizard{Opts: opts, Steps: steps}
+}
+
+// AddStep adds a step with given name, title, prompt and function that
+// adds its widgets to its layout, returning it so its Validate and Value
+// functions can be set
+func (wz *Wizard) AddStep(name, title, prompt string, config func(wz *Wizard, lay *Layout)) *WizardStep {
+	st := &WizardStep{Name: name, Title: title, Prompt: prompt, Config: config}
+	wz.Steps = append(wz.Steps, st)
+	return st
+}
+
+// Step returns the step of given name, or nil if there is none
+func (wz *Wizard) Step(name string) *WizardStep {
+	for _, st := range wz.Steps {
+		if st.Name == name {
+			return st
+		}
+	}
+	return nil
+}
+
+// CurStep returns the current step, or nil if there are no steps
+func (wz *Wizard) CurStep() *WizardStep {
+	if wz.Cur < 0 || wz.Cur >= len(wz.Steps) {
+		return nil
+	}
+	return wz.Steps[wz.Cur]
+}
+
+// IsLast returns true if the current step is the last one
+func (wz *Wizard) IsLast() bool {
+	return wz.Cur >= len(wz.Steps)-1
+}
+
+// Values returns the values collected by the steps, by step name, for the
+// steps that have a Value function -- these are sent as the data of the
+// DialogAccepted signal when the wizard is finished
+func (wz *Wizard) Values() map[string]interface{} {
+	if wz.vals != nil {
+		return wz.vals
+	}
+	vals := make(map[string]interface{}, len(wz.Steps))
+	for _, st := range wz.Steps {
+		if st.Value != nil {
+			vals[st.Name] = st.Value(wz)
+		}
+	}
+	return vals
+}
+
+// Open opens the wizard dialog at its first step, in the window of given
+// viewport (optional, as for other dialogs), optionally connecting to given
+// signal receiving object and function for the dialog signals (nil to
+// ignore)
+func (wz *Wizard) Open(avp *Viewport2D, recv ki.Ki, fun ki.RecvFunc) *Dialog {
+	if len(wz.Steps) == 0 {
+		return nil
+	}
+	dlg := NewStdDialog(wz.Opts, NoOk, NoCancel)
+	dlg.Modal = true
+	wz.Dlg = dlg
+	wz.Cur = 0
+	wz.vals = nil
+
+	frame := dlg.Frame()
+	stl := AddNewLabel(frame, "step-title", "")
+	stl.SetProp("font-size", "large")
+	stl.SetProp("font-weight", gist.WeightBold)
+	spl := AddNewLabel(frame, "step-prompt", "")
+	spl.SetProp("white-space", gist.WhiteSpaceNormal)
+	spl.SetProp("max-width", -1)
+	spl.SetProp("width", units.NewCh(30))
+
+	steps := AddNewLayout(frame, "steps", LayoutStacked)
+	steps.StackTopOnly = true
+	steps.SetStretchMax()
+	for i, st := range wz.Steps {
+		st.Lay = AddNewLayout(steps, fmt.Sprintf("step-%d", i), LayoutVert)
+		st.Lay.SetStretchMax()
+		if st.Config != nil {
+			st.Config(wz, st.Lay)
+		}
+	}
+
+	erl := AddNewLabel(frame, "step-err", "")
+	erl.SetProp("color", WizardErrColor)
+	erl.SetProp("white-space", gist.WhiteSpaceNormal)
+	erl.SetProp("max-width", -1)
+
+	bb := dlg.AddButtonBox(frame)
+	canb := AddNewButton(bb, "cancel")
+	canb.SetText(T("Cancel"))
+	canb.ButtonSig.Connect(dlg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(ButtonClicked) {
+			dlg.Cancel()
+		}
+	})
+	AddNewStretch(bb, "stretch")
+	backb := AddNewButton(bb, "back")
+	backb.SetText(T("Back"))
+	backb.ButtonSig.Connect(dlg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(ButtonClicked) {
+			wz.Back()
+		}
+	})
+	AddNewSpace(bb, "space")
+	nextb := AddNewButton(bb, "next")
+	nextb.ButtonSig.Connect(dlg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(ButtonClicked) {
+			wz.Next()
+		}
+	})
+
+	if recv != nil && fun != nil {
+		dlg.DialogSig.Connect(recv, fun)
+	}
+	wz.configStep()
+	dlg.UpdateEndNoSig(true)
+	dlg.Open(0, 0, avp, func() {
+		// accept goes on to the next step, instead of accepting the dialog
+		dlg.Win.EventMgr.ConnectEvent(dlg.This(), oswin.KeyChordEvent, HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+			kt := d.(*key.ChordEvent)
+			if KeyFun(kt.Chord()) == KeyFunAccept {
+				kt.SetProcessed()
+				wz.Next()
+			}
+		})
+	})
+	return dlg
+}
+
+// Next goes on to the next step, or finishes the wizard on the last step,
+// if the values of the current step are valid -- returns false if not
+func (wz *Wizard) Next() bool {
+	wz.editDone()
+	if !wz.UpdateValid() {
+		return false
+	}
+	if wz.IsLast() {
+		wz.Finish()
+		return true
+	}
+	wz.SetStep(wz.Cur + 1)
+	return true
+}
+
+// Back goes back to the previous step, returning false if on the first one
+// -- the values of the current step are kept, even if not valid
+func (wz *Wizard) Back() bool {
+	if wz.Cur <= 0 {
+		return false
+	}
+	wz.editDone()
+	wz.SetStep(wz.Cur - 1)
+	return true
+}
+
+// SetStep shows the step at given index
+func (wz *Wizard) SetStep(idx int) {
+	if idx < 0 || idx >= len(wz.Steps) || wz.Dlg == nil {
+		return
+	}
+	updt := wz.Dlg.UpdateStart()
+	wz.Cur = idx
+	wz.configStep()
+	wz.Dlg.SetFullReRender()
+	wz.Dlg.UpdateEnd(updt)
+}
+
+// Finish accepts the dialog, sending its DialogSig DialogAccepted with the
+// Values of the steps as data -- called by Next on the last step
+func (wz *Wizard) Finish() {
+	dlg := wz.Dlg
+	if dlg == nil {
+		return
+	}
+	wz.vals = wz.Values() // fixed once finished
+	dlg.State = DialogAccepted
+	dlg.DialogSig.Emit(dlg.This(), int64(DialogAccepted), wz.vals)
+	dlg.Close()
+}
+
+// UpdateValid validates the values of the current step, by its Validate
+// function, showing the error if they are not valid, and making Next
+// active only if they are -- returns true if valid.  It should be called
+// by the widgets of the step when their values change.
+func (wz *Wizard) UpdateValid() bool {
+	st := wz.CurStep()
+	if st == nil {
+		return false
+	}
+	wz.Err = nil
+	if st.Validate != nil {
+		wz.Err = st.Validate(wz)
+	}
+	if wz.Dlg == nil {
+		return wz.Err == nil
+	}
+	frame := wz.Dlg.Frame()
+	if erl, ok := frame.ChildByName("step-err", 0).(*Label); ok {
+		msg := ""
+		if wz.Err != nil {
+			msg = wz.Err.Error()
+		}
+		if erl.Text != msg {
+			erl.SetText(msg)
+		}
+	}
+	if bb, _ := wz.Dlg.ButtonBox(frame); bb != nil {
+		if nextb, ok := bb.ChildByName("next", 0).(*Button); ok {
+			nextb.SetActiveStateUpdt(wz.Err == nil)
+		}
+	}
+	return wz.Err == nil
+}
+
+// configStep configures the dialog for the current step
+func (wz *Wizard) configStep() {
+	st := wz.CurStep()
+	frame := wz.Dlg.Frame()
+	title := T(st.Title)
+	if len(wz.Steps) > 1 {
+		title = fmt.Sprintf(T("Step %d of %d: %s"), wz.Cur+1, len(wz.Steps), title)
+	}
+	frame.ChildByName("step-title", 0).(*Label).SetText(title)
+	frame.ChildByName("step-prompt", 0).(*Label).SetText(T(st.Prompt))
+	frame.ChildByName("steps", 0).(*Layout).StackTop = wz.Cur
+	bb, _ := wz.Dlg.ButtonBox(frame)
+	bb.ChildByName("back", 0).(*Button).SetActiveState(wz.Cur > 0)
+	nextb := bb.ChildByName("next", 0).(*Button)
+	if wz.IsLast() {
+		nextb.SetText(T("Finish"))
+	} else {
+		nextb.SetText(T("Next"))
+	}
+	wz.UpdateValid()
+}
+
+// editDone completes any editing of the text fields of the current step,
+// so their values are set before it is validated
+func (wz *Wizard) editDone() {
+	st := wz.CurStep()
+	if st == nil || st.Lay == nil {
+		return
+	}
+	st.Lay.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		if tf, ok := k.Embed(KiT_TextField).(*TextField); ok && tf.Edited {
+			tf.EditDone()
+		}
+		return ki.Continue
+	})
+}
@@ -150,6 +150,35 @@ func (cm *Camera) Orbit(delX, delY float32) {
 	cm.LookAtTarget()
 }
 
+// ArcBall rotates the camera around the Target freely in the direction
+// of the given 2D deltas in degrees (delX = left/right, delY = up/down),
+// as if rolling a ball centered on the target: the rotation axis is
+// perpendicular to the direction of movement within the current view plane,
+// and the Up direction rotates along with the camera.
+// Unlike Orbit, this allows arbitrary diagonal rotations.
+func (cm *Camera) ArcBall(delX, delY float32) {
+	ang := mat32.Sqrt(delX*delX + delY*delY)
+	if ang == 0 {
+		return
+	}
+	ctdir := cm.ViewVector()
+	if ctdir.IsNil() {
+		ctdir.Set(0, 0, 1)
+	}
+	dir := ctdir.Normal()
+
+	cm.CamMu.Lock()
+	right := cm.UpDir.Cross(dir).Normal()
+	up := dir.Cross(right).Normal()
+	axis := up.MulScalar(delX).Add(right.MulScalar(delY)).Normal()
+	q := mat32.NewQuatAxisAngle(axis, mat32.DegToRad(ang))
+	cm.Pose.Pos = cm.Target.Add(ctdir.MulQuat(q))
+	cm.UpDir = up.MulQuat(q)
+	cm.CamMu.Unlock()
+
+	cm.LookAtTarget()
+}
+
 // Pan moves the camera along the given 2D axes (left/right, up/down),
 // relative to current position and orientation (i.e., in the plane of the
 // current window view)
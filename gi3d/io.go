@@ -49,6 +49,9 @@ type Decoder interface {
 
 // Decoders is the master list of decoders, indexed by the primary extension.
 // .obj = Wavefront object file -- only has mesh data, not scene info.
+// .gltf, .glb = glTF 2.0 file -- the node hierarchy is imported as Groups.
+// Decoders are registered by importing their packages, e.g., gi3d/io/obj
+// and gi3d/io/gltf, as is done in gimain.
 var Decoders = map[string]Decoder{}
 
 // DecodeFile decodes the given file using a decoder based on the file
@@ -56,6 +59,8 @@ var Decoders = map[string]Decoder{}
 // Supported formats include:
 // .obj = Wavefront OBJ format, including associated materials (.mtl) which
 //        must have same name as .obj, or a default material is used.
+// .gltf, .glb = glTF 2.0 format, with meshes, materials, textures and the
+//        node hierarchy.
 func DecodeFile(fname string) (Decoder, error) {
 	ext := filepath.Ext(fname)
 	dt, has := Decoders[ext]
@@ -96,6 +101,8 @@ func DecodeFile(fname string) (Decoder, error) {
 // Supported formats include:
 // .obj = Wavefront OBJ format, including associated materials (.mtl) which
 //        must have same name as .obj, or a default material is used.
+// .gltf, .glb = glTF 2.0 format, with meshes, materials, textures and the
+//        node hierarchy.
 func (sc *Scene) OpenObj(fname string, gp *Group) error {
 	dec, err := DecodeFile(fname)
 	if err != nil {
@@ -113,6 +120,8 @@ func (sc *Scene) OpenObj(fname string, gp *Group) error {
 // Supported formats include:
 // .obj = Wavefront OBJ format, including associated materials (.mtl) which
 //        must have same name as .obj, or a default material is used.
+// .gltf, .glb = glTF 2.0 format, with meshes, materials, textures and the
+//        node hierarchy.
 func (sc *Scene) OpenNewObj(fname string, parent ki.Ki) (*Group, error) {
 	dec, err := DecodeFile(fname)
 	if err != nil {
@@ -134,6 +143,8 @@ func (sc *Scene) OpenNewObj(fname string, parent ki.Ki) (*Group, error) {
 // Supported formats include:
 // .obj = Wavefront OBJ format, including associated materials (.mtl) which
 //        must have same name as .obj, or a default material is used.
+// .gltf, .glb = glTF 2.0 format, with meshes, materials, textures and the
+//        node hierarchy.
 func (sc *Scene) OpenToLibrary(fname string, libnm string) (*Group, error) {
 	dec, err := DecodeFile(fname)
 	if err != nil {
@@ -154,6 +165,8 @@ func (sc *Scene) OpenToLibrary(fname string, libnm string) (*Group, error) {
 // Supported formats include:
 // .obj = Wavefront OBJ format, including associated materials (.mtl) which
 //        must have same name as .obj, or a default material is used.
+// .gltf, .glb = glTF 2.0 format, with meshes, materials, textures and the
+//        node hierarchy.
 //        Does not support full scene data so only objects are loaded
 //        into a new group in scene.
 func (sc *Scene) OpenScene(fname string) error {
@@ -202,6 +215,8 @@ func (sc *Scene) ReadObj(fname string, rs []io.Reader, gp *Group) error {
 // Supported formats include:
 // .obj = Wavefront OBJ format, including associated materials (.mtl) which
 //        must have same name as .obj, or a default material is used.
+// .gltf, .glb = glTF 2.0 format, with meshes, materials, textures and the
+//        node hierarchy.
 //        Does not support full scene data so only objects are loaded
 //        into a new group in scene.
 func (sc *Scene) ReadScene(fname string, rs []io.Reader, gp *Group) error {
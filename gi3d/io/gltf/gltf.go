@@ -0,0 +1,687 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package gltf is used to parse the glTF 2.0 file format, either as a
+// .gltf JSON file with separate or embedded (data URI) buffers and images,
+// or as a single binary .glb file.  Meshes (triangles only), materials
+// (metallic-roughness approximated by the gi3d Phong material), base color
+// textures and the node hierarchy are imported.  Animations, skins, morph
+// targets, cameras and sparse accessors are not supported.
+// Format spec: https://github.com/KhronosGroup/glTF/tree/master/specification/2.0
+package gltf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/goki/gi/gi3d"
+	"github.com/goki/ki/ki"
+	"github.com/goki/mat32"
+)
+
+// note: gimain imports "github.com/goki/gi/gi3d/io/gltf" to get this code
+func init() {
+	gi3d.Decoders[".gltf"] = &Decoder{}
+	gi3d.Decoders[".glb"] = &Decoder{}
+}
+
+// Decoder contains all decoded data from a glTF file.
+// It also implements the gi3d.Decoder interface and an instance
+// is registered to handle .gltf and .glb files.
+type Decoder struct {
+	File     string               // .gltf or .glb filename (without path)
+	Dir      string               // path to file, for loading external buffers and images
+	Doc      *GLTF                // decoded JSON document
+	Buffers  [][]byte             // loaded data for each buffer
+	Warnings []string             // warning messages
+	bin      []byte               // binary chunk of a .glb file
+	meshes   map[int][]string     // names of the gi3d meshes for each primitive of each glTF mesh
+	textures map[int]gi3d.Texture // textures made for each glTF texture
+}
+
+func (dec *Decoder) New() gi3d.Decoder {
+	di := new(Decoder)
+	di.Warnings = make([]string, 0)
+	return di
+}
+
+// Destroy deletes data created during loading
+func (dec *Decoder) Destroy() {
+	dec.Doc = nil
+	dec.Buffers = nil
+	dec.bin = nil
+	dec.meshes = nil
+	dec.textures = nil
+}
+
+func (dec *Decoder) Desc() string {
+	return ".gltf, .glb = glTF 2.0 format, as JSON with separate or embedded buffers and images, or as a single binary file.  Imports meshes, materials, textures and the node hierarchy.  Only supports Object-level data, not full Scene (camera, lights etc)."
+}
+
+func (dec *Decoder) HasScene() bool {
+	return false
+}
+
+func (dec *Decoder) SetFile(fname string) []string {
+	dec.Dir, dec.File = filepath.Split(fname)
+	return []string{fname}
+}
+
+// Decode reads the given data and decodes the glTF document and its
+// buffers.  Only the first reader is used: external buffers are loaded
+// relative to the directory of the file.
+func (dec *Decoder) Decode(rs []io.Reader) error {
+	if len(rs) == 0 {
+		return errors.New("gltf.Decoder: no readers passed")
+	}
+	data, err := ioutil.ReadAll(rs[0])
+	if err != nil {
+		return err
+	}
+	if len(data) >= 4 && string(data[:4]) == glbMagic {
+		data, err = dec.parseGLB(data)
+		if err != nil {
+			return err
+		}
+	}
+	dec.Doc = &GLTF{}
+	err = json.Unmarshal(data, dec.Doc)
+	if err != nil {
+		return fmt.Errorf("gltf.Decoder: file: %v: %v", dec.File, err)
+	}
+	if !strings.HasPrefix(dec.Doc.Asset.Version, "2") {
+		return fmt.Errorf("gltf.Decoder: file: %v has version: %v -- only glTF 2.0 is supported", dec.File, dec.Doc.Asset.Version)
+	}
+	return dec.loadBuffers()
+}
+
+// SetScene sets group with with all the decoded objects.
+func (dec *Decoder) SetScene(sc *gi3d.Scene) {
+	gp := gi3d.AddNewGroup(sc, sc, dec.File)
+	dec.SetGroup(sc, gp)
+}
+
+// SetGroup sets group with all the nodes of the default scene in the file,
+// or all the top-level nodes if there is no scene.
+// calls Destroy after to free memory
+func (dec *Decoder) SetGroup(sc *gi3d.Scene, gp *gi3d.Group) {
+	doc := dec.Doc
+	dec.meshes = make(map[int][]string)
+	dec.textures = make(map[int]gi3d.Texture)
+	var roots []int
+	switch {
+	case doc.Scene != nil && *doc.Scene >= 0 && *doc.Scene < len(doc.Scenes):
+		roots = doc.Scenes[*doc.Scene].Nodes
+	case len(doc.Scenes) > 0:
+		roots = doc.Scenes[0].Nodes
+	default:
+		ischild := make([]bool, len(doc.Nodes))
+		for ni := range doc.Nodes {
+			for _, ci := range doc.Nodes[ni].Children {
+				if ci >= 0 && ci < len(ischild) {
+					ischild[ci] = true
+				}
+			}
+		}
+		for ni := range doc.Nodes {
+			if !ischild[ni] {
+				roots = append(roots, ni)
+			}
+		}
+	}
+	for _, ni := range roots {
+		dec.SetNode(sc, gp, ni, 0)
+	}
+	dec.Destroy()
+}
+
+// SetNode adds a group for given node to parent, with the node's transform,
+// its mesh (if any) as Solids, and all of its children, recursively.
+func (dec *Decoder) SetNode(sc *gi3d.Scene, par ki.Ki, ni int, depth int) {
+	doc := dec.Doc
+	if ni < 0 || ni >= len(doc.Nodes) {
+		dec.appendWarn(fmt.Sprintf("node index: %d out of range", ni))
+		return
+	}
+	if depth > len(doc.Nodes) {
+		dec.appendWarn(fmt.Sprintf("node: %d is part of a cycle in the node hierarchy", ni))
+		return
+	}
+	nd := &doc.Nodes[ni]
+	nm := nd.Name
+	if nm == "" {
+		nm = fmt.Sprintf("node_%d", ni)
+	}
+	ngp := gi3d.AddNewGroup(sc, par, nm)
+	if len(nd.Matrix) == 16 {
+		var m mat32.Mat4
+		m.FromArray(nd.Matrix, 0)
+		ngp.Pose.SetMatrix(&m)
+	} else {
+		if len(nd.Translation) == 3 {
+			ngp.Pose.Pos.Set(nd.Translation[0], nd.Translation[1], nd.Translation[2])
+		}
+		if len(nd.Rotation) == 4 {
+			ngp.Pose.Quat.Set(nd.Rotation[0], nd.Rotation[1], nd.Rotation[2], nd.Rotation[3])
+		}
+		if len(nd.Scale) == 3 {
+			ngp.Pose.Scale.Set(nd.Scale[0], nd.Scale[1], nd.Scale[2])
+		}
+	}
+	if nd.Mesh != nil {
+		dec.SetMesh(sc, ngp, *nd.Mesh)
+	}
+	for _, ci := range nd.Children {
+		dec.SetNode(sc, ngp, ci, depth+1)
+	}
+}
+
+// SetMesh adds a Solid to the group for each primitive of given mesh --
+// the gi3d meshes are only made once, and shared among all nodes using the mesh.
+func (dec *Decoder) SetMesh(sc *gi3d.Scene, gp *gi3d.Group, mi int) {
+	doc := dec.Doc
+	if mi < 0 || mi >= len(doc.Meshes) {
+		dec.appendWarn(fmt.Sprintf("mesh index: %d out of range", mi))
+		return
+	}
+	msh := &doc.Meshes[mi]
+	mnms, has := dec.meshes[mi]
+	if !has {
+		mnms = make([]string, len(msh.Primitives))
+		for pi := range msh.Primitives {
+			nm := msh.Name
+			if nm == "" {
+				nm = fmt.Sprintf("%s_mesh_%d", strings.TrimSuffix(dec.File, filepath.Ext(dec.File)), mi)
+			}
+			if len(msh.Primitives) > 1 {
+				nm += fmt.Sprintf("_%d", pi)
+			}
+			ms, err := dec.MakeMesh(&msh.Primitives[pi], nm)
+			if err != nil {
+				dec.appendWarn(fmt.Sprintf("mesh: %v: %v", nm, err))
+				continue
+			}
+			sc.AddMeshUnique(ms)
+			mnms[pi] = ms.Name()
+		}
+		dec.meshes[mi] = mnms
+	}
+	for pi := range msh.Primitives {
+		if mnms[pi] == "" {
+			continue
+		}
+		sld := gi3d.AddNewSolid(sc, gp, mnms[pi], mnms[pi])
+		hasTex := sld.MeshPtr != nil && sld.MeshPtr.AsMeshBase().HasTex()
+		dec.SetMat(sc, sld, msh.Primitives[pi].Material, hasTex)
+	}
+}
+
+// MakeMesh makes a new mesh from given primitive, with given name
+func (dec *Decoder) MakeMesh(prim *Primitive, nm string) (*gi3d.GenMesh, error) {
+	if prim.Mode != nil && *prim.Mode != modeTriangles {
+		return nil, fmt.Errorf("primitive mode: %d not supported -- only triangles", *prim.Mode)
+	}
+	pai, has := prim.Attributes["POSITION"]
+	if !has {
+		return nil, errors.New("primitive has no POSITION attribute")
+	}
+	pos, nc, err := dec.readAccessor(pai)
+	if err != nil {
+		return nil, err
+	}
+	if nc != 3 {
+		return nil, errors.New("POSITION attribute is not VEC3")
+	}
+	nv := len(pos) / 3
+	ms := &gi3d.GenMesh{}
+	ms.Nm = nm
+	ms.Vtx = toArrayF32(pos)
+	if nai, has := prim.Attributes["NORMAL"]; has {
+		nrm, nc, err := dec.readAccessor(nai)
+		if err == nil && nc == 3 && len(nrm) == len(pos) {
+			ms.Norm = toArrayF32(nrm)
+		} else {
+			dec.appendWarn(fmt.Sprintf("mesh: %v: invalid NORMAL attribute -- computing normals", nm))
+		}
+	}
+	if tai, has := prim.Attributes["TEXCOORD_0"]; has {
+		tex, nc, err := dec.readAccessor(tai)
+		if err == nil && nc == 2 && len(tex) == nv*2 {
+			ms.Tex = toArrayF32(tex)
+		} else {
+			dec.appendWarn(fmt.Sprintf("mesh: %v: invalid TEXCOORD_0 attribute", nm))
+		}
+	}
+	if cai, has := prim.Attributes["COLOR_0"]; has {
+		clr, nc, err := dec.readAccessor(cai)
+		if err == nil && (nc == 3 || nc == 4) && len(clr) == nv*nc {
+			ms.Color = mat32.NewArrayF32(nv*4, nv*4)
+			for vi := 0; vi < nv; vi++ {
+				for c := 0; c < 4; c++ {
+					v := float32(1)
+					if c < nc {
+						v = float32(clr[vi*nc+c])
+					}
+					ms.Color[vi*4+c] = v
+				}
+				if ms.Color[vi*4+3] < 1 {
+					ms.Trans = true
+				}
+			}
+		} else {
+			dec.appendWarn(fmt.Sprintf("mesh: %v: invalid COLOR_0 attribute", nm))
+		}
+	}
+	if prim.Indices != nil {
+		idx, nc, err := dec.readAccessor(*prim.Indices)
+		if err != nil {
+			return nil, err
+		}
+		if nc != 1 {
+			return nil, errors.New("indices accessor is not SCALAR")
+		}
+		ms.Idx = mat32.NewArrayU32(len(idx), len(idx))
+		for i, v := range idx {
+			if v < 0 || int(v) >= nv {
+				return nil, fmt.Errorf("index: %v out of range of %d vertices", v, nv)
+			}
+			ms.Idx[i] = uint32(v)
+		}
+	} else {
+		ms.Idx = mat32.NewArrayU32(nv, nv)
+		for i := range ms.Idx {
+			ms.Idx[i] = uint32(i)
+		}
+	}
+	if n := len(ms.Idx) % 3; n != 0 {
+		dec.appendWarn(fmt.Sprintf("mesh: %v: number of indexes is not a multiple of 3", nm))
+		ms.Idx = ms.Idx[:len(ms.Idx)-n]
+	}
+	if len(ms.Norm) == 0 {
+		computeNorms(ms)
+	}
+	return ms, nil
+}
+
+// computeNorms computes smooth vertex normals as the average of the normals
+// of all the triangles sharing each vertex.
+func computeNorms(ms *gi3d.GenMesh) {
+	nv := len(ms.Vtx) / 3
+	ms.Norm = mat32.NewArrayF32(nv*3, nv*3)
+	var a, b, c mat32.Vec3
+	for i := 0; i+2 < len(ms.Idx); i += 3 {
+		ai, bi, ci := int(ms.Idx[i]), int(ms.Idx[i+1]), int(ms.Idx[i+2])
+		ms.Vtx.GetVec3(3*ai, &a)
+		ms.Vtx.GetVec3(3*bi, &b)
+		ms.Vtx.GetVec3(3*ci, &c)
+		nrm := mat32.Normal(a, b, c)
+		for _, vi := range []int{ai, bi, ci} {
+			ms.Norm[3*vi] += nrm.X
+			ms.Norm[3*vi+1] += nrm.Y
+			ms.Norm[3*vi+2] += nrm.Z
+		}
+	}
+	var nrm mat32.Vec3
+	for vi := 0; vi < nv; vi++ {
+		ms.Norm.GetVec3(3*vi, &nrm)
+		ms.Norm.SetVec3(3*vi, nrm.Normal())
+	}
+}
+
+// SetMat sets the material for solid from given material index, which may be nil
+// for the default material.  The metallic-roughness model is approximated using
+// the specular color and shininess: smooth surfaces are shinier, and metallic
+// ones have their specular color tinted by the base color.
+func (dec *Decoder) SetMat(sc *gi3d.Scene, sld *gi3d.Solid, mi *int, hasTex bool) {
+	doc := dec.Doc
+	sld.Mat.Defaults()
+	if mi == nil {
+		return
+	}
+	if *mi < 0 || *mi >= len(doc.Materials) {
+		dec.appendWarn(fmt.Sprintf("material index: %d out of range for solid: %s -- using default material", *mi, sld.Name()))
+		return
+	}
+	mat := &doc.Materials[*mi]
+	base := []float32{1, 1, 1, 1}
+	metal := float32(1)
+	rough := float32(1)
+	if pbr := mat.PBRMetallicRoughness; pbr != nil {
+		if len(pbr.BaseColorFactor) == 4 {
+			copy(base, pbr.BaseColorFactor)
+		}
+		if pbr.MetallicFactor != nil {
+			metal = *pbr.MetallicFactor
+		}
+		if pbr.RoughnessFactor != nil {
+			rough = *pbr.RoughnessFactor
+		}
+		if pbr.BaseColorTexture != nil && hasTex {
+			tex := dec.Texture(sc, pbr.BaseColorTexture.Index)
+			if tex != nil {
+				if mat.AlphaMode == "BLEND" {
+					tex.SetTransparent(true)
+				}
+				sld.Mat.SetTexture(sc, tex)
+			}
+		}
+	}
+	if mat.AlphaMode != "BLEND" { // MASK is rendered as opaque
+		base[3] = 1
+	}
+	sld.Mat.Color.SetFloat32(base[0], base[1], base[2], base[3])
+	smooth := 1 - rough
+	var spec [3]float32
+	for c := range spec {
+		spec[c] = smooth * (1 + metal*(base[c]-1))
+	}
+	sld.Mat.Specular.SetFloat32(spec[0], spec[1], spec[2], 1)
+	sld.Mat.Shiny = 1 + 127*smooth*smooth
+	if len(mat.EmissiveFactor) == 3 {
+		sld.Mat.Emissive.SetFloat32(mat.EmissiveFactor[0], mat.EmissiveFactor[1], mat.EmissiveFactor[2], 1)
+	}
+	sld.Mat.CullBack = !mat.DoubleSided
+}
+
+// Texture returns the texture for given texture index, making it the first
+// time it is used -- returns nil if it cannot be made.
+func (dec *Decoder) Texture(sc *gi3d.Scene, ti int) gi3d.Texture {
+	if tex, has := dec.textures[ti]; has {
+		return tex
+	}
+	tex, err := dec.makeTex(sc, ti)
+	if err != nil {
+		dec.appendWarn(fmt.Sprintf("texture: %d: %v", ti, err))
+	}
+	dec.textures[ti] = tex
+	return tex
+}
+
+// makeTex makes the texture for given texture index: image files are loaded
+// by name as with other texture files, and embedded images are decoded.
+func (dec *Decoder) makeTex(sc *gi3d.Scene, ti int) (gi3d.Texture, error) {
+	doc := dec.Doc
+	if ti < 0 || ti >= len(doc.Textures) {
+		return nil, errors.New("texture index out of range")
+	}
+	src := doc.Textures[ti].Source
+	if src == nil || *src < 0 || *src >= len(doc.Images) {
+		return nil, errors.New("texture has no valid image source")
+	}
+	img := &doc.Images[*src]
+	var data []byte
+	switch {
+	case img.BufferView != nil:
+		bv, err := dec.bufferView(*img.BufferView)
+		if err != nil {
+			return nil, err
+		}
+		data = bv
+	case strings.HasPrefix(img.URI, "data:"):
+		d, err := decodeDataURI(img.URI)
+		if err != nil {
+			return nil, err
+		}
+		data = d
+	case img.URI != "":
+		fn, err := url.PathUnescape(img.URI)
+		if err != nil {
+			fn = img.URI
+		}
+		texPath := filepath.Join(dec.Dir, fn)
+		_, tfn := filepath.Split(texPath)
+		tf, err := sc.TextureByNameTry(tfn)
+		if err != nil {
+			tf = gi3d.AddNewTextureFile(sc, tfn, texPath)
+		}
+		return tf, nil
+	default:
+		return nil, errors.New("image has no data")
+	}
+	im, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	nm := img.Name
+	if nm == "" {
+		nm = fmt.Sprintf("image_%d", *src)
+	}
+	nm = strings.TrimSuffix(dec.File, filepath.Ext(dec.File)) + "_" + nm
+	return gi3d.AddNewTextureImage(sc, nm, im), nil
+}
+
+/////////////////////////////////////////////////////////////////////
+//  Buffers
+
+const (
+	glbMagic     = "glTF"
+	glbChunkJSON = 0x4E4F534A
+	glbChunkBIN  = 0x004E4942
+)
+
+// parseGLB parses the binary .glb container, returning the JSON chunk
+// and saving the binary chunk, if present, for use as the first buffer.
+func (dec *Decoder) parseGLB(data []byte) ([]byte, error) {
+	if len(data) < 20 {
+		return nil, fmt.Errorf("gltf.Decoder: file: %v is too short for a .glb file", dec.File)
+	}
+	le := binary.LittleEndian
+	if ver := le.Uint32(data[4:]); ver != 2 {
+		return nil, fmt.Errorf("gltf.Decoder: file: %v has .glb version: %d -- only version 2 is supported", dec.File, ver)
+	}
+	if ln := int(le.Uint32(data[8:])); ln < len(data) {
+		data = data[:ln]
+	}
+	var js []byte
+	for off := 12; off+8 <= len(data); {
+		cln := int(le.Uint32(data[off:]))
+		ctyp := le.Uint32(data[off+4:])
+		off += 8
+		if off+cln > len(data) {
+			return nil, fmt.Errorf("gltf.Decoder: file: %v has a truncated chunk", dec.File)
+		}
+		switch ctyp {
+		case glbChunkJSON:
+			if js == nil {
+				js = data[off : off+cln]
+			}
+		case glbChunkBIN:
+			if dec.bin == nil {
+				dec.bin = data[off : off+cln]
+			}
+		}
+		off += cln
+	}
+	if js == nil {
+		return nil, fmt.Errorf("gltf.Decoder: file: %v has no JSON chunk", dec.File)
+	}
+	return js, nil
+}
+
+// loadBuffers loads the data for all the buffers, from the .glb binary
+// chunk, embedded data URIs, or external files.
+func (dec *Decoder) loadBuffers() error {
+	doc := dec.Doc
+	dec.Buffers = make([][]byte, len(doc.Buffers))
+	for bi := range doc.Buffers {
+		buf := &doc.Buffers[bi]
+		var data []byte
+		var err error
+		switch {
+		case buf.URI == "":
+			if dec.bin == nil {
+				return fmt.Errorf("gltf.Decoder: buffer: %d has no uri and there is no binary chunk", bi)
+			}
+			data = dec.bin
+		case strings.HasPrefix(buf.URI, "data:"):
+			data, err = decodeDataURI(buf.URI)
+		default:
+			fn, uerr := url.PathUnescape(buf.URI)
+			if uerr != nil {
+				fn = buf.URI
+			}
+			data, err = ioutil.ReadFile(filepath.Join(dec.Dir, fn))
+		}
+		if err != nil {
+			return fmt.Errorf("gltf.Decoder: buffer: %d: %v", bi, err)
+		}
+		if len(data) < buf.ByteLength {
+			return fmt.Errorf("gltf.Decoder: buffer: %d has %d bytes, less than its byteLength: %d", bi, len(data), buf.ByteLength)
+		}
+		dec.Buffers[bi] = data
+	}
+	return nil
+}
+
+// decodeDataURI returns the data from a data: URI, which is base64 encoded
+// if so indicated.
+func decodeDataURI(uri string) ([]byte, error) {
+	ci := strings.Index(uri, ",")
+	if ci < 0 {
+		return nil, errors.New("invalid data uri")
+	}
+	if strings.HasSuffix(uri[:ci], ";base64") {
+		return base64.StdEncoding.DecodeString(uri[ci+1:])
+	}
+	s, err := url.PathUnescape(uri[ci+1:])
+	return []byte(s), err
+}
+
+// bufferView returns the data for given buffer view
+func (dec *Decoder) bufferView(vi int) ([]byte, error) {
+	doc := dec.Doc
+	if vi < 0 || vi >= len(doc.BufferViews) {
+		return nil, fmt.Errorf("bufferView index: %d out of range", vi)
+	}
+	bv := &doc.BufferViews[vi]
+	if bv.Buffer < 0 || bv.Buffer >= len(dec.Buffers) {
+		return nil, fmt.Errorf("bufferView: %d buffer index: %d out of range", vi, bv.Buffer)
+	}
+	buf := dec.Buffers[bv.Buffer]
+	if bv.ByteOffset < 0 || bv.ByteLength < 0 || bv.ByteOffset+bv.ByteLength > len(buf) {
+		return nil, fmt.Errorf("bufferView: %d extends beyond the end of its buffer", vi)
+	}
+	return buf[bv.ByteOffset : bv.ByteOffset+bv.ByteLength], nil
+}
+
+// component types for accessors
+const (
+	compByte   = 5120
+	compUByte  = 5121
+	compShort  = 5122
+	compUShort = 5123
+	compUInt   = 5125
+	compFloat  = 5126
+)
+
+// modeTriangles is the only primitive mode supported
+const modeTriangles = 4
+
+// compSizes are the sizes in bytes of each component type
+var compSizes = map[int]int{compByte: 1, compUByte: 1, compShort: 2, compUShort: 2, compUInt: 4, compFloat: 4}
+
+// typeComps are the number of components for each accessor type
+var typeComps = map[string]int{"SCALAR": 1, "VEC2": 2, "VEC3": 3, "VEC4": 4, "MAT2": 4, "MAT3": 9, "MAT4": 16}
+
+// readAccessor returns the values of given accessor, with the number of
+// components per element.  Normalized integer values are converted into
+// the 0..1 (unsigned) or -1..1 (signed) range.
+func (dec *Decoder) readAccessor(ai int) ([]float64, int, error) {
+	doc := dec.Doc
+	if ai < 0 || ai >= len(doc.Accessors) {
+		return nil, 0, fmt.Errorf("accessor index: %d out of range", ai)
+	}
+	acc := &doc.Accessors[ai]
+	nc := typeComps[acc.Type]
+	cs := compSizes[acc.ComponentType]
+	if nc == 0 || cs == 0 || acc.Count < 0 {
+		return nil, 0, fmt.Errorf("accessor: %d has invalid type: %v or componentType: %d", ai, acc.Type, acc.ComponentType)
+	}
+	if acc.Sparse != nil {
+		dec.appendWarn(fmt.Sprintf("accessor: %d: sparse accessors are not supported", ai))
+	}
+	vals := make([]float64, acc.Count*nc)
+	if acc.BufferView == nil { // all zeros
+		return vals, nc, nil
+	}
+	data, err := dec.bufferView(*acc.BufferView)
+	if err != nil {
+		return nil, 0, err
+	}
+	stride := doc.BufferViews[*acc.BufferView].ByteStride
+	if stride == 0 {
+		stride = nc * cs
+	}
+	off := acc.ByteOffset
+	if acc.Count > 0 && (off < 0 || off+(acc.Count-1)*stride+nc*cs > len(data)) {
+		return nil, 0, fmt.Errorf("accessor: %d extends beyond the end of its bufferView", ai)
+	}
+	for i := 0; i < acc.Count; i++ {
+		eo := off + i*stride
+		for c := 0; c < nc; c++ {
+			vals[i*nc+c] = compValue(data[eo+c*cs:], acc.ComponentType, acc.Normalized)
+		}
+	}
+	return vals, nc, nil
+}
+
+// compValue returns the value of one component of given type at start of b
+func compValue(b []byte, ct int, norm bool) float64 {
+	le := binary.LittleEndian
+	switch ct {
+	case compByte:
+		v := float64(int8(b[0]))
+		if norm {
+			v = math.Max(v/127, -1)
+		}
+		return v
+	case compUByte:
+		v := float64(b[0])
+		if norm {
+			v /= 255
+		}
+		return v
+	case compShort:
+		v := float64(int16(le.Uint16(b)))
+		if norm {
+			v = math.Max(v/32767, -1)
+		}
+		return v
+	case compUShort:
+		v := float64(le.Uint16(b))
+		if norm {
+			v /= 65535
+		}
+		return v
+	case compUInt:
+		return float64(le.Uint32(b))
+	default:
+		return float64(math.Float32frombits(le.Uint32(b)))
+	}
+}
+
+func toArrayF32(vals []float64) mat32.ArrayF32 {
+	ar := mat32.NewArrayF32(len(vals), len(vals))
+	for i, v := range vals {
+		ar[i] = float32(v)
+	}
+	return ar
+}
+
+func (dec *Decoder) appendWarn(msg string) {
+	dec.Warnings = append(dec.Warnings, fmt.Sprintf("%s: %s", dec.File, msg))
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gltf
+
+import "encoding/json"
+
+// These types mirror the parts of the glTF 2.0 JSON document that are used
+// by the Decoder -- all indexes refer to the corresponding top-level arrays.
+
+// GLTF is the top-level glTF document
+type GLTF struct {
+	Asset       Asset        `json:"asset"`
+	Scene       *int         `json:"scene"`
+	Scenes      []Scene      `json:"scenes"`
+	Nodes       []Node       `json:"nodes"`
+	Meshes      []Mesh       `json:"meshes"`
+	Materials   []Material   `json:"materials"`
+	Textures    []Texture    `json:"textures"`
+	Images      []Image      `json:"images"`
+	Accessors   []Accessor   `json:"accessors"`
+	BufferViews []BufferView `json:"bufferViews"`
+	Buffers     []Buffer     `json:"buffers"`
+}
+
+// Asset is metadata about the file
+type Asset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator"`
+}
+
+// Scene is a set of root nodes
+type Scene struct {
+	Name  string `json:"name"`
+	Nodes []int  `json:"nodes"`
+}
+
+// Node is a node in the hierarchy, with a transform given either by Matrix
+// (column-major) or by Translation, Rotation (quaternion x,y,z,w) and Scale
+type Node struct {
+	Name        string    `json:"name"`
+	Children    []int     `json:"children"`
+	Mesh        *int      `json:"mesh"`
+	Matrix      []float32 `json:"matrix"`
+	Translation []float32 `json:"translation"`
+	Rotation    []float32 `json:"rotation"`
+	Scale       []float32 `json:"scale"`
+}
+
+// Mesh is a set of primitives to be rendered
+type Mesh struct {
+	Name       string      `json:"name"`
+	Primitives []Primitive `json:"primitives"`
+}
+
+// Primitive is geometry to be rendered with a given material -- Attributes
+// map names such as POSITION, NORMAL, TEXCOORD_0 to accessors
+type Primitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    *int           `json:"indices"`
+	Material   *int           `json:"material"`
+	Mode       *int           `json:"mode"`
+}
+
+// Material is a metallic-roughness material
+type Material struct {
+	Name                 string                `json:"name"`
+	PBRMetallicRoughness *PBRMetallicRoughness `json:"pbrMetallicRoughness"`
+	EmissiveFactor       []float32             `json:"emissiveFactor"`
+	AlphaMode            string                `json:"alphaMode"`
+	DoubleSided          bool                  `json:"doubleSided"`
+}
+
+// PBRMetallicRoughness are the parameters of the metallic-roughness material model
+type PBRMetallicRoughness struct {
+	BaseColorFactor  []float32    `json:"baseColorFactor"`
+	BaseColorTexture *TextureInfo `json:"baseColorTexture"`
+	MetallicFactor   *float32     `json:"metallicFactor"`
+	RoughnessFactor  *float32     `json:"roughnessFactor"`
+}
+
+// TextureInfo is a reference to a texture
+type TextureInfo struct {
+	Index    int `json:"index"`
+	TexCoord int `json:"texCoord"`
+}
+
+// Texture combines an image and a sampler
+type Texture struct {
+	Sampler *int `json:"sampler"`
+	Source  *int `json:"source"`
+}
+
+// Image is image data given by a URI (file or data:) or by a buffer view
+type Image struct {
+	Name       string `json:"name"`
+	URI        string `json:"uri"`
+	MimeType   string `json:"mimeType"`
+	BufferView *int   `json:"bufferView"`
+}
+
+// Accessor is a typed view into a buffer view
+type Accessor struct {
+	BufferView    *int            `json:"bufferView"`
+	ByteOffset    int             `json:"byteOffset"`
+	ComponentType int             `json:"componentType"`
+	Normalized    bool            `json:"normalized"`
+	Count         int             `json:"count"`
+	Type          string          `json:"type"`
+	Sparse        json.RawMessage `json:"sparse"`
+}
+
+// BufferView is a subset of a buffer
+type BufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	ByteStride int `json:"byteStride"`
+}
+
+// Buffer is binary data, given by a URI (file or data:), or the binary
+// chunk of a .glb file if there is no URI
+type Buffer struct {
+	URI        string `json:"uri"`
+	ByteLength int    `json:"byteLength"`
+}
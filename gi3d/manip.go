@@ -40,6 +40,27 @@ const (
 
 var KiT_SelModes = kit.Enums.AddEnum(SelModesN, kit.NotBitFlag, nil)
 
+// SceneSignals are signals that the Scene sends on its SceneSig
+// for picking events -- data is the Node3D concerned, or nil if none.
+type SceneSignals int64
+
+const (
+	// SceneHovered means that the solid under the mouse has changed,
+	// as determined by ray-cast picking -- data is nil when the mouse
+	// is no longer over any solid.
+	SceneHovered SceneSignals = iota
+
+	// SceneSelected means that the selected node (CurSel) has changed --
+	// data is nil when the selection has been cleared.
+	SceneSelected
+
+	SceneSignalsN
+)
+
+//go:generate stringer -type=SceneSignals
+
+var KiT_SceneSignals = kit.Enums.AddEnum(SceneSignalsN, kit.NotBitFlag, nil)
+
 // SelParams are parameters for selection / manipulation box
 type SelParams struct {
 	Color  gi.ColorName `desc:"name of color to use for selection box (default yellow)"`
@@ -75,18 +96,31 @@ func (sc *Scene) SetSel(nd Node3D) {
 		sc.DeleteChildByName(SelBoxName, ki.DestroyKids)
 		sc.DeleteChildByName(ManipBoxName, ki.DestroyKids)
 		sc.UpdateEnd(updt)
+		sc.SceneSig.Emit(sc.This(), int64(SceneSelected), nil)
 		return
 	}
+	if sc.CurSel != nil {
+		sc.CurSel.AsNode3D().ClearSelected()
+	}
 	sc.CurSel = nd
 	nd.AsNode3D().SetSelected()
 	switch sc.SelMode {
-	case Selectable:
-		return
 	case SelectionBox:
 		sc.SelectBox()
 	case Manipulable:
 		sc.ManipBox()
 	}
+	sc.SceneSig.Emit(sc.This(), int64(SceneSelected), nd)
+}
+
+// SetHover sets the CurHover node that the mouse is over, sending
+// the SceneHovered signal if it has changed -- nil = not over any node.
+func (sc *Scene) SetHover(nd Node3D) {
+	if sc.CurHover == nd {
+		return
+	}
+	sc.CurHover = nd
+	sc.SceneSig.Emit(sc.This(), int64(SceneHovered), nd)
 }
 
 // SelectBox draws a selection box around selected node
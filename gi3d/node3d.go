@@ -375,7 +375,9 @@ func (nb *Node3DBase) SetPoseQuat(quat mat32.Quat) {
 /////////////////////////////////////////////////////////////////
 // Events
 
-// Default node can be selected / manipulated per the Scene SelMode settings
+// Default node can be selected / manipulated per the Scene SelMode settings.
+// The node that is actually selected is the nearest solid under the mouse,
+// as determined by ray-cast picking (see Scene PickSolid).
 func (nb *Node3DBase) ConnectEvents3D(sc *Scene) {
 	nb.ConnectEvent(sc.Win, oswin.MouseEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
 		me := d.(*mouse.Event)
@@ -387,11 +389,17 @@ func (nb *Node3DBase) ConnectEvents3D(sc *Scene) {
 			return
 		}
 		ssc := sci.Embed(KiT_Scene).(*Scene)
-		ni := nb.This().(Node3D)
+		if ssc.SelMode == NotSelectable {
+			return
+		}
+		ni, _ := ssc.PickSolid(me.Where)
+		if ni == nil {
+			return // nothing actually hit -- scene clears selection
+		}
 		if ssc.CurSel != ni {
 			ssc.SetSel(ni)
-			me.SetProcessed()
 		}
+		me.SetProcessed()
 	})
 }
 
@@ -0,0 +1,133 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi3d
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"log"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/gpu"
+)
+
+// RenderToImage renders the scene from the current camera into an
+// offscreen framebuffer of given size (the current size of the scene if
+// either dimension is 0), and returns the resulting image, which is a new
+// copy owned by the caller.  This can be used for thumbnails, snapshots etc.
+// The scene must be in a visible window, which provides the GPU context,
+// but the on-screen view of the scene is not affected.
+func (sc *Scene) RenderToImage(size image.Point) (*image.RGBA, error) {
+	img, err := sc.RenderOffImage(size)
+	if err != nil {
+		return nil, err
+	}
+	cimg := image.NewRGBA(img.Bounds())
+	draw.Draw(cimg, cimg.Bounds(), img, image.ZP, draw.Src)
+	return cimg, nil
+}
+
+// SaveImage renders the scene offscreen at given size (see RenderToImage)
+// and saves the image to given file, with the format inferred from the
+// filename -- JPEG and PNG are supported.
+func (sc *Scene) SaveImage(filename gi.FileName, width, height int) error {
+	img, err := sc.RenderOffImage(image.Point{width, height})
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	err = gi.SaveImage(string(filename), img)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// RenderOffImage renders the scene from the current camera into the OffFrame
+// offscreen framebuffer at given size (the current size of the scene if
+// either dimension is 0), and returns the OffImg image, which is re-used
+// for each render -- copy to retain (or use RenderToImage).
+// The camera is restored to the on-screen view afterward.
+func (sc *Scene) RenderOffImage(size image.Point) (*image.RGBA, error) {
+	if sc.Win == nil || !sc.Win.IsVisible() {
+		return nil, fmt.Errorf("gi3d.Scene: %s RenderOffImage: not in a visible window to provide GPU context", sc.PathUnique())
+	}
+	if size.X <= 0 || size.Y <= 0 {
+		size = sc.Geom.Size
+	}
+	if size.X <= 0 || size.Y <= 0 {
+		return nil, fmt.Errorf("gi3d.Scene: %s RenderOffImage: size is empty", sc.PathUnique())
+	}
+	if !sc.ActivateWin() {
+		return nil, fmt.Errorf("gi3d.Scene: %s RenderOffImage: not able to activate window", sc.PathUnique())
+	}
+	if len(sc.SavedCams) == 0 {
+		sc.SaveCamera("default")
+	}
+	msamp := 4
+	if !gi.Prefs.Params.Smooth3D {
+		msamp = 0
+	}
+	sc.Camera.CamMu.RLock()
+	aspect := sc.Camera.Aspect
+	sc.Camera.CamMu.RUnlock()
+	defer func() { // restore on-screen view, including 2D bboxes of nodes
+		sc.Camera.CamMu.Lock()
+		sc.Camera.Aspect = aspect
+		sc.Camera.CamMu.Unlock()
+		sc.Camera.UpdateMatrix()
+		sc.UpdateMVPMatrix()
+	}()
+	err := sc.ActivateOffFrame(&sc.OffFrame, sc.Nm+"-off-frame", size, msamp)
+	if err != nil {
+		return nil, err
+	}
+	sc.UpdateNodes3D()
+	oswin.TheApp.RunOnMain(func() {
+		gpu.Draw.Wireframe(sc.Wireframe)
+	})
+	sc.RenderOffFrame()
+	oswin.TheApp.RunOnMain(func() {
+		gpu.Draw.Wireframe(false)
+		sc.OffFrame.Rendered()
+		timg, ok := sc.OffFrame.Texture().GrabImage().(*image.RGBA)
+		if !ok || timg == nil {
+			err = errors.New("gi3d.Scene: RenderOffImage: could not grab image from offscreen framebuffer")
+			return
+		}
+		if sc.OffImg == nil || sc.OffImg.Bounds().Size() != timg.Bounds().Size() {
+			sc.OffImg = image.NewRGBA(timg.Bounds())
+		}
+		draw.Draw(sc.OffImg, sc.OffImg.Bounds(), timg, timg.Bounds().Min, draw.Src)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sc.OffImg, nil
+}
+
+// DrawIntoViewport renders the scene offscreen at its current size and
+// draws it into the parent Viewport, clipped to the visible region,
+// as is done for Offscreen scenes.
+func (sc *Scene) DrawIntoViewport() {
+	img, err := sc.RenderOffImage(sc.Geom.Size)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	vp := sc.Viewport
+	if vp == nil || vp.Pixels == nil {
+		return
+	}
+	sc.BBoxMu.RLock()
+	// offset of visible region within the full scene
+	sp := sc.WinBBox.Min.Sub(sc.ObjBBox.Min)
+	r := sc.VpBBox
+	sc.BBoxMu.RUnlock()
+	draw.Draw(vp.Pixels, r, img, sp, draw.Src)
+}
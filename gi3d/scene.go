@@ -19,6 +19,7 @@ import (
 	"github.com/goki/gi/oswin/gpu"
 	"github.com/goki/gi/oswin/key"
 	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/oswin/touch"
 	"github.com/goki/ki/ki"
 	"github.com/goki/ki/kit"
 	"github.com/goki/mat32"
@@ -60,9 +61,21 @@ var Update3DTrace = false
 // There is default navigation event processing (disabled by setting NoNav)
 // where mouse drag events Orbit the camera (Shift = Pan, Alt = PanTarget)
 // and arrow keys do Orbit, Pan, PanTarget with same key modifiers.
+// Setting ArcBall makes mouse dragging rotate freely in any direction.
 // Spacebar restores original "default" camera, and numbers save (1st time)
 // or restore (subsequently) camera views (Control = always save)
 //
+// Solids are picked by casting a ray from the camera through the mouse
+// position (see PickSolid): the nearest solid hit is selected on click
+// and tracked as CurHover while the mouse moves, with changes reported
+// on SceneSig (see SceneSignals).
+//
+// By default the rendered scene is directly uploaded to the window, on top of
+// all 2D elements.  Setting Offscreen instead draws it into its parent
+// Viewport, so that it is clipped and scrolled like any other 2D element.
+// The scene can also be rendered offscreen to an image at any size
+// (see RenderToImage), e.g., for thumbnails or snapshots.
+//
 // A Group at the top-level named "TrackCamera" will automatically track
 // the camera (i.e., its Pose is copied) -- Solids in that group can
 // set their relative Pos etc to display relative to the camera, to achieve
@@ -78,15 +91,21 @@ type Scene struct {
 	Textures      map[string]Texture `desc:"all textures used in the scene"`
 	Library       map[string]*Group  `desc:"library of objects that can be used in the scene"`
 	NoNav         bool               `desc:"don't activate the standard navigation keyboard and mouse event processing to move around the camera in the scene"`
+	ArcBall       bool               `desc:"if true, mouse dragging rotates the camera freely around the target in the direction of the drag (arcball), instead of orbiting along the dominant horizontal or vertical axis"`
 	SavedCams     map[string]Camera  `desc:"saved cameras -- can Save and Set these to view the scene from different angles"`
 	Win           *gi.Window         `copy:"-" json:"-" xml:"-" desc:"our parent window that we render into"`
 	Renders       Renderers          `view:"-" desc:"rendering programs"`
 	Frame         gpu.Framebuffer    `view:"-" desc:"direct render target for scene"`
 	Tex           gpu.Texture2D      `view:"-" desc:"the texture that the framebuffer returns, which should be rendered into the window"`
+	Offscreen     bool               `desc:"if true, the scene is rendered offscreen and drawn into its parent Viewport like a regular 2D element, instead of being directly uploaded to the window on top of everything else -- this allows it to be clipped and scrolled within 2D layouts and overlaid by other 2D elements, at some cost in rendering speed -- must be set before the scene is first displayed"`
+	OffFrame      gpu.Framebuffer    `view:"-" desc:"offscreen framebuffer for rendering to an image, for Offscreen and RenderToImage"`
+	OffImg        *image.RGBA        `copy:"-" json:"-" xml:"-" view:"-" desc:"image from the last offscreen render -- re-used for each render"`
 	SetDragCursor bool               `view:"-" desc:"has dragging cursor been set yet?"`
 	SelMode       SelModes           `desc:"how to deal with selection / manipulation events"`
 	CurSel        Node3D             `copy:"-" json:"-" xml:"-" view:"-" desc:"currently selected node"`
 	CurManipPt    *ManipPt           `copy:"-" json:"-" xml:"-" view:"-" desc:"currently selected manipulation control point"`
+	CurHover      Node3D             `copy:"-" json:"-" xml:"-" view:"-" desc:"solid that the mouse is currently over, as determined by ray-cast picking"`
+	SceneSig      ki.Signal          `copy:"-" json:"-" xml:"-" view:"-" desc:"signal for picking events: hovering and selection -- see SceneSignals for the types, and data is the Node3D concerned"`
 	SelParams     SelParams          `view:"inline" desc:"parameters for selection / manipulation box"`
 }
 
@@ -462,7 +481,9 @@ func (sc *Scene) Init2D() {
 	sc.SetCurWin()
 	// note: Viewport will automatically update us for any update sigs
 	sc.Init3D()
-	sc.Win.AddDirectUploader(sc)
+	if !sc.Offscreen {
+		sc.Win.AddDirectUploader(sc)
+	}
 }
 
 func (sc *Scene) Style2D() {
@@ -560,7 +581,11 @@ func (sc *Scene) Render2D() {
 		if gi.Render2DTrace {
 			fmt.Printf("3D Render2D: %v\n", sc.PathUnique())
 		}
-		sc.Render()
+		if sc.Offscreen {
+			sc.DrawIntoViewport()
+		} else {
+			sc.Render()
+		}
 		sc.PopBounds()
 	} else {
 		sc.DisconnectAllEvents(gi.RegPri)
@@ -593,6 +618,8 @@ func (sc *Scene) NavEvents() {
 				ssc.Camera.PanAxis(dx*panDel, -dy*panDel)
 			case key.HasAllModifierBits(me.Modifiers, key.Alt):
 				ssc.Camera.PanTarget(dx*panDel, -dy*panDel, 0)
+			case ssc.ArcBall:
+				ssc.Camera.ArcBall(-dx*orbDel, -dy*orbDel)
 			default:
 				if mat32.Abs(dx) > mat32.Abs(dy) {
 					dy = 0
@@ -657,6 +684,30 @@ func (sc *Scene) NavEvents() {
 		}
 		ssc.UpdateSig()
 	})
+	sc.ConnectEvent(oswin.MagnifyEvent, gi.LowPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		ssc := recv.Embed(KiT_Scene).(*Scene)
+		if ssc.NoNav {
+			return
+		}
+		me := d.(*touch.MagnifyEvent)
+		me.SetProcessed()
+		if me.Action != touch.Move || me.Magnification <= 0 {
+			return
+		}
+		ssc.Camera.Zoom(1/me.Magnification - 1) // distance to target divided by it
+		ssc.UpdateSig()
+	})
+	sc.ConnectEvent(oswin.PanEvent, gi.LowPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		ssc := recv.Embed(KiT_Scene).(*Scene)
+		if ssc.NoNav {
+			return
+		}
+		me := d.(*touch.PanEvent)
+		me.SetProcessed()
+		panDel := float32(.01)
+		ssc.Camera.Pan(float32(me.Delta.X)*panDel, -float32(me.Delta.Y)*panDel)
+		ssc.UpdateSig()
+	})
 	sc.ConnectEvent(oswin.MouseEvent, gi.LowPri, func(recv, send ki.Ki, sig int64, d interface{}) {
 		ssc := recv.Embed(KiT_Scene).(*Scene)
 		if ssc.NoNav {
@@ -677,6 +728,22 @@ func (sc *Scene) NavEvents() {
 		ssc.SetSel(nil) // clear any selection at this point
 		// }
 	})
+	sc.ConnectEvent(oswin.MouseMoveEvent, gi.LowPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		ssc := recv.Embed(KiT_Scene).(*Scene)
+		if ssc.SelMode == NotSelectable || ssc.IsDragging() {
+			return
+		}
+		me := d.(*mouse.MoveEvent)
+		nii, _ := ssc.PickSolid(me.Where)
+		ssc.SetHover(nii)
+	})
+	sc.ConnectEvent(oswin.MouseFocusEvent, gi.LowPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		ssc := recv.Embed(KiT_Scene).(*Scene)
+		me := d.(*mouse.FocusEvent)
+		if me.Action == mouse.Exit {
+			ssc.SetHover(nil)
+		}
+	})
 	sc.ConnectEvent(oswin.KeyChordEvent, gi.LowPri, func(recv, send ki.Ki, sig int64, d interface{}) {
 		ssc := recv.Embed(KiT_Scene).(*Scene)
 		if ssc.NoNav {
@@ -953,6 +1020,10 @@ func (sc *Scene) DeleteResources() {
 		if sc.Frame != nil {
 			sc.Frame.Delete()
 		}
+		if sc.OffFrame != nil {
+			sc.OffFrame.Delete()
+			sc.OffFrame = nil
+		}
 	})
 }
 
@@ -1138,10 +1209,13 @@ func (sc *Scene) RenderOffFrame() bool {
 }
 
 func (sc *Scene) IsDirectWinUpload() bool {
-	return true
+	return !sc.Offscreen
 }
 
 func (sc *Scene) DirectWinUpload() bool {
+	if sc.Offscreen {
+		return false // re-rendered into viewport
+	}
 	if !sc.IsVisible() {
 		return true
 	}
@@ -1313,6 +1387,76 @@ func (sc *Scene) SolidsIntersectingPoint(pos image.Point) []Node3D {
 	return objs
 }
 
+// PickRay returns the ray in world coordinates from the camera position
+// through the given 2D window coordinate, pointing along the line of sight.
+func (sc *Scene) PickRay(pos image.Point) mat32.Ray {
+	sc.BBoxMu.RLock()
+	relpos := pos.Sub(sc.ObjBBox.Min) // includes scrolling
+	sc.BBoxMu.RUnlock()
+	sz := sc.Geom.Size
+	size := mat32.Vec2{float32(sz.X), float32(sz.Y)}
+	fpos := mat32.Vec2{float32(relpos.X), float32(relpos.Y)}
+	ndc := fpos.WindowToNDC(size, mat32.Vec2{}, true) // flipY
+	ndc.Z = -1                                        // at closest point
+	sc.Camera.CamMu.RLock()
+	defer sc.Camera.CamMu.RUnlock()
+	cdir := mat32.NewVec4FromVec3(ndc, 1).MulMat4(&sc.Camera.InvPrjnMatrix)
+	cdir.Z = -1
+	cdir.W = 0 // vec
+	// get world position / transform of camera: matrix is inverse of ViewMatrix
+	wdir := cdir.MulMat4(&sc.Camera.Pose.Matrix)
+	wdir.SetNormal()
+	wpos := sc.Camera.Pose.Matrix.Pos()
+	return *mat32.NewRay(wpos, mat32.Vec3{wdir.X, wdir.Y, wdir.Z})
+}
+
+// PickSolid returns the nearest visible solid whose world bounding box is
+// intersected by the ray from the camera through the given 2D window
+// coordinate, along with the world coordinates of the point of intersection.
+// The selection and manipulation boxes are ignored.  Returns nil if nothing hit.
+func (sc *Scene) PickSolid(pos image.Point) (Node3D, mat32.Vec3) {
+	ray := sc.PickRay(pos)
+	var pick Node3D
+	var ppt mat32.Vec3
+	mind := float32(-1)
+	for _, kid := range sc.Kids {
+		kii, _ := KiToNode3D(kid)
+		if kii == nil {
+			continue
+		}
+		if nm := kid.Name(); nm == SelBoxName || nm == ManipBoxName {
+			continue
+		}
+		kii.FuncDownMeFirst(0, kii.This(), func(k ki.Ki, level int, d interface{}) bool {
+			nii, ni := KiToNode3D(k)
+			if nii == nil {
+				return ki.Break // going into a different type of thing, bail
+			}
+			if ni.IsInvisible() {
+				return ki.Break
+			}
+			if !nii.IsSolid() {
+				return ki.Continue
+			}
+			ni.BBoxMu.RLock()
+			wbb := ni.WorldBBox.BBox
+			ni.BBoxMu.RUnlock()
+			ipt, ok := ray.IntersectBox(wbb)
+			if !ok {
+				return ki.Continue
+			}
+			dist := ipt.DistTo(ray.Origin)
+			if mind < 0 || dist < mind {
+				mind = dist
+				pick = nii
+				ppt = ipt
+			}
+			return ki.Continue
+		})
+	}
+	return pick, ppt
+}
+
 // SceneProps define the ToolBar and MenuBar for StructView
 var SceneProps = ki.Props{
 	"EnumType:Flag": gi.KiT_NodeFlags,
@@ -1320,5 +1464,21 @@ var SceneProps = ki.Props{
 		{"Update", ki.Props{
 			"icon": "update",
 		}},
+		{"SaveImage", ki.Props{
+			"desc":  "save an image of the scene rendered from the current camera, at given size",
+			"icon":  "file-save",
+			"label": "Save Image...",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".png,.jpg",
+				}},
+				{"Width", ki.Props{
+					"desc": "width in raw display dots -- use current size of scene if 0",
+				}},
+				{"Height", ki.Props{
+					"desc": "height in raw display dots -- use current size of scene if 0",
+				}},
+			},
+		}},
 	},
 }
@@ -0,0 +1,557 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package girl
+
+import (
+	"sort"
+
+	"github.com/chewxy/math32"
+	"github.com/goki/gi/gist"
+	"github.com/goki/mat32"
+	"golang.org/x/text/unicode/bidi"
+)
+
+// Bidirectional text, for right-to-left scripts such as Arabic and Hebrew,
+// is supported by resolving the embedding level of each rune according to
+// the Unicode Bidirectional Algorithm (UAX #9), and then reordering the
+// rune positions of each line (Span) for display, keeping the runes
+// themselves in their logical order, so all indexes into the text are
+// unaffected.  The explicit embedding, override and isolate formatting
+// characters are ignored (treated as boundary neutrals) -- the
+// unicode-bidi: bidi-override style can be used to override the direction
+// of a whole element instead.
+
+// BidiOn determines whether bidirectional reordering of text is done
+var BidiOn = true
+
+// IsRTL returns true if given text direction is right-to-left
+func IsRTL(dir gist.TextDirections) bool {
+	return dir == gist.RLTB || dir == gist.RL || dir == gist.RTL
+}
+
+// bidiClass returns the bidi class of given rune, with the explicit
+// formatting characters mapped to BN
+func bidiClass(r rune) bidi.Class {
+	if r < 0x80 {
+		switch {
+		case r >= '0' && r <= '9':
+			return bidi.EN
+		case r == ' ':
+			return bidi.WS
+		case r == '\t' || r == 0x0B || r == 0x1F:
+			return bidi.S
+		case r == '\n' || r == '\r' || r == 0x1C || r == 0x1D || r == 0x1E:
+			return bidi.B
+		case r == '+' || r == '-':
+			return bidi.ES
+		case r == '#' || r == '$' || r == '%':
+			return bidi.ET
+		case r == ',' || r == '.' || r == '/' || r == ':':
+			return bidi.CS
+		case (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+			return bidi.L
+		case r < ' ' || r == 0x7F:
+			return bidi.BN
+		}
+		return bidi.ON
+	}
+	p, _ := bidi.LookupRune(r)
+	c := p.Class()
+	if c >= bidi.Control {
+		return bidi.BN
+	}
+	return c
+}
+
+// HasRTL returns true if given text contains any right-to-left runes (or
+// Arabic numbers), which require bidirectional reordering
+func HasRTL(txt []rune) bool {
+	for _, r := range txt {
+		if r < 0x0590 {
+			continue
+		}
+		switch bidiClass(r) {
+		case bidi.R, bidi.AL, bidi.AN:
+			return true
+		}
+	}
+	return false
+}
+
+// FirstStrongRTL returns true if the first strong directional rune in given
+// text is right-to-left, which determines the direction of a paragraph
+// with automatic direction -- false if there are none
+func FirstStrongRTL(txt []rune) bool {
+	for _, r := range txt {
+		switch bidiClass(r) {
+		case bidi.L:
+			return false
+		case bidi.R, bidi.AL:
+			return true
+		}
+	}
+	return false
+}
+
+// ParaRTL returns true if the paragraph of given text has right-to-left
+// direction, according to given text style: for unicode-bidi: normal
+// (the default) this is determined by the first strong rune in the text
+// (so the Direction style is ignored), and otherwise by the Direction
+// style
+func ParaRTL(txt []rune, txtSty *gist.Text) bool {
+	if txtSty.UnicodeBidi == gist.BidiNormal {
+		return FirstStrongRTL(txt)
+	}
+	return IsRTL(txtSty.Direction)
+}
+
+// BidiLevels returns the resolved embedding level of each rune of given
+// line of text, in a paragraph with given direction -- odd levels are
+// right-to-left.  If override is true, all runes have the paragraph level
+// (as for unicode-bidi: bidi-override).  Returns nil if all levels are 0.
+func BidiLevels(txt []rune, rtl, override bool) []uint8 {
+	n := len(txt)
+	if n == 0 || (!rtl && !HasRTL(txt)) {
+		return nil
+	}
+	plev := uint8(0)
+	if rtl {
+		plev = 1
+	}
+	lvs := make([]uint8, n)
+	if override {
+		for i := range lvs {
+			lvs[i] = plev
+		}
+		return lvs
+	}
+	sor := bidi.L
+	if rtl {
+		sor = bidi.R
+	}
+	cls := make([]bidi.Class, n)
+	orig := make([]bidi.Class, n)
+	for i, r := range txt {
+		cls[i] = bidiClass(r)
+		orig[i] = cls[i]
+	}
+
+	// W1: NSM takes the type of the previous rune (BN are skipped)
+	prev := sor
+	for i, c := range cls {
+		switch c {
+		case bidi.NSM:
+			cls[i] = prev
+		case bidi.BN:
+		default:
+			prev = c
+		}
+	}
+	// W2: EN preceded by AL is AN, W3: AL is R
+	lstrong := sor
+	for i, c := range cls {
+		switch c {
+		case bidi.L, bidi.R:
+			lstrong = c
+		case bidi.AL:
+			lstrong = c
+			cls[i] = bidi.R
+		case bidi.EN:
+			if lstrong == bidi.AL {
+				cls[i] = bidi.AN
+			}
+		}
+	}
+	// W4: single ES between EN's is EN, single CS between same numbers
+	for i := 1; i < n-1; i++ {
+		c := cls[i]
+		if c != bidi.ES && c != bidi.CS {
+			continue
+		}
+		pc, nc := cls[i-1], cls[i+1]
+		if pc == bidi.EN && nc == bidi.EN {
+			cls[i] = bidi.EN
+		} else if c == bidi.CS && pc == bidi.AN && nc == bidi.AN {
+			cls[i] = bidi.AN
+		}
+	}
+	// W5: sequence of ET adjacent to EN is EN
+	for i := 0; i < n; {
+		if cls[i] != bidi.ET {
+			i++
+			continue
+		}
+		st := i
+		for i < n && (cls[i] == bidi.ET || cls[i] == bidi.BN) {
+			i++
+		}
+		if (st > 0 && cls[st-1] == bidi.EN) || (i < n && cls[i] == bidi.EN) {
+			for j := st; j < i; j++ {
+				cls[j] = bidi.EN
+			}
+		}
+	}
+	// W6: remaining separators and terminators are ON, W7: EN after L is L
+	lstrong = sor
+	for i, c := range cls {
+		switch c {
+		case bidi.ES, bidi.ET, bidi.CS:
+			cls[i] = bidi.ON
+		case bidi.L, bidi.R:
+			lstrong = c
+		case bidi.EN:
+			if lstrong == bidi.L {
+				cls[i] = bidi.L
+			}
+		}
+	}
+	strongDir := func(c bidi.Class) bidi.Class {
+		switch c {
+		case bidi.L:
+			return bidi.L
+		case bidi.R, bidi.EN, bidi.AN:
+			return bidi.R
+		}
+		return bidi.ON
+	}
+	// N0: paired brackets take the embedding direction if it is found
+	// within them, else the opposite direction if found within and before
+	for _, bp := range bidiBracketPairs(txt, orig) {
+		var in, ctx bidi.Class = bidi.ON, sor
+		for j := bp[0] + 1; j < bp[1]; j++ {
+			if d := strongDir(cls[j]); d != bidi.ON {
+				in = d
+				if d == sor {
+					break
+				}
+			}
+		}
+		if in == bidi.ON {
+			continue
+		}
+		if in != sor {
+			for j := bp[0] - 1; j >= 0; j-- {
+				if d := strongDir(cls[j]); d != bidi.ON {
+					ctx = d
+					break
+				}
+			}
+			if ctx != in {
+				in = sor
+			}
+		}
+		cls[bp[0]], cls[bp[1]] = in, in
+	}
+	// N1, N2: neutrals between the same direction take that direction
+	// (numbers count as R), otherwise the paragraph direction
+	for i := 0; i < n; {
+		if strongDir(cls[i]) != bidi.ON {
+			i++
+			continue
+		}
+		st := i
+		for i < n && strongDir(cls[i]) == bidi.ON {
+			i++
+		}
+		before := sor
+		if st > 0 {
+			before = strongDir(cls[st-1])
+		}
+		after := sor
+		if i < n {
+			after = strongDir(cls[i])
+		}
+		nd := sor
+		if before == after {
+			nd = before
+		}
+		for j := st; j < i; j++ {
+			cls[j] = nd
+		}
+	}
+	// I1, I2: implicit levels
+	hasLv := false
+	for i, c := range cls {
+		lv := plev
+		if plev%2 == 0 {
+			switch c {
+			case bidi.R:
+				lv++
+			case bidi.AN, bidi.EN:
+				lv += 2
+			}
+		} else if c == bidi.L || c == bidi.EN || c == bidi.AN {
+			lv++
+		}
+		lvs[i] = lv
+		if lv > 0 {
+			hasLv = true
+		}
+	}
+	// L1: separators and trailing whitespace have the paragraph level
+	trail := true
+	for i := n - 1; i >= 0; i-- {
+		switch orig[i] {
+		case bidi.S, bidi.B:
+			lvs[i] = plev
+			trail = true
+		case bidi.WS, bidi.BN:
+			if trail {
+				lvs[i] = plev
+			}
+		default:
+			trail = false
+		}
+	}
+	if !hasLv {
+		return nil
+	}
+	return lvs
+}
+
+// bidiBracketPairs returns the indexes of the matching pairs of brackets
+// in given text, sorted by the opening bracket (BD16)
+func bidiBracketPairs(txt []rune, cls []bidi.Class) [][2]int {
+	var pairs [][2]int
+	var stack []int
+	for i, r := range txt {
+		if cls[i] != bidi.ON {
+			continue
+		}
+		switch r {
+		case '(', '[', '{':
+			if len(stack) == 63 {
+				return pairs
+			}
+			stack = append(stack, i)
+		case ')', ']', '}':
+			for j := len(stack) - 1; j >= 0; j-- {
+				if txt[stack[j]] == bidiMirrors[r] {
+					pairs = append(pairs, [2]int{stack[j], i})
+					stack = stack[:j]
+					break
+				}
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i][0] < pairs[j][0] })
+	return pairs
+}
+
+// BidiVisualOrder returns the logical indexes of the runes with given
+// embedding levels, in visual (left-to-right display) order, by reversing
+// each sequence of runes at or above each odd level, from the highest
+// level down
+func BidiVisualOrder(levels []uint8) []int {
+	n := len(levels)
+	ord := make([]int, n)
+	var maxl, minodd uint8 = 0, 255
+	for i, lv := range levels {
+		ord[i] = i
+		if lv > maxl {
+			maxl = lv
+		}
+		if lv%2 == 1 && lv < minodd {
+			minodd = lv
+		}
+	}
+	if minodd == 255 {
+		return ord
+	}
+	for lv := maxl; lv >= minodd; lv-- {
+		for i := 0; i < n; {
+			if levels[ord[i]] < lv {
+				i++
+				continue
+			}
+			st := i
+			for i < n && levels[ord[i]] >= lv {
+				i++
+			}
+			for a, b := st, i-1; a < b; a, b = a+1, b-1 {
+				ord[a], ord[b] = ord[b], ord[a]
+			}
+		}
+	}
+	return ord
+}
+
+// bidiMirrors are the mirrored glyphs for paired characters, which are
+// displayed in right-to-left runs
+var bidiMirrors = map[rune]rune{
+	'(': ')', ')': '(', '[': ']', ']': '[', '{': '}', '}': '{', '<': '>', '>': '<',
+	'«': '»', '»': '«', '‹': '›', '›': '‹', '≤': '≥', '≥': '≤',
+}
+
+// BidiMirror returns the mirrored glyph for given rune, for display in a
+// right-to-left run, or 0 if it has none
+func BidiMirror(r rune) rune {
+	return bidiMirrors[r]
+}
+
+// SetBidiLR reorders the positions of the runes of this span for display
+// according to the bidirectional algorithm, as one line of a paragraph with
+// given direction (see ParaRTL) -- override is for unicode-bidi:
+// bidi-override.  Must be called after SetRunePosLR (and any wrapping of the
+// text), as the logical positions are reordered.  Combining marks stay with
+// their base runes, and paired characters such as brackets are mirrored in
+// right-to-left runs.  The span Dir is set to RLTB for a right-to-left
+// paragraph.  BidiLevels is set to the resulting levels, or nil if the text
+// is all left-to-right, in which case nothing is changed.
+func (sr *Span) SetBidiLR(rtl, override bool) {
+	sr.BidiLevels = nil
+	if rtl {
+		sr.Dir = gist.RLTB
+	}
+	n := len(sr.Text)
+	if !BidiOn || n == 0 || len(sr.Render) != n {
+		return
+	}
+	lvs := BidiLevels(sr.Text, rtl, override)
+	if lvs == nil {
+		return
+	}
+	sr.BidiLevels = lvs
+	base := make([]int, n) // index of base rune for each rune
+	pos := make([]float32, n)
+	adv := make([]float32, n)
+	bi := 0
+	for i, r := range sr.Text {
+		pos[i] = sr.Render[i].RelPos.X
+		if i == 0 || !ShapingOn || !IsMark(r) {
+			bi = i
+		}
+		base[i] = bi
+	}
+	nxt := sr.LastPos.X
+	for i := n - 1; i >= 0; i-- {
+		if base[i] == i {
+			adv[i] = nxt - pos[i]
+			nxt = pos[i]
+		}
+	}
+	x := pos[0]
+	for _, i := range BidiVisualOrder(lvs) {
+		if base[i] != i {
+			continue
+		}
+		sr.Render[i].RelPos.X = x
+		x += adv[i]
+	}
+	for i := range sr.Render {
+		rr := &sr.Render[i]
+		if b := base[i]; b != i {
+			rr.RelPos.X = sr.Render[b].RelPos.X + pos[i] - pos[b]
+		}
+		if lvs[i]%2 == 1 && rr.Glyph == 0 {
+			rr.Glyph = BidiMirror(sr.Text[i])
+		}
+	}
+}
+
+// CaretPosLR returns the relative position (adding Span RelPos, as in
+// RuneRelPos) of the text cursor before the rune at given logical index,
+// which is the right edge of a right-to-left rune.  If index >= length, the
+// position is after the last rune.
+func (sr *Span) CaretPosLR(idx int) mat32.Vec2 {
+	n := len(sr.Render)
+	if n == 0 {
+		return sr.RelPos
+	}
+	after := false
+	if idx >= n {
+		idx = n - 1
+		after = true
+	}
+	rr := &sr.Render[idx]
+	pos := sr.RelPos.Add(rr.RelPos)
+	rtl := sr.BidiLevels != nil && sr.BidiLevels[idx]%2 == 1
+	if rtl != after {
+		pos.X += rr.Size.X
+	}
+	return pos
+}
+
+// CaretAtPosLR returns the logical index of the text cursor position (see
+// CaretPosLR) closest to given relative horizontal position (including
+// Span RelPos) -- ranges from 0 to the length of the span
+func (sr *Span) CaretAtPosLR(x float32) int {
+	n := len(sr.Render)
+	ci := 0
+	mind := float32(math32.MaxFloat32)
+	for i := 0; i <= n; i++ {
+		d := math32.Abs(sr.CaretPosLR(i).X - x)
+		if d < mind {
+			mind = d
+			ci = i
+		}
+	}
+	return ci
+}
+
+// RangeBoxesLR returns the horizontal extents (start, end, including Span
+// RelPos) of the runes in given logical range [st, ed), in left-to-right
+// display order -- with bidirectional text, a contiguous logical range can
+// be displayed in multiple separate pieces, e.g., for selection highlighting
+func (sr *Span) RangeBoxesLR(st, ed int) [][2]float32 {
+	n := len(sr.Render)
+	if ed > n {
+		ed = n
+	}
+	if st >= ed {
+		return nil
+	}
+	var ord []int
+	if sr.BidiLevels != nil {
+		ord = BidiVisualOrder(sr.BidiLevels)
+	} else {
+		ord = make([]int, n)
+		for i := range ord {
+			ord[i] = i
+		}
+	}
+	var bxs [][2]float32
+	prvIn := false
+	for _, i := range ord {
+		if i < st || i >= ed {
+			prvIn = false
+			continue
+		}
+		rr := &sr.Render[i]
+		if rr.Size.X == 0 && prvIn { // mark
+			continue
+		}
+		sx := sr.RelPos.X + rr.RelPos.X
+		ex := sx + rr.Size.X
+		if prvIn {
+			bx := &bxs[len(bxs)-1]
+			bx[0] = math32.Min(bx[0], sx)
+			bx[1] = math32.Max(bx[1], ex)
+		} else {
+			bxs = append(bxs, [2]float32{sx, ex})
+		}
+		prvIn = true
+	}
+	return bxs
+}
+
+// CaretRelPos returns the relative position of the text cursor before the
+// given rune index, counting progressively through all spans present, as
+// in RuneRelPos, but accounting for bidirectional text (see
+// Span.CaretPosLR).  Returns also the index of the span that holds that
+// char (-1 = no spans at all) and the rune index within that span, and
+// false if index is out of range (positioned after the last rune).
+func (tx *Text) CaretRelPos(idx int) (pos mat32.Vec2, si, ri int, ok bool) {
+	si, ri, ok = tx.RuneSpanPos(idx)
+	if ok {
+		return tx.Spans[si].CaretPosLR(ri), si, ri, true
+	}
+	nsp := len(tx.Spans)
+	if nsp > 0 {
+		sr := &tx.Spans[nsp-1]
+		return sr.CaretPosLR(len(sr.Render)), nsp - 1, len(sr.Render), false
+	}
+	return mat32.Vec2Zero, -1, -1, false
+}
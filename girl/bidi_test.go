@@ -0,0 +1,117 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package girl
+
+import (
+	"image/color"
+	"reflect"
+	"testing"
+	"unicode"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestBidiLevels(t *testing.T) {
+	tests := []struct {
+		txt string
+		rtl bool
+		lvs []uint8
+		ord []int
+	}{
+		{"abc 123", false, nil, nil},
+		{"abc אבג 123", false, []uint8{0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2}, []int{0, 1, 2, 3, 8, 9, 10, 7, 6, 5, 4}},
+		{"אבג abc", true, []uint8{1, 1, 1, 1, 2, 2, 2}, []int{4, 5, 6, 3, 2, 1, 0}},
+		{"abc ", true, []uint8{2, 2, 2, 1}, []int{3, 0, 1, 2}},
+		{"אב (ab)", true, []uint8{1, 1, 1, 1, 2, 2, 1}, []int{6, 4, 5, 3, 2, 1, 0}},
+		{"ab (אב)", false, []uint8{0, 0, 0, 0, 1, 1, 0}, []int{0, 1, 2, 3, 5, 4, 6}},
+	}
+	for _, ts := range tests {
+		lvs := BidiLevels([]rune(ts.txt), ts.rtl, false)
+		if !reflect.DeepEqual(lvs, ts.lvs) {
+			t.Errorf("%q levels: %v != expected: %v", ts.txt, lvs, ts.lvs)
+			continue
+		}
+		if lvs == nil {
+			continue
+		}
+		if ord := BidiVisualOrder(lvs); !reflect.DeepEqual(ord, ts.ord) {
+			t.Errorf("%q visual order: %v != expected: %v", ts.txt, ord, ts.ord)
+		}
+	}
+}
+
+// allGlyphsFace is a font face that has every glyph, for shaping tests
+type allGlyphsFace struct {
+	font.Face
+}
+
+func (af allGlyphsFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	return fixed.I(8), true
+}
+
+func TestShapeArabic(t *testing.T) {
+	txt := []rune("سلام بك")
+	rend := make([]Rune, len(txt))
+	ShapeArabic(txt, rend, allGlyphsFace{})
+	gls := make([]rune, len(rend))
+	for i := range rend {
+		gls[i] = rend[i].Glyph
+	}
+	exp := []rune{0xFEB3, 0xFEFC, GlyphNone, 0xFEE1, 0, 0xFE91, 0xFEDA}
+	if !reflect.DeepEqual(gls, exp) {
+		t.Errorf("glyphs: %X != expected: %X", gls, exp)
+	}
+}
+
+func TestUnshapedScript(t *testing.T) {
+	tests := []struct {
+		txt string
+		sc  *unicode.RangeTable
+	}{{"hello", nil}, {"سلام", nil}, {"שלום", nil}, {"नमस्ते", unicode.Devanagari}, {"a வணக்கம்", unicode.Tamil}, {"ខ្មែរ", unicode.Khmer}}
+	for _, tst := range tests {
+		if sc := UnshapedScript([]rune(tst.txt)); sc != tst.sc {
+			t.Errorf("%q: unshaped script: %v != expected: %v", tst.txt, scriptName(sc), scriptName(tst.sc))
+		}
+	}
+}
+
+func TestSpanBidi(t *testing.T) {
+	ff, err := OpenGoFont("Go", "gofont/goregular", 16, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sr := &Span{Text: []rune("ab (אב")}
+	sr.Render = make([]Rune, len(sr.Text))
+	sr.Render[0].Face = ff.Face
+	sr.Render[0].Color = color.Black
+	sr.SetRunePosLR(0, 0, 8, 4)
+	wd := sr.SizeHV().X
+	sr.SetBidiLR(false, false)
+	if sr.BidiLevels == nil {
+		t.Fatal("no bidi levels")
+	}
+	if sz := sr.SizeHV().X; sz != wd {
+		t.Errorf("size changed by reordering: %v != %v", sz, wd)
+	}
+	// visual: a b space ( ב א -- the paren is neutral between L and R: L
+	x := func(i int) float32 { return sr.Render[i].RelPos.X }
+	if !(x(0) < x(1) && x(1) < x(2) && x(2) < x(3) && x(3) < x(5) && x(5) < x(4)) {
+		t.Errorf("bad visual positions: %v %v %v %v %v %v", x(0), x(1), x(2), x(3), x(4), x(5))
+	}
+	if sr.Render[3].Glyph != 0 {
+		t.Errorf("paren at level 0 should not be mirrored")
+	}
+	if cx := sr.CaretPosLR(4).X; cx != x(4)+sr.Render[4].Size.X {
+		t.Errorf("caret before rtl rune should be at its right edge: %v", cx)
+	}
+	if ci := sr.CaretAtPosLR(x(5)); ci != 6 {
+		t.Errorf("caret at left edge of last rtl rune should be end: %v", ci)
+	}
+	bxs := sr.RangeBoxesLR(3, 5) // paren and first hebrew: not adjacent
+	if len(bxs) != 2 {
+		t.Errorf("range boxes: %v should have 2 pieces", bxs)
+	}
+}
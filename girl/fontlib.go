@@ -6,6 +6,7 @@ package girl
 
 import (
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
@@ -17,6 +18,7 @@ import (
 	"github.com/goki/freetype/truetype"
 	"github.com/goki/gi/gist"
 	"github.com/iancoleman/strcase"
+	"golang.org/x/image/font"
 	"golang.org/x/image/font/gofont/gobold"
 	"golang.org/x/image/font/gofont/gobolditalic"
 	"golang.org/x/image/font/gofont/goitalic"
@@ -155,6 +157,37 @@ func (fl *FontLib) DeleteFont(fontnm string) {
 	}
 }
 
+// FindFontFace returns the cached FontFace that holds given font.Face,
+// or nil if not found -- e.g., to get the name of the font a face is from.
+func (fl *FontLib) FindFontFace(face font.Face) *gist.FontFace {
+	loadFontMu.RLock()
+	defer loadFontMu.RUnlock()
+	for _, facemap := range fl.Faces {
+		for _, ff := range facemap {
+			if ff.Face == face {
+				return ff
+			}
+		}
+	}
+	return nil
+}
+
+// FontData returns the raw font file data for given font name (see
+// FontsAvail list), e.g., for embedding the font in another document.
+func (fl *FontLib) FontData(fontnm string) ([]byte, error) {
+	fontnm = strings.ToLower(fontnm)
+	loadFontMu.RLock()
+	path := fl.FontsAvail[fontnm]
+	loadFontMu.RUnlock()
+	if path == "" {
+		return nil, fmt.Errorf("gi.FontLib: Font named: %v not found in list of available fonts", fontnm)
+	}
+	if gf, ok := GoFonts[path]; ok {
+		return gf.ttf, nil
+	}
+	return ioutil.ReadFile(path)
+}
+
 // OpenAllFonts attempts to load all fonts that were found -- call this before
 // displaying the font chooser to eliminate any bad fonts.
 func (fl *FontLib) OpenAllFonts(size int) {
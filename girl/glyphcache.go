@@ -0,0 +1,172 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package girl
+
+import (
+	"image"
+	"image/draw"
+	"reflect"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// GlyphCacheOn determines whether text rendering uses the shared
+// GlyphCache -- otherwise each glyph is rasterized by its font face each
+// time it is rendered
+var GlyphCacheOn = true
+
+// GlyphCache is the shared atlas of rendered glyphs used for all text
+// rendering, so that repeated text (menus, tables, code etc) is rasterized
+// only once per glyph, font face (which determines the family, size and
+// style) and sub-pixel position
+var GlyphCache = NewGlyphAtlas(1024, 16)
+
+// GlyphAtlas is a cache of rendered glyph masks, packed into large alpha
+// images (pages) -- a rendered glyph is then just a masked copy from its
+// page, and the pages are suitable for uploading as GPU textures.  When all
+// of the pages are full, the cache is cleared and starts over.  It is safe
+// for concurrent use.
+type GlyphAtlas struct {
+	PageSize int            `desc:"width and height of each page"`
+	MaxPages int            `desc:"maximum number of pages -- cache is cleared when these are all full"`
+	Pages    []*image.Alpha `desc:"pages of packed glyph masks"`
+	Mu       sync.Mutex     `desc:"mutex protecting the cache"`
+	glyphs   map[glyphKey]glyphEntry
+	comp     map[reflect.Type]bool // whether font face types can be used as keys
+	shelfX   int                   // x position of next glyph on current shelf
+	shelfY   int                   // y position of current shelf
+	shelfH   int                   // height of current shelf
+}
+
+// glyphKey is the key for a glyph in the cache
+type glyphKey struct {
+	face font.Face
+	r    rune
+	fx   fixed.Int26_6 // sub-pixel x position
+}
+
+// glyphEntry is a glyph in the cache
+type glyphEntry struct {
+	page int
+	rect image.Rectangle // location in page
+	off  image.Point     // offset of rect from the dot
+	ok   bool            // false if face has no glyph
+}
+
+// sub-pixel quantization of glyph positions, as used by truetype faces:
+// 4 positions in x and 1 in y
+const (
+	glyphBiasX = 8
+	glyphMaskX = ^fixed.Int26_6(15)
+	glyphBiasY = 32
+	glyphMaskY = ^fixed.Int26_6(63)
+)
+
+// NewGlyphAtlas returns a new glyph atlas with given page size and maximum
+// number of pages
+func NewGlyphAtlas(pageSize, maxPages int) *GlyphAtlas {
+	ga := &GlyphAtlas{PageSize: pageSize, MaxPages: maxPages}
+	ga.Reset()
+	return ga
+}
+
+// Reset clears the cache -- the existing pages are released (not reused),
+// so any glyph masks in use remain valid
+func (ga *GlyphAtlas) Reset() {
+	ga.glyphs = make(map[glyphKey]glyphEntry)
+	ga.Pages = nil
+	ga.shelfX, ga.shelfY, ga.shelfH = 0, 0, 0
+}
+
+// Len returns the number of glyphs in the cache
+func (ga *GlyphAtlas) Len() int {
+	ga.Mu.Lock()
+	defer ga.Mu.Unlock()
+	return len(ga.glyphs)
+}
+
+// Glyph returns the glyph for given rune in given face at given dot
+// position, rendering it into the cache if not already there -- it has the
+// same results as font.Face.Glyph (except advance): the destination
+// rectangle, the mask image (a cache page) and the point in the mask
+// corresponding to the top-left of the destination.
+func (ga *GlyphAtlas) Glyph(face font.Face, dot fixed.Point26_6, r rune) (dr image.Rectangle, mask image.Image, maskp image.Point, ok bool) {
+	dotX := (dot.X + glyphBiasX) & glyphMaskX
+	dotY := (dot.Y + glyphBiasY) & glyphMaskY
+	ix, fx := int(dotX>>6), dotX&0x3f
+	iy := int(dotY >> 6)
+
+	ga.Mu.Lock()
+	defer ga.Mu.Unlock()
+	if ga.comp == nil {
+		ga.comp = make(map[reflect.Type]bool)
+	}
+	ft := reflect.TypeOf(face)
+	comp, has := ga.comp[ft]
+	if !has {
+		comp = ft.Comparable()
+		ga.comp[ft] = comp
+	}
+	if !comp {
+		dr, mask, maskp, _, ok = face.Glyph(dot, r)
+		return
+	}
+	key := glyphKey{face: face, r: r, fx: fx}
+	ge, has := ga.glyphs[key]
+	if !has {
+		ge = ga.render(face, key)
+	}
+	if !ge.ok {
+		return image.Rectangle{}, nil, image.Point{}, false
+	}
+	if ge.page < 0 { // too big to cache
+		dr, mask, maskp, _, ok = face.Glyph(dot, r)
+		return
+	}
+	dr = ge.rect.Sub(ge.rect.Min).Add(ge.off).Add(image.Point{ix, iy})
+	return dr, ga.Pages[ge.page], ge.rect.Min, true
+}
+
+// render renders the glyph for given key into the cache
+func (ga *GlyphAtlas) render(face font.Face, key glyphKey) glyphEntry {
+	gdr, gmask, gmaskp, _, ok := face.Glyph(fixed.Point26_6{X: key.fx}, key.r)
+	if !ok {
+		ge := glyphEntry{}
+		ga.glyphs[key] = ge
+		return ge
+	}
+	sz := gdr.Size()
+	if sz.X+1 > ga.PageSize || sz.Y+1 > ga.PageSize {
+		ge := glyphEntry{page: -1, ok: true}
+		ga.glyphs[key] = ge
+		return ge
+	}
+	if ga.shelfX+sz.X+1 > ga.PageSize { // next shelf
+		ga.shelfY += ga.shelfH
+		ga.shelfX, ga.shelfH = 0, 0
+	}
+	if len(ga.Pages) == 0 || ga.shelfY+sz.Y+1 > ga.PageSize { // next page
+		if len(ga.Pages) >= ga.MaxPages {
+			ga.Reset()
+		}
+		ga.Pages = append(ga.Pages, image.NewAlpha(image.Rect(0, 0, ga.PageSize, ga.PageSize)))
+		ga.shelfX, ga.shelfY, ga.shelfH = 0, 0, 0
+	}
+	pg := len(ga.Pages) - 1
+	rect := image.Rectangle{Min: image.Point{ga.shelfX, ga.shelfY}}
+	rect.Max = rect.Min.Add(sz)
+	if !gdr.Empty() {
+		draw.Draw(ga.Pages[pg], rect, gmask, gmaskp, draw.Src)
+	}
+	ga.shelfX += sz.X + 1 // 1 pixel gap between glyphs, for filtering
+	if sz.Y+1 > ga.shelfH {
+		ga.shelfH = sz.Y + 1
+	}
+	ge := glyphEntry{page: pg, rect: rect, off: gdr.Min, ok: true}
+	ga.glyphs[key] = ge
+	return ge
+}
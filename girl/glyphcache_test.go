@@ -0,0 +1,46 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package girl
+
+import (
+	"image"
+	"image/draw"
+	"testing"
+
+	"golang.org/x/image/math/fixed"
+)
+
+func TestGlyphAtlas(t *testing.T) {
+	ff, err := OpenGoFont("Go", "gofont/goregular", 16, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ga := NewGlyphAtlas(64, 2) // small, to exercise shelves, pages and reset
+	for i, r := range "The quick brown fox jumps over the lazy dog! @WM" {
+		dot := fixed.Point26_6{X: fixed.Int26_6(100*64 + i*13), Y: fixed.Int26_6(50*64 + i*7)}
+		cdr, cmask, cmaskp, cok := ga.Glyph(ff.Face, dot, r)
+		if !cok {
+			t.Fatalf("glyph for %q not ok", r)
+		}
+		cimg := image.NewAlpha(cdr)
+		draw.Draw(cimg, cdr, cmask, cmaskp, draw.Src)
+		dr, mask, maskp, _, _ := ff.Face.Glyph(dot, r)
+		if dr != cdr {
+			t.Errorf("glyph %q: cached rect: %v != direct: %v", r, cdr, dr)
+			continue
+		}
+		img := image.NewAlpha(dr)
+		draw.Draw(img, dr, mask, maskp, draw.Src)
+		for j := range img.Pix {
+			if img.Pix[j] != cimg.Pix[j] {
+				t.Errorf("glyph %q: cached mask differs from direct", r)
+				break
+			}
+		}
+	}
+	if len(ga.Pages) > ga.MaxPages {
+		t.Errorf("atlas has %d pages, more than max: %d", len(ga.Pages), ga.MaxPages)
+	}
+}
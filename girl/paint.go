@@ -277,6 +277,9 @@ func (pc *Paint) stroke(rs *State) {
 	rs.Raster.SetColor(pc.StrokeStyle.Color.RenderColor(pc.FontStyle.Opacity*pc.StrokeStyle.Opacity, rs.LastRenderBBox, rs.XForm))
 	rs.Raster.Draw()
 	rs.Raster.Clear()
+	if rs.PDF != nil {
+		rs.PDF.strokePath(rs, pc, dash)
+	}
 
 	/*
 		rs.CompSpanner.DrawToImage(rs.Image)
@@ -310,6 +313,9 @@ func (pc *Paint) fill(rs *State) {
 	}
 	rf.Draw()
 	rf.Clear()
+	if rs.PDF != nil {
+		rs.PDF.fillPath(rs, pc)
+	}
 
 	/*
 		rs.CompSpanner.DrawToImage(rs.Image)
@@ -352,6 +358,9 @@ func (pc *Paint) FillBox(rs *State, pos, size mat32.Vec2, clr *gist.ColorSpec) {
 	if clr.Source == gist.SolidColor {
 		b := rs.Bounds.Intersect(mat32.RectFromPosSizeMax(pos, size))
 		draw.Draw(rs.Image, b, &image.Uniform{clr.Color}, image.ZP, draw.Src)
+		if rs.PDF != nil {
+			rs.PDF.FillRect(rs.Bounds, b, clr.Color)
+		}
 	} else {
 		pc.FillStyle.SetColorSpec(clr)
 		pc.DrawRectangle(rs, pos.X, pos.Y, size.X, size.Y)
@@ -363,20 +372,24 @@ func (pc *Paint) FillBox(rs *State, pos, size mat32.Vec2, clr *gist.ColorSpec) {
 func (pc *Paint) FillBoxColor(rs *State, pos, size mat32.Vec2, clr color.Color) {
 	b := rs.Bounds.Intersect(mat32.RectFromPosSizeMax(pos, size))
 	draw.Draw(rs.Image, b, &image.Uniform{clr}, image.ZP, draw.Src)
+	if rs.PDF != nil {
+		rs.PDF.FillRect(rs.Bounds, b, clr)
+	}
 }
 
 // ClipPreserve updates the clipping region by intersecting the current
 // clipping region with the current path as it would be filled by pc.Fill().
 // The path is preserved after this operation.
 func (pc *Paint) ClipPreserve(rs *State) {
-	clip := image.NewAlpha(rs.Image.Bounds())
+	clip := GetAlpha(rs.Image.Bounds())
 	// painter := raster.NewAlphaOverPainter(clip) // todo!
 	pc.fill(rs)
 	if rs.Mask == nil {
 		rs.Mask = clip
 	} else { // todo: this one operation MASSIVELY slows down clip usage -- unclear why
-		mask := image.NewAlpha(rs.Image.Bounds())
+		mask := GetAlpha(rs.Image.Bounds())
 		draw.DrawMask(mask, mask.Bounds(), clip, image.ZP, rs.Mask, image.ZP, draw.Over)
+		PutAlpha(clip)
 		rs.Mask = mask
 	}
 }
@@ -394,10 +407,11 @@ func (pc *Paint) SetMask(rs *State, mask *image.Alpha) error {
 
 // AsMask returns an *image.Alpha representing the alpha channel of this
 // context. This can be useful for advanced clipping operations where you first
-// render the mask geometry and then use it as a mask.
+// render the mask geometry and then use it as a mask.  It can be released
+// with PutAlpha when it is no longer used.
 func (pc *Paint) AsMask(rs *State) *image.Alpha {
 	b := rs.Image.Bounds()
-	mask := image.NewAlpha(b)
+	mask := GetAlpha(b)
 	draw.Draw(mask, b, rs.Image, image.ZP, draw.Src)
 	return mask
 }
@@ -422,6 +436,9 @@ func (pc *Paint) ResetClip(rs *State) {
 func (pc *Paint) Clear(rs *State) {
 	src := image.NewUniform(&pc.FillStyle.Color.Color)
 	draw.Draw(rs.Image, rs.Image.Bounds(), src, image.ZP, draw.Src)
+	if rs.PDF != nil {
+		rs.PDF.FillRect(rs.Image.Bounds(), rs.Image.Bounds(), &pc.FillStyle.Color.Color)
+	}
 }
 
 // SetPixel sets the color of the specified pixel using the current stroke color.
@@ -707,6 +724,9 @@ func (pc *Paint) DrawImageAnchored(rs *State, fmIm image.Image, x, y int, ax, ay
 			DstMaskP: image.ZP,
 		})
 	}
+	if rs.PDF != nil {
+		rs.PDF.DrawImage(rs.Bounds, fmIm, m)
+	}
 }
 
 //////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,685 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package girl
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goki/freetype/truetype"
+	"github.com/goki/gi/gist"
+	"github.com/goki/mat32"
+	"github.com/srwiley/rasterx"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// PDF records drawing as vector graphics, for output as a PDF document,
+// e.g., for printing.  When the PDF field of a State is set, all of the
+// drawing done through it (paths, fills and strokes, images, and text) is
+// also recorded there, in the same pixel coordinates as the raster image.
+// Text is written using the TrueType fonts embedded in the document, so it
+// is also searchable.  Write then lays out the recorded drawing on pages.
+type PDF struct {
+	Size image.Point `desc:"size of the recorded drawing, in pixels"`
+
+	content  bytes.Buffer
+	origin   image.Point   // current origin, from PushOrigin
+	origins  []image.Point // stack of origins
+	clipping bool          // a clip is in effect in content
+	clip     image.Rectangle
+	fonts    []*pdfFont
+	faces    map[font.Face]*pdfFont
+	images   []*image.NRGBA
+	alphas   map[uint8]int // ExtGState index by alpha
+	patterns []string      // pattern dicts
+}
+
+// pdfFont is a TrueType font embedded in the pdf
+type pdfFont struct {
+	data []byte
+	ttf  *truetype.Font
+	used map[truetype.Index]rune
+}
+
+// NewPDF returns a new PDF for recording a drawing of given pixel size
+func NewPDF(size image.Point) *PDF {
+	return &PDF{Size: size}
+}
+
+// PushOrigin moves the origin of subsequent drawing to given position,
+// relative to the current origin, clipping it to given rectangle in the
+// current coordinates -- used for drawing sub-viewports in place.  Must be
+// matched by a PopOrigin.
+func (pd *PDF) PushOrigin(pos image.Point, clip image.Rectangle) {
+	pd.unclip()
+	pd.origins = append(pd.origins, pd.origin)
+	pd.origin = pd.origin.Add(pos)
+	fmt.Fprintf(&pd.content, "q %s re W n 1 0 0 1 %d %d cm\n", pdfRect(clip), pos.X, pos.Y)
+}
+
+// PopOrigin restores the origin prior to the last PushOrigin
+func (pd *PDF) PopOrigin() {
+	sz := len(pd.origins)
+	if sz == 0 {
+		return
+	}
+	pd.unclip()
+	pd.origin = pd.origins[sz-1]
+	pd.origins = pd.origins[:sz-1]
+	pd.content.WriteString("Q\n")
+}
+
+// setClip ensures that drawing is clipped to given bounds
+func (pd *PDF) setClip(b image.Rectangle) {
+	if pd.clipping && b == pd.clip {
+		return
+	}
+	pd.unclip()
+	fmt.Fprintf(&pd.content, "q %s re W n\n", pdfRect(b))
+	pd.clipping = true
+	pd.clip = b
+}
+
+// unclip ends any current clip
+func (pd *PDF) unclip() {
+	if pd.clipping {
+		pd.content.WriteString("Q\n")
+		pd.clipping = false
+	}
+}
+
+// FillRect records filling given rectangle with given color, clipped to
+// given bounds
+func (pd *PDF) FillRect(bounds image.Rectangle, r image.Rectangle, clr color.Color) {
+	r = r.Intersect(bounds)
+	if r.Empty() || clr == nil {
+		return
+	}
+	if _, _, _, a := clr.RGBA(); a == 0 {
+		return
+	}
+	pd.setClip(bounds)
+	pd.setColor(clr, 1, false)
+	fmt.Fprintf(&pd.content, "%s re f\n", pdfRect(r))
+}
+
+// DrawImage records drawing given image, transformed by given transform
+// (with the image at the origin), clipped to given bounds
+func (pd *PDF) DrawImage(bounds image.Rectangle, img image.Image, xf mat32.Mat2) {
+	ib := img.Bounds()
+	if ib.Empty() {
+		return
+	}
+	nimg := image.NewNRGBA(image.Rectangle{Max: ib.Size()})
+	for y := 0; y < ib.Dy(); y++ {
+		for x := 0; x < ib.Dx(); x++ {
+			nimg.Set(x, y, img.At(ib.Min.X+x, ib.Min.Y+y))
+		}
+	}
+	pd.images = append(pd.images, nimg)
+	pd.setClip(bounds)
+	xf = xf.Translate(float32(ib.Min.X), float32(ib.Min.Y))
+	sz := ib.Size()
+	fmt.Fprintf(&pd.content, "q %s cm %d 0 0 %d 0 %d cm /Im%d Do Q\n", pdfMatrix(xf), sz.X, -sz.Y, sz.Y, len(pd.images)-1)
+}
+
+// fillPath records filling the current path of the render state
+func (pd *PDF) fillPath(rs *State, pc *Paint) {
+	if !pd.setColorSpec(rs, &pc.FillStyle.Color, pc.FontStyle.Opacity*pc.FillStyle.Opacity, false) {
+		return
+	}
+	pd.writePath(rs)
+	if pc.FillStyle.Rule == gist.FillRuleNonZero {
+		pd.content.WriteString("f\n")
+	} else {
+		pd.content.WriteString("f*\n")
+	}
+}
+
+// strokePath records stroking the current path of the render state, with
+// given (transformed) dashes
+func (pd *PDF) strokePath(rs *State, pc *Paint, dash []float64) {
+	if pc.StrokeWidth(rs) <= 0 {
+		return
+	}
+	if !pd.setColorSpec(rs, &pc.StrokeStyle.Color, pc.FontStyle.Opacity*pc.StrokeStyle.Opacity, true) {
+		return
+	}
+	st := &pc.StrokeStyle
+	cap := 0
+	switch st.Cap {
+	case gist.LineCapRound:
+		cap = 1
+	case gist.LineCapSquare:
+		cap = 2
+	}
+	join := 0
+	switch st.Join {
+	case gist.LineJoinRound, gist.LineJoinArcs, gist.LineJoinArcsClip:
+		join = 1
+	case gist.LineJoinBevel:
+		join = 2
+	}
+	fmt.Fprintf(&pd.content, "%s w %d J %d j %s M ", pdfNum(pc.StrokeWidth(rs)), cap, join, pdfNum(mat32.Max(st.MiterLimit, 1)))
+	if len(dash) > 0 {
+		ds := make([]string, len(dash))
+		for i, d := range dash {
+			ds[i] = pdfNum(float32(d))
+		}
+		fmt.Fprintf(&pd.content, "[%s] 0 d\n", strings.Join(ds, " "))
+	} else {
+		pd.content.WriteString("[] 0 d\n")
+	}
+	pd.writePath(rs)
+	pd.content.WriteString("S\n")
+}
+
+// writePath writes the current path of the render state
+func (pd *PDF) writePath(rs *State) {
+	rs.Path.AddTo(&pdfPath{buf: &pd.content})
+}
+
+// drawGlyph records drawing given rune in given face and color, at given
+// position and with given additional transform (rotation, scaling)
+func (pd *PDF) drawGlyph(rs *State, face font.Face, r rune, clr color.Color, pos mat32.Vec2, tx mat32.Mat2) {
+	pf, size := pd.font(face)
+	if pf == nil { // not a TrueType font: use image of glyph
+		dr, mask, maskp, _, ok := face.Glyph(pos.Fixed(), r)
+		if !ok || dr.Empty() {
+			return
+		}
+		gimg := image.NewNRGBA(image.Rectangle{Max: dr.Size()})
+		nc := color.NRGBAModel.Convert(clr).(color.NRGBA)
+		for y := 0; y < dr.Dy(); y++ {
+			for x := 0; x < dr.Dx(); x++ {
+				_, _, _, a := mask.At(maskp.X+x, maskp.Y+y).RGBA()
+				gimg.SetNRGBA(x, y, color.NRGBA{nc.R, nc.G, nc.B, uint8(uint32(nc.A) * a / 0xFFFF)})
+			}
+		}
+		pd.DrawImage(rs.Bounds, gimg, mat32.Translate2D(float32(dr.Min.X), float32(dr.Min.Y)))
+		return
+	}
+	idx := pf.ttf.Index(r)
+	if idx == 0 {
+		return
+	}
+	pf.used[idx] = r
+	pd.setClip(rs.Bounds)
+	pd.setColor(clr, 1, false)
+	// glyph space has Y up -- flip it into our Y down space
+	fmt.Fprintf(&pd.content, "BT /F%d %d Tf %s %s %s %s %s %s Tm <%04x> Tj ET\n", pd.fontIndex(pf), size,
+		pdfNum(tx.XX), pdfNum(tx.YX), pdfNum(-tx.XY), pdfNum(-tx.YY), pdfNum(pos.X), pdfNum(pos.Y), int(idx))
+}
+
+// font returns the embedded font for given face, adding it if needed, and
+// its size -- nil if it is not a TrueType font
+func (pd *PDF) font(face font.Face) (*pdfFont, int) {
+	if pd.faces == nil {
+		pd.faces = make(map[font.Face]*pdfFont)
+	}
+	ff := FontLibrary.FindFontFace(face)
+	if ff == nil {
+		return nil, 0
+	}
+	if pf, has := pd.faces[face]; has {
+		return pf, ff.Size
+	}
+	var pf *pdfFont
+	data, err := FontLibrary.FontData(ff.Name)
+	if err == nil {
+		if ttf, err := truetype.Parse(data); err == nil {
+			for _, of := range pd.fonts {
+				if bytes.Equal(of.data, data) {
+					pf = of
+					break
+				}
+			}
+			if pf == nil {
+				pf = &pdfFont{data: data, ttf: ttf, used: make(map[truetype.Index]rune)}
+				pd.fonts = append(pd.fonts, pf)
+			}
+		}
+	}
+	pd.faces[face] = pf
+	return pf, ff.Size
+}
+
+// fontIndex returns the index of given font
+func (pd *PDF) fontIndex(pf *pdfFont) int {
+	for i, f := range pd.fonts {
+		if f == pf {
+			return i
+		}
+	}
+	return 0
+}
+
+// setColor sets the fill (or stroke) color, with given additional opacity
+func (pd *PDF) setColor(clr color.Color, opacity float32, stroke bool) {
+	nc := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	op := "rg"
+	if stroke {
+		op = "RG"
+	}
+	fmt.Fprintf(&pd.content, "%s %s %s %s ", pdfNum(float32(nc.R)/255), pdfNum(float32(nc.G)/255), pdfNum(float32(nc.B)/255), op)
+	pd.setAlpha(opacity * float32(nc.A) / 255)
+}
+
+// setAlpha sets the fill and stroke alpha
+func (pd *PDF) setAlpha(alpha float32) {
+	a := uint8(mat32.Clamp(alpha, 0, 1)*255 + 0.5)
+	if pd.alphas == nil {
+		pd.alphas = make(map[uint8]int)
+	}
+	gi, has := pd.alphas[a]
+	if !has {
+		gi = len(pd.alphas)
+		pd.alphas[a] = gi
+	}
+	fmt.Fprintf(&pd.content, "/GS%d gs\n", gi)
+}
+
+// setColorSpec sets up the clip and given fill or stroke color spec, with
+// given opacity, returning false if nothing is to be drawn
+func (pd *PDF) setColorSpec(rs *State, cs *gist.ColorSpec, opacity float32, stroke bool) bool {
+	pd.setClip(rs.Bounds)
+	g := cs.Gradient
+	if cs.Source == gist.SolidColor || g == nil || len(g.Stops) < 2 {
+		clr := color.Color(cs.Color)
+		if cs.Source != gist.SolidColor && g != nil && len(g.Stops) == 1 {
+			clr = g.Stops[0].StopColor
+		}
+		if _, _, _, a := clr.RGBA(); a == 0 || opacity <= 0 {
+			return false
+		}
+		pd.setColor(clr, opacity, stroke)
+		return true
+	}
+	// gradient pattern, in the space of the pdf drawing
+	var base mat32.Mat2
+	if g.Units == rasterx.ObjectBoundingBox {
+		bb := rs.LastRenderBBox
+		base = mat32.Mat2{XX: float32(bb.Dx()), YY: float32(bb.Dy()), X0: float32(bb.Min.X), Y0: float32(bb.Min.Y)}
+	} else {
+		base = rs.XForm
+	}
+	gm := mat32.Mat2{XX: float32(g.Matrix.A), YX: float32(g.Matrix.B), XY: float32(g.Matrix.C), YY: float32(g.Matrix.D), X0: float32(g.Matrix.E), Y0: float32(g.Matrix.F)}
+	if gm == (mat32.Mat2{}) {
+		gm = mat32.Identity2D()
+	}
+	mtx := gm.Mul(base).Mul(mat32.Translate2D(float32(pd.origin.X), float32(pd.origin.Y)))
+	pt := g.Points
+	var sh string
+	if cs.Source == gist.RadialGradient {
+		sh = fmt.Sprintf("/ShadingType 3 /Coords [%s %s 0 %s %s %s]", pdfNum64(pt[2]), pdfNum64(pt[3]), pdfNum64(pt[0]), pdfNum64(pt[1]), pdfNum64(pt[4]))
+	} else {
+		sh = fmt.Sprintf("/ShadingType 2 /Coords [%s %s %s %s]", pdfNum64(pt[0]), pdfNum64(pt[1]), pdfNum64(pt[2]), pdfNum64(pt[3]))
+	}
+	pat := fmt.Sprintf("<< /PatternType 2 /Matrix [%s] /Shading << %s /ColorSpace /DeviceRGB /Function %s /Extend [true true] >> >>",
+		pdfMatrix(mtx), sh, pdfGradFunction(g.Stops))
+	pd.patterns = append(pd.patterns, pat)
+	if stroke {
+		fmt.Fprintf(&pd.content, "/Pattern CS /P%d SCN ", len(pd.patterns)-1)
+	} else {
+		fmt.Fprintf(&pd.content, "/Pattern cs /P%d scn ", len(pd.patterns)-1)
+	}
+	pd.setAlpha(opacity)
+	return true
+}
+
+// pdfGradFunction returns the pdf function for given gradient stops
+func pdfGradFunction(stops []rasterx.GradStop) string {
+	clr := func(c color.Color) string {
+		nc := color.NRGBAModel.Convert(c).(color.NRGBA)
+		return fmt.Sprintf("[%s %s %s]", pdfNum(float32(nc.R)/255), pdfNum(float32(nc.G)/255), pdfNum(float32(nc.B)/255))
+	}
+	n := len(stops)
+	fns := make([]string, n-1)
+	bnds := make([]string, 0, n-2)
+	encs := make([]string, n-1)
+	for i := 0; i < n-1; i++ {
+		fns[i] = fmt.Sprintf("<< /FunctionType 2 /Domain [0 1] /C0 %s /C1 %s /N 1 >>", clr(stops[i].StopColor), clr(stops[i+1].StopColor))
+		encs[i] = "0 1"
+		if i > 0 {
+			bnds = append(bnds, pdfNum64(stops[i].Offset))
+		}
+	}
+	st, ed := stops[0].Offset, stops[n-1].Offset
+	if ed <= st {
+		ed = st + 1
+	}
+	if n == 2 {
+		return fmt.Sprintf("<< /FunctionType 3 /Domain [%s %s] /Functions [%s] /Bounds [] /Encode [0 1] >>", pdfNum64(st), pdfNum64(ed), fns[0])
+	}
+	return fmt.Sprintf("<< /FunctionType 3 /Domain [%s %s] /Functions [%s] /Bounds [%s] /Encode [%s] >>", pdfNum64(st), pdfNum64(ed),
+		strings.Join(fns, " "), strings.Join(bnds, " "), strings.Join(encs, " "))
+}
+
+// pdfPath writes rasterx path commands as pdf path operators
+type pdfPath struct {
+	buf *bytes.Buffer
+	cur fixed.Point26_6
+}
+
+func (pp *pdfPath) Start(a fixed.Point26_6) {
+	fmt.Fprintf(pp.buf, "%s %s m\n", pdfFixed(a.X), pdfFixed(a.Y))
+	pp.cur = a
+}
+
+func (pp *pdfPath) Line(b fixed.Point26_6) {
+	fmt.Fprintf(pp.buf, "%s %s l\n", pdfFixed(b.X), pdfFixed(b.Y))
+	pp.cur = b
+}
+
+func (pp *pdfPath) QuadBezier(b, c fixed.Point26_6) {
+	// elevate to cubic
+	c1 := pp.cur.Add(b.Sub(pp.cur).Mul(fixed.I(2)).Div(fixed.I(3)))
+	c2 := c.Add(b.Sub(c).Mul(fixed.I(2)).Div(fixed.I(3)))
+	pp.CubeBezier(c1, c2, c)
+}
+
+func (pp *pdfPath) CubeBezier(b, c, d fixed.Point26_6) {
+	fmt.Fprintf(pp.buf, "%s %s %s %s %s %s c\n", pdfFixed(b.X), pdfFixed(b.Y), pdfFixed(c.X), pdfFixed(c.Y), pdfFixed(d.X), pdfFixed(d.Y))
+	pp.cur = d
+}
+
+func (pp *pdfPath) Stop(closeLoop bool) {
+	if closeLoop {
+		pp.buf.WriteString("h\n")
+	}
+}
+
+/////////////////////////////////////////////////////////////////
+//   Writing
+
+// Write writes the recorded drawing as a PDF document, laid out on pages
+// of given size in points (1/72 inch), inside of given margin in points,
+// with the drawing scaled by given number of points per pixel.  The
+// drawing is split across as many pages as are needed to show all of it,
+// going down and then across.
+func (pd *PDF) Write(w io.Writer, pageSize mat32.Vec2, margin, scale float32) error {
+	pd.unclip()
+	if pd.Size.X <= 0 || pd.Size.Y <= 0 {
+		return errors.New("girl.PDF Write: nothing has been recorded")
+	}
+	area := pageSize.SubScalar(2 * margin)
+	if area.X <= 0 || area.Y <= 0 || scale <= 0 {
+		return fmt.Errorf("girl.PDF Write: invalid page size: %v, margin: %v or scale: %v", pageSize, margin, scale)
+	}
+	ncol := int(mat32.Ceil(float32(pd.Size.X) * scale / area.X))
+	nrow := int(mat32.Ceil(float32(pd.Size.Y) * scale / area.Y))
+
+	pw := &pdfWriter{w: w}
+	pw.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+	catID := pw.alloc()
+	pagesID := pw.alloc()
+
+	fontIDs := make([]int, len(pd.fonts))
+	for i, pf := range pd.fonts {
+		fontIDs[i] = pd.writeFont(pw, pf)
+	}
+	imgIDs := make([]int, len(pd.images))
+	for i, img := range pd.images {
+		imgIDs[i] = pd.writeImage(pw, img)
+	}
+
+	var res strings.Builder
+	res.WriteString("<< /ProcSet [/PDF /Text /ImageC] ")
+	if len(fontIDs) > 0 {
+		res.WriteString("/Font <<")
+		for i, id := range fontIDs {
+			fmt.Fprintf(&res, " /F%d %d 0 R", i, id)
+		}
+		res.WriteString(" >> ")
+	}
+	if len(imgIDs) > 0 {
+		res.WriteString("/XObject <<")
+		for i, id := range imgIDs {
+			fmt.Fprintf(&res, " /Im%d %d 0 R", i, id)
+		}
+		res.WriteString(" >> ")
+	}
+	if len(pd.alphas) > 0 {
+		as := make([]int, len(pd.alphas))
+		for a, gi := range pd.alphas {
+			as[gi] = int(a)
+		}
+		res.WriteString("/ExtGState <<")
+		for gi, a := range as {
+			fmt.Fprintf(&res, " /GS%d << /ca %s /CA %s >>", gi, pdfNum(float32(a)/255), pdfNum(float32(a)/255))
+		}
+		res.WriteString(" >> ")
+	}
+	if len(pd.patterns) > 0 {
+		res.WriteString("/Pattern <<")
+		for i, p := range pd.patterns {
+			fmt.Fprintf(&res, " /P%d %s", i, p)
+		}
+		res.WriteString(" >> ")
+	}
+	res.WriteString(">>")
+
+	formID := pw.alloc()
+	pw.stream(formID, fmt.Sprintf("/Type /XObject /Subtype /Form /BBox [0 0 %d %d] /Resources %s", pd.Size.X, pd.Size.Y, res.String()), pd.content.Bytes())
+
+	var kids []string
+	for col := 0; col < ncol; col++ {
+		for row := 0; row < nrow; row++ {
+			var pc bytes.Buffer
+			fmt.Fprintf(&pc, "q %s %s %s %s re W n\n", pdfNum(margin), pdfNum(margin), pdfNum(area.X), pdfNum(area.Y))
+			fmt.Fprintf(&pc, "%s 0 0 %s %s %s cm /Fm0 Do Q\n", pdfNum(scale), pdfNum(-scale),
+				pdfNum(margin-float32(col)*area.X), pdfNum(pageSize.Y-margin+float32(row)*area.Y))
+			cid := pw.alloc()
+			pw.stream(cid, "", pc.Bytes())
+			pid := pw.alloc()
+			pw.object(pid, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /XObject << /Fm0 %d 0 R >> >> /Contents %d 0 R >>",
+				pagesID, pdfNum(pageSize.X), pdfNum(pageSize.Y), formID, cid))
+			kids = append(kids, fmt.Sprintf("%d 0 R", pid))
+		}
+	}
+	pw.object(pagesID, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	pw.object(catID, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesID))
+	pw.finish(catID)
+	return pw.err
+}
+
+// writeFont writes given font as a Type0 font with Identity-H encoding,
+// returning the object id of the font
+func (pd *PDF) writeFont(pw *pdfWriter, pf *pdfFont) int {
+	ttf := pf.ttf
+	upem := ttf.FUnitsPerEm()
+	scl := func(v fixed.Int26_6) int {
+		return int(int32(v) * 1000 / upem)
+	}
+	name := strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || strings.ContainsRune("()<>[]{}/%#", r) {
+			return -1
+		}
+		return r
+	}, ttf.Name(truetype.NameIDPostscriptName))
+	if name == "" {
+		name = fmt.Sprintf("Font%d", pd.fontIndex(pf))
+	}
+
+	gids := make([]int, 0, len(pf.used))
+	for idx := range pf.used {
+		gids = append(gids, int(idx))
+	}
+	sort.Ints(gids)
+	var wds strings.Builder
+	var cmap strings.Builder
+	for i, gid := range gids {
+		hm := ttf.HMetric(fixed.Int26_6(upem), truetype.Index(gid))
+		fmt.Fprintf(&wds, "%d [%d] ", gid, scl(hm.AdvanceWidth))
+		if i%100 == 0 {
+			n := len(gids) - i
+			if n > 100 {
+				n = 100
+			}
+			if i > 0 {
+				cmap.WriteString("endbfchar\n")
+			}
+			fmt.Fprintf(&cmap, "%d beginbfchar\n", n)
+		}
+		fmt.Fprintf(&cmap, "<%04x> <", gid)
+		for _, u := range utf16Units(pf.used[truetype.Index(gid)]) {
+			fmt.Fprintf(&cmap, "%04x", u)
+		}
+		cmap.WriteString(">\n")
+	}
+	if len(gids) > 0 {
+		cmap.WriteString("endbfchar\n")
+	}
+
+	fileID := pw.alloc()
+	pw.stream(fileID, fmt.Sprintf("/Length1 %d", len(pf.data)), pf.data)
+	bb := ttf.Bounds(fixed.Int26_6(upem))
+	descID := pw.alloc()
+	pw.object(descID, fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		name, scl(bb.Min.X), scl(bb.Min.Y), scl(bb.Max.X), scl(bb.Max.Y), scl(bb.Max.Y), scl(bb.Min.Y), scl(bb.Max.Y), fileID))
+	cidID := pw.alloc()
+	pw.object(cidID, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /CIDToGIDMap /Identity /W [%s] >>",
+		name, descID, wds.String()))
+	tuID := pw.alloc()
+	pw.stream(tuID, "", []byte("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n"+
+		cmap.String()+"endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n"))
+	fontID := pw.alloc()
+	pw.object(fontID, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		name, cidID, tuID))
+	return fontID
+}
+
+// writeImage writes given image as an RGB image with an alpha soft mask if
+// needed, returning the object id of the image
+func (pd *PDF) writeImage(pw *pdfWriter, img *image.NRGBA) int {
+	sz := img.Rect.Size()
+	rgb := make([]byte, 0, sz.X*sz.Y*3)
+	alpha := make([]byte, 0, sz.X*sz.Y)
+	opaque := true
+	for i := 0; i < len(img.Pix); i += 4 {
+		rgb = append(rgb, img.Pix[i], img.Pix[i+1], img.Pix[i+2])
+		alpha = append(alpha, img.Pix[i+3])
+		if img.Pix[i+3] != 0xFF {
+			opaque = false
+		}
+	}
+	smask := ""
+	if !opaque {
+		mid := pw.alloc()
+		pw.stream(mid, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray /BitsPerComponent 8", sz.X, sz.Y), alpha)
+		smask = fmt.Sprintf(" /SMask %d 0 R", mid)
+	}
+	id := pw.alloc()
+	pw.stream(id, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8%s", sz.X, sz.Y, smask), rgb)
+	return id
+}
+
+// pdfWriter writes pdf objects, keeping track of their offsets
+type pdfWriter struct {
+	w       io.Writer
+	n       int
+	offsets []int
+	err     error
+}
+
+func (pw *pdfWriter) printf(format string, args ...interface{}) {
+	if pw.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(pw.w, format, args...)
+	pw.n += n
+	pw.err = err
+}
+
+// alloc allocates a new object id
+func (pw *pdfWriter) alloc() int {
+	pw.offsets = append(pw.offsets, 0)
+	return len(pw.offsets)
+}
+
+// object writes object of given id
+func (pw *pdfWriter) object(id int, obj string) {
+	pw.offsets[id-1] = pw.n
+	pw.printf("%d 0 obj\n%s\nendobj\n", id, obj)
+}
+
+// stream writes a compressed stream object of given id, with given
+// additional dictionary entries
+func (pw *pdfWriter) stream(id int, dict string, data []byte) {
+	var zb bytes.Buffer
+	zw := zlib.NewWriter(&zb)
+	zw.Write(data)
+	zw.Close()
+	pw.offsets[id-1] = pw.n
+	pw.printf("%d 0 obj\n<< %s /Length %d /Filter /FlateDecode >>\nstream\n", id, dict, zb.Len())
+	if pw.err == nil {
+		n, err := pw.w.Write(zb.Bytes())
+		pw.n += n
+		pw.err = err
+	}
+	pw.printf("\nendstream\nendobj\n")
+}
+
+// finish writes the cross-reference table and trailer
+func (pw *pdfWriter) finish(rootID int) {
+	xref := pw.n
+	pw.printf("xref\n0 %d\n0000000000 65535 f \n", len(pw.offsets)+1)
+	for _, off := range pw.offsets {
+		pw.printf("%010d 00000 n \n", off)
+	}
+	pw.printf("trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(pw.offsets)+1, rootID, xref)
+}
+
+// utf16Units returns the utf-16 encoding of given rune
+func utf16Units(r rune) []uint16 {
+	if r < 0x10000 {
+		return []uint16{uint16(r)}
+	}
+	r -= 0x10000
+	return []uint16{uint16(0xD800 + (r>>10)&0x3FF), uint16(0xDC00 + r&0x3FF)}
+}
+
+// pdfNum formats given number for pdf output
+func pdfNum(v float32) string {
+	return pdfNum64(float64(v))
+}
+
+// pdfNum64 formats given number for pdf output
+func pdfNum64(v float64) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return "0"
+	}
+	s := strconv.FormatFloat(v, 'f', 3, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
+
+// pdfFixed formats given fixed-point number for pdf output
+func pdfFixed(v fixed.Int26_6) string {
+	return pdfNum64(float64(v) / 64)
+}
+
+// pdfRect formats given rectangle as x y w h
+func pdfRect(r image.Rectangle) string {
+	return fmt.Sprintf("%d %d %d %d", r.Min.X, r.Min.Y, r.Dx(), r.Dy())
+}
+
+// pdfMatrix formats given matrix as a b c d e f
+func pdfMatrix(m mat32.Mat2) string {
+	return strings.Join([]string{pdfNum(m.XX), pdfNum(m.YX), pdfNum(m.XY), pdfNum(m.YY), pdfNum(m.X0), pdfNum(m.Y0)}, " ")
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package girl
+
+import (
+	"image"
+	"math/bits"
+	"sync"
+)
+
+// The pixel buffers of images that are rendered into, e.g., the Pixels of
+// a Viewport2D and clipping masks, are kept in pools and reused across
+// frames, instead of allocating new ones each time a viewport is resized
+// or a popup is opened, which otherwise causes long GC pauses in complex
+// windows.  The buffers are bucketed by size in powers of 2, so a buffer
+// can be reused for any image that fits in it.
+
+// PoolMinBuf is the smallest size in bytes of the pooled buffers -- smaller
+// images use buffers of this size
+const PoolMinBuf = 4096
+
+// bufPools are the pools of buffers of each power-of-2 size, holding *[]uint8
+var bufPools [64]sync.Pool
+
+// poolBucket returns the index of the pool for buffers of given size
+func poolBucket(n int) int {
+	if n < PoolMinBuf {
+		n = PoolMinBuf
+	}
+	return bits.Len(uint(n - 1))
+}
+
+// getBuf returns a zeroed buffer of given size from the pools, allocating
+// a new one if there is none
+func getBuf(n int) []uint8 {
+	b := poolBucket(n)
+	if bp, ok := bufPools[b].Get().(*[]uint8); ok {
+		buf := (*bp)[:n]
+		for i := range buf {
+			buf[i] = 0
+		}
+		return buf
+	}
+	return make([]uint8, n, 1<<uint(b))
+}
+
+// putBuf returns given buffer to the pools -- only buffers from getBuf
+// are kept, as the others may not fill their bucket
+func putBuf(buf []uint8) {
+	c := cap(buf)
+	if c < PoolMinBuf || c&(c-1) != 0 {
+		return
+	}
+	buf = buf[:c]
+	bufPools[poolBucket(c)].Put(&buf)
+}
+
+// GetRGBA returns a new transparent image of given size, using a pooled
+// buffer if possible -- it is the same as image.NewRGBA, but the image
+// should be released with PutRGBA when it is no longer used
+func GetRGBA(sz image.Point) *image.RGBA {
+	r := image.Rectangle{Max: sz}
+	return &image.RGBA{Pix: getBuf(4 * sz.X * sz.Y), Stride: 4 * sz.X, Rect: r}
+}
+
+// PutRGBA releases given image, from GetRGBA, for reuse -- it must not be
+// used after this, so it should only be called by the owner of the image
+func PutRGBA(img *image.RGBA) {
+	if img == nil {
+		return
+	}
+	putBuf(img.Pix)
+	img.Pix = nil
+}
+
+// GetAlpha returns a new transparent alpha image with given bounds, e.g.,
+// for a clipping mask, using a pooled buffer if possible -- it is the same
+// as image.NewAlpha, but the image should be released with PutAlpha when it
+// is no longer used
+func GetAlpha(r image.Rectangle) *image.Alpha {
+	w, h := r.Dx(), r.Dy()
+	return &image.Alpha{Pix: getBuf(w * h), Stride: w, Rect: r}
+}
+
+// PutAlpha releases given image, from GetAlpha, for reuse -- it must not be
+// used after this, so it should only be called by the owner of the image
+func PutAlpha(img *image.Alpha) {
+	if img == nil {
+		return
+	}
+	putBuf(img.Pix)
+	img.Pix = nil
+}
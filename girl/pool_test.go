@@ -0,0 +1,46 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package girl
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestImagePool(t *testing.T) {
+	for _, sz := range []image.Point{{0, 0}, {3, 5}, {100, 70}, {640, 480}, {650, 470}} {
+		img := GetRGBA(sz)
+		ref := image.NewRGBA(image.Rectangle{Max: sz})
+		if img.Bounds() != ref.Bounds() || img.Stride != ref.Stride || len(img.Pix) != len(ref.Pix) {
+			t.Errorf("size %v: pooled image: %v %d %d != new image: %v %d %d", sz, img.Bounds(), img.Stride, len(img.Pix), ref.Bounds(), ref.Stride, len(ref.Pix))
+		}
+		draw.Draw(img, img.Bounds(), &image.Uniform{color.White}, image.ZP, draw.Src)
+		PutRGBA(img)
+		if img.Pix != nil {
+			t.Errorf("size %v: released image still has pixels", sz)
+		}
+		img = GetRGBA(sz) // typically reuses the buffer just released, which must be cleared
+		for i, p := range img.Pix {
+			if p != 0 {
+				t.Errorf("size %v: reused image not cleared at: %d", sz, i)
+				break
+			}
+		}
+		PutRGBA(img)
+	}
+	r := image.Rect(10, 20, 110, 70)
+	mask := GetAlpha(r)
+	if mask.Bounds() != r || mask.Stride != r.Dx() || len(mask.Pix) != r.Dx()*r.Dy() {
+		t.Errorf("pooled alpha: %v %d %d", mask.Bounds(), mask.Stride, len(mask.Pix))
+	}
+	mask.SetAlpha(109, 69, color.Alpha{0xff})
+	if mask.AlphaAt(109, 69).A != 0xff {
+		t.Errorf("pooled alpha pixel not set")
+	}
+	PutAlpha(mask)
+	PutRGBA(&image.RGBA{Pix: make([]uint8, 5000)}) // not from pool: ignored
+}
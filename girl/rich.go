@@ -0,0 +1,277 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package girl
+
+import (
+	"html"
+	"image"
+	"os"
+	"regexp"
+	"strconv"
+
+	"github.com/goki/gi/units"
+	"github.com/goki/mat32"
+	"golang.org/x/image/draw"
+)
+
+// rich.go has the support for the rich-text elements of the HTML subset
+// handled by SetHTMLNoPre, beyond the inline text styling: inline images
+// (<img>), nested lists (<ul>, <ol>, <li>), simple tables (<table>, <tr>,
+// <td>, <th>), and syntax-highlighted code spans (<code lang="go">).  Each
+// list item and table row is its own Span (line): list items are indented
+// according to their nesting level and start with a bullet or number, and
+// the cells of the rows of a table are aligned into columns.
+
+// ObjectRune is the rune (Unicode object replacement character) that is
+// used in the text in place of an inline image
+const ObjectRune = '￼'
+
+// ListBullets are the bullets used for the items of unordered lists, by
+// nesting level (repeating for deeper levels)
+var ListBullets = []rune{'•', '◦', '▪'}
+
+// ListIndent is the indent of each nesting level of lists, in Em units
+var ListIndent = float32(1.5)
+
+// TableCellPad is the padding between table columns, in Em units
+var TableCellPad = float32(1)
+
+// TextImage is an inline image displayed within text, in place of an
+// ObjectRune in the Span, with its bottom on the text baseline
+type TextImage struct {
+	Src   string      `desc:"source of the image, from the src attribute"`
+	Alt   string      `desc:"alternative text, from the alt attribute"`
+	Image image.Image `json:"-" xml:"-" desc:"the image, or nil if it could not be loaded -- the Size is still used"`
+	Size  mat32.Vec2  `desc:"size of the image as displayed, in dots"`
+}
+
+// TextImageLoaderFunc is a function that loads the image for the src
+// attribute of an <img> element in text
+type TextImageLoaderFunc func(src string) (image.Image, error)
+
+// TextImageLoader is used to load the images of <img> elements in text --
+// the default opens src as an image file (in any registered format) --
+// replace to load from other sources (e.g., embedded resources or URLs)
+var TextImageLoader TextImageLoaderFunc = OpenTextImage
+
+// OpenTextImage opens an image file, for the default TextImageLoader
+func OpenTextImage(src string) (image.Image, error) {
+	fp, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+	img, _, err := image.Decode(fp)
+	return img, err
+}
+
+// NewTextImage returns a new TextImage for given <img> element attributes
+// (src, alt, width, height) -- the image is loaded using TextImageLoader,
+// and sized according to the width and / or height attributes (keeping
+// the aspect ratio if only one is given), else at its natural size --
+// images that could not be loaded are the size of a character of the
+// given font height
+func NewTextImage(attrs map[string]string, ctxt *units.Context, fht float32) *TextImage {
+	ti := &TextImage{Src: attrs["src"], Alt: attrs["alt"]}
+	if TextImageLoader != nil && ti.Src != "" {
+		ti.Image, _ = TextImageLoader(ti.Src)
+	}
+	var nat mat32.Vec2
+	if ti.Image != nil {
+		isz := ti.Image.Bounds().Size()
+		nat = mat32.NewVec2(ctxt.PxToDots(float32(isz.X)), ctxt.PxToDots(float32(isz.Y)))
+	} else {
+		nat = mat32.NewVec2(fht, fht)
+	}
+	sz := nat
+	dim := func(attr string) float32 {
+		str := attrs[attr]
+		if str == "" {
+			return 0
+		}
+		uv := units.StringToValue(str)
+		return uv.ToDots(ctxt)
+	}
+	wd, ht := dim("width"), dim("height")
+	switch {
+	case wd > 0 && ht > 0:
+		sz = mat32.NewVec2(wd, ht)
+	case wd > 0 && nat.X > 0:
+		sz = mat32.NewVec2(wd, nat.Y*wd/nat.X)
+	case ht > 0 && nat.Y > 0:
+		sz = mat32.NewVec2(nat.X*ht/nat.Y, ht)
+	}
+	ti.Size = sz
+	return ti
+}
+
+// Render renders the image with its lower-left corner at given position
+// (the text baseline)
+func (ti *TextImage) Render(rs *State, rp mat32.Vec2) {
+	if ti.Image == nil || ti.Size.X <= 0 || ti.Size.Y <= 0 {
+		return
+	}
+	min := mat32.NewVec2(rp.X, rp.Y-ti.Size.Y)
+	dr := image.Rectangle{Min: min.ToPointFloor(), Max: min.Add(ti.Size).ToPointCeil()}
+	if !dr.Overlaps(rs.Bounds) {
+		return
+	}
+	sb := ti.Image.Bounds()
+	if dr.Size() == sb.Size() {
+		draw.Draw(rs.Image, dr.Intersect(rs.Bounds), ti.Image, sb.Min.Add(dr.Intersect(rs.Bounds).Min.Sub(dr.Min)), draw.Over)
+	} else {
+		dst := rs.Image.SubImage(rs.Bounds).(*image.RGBA)
+		draw.ApproxBiLinear.Scale(dst, dr, ti.Image, sb, draw.Over, nil)
+	}
+	if rs.PDF != nil {
+		sc := mat32.NewVec2(ti.Size.X/float32(sb.Dx()), ti.Size.Y/float32(sb.Dy()))
+		rs.PDF.DrawImage(rs.Bounds, ti.Image, mat32.Translate2D(min.X, min.Y).Scale(sc.X, sc.Y).Translate(-float32(sb.Min.X), -float32(sb.Min.Y)))
+	}
+}
+
+// ImageAscent returns the maximum height of the inline images in the span
+// above the baseline (0 if none)
+func (sr *Span) ImageAscent() float32 {
+	asc := float32(0)
+	for i := range sr.Render {
+		rr := &sr.Render[i]
+		if rr.Image != nil && rr.Image.Size.Y-rr.RelPos.Y > asc {
+			asc = rr.Image.Size.Y - rr.RelPos.Y
+		}
+	}
+	return asc
+}
+
+// HasImages returns true if the text has any inline images
+func (tr *Text) HasImages() bool {
+	for si := range tr.Spans {
+		for ri := range tr.Spans[si].Render {
+			if tr.Spans[si].Render[ri].Image != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ListItemPrefix returns the bullet or number (with a trailing space)
+// for the item of a list at given nesting level (0 = top), ordered or not,
+// at given item number (starting at 1)
+func ListItemPrefix(level int, ordered bool, num int) string {
+	if ordered {
+		return strconv.Itoa(num) + ". "
+	}
+	return string(ListBullets[level%len(ListBullets)]) + " "
+}
+
+// LayoutTables aligns the cells of the rows of each table into columns,
+// separated by given padding, after the rune positions have been set --
+// returns the maximum width of the rows
+func (tr *Text) LayoutTables(pad float32) float32 {
+	maxw := float32(0)
+	ntbl := 0
+	for si := range tr.Spans {
+		if tr.Spans[si].Table > ntbl {
+			ntbl = tr.Spans[si].Table
+		}
+	}
+	// start and end rune indexes, and natural width, of given cell
+	cell := func(sr *Span, ci int) (st, ed int, wd float32) {
+		st = sr.Cells[ci]
+		ed = len(sr.Render)
+		if ci < len(sr.Cells)-1 {
+			ed = sr.Cells[ci+1]
+		}
+		if st >= ed || ed > len(sr.Render) {
+			return st, st, 0
+		}
+		lr := &sr.Render[ed-1]
+		return st, ed, lr.RelPos.X + lr.Size.X - sr.Render[st].RelPos.X
+	}
+	for ti := 1; ti <= ntbl; ti++ {
+		var colw []float32
+		for si := range tr.Spans {
+			sr := &tr.Spans[si]
+			if sr.Table != ti || sr.IsValid() != nil {
+				continue
+			}
+			for ci := range sr.Cells {
+				_, _, wd := cell(sr, ci)
+				if ci >= len(colw) {
+					colw = append(colw, 0)
+				}
+				if wd > colw[ci] {
+					colw[ci] = wd
+				}
+			}
+		}
+		for si := range tr.Spans {
+			sr := &tr.Spans[si]
+			if sr.Table != ti || sr.IsValid() != nil {
+				continue
+			}
+			cx := float32(0)
+			nc := len(sr.Cells)
+			for ci := 0; ci < nc; ci++ {
+				st, ed, _ := cell(sr, ci)
+				if st < ed {
+					off := cx - sr.Render[st].RelPos.X
+					for ri := st; ri < ed; ri++ {
+						sr.Render[ri].RelPos.X += off
+					}
+				}
+				cx += colw[ci]
+				if ci < nc-1 {
+					cx += pad
+				}
+			}
+			if nc > 0 && len(sr.Render) > 0 {
+				last := &sr.Render[len(sr.Render)-1]
+				sr.LastPos.X = last.RelPos.X + last.Size.X
+			}
+			if sr.LastPos.X > maxw {
+				maxw = sr.LastPos.X
+			}
+		}
+	}
+	return maxw
+}
+
+// richTagRe matches the start tags of the rich-text elements
+var richTagRe = regexp.MustCompile(`(?i)<(img|ul|ol|table)[\s>/]`)
+
+// HasRichHTML returns true if given HTML has any inline images, lists or
+// tables, which are only handled by SetHTMLNoPre
+func HasRichHTML(str []byte) bool {
+	return richTagRe.Match(str)
+}
+
+// CodeMarkupFunc returns the syntax-highlighted HTML markup of given code
+// in given language (e.g., "go", "python"), using <span> elements with
+// style attributes according to the current highlighting style -- it is
+// set by the giv package, which has the highlighting support
+type CodeMarkupFunc func(code, lang string) string
+
+// CodeMarkup is used to syntax-highlight <code> elements that specify a
+// language, with a lang="go" or class="language-go" attribute, if non-nil
+var CodeMarkup CodeMarkupFunc
+
+// codeLangRe matches <code> elements with a language attribute
+var codeLangRe = regexp.MustCompile(`(?is)<code\s+(?:lang|class)="(?:language-)?([\w+#.-]+)"\s*>(.*?)</code>`)
+
+// HTMLCodeMarkup replaces the contents of any <code> elements in given
+// HTML that specify a language with their syntax-highlighted markup,
+// using CodeMarkup -- returns the HTML as-is if there are none, or no
+// CodeMarkup function
+func HTMLCodeMarkup(str []byte) []byte {
+	if CodeMarkup == nil || !codeLangRe.Match(str) {
+		return str
+	}
+	return codeLangRe.ReplaceAllFunc(str, func(cd []byte) []byte {
+		sm := codeLangRe.FindSubmatch(cd)
+		mu := CodeMarkup(html.UnescapeString(string(sm[2])), string(sm[1]))
+		return []byte("<code>" + mu + "</code>")
+	})
+}
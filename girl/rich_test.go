@@ -0,0 +1,107 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package girl
+
+import (
+	"image"
+	"testing"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/units"
+	"github.com/goki/mat32"
+)
+
+func richTestSetup() (*gist.Font, *gist.Text, *units.Context) {
+	prefs := &TestPrefs{}
+	prefs.Defaults()
+	gist.ThePrefs = prefs
+	FontLibrary.InitFontPaths("/usr/share/fonts/truetype")
+	tsty := &gist.Text{}
+	tsty.Defaults()
+	fsty := &gist.Font{}
+	fsty.Defaults()
+	ctxt := &units.Context{}
+	ctxt.Defaults()
+	return fsty, tsty, ctxt
+}
+
+func TestHTMLLists(t *testing.T) {
+	fsty, tsty, ctxt := richTestSetup()
+	txt := &Text{}
+	txt.SetHTML("Items: <ul> <li>one</li> <li>two <ol start=\"3\"> <li>sub</li> </ol></li> </ul> after", fsty, tsty, ctxt, nil)
+	exp := []string{"Items: ", "• one", "• two ", "3. sub", "after"}
+	if len(txt.Spans) != len(exp) {
+		for _, sr := range txt.Spans {
+			t.Logf("%q", string(sr.Text))
+		}
+		t.Fatalf("spans: %v != expected: %v", len(txt.Spans), len(exp))
+	}
+	for i, ex := range exp {
+		if str := string(txt.Spans[i].Text); str != ex {
+			t.Errorf("span %v: %q != expected: %q", i, str, ex)
+		}
+	}
+	if txt.Spans[1].Indent != 0 || txt.Spans[3].Indent <= 0 {
+		t.Errorf("bad list indents: %v %v", txt.Spans[1].Indent, txt.Spans[3].Indent)
+	}
+	if txt.Spans[3].Hang != 3 {
+		t.Errorf("hang for ordered item: %v != 3", txt.Spans[3].Hang)
+	}
+}
+
+func TestHTMLTable(t *testing.T) {
+	fsty, tsty, ctxt := richTestSetup()
+	txt := &Text{}
+	txt.SetHTML("<table> <tr><th>Name</th> <td>Value</td></tr> <tr><td>a much longer name</td><td>x</td></tr> </table>", fsty, tsty, ctxt, nil)
+	if len(txt.Spans) != 2 {
+		t.Fatalf("spans: %v != 2", len(txt.Spans))
+	}
+	for i := range txt.Spans {
+		sr := &txt.Spans[i]
+		if sr.Table != 1 || len(sr.Cells) != 2 {
+			t.Errorf("row %v: table: %v cells: %v", i, sr.Table, sr.Cells)
+		}
+	}
+	txt.LayoutStdLR(tsty, fsty, ctxt, mat32.Vec2{})
+	x0 := txt.Spans[0].Render[txt.Spans[0].Cells[1]].RelPos.X
+	x1 := txt.Spans[1].Render[txt.Spans[1].Cells[1]].RelPos.X
+	if x0 != x1 {
+		t.Errorf("second column not aligned: %v != %v", x0, x1)
+	}
+	txt.LayoutStdLR(tsty, fsty, ctxt, mat32.Vec2{})
+	if x := txt.Spans[0].Render[txt.Spans[0].Cells[1]].RelPos.X; x != x0 {
+		t.Errorf("table layout changed on re-layout: %v != %v", x, x0)
+	}
+}
+
+func TestHTMLImage(t *testing.T) {
+	fsty, tsty, ctxt := richTestSetup()
+	prvLoader := TextImageLoader
+	defer func() { TextImageLoader = prvLoader }()
+	TextImageLoader = func(src string) (image.Image, error) {
+		return image.NewRGBA(image.Rect(0, 0, 10, 40)), nil
+	}
+	txt := &Text{}
+	txt.SetHTML(`a <a href="x"><img src="tall.png" alt="tall" height="80px"></a> b`, fsty, tsty, ctxt, nil)
+	txt.LayoutStdLR(tsty, fsty, ctxt, mat32.Vec2{})
+	sr := &txt.Spans[0]
+	ii := 2
+	if sr.Text[ii] != ObjectRune || sr.Render[ii].Image == nil {
+		t.Fatalf("no image at %v in %q", ii, string(sr.Text))
+	}
+	im := sr.Render[ii].Image
+	if im.Size.Y != 4*im.Size.X {
+		t.Errorf("image aspect ratio not kept: %v", im.Size)
+	}
+	if sr.Render[ii+1].RelPos.X < sr.Render[ii].RelPos.X+im.Size.X {
+		t.Errorf("image overlaps next rune")
+	}
+	if txt.Size.Y < im.Size.Y {
+		t.Errorf("text height: %v is less than image height: %v", txt.Size.Y, im.Size.Y)
+	}
+	if len(txt.Links) != 1 || txt.Links[0].Label != "tall" || txt.Links[0].EndIdx != ii+1 {
+		t.Errorf("image link: %+v", txt.Links)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"image/color"
 
 	"github.com/goki/gi/gist"
+	"github.com/goki/ki/bitflag"
 	"github.com/goki/mat32"
 	"golang.org/x/image/font"
 )
@@ -19,14 +20,17 @@ import (
 // those pointers -- float32 values used to support better accuracy when
 // transforming points
 type Rune struct {
-	Face    font.Face            `json:"-" xml:"-" desc:"fully-specified font rendering info, includes fully computed font size -- this is exactly what will be drawn -- no further transforms"`
-	Color   color.Color          `json:"-" xml:"-" desc:"color to draw characters in"`
-	BgColor color.Color          `json:"-" xml:"-" desc:"background color to fill background of color -- for highlighting, <mark> tag, etc -- unlike Face, Color, this must be non-nil for every case that uses it, as nil is also used for default transparent background"`
-	Deco    gist.TextDecorations `desc:"additional decoration to apply -- underline, strike-through, etc -- also used for encoding a few special layout hints to pass info from styling tags to separate layout algorithms (e.g., &lt;P&gt; vs &lt;BR&gt;)"`
-	RelPos  mat32.Vec2           `desc:"relative position from start of Text for the lower-left baseline rendering position of the font character"`
-	Size    mat32.Vec2           `desc:"size of the rune itself, exclusive of spacing that might surround it"`
-	RotRad  float32              `desc:"rotation in radians for this character, relative to its lower-left baseline rendering position"`
-	ScaleX  float32              `desc:"scaling of the X dimension, in case of non-uniform scaling, 0 = no separate scaling"`
+	Face      font.Face            `json:"-" xml:"-" desc:"fully-specified font rendering info, includes fully computed font size -- this is exactly what will be drawn -- no further transforms"`
+	Color     color.Color          `json:"-" xml:"-" desc:"color to draw characters in"`
+	BgColor   color.Color          `json:"-" xml:"-" desc:"background color to fill background of color -- for highlighting, <mark> tag, etc -- unlike Face, Color, this must be non-nil for every case that uses it, as nil is also used for default transparent background"`
+	DecoColor color.Color          `json:"-" xml:"-" desc:"color of decorations (underline, etc) -- nil = use Color -- like BgColor, this must be set for every rune that uses it"`
+	Deco      gist.TextDecorations `desc:"additional decoration to apply -- underline, strike-through, etc -- also used for encoding a few special layout hints to pass info from styling tags to separate layout algorithms (e.g., &lt;P&gt; vs &lt;BR&gt;)"`
+	RelPos    mat32.Vec2           `desc:"relative position from start of Text for the lower-left baseline rendering position of the font character"`
+	Size      mat32.Vec2           `desc:"size of the rune itself, exclusive of spacing that might surround it"`
+	RotRad    float32              `desc:"rotation in radians for this character, relative to its lower-left baseline rendering position"`
+	ScaleX    float32              `desc:"scaling of the X dimension, in case of non-uniform scaling, 0 = no separate scaling"`
+	Glyph     rune                 `desc:"glyph to draw for this rune, set by text shaping (e.g., the contextual form of an Arabic letter, or a mirrored bracket in right-to-left text) -- 0 = the rune itself, GlyphNone = nothing, as it is part of the previous glyph"`
+	Image     *TextImage           `json:"-" xml:"-" desc:"inline image displayed in place of this rune (an ObjectRune), from an <img> element"`
 }
 
 // HasNil returns error if any of the key info (face, color) is nil -- only
@@ -58,6 +62,22 @@ func (rr *Rune) CurColor(curColor color.Color) color.Color {
 	return curColor
 }
 
+// UnderlineDeco returns the underline decoration to use for this rune:
+// DecoNone if no underline, and the most specific style if multiple are set
+func (rr *Rune) UnderlineDeco() gist.TextDecorations {
+	switch {
+	case bitflag.Has32(int32(rr.Deco), int(gist.DecoWavyUnderline)):
+		return gist.DecoWavyUnderline
+	case bitflag.Has32(int32(rr.Deco), int(gist.DecoDashedUnderline)):
+		return gist.DecoDashedUnderline
+	case bitflag.Has32(int32(rr.Deco), int(gist.DecoDottedUnderline)):
+		return gist.DecoDottedUnderline
+	case bitflag.Has32(int32(rr.Deco), int(gist.DecoUnderline)):
+		return gist.DecoUnderline
+	}
+	return gist.DecoNone
+}
+
 // RelPosAfterLR returns the relative position after given rune for LR order: RelPos.X + Size.X
 func (rr *Rune) RelPosAfterLR() float32 {
 	return rr.RelPos.X + rr.Size.X
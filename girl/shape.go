@@ -0,0 +1,271 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package girl
+
+import (
+	"log"
+	"unicode"
+
+	"golang.org/x/image/font"
+)
+
+// Text shaping selects the glyph to display for each rune according to its
+// context.  The fonts are rendered directly from their glyph outlines,
+// without the OpenType substitution (GSUB) and positioning (GPOS) tables,
+// so shaping is done here for the cases that can be handled with the
+// glyphs that are directly encoded in the fonts:
+//
+// * Arabic (and Persian / Urdu) letters are joined using their contextual
+//   (isolated, final, initial, medial) presentation forms, including the
+//   mandatory lam-alef ligatures, if the font has them.
+//
+// * Combining marks (e.g., Arabic harakat, Hebrew points, Indic vowel signs)
+//   are positioned over the preceding base rune, without advancing.
+//
+// This is deliberately the scope of shaping here: it is not a general
+// OpenType shaping engine (like HarfBuzz), which needs GSUB / GPOS lookups
+// in the font rendering.  In particular:
+//
+// * Indic scripts (Devanagari, Bengali, Tamil, etc.), and others that
+//   require reordering, conjuncts or other substitutions within syllables
+//   (e.g., Khmer, Myanmar, Sinhala), are NOT supported: they are split out
+//   as a separate follow-up, which needs the GSUB / GPOS lookups.  Their
+//   text is shown in logical order, with the marks positioned as above, so
+//   pre-base vowel signs appear after their consonant, and conjuncts are
+//   shown as separate consonants with virama -- see UnshapedScripts, which
+//   are reported once when laid out.
+//
+// * Arabic is limited to the presentation forms above, so letters are only
+//   joined with fonts that have glyphs for the Arabic Presentation Forms-B
+//   runes (e.g., DejaVu Sans) -- fonts that only provide their contextual
+//   forms through GSUB show isolated letters.  Optional ligatures and the
+//   GPOS positioning of marks (e.g., stacked harakat) are not supported.
+//
+// * Hebrew and other scripts that need only bidi reordering (see bidi.go)
+//   and mark positioning render correctly.
+
+// ShapingOn determines whether text shaping is done
+var ShapingOn = true
+
+// UnshapedScripts are the scripts whose text needs shaping that is not
+// supported (see above), so it is shown without shaping -- SetRunePosLR
+// logs a message the first time text in each of them is laid out
+var UnshapedScripts = []*unicode.RangeTable{unicode.Devanagari, unicode.Bengali, unicode.Gurmukhi, unicode.Gujarati, unicode.Oriya, unicode.Tamil, unicode.Telugu, unicode.Kannada, unicode.Malayalam, unicode.Sinhala, unicode.Tibetan, unicode.Myanmar, unicode.Khmer}
+
+// unshapedLogged records the UnshapedScripts that have been logged --
+// protected by TextFontRenderMu
+var unshapedLogged = map[*unicode.RangeTable]bool{}
+
+// UnshapedScript returns the first of the UnshapedScripts of given text, or
+// nil if the text does not need unsupported shaping
+func UnshapedScript(txt []rune) *unicode.RangeTable {
+	for _, r := range txt {
+		if r < 0x0900 { // before the first of them
+			continue
+		}
+		for _, sc := range UnshapedScripts {
+			if unicode.Is(sc, r) {
+				return sc
+			}
+		}
+	}
+	return nil
+}
+
+// scriptName returns the name of given script in unicode.Scripts
+func scriptName(sc *unicode.RangeTable) string {
+	for nm, s := range unicode.Scripts {
+		if s == sc {
+			return nm
+		}
+	}
+	return "unknown"
+}
+
+// logUnshaped logs the first time that text in one of the UnshapedScripts
+// is laid out -- must be called under TextFontRenderMu
+func logUnshaped(txt []rune) {
+	sc := UnshapedScript(txt)
+	if sc == nil || unshapedLogged[sc] {
+		return
+	}
+	unshapedLogged[sc] = true
+	log.Printf("girl.Span: text in the %v script is shown without shaping, which needs GSUB / GPOS support that is not implemented yet\n", scriptName(sc))
+}
+
+// GlyphNone is the Rune Glyph for a rune that is not drawn, because it has
+// been combined into the glyph of the previous rune (a ligature)
+const GlyphNone = rune(-1)
+
+// arabicForms are the isolated, final, initial and medial presentation
+// forms for Arabic letters -- letters with only 2 forms only join to the
+// previous (right) letter
+var arabicForms = map[rune][]rune{
+	0x0621: {0xFE80},
+	0x0622: {0xFE81, 0xFE82},
+	0x0623: {0xFE83, 0xFE84},
+	0x0624: {0xFE85, 0xFE86},
+	0x0625: {0xFE87, 0xFE88},
+	0x0626: {0xFE89, 0xFE8A, 0xFE8B, 0xFE8C},
+	0x0627: {0xFE8D, 0xFE8E},
+	0x0628: {0xFE8F, 0xFE90, 0xFE91, 0xFE92},
+	0x0629: {0xFE93, 0xFE94},
+	0x062A: {0xFE95, 0xFE96, 0xFE97, 0xFE98},
+	0x062B: {0xFE99, 0xFE9A, 0xFE9B, 0xFE9C},
+	0x062C: {0xFE9D, 0xFE9E, 0xFE9F, 0xFEA0},
+	0x062D: {0xFEA1, 0xFEA2, 0xFEA3, 0xFEA4},
+	0x062E: {0xFEA5, 0xFEA6, 0xFEA7, 0xFEA8},
+	0x062F: {0xFEA9, 0xFEAA},
+	0x0630: {0xFEAB, 0xFEAC},
+	0x0631: {0xFEAD, 0xFEAE},
+	0x0632: {0xFEAF, 0xFEB0},
+	0x0633: {0xFEB1, 0xFEB2, 0xFEB3, 0xFEB4},
+	0x0634: {0xFEB5, 0xFEB6, 0xFEB7, 0xFEB8},
+	0x0635: {0xFEB9, 0xFEBA, 0xFEBB, 0xFEBC},
+	0x0636: {0xFEBD, 0xFEBE, 0xFEBF, 0xFEC0},
+	0x0637: {0xFEC1, 0xFEC2, 0xFEC3, 0xFEC4},
+	0x0638: {0xFEC5, 0xFEC6, 0xFEC7, 0xFEC8},
+	0x0639: {0xFEC9, 0xFECA, 0xFECB, 0xFECC},
+	0x063A: {0xFECD, 0xFECE, 0xFECF, 0xFED0},
+	0x0641: {0xFED1, 0xFED2, 0xFED3, 0xFED4},
+	0x0642: {0xFED5, 0xFED6, 0xFED7, 0xFED8},
+	0x0643: {0xFED9, 0xFEDA, 0xFEDB, 0xFEDC},
+	0x0644: {0xFEDD, 0xFEDE, 0xFEDF, 0xFEE0},
+	0x0645: {0xFEE1, 0xFEE2, 0xFEE3, 0xFEE4},
+	0x0646: {0xFEE5, 0xFEE6, 0xFEE7, 0xFEE8},
+	0x0647: {0xFEE9, 0xFEEA, 0xFEEB, 0xFEEC},
+	0x0648: {0xFEED, 0xFEEE},
+	0x0649: {0xFEEF, 0xFEF0, 0xFBE8, 0xFBE9},
+	0x064A: {0xFEF1, 0xFEF2, 0xFEF3, 0xFEF4},
+	0x067E: {0xFB56, 0xFB57, 0xFB58, 0xFB59},
+	0x0686: {0xFB7A, 0xFB7B, 0xFB7C, 0xFB7D},
+	0x0698: {0xFB8A, 0xFB8B},
+	0x06A9: {0xFB8E, 0xFB8F, 0xFB90, 0xFB91},
+	0x06AF: {0xFB92, 0xFB93, 0xFB94, 0xFB95},
+	0x06CC: {0xFBFC, 0xFBFD, 0xFBFE, 0xFBFF},
+}
+
+// lamAlefForms are the isolated and final forms of the lam-alef ligatures,
+// for the alef following the lam
+var lamAlefForms = map[rune][]rune{
+	0x0622: {0xFEF5, 0xFEF6},
+	0x0623: {0xFEF7, 0xFEF8},
+	0x0625: {0xFEF9, 0xFEFA},
+	0x0627: {0xFEFB, 0xFEFC},
+}
+
+const arabicTatweel = 0x0640 // joins on both sides, without forms
+
+// IsMark returns true if given rune is a combining mark, which is
+// displayed over the preceding base rune
+func IsMark(r rune) bool {
+	return r >= 0x0300 && unicode.In(r, unicode.Mn, unicode.Me)
+}
+
+// HasArabic returns true if given text has any Arabic letters that can be
+// shaped
+func HasArabic(txt []rune) bool {
+	for _, r := range txt {
+		if r >= 0x0621 && r <= 0x06CC {
+			return true
+		}
+	}
+	return false
+}
+
+// arabicJoins returns whether given rune joins to the previous (right) and
+// next (left) letter, and whether it is a letter at all (false for marks,
+// which are transparent)
+func arabicJoins(r rune) (prev, next, letter bool) {
+	if IsMark(r) {
+		return false, false, false
+	}
+	if r == arabicTatweel {
+		return true, true, true
+	}
+	fm, ok := arabicForms[r]
+	if !ok {
+		return false, false, true
+	}
+	return len(fm) > 1, len(fm) > 2, true
+}
+
+// ShapeArabic sets the Glyph of each Rune of given text (with Rune in
+// one-to-one correspondence) to the contextual form of each Arabic letter,
+// using the given face to check that the font has that glyph -- otherwise
+// the rune itself is used.  Any previous Glyph settings are reset.
+func ShapeArabic(txt []rune, rend []Rune, face font.Face) {
+	n := len(txt)
+	curFace := face
+	for i := range rend {
+		rend[i].Glyph = 0
+	}
+	// neighbor letters, skipping marks
+	prevLetter := func(i int) int {
+		for j := i - 1; j >= 0; j-- {
+			if _, _, lt := arabicJoins(txt[j]); lt {
+				return j
+			}
+		}
+		return -1
+	}
+	nextLetter := func(i int) int {
+		for j := i + 1; j < n; j++ {
+			if _, _, lt := arabicJoins(txt[j]); lt {
+				return j
+			}
+		}
+		return -1
+	}
+	has := func(fc font.Face, g rune) bool {
+		_, ok := fc.GlyphAdvance(g)
+		return ok
+	}
+	for i := 0; i < n; i++ {
+		curFace = rend[i].CurFace(curFace)
+		r := txt[i]
+		fm, ok := arabicForms[r]
+		if !ok {
+			continue
+		}
+		joinPrev := false
+		if pi := prevLetter(i); pi >= 0 {
+			_, pnext, _ := arabicJoins(txt[pi])
+			joinPrev = pnext && len(fm) > 1
+		}
+		joinNext := false
+		ni := nextLetter(i)
+		if ni >= 0 && len(fm) > 2 {
+			nprev, _, _ := arabicJoins(txt[ni])
+			joinNext = nprev
+		}
+		if r == 0x0644 && ni >= 0 { // lam-alef ligature
+			if la, ok := lamAlefForms[txt[ni]]; ok {
+				g := la[0]
+				if joinPrev {
+					g = la[1]
+				}
+				if has(curFace, g) {
+					rend[i].Glyph = g
+					rend[ni].Glyph = GlyphNone
+					i = ni
+					continue
+				}
+			}
+		}
+		form := 0
+		switch {
+		case joinPrev && joinNext:
+			form = 3
+		case joinNext:
+			form = 2
+		case joinPrev:
+			form = 1
+		}
+		if g := fm[form]; has(curFace, g) {
+			rend[i].Glyph = g
+		}
+	}
+}
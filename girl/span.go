@@ -33,12 +33,17 @@ import (
 // span-as-line.  The first Rune RelPos for LR text should be at X=0
 // (LastPos = 0 for RL) -- i.e., relpos positions are minimal for given span.
 type Span struct {
-	Text    []rune               `desc:"text as runes"`
-	Render  []Rune               `desc:"render info for each rune in one-to-one correspondence"`
-	RelPos  mat32.Vec2           `desc:"position for start of text relative to an absolute coordinate that is provided at the time of rendering -- this typically includes the baseline offset to align all rune rendering there -- individual rune RelPos are added to this plus the render-time offset to get the final position"`
-	LastPos mat32.Vec2           `desc:"rune position for further edge of last rune -- for standard flat strings this is the overall length of the string -- used for size / layout computations -- you do not add RelPos to this -- it is in same Text relative coordinates"`
-	Dir     gist.TextDirections  `desc:"where relevant, this is the (default, dominant) text direction for the span"`
-	HasDeco gist.TextDecorations `desc:"mask of decorations that have been set on this span -- optimizes rendering passes"`
+	Text       []rune               `desc:"text as runes"`
+	Render     []Rune               `desc:"render info for each rune in one-to-one correspondence"`
+	RelPos     mat32.Vec2           `desc:"position for start of text relative to an absolute coordinate that is provided at the time of rendering -- this typically includes the baseline offset to align all rune rendering there -- individual rune RelPos are added to this plus the render-time offset to get the final position"`
+	LastPos    mat32.Vec2           `desc:"rune position for further edge of last rune -- for standard flat strings this is the overall length of the string -- used for size / layout computations -- you do not add RelPos to this -- it is in same Text relative coordinates"`
+	Dir        gist.TextDirections  `desc:"where relevant, this is the (default, dominant) text direction for the span"`
+	HasDeco    gist.TextDecorations `desc:"mask of decorations that have been set on this span -- optimizes rendering passes"`
+	BidiLevels []uint8              `desc:"bidirectional embedding level of each rune, if the runes have been reordered for display by SetBidiLR -- nil if all runes are left-to-right"`
+	Indent     float32              `desc:"additional indent of the start of the span, e.g., for the nesting level of a list item"`
+	Hang       int                  `desc:"number of leading runes (e.g., a list bullet) that lines wrapped from this span hang after -- they are indented to the position of the rune after these"`
+	Table      int                  `desc:"if > 0, the span is a row of the table with this number (starting at 1) within the Text, and is not wrapped"`
+	Cells      []int                `desc:"for table rows, the starting rune index of each cell"`
 }
 
 // Init initializes a new span with given capacity
@@ -67,6 +72,9 @@ func (sr *Span) SizeHV() mat32.Vec2 {
 	if sr.IsValid() != nil {
 		return mat32.Vec2{}
 	}
+	if sr.BidiLevels != nil { // reordered: first rune is not at the start
+		return mat32.Vec2{sr.LastPos.X, 0}
+	}
 	sz := sr.Render[0].RelPos.Sub(sr.LastPos)
 	if sz.X < 0 {
 		sz.X = -sz.X
@@ -124,16 +132,16 @@ func (sr *Span) SetNewPara() {
 }
 
 // AppendRune adds one rune and associated formatting info
-func (sr *Span) AppendRune(r rune, face font.Face, clr, bg color.Color, deco gist.TextDecorations) {
+func (sr *Span) AppendRune(r rune, face font.Face, clr, bg, dclr color.Color, deco gist.TextDecorations) {
 	sr.Text = append(sr.Text, r)
-	rr := Rune{Face: face, Color: clr, BgColor: bg, Deco: deco}
+	rr := Rune{Face: face, Color: clr, BgColor: bg, DecoColor: dclr, Deco: deco}
 	sr.Render = append(sr.Render, rr)
 	sr.HasDecoUpdate(bg, deco)
 }
 
 // AppendString adds string and associated formatting info, optimized with
 // only first rune having non-nil face and color settings
-func (sr *Span) AppendString(str string, face font.Face, clr, bg color.Color, deco gist.TextDecorations, sty *gist.Font, ctxt *units.Context) {
+func (sr *Span) AppendString(str string, face font.Face, clr, bg, dclr color.Color, deco gist.TextDecorations, sty *gist.Font, ctxt *units.Context) {
 	if len(str) == 0 {
 		return
 	}
@@ -149,7 +157,7 @@ func (sr *Span) AppendString(str string, face font.Face, clr, bg color.Color, de
 	nwr := []rune(str)
 	sz := len(nwr)
 	sr.Text = append(sr.Text, nwr...)
-	rr := Rune{Face: face, Color: clr, BgColor: bg, Deco: deco}
+	rr := Rune{Face: face, Color: clr, BgColor: bg, DecoColor: dclr, Deco: deco}
 	r := nwr[0]
 	lastUc := false
 	if r > 0xFF && unicode.IsSymbol(r) {
@@ -159,7 +167,7 @@ func (sr *Span) AppendString(str string, face font.Face, clr, bg color.Color, de
 	sr.HasDecoUpdate(bg, deco)
 	sr.Render = append(sr.Render, rr)
 	for i := 1; i < sz; i++ { // optimize by setting rest to nil for same
-		rp := Rune{Deco: deco, BgColor: bg}
+		rp := Rune{Deco: deco, BgColor: bg, DecoColor: dclr}
 		r := nwr[i]
 		if oswin.TheApp != nil && oswin.TheApp.Platform() == oswin.MacOS {
 			if r > 0xFF && unicode.IsSymbol(r) {
@@ -218,8 +226,10 @@ func (sr *Span) SetRenders(sty *gist.Font, ctxt *units.Context, noBG bool, rot,
 		}
 	}
 	if sty.Deco != gist.DecoNone {
+		dclr := sty.DecoColorOrNil()
 		for i := range sr.Text {
 			sr.Render[i].Deco = sty.Deco
+			sr.Render[i].DecoColor = dclr
 		}
 	}
 	// use unicode font for all non-ascii symbols
@@ -263,13 +273,16 @@ var TextFontRenderMu sync.Mutex
 
 // SetRunePosLR sets relative positions of each rune using a flat
 // left-to-right text layout, based on font size info and additional extra
-// letter and word spacing parameters (which can be negative)
+// letter and word spacing parameters (which can be negative).  The runes are
+// shaped (see ShapeArabic and IsMark), and are in their logical order --
+// use SetBidiLR to reorder them for display.
 func (sr *Span) SetRunePosLR(letterSpace, wordSpace, chsz float32, tabSize int) {
 	if err := sr.IsValid(); err != nil {
 		// log.Println(err)
 		return
 	}
 	sr.Dir = gist.LRTB
+	sr.BidiLevels = nil
 	sz := len(sr.Text)
 	prevR := rune(-1)
 	lspc := letterSpace
@@ -281,13 +294,57 @@ func (sr *Span) SetRunePosLR(letterSpace, wordSpace, chsz float32, tabSize int)
 	curFace := sr.Render[0].Face
 	TextFontRenderMu.Lock()
 	defer TextFontRenderMu.Unlock()
+	if ShapingOn && HasArabic(sr.Text) {
+		ShapeArabic(sr.Text, sr.Render, curFace)
+	} else {
+		for i := range sr.Render {
+			sr.Render[i].Glyph = 0
+		}
+	}
+	if ShapingOn {
+		logUnshaped(sr.Text)
+	}
+	var baseX, baseA float32 // start and advance of last base (non-mark) rune
 	for i, r := range sr.Text {
 		rr := &(sr.Render[i])
 		curFace = rr.CurFace(curFace)
 
 		fht := mat32.FromFixed(curFace.Metrics().Height)
+		if rr.Glyph == GlyphNone { // part of previous ligature glyph
+			rr.RelPos = mat32.Vec2{fpos, 0}
+			rr.Size = mat32.Vec2{0, fht}
+			continue
+		}
+		if rr.Image != nil { // inline image: its own advance, no kerning
+			rr.RelPos = mat32.Vec2{fpos, 0}
+			rr.Size = rr.Image.Size
+			baseX, baseA = fpos, rr.Size.X
+			fpos += rr.Size.X
+			if i < sz-1 {
+				fpos += lspc
+			}
+			prevR = -1
+			continue
+		}
+		g := r
+		if rr.Glyph > 0 {
+			g = rr.Glyph
+		}
+		if ShapingOn && i > 0 && IsMark(r) {
+			// centered over base if it has its own advance, else drawn
+			// from the end of the base, as fonts design zero-width marks
+			a, _ := curFace.GlyphAdvance(g)
+			a32 := mat32.FromFixed(a)
+			rr.RelPos.X = baseX + baseA
+			if a32 > 0 {
+				rr.RelPos.X = baseX + 0.5*(baseA-a32)
+			}
+			rr.RelPos.Y = sr.Render[i-1].RelPos.Y
+			rr.Size = mat32.Vec2{0, fht}
+			continue
+		}
 		if prevR >= 0 {
-			fpos += mat32.FromFixed(curFace.Kern(prevR, r))
+			fpos += mat32.FromFixed(curFace.Kern(prevR, g))
 		}
 		rr.RelPos.X = fpos
 		rr.RelPos.Y = 0
@@ -300,12 +357,13 @@ func (sr *Span) SetRunePosLR(letterSpace, wordSpace, chsz float32, tabSize int)
 		}
 
 		// todo: could check for various types of special unicode space chars here
-		a, _ := curFace.GlyphAdvance(r)
+		a, _ := curFace.GlyphAdvance(g)
 		a32 := mat32.FromFixed(a)
 		if a32 == 0 {
 			a32 = .1 * fht // something..
 		}
 		rr.Size = mat32.Vec2{a32, fht}
+		baseX, baseA = fpos, a32
 
 		if r == '\t' {
 			col := int(math32.Ceil(fpos / chsz))
@@ -325,7 +383,7 @@ func (sr *Span) SetRunePosLR(letterSpace, wordSpace, chsz float32, tabSize int)
 				}
 			}
 		}
-		prevR = r
+		prevR = g
 	}
 	sr.LastPos.X = fpos
 	sr.LastPos.Y = 0
@@ -602,7 +660,10 @@ func (sr *Span) SplitAtLR(idx int) *Span {
 	if idx <= 0 || idx >= len(sr.Text)-1 { // shouldn't happen
 		return nil
 	}
-	nsr := Span{Text: sr.Text[idx:], Render: sr.Render[idx:], Dir: sr.Dir, HasDeco: sr.HasDeco}
+	nsr := Span{Text: sr.Text[idx:], Render: sr.Render[idx:], Dir: sr.Dir, HasDeco: sr.HasDeco, Indent: sr.Indent}
+	if sr.Hang > 0 && sr.Hang < idx {
+		nsr.Indent += sr.Render[sr.Hang].RelPos.X - sr.Render[0].RelPos.X
+	}
 	sr.Text = sr.Text[:idx]
 	sr.Render = sr.Render[:idx]
 	sr.LastPos.X = sr.Render[idx-1].RelPosAfterLR()
@@ -689,11 +750,21 @@ func (sr *Span) RenderBg(rs *State, tpos mat32.Vec2) {
 	}
 }
 
+// UnderlineDecos are all of the decorations rendered by RenderUnderline
+var UnderlineDecos = []int{int(gist.DecoUnderline), int(gist.DecoDottedUnderline), int(gist.DecoWavyUnderline), int(gist.DecoDashedUnderline)}
+
+// HasUnderline returns true if any of the runes in span have an underline decoration
+func (sr *Span) HasUnderline() bool {
+	return bitflag.HasAny32(int32(sr.HasDeco), UnderlineDecos...)
+}
+
 // RenderUnderline renders the underline for span -- ensures continuity to do it all at once
 func (sr *Span) RenderUnderline(rs *State, tpos mat32.Vec2) {
 	curFace := sr.Render[0].Face
 	curColor := sr.Render[0].Color
 	didLast := false
+	lastDeco := gist.DecoNone
+	var lastColor color.Color
 	pc := &rs.Paint
 
 	for i, r := range sr.Text {
@@ -701,7 +772,7 @@ func (sr *Span) RenderUnderline(rs *State, tpos mat32.Vec2) {
 			continue
 		}
 		rr := &(sr.Render[i])
-		if !bitflag.HasAny32(int32(rr.Deco), int(gist.DecoUnderline), int(gist.DecoDottedUnderline)) {
+		if !bitflag.HasAny32(int32(rr.Deco), UnderlineDecos...) {
 			if didLast {
 				pc.Stroke(rs)
 			}
@@ -712,6 +783,15 @@ func (sr *Span) RenderUnderline(rs *State, tpos mat32.Vec2) {
 		if rr.Color != nil {
 			curColor = rr.Color
 		}
+		ulColor := curColor
+		if rr.DecoColor != nil {
+			ulColor = rr.DecoColor
+		}
+		ulDeco := rr.UnderlineDeco()
+		if didLast && (ulDeco != lastDeco || ulColor != lastColor) {
+			pc.Stroke(rs)
+			didLast = false
+		}
 		dsc32 := mat32.FromFixed(curFace.Metrics().Descent)
 		rp := tpos.Add(rr.RelPos)
 		scx := float32(1)
@@ -726,15 +806,21 @@ func (sr *Span) RenderUnderline(rs *State, tpos mat32.Vec2) {
 			if didLast {
 				pc.Stroke(rs)
 			}
+			didLast = false
 			continue
 		}
 		dw := .05 * rr.Size.Y
 		if !didLast {
 			pc.StrokeStyle.Width.Dots = dw
-			pc.StrokeStyle.Color.SetColor(curColor)
-		}
-		if bitflag.Has32(int32(rr.Deco), int(gist.DecoDottedUnderline)) {
-			pc.StrokeStyle.Dashes = []float64{2, 2}
+			pc.StrokeStyle.Color.SetColor(ulColor)
+			switch ulDeco {
+			case gist.DecoDottedUnderline:
+				pc.StrokeStyle.Dashes = []float64{2, 2}
+			case gist.DecoDashedUnderline:
+				pc.StrokeStyle.Dashes = []float64{6, 3}
+			default:
+				pc.StrokeStyle.Dashes = nil
+			}
 		}
 		sp := rp.Add(tx.MulVec2AsVec(mat32.Vec2{0, 2 * dw}))
 		ep := rp.Add(tx.MulVec2AsVec(mat32.Vec2{rr.Size.X, 2 * dw}))
@@ -745,8 +831,27 @@ func (sr *Span) RenderUnderline(rs *State, tpos mat32.Vec2) {
 			pc.NewSubPath(rs)
 			pc.MoveTo(rs, sp.X, sp.Y)
 		}
-		pc.LineTo(rs, ep.X, ep.Y)
+		if ulDeco == gist.DecoWavyUnderline {
+			// zig-zag between baseline and one amplitude below, with a
+			// half-period of 2 stroke widths
+			amp := 1.5 * dw
+			hp := 2 * dw
+			np := int(math32.Ceil(rr.Size.X / hp))
+			for p := 1; p <= np; p++ {
+				x := math32.Min(float32(p)*hp, rr.Size.X)
+				y := 2 * dw
+				if p%2 == 1 {
+					y += amp
+				}
+				wp := rp.Add(tx.MulVec2AsVec(mat32.Vec2{x, y}))
+				pc.LineTo(rs, wp.X, wp.Y)
+			}
+		} else {
+			pc.LineTo(rs, ep.X, ep.Y)
+		}
 		didLast = true
+		lastDeco = ulDeco
+		lastColor = ulColor
 	}
 	if didLast {
 		pc.Stroke(rs)
@@ -38,6 +38,7 @@ type State struct {
 	PaintBack      Paint             `desc:"backup of paint -- don't need a full stack but sometimes safer to backup and restore"`
 	RenderMu       sync.Mutex        `desc:"mutex for overall rendering"`
 	RasterMu       sync.Mutex        `desc:"mutex for final rasterx rendering -- only one at a time"`
+	PDF            *PDF              `json:"-" xml:"-" desc:"if non-nil, all drawing is also recorded as vector graphics into this PDF, e.g., for printing"`
 }
 
 // Init initializes State -- must be called whenever image size changes
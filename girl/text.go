@@ -11,6 +11,7 @@ import (
 	"image"
 	"io"
 	"math"
+	"strconv"
 	"strings"
 
 	"unicode"
@@ -27,6 +28,7 @@ import (
 	"golang.org/x/image/font"
 	"golang.org/x/image/math/f64"
 	"golang.org/x/net/html/charset"
+	"golang.org/x/text/unicode/bidi"
 )
 
 // text.go contains all the core text rendering and formatting code -- see
@@ -100,7 +102,7 @@ func (tr *Text) Render(rs *State, pos mat32.Vec2) {
 		if bitflag.Has32(int32(sr.HasDeco), int(gist.DecoBgColor)) {
 			sr.RenderBg(rs, tpos)
 		}
-		if bitflag.HasAny32(int32(sr.HasDeco), int(gist.DecoUnderline), int(gist.DecoDottedUnderline)) {
+		if sr.HasUnderline() {
 			sr.RenderUnderline(rs, tpos)
 		}
 		if bitflag.Has32(int32(sr.HasDeco), int(gist.DecoOverline)) {
@@ -114,7 +116,11 @@ func (tr *Text) Render(rs *State, pos mat32.Vec2) {
 				d.Src = image.NewUniform(curColor)
 			}
 			curFace = rr.CurFace(curFace)
-			if !unicode.IsPrint(r) {
+			if rr.Image != nil {
+				rr.Image.Render(rs, tpos.Add(rr.RelPos))
+				continue
+			}
+			if !unicode.IsPrint(r) || rr.Glyph == GlyphNone {
 				continue
 			}
 			dsc32 := mat32.FromFixed(curFace.Metrics().Descent)
@@ -132,11 +138,26 @@ func (tr *Text) Render(rs *State, pos mat32.Vec2) {
 			}
 			d.Face = curFace
 			d.Dot = rp.Fixed()
-			dr, mask, maskp, _, ok := d.Face.Glyph(d.Dot, r)
+			var dr image.Rectangle
+			var mask image.Image
+			var maskp image.Point
+			var ok bool
+			gr := r
+			if rr.Glyph > 0 {
+				gr = rr.Glyph
+			}
+			if GlyphCacheOn {
+				dr, mask, maskp, ok = GlyphCache.Glyph(d.Face, d.Dot, gr)
+			} else {
+				dr, mask, maskp, _, ok = d.Face.Glyph(d.Dot, gr)
+			}
 			if !ok {
 				// fmt.Printf("not ok rendering rune: %v\n", string(r))
 				continue
 			}
+			if rs.PDF != nil {
+				rs.PDF.drawGlyph(rs, curFace, gr, curColor, rp, tx)
+			}
 			if rr.RotRad == 0 && (rr.ScaleX == 0 || rr.ScaleX == 1) {
 				idr := dr.Intersect(rs.Bounds)
 				soff := image.ZP
@@ -201,6 +222,7 @@ func (tr *Text) SetString(str string, fontSty *gist.Font, ctxt *units.Context, t
 	sr := &(tr.Spans[0])
 	sr.SetString(str, fontSty, ctxt, noBG, rot, scalex)
 	sr.SetRunePosLR(txtSty.LetterSpacing.Dots, txtSty.WordSpacing.Dots, fontSty.Face.Metrics.Ch, txtSty.TabSize)
+	sr.SetBidiLR(ParaRTL(sr.Text, txtSty), txtSty.UnicodeBidi == gist.BidiBidiOverride)
 	ssz := sr.SizeHV()
 	vht := fontSty.Face.Face.Metrics().Height
 	tr.Size = mat32.Vec2{ssz.X, mat32.FromFixed(vht)}
@@ -241,6 +263,7 @@ func (tr *Text) SetRunes(str []rune, fontSty *gist.Font, ctxt *units.Context, tx
 	sr := &(tr.Spans[0])
 	sr.SetRunes(str, fontSty, ctxt, noBG, rot, scalex)
 	sr.SetRunePosLR(txtSty.LetterSpacing.Dots, txtSty.WordSpacing.Dots, fontSty.Face.Metrics.Ch, txtSty.TabSize)
+	sr.SetBidiLR(ParaRTL(sr.Text, txtSty), txtSty.UnicodeBidi == gist.BidiBidiOverride)
 	ssz := sr.SizeHV()
 	vht := fontSty.Face.Face.Metrics().Height
 	tr.Size = mat32.Vec2{ssz.X, mat32.FromFixed(vht)}
@@ -321,19 +344,18 @@ func SetHTMLSimpleTag(tag string, fs *gist.Font, ctxt *units.Context, cssAgg ki.
 // sets font, color, and decoration info, and strips out the tags it processes
 // -- result can then be processed by different layout algorithms as needed.
 // cssAgg, if non-nil, should contain CSSAgg properties -- will be tested for
-// special css styling of each element.
+// special css styling of each element.  Inline images, lists and tables are
+// also supported (see rich.go) -- text with these elements is always parsed
+// with SetHTMLNoPre, even if the white space is preformatted.
 func (tr *Text) SetHTML(str string, font *gist.Font, txtSty *gist.Text, ctxt *units.Context, cssAgg ki.Props) {
-	if txtSty.HasPre() {
-		tr.SetHTMLPre([]byte(str), font, txtSty, ctxt, cssAgg)
-	} else {
-		tr.SetHTMLNoPre([]byte(str), font, txtSty, ctxt, cssAgg)
-	}
+	tr.SetHTMLBytes([]byte(str), font, txtSty, ctxt, cssAgg)
 }
 
 // SetHTMLBytes does SetHTML with bytes as input -- more efficient -- use this
 // if already in bytes
 func (tr *Text) SetHTMLBytes(str []byte, font *gist.Font, txtSty *gist.Text, ctxt *units.Context, cssAgg ki.Props) {
-	if txtSty.HasPre() {
+	str = HTMLCodeMarkup(str)
+	if txtSty.HasPre() && !HasRichHTML(str) {
 		tr.SetHTMLPre(str, font, txtSty, ctxt, cssAgg)
 	} else {
 		tr.SetHTMLNoPre(str, font, txtSty, ctxt, cssAgg)
@@ -369,6 +391,23 @@ func (tr *Text) SetHTMLNoPre(str []byte, font *gist.Font, txtSty *gist.Text, ctx
 	nextIsParaStart := false
 	curLinkIdx := -1 // if currently processing an <a> link element
 
+	type htmlList struct {
+		ordered bool
+		num     int
+	}
+	var lists []htmlList // stack of current lists, for nesting
+	ntables := 0
+	curTable := 0
+	blockWS := false  // skip whitespace before the content of list items, table rows and cells
+	needSpan := false // start a new span for subsequent content, after a list or table
+	newSpan := func() {
+		if len(curSp.Text) > 0 {
+			tr.Spans = append(tr.Spans, Span{})
+			curSp = &(tr.Spans[len(tr.Spans)-1])
+		}
+		needSpan = false
+	}
+
 	fstack := make([]*gist.Font, 1, 10)
 	fstack[0] = font
 	for {
@@ -385,7 +424,9 @@ func (tr *Text) SetHTMLNoPre(str []byte, font *gist.Font, txtSty *gist.Text, ctx
 			curf := fstack[len(fstack)-1]
 			fs := *curf
 			nm := strings.ToLower(se.Name.Local)
-			curLinkIdx = -1
+			if nm != "img" { // an image can be the label of a link
+				curLinkIdx = -1
+			}
 			if !SetHTMLSimpleTag(nm, &fs, ctxt, cssAgg) {
 				switch nm {
 				case "a":
@@ -407,7 +448,7 @@ func (tr *Text) SetHTMLNoPre(str []byte, font *gist.Font, txtSty *gist.Text, ctx
 				case "q":
 					curf := fstack[len(fstack)-1]
 					atStart := len(curSp.Text) == 0
-					curSp.AppendRune('“', curf.Face.Face, curf.Color, curf.BgColor.ColorOrNil(), curf.Deco)
+					curSp.AppendRune('“', curf.Face.Face, curf.Color, curf.BgColor.ColorOrNil(), curf.DecoColorOrNil(), curf.Deco)
 					if nextIsParaStart && atStart {
 						curSp.SetNewPara()
 					}
@@ -424,6 +465,73 @@ func (tr *Text) SetHTMLNoPre(str []byte, font *gist.Font, txtSty *gist.Text, ctx
 					}
 					nextIsParaStart = true
 				case "br":
+				case "img":
+					if needSpan {
+						newSpan()
+					}
+					attrs := make(map[string]string, len(se.Attr))
+					for _, attr := range se.Attr {
+						attrs[attr.Name.Local] = attr.Value
+					}
+					ti := NewTextImage(attrs, ctxt, fs.Face.Metrics.Height)
+					atStart := len(curSp.Text) == 0
+					curSp.AppendRune(ObjectRune, fs.Face.Face, fs.Color, fs.BgColor.ColorOrNil(), fs.DecoColorOrNil(), fs.Deco)
+					curSp.Render[len(curSp.Render)-1].Image = ti
+					if nextIsParaStart && atStart {
+						curSp.SetNewPara()
+					}
+					nextIsParaStart = false
+					blockWS = false
+					if curLinkIdx >= 0 && tr.Links[curLinkIdx].Label == "" {
+						tr.Links[curLinkIdx].Label = ti.Alt
+					}
+				case "ul", "ol":
+					newSpan()
+					ls := htmlList{ordered: nm == "ol"}
+					for _, attr := range se.Attr {
+						if attr.Name.Local == "start" {
+							if st, err := strconv.Atoi(attr.Value); err == nil {
+								ls.num = st - 1
+							}
+						}
+					}
+					lists = append(lists, ls)
+					blockWS = true
+				case "li":
+					newSpan()
+					lvl := len(lists) - 1
+					ordered, num := false, 1
+					if lvl >= 0 {
+						lists[lvl].num++
+						ordered, num = lists[lvl].ordered, lists[lvl].num
+					} else {
+						lvl = 0
+					}
+					curSp.Indent = float32(lvl) * ListIndent * fs.Size.Dots
+					pfx := ListItemPrefix(lvl, ordered, num)
+					curSp.AppendString(pfx, fs.Face.Face, fs.Color, nil, nil, 0, &fs, ctxt)
+					curSp.Hang = len([]rune(pfx))
+					nextIsParaStart = false
+					blockWS = true
+				case "table":
+					newSpan()
+					ntables++
+					curTable = ntables
+					blockWS = true
+				case "tr":
+					newSpan()
+					curSp.Table = curTable
+					blockWS = true
+				case "td", "th":
+					if curSp.Table == 0 {
+						curSp.Table = curTable
+					}
+					curSp.Cells = append(curSp.Cells, len(curSp.Text))
+					if nm == "th" {
+						fs.Weight = gist.WeightBold
+						OpenFont(&fs, ctxt)
+					}
+					blockWS = true
 				default:
 					// log.Printf("%v tag not recognized: %v for string\n%v\n", errstr, nm, string(str))
 				}
@@ -464,7 +572,7 @@ func (tr *Text) SetHTMLNoPre(str []byte, font *gist.Font, txtSty *gist.Text, ctx
 				curSp = &(tr.Spans[len(tr.Spans)-1])
 			case "q":
 				curf := fstack[len(fstack)-1]
-				curSp.AppendRune('”', curf.Face.Face, curf.Color, curf.BgColor.ColorOrNil(), curf.Deco)
+				curSp.AppendRune('”', curf.Face.Face, curf.Color, curf.BgColor.ColorOrNil(), curf.DecoColorOrNil(), curf.Deco)
 			case "a":
 				if curLinkIdx >= 0 {
 					tl := &tr.Links[curLinkIdx]
@@ -472,20 +580,42 @@ func (tr *Text) SetHTMLNoPre(str []byte, font *gist.Font, txtSty *gist.Text, ctx
 					tl.EndIdx = len(curSp.Text)
 					curLinkIdx = -1
 				}
+			case "ul", "ol":
+				if len(lists) > 0 {
+					lists = lists[:len(lists)-1]
+				}
+				needSpan = true
+				blockWS = true
+			case "table":
+				curTable = 0
+				needSpan = true
+				blockWS = true
+			case "li", "tr", "td", "th":
+				blockWS = true
 			}
 			if len(fstack) > 1 {
 				fstack = fstack[:len(fstack)-1]
 			}
 		case xml.CharData:
 			curf := fstack[len(fstack)-1]
-			atStart := len(curSp.Text) == 0
 			sstr := html.UnescapeString(string(se))
+			if blockWS {
+				sstr = strings.TrimLeftFunc(sstr, unicode.IsSpace)
+				if sstr == "" {
+					break
+				}
+				blockWS = false
+			}
+			if needSpan {
+				newSpan()
+			}
+			atStart := len(curSp.Text) == 0
 			if nextIsParaStart && atStart {
 				sstr = strings.TrimLeftFunc(sstr, func(r rune) bool {
 					return unicode.IsSpace(r)
 				})
 			}
-			curSp.AppendString(sstr, curf.Face.Face, curf.Color, curf.BgColor.ColorOrNil(), curf.Deco, font, ctxt)
+			curSp.AppendString(sstr, curf.Face.Face, curf.Color, curf.BgColor.ColorOrNil(), curf.DecoColorOrNil(), curf.Deco, font, ctxt)
 			if nextIsParaStart && atStart {
 				curSp.SetNewPara()
 			}
@@ -544,7 +674,7 @@ func (tr *Text) SetHTMLPre(str []byte, font *gist.Font, txtSty *gist.Text, ctxt
 				bidx += eidx + 2
 			} else { // get past <
 				curf := fstack[len(fstack)-1]
-				curSp.AppendString(string(str[bidx:bidx+1]), curf.Face.Face, curf.Color, curf.BgColor.ColorOrNil(), curf.Deco, font, ctxt)
+				curSp.AppendString(string(str[bidx:bidx+1]), curf.Face.Face, curf.Color, curf.BgColor.ColorOrNil(), curf.DecoColorOrNil(), curf.Deco, font, ctxt)
 				bidx++
 			}
 		}
@@ -568,7 +698,7 @@ func (tr *Text) SetHTMLPre(str []byte, font *gist.Font, txtSty *gist.Text, ctxt
 				// 	curSp = &(tr.Spans[len(tr.Spans)-1])
 				case "q":
 					curf := fstack[len(fstack)-1]
-					curSp.AppendRune('”', curf.Face.Face, curf.Color, curf.BgColor.ColorOrNil(), curf.Deco)
+					curSp.AppendRune('”', curf.Face.Face, curf.Color, curf.BgColor.ColorOrNil(), curf.DecoColorOrNil(), curf.Deco)
 				case "a":
 					if curLinkIdx >= 0 {
 						tl := &tr.Links[curLinkIdx]
@@ -623,7 +753,7 @@ func (tr *Text) SetHTMLPre(str []byte, font *gist.Font, txtSty *gist.Text, ctxt
 					case "q":
 						curf := fstack[len(fstack)-1]
 						atStart := len(curSp.Text) == 0
-						curSp.AppendRune('“', curf.Face.Face, curf.Color, curf.BgColor.ColorOrNil(), curf.Deco)
+						curSp.AppendRune('“', curf.Face.Face, curf.Color, curf.BgColor.ColorOrNil(), curf.DecoColorOrNil(), curf.Deco)
 						if nextIsParaStart && atStart {
 							curSp.SetNewPara()
 						}
@@ -699,7 +829,7 @@ func (tr *Text) SetHTMLPre(str []byte, font *gist.Font, txtSty *gist.Text, ctxt
 					}
 				case '\n': // todo absorb other line endings
 					unestr := html.UnescapeString(string(tmpbuf))
-					curSp.AppendString(unestr, curf.Face.Face, curf.Color, curf.BgColor.ColorOrNil(), curf.Deco, font, ctxt)
+					curSp.AppendString(unestr, curf.Face.Face, curf.Color, curf.BgColor.ColorOrNil(), curf.DecoColorOrNil(), curf.Deco, font, ctxt)
 					tmpbuf = tmpbuf[0:0]
 					tr.Spans = append(tr.Spans, Span{})
 					curSp = &(tr.Spans[len(tr.Spans)-1])
@@ -712,7 +842,7 @@ func (tr *Text) SetHTMLPre(str []byte, font *gist.Font, txtSty *gist.Text, ctxt
 			if !didNl {
 				unestr := html.UnescapeString(string(tmpbuf))
 				// fmt.Printf("%v added: %v\n", bidx, unestr)
-				curSp.AppendString(unestr, curf.Face.Face, curf.Color, curf.BgColor.ColorOrNil(), curf.Deco, font, ctxt)
+				curSp.AppendString(unestr, curf.Face.Face, curf.Color, curf.BgColor.ColorOrNil(), curf.DecoColorOrNil(), curf.Deco, font, ctxt)
 				if curLinkIdx >= 0 {
 					tl := &tr.Links[curLinkIdx]
 					tl.Label = unestr
@@ -843,7 +973,7 @@ func (tr *Text) LayoutStdLR(txtSty *gist.Text, fontSty *gist.Font, ctxt *units.C
 			si++
 			continue
 		}
-		if sr.LastPos.X == 0 { // don't re-do unless necessary
+		if sr.LastPos.X == 0 || sr.BidiLevels != nil { // don't re-do unless necessary
 			sr.SetRunePosLR(txtSty.LetterSpacing.Dots, txtSty.WordSpacing.Dots, fontSty.Face.Metrics.Ch, txtSty.TabSize)
 		}
 		if sr.IsNewPara() {
@@ -851,9 +981,10 @@ func (tr *Text) LayoutStdLR(txtSty *gist.Text, fontSty *gist.Font, ctxt *units.C
 		} else {
 			sr.RelPos.X = 0
 		}
+		sr.RelPos.X += sr.Indent
 		ssz := sr.SizeHV()
 		ssz.X += sr.RelPos.X
-		if size.X > 0 && ssz.X > size.X && txtSty.HasWordWrap() {
+		if size.X > 0 && ssz.X > size.X && txtSty.HasWordWrap() && sr.Table == 0 {
 			for {
 				wp := sr.FindWrapPosLR(size.X, ssz.X)
 				if wp > 0 && wp < len(sr.Text)-1 {
@@ -867,7 +998,9 @@ func (tr *Text) LayoutStdLR(txtSty *gist.Text, fontSty *gist.Font, ctxt *units.C
 					si++
 					sr = &(tr.Spans[si]) // keep going with nsr
 					sr.SetRunePosLR(txtSty.LetterSpacing.Dots, txtSty.WordSpacing.Dots, fontSty.Face.Metrics.Ch, txtSty.TabSize)
+					sr.RelPos.X = txtSty.WrapIndent.Dots + sr.Indent
 					ssz = sr.SizeHV()
+					ssz.X += sr.RelPos.X
 
 					// fixup links
 					for li := range tr.Links {
@@ -910,6 +1043,9 @@ func (tr *Text) LayoutStdLR(txtSty *gist.Text, fontSty *gist.Font, ctxt *units.C
 		}
 		si++
 	}
+	if tw := tr.LayoutTables(TableCellPad * fontSty.Size.Dots); tw > maxw {
+		maxw = tw
+	}
 	// have maxw, can do alignment cases..
 
 	// make sure links are still in range
@@ -939,7 +1075,23 @@ func (tr *Text) LayoutStdLR(txtSty *gist.Text, fontSty *gist.Font, ctxt *units.C
 		}
 	}
 
+	vbaseoff := lspc - lpad - dsc // offset of baseline within overall line
+
+	// extra space above lines with inline images taller than the text
+	var imgExtra []float32
+	if tr.HasImages() {
+		imgExtra = make([]float32, nsp)
+		for si := range tr.Spans {
+			if ext := tr.Spans[si].ImageAscent() - vbaseoff; ext > 0 {
+				imgExtra[si] = ext
+			}
+		}
+	}
+
 	vht := lspc*float32(nsp) + float32(npara)*txtSty.ParaSpacing.Dots
+	for _, ext := range imgExtra {
+		vht += ext
+	}
 	if vht > size.Y {
 		size.Y = vht
 	}
@@ -957,14 +1109,22 @@ func (tr *Text) LayoutStdLR(txtSty *gist.Text, fontSty *gist.Font, ctxt *units.C
 		}
 	}
 
-	vbaseoff := lspc - lpad - dsc // offset of baseline within overall line
 	vpos := vpad + vbaseoff
 
+	override := txtSty.UnicodeBidi == gist.BidiBidiOverride
+	rtl := false
 	for si := range tr.Spans {
 		sr := &(tr.Spans[si])
 		if si > 0 && sr.IsNewPara() {
 			vpos += txtSty.ParaSpacing.Dots
 		}
+		if imgExtra != nil {
+			vpos += imgExtra[si]
+		}
+		if si == 0 || sr.IsNewPara() {
+			rtl = tr.ParaRTL(si, txtSty)
+		}
+		sr.SetBidiLR(rtl, override)
 		sr.RelPos.Y = vpos
 		sr.LastPos.Y = vpos
 		ssz := sr.SizeHV()
@@ -974,7 +1134,7 @@ func (tr *Text) LayoutStdLR(txtSty *gist.Text, fontSty *gist.Font, ctxt *units.C
 			switch {
 			case gist.IsAlignMiddle(txtSty.Align):
 				sr.RelPos.X += hextra / 2
-			case gist.IsAlignEnd(txtSty.Align):
+			case gist.IsAlignEnd(txtSty.Align) != rtl: // start and end swap for RTL
 				sr.RelPos.X += hextra
 			}
 		}
@@ -983,6 +1143,30 @@ func (tr *Text) LayoutStdLR(txtSty *gist.Text, fontSty *gist.Font, ctxt *units.C
 	return size
 }
 
+// ParaRTL returns true if the paragraph starting at given span (continuing
+// until the next span that starts a new paragraph) has right-to-left
+// direction -- see the ParaRTL function
+func (tr *Text) ParaRTL(si int, txtSty *gist.Text) bool {
+	if txtSty.UnicodeBidi != gist.BidiNormal {
+		return IsRTL(txtSty.Direction)
+	}
+	for i := si; i < len(tr.Spans); i++ {
+		sr := &tr.Spans[i]
+		if i > si && sr.IsNewPara() {
+			break
+		}
+		for _, r := range sr.Text {
+			switch bidiClass(r) {
+			case bidi.L:
+				return false
+			case bidi.R, bidi.AL:
+				return true
+			}
+		}
+	}
+	return false
+}
+
 //////////////////////////////////////////////////////////////////////////////////
 //  Utilities
 
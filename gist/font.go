@@ -20,19 +20,20 @@ import (
 // is used in SVG text rendering -- used in Paint and in Style. Most of font
 // information is inherited.
 type Font struct {
-	Color   Color           `xml:"color" inherit:"true" desc:"prop: color (inherited) = text color -- also defines the currentColor variable value"`
-	BgColor ColorSpec       `xml:"background-color" desc:"prop: background-color = background color -- not inherited, transparent by default"`
-	Opacity float32         `xml:"opacity" desc:"prop: opacity = alpha value to apply to all elements"`
-	Size    units.Value     `xml:"font-size" inherit:"true" desc:"prop: font-size (inherited)= size of font to render -- convert to points when getting font to use"`
-	Family  string          `xml:"font-family" inherit:"true" desc:"prop: font-family = font family -- ordered list of comma-separated names from more general to more specific to use -- use split on , to parse"`
-	Style   FontStyles      `xml:"font-style" inherit:"true" desc:"prop: font-style = style -- normal, italic, etc"`
-	Weight  FontWeights     `xml:"font-weight" inherit:"true" desc:"prop: font-weight = weight: normal, bold, etc"`
-	Stretch FontStretch     `xml:"font-stretch" inherit:"true" desc:"prop: font-stretch = font stretch / condense options"`
-	Variant FontVariants    `xml:"font-variant" inherit:"true" desc:"prop: font-variant = normal or small caps"`
-	Deco    TextDecorations `xml:"text-decoration" desc:"prop: text-decoration = underline, line-through, etc -- not inherited"`
-	Shift   BaselineShifts  `xml:"baseline-shift" desc:"prop: baseline-shift = super / sub script -- not inherited"`
-	Face    *FontFace       `view:"-" desc:"full font information including enhanced metrics and actual font codes for drawing text -- this is a pointer into FontLibrary of loaded fonts"`
-	Rem     float32         `desc:"Rem size of font -- 12pt converted to same effective DPI as above measurements"`
+	Color     Color           `xml:"color" inherit:"true" desc:"prop: color (inherited) = text color -- also defines the currentColor variable value"`
+	BgColor   ColorSpec       `xml:"background-color" desc:"prop: background-color = background color -- not inherited, transparent by default"`
+	Opacity   float32         `xml:"opacity" desc:"prop: opacity = alpha value to apply to all elements"`
+	Size      units.Value     `xml:"font-size" inherit:"true" desc:"prop: font-size (inherited)= size of font to render -- convert to points when getting font to use"`
+	Family    string          `xml:"font-family" inherit:"true" desc:"prop: font-family = font family -- ordered list of comma-separated names from more general to more specific to use -- use split on , to parse"`
+	Style     FontStyles      `xml:"font-style" inherit:"true" desc:"prop: font-style = style -- normal, italic, etc"`
+	Weight    FontWeights     `xml:"font-weight" inherit:"true" desc:"prop: font-weight = weight: normal, bold, etc"`
+	Stretch   FontStretch     `xml:"font-stretch" inherit:"true" desc:"prop: font-stretch = font stretch / condense options"`
+	Variant   FontVariants    `xml:"font-variant" inherit:"true" desc:"prop: font-variant = normal or small caps"`
+	Deco      TextDecorations `xml:"text-decoration" desc:"prop: text-decoration = underline, line-through, etc -- not inherited"`
+	DecoColor Color           `xml:"text-decoration-color" desc:"prop: text-decoration-color = color of underline, line-through etc -- nil = use text color -- not inherited"`
+	Shift     BaselineShifts  `xml:"baseline-shift" desc:"prop: baseline-shift = super / sub script -- not inherited"`
+	Face      *FontFace       `view:"-" desc:"full font information including enhanced metrics and actual font codes for drawing text -- this is a pointer into FontLibrary of loaded fonts"`
+	Rem       float32         `desc:"Rem size of font -- 12pt converted to same effective DPI as above measurements"`
 	// todo: kerning
 	// todo: stretch -- css 3 -- not supported
 }
@@ -73,6 +74,15 @@ func (fs *Font) SetDeco(deco TextDecorations) {
 	bitflag.Set32((*int32)(&fs.Deco), int(deco))
 }
 
+// DecoColorOrNil returns the decoration color, or nil if not set, in which
+// case the text color is used
+func (fs *Font) DecoColorOrNil() color.Color {
+	if fs.DecoColor.IsNil() {
+		return nil
+	}
+	return fs.DecoColor
+}
+
 // ClearDeco clears decoration (underline, etc), which uses bitflag to allow
 // multiple combinations
 func (fs *Font) ClearDeco(deco TextDecorations) {
@@ -145,6 +155,9 @@ func (fs *Font) CopyNonDefaultProps(node ki.Ki) {
 	if fs.Deco != DecoNone {
 		node.SetProp("font-decoration", fs.Deco)
 	}
+	if !fs.DecoColor.IsNil() {
+		node.SetProp("text-decoration-color", fs.DecoColor)
+	}
 	if fs.Shift != ShiftBaseline {
 		node.SetProp("baseline-shift", fs.Shift)
 	}
@@ -323,6 +336,15 @@ const (
 	DecoSub
 	// DecoBgColor indicates that a bg color has been set -- for use in optimizing rendering
 	DecoBgColor
+
+	// DecoWavyUnderline is a wavy "squiggle" underline, used for spelling
+	// and lint errors in text editors -- it and DecoDashedUnderline come
+	// after the layout hints, so that their values are unchanged
+	DecoWavyUnderline
+
+	// DecoDashedUnderline is a dashed underline
+	DecoDashedUnderline
+
 	TextDecorationsN
 )
 
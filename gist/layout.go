@@ -5,6 +5,11 @@
 package gist
 
 import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
 	"github.com/goki/gi/units"
 	"github.com/goki/ki/ki"
 	"github.com/goki/ki/kit"
@@ -36,26 +41,31 @@ var ScrollBarWidthDefault = float32(16)
 
 // Layout contains style preferences on the layout of the element.
 type Layout struct {
-	ZIndex         int         `xml:"z-index" desc:"prop: z-index = ordering factor for rendering depth -- lower numbers rendered first -- sort children according to this factor"`
-	AlignH         Align       `xml:"horizontal-align" desc:"prop: horizontal-align specifies the horizontal alignment of widget elements within a *vertical* layout container (has no effect within horizontal layouts -- use space / stretch elements instead).  For text layout, use text-align. This is not a standard css property."`
-	AlignV         Align       `xml:"vertical-align" desc:"prop: vertical-align specifies the vertical alignment of widget elements within a *horizontal* layout container (has no effect within vertical layouts -- use space / stretch elements instead).  For text layout, use text-vertical-align.  This is not a standard css property"`
-	PosX           units.Value `xml:"x" desc:"prop: x = horizontal position -- often superseded by layout but otherwise used"`
-	PosY           units.Value `xml:"y" desc:"prop: y = vertical position -- often superseded by layout but otherwise used"`
-	Width          units.Value `xml:"width" desc:"prop: width = specified size of element -- 0 if not specified"`
-	Height         units.Value `xml:"height" desc:"prop: height = specified size of element -- 0 if not specified"`
-	MaxWidth       units.Value `xml:"max-width" desc:"prop: max-width = specified maximum size of element -- 0  means just use other values, negative means stretch"`
-	MaxHeight      units.Value `xml:"max-height" desc:"prop: max-height = specified maximum size of element -- 0 means just use other values, negative means stretch"`
-	MinWidth       units.Value `xml:"min-width" desc:"prop: min-width = specified minimum size of element -- 0 if not specified"`
-	MinHeight      units.Value `xml:"min-height" desc:"prop: min-height = specified minimum size of element -- 0 if not specified"`
-	Margin         units.Value `xml:"margin" desc:"prop: margin = outer-most transparent space around box element -- todo: can be specified per side"`
-	Padding        units.Value `xml:"padding" desc:"prop: padding = transparent space around central content of box -- todo: if 4 values it is top, right, bottom, left; 3 is top, right&left, bottom; 2 is top & bottom, right and left"`
-	Overflow       Overflow    `xml:"overflow" desc:"prop: overflow = what to do with content that overflows -- default is Auto add of scrollbars as needed -- todo: can have separate -x -y values"`
-	Columns        int         `xml:"columns" alt:"grid-cols" desc:"prop: columns = number of columns to use in a grid layout -- used as a constraint in layout if individual elements do not specify their row, column positions"`
-	Row            int         `xml:"row" desc:"prop: row = specifies the row that this element should appear within a grid layout"`
-	Col            int         `xml:"col" desc:"prop: col = specifies the column that this element should appear within a grid layout"`
-	RowSpan        int         `xml:"row-span" desc:"prop: row-span = specifies the number of sequential rows that this element should occupy within a grid layout (todo: not currently supported)"`
-	ColSpan        int         `xml:"col-span" desc:"prop: col-span = specifies the number of sequential columns that this element should occupy within a grid layout"`
-	ScrollBarWidth units.Value `xml:"scrollbar-width" desc:"prop: scrollbar-width = width of a layout scrollbar"`
+	ZIndex              int         `xml:"z-index" desc:"prop: z-index = ordering factor for rendering depth -- lower numbers rendered first -- sort children according to this factor"`
+	AlignH              Align       `xml:"horizontal-align" desc:"prop: horizontal-align specifies the horizontal alignment of widget elements within a *vertical* layout container (has no effect within horizontal layouts -- use space / stretch elements instead).  For text layout, use text-align. This is not a standard css property."`
+	AlignV              Align       `xml:"vertical-align" desc:"prop: vertical-align specifies the vertical alignment of widget elements within a *horizontal* layout container (has no effect within vertical layouts -- use space / stretch elements instead).  For text layout, use text-vertical-align.  This is not a standard css property"`
+	PosX                units.Value `xml:"x" desc:"prop: x = horizontal position -- often superseded by layout but otherwise used"`
+	PosY                units.Value `xml:"y" desc:"prop: y = vertical position -- often superseded by layout but otherwise used"`
+	Width               units.Value `xml:"width" desc:"prop: width = specified size of element -- 0 if not specified"`
+	Height              units.Value `xml:"height" desc:"prop: height = specified size of element -- 0 if not specified"`
+	MaxWidth            units.Value `xml:"max-width" desc:"prop: max-width = specified maximum size of element -- 0  means just use other values, negative means stretch"`
+	MaxHeight           units.Value `xml:"max-height" desc:"prop: max-height = specified maximum size of element -- 0 means just use other values, negative means stretch"`
+	MinWidth            units.Value `xml:"min-width" desc:"prop: min-width = specified minimum size of element -- 0 if not specified"`
+	MinHeight           units.Value `xml:"min-height" desc:"prop: min-height = specified minimum size of element -- 0 if not specified"`
+	Margin              units.Value `xml:"margin" desc:"prop: margin = outer-most transparent space around box element -- todo: can be specified per side"`
+	Padding             units.Value `xml:"padding" desc:"prop: padding = transparent space around central content of box -- todo: if 4 values it is top, right, bottom, left; 3 is top, right&left, bottom; 2 is top & bottom, right and left"`
+	Overflow            Overflow    `xml:"overflow" desc:"prop: overflow = what to do with content that overflows -- default is Auto add of scrollbars as needed -- todo: can have separate -x -y values"`
+	Columns             int         `xml:"columns" alt:"grid-cols" desc:"prop: columns = number of columns to use in a grid layout -- used as a constraint in layout if individual elements do not specify their row, column positions"`
+	Row                 int         `xml:"row" desc:"prop: row = specifies the row that this element should appear within a grid layout"`
+	Col                 int         `xml:"col" desc:"prop: col = specifies the column that this element should appear within a grid layout"`
+	RowSpan             int         `xml:"row-span" desc:"prop: row-span = specifies the number of sequential rows that this element should occupy within a grid layout (only supported by LayoutGridTemplate)"`
+	ColSpan             int         `xml:"col-span" desc:"prop: col-span = specifies the number of sequential columns that this element should occupy within a grid layout"`
+	ScrollBarWidth      units.Value `xml:"scrollbar-width" desc:"prop: scrollbar-width = width of a layout scrollbar"`
+	GridTemplateColumns []GridTrack `xml:"grid-template-columns" desc:"prop: grid-template-columns = sizes of the columns in a LayoutGridTemplate layout, as a space-separated list of tracks (see ParseGridTemplate), e.g., auto 1fr 2fr 10em -- the number of tracks determines the number of columns"`
+	GridTemplateRows    []GridTrack `xml:"grid-template-rows" desc:"prop: grid-template-rows = sizes of the rows in a LayoutGridTemplate layout (see grid-template-columns) -- rows beyond those specified are auto sized"`
+	FlexGrow            float32     `xml:"flex-grow" desc:"prop: flex-grow = how much this element grows, relative to the others, to take up extra space along the main dimension of a LayoutFlexHoriz or LayoutFlexVert layout -- 0 = does not grow"`
+	FlexShrink          float32     `xml:"flex-shrink" desc:"prop: flex-shrink = how much this element shrinks (in proportion to its flex-basis), relative to the others, when there is not enough space along the main dimension of a flex layout -- 0 = does not shrink below its flex-basis"`
+	FlexBasis           units.Value `xml:"flex-basis" desc:"prop: flex-basis = initial size of this element along the main dimension of a flex layout, before growing or shrinking -- 0 = use its preferred size.  The flex property sets flex-grow, flex-shrink and flex-basis together, e.g., flex: 1 (= 1 1 0) or flex: 2 0 10em"`
 }
 
 func (ls *Layout) Defaults() {
@@ -63,6 +73,7 @@ func (ls *Layout) Defaults() {
 	ls.MinWidth.Set(2.0, units.Px)
 	ls.MinHeight.Set(2.0, units.Px)
 	ls.ScrollBarWidth.Set(ScrollBarWidthDefault, units.Px)
+	ls.FlexShrink = 1
 }
 
 func (ls *Layout) SetStylePost(props ki.Props) {
@@ -111,6 +122,118 @@ func (ly *Layout) ToDots(uc *units.Context) {
 	ly.Margin.ToDots(uc)
 	ly.Padding.ToDots(uc)
 	ly.ScrollBarWidth.ToDots(uc)
+	ly.FlexBasis.ToDots(uc)
+	for i := range ly.GridTemplateColumns {
+		ly.GridTemplateColumns[i].Size.ToDots(uc)
+	}
+	for i := range ly.GridTemplateRows {
+		ly.GridTemplateRows[i].Size.ToDots(uc)
+	}
+}
+
+// GridTrack is the size of one column or row (track) of a grid template
+// layout, which is either a fixed size, auto-sized to fit its contents, or
+// a fraction (fr) of the space remaining after the other tracks
+type GridTrack struct {
+	Size units.Value `desc:"fixed size of the track, if not Auto or Fr"`
+	Auto bool        `desc:"track is sized to fit its contents"`
+	Fr   float32     `desc:"if > 0, track gets this fraction of the remaining space, relative to the other fr tracks -- its contents determine its minimum size"`
+}
+
+// String returns the CSS representation of the track
+func (gt GridTrack) String() string {
+	switch {
+	case gt.Auto:
+		return "auto"
+	case gt.Fr > 0:
+		return strconv.FormatFloat(float64(gt.Fr), 'g', -1, 32) + "fr"
+	default:
+		return gt.Size.String()
+	}
+}
+
+// ParseGridTemplate parses a CSS grid-template-columns / rows style list of
+// tracks, separated by spaces, where each track is auto, a fraction of
+// remaining space, e.g., 1fr, or a fixed units value, e.g., 10em or 100px.
+// repeat(n, tracks...) repeats the given tracks n times.
+func ParseGridTemplate(str string) ([]GridTrack, error) {
+	var tracks []GridTrack
+	str = strings.TrimSpace(str)
+	for len(str) > 0 {
+		if strings.HasPrefix(str, "repeat(") {
+			ep := strings.Index(str, ")")
+			if ep < 0 {
+				return tracks, fmt.Errorf("gist.ParseGridTemplate: repeat missing closing paren: %v", str)
+			}
+			args := strings.SplitN(str[len("repeat("):ep], ",", 2)
+			if len(args) != 2 {
+				return tracks, fmt.Errorf("gist.ParseGridTemplate: repeat must have count and tracks: %v", str[:ep+1])
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(args[0]))
+			if err != nil || n < 1 {
+				return tracks, fmt.Errorf("gist.ParseGridTemplate: invalid repeat count: %v", args[0])
+			}
+			rtr, err := ParseGridTemplate(args[1])
+			if err != nil {
+				return tracks, err
+			}
+			for i := 0; i < n; i++ {
+				tracks = append(tracks, rtr...)
+			}
+			str = strings.TrimSpace(str[ep+1:])
+			continue
+		}
+		fld := str
+		if sp := strings.IndexAny(str, " \t"); sp >= 0 {
+			fld = str[:sp]
+		}
+		str = strings.TrimSpace(str[len(fld):])
+		lfld := strings.ToLower(fld)
+		switch {
+		case lfld == "auto":
+			tracks = append(tracks, GridTrack{Auto: true})
+		case strings.HasSuffix(lfld, "fr"):
+			fr, err := strconv.ParseFloat(lfld[:len(lfld)-2], 32)
+			if err != nil || fr <= 0 {
+				return tracks, fmt.Errorf("gist.ParseGridTemplate: invalid fraction: %v", fld)
+			}
+			tracks = append(tracks, GridTrack{Fr: float32(fr)})
+		default:
+			num := strings.TrimRight(lfld, "abcdefghijklmnopqrstuvwxyz%")
+			_, err := strconv.ParseFloat(num, 32)
+			if un := lfld[len(num):]; err == nil && un != "" && un != "%" {
+				err = fmt.Errorf("unknown units: %v", un)
+				for _, nm := range units.UnitNames {
+					if un == nm {
+						err = nil
+						break
+					}
+				}
+			}
+			if err != nil {
+				return tracks, fmt.Errorf("gist.ParseGridTemplate: invalid track size: %v", fld)
+			}
+			tracks = append(tracks, GridTrack{Size: units.StringToValue(fld)})
+		}
+	}
+	return tracks, nil
+}
+
+// SetGridTemplateProp sets given grid template tracks from a property value,
+// which can be a string (see ParseGridTemplate) or a []GridTrack
+func SetGridTemplateProp(tracks *[]GridTrack, val interface{}, key string) {
+	switch vt := val.(type) {
+	case string:
+		gt, err := ParseGridTemplate(vt)
+		if err != nil {
+			log.Println(err)
+		}
+		*tracks = gt
+	case []GridTrack:
+		*tracks = append([]GridTrack{}, vt...) // own copy, for ToDots
+	default:
+		StyleSetError(key, val)
+	}
 }
 
 // Align has all different types of alignment -- only some are applicable to
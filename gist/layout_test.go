@@ -0,0 +1,47 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gist
+
+import (
+	"testing"
+
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+)
+
+func TestParseGridTemplate(t *testing.T) {
+	tracks, err := ParseGridTemplate("auto 1fr repeat(2, 10em 2.5fr) 100px")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []GridTrack{{Auto: true}, {Fr: 1}, {Size: units.NewEm(10)}, {Fr: 2.5}, {Size: units.NewEm(10)}, {Fr: 2.5}, {Size: units.NewPx(100)}}
+	if len(tracks) != len(exp) {
+		t.Fatalf("got %d tracks: %v, expected %d", len(tracks), tracks, len(exp))
+	}
+	for i := range exp {
+		if tracks[i] != exp[i] {
+			t.Errorf("track %d: %v != expected: %v", i, tracks[i], exp[i])
+		}
+	}
+	for _, bad := range []string{"1fx", "-1fr", "repeat(2 1fr)", "repeat(x, auto)"} {
+		if _, err := ParseGridTemplate(bad); err == nil {
+			t.Errorf("expected error for: %v", bad)
+		}
+	}
+}
+
+func TestFlexProps(t *testing.T) {
+	var s, p Style
+	s.Defaults()
+	p.Defaults()
+	s.SetStyleProps(&p, ki.Props{"flex": "2 0 10em"}, nil)
+	if s.Layout.FlexGrow != 2 || s.Layout.FlexShrink != 0 || s.Layout.FlexBasis != units.NewEm(10) {
+		t.Errorf("flex: 2 0 10em: got grow: %v shrink: %v basis: %v", s.Layout.FlexGrow, s.Layout.FlexShrink, s.Layout.FlexBasis)
+	}
+	s.SetStyleProps(&p, ki.Props{"flex": 1}, nil)
+	if s.Layout.FlexGrow != 1 || s.Layout.FlexShrink != 1 || s.Layout.FlexBasis.Val != 0 {
+		t.Errorf("flex: 1: got grow: %v shrink: %v basis: %v", s.Layout.FlexGrow, s.Layout.FlexShrink, s.Layout.FlexBasis)
+	}
+}
@@ -190,6 +190,32 @@ func (s *Style) InheritFields(par *Style) {
 	s.Text.InheritFields(&par.Text)
 }
 
+// StyleInheritedProps are the style property keys whose values are
+// inherited from the parent by InheritFields, if not set on an element
+var StyleInheritedProps = map[string]bool{
+	"color":                        true,
+	"font-family":                  true,
+	"font-style":                   true,
+	"font-size":                    true,
+	"font-weight":                  true,
+	"font-stretch":                 true,
+	"font-variant":                 true,
+	"text-align":                   true,
+	"text-vertical-align":          true,
+	"text-anchor":                  true,
+	"word-spacing":                 true,
+	"line-height":                  true,
+	"unicode-bidi":                 true,
+	"direction":                    true,
+	"writing-mode":                 true,
+	"glyph-orientation-vertical":   true,
+	"glyph-orientation-horizontal": true,
+	"text-indent":                  true,
+	"wrap-indent":                  true,
+	"para-spacing":                 true,
+	"tab-size":                     true,
+}
+
 // SetStyleProps sets style values based on given property map (name: value pairs),
 // inheriting elements as appropriate from parent
 func (s *Style) SetStyleProps(par *Style, props ki.Props, ctxt Context) {
@@ -7,6 +7,8 @@ package gist
 import (
 	"image/color"
 	"log"
+	"strconv"
+	"strings"
 
 	"github.com/goki/gi/units"
 	"github.com/goki/ki/ki"
@@ -457,6 +459,122 @@ var StyleLayoutFuncs = map[string]StyleFunc{
 		}
 		ly.ScrollBarWidth.SetIFace(val, key)
 	},
+	"grid-template-columns": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ly := obj.(*Layout)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				ly.GridTemplateColumns = par.(*Layout).GridTemplateColumns
+			} else if init {
+				ly.GridTemplateColumns = nil
+			}
+			return
+		}
+		SetGridTemplateProp(&ly.GridTemplateColumns, val, key)
+	},
+	"grid-template-rows": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ly := obj.(*Layout)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				ly.GridTemplateRows = par.(*Layout).GridTemplateRows
+			} else if init {
+				ly.GridTemplateRows = nil
+			}
+			return
+		}
+		SetGridTemplateProp(&ly.GridTemplateRows, val, key)
+	},
+	"flex-grow": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ly := obj.(*Layout)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				ly.FlexGrow = par.(*Layout).FlexGrow
+			} else if init {
+				ly.FlexGrow = 0
+			}
+			return
+		}
+		if fv, ok := kit.ToFloat32(val); ok {
+			ly.FlexGrow = fv
+		}
+	},
+	"flex-shrink": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ly := obj.(*Layout)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				ly.FlexShrink = par.(*Layout).FlexShrink
+			} else if init {
+				ly.FlexShrink = 1
+			}
+			return
+		}
+		if fv, ok := kit.ToFloat32(val); ok {
+			ly.FlexShrink = fv
+		}
+	},
+	"flex-basis": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ly := obj.(*Layout)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				ly.FlexBasis = par.(*Layout).FlexBasis
+			} else if init {
+				ly.FlexBasis.Val = 0
+			}
+			return
+		}
+		if str, ok := val.(string); ok && str == "auto" {
+			ly.FlexBasis.Val = 0
+			return
+		}
+		ly.FlexBasis.SetIFace(val, key)
+	},
+	"flex": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ly := obj.(*Layout)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				pl := par.(*Layout)
+				ly.FlexGrow, ly.FlexShrink, ly.FlexBasis = pl.FlexGrow, pl.FlexShrink, pl.FlexBasis
+			} else if init {
+				ly.FlexGrow, ly.FlexShrink, ly.FlexBasis = 0, 1, units.Value{}
+			}
+			return
+		}
+		str, ok := val.(string)
+		if !ok {
+			if fv, ok := kit.ToFloat32(val); ok { // flex: n = n 1 0
+				ly.FlexGrow, ly.FlexShrink, ly.FlexBasis = fv, 1, units.Value{}
+			} else {
+				StyleSetError(key, val)
+			}
+			return
+		}
+		switch str {
+		case "none":
+			ly.FlexGrow, ly.FlexShrink, ly.FlexBasis = 0, 0, units.Value{}
+			return
+		case "auto":
+			ly.FlexGrow, ly.FlexShrink, ly.FlexBasis = 1, 1, units.Value{}
+			return
+		}
+		ly.FlexGrow, ly.FlexShrink, ly.FlexBasis = 0, 1, units.Value{}
+		for i, fs := range strings.Fields(str) {
+			switch i {
+			case 0:
+				if fv, err := strconv.ParseFloat(fs, 32); err == nil {
+					ly.FlexGrow = float32(fv)
+				} else {
+					StyleSetError(key, val)
+				}
+			case 1:
+				if fv, err := strconv.ParseFloat(fs, 32); err == nil {
+					ly.FlexShrink = float32(fv)
+				} else {
+					ly.FlexBasis.SetString(fs) // flex: grow basis
+				}
+			case 2:
+				ly.FlexBasis.SetString(fs)
+			}
+		}
+	},
 }
 
 /////////////////////////////////////////////////////////////////////////////////
@@ -654,6 +772,18 @@ var StyleFontFuncs = map[string]StyleFunc{
 			}
 		}
 	},
+	"text-decoration-color": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		fs := obj.(*Font)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				fs.DecoColor = par.(*Font).DecoColor
+			} else if init {
+				fs.DecoColor.SetToNil()
+			}
+			return
+		}
+		fs.DecoColor.SetIFace(val, ctxt, key)
+	},
 	"baseline-shift": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
 		fs := obj.(*Font)
 		if inh, init := StyleInhInit(val, par); inh || init {
@@ -923,6 +1053,18 @@ var StyleTextFuncs = map[string]StyleFunc{
 		}
 		ts.Indent.SetIFace(val, key)
 	},
+	"wrap-indent": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
+		ts := obj.(*Text)
+		if inh, init := StyleInhInit(val, par); inh || init {
+			if inh {
+				ts.WrapIndent = par.(*Text).WrapIndent
+			} else if init {
+				ts.WrapIndent.Val = 0
+			}
+			return
+		}
+		ts.WrapIndent.SetIFace(val, key)
+	},
 	"para-spacing": func(obj interface{}, key string, val interface{}, par interface{}, ctxt Context) {
 		ts := obj.(*Text)
 		if inh, init := StyleInhInit(val, par); inh || init {
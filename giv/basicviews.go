@@ -8,12 +8,15 @@ import (
 	"fmt"
 	"log"
 	"reflect"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/units"
 	"github.com/goki/ki/ki"
 	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
 )
 
 // basicviews contains all the ValueView's for basic builtin types
@@ -618,6 +621,7 @@ func (vv *IntValueView) ConfigWidget(widg gi.Node2D) {
 	if fmttag, ok := vv.Tag("format"); ok {
 		sb.Format = fmttag
 	}
+	sb.Scale, sb.Mapping, sb.Unit, sb.SIPrefix, sb.Snap = vv.NumberScaleTags()
 	sb.SpinBoxSig.ConnectOnly(vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 		vvv, _ := recv.Embed(KiT_IntValueView).(*IntValueView)
 		sbb := vvv.Widget.(*gi.SpinBox)
@@ -688,6 +692,7 @@ func (vv *FloatValueView) ConfigWidget(widg gi.Node2D) {
 	if fmttag, ok := vv.Tag("format"); ok {
 		sb.Format = fmttag
 	}
+	sb.Scale, sb.Mapping, sb.Unit, sb.SIPrefix, sb.Snap = vv.NumberScaleTags()
 
 	sb.SpinBoxSig.ConnectOnly(vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 		vvv, _ := recv.Embed(KiT_FloatValueView).(*FloatValueView)
@@ -699,6 +704,174 @@ func (vv *FloatValueView) ConfigWidget(widg gi.Node2D) {
 	vv.UpdateWidget()
 }
 
+////////////////////////////////////////////////////////////////////////////////////////
+//  SliderValueView
+
+// SliderValueView presents a slider for a number, for fields with a
+// view:"slider" tag -- the min and max tags give its range, which is 0..1 by
+// default, and the step tag and those of NumberScaleTags are as for
+// IntValueView and FloatValueView -- the value is shown in the tooltip
+type SliderValueView struct {
+	ValueViewBase
+}
+
+var KiT_SliderValueView = kit.Types.AddType(&SliderValueView{}, nil)
+
+func (vv *SliderValueView) WidgetType() reflect.Type {
+	vv.WidgetTyp = gi.KiT_Slider
+	return vv.WidgetTyp
+}
+
+func (vv *SliderValueView) UpdateWidget() {
+	if vv.Widget == nil {
+		return
+	}
+	sl := vv.Widget.(*gi.Slider)
+	npv := kit.NonPtrValue(vv.Value)
+	fv, ok := kit.ToFloat32(npv.Interface())
+	if ok {
+		sl.SetValue(fv)
+	}
+	sl.Tooltip = sl.ValueString()
+	if desc, ok := vv.Tag("desc"); ok && desc != "" {
+		sl.Tooltip = desc + ": " + sl.Tooltip
+	}
+}
+
+func (vv *SliderValueView) ConfigWidget(widg gi.Node2D) {
+	vv.Widget = widg
+	vv.StdConfigWidget(widg)
+	sl := vv.Widget.(*gi.Slider)
+	sl.SetInactiveState(vv.This().(ValueView).IsInactive())
+	sl.Defaults()
+	sl.Dim = mat32.X
+	sl.SetMinPrefWidth(units.NewEm(20))
+	sl.SetMinPrefHeight(units.NewEm(1))
+	vk := kit.NonPtrValue(vv.Value).Kind()
+	if vk >= reflect.Int && vk <= reflect.Uint64 {
+		sl.Step = 1
+		sl.Snap = true
+	}
+	if mintag, ok := vv.Tag("min"); ok {
+		if minv, ok := kit.ToFloat32(mintag); ok {
+			sl.Min = minv
+		}
+	}
+	if maxtag, ok := vv.Tag("max"); ok {
+		if maxv, ok := kit.ToFloat32(maxtag); ok {
+			sl.Max = maxv
+		}
+	}
+	if steptag, ok := vv.Tag("step"); ok {
+		if step, ok := kit.ToFloat32(steptag); ok {
+			sl.Step = step
+			sl.PageStep = 10 * step
+		}
+	}
+	var snap bool
+	sl.Scale, sl.Mapping, sl.Unit, sl.SIPrefix, snap = vv.NumberScaleTags()
+	sl.Snap = sl.Snap || snap
+	sl.SliderSig.ConnectOnly(vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig != int64(gi.SliderValueChanged) {
+			return
+		}
+		vvv, _ := recv.Embed(KiT_SliderValueView).(*SliderValueView)
+		slv := vvv.Widget.(*gi.Slider)
+		if vvv.SetValue(slv.Value) {
+			vvv.UpdateWidget()
+		}
+	})
+	vv.UpdateWidget()
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  ProgressValueView
+
+// ProgressValueView presents an inactive progress bar for a number, for
+// fields with a view:"progress" tag, e.g., for a column of a TableView --
+// the min and max tags give the range of values that fill the bar, which is
+// 0..1 by default -- the value is shown in the tooltip
+type ProgressValueView struct {
+	ValueViewBase
+}
+
+var KiT_ProgressValueView = kit.Types.AddType(&ProgressValueView{}, nil)
+
+func (vv *ProgressValueView) WidgetType() reflect.Type {
+	vv.WidgetTyp = gi.KiT_ProgressBar
+	return vv.WidgetTyp
+}
+
+// Range returns the range of values that fill the bar, from the min and max tags
+func (vv *ProgressValueView) Range() (min, max float32) {
+	min, max = 0, 1
+	if mintag, ok := vv.Tag("min"); ok {
+		if minv, ok := kit.ToFloat32(mintag); ok {
+			min = minv
+		}
+	}
+	if maxtag, ok := vv.Tag("max"); ok {
+		if maxv, ok := kit.ToFloat32(maxtag); ok {
+			max = maxv
+		}
+	}
+	return
+}
+
+func (vv *ProgressValueView) UpdateWidget() {
+	if vv.Widget == nil {
+		return
+	}
+	pb := vv.Widget.(*gi.ProgressBar)
+	npv := kit.NonPtrValue(vv.Value)
+	fv, ok := kit.ToFloat32(npv.Interface())
+	if !ok {
+		return
+	}
+	min, max := vv.Range()
+	prop := float32(0)
+	if max > min {
+		prop = mat32.Clamp((fv-min)/(max-min), 0, 1)
+	}
+	pb.SetProgress(prop)
+	pb.Tooltip = fmt.Sprintf("%.4g", fv)
+	if desc, ok := vv.Tag("desc"); ok && desc != "" {
+		pb.Tooltip = desc + ": " + pb.Tooltip
+	}
+}
+
+func (vv *ProgressValueView) ConfigWidget(widg gi.Node2D) {
+	vv.Widget = widg
+	vv.StdConfigWidget(widg)
+	pb := vv.Widget.(*gi.ProgressBar)
+	pb.Defaults()
+	pb.SetMinPrefWidth(units.NewEm(8))
+	vv.UpdateWidget()
+}
+
+// NumberScaleTags returns the scale of the values, and the unit, of the
+// tags of a number field: scale:"log" (or linear, or the name of a custom
+// mapping in gi.ValueMappings), unit:"s", si-prefix:"+" for showing values
+// with the SI prefix of their magnitude, e.g., 10ms, and snap:"+" for
+// snapping values that are entered to step increments
+func (vv *ValueViewBase) NumberScaleTags() (scale gi.ValueScales, mapping gi.ValueMapping, unit string, siPrefix, snap bool) {
+	if sctag, ok := vv.Tag("scale"); ok {
+		var ok bool
+		scale, mapping, ok = gi.ScaleFromName(sctag)
+		if !ok {
+			log.Printf("giv.ValueView: unknown scale in scale tag: %v\n", sctag)
+		}
+	}
+	unit, _ = vv.Tag("unit")
+	if sitag, ok := vv.Tag("si-prefix"); ok {
+		siPrefix = sitag == "+" || sitag == "true"
+	}
+	if sntag, ok := vv.Tag("snap"); ok {
+		snap = sntag == "+" || sntag == "true"
+	}
+	return
+}
+
 ////////////////////////////////////////////////////////////////////////////////////////
 //  EnumValueView
 
@@ -765,6 +938,67 @@ func (vv *EnumValueView) ConfigWidget(widg gi.Node2D) {
 	vv.UpdateWidget()
 }
 
+////////////////////////////////////////////////////////////////////////////////////////
+//  EnumIconValueView
+
+// EnumIconValueView presents an icon for each value of an enum, or any other
+// value, for fields with a view:"icon" tag, e.g., for a status column of a
+// TableView -- the icons tag gives the icon for each value, as a list of
+// value:icon pairs, e.g., icons:"Running:play,Stopped:stop", and otherwise
+// the icon is the lower-case name of the value -- the name of the value is
+// shown in the tooltip
+type EnumIconValueView struct {
+	ValueViewBase
+}
+
+var KiT_EnumIconValueView = kit.Types.AddType(&EnumIconValueView{}, nil)
+
+func (vv *EnumIconValueView) WidgetType() reflect.Type {
+	vv.WidgetTyp = gi.KiT_Action
+	return vv.WidgetTyp
+}
+
+// IconFor returns the icon for given value name, from the icons tag
+func (vv *EnumIconValueView) IconFor(val string) gi.IconName {
+	if ictag, ok := vv.Tag("icons"); ok {
+		for _, pr := range strings.Split(ictag, ",") {
+			vi := strings.SplitN(pr, ":", 2)
+			if len(vi) == 2 && strings.TrimSpace(vi[0]) == val {
+				return gi.IconName(strings.TrimSpace(vi[1]))
+			}
+		}
+	}
+	return gi.IconName(strings.ToLower(val))
+}
+
+func (vv *EnumIconValueView) UpdateWidget() {
+	if vv.Widget == nil {
+		return
+	}
+	ac := vv.Widget.(*gi.Action)
+	txt := kit.ToString(kit.NonPtrValue(vv.Value).Interface())
+	ic := vv.IconFor(txt)
+	if !ic.IsValid() {
+		ic = "blank"
+	}
+	ac.SetIcon(string(ic))
+	ac.Tooltip = txt
+	if desc, ok := vv.Tag("desc"); ok && desc != "" {
+		ac.Tooltip = desc + ": " + txt
+	}
+}
+
+func (vv *EnumIconValueView) ConfigWidget(widg gi.Node2D) {
+	vv.Widget = widg
+	vv.StdConfigWidget(widg)
+	ac := vv.Widget.(*gi.Action)
+	ac.SetProp("border-radius", units.NewPx(4))
+	ac.SetProp("padding", 0)
+	ac.SetProp("margin", 0)
+	ac.SetInactive()
+	vv.UpdateWidget()
+}
+
 ////////////////////////////////////////////////////////////////////////////////////////
 //  BitFlagView
 
@@ -897,6 +1131,150 @@ func (vv *TypeValueView) ConfigWidget(widg gi.Node2D) {
 	vv.UpdateWidget()
 }
 
+////////////////////////////////////////////////////////////////////////////////////////
+//  InterfaceValueView
+
+// InterfaceValueView presents a combobox for choosing the concrete type of
+// an interface value, among the types registered in kit.Types that
+// implement the interface (see InterfaceImplementers), with the ValueView of
+// the current value beneath it -- a new value of the chosen type is made as
+// a pointer, so it can be edited in place
+type InterfaceValueView struct {
+	ValueViewBase
+	ValView ValueView `desc:"value view of the current concrete value, if it is not nil"`
+}
+
+var KiT_InterfaceValueView = kit.Types.AddType(&InterfaceValueView{}, nil)
+
+// InterfaceImplementers returns the types registered in kit.Types that can
+// be the concrete types of given interface type in InterfaceValueView:
+// non-base, non-Ki struct types whose pointer implements it, sorted by name
+func InterfaceImplementers(iface reflect.Type) []reflect.Type {
+	var tl []reflect.Type
+	for _, typ := range kit.Types.AllImplementersOf(iface, false) {
+		if !ki.IsKi(typ) && reflect.PtrTo(typ).Implements(iface) {
+			tl = append(tl, typ)
+		}
+	}
+	sort.Slice(tl, func(i, j int) bool {
+		return tl[i].String() < tl[j].String()
+	})
+	return tl
+}
+
+// InterfaceNilItem is the item for a nil value in the combobox of
+// InterfaceValueView
+var InterfaceNilItem = "nil"
+
+func (vv *InterfaceValueView) WidgetType() reflect.Type {
+	vv.WidgetTyp = gi.KiT_Layout
+	return vv.WidgetTyp
+}
+
+// IsInactive is only true for an inactive tag -- a nil value can be set
+// to a new value of one of the implementing types
+func (vv *InterfaceValueView) IsInactive() bool {
+	if vv.OwnKind == reflect.Struct {
+		if _, ok := vv.Tag("inactive"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// IfaceType returns the interface type of the value
+func (vv *InterfaceValueView) IfaceType() reflect.Type {
+	return kit.NonPtrType(vv.Value.Type())
+}
+
+func (vv *InterfaceValueView) UpdateWidget() {
+	if vv.Widget == nil {
+		return
+	}
+	ly := vv.Widget.(*gi.Layout)
+	ifv := kit.NonPtrValue(vv.Value)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ComboBox, "type")
+	vv.ValView = nil
+	if ifv.IsValid() && !ifv.IsNil() {
+		cur := ifv.Interface()
+		vv.ValView = ToValueView(cur, "")
+		vvb := vv.ValView.AsValueViewBase()
+		vvb.SetSoloValue(reflect.ValueOf(cur))
+		vvb.ViewPath = vv.ViewPath
+		vvb.TmpSave = vv.TmpSave
+		config.Add(vv.ValView.WidgetType(), "value")
+	}
+	mods, updt := ly.ConfigChildren(config, ki.UniqueNames)
+	if mods {
+		ly.SetFullReRender()
+	} else {
+		updt = ly.UpdateStart()
+	}
+	cb := ly.Child(0).(*gi.ComboBox)
+	if vv.ValView == nil {
+		cb.SetCurVal(InterfaceNilItem)
+	} else {
+		cb.SetCurVal(kit.NonPtrType(ifv.Elem().Type()))
+		widg := ly.Child(1).(gi.Node2D)
+		if ifv.Elem().Kind() != reflect.Ptr || vv.This().(ValueView).IsInactive() {
+			vv.ValView.SetTag("inactive", "true") // can only edit pointers in place
+		}
+		vv.ValView.ConfigWidget(widg)
+		vv.ValView.AsValueViewBase().ViewSig.ConnectOnly(vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			vvv, _ := recv.Embed(KiT_InterfaceValueView).(*InterfaceValueView)
+			vvv.SaveTmp()
+			vvv.ViewSig.Emit(vvv.This(), 0, nil)
+		})
+	}
+	ly.UpdateEnd(updt)
+}
+
+func (vv *InterfaceValueView) ConfigWidget(widg gi.Node2D) {
+	vv.Widget = widg
+	vv.StdConfigWidget(widg)
+	ly := vv.Widget.(*gi.Layout)
+	ly.Lay = gi.LayoutVert
+	ly.SetProp("spacing", units.NewEx(0.5))
+	ly.ConfigChildren(kit.TypeAndNameList{{Type: gi.KiT_ComboBox, Name: "type"}}, ki.UniqueNames)
+	cb := ly.Child(0).(*gi.ComboBox)
+	cb.Tooltip, _ = vv.Tag("desc")
+	cb.SetInactiveState(vv.This().(ValueView).IsInactive())
+	cb.ItemsFromTypes(InterfaceImplementers(vv.IfaceType()), false, false, 50)
+	cb.Items = append([]interface{}{InterfaceNilItem}, cb.Items...)
+	cb.ComboSig.ConnectOnly(vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		vvv, _ := recv.Embed(KiT_InterfaceValueView).(*InterfaceValueView)
+		cbb := vvv.Widget.(*gi.Layout).Child(0).(*gi.ComboBox)
+		vvv.SetType(cbb.CurVal)
+	})
+	vv.UpdateWidget()
+}
+
+// SetType sets the value to a new value of given type, a reflect.Type, or
+// to nil for InterfaceNilItem, and updates the widget
+func (vv *InterfaceValueView) SetType(typ interface{}) {
+	if vv.This().(ValueView).IsInactive() {
+		return
+	}
+	ifv := kit.NonPtrValue(vv.Value)
+	nv := reflect.Zero(vv.IfaceType())
+	if tp, ok := typ.(reflect.Type); ok {
+		if !ifv.IsNil() && kit.NonPtrType(ifv.Elem().Type()) == tp {
+			return
+		}
+		nv = reflect.New(tp)
+	} else if ifv.IsNil() {
+		return
+	}
+	ifv.Set(nv)
+	vv.SaveTmp()
+	vv.ViewSig.Emit(vv.This(), 0, nil)
+	vv.UpdateWidget()
+	if vp := vv.Widget.AsNode2D().ViewportSafe(); vp != nil {
+		vp.SetNeedsFullRender() // editor changes size
+	}
+}
+
 ////////////////////////////////////////////////////////////////////////////////////////
 //  ByteSliceValueView
 
@@ -1036,15 +1414,31 @@ func (vv *NilValueView) ConfigWidget(widg gi.Node2D) {
 
 var DefaultTimeFormat = "2006-01-02 15:04:05 MST"
 
-// TimeValueView presents a checkbox for a boolean
+// TimeValueView presents a gi.DatePicker for a time.Time, or a
+// gi.TimePicker if its format tag only has the time of day, e.g.,
+// format:"15:04" -- the format tag is as in time.Format, and defaults to
+// DefaultTimeFormat -- the seconds are shown in the TimePicker if the
+// format has them
 type TimeValueView struct {
 	ValueViewBase
 }
 
 var KiT_TimeValueView = kit.Types.AddType(&TimeValueView{}, nil)
 
+// TimeFormat returns the format tag, or DefaultTimeFormat if none
+func (vv *TimeValueView) TimeFormat() string {
+	if fmttag, ok := vv.Tag("format"); ok && fmttag != "" {
+		return fmttag
+	}
+	return DefaultTimeFormat
+}
+
 func (vv *TimeValueView) WidgetType() reflect.Type {
-	vv.WidgetTyp = gi.KiT_TextField
+	if gi.TimeFormatHasDate(vv.TimeFormat()) {
+		vv.WidgetTyp = gi.KiT_DatePicker
+	} else {
+		vv.WidgetTyp = gi.KiT_TimePicker
+	}
 	return vv.WidgetTyp
 }
 
@@ -1064,33 +1458,102 @@ func (vv *TimeValueView) UpdateWidget() {
 	if vv.Widget == nil {
 		return
 	}
-	tf := vv.Widget.(*gi.TextField)
 	tm := vv.TimeVal()
-	tf.SetText(tm.Format(DefaultTimeFormat))
+	switch w := vv.Widget.(type) {
+	case *gi.DatePicker:
+		w.SetDate(*tm)
+	case *gi.TimePicker:
+		w.SetTime(*tm)
+	}
+}
+
+// SetTimeAction sets the time from the widget, and emits ViewSig
+func (vv *TimeValueView) SetTimeAction(nt time.Time) {
+	tm := vv.TimeVal()
+	*tm = nt
+	vv.ViewSig.Emit(vv.This(), 0, nil)
+	vv.UpdateWidget()
 }
 
 func (vv *TimeValueView) ConfigWidget(widg gi.Node2D) {
 	vv.Widget = widg
 	vv.StdConfigWidget(widg)
-	tf := vv.Widget.(*gi.TextField)
-	tf.SetStretchMaxWidth()
-	tf.Tooltip, _ = vv.Tag("desc")
-	tf.SetInactiveState(vv.This().(ValueView).IsInactive())
-	tf.SetProp("min-width", units.NewCh(float32(len(DefaultTimeFormat)+2)))
-	tf.TextFieldSig.ConnectOnly(vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
-		if sig == int64(gi.TextFieldDone) || sig == int64(gi.TextFieldDeFocused) {
+	inact := vv.This().(ValueView).IsInactive()
+	switch w := vv.Widget.(type) {
+	case *gi.DatePicker:
+		w.Format = vv.TimeFormat()
+		w.Tooltip, _ = vv.Tag("desc")
+		w.SetInactiveState(inact)
+		w.DatePickerSig.ConnectOnly(vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 			vvv, _ := recv.Embed(KiT_TimeValueView).(*TimeValueView)
-			tf := send.(*gi.TextField)
-			nt, err := time.Parse(DefaultTimeFormat, tf.Text())
-			if err != nil {
-				log.Println(err)
-			} else {
-				tm := vvv.TimeVal()
-				*tm = nt
-				vvv.ViewSig.Emit(vvv.This(), 0, nil)
-				vvv.UpdateWidget()
-			}
+			vvv.SetTimeAction(data.(time.Time))
+		})
+	case *gi.TimePicker:
+		w.ShowSecs = gi.TimeFormatHasSeconds(vv.TimeFormat())
+		w.Tooltip, _ = vv.Tag("desc")
+		w.SetInactiveState(inact)
+		w.TimePickerSig.ConnectOnly(vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			vvv, _ := recv.Embed(KiT_TimeValueView).(*TimeValueView)
+			vvv.SetTimeAction(data.(time.Time))
+		})
+	}
+	vv.UpdateWidget()
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  DurationValueView
+
+// DurationValueView presents a gi.DurationField for a time.Duration -- the
+// format tag gives the unit in which it is shown, e.g., format:"ms", which
+// otherwise is the largest unit in which it is at least 1
+type DurationValueView struct {
+	ValueViewBase
+}
+
+var KiT_DurationValueView = kit.Types.AddType(&DurationValueView{}, nil)
+
+func (vv *DurationValueView) WidgetType() reflect.Type {
+	vv.WidgetTyp = gi.KiT_DurationField
+	return vv.WidgetTyp
+}
+
+// DurationVal decodes Value into a *time.Duration value
+func (vv *DurationValueView) DurationVal() *time.Duration {
+	dv, _ := kit.PtrValue(vv.Value).Interface().(*time.Duration)
+	return dv
+}
+
+func (vv *DurationValueView) UpdateWidget() {
+	if vv.Widget == nil {
+		return
+	}
+	df := vv.Widget.(*gi.DurationField)
+	if dv := vv.DurationVal(); dv != nil {
+		df.SetDuration(*dv)
+	}
+}
+
+func (vv *DurationValueView) ConfigWidget(widg gi.Node2D) {
+	vv.Widget = widg
+	vv.StdConfigWidget(widg)
+	df := vv.Widget.(*gi.DurationField)
+	df.Tooltip, _ = vv.Tag("desc")
+	df.SetInactiveState(vv.This().(ValueView).IsInactive())
+	if fmttag, ok := vv.Tag("format"); ok {
+		if gi.DurationUnitByName(fmttag) >= 0 {
+			df.Unit = fmttag
+		} else {
+			log.Printf("giv.DurationValueView: unknown unit in format tag: %v\n", fmttag)
 		}
+	}
+	df.DurationFieldSig.ConnectOnly(vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		vvv, _ := recv.Embed(KiT_DurationValueView).(*DurationValueView)
+		dv := vvv.DurationVal()
+		if dv == nil {
+			return
+		}
+		*dv = data.(time.Duration)
+		vvv.ViewSig.Emit(vvv.This(), 0, nil)
 	})
 	vv.UpdateWidget()
 }
@@ -0,0 +1,141 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// BoundView is implemented by views that are bound to a model, i.e., the
+// struct or slice that they are a view onto, so that they update
+// automatically when it changes -- StructView binds to its Struct, and
+// SliceView and TableView to their Slice.  Any other Ki can implement it and
+// call BindModel to observe changes to a model.
+type BoundView interface {
+	ki.Ki
+
+	// ModelChanged updates the view for a change to the given field of its
+	// model (a struct), or to all of it if field is "" (always for a slice)
+	ModelChanged(field string)
+}
+
+// bindViews is the registry of views bound to each model, by pointer
+var bindViews = map[interface{}][]BoundView{}
+
+// bindMu protects bindViews
+var bindMu sync.Mutex
+
+// bindKey returns the key of given model in bindViews -- nil if it is not a
+// non-nil pointer, which is required to identify the model
+func bindKey(model interface{}) interface{} {
+	if kit.IfaceIsNil(model) || reflect.TypeOf(model).Kind() != reflect.Ptr {
+		return nil
+	}
+	return model
+}
+
+// BindModel binds given view to given model, which must be a pointer to a
+// struct or slice, so that NotifyChange of the model updates the view --
+// views bind themselves to the model they are set to view.
+func BindModel(model interface{}, vw BoundView) {
+	key := bindKey(model)
+	if key == nil || vw == nil {
+		return
+	}
+	bindMu.Lock()
+	defer bindMu.Unlock()
+	for _, bv := range bindViews[key] {
+		if bv == vw {
+			return
+		}
+	}
+	bindViews[key] = append(bindViews[key], vw)
+}
+
+// UnbindModel removes the binding of given view to given model -- called
+// when the view is set to another model or destroyed.
+func UnbindModel(model interface{}, vw BoundView) {
+	key := bindKey(model)
+	if key == nil || vw == nil {
+		return
+	}
+	bindMu.Lock()
+	defer bindMu.Unlock()
+	bvs := bindViews[key]
+	for i, bv := range bvs {
+		if bv == vw {
+			bvs = append(bvs[:i], bvs[i+1:]...)
+			break
+		}
+	}
+	if len(bvs) == 0 {
+		delete(bindViews, key)
+	} else {
+		bindViews[key] = bvs
+	}
+}
+
+// NotifyChange notifies all the views bound to given model (a pointer to a
+// struct or slice) of a change to the given field of it, or to all of it if
+// field is "", so that they update their display -- call this after changing
+// values of the model in code, instead of doing a full re-render of the
+// views.  Edits in a view notify the other views of the same model in the
+// same way.  It must be called in the event loop goroutine of the windows of
+// the views -- from another goroutine, e.g., use the Do method of a
+// gi.Progress.
+func NotifyChange(model interface{}, field string) {
+	NotifyChangeFrom(model, field, nil)
+}
+
+// NotifyChangeFrom is NotifyChange for a change made by given view, which is
+// not notified
+func NotifyChangeFrom(model interface{}, field string, from ki.Ki) {
+	key := bindKey(model)
+	if key == nil {
+		return
+	}
+	bindMu.Lock()
+	bvs := make([]BoundView, len(bindViews[key]))
+	copy(bvs, bindViews[key])
+	bindMu.Unlock()
+	for _, bv := range bvs {
+		if bv == from || bv.IsDestroyed() || bv.IsDeleted() {
+			continue
+		}
+		bv.ModelChanged(field)
+	}
+}
+
+// SetModelField sets the value of given field of given model, a pointer to a
+// struct, and calls NotifyChange for it -- returns false if the field does
+// not exist or the value could not be set.
+func SetModelField(model interface{}, field string, val interface{}) bool {
+	if k, ok := model.(ki.Ki); ok {
+		if k.SetField(field, val) != nil {
+			return false
+		}
+		NotifyChange(model, field)
+		return true
+	}
+	fv := kit.NonPtrValue(reflect.ValueOf(model))
+	if fv.Kind() != reflect.Struct {
+		return false
+	}
+	fv = fv.FieldByName(field)
+	if !fv.IsValid() || !fv.CanAddr() {
+		return false
+	}
+	if vv := reflect.ValueOf(val); vv.IsValid() && vv.Type().AssignableTo(fv.Type()) {
+		fv.Set(vv)
+	} else if !kit.SetRobust(fv.Addr().Interface(), val) {
+		return false
+	}
+	NotifyChange(model, field)
+	return true
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/goki/gi/gist"
+	"golang.org/x/image/colornames"
+)
+
+// ColorPalette is a named set of colors, shown as a grid of swatches in the
+// ColorPicker, for choosing one of them
+type ColorPalette struct {
+	Name   string       `desc:"name of the palette, for choosing it in the ColorPicker"`
+	Colors []gist.Color `desc:"colors of the palette"`
+	Names  []string     `desc:"names of the colors, shown in the tooltips of their swatches -- can be empty"`
+}
+
+// ColorPalettes are the palettes that can be chosen in the ColorPicker --
+// apps can add their own, e.g., the colors of their documents
+var ColorPalettes = []*ColorPalette{
+	NewColorPaletteNamed("Basic", "black", "gray", "silver", "white", "maroon", "red", "purple", "fuchsia", "green", "lime", "olive", "yellow", "navy", "blue", "teal", "aqua"),
+	NewColorPaletteHex("Material",
+		"red", "#F44336", "pink", "#E91E63", "purple", "#9C27B0", "deep purple", "#673AB7",
+		"indigo", "#3F51B5", "blue", "#2196F3", "light blue", "#03A9F4", "cyan", "#00BCD4",
+		"teal", "#009688", "green", "#4CAF50", "light green", "#8BC34A", "lime", "#CDDC39",
+		"yellow", "#FFEB3B", "amber", "#FFC107", "orange", "#FF9800", "deep orange", "#FF5722",
+		"brown", "#795548", "grey", "#9E9E9E", "blue grey", "#607D8B"),
+	NewColorPaletteGrays("Grays", 11),
+	NewColorPaletteNamed("Web", colorNamesSorted()...),
+}
+
+// ColorPaletteByName returns the palette of given name in ColorPalettes --
+// nil if none
+func ColorPaletteByName(name string) *ColorPalette {
+	for _, cp := range ColorPalettes {
+		if cp.Name == name {
+			return cp
+		}
+	}
+	return nil
+}
+
+// NewColorPaletteNamed returns a new palette of given name with the colors
+// of given standard color names, e.g., red -- unknown names are skipped
+func NewColorPaletteNamed(name string, colors ...string) *ColorPalette {
+	cp := &ColorPalette{Name: name}
+	for _, cn := range colors {
+		c, ok := colornames.Map[cn]
+		if !ok {
+			continue
+		}
+		var clr gist.Color
+		clr.SetColor(c)
+		cp.Colors = append(cp.Colors, clr)
+		cp.Names = append(cp.Names, cn)
+	}
+	return cp
+}
+
+// NewColorPaletteHex returns a new palette of given name with colors given as
+// pairs of a name and a #hex color spec
+func NewColorPaletteHex(name string, namesHex ...string) *ColorPalette {
+	cp := &ColorPalette{Name: name}
+	for i := 0; i+1 < len(namesHex); i += 2 {
+		var clr gist.Color
+		if err := clr.ParseHex(namesHex[i+1]); err != nil {
+			continue
+		}
+		cp.Colors = append(cp.Colors, clr)
+		cp.Names = append(cp.Names, namesHex[i])
+	}
+	return cp
+}
+
+// NewColorPaletteGrays returns a new palette of given name with given number
+// of evenly-spaced grays, from black to white
+func NewColorPaletteGrays(name string, n int) *ColorPalette {
+	cp := &ColorPalette{Name: name}
+	for i := 0; i < n; i++ {
+		l := float32(i) / float32(n-1)
+		var clr gist.Color
+		clr.SetFloat32(l, l, l, 1)
+		cp.Colors = append(cp.Colors, clr)
+		cp.Names = append(cp.Names, fmt.Sprintf("gray %d%%", int(l*100+0.5)))
+	}
+	return cp
+}
+
+// colorNamesSorted returns all the standard color names, sorted
+func colorNamesSorted() []string {
+	nms := make([]string, 0, len(colornames.Map))
+	for nm := range colornames.Map {
+		nms = append(nms, nm)
+	}
+	sort.Strings(nms)
+	return nms
+}
@@ -0,0 +1,758 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+)
+
+/////////////////////////////////////////////////////////////////////////////
+//  ColorPicker
+
+// ColorPicker is a full editor for a color: the hue and saturation are
+// picked in a HueSatArea, with sliders for the lightness and alpha, and the
+// color can be entered as hex, RGBA or HSL values, chosen from the recently
+// used colors, gi.RecentColors, or from one of the ColorPalettes, or picked
+// from anywhere on the screen with an eyedropper, where the platform
+// supports that (oswin.ScreenColorPicker).  It is used by the
+// ColorViewDialog, e.g., for ColorValueView values in a StructView.
+type ColorPicker struct {
+	gi.Frame
+	Color    gist.Color `desc:"the color that we edit"`
+	Palette  string     `desc:"name of the ColorPalette shown in the palette grid -- the first one if empty"`
+	TmpSave  ValueView  `json:"-" xml:"-" desc:"value view that needs to have SaveTmp called on it whenever a change is made to one of the underlying values -- pass this down to any sub-views created from a parent"`
+	ViewSig  ki.Signal  `json:"-" xml:"-" desc:"signal for valueview -- only one signal sent when a value has been set -- all related value views interconnect with each other to update when others update"`
+	ViewPath string     `desc:"a record of parent View names that have led up to this view -- displayed as extra contextual information in view dialog windows"`
+	hue      float32    // HSL of the color, kept here so that the hue and
+	sat      float32    // saturation are not lost for grays, black and white
+	light    float32
+}
+
+var KiT_ColorPicker = kit.Types.AddType(&ColorPicker{}, ColorPickerProps)
+
+// AddNewColorPicker adds a new color picker to given parent node, with given name.
+func AddNewColorPicker(parent ki.Ki, name string) *ColorPicker {
+	return parent.AddNewChild(KiT_ColorPicker, name).(*ColorPicker)
+}
+
+func (cp *ColorPicker) Disconnect() {
+	cp.Frame.Disconnect()
+	cp.ViewSig.DisconnectAll()
+}
+
+var ColorPickerProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+}
+
+// ColorPickerSwatchSize is the size of the swatches of the recent colors
+// and palette grids
+var ColorPickerSwatchSize = units.NewEm(1.5)
+
+// SetColor sets the color to edit
+func (cp *ColorPicker) SetColor(clr color.Color) {
+	cp.Color.SetColor(clr)
+	cp.updateHSL()
+	cp.Config()
+	cp.Update()
+}
+
+// SetColorAction sets the color from an action of the user, updating the
+// view and emitting ViewSig
+func (cp *ColorPicker) SetColorAction(clr gist.Color) {
+	cp.Color = clr
+	cp.updateHSL()
+	cp.changed()
+}
+
+// SetHSLAAction sets the color from given hue [0..360), saturation,
+// lightness and alpha [0..1], updating the view and emitting ViewSig
+func (cp *ColorPicker) SetHSLAAction(h, s, l, a float32) {
+	cp.hue, cp.sat, cp.light = h, s, l
+	cp.Color.SetHSLA(h, s, l, a)
+	cp.changed()
+}
+
+// SetNPRGBAAction sets the color from given non alpha-premultiplied red,
+// green, blue and alpha values, updating the view and emitting ViewSig
+func (cp *ColorPicker) SetNPRGBAAction(r, g, b, a uint8) {
+	cp.Color.SetNPFloat32(float32(r)/255, float32(g)/255, float32(b)/255, float32(a)/255)
+	cp.updateHSL()
+	cp.changed()
+}
+
+// SetHexAction sets the color from given #RRGGBB or #RRGGBBAA hex spec, or
+// any other spec of gist.Color SetString, e.g., a color name, updating the
+// view and emitting ViewSig
+func (cp *ColorPicker) SetHexAction(hex string) error {
+	var clr gist.Color
+	err := clr.SetString(strings.TrimSpace(hex), nil)
+	if err != nil {
+		cp.Update() // restore the hex field
+		return err
+	}
+	clr.SetAlphaPreMult() // specs are not premultiplied
+	cp.SetColorAction(clr)
+	return nil
+}
+
+// NPRGBA returns the non alpha-premultiplied red, green, blue and alpha
+// values of the color, as entered in the RGBA and hex fields
+func (cp *ColorPicker) NPRGBA() (r, g, b, a uint8) {
+	return ColorNPRGBA(cp.Color)
+}
+
+// HexString returns the #RRGGBB hex spec of the color, or #RRGGBBAA if it
+// is not opaque -- see ColorHexString
+func (cp *ColorPicker) HexString() string {
+	return ColorHexString(cp.Color)
+}
+
+// ColorNPRGBA returns the non alpha-premultiplied red, green, blue and
+// alpha values of given color
+func ColorNPRGBA(clr gist.Color) (r, g, b, a uint8) {
+	rf, gf, bf, af := clr.ToNPFloat32()
+	return uint8(rf*255 + 0.5), uint8(gf*255 + 0.5), uint8(bf*255 + 0.5), uint8(af*255 + 0.5)
+}
+
+// ColorHexString returns the #RRGGBB hex spec of given color, or #RRGGBBAA
+// if it is not opaque, with non alpha-premultiplied values, as used in CSS
+// -- unlike gist.Color HexString, which has the raw values
+func ColorHexString(clr gist.Color) string {
+	r, g, b, a := ColorNPRGBA(clr)
+	if a == 255 {
+		return fmt.Sprintf("#%02X%02X%02X", r, g, b)
+	}
+	return fmt.Sprintf("#%02X%02X%02X%02X", r, g, b, a)
+}
+
+// updateHSL updates the HSL values from the color, keeping the hue and
+// saturation where they are not defined
+func (cp *ColorPicker) updateHSL() {
+	h, s, l, _ := cp.Color.ToHSLA()
+	if l > 0 && l < 1 {
+		if s > 0 {
+			cp.hue = h
+		}
+		cp.sat = s
+	}
+	cp.light = l
+}
+
+// changed saves and signals a change of the color by the user, and updates
+// the view
+func (cp *ColorPicker) changed() {
+	if cp.TmpSave != nil {
+		cp.TmpSave.SaveTmp()
+	}
+	cp.Update()
+	cp.ViewSig.Emit(cp.This(), 0, nil)
+}
+
+// AddRecentColor adds the color to gi.RecentColors, which are shown in the
+// recent colors grid -- called when the color is accepted in a
+// ColorViewDialog
+func (cp *ColorPicker) AddRecentColor() {
+	if len(gi.RecentColors) == 0 {
+		gi.OpenRecentColors()
+	}
+	gi.RecentColors.AddColor(cp.Color, gi.Prefs.Params.RecentColorsMax)
+	gi.SaveRecentColors()
+}
+
+// HasScreenColorPicker returns true if colors can be picked from the
+// screen with the eyedropper, i.e., the oswin.App is a
+// oswin.ScreenColorPicker
+func HasScreenColorPicker() bool {
+	_, ok := oswin.TheApp.(oswin.ScreenColorPicker)
+	return ok
+}
+
+// PickScreenColor lets the user pick the color from anywhere on the screen,
+// with the oswin.ScreenColorPicker of the platform, which runs in the
+// background with its progress shown in the window, as the user may take a
+// while -- the alpha of the color is kept
+func (cp *ColorPicker) PickScreenColor() {
+	scp, ok := oswin.TheApp.(oswin.ScreenColorPicker)
+	if !ok {
+		return
+	}
+	var clr color.RGBA
+	gi.RunProgress(nil, cp.ParentWindow(), gi.ProgressOpts{Title: gi.T("Click on the Color to Pick")}, func(p *gi.Progress) error {
+		var err error
+		clr, err = scp.PickScreenColor()
+		return err
+	}, func(p *gi.Progress) {
+		if p.Err != nil {
+			log.Printf("giv.ColorPicker: could not pick a color from the screen: %v\n", p.Err)
+		}
+		if p.Err != nil || p.Canceled() || cp.This() == nil {
+			return
+		}
+		_, _, _, a := cp.NPRGBA()
+		cp.SetNPRGBAAction(clr.R, clr.G, clr.B, a)
+	})
+}
+
+// SetPalette sets the ColorPalette shown in the palette grid, by name
+func (cp *ColorPicker) SetPalette(name string) {
+	cp.Palette = name
+	updt := cp.UpdateStart()
+	cp.ConfigPaletteGrid()
+	cp.UpdateEnd(updt)
+}
+
+// Config configures a standard setup of entire view
+func (cp *ColorPicker) Config() {
+	cp.Lay = gi.LayoutVert
+	cp.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_Layout, "area-lay")
+	config.Add(gi.KiT_Layout, "slider-grid")
+	config.Add(gi.KiT_Layout, "hex-lay")
+	config.Add(gi.KiT_Layout, "rgba-lay")
+	config.Add(gi.KiT_Layout, "hsl-lay")
+	if len(gi.RecentColors) == 0 {
+		gi.OpenRecentColors()
+	}
+	if len(gi.RecentColors) > 0 {
+		config.Add(gi.KiT_Label, "recents-lbl")
+		config.Add(gi.KiT_Layout, "recents-grid")
+	}
+	config.Add(gi.KiT_Layout, "palette-lay")
+	config.Add(gi.KiT_Layout, "palette-grid")
+	mods, updt := cp.ConfigChildren(config, ki.UniqueNames)
+	if mods {
+		cp.ConfigAreaLay()
+		cp.ConfigSliderGrid()
+		cp.ConfigHexLay()
+		cp.ConfigRGBALay()
+		cp.ConfigHSLLay()
+		if len(gi.RecentColors) > 0 {
+			cp.ConfigRecents()
+		}
+		cp.ConfigPaletteLay()
+		cp.ConfigPaletteGrid()
+	} else {
+		updt = cp.UpdateStart()
+	}
+	cp.UpdateEnd(updt)
+}
+
+// IsConfiged returns true if widget is fully configured
+func (cp *ColorPicker) IsConfiged() bool {
+	return len(cp.Kids) > 0
+}
+
+// AreaLay returns the layout of the HueSatArea and the value swatch
+func (cp *ColorPicker) AreaLay() *gi.Layout {
+	return cp.ChildByName("area-lay", 0).(*gi.Layout)
+}
+
+// Area returns the HueSatArea
+func (cp *ColorPicker) Area() *HueSatArea {
+	return cp.AreaLay().ChildByName("area", 0).(*HueSatArea)
+}
+
+// Value returns the swatch that shows the color
+func (cp *ColorPicker) Value() *gi.Frame {
+	return cp.AreaLay().ChildByName("value-lay", 1).ChildByName("value", 0).(*gi.Frame)
+}
+
+// SliderGrid returns the grid of the lightness and alpha sliders
+func (cp *ColorPicker) SliderGrid() *gi.Layout {
+	return cp.ChildByName("slider-grid", 1).(*gi.Layout)
+}
+
+// HexField returns the text field of the hex spec of the color
+func (cp *ColorPicker) HexField() *gi.TextField {
+	return cp.ChildByName("hex-lay", 2).ChildByName("hex", 1).(*gi.TextField)
+}
+
+// RecentsGrid returns the grid of the swatches of the recent colors -- nil
+// if there are none
+func (cp *ColorPicker) RecentsGrid() *gi.Layout {
+	rg, _ := cp.ChildByName("recents-grid", 6).(*gi.Layout)
+	return rg
+}
+
+// PaletteField returns the combobox for choosing the ColorPalette
+func (cp *ColorPicker) PaletteField() *gi.ComboBox {
+	return cp.ChildByName("palette-lay", 7).ChildByName("palette", 1).(*gi.ComboBox)
+}
+
+// PaletteGrid returns the grid of the swatches of the ColorPalette
+func (cp *ColorPicker) PaletteGrid() *gi.Layout {
+	return cp.ChildByName("palette-grid", 8).(*gi.Layout)
+}
+
+// ConfigAreaLay configures the HueSatArea, and the swatch of the color with
+// the eyedropper below it
+func (cp *ColorPicker) ConfigAreaLay() {
+	al := cp.AreaLay()
+	al.Lay = gi.LayoutHoriz
+	al.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(KiT_HueSatArea, "area")
+	config.Add(gi.KiT_Layout, "value-lay")
+	al.ConfigChildren(config, ki.UniqueNames) // already covered by parent update
+
+	ar := cp.Area()
+	ar.Tooltip = "click or drag to set the hue (horizontal) and saturation (vertical) of the color"
+	ar.AreaSig.ConnectOnly(cp.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		cpp, _ := recv.Embed(KiT_ColorPicker).(*ColorPicker)
+		arr := send.(*HueSatArea)
+		cpp.SetHSLAAction(arr.Hue, arr.Sat, cpp.light, float32(cpp.Color.A)/255)
+	})
+
+	vl := al.ChildByName("value-lay", 1).(*gi.Layout)
+	vl.Lay = gi.LayoutVert
+	vl.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config = kit.TypeAndNameList{}
+	config.Add(gi.KiT_Frame, "value")
+	config.Add(gi.KiT_Action, "pick")
+	vl.ConfigChildren(config, ki.UniqueNames)
+	v := cp.Value()
+	v.SetProp("min-width", units.NewEm(6))
+	v.SetProp("min-height", units.NewEm(4))
+	pk := vl.ChildByName("pick", 1).(*gi.Action)
+	pk.SetText(gi.T("Pick from Screen"))
+	if HasScreenColorPicker() {
+		pk.Tooltip = "eyedropper: click on any pixel of the screen to pick its color"
+	} else {
+		pk.Tooltip = "picking colors from the screen is not supported on this platform"
+		pk.SetInactive()
+	}
+	pk.ActionSig.ConnectOnly(cp.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		cpp, _ := recv.Embed(KiT_ColorPicker).(*ColorPicker)
+		cpp.PickScreenColor()
+	})
+}
+
+// ConfigSliderGrid configures the lightness and alpha sliders
+func (cp *ColorPicker) ConfigSliderGrid() {
+	sg := cp.SliderGrid()
+	sg.Lay = gi.LayoutGrid
+	sg.SetProp("columns", 2)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_Label, "llab")
+	config.Add(gi.KiT_Slider, "light")
+	config.Add(gi.KiT_Label, "alab")
+	config.Add(gi.KiT_Slider, "alpha")
+	sg.ConfigChildren(config, ki.UniqueNames)
+	sg.ChildByName("llab", 0).(*gi.Label).SetText(gi.T("Light:"))
+	sg.ChildByName("alab", 2).(*gi.Label).SetText(gi.T("Alpha:"))
+	for i, nm := range []string{"light", "alpha"} {
+		sl := sg.ChildByName(nm, 1).(*gi.Slider)
+		sl.Defaults()
+		sl.Max = 100
+		sl.Step = 1
+		sl.PageStep = 10
+		sl.Prec = 3
+		sl.Dim = mat32.X
+		sl.Tracking = true
+		sl.TrackThr = 1
+		sl.SetMinPrefWidth(units.NewCh(30))
+		sl.SetMinPrefHeight(units.NewEm(1.5))
+		sl.SetStretchMaxWidth()
+		alpha := i == 1
+		sl.SliderSig.ConnectOnly(cp.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != int64(gi.SliderValueChanged) {
+				return
+			}
+			cpp, _ := recv.Embed(KiT_ColorPicker).(*ColorPicker)
+			slv := send.Embed(gi.KiT_Slider).(*gi.Slider)
+			a := float32(cpp.Color.A) / 255
+			if alpha {
+				cpp.SetHSLAAction(cpp.hue, cpp.sat, cpp.light, slv.Value/100)
+			} else {
+				cpp.SetHSLAAction(cpp.hue, cpp.sat, slv.Value/100, a)
+			}
+		})
+	}
+}
+
+// ConfigHexLay configures the hex field
+func (cp *ColorPicker) ConfigHexLay() {
+	hl := cp.ChildByName("hex-lay", 2).(*gi.Layout)
+	hl.Lay = gi.LayoutHoriz
+	hl.SetProp("spacing", units.NewPx(4))
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_Label, "hex-lbl")
+	config.Add(gi.KiT_TextField, "hex")
+	hl.ConfigChildren(config, ki.UniqueNames)
+	hl.ChildByName("hex-lbl", 0).(*gi.Label).SetText(gi.T("Hex:"))
+	tf := cp.HexField()
+	tf.Tooltip = "hex spec of the color: #RRGGBB, or #RRGGBBAA with alpha -- a color name can also be entered"
+	tf.SetMinPrefWidth(units.NewCh(16))
+	tf.TextFieldSig.ConnectOnly(cp.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.TextFieldDone) || sig == int64(gi.TextFieldDeFocused) {
+			cpp, _ := recv.Embed(KiT_ColorPicker).(*ColorPicker)
+			tff := send.(*gi.TextField)
+			if tff.Text() != cpp.HexString() {
+				cpp.SetHexAction(tff.Text())
+			}
+		}
+	})
+}
+
+// configSpinBoxes configures the spin boxes of given layout, with given
+// label and max values, calling given function when one is changed
+func (cp *ColorPicker) configSpinBoxes(lay *gi.Layout, label string, names []string, maxs []float32, tips []string, fun func(cpp *ColorPicker)) {
+	lay.Lay = gi.LayoutHoriz
+	lay.SetProp("spacing", units.NewPx(4))
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_Label, "lbl")
+	for _, nm := range names {
+		config.Add(gi.KiT_SpinBox, nm)
+	}
+	lay.ConfigChildren(config, ki.UniqueNames)
+	lay.ChildByName("lbl", 0).(*gi.Label).SetText(label)
+	for i, nm := range names {
+		sb := lay.ChildByName(nm, i+1).(*gi.SpinBox)
+		sb.Defaults()
+		sb.SetMin(0)
+		sb.SetMax(maxs[i])
+		sb.Step = 1
+		sb.PageStep = 10
+		sb.Format = "%g"
+		sb.Tooltip = tips[i]
+		sb.SpinBoxSig.ConnectOnly(cp.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			cpp, _ := recv.Embed(KiT_ColorPicker).(*ColorPicker)
+			fun(cpp)
+		})
+	}
+}
+
+// spinValue returns the value of the spin box of given name in given layout
+func spinValue(lay ki.Ki, name string) float32 {
+	return lay.ChildByName(name, 1).(*gi.SpinBox).Value
+}
+
+// ConfigRGBALay configures the spin boxes of the RGBA values
+func (cp *ColorPicker) ConfigRGBALay() {
+	rl := cp.ChildByName("rgba-lay", 3).(*gi.Layout)
+	cp.configSpinBoxes(rl, gi.T("RGBA:"), []string{"red", "green", "blue", "alpha"}, []float32{255, 255, 255, 255},
+		[]string{"red [0..255]", "green [0..255]", "blue [0..255]", "alpha (opacity) [0..255]"}, func(cpp *ColorPicker) {
+			rl := cpp.ChildByName("rgba-lay", 3)
+			cpp.SetNPRGBAAction(uint8(spinValue(rl, "red")), uint8(spinValue(rl, "green")), uint8(spinValue(rl, "blue")), uint8(spinValue(rl, "alpha")))
+		})
+}
+
+// ConfigHSLLay configures the spin boxes of the HSL values
+func (cp *ColorPicker) ConfigHSLLay() {
+	hl := cp.ChildByName("hsl-lay", 4).(*gi.Layout)
+	cp.configSpinBoxes(hl, gi.T("HSL:"), []string{"hue", "sat", "light"}, []float32{360, 100, 100},
+		[]string{"hue, in degrees [0..360]", "saturation, in percent [0..100]", "lightness, in percent [0..100]"}, func(cpp *ColorPicker) {
+			hl := cpp.ChildByName("hsl-lay", 4)
+			h := spinValue(hl, "hue")
+			if h >= 360 {
+				h = 0
+			}
+			cpp.SetHSLAAction(h, spinValue(hl, "sat")/100, spinValue(hl, "light")/100, float32(cpp.Color.A)/255)
+		})
+}
+
+// ConfigRecents configures the grid of the recent colors, from
+// gi.RecentColors, which are only shown if there are any
+func (cp *ColorPicker) ConfigRecents() {
+	rl := cp.ChildByName("recents-lbl", 5).(*gi.Label)
+	rl.SetText(gi.T("Recent Colors"))
+	rl.Tooltip = "colors recently chosen in color dialogs -- click to choose one again"
+	cp.configSwatches(cp.RecentsGrid(), gi.RecentColors, nil)
+}
+
+// ConfigPaletteLay configures the combobox for choosing the ColorPalette
+func (cp *ColorPicker) ConfigPaletteLay() {
+	pl := cp.ChildByName("palette-lay", 7).(*gi.Layout)
+	pl.Lay = gi.LayoutHoriz
+	pl.SetProp("spacing", units.NewPx(4))
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_Label, "palette-lbl")
+	config.Add(gi.KiT_ComboBox, "palette")
+	pl.ConfigChildren(config, ki.UniqueNames)
+	pl.ChildByName("palette-lbl", 0).(*gi.Label).SetText(gi.T("Palette:"))
+	cb := cp.PaletteField()
+	nms := make([]string, len(ColorPalettes))
+	for i, pal := range ColorPalettes {
+		nms[i] = pal.Name
+	}
+	cb.ItemsFromStringList(nms, false, 0)
+	if cp.Palette == "" && len(nms) > 0 {
+		cp.Palette = nms[0]
+	}
+	cb.SetCurVal(cp.Palette)
+	cb.ComboSig.ConnectOnly(cp.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		cpp, _ := recv.Embed(KiT_ColorPicker).(*ColorPicker)
+		cbb := send.(*gi.ComboBox)
+		cpp.SetPalette(kit.ToString(cbb.CurVal))
+	})
+}
+
+// ConfigPaletteGrid configures the grid of the colors of the Palette
+func (cp *ColorPicker) ConfigPaletteGrid() {
+	pal := ColorPaletteByName(cp.Palette)
+	if pal == nil {
+		cp.configSwatches(cp.PaletteGrid(), nil, nil)
+		return
+	}
+	pg := cp.PaletteGrid()
+	pg.SetProp("max-height", units.NewEm(10))
+	pg.SetProp("overflow", gist.OverflowAuto)
+	cp.configSwatches(pg, pal.Colors, pal.Names)
+}
+
+// configSwatches configures given grid with swatches of given colors, with
+// given names shown in their tooltips, if non-nil, which set the color when
+// clicked
+func (cp *ColorPicker) configSwatches(grid *gi.Layout, colors []gist.Color, names []string) {
+	grid.Lay = gi.LayoutGrid
+	grid.SetProp("columns", 16)
+	grid.SetProp("spacing", units.NewPx(0))
+	config := kit.TypeAndNameList{}
+	for i := range colors {
+		config.Add(gi.KiT_Action, fmt.Sprintf("s%d", i))
+	}
+	grid.ConfigChildren(config, ki.UniqueNames)
+	for i, clr := range colors {
+		ac := grid.Child(i).(*gi.Action)
+		ac.SetProp("background-color", clr)
+		ac.SetProp("border-width", units.NewPx(1))
+		ac.SetProp("min-width", ColorPickerSwatchSize)
+		ac.SetProp("min-height", ColorPickerSwatchSize)
+		ac.SetProp("margin", units.NewPx(1))
+		ac.SetFullReRender()
+		ac.Data = clr
+		ac.Tooltip = ColorHexString(clr)
+		if i < len(names) {
+			ac.Tooltip = names[i] + ": " + ac.Tooltip
+		}
+		ac.ActionSig.ConnectOnly(cp.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			cpp, _ := recv.Embed(KiT_ColorPicker).(*ColorPicker)
+			acc := send.(*gi.Action)
+			cpp.SetColorAction(acc.Data.(gist.Color))
+		})
+	}
+}
+
+// Update updates all the widgets of the view from the color
+func (cp *ColorPicker) Update() {
+	if !cp.IsConfiged() {
+		return
+	}
+	updt := cp.UpdateStart()
+	ar := cp.Area()
+	ar.SetHueSat(cp.hue, cp.sat, cp.light)
+	v := cp.Value()
+	v.SetProp("background-color", cp.Color)
+	v.SetFullReRender()
+
+	sg := cp.SliderGrid()
+	sg.ChildByName("light", 1).(*gi.Slider).SetValue(cp.light * 100)
+	sg.ChildByName("alpha", 3).(*gi.Slider).SetValue(float32(cp.Color.A) * 100 / 255)
+
+	cp.HexField().SetText(cp.HexString())
+
+	r, g, b, a := cp.NPRGBA()
+	rl := cp.ChildByName("rgba-lay", 3)
+	for i, vl := range []uint8{r, g, b, a} {
+		rl.Child(i + 1).(*gi.SpinBox).SetValue(float32(vl))
+	}
+	hl := cp.ChildByName("hsl-lay", 4)
+	for i, vl := range []float32{cp.hue, cp.sat * 100, cp.light * 100} {
+		hl.Child(i + 1).(*gi.SpinBox).SetValue(mat32.Round(vl))
+	}
+	cp.UpdateEnd(updt)
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//  HueSatArea
+
+// HueSatArea is the area of the ColorPicker in which the hue, horizontally,
+// and the saturation, vertically, of the color are picked, by clicking or
+// dragging -- it shows all the hues and saturations at the lightness of the
+// color, with a marker at those of the color
+type HueSatArea struct {
+	gi.WidgetBase
+	Hue     float32     `desc:"hue of the color, in degrees [0..360), along the width of the area"`
+	Sat     float32     `desc:"saturation of the color [0..1], from the bottom to the top of the area"`
+	Light   float32     `desc:"lightness of the color [0..1], at which the area shows the hues and saturations"`
+	AreaSig ki.Signal   `copy:"-" json:"-" xml:"-" view:"-" desc:"signal for the area -- has no signal types, just emitted when the user sets the Hue and Sat"`
+	img     *image.RGBA // rendered hues and saturations
+	imgL    float32     // lightness at which img was rendered
+}
+
+var KiT_HueSatArea = kit.Types.AddType(&HueSatArea{}, HueSatAreaProps)
+
+// AddNewHueSatArea adds a new hue / saturation area to given parent node, with given name.
+func AddNewHueSatArea(parent ki.Ki, name string) *HueSatArea {
+	return parent.AddNewChild(KiT_HueSatArea, name).(*HueSatArea)
+}
+
+func (ha *HueSatArea) CopyFieldsFrom(frm interface{}) {
+	fr := frm.(*HueSatArea)
+	ha.WidgetBase.CopyFieldsFrom(&fr.WidgetBase)
+	ha.Hue = fr.Hue
+	ha.Sat = fr.Sat
+	ha.Light = fr.Light
+}
+
+func (ha *HueSatArea) Disconnect() {
+	ha.WidgetBase.Disconnect()
+	ha.AreaSig.DisconnectAll()
+}
+
+var HueSatAreaProps = ki.Props{
+	"EnumType:Flag": gi.KiT_NodeFlags,
+	"border-width":  units.NewPx(1),
+	"border-color":  &gi.Prefs.Colors.Border,
+	"padding":       units.NewPx(0),
+	"margin":        units.NewPx(2),
+	"min-width":     units.NewEm(20),
+	"min-height":    units.NewEm(10),
+}
+
+// SetHueSat sets the hue, saturation and lightness shown, and updates the
+// area if they changed
+func (ha *HueSatArea) SetHueSat(h, s, l float32) {
+	if ha.Hue == h && ha.Sat == s && ha.Light == l {
+		return
+	}
+	updt := ha.UpdateStart()
+	ha.Hue, ha.Sat, ha.Light = h, s, l
+	ha.UpdateEnd(updt)
+}
+
+// AreaBox returns the position and size of the area within the border
+func (ha *HueSatArea) AreaBox() (pos, sz mat32.Vec2) {
+	st := &ha.Sty
+	spc := st.Layout.Margin.Dots + st.Border.Width.Dots
+	pos = ha.LayState.Alloc.Pos.AddScalar(spc)
+	sz = ha.LayState.Alloc.Size.AddScalar(-2 * spc)
+	return
+}
+
+// SetFromPoint sets the Hue and Sat from given point in window coordinates,
+// and emits AreaSig
+func (ha *HueSatArea) SetFromPoint(pt image.Point) {
+	ha.BBoxMu.RLock()
+	rp := pt.Sub(ha.WinBBox.Min).Add(ha.VpBBox.Min) // in viewport coords
+	ha.BBoxMu.RUnlock()
+	pos, sz := ha.AreaBox()
+	if sz.X <= 1 || sz.Y <= 1 {
+		return
+	}
+	rp = rp.Sub(pos.ToPoint())
+	h := mat32.Clamp(float32(rp.X)/(sz.X-1), 0, 1) * 360
+	if h >= 360 {
+		h = 0
+	}
+	s := 1 - mat32.Clamp(float32(rp.Y)/(sz.Y-1), 0, 1)
+	ha.SetHueSat(h, s, ha.Light)
+	ha.AreaSig.Emit(ha.This(), 0, nil)
+}
+
+// renderImage renders the hues and saturations at the lightness into img,
+// for given size, if they are not already
+func (ha *HueSatArea) renderImage(sz image.Point) {
+	if ha.img != nil && ha.img.Rect.Size() == sz && ha.imgL == ha.Light {
+		return
+	}
+	ha.img = image.NewRGBA(image.Rectangle{Max: sz})
+	ha.imgL = ha.Light
+	var clr gist.Color
+	for y := 0; y < sz.Y; y++ {
+		s := 1 - float32(y)/float32(sz.Y-1)
+		for x := 0; x < sz.X; x++ {
+			clr.SetHSLA(360*float32(x)/float32(sz.X), s, ha.Light, 1)
+			ha.img.SetRGBA(x, y, color.RGBA{clr.R, clr.G, clr.B, 255})
+		}
+	}
+}
+
+// RenderArea renders the hues and saturations, and the marker of the color
+func (ha *HueSatArea) RenderArea() {
+	rs, pc, st := ha.RenderLock()
+	defer ha.RenderUnlock(rs)
+
+	pos, sz := ha.AreaBox()
+	isz := sz.ToPoint()
+	if isz.X > 1 && isz.Y > 1 {
+		ha.renderImage(isz)
+		pc.DrawImage(rs, ha.img, int(pos.X), int(pos.Y))
+	}
+
+	pc.FillStyle.SetColor(nil)
+	pc.StrokeStyle.SetColor(&st.Border.Color)
+	pc.StrokeStyle.Width = st.Border.Width
+	bw := 0.5 * st.Border.Width.Dots
+	pc.DrawRectangle(rs, pos.X-bw, pos.Y-bw, sz.X+2*bw, sz.Y+2*bw)
+	pc.Stroke(rs)
+
+	mx := pos.X + sz.X*ha.Hue/360
+	my := pos.Y + sz.Y*(1-ha.Sat)
+	rad := mat32.Max(4, 0.3*st.Font.Size.Dots)
+	for i, clr := range []color.Color{color.Black, color.White} {
+		pc.StrokeStyle.SetColor(clr)
+		pc.StrokeStyle.Width.Dots = 3 / float32(i+1) // black outside white
+		pc.DrawCircle(rs, mx, my, rad)
+		pc.Stroke(rs)
+	}
+}
+
+func (ha *HueSatArea) Init2D() {
+	ha.Init2DWidget()
+	ha.SetFlag(int(gi.InstaDrag))
+}
+
+func (ha *HueSatArea) ConnectEvents2D() {
+	ha.ConnectEvent(oswin.MouseEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.Event)
+		haa := recv.Embed(KiT_HueSatArea).(*HueSatArea)
+		if haa.IsInactive() || me.Button != mouse.Left || me.Action != mouse.Press {
+			return
+		}
+		me.SetProcessed()
+		haa.SetFromPoint(me.Where)
+	})
+	ha.ConnectEvent(oswin.MouseDragEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.DragEvent)
+		haa := recv.Embed(KiT_HueSatArea).(*HueSatArea)
+		if haa.IsInactive() {
+			return
+		}
+		me.SetProcessed()
+		haa.SetFromPoint(me.Where)
+	})
+	ha.HoverTooltipEvent()
+}
+
+func (ha *HueSatArea) Render2D() {
+	if ha.FullReRenderIfNeeded() {
+		return
+	}
+	if ha.PushBounds() {
+		ha.This().(gi.Node2D).ConnectEvents2D()
+		ha.RenderArea()
+		ha.Render2DChildren()
+		ha.PopBounds()
+	} else {
+		ha.DisconnectAllEvents(gi.RegPri)
+	}
+}
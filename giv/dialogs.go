@@ -5,6 +5,8 @@
 package giv
 
 import (
+	"log"
+	"path/filepath"
 	"reflect"
 
 	"github.com/goki/gi/gi"
@@ -32,6 +34,7 @@ type DlgOpts struct {
 	Data     interface{} `desc:"if non-nil, this is data that identifies what the dialog is about -- if an existing dialog for such data is already in place, then it is shown instead of making a new one"`
 	Filename string      `desc:"filename, e.g., for TextView, to get highlighting"`
 	LineNos  bool        `desc:"include line numbers for TextView"`
+	Save     bool        `desc:"for FileViewDialog, the file is to be saved, so a native file dialog allows entering a new file name"`
 }
 
 // ToGiOpts converts giv opts to gi opts
@@ -81,7 +84,7 @@ func TextViewDialog(avp *gi.Viewport2D, text []byte, opts DlgOpts) *TextView {
 		bbox = dlg.AddButtonBox(frame)
 	}
 	cpb := gi.AddNewButton(bbox, "copy-to-clip")
-	cpb.SetText("Copy To Clipboard")
+	cpb.SetText(gi.T("Copy To Clipboard"))
 	cpb.SetIcon("copy")
 	cpb.ButtonSig.Connect(dlg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 		if sig == int64(gi.ButtonClicked) {
@@ -124,6 +127,12 @@ func StructViewDialog(avp *gi.Viewport2D, stru interface{}, opts DlgOpts, recv k
 	sv.ViewPath = opts.ViewPath
 	sv.TmpSave = opts.TmpSave
 	sv.SetStruct(stru)
+	if okb := dlg.OkButton(); okb != nil && sv.HasValids { // only Ok when valid
+		okb.SetActiveState(sv.IsValid())
+		sv.ValidSig.Connect(dlg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			okb.SetActiveStateUpdt(data.(bool))
+		})
+	}
 	if recv != nil && dlgFunc != nil {
 		dlg.DialogSig.Connect(recv, dlgFunc)
 	}
@@ -443,9 +452,9 @@ func IconChooserStyleFunc(sv *SliceView, slice interface{}, widg gi.Node2D, row
 	}
 }
 
-// ColorViewDialog for editing a color using a ColorView -- optionally
+// ColorViewDialog for editing a color using a ColorPicker -- optionally
 // connects to given signal receiving object and function for dialog signals
-// (nil to ignore)
+// (nil to ignore) -- the color is added to gi.RecentColors when accepted
 func ColorViewDialog(avp *gi.Viewport2D, clr gist.Color, opts DlgOpts, recv ki.Ki, dlgFunc ki.RecvFunc) *gi.Dialog {
 	dlg, recyc := gi.RecycleStdDialog(clr, opts.ToGiOpts(), gi.AddOk, gi.AddCancel)
 	if recyc {
@@ -455,12 +464,18 @@ func ColorViewDialog(avp *gi.Viewport2D, clr gist.Color, opts DlgOpts, recv ki.K
 	frame := dlg.Frame()
 	_, prIdx := dlg.PromptWidget(frame)
 
-	sv := frame.InsertNewChild(KiT_ColorView, prIdx+1, "color-view").(*ColorView)
+	sv := frame.InsertNewChild(KiT_ColorPicker, prIdx+1, "color-picker").(*ColorPicker)
 	sv.Viewport = dlg.Embed(gi.KiT_Viewport2D).(*gi.Viewport2D)
 	sv.ViewPath = opts.ViewPath
 	sv.TmpSave = opts.TmpSave
 	sv.SetColor(clr)
 
+	dlg.DialogSig.Connect(sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.DialogAccepted) {
+			svv, _ := recv.Embed(KiT_ColorPicker).(*ColorPicker)
+			svv.AddRecentColor()
+		}
+	})
 	if recv != nil && dlgFunc != nil {
 		dlg.DialogSig.Connect(recv, dlgFunc)
 	}
@@ -473,14 +488,54 @@ func ColorViewDialog(avp *gi.Viewport2D, clr gist.Color, opts DlgOpts, recv ki.K
 // ColorViewDialogValue gets the color from the dialog
 func ColorViewDialogValue(dlg *gi.Dialog) gist.Color {
 	frame := dlg.Frame()
-	cvvvk := frame.ChildByType(KiT_ColorView, ki.Embeds, 2)
-	if cvvvk != nil {
-		cvvv := cvvvk.(*ColorView)
-		return cvvv.Color
+	cpk := frame.ChildByType(KiT_ColorPicker, ki.Embeds, 2)
+	if cpk != nil {
+		cp := cpk.(*ColorPicker)
+		return cp.Color
 	}
 	return gist.Color{}
 }
 
+// GradientViewDialog for editing a color spec, as a solid color or a
+// gradient, using a GradientView -- the spec is copied, and the edited one
+// is returned by GradientViewDialogValue -- optionally connects to given
+// signal receiving object and function for dialog signals (nil to ignore)
+func GradientViewDialog(avp *gi.Viewport2D, cs *gist.ColorSpec, opts DlgOpts, recv ki.Ki, dlgFunc ki.RecvFunc) *gi.Dialog {
+	dlg, recyc := gi.RecycleStdDialog(cs, opts.ToGiOpts(), gi.AddOk, gi.AddCancel)
+	if recyc {
+		return dlg
+	}
+
+	frame := dlg.Frame()
+	_, prIdx := dlg.PromptWidget(frame)
+
+	gv := frame.InsertNewChild(KiT_GradientView, prIdx+1, "gradient-view").(*GradientView)
+	gv.Viewport = dlg.Embed(gi.KiT_Viewport2D).(*gi.Viewport2D)
+	gv.ViewPath = opts.ViewPath
+	gv.TmpSave = opts.TmpSave
+	gv.SetColorSpec(cs)
+
+	if recv != nil && dlgFunc != nil {
+		dlg.DialogSig.Connect(recv, dlgFunc)
+	}
+
+	dlg.UpdateEndNoSig(true)
+	dlg.Open(0, 0, avp, nil)
+	return dlg
+}
+
+// GradientViewDialogValue gets the color spec from the dialog
+func GradientViewDialogValue(dlg *gi.Dialog) gist.ColorSpec {
+	var cs gist.ColorSpec
+	frame := dlg.Frame()
+	gvk := frame.ChildByType(KiT_GradientView, ki.Embeds, 2)
+	if gvk != nil {
+		gv := gvk.(*GradientView)
+		cs.CopyFrom(&gv.Spec)
+	}
+	return cs
+}
+
 // FileViewDialog is for selecting / manipulating files -- ext is one or more
 // (comma separated) extensions -- files with those will be highlighted
 // (include the . at the start of the extension).  recv and dlgFunc connect to the
@@ -488,6 +543,11 @@ func ColorViewDialogValue(dlg *gi.Dialog) gist.Color {
 // to get the resulting selected file.  The optional filterFunc can filter
 // files shown in the view -- e.g., FileViewDirOnlyFilter (for only showing
 // directories) and FileViewExtOnlyFilter (for only showing directories).
+// The platform-native file dialog is used instead if available and enabled
+// in gi.Prefs.Params.NativeFileDialogs, and filterFunc is nil or
+// FileViewDirOnlyFilter -- the dialog signal and FileViewDialogValue work
+// the same way, but the returned dialog is only opened if the native one
+// fails.  Set opts.Save when a new file name can be entered.
 func FileViewDialog(avp *gi.Viewport2D, filename, ext string, opts DlgOpts, filterFunc FileViewFilterFunc, recv ki.Ki, dlgFunc ki.RecvFunc) *gi.Dialog {
 	dlg := gi.NewStdDialog(opts.ToGiOpts(), gi.AddOk, gi.AddCancel)
 	dlg.SetName("file-view") // use a consistent name for consistent sizing / placement
@@ -506,16 +566,62 @@ func FileViewDialog(avp *gi.Viewport2D, filename, ext string, opts DlgOpts, filt
 			ddlg.Accept()
 		}
 	})
+	dlg.DialogSig.Connect(fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.DialogAccepted) {
+			fvv, _ := recv.Embed(KiT_FileView).(*FileView)
+			fvv.AddRecentFile()
+		}
+	})
 
 	if recv != nil && dlgFunc != nil {
 		dlg.DialogSig.Connect(recv, dlgFunc)
 	}
 
 	dlg.UpdateEndNoSig(true)
+	if FileViewNativeDialog(avp, fv, dlg, filename, ext, opts) {
+		return dlg
+	}
 	dlg.Open(0, 0, avp, nil)
 	return dlg
 }
 
+// FileViewNativeDialog shows the platform-native file dialog in place of
+// given FileViewDialog, if available and enabled, returning false if not.
+// The dialog is accepted with the chosen file set in the FileView, or
+// canceled, and if the native dialog fails it is opened instead.
+func FileViewNativeDialog(avp *gi.Viewport2D, fv *FileView, dlg *gi.Dialog, filename, ext string, opts DlgOpts) bool {
+	if !gi.Prefs.Params.NativeFileDialogs || oswin.TheApp == nil || !oswin.TheApp.HasFileDialog() {
+		return false
+	}
+	dirOnly := false
+	if fv.FilterFunc != nil {
+		if reflect.ValueOf(fv.FilterFunc).Pointer() != reflect.ValueOf(FileViewDirOnlyFilter).Pointer() {
+			return false // custom filters are not supported natively
+		}
+		dirOnly = true
+	}
+	fo := &oswin.FileDialogOptions{Title: opts.Title, Filename: filename, Save: opts.Save, Dir: dirOnly}
+	fo.SetExts(ext)
+	var owin oswin.Window
+	if avp != nil && avp.Win != nil {
+		owin = avp.Win.OSWin
+	}
+	go func() {
+		fn, err := oswin.TheApp.FileDialog(owin, fo)
+		switch {
+		case err != nil:
+			log.Println(err)
+			dlg.Open(0, 0, avp, nil)
+		case fn == "":
+			dlg.Cancel()
+		default:
+			fv.DirPath, fv.SelFile = filepath.Split(fn)
+			dlg.Accept()
+		}
+	}()
+	return true
+}
+
 // FileViewDialogValue gets the full path of selected file
 func FileViewDialogValue(dlg *gi.Dialog) string {
 	frame := dlg.Frame()
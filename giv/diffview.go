@@ -369,8 +369,8 @@ func (dv *DiffView) TagWordDiffs() {
 		stln := df.I1
 		for i := 0; i < mx; i++ {
 			ln := stln + i
-			ra := dv.BufA.Lines[ln]
-			rb := dv.BufB.Lines[ln]
+			ra := dv.BufA.Line(ln)
+			rb := dv.BufB.Line(ln)
 			lna := lex.RuneFields(ra)
 			lnb := lex.RuneFields(rb)
 			fla := lna.RuneStrings(ra)
@@ -737,6 +737,7 @@ var DiffViewProps = ki.Props{
 			"Args": ki.PropSlice{
 				{"File Name", ki.Props{
 					"default-field": "FileA",
+					"save":          true,
 				}},
 			},
 		}},
@@ -744,6 +745,7 @@ var DiffViewProps = ki.Props{
 			"Args": ki.PropSlice{
 				{"File Name", ki.Props{
 					"default-field": "FileB",
+					"save":          true,
 				}},
 			},
 		}},
@@ -37,6 +37,7 @@ type FileInfo struct {
 	ModTime FileTime          `desc:"time that contents (only) were last modified"`
 	Vcs     vci.FileStatus    `tableview:"-" desc:"version control system status, when enabled"`
 	Path    string            `tableview:"-" desc:"full path to file, including name -- for file functions"`
+	Type    *FileType         `tableview:"-" view:"-" json:"-" xml:"-" desc:"registered type of the file, if any, which overrides the detection of the mime type etc -- see AddFileType"`
 }
 
 var KiT_FileInfo = kit.Types.AddType(&FileInfo{}, FileInfoProps)
@@ -76,6 +77,7 @@ func (fi *FileInfo) Stat() error {
 	fi.Size = FileSize(info.Size())
 	fi.Mode = info.Mode()
 	fi.ModTime = FileTime(info.ModTime())
+	fi.Type = nil
 	if info.IsDir() {
 		fi.Kind = "Folder"
 		fi.Cat = filecat.Folder
@@ -84,6 +86,7 @@ func (fi *FileInfo) Stat() error {
 		fi.Cat = filecat.Unknown
 		fi.Sup = filecat.NoSupport
 		fi.Kind = ""
+		fi.Type = FileTypeForName(fi.Name)
 		mtyp, _, err := filecat.MimeFromFile(fi.Path)
 		if err == nil {
 			fi.Mime = mtyp
@@ -98,6 +101,12 @@ func (fi *FileInfo) Stat() error {
 				fi.Kind += filecat.MimeSub(fi.Mime)
 			}
 		}
+		if fi.Type == nil && fi.Cat == filecat.Unknown && info.Mode().IsRegular() {
+			fi.Type = FileTypeForContents(fi.Path)
+		}
+		if fi.Type != nil {
+			fi.SetType(fi.Type)
+		}
 		if fi.Cat == filecat.Unknown {
 			if fi.IsExec() {
 				fi.Cat = filecat.Exe
@@ -109,6 +118,25 @@ func (fi *FileInfo) Stat() error {
 	return nil
 }
 
+// SetType sets the file to be of given registered FileType, setting its
+// mime type, category, supported type and kind from it
+func (fi *FileInfo) SetType(ft *FileType) {
+	fi.Type = ft
+	if mtyp := ft.MimeString(); mtyp != "" {
+		fi.Mime = mtyp
+	}
+	if ft.Cat != filecat.Unknown {
+		fi.Cat = ft.Cat
+	}
+	if ft.Sup != filecat.NoSupport {
+		fi.Sup = ft.Sup
+	}
+	fi.Kind = ft.Name
+	if fi.Cat != filecat.Unknown {
+		fi.Kind = fi.Cat.String() + ": " + ft.Name
+	}
+}
+
 // IsDir returns true if file is a directory (folder)
 func (fi *FileInfo) IsDir() bool {
 	return fi.Mode.IsDir()
@@ -259,6 +287,9 @@ func (fi *FileInfo) FindIcon() (gi.IconName, bool) {
 	if fi.IsDir() {
 		return gi.IconName("folder"), true
 	}
+	if fi.Type != nil && fi.Type.Icon.IsValid() {
+		return fi.Type.Icon, true
+	}
 	if fi.Sup != filecat.NoSupport {
 		snm := strings.ToLower(fi.Sup.String())
 		if icn := gi.IconName(snm); icn.IsValid() {
@@ -15,12 +15,13 @@ func _() {
 	var x [1]struct{}
 	_ = x[FileNodeOpen-16]
 	_ = x[FileNodeSymLink-17]
-	_ = x[FileNodeFlagsN-18]
+	_ = x[FileNodeVcsIgnored-18]
+	_ = x[FileNodeFlagsN-19]
 }
 
-const _FileNodeFlags_name = "FileNodeOpenFileNodeSymLinkFileNodeFlagsN"
+const _FileNodeFlags_name = "FileNodeOpenFileNodeSymLinkFileNodeVcsIgnoredFileNodeFlagsN"
 
-var _FileNodeFlags_index = [...]uint8{0, 12, 27, 41}
+var _FileNodeFlags_index = [...]uint8{0, 12, 27, 45, 59}
 
 func (i FileNodeFlags) String() string {
 	i -= 16
@@ -70,6 +70,8 @@ type FileTree struct {
 	DirsOnTop bool         `desc:"if true, then all directories are placed at the top of the tree view -- otherwise everything is mixed"`
 	NodeType  reflect.Type `view:"-" json:"-" xml:"-" desc:"type of node to create -- defaults to giv.FileNode but can use custom node types"`
 	InOpenAll bool         `desc:"if true, we are in midst of an OpenAll call -- nodes should open all dirs"`
+	VcsSig    ki.Signal    `json:"-" xml:"-" view:"-" desc:"signal for version control status updates -- see FileTreeSignals"`
+	watchRecv ki.Ki        // receiver of the changes of the watched directories -- see WatchFiles
 }
 
 var KiT_FileTree = kit.Types.AddType(&FileTree{}, FileTreeProps)
@@ -85,6 +87,12 @@ func (ft *FileTree) CopyFieldsFrom(frm interface{}) {
 	ft.NodeType = fr.NodeType
 }
 
+func (ft *FileTree) Disconnect() {
+	ft.FileNode.Disconnect()
+	ft.VcsSig.DisconnectAll()
+	ft.UnwatchFiles()
+}
+
 // OpenPath opens a filetree at given directory path -- reads all the files at
 // given path into this tree -- uses config children to preserve extra info
 // already stored about files.  Only paths listed in Dirs will be opened.
@@ -103,6 +111,7 @@ func (ft *FileTree) UpdateAll() {
 	ft.ReadDir(string(ft.FPath))
 	// the problem here is that closed dirs are not visited but we want to keep their settings:
 	// ft.Dirs.DeleteStale()
+	ft.VcsSig.Emit(ft.This(), int64(FileTreeVcsUpdated), ft.This())
 }
 
 // UpdateNewFile should be called with path to a new file that has just been
@@ -260,12 +269,13 @@ var FileNodeHiStyle = histyle.StyleDefault
 // the name of the file.  Folders have children containing further nodes.
 type FileNode struct {
 	ki.Node
-	FPath     gi.FileName `json:"-" xml:"-" copy:"-" desc:"full path to this file"`
-	Info      FileInfo    `json:"-" xml:"-" copy:"-" desc:"full standard file info about this file"`
-	Buf       *TextBuf    `json:"-" xml:"-" copy:"-" desc:"file buffer for editing this file"`
-	FRoot     *FileTree   `json:"-" xml:"-" copy:"-" desc:"root of the tree -- has global state"`
-	DirRepo   vci.Repo    `json:"-" xml:"-" copy:"-" desc:"version control system repository for this directory, only non-nil if this is the highest-level directory in the tree under vcs control"`
-	RepoFiles vci.Files   `json:"-" xml:"-" copy:"-" desc:"version control system repository file status -- only valid during ReadDir"`
+	FPath       gi.FileName     `json:"-" xml:"-" copy:"-" desc:"full path to this file"`
+	Info        FileInfo        `json:"-" xml:"-" copy:"-" desc:"full standard file info about this file"`
+	Buf         *TextBuf        `json:"-" xml:"-" copy:"-" desc:"file buffer for editing this file"`
+	FRoot       *FileTree       `json:"-" xml:"-" copy:"-" desc:"root of the tree -- has global state"`
+	DirRepo     vci.Repo        `json:"-" xml:"-" copy:"-" desc:"version control system repository for this directory, only non-nil if this is the highest-level directory in the tree under vcs control"`
+	RepoFiles   vci.Files       `json:"-" xml:"-" copy:"-" desc:"version control system repository file status -- only valid during ReadDir"`
+	RepoIgnored map[string]bool `json:"-" xml:"-" copy:"-" desc:"files and directories ignored by the version control system, relative to the repository -- only set for the root of a repository, with RepoFiles"`
 }
 
 var KiT_FileNode = kit.Types.AddType(&FileNode{}, FileNodeProps)
@@ -427,9 +437,9 @@ func (fn *FileNode) UpdateDir() {
 	fn.DetectVcsRepo(true) // update files
 	path := string(fn.FPath)
 	// fmt.Printf("path: %v  node: %v\n", path, fn.PathUnique())
-	repo, rnode := fn.Repo()
 	fn.SetOpen()
 	fn.FRoot.SetDirOpen(fn.FPath)
+	fn.watchDir()
 	config := fn.ConfigOfFiles(path)
 	hasExtFiles := false
 	if fn.This() == fn.FRoot.This() {
@@ -455,14 +465,7 @@ func (fn *FileNode) UpdateDir() {
 		// 	fmt.Printf("fp: %v  nm: %v\n", fp, sf.Nm)
 		// }
 		sf.SetNodePath(fp)
-		if sf.IsDir() {
-			sf.Info.Vcs = vci.Stored // always
-		} else if repo != nil {
-			rstat := rnode.RepoFiles.Status(repo, string(sf.FPath))
-			sf.Info.Vcs = rstat
-		} else {
-			sf.Info.Vcs = vci.Stored
-		}
+		sf.UpdateVcsStatus()
 	}
 	if mods {
 		fn.UpdateEnd(updt)
@@ -605,6 +608,7 @@ func (fn *FileNode) OpenDir() {
 func (fn *FileNode) CloseDir() {
 	fn.SetClosed()
 	fn.FRoot.SetDirClosed(fn.FPath)
+	fn.unwatchDir()
 	// note: not doing anything with open files within directory..
 }
 
@@ -630,6 +634,7 @@ func (fn *FileNode) OpenAll() {
 func (fn *FileNode) CloseAll() {
 	fn.SetClosed()
 	fn.FRoot.SetDirClosed(fn.FPath)
+	fn.unwatchDir()
 	fn.FuncDownMeFirst(0, fn, func(k ki.Ki, level int, d interface{}) bool {
 		sfn := k.Embed(KiT_FileNode).(*FileNode)
 		if sfn.IsDir() {
@@ -885,7 +890,7 @@ func OSOpenCommand() string {
 	switch oswin.TheApp.Platform() {
 	case oswin.MacOS:
 		return "open"
-	case oswin.LinuxX11:
+	case oswin.LinuxX11, oswin.LinuxWayland:
 		return "xdg-open"
 	case oswin.Windows:
 		return "start"
@@ -1093,6 +1098,7 @@ func (fn *FileNode) UpdateRepoFiles() {
 		return
 	}
 	fn.RepoFiles, _ = fn.DirRepo.Files()
+	fn.updateRepoStatus()
 }
 
 // AddToVcs adds file to version control
@@ -1385,6 +1391,10 @@ const (
 	// all for the target of the symlink
 	FileNodeSymLink
 
+	// FileNodeVcsIgnored indicates that file is ignored by the version
+	// control system, e.g., in .gitignore -- it is dimmed in the view
+	FileNodeVcsIgnored
+
 	FileNodeFlagsN
 )
 
@@ -1548,6 +1558,11 @@ func (ftv *FileTreeView) UpdateAllFiles() {
 
 func (ftv *FileTreeView) ConnectEvents2D() {
 	ftv.FileTreeViewEvents()
+	if ftv.RootView != nil && ftv.RootView.This() == ftv.This() {
+		if fn := ftv.FileNode(); fn != nil && fn.FRoot != nil {
+			fn.FRoot.WatchFiles(ftv.This())
+		}
+	}
 }
 
 func (ftv *FileTreeView) FileTreeViewEvents() {
@@ -2410,6 +2425,9 @@ var FileTreeViewProps = ki.Props{
 	".updated": ki.Props{
 		"color": "#008060",
 	},
+	".ignored": ki.Props{
+		"opacity": 0.5,
+	},
 	"#icon": ki.Props{
 		"width":   units.NewEm(1),
 		"height":  units.NewEm(1),
@@ -2606,6 +2624,9 @@ func (ft *FileTreeView) Style2D() {
 				ft.AddClass("updated")
 			}
 		}
+		if fn.IsVcsIgnored() {
+			ft.AddClass("ignored")
+		}
 		ft.StyleTreeView()
 		ft.LayState.SetFromStyle(&ft.Sty.Layout) // also does reset
 	}
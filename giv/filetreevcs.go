@@ -0,0 +1,199 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/vcs"
+	"github.com/goki/ki/ki"
+	"github.com/goki/vci"
+)
+
+// FileTreeSignals are signals that FileTree sends on its VcsSig
+type FileTreeSignals int64
+
+const (
+	// FileTreeVcsUpdated is emitted when the version control status of the
+	// files in the tree has been updated, by UpdateAll or UpdateVcs (data is
+	// the FileTree)
+	FileTreeVcsUpdated FileTreeSignals = iota
+
+	FileTreeSignalsN
+)
+
+//go:generate stringer -type=FileTreeSignals
+
+// FileNodeVcsBadges are the badges shown after the names of files in a
+// FileTreeView for their version control status -- none for Stored files
+var FileNodeVcsBadges = map[vci.FileStatus]string{
+	vci.Untracked:  "U",
+	vci.Modified:   "M",
+	vci.Added:      "A",
+	vci.Deleted:    "D",
+	vci.Conflicted: "C",
+	vci.Updated:    "↓",
+}
+
+// UpdateVcs updates the version control status of all the files in the tree,
+// e.g., after a change made outside of the app such as a git command run in a
+// terminal, without reading the directories again, and emits
+// FileTreeVcsUpdated on VcsSig
+func (ft *FileTree) UpdateVcs() {
+	updt := ft.UpdateStart()
+	ft.FuncDownMeFirst(0, ft, func(k ki.Ki, level int, d interface{}) bool {
+		sfni := k.Embed(KiT_FileNode)
+		if sfni == nil {
+			return ki.Break
+		}
+		sfn := sfni.(*FileNode)
+		if sfn.IsIrregular() {
+			return ki.Break // external files
+		}
+		if sfn.DirRepo != nil {
+			sfn.UpdateRepoFiles()
+		}
+		if sfn.This() != ft.This() {
+			sfn.UpdateVcsStatus()
+		}
+		return ki.Continue
+	})
+	ft.UpdateEnd(updt)
+	ft.VcsSig.Emit(ft.This(), int64(FileTreeVcsUpdated), ft.This())
+}
+
+// IsVcsIgnored returns true if the file is ignored by its version control
+// system, e.g., in .gitignore
+func (fn *FileNode) IsVcsIgnored() bool {
+	return fn.HasFlag(int(FileNodeVcsIgnored))
+}
+
+// UpdateVcsStatus updates the version control status of the file, and
+// whether it is ignored, from the files of its repository, last updated by
+// UpdateRepoFiles
+func (fn *FileNode) UpdateVcsStatus() {
+	repo, rnode := fn.Repo()
+	switch {
+	case repo == nil:
+		fn.Info.Vcs = vci.Stored
+		fn.ClearFlag(int(FileNodeVcsIgnored))
+		return
+	case fn.IsDir():
+		fn.Info.Vcs = vci.Stored // always
+	default:
+		fn.Info.Vcs = rnode.RepoFiles.Status(repo, string(fn.FPath))
+	}
+	fn.SetFlagState(rnode.IsRepoIgnored(vci.RelPath(repo, string(fn.FPath))), int(FileNodeVcsIgnored))
+}
+
+// VcsBadge returns the badge for the version control status of the file,
+// from FileNodeVcsBadges -- "" if none, including for ignored files
+func (fn *FileNode) VcsBadge() string {
+	if fn.IsDir() || fn.IsIrregular() || fn.IsVcsIgnored() {
+		return ""
+	}
+	if repo, _ := fn.Repo(); repo == nil {
+		return ""
+	}
+	return FileNodeVcsBadges[fn.Info.Vcs]
+}
+
+// TreeViewBadge satisfies the TreeViewBadger interface, showing the VcsBadge
+func (fn *FileNode) TreeViewBadge() string {
+	return fn.VcsBadge()
+}
+
+// IsRepoIgnored returns true if the file at given path, relative to this
+// root of a repository, or any directory containing it, is in the
+// RepoIgnored files
+func (fn *FileNode) IsRepoIgnored(relpath string) bool {
+	if len(fn.RepoIgnored) == 0 {
+		return false
+	}
+	for relpath != "." && relpath != "" && relpath != string(filepath.Separator) {
+		if fn.RepoIgnored[relpath] {
+			return true
+		}
+		relpath = filepath.Dir(relpath)
+	}
+	return false
+}
+
+// updateRepoStatus updates RepoFiles of this root of a repository from the
+// status of its changed files, which are Added, Deleted etc, and gets the
+// RepoIgnored files, for git and svn
+func (fn *FileNode) updateRepoStatus() {
+	fn.RepoIgnored = nil
+	var out []byte
+	var err error
+	switch fn.DirRepo.Vcs() {
+	case vcs.Git:
+		out, err = fn.DirRepo.RunFromDir("git", "status", "--porcelain", "--ignored", "-z")
+	case vcs.Svn:
+		out, err = fn.DirRepo.RunFromDir("svn", "status", "--no-ignore")
+	default:
+		return
+	}
+	if err != nil {
+		return
+	}
+	if fn.RepoFiles == nil {
+		fn.RepoFiles = make(vci.Files)
+	}
+	fn.RepoIgnored = make(map[string]bool)
+	if fn.DirRepo.Vcs() == vcs.Git {
+		ents := bytes.Split(out, []byte{0})
+		for i := 0; i < len(ents); i++ {
+			ent := ents[i]
+			if len(ent) < 4 {
+				continue
+			}
+			x, y := ent[0], ent[1]
+			fnm := filepath.FromSlash(strings.TrimSuffix(string(ent[3:]), "/"))
+			if x == 'R' || x == 'C' {
+				i++ // original path follows
+			}
+			switch {
+			case x == '!':
+				fn.RepoIgnored[fnm] = true
+			case x == '?': // untracked files are already in RepoFiles
+			case x == 'U' || y == 'U' || (x == 'A' && y == 'A') || (x == 'D' && y == 'D'):
+				fn.RepoFiles[fnm] = vci.Conflicted
+			case x == 'A' || x == 'R' || x == 'C':
+				fn.RepoFiles[fnm] = vci.Added
+			case x == 'D' || y == 'D':
+				fn.RepoFiles[fnm] = vci.Deleted
+			default:
+				fn.RepoFiles[fnm] = vci.Modified
+			}
+		}
+		return
+	}
+	scan := bufio.NewScanner(bytes.NewReader(out))
+	for scan.Scan() {
+		ln := scan.Text()
+		if len(ln) < 9 {
+			continue
+		}
+		fnm := filepath.FromSlash(strings.TrimSpace(ln[8:]))
+		switch ln[0] {
+		case 'I':
+			fn.RepoIgnored[fnm] = true
+		case '?':
+			fn.RepoFiles[fnm] = vci.Untracked
+		case 'A':
+			fn.RepoFiles[fnm] = vci.Added
+		case 'D', '!':
+			fn.RepoFiles[fnm] = vci.Deleted
+		case 'C':
+			fn.RepoFiles[fnm] = vci.Conflicted
+		case 'M', 'R':
+			fn.RepoFiles[fnm] = vci.Modified
+		}
+	}
+}
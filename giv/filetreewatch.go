@@ -0,0 +1,116 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"path/filepath"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki/ki"
+)
+
+// WatchFiles starts watching the open directories of the tree with
+// gi.TheFileWatcher, so that changes made outside of the app, e.g., by a
+// version control command, update the tree -- the changes are handled in
+// the window of given receiver, typically the root FileTreeView of the
+// tree, which does this automatically.  Directories are watched as they are
+// opened, and no longer watched when they are closed.
+func (ft *FileTree) WatchFiles(recv ki.Ki) {
+	if ft.watchRecv == recv {
+		return
+	}
+	ft.UnwatchFiles()
+	ft.watchRecv = recv
+	ft.FuncDownMeFirst(0, ft, func(k ki.Ki, level int, d interface{}) bool {
+		sfni := k.Embed(KiT_FileNode)
+		if sfni == nil {
+			return ki.Break
+		}
+		sfn := sfni.(*FileNode)
+		if sfn.IsIrregular() || !sfn.IsDir() || !sfn.IsOpen() {
+			return ki.Break
+		}
+		sfn.watchDir()
+		return ki.Continue
+	})
+}
+
+// UnwatchFiles stops watching the directories of the tree, started by
+// WatchFiles
+func (ft *FileTree) UnwatchFiles() {
+	if ft.watchRecv == nil {
+		return
+	}
+	gi.TheFileWatcher.UnwatchAll(ft.watchRecv)
+	ft.watchRecv = nil
+}
+
+// IsWatching returns true if the open directories of the tree are being
+// watched for changes -- see WatchFiles
+func (ft *FileTree) IsWatching() bool {
+	return ft.watchRecv != nil
+}
+
+// FilesChanged updates the tree for given changes of the files in its
+// directories, from gi.TheFileWatcher: the directories in which files were
+// created, removed or renamed are updated, as are the files that were
+// written
+func (ft *FileTree) FilesChanged(evs []gi.FileWatchEvent) {
+	if ft.This() == nil {
+		return
+	}
+	var dirs []*FileNode
+	for i := range evs {
+		ev := &evs[i]
+		dn, ok := ft.FindFile(filepath.Dir(ev.Path))
+		if !ok || !dn.IsDir() {
+			continue
+		}
+		if ev.HasOp(gi.FileWatchRemove) || ev.HasOp(gi.FileWatchRename) {
+			gi.TheFileWatcher.Unwatch(ev.Path, ft.watchRecv) // if it was an open dir
+		}
+		if ev.HasOp(gi.FileWatchCreate) || ev.HasOp(gi.FileWatchRemove) || ev.HasOp(gi.FileWatchRename) {
+			has := false
+			for _, d := range dirs {
+				if d == dn {
+					has = true
+					break
+				}
+			}
+			if !has {
+				dirs = append(dirs, dn)
+			}
+			continue
+		}
+		if sfni, err := dn.ChildByNameTry(filepath.Base(ev.Path), 0); err == nil {
+			sfn := sfni.Embed(KiT_FileNode).(*FileNode)
+			if !sfn.IsDir() {
+				sfn.UpdateNode()
+			}
+		}
+	}
+	for _, dn := range dirs {
+		dn.UpdateNode()
+	}
+}
+
+// watchDir watches this open directory for changes, if the tree is being
+// watched
+func (fn *FileNode) watchDir() {
+	ft := fn.FRoot
+	if ft == nil || ft.watchRecv == nil {
+		return
+	}
+	gi.TheFileWatcher.Watch(string(fn.FPath), true, ft.watchRecv, ft.FilesChanged)
+}
+
+// unwatchDir stops watching this directory, when it is closed
+func (fn *FileNode) unwatchDir() {
+	ft := fn.FRoot
+	if ft == nil || ft.watchRecv == nil {
+		return
+	}
+	gi.TheFileWatcher.Unwatch(string(fn.FPath), ft.watchRecv)
+}
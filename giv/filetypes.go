@@ -0,0 +1,200 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/pi/filecat"
+)
+
+// FileType is a type of file, registered with AddFileType, which determines
+// the category, mime type, syntax highlighting lexer and icon of the files of
+// that type -- it is applied by FileInfo on top of the standard detection of
+// filecat, so that FileView, FileTreeView and TextBuf all agree on them.
+// Apps register their own types to support more files, or to override the
+// standard detection.
+type FileType struct {
+	Name  string                 `desc:"name of the type, shown in the Kind of its files, e.g., Dockerfile"`
+	Mime  string                 `desc:"mime type of its files -- if empty, that of Sup is used, if any, else that detected by filecat"`
+	Exts  []string               `desc:"lower-case extensions of its files, including the initial ., e.g., .proto"`
+	Names []string               `desc:"full names of its files, for files that are recognized by name, e.g., Dockerfile"`
+	Cat   filecat.Cat            `desc:"category of its files"`
+	Sup   filecat.Supported      `desc:"supported file type of its files, if any, which determines the pi language used for them"`
+	Lexer string                 `desc:"name of the chroma lexer used for syntax highlighting its files, when there is no pi language for them, e.g., docker"`
+	Icon  gi.IconName            `desc:"icon of its files"`
+	Sniff func(head []byte) bool `view:"-" json:"-" xml:"-" desc:"optional function that recognizes its files from the start of their contents, up to FileTypeSniffLen bytes -- only used for files that are not recognized by name, nor by filecat"`
+}
+
+// FileTypeSniffLen is the number of bytes at the start of a file that are
+// passed to the Sniff functions of the FileTypes
+var FileTypeSniffLen = 512
+
+// StdFileTypes are the standard FileTypes, for files that filecat does not
+// recognize -- they are registered at startup
+var StdFileTypes = []*FileType{
+	{Name: "License", Names: []string{"LICENSE", "LICENSE.txt", "COPYING"}, Mime: "text/plain", Cat: filecat.Doc, Icon: "file-text"},
+	{Name: "Git Ignore", Names: []string{".gitignore"}, Mime: "text/plain", Cat: filecat.Text, Icon: "file-text"},
+	{Name: "Dockerfile", Names: []string{"Dockerfile"}, Mime: "text/x-dockerfile", Cat: filecat.Code, Lexer: "docker", Icon: "file-code"},
+	{Name: "Shell Script", Cat: filecat.Code, Sup: filecat.Bash, Lexer: "bash", Icon: "terminal", Sniff: SniffShebang("sh", "bash", "zsh")},
+}
+
+// fileTypes are the registered FileTypes, in order of precedence (last first)
+var fileTypes []*FileType
+
+// fileTypesMu protects fileTypes, which are used on the goroutines that load
+// files in the background
+var fileTypesMu sync.RWMutex
+
+func init() {
+	for _, ft := range StdFileTypes {
+		AddFileType(ft)
+	}
+}
+
+// AddFileType registers given FileType, replacing any registered type of the
+// same Name -- types registered later take precedence over those registered
+// before, which include StdFileTypes, and over the standard detection of
+// filecat.  If it has a Mime and Exts that filecat does not know, it is also
+// added to filecat.CustomMimes, so that filecat and pi detect it too.  It
+// should be called at startup, before files are opened, e.g., in an init
+// function.
+func AddFileType(ft *FileType) {
+	fileTypesMu.Lock()
+	for i, eft := range fileTypes {
+		if eft.Name == ft.Name {
+			fileTypes = append(fileTypes[:i], fileTypes[i+1:]...)
+			break
+		}
+	}
+	fileTypes = append(fileTypes, ft)
+	fileTypesMu.Unlock()
+
+	if ft.Mime == "" || len(ft.Exts) == 0 {
+		return
+	}
+	for _, ext := range ft.Exts {
+		if _, has := filecat.ExtMimeMap[ext]; has {
+			return
+		}
+	}
+	filecat.CustomMimes = append(filecat.CustomMimes, filecat.MimeType{Mime: ft.Mime, Exts: ft.Exts, Cat: ft.Cat, Sup: ft.Sup})
+	filecat.MergeAvailMimes()
+}
+
+// FileTypeByName returns the registered FileType of given Name -- nil if
+// none
+func FileTypeByName(name string) *FileType {
+	fileTypesMu.RLock()
+	defer fileTypesMu.RUnlock()
+	for i := len(fileTypes) - 1; i >= 0; i-- {
+		if fileTypes[i].Name == name {
+			return fileTypes[i]
+		}
+	}
+	return nil
+}
+
+// FileTypeForName returns the registered FileType of files with given file
+// name, which can include a path, from its Names or Exts -- nil if none
+func FileTypeForName(fname string) *FileType {
+	_, fn := filepath.Split(fname)
+	ext := strings.ToLower(filepath.Ext(fn))
+	fileTypesMu.RLock()
+	defer fileTypesMu.RUnlock()
+	for i := len(fileTypes) - 1; i >= 0; i-- {
+		ft := fileTypes[i]
+		for _, nm := range ft.Names {
+			if nm == fn {
+				return ft
+			}
+		}
+		if ext == "" {
+			continue
+		}
+		for _, ex := range ft.Exts {
+			if ex == ext {
+				return ft
+			}
+		}
+	}
+	return nil
+}
+
+// FileTypeForHead returns the registered FileType whose Sniff function
+// recognizes given start of the contents of a file -- nil if none
+func FileTypeForHead(head []byte) *FileType {
+	fileTypesMu.RLock()
+	defer fileTypesMu.RUnlock()
+	for i := len(fileTypes) - 1; i >= 0; i-- {
+		ft := fileTypes[i]
+		if ft.Sniff != nil && ft.Sniff(head) {
+			return ft
+		}
+	}
+	return nil
+}
+
+// FileTypeForContents returns the registered FileType of given file from
+// the start of its contents, using the Sniff functions -- nil if none, or
+// the file cannot be read
+func FileTypeForContents(fname string) *FileType {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	head := make([]byte, FileTypeSniffLen)
+	n, _ := f.Read(head)
+	if n == 0 {
+		return nil
+	}
+	return FileTypeForHead(head[:n])
+}
+
+// SniffShebang returns a Sniff function for FileType that recognizes
+// scripts that start with a #! line for one of the given interpreters,
+// e.g., #!/bin/sh or #!/usr/bin/env python
+func SniffShebang(interps ...string) func(head []byte) bool {
+	return func(head []byte) bool {
+		if !bytes.HasPrefix(head, []byte("#!")) {
+			return false
+		}
+		ln := head[2:]
+		if i := bytes.IndexByte(ln, '\n'); i >= 0 {
+			ln = ln[:i]
+		}
+		flds := strings.Fields(string(ln))
+		if len(flds) == 0 {
+			return false
+		}
+		cmd := filepath.Base(flds[0])
+		if cmd == "env" && len(flds) > 1 {
+			cmd = flds[1]
+		}
+		for _, in := range interps {
+			if cmd == in {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MimeString returns the mime type of the files of the type
+func (ft *FileType) MimeString() string {
+	if ft.Mime != "" {
+		return ft.Mime
+	}
+	if ft.Sup != filecat.NoSupport {
+		return filecat.MimeString(ft.Sup)
+	}
+	return ""
+}
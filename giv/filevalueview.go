@@ -65,7 +65,8 @@ func (vv *FileValueView) Activate(vp *gi.Viewport2D, dlgRecv ki.Ki, dlgFunc ki.R
 	cur := kit.ToString(vv.Value.Interface())
 	ext, _ := vv.Tag("ext")
 	desc, _ := vv.Tag("desc")
-	FileViewDialog(vp, cur, ext, DlgOpts{Title: vv.Name(), Prompt: desc}, nil,
+	_, save := vv.Tag("save") // file is to be saved, e.g., a SaveAs arg
+	FileViewDialog(vp, cur, ext, DlgOpts{Title: vv.Name(), Prompt: desc, Save: save}, nil,
 		vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 			if sig == int64(gi.DialogAccepted) {
 				dlg, _ := send.Embed(gi.KiT_Dialog).(*gi.Dialog)
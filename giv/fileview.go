@@ -6,17 +6,20 @@ package giv
 
 import (
 	"fmt"
+	"image"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"unicode"
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/oswin"
-	"github.com/goki/gi/oswin/cursor"
+	"github.com/goki/gi/oswin/dnd"
 	"github.com/goki/gi/oswin/key"
 	"github.com/goki/gi/units"
 	"github.com/goki/ki/ki"
@@ -42,20 +45,31 @@ type FileView struct {
 	Ext         string             `desc:"target extension(s) (comma separated if multiple, including initial .), if any"`
 	FilterFunc  FileViewFilterFunc `view:"-" json:"-" xml:"-" desc:"optional styling function"`
 	ExtMap      map[string]string  `desc:"map of lower-cased extensions from Ext -- used for highlighting files with one of these extensions -- maps onto original ext value"`
-	Files       []*FileInfo        `desc:"files for current directory"`
+	Files       []*FileInfo        `desc:"files for current directory -- loaded in the background, so this grows progressively while IsLoading"`
 	SelectedIdx int                `desc:"index of currently-selected file in Files list (-1 if none)"`
+	Recents     gi.FavPaths        `view:"-" json:"-" xml:"-" desc:"recent files shown in the sidebar, from gi.RecentFiles"`
 	FileSig     ki.Signal          `desc:"signal for file actions"`
+	loadProg    *gi.Progress       // loading of the files of the directory, nil if not loading
+	loadPath    string             // path being loaded, with symlinks evaluated
+	loadPend    bool               // loading waits for the view to be in a window
+	loadDone    func()             // called when loading is done
 }
 
 var KiT_FileView = kit.Types.AddType(&FileView{}, FileViewProps)
 
 func (fv *FileView) Disconnect() {
+	if fv.loadProg != nil {
+		fv.loadProg.Cancel()
+		fv.loadProg = nil
+	}
 	fv.Frame.Disconnect()
 	fv.FileSig.DisconnectAll()
 }
 
 // FileViewFilterFunc is a filtering function for files -- returns true if the
-// file should be visible in the view, and false if not
+// file should be visible in the view, and false if not.  It is called on the
+// goroutine that loads the files in the background, so it must not update
+// the view.
 type FileViewFilterFunc func(fv *FileView, fi *FileInfo) bool
 
 // FileViewDirOnlyFilter is a FileViewFilterFunc that only shows directories (folders).
@@ -119,6 +133,10 @@ func (fv *FileView) SelectFile() {
 	}
 }
 
+// FileViewLoadUpdate is the interval between the updates of the files shown
+// while a directory is loaded in the background
+var FileViewLoadUpdate = 200 * time.Millisecond
+
 var FileViewProps = ki.Props{
 	"EnumType:Flag":    gi.KiT_NodeFlags,
 	"color":            &gi.Prefs.Colors.Font,
@@ -226,8 +244,10 @@ func (fv *FileView) ConfigPathBar() {
 	config.Add(gi.KiT_Action, "path-ref")
 	config.Add(gi.KiT_Action, "path-fav")
 	config.Add(gi.KiT_Action, "new-folder")
+	config.Add(gi.KiT_Action, "path-stop")
+	config.Add(gi.KiT_ProgressBar, "load-bar")
 
-	pl := gi.AddNewLabel(pr, "path-lbl", "Path:")
+	pl := gi.AddNewLabel(pr, "path-lbl", gi.T("Path:"))
 	pl.Tooltip = "Path to look for files in: can select from list of recent paths, or edit a value directly"
 	pf := gi.AddNewComboBox(pr, "path")
 	pf.Editable = true
@@ -285,6 +305,17 @@ func (fv *FileView) ConfigPathBar() {
 			fvv, _ := recv.Embed(KiT_FileView).(*FileView)
 			fvv.NewFolder()
 		})
+
+	st := pr.AddAction(gi.ActOpts{Name: "path-stop", Icon: "stop", Tooltip: "Stop loading the files of this folder -- large folders are loaded in the background, showing the files loaded so far"}, fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		fvv, _ := recv.Embed(KiT_FileView).(*FileView)
+		fvv.StopLoad()
+	})
+	st.SetInactive()
+
+	lb := gi.AddNewProgressBar(pr, "load-bar")
+	lb.SetProp("min-width", units.NewEm(6))
+	lb.SetProp("width", units.NewEm(6))
+	lb.Tooltip = "progress of loading the files of this folder"
 }
 
 func (fv *FileView) ConfigFilesRow() {
@@ -292,35 +323,13 @@ func (fv *FileView) ConfigFilesRow() {
 	fr.SetStretchMax()
 	fr.Lay = gi.LayoutHoriz
 	config := kit.TypeAndNameList{}
-	config.Add(KiT_TableView, "favs-view")
+	config.Add(gi.KiT_Layout, "side")
 	config.Add(KiT_TableView, "files-view")
 	fr.ConfigChildren(config, ki.UniqueNames) // already covered by parent update
 
-	sv := fv.FavsView()
-	sv.CSS = ki.Props{
-		"textfield": ki.Props{
-			":inactive": ki.Props{
-				"background-color": &gi.Prefs.Colors.Control,
-			},
-		},
-	}
-	sv.SetStretchMaxHeight()
-	sv.SetProp("max-width", 0) // no stretch
-	sv.SetProp("index", false)
-	sv.SetProp("inact-key-nav", false) // can only have one active -- files..
-	sv.SetProp("toolbar", false)
-	sv.SetInactive() // select only
-	sv.SelectedIdx = -1
-	sv.SetSlice(&gi.Prefs.FavPaths)
-	sv.WidgetSig.Connect(fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
-		if sig == int64(gi.WidgetSelected) {
-			fvv, _ := recv.Embed(KiT_FileView).(*FileView)
-			svv, _ := send.(*TableView)
-			fvv.FavSelect(svv.SelectedIdx)
-		}
-	})
+	fv.ConfigSide()
 
-	sv = fv.FilesView()
+	sv := fv.FilesView()
 	sv.CSS = ki.Props{
 		"textfield": ki.Props{
 			":inactive": ki.Props{
@@ -352,6 +361,66 @@ func (fv *FileView) ConfigFilesRow() {
 	})
 }
 
+// ConfigSide configures the sidebar, with the favorite paths and the recent
+// files
+func (fv *FileView) ConfigSide() {
+	sd := fv.SideView()
+	sd.Lay = gi.LayoutVert
+	sd.SetStretchMaxHeight()
+	sd.SetProp("max-width", 0) // no stretch
+	config := kit.TypeAndNameList{}
+	config.Add(KiT_TableView, "favs-view")
+	config.Add(gi.KiT_Label, "recents-lbl")
+	config.Add(KiT_TableView, "recents-view")
+	sd.ConfigChildren(config, ki.UniqueNames) // already covered by parent update
+
+	sv := fv.FavsView()
+	fv.ConfigSideTable(sv)
+	sv.SetSlice(&gi.Prefs.FavPaths)
+	sv.WidgetSig.Connect(fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.WidgetSelected) {
+			fvv, _ := recv.Embed(KiT_FileView).(*FileView)
+			svv, _ := send.(*TableView)
+			fvv.FavSelect(svv.SelectedIdx)
+		}
+	})
+
+	rl := sd.ChildByName("recents-lbl", 1).(*gi.Label)
+	rl.SetText(gi.T("Recent Files"))
+	rl.Tooltip = "files recently chosen in file dialogs -- select to go to the folder of the file and select it"
+
+	fv.UpdateRecents()
+	sv = fv.RecentsView()
+	fv.ConfigSideTable(sv)
+	sv.SetSlice(&fv.Recents)
+	sv.WidgetSig.Connect(fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.WidgetSelected) {
+			fvv, _ := recv.Embed(KiT_FileView).(*FileView)
+			svv, _ := send.(*TableView)
+			fvv.RecentSelect(svv.SelectedIdx)
+		}
+	})
+}
+
+// ConfigSideTable configures given TableView of the sidebar, for selecting
+// only
+func (fv *FileView) ConfigSideTable(sv *TableView) {
+	sv.CSS = ki.Props{
+		"textfield": ki.Props{
+			":inactive": ki.Props{
+				"background-color": &gi.Prefs.Colors.Control,
+			},
+		},
+	}
+	sv.SetStretchMaxHeight()
+	sv.SetProp("max-width", 0) // no stretch
+	sv.SetProp("index", false)
+	sv.SetProp("inact-key-nav", false) // can only have one active -- files..
+	sv.SetProp("toolbar", false)
+	sv.SetInactive() // select only
+	sv.SelectedIdx = -1
+}
+
 func (fv *FileView) ConfigSelRow() {
 	sr := fv.SelRow()
 	sr.Lay = gi.LayoutHoriz
@@ -365,7 +434,7 @@ func (fv *FileView) ConfigSelRow() {
 	sr.ConfigChildren(config, ki.UniqueNames) // already covered by parent update
 
 	sl := sr.ChildByName("sel-lbl", 0).(*gi.Label)
-	sl.Text = "File:"
+	sl.Text = gi.T("File:")
 	sl.Tooltip = "enter file name here (or select from above list)"
 	sf := fv.SelField()
 	sf.Tooltip = fmt.Sprintf("enter file name.  special keys: up/down to move selection; %v or %v to go up to parent folder; %v or %v or %v or %v to select current file (if directory, goes into it, if file, selects and closes); %v or %v for prev / next history item; %s return to this field", gi.ShortcutForFun(gi.KeyFunWordLeft), gi.ShortcutForFun(gi.KeyFunJump), gi.ShortcutForFun(gi.KeyFunSelectMode), gi.ShortcutForFun(gi.KeyFunInsert), gi.ShortcutForFun(gi.KeyFunInsertAfter), gi.ShortcutForFun(gi.KeyFunMenuOpen), gi.ShortcutForFun(gi.KeyFunHistPrev), gi.ShortcutForFun(gi.KeyFunHistNext), gi.ShortcutForFun(gi.KeyFunSearch))
@@ -383,7 +452,7 @@ func (fv *FileView) ConfigSelRow() {
 	sf.StartFocus()
 
 	el := sr.ChildByName("ext-lbl", 0).(*gi.Label)
-	el.Text = "Ext(s):"
+	el.Text = gi.T("Ext(s):")
 	el.Tooltip = "target extension(s) to highlight -- if multiple, separate with commas, and do include the . at the start"
 	ef := fv.ExtField()
 	ef.SetText(fv.Ext)
@@ -403,13 +472,35 @@ func (fv *FileView) PathField() *gi.ComboBox {
 	return pr.ChildByName("path", 1).(*gi.ComboBox)
 }
 
+// StopAction returns the Action that stops loading the files
+func (fv *FileView) StopAction() *gi.Action {
+	pr := fv.ChildByName("path-tbar", 0).(*gi.ToolBar)
+	return pr.ChildByName("path-stop", 6).(*gi.Action)
+}
+
+// LoadBar returns the ProgressBar showing the progress of loading the files
+func (fv *FileView) LoadBar() *gi.ProgressBar {
+	pr := fv.ChildByName("path-tbar", 0).(*gi.ToolBar)
+	return pr.ChildByName("load-bar", 7).(*gi.ProgressBar)
+}
+
 func (fv *FileView) FilesRow() *gi.Layout {
 	return fv.ChildByName("files-row", 2).(*gi.Layout)
 }
 
+// SideView returns the sidebar Layout, with the favorites and recent files
+func (fv *FileView) SideView() *gi.Layout {
+	return fv.FilesRow().ChildByName("side", 0).(*gi.Layout)
+}
+
 // FavsView returns the TableView of the favorites
 func (fv *FileView) FavsView() *TableView {
-	return fv.FilesRow().ChildByName("favs-view", 1).(*TableView)
+	return fv.SideView().ChildByName("favs-view", 0).(*TableView)
+}
+
+// RecentsView returns the TableView of the recent files
+func (fv *FileView) RecentsView() *TableView {
+	return fv.SideView().ChildByName("recents-view", 2).(*TableView)
 }
 
 // FilesView returns the TableView of the files
@@ -443,25 +534,37 @@ func (fv *FileView) UpdatePath() {
 // UpdateFilesAction updates list of files and other views for current path,
 // emitting FileSig signals around it -- this is for gui-generated actions only.
 func (fv *FileView) UpdateFilesAction() {
+	fv.updateFilesAction(nil)
+}
+
+// updateFilesAction is UpdateFilesAction, calling done, if non-nil, when
+// the files have been loaded
+func (fv *FileView) updateFilesAction(done func()) {
 	fv.FileSig.Emit(fv.This(), int64(FileViewWillUpdate), fv.DirPath)
 	fv.SetFullReRender()
-	fv.UpdateFiles()
+	fv.updateFiles(func() {
+		if done != nil {
+			done()
+		}
+		fv.FileSig.Emit(fv.This(), int64(FileViewUpdated), fv.DirPath)
+	})
 	sf := fv.SelField()
 	sf.GrabFocus()
-	fv.FileSig.Emit(fv.This(), int64(FileViewUpdated), fv.DirPath)
 }
 
-// UpdateFiles updates list of files and other views for current path
+// UpdateFiles updates list of files and other views for current path -- the
+// files are loaded in the background, starting once the view is in a
+// window, and shown progressively as they are loaded, so that large
+// directories do not block the GUI.  Any previous loading is canceled.
 func (fv *FileView) UpdateFiles() {
+	fv.updateFiles(nil)
+}
+
+// updateFiles is UpdateFiles, calling done, if non-nil, when the files have
+// been loaded
+func (fv *FileView) updateFiles(done func()) {
 	updt := fv.UpdateStart()
 	defer fv.UpdateEnd(updt)
-	var owin oswin.Window
-	win := fv.ParentWindow()
-	if win != nil {
-		owin = fv.Viewport.Win.OSWin
-	} else {
-		owin = oswin.TheApp.WindowInFocus()
-	}
 
 	fv.UpdatePath()
 	pf := fv.PathField()
@@ -475,8 +578,13 @@ func (fv *FileView) UpdateFiles() {
 	pf.SetText(fv.DirPath)
 	sf := fv.SelField()
 	sf.SetText(fv.SelFile)
-	oswin.TheApp.Cursor(owin).Push(cursor.Wait)
-	defer oswin.TheApp.Cursor(owin).Pop()
+
+	if fv.loadProg != nil {
+		fv.loadProg.Cancel()
+		fv.loadProg = nil
+	}
+	fv.loadPend = false
+	fv.loadDone = done
 
 	effpath, err := filepath.EvalSymlinks(fv.DirPath)
 	if err != nil {
@@ -490,51 +598,157 @@ func (fv *FileView) UpdateFiles() {
 	}
 
 	fv.Files = make([]*FileInfo, 0, 1000)
-	filepath.Walk(effpath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			emsg := fmt.Sprintf("Path %q: Error: %v", effpath, err)
-			// if fv.Viewport != nil {
-			// 	gi.PromptDialog(fv.Viewport, "FileView UpdateFiles", emsg, gi.AddOk, gi.NoCancel, nil, nil)
-			// } else {
-			log.Printf("gi.FileView error: %v\n", emsg)
-			// }
-			return nil // ignore
-		}
-		if path == effpath { // proceed..
-			return nil
-		}
-		fi, ferr := NewFileInfo(path)
-		keep := ferr == nil
-		if fv.FilterFunc != nil {
-			keep = fv.FilterFunc(fv, fi)
-		}
-		if keep {
-			fv.Files = append(fv.Files, fi)
-		}
-		if info.IsDir() {
-			return filepath.SkipDir
-		}
-		return nil
-	})
+	fv.loadPath = effpath
 
 	fvv := fv.FavsView()
 	fvv.ResetSelectedIdxs()
+	rvv := fv.RecentsView()
+	rvv.ResetSelectedIdxs()
 
 	sv := fv.FilesView()
 	sv.ResetSelectedIdxs()
 	sv.SelField = "Name"
 	sv.SelVal = fv.SelFile
+	fv.UpdateFilesView()
+
+	if fv.ParentWindow() == nil {
+		fv.loadPend = true // started in Render2D
+		return
+	}
+	fv.startLoad()
+}
+
+// UpdateFilesView updates the TableView of the files for the current Files
+func (fv *FileView) UpdateFilesView() {
+	sv := fv.FilesView()
 	sv.SortSlice()
 	if !sv.IsConfiged() {
 		sv.Config()
+		if !sv.IsConfiged() { // no files yet
+			fv.SelectedIdx = -1
+			return
+		}
 		sv.LayoutSliceGrid()
 	}
+	if len(fv.Files) == 0 {
+		sv.LayoutSliceGrid() // clears the previous files
+		fv.SelectedIdx = -1
+		return
+	}
 	sv.UpdateSliceGrid()
 	sv.LayoutHeader()
 	fv.SelectedIdx = sv.SelectedIdx
+}
+
+// IsLoading returns true if the files of the directory are being loaded
+func (fv *FileView) IsLoading() bool {
+	return fv.loadProg != nil
+}
+
+// StopLoad stops loading the files of the directory, keeping those loaded
+// so far
+func (fv *FileView) StopLoad() {
+	if fv.loadProg != nil {
+		fv.loadProg.Cancel()
+	}
+}
+
+// startLoad starts loading the files of loadPath in the background
+func (fv *FileView) startLoad() {
+	fv.loadPend = false
+	path, filter := fv.loadPath, fv.FilterFunc
+	fv.StopAction().SetActiveStateUpdt(true)
+	fv.loadProg = gi.RunProgress(nil, fv.ParentWindow(), gi.ProgressOpts{Title: gi.T("Loading Files"), Bar: fv.LoadBar()}, func(p *gi.Progress) error {
+		return fv.loadFiles(p, path, filter)
+	}, fv.loadFilesDone)
+}
+
+// loadFiles loads the files of given directory, running on the goroutine of
+// given Progress, and adds them to Files every FileViewLoadUpdate
+func (fv *FileView) loadFiles(p *gi.Progress, path string, filter FileViewFilterFunc) error {
+	d, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	names, err := d.Readdirnames(-1)
+	d.Close()
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+	p.SetMax(len(names))
+	var fis []*FileInfo
+	done := 0
+	lst := time.Now()
+	for i, nm := range names {
+		if p.Canceled() {
+			return p.Ctx.Err()
+		}
+		fi, ferr := NewFileInfo(filepath.Join(path, nm))
+		keep := ferr == nil
+		if filter != nil {
+			keep = filter(fv, fi)
+		}
+		if keep {
+			fis = append(fis, fi)
+		}
+		if i == len(names)-1 || time.Since(lst) >= FileViewLoadUpdate {
+			lst = time.Now()
+			p.Step(i + 1 - done)
+			done = i + 1
+			if len(fis) > 0 {
+				add := fis
+				fis = nil
+				p.Do(func() {
+					fv.addFiles(p, add)
+				})
+			}
+		}
+	}
+	return nil
+}
+
+// addFiles adds given files, loaded by given Progress, to Files and updates
+// the view -- called in the event loop
+func (fv *FileView) addFiles(p *gi.Progress, fis []*FileInfo) {
+	if fv.loadProg != p || fv.IsDestroyed() {
+		return
+	}
+	updt := fv.UpdateStart()
+	fv.SetFullReRender() // rows are added to the grid
+	fv.Files = append(fv.Files, fis...)
+	fv.UpdateFilesView()
+	fv.UpdateEnd(updt)
+}
+
+// loadFilesDone is called in the event loop when loading the files with
+// given Progress is done
+func (fv *FileView) loadFilesDone(p *gi.Progress) {
+	if fv.loadProg != p || fv.IsDestroyed() {
+		return
+	}
+	fv.loadProg = nil
+	fv.StopAction().SetActiveStateUpdt(false)
+	if p.Err != nil && !p.Canceled() {
+		log.Printf("gi.FileView Path: %v could not be opened -- error: %v\n", fv.loadPath, p.Err)
+	}
+	sv := fv.FilesView()
 	if sv.SelectedIdx >= 0 {
 		sv.ScrollToIdx(sv.SelectedIdx)
 	}
+	if done := fv.loadDone; done != nil {
+		fv.loadDone = nil
+		done()
+	}
+}
+
+// Render2D starts loading the files if that was waiting for the view to be
+// in a window
+func (fv *FileView) Render2D() {
+	if fv.loadPend && fv.ParentWindow() != nil {
+		fv.startLoad()
+	}
+	fv.Frame.Render2D()
 }
 
 // UpdateFavs updates list of files and other views for current path
@@ -607,6 +821,27 @@ func (fv *FileView) NewFolder() {
 	fv.UpdateFilesAction()
 }
 
+// DropFiles implements gi.FileDropper: a directory dropped from the OS is
+// opened, and for a file its directory is opened and it is selected
+func (fv *FileView) DropFiles(files []string, pos image.Point, mod dnd.DropMods) bool {
+	fn := files[0]
+	if fi, err := os.Stat(fn); err != nil {
+		return false
+	} else if fi.IsDir() {
+		fv.DirPath = fn
+		fv.SelFile = ""
+	} else {
+		fv.DirPath, fv.SelFile = filepath.Split(fn)
+	}
+	fv.SelectedIdx = -1
+	fv.updateFilesAction(func() {
+		if fv.SelFile != "" {
+			fv.SetSelFileAction(fv.SelFile)
+		}
+	})
+	return true
+}
+
 // SetSelFileAction sets the currently selected file to given name, and sends
 // selection action with current full file name, and updates selection in
 // table view
@@ -683,6 +918,50 @@ func (fv *FileView) FavSelect(idx int) {
 	fv.UpdateFilesAction()
 }
 
+// UpdateRecents updates the Recents shown in the sidebar from
+// gi.RecentFiles, skipping files that no longer exist
+func (fv *FileView) UpdateRecents() {
+	if len(gi.RecentFiles) == 0 {
+		gi.OpenRecentFiles()
+	}
+	fv.Recents = make(gi.FavPaths, 0, len(gi.RecentFiles))
+	for _, fn := range gi.RecentFiles {
+		fi, err := NewFileInfo(fn)
+		if err != nil {
+			continue
+		}
+		fv.Recents = append(fv.Recents, gi.FavPathItem{Ic: fi.Ic, Name: fi.Name, Path: fi.Path})
+	}
+	if sv := fv.RecentsView(); sv.IsConfiged() {
+		sv.UpdateSliceGrid()
+	}
+}
+
+// AddRecentFile adds the selected file to gi.RecentFiles, which are shown
+// in the sidebar -- called when the file is chosen in a FileViewDialog
+func (fv *FileView) AddRecentFile() {
+	if fv.SelFile == "" {
+		return
+	}
+	if len(gi.RecentFiles) == 0 {
+		gi.OpenRecentFiles()
+	}
+	gi.RecentFiles.AddPath(fv.SelectedFile(), gi.Prefs.Params.RecentFilesMax)
+	gi.SaveRecentFiles()
+}
+
+// RecentSelect selects a recent file: goes to its folder and selects it
+func (fv *FileView) RecentSelect(idx int) {
+	if idx < 0 || idx >= len(fv.Recents) {
+		return
+	}
+	fv.DirPath, fv.SelFile = filepath.Split(fv.Recents[idx].Path)
+	fv.SelectedIdx = -1
+	fv.updateFilesAction(func() {
+		fv.SetSelFileAction(fv.SelFile)
+	})
+}
+
 // SaveSortPrefs saves current sorting preferences
 func (fv *FileView) SaveSortPrefs() {
 	sv := fv.FilesView()
@@ -21,9 +21,14 @@ import (
 // box at the bottom where methods can be invoked
 type GiEditor struct {
 	gi.Frame
-	KiRoot   ki.Ki       `desc:"root of tree being edited"`
-	Changed  bool        `desc:"has the root changed via gui actions?  updated from treeview and structview for changes"`
-	Filename gi.FileName `desc:"current filename for saving / loading"`
+	KiRoot     ki.Ki            `desc:"root of tree being edited"`
+	Changed    bool             `desc:"has the root changed via gui actions?  updated from treeview and structview for changes"`
+	Filename   gi.FileName      `desc:"current filename for saving / loading"`
+	SelNode    ki.Ki            `json:"-" xml:"-" desc:"currently selected node, whose fields, style, props and events are shown"`
+	StyleSrcs  []gi.StyleSource `json:"-" xml:"-" desc:"style properties of the selected widget, with their current values and sources"`
+	EventLog   *TextBuf         `json:"-" xml:"-" desc:"log of the routing of the events of the selected node"`
+	Inspecting bool             `json:"-" xml:"-" desc:"inspecting the widgets of the window of the root: hovering over them highlights them, and clicking on one selects it -- Esc stops inspecting"`
+	inspHover  ki.Ki            // node highlighted while inspecting
 }
 
 var KiT_GiEditor = kit.Types.AddType(&GiEditor{}, GiEditorProps)
@@ -144,9 +149,29 @@ func (ge *GiEditor) TreeView() *TreeView {
 	return ge.SplitView().Child(0).Child(0).(*TreeView)
 }
 
+// TabView returns the TabView with the views of the selected node
+func (ge *GiEditor) TabView() *gi.TabView {
+	return ge.SplitView().Child(1).(*gi.TabView)
+}
+
 // StructView returns the main StructView
 func (ge *GiEditor) StructView() *StructView {
-	return ge.SplitView().Child(1).(*StructView)
+	return ge.TabView().TabByName("Fields").(*StructView)
+}
+
+// StyleView returns the TableView of the style of the selected widget
+func (ge *GiEditor) StyleView() *TableView {
+	return ge.TabView().TabByName("Style").(*TableView)
+}
+
+// PropsView returns the MapView of the props of the selected node
+func (ge *GiEditor) PropsView() *MapView {
+	return ge.TabView().TabByName("Props").(*MapView)
+}
+
+// EventsView returns the TextView of the EventLog
+func (ge *GiEditor) EventsView() *TextView {
+	return ge.TabView().TabByName("Events").Child(0).(*TextView)
 }
 
 // ToolBar returns the toolbar widget
@@ -176,16 +201,30 @@ func (ge *GiEditor) ConfigSplitView() {
 	if len(split.Kids) == 0 {
 		tvfr := gi.AddNewFrame(split, "tvfr", gi.LayoutHoriz)
 		tv := AddNewTreeView(tvfr, "tv")
-		sv := AddNewStructView(split, "sv")
+		tabs := gi.AddNewTabView(split, "tabs")
+		tabs.NoDeleteTabs = true
+		sv := tabs.AddNewTab(KiT_StructView, "Fields").(*StructView)
+		stv := tabs.AddNewTab(KiT_TableView, "Style").(*TableView)
+		stv.SetInactive()
+		pv := tabs.AddNewTab(KiT_MapView, "Props").(*MapView)
+		evly := tabs.AddNewTab(gi.KiT_Layout, "Events").(*gi.Layout)
+		evly.Lay = gi.LayoutVert
+		evly.SetStretchMax()
+		if ge.EventLog == nil {
+			ge.EventLog = NewTextBuf()
+		}
+		etv := AddNewTextView(evly, "events")
+		etv.SetInactive()
+		etv.SetBuf(ge.EventLog)
+		tabs.SelectTabIndex(0)
 		tv.TreeViewSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 			if data == nil {
 				return
 			}
 			gee, _ := recv.Embed(KiT_GiEditor).(*GiEditor)
-			svr := gee.StructView()
 			tvn, _ := data.(ki.Ki).Embed(KiT_TreeView).(*TreeView)
 			if sig == int64(TreeViewSelected) {
-				svr.SetStruct(tvn.SrcNode)
+				gee.SetSelNode(tvn.SrcNode)
 			} else if sig == int64(TreeViewChanged) {
 				gee.SetChanged()
 			}
@@ -194,12 +233,15 @@ func (ge *GiEditor) ConfigSplitView() {
 			gee, _ := recv.Embed(KiT_GiEditor).(*GiEditor)
 			gee.SetChanged()
 		})
+		pv.ViewSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			gee, _ := recv.Embed(KiT_GiEditor).(*GiEditor)
+			gee.PropsChanged()
+		})
 		split.SetSplits(.3, .7)
 	}
 	tv := ge.TreeView()
 	tv.SetRootNode(ge.KiRoot)
-	sv := ge.StructView()
-	sv.SetStruct(ge.KiRoot)
+	ge.SetSelNode(ge.KiRoot)
 }
 
 func (ge *GiEditor) SetChanged() {
@@ -245,6 +287,7 @@ var GiEditorProps = ki.Props{
 				{"File Name", ki.Props{
 					"default-field": "Filename",
 					"ext":           ".json",
+					"save":          true,
 				}},
 			},
 		}},
@@ -264,9 +307,25 @@ var GiEditorProps = ki.Props{
 				{"File Name", ki.Props{
 					"default-field": "Filename",
 					"ext":           ".json",
+					"save":          true,
 				}},
 			},
 		}},
+		{"sep-inspect", ki.BlankProp{}},
+		{"ToggleInspect", ki.Props{
+			"label": "Inspect",
+			"icon":  "search",
+			"desc":  "Inspect the widgets of the window being edited: hovering over a widget highlights it, and clicking on it selects it here -- Esc stops inspecting",
+			"updtfunc": ActionUpdateFunc(func(gei interface{}, act *gi.Action) {
+				ge := gei.(*GiEditor)
+				act.SetActiveStateUpdt(ge.TargetWindow() != nil)
+			}),
+		}},
+		{"ClearEventLog", ki.Props{
+			"label": "Clear Events",
+			"icon":  "reset",
+			"desc":  "Clear the log of the events of the selected node",
+		}},
 	},
 	"MainMenu": ki.PropSlice{
 		{"AppMenu", ki.BlankProp{}},
@@ -285,6 +344,7 @@ var GiEditorProps = ki.Props{
 					{"File Name", ki.Props{
 						"default-field": "Filename",
 						"ext":           ".json",
+						"save":          true,
 					}},
 				},
 			}},
@@ -304,6 +364,7 @@ var GiEditorProps = ki.Props{
 					{"File Name", ki.Props{
 						"default-field": "Filename",
 						"ext":           ".json",
+						"save":          true,
 					}},
 				},
 			}},
@@ -351,6 +412,11 @@ func GoGiEditorDialog(obj ki.Ki) *GiEditor {
 	tb := ge.ToolBar()
 	tb.UpdateActions()
 
+	win.SetCloseCleanFunc(func(w *gi.Window) {
+		ge.StopInspect()
+		ge.TraceEvents(nil)
+	})
+
 	inClosePrompt := false
 	win.OSWin.SetCloseReqFunc(func(w oswin.Window) {
 		if !ge.Changed {
@@ -0,0 +1,251 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/ki/ki"
+)
+
+// GiEditorInspectSpriteName is the name of the window sprite used to
+// highlight the widget under the mouse while inspecting
+var GiEditorInspectSpriteName = "giv.GiEditor.Inspect"
+
+// TargetWindow returns the window of the root of the tree being edited, or
+// nil if it is not in a window
+func (ge *GiEditor) TargetWindow() *gi.Window {
+	if ge.KiRoot == nil {
+		return nil
+	}
+	if win, ok := ge.KiRoot.(*gi.Window); ok {
+		return win
+	}
+	if _, ni := gi.KiToNode2D(ge.KiRoot); ni != nil {
+		return ni.ParentWindow()
+	}
+	return nil
+}
+
+// SetSelNode sets the selected node, whose fields, style, props and events
+// are shown
+func (ge *GiEditor) SetSelNode(k ki.Ki) {
+	ge.SelNode = k
+	ge.StructView().SetStruct(k)
+	pp := k.Properties()
+	if *pp == nil {
+		*pp = make(ki.Props)
+	}
+	ge.PropsView().SetMap(pp)
+	ge.UpdateStyleView()
+	ge.TraceEvents(k)
+}
+
+// UpdateStyleView updates the view of the style properties of the selected
+// widget, with their current values and sources
+func (ge *GiEditor) UpdateStyleView() {
+	ge.StyleSrcs = nil
+	if ni, _ := gi.KiToNode2D(ge.SelNode); ni != nil {
+		ge.StyleSrcs = gi.WidgetStyleSources(ni)
+	}
+	ge.StyleView().SetSlice(&ge.StyleSrcs)
+}
+
+// PropsChanged is called when the props of the selected node have been
+// edited, and re-styles and re-renders its window so the changes are shown
+// immediately
+func (ge *GiEditor) PropsChanged() {
+	ge.SetChanged()
+	_, ni := gi.KiToNode2D(ge.SelNode)
+	if ni == nil {
+		return
+	}
+	if win := ni.ParentWindow(); win != nil {
+		win.FullReRender()
+	}
+	ge.UpdateStyleView()
+}
+
+// SelectNode selects given node in the TreeView, opening its parents,
+// which also makes it the selected node
+func (ge *GiEditor) SelectNode(k ki.Ki) {
+	tvn := ge.TreeView().FindSrcNode(k)
+	if tvn == nil {
+		return
+	}
+	tvn.OpenParents()
+	tvn.SelectAction(mouse.SelectOne)
+	tvn.ScrollToMe()
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//    Inspect
+
+// ToggleInspect starts or stops inspecting the widgets of the window being
+// edited
+func (ge *GiEditor) ToggleInspect() {
+	if ge.Inspecting {
+		ge.StopInspect()
+	} else {
+		ge.StartInspect()
+	}
+}
+
+// StartInspect starts inspecting the widgets of the window being edited:
+// hovering over a widget highlights it, and clicking on it selects it in
+// the editor, and stops inspecting, as does Esc
+func (ge *GiEditor) StartInspect() {
+	win := ge.TargetWindow()
+	if win == nil {
+		return
+	}
+	ge.Inspecting = true
+	ge.inspHover = nil
+	win.Inspect = ge.InspectEvent
+}
+
+// StopInspect stops inspecting the widgets of the window being edited
+func (ge *GiEditor) StopInspect() {
+	if !ge.Inspecting {
+		return
+	}
+	ge.Inspecting = false
+	ge.InspectHighlight(nil)
+	if win := ge.TargetWindow(); win != nil {
+		win.Inspect = nil
+	}
+}
+
+// InspectEvent is the gi.InspectFunc of the window being edited while
+// inspecting: mouse events are used for selecting widgets, and not sent to
+// them, except for scrolling
+func (ge *GiEditor) InspectEvent(evi oswin.Event) bool {
+	root, ok := ge.KiRoot.Embed(gi.KiT_NodeBase).(*gi.NodeBase)
+	if !ok {
+		return false
+	}
+	switch e := evi.(type) {
+	case *mouse.MoveEvent:
+		ge.InspectHighlight(inspectNodeAt(root, e.Pos()))
+		return true
+	case *mouse.DragEvent, *mouse.HoverEvent:
+		return true
+	case *mouse.Event:
+		if e.Button == mouse.Left {
+			switch e.Action {
+			case mouse.Press:
+				if k := inspectNodeAt(root, e.Pos()); k != nil {
+					ge.SelectNode(k)
+				}
+			case mouse.Release:
+				ge.StopInspect()
+			}
+		}
+		return true
+	case *key.ChordEvent:
+		if gi.KeyFun(e.Chord()) == gi.KeyFunAbort {
+			ge.StopInspect()
+			return true
+		}
+	}
+	return false
+}
+
+// inspectNodeAt returns the widget under given position in the tree of
+// given root node -- for the parts of a widget, it is the widget itself
+func inspectNodeAt(root *gi.NodeBase, pos image.Point) ki.Ki {
+	k := root.DeepestContainingPoint(pos)
+	for p := k; p != nil && p != root.This(); p = p.Parent() {
+		if p.IsField() {
+			k = p.Parent()
+		}
+	}
+	return k
+}
+
+// InspectHighlight highlights given node in the window being edited, with
+// a sprite over its bounding box -- nil removes the highlight
+func (ge *GiEditor) InspectHighlight(k ki.Ki) {
+	if k == ge.inspHover {
+		return
+	}
+	ge.inspHover = k
+	win := ge.TargetWindow()
+	if win == nil {
+		return
+	}
+	win.DeleteSprite(GiEditorInspectSpriteName)
+	if _, ni := gi.KiToNode2D(k); ni != nil {
+		ni.BBoxMu.RLock()
+		bb := ni.WinBBox
+		ni.BBoxMu.RUnlock()
+		if bb.Dx() > 0 && bb.Dy() > 0 {
+			sp := win.AddNewSprite(GiEditorInspectSpriteName, bb.Size(), bb.Min)
+			sc := gi.Prefs.Colors.Select
+			bc := color.RGBA{sc.R, sc.G, sc.B, 0xff}
+			fc := color.RGBA{sc.R / 4, sc.G / 4, sc.B / 4, 0x40} // premultiplied
+			draw.Draw(sp.Pixels, sp.Pixels.Bounds(), &image.Uniform{bc}, image.ZP, draw.Src)
+			draw.Draw(sp.Pixels, sp.Pixels.Bounds().Inset(2), &image.Uniform{fc}, image.ZP, draw.Src)
+			win.ActivateSprite(GiEditorInspectSpriteName)
+		}
+	}
+	win.RenderOverlays()
+	win.UpdateSig()
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//    Events
+
+// TraceEvents logs the routing of the events of given node in the EventLog
+// -- nil stops logging
+func (ge *GiEditor) TraceEvents(k ki.Ki) {
+	win := ge.TargetWindow()
+	if win == nil {
+		return
+	}
+	em := &win.EventMgr
+	em.EventMu.Lock()
+	if k == nil {
+		em.TraceNode = nil
+		em.TraceFunc = nil
+	} else {
+		em.TraceNode = k
+		em.TraceFunc = ge.LogEvent
+	}
+	em.EventMu.Unlock()
+}
+
+// LogEvent is the gi.EventTraceFunc that adds an event of the selected
+// node to the EventLog
+func (ge *GiEditor) LogEvent(evi oswin.Event, pri gi.EventPris, by ki.Ki) {
+	if ge.EventLog == nil {
+		return
+	}
+	res := "not processed"
+	switch {
+	case by == nil:
+	case by == ge.SelNode:
+		res = "processed"
+	default:
+		res = "taken by " + by.Path()
+	}
+	ln := fmt.Sprintf("%v  %v  %v", pri, res, evi)
+	ge.EventLog.AppendTextLine([]byte(ln), EditSignal)
+	ge.EventLog.AutoScrollViews()
+}
+
+// ClearEventLog clears the log of the events of the selected node
+func (ge *GiEditor) ClearEventLog() {
+	if ge.EventLog != nil {
+		ge.EventLog.SetText(nil)
+	}
+}
@@ -0,0 +1,846 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"image"
+	"image/color"
+	"log"
+	"reflect"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/girl"
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+	"github.com/srwiley/rasterx"
+)
+
+/////////////////////////////////////////////////////////////////////////////
+//  GradientView
+
+// GradientView is an editor for a gist.ColorSpec, as used for the fill and
+// stroke of SVG elements and for backgrounds in styles, which can be a solid
+// color, or a linear or radial gradient.  The stops of a gradient are shown
+// as handles on a GradientBar preview of it, which are clicked to select a
+// stop and dragged to set its position, and double-clicking the bar adds a
+// stop -- the color and alpha of the selected stop, or of the solid color,
+// are set in a ColorPicker.  It is used by the GradientViewDialog, e.g., for
+// ColorSpecValueView values in a StructView.
+type GradientView struct {
+	gi.Frame
+	Spec     gist.ColorSpec    `desc:"the color spec that we edit"`
+	Stop     int               `desc:"index of the selected stop of the gradient"`
+	TmpSave  ValueView         `json:"-" xml:"-" desc:"value view that needs to have SaveTmp called on it whenever a change is made to one of the underlying values -- pass this down to any sub-views created from a parent"`
+	ViewSig  ki.Signal         `json:"-" xml:"-" desc:"signal for valueview -- only one signal sent when a value has been set -- all related value views interconnect with each other to update when others update"`
+	ViewPath string            `desc:"a record of parent View names that have led up to this view -- displayed as extra contextual information in view dialog windows"`
+	lastGrad *rasterx.Gradient // gradient kept when switching to a solid color, to switch back to
+}
+
+var KiT_GradientView = kit.Types.AddType(&GradientView{}, GradientViewProps)
+
+// AddNewGradientView adds a new gradient view to given parent node, with given name.
+func AddNewGradientView(parent ki.Ki, name string) *GradientView {
+	return parent.AddNewChild(KiT_GradientView, name).(*GradientView)
+}
+
+func (gv *GradientView) Disconnect() {
+	gv.Frame.Disconnect()
+	gv.ViewSig.DisconnectAll()
+}
+
+var GradientViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+}
+
+// SetColorSpec sets the color spec to edit, which is copied
+func (gv *GradientView) SetColorSpec(cs *gist.ColorSpec) {
+	gv.Spec.CopyFrom(cs)
+	if gv.Spec.Source != gist.SolidColor && gv.Spec.Gradient == nil {
+		gv.Spec.Source = gist.SolidColor
+	}
+	if gv.Spec.Gradient != nil {
+		SortGradientStops(gv.Spec.Gradient, -1)
+	}
+	gv.Stop = 0
+	gv.Config()
+	gv.Update()
+}
+
+// IsGradient returns true if the spec is a gradient, not a solid color
+func (gv *GradientView) IsGradient() bool {
+	return gv.Spec.Source != gist.SolidColor && gv.Spec.Gradient != nil
+}
+
+// SetSourceAction sets the source of the color spec, switching between a
+// solid color and linear and radial gradients, updating the view and
+// emitting ViewSig -- a new gradient starts with two stops, from the solid
+// color to white
+func (gv *GradientView) SetSourceAction(src gist.ColorSources) {
+	if src == gv.Spec.Source {
+		return
+	}
+	g := gv.Spec.Gradient
+	switch {
+	case src == gist.SolidColor:
+		if g != nil && len(g.Stops) > 0 {
+			gv.Spec.Color = GradientStopColor(&g.Stops[0])
+			gv.lastGrad = g
+		}
+		gv.Spec.Gradient = nil
+	case g == nil:
+		g = gv.lastGrad
+		if g == nil {
+			g = &rasterx.Gradient{Matrix: rasterx.Identity, Spread: rasterx.PadSpread}
+			var c0, c1 gist.Color
+			c0 = gv.Spec.Color
+			if c0.IsNil() {
+				c0.SetColor(color.Black)
+			}
+			c1.SetColor(color.White)
+			g.Stops = []rasterx.GradStop{{StopColor: c0, Offset: 0, Opacity: 1}, {StopColor: c1, Offset: 1, Opacity: 1}}
+		}
+		gv.Spec.Gradient = g
+		gv.Stop = 0
+	}
+	if g = gv.Spec.Gradient; g != nil {
+		radial := src == gist.RadialGradient
+		if radial != g.IsRadial || g.Points == [5]float64{} {
+			if radial {
+				g.Points = [5]float64{0.5, 0.5, 0.5, 0.5, 0.5}
+			} else {
+				g.Points = [5]float64{0, 0, 1, 0, 0}
+			}
+		}
+		g.IsRadial = radial
+	}
+	gv.Spec.Source = src
+	gv.Config()
+	gv.changed()
+}
+
+// SelectStop selects the stop of given index, for editing its color and
+// position
+func (gv *GradientView) SelectStop(idx int) {
+	if !gv.IsGradient() || idx < 0 || idx >= len(gv.Spec.Gradient.Stops) {
+		return
+	}
+	gv.Stop = idx
+	gv.Update()
+}
+
+// SetColorAction sets the solid color, or the color and opacity of the
+// selected stop of a gradient, from the alpha of the color, updating the
+// view and emitting ViewSig
+func (gv *GradientView) SetColorAction(clr gist.Color) {
+	if !gv.IsGradient() {
+		gv.Spec.Color = clr
+		gv.changed()
+		return
+	}
+	if gv.Stop >= len(gv.Spec.Gradient.Stops) {
+		return
+	}
+	st := &gv.Spec.Gradient.Stops[gv.Stop]
+	r, g, b, a := clr.ToNPFloat32()
+	var sc gist.Color
+	sc.SetNPFloat32(r, g, b, 1)
+	st.StopColor = sc
+	st.Opacity = float64(a)
+	if gv.Stop == 0 {
+		gv.Spec.Color = sc // keep first one, as in parsing
+	}
+	gv.changed()
+}
+
+// SetStopOffsetAction sets the position of the selected stop, in [0..1],
+// updating the view and emitting ViewSig -- the stops are kept in order of
+// their positions
+func (gv *GradientView) SetStopOffsetAction(off float64) {
+	if !gv.IsGradient() || gv.Stop >= len(gv.Spec.Gradient.Stops) {
+		return
+	}
+	gv.Spec.Gradient.Stops[gv.Stop].Offset = float64(mat32.Clamp(float32(off), 0, 1))
+	gv.Stop = SortGradientStops(gv.Spec.Gradient, gv.Stop)
+	gv.changed()
+}
+
+// AddStopAction adds a stop at given position, in [0..1], with the color of
+// the gradient at that position, and selects it, updating the view and
+// emitting ViewSig
+func (gv *GradientView) AddStopAction(off float64) {
+	if !gv.IsGradient() {
+		return
+	}
+	g := gv.Spec.Gradient
+	off = float64(mat32.Clamp(float32(off), 0, 1))
+	clr, op := GradientColorAt(g, off)
+	g.Stops = append(g.Stops, rasterx.GradStop{StopColor: clr, Offset: off, Opacity: op})
+	gv.Stop = SortGradientStops(g, len(g.Stops)-1)
+	gv.changed()
+}
+
+// DeleteStopAction deletes the selected stop, updating the view and
+// emitting ViewSig -- a gradient always keeps at least two stops
+func (gv *GradientView) DeleteStopAction() {
+	if !gv.IsGradient() {
+		return
+	}
+	g := gv.Spec.Gradient
+	if len(g.Stops) <= 2 || gv.Stop >= len(g.Stops) {
+		return
+	}
+	g.Stops = append(g.Stops[:gv.Stop], g.Stops[gv.Stop+1:]...)
+	if gv.Stop >= len(g.Stops) {
+		gv.Stop = len(g.Stops) - 1
+	}
+	gv.changed()
+}
+
+// changed saves and signals a change of the spec by the user, and updates
+// the view
+func (gv *GradientView) changed() {
+	if gv.TmpSave != nil {
+		gv.TmpSave.SaveTmp()
+	}
+	gv.Update()
+	gv.ViewSig.Emit(gv.This(), 0, nil)
+}
+
+// Config configures a standard setup of entire view
+func (gv *GradientView) Config() {
+	gv.Lay = gi.LayoutVert
+	gv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_Layout, "type-lay")
+	if gv.IsGradient() {
+		config.Add(KiT_GradientBar, "bar")
+		config.Add(gi.KiT_Layout, "stop-lay")
+	}
+	config.Add(KiT_ColorPicker, "color-picker")
+	mods, updt := gv.ConfigChildren(config, ki.UniqueNames)
+	if mods {
+		gv.ConfigTypeLay()
+		if gv.IsGradient() {
+			gv.ConfigBar()
+			gv.ConfigStopLay()
+		}
+		gv.ConfigColorPicker()
+	} else {
+		updt = gv.UpdateStart()
+	}
+	gv.UpdateEnd(updt)
+}
+
+// IsConfiged returns true if widget is fully configured
+func (gv *GradientView) IsConfiged() bool {
+	return len(gv.Kids) > 0
+}
+
+// TypeField returns the combobox for choosing the source of the spec
+func (gv *GradientView) TypeField() *gi.ComboBox {
+	return gv.ChildByName("type-lay", 0).ChildByName("type", 1).(*gi.ComboBox)
+}
+
+// Bar returns the GradientBar -- nil for a solid color
+func (gv *GradientView) Bar() *GradientBar {
+	gb, _ := gv.ChildByName("bar", 1).(*GradientBar)
+	return gb
+}
+
+// StopLay returns the layout of the fields of the selected stop -- nil for
+// a solid color
+func (gv *GradientView) StopLay() *gi.Layout {
+	sl, _ := gv.ChildByName("stop-lay", 2).(*gi.Layout)
+	return sl
+}
+
+// ColorPicker returns the ColorPicker for the solid color, or the color of
+// the selected stop
+func (gv *GradientView) ColorPicker() *ColorPicker {
+	return gv.ChildByName("color-picker", 3).(*ColorPicker)
+}
+
+// ConfigTypeLay configures the combobox for choosing the source of the spec
+func (gv *GradientView) ConfigTypeLay() {
+	tl := gv.ChildByName("type-lay", 0).(*gi.Layout)
+	tl.Lay = gi.LayoutHoriz
+	tl.SetProp("spacing", units.NewPx(4))
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_Label, "type-lbl")
+	config.Add(gi.KiT_ComboBox, "type")
+	tl.ConfigChildren(config, ki.UniqueNames)
+	tl.ChildByName("type-lbl", 0).(*gi.Label).SetText(gi.T("Type:"))
+	cb := gv.TypeField()
+	cb.Tooltip = "a solid color, or a linear or radial gradient between the colors of its stops"
+	cb.ItemsFromStringList([]string{gi.T("Solid Color"), gi.T("Linear Gradient"), gi.T("Radial Gradient")}, false, 0)
+	cb.ComboSig.ConnectOnly(gv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		gvv, _ := recv.Embed(KiT_GradientView).(*GradientView)
+		cbb := send.(*gi.ComboBox)
+		gvv.SetSourceAction(gist.ColorSources(cbb.CurIndex))
+	})
+}
+
+// ConfigBar configures the GradientBar
+func (gv *GradientView) ConfigBar() {
+	gb := gv.Bar()
+	gb.Tooltip = "click on a stop to select it, and drag it to move it -- double-click to add a stop"
+	gb.BarSig.ConnectOnly(gv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		gvv, _ := recv.Embed(KiT_GradientView).(*GradientView)
+		gbb := send.(*GradientBar)
+		switch GradientBarSignals(sig) {
+		case GradientBarSelected:
+			gvv.SelectStop(data.(int))
+		case GradientBarMoved:
+			gvv.Stop = gbb.Sel
+			gvv.SetStopOffsetAction(data.(float64))
+		case GradientBarAdded:
+			gvv.AddStopAction(data.(float64))
+		}
+	})
+}
+
+// ConfigStopLay configures the fields of the selected stop
+func (gv *GradientView) ConfigStopLay() {
+	sl := gv.StopLay()
+	sl.Lay = gi.LayoutHoriz
+	sl.SetProp("spacing", units.NewPx(4))
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_Label, "offset-lbl")
+	config.Add(gi.KiT_SpinBox, "offset")
+	config.Add(gi.KiT_Action, "add")
+	config.Add(gi.KiT_Action, "del")
+	sl.ConfigChildren(config, ki.UniqueNames)
+	sl.ChildByName("offset-lbl", 0).(*gi.Label).SetText(gi.T("Position:"))
+	sb := sl.ChildByName("offset", 1).(*gi.SpinBox)
+	sb.Defaults()
+	sb.SetMin(0)
+	sb.SetMax(100)
+	sb.Step = 1
+	sb.PageStep = 10
+	sb.Format = "%g"
+	sb.Tooltip = "position of the selected stop along the gradient, in percent [0..100]"
+	sb.SpinBoxSig.ConnectOnly(gv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		gvv, _ := recv.Embed(KiT_GradientView).(*GradientView)
+		sbb := send.(*gi.SpinBox)
+		gvv.SetStopOffsetAction(float64(sbb.Value) / 100)
+	})
+	add := sl.ChildByName("add", 2).(*gi.Action)
+	add.SetIcon("plus")
+	add.SetText(gi.T("Add Stop"))
+	add.Tooltip = "add a stop half way between the selected stop and the next one"
+	add.ActionSig.ConnectOnly(gv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		gvv, _ := recv.Embed(KiT_GradientView).(*GradientView)
+		stops := gvv.Spec.Gradient.Stops
+		off := stops[gvv.Stop].Offset
+		if gvv.Stop+1 < len(stops) {
+			off = 0.5 * (off + stops[gvv.Stop+1].Offset)
+		} else if gvv.Stop > 0 {
+			off = 0.5 * (off + stops[gvv.Stop-1].Offset)
+		}
+		gvv.AddStopAction(off)
+	})
+	del := sl.ChildByName("del", 3).(*gi.Action)
+	del.SetIcon("minus")
+	del.SetText(gi.T("Delete Stop"))
+	del.Tooltip = "delete the selected stop -- a gradient has at least two stops"
+	del.ActionSig.ConnectOnly(gv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		gvv, _ := recv.Embed(KiT_GradientView).(*GradientView)
+		gvv.DeleteStopAction()
+	})
+}
+
+// ConfigColorPicker configures the ColorPicker
+func (gv *GradientView) ConfigColorPicker() {
+	cp := gv.ColorPicker()
+	cp.ViewPath = gv.ViewPath
+	cp.ViewSig.ConnectOnly(gv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		gvv, _ := recv.Embed(KiT_GradientView).(*GradientView)
+		cpp := send.(*ColorPicker)
+		gvv.SetColorAction(cpp.Color)
+	})
+}
+
+// CurColor returns the solid color, or the color of the selected stop of a
+// gradient, with its opacity as the alpha
+func (gv *GradientView) CurColor() gist.Color {
+	if !gv.IsGradient() || gv.Stop >= len(gv.Spec.Gradient.Stops) {
+		return gv.Spec.Color
+	}
+	return GradientStopColor(&gv.Spec.Gradient.Stops[gv.Stop])
+}
+
+// Update updates all the widgets of the view from the spec
+func (gv *GradientView) Update() {
+	if !gv.IsConfiged() {
+		return
+	}
+	updt := gv.UpdateStart()
+	gv.TypeField().SetCurIndex(int(gv.Spec.Source))
+	if gv.IsGradient() {
+		if gv.Stop >= len(gv.Spec.Gradient.Stops) {
+			gv.Stop = 0
+		}
+		gb := gv.Bar()
+		gb.SetGradient(gv.Spec.Gradient, gv.Stop)
+		sl := gv.StopLay()
+		sl.ChildByName("offset", 1).(*gi.SpinBox).SetValue(mat32.Round(float32(gv.Spec.Gradient.Stops[gv.Stop].Offset * 100)))
+		del := sl.ChildByName("del", 3).(*gi.Action)
+		del.SetInactiveState(len(gv.Spec.Gradient.Stops) <= 2)
+	}
+	cp := gv.ColorPicker()
+	if clr := gv.CurColor(); clr != cp.Color {
+		cp.SetColor(clr)
+	}
+	gv.UpdateEnd(updt)
+}
+
+// GradientStopColor returns the color of given gradient stop, with its
+// opacity applied to its alpha
+func GradientStopColor(st *rasterx.GradStop) gist.Color {
+	var clr gist.Color
+	if st.StopColor == nil {
+		return clr
+	}
+	clr.SetColor(st.StopColor)
+	r, g, b, a := clr.ToNPFloat32()
+	clr.SetNPFloat32(r, g, b, a*float32(st.Opacity))
+	return clr
+}
+
+// GradientColorAt returns the color and opacity of given gradient at given
+// position, in [0..1], interpolated between its stops, which must be in
+// order of their positions
+func GradientColorAt(g *rasterx.Gradient, off float64) (gist.Color, float64) {
+	var clr gist.Color
+	n := len(g.Stops)
+	if n == 0 {
+		return clr, 1
+	}
+	i := 0
+	for i < n && g.Stops[i].Offset < off {
+		i++
+	}
+	if i == 0 || i == n {
+		if i == n {
+			i = n - 1
+		}
+		if g.Stops[i].StopColor != nil {
+			clr.SetColor(g.Stops[i].StopColor)
+		}
+		return clr, g.Stops[i].Opacity
+	}
+	s0, s1 := &g.Stops[i-1], &g.Stops[i]
+	t := float32(0)
+	if s1.Offset > s0.Offset {
+		t = float32((off - s0.Offset) / (s1.Offset - s0.Offset))
+	}
+	var c0, c1 gist.Color
+	if s0.StopColor != nil {
+		c0.SetColor(s0.StopColor)
+	}
+	if s1.StopColor != nil {
+		c1.SetColor(s1.StopColor)
+	}
+	r0, g0, b0, a0 := c0.ToNPFloat32()
+	r1, g1, b1, a1 := c1.ToNPFloat32()
+	clr.SetNPFloat32(r0+t*(r1-r0), g0+t*(g1-g0), b0+t*(b1-b0), a0+t*(a1-a0))
+	return clr, s0.Opacity + float64(t)*(s1.Opacity-s0.Opacity)
+}
+
+// SortGradientStops puts the stops of given gradient in order of their
+// positions, and returns the new index of the stop at given index, e.g.,
+// of the selected stop, or -1 if it is -1
+func SortGradientStops(g *rasterx.Gradient, idx int) int {
+	stops := g.Stops
+	for i := 1; i < len(stops); i++ { // insertion sort: stable, and keeps track of idx
+		for j := i; j > 0 && stops[j].Offset < stops[j-1].Offset; j-- {
+			stops[j], stops[j-1] = stops[j-1], stops[j]
+			switch idx {
+			case j:
+				idx = j - 1
+			case j - 1:
+				idx = j
+			}
+		}
+	}
+	return idx
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//  GradientBar
+
+// GradientBar shows a preview of a gradient, from left to right, with the
+// stops of the gradient as handles below it, for the GradientView: clicking
+// on a handle selects its stop, dragging it moves the stop, and
+// double-clicking on the bar adds a stop -- the gradient is not changed by
+// the bar itself, only through the GradientView that gets its BarSig
+type GradientBar struct {
+	gi.WidgetBase
+	Grad     *rasterx.Gradient `json:"-" xml:"-" desc:"the gradient shown, which is owned by the GradientView"`
+	Sel      int               `desc:"index of the selected stop"`
+	BarSig   ki.Signal         `copy:"-" json:"-" xml:"-" view:"-" desc:"signal for the bar -- see GradientBarSignals for the types"`
+	dragging bool              // a stop is being dragged
+}
+
+var KiT_GradientBar = kit.Types.AddType(&GradientBar{}, GradientBarProps)
+
+// AddNewGradientBar adds a new gradient bar to given parent node, with given name.
+func AddNewGradientBar(parent ki.Ki, name string) *GradientBar {
+	return parent.AddNewChild(KiT_GradientBar, name).(*GradientBar)
+}
+
+func (gb *GradientBar) CopyFieldsFrom(frm interface{}) {
+	fr := frm.(*GradientBar)
+	gb.WidgetBase.CopyFieldsFrom(&fr.WidgetBase)
+	gb.Grad = fr.Grad
+	gb.Sel = fr.Sel
+}
+
+func (gb *GradientBar) Disconnect() {
+	gb.WidgetBase.Disconnect()
+	gb.BarSig.DisconnectAll()
+}
+
+var GradientBarProps = ki.Props{
+	"EnumType:Flag": gi.KiT_NodeFlags,
+	"border-width":  units.NewPx(1),
+	"border-color":  &gi.Prefs.Colors.Border,
+	"padding":       units.NewPx(0),
+	"margin":        units.NewPx(2),
+	"min-width":     units.NewEm(20),
+	"min-height":    units.NewEm(3),
+}
+
+// GradientBarSignals are signals that the GradientBar sends to the
+// GradientView, for the actions of the user
+type GradientBarSignals int64
+
+const (
+	// GradientBarSelected is emitted when a stop is clicked -- data is the
+	// index of the stop
+	GradientBarSelected GradientBarSignals = iota
+
+	// GradientBarMoved is emitted when the selected stop is dragged -- data
+	// is its new position, in [0..1]
+	GradientBarMoved
+
+	// GradientBarAdded is emitted when the bar is double-clicked to add a
+	// stop -- data is its position, in [0..1]
+	GradientBarAdded
+
+	GradientBarSignalsN
+)
+
+//go:generate stringer -type=GradientBarSignals
+
+// SetGradient sets the gradient shown, and the index of the selected stop
+func (gb *GradientBar) SetGradient(g *rasterx.Gradient, sel int) {
+	updt := gb.UpdateStart()
+	gb.Grad = g
+	gb.Sel = sel
+	gb.UpdateEnd(updt)
+}
+
+// BarBox returns the position and size of the preview of the gradient, and
+// the height of the handles of the stops below it
+func (gb *GradientBar) BarBox() (pos, sz mat32.Vec2, hh float32) {
+	st := &gb.Sty
+	spc := st.Layout.Margin.Dots + st.Border.Width.Dots
+	pos = gb.LayState.Alloc.Pos.AddScalar(spc)
+	sz = gb.LayState.Alloc.Size.AddScalar(-2 * spc)
+	hh = mat32.Max(8, 0.8*st.Font.Size.Dots)
+	sz.Y -= hh
+	return
+}
+
+// PointOffset returns the position along the gradient, in [0..1], of given
+// point in window coordinates, and whether it is on the handles of the
+// stops, below the bar
+func (gb *GradientBar) PointOffset(pt image.Point) (float64, bool) {
+	gb.BBoxMu.RLock()
+	rp := pt.Sub(gb.WinBBox.Min).Add(gb.VpBBox.Min) // in viewport coords
+	gb.BBoxMu.RUnlock()
+	pos, sz, _ := gb.BarBox()
+	if sz.X <= 1 {
+		return 0, false
+	}
+	off := mat32.Clamp((float32(rp.X)-pos.X)/(sz.X-1), 0, 1)
+	return float64(off), float32(rp.Y) >= pos.Y+sz.Y
+}
+
+// StopAt returns the index of the stop whose handle is at given point in
+// window coordinates, or -1 if none
+func (gb *GradientBar) StopAt(pt image.Point) int {
+	if gb.Grad == nil {
+		return -1
+	}
+	off, _ := gb.PointOffset(pt)
+	_, sz, hh := gb.BarBox()
+	tol := float64(0.5*hh+2) / float64(sz.X)
+	best := -1
+	bestd := tol
+	for i := range gb.Grad.Stops {
+		d := off - gb.Grad.Stops[i].Offset
+		if d < 0 {
+			d = -d
+		}
+		if d <= bestd {
+			best = i
+			bestd = d
+		}
+	}
+	return best
+}
+
+// RenderBar renders the preview of the gradient, over a checkerboard to
+// show its transparency, and the handles of its stops
+func (gb *GradientBar) RenderBar() {
+	rs, pc, st := gb.RenderLock()
+	defer gb.RenderUnlock(rs)
+
+	pos, sz, hh := gb.BarBox()
+	if sz.X <= 1 || sz.Y <= 1 {
+		return
+	}
+	csz := mat32.Max(4, 0.5*sz.Y)
+	for y, row := pos.Y, 0; y < pos.Y+sz.Y; y, row = y+csz, row+1 {
+		for x, col := pos.X, 0; x < pos.X+sz.X; x, col = x+csz, col+1 {
+			clr := color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+			if (row+col)%2 == 1 {
+				clr = color.RGBA{0xCC, 0xCC, 0xCC, 0xFF}
+			}
+			csx := mat32.Min(csz, pos.X+sz.X-x)
+			csy := mat32.Min(csz, pos.Y+sz.Y-y)
+			pc.FillBoxColor(rs, mat32.Vec2{x, y}, mat32.Vec2{csx, csy}, clr)
+		}
+	}
+
+	if gb.Grad != nil && len(gb.Grad.Stops) > 0 {
+		prev := gist.ColorSpec{Source: gist.LinearGradient, Gradient: &rasterx.Gradient{}}
+		gist.CopyGradient(prev.Gradient, gb.Grad)
+		prev.Gradient.Points = [5]float64{0, 0, 1, 0, 0} // always left to right
+		prev.Gradient.IsRadial = false
+		prev.Gradient.Units = rasterx.ObjectBoundingBox
+		prev.Gradient.Matrix = rasterx.Identity
+		prev.Gradient.Spread = rasterx.PadSpread
+		pc.FillStyle.SetColorSpec(&prev)
+		pc.StrokeStyle.SetColor(nil)
+		pc.DrawRectangle(rs, pos.X, pos.Y, sz.X, sz.Y)
+		pc.Fill(rs)
+	}
+
+	pc.FillStyle.SetColor(nil)
+	pc.StrokeStyle.SetColor(&st.Border.Color)
+	pc.StrokeStyle.Width = st.Border.Width
+	bw := 0.5 * st.Border.Width.Dots
+	pc.DrawRectangle(rs, pos.X-bw, pos.Y-bw, sz.X+2*bw, sz.Y+2*bw)
+	pc.Stroke(rs)
+
+	if gb.Grad == nil {
+		return
+	}
+	hw := 0.5 * hh
+	by := pos.Y + sz.Y
+	for i := range gb.Grad.Stops {
+		if i == gb.Sel {
+			continue
+		}
+		gb.renderHandle(rs, pc, &gb.Grad.Stops[i], pos.X+sz.X*float32(gb.Grad.Stops[i].Offset), by, hw, hh, false)
+	}
+	if gb.Sel >= 0 && gb.Sel < len(gb.Grad.Stops) { // selected on top
+		gb.renderHandle(rs, pc, &gb.Grad.Stops[gb.Sel], pos.X+sz.X*float32(gb.Grad.Stops[gb.Sel].Offset), by, hw, hh, true)
+	}
+}
+
+// renderHandle renders the handle of given stop at given x position,
+// pointing up at the bottom of the bar, at given y position
+func (gb *GradientBar) renderHandle(rs *girl.State, pc *girl.Paint, st *rasterx.GradStop, x, y, hw, hh float32, sel bool) {
+	clr := GradientStopColor(st)
+	r, g, b, _ := clr.ToNPFloat32()
+	clr.SetNPFloat32(r, g, b, 1) // opaque, to be seen
+	pc.FillStyle.SetColor(clr)
+	pc.StrokeStyle.SetColor(color.Black)
+	pc.StrokeStyle.Width.Dots = 1
+	if sel {
+		pc.StrokeStyle.SetColor(&gi.Prefs.Colors.Select)
+		pc.StrokeStyle.Width.Dots = 3
+	}
+	pts := []mat32.Vec2{{x, y}, {x + hw, y + hw}, {x + hw, y + hh - 1}, {x - hw, y + hh - 1}, {x - hw, y + hw}}
+	pc.DrawPolygon(rs, pts)
+	pc.FillStrokeClear(rs)
+}
+
+func (gb *GradientBar) Init2D() {
+	gb.Init2DWidget()
+	gb.SetFlag(int(gi.InstaDrag))
+}
+
+func (gb *GradientBar) ConnectEvents2D() {
+	gb.ConnectEvent(oswin.MouseEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.Event)
+		gbb := recv.Embed(KiT_GradientBar).(*GradientBar)
+		if gbb.IsInactive() || me.Button != mouse.Left {
+			return
+		}
+		switch me.Action {
+		case mouse.Press:
+			me.SetProcessed()
+			if si := gbb.StopAt(me.Where); si >= 0 {
+				gbb.dragging = true
+				gbb.BarSig.Emit(gbb.This(), int64(GradientBarSelected), si)
+			}
+		case mouse.DoubleClick:
+			me.SetProcessed()
+			if off, onStops := gbb.PointOffset(me.Where); !onStops && gbb.StopAt(me.Where) < 0 {
+				gbb.BarSig.Emit(gbb.This(), int64(GradientBarAdded), off)
+			}
+		case mouse.Release:
+			gbb.dragging = false
+		}
+	})
+	gb.ConnectEvent(oswin.MouseDragEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.DragEvent)
+		gbb := recv.Embed(KiT_GradientBar).(*GradientBar)
+		if gbb.IsInactive() || !gbb.dragging {
+			return
+		}
+		me.SetProcessed()
+		off, _ := gbb.PointOffset(me.Where)
+		gbb.BarSig.Emit(gbb.This(), int64(GradientBarMoved), off)
+	})
+	gb.HoverTooltipEvent()
+}
+
+func (gb *GradientBar) Render2D() {
+	if gb.FullReRenderIfNeeded() {
+		return
+	}
+	if gb.PushBounds() {
+		gb.This().(gi.Node2D).ConnectEvents2D()
+		gb.RenderBar()
+		gb.Render2DChildren()
+		gb.PopBounds()
+	} else {
+		gb.DisconnectAllEvents(gi.RegPri)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  ColorSpecValueView
+
+// ColorSpecValueView presents an action showing a gist.ColorSpec, e.g., the
+// fill or stroke of an SVG element, which opens a GradientViewDialog to edit
+// it as a solid color or a gradient
+type ColorSpecValueView struct {
+	ValueViewBase
+	TmpSpec gist.ColorSpec
+}
+
+var KiT_ColorSpecValueView = kit.Types.AddType(&ColorSpecValueView{}, nil)
+
+// ColorSpec returns the color spec represented by the value
+func (vv *ColorSpecValueView) ColorSpec() (*gist.ColorSpec, bool) {
+	csi := vv.Value.Interface()
+	switch c := csi.(type) {
+	case gist.ColorSpec:
+		vv.TmpSpec = c
+		return &vv.TmpSpec, true
+	case *gist.ColorSpec:
+		if c != nil {
+			return c, true
+		}
+	case **gist.ColorSpec:
+		if c != nil && *c != nil {
+			return *c, true
+		}
+	default:
+		log.Printf("ColorSpecValueView: could not get color spec value from type: %T val: %+v\n", c, c)
+	}
+	return nil, false
+}
+
+func (vv *ColorSpecValueView) WidgetType() reflect.Type {
+	vv.WidgetTyp = gi.KiT_Action
+	return vv.WidgetTyp
+}
+
+func (vv *ColorSpecValueView) UpdateWidget() {
+	if vv.Widget == nil {
+		return
+	}
+	ac := vv.Widget.(*gi.Action)
+	cs, ok := vv.ColorSpec()
+	if !ok {
+		return
+	}
+	updt := ac.UpdateStart()
+	bg := &gist.ColorSpec{} // copy, so edits are only shown when set
+	bg.CopyFrom(cs)
+	ac.SetProp("background-color", bg)
+	switch {
+	case cs.Source == gist.LinearGradient && cs.Gradient != nil:
+		ac.Tooltip = "linear gradient"
+	case cs.Source == gist.RadialGradient && cs.Gradient != nil:
+		ac.Tooltip = "radial gradient"
+	case cs.Color.IsNil():
+		ac.Tooltip = "none"
+	default:
+		ac.Tooltip = ColorHexString(cs.Color)
+	}
+	ac.Tooltip += " -- click to edit as a solid color or a gradient"
+	ac.SetFullReRender()
+	ac.UpdateEnd(updt)
+}
+
+func (vv *ColorSpecValueView) ConfigWidget(widg gi.Node2D) {
+	vv.Widget = widg
+	vv.StdConfigWidget(widg)
+	ac := vv.Widget.(*gi.Action)
+	ac.Class = "color-spec" // not a default class, whose state styles would replace the spec with gradients of its color
+	ac.SetProp("border-radius", units.NewPx(4))
+	ac.SetProp("border-width", units.NewPx(1))
+	ac.SetProp("min-width", units.NewEm(8))
+	ac.SetProp("min-height", units.NewEm(1.5))
+	ac.ActionSig.ConnectOnly(vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		vvv, _ := recv.Embed(KiT_ColorSpecValueView).(*ColorSpecValueView)
+		ac := vvv.Widget.(*gi.Action)
+		vvv.Activate(ac.ViewportSafe(), nil, nil)
+	})
+	vv.UpdateWidget()
+}
+
+func (vv *ColorSpecValueView) HasAction() bool {
+	return true
+}
+
+func (vv *ColorSpecValueView) Activate(vp *gi.Viewport2D, dlgRecv ki.Ki, dlgFunc ki.RecvFunc) {
+	if vv.IsInactive() {
+		return
+	}
+	cs, ok := vv.ColorSpec()
+	if !ok {
+		return
+	}
+	desc, _ := vv.Tag("desc")
+	GradientViewDialog(vp, cs, DlgOpts{Title: "Color Spec View", Prompt: desc, TmpSave: vv.TmpSave},
+		vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig == int64(gi.DialogAccepted) {
+				ddlg := send.Embed(gi.KiT_Dialog).(*gi.Dialog)
+				ncs := GradientViewDialogValue(ddlg)
+				vv.SetValue(ncs)
+				vv.UpdateWidget()
+			}
+			if dlgRecv != nil && dlgFunc != nil {
+				dlgFunc(dlgRecv, send, sig, data)
+			}
+		})
+}
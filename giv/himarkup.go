@@ -5,14 +5,17 @@
 package giv
 
 import (
+	"bytes"
 	stdhtml "html"
 	"log"
+	"regexp"
 	"strings"
 
 	"github.com/alecthomas/chroma"
 	"github.com/alecthomas/chroma/formatters/html"
 	"github.com/alecthomas/chroma/lexers"
 	"github.com/goki/gi/gi"
+	"github.com/goki/gi/girl"
 	"github.com/goki/gi/histyle"
 	"github.com/goki/ki/ints"
 	"github.com/goki/ki/ki"
@@ -70,7 +73,13 @@ func (hm *HiMarkup) Init(info *FileInfo, pist *pi.FileStates) {
 	}
 
 	if hm.PiLang == nil {
-		lexer := lexers.Match(hm.Info.Name)
+		var lexer chroma.Lexer
+		if hm.Info.Type != nil && hm.Info.Type.Lexer != "" {
+			lexer = lexers.Get(hm.Info.Type.Lexer)
+		}
+		if lexer == nil {
+			lexer = lexers.Match(hm.Info.Name)
+		}
 		// if lexer == nil && len(pist.Src.Lines) > 0 {
 		// 	lexer = lexers.Analyse(string(tb.Txt))
 		// }
@@ -281,6 +290,82 @@ func (hm *HiMarkup) MarkupLine(txt []rune, hitags, tags lex.Line) []byte {
 	return mu
 }
 
+// spanClassRe matches the start of each span tag in MarkupLine output
+var spanClassRe = regexp.MustCompile(`<span class="([^"]*)">`)
+
+// MarkupLinesHTML returns standalone HTML for given lines and hi tags (which
+// may be nil), as a <pre> element using inline CSS styles from the current
+// highlighting style instead of the classes used by MarkupLine, so that it
+// displays properly in other applications, e.g., when pasted
+func (hm *HiMarkup) MarkupLinesHTML(lines [][]rune, tags []lex.Line) []byte {
+	bg := ""
+	if hm.HiStyle != nil {
+		bg = hm.HiStyle.TagRaw(token.Background).ToCSS()
+	}
+	var b bytes.Buffer
+	b.WriteString(`<pre style="` + bg + `">`)
+	b.Write(hm.markupLinesStyled(lines, tags))
+	b.WriteString("</pre>")
+	return b.Bytes()
+}
+
+// markupLinesStyled returns the markup for given lines and hi tags, with
+// <span> elements using inline CSS styles from the current highlighting
+// style, separated by newlines
+func (hm *HiMarkup) markupLinesStyled(lines [][]rune, tags []lex.Line) []byte {
+	css := map[string]string{}
+	if hm.HiStyle != nil {
+		for tok, cs := range hm.HiStyle.ToCSS() {
+			css[tok.StyleName()] = cs
+		}
+	}
+	var b bytes.Buffer
+	for i, txt := range lines {
+		var ht lex.Line
+		if i < len(tags) {
+			ht = tags[i]
+		}
+		mu := hm.MarkupLine(txt, ht, nil)
+		b.Write(spanClassRe.ReplaceAllFunc(mu, func(sp []byte) []byte {
+			cls := string(spanClassRe.FindSubmatch(sp)[1])
+			return []byte(`<span style="` + stdhtml.EscapeString(css[cls]) + `">`)
+		}))
+		if i < len(lines)-1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.Bytes()
+}
+
+func init() {
+	girl.CodeMarkup = CodeMarkup
+}
+
+// CodeMarkup returns the syntax-highlighted markup of given code in given
+// chroma language (e.g., "go", "python"), using <span> elements with inline
+// styles from the default highlighting style -- this is the
+// girl.CodeMarkup function for <code lang="go"> elements in HTML text
+// (e.g., in a gi.Label).  Code in an unknown language is just escaped.
+func CodeMarkup(code, lang string) string {
+	lexer := lexers.Get(lang)
+	hs := histyle.AvailStyle(histyle.StyleDefault)
+	if lexer == nil || hs == nil {
+		return string(HTMLEscapeBytes([]byte(code)))
+	}
+	hm := &HiMarkup{Style: histyle.StyleDefault, HiStyle: hs}
+	hm.lexer = chroma.Coalesce(lexer)
+	tags, err := hm.ChromaTagsAll([]byte(code))
+	if err != nil {
+		return string(HTMLEscapeBytes([]byte(code)))
+	}
+	lns := strings.Split(code, "\n")
+	lines := make([][]rune, len(lns))
+	for i, ln := range lns {
+		lines[i] = []rune(ln)
+	}
+	return string(hm.markupLinesStyled(lines, tags))
+}
+
 ///////////////////////////////////////////////////////////////////////////
 // HTMLEscapeBytes
 
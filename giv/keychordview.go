@@ -6,6 +6,7 @@ package giv
 
 import (
 	"reflect"
+	"time"
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/gist"
@@ -64,13 +65,19 @@ func (vv *KeyChordValueView) HasAction() bool {
 /////////////////////////////////////////////////////////////////////////////////
 // KeyChordEdit
 
+// KeySeqMSec is the number of milliseconds within which chords typed into a
+// KeyChordEdit are added to a multi-key sequence
+var KeySeqMSec = 1000
+
 // KeyChordEdit is a label widget that shows a key chord string, and, when in
 // focus (after being clicked) will update to whatever key chord is typed --
-// used for representing and editing key chords.
+// used for representing and editing key chords.  Chords typed within
+// KeySeqMSec of the previous one are added to a multi-key sequence.
 type KeyChordEdit struct {
 	gi.Label
 	FocusActive bool      `json:"-" xml:"-" desc:"true if the keyboard focus is active or not -- when we lose active focus we apply changes"`
 	KeyChordSig ki.Signal `json:"-" xml:"-" view:"-" desc:"signal -- only one event, when chord is updated from key input"`
+	lastChord   time.Time // when the last chord was typed, for multi-key sequences
 }
 
 var KiT_KeyChordEdit = kit.Types.AddType(&KeyChordEdit{}, KeyChordEditProps)
@@ -148,13 +155,24 @@ func (kc *KeyChordEdit) KeyChordEvent() {
 		if kcc.HasFocus() && kcc.FocusActive {
 			kt := d.(*key.ChordEvent)
 			kt.SetProcessed()
-			kcc.SetText(string(kt.Chord())) // that's easy!
-			oswin.TheApp.ClipBoard(kc.ParentWindow().OSWin).Write(mimedata.NewText(string(kt.Chord())))
+			ch := string(kt.Chord()) // that's easy!
+			if kcc.Text != "" && time.Since(kcc.lastChord) < time.Duration(KeySeqMSec)*time.Millisecond {
+				ch = kcc.Text + " " + ch
+			}
+			kcc.lastChord = time.Now()
+			kcc.SetText(ch)
+			oswin.TheApp.ClipBoard(kc.ParentWindow().OSWin).Write(mimedata.NewText(ch))
 			kcc.ChordUpdated()
 		}
 	})
 }
 
+// GrabsKeyChords returns true when the chords typed are being recorded, so
+// the window does not gather multi-key sequences
+func (kc *KeyChordEdit) GrabsKeyChords() bool {
+	return kc.HasFocus() && kc.FocusActive
+}
+
 func (kc *KeyChordEdit) Style2D() {
 	kc.SetCanFocusIfActive()
 	kc.Selectable = true
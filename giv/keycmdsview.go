@@ -0,0 +1,113 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"fmt"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/mat32"
+)
+
+// KeyCmdsView opens a view for remapping the key chords of the commands of
+// the app, and the key functions for this app, e.g., gi.AppKeyCmds
+func KeyCmdsView(kc *gi.KeyCmds) {
+	winm := "gogi-key-cmds"
+	width := 800
+	height := 800
+	win, recyc := gi.RecycleMainWindow(kc, winm, "Keyboard Shortcuts", width, height)
+	if recyc {
+		return
+	}
+
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+
+	mfr := win.SetMainFrame()
+	mfr.Lay = gi.LayoutVert
+
+	title := mfr.AddNewChild(gi.KiT_Label, "title").(*gi.Label)
+	title.SetText("Keyboard Shortcuts of " + gi.AppName() + ": click on a Chord and type the new key chord, or several in quick succession for a multi-key sequence")
+	title.SetProp("width", units.NewCh(30)) // need for wrap
+	title.SetStretchMaxWidth()
+	title.SetProp("white-space", gist.WhiteSpaceNormal) // wrap
+
+	split := gi.AddNewSplitView(mfr, "split")
+	split.Dim = mat32.Y
+	split.SetStretchMax()
+
+	tv := AddNewTableView(split, "tv")
+	tv.Viewport = vp
+	tv.NoAdd = true
+	tv.NoDelete = true
+	tv.SetSlice(kc)
+	tv.SetStretchMax()
+
+	kmfr := gi.AddNewFrame(split, "keymap", gi.LayoutVert)
+	kmlb := gi.AddNewLabel(kmfr, "keymap-lbl", "Key functions for this app, overriding the active key map (KeyFunNil to disable a chord):")
+	kmlb.SetProp("white-space", gist.WhiteSpaceNormal)
+	if gi.AppKeyMap == nil {
+		gi.AppKeyMap = make(gi.KeyMap)
+	}
+	mv := kmfr.AddNewChild(KiT_MapView, "map-view").(*MapView)
+	mv.Viewport = vp
+	mv.SetMap(&gi.AppKeyMap)
+	mv.SetStretchMax()
+	split.SetSplits(.7, .3)
+
+	gi.AppKeyCmdsChanged = false
+	tv.ViewSig.Connect(mfr.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		gi.AppKeyCmdsChanged = true
+	})
+	mv.ViewSig.Connect(mfr.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		gi.AppKeyCmdsChanged = true
+	})
+
+	mmen := win.MainMenu
+	MainMenuView(kc, win, mmen)
+
+	inClosePrompt := false
+	win.OSWin.SetCloseReqFunc(func(w oswin.Window) {
+		if !gi.AppKeyCmdsChanged || kc != &gi.AppKeyCmds {
+			win.Close()
+			return
+		}
+		if inClosePrompt {
+			return
+		}
+		inClosePrompt = true
+		gi.ChoiceDialog(vp, gi.DlgOpts{Title: "Save Shortcuts Before Closing?",
+			Prompt: "Do you want to save any changes to the keyboard shortcuts of this app before closing, or Cancel the close and do a Save to a different file?"},
+			[]string{"Save and Close", "Discard and Close", "Cancel"},
+			win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				switch sig {
+				case 0:
+					kc.SavePrefs()
+					fmt.Printf("Preferences Saved to %v\n", gi.PrefsKeyCmdsFileName)
+					win.Close()
+				case 1:
+					kc.OpenPrefs() // revert
+					win.Close()
+				case 2:
+					inClosePrompt = false
+					// default is to do nothing, i.e., cancel
+				}
+			})
+	})
+
+	win.MainMenuUpdated()
+
+	if !win.HasGeomPrefs() { // resize to contents
+		vpsz := vp.PrefSize(win.OSWin.Screen().PixSize)
+		win.SetSize(vpsz)
+	}
+
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+}
@@ -0,0 +1,264 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Server specifies the command for running a language server, which must
+// communicate over its stdin / stdout
+type Server struct {
+	Cmd      string                 `desc:"command to run the server"`
+	Args     []string               `desc:"arguments to the command"`
+	LangID   string                 `desc:"LSP language identifier for documents handled by the server, e.g., go, python, c"`
+	Settings map[string]interface{} `desc:"settings of the server, by configuration section (e.g., gopls), returned for workspace/configuration requests -- sections not present get null, which servers take as their defaults"`
+}
+
+// DiagnosticsFunc is called with diagnostics published by the server, in
+// the connection's reading goroutine
+type DiagnosticsFunc func(params *PublishDiagnosticsParams)
+
+// Client is a running language server for a given root directory
+type Client struct {
+	Server      Server          `desc:"the server that is running"`
+	Root        string          `desc:"root directory of the workspace"`
+	Diagnostics DiagnosticsFunc `desc:"function called for diagnostics published by the server"`
+	Cmd         *exec.Cmd       `desc:"the running server process"`
+	Conn        *Conn           `desc:"connection to the server"`
+}
+
+// Start starts given server for given workspace root directory, and
+// performs the initialize handshake
+func Start(srv Server, root string, diags DiagnosticsFunc) (*Client, error) {
+	cl := &Client{Server: srv, Root: root, Diagnostics: diags}
+	cl.Cmd = exec.Command(srv.Cmd, srv.Args...)
+	cl.Cmd.Dir = root
+	in, err := cl.Cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cl.Cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cl.Cmd.Start(); err != nil {
+		return nil, fmt.Errorf("lsp: could not start server %v: %v", srv.Cmd, err)
+	}
+	cl.Conn = NewConn(in, out, cl.notify, cl.request)
+	go func() {
+		cl.Cmd.Wait()
+		cl.Conn.Close()
+	}()
+	if err := cl.initialize(); err != nil {
+		cl.Cmd.Process.Kill()
+		return nil, err
+	}
+	return cl, nil
+}
+
+// notify handles notifications from the server
+func (cl *Client) notify(method string, params json.RawMessage) {
+	switch method {
+	case "textDocument/publishDiagnostics":
+		if cl.Diagnostics == nil {
+			return
+		}
+		pd := &PublishDiagnosticsParams{}
+		if json.Unmarshal(params, pd) == nil {
+			cl.Diagnostics(pd)
+		}
+	}
+}
+
+// configurationItem is an item of the workspace/configuration request
+type configurationItem struct {
+	ScopeURI string `json:"scopeUri"`
+	Section  string `json:"section"`
+}
+
+// workspaceFolder is a root folder of the workspace
+type workspaceFolder struct {
+	URI  string `json:"uri"`
+	Name string `json:"name"`
+}
+
+// request handles requests from the server
+func (cl *Client) request(method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case "workspace/configuration":
+		var cp struct {
+			Items []configurationItem `json:"items"`
+		}
+		if err := json.Unmarshal(params, &cp); err != nil {
+			return nil, &RespError{Code: -32602, Message: err.Error()} // invalid params
+		}
+		res := make([]interface{}, len(cp.Items))
+		for i, it := range cp.Items {
+			res[i] = cl.Server.Settings[it.Section]
+		}
+		return res, nil
+	case "workspace/workspaceFolders":
+		return []workspaceFolder{cl.workspaceFolder()}, nil
+	case "workspace/applyEdit":
+		// edits of other documents are not supported
+		return map[string]interface{}{"applied": false, "failureReason": "not supported"}, nil
+	case "window/workDoneProgress/create", "client/registerCapability", "client/unregisterCapability", "window/showMessageRequest":
+		return nil, nil
+	}
+	return nil, &RespError{Code: ErrMethodNotFound, Message: "method not supported: " + method}
+}
+
+// workspaceFolder returns the root folder of the workspace
+func (cl *Client) workspaceFolder() workspaceFolder {
+	return workspaceFolder{URI: FileURI(cl.Root), Name: filepath.Base(cl.Root)}
+}
+
+// initialize does the initialize handshake
+func (cl *Client) initialize() error {
+	params := map[string]interface{}{
+		"processId":        os.Getpid(),
+		"rootUri":          FileURI(cl.Root),
+		"workspaceFolders": []workspaceFolder{cl.workspaceFolder()},
+		"capabilities": map[string]interface{}{
+			"workspace": map[string]interface{}{
+				"configuration":    true,
+				"workspaceFolders": true,
+				"applyEdit":        false,
+			},
+			"textDocument": map[string]interface{}{
+				"synchronization": map[string]interface{}{"didSave": true},
+				"completion": map[string]interface{}{
+					"completionItem": map[string]interface{}{"snippetSupport": false},
+				},
+				"hover":              map[string]interface{}{"contentFormat": []string{"plaintext", "markdown"}},
+				"definition":         map[string]interface{}{"linkSupport": true},
+				"publishDiagnostics": map[string]interface{}{},
+			},
+		},
+	}
+	if err := cl.Conn.Call("initialize", params, nil); err != nil {
+		return err
+	}
+	return cl.Conn.SendNotify("initialized", struct{}{})
+}
+
+// IsRunning returns true if the server is still running
+func (cl *Client) IsRunning() bool {
+	return cl.Conn != nil && !cl.Conn.IsClosed()
+}
+
+// Shutdown shuts down the server
+func (cl *Client) Shutdown() error {
+	if !cl.IsRunning() {
+		return nil
+	}
+	err := cl.Conn.Call("shutdown", nil, nil)
+	cl.Conn.SendNotify("exit", nil)
+	return err
+}
+
+// DidOpen notifies the server that a document has been opened, with its full text
+func (cl *Client) DidOpen(uri string, version int, text string) error {
+	return cl.Conn.SendNotify("textDocument/didOpen", map[string]interface{}{
+		"textDocument": TextDocumentItem{URI: uri, LanguageID: cl.Server.LangID, Version: version, Text: text},
+	})
+}
+
+// DidChange notifies the server of the new full text of a document
+func (cl *Client) DidChange(uri string, version int, text string) error {
+	return cl.Conn.SendNotify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   VersionedTextDocumentIdentifier{URI: uri, Version: version},
+		"contentChanges": []TextDocumentContentChangeEvent{{Text: text}},
+	})
+}
+
+// DidSave notifies the server that a document has been saved
+func (cl *Client) DidSave(uri string) error {
+	return cl.Conn.SendNotify("textDocument/didSave", map[string]interface{}{
+		"textDocument": TextDocumentIdentifier{URI: uri},
+	})
+}
+
+// DidClose notifies the server that a document has been closed
+func (cl *Client) DidClose(uri string) error {
+	return cl.Conn.SendNotify("textDocument/didClose", map[string]interface{}{
+		"textDocument": TextDocumentIdentifier{URI: uri},
+	})
+}
+
+// posParams returns the params for a position-based request
+func posParams(uri string, pos Position) *TextDocumentPositionParams {
+	return &TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: pos}
+}
+
+// Completion returns the completions at given position in document
+func (cl *Client) Completion(uri string, pos Position) ([]CompletionItem, error) {
+	var raw json.RawMessage
+	if err := cl.Conn.Call("textDocument/completion", posParams(uri, pos), &raw); err != nil {
+		return nil, err
+	}
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	if raw[0] == '{' {
+		var lst completionList
+		err := json.Unmarshal(raw, &lst)
+		return lst.Items, err
+	}
+	var items []CompletionItem
+	err := json.Unmarshal(raw, &items)
+	return items, err
+}
+
+// Hover returns the hover documentation at given position in document, as text
+func (cl *Client) Hover(uri string, pos Position) (string, error) {
+	var hv *hover
+	if err := cl.Conn.Call("textDocument/hover", posParams(uri, pos), &hv); err != nil || hv == nil {
+		return "", err
+	}
+	return MarkupText(hv.Contents), nil
+}
+
+// Definition returns the location(s) where the symbol at given position in
+// document is defined
+func (cl *Client) Definition(uri string, pos Position) ([]Location, error) {
+	var raw json.RawMessage
+	if err := cl.Conn.Call("textDocument/definition", posParams(uri, pos), &raw); err != nil {
+		return nil, err
+	}
+	raw = bytes.TrimSpace(raw)
+	switch {
+	case len(raw) == 0 || string(raw) == "null":
+		return nil, nil
+	case raw[0] == '{':
+		var loc Location
+		err := json.Unmarshal(raw, &loc)
+		return []Location{loc}, err
+	}
+	var rl []json.RawMessage
+	if err := json.Unmarshal(raw, &rl); err != nil {
+		return nil, err
+	}
+	locs := make([]Location, 0, len(rl))
+	for _, r := range rl {
+		var ll locationLink
+		if json.Unmarshal(r, &ll) == nil && ll.TargetURI != "" {
+			locs = append(locs, Location{URI: ll.TargetURI, Range: ll.TargetSelectionRange})
+			continue
+		}
+		var loc Location
+		if json.Unmarshal(r, &loc) == nil {
+			locs = append(locs, loc)
+		}
+	}
+	return locs, nil
+}
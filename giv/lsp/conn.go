@@ -0,0 +1,264 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package lsp is a minimal client for the Language Server Protocol
+// (https://microsoft.github.io/language-server-protocol), providing
+// completion, hover, go-to-definition and diagnostics from a language
+// server process communicating over its stdin / stdout.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// CallTimeout is the maximum time to wait for a response to a request
+var CallTimeout = 10 * time.Second
+
+// ErrClosed is returned for calls on a connection that has been closed
+var ErrClosed = errors.New("lsp: connection closed")
+
+// RespError is an error returned by the server in response to a request
+type RespError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (re *RespError) Error() string {
+	return fmt.Sprintf("lsp: server error %d: %s", re.Code, re.Message)
+}
+
+// message is the JSON-RPC 2.0 wire format for all messages: requests have
+// an ID and Method, notifications only a Method, and responses only an ID.
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *RespError       `json:"error,omitempty"`
+}
+
+// NotifyFunc is called for each notification from the server, in the
+// connection's reading goroutine
+type NotifyFunc func(method string, params json.RawMessage)
+
+// RequestFunc is called for each request from the server, in the
+// connection's reading goroutine, returning the result to send back, or
+// an error (a *RespError for a specific error code)
+type RequestFunc func(method string, params json.RawMessage) (interface{}, error)
+
+// ErrMethodNotFound is the JSON-RPC error code for requests that are not
+// supported
+const ErrMethodNotFound = -32601
+
+// Conn is a JSON-RPC 2.0 connection using the LSP base protocol, where
+// each message is preceded by a Content-Length header
+type Conn struct {
+	Notify  NotifyFunc  `desc:"function called for notifications from the server"`
+	Request RequestFunc `desc:"function called for requests from the server -- if nil, all requests get a method not found error"`
+	in      *bufio.Reader
+	out     io.Writer
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]chan *message
+	closed  bool
+}
+
+// NewConn returns a new connection reading from in and writing to out, and
+// starts the goroutine that reads and dispatches incoming messages
+func NewConn(in io.Reader, out io.Writer, notify NotifyFunc, request RequestFunc) *Conn {
+	cn := &Conn{Notify: notify, Request: request, in: bufio.NewReader(in), out: out, pending: make(map[int64]chan *message)}
+	go cn.ReadLoop()
+	return cn
+}
+
+// Call sends a request and waits for the response, which is unmarshaled
+// into result if non-nil
+func (cn *Conn) Call(method string, params, result interface{}) error {
+	cn.mu.Lock()
+	if cn.closed {
+		cn.mu.Unlock()
+		return ErrClosed
+	}
+	cn.nextID++
+	id := cn.nextID
+	rch := make(chan *message, 1)
+	cn.pending[id] = rch
+	cn.mu.Unlock()
+
+	idb := json.RawMessage(strconv.FormatInt(id, 10))
+	if err := cn.send(&message{ID: &idb, Method: method}, params); err != nil {
+		cn.forget(id)
+		return err
+	}
+	select {
+	case rm := <-rch:
+		if rm == nil {
+			return ErrClosed
+		}
+		if rm.Error != nil {
+			return rm.Error
+		}
+		if result == nil || len(rm.Result) == 0 {
+			return nil
+		}
+		return json.Unmarshal(rm.Result, result)
+	case <-time.After(CallTimeout):
+		cn.forget(id)
+		return fmt.Errorf("lsp: timeout waiting for response to %v", method)
+	}
+}
+
+// SendNotify sends a notification, which has no response
+func (cn *Conn) SendNotify(method string, params interface{}) error {
+	return cn.send(&message{Method: method}, params)
+}
+
+// forget removes a pending request
+func (cn *Conn) forget(id int64) {
+	cn.mu.Lock()
+	delete(cn.pending, id)
+	cn.mu.Unlock()
+}
+
+// send writes given message with params
+func (cn *Conn) send(msg *message, params interface{}) error {
+	msg.JSONRPC = "2.0"
+	if params != nil {
+		pb, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		msg.Params = pb
+	}
+	return cn.write(msg)
+}
+
+// write writes a message with its header
+func (cn *Conn) write(msg *message) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	cn.writeMu.Lock()
+	defer cn.writeMu.Unlock()
+	if _, err := fmt.Fprintf(cn.out, "Content-Length: %d\r\n\r\n", len(b)); err != nil {
+		return err
+	}
+	_, err = cn.out.Write(b)
+	return err
+}
+
+// read reads the next message
+func (cn *Conn) read() (*message, error) {
+	hdr, err := textproto.NewReader(cn.in).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(hdr.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("lsp: invalid Content-Length header: %v", err)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(cn.in, b); err != nil {
+		return nil, err
+	}
+	msg := &message{}
+	return msg, json.Unmarshal(b, msg)
+}
+
+// ReadLoop reads and dispatches messages until the input is closed --
+// called in a separate goroutine by NewConn
+func (cn *Conn) ReadLoop() {
+	for {
+		msg, err := cn.read()
+		if err != nil {
+			if _, ok := err.(*json.SyntaxError); ok {
+				continue
+			}
+			cn.Close()
+			return
+		}
+		switch {
+		case msg.Method != "" && msg.ID != nil: // request from server
+			cn.respond(msg)
+		case msg.Method != "":
+			if cn.Notify != nil {
+				cn.Notify(msg.Method, msg.Params)
+			}
+		case msg.ID != nil:
+			id, err := strconv.ParseInt(string(*msg.ID), 10, 64)
+			if err != nil {
+				continue
+			}
+			cn.mu.Lock()
+			rch, ok := cn.pending[id]
+			delete(cn.pending, id)
+			cn.mu.Unlock()
+			if ok {
+				rch <- msg
+			}
+		}
+	}
+}
+
+// respond sends the response to given request from the server
+func (cn *Conn) respond(msg *message) {
+	rm := &message{JSONRPC: "2.0", ID: msg.ID}
+	if cn.Request == nil {
+		rm.Error = &RespError{Code: ErrMethodNotFound, Message: "method not supported: " + msg.Method}
+		cn.write(rm)
+		return
+	}
+	res, err := cn.Request(msg.Method, msg.Params)
+	if err != nil {
+		re, ok := err.(*RespError)
+		if !ok {
+			re = &RespError{Code: -32603, Message: err.Error()} // internal error
+		}
+		rm.Error = re
+		cn.write(rm)
+		return
+	}
+	rb, err := json.Marshal(res)
+	if err != nil {
+		rm.Error = &RespError{Code: -32603, Message: err.Error()}
+		cn.write(rm)
+		return
+	}
+	rm.Result = rb
+	cn.write(rm)
+}
+
+// Close marks the connection as closed, and returns ErrClosed for all
+// pending calls
+func (cn *Conn) Close() {
+	cn.mu.Lock()
+	defer cn.mu.Unlock()
+	if cn.closed {
+		return
+	}
+	cn.closed = true
+	for id, rch := range cn.pending {
+		rch <- nil
+		delete(cn.pending, id)
+	}
+}
+
+// IsClosed returns true if the connection has been closed
+func (cn *Conn) IsClosed() bool {
+	cn.mu.Lock()
+	defer cn.mu.Unlock()
+	return cn.closed
+}
@@ -0,0 +1,288 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// This file has the subset of the LSP protocol types used by the Client.
+// Positions use 0-based lines, and characters in UTF-16 code units, as
+// per the protocol -- see UTF16ToRune and RuneToUTF16 for conversion.
+
+// Position is a position in a text document
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a range in a text document, with an exclusive end
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location is a range within a document
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+// locationLink is the alternative form of definition results
+type locationLink struct {
+	TargetURI            string `json:"targetUri"`
+	TargetSelectionRange Range  `json:"targetSelectionRange"`
+}
+
+// TextDocumentIdentifier identifies a document by URI
+type TextDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+// VersionedTextDocumentIdentifier identifies a specific version of a document
+type VersionedTextDocumentIdentifier struct {
+	URI     string `json:"uri"`
+	Version int    `json:"version"`
+}
+
+// TextDocumentItem is a document being opened, with its full text
+type TextDocumentItem struct {
+	URI        string `json:"uri"`
+	LanguageID string `json:"languageId"`
+	Version    int    `json:"version"`
+	Text       string `json:"text"`
+}
+
+// TextDocumentPositionParams are the params for position-based requests
+type TextDocumentPositionParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+// TextDocumentContentChangeEvent is a change to a document -- we always
+// send the full text
+type TextDocumentContentChangeEvent struct {
+	Text string `json:"text"`
+}
+
+// DiagnosticSeverity is the severity of a Diagnostic
+type DiagnosticSeverity int
+
+const (
+	SeverityError DiagnosticSeverity = iota + 1
+	SeverityWarning
+	SeverityInformation
+	SeverityHint
+)
+
+// Diagnostic is an error, warning etc reported by the server for a range
+// of a document
+type Diagnostic struct {
+	Range    Range              `json:"range"`
+	Severity DiagnosticSeverity `json:"severity,omitempty"`
+	Source   string             `json:"source,omitempty"`
+	Message  string             `json:"message"`
+}
+
+// PublishDiagnosticsParams are the current diagnostics for a document,
+// replacing any previous ones
+type PublishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// TextEdit is an edit to a document
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+// CompletionItemKind is the kind of a completion item
+type CompletionItemKind int
+
+const (
+	KindText CompletionItemKind = iota + 1
+	KindMethod
+	KindFunction
+	KindConstructor
+	KindField
+	KindVariable
+	KindClass
+	KindInterface
+	KindModule
+	KindProperty
+	KindUnit
+	KindValue
+	KindEnum
+	KindKeyword
+	KindSnippet
+	KindColor
+	KindFile
+	KindReference
+	KindFolder
+	KindEnumMember
+	KindConstant
+	KindStruct
+	KindEvent
+	KindOperator
+	KindTypeParameter
+)
+
+// IconName returns the icon name used in completion menus for this kind,
+// consistent with token.Tokens IconName
+func (ck CompletionItemKind) IconName() string {
+	switch ck {
+	case KindMethod:
+		return "method"
+	case KindFunction, KindConstructor:
+		return "function"
+	case KindField, KindProperty:
+		return "field"
+	case KindVariable:
+		return "var"
+	case KindClass, KindInterface, KindStruct, KindEnum, KindTypeParameter:
+		return "type"
+	case KindConstant, KindEnumMember:
+		return "const"
+	}
+	return ""
+}
+
+// CompletionItem is one possible completion
+type CompletionItem struct {
+	Label         string             `json:"label"`
+	Kind          CompletionItemKind `json:"kind,omitempty"`
+	Detail        string             `json:"detail,omitempty"`
+	Documentation json.RawMessage    `json:"documentation,omitempty"`
+	InsertText    string             `json:"insertText,omitempty"`
+	TextEdit      *TextEdit          `json:"textEdit,omitempty"`
+}
+
+// Text returns the text to insert for this completion
+func (ci *CompletionItem) Text() string {
+	switch {
+	case ci.TextEdit != nil:
+		return ci.TextEdit.NewText
+	case ci.InsertText != "":
+		return ci.InsertText
+	}
+	return ci.Label
+}
+
+// DocText returns the documentation as plain text
+func (ci *CompletionItem) DocText() string {
+	return MarkupText(ci.Documentation)
+}
+
+// completionList is the alternative form of completion results
+type completionList struct {
+	IsIncomplete bool             `json:"isIncomplete"`
+	Items        []CompletionItem `json:"items"`
+}
+
+// markupContent is formatted (markdown or plaintext) content
+type markupContent struct {
+	Kind     string `json:"kind"`
+	Value    string `json:"value"`
+	Language string `json:"language"`
+}
+
+// hover is the result of a hover request
+type hover struct {
+	Contents json.RawMessage `json:"contents"`
+}
+
+// MarkupText returns the text of documentation or hover contents, which
+// can be a string, a MarkupContent or MarkedString object, or a list of
+// those.
+func MarkupText(raw json.RawMessage) string {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	switch raw[0] {
+	case '"':
+		var s string
+		json.Unmarshal(raw, &s)
+		return s
+	case '[':
+		var rl []json.RawMessage
+		json.Unmarshal(raw, &rl)
+		strs := make([]string, 0, len(rl))
+		for _, r := range rl {
+			if s := MarkupText(r); s != "" {
+				strs = append(strs, s)
+			}
+		}
+		return strings.Join(strs, "\n\n")
+	}
+	var mc markupContent
+	json.Unmarshal(raw, &mc)
+	return mc.Value
+}
+
+// FileURI returns the file:// URI for given file path
+func FileURI(fn string) string {
+	if afn, err := filepath.Abs(fn); err == nil {
+		fn = afn
+	}
+	pth := filepath.ToSlash(fn)
+	if !strings.HasPrefix(pth, "/") { // windows drive letter
+		pth = "/" + pth
+	}
+	u := url.URL{Scheme: "file", Path: pth}
+	return u.String()
+}
+
+// URIFile returns the file path for given file:// URI
+func URIFile(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	pth := u.Path
+	if len(pth) > 2 && pth[0] == '/' && pth[2] == ':' { // windows drive letter
+		pth = pth[1:]
+	}
+	return filepath.FromSlash(pth)
+}
+
+// utf16Len returns the number of UTF-16 code units for given rune
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+// UTF16ToRune returns the rune index within line for given character
+// offset in UTF-16 code units
+func UTF16ToRune(line []rune, u16 int) int {
+	n := 0
+	for i, r := range line {
+		if n >= u16 {
+			return i
+		}
+		n += utf16Len(r)
+	}
+	return len(line)
+}
+
+// RuneToUTF16 returns the character offset in UTF-16 code units for given
+// rune index within line
+func RuneToUTF16(line []rune, ri int) int {
+	if ri > len(line) {
+		ri = len(line)
+	}
+	n := 0
+	for _, r := range line[:ri] {
+		n += utf16Len(r)
+	}
+	return n
+}
@@ -0,0 +1,900 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"fmt"
+	stdhtml "html"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/goki/ki/kit"
+)
+
+// markdown.go has a parser for Markdown (CommonMark, plus the GitHub
+// tables, strikethrough, task list and bare URL autolink extensions),
+// which produces the blocks displayed by MarkdownView, with the inline
+// content of each block as the HTML subset supported by gi.Label.
+// Raw HTML blocks are not supported, but inline HTML tags are passed
+// through, so they can be used for any styling that gi.Label supports.
+
+// MarkdownBlocks are the kinds of MarkdownBlock
+type MarkdownBlocks int32
+
+const (
+	// MarkdownPara is a paragraph of text
+	MarkdownPara MarkdownBlocks = iota
+
+	// MarkdownHeading is a heading, with a Level from 1 to 6
+	MarkdownHeading
+
+	// MarkdownCode is a fenced or indented code block, with a Lang from
+	// the info string of the fence -- its Text is the raw code
+	MarkdownCode
+
+	// MarkdownQuote is a block quote, whose Children are the quoted blocks
+	MarkdownQuote
+
+	// MarkdownList is a bullet or ordered list, including all nested lists
+	MarkdownList
+
+	// MarkdownTable is a table
+	MarkdownTable
+
+	// MarkdownRule is a thematic break (horizontal rule)
+	MarkdownRule
+
+	MarkdownBlocksN
+)
+
+//go:generate stringer -type=MarkdownBlocks
+
+var KiT_MarkdownBlocks = kit.Enums.AddEnumAltLower(MarkdownBlocksN, kit.NotBitFlag, nil, "Markdown")
+
+func (ev MarkdownBlocks) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *MarkdownBlocks) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// MarkdownBlock is one block of a parsed Markdown document
+type MarkdownBlock struct {
+	Kind     MarkdownBlocks  `desc:"kind of block"`
+	Level    int             `desc:"level of a heading, from 1 to 6"`
+	Lang     string          `desc:"language of a code block, from the info string of the fence"`
+	Anchor   string          `desc:"anchor of a heading, for links to #anchor -- the heading text in lower case, with spaces replaced by - and other punctuation removed"`
+	Text     string          `desc:"content of the block as HTML for gi.Label, except the raw code of a code block"`
+	Children []MarkdownBlock `desc:"blocks within a block quote"`
+}
+
+// MarkdownParser parses Markdown text into MarkdownBlocks
+type MarkdownParser struct {
+	BaseDir string            `desc:"directory for relative image paths, typically that of the Markdown file"`
+	Refs    map[string]string `desc:"link reference definitions, by lower-case label -- collected in a first pass over the text"`
+}
+
+// ParseMarkdown parses given Markdown text into blocks, with relative
+// image paths relative to given base directory (if non-empty)
+func ParseMarkdown(md string, baseDir string) []MarkdownBlock {
+	mp := &MarkdownParser{BaseDir: baseDir}
+	return mp.Parse(md)
+}
+
+var (
+	mdRefDefRe     = regexp.MustCompile(`^ {0,3}\[([^\]]+)\]:\s*<?([^\s>]+)>?(?:\s+["'(](.*)["')])?\s*$`)
+	mdATXRe        = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	mdRuleRe       = regexp.MustCompile(`^ {0,3}((?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	mdSetext1Re    = regexp.MustCompile(`^ {0,3}=+[ \t]*$`)
+	mdSetext2Re    = regexp.MustCompile(`^ {0,3}-+[ \t]*$`)
+	mdFenceRe      = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^`]*?)[ \t]*$")
+	mdQuoteRe      = regexp.MustCompile(`^ {0,3}> ?(.*)$`)
+	mdListRe       = regexp.MustCompile(`^( {0,3})([-*+]|\d{1,9}[.)])(?:([ \t]+)(.*))?$`)
+	mdTableDelimRe = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+)
+
+// Parse parses given Markdown text into blocks
+func (mp *MarkdownParser) Parse(md string) []MarkdownBlock {
+	md = strings.Replace(md, "\r\n", "\n", -1)
+	lines := strings.Split(md, "\n")
+	for i, ln := range lines {
+		lines[i] = mdExpandTabs(ln)
+	}
+	if mp.Refs == nil {
+		mp.Refs = make(map[string]string)
+	}
+	lines = mp.collectRefs(lines)
+	return mp.parseBlocks(lines)
+}
+
+// mdExpandTabs expands the tabs in the leading indentation of given line
+// to spaces, with tab stops of 4
+func mdExpandTabs(ln string) string {
+	if !strings.Contains(ln, "\t") {
+		return ln
+	}
+	var b strings.Builder
+	col := 0
+	for i, r := range ln {
+		switch r {
+		case '\t':
+			n := 4 - col%4
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+		case ' ':
+			b.WriteRune(r)
+			col++
+		default:
+			b.WriteString(ln[i:])
+			return b.String()
+		}
+	}
+	return b.String()
+}
+
+// mdIndent returns the number of leading spaces in given line
+func mdIndent(ln string) int {
+	return len(ln) - len(strings.TrimLeft(ln, " "))
+}
+
+// mdIsBlank returns true if the line is empty or only white space
+func mdIsBlank(ln string) bool {
+	return strings.TrimSpace(ln) == ""
+}
+
+// collectRefs removes link reference definitions from the lines, and
+// records them in Refs -- they can only start a paragraph, i.e., follow a
+// blank line or another definition, or be at the start
+func (mp *MarkdownParser) collectRefs(lines []string) []string {
+	out := lines[:0:0]
+	inFence := false
+	prevBlank := true
+	for _, ln := range lines {
+		if mdFenceRe.MatchString(ln) {
+			inFence = !inFence
+		}
+		if !inFence && prevBlank {
+			if sm := mdRefDefRe.FindStringSubmatch(ln); sm != nil {
+				lbl := strings.ToLower(strings.Join(strings.Fields(sm[1]), " "))
+				if _, has := mp.Refs[lbl]; !has {
+					mp.Refs[lbl] = sm[2]
+				}
+				continue
+			}
+		}
+		prevBlank = mdIsBlank(ln)
+		out = append(out, ln)
+	}
+	return out
+}
+
+// mdIsBlockStart returns true if given line starts a block that interrupts
+// a paragraph
+func mdIsBlockStart(ln string) bool {
+	if mdATXRe.MatchString(ln) || mdRuleRe.MatchString(ln) || mdFenceRe.MatchString(ln) || mdQuoteRe.MatchString(ln) {
+		return true
+	}
+	if sm := mdListRe.FindStringSubmatch(ln); sm != nil && strings.TrimSpace(sm[4]) != "" {
+		if sm[2][0] >= '0' && sm[2][0] <= '9' {
+			return strings.HasPrefix(sm[2], "1") && len(sm[2]) == 2 // only lists starting at 1 interrupt
+		}
+		return true
+	}
+	return false
+}
+
+// parseBlocks parses given lines (with containers already stripped) into
+// blocks
+func (mp *MarkdownParser) parseBlocks(lines []string) []MarkdownBlock {
+	var blks []MarkdownBlock
+	var para []string
+	endPara := func() {
+		if len(para) > 0 {
+			txt := strings.TrimSpace(strings.Join(para, "\n"))
+			blks = append(blks, MarkdownBlock{Kind: MarkdownPara, Text: mp.Inline(txt)})
+			para = nil
+		}
+	}
+	n := len(lines)
+	for i := 0; i < n; i++ {
+		ln := lines[i]
+		if mdIsBlank(ln) {
+			endPara()
+			continue
+		}
+		if len(para) > 0 { // setext headings underline a paragraph
+			lvl := 0
+			if mdSetext1Re.MatchString(ln) {
+				lvl = 1
+			} else if mdSetext2Re.MatchString(ln) {
+				lvl = 2
+			}
+			if lvl > 0 {
+				txt := strings.TrimSpace(strings.Join(para, "\n"))
+				para = nil
+				blks = append(blks, mp.heading(lvl, txt))
+				continue
+			}
+		}
+		if len(para) == 0 && mdIndent(ln) >= 4 { // indented code
+			var code []string
+			for ; i < n; i++ {
+				if !mdIsBlank(lines[i]) && mdIndent(lines[i]) < 4 {
+					break
+				}
+				if len(lines[i]) >= 4 {
+					code = append(code, lines[i][4:])
+				} else {
+					code = append(code, "")
+				}
+			}
+			i--
+			for len(code) > 0 && mdIsBlank(code[len(code)-1]) {
+				code = code[:len(code)-1]
+			}
+			blks = append(blks, MarkdownBlock{Kind: MarkdownCode, Text: strings.Join(code, "\n")})
+			continue
+		}
+		if sm := mdFenceRe.FindStringSubmatch(ln); sm != nil {
+			endPara()
+			ind := len(sm[1])
+			fence := sm[2]
+			lang := strings.Fields(sm[3] + " ")
+			var code []string
+			for i++; i < n; i++ {
+				cl := lines[i]
+				tcl := strings.TrimSpace(cl)
+				if mdIndent(cl) < 4 && strings.HasPrefix(tcl, fence) && strings.Trim(tcl, fence[:1]) == "" {
+					break
+				}
+				ci := mdIndent(cl)
+				if ci > ind {
+					ci = ind
+				}
+				code = append(code, cl[ci:])
+			}
+			blk := MarkdownBlock{Kind: MarkdownCode, Text: strings.Join(code, "\n")}
+			if len(lang) > 0 {
+				blk.Lang = lang[0]
+			}
+			blks = append(blks, blk)
+			continue
+		}
+		if sm := mdATXRe.FindStringSubmatch(ln); sm != nil {
+			endPara()
+			blks = append(blks, mp.heading(len(sm[1]), strings.TrimSpace(sm[2])))
+			continue
+		}
+		if mdRuleRe.MatchString(ln) {
+			endPara()
+			blks = append(blks, MarkdownBlock{Kind: MarkdownRule})
+			continue
+		}
+		if mdQuoteRe.MatchString(ln) {
+			endPara()
+			var qls []string
+			for ; i < n; i++ {
+				ql := lines[i]
+				if sm := mdQuoteRe.FindStringSubmatch(ql); sm != nil {
+					qls = append(qls, sm[1])
+				} else if !mdIsBlank(ql) && len(qls) > 0 && !mdIsBlank(qls[len(qls)-1]) && !mdIsBlockStart(ql) {
+					qls = append(qls, ql) // lazy continuation
+				} else {
+					break
+				}
+			}
+			i--
+			blks = append(blks, MarkdownBlock{Kind: MarkdownQuote, Children: mp.parseBlocks(qls)})
+			continue
+		}
+		if sm := mdListRe.FindStringSubmatch(ln); sm != nil && (len(para) == 0 || mdIsBlockStart(ln)) {
+			endPara()
+			ni := mp.listEnd(lines, i)
+			blks = append(blks, MarkdownBlock{Kind: MarkdownList, Text: mp.listHTML(lines[i:ni])})
+			i = ni - 1
+			continue
+		}
+		if len(para) == 0 && strings.Contains(ln, "|") && i+1 < n && mdTableDelimRe.MatchString(lines[i+1]) && strings.Contains(lines[i+1], "-") {
+			ni := i + 2
+			for ni < n && !mdIsBlank(lines[ni]) && strings.Contains(lines[ni], "|") {
+				ni++
+			}
+			blks = append(blks, MarkdownBlock{Kind: MarkdownTable, Text: mp.tableHTML(lines[i], lines[i+2:ni])})
+			i = ni - 1
+			continue
+		}
+		para = append(para, ln)
+	}
+	endPara()
+	return blks
+}
+
+// heading returns a heading block for given level and text
+func (mp *MarkdownParser) heading(lvl int, txt string) MarkdownBlock {
+	return MarkdownBlock{Kind: MarkdownHeading, Level: lvl, Anchor: MarkdownAnchor(txt), Text: mp.Inline(txt)}
+}
+
+// MarkdownAnchor returns the anchor for a heading with given text, as used
+// by GitHub: lower case, with spaces replaced by - and other punctuation
+// (except - and _) removed
+func MarkdownAnchor(txt string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(txt)) {
+		switch {
+		case r == ' ':
+			b.WriteRune('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// mdListItem returns the marker, content indent, and content of the line
+// if it starts a list item
+func mdListItem(ln string) (marker string, cind int, content string, ok bool) {
+	sm := mdListRe.FindStringSubmatch(ln)
+	if sm == nil {
+		return
+	}
+	marker = sm[2]
+	spc := len(sm[3])
+	if spc > 4 || sm[4] == "" { // content starts with indented code, or is empty
+		spc = 1
+	}
+	cind = len(sm[1]) + len(marker) + spc
+	content = sm[4]
+	return marker, cind, content, true
+}
+
+// mdSameList returns true if the list item markers are of the same list
+func mdSameList(m1, m2 string) bool {
+	if len(m1) == 0 || len(m2) == 0 {
+		return false
+	}
+	o1 := m1[0] >= '0' && m1[0] <= '9'
+	o2 := m2[0] >= '0' && m2[0] <= '9'
+	if o1 != o2 {
+		return false
+	}
+	if o1 {
+		return m1[len(m1)-1] == m2[len(m2)-1]
+	}
+	return m1 == m2
+}
+
+// listEnd returns the index of the line after the end of the list starting
+// at given line
+func (mp *MarkdownParser) listEnd(lines []string, st int) int {
+	marker, cind, _, _ := mdListItem(lines[st])
+	n := len(lines)
+	i := st + 1
+	for ; i < n; i++ {
+		ln := lines[i]
+		if mdIsBlank(ln) {
+			// continues if the next non-blank line is indented, or another item
+			j := i + 1
+			for j < n && mdIsBlank(lines[j]) {
+				j++
+			}
+			if j >= n {
+				return i
+			}
+			if mdIndent(lines[j]) >= cind {
+				continue
+			}
+			if m, ci, _, ok := mdListItem(lines[j]); ok && mdSameList(m, marker) && mdIndent(lines[j]) < cind {
+				cind = ci
+				i = j
+				continue
+			}
+			return i
+		}
+		if mdIndent(ln) >= cind {
+			continue
+		}
+		if m, ci, _, ok := mdListItem(ln); ok {
+			if !mdSameList(m, marker) {
+				return i
+			}
+			cind = ci
+			continue
+		}
+		if mdIsBlockStart(ln) {
+			return i
+		}
+		// lazy continuation of the item paragraph
+	}
+	return i
+}
+
+// listHTML returns the HTML for the list in given lines
+func (mp *MarkdownParser) listHTML(lines []string) string {
+	marker, _, _, _ := mdListItem(lines[0])
+	ordered := marker[0] >= '0' && marker[0] <= '9'
+	var b strings.Builder
+	if ordered {
+		start := strings.TrimLeft(marker[:len(marker)-1], "0")
+		if start != "1" && start != "" {
+			fmt.Fprintf(&b, `<ol start="%s">`, start)
+		} else if start == "" {
+			b.WriteString(`<ol start="0">`)
+		} else {
+			b.WriteString("<ol>")
+		}
+	} else {
+		b.WriteString("<ul>")
+	}
+	var item []string
+	endItem := func() {
+		if item == nil {
+			return
+		}
+		b.WriteString("<li>")
+		b.WriteString(mp.itemHTML(item))
+		b.WriteString("</li>")
+		item = nil
+	}
+	cind := 0
+	for _, ln := range lines {
+		if _, ci, content, ok := mdListItem(ln); ok && (mdIndent(ln) < cind || item == nil) {
+			endItem()
+			cind = ci
+			item = []string{content}
+			continue
+		}
+		if mdIndent(ln) >= cind {
+			item = append(item, ln[cind:])
+		} else {
+			item = append(item, strings.TrimLeft(ln, " "))
+		}
+	}
+	endItem()
+	if ordered {
+		b.WriteString("</ol>")
+	} else {
+		b.WriteString("</ul>")
+	}
+	return b.String()
+}
+
+// itemHTML returns the HTML for the content of a list item, with its
+// paragraphs separated by line breaks and nested lists included
+func (mp *MarkdownParser) itemHTML(lines []string) string {
+	task := ""
+	if len(lines) > 0 {
+		switch {
+		case strings.HasPrefix(lines[0], "[ ] "):
+			task = "[ ] "
+		case strings.HasPrefix(lines[0], "[x] "), strings.HasPrefix(lines[0], "[X] "):
+			task = "[x] "
+		}
+		if task != "" {
+			lines[0] = lines[0][4:]
+		}
+	}
+	blks := mp.parseBlocks(lines)
+	var b strings.Builder
+	b.WriteString(task)
+	for i, blk := range blks {
+		switch blk.Kind {
+		case MarkdownList:
+			b.WriteString(blk.Text)
+			continue
+		case MarkdownCode:
+			b.WriteString("<code>" + stdhtml.EscapeString(blk.Text) + "</code>")
+		case MarkdownQuote:
+			for _, cb := range blk.Children {
+				b.WriteString("<i>" + cb.Text + "</i>")
+			}
+		default:
+			b.WriteString(blk.Text)
+		}
+		if i < len(blks)-1 && blks[i+1].Kind != MarkdownList {
+			b.WriteString("<br>")
+		}
+	}
+	return b.String()
+}
+
+// mdSplitRow splits a table row into its cells
+func mdSplitRow(ln string) []string {
+	ln = strings.TrimSpace(ln)
+	ln = strings.TrimPrefix(ln, "|")
+	if strings.HasSuffix(ln, "|") && !strings.HasSuffix(ln, `\|`) {
+		ln = ln[:len(ln)-1]
+	}
+	var cells []string
+	st := 0
+	for i := 0; i < len(ln); i++ {
+		switch ln[i] {
+		case '\\':
+			i++
+		case '|':
+			cells = append(cells, strings.TrimSpace(ln[st:i]))
+			st = i + 1
+		}
+	}
+	cells = append(cells, strings.TrimSpace(ln[st:]))
+	for i, c := range cells {
+		cells[i] = strings.Replace(c, `\|`, "|", -1)
+	}
+	return cells
+}
+
+// tableHTML returns the HTML for a table with given header and body rows
+func (mp *MarkdownParser) tableHTML(hdr string, rows []string) string {
+	var b strings.Builder
+	b.WriteString("<table><tr>")
+	hcells := mdSplitRow(hdr)
+	for _, c := range hcells {
+		b.WriteString("<th>" + mp.Inline(c) + "</th>")
+	}
+	b.WriteString("</tr>")
+	for _, rw := range rows {
+		b.WriteString("<tr>")
+		cells := mdSplitRow(rw)
+		for ci := range hcells {
+			c := ""
+			if ci < len(cells) {
+				c = cells[ci]
+			}
+			b.WriteString("<td>" + mp.Inline(c) + "</td>")
+		}
+		b.WriteString("</tr>")
+	}
+	b.WriteString("</table>")
+	return b.String()
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//  Inline
+
+var (
+	mdEntityRe   = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+	mdAutoLinkRe = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9+.-]{1,31}:[^\s<>]*|[A-Za-z0-9.!#$%&'*+/=?^_{|}~-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*)>`)
+	mdHTMLTagRe  = regexp.MustCompile(`^</?[A-Za-z][A-Za-z0-9-]*(?:\s+[A-Za-z_:][A-Za-z0-9_.:-]*(?:\s*=\s*(?:[^\s"'=<>` + "`" + `]+|'[^']*'|"[^"]*"))?)*\s*/?>`)
+	mdBareURLRe  = regexp.MustCompile(`^(?:https?://|www\.)[^\s<]*[^\s<?!.,:*_~)'"]`)
+	mdLinkDestRe = regexp.MustCompile(`^\(\s*(<[^>\n]*>|[^\s()]*(?:\([^\s()]*\)[^\s()]*)*)(?:\s+("[^"]*"|'[^']*'|\([^)]*\)))?\s*\)`)
+)
+
+// mdDelim is a run of emphasis delimiters in inline text
+type mdDelim struct {
+	tok      int  // index of the token
+	ch       byte // * _ or ~
+	n        int  // remaining number of delimiters
+	canOpen  bool
+	canClose bool
+}
+
+// Inline returns the HTML for given inline Markdown text: code spans,
+// emphasis, strong emphasis, strikethrough, links, images, autolinks,
+// hard line breaks, and escapes
+func (mp *MarkdownParser) Inline(txt string) string {
+	var toks []string
+	var delims []mdDelim
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() > 0 {
+			toks = append(toks, cur.String())
+			cur.Reset()
+		}
+	}
+	n := len(txt)
+	prevWS := true // previous char is white space (or start)
+	for i := 0; i < n; {
+		c := txt[i]
+		switch {
+		case c == '\\' && i+1 < n && txt[i+1] == '\n':
+			cur.WriteString("<br>")
+			i += 2
+			prevWS = true
+			continue
+		case c == '\\' && i+1 < n && unicode.IsPunct(rune(txt[i+1])) || c == '\\' && i+1 < n && unicode.IsSymbol(rune(txt[i+1])):
+			cur.WriteString(stdhtml.EscapeString(txt[i+1 : i+2]))
+			i += 2
+			prevWS = false
+			continue
+		case c == '\n':
+			// hard break if preceded by 2+ spaces
+			str := cur.String()
+			tr := strings.TrimRight(str, " ")
+			if len(str)-len(tr) >= 2 {
+				cur.Reset()
+				cur.WriteString(tr + "<br>")
+			} else {
+				cur.Reset()
+				cur.WriteString(tr + " ")
+			}
+			i++
+			for i < n && txt[i] == ' ' {
+				i++
+			}
+			prevWS = true
+			continue
+		case c == '`':
+			run := i
+			for run < n && txt[run] == '`' {
+				run++
+			}
+			tick := txt[i:run]
+			end := mdFindTicks(txt, run, len(tick))
+			if end < 0 {
+				cur.WriteString(tick)
+				i = run
+				prevWS = false
+				continue
+			}
+			code := strings.Replace(txt[run:end], "\n", " ", -1)
+			if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+				code = code[1 : len(code)-1]
+			}
+			cur.WriteString("<code>" + stdhtml.EscapeString(code) + "</code>")
+			i = end + len(tick)
+			prevWS = false
+			continue
+		case c == '!' && i+1 < n && txt[i+1] == '[':
+			if html, ln, ok := mp.link(txt[i+1:], true); ok {
+				cur.WriteString(html)
+				i += 1 + ln
+				prevWS = false
+				continue
+			}
+		case c == '[':
+			if html, ln, ok := mp.link(txt[i:], false); ok {
+				cur.WriteString(html)
+				i += ln
+				prevWS = false
+				continue
+			}
+		case c == '<':
+			if sm := mdAutoLinkRe.FindStringSubmatch(txt[i:]); sm != nil {
+				url := sm[1]
+				href := url
+				if !strings.Contains(url, ":") {
+					href = "mailto:" + url
+				}
+				cur.WriteString(`<a href="` + stdhtml.EscapeString(href) + `">` + stdhtml.EscapeString(url) + "</a>")
+				i += len(sm[0])
+				prevWS = false
+				continue
+			}
+			if tag := mdHTMLTagRe.FindString(txt[i:]); tag != "" {
+				cur.WriteString(tag)
+				i += len(tag)
+				prevWS = false
+				continue
+			}
+		case c == '&':
+			if ent := mdEntityRe.FindString(txt[i:]); ent != "" {
+				cur.WriteString(ent)
+				i += len(ent)
+				prevWS = false
+				continue
+			}
+		case c == 'h' || c == 'w':
+			if prevWS || (i > 0 && strings.ContainsRune("(*_~", rune(txt[i-1]))) {
+				if url := mdBareURLRe.FindString(txt[i:]); url != "" {
+					href := url
+					if strings.HasPrefix(url, "www.") {
+						href = "http://" + url
+					}
+					cur.WriteString(`<a href="` + stdhtml.EscapeString(href) + `">` + stdhtml.EscapeString(url) + "</a>")
+					i += len(url)
+					prevWS = false
+					continue
+				}
+			}
+		case c == '*' || c == '_' || c == '~':
+			run := i
+			for run < n && txt[run] == c {
+				run++
+			}
+			nd := run - i
+			if c == '~' && nd != 2 {
+				break
+			}
+			nextWS := run >= n || unicode.IsSpace(rune(txt[run]))
+			nextP := run < n && unicode.IsPunct(rune(txt[run]))
+			prevP := i > 0 && unicode.IsPunct(rune(txt[i-1]))
+			left := !nextWS && (!nextP || prevWS || prevP)
+			right := !prevWS && (!prevP || nextWS || nextP)
+			dl := mdDelim{ch: c, n: nd, canOpen: left, canClose: right}
+			if c == '_' {
+				dl.canOpen = left && (!right || prevP)
+				dl.canClose = right && (!left || nextP)
+			}
+			flush()
+			dl.tok = len(toks)
+			toks = append(toks, txt[i:run])
+			delims = append(delims, dl)
+			i = run
+			prevWS = false
+			continue
+		}
+		switch c {
+		case '<':
+			cur.WriteString("&lt;")
+		case '>':
+			cur.WriteString("&gt;")
+		case '&':
+			cur.WriteString("&amp;")
+		case '"':
+			cur.WriteString("&quot;")
+		default:
+			cur.WriteByte(c)
+		}
+		prevWS = c == ' ' || c == '\t'
+		i++
+	}
+	flush()
+	mdEmphasis(toks, delims)
+	str := strings.Join(toks, "")
+	return strings.TrimSuffix(str, "<br>")
+}
+
+// mdFindTicks returns the index of the next run of exactly n backticks in
+// txt from st, or -1 if none
+func mdFindTicks(txt string, st, n int) int {
+	for i := st; i < len(txt); {
+		if txt[i] != '`' {
+			i++
+			continue
+		}
+		run := i
+		for run < len(txt) && txt[run] == '`' {
+			run++
+		}
+		if run-i == n {
+			return i
+		}
+		i = run
+	}
+	return -1
+}
+
+// mdEmphasis matches the emphasis delimiter runs in given tokens, replacing
+// them with the corresponding HTML tags -- unmatched delimiters are left
+// as literal text
+func mdEmphasis(toks []string, delims []mdDelim) {
+	opens := make([]string, len(toks))
+	closes := make([]string, len(toks))
+	for ci := range delims {
+		cl := &delims[ci]
+		if !cl.canClose {
+			continue
+		}
+		for oi := ci - 1; oi >= 0 && cl.n > 0; oi-- {
+			op := &delims[oi]
+			if op.ch != cl.ch || !op.canOpen || op.n == 0 {
+				continue
+			}
+			if (op.canClose || cl.canOpen) && (op.n+cl.n)%3 == 0 && (op.n%3 != 0 || cl.n%3 != 0) && op.ch != '~' {
+				continue // "rule of 3"
+			}
+			for op.n > 0 && cl.n > 0 {
+				tag := "em"
+				use := 1
+				switch {
+				case op.ch == '~':
+					tag = "del"
+					use = 2
+				case op.n >= 2 && cl.n >= 2:
+					tag = "strong"
+					use = 2
+				}
+				opens[op.tok] = opens[op.tok] + "<" + tag + ">"
+				closes[cl.tok] = "</" + tag + ">" + closes[cl.tok]
+				op.n -= use
+				cl.n -= use
+			}
+			// delimiters between the matched pair can no longer match
+			for bi := oi + 1; bi < ci; bi++ {
+				delims[bi].canOpen = false
+				delims[bi].canClose = false
+			}
+		}
+	}
+	for _, dl := range delims {
+		lit := strings.Repeat(string(dl.ch), dl.n)
+		// closing tags go before leftover literal delimiters of a closer, and
+		// opening tags after those of an opener
+		toks[dl.tok] = closes[dl.tok] + lit + opens[dl.tok]
+	}
+}
+
+// link parses a link or image at the start of given text, which starts
+// with [ -- returns the HTML, the length of the text consumed, and true if
+// it is a valid link (inline, full, collapsed or shortcut reference)
+func (mp *MarkdownParser) link(txt string, image bool) (string, int, bool) {
+	depth := 0
+	end := -1
+	for i := 0; i < len(txt); i++ {
+		switch txt[i] {
+		case '\\':
+			i++
+		case '`':
+			if e := mdFindTicks(txt, i+1+strings.IndexFunc(txt[i:], func(r rune) bool { return r != '`' })-1, 1); e < 0 {
+				continue
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				end = i
+			}
+		}
+		if end >= 0 {
+			break
+		}
+	}
+	if end < 0 {
+		return "", 0, false
+	}
+	lbl := txt[1:end]
+	rest := txt[end+1:]
+	dest := ""
+	ln := end + 1
+	found := false
+	if sm := mdLinkDestRe.FindStringSubmatch(rest); sm != nil {
+		dest = strings.TrimSuffix(strings.TrimPrefix(sm[1], "<"), ">")
+		ln += len(sm[0])
+		found = true
+	} else {
+		ref := lbl
+		if strings.HasPrefix(rest, "[]") {
+			ln += 2
+		} else if strings.HasPrefix(rest, "[") {
+			if re := strings.Index(rest, "]"); re > 0 {
+				ref = rest[1:re]
+				ln += re + 1
+			}
+		}
+		dest, found = mp.Refs[strings.ToLower(strings.Join(strings.Fields(ref), " "))]
+		if !found {
+			return "", 0, false
+		}
+	}
+	if !found {
+		return "", 0, false
+	}
+	dest = mdUnescape(dest)
+	if image {
+		src := dest
+		if mp.BaseDir != "" && !strings.Contains(src, "://") && !filepath.IsAbs(src) {
+			src = filepath.Join(mp.BaseDir, src)
+		}
+		alt := mdPlainText(lbl)
+		return `<img src="` + stdhtml.EscapeString(src) + `" alt="` + stdhtml.EscapeString(alt) + `">`, ln, true
+	}
+	return `<a href="` + stdhtml.EscapeString(dest) + `">` + mp.Inline(lbl) + "</a>", ln, true
+}
+
+// mdUnescape removes backslash escapes from given text
+func mdUnescape(str string) string {
+	if !strings.Contains(str, `\`) {
+		return str
+	}
+	var b strings.Builder
+	for i := 0; i < len(str); i++ {
+		if str[i] == '\\' && i+1 < len(str) && unicode.IsPunct(rune(str[i+1])) {
+			i++
+		}
+		b.WriteByte(str[i])
+	}
+	return b.String()
+}
+
+// mdPlainText returns the text of given inline Markdown without the
+// markup, e.g., for image alt text
+func mdPlainText(str string) string {
+	str = mdUnescape(str)
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '*', '_', '`', '[', ']':
+			return -1
+		}
+		return r
+	}, str)
+}
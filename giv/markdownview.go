@@ -0,0 +1,233 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/girl"
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/histyle"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ints"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+	"github.com/goki/pi/token"
+)
+
+// MarkdownView displays Markdown text (CommonMark, plus GitHub tables,
+// strikethrough and task lists), e.g., for in-app help or README panes.
+// Each block is rendered by a gi.Label using its rich text layout, with
+// fenced code blocks syntax-highlighted according to their language.
+// Links to #anchor scroll to the heading with that anchor -- other links
+// are sent on LinkSig if anyone is connected, else relative links to
+// Markdown files are opened in the view, and the rest are passed to the
+// girl.TextLinkHandler and girl.URLHandler as for gi.Label.
+type MarkdownView struct {
+	gi.Frame
+	Markdown string          `desc:"the Markdown text being displayed"`
+	Filename gi.FileName     `desc:"file that the Markdown was opened from, if any"`
+	BaseDir  string          `desc:"directory for relative links and image paths -- set to the directory of the file when opened"`
+	Blocks   []MarkdownBlock `json:"-" xml:"-" desc:"the parsed blocks of the Markdown"`
+	LinkSig  ki.Signal       `copy:"-" json:"-" xml:"-" view:"-" desc:"signal for clicking on a link, other than to an #anchor within the view -- sends gi.LabelLinkClicked with data of a string of the URL"`
+}
+
+var KiT_MarkdownView = kit.Types.AddType(&MarkdownView{}, MarkdownViewProps)
+
+// AddNewMarkdownView adds a new markdownview to given parent node, with given name.
+func AddNewMarkdownView(parent ki.Ki, name string) *MarkdownView {
+	return parent.AddNewChild(KiT_MarkdownView, name).(*MarkdownView)
+}
+
+func (mv *MarkdownView) Disconnect() {
+	mv.Frame.Disconnect()
+	mv.LinkSig.DisconnectAll()
+}
+
+var MarkdownViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"padding":          units.NewPx(8),
+	"spacing":          units.NewEm(0.5),
+	"max-width":        -1,
+	"max-height":       -1,
+	"overflow":         gist.OverflowAuto,
+}
+
+// MarkdownHeadingSizes are the font sizes of headings, by level
+var MarkdownHeadingSizes = []string{"xx-large", "x-large", "large", "medium", "medium", "small"}
+
+// SetMarkdown sets the Markdown text to display, and rebuilds the view
+func (mv *MarkdownView) SetMarkdown(md string) {
+	updt := mv.UpdateStart()
+	mv.Markdown = md
+	mv.Config()
+	mv.UpdateEnd(updt)
+}
+
+// OpenMarkdown opens the Markdown file and displays it, with relative links
+// and image paths relative to its directory
+func (mv *MarkdownView) OpenMarkdown(filename gi.FileName) error {
+	b, err := ioutil.ReadFile(string(filename))
+	if err != nil {
+		return err
+	}
+	mv.Filename = filename
+	mv.BaseDir = filepath.Dir(string(filename))
+	mv.SetMarkdown(string(b))
+	return nil
+}
+
+// Config parses the Markdown and configures the view to display its blocks
+func (mv *MarkdownView) Config() {
+	mv.Lay = gi.LayoutVert
+	// setting a pref here is key for giving it a scrollbar in larger context
+	mv.SetMinPrefHeight(units.NewEm(10))
+	mv.SetMinPrefWidth(units.NewCh(40))
+	mv.Blocks = ParseMarkdown(mv.Markdown, mv.BaseDir)
+	mv.DeleteChildren(ki.DestroyKids)
+	mv.ConfigBlocks(mv.This().(gi.Node2D), mv.Blocks)
+	mv.SetFullReRender()
+}
+
+// ConfigBlocks adds the widgets for given blocks to given parent
+func (mv *MarkdownView) ConfigBlocks(par gi.Node2D, blks []MarkdownBlock) {
+	for bi := range blks {
+		blk := &blks[bi]
+		nm := fmt.Sprintf("b%d", bi)
+		switch blk.Kind {
+		case MarkdownRule:
+			gi.AddNewSeparator(par, nm, true)
+		case MarkdownQuote:
+			fr := gi.AddNewFrame(par, nm, gi.LayoutVert)
+			fr.SetProp("border-width", units.NewPx(0))
+			fr.SetProp("background-color", &gi.Prefs.Colors.Control)
+			fr.SetProp("padding", units.NewEm(0.5))
+			fr.SetProp("margin", units.NewEm(0.25))
+			fr.SetProp("spacing", units.NewEm(0.5))
+			fr.SetProp("font-style", gist.FontItalic)
+			fr.SetStretchMaxWidth()
+			mv.ConfigBlocks(fr, blk.Children)
+		case MarkdownCode:
+			lb := mv.AddLabel(par, nm, CodeMarkup(blk.Text, blk.Lang))
+			lb.SetProp("white-space", gist.WhiteSpacePre)
+			lb.SetProp("font-family", gi.Prefs.MonoFont)
+			lb.SetProp("padding", units.NewEm(0.5))
+			lb.SetProp("background-color", &gi.Prefs.Colors.Control)
+			if hs := histyle.AvailStyle(histyle.StyleDefault); hs != nil {
+				if bg := hs.TagRaw(token.Background).Background; !bg.IsNil() {
+					lb.SetProp("background-color", bg)
+				}
+			}
+		case MarkdownHeading:
+			lb := mv.AddLabel(par, nm, blk.Text)
+			lvl := ints.MinInt(ints.MaxInt(blk.Level, 1), len(MarkdownHeadingSizes))
+			lb.SetProp("font-size", MarkdownHeadingSizes[lvl-1])
+			lb.SetProp("font-weight", gist.WeightBold)
+		default:
+			mv.AddLabel(par, nm, blk.Text)
+		}
+	}
+}
+
+// AddLabel adds a word-wrapping label with given HTML text to given parent,
+// with its links connected to OpenLink
+func (mv *MarkdownView) AddLabel(par gi.Node2D, nm string, html string) *gi.Label {
+	lb := gi.AddNewLabel(par, nm, html)
+	lb.SetProp("white-space", gist.WhiteSpaceNormal)
+	lb.SetProp("width", units.NewCh(30)) // need for wrap
+	lb.SetStretchMaxWidth()
+	lb.LinkSig.Connect(mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		mvv := recv.Embed(KiT_MarkdownView).(*MarkdownView)
+		mvv.OpenLink(data.(string))
+	})
+	return lb
+}
+
+// HeadingLabel returns the label of the heading with given anchor, or nil
+// if none
+func (mv *MarkdownView) HeadingLabel(anchor string) *gi.Label {
+	var fnd *gi.Label
+	var find func(par ki.Ki, blks []MarkdownBlock)
+	find = func(par ki.Ki, blks []MarkdownBlock) {
+		for bi := range blks {
+			if fnd != nil || bi >= par.NumChildren() {
+				return
+			}
+			blk := &blks[bi]
+			switch {
+			case blk.Kind == MarkdownHeading && blk.Anchor == anchor:
+				fnd, _ = par.Child(bi).(*gi.Label)
+			case blk.Kind == MarkdownQuote:
+				find(par.Child(bi), blk.Children)
+			}
+		}
+	}
+	find(mv.This(), mv.Blocks)
+	return fnd
+}
+
+// ScrollToAnchor scrolls the view so that the heading with given anchor
+// (e.g., from a #anchor link) is at the top -- returns false if not found
+func (mv *MarkdownView) ScrollToAnchor(anchor string) bool {
+	lb := mv.HeadingLabel(strings.TrimPrefix(anchor, "#"))
+	if lb == nil {
+		return false
+	}
+	if !mv.HasScroll[mat32.Y] {
+		return true
+	}
+	pos := float32(lb.WinBBox.Min.Y-mv.WinBBox.Min.Y) + mv.Scrolls[mat32.Y].Value
+	pos -= mv.BoxSpace()
+	mv.ScrollToPos(mat32.Y, pos)
+	return true
+}
+
+// OpenLink handles a link clicked in the view: #anchor links scroll to the
+// heading, and others are sent on LinkSig if anyone is connected, else
+// relative links to Markdown files are opened in the view, and the rest
+// are passed to girl.TextLinkHandler and girl.URLHandler
+func (mv *MarkdownView) OpenLink(url string) {
+	if strings.HasPrefix(url, "#") {
+		mv.ScrollToAnchor(url)
+		return
+	}
+	if len(mv.LinkSig.Cons) > 0 {
+		mv.LinkSig.Emit(mv.This(), int64(gi.LabelLinkClicked), url)
+		return
+	}
+	if !strings.Contains(url, ":") {
+		fn, anchor := url, ""
+		if hi := strings.Index(url, "#"); hi >= 0 {
+			fn, anchor = url[:hi], url[hi:]
+		}
+		ext := strings.ToLower(filepath.Ext(fn))
+		if ext == ".md" || ext == ".markdown" {
+			if !filepath.IsAbs(fn) {
+				fn = filepath.Join(mv.BaseDir, fn)
+			}
+			if err := mv.OpenMarkdown(gi.FileName(fn)); err == nil {
+				if anchor != "" {
+					mv.ScrollToAnchor(anchor)
+				}
+				return
+			}
+		}
+	}
+	if girl.TextLinkHandler != nil {
+		if girl.TextLinkHandler(girl.TextLink{URL: url, Widget: mv.This()}) {
+			return
+		}
+	}
+	if girl.URLHandler != nil {
+		girl.URLHandler(url)
+	}
+}
@@ -0,0 +1,1013 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/girl"
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+)
+
+// PlotTypes are the ways of plotting a PlotSeries
+type PlotTypes int32
+
+const (
+	// PlotLine draws lines between successive points
+	PlotLine PlotTypes = iota
+
+	// PlotScatter draws a circle at each point
+	PlotScatter
+
+	// PlotBar draws a bar from zero to each point -- multiple bar series
+	// are drawn side by side
+	PlotBar
+
+	// PlotHeatmap draws a column of colored cells for each element, from
+	// a slice of numbers in each element (e.g., a [][]float64), colored
+	// according to the ColorMap of the Plot
+	PlotHeatmap
+
+	PlotTypesN
+)
+
+//go:generate stringer -type=PlotTypes
+
+var KiT_PlotTypes = kit.Enums.AddEnumAltLower(PlotTypesN, kit.NotBitFlag, nil, "Plot")
+
+func (ev PlotTypes) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *PlotTypes) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// PlotColors are the colors used for series that do not set their own
+// Color, in order
+var PlotColors = []gist.Color{
+	{R: 31, G: 119, B: 180, A: 255},
+	{R: 255, G: 127, B: 14, A: 255},
+	{R: 44, G: 160, B: 44, A: 255},
+	{R: 214, G: 39, B: 40, A: 255},
+	{R: 148, G: 103, B: 189, A: 255},
+	{R: 140, G: 86, B: 75, A: 255},
+	{R: 227, G: 119, B: 194, A: 255},
+	{R: 127, G: 127, B: 127, A: 255},
+	{R: 188, G: 189, B: 34, A: 255},
+	{R: 23, G: 190, B: 207, A: 255},
+}
+
+// PlotFileFg and PlotFileBg are the foreground (text and axes) and
+// background colors of plots saved to files
+var (
+	PlotFileFg = gist.Color{A: 255}
+	PlotFileBg = gist.Color{R: 255, G: 255, B: 255, A: 255}
+)
+
+// PlotSeries is one series of values in a Plot, from a field of the data
+// elements
+type PlotSeries struct {
+	Field  string     `desc:"name of the field of the struct data elements holding the values -- empty for elements that are themselves numbers, or slices of numbers for a heatmap"`
+	Label  string     `desc:"label for the legend -- the Field name if empty"`
+	Type   PlotTypes  `desc:"how to plot the values"`
+	Color  gist.Color `desc:"color of the series -- the PlotColors are used in order if not set"`
+	Width  float32    `desc:"width of lines, and radius of scatter points, in dots -- 2 if 0"`
+	Hidden bool       `desc:"do not plot this series -- toggled by clicking on it in the legend"`
+}
+
+// Name returns the Label, or the Field if not set
+func (ps *PlotSeries) Name() string {
+	switch {
+	case ps.Label != "":
+		return ps.Label
+	case ps.Field != "":
+		return ps.Field
+	}
+	return "Value"
+}
+
+// PlotRange is a range of values on an axis of a Plot
+type PlotRange struct {
+	Min float64 `desc:"minimum value"`
+	Max float64 `desc:"maximum value"`
+}
+
+// Range returns Max - Min
+func (pr *PlotRange) Range() float64 {
+	return pr.Max - pr.Min
+}
+
+// Reset sets the range to be empty, for including values
+func (pr *PlotRange) Reset() {
+	pr.Min = math.Inf(1)
+	pr.Max = math.Inf(-1)
+}
+
+// IsValid returns true if the range includes at least one value
+func (pr *PlotRange) IsValid() bool {
+	return pr.Min <= pr.Max
+}
+
+// Include extends the range to include given value -- NaN and Inf are
+// ignored
+func (pr *PlotRange) Include(val float64) {
+	if math.IsNaN(val) || math.IsInf(val, 0) {
+		return
+	}
+	pr.Min = math.Min(pr.Min, val)
+	pr.Max = math.Max(pr.Max, val)
+}
+
+// Zoom scales the range by given factor (< 1 zooms in) about given value
+func (pr *PlotRange) Zoom(factor, about float64) {
+	pr.Min = about + (pr.Min-about)*factor
+	pr.Max = about + (pr.Max-about)*factor
+}
+
+// PlotTicks returns nicely-rounded tick values (multiples of 1, 2 or 5 times
+// a power of 10) within given range, with about n ticks
+func PlotTicks(min, max float64, n int) []float64 {
+	rng := max - min
+	if !(rng > 0) || n < 1 || math.IsInf(rng, 0) {
+		return nil
+	}
+	raw := rng / float64(n)
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := mag
+	switch {
+	case raw/mag >= 5:
+		step = 10 * mag
+	case raw/mag >= 2:
+		step = 5 * mag
+	case raw/mag >= 1.5:
+		step = 2 * mag
+	}
+	var ticks []float64
+	for t := math.Ceil(min/step) * step; t <= max+step*1e-9; t += step {
+		if math.Abs(t) < step*1e-9 {
+			t = 0
+		}
+		ticks = append(ticks, t)
+	}
+	return ticks
+}
+
+// PlotTickLabel returns the label for given tick value
+func PlotTickLabel(val float64) string {
+	return strconv.FormatFloat(val, 'g', 6, 64)
+}
+
+// Plot is a chart of line, scatter, bar and heatmap series of data, bound
+// by reflection to a slice of structs (each series plotting a field of the
+// structs, by name, as in TableView), a slice of numbers, a slice of slices
+// of numbers (for a heatmap), or a TableSource.  It is rendered through a
+// PlotPainter, either to a PlotView widget, or to PNG and SVG files.
+type Plot struct {
+	Title       string          `desc:"title shown at the top of the plot"`
+	XLabel      string          `desc:"label of the X axis -- the XField if empty"`
+	YLabel      string          `desc:"label of the Y axis"`
+	XField      string          `desc:"name of the field of the struct data elements for the X values -- the index of each element is used if empty"`
+	Series      []PlotSeries    `desc:"the series of values to plot -- set to all the numerical fields of the data elements by SetData if empty"`
+	NoLegend    bool            `desc:"do not show the legend"`
+	ColorMap    ColorMapName    `desc:"color map for heatmap series -- ColdHot if empty"`
+	XRange      PlotRange       `desc:"the visible range of X values -- set to fit the data by FitData, and changed by zooming and panning"`
+	YRange      PlotRange       `desc:"the visible range of Y values -- set to fit the data by FitData, and changed by zooming and panning"`
+	Data        interface{}     `json:"-" xml:"-" view:"-" desc:"the data being plotted -- see SetData"`
+	AreaPos     mat32.Vec2      `json:"-" xml:"-" view:"-" desc:"position of the data area (inside the axes) when last rendered"`
+	AreaSize    mat32.Vec2      `json:"-" xml:"-" view:"-" desc:"size of the data area (inside the axes) when last rendered"`
+	LegendBoxes []PlotLegendBox `json:"-" xml:"-" view:"-" desc:"boxes of the legend entries when last rendered, for toggling series"`
+}
+
+// PlotLegendBox is the box of a legend entry, for the series of given index
+type PlotLegendBox struct {
+	Series int
+	Pos    mat32.Vec2
+	Size   mat32.Vec2
+}
+
+// SetData sets the data to plot (see Plot for the kinds of data), sets the
+// Series to all of its numerical fields if none have been set, and fits the
+// visible ranges to the data
+func (pl *Plot) SetData(data interface{}) {
+	pl.Data = data
+	if len(pl.Series) == 0 {
+		pl.AutoSeries()
+	}
+	pl.FitData()
+}
+
+// plotNonPtr returns the value underlying any pointers and interfaces
+func plotNonPtr(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// plotNumKind returns true if given kind is a number
+func plotNumKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64 && k != reflect.Uintptr
+}
+
+// ElemType returns the (non-pointer) type of the data elements, or nil
+func (pl *Plot) ElemType() reflect.Type {
+	if kit.IfaceIsNil(pl.Data) {
+		return nil
+	}
+	if src, ok := pl.Data.(TableSource); ok {
+		return src.RowType()
+	}
+	typ := kit.NonPtrType(reflect.TypeOf(pl.Data))
+	if typ.Kind() != reflect.Slice && typ.Kind() != reflect.Array {
+		return nil
+	}
+	return kit.NonPtrType(typ.Elem())
+}
+
+// AutoSeries sets the Series to a line for each numerical field of the data
+// elements (other than the XField), and a heatmap for each slice of numbers
+func (pl *Plot) AutoSeries() {
+	pl.Series = nil
+	typ := pl.ElemType()
+	if typ == nil {
+		return
+	}
+	isSlice := func(t reflect.Type) bool {
+		t = kit.NonPtrType(t)
+		return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && plotNumKind(kit.NonPtrType(t.Elem()).Kind())
+	}
+	switch {
+	case plotNumKind(typ.Kind()):
+		pl.Series = append(pl.Series, PlotSeries{})
+	case isSlice(typ):
+		pl.Series = append(pl.Series, PlotSeries{Type: PlotHeatmap})
+	case typ.Kind() == reflect.Struct:
+		for fi := 0; fi < typ.NumField(); fi++ {
+			fld := typ.Field(fi)
+			if fld.PkgPath != "" || fld.Name == pl.XField || fld.Tag.Get("view") == "-" {
+				continue
+			}
+			switch {
+			case plotNumKind(kit.NonPtrType(fld.Type).Kind()):
+				pl.Series = append(pl.Series, PlotSeries{Field: fld.Name})
+			case isSlice(fld.Type):
+				pl.Series = append(pl.Series, PlotSeries{Field: fld.Name, Type: PlotHeatmap})
+			}
+		}
+	}
+}
+
+// NumElems returns the number of data elements
+func (pl *Plot) NumElems() int {
+	if kit.IfaceIsNil(pl.Data) {
+		return 0
+	}
+	if src, ok := pl.Data.(TableSource); ok {
+		return src.NumRows()
+	}
+	sv := plotNonPtr(reflect.ValueOf(pl.Data))
+	if sv.Kind() != reflect.Slice && sv.Kind() != reflect.Array {
+		return 0
+	}
+	return sv.Len()
+}
+
+// Elem returns the (non-pointer) value of the data element at given index
+// -- invalid if not available
+func (pl *Plot) Elem(idx int) reflect.Value {
+	if src, ok := pl.Data.(TableSource); ok {
+		return plotNonPtr(reflect.ValueOf(src.Row(idx)))
+	}
+	sv := plotNonPtr(reflect.ValueOf(pl.Data))
+	return plotNonPtr(sv.Index(idx))
+}
+
+// FieldVal returns the value of given field of given element, or the
+// element itself if field is empty
+func (pl *Plot) FieldVal(el reflect.Value, field string) reflect.Value {
+	if field == "" || !el.IsValid() {
+		return el
+	}
+	if el.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	return plotNonPtr(el.FieldByName(field))
+}
+
+// plotFloat returns the value as a float64, or NaN if not a number
+func plotFloat(v reflect.Value) float64 {
+	if !v.IsValid() || !plotNumKind(v.Kind()) {
+		return math.NaN()
+	}
+	f, _ := kit.ToFloat(v.Interface())
+	return f
+}
+
+// XVal returns the X value of the element at given index
+func (pl *Plot) XVal(idx int) float64 {
+	if pl.XField == "" {
+		return float64(idx)
+	}
+	return plotFloat(pl.FieldVal(pl.Elem(idx), pl.XField))
+}
+
+// Values returns the X and Y values of the series at given index, with
+// NaN for values that are missing
+func (pl *Plot) Values(si int) (xs, ys []float64) {
+	ser := &pl.Series[si]
+	n := pl.NumElems()
+	xs = make([]float64, n)
+	ys = make([]float64, n)
+	for i := 0; i < n; i++ {
+		el := pl.Elem(i)
+		xs[i] = pl.XVal(i)
+		ys[i] = plotFloat(pl.FieldVal(el, ser.Field))
+	}
+	return
+}
+
+// HeatVals returns the slice of values of the heatmap series at given
+// index, for the element at given index
+func (pl *Plot) HeatVals(si, idx int) []float64 {
+	sv := pl.FieldVal(pl.Elem(idx), pl.Series[si].Field)
+	if sv.Kind() != reflect.Slice && sv.Kind() != reflect.Array {
+		return nil
+	}
+	vals := make([]float64, sv.Len())
+	for i := range vals {
+		vals[i] = plotFloat(plotNonPtr(sv.Index(i)))
+	}
+	return vals
+}
+
+// DataRanges returns the ranges of the X and Y values of the visible series
+// -- bars include zero and heatmaps cover the cells, with X padded by half
+// the spacing of the elements for bars and heatmaps
+func (pl *Plot) DataRanges() (xr, yr PlotRange) {
+	xr.Reset()
+	yr.Reset()
+	pad := false
+	n := pl.NumElems()
+	for si := range pl.Series {
+		ser := &pl.Series[si]
+		if ser.Hidden {
+			continue
+		}
+		if ser.Type == PlotHeatmap {
+			pad = true
+			for i := 0; i < n; i++ {
+				xr.Include(pl.XVal(i))
+				if nv := len(pl.HeatVals(si, i)); nv > 0 {
+					yr.Include(-0.5)
+					yr.Include(float64(nv) - 0.5)
+				}
+			}
+			continue
+		}
+		xs, ys := pl.Values(si)
+		for i := range xs {
+			if math.IsNaN(ys[i]) {
+				continue
+			}
+			xr.Include(xs[i])
+			yr.Include(ys[i])
+		}
+		if ser.Type == PlotBar {
+			pad = true
+			yr.Include(0)
+		}
+	}
+	if pad && xr.IsValid() {
+		sp := pl.XSpacing() / 2
+		xr.Min -= sp
+		xr.Max += sp
+	}
+	return
+}
+
+// XSpacing returns the minimum spacing between the X values of the
+// elements, e.g., for the width of bars -- 1 if there is only one
+func (pl *Plot) XSpacing() float64 {
+	n := pl.NumElems()
+	xs := make([]float64, 0, n)
+	for i := 0; i < n; i++ {
+		if x := pl.XVal(i); !math.IsNaN(x) {
+			xs = append(xs, x)
+		}
+	}
+	sort.Float64s(xs)
+	sp := math.Inf(1)
+	for i := 1; i < len(xs); i++ {
+		if d := xs[i] - xs[i-1]; d > 0 && d < sp {
+			sp = d
+		}
+	}
+	if math.IsInf(sp, 1) {
+		return 1
+	}
+	return sp
+}
+
+// HasType returns true if any visible series is of given type
+func (pl *Plot) HasType(typ PlotTypes) bool {
+	for si := range pl.Series {
+		if !pl.Series[si].Hidden && pl.Series[si].Type == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// FitData sets the visible XRange and YRange to fit the data, with a
+// margin of 5% in Y unless there is a heatmap
+func (pl *Plot) FitData() {
+	xr, yr := pl.DataRanges()
+	if !xr.IsValid() {
+		xr = PlotRange{0, 1}
+	}
+	if !yr.IsValid() {
+		yr = PlotRange{0, 1}
+	}
+	if xr.Range() == 0 {
+		xr.Min--
+		xr.Max++
+	}
+	if yr.Range() == 0 {
+		yr.Min--
+		yr.Max++
+	}
+	if !pl.HasType(PlotHeatmap) {
+		marg := 0.05 * yr.Range()
+		if yr.Min != 0 {
+			yr.Min -= marg
+		}
+		if yr.Max != 0 {
+			yr.Max += marg
+		}
+	}
+	pl.XRange = xr
+	pl.YRange = yr
+}
+
+// DataToPos returns the position, in the last rendered data area, of given
+// data values
+func (pl *Plot) DataToPos(x, y float64) mat32.Vec2 {
+	fx := (x - pl.XRange.Min) / pl.XRange.Range()
+	fy := (y - pl.YRange.Min) / pl.YRange.Range()
+	return mat32.NewVec2(pl.AreaPos.X+float32(fx)*pl.AreaSize.X, pl.AreaPos.Y+(1-float32(fy))*pl.AreaSize.Y)
+}
+
+// PosToData returns the data values at given position in the last rendered
+// data area
+func (pl *Plot) PosToData(pos mat32.Vec2) (x, y float64) {
+	if pl.AreaSize.X <= 0 || pl.AreaSize.Y <= 0 {
+		return pl.XRange.Min, pl.YRange.Min
+	}
+	fx := float64((pos.X - pl.AreaPos.X) / pl.AreaSize.X)
+	fy := 1 - float64((pos.Y-pl.AreaPos.Y)/pl.AreaSize.Y)
+	return pl.XRange.Min + fx*pl.XRange.Range(), pl.YRange.Min + fy*pl.YRange.Range()
+}
+
+// InArea returns true if given position is within the last rendered data
+// area
+func (pl *Plot) InArea(pos mat32.Vec2) bool {
+	return pos.X >= pl.AreaPos.X && pos.Y >= pl.AreaPos.Y && pos.X <= pl.AreaPos.X+pl.AreaSize.X && pos.Y <= pl.AreaPos.Y+pl.AreaSize.Y
+}
+
+// LegendAt returns the index of the series whose legend entry is at given
+// position when last rendered, or -1 if none
+func (pl *Plot) LegendAt(pos mat32.Vec2) int {
+	for _, lb := range pl.LegendBoxes {
+		if pos.X >= lb.Pos.X && pos.Y >= lb.Pos.Y && pos.X <= lb.Pos.X+lb.Size.X && pos.Y <= lb.Pos.Y+lb.Size.Y {
+			return lb.Series
+		}
+	}
+	return -1
+}
+
+// SeriesColor returns the color of the series at given index
+func (pl *Plot) SeriesColor(si int) gist.Color {
+	if clr := pl.Series[si].Color; !clr.IsNil() {
+		return clr
+	}
+	return PlotColors[si%len(PlotColors)]
+}
+
+// CurColorMap returns the color map for heatmaps
+func (pl *Plot) CurColorMap() *ColorMap {
+	if cm, ok := AvailColorMaps[string(pl.ColorMap)]; ok {
+		return cm
+	}
+	return StdColorMaps["ColdHot"]
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//  Rendering
+
+// PlotPainter is the drawing interface that a Plot is rendered through, in
+// dots -- PlotGirl renders to a girl.State (for the PlotView and PNG files),
+// and PlotSVG writes SVG
+type PlotPainter interface {
+	// Line draws a line through given points
+	Line(pts []mat32.Vec2, clr gist.Color, width float32)
+
+	// Box fills a box
+	Box(pos, sz mat32.Vec2, clr gist.Color)
+
+	// Circle fills a circle
+	Circle(ctr mat32.Vec2, r float32, clr gist.Color)
+
+	// Text draws text with its top-left at given position
+	Text(str string, pos mat32.Vec2, clr gist.Color)
+
+	// TextSize returns the size of given text
+	TextSize(str string) mat32.Vec2
+
+	// Clip restricts drawing to given box, until Unclip
+	Clip(pos, sz mat32.Vec2)
+
+	// Unclip ends the Clip
+	Unclip()
+}
+
+// Render renders the plot in given box, with given foreground color for
+// the text and axes, and background color
+func (pl *Plot) Render(pp PlotPainter, pos, sz mat32.Vec2, fg, bg gist.Color) {
+	pp.Box(pos, sz, bg)
+	pad := mat32.Max(pp.TextSize("M").X/2, 2)
+	th := pp.TextSize("0").Y
+	nyt := int(mat32.Max(sz.Y/(3*th), 2))
+	yticks := PlotTicks(pl.YRange.Min, pl.YRange.Max, nyt)
+	ytw := float32(0)
+	for _, t := range yticks {
+		ytw = mat32.Max(ytw, pp.TextSize(PlotTickLabel(t)).X)
+	}
+	top := pos.Y + pad
+	if pl.Title != "" {
+		tsz := pp.TextSize(pl.Title)
+		pp.Text(pl.Title, mat32.NewVec2(pos.X+(sz.X-tsz.X)/2, top), fg)
+		top += tsz.Y + pad
+	}
+	if pl.YLabel != "" {
+		pp.Text(pl.YLabel, mat32.NewVec2(pos.X+pad, top), fg)
+		top += th + pad
+	}
+	top += th / 2 // room for top tick label
+	xlab := pl.XLabel
+	if xlab == "" {
+		xlab = pl.XField
+	}
+	bot := pos.Y + sz.Y - pad - th - pad
+	if xlab != "" {
+		bot -= th + pad
+	}
+	left := pos.X + pad + ytw + pad
+	right := pos.X + sz.X - pad - pp.TextSize("0000").X/2
+	pl.AreaPos = mat32.NewVec2(left, top)
+	pl.AreaSize = mat32.NewVec2(mat32.Max(right-left, 1), mat32.Max(bot-top, 1))
+	pl.LegendBoxes = nil
+	if pl.XRange.Range() <= 0 || pl.YRange.Range() <= 0 {
+		return
+	}
+	ap, as := pl.AreaPos, pl.AreaSize
+
+	// grid and tick labels
+	grid := fg.Clearer(85)
+	for _, t := range yticks {
+		y := pl.DataToPos(0, t).Y
+		pp.Line([]mat32.Vec2{mat32.NewVec2(ap.X, y), mat32.NewVec2(ap.X+as.X, y)}, grid, 1)
+		lb := PlotTickLabel(t)
+		lsz := pp.TextSize(lb)
+		pp.Text(lb, mat32.NewVec2(ap.X-pad-lsz.X, y-lsz.Y/2), fg)
+	}
+	nxt := int(mat32.Max(as.X/(6*pp.TextSize("0").X), 2))
+	for _, t := range PlotTicks(pl.XRange.Min, pl.XRange.Max, nxt) {
+		x := pl.DataToPos(t, 0).X
+		pp.Line([]mat32.Vec2{mat32.NewVec2(x, ap.Y), mat32.NewVec2(x, ap.Y+as.Y)}, grid, 1)
+		lb := PlotTickLabel(t)
+		lsz := pp.TextSize(lb)
+		pp.Text(lb, mat32.NewVec2(x-lsz.X/2, ap.Y+as.Y+pad), fg)
+	}
+	if xlab != "" {
+		lsz := pp.TextSize(xlab)
+		pp.Text(xlab, mat32.NewVec2(ap.X+(as.X-lsz.X)/2, ap.Y+as.Y+pad+th+pad), fg)
+	}
+
+	pp.Clip(ap, as)
+	pl.RenderSeries(pp)
+	pp.Unclip()
+
+	// axes
+	pp.Line([]mat32.Vec2{ap, mat32.NewVec2(ap.X, ap.Y+as.Y), mat32.NewVec2(ap.X+as.X, ap.Y+as.Y)}, fg, 1)
+	if !pl.NoLegend {
+		pl.RenderLegend(pp, fg, bg, pad)
+	}
+}
+
+// RenderSeries renders the visible series in the data area: heatmaps
+// first, then bars, then lines and points
+func (pl *Plot) RenderSeries(pp PlotPainter) {
+	var bars []int
+	for si := range pl.Series {
+		if !pl.Series[si].Hidden && pl.Series[si].Type == PlotBar {
+			bars = append(bars, si)
+		}
+	}
+	for _, typ := range []PlotTypes{PlotHeatmap, PlotBar, PlotLine, PlotScatter} {
+		for si := range pl.Series {
+			ser := &pl.Series[si]
+			if ser.Hidden || ser.Type != typ {
+				continue
+			}
+			switch typ {
+			case PlotHeatmap:
+				pl.RenderHeatmap(pp, si)
+			case PlotBar:
+				bi := 0
+				for i, b := range bars {
+					if b == si {
+						bi = i
+					}
+				}
+				pl.RenderBars(pp, si, bi, len(bars))
+			default:
+				pl.RenderLine(pp, si)
+			}
+		}
+	}
+}
+
+// seriesWidth returns the line width of the series
+func (pl *Plot) seriesWidth(si int) float32 {
+	if w := pl.Series[si].Width; w > 0 {
+		return w
+	}
+	return 2
+}
+
+// RenderLine renders a line or scatter series -- lines are broken at
+// missing (NaN) values
+func (pl *Plot) RenderLine(pp PlotPainter, si int) {
+	ser := &pl.Series[si]
+	clr := pl.SeriesColor(si)
+	wd := pl.seriesWidth(si)
+	xs, ys := pl.Values(si)
+	var pts []mat32.Vec2
+	for i := range xs {
+		if math.IsNaN(xs[i]) || math.IsNaN(ys[i]) {
+			if len(pts) > 1 {
+				pp.Line(pts, clr, wd)
+			}
+			pts = pts[:0]
+			continue
+		}
+		pt := pl.DataToPos(xs[i], ys[i])
+		if ser.Type == PlotScatter {
+			pp.Circle(pt, wd+1, clr)
+			continue
+		}
+		pts = append(pts, pt)
+	}
+	if len(pts) > 1 {
+		pp.Line(pts, clr, wd)
+	}
+}
+
+// RenderBars renders a bar series, as bar bi of nbar bars at each X value
+func (pl *Plot) RenderBars(pp PlotPainter, si, bi, nbar int) {
+	clr := pl.SeriesColor(si)
+	xs, ys := pl.Values(si)
+	bw := 0.8 * pl.XSpacing() / float64(nbar)
+	for i := range xs {
+		if math.IsNaN(xs[i]) || math.IsNaN(ys[i]) {
+			continue
+		}
+		x := xs[i] - 0.4*pl.XSpacing() + float64(bi)*bw
+		p0 := pl.DataToPos(x, math.Max(ys[i], 0))
+		p1 := pl.DataToPos(x+bw, math.Min(ys[i], 0))
+		pp.Box(p0, p1.Sub(p0), clr)
+	}
+}
+
+// RenderHeatmap renders a heatmap series, with a column of cells for each
+// element, colored by the ColorMap over the range of all the values
+func (pl *Plot) RenderHeatmap(pp PlotPainter, si int) {
+	n := pl.NumElems()
+	cols := make([][]float64, n)
+	var vr PlotRange
+	vr.Reset()
+	for i := range cols {
+		cols[i] = pl.HeatVals(si, i)
+		for _, v := range cols[i] {
+			vr.Include(v)
+		}
+	}
+	if !vr.IsValid() {
+		return
+	}
+	cm := pl.CurColorMap()
+	hw := pl.XSpacing() / 2
+	for i, vals := range cols {
+		x := pl.XVal(i)
+		if math.IsNaN(x) {
+			continue
+		}
+		for j, v := range vals {
+			norm := 0.5
+			if vr.Range() > 0 {
+				norm = (v - vr.Min) / vr.Range()
+			}
+			if math.IsNaN(v) {
+				norm = v
+			}
+			p0 := pl.DataToPos(x-hw, float64(j)+0.5)
+			p1 := pl.DataToPos(x+hw, float64(j)-0.5)
+			pp.Box(p0, p1.Sub(p0), cm.Map(norm))
+		}
+	}
+}
+
+// RenderLegend renders the legend at the top right of the data area,
+// recording the LegendBoxes
+func (pl *Plot) RenderLegend(pp PlotPainter, fg, bg gist.Color, pad float32) {
+	th := pp.TextSize("0").Y
+	sw := 2 * th
+	wd := float32(0)
+	for si := range pl.Series {
+		wd = mat32.Max(wd, pp.TextSize(pl.Series[si].Name()).X)
+	}
+	if len(pl.Series) == 0 {
+		return
+	}
+	bsz := mat32.NewVec2(pad+sw+pad+wd+pad, pad+float32(len(pl.Series))*(th+pad))
+	bpos := mat32.NewVec2(pl.AreaPos.X+pl.AreaSize.X-bsz.X-pad, pl.AreaPos.Y+pad)
+	pp.Box(bpos, bsz, bg.Clearer(15))
+	pp.Line([]mat32.Vec2{bpos, mat32.NewVec2(bpos.X+bsz.X, bpos.Y), bpos.Add(bsz), mat32.NewVec2(bpos.X, bpos.Y+bsz.Y), bpos}, fg.Clearer(50), 1)
+	y := bpos.Y + pad
+	for si := range pl.Series {
+		ser := &pl.Series[si]
+		clr := pl.SeriesColor(si)
+		tc := fg
+		if ser.Hidden {
+			clr = clr.Clearer(75)
+			tc = fg.Clearer(60)
+		}
+		x := bpos.X + pad
+		my := y + th/2
+		switch ser.Type {
+		case PlotLine:
+			pp.Line([]mat32.Vec2{mat32.NewVec2(x, my), mat32.NewVec2(x+sw, my)}, clr, pl.seriesWidth(si))
+		case PlotScatter:
+			pp.Circle(mat32.NewVec2(x+sw/2, my), pl.seriesWidth(si)+1, clr)
+		case PlotBar:
+			pp.Box(mat32.NewVec2(x, y+th/4), mat32.NewVec2(sw, th/2), clr)
+		case PlotHeatmap:
+			cm := pl.CurColorMap()
+			for i := 0; i < 8; i++ {
+				pp.Box(mat32.NewVec2(x+float32(i)*sw/8, y+th/4), mat32.NewVec2(sw/8, th/2), cm.Map(float64(i)/7))
+			}
+		}
+		pp.Text(ser.Name(), mat32.NewVec2(x+sw+pad, y), tc)
+		pl.LegendBoxes = append(pl.LegendBoxes, PlotLegendBox{Series: si, Pos: mat32.NewVec2(bpos.X, y), Size: mat32.NewVec2(bsz.X, th)})
+		y += th + pad
+	}
+}
+
+// PlotDefaultFont returns a font for rendering plots outside of a widget,
+// e.g., for SavePNG and SaveSVG
+func PlotDefaultFont() (*gist.Font, *units.Context) {
+	fs := &gist.Font{}
+	fs.Defaults()
+	fs.Family = gi.Prefs.PrefFontFamily()
+	ctxt := &units.Context{}
+	ctxt.Defaults()
+	girl.OpenFont(fs, ctxt)
+	return fs, ctxt
+}
+
+// RenderImage renders the plot to a new image of given size, in the
+// default font and the PlotFile colors
+func (pl *Plot) RenderImage(sz image.Point) *image.RGBA {
+	fs, ctxt := PlotDefaultFont()
+	img := image.NewRGBA(image.Rectangle{Max: sz})
+	rs := &girl.State{}
+	rs.Init(sz.X, sz.Y, img)
+	rs.PushBounds(img.Bounds())
+	rs.Lock()
+	pp := NewPlotGirl(rs, fs, ctxt)
+	pl.Render(pp, mat32.Vec2{}, mat32.NewVec2FmPoint(sz), PlotFileFg, PlotFileBg)
+	rs.Unlock()
+	rs.PopBounds()
+	return img
+}
+
+// SavePNG saves the plot to a PNG image file of given size in dots
+func (pl *Plot) SavePNG(filename gi.FileName, width, height int) error {
+	return gi.SavePNG(string(filename), pl.RenderImage(image.Point{width, height}))
+}
+
+// WriteSVG writes the plot as SVG of given size, in the default font and
+// the PlotFile colors
+func (pl *Plot) WriteSVG(w io.Writer, width, height int) error {
+	fs, ctxt := PlotDefaultFont()
+	pp := NewPlotSVG(w, width, height, fs, ctxt)
+	pl.Render(pp, mat32.Vec2{}, mat32.NewVec2(float32(width), float32(height)), PlotFileFg, PlotFileBg)
+	return pp.End()
+}
+
+// SaveSVG saves the plot to an SVG file of given size
+func (pl *Plot) SaveSVG(filename gi.FileName, width, height int) error {
+	f, err := os.Create(string(filename))
+	if err != nil {
+		return err
+	}
+	err = pl.WriteSVG(f, width, height)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//  PlotGirl
+
+// PlotGirl is a PlotPainter that renders to a girl.State, in given font --
+// the state must already have its bounds pushed, and be locked
+type PlotGirl struct {
+	RS    *girl.State
+	Paint girl.Paint
+	Font  gist.Font
+	Ctxt  *units.Context
+	TxtSt gist.Text
+	Saved image.Rectangle `desc:"bounds saved by Clip"`
+}
+
+// NewPlotGirl returns a new PlotGirl rendering to given state, in given
+// font, which must have been opened
+func NewPlotGirl(rs *girl.State, fs *gist.Font, ctxt *units.Context) *PlotGirl {
+	pg := &PlotGirl{RS: rs, Font: *fs, Ctxt: ctxt}
+	pg.Paint.Defaults()
+	pg.TxtSt.Defaults()
+	return pg
+}
+
+// opaque returns the opaque version of given color, and its opacity, as
+// paths are rendered with the Opacity of the paint style, not the alpha of
+// the color
+func (pg *PlotGirl) opaque(clr gist.Color) (gist.Color, float32) {
+	nc := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	return gist.Color{R: nc.R, G: nc.G, B: nc.B, A: 255}, float32(nc.A) / 255
+}
+
+func (pg *PlotGirl) Line(pts []mat32.Vec2, clr gist.Color, width float32) {
+	pc := &pg.Paint
+	pc.FillStyle.SetColor(nil)
+	oc, op := pg.opaque(clr)
+	pc.StrokeStyle.SetColor(oc)
+	pc.StrokeStyle.Opacity = op
+	pc.StrokeStyle.Width.SetDot(width)
+	pc.DrawPolyline(pg.RS, pts)
+	pc.Stroke(pg.RS)
+}
+
+func (pg *PlotGirl) Box(pos, sz mat32.Vec2, clr gist.Color) {
+	pg.Paint.FillBoxColor(pg.RS, pos, sz, clr)
+}
+
+func (pg *PlotGirl) Circle(ctr mat32.Vec2, r float32, clr gist.Color) {
+	pc := &pg.Paint
+	pc.StrokeStyle.SetColor(nil)
+	oc, op := pg.opaque(clr)
+	pc.FillStyle.SetColor(oc)
+	pc.FillStyle.Opacity = op
+	pc.DrawCircle(pg.RS, ctr.X, ctr.Y, r)
+	pc.Fill(pg.RS)
+}
+
+func (pg *PlotGirl) Text(str string, pos mat32.Vec2, clr gist.Color) {
+	pg.Font.Color = clr
+	var tr girl.Text
+	tr.SetString(str, &pg.Font, pg.Ctxt, &pg.TxtSt, true, 0, 1)
+	tr.RenderTopPos(pg.RS, pos)
+}
+
+func (pg *PlotGirl) TextSize(str string) mat32.Vec2 {
+	var tr girl.Text
+	tr.SetString(str, &pg.Font, pg.Ctxt, &pg.TxtSt, true, 0, 1)
+	return tr.Size
+}
+
+func (pg *PlotGirl) Clip(pos, sz mat32.Vec2) {
+	bb := image.Rectangle{Min: pos.ToPointFloor(), Max: pos.Add(sz).ToPointCeil()}
+	pg.Saved = pg.RS.Bounds
+	pg.RS.Bounds = bb.Intersect(pg.RS.Bounds)
+}
+
+func (pg *PlotGirl) Unclip() {
+	pg.RS.Bounds = pg.Saved
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//  PlotSVG
+
+// PlotSVG is a PlotPainter that writes SVG, measuring text in given font
+type PlotSVG struct {
+	W     io.Writer
+	Err   error
+	Font  gist.Font
+	Ctxt  *units.Context
+	TxtSt gist.Text
+	NClip int
+}
+
+// NewPlotSVG returns a new PlotSVG writing to given writer, having written
+// the svg element header for given size
+func NewPlotSVG(w io.Writer, width, height int, fs *gist.Font, ctxt *units.Context) *PlotSVG {
+	ps := &PlotSVG{W: w, Font: *fs, Ctxt: ctxt}
+	ps.TxtSt.Defaults()
+	ps.printf("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\" font-family=\"%s\" font-size=\"%g\">\n", width, height, width, height, escapeSVG(fs.Family), fs.Size.Dots)
+	return ps
+}
+
+// End writes the end of the svg element, returning the first write error
+func (ps *PlotSVG) End() error {
+	ps.printf("</svg>\n")
+	return ps.Err
+}
+
+func (ps *PlotSVG) printf(format string, args ...interface{}) {
+	if ps.Err == nil {
+		_, ps.Err = fmt.Fprintf(ps.W, format, args...)
+	}
+}
+
+// svgColor returns the SVG color and opacity attributes for given
+// attribute (fill or stroke) and color
+func svgColor(attr string, clr gist.Color) string {
+	nc := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	s := fmt.Sprintf("%s=\"#%02x%02x%02x\"", attr, nc.R, nc.G, nc.B)
+	if nc.A < 255 {
+		s += fmt.Sprintf(" %s-opacity=\"%.3g\"", attr, float32(nc.A)/255)
+	}
+	return s
+}
+
+func escapeSVG(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\"", "&quot;").Replace(s)
+}
+
+func (ps *PlotSVG) Line(pts []mat32.Vec2, clr gist.Color, width float32) {
+	var sb strings.Builder
+	for i, pt := range pts {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		fmt.Fprintf(&sb, "%.2f,%.2f", pt.X, pt.Y)
+	}
+	ps.printf("<polyline points=\"%s\" fill=\"none\" %s stroke-width=\"%g\" stroke-linejoin=\"round\"/>\n", sb.String(), svgColor("stroke", clr), width)
+}
+
+func (ps *PlotSVG) Box(pos, sz mat32.Vec2, clr gist.Color) {
+	ps.printf("<rect x=\"%.2f\" y=\"%.2f\" width=\"%.2f\" height=\"%.2f\" %s/>\n", pos.X, pos.Y, sz.X, sz.Y, svgColor("fill", clr))
+}
+
+func (ps *PlotSVG) Circle(ctr mat32.Vec2, r float32, clr gist.Color) {
+	ps.printf("<circle cx=\"%.2f\" cy=\"%.2f\" r=\"%g\" %s/>\n", ctr.X, ctr.Y, r, svgColor("fill", clr))
+}
+
+func (ps *PlotSVG) Text(str string, pos mat32.Vec2, clr gist.Color) {
+	asc := float32(0)
+	if ps.Font.Face != nil {
+		asc = mat32.FromFixed(ps.Font.Face.Face.Metrics().Ascent)
+	}
+	ps.printf("<text x=\"%.2f\" y=\"%.2f\" %s>%s</text>\n", pos.X, pos.Y+asc, svgColor("fill", clr), escapeSVG(str))
+}
+
+func (ps *PlotSVG) TextSize(str string) mat32.Vec2 {
+	var tr girl.Text
+	tr.SetString(str, &ps.Font, ps.Ctxt, &ps.TxtSt, true, 0, 1)
+	return tr.Size
+}
+
+func (ps *PlotSVG) Clip(pos, sz mat32.Vec2) {
+	ps.NClip++
+	ps.printf("<clipPath id=\"clip%d\"><rect x=\"%.2f\" y=\"%.2f\" width=\"%.2f\" height=\"%.2f\"/></clipPath>\n<g clip-path=\"url(#clip%d)\">\n", ps.NClip, pos.X, pos.Y, sz.X, sz.Y, ps.NClip)
+}
+
+func (ps *PlotSVG) Unclip() {
+	ps.printf("</g>\n")
+}
@@ -0,0 +1,260 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"image"
+	"os"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/girl"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+)
+
+// PlotView is a widget that displays a Plot of data -- see Plot for the
+// kinds of data that can be plotted, and SetData.  The mouse wheel zooms in
+// and out about the mouse position (in X only with Shift, and in Y only
+// with Control), dragging pans, double-clicking fits the view to the data
+// again, and clicking on a series in the legend hides or shows it.
+type PlotView struct {
+	gi.WidgetBase
+	Plot     Plot        `desc:"the plot being displayed"`
+	ZoomRate float64     `desc:"factor by which each step of the mouse wheel zooms -- 1.1 if not set"`
+	dragPos  image.Point `desc:"last position of a drag, for panning"`
+}
+
+var KiT_PlotView = kit.Types.AddType(&PlotView{}, PlotViewProps)
+
+// AddNewPlotView adds a new plotview to given parent node, with given name.
+func AddNewPlotView(parent ki.Ki, name string) *PlotView {
+	return parent.AddNewChild(KiT_PlotView, name).(*PlotView)
+}
+
+var PlotViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"width":            units.NewEm(30),
+	"height":           units.NewEm(20),
+	"max-width":        -1,
+	"max-height":       -1,
+	"color":            &gi.Prefs.Colors.Font,
+	"background-color": &gi.Prefs.Colors.Background,
+	"CallMethods": ki.PropSlice{
+		{"SavePNG", ki.Props{
+			"label": "Save PNG...",
+			"desc":  "save the plot to a PNG image file, at its current size",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".png",
+				}},
+			},
+		}},
+		{"SaveSVG", ki.Props{
+			"label": "Save SVG...",
+			"desc":  "save the plot to an SVG file, at its current size",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".svg",
+				}},
+			},
+		}},
+	},
+}
+
+// SetData sets the data to plot, and fits the view to it -- see Plot.SetData
+func (pv *PlotView) SetData(data interface{}) {
+	pv.Plot.SetData(data)
+	pv.UpdateSig()
+}
+
+// Update updates the plot after the data has changed, keeping the
+// current view
+func (pv *PlotView) Update() {
+	pv.UpdateSig()
+}
+
+// FitData fits the view to the data, undoing any zooming and panning
+func (pv *PlotView) FitData() {
+	pv.Plot.FitData()
+	pv.UpdateSig()
+}
+
+// Zoom zooms the view by given factor (< 1 zooms in) in X and / or Y, about
+// given position in window coordinates
+func (pv *PlotView) Zoom(factor float64, pos image.Point, zx, zy bool) {
+	pl := &pv.Plot
+	x, y := pl.PosToData(pv.WinPosToPlot(pos))
+	if zx {
+		pl.XRange.Zoom(factor, x)
+	}
+	if zy {
+		pl.YRange.Zoom(factor, y)
+	}
+	pv.UpdateSig()
+}
+
+// Pan pans the view by given distance in dots
+func (pv *PlotView) Pan(del image.Point) {
+	pl := &pv.Plot
+	if pl.AreaSize.X <= 0 || pl.AreaSize.Y <= 0 {
+		return
+	}
+	dx := -float64(del.X) / float64(pl.AreaSize.X) * pl.XRange.Range()
+	dy := float64(del.Y) / float64(pl.AreaSize.Y) * pl.YRange.Range()
+	pl.XRange.Min += dx
+	pl.XRange.Max += dx
+	pl.YRange.Min += dy
+	pl.YRange.Max += dy
+	pv.UpdateSig()
+}
+
+// ToggleSeries hides or shows the series of given index
+func (pv *PlotView) ToggleSeries(si int) {
+	if si < 0 || si >= len(pv.Plot.Series) {
+		return
+	}
+	pv.Plot.Series[si].Hidden = !pv.Plot.Series[si].Hidden
+	pv.UpdateSig()
+}
+
+// WinPosToPlot converts a position in window coordinates to the coordinates
+// that the plot is rendered in
+func (pv *PlotView) WinPosToPlot(pos image.Point) mat32.Vec2 {
+	return mat32.NewVec2FmPoint(pos.Sub(pv.WinBBox.Min).Add(pv.VpBBox.Min))
+}
+
+// SavePNG saves the plot to a PNG image file, at its current size
+func (pv *PlotView) SavePNG(filename gi.FileName) error {
+	sz := pv.VpBBox.Size()
+	if sz.X <= 0 || sz.Y <= 0 {
+		sz = image.Point{640, 480}
+	}
+	return pv.Plot.SavePNG(filename, sz.X, sz.Y)
+}
+
+// SaveSVG saves the plot to an SVG file, at its current size
+func (pv *PlotView) SaveSVG(filename gi.FileName) error {
+	sz := pv.VpBBox.Size()
+	if sz.X <= 0 || sz.Y <= 0 {
+		sz = image.Point{640, 480}
+	}
+	f, err := os.Create(string(filename))
+	if err != nil {
+		return err
+	}
+	err = pv.Plot.WriteSVG(f, sz.X, sz.Y)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// MakeContextMenu adds the actions for resetting the view and saving the
+// plot to given menu
+func (pv *PlotView) MakeContextMenu(m *gi.Menu) {
+	m.AddAction(gi.ActOpts{Label: "Fit Data"}, pv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		pvv := recv.Embed(KiT_PlotView).(*PlotView)
+		pvv.FitData()
+	})
+	m.AddAction(gi.ActOpts{Label: "Save PNG..."}, pv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		pvv := recv.Embed(KiT_PlotView).(*PlotView)
+		CallMethod(pvv, "SavePNG", pvv.ViewportSafe())
+	})
+	m.AddAction(gi.ActOpts{Label: "Save SVG..."}, pv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		pvv := recv.Embed(KiT_PlotView).(*PlotView)
+		CallMethod(pvv, "SaveSVG", pvv.ViewportSafe())
+	})
+}
+
+// RenderPlot renders the plot
+func (pv *PlotView) RenderPlot() {
+	rs, _, sty := pv.RenderLock()
+	defer pv.RenderUnlock(rs)
+	pos := mat32.NewVec2FmPoint(pv.VpBBox.Min)
+	sz := mat32.NewVec2FmPoint(pv.VpBBox.Size())
+	pp := NewPlotGirl(rs, &sty.Font, &sty.UnContext)
+	pv.Plot.Render(pp, pos, sz, sty.Font.Color, sty.Font.BgColor.Color)
+}
+
+// MouseEvents connects the mouse events for zooming, panning and toggling
+// series
+func (pv *PlotView) MouseEvents() {
+	pv.ConnectEvent(oswin.MouseEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.Event)
+		pvv := recv.Embed(KiT_PlotView).(*PlotView)
+		if me.Button != mouse.Left {
+			return
+		}
+		switch me.Action {
+		case mouse.Press:
+			me.SetProcessed()
+			pvv.dragPos = me.Pos()
+			if si := pvv.Plot.LegendAt(pvv.WinPosToPlot(me.Pos())); si >= 0 {
+				pvv.ToggleSeries(si)
+			}
+		case mouse.DoubleClick:
+			me.SetProcessed()
+			if pvv.Plot.LegendAt(pvv.WinPosToPlot(me.Pos())) < 0 {
+				pvv.FitData()
+			}
+		}
+	})
+	pv.ConnectEvent(oswin.MouseDragEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.DragEvent)
+		me.SetProcessed()
+		pvv := recv.Embed(KiT_PlotView).(*PlotView)
+		pvv.Pan(me.Pos().Sub(pvv.dragPos))
+		pvv.dragPos = me.Pos()
+	})
+	pv.ConnectEvent(oswin.MouseScrollEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.ScrollEvent)
+		me.SetProcessed()
+		pvv := recv.Embed(KiT_PlotView).(*PlotView)
+		del := me.NonZeroDelta(false)
+		if del == 0 {
+			return
+		}
+		rate := pvv.ZoomRate
+		if rate <= 1 {
+			rate = 1.1
+		}
+		factor := 1 / rate
+		if del < 0 {
+			factor = rate
+		}
+		zx := !me.HasAnyModifier(key.Control)
+		zy := !me.HasAnyModifier(key.Shift)
+		pvv.Zoom(factor, me.Pos(), zx, zy)
+	})
+}
+
+// Style2D sets the style and opens the font for the text of the plot
+func (pv *PlotView) Style2D() {
+	pv.Style2DWidget()
+	girl.OpenFont(&pv.Sty.Font, &pv.Sty.UnContext)
+}
+
+func (pv *PlotView) Render2D() {
+	if pv.FullReRenderIfNeeded() {
+		return
+	}
+	if pv.PushBounds() {
+		pv.This().(gi.Node2D).ConnectEvents2D()
+		pv.RenderPlot()
+		pv.PopBounds()
+	} else {
+		pv.DisconnectAllEvents(gi.RegPri)
+	}
+}
+
+func (pv *PlotView) ConnectEvents2D() {
+	pv.MouseEvents()
+	pv.HoverTooltipEvent()
+}
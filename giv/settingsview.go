@@ -0,0 +1,106 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/ki/ki"
+)
+
+// SettingsView opens a view of the registered settings sections, with a
+// tab with a StructView of each section, which marks the section as
+// changed (applying it) when it is edited -- see gi.RegisterSettings
+func SettingsView(reg *gi.SettingsRegistry) *gi.Window {
+	winm := "gogi-settings"
+	width := 1024
+	height := 600
+	win, recyc := gi.RecycleMainWindow(reg, winm, oswin.TheApp.Name()+" Settings", width, height)
+	if recyc {
+		return win
+	}
+
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+
+	mfr := win.SetMainFrame()
+	mfr.Lay = gi.LayoutVert
+
+	tb := gi.AddNewToolBar(mfr, "toolbar")
+	tb.SetStretchMaxWidth()
+	ToolBarView(reg, vp, tb)
+
+	tv := gi.AddNewTabView(mfr, "tabs")
+	tv.NoDeleteTabs = true
+	tv.SetStretchMax()
+
+	svs := make(map[*gi.SettingsSection]*StructView)
+	for _, ss := range reg.Sections {
+		sv := tv.AddNewTab(KiT_StructView, ss.Title()).(*StructView)
+		sv.Viewport = vp
+		sv.SetStruct(ss.Value)
+		sv.SetStretchMax()
+		svs[ss] = sv
+		sec := ss
+		sv.ViewSig.Connect(tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			reg.SetChanged(sec)
+			tb.UpdateActions()
+		})
+	}
+	tv.SelectTabIndex(0)
+
+	// the values are replaced when opened or reset
+	reg.SettingsSig.Connect(tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		ss := data.(*gi.SettingsSection)
+		if sig == int64(gi.SettingsOpened) || sig == int64(gi.SettingsReset) {
+			if sv, ok := svs[ss]; ok {
+				sv.UpdateFields()
+			}
+		}
+		tb.UpdateActions()
+	})
+
+	mmen := win.MainMenu
+	MainMenuView(reg, win, mmen)
+
+	inClosePrompt := false
+	win.OSWin.SetCloseReqFunc(func(w oswin.Window) {
+		if !reg.HasChanged() {
+			win.Close()
+			return
+		}
+		if inClosePrompt {
+			return
+		}
+		inClosePrompt = true
+		gi.ChoiceDialog(vp, gi.DlgOpts{Title: "Save Settings Before Closing?",
+			Prompt: "Do you want to save any changes to settings before closing?"},
+			[]string{"Save and Close", "Discard and Close", "Cancel"},
+			win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				switch sig {
+				case 0:
+					reg.SaveAll()
+					win.Close()
+				case 1:
+					reg.OpenAll() // if we don't do this, then it actually remains in edited state
+					win.Close()
+				case 2:
+					inClosePrompt = false
+					// default is to do nothing, i.e., cancel
+				}
+			})
+	})
+
+	win.MainMenuUpdated()
+
+	if !win.HasGeomPrefs() { // resize to contents
+		vpsz := vp.PrefSize(win.OSWin.Screen().PixSize)
+		win.SetSize(vpsz)
+	}
+
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+	return win
+}
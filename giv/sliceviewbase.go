@@ -58,6 +58,9 @@ type SliceViewer interface {
 	// SliceSize returns the current size of the slice and sets SliceSize
 	UpdtSliceSize() int
 
+	// SliceVal returns value interface at given slice index
+	SliceVal(idx int) interface{}
+
 	// LayoutSliceGrid does the proper layout of slice grid depending on allocated size
 	// returns true if UpdateSliceGrid should be called after this
 	LayoutSliceGrid() bool
@@ -163,6 +166,7 @@ func AddNewSliceViewBase(parent ki.Ki, name string) *SliceViewBase {
 
 func (sv *SliceViewBase) Disconnect() {
 	sv.Frame.Disconnect()
+	sv.BindSlice(nil)
 	sv.SliceViewSig.DisconnectAll()
 	sv.ViewSig.DisconnectAll()
 }
@@ -179,6 +183,7 @@ func (sv *SliceViewBase) AsSliceViewBase() *SliceViewBase {
 // to represent this slice
 func (sv *SliceViewBase) SetSlice(sl interface{}) {
 	if kit.IfaceIsNil(sl) {
+		sv.BindSlice(nil)
 		sv.Slice = nil
 		return
 	}
@@ -188,6 +193,7 @@ func (sv *SliceViewBase) SetSlice(sl interface{}) {
 	}
 	updt := sv.UpdateStart()
 	sv.StartIdx = 0
+	sv.BindSlice(sl)
 	sv.Slice = sl
 	sv.SliceNPVal = kit.NonPtrValue(reflect.ValueOf(sv.Slice))
 	sv.isArray = kit.NonPtrType(reflect.TypeOf(sl)).Kind() == reflect.Array
@@ -209,6 +215,30 @@ func (sv *SliceViewBase) SetSlice(sl interface{}) {
 	sv.UpdateEnd(updt)
 }
 
+// BindSlice binds the view to given slice, to update when NotifyChange is
+// called for it, removing the binding to the current slice -- nil just
+// removes it.  Called by SetSlice.
+func (sv *SliceViewBase) BindSlice(sl interface{}) {
+	bv, ok := sv.This().(BoundView)
+	if !ok {
+		return
+	}
+	if sv.Slice != nil {
+		UnbindModel(sv.Slice, bv)
+	}
+	if sl != nil {
+		BindModel(sl, bv)
+	}
+}
+
+// ModelChanged updates the view for a change to its slice, per BoundView
+func (sv *SliceViewBase) ModelChanged(field string) {
+	if sv.Slice == nil || !sv.IsConfiged() {
+		return
+	}
+	sv.Update()
+}
+
 // Update is the high-level update display call -- robust to any changes
 func (sv *SliceViewBase) Update() {
 	wupdt := sv.TopUpdateStart()
@@ -663,6 +693,10 @@ func (sv *SliceViewBase) UpdateSliceGrid() {
 				vvb := vv.AsValueViewBase()
 				vvb.ViewSig.ConnectOnly(sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 					svv, _ := recv.Embed(KiT_SliceViewBase).(*SliceViewBase)
+					slc := svv.Slice
+					SaveValueUndo(ViewUndoMgr(svv.This().(gi.Node2D)), send.(ValueView), func() {
+						NotifyChange(slc, "")
+					})
 					svv.SetChanged()
 				})
 				if !sv.isArray {
@@ -717,9 +751,11 @@ func (sv *SliceViewBase) UpdateSliceGrid() {
 // SetChanged sets the Changed flag and emits the ViewSig signal for the
 // SliceViewBase, indicating that some kind of edit / change has taken place to
 // the table data.  It isn't really practical to record all the different
-// types of changes, so this is just generic.
+// types of changes, so this is just generic.  Other views bound to the same
+// slice are updated via NotifyChange.
 func (sv *SliceViewBase) SetChanged() {
 	sv.Changed = true
+	NotifyChangeFrom(sv.Slice, "", sv.This())
 	sv.ViewSig.Emit(sv.This(), 0, nil)
 	sv.ToolBar().UpdateActions() // nil safe
 }
@@ -789,6 +825,9 @@ func (sv *SliceViewBase) SliceNewAt(idx int) {
 	}
 
 	sv.SliceNPVal = kit.NonPtrValue(reflect.ValueOf(sv.Slice)) // need to update after changes
+	if !iski {
+		sv.SaveSliceUndo(gi.T("Insert Item"), idx, true)
+	}
 
 	if sv.TmpSave != nil {
 		sv.TmpSave.SaveTmp()
@@ -804,6 +843,33 @@ func (sv *SliceViewBase) SliceNewAt(idx int) {
 	sv.SliceViewSig.Emit(sv.This(), int64(SliceViewInserted), idx)
 }
 
+// SaveSliceUndo saves an undo record to the UndoMgr of the window for the
+// insert (ins = true, just done) or delete (about to be done) of the element
+// at given index of the slice, with given label
+func (sv *SliceViewBase) SaveSliceUndo(label string, idx int, ins bool) {
+	um := ViewUndoMgr(sv.This().(gi.Node2D))
+	if um == nil || kit.IfaceIsNil(sv.Slice) {
+		return
+	}
+	slc := sv.Slice
+	ev := kit.NonPtrValue(reflect.ValueOf(slc)).Index(idx)
+	val := reflect.New(ev.Type()).Elem()
+	val.Set(ev)
+	insf := func() {
+		sliceInsertAt(slc, idx, val)
+		NotifyChange(slc, "")
+	}
+	delf := func() {
+		kit.SliceDeleteAt(slc, idx)
+		NotifyChange(slc, "")
+	}
+	if ins {
+		um.Save(label, delf, insf)
+	} else {
+		um.Save(label, insf, delf)
+	}
+}
+
 // SliceDeleteAtRow deletes element at given display row
 // if updt is true, then update the grid after
 func (sv *SliceViewBase) SliceDeleteAtRow(row int, updt bool) {
@@ -822,6 +888,7 @@ func (sv *SliceViewBase) SliceDeleteAt(idx int, doupdt bool) {
 	updt := sv.UpdateStart()
 	defer sv.UpdateEnd(updt)
 
+	sv.SaveSliceUndo(gi.T("Delete Item"), idx, false)
 	kit.SliceDeleteAt(sv.Slice, idx)
 
 	if sv.TmpSave != nil {
@@ -951,6 +1018,13 @@ func (sv *SliceViewBase) ConnectEvents2D() {
 	sv.SliceViewBaseEvents()
 }
 
+// InSliceGrid returns true if given window position is within the
+// SliceGrid, e.g., to distinguish clicks on items from those on headers
+func (sv *SliceViewBase) InSliceGrid(pos image.Point) bool {
+	sg := sv.This().(SliceViewer).SliceGrid()
+	return sg != nil && pos.In(sg.WinBBox)
+}
+
 func (sv *SliceViewBase) HasFocus2D() bool {
 	if !sv.ContainsFocus() {
 		return false
@@ -1462,7 +1536,7 @@ func (sv *SliceViewBase) UnselectIdxAction(idx int) {
 // MimeDataIdx adds mimedata for given idx: an application/json of the struct
 func (sv *SliceViewBase) MimeDataIdx(md *mimedata.Mimes, idx int) {
 	sv.ViewMuLock()
-	val := sv.SliceVal(idx)
+	val := sv.This().(SliceViewer).SliceVal(idx)
 	b, err := json.MarshalIndent(val, "", "  ")
 	if err == nil {
 		*md = append(*md, &mimedata.Data{Type: filecat.DataJson, Data: b})
@@ -1544,10 +1618,13 @@ func (sv *SliceViewBase) DeleteIdxs() {
 	defer sv.TopUpdateEnd(wupdt)
 
 	updt := sv.UpdateStart()
+	um := ViewUndoMgr(sv.This().(gi.Node2D))
+	um.StartGroup(gi.T("Delete Selected"))
 	ixs := sv.SelectedIdxsList(true) // descending sort
 	for _, i := range ixs {
 		sv.This().(SliceViewer).SliceDeleteAt(i, false)
 	}
+	um.EndGroup()
 	sv.SetChanged()
 	sv.This().(SliceViewer).UpdateSliceGrid()
 	sv.UpdateEnd(updt)
@@ -1567,9 +1644,12 @@ func (sv *SliceViewBase) Cut() {
 	ixs := sv.SelectedIdxsList(true) // descending sort
 	idx := ixs[0]
 	sv.UnselectAllIdxs()
+	um := ViewUndoMgr(sv.This().(gi.Node2D))
+	um.StartGroup(gi.T("Cut"))
 	for _, i := range ixs {
 		sv.This().(SliceViewer).SliceDeleteAt(i, false)
 	}
+	um.EndGroup()
 	sv.SetChanged()
 	sv.This().(SliceViewer).UpdateSliceGrid()
 	sv.UpdateEnd(updt)
@@ -1644,7 +1724,21 @@ func (sv *SliceViewBase) PasteAssign(md mimedata.Mimes, idx int) {
 	}
 	updt := sv.UpdateStart()
 	ns := sl[0]
-	sv.SliceNPVal.Index(idx).Set(reflect.ValueOf(ns).Elem())
+	ev := sv.SliceNPVal.Index(idx)
+	prv := reflect.New(ev.Type()).Elem()
+	prv.Set(ev)
+	ev.Set(reflect.ValueOf(ns).Elem())
+	if um := ViewUndoMgr(sv.This().(gi.Node2D)); um != nil {
+		slc := sv.Slice
+		nv := reflect.ValueOf(ns).Elem()
+		um.Save(gi.T("Paste"), func() {
+			kit.NonPtrValue(reflect.ValueOf(slc)).Index(idx).Set(prv)
+			NotifyChange(slc, "")
+		}, func() {
+			kit.NonPtrValue(reflect.ValueOf(slc)).Index(idx).Set(nv)
+			NotifyChange(slc, "")
+		})
+	}
 	if sv.TmpSave != nil {
 		sv.TmpSave.SaveTmp()
 	}
@@ -1664,6 +1758,8 @@ func (sv *SliceViewBase) PasteAtIdx(md mimedata.Mimes, idx int) {
 	wupdt := sv.TopUpdateStart()
 	defer sv.TopUpdateEnd(wupdt)
 	updt := sv.UpdateStart()
+	um := ViewUndoMgr(sv.This().(gi.Node2D))
+	um.StartGroup(gi.T("Paste"))
 	for _, ns := range sl {
 		sz := svnp.Len()
 		svnp = reflect.Append(svnp, reflect.ValueOf(ns).Elem())
@@ -1673,8 +1769,10 @@ func (sv *SliceViewBase) PasteAtIdx(md mimedata.Mimes, idx int) {
 			svnp.Index(idx).Set(reflect.ValueOf(ns).Elem())
 			svl.Elem().Set(svnp)
 		}
+		sv.SaveSliceUndo("", ints.MinInt(idx, sz), true)
 		idx++
 	}
+	um.EndGroup()
 
 	sv.SliceNPVal = kit.NonPtrValue(reflect.ValueOf(sv.Slice)) // need to update after changes
 
@@ -1841,16 +1939,22 @@ func (sv *SliceViewBase) SaveDraggedIdxs(idx int) {
 
 // DropBefore inserts object(s) from mime data before this node
 func (sv *SliceViewBase) DropBefore(md mimedata.Mimes, mod dnd.DropMods, idx int) {
+	um := ViewUndoMgr(sv.This().(gi.Node2D))
+	um.StartGroup(gi.T("Drop"))
 	sv.SaveDraggedIdxs(idx)
 	sv.This().(SliceViewer).PasteAtIdx(md, idx)
 	sv.DragNDropFinalize(mod)
+	um.EndGroup()
 }
 
 // DropAfter inserts object(s) from mime data after this node
 func (sv *SliceViewBase) DropAfter(md mimedata.Mimes, mod dnd.DropMods, idx int) {
+	um := ViewUndoMgr(sv.This().(gi.Node2D))
+	um.StartGroup(gi.T("Drop"))
 	sv.SaveDraggedIdxs(idx + 1)
 	sv.This().(SliceViewer).PasteAtIdx(md, idx+1)
 	sv.DragNDropFinalize(mod)
+	um.EndGroup()
 }
 
 // DropCancel cancels the drop action e.g., preventing deleting of source
@@ -1889,7 +1993,7 @@ func (sv *SliceViewBase) StdCtxtMenu(m *gi.Menu, idx int) {
 }
 
 func (sv *SliceViewBase) ItemCtxtMenu(idx int) {
-	val := sv.SliceVal(idx)
+	val := sv.This().(SliceViewer).SliceVal(idx)
 	if val == nil {
 		return
 	}
@@ -2043,7 +2147,7 @@ func (sv *SliceViewBase) SliceViewBaseEvents() {
 			svv.SliceViewSig.Emit(svv.This(), int64(SliceViewDoubleClicked), si)
 			me.SetProcessed()
 		}
-		if me.Button == mouse.Right && me.Action == mouse.Release {
+		if me.Button == mouse.Right && me.Action == mouse.Release && svv.InSliceGrid(me.Where) {
 			svv.This().(SliceViewer).ItemCtxtMenu(svv.SelectedIdx)
 			me.SetProcessed()
 		}
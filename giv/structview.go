@@ -31,9 +31,11 @@ type StructView struct {
 	FieldViews    []ValueView       `json:"-" xml:"-" desc:"ValueView representations of the fields"`
 	TmpSave       ValueView         `json:"-" xml:"-" desc:"value view that needs to have SaveTmp called on it whenever a change is made to one of the underlying values -- pass this down to any sub-views created from a parent"`
 	ViewSig       ki.Signal         `json:"-" xml:"-" desc:"signal for valueview -- only one signal sent when a value has been set -- all related value views interconnect with each other to update when others update"`
+	ValidSig      ki.Signal         `json:"-" xml:"-" desc:"signal emitted when a field is edited and HasValids -- data is the IsValid bool of all the fields, e.g., for making the Ok button of a dialog inactive until it is true"`
 	ViewPath      string            `desc:"a record of parent View names that have led up to this view -- displayed as extra contextual information in view dialog windows"`
 	ToolbarStru   interface{}       `desc:"the struct that we successfully set a toolbar for"`
 	HasDefs       bool              `json:"-" xml:"-" view:"inactive" desc:"if true, some fields have default values -- update labels when values change"`
+	HasValids     bool              `json:"-" xml:"-" view:"inactive" desc:"if true, some fields are validated, by validate tags or the Validator interface of the struct -- their errors are shown in a third column of the grid"`
 	TypeFieldTags map[string]string `json:"-" xml:"-" view:"inactive" desc:"extra tags by field name -- from type properties"`
 }
 
@@ -46,7 +48,11 @@ func AddNewStructView(parent ki.Ki, name string) *StructView {
 
 func (sv *StructView) Disconnect() {
 	sv.Frame.Disconnect()
+	if bv, ok := sv.This().(BoundView); ok && sv.Struct != nil {
+		UnbindModel(sv.Struct, bv)
+	}
 	sv.ViewSig.DisconnectAll()
+	sv.ValidSig.DisconnectAll()
 }
 
 var StructViewProps = ki.Props{
@@ -65,12 +71,15 @@ func (sv *StructView) SetStruct(st interface{}) {
 		sv.Changed = false
 		updt = sv.UpdateStart()
 		sv.SetFullReRender()
+		bv, _ := sv.This().(BoundView)
 		if sv.Struct != nil {
 			if k, ok := sv.Struct.(ki.Ki); ok {
 				k.NodeSignal().Disconnect(sv.This())
 			}
+			UnbindModel(sv.Struct, bv)
 		}
 		sv.Struct = st
+		BindModel(st, bv)
 		tp := kit.Types.Properties(kit.NonPtrType(reflect.TypeOf(sv.Struct)), false)
 		if tp != nil {
 			if sfp, has := ki.SubTypeProps(*tp, "StructViewFields"); has {
@@ -99,8 +108,10 @@ func (sv *StructView) SetStruct(st interface{}) {
 func (sv *StructView) UpdateFields() {
 	updt := sv.UpdateStart()
 	for _, vv := range sv.FieldViews {
+		vv.AsValueViewBase().ValidErr = nil
 		vv.UpdateWidget()
 	}
+	sv.UpdateValids()
 	sv.UpdateEnd(updt)
 }
 
@@ -109,13 +120,25 @@ func (sv *StructView) UpdateField(field string) {
 	updt := sv.UpdateStart()
 	for _, vv := range sv.FieldViews {
 		if vv.Name() == field {
+			vv.AsValueViewBase().ValidErr = nil
 			vv.UpdateWidget()
 			break
 		}
 	}
+	sv.UpdateValids()
 	sv.UpdateEnd(updt)
 }
 
+// ModelChanged updates the view for a change to the given field of its
+// struct, or all of them if field is "", per BoundView
+func (sv *StructView) ModelChanged(field string) {
+	if field == "" {
+		sv.UpdateFields()
+	} else {
+		sv.UpdateField(field)
+	}
+}
+
 // Config configures the view
 func (sv *StructView) Config() {
 	if ks, ok := sv.Struct.(ki.Ki); ok {
@@ -224,10 +247,16 @@ func (sv *StructView) ConfigStructGrid() {
 	sg.SetMinPrefWidth(units.NewEm(10))
 	sg.SetStretchMax()                          // for this to work, ALL layers above need it too
 	sg.SetProp("overflow", gist.OverflowScroll) // this still gives it true size during PrefSize
-	sg.SetProp("columns", 2)
 	config := kit.TypeAndNameList{}
+	verrs := make(map[uintptr]error) // values that were not set, kept across configs
+	for _, vv := range sv.FieldViews {
+		if vvb := vv.AsValueViewBase(); vvb.ValidErr != nil {
+			verrs[vvb.Value.Pointer()] = vvb.ValidErr
+		}
+	}
 	// always start fresh!
 	sv.FieldViews = make([]ValueView, 0)
+	_, sv.HasValids = sv.Struct.(Validator)
 	kit.FlatFieldsValueFunc(sv.Struct, func(fval interface{}, typ reflect.Type, field reflect.StructField, fieldVal reflect.Value) bool {
 		// todo: check tags, skip various etc
 		ftags := sv.FieldTags(field)
@@ -243,17 +272,24 @@ func (sv *StructView) ConfigStructGrid() {
 		}
 		if vwtag == "add-fields" && field.Type.Kind() == reflect.Struct {
 			fvalp := fieldVal.Addr().Interface()
+			if _, ok := fvalp.(Validator); ok {
+				sv.HasValids = true
+			}
 			kit.FlatFieldsValueFunc(fvalp, func(sfval interface{}, styp reflect.Type, sfield reflect.StructField, sfieldVal reflect.Value) bool {
 				svwtag := sfield.Tag.Get("view")
 				if svwtag == "-" {
 					return true
 				}
-				svv := FieldToValueView(fvalp, sfield.Name, sfval)
+				svv := FieldToValueView(fvalp, sfield.Name, fieldViewVal(sfval, sfieldVal))
 				if svv == nil { // shouldn't happen
 					return true
 				}
+				if _, has := sfield.Tag.Lookup("validate"); has {
+					sv.HasValids = true
+				}
 				svvp := sfieldVal.Addr()
 				svv.SetStructValue(svvp, fvalp, &sfield, sv.TmpSave, sv.ViewPath)
+				svv.AsValueViewBase().ValidErr = verrs[svvp.Pointer()]
 
 				svtyp := svv.WidgetType()
 				// todo: other things with view tag..
@@ -268,12 +304,16 @@ func (sv *StructView) ConfigStructGrid() {
 			})
 			return true
 		}
-		vv := FieldToValueView(sv.Struct, field.Name, fval)
+		vv := FieldToValueView(sv.Struct, field.Name, fieldViewVal(fval, fieldVal))
 		if vv == nil { // shouldn't happen
 			return true
 		}
+		if _, has := ftags.Lookup("validate"); has {
+			sv.HasValids = true
+		}
 		vvp := fieldVal.Addr()
 		vv.SetStructValue(vvp, sv.Struct, &field, sv.TmpSave, sv.ViewPath)
+		vv.AsValueViewBase().ValidErr = verrs[vvp.Pointer()]
 		vtyp := vv.WidgetType()
 		// todo: other things with view tag..
 		labnm := fmt.Sprintf("label-%v", field.Name)
@@ -283,6 +323,18 @@ func (sv *StructView) ConfigStructGrid() {
 		sv.FieldViews = append(sv.FieldViews, vv)
 		return true
 	})
+	ncol := sv.GridCols()
+	sg.SetProp("columns", ncol)
+	if sv.HasValids { // add error labels after the values
+		vcfg := kit.TypeAndNameList{}
+		for i, tn := range config {
+			vcfg = append(vcfg, tn)
+			if i%2 == 1 {
+				vcfg.Add(gi.KiT_Label, "error-"+strings.TrimPrefix(tn.Name, "value-"))
+			}
+		}
+		config = vcfg
+	}
 	mods, updt := sg.ConfigChildren(config, ki.NonUniqueNames) // fields could be non-unique with labels..
 	if mods {
 		sg.SetFullReRender()
@@ -291,20 +343,32 @@ func (sv *StructView) ConfigStructGrid() {
 	}
 	sv.HasDefs = false
 	for i, vv := range sv.FieldViews {
-		lbl := sg.Child(i * 2).(*gi.Label)
+		lbl := sg.Child(i * ncol).(*gi.Label)
 		vvb := vv.AsValueViewBase()
 		vvb.ViewPath = sv.ViewPath
 		lbl.Redrawable = true
-		widg := sg.Child((i * 2) + 1).(gi.Node2D)
+		widg := sg.Child((i * ncol) + 1).(gi.Node2D)
 		widg.SetProp("horizontal-align", gist.AlignLeft)
 		hasDef, inactTag := StructViewFieldTags(vv, lbl, widg, sv.IsInactive())
 		if hasDef {
 			sv.HasDefs = true
 		}
 		vv.ConfigWidget(widg)
+		if sv.HasValids {
+			elbl := sg.Child((i * ncol) + 2).(*gi.Label)
+			elbl.Redrawable = true
+			elbl.SetProp("color", ValidErrColor)
+			elbl.SetProp("vertical-align", gist.AlignMiddle)
+		}
 		if !sv.IsInactive() && !inactTag {
 			vvb.ViewSig.ConnectOnly(sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 				svv := recv.Embed(KiT_StructView).(*StructView)
+				if svv.HasValids {
+					svv.ValidSig.Emit(svv.This(), 0, svv.UpdateValids())
+					if send.(ValueView).AsValueViewBase().ValidErr != nil {
+						return // value was not set
+					}
+				}
 				svv.UpdateDefaults()
 				// note: updating vv here is redundant -- relevant field will have already updated
 				svv.Changed = true
@@ -312,6 +376,12 @@ func (sv *StructView) ConfigStructGrid() {
 					svv.ChangeFlag.SetBool(true)
 				}
 				vvv := send.(ValueView).AsValueViewBase()
+				NotifyChangeFrom(svv.Struct, vvv.Field.Name, svv.This())
+				stru, fld := svv.Struct, vvv.Field.Name
+				SaveValueUndo(ViewUndoMgr(svv.This().(gi.Node2D)), send.(ValueView), func() {
+					NotifyChange(stru, fld)
+					undoUpdtKi(stru)
+				})
 				if !kit.KindIsBasic(kit.NonPtrValue(vvv.Value).Kind()) {
 					if updtr, ok := svv.Struct.(gi.Updater); ok {
 						// fmt.Printf("updating: %v kind: %v\n", updtr, vvv.Value.Kind())
@@ -328,9 +398,19 @@ func (sv *StructView) ConfigStructGrid() {
 			})
 		}
 	}
+	sv.UpdateValids()
 	sg.UpdateEnd(updt)
 }
 
+// GridCols returns the number of columns of the StructGrid: 2 for the labels
+// and values of the fields, and a third for their errors, if HasValids
+func (sv *StructView) GridCols() int {
+	if sv.HasValids {
+		return 3
+	}
+	return 2
+}
+
 func (sv *StructView) Style2D() {
 	mvp := sv.ViewportSafe()
 	if mvp != nil && mvp.IsDoingFullRender() {
@@ -344,14 +424,66 @@ func (sv *StructView) UpdateDefaults() {
 		return
 	}
 	sg := sv.StructGrid()
+	ncol := sv.GridCols()
 	updt := sg.UpdateStart()
 	for i, vv := range sv.FieldViews {
-		lbl := sg.Child(i * 2).(*gi.Label)
+		lbl := sg.Child(i * ncol).(*gi.Label)
 		StructViewFieldDefTag(vv, lbl)
 	}
 	sg.UpdateEnd(updt)
 }
 
+// UpdateValids shows the errors of the fields that are not valid next to
+// them, if HasValids -- returns true if all fields are valid
+func (sv *StructView) UpdateValids() bool {
+	if !sv.HasValids || !sv.IsConfiged() {
+		return true
+	}
+	sg := sv.StructGrid()
+	if len(sg.Kids) < 3*len(sv.FieldViews) {
+		return sv.IsValid()
+	}
+	updt := sg.UpdateStart()
+	valid := true
+	for i, vv := range sv.FieldViews {
+		elbl := sg.Child(i*3 + 2).(*gi.Label)
+		etxt := ""
+		if err := StructViewFieldValidErr(vv); err != nil {
+			valid = false
+			etxt = err.Error()
+		}
+		if elbl.Text != etxt {
+			elbl.SetText(etxt)
+			sg.SetFullReRender()
+		}
+	}
+	sg.UpdateEnd(updt)
+	return valid
+}
+
+// IsValid returns true if all the fields are valid -- see ValidErrors
+func (sv *StructView) IsValid() bool {
+	return len(sv.ValidErrors()) == 0
+}
+
+// ValidErrors returns the errors of all the fields that are not valid,
+// according to their validate tags and the Validator interface of the
+// struct, including any values that were not set because they are not valid
+// -- each error starts with the label of its field
+func (sv *StructView) ValidErrors() []error {
+	var errs []error
+	for _, vv := range sv.FieldViews {
+		if err := StructViewFieldValidErr(vv); err != nil {
+			lbl, has := vv.Tag("label")
+			if !has {
+				lbl = vv.AsValueViewBase().Field.Name
+			}
+			errs = append(errs, fmt.Errorf("%v: %v", lbl, err))
+		}
+	}
+	return errs
+}
+
 func (sv *StructView) Render2D() {
 	if sv.IsConfiged() {
 		sv.ToolBar().UpdateActions()
@@ -394,6 +526,17 @@ func StructViewFieldTags(vv ValueView, lbl *gi.Label, widg gi.Node2D, isInact bo
 	return
 }
 
+// StructViewFieldValidErr returns the error of the field of given value
+// view if it is not valid: of a value that was not set because it is not
+// valid, or of the current value -- nil if it is valid
+func StructViewFieldValidErr(vv ValueView) error {
+	vvb := vv.AsValueViewBase()
+	if vvb.ValidErr != nil {
+		return vvb.ValidErr
+	}
+	return vvb.Validate()
+}
+
 // StructViewFieldDefTag processes the "def" tag for default values -- can be
 // called multiple times for updating as values change.
 // returns true if value is default, and string to add to tooltip for default vals
@@ -76,7 +76,7 @@ func (sv *StructViewInline) ConfigParts() {
 		if vwtag == "-" {
 			return true
 		}
-		vv := FieldToValueView(sv.Struct, field.Name, fval)
+		vv := FieldToValueView(sv.Struct, field.Name, fieldViewVal(fval, fieldVal))
 		if vv == nil { // shouldn't happen
 			return true
 		}
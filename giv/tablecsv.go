@@ -0,0 +1,320 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  CSV / TSV of slices of structs
+
+// CSVSep returns the separator of the values in each row of the CSV or TSV
+// file with given name: '\t' for the .tsv and .tab extensions, else ','
+func CSVSep(filename string) rune {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".tsv", ".tab":
+		return '\t'
+	}
+	return ','
+}
+
+// CSVFields returns the fields of given struct type that are written to, and
+// read from, CSV files: the exported fields, including those of embedded
+// structs, except those with a view:"-" or tableview:"-" tag
+func CSVFields(styp reflect.Type) []reflect.StructField {
+	var flds []reflect.StructField
+	kit.FlatFieldsTypeFunc(styp, func(typ reflect.Type, fld reflect.StructField) bool {
+		if fld.PkgPath != "" || fld.Tag.Get("view") == "-" || fld.Tag.Get("tableview") == "-" {
+			return true
+		}
+		if typ != styp {
+			rfld, has := styp.FieldByName(fld.Name)
+			if !has {
+				return true
+			}
+			fld = rfld
+		}
+		flds = append(flds, fld)
+		return true
+	})
+	return flds
+}
+
+// csvColName returns the normalized name of a column or field, for matching
+// the header of a CSV file to the fields: lower case without spaces, _ or -
+func csvColName(nm string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.TrimSpace(nm)))
+}
+
+// sliceStructType returns the struct type of the elements of the slice that
+// given slc points to, or an error
+func sliceStructType(slc interface{}) (reflect.Type, error) {
+	slpTyp := reflect.TypeOf(slc)
+	if slpTyp == nil || slpTyp.Kind() != reflect.Ptr || slpTyp.Elem().Kind() != reflect.Slice {
+		return nil, fmt.Errorf("must be a pointer to a slice of structs, not: %v", slpTyp)
+	}
+	styp := kit.NonPtrType(slpTyp.Elem().Elem())
+	if styp.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("must be a pointer to a slice of structs, not: %v", slpTyp)
+	}
+	return styp, nil
+}
+
+// ReadSliceCSV reads CSV or TSV data with given separator between the values
+// in each row into the slice of structs, or pointers to structs, that slc
+// points to, replacing its elements -- the first row is a header with the
+// names of the fields for each column, matched ignoring case, spaces and _,
+// and columns without a matching field are ignored.  Values are converted
+// to the types of the fields as for pasting cells in a TableView (see
+// SetTableCellText), and empty values are the zero value -- all rows are
+// read even if some values cannot be converted, which is reported in the
+// error returned.
+func ReadSliceCSV(slc interface{}, r io.Reader, sep rune) error {
+	styp, err := sliceStructType(slc)
+	if err != nil {
+		return fmt.Errorf("giv.ReadSliceCSV: %v", err)
+	}
+	rd := csv.NewReader(r)
+	rd.Comma = sep
+	rd.FieldsPerRecord = -1
+	rd.LazyQuotes = true
+	hdr, err := rd.Read()
+	if err != nil {
+		return fmt.Errorf("giv.ReadSliceCSV: could not read header: %v", err)
+	}
+	fmap := make(map[string][]int)
+	for _, fld := range CSVFields(styp) {
+		fmap[csvColName(fld.Name)] = fld.Index
+	}
+	cols := make([][]int, len(hdr))
+	nmatch := 0
+	for ci, nm := range hdr {
+		if fidx, has := fmap[csvColName(nm)]; has {
+			cols[ci] = fidx
+			nmatch++
+		}
+	}
+	if nmatch == 0 {
+		return fmt.Errorf("giv.ReadSliceCSV: no columns in header match fields of %v: %v", styp.Name(), strings.Join(hdr, ", "))
+	}
+	svl := reflect.ValueOf(slc).Elem()
+	eptr := svl.Type().Elem().Kind() == reflect.Ptr
+	nsl := reflect.MakeSlice(svl.Type(), 0, 0)
+	var cerr error
+	for row := 1; ; row++ {
+		rec, err := rd.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("giv.ReadSliceCSV: %v", err)
+		}
+		nv := reflect.New(styp)
+		for ci, str := range rec {
+			if ci >= len(cols) || cols[ci] == nil || strings.TrimSpace(str) == "" {
+				continue
+			}
+			if !SetTableCellText(nv.Elem().FieldByIndex(cols[ci]), str) && cerr == nil {
+				cerr = fmt.Errorf("giv.ReadSliceCSV: row %v, column %v: could not convert value: %q", row, hdr[ci], str)
+			}
+		}
+		if eptr {
+			nsl = reflect.Append(nsl, nv)
+		} else {
+			nsl = reflect.Append(nsl, nv.Elem())
+		}
+	}
+	svl.Set(nsl)
+	return cerr
+}
+
+// OpenSliceCSV reads the CSV or TSV file with given name into the slice of
+// structs that slc points to -- see ReadSliceCSV -- the separator is from
+// the extension of the file (see CSVSep)
+func OpenSliceCSV(slc interface{}, filename gi.FileName) error {
+	fp, err := os.Open(string(filename))
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	return ReadSliceCSV(slc, fp, CSVSep(string(filename)))
+}
+
+// WriteSliceCSV writes the slice of structs, or pointers to structs, that
+// slc points to as CSV or TSV data with given separator between the values
+// in each row, with a header row of the names of the fields (see CSVFields)
+func WriteSliceCSV(slc interface{}, w io.Writer, sep rune) error {
+	styp, err := sliceStructType(slc)
+	if err != nil {
+		return fmt.Errorf("giv.WriteSliceCSV: %v", err)
+	}
+	svl := reflect.ValueOf(slc).Elem()
+	return writeCSV(w, sep, CSVFields(styp), svl.Len(), func(i int) reflect.Value {
+		return kit.OnePtrUnderlyingValue(svl.Index(i)).Elem()
+	})
+}
+
+// SaveSliceCSV saves the slice of structs that slc points to in the CSV or
+// TSV file with given name -- see WriteSliceCSV -- the separator is from the
+// extension of the file (see CSVSep)
+func SaveSliceCSV(slc interface{}, filename gi.FileName) error {
+	fp, err := os.Create(string(filename))
+	if err != nil {
+		return err
+	}
+	err = WriteSliceCSV(slc, fp, CSVSep(string(filename)))
+	if cerr := fp.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// writeCSV writes a header row with the names of given fields, and the
+// values of those fields of the n structs returned by given function --
+// invalid values are skipped
+func writeCSV(w io.Writer, sep rune, flds []reflect.StructField, n int, rowFun func(i int) reflect.Value) error {
+	wr := csv.NewWriter(w)
+	wr.Comma = sep
+	rec := make([]string, len(flds))
+	for fi, fld := range flds {
+		rec[fi] = fld.Name
+	}
+	wr.Write(rec)
+	for i := 0; i < n; i++ {
+		rv := rowFun(i)
+		if !rv.IsValid() {
+			continue
+		}
+		for fi, fld := range flds {
+			rec[fi] = TableCellText(rv.FieldByIndex(fld.Index))
+		}
+		wr.Write(rec)
+	}
+	wr.Flush()
+	return wr.Error()
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  TableView import / export
+
+// WriteCSV writes the rows of the view, in their current order and without
+// those that are filtered out, as CSV or TSV data with given separator
+// between the values in each row -- the columns are the visible fields,
+// with a header row of their names
+func (tv *TableView) WriteCSV(w io.Writer, sep rune) error {
+	if kit.IfaceIsNil(tv.Slice) {
+		return errors.New("giv.TableView WriteCSV: no slice")
+	}
+	tv.UpdtSliceSize()
+	return writeCSV(w, sep, tv.VisFields, tv.SliceSize, func(i int) reflect.Value {
+		sli := tv.SliceIdx(i)
+		if sli < 0 {
+			return reflect.Value{}
+		}
+		return tv.RowVal(sli).Elem()
+	})
+}
+
+// SaveCSV exports the rows of the view to the CSV or TSV file with given
+// name -- see WriteCSV -- the separator is from the extension of the file
+// (see CSVSep)
+func (tv *TableView) SaveCSV(filename gi.FileName) error {
+	fp, err := os.Create(string(filename))
+	if err != nil {
+		return err
+	}
+	err = tv.WriteCSV(fp, CSVSep(string(filename)))
+	if cerr := fp.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// OpenCSV imports the CSV or TSV file with given name into the Slice,
+// replacing its elements -- see ReadSliceCSV -- this can be undone
+func (tv *TableView) OpenCSV(filename gi.FileName) error {
+	if kit.IfaceIsNil(tv.Slice) || tv.Source != nil || tv.isArray {
+		return errors.New("giv.TableView OpenCSV: can only import into a slice")
+	}
+	prv := reflect.New(tv.SliceNPVal.Type()).Elem()
+	prv.Set(tv.SliceNPVal)
+	err := OpenSliceCSV(tv.Slice, filename)
+	if _, ok := err.(*os.PathError); ok {
+		return err
+	}
+	slc := tv.Slice
+	nv := reflect.New(prv.Type()).Elem()
+	nv.Set(kit.NonPtrValue(reflect.ValueOf(slc)))
+	if nv.Len() == prv.Len() && (nv.Len() == 0 || nv.Pointer() == prv.Pointer()) {
+		return err // not read
+	}
+	ViewUndoMgr(tv.This().(gi.Node2D)).Save(gi.T("Import CSV"), func() {
+		kit.NonPtrValue(reflect.ValueOf(slc)).Set(prv)
+		NotifyChange(slc, "")
+	}, func() {
+		kit.NonPtrValue(reflect.ValueOf(slc)).Set(nv)
+		NotifyChange(slc, "")
+	})
+	tv.SliceNPVal = kit.NonPtrValue(reflect.ValueOf(tv.Slice))
+	tv.ResetSelectedIdxs()
+	tv.CellSel = nil
+	tv.StartIdx = 0
+	if tv.TmpSave != nil {
+		tv.TmpSave.SaveTmp()
+	}
+	tv.SetChanged()
+	tv.ScrollBar().SetFullReRender()
+	tv.ModelChanged("")
+	return err
+}
+
+// CSVFileExts are the extensions of the files shown for importing and
+// exporting CSV and TSV files
+var CSVFileExts = ".csv,.tsv,.tab"
+
+// ImportCSVDialog opens a dialog for choosing a CSV or TSV file to import
+// into the Slice -- see OpenCSV -- errors are shown in a prompt dialog
+func (tv *TableView) ImportCSVDialog() {
+	FileViewDialog(tv.ViewportSafe(), "", CSVFileExts, DlgOpts{Title: gi.T("Import CSV"), Prompt: gi.T("Choose a CSV or TSV file, with a header row of field names, to replace the rows of the table")}, nil,
+		tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			tvv := recv.Embed(KiT_TableView).(*TableView)
+			fn := FileViewDialogValue(send.Embed(gi.KiT_Dialog).(*gi.Dialog))
+			if err := tvv.OpenCSV(gi.FileName(fn)); err != nil {
+				gi.PromptDialog(tvv.ViewportSafe(), gi.DlgOpts{Title: gi.T("Import CSV"), Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+			}
+		})
+}
+
+// ExportCSVDialog opens a dialog for choosing the CSV or TSV file to export
+// the rows of the view to -- see SaveCSV -- errors are shown in a prompt
+// dialog
+func (tv *TableView) ExportCSVDialog() {
+	FileViewDialog(tv.ViewportSafe(), "", CSVFileExts, DlgOpts{Title: gi.T("Export CSV"), Prompt: gi.T("Name of the CSV or TSV file (by extension) to export the rows of the table to"), Save: true}, nil,
+		tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			tvv := recv.Embed(KiT_TableView).(*TableView)
+			fn := FileViewDialogValue(send.Embed(gi.KiT_Dialog).(*gi.Dialog))
+			if err := tvv.SaveCSV(gi.FileName(fn)); err != nil {
+				gi.PromptDialog(tvv.ViewportSafe(), gi.DlgOpts{Title: gi.T("Export CSV"), Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+			}
+		})
+}
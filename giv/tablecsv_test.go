@@ -0,0 +1,140 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type csvTestEmbed struct {
+	Note string
+}
+
+type csvTestRow struct {
+	csvTestEmbed
+	Name  string
+	Count int
+	Ratio float64
+	On    bool
+	Dur   time.Duration
+	Skip  string `view:"-"`
+	priv  int
+}
+
+func TestCSVSep(t *testing.T) {
+	tests := []struct {
+		fn  string
+		sep rune
+	}{
+		{"a.csv", ','},
+		{"a.tsv", '\t'},
+		{"dir/a.TAB", '\t'},
+		{"a.txt", ','},
+		{"a", ','},
+	}
+	for _, ts := range tests {
+		if sep := CSVSep(ts.fn); sep != ts.sep {
+			t.Errorf("%s: sep: %q != expected: %q", ts.fn, sep, ts.sep)
+		}
+	}
+}
+
+func TestCSVFields(t *testing.T) {
+	var nms []string
+	for _, fld := range CSVFields(reflect.TypeOf(csvTestRow{})) {
+		nms = append(nms, fld.Name)
+	}
+	exp := []string{"Note", "Name", "Count", "Ratio", "On", "Dur"}
+	if !reflect.DeepEqual(nms, exp) {
+		t.Errorf("fields: %v != expected: %v", nms, exp)
+	}
+}
+
+func TestReadSliceCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		sep  rune
+		rows []csvTestRow
+		err  bool
+	}{
+		{"basic", "Name,Count,Ratio,On,Dur\na,1,0.5,true,2s\nb,2,1.5,false,1m\n", ',',
+			[]csvTestRow{{Name: "a", Count: 1, Ratio: 0.5, On: true, Dur: 2 * time.Second},
+				{Name: "b", Count: 2, Ratio: 1.5, Dur: time.Minute}}, false},
+		{"header names", "name, COUNT ,r_a-tio,note,extra\na,3,2,n,x\n", ',',
+			[]csvTestRow{{csvTestEmbed: csvTestEmbed{Note: "n"}, Name: "a", Count: 3, Ratio: 2}}, false},
+		{"quoted", "Name,Note\n\"a, b\",\"say \"\"hi\"\"\"\n", ',',
+			[]csvTestRow{{csvTestEmbed: csvTestEmbed{Note: `say "hi"`}, Name: "a, b"}}, false},
+		{"empty values", "Name,Count,On\na,,\n,5, \n", ',',
+			[]csvTestRow{{Name: "a"}, {Count: 5}}, false},
+		{"short and long rows", "Name,Count\na\nb,2,extra\n", ',',
+			[]csvTestRow{{Name: "a"}, {Name: "b", Count: 2}}, false},
+		{"tsv", "Name\tCount\na b\t4\n", '\t',
+			[]csvTestRow{{Name: "a b", Count: 4}}, false},
+		{"skipped fields", "Name,Skip,priv\na,s,1\n", ',',
+			[]csvTestRow{{Name: "a"}}, false},
+		{"bad value", "Name,Count\na,x\nb,2\n", ',',
+			[]csvTestRow{{Name: "a"}, {Name: "b", Count: 2}}, true},
+		{"no matching columns", "Foo,Bar\n1,2\n", ',', nil, true},
+		{"no header", "", ',', nil, true},
+	}
+	for _, ts := range tests {
+		var rows []csvTestRow
+		err := ReadSliceCSV(&rows, strings.NewReader(ts.csv), ts.sep)
+		if (err != nil) != ts.err {
+			t.Errorf("%s: error: %v, expected error: %v", ts.name, err, ts.err)
+		}
+		if len(rows) == 0 && len(ts.rows) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(rows, ts.rows) {
+			t.Errorf("%s: rows: %+v != expected: %+v", ts.name, rows, ts.rows)
+		}
+	}
+}
+
+func TestReadSliceCSVPtrs(t *testing.T) {
+	rows := []*csvTestRow{{Name: "old"}}
+	if err := ReadSliceCSV(&rows, strings.NewReader("Name\na\nb\n"), ','); err != nil {
+		t.Error(err)
+	}
+	if len(rows) != 2 || rows[0].Name != "a" || rows[1].Name != "b" {
+		t.Errorf("pointer rows: %+v != expected: a, b", rows)
+	}
+	var nsl []int
+	if err := ReadSliceCSV(&nsl, strings.NewReader("a\n1\n"), ','); err == nil {
+		t.Errorf("slice of ints: no error")
+	}
+	if err := ReadSliceCSV(rows, strings.NewReader("Name\na\n"), ','); err == nil {
+		t.Errorf("non-pointer slice: no error")
+	}
+}
+
+func TestWriteSliceCSV(t *testing.T) {
+	rows := []csvTestRow{
+		{csvTestEmbed: csvTestEmbed{Note: "n"}, Name: "a, b", Count: 1, Ratio: 0.5, On: true, Dur: 2 * time.Second, Skip: "s"},
+		{Name: `say "hi"`, Count: -2},
+	}
+	var b bytes.Buffer
+	if err := WriteSliceCSV(&rows, &b, ','); err != nil {
+		t.Fatal(err)
+	}
+	exp := "Note,Name,Count,Ratio,On,Dur\nn,\"a, b\",1,0.5,true,2s\n,\"say \"\"hi\"\"\",-2,0,false,0s\n"
+	if b.String() != exp {
+		t.Errorf("csv: %q != expected: %q", b.String(), exp)
+	}
+	var rrows []csvTestRow
+	if err := ReadSliceCSV(&rrows, &b, ','); err != nil {
+		t.Error(err)
+	}
+	rows[0].Skip = ""
+	if !reflect.DeepEqual(rrows, rows) {
+		t.Errorf("read back: %+v != expected: %+v", rrows, rows)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"reflect"
+
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/ki/kit"
+)
+
+// TableSource is a source of rows for a TableView that are provided on
+// demand, instead of as a slice of structs -- e.g., for tables with millions
+// of rows that are read from a file or database a page at a time.  The
+// TableView only ever asks for the rows that are visible, so the cost of
+// displaying and scrolling does not depend on the number of rows.  Rows
+// cannot be added, deleted, moved or pasted through the view, but the
+// fields of each row can be edited as usual.
+type TableSource interface {
+	// RowType returns the (non-pointer) struct type of each row
+	RowType() reflect.Type
+
+	// NumRows returns the total number of rows
+	NumRows() int
+
+	// Row returns a pointer to the struct for given row, or nil if it is not
+	// available yet (e.g., its page is still loading), in which case it is
+	// shown as blank -- call TableView.Update when it becomes available
+	Row(idx int) interface{}
+}
+
+// TableSourcePager is an optional interface for a TableSource that loads
+// rows in pages: PageRows is called with the range of rows [st, ed) that are
+// about to be displayed, before Row is called for each of them
+type TableSourcePager interface {
+	PageRows(st, ed int)
+}
+
+// TableSourceSorter is an optional interface for a TableSource that can sort
+// its rows, by the field of given name -- otherwise the TableView cannot be
+// sorted by clicking on the column headers
+type TableSourceSorter interface {
+	SortRows(field string, ascending bool)
+}
+
+// SetSource sets the source of rows that we are viewing, instead of a slice
+// -- see TableSource
+func (tv *TableView) SetSource(src TableSource) {
+	if kit.IfaceIsNil(src) {
+		tv.Source = nil
+		tv.Slice = nil
+		return
+	}
+	// the Slice is an empty slice of the row type, for the type information
+	sl := reflect.New(reflect.SliceOf(reflect.PtrTo(src.RowType()))).Interface()
+	tv.SetSlice(sl)
+	updt := tv.UpdateStart()
+	tv.Source = src
+	tv.isArray = true // no structural changes
+	tv.NoAdd = true
+	tv.NoDelete = true
+	tv.SetFullReRender()
+	tv.Config()
+	tv.UpdateEnd(updt)
+}
+
+// RowVal returns the pointer to the struct for given slice index, from the
+// Source if set, or the Slice -- returns a blank struct for Source rows
+// that are not available, and indexes < 0 (e.g., group headers)
+func (tv *TableView) RowVal(idx int) reflect.Value {
+	if idx < 0 {
+		return reflect.New(tv.StruType)
+	}
+	if tv.Source == nil {
+		return kit.OnePtrUnderlyingValue(tv.SliceNPVal.Index(idx)) // deal with pointer lists
+	}
+	if row := tv.Source.Row(idx); !kit.IfaceIsNil(row) {
+		return reflect.ValueOf(row)
+	}
+	return reflect.New(tv.StruType)
+}
+
+// UpdtSliceSize updates and returns the size of the slice and sets SliceSize
+func (tv *TableView) UpdtSliceSize() int {
+	switch {
+	case tv.Source != nil:
+		tv.SliceSize = tv.Source.NumRows()
+	case tv.ViewIdxs != nil:
+		if tv.SliceNPVal.Len() != tv.viewSrcLen {
+			tv.UpdateViewIdxs()
+		}
+		tv.SliceSize = len(tv.ViewIdxs)
+	default:
+		return tv.SliceViewBase.UpdtSliceSize()
+	}
+	return tv.SliceSize
+}
+
+// SliceVal returns value interface at given slice index
+// must be protected by mutex
+func (tv *TableView) SliceVal(idx int) interface{} {
+	if tv.Source == nil && tv.ViewIdxs == nil {
+		return tv.SliceViewBase.SliceVal(idx)
+	}
+	if idx < 0 || idx >= tv.SliceSize || tv.SliceIdx(idx) < 0 {
+		return nil
+	}
+	return tv.RowVal(tv.SliceIdx(idx)).Interface()
+}
+
+// PasteAssign assigns mime data (only the first one!) to this idx
+func (tv *TableView) PasteAssign(md mimedata.Mimes, idx int) {
+	idx = tv.SliceIdx(idx)
+	if tv.Source != nil || idx < 0 {
+		return
+	}
+	tv.SliceViewBase.PasteAssign(md, idx)
+	tv.UpdateViewIdxs()
+}
+
+// PasteAtIdx inserts object(s) from mime data at (before) given slice index
+func (tv *TableView) PasteAtIdx(md mimedata.Mimes, idx int) {
+	if tv.Source != nil {
+		return
+	}
+	tv.SliceViewBase.PasteAtIdx(md, tv.InsertSliceIdx(idx))
+}
@@ -41,13 +41,23 @@ import (
 // set prop toolbar = false to turn off
 type TableView struct {
 	SliceViewBase
-	StyleFunc  TableViewStyleFunc    `copy:"-" view:"-" json:"-" xml:"-" desc:"optional styling function"`
-	SelField   string                `copy:"-" view:"-" json:"-" xml:"-" desc:"current selection field -- initially select value in this field"`
-	SortIdx    int                   `desc:"current sort index"`
-	SortDesc   bool                  `desc:"whether current sort order is descending"`
-	StruType   reflect.Type          `copy:"-" view:"-" json:"-" xml:"-" desc:"struct type for each row"`
-	VisFields  []reflect.StructField `copy:"-" view:"-" json:"-" xml:"-" desc:"the visible fields"`
-	NVisFields int                   `copy:"-" view:"-" json:"-" xml:"-" desc:"number of visible fields"`
+	StyleFunc       TableViewStyleFunc       `copy:"-" view:"-" json:"-" xml:"-" desc:"optional styling function"`
+	SelField        string                   `copy:"-" view:"-" json:"-" xml:"-" desc:"current selection field -- initially select value in this field"`
+	SortIdx         int                      `desc:"current sort index"`
+	SortDesc        bool                     `desc:"whether current sort order is descending"`
+	StruType        reflect.Type             `copy:"-" view:"-" json:"-" xml:"-" desc:"struct type for each row"`
+	VisFields       []reflect.StructField    `copy:"-" view:"-" json:"-" xml:"-" desc:"the visible fields"`
+	NVisFields      int                      `copy:"-" view:"-" json:"-" xml:"-" desc:"number of visible fields"`
+	Source          TableSource              `copy:"-" view:"-" json:"-" xml:"-" desc:"source of rows provided on demand, if set by SetSource instead of a slice -- see TableSource"`
+	SortKeys        []TableSortKey           `desc:"additional fields to sort by after the SortIdx field, for rows with equal values in it -- see ThenSortBy"`
+	Filters         []*TableFilter           `desc:"filters on the values of fields -- only rows that match all of them are shown -- see SetFilter"`
+	GroupField      string                   `desc:"name of the field to group rows by, with a collapsible header row for each distinct value -- see GroupBy"`
+	Groups          []TableGroup             `copy:"-" view:"-" json:"-" xml:"-" desc:"the groups of rows, when grouping by GroupField"`
+	GroupsCollapsed map[string]bool          `copy:"-" view:"-" json:"-" xml:"-" desc:"values of GroupField for the groups that are collapsed"`
+	ViewIdxs        []int                    `copy:"-" view:"-" json:"-" xml:"-" desc:"slice index for each row of the view when filtering or grouping, with -1-g for the header of group g -- nil otherwise, when the view and slice indexes are the same"`
+	ColViews        map[string]ValueViewFunc `copy:"-" view:"-" json:"-" xml:"-" desc:"custom cell editor / viewer ValueViews for columns, by field name -- see SetColumnView"`
+	CellSel         *TableCellRange          `copy:"-" view:"-" json:"-" xml:"-" desc:"the selected rectangular range of cells, for spreadsheet-style copy / paste and fill -- nil if none -- see SelectCells"`
+	viewSrcLen      int
 }
 
 var KiT_TableView = kit.Types.AddType(&TableView{}, TableViewProps)
@@ -70,19 +80,29 @@ type TableViewStyleFunc func(tv *TableView, slice interface{}, widg gi.Node2D, r
 // to represent this slice (does Update if already viewing).
 func (tv *TableView) SetSlice(sl interface{}) {
 	if kit.IfaceIsNil(sl) {
+		tv.BindSlice(nil)
 		tv.Slice = nil
+		tv.Source = nil
 		return
 	}
 	if tv.Slice == sl && tv.IsConfiged() {
 		tv.Update()
 		return
 	}
+	tv.Source = nil
+	tv.isArray = false
 	if !tv.IsInactive() {
 		tv.SelectedIdx = -1
 	}
 	tv.StartIdx = 0
 	tv.SortIdx = -1
 	tv.SortDesc = false
+	tv.SortKeys = nil
+	tv.Filters = nil
+	tv.GroupField = ""
+	tv.GroupsCollapsed = nil
+	tv.ViewIdxs = nil
+	tv.CellSel = nil
 	slpTyp := reflect.TypeOf(sl)
 	if slpTyp.Kind() != reflect.Ptr {
 		log.Printf("TableView requires that you pass a pointer to a slice of struct elements -- type is not a Ptr: %v\n", slpTyp.String())
@@ -92,6 +112,7 @@ func (tv *TableView) SetSlice(sl interface{}) {
 		log.Printf("TableView requires that you pass a pointer to a slice of struct elements -- ptr doesn't point to a slice: %v\n", slpTyp.Elem().String())
 		return
 	}
+	tv.BindSlice(sl)
 	tv.Slice = sl
 	tv.SliceNPVal = kit.NonPtrValue(reflect.ValueOf(tv.Slice))
 	struTyp := tv.StructType()
@@ -116,6 +137,16 @@ func (tv *TableView) SetSlice(sl interface{}) {
 	tv.UpdateEnd(updt)
 }
 
+// ModelChanged updates the view for a change to its slice, per BoundView,
+// applying the Filters and GroupField to the changed values
+func (tv *TableView) ModelChanged(field string) {
+	if tv.Slice == nil || !tv.IsConfiged() {
+		return
+	}
+	tv.UpdateViewIdxs()
+	tv.Update()
+}
+
 var TableViewProps = ki.Props{
 	"EnumType:Flag":    gi.KiT_NodeFlags,
 	"background-color": &gi.Prefs.Colors.Background,
@@ -169,6 +200,32 @@ func (tv *TableView) CacheVisFields() {
 	tv.NVisFields = len(tv.VisFields)
 }
 
+// SetColumnView sets the function that makes the ValueView for the cells of
+// the column of given field, overriding the ValueView for the type of the
+// field, and its view tag -- e.g., a ProgressValueView for a float column --
+// this takes effect when the grid is next configured, e.g., by SetSlice
+func (tv *TableView) SetColumnView(field string, fun ValueViewFunc) {
+	if tv.ColViews == nil {
+		tv.ColViews = make(map[string]ValueViewFunc)
+	}
+	tv.ColViews[field] = fun
+}
+
+// ColumnValueView returns a new ValueView for a cell of the column of given
+// visible field index, with given field value -- uses SetColumnView custom
+// views, and otherwise the tags of the field, e.g., view:"progress"
+func (tv *TableView) ColumnValueView(fli int, fval reflect.Value) ValueView {
+	field := tv.VisFields[fli]
+	if vvf, has := tv.ColViews[field.Name]; has {
+		return vvf()
+	}
+	tags := string(field.Tag)
+	if _, has := field.Tag.Lookup("view"); !has && (fval.Kind() == reflect.Slice || fval.Kind() == reflect.Map) {
+		tags += ` view:"no-inline"`
+	}
+	return ToValueView(fieldViewVal(fval.Interface(), fval), tags)
+}
+
 // IsConfiged returns true if the widget is fully configured
 func (tv *TableView) IsConfiged() bool {
 	if len(tv.Kids) == 0 {
@@ -264,7 +321,7 @@ func (tv *TableView) ConfigSliceGrid() {
 	tv.CacheVisFields()
 
 	sz := tv.This().(SliceViewer).UpdtSliceSize()
-	if sz == 0 {
+	if sz == 0 && tv.ViewIdxs == nil {
 		return
 	}
 
@@ -368,10 +425,10 @@ func (tv *TableView) ConfigSliceGrid() {
 			tvv.SortSliceAction(fldIdx)
 		})
 
-		val := kit.OnePtrUnderlyingValue(tv.SliceNPVal.Index(0)) // deal with pointer lists
+		val := tv.RowVal(tv.SliceIdx(0))
 		stru := val.Interface()
 		fval := val.Elem().FieldByIndex(field.Index)
-		vv := ToValueView(fval.Interface(), "")
+		vv := tv.ColumnValueView(fli, fval)
 		if vv == nil { // shouldn't happen
 			continue
 		}
@@ -411,6 +468,8 @@ func (tv *TableView) ConfigSliceGrid() {
 	if tv.SortIdx >= 0 {
 		tv.SortSlice()
 	}
+	tv.UpdateViewIdxs()
+	tv.UpdateHeaders()
 	tv.ConfigScroll()
 }
 
@@ -461,16 +520,42 @@ func (tv *TableView) LayoutSliceGrid() bool {
 	updt := sg.UpdateStart()
 	defer sg.UpdateEnd(updt)
 	if tv.Values == nil || sg.NumChildren() != nWidg {
-		sg.DeleteChildren(ki.DestroyKids)
-
-		tv.Values = make([]ValueView, tv.NVisFields*tv.DispRows)
-		sg.Kids = make(ki.Slice, nWidg)
+		tv.ResizeSliceGrid(nWidgPerRow)
 	}
 	tv.ConfigScroll()
 	tv.LayoutHeader()
 	return true
 }
 
+// ResizeSliceGrid sets the number of rows of widgets in the grid to
+// DispRows, keeping the widgets of existing rows to be reused for the new
+// range of rows -- widgets for new rows are made in UpdateSliceGrid
+func (tv *TableView) ResizeSliceGrid(nWidgPerRow int) {
+	sg := tv.SliceGrid()
+	nvals := tv.NVisFields * tv.DispRows
+	nWidg := nWidgPerRow * tv.DispRows
+	nold := sg.NumChildren()
+	if tv.Values == nil || nold%nWidgPerRow != 0 || len(tv.Values) != tv.NVisFields*(nold/nWidgPerRow) {
+		sg.DeleteChildren(ki.DestroyKids)
+		tv.Values = make([]ValueView, nvals)
+		sg.Kids = make(ki.Slice, nWidg)
+		return
+	}
+	if nWidg < nold {
+		for i := nold - 1; i >= nWidg; i-- {
+			if sg.Kids[i] != nil {
+				sg.DeleteChildAtIndex(i, ki.DestroyKids)
+			} else {
+				sg.Kids = sg.Kids[:i]
+			}
+		}
+		tv.Values = tv.Values[:nvals]
+		return
+	}
+	sg.Kids = append(sg.Kids, make(ki.Slice, nWidg-nold)...)
+	tv.Values = append(tv.Values, make([]ValueView, nvals-len(tv.Values))...)
+}
+
 // LayoutHeader updates the header layout based on field widths
 func (tv *TableView) LayoutHeader() {
 	_, idxOff := tv.RowWidgetNs()
@@ -545,15 +630,21 @@ func (tv *TableView) UpdateSliceGrid() {
 		tv.StartIdx = 0
 	}
 
+	if pgr, ok := tv.Source.(TableSourcePager); ok {
+		pgr.PageRows(tv.StartIdx, tv.StartIdx+tv.DispRows)
+	}
+
 	for i := 0; i < tv.DispRows; i++ {
 		ridx := i * nWidgPerRow
-		si := tv.StartIdx + i // slice idx
+		si := tv.StartIdx + i  // view idx
+		sli := tv.SliceIdx(si) // slice idx, -1 for group header
+		grp := tv.GroupAt(si)
 		issel := tv.IdxIsSelected(si)
-		val := kit.OnePtrUnderlyingValue(tv.SliceNPVal.Index(si)) // deal with pointer lists
+		val := tv.RowVal(sli)
 		stru := val.Interface()
 
 		itxt := fmt.Sprintf("%05d", i)
-		sitxt := fmt.Sprintf("%05d", si)
+		sitxt := fmt.Sprintf("%05d", sli)
 		labnm := fmt.Sprintf("index-%v", itxt)
 		if tv.ShowIndex {
 			var idxlab *gi.Label
@@ -571,18 +662,25 @@ func (tv *TableView) UpdateSliceGrid() {
 						wbb := send.(gi.Node2D).AsWidget()
 						row := wbb.Prop("tv-row").(int)
 						tvv := recv.Embed(KiT_TableView).(*TableView)
+						if tvv.ToggleGroupRow(row) {
+							return
+						}
 						tvv.UpdateSelectRow(row, wbb.IsSelected())
 					}
 				})
 			}
 			idxlab.CurBgColor = gi.Prefs.Colors.Background
 			idxlab.SetSelectedState(issel)
-			idxlab.SetText(sitxt)
+			if grp != nil {
+				idxlab.SetText(tv.GroupLabel(grp))
+			} else {
+				idxlab.SetText(sitxt)
+			}
 		}
 
 		vpath := tv.ViewPath + "[" + sitxt + "]"
 		if lblr, ok := tv.Slice.(gi.SliceLabeler); ok {
-			slbl := lblr.ElemLabel(si)
+			slbl := lblr.ElemLabel(sli)
 			if slbl != "" {
 				vpath = tv.ViewPath + "[" + slbl + "]"
 			}
@@ -593,11 +691,7 @@ func (tv *TableView) UpdateSliceGrid() {
 			vvi := i*tv.NVisFields + fli
 			var vv ValueView
 			if tv.Values[vvi] == nil {
-				tags := ""
-				if fval.Kind() == reflect.Slice || fval.Kind() == reflect.Map {
-					tags = `view:"no-inline"`
-				}
-				vv = ToValueView(fval.Interface(), tags)
+				vv = tv.ColumnValueView(fli, fval)
 				tv.Values[vvi] = vv
 			} else {
 				vv = tv.Values[vvi]
@@ -618,7 +712,7 @@ func (tv *TableView) UpdateSliceGrid() {
 				if tv.IsInactive() {
 					widg.AsNode2D().SetInactive()
 				}
-				widg.AsNode2D().SetSelectedState(issel)
+				widg.AsNode2D().SetSelectedState(issel || tv.IsCellSelected(si, fli))
 			} else {
 				widg = ki.NewOfType(vtyp).(gi.Node2D)
 				sg.SetChild(widg, cidx, valnm)
@@ -647,11 +741,21 @@ func (tv *TableView) UpdateSliceGrid() {
 					vvb.ViewSig.ConnectOnly(tv.This(), // todo: do we need this?
 						func(recv, send ki.Ki, sig int64, data interface{}) {
 							tvv, _ := recv.Embed(KiT_TableView).(*TableView)
+							vvv := send.(ValueView).AsValueViewBase()
+							slc, stru, fld := tvv.Slice, vvv.Owner, vvv.Field.Name
+							SaveValueUndo(ViewUndoMgr(tvv.This().(gi.Node2D)), send.(ValueView), func() {
+								NotifyChange(slc, "")
+								NotifyChange(stru, fld)
+								undoUpdtKi(stru)
+							})
 							tvv.SetChanged()
 						})
 				}
 			}
-			tv.This().(SliceViewer).StyleRow(tv.SliceNPVal, widg, si, fli, vv)
+			widg.AsNode2D().SetInvisibleState(grp != nil)
+			if grp == nil {
+				tv.This().(SliceViewer).StyleRow(tv.SliceNPVal, widg, sli, fli, vv)
+			}
 		}
 
 		if !tv.IsInactive() {
@@ -671,6 +775,7 @@ func (tv *TableView) UpdateSliceGrid() {
 						tvv.SliceNewAtRow(act.Data.(int) + 1)
 					})
 				}
+				sg.Kids[cidx].(gi.Node2D).AsNode2D().SetInvisibleState(grp != nil)
 				cidx++
 			}
 			if !tv.NoDelete {
@@ -688,13 +793,15 @@ func (tv *TableView) UpdateSliceGrid() {
 						tvv.SliceDeleteAtRow(act.Data.(int), true)
 					})
 				}
+				sg.Kids[cidx].(gi.Node2D).AsNode2D().SetInvisibleState(grp != nil)
 				cidx++
 			}
 		}
 	}
 
-	if tv.SelField != "" && tv.SelVal != nil {
-		tv.SelectedIdx, _ = StructSliceIdxByValue(tv.Slice, tv.SelField, tv.SelVal)
+	if tv.SelField != "" && tv.SelVal != nil && tv.Source == nil && !tv.SelFieldValAt(tv.SelectedIdx) {
+		sidx, _ := StructSliceIdxByValue(tv.Slice, tv.SelField, tv.SelVal)
+		tv.SelectedIdx = tv.ViewIdx(sidx)
 	}
 	if tv.IsInactive() && tv.SelectedIdx >= 0 {
 		tv.SelectIdx(tv.SelectedIdx)
@@ -711,16 +818,22 @@ func (tv *TableView) StyleRow(svnp reflect.Value, widg gi.Node2D, idx, fidx int,
 // SliceNewAt inserts a new blank element at given index in the slice -- -1
 // means the end
 func (tv *TableView) SliceNewAt(idx int) {
+	if tv.isArray {
+		return
+	}
 	wupdt := tv.TopUpdateStart()
 	defer tv.TopUpdateEnd(wupdt)
 
 	updt := tv.UpdateStart()
 	defer tv.UpdateEnd(updt)
 
+	idx = tv.InsertSliceIdx(idx)
 	kit.SliceNewAt(tv.Slice, idx)
 	if idx < 0 {
-		idx = tv.SliceSize
+		idx = tv.SliceNPVal.Len() - 1
 	}
+	tv.SaveSliceUndo(gi.T("Insert Row"), idx, true)
+	tv.UpdateViewIdxs()
 
 	if tv.TmpSave != nil {
 		tv.TmpSave.SaveTmp()
@@ -736,7 +849,8 @@ func (tv *TableView) SliceNewAt(idx int) {
 // SliceDeleteAt deletes element at given index from slice -- doupdt means
 // call UpdateSliceGrid to update display
 func (tv *TableView) SliceDeleteAt(idx int, doupdt bool) {
-	if idx < 0 {
+	idx = tv.SliceIdx(idx)
+	if idx < 0 || tv.isArray {
 		return
 	}
 	wupdt := tv.TopUpdateStart()
@@ -745,7 +859,9 @@ func (tv *TableView) SliceDeleteAt(idx int, doupdt bool) {
 	updt := tv.UpdateStart()
 	defer tv.UpdateEnd(updt)
 
+	tv.SaveSliceUndo(gi.T("Delete Row"), idx, false)
 	kit.SliceDeleteAt(tv.Slice, idx)
+	tv.UpdateViewIdxs()
 
 	if tv.TmpSave != nil {
 		tv.TmpSave.SaveTmp()
@@ -765,6 +881,16 @@ func (tv *TableView) SortSlice() {
 	if tv.SortIdx < 0 || tv.SortIdx >= len(tv.VisFields) {
 		return
 	}
+	if tv.Source != nil {
+		if srt, ok := tv.Source.(TableSourceSorter); ok {
+			srt.SortRows(tv.VisFields[tv.SortIdx].Name, !tv.SortDesc)
+		}
+		return
+	}
+	if len(tv.SortKeys) > 0 {
+		tv.SortSliceKeys()
+		return
+	}
 	rawIdx := tv.VisFields[tv.SortIdx].Index
 	kit.StructSliceSort(tv.Slice, rawIdx, !tv.SortDesc)
 }
@@ -793,6 +919,7 @@ func (tv *TableView) SortSliceAction(fldIdx int) {
 				ascending = !tv.SortDesc
 			} else {
 				tv.SortDesc = false
+				tv.SortKeys = nil
 			}
 			if ascending {
 				hdr.SetIcon("wedge-up")
@@ -806,6 +933,7 @@ func (tv *TableView) SortSliceAction(fldIdx int) {
 
 	tv.SortIdx = fldIdx
 	tv.SortSlice()
+	tv.UpdateViewIdxs()
 	tv.UpdateSliceGrid()
 	tv.UpdateEnd(updt)
 }
@@ -826,9 +954,14 @@ func (tv *TableView) ConfigToolbar() {
 		}
 	}
 	tb := tv.ToolBar()
+	add := !(tv.isArray || tv.IsInactive() || tv.NoAdd)
+	imp := add && !tv.NoDelete && tv.Source == nil
 	ndef := 2 // number of default actions
-	if tv.isArray || tv.IsInactive() || tv.NoAdd {
-		ndef = 1
+	if add {
+		ndef++
+	}
+	if imp {
+		ndef++
 	}
 	if len(*tb.Children()) < ndef {
 		tb.SetStretchMaxWidth()
@@ -837,13 +970,25 @@ func (tv *TableView) ConfigToolbar() {
 				tvv := recv.Embed(KiT_TableView).(*TableView)
 				tvv.UpdateSliceGrid()
 			})
-		if ndef > 1 {
+		if add {
 			tb.AddAction(gi.ActOpts{Label: "Add", Icon: "plus", Tooltip: "add a new element to the table"},
 				tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 					tvv := recv.Embed(KiT_TableView).(*TableView)
 					tvv.SliceNewAt(-1)
 				})
 		}
+		if imp {
+			tb.AddAction(gi.ActOpts{Label: "Import", Icon: "file-open", Tooltip: "replace the rows of the table with those of a CSV or TSV file, with a header row of field names"},
+				tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+					tvv := recv.Embed(KiT_TableView).(*TableView)
+					tvv.ImportCSVDialog()
+				})
+		}
+		tb.AddAction(gi.ActOpts{Label: "Export", Icon: "file-save", Tooltip: "save the rows of the table, as currently shown, in a CSV or TSV file"},
+			tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				tvv := recv.Embed(KiT_TableView).(*TableView)
+				tvv.ExportCSVDialog()
+			})
 	}
 	sz := len(*tb.Children())
 	if sz > ndef {
@@ -895,6 +1040,12 @@ func (tv *TableView) SetSortFieldName(nm string) {
 	}
 }
 
+func (tv *TableView) ConnectEvents2D() {
+	tv.SliceViewBase.ConnectEvents2D()
+	tv.HeaderEvents()
+	tv.CellEvents()
+}
+
 func (tv *TableView) Layout2D(parBBox image.Rectangle, iter int) bool {
 	redo := tv.Frame.Layout2D(parBBox, iter)
 	if !tv.IsConfiged() {
@@ -990,8 +1141,9 @@ func (tv *TableView) SelectRowWidgets(row int, sel bool) {
 func (tv *TableView) SelectFieldVal(fld, val string) bool {
 	tv.SelField = fld
 	tv.SelVal = val
-	if tv.SelField != "" && tv.SelVal != nil {
-		idx, _ := StructSliceIdxByValue(tv.Slice, tv.SelField, tv.SelVal)
+	if tv.SelField != "" && tv.SelVal != nil && tv.Source == nil {
+		sidx, _ := StructSliceIdxByValue(tv.Slice, tv.SelField, tv.SelVal)
+		idx := tv.ViewIdx(sidx)
 		if idx >= 0 {
 			tv.ScrollToIdx(idx)
 			tv.UpdateSelectIdx(idx, true)
@@ -1001,6 +1153,20 @@ func (tv *TableView) SelectFieldVal(fld, val string) bool {
 	return false
 }
 
+// SelFieldValAt returns true if the SelField of the row at given slice
+// index has the SelVal value -- used to avoid searching the whole slice for
+// the selected row on each update
+func (tv *TableView) SelFieldValAt(idx int) bool {
+	if idx < 0 || idx >= tv.SliceSize || tv.SliceIdx(idx) < 0 {
+		return false
+	}
+	fld, ok := tv.StruType.FieldByName(tv.SelField)
+	if !ok {
+		return false
+	}
+	return tv.RowVal(tv.SliceIdx(idx)).Elem().FieldByIndex(fld.Index).Interface() == tv.SelVal
+}
+
 // StructSliceIdxByValue searches for first index that contains given value in field of
 // given name.
 func StructSliceIdxByValue(struSlice interface{}, fldName string, fldVal interface{}) (int, error) {
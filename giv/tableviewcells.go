@@ -0,0 +1,628 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"encoding"
+	"encoding/csv"
+	"image"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/ki/ints"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/pi/filecat"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Cell selection
+
+// TableCellRange is a rectangular range of cells of a TableView, between
+// the starting cell where the selection was made and the ending cell, which
+// can be in either order -- rows are indexes in the view, as for
+// SelectedIdx, and cols are indexes of the visible fields
+type TableCellRange struct {
+	Row    int `desc:"view index of the row of the starting cell"`
+	Col    int `desc:"visible field index of the column of the starting cell"`
+	EndRow int `desc:"view index of the row of the ending cell"`
+	EndCol int `desc:"visible field index of the column of the ending cell"`
+}
+
+// Bounds returns the first and last rows and cols of the range
+func (cr *TableCellRange) Bounds() (row, col, lastRow, lastCol int) {
+	return ints.MinInt(cr.Row, cr.EndRow), ints.MinInt(cr.Col, cr.EndCol), ints.MaxInt(cr.Row, cr.EndRow), ints.MaxInt(cr.Col, cr.EndCol)
+}
+
+// Contains returns true if the range contains the cell at given row and col
+func (cr *TableCellRange) Contains(row, col int) bool {
+	r1, c1, r2, c2 := cr.Bounds()
+	return row >= r1 && row <= r2 && col >= c1 && col <= c2
+}
+
+// SelectCells selects the rectangular range of cells between the cell at
+// given view index and visible field index, and the one at given end indexes
+// -- any selected rows are unselected
+func (tv *TableView) SelectCells(idx, fli, endIdx, endFli int) {
+	if len(tv.SelectedIdxs) > 0 {
+		tv.UnselectAllIdxs()
+	}
+	tv.CellSel = &TableCellRange{Row: idx, Col: fli, EndRow: endIdx, EndCol: endFli}
+	tv.UpdateCellSelWidgets()
+}
+
+// UnselectCells clears the selection of cells
+func (tv *TableView) UnselectCells() {
+	if tv.CellSel == nil {
+		return
+	}
+	tv.CellSel = nil
+	tv.UpdateCellSelWidgets()
+}
+
+// IsCellSelected returns true if the cell at given view index and visible
+// field index is in the selection of cells
+func (tv *TableView) IsCellSelected(idx, fli int) bool {
+	return tv.CellSel != nil && tv.CellSel.Contains(idx, fli)
+}
+
+// UpdateCellSelWidgets updates the selection state of the widgets of the
+// displayed cells for the selection of cells
+func (tv *TableView) UpdateCellSelWidgets() {
+	if !tv.IsConfiged() {
+		return
+	}
+	wupdt := tv.TopUpdateStart()
+	defer tv.TopUpdateEnd(wupdt)
+
+	sg := tv.SliceGrid()
+	nWidgPerRow, idxOff := tv.RowWidgetNs()
+	for i := 0; i < tv.DispRows; i++ {
+		si := tv.StartIdx + i
+		issel := tv.IdxIsSelected(si)
+		for fli := 0; fli < tv.NVisFields; fli++ {
+			cidx := i*nWidgPerRow + idxOff + fli
+			if sg.Kids.IsValidIndex(cidx) != nil {
+				continue
+			}
+			widg := sg.Child(cidx).(gi.Node2D).AsNode2D()
+			widg.SetSelectedState(issel || tv.IsCellSelected(si, fli))
+			widg.UpdateSig()
+		}
+	}
+}
+
+// CellAtPos returns the view index and visible field index of the displayed
+// cell at given window position -- false if not on a cell
+func (tv *TableView) CellAtPos(pos image.Point) (idx, fli int, ok bool) {
+	if !tv.IsConfiged() {
+		return
+	}
+	sg := tv.SliceGrid()
+	nWidgPerRow, idxOff := tv.RowWidgetNs()
+	for i := 0; i < tv.DispRows; i++ {
+		for fi := 0; fi < tv.NVisFields; fi++ {
+			cidx := i*nWidgPerRow + idxOff + fi
+			if sg.Kids.IsValidIndex(cidx) != nil {
+				continue
+			}
+			widg := sg.Child(cidx).(gi.Node2D).AsNode2D()
+			if pos.In(widg.WinBBox) {
+				return tv.StartIdx + i, fi, true
+			}
+		}
+	}
+	return
+}
+
+// FocusCell returns the view index and visible field index of the cell with
+// the keyboard focus -- false if the focus is not in a cell
+func (tv *TableView) FocusCell() (idx, fli int, ok bool) {
+	win := tv.ParentWindow()
+	if win == nil || !tv.IsConfiged() {
+		return
+	}
+	sg := tv.SliceGrid()
+	cur := win.EventMgr.CurFocus()
+	for cur != nil && cur.Parent() != sg.This() {
+		cur = cur.Parent()
+	}
+	if cur == nil {
+		return
+	}
+	cidx, has := cur.IndexInParent()
+	if !has {
+		return
+	}
+	nWidgPerRow, idxOff := tv.RowWidgetNs()
+	fli = cidx%nWidgPerRow - idxOff
+	if fli < 0 || fli >= tv.NVisFields {
+		return
+	}
+	return tv.StartIdx + cidx/nWidgPerRow, fli, true
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Cell values
+
+// CellVal returns the value of the field of the cell at given view index and
+// visible field index -- invalid for group header rows and out of range
+func (tv *TableView) CellVal(idx, fli int) reflect.Value {
+	if idx < 0 || idx >= tv.SliceSize || fli < 0 || fli >= tv.NVisFields {
+		return reflect.Value{}
+	}
+	sli := tv.SliceIdx(idx)
+	if sli < 0 {
+		return reflect.Value{}
+	}
+	return tv.RowVal(sli).Elem().FieldByIndex(tv.VisFields[fli].Index)
+}
+
+// TableCellText returns the text for the value of a cell, as used for
+// copying cells -- see SetTableCellText
+func TableCellText(fv reflect.Value) string {
+	if fv.CanAddr() {
+		if tm, ok := fv.Addr().Interface().(encoding.TextMarshaler); ok {
+			if b, err := tm.MarshalText(); err == nil {
+				return string(b)
+			}
+		}
+	}
+	return kit.ToString(fv.Interface())
+}
+
+// SetTableCellText sets the value of a cell from given text, as used for
+// pasting cells, converting it to the type of the value, including enums
+// by name, durations, and types that implement encoding.TextUnmarshaler --
+// returns false if it could not be converted
+func SetTableCellText(fv reflect.Value, str string) bool {
+	if !fv.CanAddr() {
+		return false
+	}
+	ptr := fv.Addr().Interface()
+	if tu, ok := ptr.(encoding.TextUnmarshaler); ok {
+		return tu.UnmarshalText([]byte(str)) == nil
+	}
+	str = strings.TrimSpace(str)
+	if kit.Enums.TypeRegistered(fv.Type()) {
+		if err := kit.Enums.SetAnyEnumIfaceFromString(ptr, str); err == nil {
+			return true
+		}
+	}
+	if dp, ok := ptr.(*time.Duration); ok {
+		if d, err := time.ParseDuration(str); err == nil {
+			*dp = d
+			return true
+		}
+	}
+	switch fv.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Map, reflect.Array, reflect.Ptr, reflect.Interface:
+		return false
+	}
+	return kit.SetRobust(ptr, str)
+}
+
+// SetCellVal sets the value of the cell at given view index and visible
+// field index using given set function, which returns false if it did not
+// set the value, saving an undo record for a Slice (not a Source) -- returns
+// true if the value was set
+func (tv *TableView) SetCellVal(idx, fli int, set func(fv reflect.Value) bool) bool {
+	fv := tv.CellVal(idx, fli)
+	if !fv.IsValid() || !fv.CanSet() {
+		return false
+	}
+	prv := reflect.New(fv.Type()).Elem()
+	prv.Set(fv)
+	if !set(fv) {
+		fv.Set(prv)
+		return false
+	}
+	if tv.Source != nil || reflect.DeepEqual(prv.Interface(), fv.Interface()) {
+		return true
+	}
+	nv := reflect.New(fv.Type()).Elem()
+	nv.Set(fv)
+	if um := ViewUndoMgr(tv.This().(gi.Node2D)); um != nil {
+		slc, sli, fidx := tv.Slice, tv.SliceIdx(idx), tv.VisFields[fli].Index
+		cell := func() reflect.Value {
+			return kit.OnePtrUnderlyingValue(kit.NonPtrValue(reflect.ValueOf(slc)).Index(sli)).Elem().FieldByIndex(fidx)
+		}
+		um.Save(gi.T("Edit Cells"), func() {
+			cell().Set(prv)
+			NotifyChange(slc, "")
+		}, func() {
+			cell().Set(nv)
+			NotifyChange(slc, "")
+		})
+	}
+	return true
+}
+
+// CellsChanged updates the view after changes to the values of cells
+func (tv *TableView) CellsChanged() {
+	if tv.TmpSave != nil {
+		tv.TmpSave.SaveTmp()
+	}
+	tv.SetChanged()
+	tv.This().(SliceViewer).UpdateSliceGrid()
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Copy / Paste
+
+// CellsText returns the text of the selected cells, with the values of each
+// row separated by given separator: '\t' for the tab-separated values (TSV)
+// used by spreadsheets, or ',' for CSV -- values are quoted as needed
+func (tv *TableView) CellsText(sep rune) string {
+	if tv.CellSel == nil {
+		return ""
+	}
+	r1, c1, r2, c2 := tv.CellSel.Bounds()
+	var sb strings.Builder
+	wr := csv.NewWriter(&sb)
+	wr.Comma = sep
+	rec := make([]string, c2-c1+1)
+	for idx := r1; idx <= r2; idx++ {
+		if tv.SliceIdx(idx) < 0 {
+			continue
+		}
+		for fli := c1; fli <= c2; fli++ {
+			rec[fli-c1] = ""
+			if fv := tv.CellVal(idx, fli); fv.IsValid() {
+				rec[fli-c1] = TableCellText(fv)
+			}
+		}
+		wr.Write(rec)
+	}
+	wr.Flush()
+	return sb.String()
+}
+
+// CopyCells copies the selected cells to the clipboard, as plain text with
+// given separator between the values of each row: '\t' for the TSV that
+// spreadsheets paste, or ',' for CSV
+func (tv *TableView) CopyCells(sep rune) {
+	if tv.CellSel == nil {
+		return
+	}
+	oswin.TheApp.ClipBoard(tv.ParentWindow().OSWin).Write(mimedata.NewText(tv.CellsText(sep)))
+}
+
+// CutCells copies the selected cells to the clipboard as TSV, and clears them
+func (tv *TableView) CutCells() {
+	tv.CopyCells('\t')
+	tv.ClearCells()
+}
+
+// PasteCells pastes TSV (e.g., from a spreadsheet) or CSV text from the
+// clipboard into the cells starting at the selected cells, or the cell with
+// the focus -- a single value is pasted into all of the selected cells --
+// returns false if there is no such text, or no cell to paste into, e.g.,
+// if the clipboard has rows copied from a view, which are pasted as rows
+func (tv *TableView) PasteCells() bool {
+	if tv.IsInactive() {
+		return false
+	}
+	cb := oswin.TheApp.ClipBoard(tv.ParentWindow().OSWin)
+	if md := cb.Read([]string{filecat.DataJson}); md != nil {
+		return false
+	}
+	md := cb.Read([]string{filecat.DataCsv, filecat.TextPlain})
+	if len(md) == 0 {
+		return false
+	}
+	sep := '\t'
+	if md[0].Type == filecat.DataCsv {
+		sep = ','
+	}
+	var idx, fli int
+	if tv.CellSel != nil {
+		idx, fli, _, _ = tv.CellSel.Bounds()
+	} else {
+		var ok bool
+		if idx, fli, ok = tv.FocusCell(); !ok {
+			return false
+		}
+	}
+	tv.PasteCellsText(string(md[0].Data), sep, idx, fli)
+	return true
+}
+
+// PasteCellsText pastes given text, with given separator between the values
+// of each row, into the cells starting at given view index and visible
+// field index, skipping group header rows, and adding rows at the end of
+// the Slice as needed, unless NoAdd or viewing a Source or filtered rows --
+// a single value is pasted into all of the selected cells, if they include
+// the starting cell -- the pasted cells are then selected
+func (tv *TableView) PasteCellsText(text string, sep rune, idx, fli int) {
+	rd := csv.NewReader(strings.NewReader(text))
+	rd.Comma = sep
+	rd.FieldsPerRecord = -1
+	rd.LazyQuotes = true
+	recs, err := rd.ReadAll()
+	if err != nil {
+		log.Printf("giv.TableView PasteCells: could not parse text: %v\n", err)
+		return
+	}
+	if len(recs) == 0 {
+		return
+	}
+	wupdt := tv.TopUpdateStart()
+	defer tv.TopUpdateEnd(wupdt)
+	updt := tv.UpdateStart()
+	defer tv.UpdateEnd(updt)
+
+	um := ViewUndoMgr(tv.This().(gi.Node2D))
+	um.StartGroup(gi.T("Paste Cells"))
+	defer um.EndGroup()
+
+	if len(recs) == 1 && len(recs[0]) == 1 && tv.CellSel != nil && tv.CellSel.Contains(idx, fli) {
+		str := recs[0][0]
+		r1, c1, r2, c2 := tv.CellSel.Bounds()
+		for ri := r1; ri <= r2; ri++ {
+			for ci := c1; ci <= c2; ci++ {
+				tv.SetCellVal(ri, ci, func(fv reflect.Value) bool { return SetTableCellText(fv, str) })
+			}
+		}
+		tv.CellsChanged()
+		return
+	}
+
+	canAdd := !tv.NoAdd && !tv.isArray && tv.Source == nil && tv.ViewIdxs == nil
+	added := false
+	lastIdx, lastFli := idx, fli
+	ri := idx
+	for _, rec := range recs {
+		for ri < tv.SliceSize && tv.SliceIdx(ri) < 0 {
+			ri++
+		}
+		if ri >= tv.SliceSize {
+			if !canAdd {
+				break
+			}
+			kit.SliceNewAt(tv.Slice, -1)
+			tv.SliceNPVal = kit.NonPtrValue(reflect.ValueOf(tv.Slice))
+			tv.SaveSliceUndo(gi.T("Insert Row"), tv.SliceNPVal.Len()-1, true)
+			tv.This().(SliceViewer).UpdtSliceSize()
+			added = true
+		}
+		for ci, str := range rec {
+			if fli+ci >= tv.NVisFields {
+				break
+			}
+			tv.SetCellVal(ri, fli+ci, func(fv reflect.Value) bool { return SetTableCellText(fv, str) })
+			lastFli = ints.MaxInt(lastFli, fli+ci)
+		}
+		lastIdx = ri
+		ri++
+	}
+	if added {
+		tv.ScrollBar().SetFullReRender()
+		tv.This().(SliceViewer).LayoutSliceGrid()
+	}
+	tv.CellsChanged()
+	tv.SelectCells(idx, fli, lastIdx, lastFli)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Clear / Fill
+
+// ClearCells sets the values of the selected cells to the zero value of
+// their type, e.g., 0 or an empty string
+func (tv *TableView) ClearCells() {
+	if tv.CellSel == nil || tv.IsInactive() {
+		return
+	}
+	wupdt := tv.TopUpdateStart()
+	defer tv.TopUpdateEnd(wupdt)
+	um := ViewUndoMgr(tv.This().(gi.Node2D))
+	um.StartGroup(gi.T("Clear Cells"))
+	r1, c1, r2, c2 := tv.CellSel.Bounds()
+	for ri := r1; ri <= r2; ri++ {
+		for ci := c1; ci <= c2; ci++ {
+			tv.SetCellVal(ri, ci, func(fv reflect.Value) bool {
+				fv.Set(reflect.Zero(fv.Type()))
+				return true
+			})
+		}
+	}
+	um.EndGroup()
+	tv.CellsChanged()
+}
+
+// FillDown copies the values in the first row of the selected cells into
+// the other rows -- if only one row is selected, the values of the row
+// above are copied into it, as in spreadsheets
+func (tv *TableView) FillDown() {
+	if tv.CellSel == nil || tv.IsInactive() {
+		return
+	}
+	r1, c1, r2, c2 := tv.CellSel.Bounds()
+	src := r1
+	if r1 == r2 {
+		src = r1 - 1
+	}
+	for src >= 0 && src < r2 && tv.SliceIdx(src) < 0 { // skip group headers
+		src++
+	}
+	tv.fillCells(gi.T("Fill Down"), r1, c1, r2, c2, func(ri, ci int) (int, int) { return src, ci })
+}
+
+// FillRight copies the values in the first column of the selected cells
+// into the other columns -- if only one column is selected, the values of
+// the column to the left are copied into it -- values are converted through
+// their text for columns of different types
+func (tv *TableView) FillRight() {
+	if tv.CellSel == nil || tv.IsInactive() {
+		return
+	}
+	r1, c1, r2, c2 := tv.CellSel.Bounds()
+	src := c1
+	if c1 == c2 {
+		src = c1 - 1
+	}
+	tv.fillCells(gi.T("Fill Right"), r1, c1, r2, c2, func(ri, ci int) (int, int) { return ri, src })
+}
+
+// fillCells sets the values of the cells in given range from the cells
+// returned by given source function
+func (tv *TableView) fillCells(label string, r1, c1, r2, c2 int, srcFun func(ri, ci int) (int, int)) {
+	wupdt := tv.TopUpdateStart()
+	defer tv.TopUpdateEnd(wupdt)
+	um := ViewUndoMgr(tv.This().(gi.Node2D))
+	um.StartGroup(label)
+	for ri := r1; ri <= r2; ri++ {
+		for ci := c1; ci <= c2; ci++ {
+			sr, sc := srcFun(ri, ci)
+			if sr == ri && sc == ci {
+				continue
+			}
+			sv := tv.CellVal(sr, sc)
+			if !sv.IsValid() {
+				continue
+			}
+			tv.SetCellVal(ri, ci, func(fv reflect.Value) bool {
+				if sv.Type() == fv.Type() {
+					fv.Set(sv)
+					return true
+				}
+				return SetTableCellText(fv, TableCellText(sv))
+			})
+		}
+	}
+	um.EndGroup()
+	tv.CellsChanged()
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Events
+
+// CellsCtxtMenu adds the actions for the selected cells to given menu
+func (tv *TableView) CellsCtxtMenu(m *gi.Menu) {
+	m.AddAction(gi.ActOpts{Label: "Copy Cells"}, tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		tvv := recv.Embed(KiT_TableView).(*TableView)
+		tvv.CopyCells('\t')
+	})
+	m.AddAction(gi.ActOpts{Label: "Copy Cells as CSV"}, tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		tvv := recv.Embed(KiT_TableView).(*TableView)
+		tvv.CopyCells(',')
+	})
+	m.AddAction(gi.ActOpts{Label: "Cut Cells"}, tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		tvv := recv.Embed(KiT_TableView).(*TableView)
+		tvv.CutCells()
+	})
+	m.AddAction(gi.ActOpts{Label: "Paste Cells"}, tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		tvv := recv.Embed(KiT_TableView).(*TableView)
+		tvv.PasteCells()
+	})
+	m.AddSeparator("sep-fill")
+	m.AddAction(gi.ActOpts{Label: "Fill Down"}, tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		tvv := recv.Embed(KiT_TableView).(*TableView)
+		tvv.FillDown()
+	})
+	m.AddAction(gi.ActOpts{Label: "Fill Right"}, tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		tvv := recv.Embed(KiT_TableView).(*TableView)
+		tvv.FillRight()
+	})
+	m.AddAction(gi.ActOpts{Label: "Clear Cells"}, tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		tvv := recv.Embed(KiT_TableView).(*TableView)
+		tvv.ClearCells()
+	})
+}
+
+// ItemCtxtMenu pulls up the context menu for given slice index, which is
+// the menu for the selected cells if there are any
+func (tv *TableView) ItemCtxtMenu(idx int) {
+	if tv.CellSel == nil || tv.IsInactive() {
+		tv.SliceViewBase.ItemCtxtMenu(idx)
+		return
+	}
+	var men gi.Menu
+	tv.CellsCtxtMenu(&men)
+	pos := tv.IdxPos(tv.CellSel.EndRow)
+	gi.PopupMenu(men, pos.X, pos.Y, tv.ViewportSafe(), tv.Nm+"-cells-menu")
+}
+
+// KeyInputCells handles the keys for the selected cells: copy, cut, paste,
+// delete to clear them, duplicate to fill down, and cancel select -- paste
+// also pastes text into the cell with the focus
+func (tv *TableView) KeyInputCells(kt *key.ChordEvent) {
+	kf := gi.KeyFun(kt.Chord())
+	if kf == gi.KeyFunPaste {
+		if tv.PasteCells() {
+			kt.SetProcessed()
+		}
+		return
+	}
+	if tv.CellSel == nil {
+		return
+	}
+	switch kf {
+	case gi.KeyFunCopy:
+		tv.CopyCells('\t')
+		kt.SetProcessed()
+	case gi.KeyFunCut:
+		tv.CutCells()
+		kt.SetProcessed()
+	case gi.KeyFunDelete, gi.KeyFunBackspace:
+		tv.ClearCells()
+		kt.SetProcessed()
+	case gi.KeyFunDuplicate:
+		tv.FillDown()
+		kt.SetProcessed()
+	case gi.KeyFunCancelSelect:
+		tv.UnselectCells()
+		kt.SetProcessed()
+	}
+}
+
+// CellEvents connects the events for selecting cells: shift-click on a cell
+// selects the cells from the selected cells, or the cell with the focus, to
+// it, and any other click in the grid clears the selection -- the keys for
+// the selected cells are handled before those of the SliceViewBase
+func (tv *TableView) CellEvents() {
+	if tv.IsInactive() {
+		return
+	}
+	tv.ConnectEvent(oswin.MouseEvent, gi.HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.Event)
+		if me.Button != mouse.Left || me.Action != mouse.Press {
+			return
+		}
+		tvv := recv.Embed(KiT_TableView).(*TableView)
+		if !tvv.InSliceGrid(me.Where) {
+			return
+		}
+		idx, fli, ok := tvv.CellAtPos(me.Where)
+		if ok && me.HasAnyModifier(key.Shift) {
+			sidx, sfli, has := tvv.FocusCell()
+			if tvv.CellSel != nil {
+				sidx, sfli, has = tvv.CellSel.Row, tvv.CellSel.Col, true
+			}
+			if has {
+				me.SetProcessed()
+				tvv.SelectCells(sidx, sfli, idx, fli)
+				return
+			}
+		}
+		tvv.UnselectCells()
+	})
+	// replaces the SliceViewBase key handler, which is called for other keys
+	tv.ConnectEvent(oswin.KeyChordEvent, gi.HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		tvv := recv.Embed(KiT_TableView).(*TableView)
+		kt := d.(*key.ChordEvent)
+		tvv.KeyInputCells(kt)
+		if !kt.IsProcessed() {
+			tvv.KeyInputActive(kt)
+		}
+	})
+}
@@ -0,0 +1,622 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Sorting
+
+// TableSortKey is an additional field that a TableView is sorted by, for
+// rows with equal values in the fields before it -- see ThenSortBy
+type TableSortKey struct {
+	Field string `desc:"name of the field"`
+	Desc  bool   `desc:"sort in descending order"`
+}
+
+// TableCompare compares two field values, returning -1, 0, or 1 if a is
+// less than, equal to, or greater than b: numbers and bools are compared
+// numerically, and everything else by string value
+func TableCompare(a, b reflect.Value) int {
+	switch k := a.Kind(); {
+	case k >= reflect.Int && k <= reflect.Int64:
+		return cmpOrder(a.Int() < b.Int(), a.Int() > b.Int())
+	case k >= reflect.Uint && k <= reflect.Uintptr:
+		return cmpOrder(a.Uint() < b.Uint(), a.Uint() > b.Uint())
+	case k == reflect.Float32 || k == reflect.Float64:
+		return cmpOrder(a.Float() < b.Float(), a.Float() > b.Float())
+	case k == reflect.Bool:
+		return cmpOrder(!a.Bool() && b.Bool(), a.Bool() && !b.Bool())
+	case k == reflect.String:
+		return strings.Compare(a.String(), b.String())
+	}
+	return strings.Compare(kit.ToString(a.Interface()), kit.ToString(b.Interface()))
+}
+
+func cmpOrder(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+// SortBy sorts the slice by the field of given name, replacing any current
+// sort order
+func (tv *TableView) SortBy(field string, ascending bool) {
+	fli := tv.VisFieldIdx(field)
+	if fli < 0 {
+		return
+	}
+	tv.SortIdx = fli
+	tv.SortDesc = !ascending
+	tv.SortKeys = nil
+	tv.SortSlice()
+	tv.ApplyView()
+}
+
+// ThenSortBy adds the field of given name to the fields that the slice is
+// sorted by, for rows with equal values in the current sort fields
+func (tv *TableView) ThenSortBy(field string, ascending bool) {
+	if tv.SortIdx < 0 {
+		tv.SortBy(field, ascending)
+		return
+	}
+	if tv.VisFieldIdx(field) < 0 || tv.VisFields[tv.SortIdx].Name == field {
+		return
+	}
+	for i, sk := range tv.SortKeys {
+		if sk.Field == field {
+			tv.SortKeys = append(tv.SortKeys[:i], tv.SortKeys[i+1:]...)
+			break
+		}
+	}
+	tv.SortKeys = append(tv.SortKeys, TableSortKey{Field: field, Desc: !ascending})
+	tv.SortSlice()
+	tv.ApplyView()
+}
+
+// SortSliceKeys sorts the slice by SortIdx and then the SortKeys, keeping
+// the existing order of rows that are equal in all of them
+func (tv *TableView) SortSliceKeys() {
+	keys := make([]TableSortKey, 0, 1+len(tv.SortKeys))
+	keys = append(keys, TableSortKey{Field: tv.VisFields[tv.SortIdx].Name, Desc: tv.SortDesc})
+	keys = append(keys, tv.SortKeys...)
+	flds := make([][]int, 0, len(keys))
+	desc := make([]bool, 0, len(keys))
+	for _, sk := range keys {
+		if fld, ok := tv.StruType.FieldByName(sk.Field); ok {
+			flds = append(flds, fld.Index)
+			desc = append(desc, sk.Desc)
+		}
+	}
+	svnp := tv.SliceNPVal
+	sort.SliceStable(svnp.Interface(), func(i, j int) bool {
+		iv := kit.OnePtrUnderlyingValue(svnp.Index(i)).Elem()
+		jv := kit.OnePtrUnderlyingValue(svnp.Index(j)).Elem()
+		for k, fi := range flds {
+			c := TableCompare(iv.FieldByIndex(fi), jv.FieldByIndex(fi))
+			if c != 0 {
+				return (c < 0) != desc[k]
+			}
+		}
+		return false
+	})
+}
+
+// VisFieldIdx returns the index in VisFields of the field of given name,
+// or -1 if it is not visible
+func (tv *TableView) VisFieldIdx(field string) int {
+	for fli, fld := range tv.VisFields {
+		if fld.Name == field {
+			return fli
+		}
+	}
+	return -1
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Filtering
+
+// TableFilter is a filter on the values of a field of a TableView: only rows
+// with values that match the Expr are shown.  The Expr is a value,
+// optionally preceded by an operator: = (equal), != (not equal), < <= > >=
+// (compared as numbers if both are numbers, otherwise as strings), ~
+// (matches regular expression), or ! (does not contain) -- a value alone
+// matches values that contain it.  Strings are compared ignoring case.
+type TableFilter struct {
+	Field string `desc:"name of the field"`
+	Expr  string `desc:"filter expression"`
+	op    string
+	val   string
+	num   float64
+	isNum bool
+	re    *regexp.Regexp
+}
+
+// tableFilterOps are the filter operators, with longer ones first
+var tableFilterOps = []string{"!=", "<=", ">=", "=", "<", ">", "~", "!"}
+
+// NewTableFilter returns a new filter for given field and expression --
+// returns an error if it is a ~ expression with an invalid regexp
+func NewTableFilter(field, expr string) (*TableFilter, error) {
+	tf := &TableFilter{Field: field, Expr: expr}
+	ex := strings.TrimSpace(expr)
+	for _, op := range tableFilterOps {
+		if strings.HasPrefix(ex, op) {
+			tf.op = op
+			ex = strings.TrimSpace(ex[len(op):])
+			break
+		}
+	}
+	if tf.op == "~" {
+		re, err := regexp.Compile("(?i)" + ex)
+		if err != nil {
+			return nil, err
+		}
+		tf.re = re
+	}
+	tf.val = strings.ToLower(ex)
+	tf.num, tf.isNum = kit.ToFloat(ex)
+	return tf, nil
+}
+
+// Match returns true if given field value matches the filter
+func (tf *TableFilter) Match(v interface{}) bool {
+	s := strings.ToLower(kit.ToString(v))
+	cmp := 0
+	switch tf.op {
+	case "", "!":
+		return strings.Contains(s, tf.val) == (tf.op == "")
+	case "~":
+		return tf.re.MatchString(s)
+	}
+	if fv, ok := kit.ToFloat(v); ok && tf.isNum {
+		cmp = cmpOrder(fv < tf.num, fv > tf.num)
+	} else {
+		cmp = strings.Compare(s, tf.val)
+	}
+	switch tf.op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return true
+}
+
+// SetFilter sets the filter expression for the field of given name (see
+// TableFilter) -- an empty expression removes the filter
+func (tv *TableView) SetFilter(field, expr string) error {
+	for i, tf := range tv.Filters {
+		if tf.Field == field {
+			tv.Filters = append(tv.Filters[:i], tv.Filters[i+1:]...)
+			break
+		}
+	}
+	if strings.TrimSpace(expr) != "" {
+		tf, err := NewTableFilter(field, expr)
+		if err != nil {
+			return err
+		}
+		tv.Filters = append(tv.Filters, tf)
+	}
+	tv.ApplyView()
+	return nil
+}
+
+// Filter returns the filter for the field of given name, or nil if none
+func (tv *TableView) Filter(field string) *TableFilter {
+	for _, tf := range tv.Filters {
+		if tf.Field == field {
+			return tf
+		}
+	}
+	return nil
+}
+
+// ClearFilters removes all of the filters
+func (tv *TableView) ClearFilters() {
+	tv.Filters = nil
+	tv.ApplyView()
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Grouping
+
+// TableGroup is a group of rows in a TableView that have the same value of
+// the GroupField
+type TableGroup struct {
+	Val  string `desc:"the value of the GroupField for the rows in the group"`
+	Idxs []int  `desc:"slice indexes of the rows in the group"`
+}
+
+// GroupBy groups the rows by the value of the field of given name, with a
+// header row for each group that can be clicked to collapse or expand it
+// -- the header is shown in the index column, so ShowIndex is turned on --
+// an empty field name removes the grouping
+func (tv *TableView) GroupBy(field string) {
+	tv.GroupField = field
+	if field != "" && !tv.ShowIndex {
+		tv.ShowIndex = true
+		tv.Config()
+	}
+	tv.ApplyView()
+}
+
+// SetGroupCollapsed sets whether the group with given value is collapsed,
+// hiding its rows
+func (tv *TableView) SetGroupCollapsed(val string, collapsed bool) {
+	if tv.GroupsCollapsed == nil {
+		tv.GroupsCollapsed = make(map[string]bool)
+	}
+	if collapsed {
+		tv.GroupsCollapsed[val] = true
+	} else {
+		delete(tv.GroupsCollapsed, val)
+	}
+	tv.ApplyView()
+}
+
+// SetAllGroupsCollapsed collapses or expands all of the groups
+func (tv *TableView) SetAllGroupsCollapsed(collapsed bool) {
+	tv.GroupsCollapsed = make(map[string]bool)
+	if collapsed {
+		for _, g := range tv.Groups {
+			tv.GroupsCollapsed[g.Val] = true
+		}
+	}
+	tv.ApplyView()
+}
+
+// GroupAt returns the group whose header is at given index in the view,
+// or nil if it is not a group header
+func (tv *TableView) GroupAt(idx int) *TableGroup {
+	if tv.ViewIdxs == nil || idx < 0 || idx >= len(tv.ViewIdxs) {
+		return nil
+	}
+	if si := tv.ViewIdxs[idx]; si < 0 {
+		return &tv.Groups[-1-si]
+	}
+	return nil
+}
+
+// GroupLabel returns the text for the header of given group
+func (tv *TableView) GroupLabel(g *TableGroup) string {
+	mark := "▾"
+	if tv.GroupsCollapsed[g.Val] {
+		mark = "▸"
+	}
+	return fmt.Sprintf("%v %v: %v (%v)", mark, tv.GroupField, g.Val, len(g.Idxs))
+}
+
+// ToggleGroupRow collapses or expands the group whose header is at given
+// display row, returning false if it is not a group header
+func (tv *TableView) ToggleGroupRow(row int) bool {
+	g := tv.GroupAt(tv.StartIdx + row)
+	if g == nil {
+		return false
+	}
+	tv.SetGroupCollapsed(g.Val, !tv.GroupsCollapsed[g.Val])
+	return true
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  View indexes
+
+// SliceIdx returns the slice index for given index in the view, which are
+// different when filtering or grouping -- returns -1 for group headers and
+// indexes out of range
+func (tv *TableView) SliceIdx(idx int) int {
+	if tv.ViewIdxs == nil {
+		return idx
+	}
+	if idx < 0 || idx >= len(tv.ViewIdxs) {
+		return -1
+	}
+	if si := tv.ViewIdxs[idx]; si >= 0 {
+		return si
+	}
+	return -1
+}
+
+// ViewIdx returns the index in the view for given slice index, or -1 if
+// the row is not shown because it is filtered out or in a collapsed group
+func (tv *TableView) ViewIdx(sidx int) int {
+	if tv.ViewIdxs == nil || sidx < 0 {
+		return sidx
+	}
+	for idx, si := range tv.ViewIdxs {
+		if si == sidx {
+			return idx
+		}
+	}
+	return -1
+}
+
+// InsertSliceIdx returns the slice index at which to insert new rows for
+// given index in the view -- -1 means the end
+func (tv *TableView) InsertSliceIdx(idx int) int {
+	if tv.ViewIdxs == nil {
+		return idx
+	}
+	if idx < 0 || idx >= len(tv.ViewIdxs) {
+		return -1
+	}
+	if g := tv.GroupAt(idx); g != nil {
+		if len(g.Idxs) == 0 {
+			return -1
+		}
+		return g.Idxs[0]
+	}
+	return tv.ViewIdxs[idx]
+}
+
+// UpdateViewIdxs updates the ViewIdxs and Groups for the current Filters
+// and GroupField, which are not available for a Source
+func (tv *TableView) UpdateViewIdxs() {
+	tv.ViewIdxs = nil
+	tv.Groups = nil
+	tv.viewSrcLen = 0
+	if tv.Source != nil || kit.IfaceIsNil(tv.Slice) || (len(tv.Filters) == 0 && tv.GroupField == "") {
+		return
+	}
+	n := tv.SliceNPVal.Len()
+	tv.viewSrcLen = n
+	fflds := make([][]int, len(tv.Filters))
+	for i, tf := range tv.Filters {
+		if fld, ok := tv.StruType.FieldByName(tf.Field); ok {
+			fflds[i] = fld.Index
+		}
+	}
+	idxs := make([]int, 0, n)
+	for si := 0; si < n; si++ {
+		rv := kit.OnePtrUnderlyingValue(tv.SliceNPVal.Index(si)).Elem()
+		match := true
+		for i, tf := range tv.Filters {
+			if fflds[i] != nil && !tf.Match(rv.FieldByIndex(fflds[i]).Interface()) {
+				match = false
+				break
+			}
+		}
+		if match {
+			idxs = append(idxs, si)
+		}
+	}
+	gfld, ok := tv.StruType.FieldByName(tv.GroupField)
+	if tv.GroupField == "" || !ok {
+		tv.ViewIdxs = idxs
+		return
+	}
+	gmap := make(map[string]int)
+	var gvals []reflect.Value
+	for _, si := range idxs {
+		fv := kit.OnePtrUnderlyingValue(tv.SliceNPVal.Index(si)).Elem().FieldByIndex(gfld.Index)
+		gv := kit.ToString(fv.Interface())
+		gidx, has := gmap[gv]
+		if !has {
+			gidx = len(tv.Groups)
+			gmap[gv] = gidx
+			tv.Groups = append(tv.Groups, TableGroup{Val: gv})
+			gvals = append(gvals, fv)
+		}
+		tv.Groups[gidx].Idxs = append(tv.Groups[gidx].Idxs, si)
+	}
+	desc := tv.SortIdx >= 0 && tv.SortIdx < len(tv.VisFields) && tv.VisFields[tv.SortIdx].Name == tv.GroupField && tv.SortDesc
+	gord := make([]int, len(tv.Groups))
+	for i := range gord {
+		gord[i] = i
+	}
+	sort.SliceStable(gord, func(i, j int) bool {
+		c := TableCompare(gvals[gord[i]], gvals[gord[j]])
+		return c != 0 && (c < 0) != desc
+	})
+	grps := make([]TableGroup, len(gord))
+	for i, gidx := range gord {
+		grps[i] = tv.Groups[gidx]
+	}
+	tv.Groups = grps
+	tv.ViewIdxs = make([]int, 0, len(idxs)+len(grps))
+	for gidx, g := range tv.Groups {
+		tv.ViewIdxs = append(tv.ViewIdxs, -1-gidx)
+		if !tv.GroupsCollapsed[g.Val] {
+			tv.ViewIdxs = append(tv.ViewIdxs, g.Idxs...)
+		}
+	}
+}
+
+// ApplyView updates the view for changes in the sorting, filtering or
+// grouping, and updates the display
+func (tv *TableView) ApplyView() {
+	if kit.IfaceIsNil(tv.Slice) || !tv.IsConfiged() {
+		return
+	}
+	wupdt := tv.TopUpdateStart()
+	defer tv.TopUpdateEnd(wupdt)
+	updt := tv.UpdateStart()
+	defer tv.UpdateEnd(updt)
+	tv.UpdateViewIdxs()
+	tv.ResetSelectedIdxs()
+	tv.StartIdx = 0
+	tv.UpdateHeaders()
+	tv.ScrollBar().SetFullReRender()
+	tv.This().(SliceViewer).LayoutSliceGrid()
+	tv.This().(SliceViewer).UpdateSliceGrid()
+}
+
+// UpdateHeaders updates the column headers for the current sorting,
+// filtering and grouping
+func (tv *TableView) UpdateHeaders() {
+	sgh := tv.SliceHeader()
+	sgh.SetFullReRender()
+	_, idxOff := tv.RowWidgetNs()
+	for fli := 0; fli < tv.NVisFields; fli++ {
+		field := tv.VisFields[fli]
+		hdr := sgh.Child(idxOff + fli).(*gi.Action)
+		txt := field.Name
+		if tf := tv.Filter(field.Name); tf != nil {
+			txt += " [" + tf.Expr + "]"
+		}
+		hdr.SetText(txt)
+		switch {
+		case fli != tv.SortIdx:
+			hdr.SetIcon("none")
+		case tv.SortDesc:
+			hdr.SetIcon("wedge-down")
+		default:
+			hdr.SetIcon("wedge-up")
+		}
+		hdr.Tooltip = field.Name + " (click to sort by, right-click for more options)"
+		if dsc := field.Tag.Get("desc"); dsc != "" {
+			hdr.Tooltip += ": " + dsc
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Header menu
+
+// HeaderEvents connects to right-clicks on the column headers, to show the
+// HeaderCtxtMenu
+func (tv *TableView) HeaderEvents() {
+	if !tv.IsConfiged() {
+		return
+	}
+	sgh := tv.SliceHeader()
+	sgh.ConnectEvent(oswin.MouseEvent, gi.LowPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.Event)
+		if me.Button != mouse.Right || me.Action != mouse.Release {
+			return
+		}
+		sghh := recv.Embed(gi.KiT_ToolBar).(*gi.ToolBar)
+		tvk := sghh.ParentByType(KiT_TableView, ki.Embeds)
+		if tvk == nil {
+			return
+		}
+		tvv := tvk.Embed(KiT_TableView).(*TableView)
+		_, idxOff := tvv.RowWidgetNs()
+		for fli := 0; fli < tvv.NVisFields; fli++ {
+			hdr := sghh.Child(idxOff + fli).(gi.Node2D).AsWidget()
+			if me.Where.In(hdr.WinBBox) {
+				me.SetProcessed()
+				tvv.HeaderCtxtMenu(fli)
+				return
+			}
+		}
+	})
+}
+
+// HeaderCtxtMenu pulls up the context menu for the header of given field,
+// with options for sorting, filtering and grouping
+func (tv *TableView) HeaderCtxtMenu(fli int) {
+	if fli < 0 || fli >= tv.NVisFields {
+		return
+	}
+	fnm := tv.VisFields[fli].Name
+	var men gi.Menu
+	sortAct := func(lbl string, then, asc bool) {
+		men.AddAction(gi.ActOpts{Label: lbl}, tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			tvv := recv.Embed(KiT_TableView).(*TableView)
+			if then {
+				tvv.ThenSortBy(fnm, asc)
+			} else {
+				tvv.SortBy(fnm, asc)
+			}
+		})
+	}
+	sortAct("Sort Ascending", false, true)
+	sortAct("Sort Descending", false, false)
+	if tv.SortIdx >= 0 && tv.SortIdx != fli && tv.Source == nil {
+		sortAct("Then Sort Ascending", true, true)
+		sortAct("Then Sort Descending", true, false)
+	}
+	if tv.Source == nil {
+		men.AddSeparator("sep-filter")
+		men.AddAction(gi.ActOpts{Label: "Filter..."}, tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			tvv := recv.Embed(KiT_TableView).(*TableView)
+			tvv.FilterPrompt(fnm)
+		})
+		if tv.Filter(fnm) != nil {
+			men.AddAction(gi.ActOpts{Label: "Clear Filter"}, tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				tvv := recv.Embed(KiT_TableView).(*TableView)
+				tvv.SetFilter(fnm, "")
+			})
+		}
+		if len(tv.Filters) > 0 {
+			men.AddAction(gi.ActOpts{Label: "Clear All Filters"}, tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				tvv := recv.Embed(KiT_TableView).(*TableView)
+				tvv.ClearFilters()
+			})
+		}
+		men.AddSeparator("sep-group")
+		if tv.GroupField != fnm {
+			men.AddAction(gi.ActOpts{Label: "Group By " + fnm}, tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				tvv := recv.Embed(KiT_TableView).(*TableView)
+				tvv.GroupBy(fnm)
+			})
+		}
+		if tv.GroupField != "" {
+			men.AddAction(gi.ActOpts{Label: "Ungroup"}, tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				tvv := recv.Embed(KiT_TableView).(*TableView)
+				tvv.GroupBy("")
+			})
+			men.AddAction(gi.ActOpts{Label: "Collapse All Groups"}, tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				tvv := recv.Embed(KiT_TableView).(*TableView)
+				tvv.SetAllGroupsCollapsed(true)
+			})
+			men.AddAction(gi.ActOpts{Label: "Expand All Groups"}, tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				tvv := recv.Embed(KiT_TableView).(*TableView)
+				tvv.SetAllGroupsCollapsed(false)
+			})
+		}
+	}
+	_, idxOff := tv.RowWidgetNs()
+	hdr := tv.SliceHeader().Child(idxOff + fli).(gi.Node2D).AsWidget()
+	pos := hdr.ContextMenuPos()
+	gi.PopupMenu(men, pos.X, pos.Y, tv.ViewportSafe(), "tvHeaderMenu")
+}
+
+// FilterPrompt prompts for the filter expression for the field of given
+// name -- see TableFilter
+func (tv *TableView) FilterPrompt(field string) {
+	cur := ""
+	if tf := tv.Filter(field); tf != nil {
+		cur = tf.Expr
+	}
+	gi.StringPromptDialog(tv.ViewportSafe(), cur, "value, or =, !=, <, <=, >, >=, ~ (regexp), ! (not) value",
+		gi.DlgOpts{Title: "Filter " + field, Prompt: "Show only rows whose " + field + " matches this expression (blank for all)"},
+		tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			tvv := recv.Embed(KiT_TableView).(*TableView)
+			dlg := send.(*gi.Dialog)
+			if err := tvv.SetFilter(field, gi.StringPromptDialogValue(dlg)); err != nil {
+				gi.PromptDialog(tvv.ViewportSafe(), gi.DlgOpts{Title: "Invalid Filter", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+			}
+		})
+}
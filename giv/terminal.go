@@ -0,0 +1,888 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/girl"
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/histyle"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/cursor"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ints"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+	"github.com/goki/pi/filecat"
+	"github.com/goki/pi/token"
+	"golang.org/x/image/font"
+)
+
+// TermPalette is the set of colors used for displaying a Terminal: the
+// default foreground and background, and the 16 standard ANSI colors, from
+// which the rest of the xterm 256-color palette is derived
+type TermPalette struct {
+	Name   string         `desc:"name of the palette"`
+	Fg     gist.Color     `desc:"default foreground (text) color"`
+	Bg     gist.Color     `desc:"default background color"`
+	Cursor gist.Color     `desc:"color of the cursor"`
+	Select gist.Color     `desc:"background color of selected text"`
+	ANSI   [16]gist.Color `desc:"the standard ANSI colors: black, red, green, yellow, blue, magenta, cyan, white, and then the bright versions of each"`
+}
+
+// TermANSIXterm are the standard ANSI colors of xterm
+var TermANSIXterm = [16]string{
+	"#000000", "#CD0000", "#00CD00", "#CDCD00", "#0000EE", "#CD00CD", "#00CDCD", "#E5E5E5",
+	"#7F7F7F", "#FF0000", "#00FF00", "#FFFF00", "#5C5CFF", "#FF00FF", "#00FFFF", "#FFFFFF",
+}
+
+// NewTermPaletteXterm returns a new palette with the standard xterm colors,
+// with given default foreground and background colors
+func NewTermPaletteXterm(name string, fg, bg string) *TermPalette {
+	tp := &TermPalette{Name: name}
+	tp.Fg.SetString(fg, nil)
+	tp.Bg.SetString(bg, nil)
+	for i, cs := range TermANSIXterm {
+		tp.ANSI[i].SetString(cs, nil)
+	}
+	tp.Cursor = tp.Fg
+	tp.Select = tp.Bg.Blend(30, tp.Fg)
+	return tp
+}
+
+// TermPaletteFromHiStyle returns a palette derived from given syntax
+// highlighting style: the default colors are those of its text and
+// background, with the standard xterm ANSI colors, except that black and
+// white are the darker and lighter of those defaults
+func TermPaletteFromHiStyle(name string, hs *histyle.Style) *TermPalette {
+	tp := NewTermPaletteXterm(name, "black", "white")
+	if hs == nil {
+		return tp
+	}
+	if bg := hs.TagRaw(token.Background).Background; !bg.IsNil() {
+		tp.Bg = bg
+	}
+	if txt := hs.Tag(token.Text); !txt.Color.IsNil() {
+		tp.Fg = txt.Color
+	} else if tp.Bg.IsDark() {
+		tp.Fg.SetString("#E5E5E5", nil)
+	}
+	if tp.Bg.IsDark() {
+		tp.ANSI[0] = tp.Bg.Lighter(10)
+		tp.ANSI[7] = tp.Fg
+	} else {
+		tp.ANSI[0] = tp.Fg
+		tp.ANSI[7] = tp.Bg.Darker(10)
+	}
+	tp.Cursor = tp.Fg
+	tp.Select = tp.Bg.Blend(30, tp.Fg)
+	return tp
+}
+
+// TermPalettes are the available named palettes for Terminal -- the
+// palette of a Terminal with no Palette name is derived from the current
+// syntax highlighting style
+var TermPalettes = map[string]*TermPalette{
+	"xterm":          NewTermPaletteXterm("xterm", "black", "white"),
+	"xterm-dark":     NewTermPaletteXterm("xterm-dark", "#E5E5E5", "black"),
+	"solarized":      termSolarized("solarized", false),
+	"solarized-dark": termSolarized("solarized-dark", true),
+}
+
+// termSolarized returns the solarized light or dark palette
+func termSolarized(name string, dark bool) *TermPalette {
+	tp := NewTermPaletteXterm(name, "#657B83", "#FDF6E3")
+	if dark {
+		tp.Fg.SetString("#839496", nil)
+		tp.Bg.SetString("#002B36", nil)
+	}
+	for i, cs := range [16]string{
+		"#073642", "#DC322F", "#859900", "#B58900", "#268BD2", "#D33682", "#2AA198", "#EEE8D5",
+		"#002B36", "#CB4B16", "#586E75", "#657B83", "#839496", "#6C71C4", "#93A1A1", "#FDF6E3",
+	} {
+		tp.ANSI[i].SetString(cs, nil)
+	}
+	tp.Cursor = tp.Fg
+	tp.Select = tp.Bg.Blend(30, tp.Fg)
+	return tp
+}
+
+// Color returns the color for given TermColor, with given default for
+// TermColorDefault -- colors 16-255 are the xterm 6x6x6 color cube and
+// grayscale ramp
+func (tp *TermPalette) Color(tc TermColor, def gist.Color) gist.Color {
+	var c gist.Color
+	switch {
+	case tc < 0:
+		return def
+	case tc.IsRGB():
+		r, g, b := tc.RGB()
+		c.SetUInt8(r, g, b, 255)
+	case tc < 16:
+		return tp.ANSI[tc]
+	case tc < 232:
+		ci := int(tc) - 16
+		lvl := func(v int) uint8 {
+			if v == 0 {
+				return 0
+			}
+			return uint8(55 + v*40)
+		}
+		c.SetUInt8(lvl(ci/36), lvl((ci/6)%6), lvl(ci%6), 255)
+	default:
+		gv := uint8(8 + (int(tc)-232)*10)
+		c.SetUInt8(gv, gv, gv, 255)
+	}
+	return c
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//  Terminal
+
+// Terminal is a terminal emulator widget, which runs a command (by default
+// the user's shell) in a pseudo-terminal, and displays its output, with
+// VT100 / xterm escape sequences, including 256-color and 24-bit colors
+// mapped through its TermPalette.  Keys typed while it has the focus are
+// sent to the command.  Lines scrolled off the top are kept as scrollback,
+// viewed by scrolling with the mouse wheel or Shift+PageUp / PageDown.
+// Text is selected by dragging with the mouse, and copied with the Copy key
+// function or Ctrl+Shift+C -- a Ctrl+C Copy key is only used for copying when
+// there is a selection, else it is sent to the command.  The Paste key
+// function (unless it is a Ctrl key) or Ctrl+Shift+V pastes to the command.
+type Terminal struct {
+	gi.WidgetBase
+	Command   []string    `desc:"command to run, with its arguments -- if empty, the SHELL environment variable is used, or /bin/sh"`
+	Dir       string      `desc:"working directory for the command -- the current directory if empty"`
+	Env       []string    `desc:"additional environment variables for the command, in the form key=value -- TERM is set to xterm-256color"`
+	Palette   string      `desc:"name of the palette in TermPalettes used to display the terminal -- if empty, a palette is derived from the current syntax highlighting style"`
+	Screen    *TermScreen `json:"-" xml:"-" desc:"the screen contents, including the scrollback"`
+	Cmd       *exec.Cmd   `json:"-" xml:"-" view:"-" desc:"the running command"`
+	PTY       *os.File    `json:"-" xml:"-" view:"-" desc:"the master side of the pseudo-terminal of the command"`
+	ScrollOff int         `json:"-" xml:"-" desc:"number of lines scrolled back into the scrollback -- 0 shows the live screen"`
+	SelStart  TermPos     `json:"-" xml:"-" desc:"start of the selected text"`
+	SelEnd    TermPos     `json:"-" xml:"-" desc:"end of the selected text -- same as SelStart if none"`
+	CharSize  mat32.Vec2  `json:"-" xml:"-" desc:"size of each character cell, in dots"`
+	TermSig   ki.Signal   `json:"-" xml:"-" view:"-" desc:"signal for terminal events -- see TerminalSignals for the types"`
+	Mu        sync.Mutex  `json:"-" xml:"-" view:"-" desc:"mutex protecting the Screen, which is updated by the output of the command"`
+	pal       *TermPalette
+	faces     [4]font.Face
+	renderSet bool
+}
+
+var KiT_Terminal = kit.Types.AddType(&Terminal{}, TerminalProps)
+
+// AddNewTerminal adds a new terminal to given parent node, with given name.
+func AddNewTerminal(parent ki.Ki, name string) *Terminal {
+	return parent.AddNewChild(KiT_Terminal, name).(*Terminal)
+}
+
+func (tm *Terminal) Disconnect() {
+	tm.WidgetBase.Disconnect()
+	tm.TermSig.DisconnectAll()
+}
+
+var TerminalProps = ki.Props{
+	"EnumType:Flag": gi.KiT_NodeFlags,
+	"font-family":   &gi.Prefs.MonoFont,
+	"padding":       units.NewPx(2),
+	"width":         units.NewCh(80),
+	"height":        units.NewEm(12),
+	"min-width":     units.NewCh(20),
+	"min-height":    units.NewEm(4),
+	"max-width":     -1,
+	"max-height":    -1,
+}
+
+// TerminalSignals are signals that Terminal sends on its TermSig
+type TerminalSignals int64
+
+const (
+	// TerminalExited is sent when the command has exited -- the data is
+	// the error returned by waiting for it (nil if it exited normally)
+	TerminalExited TerminalSignals = iota
+
+	// TerminalTitle is sent when the command sets the title, with the
+	// title string as the data
+	TerminalTitle
+
+	// TerminalBell is sent when the command rings the bell
+	TerminalBell
+
+	TerminalSignalsN
+)
+
+//go:generate stringer -type=TerminalSignals
+
+// TerminalRenderMSec is the minimum number of milliseconds between
+// renders of the terminal when there is output from the command
+var TerminalRenderMSec = 20
+
+// Start starts the command in a new pseudo-terminal, with the size of the
+// terminal as currently displayed
+func (tm *Terminal) Start() error {
+	if tm.Cmd != nil {
+		return fmt.Errorf("giv.Terminal: command is already running")
+	}
+	cmdArgs := tm.Command
+	if len(cmdArgs) == 0 {
+		sh := os.Getenv("SHELL")
+		if sh == "" {
+			sh = "/bin/sh"
+		}
+		cmdArgs = []string{sh}
+	}
+	tm.Mu.Lock()
+	if tm.Screen == nil {
+		tm.Screen = NewTermScreen(24, 80)
+	}
+	rows, cols := tm.Screen.Rows, tm.Screen.Cols
+	tm.Mu.Unlock()
+	cmd := exec.Command(cmdArgs[0], cmdArgs[1:]...)
+	cmd.Dir = tm.Dir
+	cmd.Env = append(os.Environ(), "TERM=xterm-256color", "COLORTERM=truecolor")
+	cmd.Env = append(cmd.Env, tm.Env...)
+	ptm, err := StartPTY(cmd, rows, cols)
+	if err != nil {
+		return err
+	}
+	tm.Cmd = cmd
+	tm.PTY = ptm
+	tm.Screen.Reply = func(b []byte) { ptm.Write(b) }
+	go tm.ReadOutput(ptm, cmd)
+	return nil
+}
+
+// Stop stops the command, if running
+func (tm *Terminal) Stop() {
+	if tm.Cmd != nil && tm.Cmd.Process != nil {
+		tm.Cmd.Process.Kill()
+	}
+}
+
+// IsRunning returns true if the command is running
+func (tm *Terminal) IsRunning() bool {
+	return tm.Cmd != nil
+}
+
+// ReadOutput reads the output of the command from the pseudo-terminal
+// into the screen until it exits -- run in a separate goroutine by Start
+func (tm *Terminal) ReadOutput(ptm *os.File, cmd *exec.Cmd) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := ptm.Read(buf)
+		if n > 0 {
+			tm.WriteOutput(buf[:n])
+		}
+		if err != nil {
+			break
+		}
+	}
+	err := cmd.Wait()
+	ptm.Close()
+	tm.Mu.Lock()
+	if tm.Cmd == cmd {
+		tm.Cmd = nil
+		tm.PTY = nil
+		tm.Screen.Reply = nil
+	}
+	tm.Mu.Unlock()
+	tm.TermSig.Emit(tm.This(), int64(TerminalExited), err)
+}
+
+// WriteOutput writes given output to the screen, as from the command, and
+// schedules a render -- it can be used to display output without a command
+func (tm *Terminal) WriteOutput(b []byte) {
+	tm.Mu.Lock()
+	if tm.Screen == nil {
+		tm.Screen = NewTermScreen(24, 80)
+	}
+	ts := tm.Screen
+	title, bells := ts.Title, ts.Bells
+	ts.Write(b)
+	newTitle := ts.Title != title
+	rang := ts.Bells != bells
+	ts.Bells = 0
+	title = ts.Title
+	tm.Mu.Unlock()
+	if newTitle {
+		tm.TermSig.Emit(tm.This(), int64(TerminalTitle), title)
+	}
+	if rang {
+		tm.TermSig.Emit(tm.This(), int64(TerminalBell), nil)
+	}
+	tm.ScheduleRender()
+}
+
+// Write writes given input to the command -- implements io.Writer
+func (tm *Terminal) Write(b []byte) (int, error) {
+	ptm := tm.PTY
+	if ptm == nil {
+		return 0, io.ErrClosedPipe
+	}
+	return ptm.Write(b)
+}
+
+// ScheduleRender renders the terminal after TerminalRenderMSec, unless a
+// render is already scheduled, so that bursts of output are rendered once
+func (tm *Terminal) ScheduleRender() {
+	tm.Mu.Lock()
+	if tm.renderSet {
+		tm.Mu.Unlock()
+		return
+	}
+	tm.renderSet = true
+	tm.Mu.Unlock()
+	time.AfterFunc(time.Duration(TerminalRenderMSec)*time.Millisecond, func() {
+		tm.Mu.Lock()
+		tm.renderSet = false
+		tm.Mu.Unlock()
+		tm.RenderTerm()
+	})
+}
+
+// CurPalette returns the palette for displaying the terminal
+func (tm *Terminal) CurPalette() *TermPalette {
+	if tp, ok := TermPalettes[tm.Palette]; ok {
+		return tp
+	}
+	if tm.pal == nil || gist.RebuildDefaultStyles {
+		tm.pal = TermPaletteFromHiStyle("", histyle.AvailStyle(histyle.StyleDefault))
+	}
+	return tm.pal
+}
+
+// VisRows returns the number of rows and columns that fit in the terminal
+// as currently displayed
+func (tm *Terminal) VisRowsCols() (rows, cols int) {
+	if tm.CharSize.X <= 0 || tm.CharSize.Y <= 0 {
+		return 24, 80
+	}
+	sz := tm.LayState.Alloc.Size.SubScalar(2 * tm.Sty.BoxSpace())
+	rows = ints.MaxInt(int(sz.Y/tm.CharSize.Y), 1)
+	cols = ints.MaxInt(int(sz.X/tm.CharSize.X), 1)
+	return
+}
+
+// ResizeScreen resizes the screen (and pseudo-terminal) to fit the terminal
+// as currently displayed
+func (tm *Terminal) ResizeScreen() {
+	rows, cols := tm.VisRowsCols()
+	tm.Mu.Lock()
+	defer tm.Mu.Unlock()
+	if tm.Screen == nil {
+		tm.Screen = NewTermScreen(rows, cols)
+		return
+	}
+	if tm.Screen.Rows == rows && tm.Screen.Cols == cols {
+		return
+	}
+	tm.Screen.Resize(rows, cols)
+	if tm.PTY != nil {
+		SetPTYSize(tm.PTY, rows, cols)
+	}
+}
+
+// FirstRow returns the first row of the screen lines that is displayed,
+// according to ScrollOff -- must be called under Mu lock
+func (tm *Terminal) FirstRow() int {
+	ts := tm.Screen
+	tm.ScrollOff = ints.MaxInt(0, ints.MinInt(tm.ScrollOff, len(ts.Scrollback)))
+	return len(ts.Scrollback) - tm.ScrollOff
+}
+
+// ScrollBy scrolls the view by given number of lines (negative = back into
+// the scrollback)
+func (tm *Terminal) ScrollBy(lines int) {
+	tm.Mu.Lock()
+	tm.ScrollOff -= lines
+	if tm.Screen != nil {
+		tm.FirstRow() // clips
+	}
+	tm.Mu.Unlock()
+	tm.RenderTerm()
+}
+
+// PosAt returns the text position for given point in window coordinates
+func (tm *Terminal) PosAt(pt image.Point) TermPos {
+	spc := tm.Sty.BoxSpace()
+	rp := mat32.NewVec2FmPoint(pt).Sub(tm.LayState.Alloc.Pos).SubScalar(spc)
+	tm.Mu.Lock()
+	defer tm.Mu.Unlock()
+	if tm.Screen == nil || tm.CharSize.X <= 0 {
+		return TermPos{}
+	}
+	row := tm.FirstRow() + int(mat32.Floor(rp.Y/tm.CharSize.Y))
+	col := int(mat32.Floor(rp.X/tm.CharSize.X + 0.5))
+	row = ints.MaxInt(0, ints.MinInt(row, tm.Screen.NumLines()-1))
+	col = ints.MaxInt(0, ints.MinInt(col, tm.Screen.Cols))
+	return TermPos{Row: row, Col: col}
+}
+
+// HasSelection returns true if there is selected text
+func (tm *Terminal) HasSelection() bool {
+	return tm.SelStart != tm.SelEnd
+}
+
+// IsSelected returns true if given cell is in the selection
+func (tm *Terminal) IsSelected(row, col int) bool {
+	if !tm.HasSelection() {
+		return false
+	}
+	st, ed := tm.SelStart, tm.SelEnd
+	if ed.IsLess(st) {
+		st, ed = ed, st
+	}
+	pos := TermPos{Row: row, Col: col}
+	return !pos.IsLess(st) && pos.IsLess(ed)
+}
+
+// Selection returns the selected text
+func (tm *Terminal) Selection() string {
+	if !tm.HasSelection() {
+		return ""
+	}
+	tm.Mu.Lock()
+	defer tm.Mu.Unlock()
+	return tm.Screen.Text(tm.SelStart, tm.SelEnd)
+}
+
+// SelectReset clears the selection
+func (tm *Terminal) SelectReset() {
+	tm.SelEnd = tm.SelStart
+}
+
+// Copy copies the selected text to the clipboard
+func (tm *Terminal) Copy() {
+	txt := tm.Selection()
+	if txt == "" {
+		return
+	}
+	oswin.TheApp.ClipBoard(tm.ParentWindow().OSWin).Write(mimedata.NewText(txt))
+}
+
+// Paste sends the text from the clipboard to the command, as bracketed
+// paste if the command has enabled it
+func (tm *Terminal) Paste() {
+	data := oswin.TheApp.ClipBoard(tm.ParentWindow().OSWin).Read([]string{filecat.TextPlain})
+	if data == nil {
+		return
+	}
+	txt := data.TypeData(filecat.TextPlain)
+	if len(txt) == 0 {
+		return
+	}
+	tm.Mu.Lock()
+	bp := tm.Screen != nil && tm.Screen.BracketPaste
+	tm.Mu.Unlock()
+	if bp {
+		txt = append(append([]byte("\x1b[200~"), txt...), "\x1b[201~"...)
+	}
+	tm.Write(txt)
+}
+
+// termKeyCodes are the sequences sent for special keys, for normal and
+// application cursor key modes
+var termKeyCodes = map[key.Codes][2]string{
+	key.CodeUpArrow:         {"\x1b[A", "\x1bOA"},
+	key.CodeDownArrow:       {"\x1b[B", "\x1bOB"},
+	key.CodeRightArrow:      {"\x1b[C", "\x1bOC"},
+	key.CodeLeftArrow:       {"\x1b[D", "\x1bOD"},
+	key.CodeHome:            {"\x1b[H", "\x1bOH"},
+	key.CodeEnd:             {"\x1b[F", "\x1bOF"},
+	key.CodeInsert:          {"\x1b[2~", "\x1b[2~"},
+	key.CodeDeleteForward:   {"\x1b[3~", "\x1b[3~"},
+	key.CodePageUp:          {"\x1b[5~", "\x1b[5~"},
+	key.CodePageDown:        {"\x1b[6~", "\x1b[6~"},
+	key.CodeF1:              {"\x1bOP", "\x1bOP"},
+	key.CodeF2:              {"\x1bOQ", "\x1bOQ"},
+	key.CodeF3:              {"\x1bOR", "\x1bOR"},
+	key.CodeF4:              {"\x1bOS", "\x1bOS"},
+	key.CodeF5:              {"\x1b[15~", "\x1b[15~"},
+	key.CodeF6:              {"\x1b[17~", "\x1b[17~"},
+	key.CodeF7:              {"\x1b[18~", "\x1b[18~"},
+	key.CodeF8:              {"\x1b[19~", "\x1b[19~"},
+	key.CodeF9:              {"\x1b[20~", "\x1b[20~"},
+	key.CodeF10:             {"\x1b[21~", "\x1b[21~"},
+	key.CodeF11:             {"\x1b[23~", "\x1b[23~"},
+	key.CodeF12:             {"\x1b[24~", "\x1b[24~"},
+	key.CodeReturnEnter:     {"\r", "\r"},
+	key.CodeKeypadEnter:     {"\r", "\r"},
+	key.CodeTab:             {"\t", "\t"},
+	key.CodeEscape:          {"\x1b", "\x1b"},
+	key.CodeDeleteBackspace: {"\x7f", "\x7f"},
+}
+
+// TermKeyBytes returns the bytes to send to the command for given key
+// event, in normal or application cursor key mode -- nil if none
+func TermKeyBytes(kt *key.ChordEvent, appCursor bool) []byte {
+	ctrl := kt.HasAnyModifier(key.Control)
+	alt := kt.HasAnyModifier(key.Alt)
+	var b []byte
+	if kc, ok := termKeyCodes[kt.Code]; ok {
+		if kt.Code == key.CodeTab && kt.HasAnyModifier(key.Shift) {
+			return []byte("\x1b[Z")
+		}
+		if appCursor {
+			b = []byte(kc[1])
+		} else {
+			b = []byte(kc[0])
+		}
+	} else {
+		r := kt.Rune
+		switch {
+		case ctrl && r >= 'a' && r <= 'z':
+			b = []byte{byte(r - 'a' + 1)}
+		case ctrl && r >= '@' && r <= '_':
+			b = []byte{byte(r - '@')}
+		case ctrl && r == ' ':
+			b = []byte{0}
+		case r > 0 && r != utf8.RuneError:
+			b = []byte(string(r))
+		default:
+			return nil
+		}
+	}
+	if alt {
+		b = append([]byte{0x1b}, b...)
+	}
+	return b
+}
+
+// KeyInput handles a key event: shortcuts for the terminal itself are
+// Shift+PageUp / PageDown to scroll, and the copy and paste keys described
+// for Terminal -- all other keys are sent to the command
+func (tm *Terminal) KeyInput(kt *key.ChordEvent) {
+	if kt.HasAllModifier(key.Control, key.Shift) {
+		switch kt.Code {
+		case key.CodeC:
+			kt.SetProcessed()
+			tm.Copy()
+			return
+		case key.CodeV:
+			kt.SetProcessed()
+			tm.Paste()
+			return
+		}
+	}
+	if kt.HasAnyModifier(key.Shift) {
+		switch kt.Code {
+		case key.CodePageUp:
+			kt.SetProcessed()
+			tm.ScrollBy(-tm.Screen.Rows / 2)
+			return
+		case key.CodePageDown:
+			kt.SetProcessed()
+			tm.ScrollBy(tm.Screen.Rows / 2)
+			return
+		}
+	}
+	kf := gi.KeyFun(kt.Chord())
+	if kf == gi.KeyFunCopy && (tm.HasSelection() || !kt.HasAnyModifier(key.Control)) {
+		kt.SetProcessed()
+		tm.Copy()
+		return
+	}
+	if kf == gi.KeyFunPaste && !kt.HasAnyModifier(key.Control) {
+		kt.SetProcessed()
+		tm.Paste()
+		return
+	}
+	if kt.HasAnyModifier(key.Meta) || tm.PTY == nil {
+		return
+	}
+	tm.Mu.Lock()
+	app := tm.Screen != nil && tm.Screen.AppCursor
+	tm.Mu.Unlock()
+	b := TermKeyBytes(kt, app)
+	if b == nil {
+		return
+	}
+	kt.SetProcessed()
+	tm.SelectReset()
+	if tm.ScrollOff != 0 {
+		tm.ScrollOff = 0
+		tm.RenderTerm()
+	}
+	tm.Write(b)
+}
+
+// MouseEvents connects the mouse events, for selecting text, pasting with
+// the middle button, and scrolling
+func (tm *Terminal) MouseEvents() {
+	tm.ConnectEvent(oswin.MouseEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.Event)
+		tmv := recv.Embed(KiT_Terminal).(*Terminal)
+		if !tmv.HasFocus() {
+			tmv.GrabFocus()
+		}
+		switch {
+		case me.Button == mouse.Left && me.Action == mouse.Press:
+			me.SetProcessed()
+			pos := tmv.PosAt(me.Pos())
+			if me.HasAnyModifier(key.Shift) {
+				tmv.SelEnd = pos
+			} else {
+				tmv.SelStart = pos
+				tmv.SelEnd = pos
+			}
+			tmv.RenderTerm()
+		case me.Button == mouse.Left && me.Action == mouse.DoubleClick:
+			me.SetProcessed()
+			tmv.SelectWord(tmv.PosAt(me.Pos()))
+			tmv.RenderTerm()
+		case me.Button == mouse.Middle && me.Action == mouse.Press:
+			me.SetProcessed()
+			tmv.Paste()
+		}
+	})
+	tm.ConnectEvent(oswin.MouseDragEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.DragEvent)
+		me.SetProcessed()
+		tmv := recv.Embed(KiT_Terminal).(*Terminal)
+		tmv.SelEnd = tmv.PosAt(me.Pos())
+		tmv.RenderTerm()
+	})
+	tm.ConnectEvent(oswin.MouseScrollEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.ScrollEvent)
+		me.SetProcessed()
+		tmv := recv.Embed(KiT_Terminal).(*Terminal)
+		del := me.NonZeroDelta(false)
+		lines := 0
+		if tmv.CharSize.Y > 0 {
+			lines = int(float32(del) / tmv.CharSize.Y)
+		}
+		if lines == 0 && del != 0 {
+			lines = ints.MaxInt(ints.MinInt(del, 1), -1)
+		}
+		tmv.ScrollBy(lines)
+	})
+	tm.ConnectEvent(oswin.MouseFocusEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.FocusEvent)
+		me.SetProcessed()
+		tmv := recv.Embed(KiT_Terminal).(*Terminal)
+		if me.Action == mouse.Enter {
+			oswin.TheApp.Cursor(tmv.ParentWindow().OSWin).PushIfNot(cursor.IBeam)
+		} else {
+			oswin.TheApp.Cursor(tmv.ParentWindow().OSWin).PopIf(cursor.IBeam)
+		}
+	})
+}
+
+// SelectWord selects the word (run of non-space characters) at given position
+func (tm *Terminal) SelectWord(pos TermPos) {
+	tm.Mu.Lock()
+	defer tm.Mu.Unlock()
+	ln := tm.Screen.Line(pos.Row)
+	if pos.Col >= len(ln) || ln[pos.Col].Rune == ' ' {
+		return
+	}
+	st, ed := pos.Col, pos.Col
+	for st > 0 && ln[st-1].Rune != ' ' {
+		st--
+	}
+	for ed < len(ln) && ln[ed].Rune != ' ' {
+		ed++
+	}
+	tm.SelStart = TermPos{Row: pos.Row, Col: st}
+	tm.SelEnd = TermPos{Row: pos.Row, Col: ed}
+}
+
+// SetFaces sets the font faces for normal, bold, italic and bold italic
+// text, and the character cell size, from the style
+func (tm *Terminal) SetFaces() {
+	sty := &tm.Sty
+	girl.OpenFont(&sty.Font, &sty.UnContext)
+	for i := range tm.faces {
+		fs := sty.Font
+		if i&1 != 0 {
+			fs.Weight = gist.WeightBold
+		}
+		if i&2 != 0 {
+			fs.Style = gist.FontItalic
+		}
+		if i == 0 {
+			tm.faces[i] = sty.Font.Face.Face
+			continue
+		}
+		girl.OpenFont(&fs, &sty.UnContext)
+		tm.faces[i] = fs.Face.Face
+	}
+	tm.CharSize = mat32.NewVec2(sty.Font.Face.Metrics.Ch, sty.Font.Face.Metrics.Height)
+}
+
+// RenderTerm renders the terminal outside of the update process -- used
+// for updates from the command output and user actions
+func (tm *Terminal) RenderTerm() {
+	if tm == nil || tm.This() == nil || tm.IsDeleted() || tm.IsDestroyed() {
+		return
+	}
+	if !tm.This().(gi.Node2D).IsVisible() || tm.Viewport == nil {
+		return
+	}
+	wupdt := tm.TopUpdateStart()
+	rs := tm.Render()
+	rs.PushBounds(tm.VpBBox)
+	tm.RenderScreen()
+	rs.PopBounds()
+	tm.Viewport.This().(gi.Viewport).VpUploadRegion(tm.VpBBox, tm.WinBBox)
+	tm.TopUpdateEnd(wupdt)
+}
+
+// RenderScreen renders the visible lines of the screen, with the selection
+// and cursor
+func (tm *Terminal) RenderScreen() {
+	rs, pc, sty := tm.RenderLock()
+	defer tm.RenderUnlock(rs)
+	tp := tm.CurPalette()
+	pos := mat32.NewVec2FmPoint(tm.VpBBox.Min)
+	pc.FillBoxColor(rs, pos, mat32.NewVec2FmPoint(tm.VpBBox.Size()), tp.Bg)
+	tm.Mu.Lock()
+	defer tm.Mu.Unlock()
+	ts := tm.Screen
+	if ts == nil || tm.faces[0] == nil {
+		return
+	}
+	cw, ch := tm.CharSize.X, tm.CharSize.Y
+	asc := mat32.FromFixed(tm.faces[0].Metrics().Ascent)
+	pos = tm.LayState.Alloc.Pos.AddScalar(sty.BoxSpace())
+	fst := tm.FirstRow()
+	focus := tm.HasFocus()
+	for r := 0; r < ts.Rows; r++ {
+		row := fst + r
+		ln := ts.Line(row)
+		if ln == nil {
+			break
+		}
+		y := pos.Y + float32(r)*ch
+		sr := girl.Span{}
+		sr.Init(len(ln))
+		sr.RelPos.Y = asc
+		for c := range ln {
+			cell := &ln[c]
+			fg := tp.Color(cell.Fg, tp.Fg)
+			bg := tp.Color(cell.Bg, tp.Bg)
+			if (cell.HasAttr(TermBold) || cell.HasAttr(TermBlink)) && cell.Fg >= 0 && cell.Fg < 8 {
+				fg = tp.ANSI[cell.Fg+8]
+			}
+			if cell.HasAttr(TermInverse) {
+				fg, bg = bg, fg
+			}
+			if tm.IsSelected(row, c) {
+				bg = tp.Select
+			}
+			isCur := tm.ScrollOff == 0 && ts.CursorVisible && r == ts.Cur.Row && c == ts.Cur.Col
+			if isCur && focus {
+				fg, bg = tp.Bg, tp.Cursor
+			}
+			if cell.HasAttr(TermDim) {
+				fg = fg.Blend(50, bg)
+			}
+			if cell.HasAttr(TermHidden) {
+				fg = bg
+			}
+			x := pos.X + float32(c)*cw
+			if bg != tp.Bg {
+				pc.FillBoxColor(rs, mat32.NewVec2(x, y), mat32.NewVec2(cw, ch), bg)
+			}
+			if isCur && !focus {
+				pc.StrokeStyle.SetColor(tp.Cursor)
+				pc.StrokeStyle.Width.Dots = 1
+				pc.DrawRectangle(rs, x+0.5, y+0.5, cw-1, ch-1)
+				pc.Stroke(rs)
+			}
+			fi := 0
+			if cell.HasAttr(TermBold) || cell.HasAttr(TermBlink) {
+				fi |= 1
+			}
+			if cell.HasAttr(TermItalic) {
+				fi |= 2
+			}
+			var deco gist.TextDecorations
+			if cell.HasAttr(TermUnderline) {
+				deco |= 1 << uint32(gist.DecoUnderline)
+			}
+			if cell.HasAttr(TermStrike) {
+				deco |= 1 << uint32(gist.DecoLineThrough)
+			}
+			var clr color.Color = fg
+			sr.AppendRune(cell.Rune, tm.faces[fi], clr, nil, nil, deco)
+			rr := &sr.Render[len(sr.Render)-1]
+			rr.RelPos.X = float32(c) * cw
+			rr.Size = mat32.NewVec2(cw, ch)
+		}
+		sr.LastPos.X = float32(len(ln)) * cw
+		txt := girl.Text{Spans: []girl.Span{sr}}
+		txt.Render(rs, mat32.NewVec2(pos.X, y))
+	}
+}
+
+// Style2D sets the style and the font faces
+func (tm *Terminal) Style2D() {
+	tm.SetCanFocusIfActive()
+	tm.Style2DWidget()
+	tm.SetFaces()
+}
+
+// Size2D sets the size from the style, with a minimum of one character
+func (tm *Terminal) Size2D(iter int) {
+	tm.InitLayout2D()
+	tm.Size2DFromWH(tm.CharSize.X, tm.CharSize.Y)
+}
+
+// Layout2D lays out the terminal and resizes the screen to fit
+func (tm *Terminal) Layout2D(parBBox image.Rectangle, iter int) bool {
+	tm.Layout2DBase(parBBox, true, iter)
+	tm.ResizeScreen()
+	return tm.Layout2DChildren(iter)
+}
+
+func (tm *Terminal) Render2D() {
+	if tm.FullReRenderIfNeeded() {
+		return
+	}
+	if tm.PushBounds() {
+		tm.This().(gi.Node2D).ConnectEvents2D()
+		tm.RenderScreen()
+		tm.PopBounds()
+	} else {
+		tm.DisconnectAllEvents(gi.RegPri)
+	}
+}
+
+func (tm *Terminal) ConnectEvents2D() {
+	tm.MouseEvents()
+	tm.ConnectEvent(oswin.KeyChordEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		tmv := recv.Embed(KiT_Terminal).(*Terminal)
+		tmv.KeyInput(d.(*key.ChordEvent))
+	})
+}
+
+// FocusChanged2D re-renders the cursor, which is only filled when focused
+func (tm *Terminal) FocusChanged2D(change gi.FocusChanges) {
+	switch change {
+	case gi.FocusGot, gi.FocusLost:
+		tm.RenderTerm()
+	}
+}
+
+// Destroy stops the command when the terminal is destroyed
+func (tm *Terminal) Destroy() {
+	tm.Stop()
+	tm.WidgetBase.Destroy()
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux
+
+package giv
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// StartPTY starts the command with its standard input, output and error
+// connected to a new pseudo-terminal of given size, as the controlling
+// terminal of a new session -- returns the master side of the
+// pseudo-terminal, for reading the output of the command and writing
+// its input
+func StartPTY(cmd *exec.Cmd, rows, cols int) (*os.File, error) {
+	ptm, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	var n uint32
+	if err := ptyIoctl(ptm, syscall.TIOCGPTN, uintptr(unsafe.Pointer(&n))); err != nil {
+		ptm.Close()
+		return nil, err
+	}
+	var unlock int32
+	if err := ptyIoctl(ptm, syscall.TIOCSPTLCK, uintptr(unsafe.Pointer(&unlock))); err != nil {
+		ptm.Close()
+		return nil, err
+	}
+	pts, err := os.OpenFile("/dev/pts/"+strconv.Itoa(int(n)), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		ptm.Close()
+		return nil, err
+	}
+	defer pts.Close()
+	SetPTYSize(ptm, rows, cols)
+	cmd.Stdin = pts
+	cmd.Stdout = pts
+	cmd.Stderr = pts
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setsid = true
+	cmd.SysProcAttr.Setctty = true
+	if err := cmd.Start(); err != nil {
+		ptm.Close()
+		return nil, err
+	}
+	return ptm, nil
+}
+
+// SetPTYSize sets the size of the pseudo-terminal, which sends the SIGWINCH
+// signal to its programs
+func SetPTYSize(ptm *os.File, rows, cols int) error {
+	ws := struct{ Row, Col, X, Y uint16 }{Row: uint16(rows), Col: uint16(cols)}
+	return ptyIoctl(ptm, syscall.TIOCSWINSZ, uintptr(unsafe.Pointer(&ws)))
+}
+
+// ptyIoctl does an ioctl on the pseudo-terminal
+func ptyIoctl(f *os.File, cmd, ptr uintptr) error {
+	_, _, e := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), cmd, ptr)
+	if e != 0 {
+		return e
+	}
+	return nil
+}
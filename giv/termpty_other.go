@@ -0,0 +1,30 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !linux
+
+package giv
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// ErrNoPTY is returned by StartPTY on platforms without pseudo-terminal
+// support
+var ErrNoPTY = errors.New("giv.StartPTY: pseudo-terminals are not supported on this platform")
+
+// StartPTY starts the command with its standard input, output and error
+// connected to a new pseudo-terminal of given size -- not supported on
+// this platform
+func StartPTY(cmd *exec.Cmd, rows, cols int) (*os.File, error) {
+	return nil, ErrNoPTY
+}
+
+// SetPTYSize sets the size of the pseudo-terminal -- not supported on
+// this platform
+func SetPTYSize(ptm *os.File, rows, cols int) error {
+	return ErrNoPTY
+}
@@ -0,0 +1,1059 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/goki/ki/bitflag"
+	"github.com/goki/ki/ints"
+	"github.com/goki/ki/kit"
+)
+
+// TermColor is the color of a TermCell: TermColorDefault for the default
+// foreground or background color, 0-255 for the xterm 256-color palette
+// (0-15 being the standard ANSI colors), or a 24-bit RGB color made by
+// TermRGB
+type TermColor int32
+
+// TermColorDefault is the default foreground or background color
+const TermColorDefault TermColor = -1
+
+// termColorRGB is the flag for 24-bit RGB TermColors
+const termColorRGB TermColor = 1 << 24
+
+// TermRGB returns the TermColor for given 24-bit RGB color
+func TermRGB(r, g, b uint8) TermColor {
+	return termColorRGB | TermColor(r)<<16 | TermColor(g)<<8 | TermColor(b)
+}
+
+// IsRGB returns true if the color is a 24-bit RGB color
+func (tc TermColor) IsRGB() bool {
+	return tc >= 0 && tc&termColorRGB != 0
+}
+
+// RGB returns the components of a 24-bit RGB color
+func (tc TermColor) RGB() (r, g, b uint8) {
+	return uint8(tc >> 16), uint8(tc >> 8), uint8(tc)
+}
+
+// TermAttrs are the display attribute bit flags of a TermCell, as set by
+// the SGR escape sequence
+type TermAttrs int32
+
+const (
+	// TermBold is bold (or bright) text
+	TermBold TermAttrs = iota
+
+	// TermDim is dim (faint) text
+	TermDim
+
+	// TermItalic is italic text
+	TermItalic
+
+	// TermUnderline is underlined text
+	TermUnderline
+
+	// TermBlink is blinking text -- displayed as bold
+	TermBlink
+
+	// TermInverse swaps the foreground and background colors
+	TermInverse
+
+	// TermHidden is invisible text
+	TermHidden
+
+	// TermStrike is struck-through text
+	TermStrike
+
+	TermAttrsN
+)
+
+//go:generate stringer -type=TermAttrs
+
+var KiT_TermAttrs = kit.Enums.AddEnum(TermAttrsN, kit.BitFlag, nil)
+
+// TermCell is one character cell of a TermScreen
+type TermCell struct {
+	Rune  rune      `desc:"character in the cell"`
+	Fg    TermColor `desc:"foreground (text) color"`
+	Bg    TermColor `desc:"background color"`
+	Attrs int32     `desc:"display attributes, as TermAttrs bit flags"`
+}
+
+// HasAttr returns true if the cell has given attribute
+func (tc *TermCell) HasAttr(attr TermAttrs) bool {
+	return bitflag.Has32(tc.Attrs, int(attr))
+}
+
+// TermPos is a position in the text of a TermScreen: a Row in all the
+// lines, including the scrollback lines before the screen, and a Col
+type TermPos struct {
+	Row int
+	Col int
+}
+
+// IsLess returns true if the position is before the other one
+func (tp TermPos) IsLess(cmp TermPos) bool {
+	return tp.Row < cmp.Row || (tp.Row == cmp.Row && tp.Col < cmp.Col)
+}
+
+// TermMaxScrollback is the default maximum number of scrollback lines
+var TermMaxScrollback = 10000
+
+// termParseStates are the states of the escape sequence parser
+type termParseStates int
+
+const (
+	termGround termParseStates = iota
+	termEsc
+	termEscInter
+	termCSI
+	termOSC
+	termOSCEsc
+	termStr
+	termStrEsc
+)
+
+// termSaved is the cursor state saved by DECSC
+type termSaved struct {
+	cur    TermPos
+	attr   TermCell
+	origin bool
+	g0, g1 bool
+}
+
+// TermScreen is the screen of a terminal: it interprets the output of a
+// program, with VT100 / xterm escape sequences, into a grid of TermCells,
+// keeping the lines scrolled off the top of the screen as scrollback.  It
+// is an io.Writer for the output.  Each rune takes one cell.
+type TermScreen struct {
+	Rows          int            `desc:"number of rows of the screen"`
+	Cols          int            `desc:"number of columns of the screen"`
+	Lines         [][]TermCell   `desc:"the lines of the screen, each with Cols cells"`
+	Scrollback    [][]TermCell   `desc:"lines scrolled off the top of the screen, oldest first"`
+	MaxScrollback int            `desc:"maximum number of scrollback lines, after which the oldest are discarded"`
+	Cur           TermPos        `desc:"cursor position, within the screen"`
+	Attr          TermCell       `desc:"current colors and attributes for new text"`
+	Title         string         `desc:"window title, set by the OSC 0 or 2 escape sequence"`
+	CursorVisible bool           `desc:"cursor is shown"`
+	AppCursor     bool           `desc:"application cursor keys mode: arrow keys send ESC O sequences"`
+	AppKeypad     bool           `desc:"application keypad mode"`
+	AutoWrap      bool           `desc:"text wraps to the next line at the right margin"`
+	Insert        bool           `desc:"insert mode: new text shifts the rest of the line right"`
+	Origin        bool           `desc:"origin mode: cursor positions are relative to the scroll region"`
+	BracketPaste  bool           `desc:"bracketed paste mode: pasted text is enclosed in ESC [200~ and ESC [201~"`
+	AltScreen     bool           `desc:"the alternate screen (used by full-screen programs) is active -- it has no scrollback"`
+	Bells         int            `desc:"number of bell characters received -- reset by the user"`
+	Reply         func(b []byte) `json:"-" xml:"-" desc:"function called with replies to queries from the program (e.g., cursor position reports) -- typically writes them to the program's input"`
+
+	top, bot    int
+	wrapPending bool
+	tabs        []bool
+	g0, g1      bool // line drawing charset
+	shift       bool // using g1
+	saved       termSaved
+	altSaved    termSaved
+	mainLines   [][]TermCell
+	lastRune    rune
+	state       termParseStates
+	params      []int
+	priv        byte
+	inter       []byte
+	osc         []byte
+	utf         []byte
+}
+
+// NewTermScreen returns a new screen of given size
+func NewTermScreen(rows, cols int) *TermScreen {
+	ts := &TermScreen{}
+	ts.Rows = ints.MaxInt(rows, 1)
+	ts.Cols = ints.MaxInt(cols, 1)
+	ts.MaxScrollback = TermMaxScrollback
+	ts.Reset()
+	return ts
+}
+
+// Reset resets the screen to its initial state, clearing it, and also
+// the scrollback
+func (ts *TermScreen) Reset() {
+	ts.Attr = TermCell{Fg: TermColorDefault, Bg: TermColorDefault}
+	ts.Lines = make([][]TermCell, ts.Rows)
+	for i := range ts.Lines {
+		ts.Lines[i] = ts.blankLine()
+	}
+	ts.Scrollback = nil
+	ts.mainLines = nil
+	ts.Cur = TermPos{}
+	ts.CursorVisible = true
+	ts.AutoWrap = true
+	ts.AppCursor, ts.AppKeypad, ts.Insert, ts.Origin, ts.BracketPaste, ts.AltScreen = false, false, false, false, false, false
+	ts.top, ts.bot = 0, ts.Rows-1
+	ts.wrapPending = false
+	ts.g0, ts.g1, ts.shift = false, false, false
+	ts.saved = termSaved{attr: ts.Attr}
+	ts.resetTabs()
+	ts.state = termGround
+}
+
+// resetTabs sets tab stops every 8 columns
+func (ts *TermScreen) resetTabs() {
+	ts.tabs = make([]bool, ts.Cols)
+	for i := 8; i < ts.Cols; i += 8 {
+		ts.tabs[i] = true
+	}
+}
+
+// blankCell returns an erased cell, with the current background color
+func (ts *TermScreen) blankCell() TermCell {
+	return TermCell{Rune: ' ', Fg: TermColorDefault, Bg: ts.Attr.Bg}
+}
+
+// blankLine returns a new erased line
+func (ts *TermScreen) blankLine() []TermCell {
+	ln := make([]TermCell, ts.Cols)
+	bc := ts.blankCell()
+	for i := range ln {
+		ln[i] = bc
+	}
+	return ln
+}
+
+// NumLines returns the total number of lines: scrollback plus screen
+func (ts *TermScreen) NumLines() int {
+	return len(ts.Scrollback) + ts.Rows
+}
+
+// Line returns the line at given row of all the lines (scrollback plus
+// screen) -- nil if out of range
+func (ts *TermScreen) Line(row int) []TermCell {
+	nsb := len(ts.Scrollback)
+	switch {
+	case row < 0:
+		return nil
+	case row < nsb:
+		return ts.Scrollback[row]
+	case row-nsb < ts.Rows:
+		return ts.Lines[row-nsb]
+	}
+	return nil
+}
+
+// LineString returns the text of given line, without trailing spaces
+func (ts *TermScreen) LineString(row int) string {
+	ln := ts.Line(row)
+	rs := make([]rune, len(ln))
+	for i := range ln {
+		rs[i] = ln[i].Rune
+	}
+	return strings.TrimRight(string(rs), " ")
+}
+
+// Text returns the text between given positions (in either order), with
+// trailing spaces of the lines removed
+func (ts *TermScreen) Text(st, ed TermPos) string {
+	if ed.IsLess(st) {
+		st, ed = ed, st
+	}
+	var b strings.Builder
+	for row := st.Row; row <= ed.Row; row++ {
+		ln := ts.Line(row)
+		sc, ec := 0, len(ln)
+		if row == st.Row {
+			sc = ints.MinInt(st.Col, len(ln))
+		}
+		if row == ed.Row {
+			ec = ints.MinInt(ed.Col, len(ln))
+		}
+		rs := make([]rune, 0, ec-sc)
+		for i := sc; i < ec; i++ {
+			rs = append(rs, ln[i].Rune)
+		}
+		b.WriteString(strings.TrimRight(string(rs), " "))
+		if row < ed.Row {
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+// Resize changes the size of the screen -- when it gets shorter, lines are
+// removed from the top into the scrollback, as long as the cursor is below
+// the new bottom, and then from the bottom
+func (ts *TermScreen) Resize(rows, cols int) {
+	rows = ints.MaxInt(rows, 1)
+	cols = ints.MaxInt(cols, 1)
+	if rows == ts.Rows && cols == ts.Cols {
+		return
+	}
+	ts.Cols = cols
+	fit := func(lns [][]TermCell) {
+		for i, ln := range lns {
+			if len(ln) > cols {
+				lns[i] = ln[:cols]
+			}
+			for len(lns[i]) < cols {
+				lns[i] = append(lns[i], TermCell{Rune: ' ', Fg: TermColorDefault, Bg: TermColorDefault})
+			}
+		}
+	}
+	fit(ts.Lines)
+	if ts.mainLines != nil {
+		fit(ts.mainLines)
+	}
+	for rows < len(ts.Lines) {
+		if ts.Cur.Row > 0 && ts.Cur.Row >= rows {
+			ts.pushScrollback(ts.Lines[0])
+			ts.Lines = ts.Lines[1:]
+			ts.Cur.Row--
+		} else {
+			ts.Lines = ts.Lines[:len(ts.Lines)-1]
+		}
+	}
+	for rows > len(ts.Lines) {
+		ts.Lines = append(ts.Lines, ts.blankLine())
+	}
+	if ts.mainLines != nil {
+		for rows < len(ts.mainLines) {
+			ts.mainLines = ts.mainLines[:len(ts.mainLines)-1]
+		}
+		for rows > len(ts.mainLines) {
+			ts.mainLines = append(ts.mainLines, ts.blankLine())
+		}
+	}
+	ts.Rows = rows
+	ts.top, ts.bot = 0, rows-1
+	ts.Cur.Row = ints.MinInt(ts.Cur.Row, rows-1)
+	ts.Cur.Col = ints.MinInt(ts.Cur.Col, cols-1)
+	ts.wrapPending = false
+	ts.resetTabs()
+}
+
+// pushScrollback adds given line to the scrollback, unless the alternate
+// screen is active
+func (ts *TermScreen) pushScrollback(ln []TermCell) {
+	if ts.AltScreen || ts.MaxScrollback <= 0 {
+		return
+	}
+	ts.Scrollback = append(ts.Scrollback, ln)
+	if over := len(ts.Scrollback) - ts.MaxScrollback; over > 0 {
+		copy(ts.Scrollback, ts.Scrollback[over:])
+		ts.Scrollback = ts.Scrollback[:ts.MaxScrollback]
+	}
+}
+
+// ClearScrollback removes all the scrollback lines
+func (ts *TermScreen) ClearScrollback() {
+	ts.Scrollback = nil
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//  Parsing
+
+// Write interprets the output of a program -- it always consumes all the
+// bytes, which can end in the middle of an escape sequence or UTF-8
+// character
+func (ts *TermScreen) Write(b []byte) (int, error) {
+	for _, c := range b {
+		ts.parseByte(c)
+	}
+	return len(b), nil
+}
+
+// parseByte advances the parser state with given byte
+func (ts *TermScreen) parseByte(c byte) {
+	switch ts.state {
+	case termOSC, termOSCEsc, termStr, termStrEsc:
+		ts.parseString(c)
+		return
+	}
+	if c == 0x1b {
+		ts.utf = ts.utf[:0]
+		ts.state = termEsc
+		ts.inter = ts.inter[:0]
+		return
+	}
+	if c == 0x18 || c == 0x1a { // CAN, SUB abort sequences
+		ts.state = termGround
+		return
+	}
+	if c < 0x20 || c == 0x7f {
+		ts.execute(c)
+		return
+	}
+	switch ts.state {
+	case termGround:
+		ts.parseText(c)
+	case termEsc:
+		ts.parseEsc(c)
+	case termEscInter:
+		ts.escInter(c)
+	case termCSI:
+		ts.parseCSI(c)
+	}
+}
+
+// parseText decodes the UTF-8 text in the ground state
+func (ts *TermScreen) parseText(c byte) {
+	if c < utf8.RuneSelf && len(ts.utf) == 0 {
+		ts.put(rune(c))
+		return
+	}
+	if c >= 0xc0 || c < utf8.RuneSelf { // start of a new character
+		if len(ts.utf) > 0 {
+			ts.put(utf8.RuneError)
+			ts.utf = ts.utf[:0]
+		}
+		if c < utf8.RuneSelf {
+			ts.put(rune(c))
+			return
+		}
+	}
+	ts.utf = append(ts.utf, c)
+	if utf8.FullRune(ts.utf) {
+		r, _ := utf8.DecodeRune(ts.utf)
+		ts.utf = ts.utf[:0]
+		ts.put(r)
+	}
+}
+
+// parseString handles the text of OSC and other string sequences, which
+// end with BEL or ST (ESC \)
+func (ts *TermScreen) parseString(c byte) {
+	osc := ts.state == termOSC || ts.state == termOSCEsc
+	switch {
+	case c == 0x07 || ((ts.state == termOSCEsc || ts.state == termStrEsc) && c == '\\'):
+		if osc {
+			ts.oscDispatch()
+		}
+		ts.state = termGround
+	case c == 0x1b:
+		if osc {
+			ts.state = termOSCEsc
+		} else {
+			ts.state = termStrEsc
+		}
+	case c == 0x18 || c == 0x1a:
+		ts.state = termGround
+	default:
+		if ts.state == termOSCEsc || ts.state == termStrEsc { // not ST: abandon string
+			ts.state = termGround
+			ts.parseByte(0x1b)
+			ts.parseByte(c)
+			return
+		}
+		if osc && len(ts.osc) < 4096 {
+			ts.osc = append(ts.osc, c)
+		}
+	}
+}
+
+// oscDispatch handles an OSC sequence -- only setting the title is supported
+func (ts *TermScreen) oscDispatch() {
+	str := string(ts.osc)
+	ts.osc = ts.osc[:0]
+	si := strings.IndexByte(str, ';')
+	if si < 0 {
+		return
+	}
+	switch str[:si] {
+	case "0", "2":
+		ts.Title = str[si+1:]
+	}
+}
+
+// reply sends given reply to the program, if there is a Reply function
+func (ts *TermScreen) reply(str string) {
+	if ts.Reply != nil {
+		ts.Reply([]byte(str))
+	}
+}
+
+// execute executes given C0 control character
+func (ts *TermScreen) execute(c byte) {
+	switch c {
+	case 0x07:
+		ts.Bells++
+	case 0x08:
+		if ts.Cur.Col > 0 {
+			ts.Cur.Col--
+		}
+		ts.wrapPending = false
+	case 0x09:
+		ts.tab(1)
+	case 0x0a, 0x0b, 0x0c:
+		ts.lineFeed()
+	case 0x0d:
+		ts.Cur.Col = 0
+		ts.wrapPending = false
+	case 0x0e:
+		ts.shift = true
+	case 0x0f:
+		ts.shift = false
+	}
+}
+
+// termLineDrawing maps the characters of the DEC special graphics
+// (line drawing) character set
+var termLineDrawing = map[rune]rune{
+	'`': '◆', 'a': '▒', 'f': '°', 'g': '±', 'j': '┘', 'k': '┐', 'l': '┌',
+	'm': '└', 'n': '┼', 'o': '⎺', 'p': '⎻', 'q': '─', 'r': '⎼', 's': '⎽',
+	't': '├', 'u': '┤', 'v': '┴', 'w': '┬', 'x': '│', 'y': '≤', 'z': '≥',
+	'{': 'π', '|': '≠', '}': '£', '~': '·',
+}
+
+// put puts given character at the cursor, and advances it
+func (ts *TermScreen) put(r rune) {
+	if (ts.shift && ts.g1) || (!ts.shift && ts.g0) {
+		if lr, ok := termLineDrawing[r]; ok {
+			r = lr
+		}
+	}
+	if ts.wrapPending {
+		ts.wrapPending = false
+		if ts.AutoWrap {
+			ts.Cur.Col = 0
+			ts.lineFeed()
+		}
+	}
+	ln := ts.Lines[ts.Cur.Row]
+	if ts.Insert {
+		copy(ln[ts.Cur.Col+1:], ln[ts.Cur.Col:])
+	}
+	cell := ts.Attr
+	cell.Rune = r
+	ln[ts.Cur.Col] = cell
+	ts.lastRune = r
+	if ts.Cur.Col == ts.Cols-1 {
+		ts.wrapPending = true
+	} else {
+		ts.Cur.Col++
+	}
+}
+
+// tab moves the cursor to the n-th next tab stop (previous if n < 0)
+func (ts *TermScreen) tab(n int) {
+	for ; n > 0; n-- {
+		ts.Cur.Col++
+		for ts.Cur.Col < ts.Cols-1 && !ts.tabs[ts.Cur.Col] {
+			ts.Cur.Col++
+		}
+	}
+	for ; n < 0; n++ {
+		if ts.Cur.Col > 0 {
+			ts.Cur.Col--
+		}
+		for ts.Cur.Col > 0 && !ts.tabs[ts.Cur.Col] {
+			ts.Cur.Col--
+		}
+	}
+	ts.Cur.Col = ints.MinInt(ts.Cur.Col, ts.Cols-1)
+	ts.wrapPending = false
+}
+
+// lineFeed moves the cursor down, scrolling at the bottom of the scroll region
+func (ts *TermScreen) lineFeed() {
+	ts.wrapPending = false
+	switch {
+	case ts.Cur.Row == ts.bot:
+		ts.scrollUp(1)
+	case ts.Cur.Row < ts.Rows-1:
+		ts.Cur.Row++
+	}
+}
+
+// reverseIndex moves the cursor up, scrolling at the top of the scroll region
+func (ts *TermScreen) reverseIndex() {
+	ts.wrapPending = false
+	switch {
+	case ts.Cur.Row == ts.top:
+		ts.scrollDown(1)
+	case ts.Cur.Row > 0:
+		ts.Cur.Row--
+	}
+}
+
+// scrollUp scrolls the scroll region up by n lines -- lines scrolled off
+// the top of the screen go to the scrollback
+func (ts *TermScreen) scrollUp(n int) {
+	n = ints.MinInt(n, ts.bot-ts.top+1)
+	for i := 0; i < n; i++ {
+		if ts.top == 0 {
+			ts.pushScrollback(ts.Lines[ts.top])
+		}
+		copy(ts.Lines[ts.top:ts.bot], ts.Lines[ts.top+1:ts.bot+1])
+		ts.Lines[ts.bot] = ts.blankLine()
+	}
+}
+
+// scrollDown scrolls the scroll region down by n lines
+func (ts *TermScreen) scrollDown(n int) {
+	n = ints.MinInt(n, ts.bot-ts.top+1)
+	for i := 0; i < n; i++ {
+		copy(ts.Lines[ts.top+1:ts.bot+1], ts.Lines[ts.top:ts.bot])
+		ts.Lines[ts.top] = ts.blankLine()
+	}
+}
+
+// parseEsc handles the character after ESC
+func (ts *TermScreen) parseEsc(c byte) {
+	ts.state = termGround
+	switch c {
+	case '[':
+		ts.state = termCSI
+		ts.params = ts.params[:0]
+		ts.priv = 0
+		ts.inter = ts.inter[:0]
+	case ']':
+		ts.state = termOSC
+		ts.osc = ts.osc[:0]
+	case 'P', 'X', '^', '_':
+		ts.state = termStr
+	case '(', ')', '*', '+', '#', '%', ' ':
+		ts.inter = append(ts.inter[:0], c)
+		ts.state = termEscInter
+	case '7':
+		ts.saveCursor()
+	case '8':
+		ts.restoreCursor()
+	case 'D':
+		ts.lineFeed()
+	case 'E':
+		ts.Cur.Col = 0
+		ts.lineFeed()
+	case 'H':
+		ts.tabs[ts.Cur.Col] = true
+	case 'M':
+		ts.reverseIndex()
+	case 'c':
+		ts.Reset()
+	case '=':
+		ts.AppKeypad = true
+	case '>':
+		ts.AppKeypad = false
+	}
+}
+
+// escInter handles the final character of an ESC sequence with an
+// intermediate character, e.g., character set designation
+func (ts *TermScreen) escInter(c byte) {
+	ts.state = termGround
+	if len(ts.inter) == 0 {
+		return
+	}
+	switch ts.inter[0] {
+	case '(':
+		ts.g0 = c == '0'
+	case ')':
+		ts.g1 = c == '0'
+	case '#':
+		if c == '8' { // DECALN: fill with E
+			for _, ln := range ts.Lines {
+				for i := range ln {
+					ln[i] = TermCell{Rune: 'E', Fg: TermColorDefault, Bg: TermColorDefault}
+				}
+			}
+		}
+	}
+}
+
+// parseCSI handles the characters of a CSI sequence
+func (ts *TermScreen) parseCSI(c byte) {
+	switch {
+	case c >= '0' && c <= '9':
+		if len(ts.params) == 0 {
+			ts.params = append(ts.params, 0)
+		}
+		pi := len(ts.params) - 1
+		if ts.params[pi] < 0 {
+			ts.params[pi] = 0
+		}
+		if ts.params[pi] < 100000 {
+			ts.params[pi] = ts.params[pi]*10 + int(c-'0')
+		}
+	case c == ';' || c == ':':
+		if len(ts.params) == 0 {
+			ts.params = append(ts.params, -1)
+		}
+		ts.params = append(ts.params, -1)
+	case c >= '<' && c <= '?':
+		ts.priv = c
+	case c >= 0x20 && c <= 0x2f:
+		ts.inter = append(ts.inter, c)
+	case c >= 0x40 && c <= 0x7e:
+		ts.state = termGround
+		ts.csiDispatch(c)
+	default:
+		ts.state = termGround
+	}
+}
+
+// param returns the i-th parameter of the CSI sequence, or given default
+// if it is missing or 0
+func (ts *TermScreen) param(i, def int) int {
+	if i >= len(ts.params) || ts.params[i] <= 0 {
+		return def
+	}
+	return ts.params[i]
+}
+
+// moveTo moves the cursor to given screen position, clipped to the screen
+func (ts *TermScreen) moveTo(row, col int) {
+	ts.Cur.Row = ints.MaxInt(0, ints.MinInt(row, ts.Rows-1))
+	ts.Cur.Col = ints.MaxInt(0, ints.MinInt(col, ts.Cols-1))
+	ts.wrapPending = false
+}
+
+// eraseCells erases the cells of the line from st up to ed
+func (ts *TermScreen) eraseCells(ln []TermCell, st, ed int) {
+	bc := ts.blankCell()
+	for i := ints.MaxInt(st, 0); i < ints.MinInt(ed, len(ln)); i++ {
+		ln[i] = bc
+	}
+}
+
+// csiDispatch executes a complete CSI sequence with given final character
+func (ts *TermScreen) csiDispatch(c byte) {
+	if len(ts.inter) > 0 { // e.g., cursor style (SP q) -- not supported
+		return
+	}
+	cur := &ts.Cur
+	ln := ts.Lines[cur.Row]
+	switch c {
+	case '@':
+		n := ints.MinInt(ts.param(0, 1), ts.Cols-cur.Col)
+		copy(ln[cur.Col+n:], ln[cur.Col:])
+		ts.eraseCells(ln, cur.Col, cur.Col+n)
+	case 'A':
+		top := 0
+		if cur.Row >= ts.top {
+			top = ts.top
+		}
+		ts.moveTo(ints.MaxInt(cur.Row-ts.param(0, 1), top), cur.Col)
+	case 'B', 'e':
+		bot := ts.Rows - 1
+		if cur.Row <= ts.bot {
+			bot = ts.bot
+		}
+		ts.moveTo(ints.MinInt(cur.Row+ts.param(0, 1), bot), cur.Col)
+	case 'C', 'a':
+		ts.moveTo(cur.Row, cur.Col+ts.param(0, 1))
+	case 'D':
+		ts.moveTo(cur.Row, cur.Col-ts.param(0, 1))
+	case 'E':
+		ts.moveTo(cur.Row+ts.param(0, 1), 0)
+	case 'F':
+		ts.moveTo(cur.Row-ts.param(0, 1), 0)
+	case 'G', '`':
+		ts.moveTo(cur.Row, ts.param(0, 1)-1)
+	case 'H', 'f':
+		row := ts.param(0, 1) - 1
+		if ts.Origin {
+			row = ints.MinInt(row+ts.top, ts.bot)
+		}
+		ts.moveTo(row, ts.param(1, 1)-1)
+	case 'I':
+		ts.tab(ts.param(0, 1))
+	case 'Z':
+		ts.tab(-ts.param(0, 1))
+	case 'J':
+		switch ts.param(0, 0) {
+		case 0:
+			ts.eraseCells(ln, cur.Col, ts.Cols)
+			for r := cur.Row + 1; r < ts.Rows; r++ {
+				ts.Lines[r] = ts.blankLine()
+			}
+		case 1:
+			ts.eraseCells(ln, 0, cur.Col+1)
+			for r := 0; r < cur.Row; r++ {
+				ts.Lines[r] = ts.blankLine()
+			}
+		case 2:
+			for r := range ts.Lines {
+				ts.Lines[r] = ts.blankLine()
+			}
+		case 3:
+			ts.ClearScrollback()
+		}
+	case 'K':
+		switch ts.param(0, 0) {
+		case 0:
+			ts.eraseCells(ln, cur.Col, ts.Cols)
+		case 1:
+			ts.eraseCells(ln, 0, cur.Col+1)
+		case 2:
+			ts.eraseCells(ln, 0, ts.Cols)
+		}
+	case 'L', 'M':
+		if cur.Row < ts.top || cur.Row > ts.bot {
+			return
+		}
+		top := ts.top
+		ts.top = cur.Row
+		if c == 'L' {
+			ts.scrollDown(ts.param(0, 1))
+		} else {
+			nsb := len(ts.Scrollback)
+			ts.scrollUp(ts.param(0, 1))
+			if ts.top == 0 { // deleted lines do not go to scrollback
+				ts.Scrollback = ts.Scrollback[:ints.MinInt(nsb, len(ts.Scrollback))]
+			}
+		}
+		ts.top = top
+		cur.Col = 0
+		ts.wrapPending = false
+	case 'P':
+		n := ints.MinInt(ts.param(0, 1), ts.Cols-cur.Col)
+		copy(ln[cur.Col:], ln[cur.Col+n:])
+		ts.eraseCells(ln, ts.Cols-n, ts.Cols)
+	case 'S':
+		if ts.priv == 0 {
+			ts.scrollUp(ts.param(0, 1))
+		}
+	case 'T':
+		if ts.priv == 0 && len(ts.params) <= 1 {
+			ts.scrollDown(ts.param(0, 1))
+		}
+	case 'X':
+		ts.eraseCells(ln, cur.Col, cur.Col+ts.param(0, 1))
+	case 'b':
+		if ts.lastRune != 0 {
+			for n := ts.param(0, 1); n > 0; n-- {
+				ts.put(ts.lastRune)
+			}
+		}
+	case 'c':
+		switch ts.priv {
+		case 0:
+			ts.reply("\x1b[?62;22c")
+		case '>':
+			ts.reply("\x1b[>0;10;0c")
+		}
+	case 'd':
+		row := ts.param(0, 1) - 1
+		if ts.Origin {
+			row += ts.top
+		}
+		ts.moveTo(row, cur.Col)
+	case 'g':
+		switch ts.param(0, 0) {
+		case 0:
+			ts.tabs[cur.Col] = false
+		case 3:
+			for i := range ts.tabs {
+				ts.tabs[i] = false
+			}
+		}
+	case 'h', 'l':
+		ts.setModes(c == 'h')
+	case 'm':
+		if ts.priv == 0 {
+			ts.sgr()
+		}
+	case 'n':
+		switch ts.param(0, 0) {
+		case 5:
+			ts.reply("\x1b[0n")
+		case 6:
+			row := cur.Row
+			if ts.Origin {
+				row -= ts.top
+			}
+			ts.reply(fmt.Sprintf("\x1b[%d;%dR", row+1, cur.Col+1))
+		}
+	case 'r':
+		if ts.priv != 0 {
+			return
+		}
+		top, bot := ts.param(0, 1)-1, ts.param(1, ts.Rows)-1
+		bot = ints.MinInt(bot, ts.Rows-1)
+		if top < bot {
+			ts.top, ts.bot = top, bot
+			if ts.Origin {
+				ts.moveTo(top, 0)
+			} else {
+				ts.moveTo(0, 0)
+			}
+		}
+	case 's':
+		if ts.priv == 0 {
+			ts.saveCursor()
+		}
+	case 'u':
+		if ts.priv == 0 {
+			ts.restoreCursor()
+		}
+	}
+}
+
+// setModes sets or resets the modes of the CSI h or l sequence
+func (ts *TermScreen) setModes(on bool) {
+	for i := range ts.params {
+		md := ts.param(i, 0)
+		if ts.priv != '?' {
+			if md == 4 {
+				ts.Insert = on
+			}
+			continue
+		}
+		switch md {
+		case 1:
+			ts.AppCursor = on
+		case 6:
+			ts.Origin = on
+			if on {
+				ts.moveTo(ts.top, 0)
+			} else {
+				ts.moveTo(0, 0)
+			}
+		case 7:
+			ts.AutoWrap = on
+		case 25:
+			ts.CursorVisible = on
+		case 47, 1047:
+			ts.setAltScreen(on, false)
+		case 1048:
+			if on {
+				ts.saveCursor()
+			} else {
+				ts.restoreCursor()
+			}
+		case 1049:
+			ts.setAltScreen(on, true)
+		case 2004:
+			ts.BracketPaste = on
+		}
+	}
+}
+
+// setAltScreen switches to or from the alternate screen, optionally saving
+// and restoring the cursor and clearing the alternate screen
+func (ts *TermScreen) setAltScreen(on, cursor bool) {
+	if on == ts.AltScreen {
+		return
+	}
+	if on {
+		if cursor {
+			ts.saveCursor()
+		}
+		ts.altSaved = ts.saved
+		ts.mainLines = ts.Lines
+		ts.Lines = make([][]TermCell, ts.Rows)
+		for i := range ts.Lines {
+			ts.Lines[i] = ts.blankLine()
+		}
+		ts.AltScreen = true
+		return
+	}
+	ts.AltScreen = false
+	if ts.mainLines != nil {
+		ts.Lines = ts.mainLines
+		ts.mainLines = nil
+	}
+	if cursor {
+		ts.saved = ts.altSaved
+		ts.restoreCursor()
+	}
+}
+
+// saveCursor saves the cursor position and attributes (DECSC)
+func (ts *TermScreen) saveCursor() {
+	ts.saved = termSaved{cur: ts.Cur, attr: ts.Attr, origin: ts.Origin, g0: ts.g0, g1: ts.g1}
+}
+
+// restoreCursor restores the saved cursor position and attributes (DECRC)
+func (ts *TermScreen) restoreCursor() {
+	ts.Attr = ts.saved.attr
+	ts.Origin = ts.saved.origin
+	ts.g0, ts.g1 = ts.saved.g0, ts.saved.g1
+	ts.moveTo(ts.saved.cur.Row, ts.saved.cur.Col)
+}
+
+// sgr sets the colors and attributes from the parameters of the SGR
+// (CSI m) sequence
+func (ts *TermScreen) sgr() {
+	at := &ts.Attr
+	if len(ts.params) == 0 {
+		ts.params = append(ts.params, 0)
+	}
+	np := len(ts.params)
+	set := func(attr TermAttrs, on bool) {
+		bitflag.SetState32(&at.Attrs, on, int(attr))
+	}
+	// extColor parses an extended 38 / 48 color at given param index,
+	// returning the color and the index of its last param
+	extColor := func(i int) (TermColor, int) {
+		if i+1 >= np {
+			return TermColorDefault, np
+		}
+		switch ts.param(i+1, 0) {
+		case 5:
+			if i+2 < np {
+				return TermColor(ints.MinInt(ts.param(i+2, 0), 255)), i + 2
+			}
+		case 2:
+			if i+4 < np {
+				return TermRGB(uint8(ts.param(i+2, 0)), uint8(ts.param(i+3, 0)), uint8(ts.param(i+4, 0))), i + 4
+			}
+		}
+		return TermColorDefault, np
+	}
+	for i := 0; i < np; i++ {
+		p := ts.param(i, 0)
+		switch {
+		case p == 0:
+			*at = TermCell{Fg: TermColorDefault, Bg: TermColorDefault}
+		case p == 1:
+			set(TermBold, true)
+		case p == 2:
+			set(TermDim, true)
+		case p == 3:
+			set(TermItalic, true)
+		case p == 4:
+			set(TermUnderline, true)
+		case p == 5 || p == 6:
+			set(TermBlink, true)
+		case p == 7:
+			set(TermInverse, true)
+		case p == 8:
+			set(TermHidden, true)
+		case p == 9:
+			set(TermStrike, true)
+		case p == 21 || p == 22:
+			set(TermBold, false)
+			set(TermDim, false)
+		case p == 23:
+			set(TermItalic, false)
+		case p == 24:
+			set(TermUnderline, false)
+		case p == 25:
+			set(TermBlink, false)
+		case p == 27:
+			set(TermInverse, false)
+		case p == 28:
+			set(TermHidden, false)
+		case p == 29:
+			set(TermStrike, false)
+		case p >= 30 && p <= 37:
+			at.Fg = TermColor(p - 30)
+		case p == 38:
+			at.Fg, i = extColor(i)
+		case p == 39:
+			at.Fg = TermColorDefault
+		case p >= 40 && p <= 47:
+			at.Bg = TermColor(p - 40)
+		case p == 48:
+			at.Bg, i = extColor(i)
+		case p == 49:
+			at.Bg = TermColorDefault
+		case p >= 90 && p <= 97:
+			at.Fg = TermColor(p - 90 + 8)
+		case p >= 100 && p <= 107:
+			at.Bg = TermColor(p - 100 + 8)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"github.com/goki/gi/oswin"
+	"github.com/goki/pi/lex"
+)
+
+// RuneOffset returns the offset in characters (runes) of given position
+// from the start of the text, counting one for the newline at the end of
+// each line
+func (tb *TextBuf) RuneOffset(pos lex.Pos) int {
+	tb.LinesMu.RLock()
+	defer tb.LinesMu.RUnlock()
+	if tb.NLines == 0 {
+		return 0
+	}
+	if pos.Ln >= tb.NLines {
+		pos = lex.Pos{Ln: tb.NLines - 1, Ch: tb.LineLenImpl(tb.NLines - 1)}
+	}
+	off := 0
+	for ln := 0; ln < pos.Ln; ln++ {
+		off += tb.LineLenImpl(ln) + 1
+	}
+	return off + pos.Ch
+}
+
+// RunePos returns the position of given offset in characters (runes) from
+// the start of the text (see RuneOffset) -- offsets beyond the end of the
+// text return the end position
+func (tb *TextBuf) RunePos(off int) lex.Pos {
+	tb.LinesMu.RLock()
+	defer tb.LinesMu.RUnlock()
+	return tb.RunePosImpl(off)
+}
+
+// RunePosImpl returns the position of given character offset (see RunePos)
+// -- must be called under LinesMu lock
+func (tb *TextBuf) RunePosImpl(off int) lex.Pos {
+	if tb.NLines == 0 || off <= 0 {
+		return lex.PosZero
+	}
+	for ln := 0; ln < tb.NLines; ln++ {
+		ll := tb.LineLenImpl(ln)
+		if off <= ll {
+			return lex.Pos{Ln: ln, Ch: off}
+		}
+		off -= ll + 1
+	}
+	return lex.Pos{Ln: tb.NLines - 1, Ch: tb.LineLenImpl(tb.NLines - 1)}
+}
+
+// AccessTextLen is the oswin.AccessText method returning the number of
+// characters in the text of the buffer
+func (tv *TextView) AccessTextLen() int {
+	if tv.Buf == nil {
+		return 0
+	}
+	return tv.Buf.RuneOffset(tv.Buf.EndPos())
+}
+
+// AccessTextRange is the oswin.AccessText method returning the text of the
+// buffer between given character offsets
+func (tv *TextView) AccessTextRange(st, ed int) string {
+	if tv.Buf == nil {
+		return ""
+	}
+	sp, ep := tv.Buf.RunePos(st), tv.Buf.RunePos(ed)
+	if !sp.IsLess(ep) {
+		return ""
+	}
+	tbe := tv.Buf.Region(sp, ep)
+	if tbe == nil {
+		return ""
+	}
+	return string(tbe.ToBytes())
+}
+
+// AccessTextLine is the oswin.AccessText method returning the start and
+// end character offsets of the line containing given offset
+func (tv *TextView) AccessTextLine(off int) (st, ed int) {
+	if tv.Buf == nil {
+		return 0, 0
+	}
+	tb := tv.Buf
+	tb.LinesMu.RLock()
+	defer tb.LinesMu.RUnlock()
+	for ln := 0; ln < tb.NLines; ln++ {
+		ll := tb.LineLenImpl(ln)
+		if off <= st+ll || ln == tb.NLines-1 { // offsets beyond the end are on the last line
+			ed = st + ll
+			if ln < tb.NLines-1 {
+				ed++ // lines include their newline
+			}
+			return st, ed
+		}
+		st += ll + 1
+	}
+	return 0, 0
+}
+
+// check that TextView serves its text on demand
+var _ oswin.AccessText = (*TextView)(nil)
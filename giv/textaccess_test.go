@@ -0,0 +1,61 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"testing"
+
+	"github.com/goki/pi/lex"
+)
+
+func TestTextViewAccessText(t *testing.T) {
+	for _, large := range []bool{false, true} {
+		tv := &TextView{}
+		tv.Buf = newTestBuf("héllo\n\nwörld", large)
+		if got := tv.AccessTextLen(); got != 12 {
+			t.Errorf("large %v: len: %d != expected: 12", large, got)
+		}
+		offs := []struct {
+			pos lex.Pos
+			off int
+		}{{lex.Pos{Ln: 0, Ch: 0}, 0}, {lex.Pos{Ln: 0, Ch: 5}, 5}, {lex.Pos{Ln: 1, Ch: 0}, 6}, {lex.Pos{Ln: 2, Ch: 1}, 8}, {lex.Pos{Ln: 2, Ch: 5}, 12}}
+		for _, o := range offs {
+			if got := tv.Buf.RuneOffset(o.pos); got != o.off {
+				t.Errorf("large %v: offset of %v: %d != expected: %d", large, o.pos, got, o.off)
+			}
+			if got := tv.Buf.RunePos(o.off); got != o.pos {
+				t.Errorf("large %v: pos of %d: %v != expected: %v", large, o.off, got, o.pos)
+			}
+		}
+		if got := tv.Buf.RunePos(20); got != (lex.Pos{Ln: 2, Ch: 5}) {
+			t.Errorf("large %v: pos past end: %v", large, got)
+		}
+		rngs := []struct {
+			st, ed int
+			txt    string
+		}{{0, 5, "héllo"}, {1, 9, "éllo\n\nwö"}, {8, 20, "örld"}, {4, 4, ""}, {5, 2, ""}}
+		for _, r := range rngs {
+			if got := tv.AccessTextRange(r.st, r.ed); got != r.txt {
+				t.Errorf("large %v: range %d-%d: %q != expected: %q", large, r.st, r.ed, got, r.txt)
+			}
+		}
+		lns := []struct {
+			off, st, ed int
+		}{{0, 0, 6}, {5, 0, 6}, {6, 6, 7}, {7, 7, 12}, {12, 7, 12}, {20, 7, 12}}
+		for _, l := range lns {
+			if st, ed := tv.AccessTextLine(l.off); st != l.st || ed != l.ed {
+				t.Errorf("large %v: line of %d: %d-%d != expected: %d-%d", large, l.off, st, ed, l.st, l.ed)
+			}
+		}
+		rev := tv.Buf.Revision()
+		tv.Buf.InsertText(lex.Pos{Ln: 1, Ch: 0}, []byte("x"), EditSignal)
+		if tv.Buf.Revision() == rev {
+			t.Errorf("large %v: revision not changed by edit", large)
+		}
+		if got := tv.AccessTextRange(6, 8); got != "x\n" {
+			t.Errorf("large %v: range after edit: %q", large, got)
+		}
+	}
+}
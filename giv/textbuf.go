@@ -13,10 +13,12 @@ import (
 	"path/filepath"
 	"regexp"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/gist"
+	"github.com/goki/gi/giv/lsp"
 	"github.com/goki/gi/giv/textbuf"
 	"github.com/goki/gi/histyle"
 	"github.com/goki/gi/units"
@@ -62,36 +64,49 @@ var TextBufMarkupDelayMSec = 1000
 // Windows/DOS CRLF format.
 type TextBuf struct {
 	ki.Node
-	Txt              []byte              `json:"-" xml:"text" desc:"the current value of the entire text being edited -- using []byte slice for greater efficiency"`
-	Autosave         bool                `desc:"if true, auto-save file after changes (in a separate routine)"`
-	Opts             textbuf.Opts        `desc:"options for how text editing / viewing works"`
-	Filename         gi.FileName         `json:"-" xml:"-" desc:"filename of file last loaded or saved"`
-	Info             FileInfo            `desc:"full info about file"`
-	PiState          pi.FileStates       `desc:"Pi parsing state info for file"`
-	Hi               HiMarkup            `desc:"syntax highlighting markup parameters (language, style, etc)"`
-	NLines           int                 `json:"-" xml:"-" desc:"number of lines"`
-	LineIcons        map[int]string      `desc:"icons for given lines -- use SetLineIcon and DeleteLineIcon"`
-	LineColors       map[int]gist.Color  `desc:"special line number colors given lines -- use SetLineColor and DeleteLineColor"`
-	Icons            map[string]*gi.Icon `json:"-" xml:"-" desc:"icons for each LineIcons being used"`
-	Lines            [][]rune            `json:"-" xml:"-" desc:"the live lines of text being edited, with latest modifications -- encoded as runes per line, which is necessary for one-to-one rune / glyph rendering correspondence -- all TextPos positions etc are in *rune* indexes, not byte indexes!"`
-	LineBytes        [][]byte            `json:"-" xml:"-" desc:"the live lines of text being edited, with latest modifications -- encoded in bytes per line translated from Lines, and used for input to markup -- essential to use Lines and not LineBytes when dealing with TextPos positions, which are in runes"`
-	Tags             []lex.Line          `json:"extra custom tagged regions for each line"`
-	HiTags           []lex.Line          `json:"syntax highlighting tags -- auto-generated"`
-	Markup           [][]byte            `json:"-" xml:"-" desc:"marked-up version of the edit text lines, after being run through the syntax highlighting process etc -- this is what is actually rendered"`
-	MarkupEdits      []*textbuf.Edit     `json:"-" xml:"-" desc:"edits that have been made since last full markup"`
-	ByteOffs         []int               `json:"-" xml:"-" desc:"offsets for start of each line in Txt []byte slice -- this is NOT updated with edits -- call SetByteOffs to set it when needed -- used for re-generating the Txt in LinesToBytes, and set on initial open in BytesToLines"`
-	TotalBytes       int                 `json:"-" xml:"-" desc:"total bytes in document -- see ByteOffs for when it is updated"`
-	LinesMu          sync.RWMutex        `json:"-" xml:"-" desc:"mutex for updating lines"`
-	MarkupMu         sync.RWMutex        `json:"-" xml:"-" desc:"mutex for updating markup"`
-	MarkupDelayTimer *time.Timer         `json:"-" xml:"-" desc:"markup delay timer"`
-	MarkupDelayMu    sync.Mutex          `json:"-" xml:"-" desc:"mutex for updating markup delay timer"`
-	TextBufSig       ki.Signal           `json:"-" xml:"-" view:"-" desc:"signal for buffer -- see TextBufSignals for the types"`
-	Views            []*TextView         `json:"-" xml:"-" desc:"the TextViews that are currently viewing this buffer"`
-	Undos            textbuf.Undo        `json:"-" xml:"-" desc:"undo manager"`
-	PosHistory       []lex.Pos           `json:"-" xml:"-" desc:"history of cursor positions -- can move back through them"`
-	Complete         *gi.Complete        `json:"-" xml:"-" desc:"functions and data for text completion"`
-	Spell            *gi.Spell           `json:"-" xml:"-" desc:"functions and data for spelling correction"`
-	CurView          *TextView           `json:"-" xml:"-" desc:"current textview -- e.g., the one that initiated Complete or Correct process -- update cursor position in this view -- is reset to nil after usage always"`
+	Txt              []byte                `json:"-" xml:"text" desc:"the current value of the entire text being edited -- using []byte slice for greater efficiency"`
+	Autosave         bool                  `desc:"if true, auto-save file after changes (in a separate routine)"`
+	NoRecovery       bool                  `desc:"if true, do not save a crash-recovery journal of unsaved changes for this buffer -- see RecoverySecs in Editor prefs"`
+	Opts             textbuf.Opts          `desc:"options for how text editing / viewing works"`
+	Filename         gi.FileName           `json:"-" xml:"-" desc:"filename of file last loaded or saved"`
+	Info             FileInfo              `desc:"full info about file"`
+	PiState          pi.FileStates         `desc:"Pi parsing state info for file"`
+	Hi               HiMarkup              `desc:"syntax highlighting markup parameters (language, style, etc)"`
+	NLines           int                   `json:"-" xml:"-" desc:"number of lines"`
+	Rev              int64                 `json:"-" xml:"-" desc:"revision number of the text, which is incremented by every change to it -- use Revision for concurrent-safe access"`
+	LineIcons        map[int]string        `desc:"icons for given lines -- use SetLineIcon and DeleteLineIcon"`
+	LineColors       map[int]gist.Color    `desc:"special line number colors given lines -- use SetLineColor and DeleteLineColor"`
+	Icons            map[string]*gi.Icon   `json:"-" xml:"-" desc:"icons for each LineIcons being used"`
+	Lines            [][]rune              `json:"-" xml:"-" desc:"the live lines of text being edited, with latest modifications -- encoded as runes per line, which is necessary for one-to-one rune / glyph rendering correspondence -- all TextPos positions etc are in *rune* indexes, not byte indexes!"`
+	LineBytes        [][]byte              `json:"-" xml:"-" desc:"the live lines of text being edited, with latest modifications -- encoded in bytes per line translated from Lines, and used for input to markup -- essential to use Lines and not LineBytes when dealing with TextPos positions, which are in runes"`
+	Tags             []lex.Line            `json:"extra custom tagged regions for each line"`
+	Folds            textbuf.Folds         `json:"-" xml:"-" desc:"regions of lines that can be folded (collapsed) in views -- updated after markup -- use FoldToggle etc to change"`
+	HiTags           []lex.Line            `json:"syntax highlighting tags -- auto-generated"`
+	Markup           [][]byte              `json:"-" xml:"-" desc:"marked-up version of the edit text lines, after being run through the syntax highlighting process etc -- this is what is actually rendered"`
+	MarkupEdits      []*textbuf.Edit       `json:"-" xml:"-" desc:"edits that have been made since last full markup"`
+	ByteOffs         []int                 `json:"-" xml:"-" desc:"offsets for start of each line in Txt []byte slice -- this is NOT updated with edits -- call SetByteOffs to set it when needed -- used for re-generating the Txt in LinesToBytes, and set on initial open in BytesToLines"`
+	TotalBytes       int                   `json:"-" xml:"-" desc:"total bytes in document -- see ByteOffs for when it is updated"`
+	LinesMu          sync.RWMutex          `json:"-" xml:"-" desc:"mutex for updating lines"`
+	MarkupMu         sync.RWMutex          `json:"-" xml:"-" desc:"mutex for updating markup"`
+	FoldsMu          sync.RWMutex          `json:"-" xml:"-" desc:"mutex for updating Folds -- no other mutex is locked while it is held"`
+	MarkupDelayTimer *time.Timer           `json:"-" xml:"-" desc:"markup delay timer"`
+	MarkupDelayMu    sync.Mutex            `json:"-" xml:"-" desc:"mutex for updating markup delay timer"`
+	RecoveryTimer    *time.Timer           `json:"-" xml:"-" desc:"timer for saving the crash-recovery journal"`
+	RecoveryMu       sync.Mutex            `json:"-" xml:"-" desc:"mutex for updating recovery timer"`
+	LSP              *lsp.Client           `json:"-" xml:"-" desc:"language server that this buffer is bound to, if any -- see LSPStart"`
+	LSPVersion       int                   `json:"-" xml:"-" desc:"version number of the text last sent to the language server"`
+	LSPTimer         *time.Timer           `json:"-" xml:"-" desc:"timer for sending changes to the language server"`
+	LSPMu            sync.Mutex            `json:"-" xml:"-" desc:"mutex for updating language server timer and version"`
+	Diagnostics      []lsp.Diagnostic      `json:"-" xml:"-" desc:"current diagnostics from the language server -- positions are as of when they were received"`
+	EditorConfig     *textbuf.EditorConfig `json:"-" xml:"-" desc:"settings from .editorconfig files that apply to this file, if any -- see ConfigEditorConfig"`
+	Pieces           *textbuf.PieceTable   `json:"-" xml:"-" desc:"piece table holding the text in large-file mode, in place of Lines and the other per-line data, which are nil -- nil otherwise -- see TextBufLargeFileSize"`
+	TextBufSig       ki.Signal             `json:"-" xml:"-" view:"-" desc:"signal for buffer -- see TextBufSignals for the types"`
+	Views            []*TextView           `json:"-" xml:"-" desc:"the TextViews that are currently viewing this buffer"`
+	Undos            textbuf.Undo          `json:"-" xml:"-" desc:"undo manager"`
+	PosHistory       []lex.Pos             `json:"-" xml:"-" desc:"history of cursor positions -- can move back through them"`
+	Complete         *gi.Complete          `json:"-" xml:"-" desc:"functions and data for text completion"`
+	Spell            *gi.Spell             `json:"-" xml:"-" desc:"functions and data for spelling correction"`
+	CurView          *TextView             `json:"-" xml:"-" desc:"current textview -- e.g., the one that initiated Complete or Correct process -- update cursor position in this view -- is reset to nil after usage always"`
 }
 
 var KiT_TextBuf = kit.Types.AddType(&TextBuf{}, TextBufProps)
@@ -101,6 +116,8 @@ func (tb *TextBuf) Disconnect() {
 	tb.TextBufSig.DisconnectAll()
 	tb.DeleteSpell()
 	tb.DeleteCompleter()
+	tb.LSPStop()
+	gi.TheFileWatcher.UnwatchAll(tb.This())
 }
 
 var TextBufProps = ki.Props{
@@ -110,6 +127,7 @@ var TextBufProps = ki.Props{
 			"Args": ki.PropSlice{
 				{"File Name", ki.Props{
 					"default-field": "Filename",
+					"save":          true,
 				}},
 			},
 		}},
@@ -185,6 +203,15 @@ func (tb *TextBuf) IsChanged() bool {
 // SetChanged marks buffer as changed
 func (tb *TextBuf) SetChanged() {
 	tb.SetFlag(int(TextBufChanged))
+	atomic.AddInt64(&tb.Rev, 1)
+	tb.RecoveryStart()
+	tb.LSPChanged()
+}
+
+// Revision returns the revision number of the text, which is incremented
+// by every change to it, so changes can be detected without comparing the text
+func (tb *TextBuf) Revision() int64 {
+	return atomic.LoadInt64(&tb.Rev)
 }
 
 // ClearChanged marks buffer as un-changed
@@ -274,7 +301,7 @@ func (tb *TextBuf) Line(ln int) []rune {
 	if ln >= tb.NLines || ln < 0 {
 		return nil
 	}
-	return tb.Lines[ln]
+	return tb.LineImpl(ln)
 }
 
 // LineLen is the concurrent-safe accessor to length of specific Line of Lines runes
@@ -284,7 +311,7 @@ func (tb *TextBuf) LineLen(ln int) int {
 	if ln >= tb.NLines || ln < 0 {
 		return 0
 	}
-	return len(tb.Lines[ln])
+	return tb.LineLenImpl(ln)
 }
 
 // BytesLine is the concurrent-safe accessor to specific Line of LineBytes
@@ -294,7 +321,7 @@ func (tb *TextBuf) BytesLine(ln int) []byte {
 	if ln >= tb.NLines || ln < 0 {
 		return nil
 	}
-	return tb.LineBytes[ln]
+	return tb.BytesLineImpl(ln)
 }
 
 // SetHiStyle sets the highlighting style -- needs to be protected by mutex
@@ -331,6 +358,7 @@ func (tb *TextBuf) SetInactive(inactive bool) {
 // New initializes a new buffer with n blank lines
 func (tb *TextBuf) New(nlines int) {
 	tb.Defaults()
+	atomic.AddInt64(&tb.Rev, 1)
 	nlines = ints.MaxInt(nlines, 1)
 	tb.LinesMu.Lock()
 	tb.MarkupMu.Lock()
@@ -340,6 +368,10 @@ func (tb *TextBuf) New(nlines int) {
 	tb.Tags = make([]lex.Line, nlines)
 	tb.HiTags = make([]lex.Line, nlines)
 	tb.Markup = make([][]byte, nlines)
+	tb.FoldsMu.Lock()
+	tb.Folds = nil
+	tb.FoldsMu.Unlock()
+	tb.Pieces = nil
 
 	if cap(tb.ByteOffs) >= nlines {
 		tb.ByteOffs = tb.ByteOffs[:nlines]
@@ -372,6 +404,7 @@ func (tb *TextBuf) Stat() error {
 		return err
 	}
 	tb.ConfigSupported()
+	tb.ConfigEditorConfig()
 	return nil
 }
 
@@ -390,6 +423,25 @@ func (tb *TextBuf) ConfigSupported() bool {
 	return false
 }
 
+// ConfigEditorConfig applies the indentation settings from any
+// .editorconfig files for the file (see textbuf.ReadEditorConfig), if the
+// EditorConfig option is on, overriding the prefs and language defaults
+func (tb *TextBuf) ConfigEditorConfig() {
+	tb.EditorConfig = nil
+	if !tb.Opts.EditorConfig || tb.Filename == "" {
+		return
+	}
+	ec, err := textbuf.ReadEditorConfig(string(tb.Filename))
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if ec != nil {
+		ec.SetOpts(&tb.Opts)
+		tb.EditorConfig = ec
+	}
+}
+
 // FileModCheck checks if the underlying file has been modified since last
 // Stat (open, save) -- if haven't yet prompted, user is prompted to ensure
 // that this is OK.  returns true if file was modified
@@ -421,6 +473,50 @@ func (tb *TextBuf) FileModCheck() bool {
 	return false
 }
 
+// WatchFile watches the current file of the buffer with gi.TheFileWatcher,
+// so that it is reverted when it is changed on disk, e.g., by another
+// editor, or the user is asked what to do if the buffer has unsaved changes
+// -- see FileChanged.  It is called on Open and Save, and the file is no
+// longer watched when the buffer is closed.
+func (tb *TextBuf) WatchFile() {
+	fname := string(tb.Filename)
+	if fname == "" || gi.TheFileWatcher.IsWatching(fname, tb.This()) {
+		return
+	}
+	gi.TheFileWatcher.UnwatchAll(tb.This()) // any previous file
+	gi.TheFileWatcher.Watch(fname, false, tb.This(), tb.FileChanged)
+}
+
+// FileChanged handles changes of the file on disk, from gi.TheFileWatcher:
+// if its modification time differs from that of the last open or save, the
+// buffer is reverted from the file if it has no unsaved changes, and
+// otherwise the user is asked what to do with FileModCheck
+func (tb *TextBuf) FileChanged(evs []gi.FileWatchEvent) {
+	if tb.This() == nil || tb.Filename == "" {
+		return
+	}
+	info, err := os.Stat(string(tb.Filename))
+	if err != nil || info.ModTime() == time.Time(tb.Info.ModTime) { // removed, or our own save
+		return
+	}
+	if !tb.IsChanged() {
+		tb.Revert()
+		return
+	}
+	if tb.ViewportFromView() != nil {
+		tb.FileModCheck()
+	}
+}
+
+// ParentWindow returns the window of the first view of the buffer, in which
+// the changes of its file are handled -- nil if not being viewed
+func (tb *TextBuf) ParentWindow() *gi.Window {
+	if len(tb.Views) > 0 && tb.Views[0].This() != nil {
+		return tb.Views[0].ParentWindow()
+	}
+	return nil
+}
+
 // Open loads text from a file into the buffer
 func (tb *TextBuf) Open(filename gi.FileName) error {
 	tb.Defaults()
@@ -432,10 +528,12 @@ func (tb *TextBuf) Open(filename gi.FileName) error {
 		return err
 	}
 	tb.SetName(string(filename))
+	tb.WatchFile()
 
 	tb.InitialMarkup()
 	tb.Refresh()
 	tb.ReMarkup()
+	tb.RecoveryPrompt()
 	return nil
 }
 
@@ -534,7 +632,12 @@ func (tb *TextBuf) SaveAs(filename gi.FileName) {
 
 // SaveFile writes current buffer to file, with no prompting, etc
 func (tb *TextBuf) SaveFile(filename gi.FileName) error {
-	err := ioutil.WriteFile(string(filename), tb.Txt, 0644)
+	var err error
+	if tb.Pieces != nil {
+		err = tb.SavePieces(string(filename))
+	} else {
+		err = ioutil.WriteFile(string(filename), tb.Txt, 0644)
+	}
 	if err != nil {
 		gi.PromptDialog(nil, gi.DlgOpts{Title: "Could not Save to File", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
 		log.Println(err)
@@ -542,6 +645,8 @@ func (tb *TextBuf) SaveFile(filename gi.FileName) error {
 		tb.Filename = filename
 		tb.SetName(string(filename))
 		tb.Stat()
+		tb.WatchFile()
+		tb.LSPSaved()
 	}
 	return err
 }
@@ -617,6 +722,8 @@ func (tb *TextBuf) Close(afterFun func(canceled bool)) bool {
 		return false // awaiting decisions..
 	}
 	tb.TextBufSig.Emit(tb.This(), int64(TextBufClosed), nil)
+	tb.LSPStop()
+	gi.TheFileWatcher.UnwatchAll(tb.This())
 	// for _, tve := range tb.Views {
 	// 	tve.SetBuf(nil) // automatically disconnects signals, views
 	// }
@@ -672,10 +779,12 @@ func (tb *TextBuf) AutoSave() error {
 	return err
 }
 
-// AutoSaveDelete deletes any existing autosave file
+// AutoSaveDelete deletes any existing autosave file, and the
+// crash-recovery journal
 func (tb *TextBuf) AutoSaveDelete() {
 	asfn := tb.AutoSaveFilename()
 	os.Remove(asfn)
+	tb.RecoveryDelete()
 }
 
 // AutoSaveCheck checks if an autosave file exists -- logic for dealing with
@@ -699,7 +808,7 @@ func (tb *TextBuf) EndPos() lex.Pos {
 	if tb.NLines == 0 {
 		return lex.PosZero
 	}
-	ed := lex.Pos{tb.NLines - 1, len(tb.Lines[tb.NLines-1])}
+	ed := lex.Pos{tb.NLines - 1, tb.LineLenImpl(tb.NLines - 1)}
 	return ed
 }
 
@@ -754,7 +863,7 @@ func (tb *TextBuf) AppendTextMarkup(text []byte, markup []byte, signal bool) *te
 		log.Printf("TextBuf AppendTextMarkup: markup text less than appended text: is: %v, should be: %v\n", len(msplt), sz)
 		el = ints.MinInt(st+len(msplt)-1, el)
 	}
-	for ln := st; ln <= el; ln++ {
+	for ln := st; ln <= el && ln < len(tb.Markup); ln++ {
 		tb.Markup[ln] = msplt[ln-st]
 	}
 	if signal {
@@ -785,7 +894,9 @@ func (tb *TextBuf) AppendTextLineMarkup(text []byte, markup []byte, signal bool)
 		efft = tcpy
 	}
 	tbe := tb.InsertText(ed, efft, false)
-	tb.Markup[tbe.Reg.Start.Ln] = markup
+	if tbe.Reg.Start.Ln < len(tb.Markup) {
+		tb.Markup[tbe.Reg.Start.Ln] = markup
+	}
 	if signal {
 		tb.TextBufSig.Emit(tb.This(), int64(TextBufInsert), tbe)
 	}
@@ -879,6 +990,10 @@ func (tb *TextBuf) AddFileNode(fn *FileNode) {
 
 // SetByteOffs sets the byte offsets for each line into the raw text
 func (tb *TextBuf) SetByteOffs() {
+	if tb.Pieces != nil {
+		tb.TotalBytes = tb.Pieces.Len()
+		return
+	}
 	bo := 0
 	for ln, txt := range tb.LineBytes {
 		tb.ByteOffs[ln] = bo
@@ -898,6 +1013,10 @@ func (tb *TextBuf) LinesToBytes() {
 		}
 		return
 	}
+	if tb.Pieces != nil {
+		tb.Txt = tb.Pieces.Bytes()
+		return
+	}
 
 	txt := bytes.Join(tb.LineBytes, []byte("\n"))
 	txt = append(txt, '\n')
@@ -910,6 +1029,9 @@ func (tb *TextBuf) LinesToBytes() {
 func (tb *TextBuf) LinesToBytesCopy() []byte {
 	tb.LinesMu.RLock()
 	defer tb.LinesMu.RUnlock()
+	if tb.Pieces != nil {
+		return tb.Pieces.Bytes()
+	}
 
 	txt := bytes.Join(tb.LineBytes, []byte("\n"))
 	txt = append(txt, '\n')
@@ -919,10 +1041,15 @@ func (tb *TextBuf) LinesToBytesCopy() []byte {
 // BytesToLines converts current Txt bytes into lines, and initializes markup
 // with raw text
 func (tb *TextBuf) BytesToLines() {
+	atomic.AddInt64(&tb.Rev, 1)
 	if len(tb.Txt) == 0 {
 		tb.New(1)
 		return
 	}
+	if len(tb.Txt) >= TextBufLargeFileSize {
+		tb.NewLarge()
+		return
+	}
 	tb.LinesMu.Lock()
 	lns := bytes.Split(tb.Txt, []byte("\n"))
 	tb.NLines = len(lns)
@@ -952,8 +1079,8 @@ func (tb *TextBuf) Strings(addNewLn bool) []string {
 	tb.LinesMu.RLock()
 	defer tb.LinesMu.RUnlock()
 	str := make([]string, tb.NLines)
-	for i, l := range tb.Lines {
-		str[i] = string(l)
+	for i := range str {
+		str[i] = string(tb.LineImpl(i))
 		if addNewLn {
 			str[i] += "\n"
 		}
@@ -967,6 +1094,9 @@ func (tb *TextBuf) Strings(addNewLn bool) []string {
 func (tb *TextBuf) Search(find []byte, ignoreCase, lexItems bool) (int, []textbuf.Match) {
 	tb.LinesMu.RLock()
 	defer tb.LinesMu.RUnlock()
+	if tb.Pieces != nil { // no lexical items without highlighting
+		return tb.SearchLarge(find, ignoreCase)
+	}
 	if lexItems {
 		tb.MarkupMu.RLock()
 		defer tb.MarkupMu.RUnlock()
@@ -982,6 +1112,9 @@ func (tb *TextBuf) Search(find []byte, ignoreCase, lexItems bool) (int, []textbu
 func (tb *TextBuf) SearchRegexp(re *regexp.Regexp) (int, []textbuf.Match) {
 	tb.LinesMu.RLock()
 	defer tb.LinesMu.RUnlock()
+	if tb.Pieces != nil {
+		return tb.SearchRegexpLarge(re)
+	}
 	return textbuf.SearchByteLinesRegexp(tb.LineBytes, re)
 }
 
@@ -992,6 +1125,16 @@ func (tb *TextBuf) BraceMatch(r rune, st lex.Pos) (en lex.Pos, found bool) {
 	defer tb.LinesMu.RUnlock()
 	tb.MarkupMu.RLock()
 	defer tb.MarkupMu.RUnlock()
+	if tb.Pieces != nil { // only the lines in scope
+		lo := st.Ln - TextBufMaxScopeLines
+		lns, tags := tb.LinesRange(lo, st.Ln+TextBufMaxScopeLines+1)
+		lo = ints.MaxInt(lo, 0)
+		en, found = lex.BraceMatch(lns, tags, r, lex.Pos{Ln: st.Ln - lo, Ch: st.Ch}, TextBufMaxScopeLines)
+		if found {
+			en.Ln += lo
+		}
+		return
+	}
 	return lex.BraceMatch(tb.Lines, tb.HiTags, r, st, TextBufMaxScopeLines)
 }
 
@@ -1009,13 +1152,12 @@ func (tb *TextBuf) ValidPos(pos lex.Pos) lex.Pos {
 	if pos.Ln < 0 {
 		pos.Ln = 0
 	}
-	if pos.Ln >= len(tb.Lines) {
-		pos.Ln = len(tb.Lines) - 1
-		pos.Ch = len(tb.Lines[pos.Ln])
+	if pos.Ln >= tb.NLines {
+		pos.Ln = tb.NLines - 1
+		pos.Ch = tb.LineLenImpl(pos.Ln)
 		return pos
 	}
-	pos.Ln = ints.MinInt(pos.Ln, len(tb.Lines)-1)
-	llen := len(tb.Lines[pos.Ln])
+	llen := tb.LineLenImpl(pos.Ln)
 	pos.Ch = ints.MinInt(pos.Ch, llen)
 	if pos.Ch < 0 {
 		pos.Ch = 0
@@ -1078,6 +1220,10 @@ func (tb *TextBuf) DeleteTextImpl(st, ed lex.Pos) *textbuf.Edit {
 		return nil
 	}
 	tbe.Delete = true
+	if tb.Pieces != nil {
+		tb.DeleteTextLarge(st, ed)
+		return tbe
+	}
 	if ed.Ln == st.Ln {
 		tb.Lines[st.Ln] = append(tb.Lines[st.Ln][:st.Ch], tb.Lines[st.Ln][ed.Ch:]...)
 		tb.LinesEdited(tbe)
@@ -1142,8 +1288,12 @@ func (tb *TextBuf) DeleteTextRectImpl(st, ed lex.Pos) *textbuf.Edit {
 	}
 	tbe.Delete = true
 	for ln := st.Ln; ln <= ed.Ln; ln++ {
-		ls := tb.Lines[ln]
+		ls := tb.LineImpl(ln)
 		if len(ls) > st.Ch {
+			if tb.Pieces != nil {
+				tb.SetLineLarge(ln, append(ls[:st.Ch], ls[ints.MinInt(ed.Ch, len(ls)):]...))
+				continue
+			}
 			tb.Lines[ln] = append(ls[:st.Ch], ls[ed.Ch:]...) // should be ok even if shorter?
 		}
 	}
@@ -1162,7 +1312,7 @@ func (tb *TextBuf) InsertText(st lex.Pos, text []byte, signal bool) *textbuf.Edi
 	st = tb.ValidPos(st)
 	tb.FileModCheck() // will just revert changes if shouldn't have changed
 	tb.SetChanged()
-	if len(tb.Lines) == 0 {
+	if tb.NLines == 0 {
 		tb.New(1)
 	}
 	tb.LinesMu.Lock()
@@ -1181,6 +1331,9 @@ func (tb *TextBuf) InsertText(st lex.Pos, text []byte, signal bool) *textbuf.Edi
 // InsertTextImpl does the raw insert of new text at given starting position, returning
 // a new Edit with timestamp of Now.  LinesMu must be locked surrounding this call.
 func (tb *TextBuf) InsertTextImpl(st lex.Pos, text []byte) *textbuf.Edit {
+	if tb.Pieces != nil {
+		return tb.InsertTextLarge(st, text)
+	}
 	lns := bytes.Split(text, []byte("\n"))
 	sz := len(lns)
 	rs := bytes.Runes(lns[0])
@@ -1271,14 +1424,18 @@ func (tb *TextBuf) InsertTextRectImpl(tbe *textbuf.Edit) *textbuf.Edit {
 		return nil
 	}
 	// make sure there are enough lines -- add as needed
-	cln := len(tb.Lines)
+	cln := tb.NLines
 	if cln == 0 {
 		tb.New(nlns)
 	} else if cln <= ed.Ln {
 		nln := (1 + ed.Ln) - cln
-		tmp := make([][]rune, nln)
-		tb.Lines = append(tb.Lines, tmp...) // first append to end to extend capacity
-		tb.NLines = len(tb.Lines)
+		if tb.Pieces != nil {
+			tb.AddLinesLarge(nln)
+		} else {
+			tmp := make([][]rune, nln)
+			tb.Lines = append(tb.Lines, tmp...) // first append to end to extend capacity
+			tb.NLines = len(tb.Lines)
+		}
 		ie := &textbuf.Edit{}
 		ie.Reg.Start.Ln = cln - 1
 		ie.Reg.End.Ln = ed.Ln
@@ -1287,7 +1444,7 @@ func (tb *TextBuf) InsertTextRectImpl(tbe *textbuf.Edit) *textbuf.Edit {
 	nch := (ed.Ch - st.Ch)
 	for i := 0; i < nlns; i++ {
 		ln := st.Ln + i
-		lr := tb.Lines[ln]
+		lr := tb.LineImpl(ln)
 		ir := tbe.Text[i]
 		if len(lr) < st.Ch {
 			lr = append(lr, runes.Repeat([]rune(" "), st.Ch-len(lr))...)
@@ -1295,7 +1452,11 @@ func (tb *TextBuf) InsertTextRectImpl(tbe *textbuf.Edit) *textbuf.Edit {
 		nt := append(lr, ir...)          // first append to end to extend capacity
 		copy(nt[st.Ch+nch:], nt[st.Ch:]) // move stuff to end
 		copy(nt[st.Ch:], ir)             // copy into position
-		tb.Lines[ln] = nt
+		if tb.Pieces != nil {
+			tb.SetLineLarge(ln, nt)
+		} else {
+			tb.Lines[ln] = nt
+		}
 	}
 	re := tbe.Clone()
 	re.Delete = false
@@ -1314,6 +1475,48 @@ func (tb *TextBuf) Region(st, ed lex.Pos) *textbuf.Edit {
 	return tb.RegionImpl(st, ed)
 }
 
+// RegionHTML returns the text between start and end positions as HTML,
+// with syntax highlighting if available -- see HiMarkup.MarkupLinesHTML
+func (tb *TextBuf) RegionHTML(st, ed lex.Pos) []byte {
+	st = tb.ValidPos(st)
+	ed = tb.ValidPos(ed)
+	tb.LinesMu.RLock()
+	defer tb.LinesMu.RUnlock()
+	if !st.IsLess(ed) {
+		return nil
+	}
+	nl := ed.Ln - st.Ln + 1
+	lines := make([][]rune, nl)
+	var tags []lex.Line
+	if tb.Hi.HasHi() && ed.Ln < len(tb.HiTags) {
+		tags = make([]lex.Line, nl)
+	}
+	for i := range lines {
+		ln := st.Ln + i
+		txt := tb.LineImpl(ln)
+		sc, ec := 0, len(txt)
+		if ln == st.Ln {
+			sc = ints.MinInt(st.Ch, ec)
+		}
+		if ln == ed.Ln {
+			ec = ints.MinInt(ed.Ch, ec)
+		}
+		lines[i] = txt[sc:ec]
+		if tags == nil {
+			continue
+		}
+		for _, lx := range tb.HiTags[ln] { // shift tags to the start of the text
+			if lx.Ed <= sc || lx.St >= ec {
+				continue
+			}
+			lx.St = ints.MaxInt(lx.St, sc) - sc
+			lx.Ed = ints.MinInt(lx.Ed, ec) - sc
+			tags[i] = append(tags[i], lx)
+		}
+	}
+	return tb.Hi.MarkupLinesHTML(lines, tags)
+}
+
 // RegionImpl returns a textbuf.Edit representation of text between
 // start and end positions. Returns nil if not a valid region.
 // Sets the timestamp on the textbuf.Edit to now.
@@ -1331,36 +1534,36 @@ func (tb *TextBuf) RegionImpl(st, ed lex.Pos) *textbuf.Edit {
 		sz := ed.Ch - st.Ch
 		tbe.Text = make([][]rune, 1)
 		tbe.Text[0] = make([]rune, sz)
-		copy(tbe.Text[0][:sz], tb.Lines[st.Ln][st.Ch:ed.Ch])
+		copy(tbe.Text[0][:sz], tb.LineImpl(st.Ln)[st.Ch:ed.Ch])
 	} else {
 		// first get chars on start and end
-		if ed.Ln >= len(tb.Lines) {
-			ed.Ln = len(tb.Lines) - 1
-			ed.Ch = len(tb.Lines[ed.Ln])
+		if ed.Ln >= tb.NLines {
+			ed.Ln = tb.NLines - 1
+			ed.Ch = tb.LineLenImpl(ed.Ln)
 		}
 		nlns := (ed.Ln - st.Ln) + 1
 		tbe.Text = make([][]rune, nlns)
 		stln := st.Ln
 		if st.Ch > 0 {
-			ec := len(tb.Lines[st.Ln])
-			sz := ec - st.Ch
+			stl := tb.LineImpl(st.Ln)
+			sz := len(stl) - st.Ch
 			if sz > 0 {
 				tbe.Text[0] = make([]rune, sz)
-				copy(tbe.Text[0][0:sz], tb.Lines[st.Ln][st.Ch:])
+				copy(tbe.Text[0][0:sz], stl[st.Ch:])
 			}
 			stln++
 		}
 		edln := ed.Ln
-		if ed.Ch < len(tb.Lines[ed.Ln]) {
+		if edl := tb.LineImpl(ed.Ln); ed.Ch < len(edl) {
 			tbe.Text[ed.Ln-st.Ln] = make([]rune, ed.Ch)
-			copy(tbe.Text[ed.Ln-st.Ln], tb.Lines[ed.Ln][:ed.Ch])
+			copy(tbe.Text[ed.Ln-st.Ln], edl[:ed.Ch])
 			edln--
 		}
 		for ln := stln; ln <= edln; ln++ {
 			ti := ln - st.Ln
-			sz := len(tb.Lines[ln])
-			tbe.Text[ti] = make([]rune, sz)
-			copy(tbe.Text[ti], tb.Lines[ln])
+			lr := tb.LineImpl(ln)
+			tbe.Text[ti] = make([]rune, len(lr))
+			copy(tbe.Text[ti], lr)
 		}
 	}
 	return tbe
@@ -1400,7 +1603,7 @@ func (tb *TextBuf) RegionRectImpl(st, ed lex.Pos) *textbuf.Edit {
 	tbe.Text = make([][]rune, nlns)
 	for i := 0; i < nlns; i++ {
 		ln := st.Ln + i
-		lr := tb.Lines[ln]
+		lr := tb.LineImpl(ln)
 		ll := len(lr)
 		var txt []rune
 		if ll > st.Ch {
@@ -1456,7 +1659,11 @@ func (tb *TextBuf) SavePosHistory(pos lex.Pos) bool {
 
 // LinesEdited re-marks-up lines in edit (typically only 1).  Locks and
 // unlocks the Markup mutex.  Must be called under Lines mutex lock.
+// Not used in large-file mode, which keeps no per-line data.
 func (tb *TextBuf) LinesEdited(tbe *textbuf.Edit) {
+	if tb.Pieces != nil {
+		return
+	}
 	tb.MarkupMu.Lock()
 	st, ed := tbe.Reg.Start.Ln, tbe.Reg.End.Ln
 	for ln := st; ln <= ed; ln++ {
@@ -1472,6 +1679,9 @@ func (tb *TextBuf) LinesEdited(tbe *textbuf.Edit) {
 // inserted in Lines text.  Locks and unlocks the Markup mutex, and
 // must be called under lines mutex
 func (tb *TextBuf) LinesInserted(tbe *textbuf.Edit) {
+	if tb.Pieces != nil {
+		return
+	}
 	stln := tbe.Reg.Start.Ln + 1
 	nsz := (tbe.Reg.End.Ln - tbe.Reg.Start.Ln)
 
@@ -1517,6 +1727,9 @@ func (tb *TextBuf) LinesInserted(tbe *textbuf.Edit) {
 		pfs := tb.PiState.Done()
 		pfs.Src.LinesInserted(stln, nsz)
 	}
+	tb.FoldsMu.Lock()
+	tb.Folds.LinesInserted(tbe.Reg.Start.Ln, nsz)
+	tb.FoldsMu.Unlock()
 
 	st, ed := tbe.Reg.Start.Ln, tbe.Reg.End.Ln
 	bo := tb.ByteOffs[st]
@@ -1535,6 +1748,9 @@ func (tb *TextBuf) LinesInserted(tbe *textbuf.Edit) {
 // deleted in Lines text.  Locks and unlocks the Markup mutex, and
 // must be called under lines mutex.
 func (tb *TextBuf) LinesDeleted(tbe *textbuf.Edit) {
+	if tb.Pieces != nil {
+		return
+	}
 	tb.MarkupMu.Lock()
 
 	tb.MarkupEdits = append(tb.MarkupEdits, tbe)
@@ -1552,6 +1768,9 @@ func (tb *TextBuf) LinesDeleted(tbe *textbuf.Edit) {
 		pfs := tb.PiState.Done()
 		pfs.Src.LinesDeleted(stln, edln)
 	}
+	tb.FoldsMu.Lock()
+	tb.Folds.LinesDeleted(stln, edln)
+	tb.FoldsMu.Unlock()
 
 	st := tbe.Reg.Start.Ln
 	tb.LineBytes[st] = []byte(string(tb.Lines[st]))
@@ -1585,6 +1804,9 @@ func (tb *TextBuf) IsMarkingUp() bool {
 
 // InitialMarkup does the first-pass markup on the file
 func (tb *TextBuf) InitialMarkup() {
+	if tb.IsLargeFile() {
+		return
+	}
 	if tb.Hi.UsingPi() {
 		fs := tb.PiState.Done() // initialize
 		fs.Src.SetBytes(tb.Txt)
@@ -1762,6 +1984,9 @@ func (tb *TextBuf) MarkupAllLines(maxLines int) {
 		tb.Tags[ln] = tb.AdjustedTags(ln)
 		tb.Markup[ln] = tb.Hi.MarkupLine(tb.Lines[ln], tb.HiTags[ln], tb.Tags[ln])
 	}
+	if maxLines <= 0 {
+		tb.UpdateFolds()
+	}
 	tb.MarkupMu.Unlock()
 	tb.LinesMu.Unlock()
 	tb.ClearFlag(int(TextBufMarkingUp))
@@ -1972,7 +2197,7 @@ func (tb *TextBuf) AdjustReg(reg textbuf.Region) textbuf.Region {
 
 // AddTag adds a new custom tag for given line, at given position
 func (tb *TextBuf) AddTag(ln, st, ed int, tag token.Tokens) {
-	if !tb.IsValidLine(ln) {
+	if !tb.IsValidLine(ln) || tb.IsLargeFile() {
 		return
 	}
 	tb.MarkupMu.Lock()
@@ -1997,7 +2222,7 @@ func (tb *TextBuf) AddTagEdit(tbe *textbuf.Edit, tag token.Tokens) {
 func (tb *TextBuf) TagAt(pos lex.Pos) (reg lex.Lex, ok bool) {
 	tb.MarkupMu.Lock()
 	defer tb.MarkupMu.Unlock()
-	if !tb.IsValidLine(pos.Ln) {
+	if !tb.IsValidLine(pos.Ln) || tb.IsLargeFile() {
 		return
 	}
 	tb.Tags[pos.Ln] = tb.AdjustedTags(pos.Ln) // re-adjust for current info
@@ -2012,7 +2237,7 @@ func (tb *TextBuf) TagAt(pos lex.Pos) (reg lex.Lex, ok bool) {
 // RemoveTag removes tag (optionally only given tag if non-zero) at given position
 // if it exists -- returns tag
 func (tb *TextBuf) RemoveTag(pos lex.Pos, tag token.Tokens) (reg lex.Lex, ok bool) {
-	if !tb.IsValidLine(pos.Ln) {
+	if !tb.IsValidLine(pos.Ln) || tb.IsLargeFile() {
 		return
 	}
 	tb.MarkupMu.Lock()
@@ -2040,7 +2265,7 @@ func (tb *TextBuf) RemoveTag(pos lex.Pos, tag token.Tokens) (reg lex.Lex, ok boo
 func (tb *TextBuf) HiTagAtPos(pos lex.Pos) (*lex.Lex, int) {
 	tb.MarkupMu.Lock()
 	defer tb.MarkupMu.Unlock()
-	if !tb.IsValidLine(pos.Ln) {
+	if !tb.IsValidLine(pos.Ln) || tb.IsLargeFile() {
 		return nil, -1
 	}
 	return tb.HiTags[pos.Ln].AtPos(pos.Ch)
@@ -2053,7 +2278,7 @@ func (tb *TextBuf) LexString(ln int, lx *lex.Lex) string {
 	if !tb.IsValidLine(ln) {
 		return ""
 	}
-	rns := tb.Lines[ln][lx.St:lx.Ed]
+	rns := tb.LineImpl(ln)[lx.St:lx.Ed]
 	return string(rns)
 }
 
@@ -2067,8 +2292,12 @@ func (tb *TextBuf) LexObjPathString(ln int, lx *lex.Lex) string {
 	if !tb.IsValidLine(ln) {
 		return ""
 	}
-	stlx := lex.ObjPathAt(tb.HiTags[ln], lx)
-	rns := tb.Lines[ln][stlx.St:lx.Ed]
+	var tags lex.Line
+	if ln < len(tb.HiTags) {
+		tags = tb.HiTags[ln]
+	}
+	stlx := lex.ObjPathAt(tags, lx)
+	rns := tb.LineImpl(ln)[stlx.St:lx.Ed]
 	return string(rns)
 }
 
@@ -2190,7 +2419,7 @@ func (tb *TextBuf) IndentLine(ln, ind int) *textbuf.Edit {
 	}
 
 	tb.LinesMu.RLock()
-	curind, _ := lex.LineIndent(tb.Lines[ln], tabSz)
+	curind, _ := lex.LineIndent(tb.LineImpl(ln), tabSz)
 	tb.LinesMu.RUnlock()
 	if ind > curind {
 		return tb.InsertText(lex.Pos{Ln: ln}, indent.Bytes(ichr, ind-curind, tabSz), EditSignal)
@@ -2214,7 +2443,11 @@ func (tb *TextBuf) AutoIndent(ln int) (tbe *textbuf.Edit, indLev, chPos int) {
 	tb.MarkupMu.RLock()
 	lp, _ := pi.LangSupport.Props(tb.PiState.Sup)
 	var pInd, delInd int
-	if lp != nil && lp.Lang != nil {
+	if tb.Pieces != nil { // only the lines in scope, without tags
+		lo := ints.MaxInt(ln-TextBufMaxScopeLines, 0)
+		lns, tags := tb.LinesRange(lo, ln+1)
+		pInd, delInd, _, _ = lex.BracketIndentLine(lns, tags, ln-lo, tabSz)
+	} else if lp != nil && lp.Lang != nil {
 		pInd, delInd, _, _ = lp.Lang.IndentLine(&tb.PiState, tb.Lines, tb.HiTags, ln, tabSz)
 	} else {
 		pInd, delInd, _, _ = lex.BracketIndentLine(tb.Lines, tb.HiTags, ln, tabSz)
@@ -2271,6 +2504,9 @@ func (tb *TextBuf) InComment(pos lex.Pos) bool {
 func (tb *TextBuf) LineCommented(ln int) bool {
 	tb.MarkupMu.RLock()
 	defer tb.MarkupMu.RUnlock()
+	if ln >= len(tb.HiTags) {
+		return false
+	}
 	tags := tb.HiTags[ln]
 	if len(tags) == 0 {
 		return false
@@ -2290,7 +2526,7 @@ func (tb *TextBuf) CommentRegion(st, ed int) {
 
 	ch := 0
 	tb.LinesMu.RLock()
-	ind, _ := lex.LineIndent(tb.Lines[st], tabSz)
+	ind, _ := lex.LineIndent(tb.LineImpl(st), tabSz)
 	tb.LinesMu.RUnlock()
 
 	if ind > 0 {
@@ -2325,7 +2561,7 @@ func (tb *TextBuf) CommentRegion(st, ed int) {
 		if doCom {
 			tb.InsertText(lex.Pos{Ln: ln, Ch: ch}, []byte(comst), EditSignal)
 			if comed != "" {
-				lln := len(tb.Lines[ln])
+				lln := tb.LineLen(ln)
 				tb.InsertText(lex.Pos{Ln: ln, Ch: lln}, []byte(comed), EditSignal)
 			}
 		} else {
@@ -2351,7 +2587,7 @@ func (tb *TextBuf) JoinParaLines(stLn, edLn int) {
 
 	curEd := edLn                      // current end of region being joined == last blank line
 	for ln := edLn; ln >= stLn; ln-- { // reverse order
-		lb := tb.LineBytes[ln]
+		lb := tb.BytesLine(ln)
 		lbt := bytes.TrimSpace(lb)
 		if len(lbt) == 0 || ln == stLn {
 			if ln < curEd-1 {
@@ -2363,9 +2599,12 @@ func (tb *TextBuf) JoinParaLines(stLn, edLn int) {
 				if curEd == edLn {
 					ep.Ln = curEd
 				}
-				eln := tb.Lines[ep.Ln]
-				ep.Ch = len(eln)
-				tlb := bytes.Join(tb.LineBytes[stp.Ln:ep.Ln+1], []byte(" "))
+				ep.Ch = tb.LineLen(ep.Ln)
+				lbs := make([][]byte, ep.Ln+1-stp.Ln)
+				for i := range lbs {
+					lbs[i] = tb.BytesLine(stp.Ln + i)
+				}
+				tlb := bytes.Join(lbs, []byte(" "))
 				tb.ReplaceText(stp, ep, stp, string(tlb), EditSignal, ReplaceNoMatchCase)
 			}
 			curEd = ln
@@ -2379,7 +2618,7 @@ func (tb *TextBuf) JoinParaLines(stLn, edLn int) {
 func (tb *TextBuf) TabsToSpaces(ln int) {
 	tabSz := tb.Opts.TabSize
 
-	lr := tb.Lines[ln]
+	lr := tb.Line(ln)
 	st := lex.Pos{Ln: ln}
 	ed := lex.Pos{Ln: ln}
 	i := 0
@@ -2395,7 +2634,7 @@ func (tb *TextBuf) TabsToSpaces(ln int) {
 			ed.Ch = i + 1
 			tb.ReplaceText(st, ed, st, indent.Spaces(1, nspc), EditNoSignal, ReplaceNoMatchCase)
 			i += nspc
-			lr = tb.Lines[ln]
+			lr = tb.Line(ln)
 		} else {
 			i++
 		}
@@ -2448,6 +2687,30 @@ func (tb *TextBuf) SetCompleter(data interface{}, matchFun complete.MatchFunc, e
 	})
 }
 
+// SetCompleteSource sets a completer that gets candidates from given
+// source function, and ranks them by fuzzy matching against the
+// identifier before the cursor (see gi.CompleteFuzzy) -- the selected
+// completion replaces that identifier, including any of it after the
+// cursor.  lookupFun is optional.
+func (tb *TextBuf) SetCompleteSource(data interface{}, src gi.CompleteSourceFunc, lookupFun complete.LookupFunc) {
+	tb.SetCompleter(data, gi.CompleteFuzzy(src), CompleteEditIdent, lookupFun)
+}
+
+// CompleteEditIdent is a complete.EditFunc for TextBuf that inserts the
+// completion in place of the seed, deleting the rest of the identifier
+// (letters, digits and _) after the cursor
+func CompleteEditIdent(data interface{}, text string, cursorPos int, comp complete.Completion, seed string) (ed complete.Edit) {
+	ed.NewText = comp.Text
+	rn := []rune(text)
+	for i := cursorPos; i < len(rn); i++ {
+		if !isWordRune(rn[i]) {
+			break
+		}
+		ed.ForwardDelete++
+	}
+	return ed
+}
+
 func (tb *TextBuf) DeleteCompleter() {
 	if tb.Complete == nil {
 		return
@@ -2474,6 +2737,8 @@ func (tb *TextBuf) CompleteText(s string) {
 	c := tb.Complete.GetCompletion(s)
 	pos := lex.Pos{tb.Complete.SrcLn, tb.Complete.SrcCh}
 	ed := tb.Complete.EditFunc(tb.Complete.Context, tbes, tb.Complete.SrcCh, c, tb.Complete.Seed)
+	tb.Undos.NewGroup() // completion is undone as one unit, separate from typing
+	defer tb.Undos.NewGroup()
 	if ed.ForwardDelete > 0 {
 		delEn := lex.Pos{tb.Complete.SrcLn, tb.Complete.SrcCh + ed.ForwardDelete}
 		tb.DeleteText(pos, delEn, EditNoSignal)
@@ -2498,7 +2763,9 @@ func (tb *TextBuf) CompleteExtend(s string) {
 	pos := lex.Pos{tb.Complete.SrcLn, tb.Complete.SrcCh}
 	st := pos
 	st.Ch -= len(tb.Complete.Seed)
+	tb.Undos.NewGroup()
 	tb.ReplaceText(st, pos, st, s, EditSignal, ReplaceNoMatchCase)
+	tb.Undos.NewGroup()
 	if tb.CurView != nil {
 		ep := st
 		ep.Ch += len(s)
@@ -2558,7 +2825,7 @@ func (tb *TextBuf) DeleteSpell() {
 // CorrectText edits the text using the string chosen from the correction menu
 func (tb *TextBuf) CorrectText(s string) {
 	st := lex.Pos{tb.Spell.SrcLn, tb.Spell.SrcCh} // start of word
-	tb.RemoveTag(st, token.TextSpellErr)
+	tb.RemoveTag(st, histyle.SpellErr)
 	oend := st
 	oend.Ch += len(tb.Spell.Word)
 	tb.ReplaceText(st, oend, st, s, EditSignal, ReplaceNoMatchCase)
@@ -2570,35 +2837,39 @@ func (tb *TextBuf) CorrectText(s string) {
 	}
 }
 
-// CorrectClear clears the TextSpellErr tag for given word
+// CorrectClear clears the SpellErr tag for given word
 func (tb *TextBuf) CorrectClear(s string) {
 	st := lex.Pos{tb.Spell.SrcLn, tb.Spell.SrcCh} // start of word
-	tb.RemoveTag(st, token.TextSpellErr)
+	tb.RemoveTag(st, histyle.SpellErr)
 }
 
 // SpellCheckLineErrs runs spell check on given line, and returns Lex tags
-// with token.TextSpellErr for any misspelled words
+// with histyle.SpellErr for any misspelled words
 func (tb *TextBuf) SpellCheckLineErrs(ln int) lex.Line {
-	if !tb.IsValidLine(ln) {
+	if !tb.IsValidLine(ln) || tb.IsLargeFile() {
 		return nil
 	}
 	tb.LinesMu.RLock()
 	defer tb.LinesMu.RUnlock()
 	tb.MarkupMu.RLock()
 	defer tb.MarkupMu.RUnlock()
-	return spell.CheckLexLine(tb.Lines[ln], tb.HiTags[ln])
+	ser := spell.CheckLexLine(tb.Lines[ln], tb.HiTags[ln])
+	for i := range ser {
+		ser[i].Tok.Tok = histyle.SpellErr // pi tags them as token.TextSpellErr
+	}
+	return ser
 }
 
 // SpellCheckLineTag runs spell check on given line, and sets Tags for any
 // misspelled words and updates markup for that line.
 func (tb *TextBuf) SpellCheckLineTag(ln int) {
-	if !tb.IsValidLine(ln) {
+	if !tb.IsValidLine(ln) || tb.IsLargeFile() {
 		return
 	}
 	ser := tb.SpellCheckLineErrs(ln)
 	tb.MarkupMu.Lock()
 	ntgs := tb.AdjustedTags(ln)
-	ntgs.DeleteToken(token.TextSpellErr)
+	ntgs.DeleteToken(histyle.SpellErr)
 	for _, t := range ser {
 		ntgs.AddSort(t)
 	}
@@ -0,0 +1,312 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textbuf
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// EditorConfigFile is the name of the files with editor settings, as
+// described at https://editorconfig.org
+var EditorConfigFile = ".editorconfig"
+
+// EditorConfig has the settings from EditorConfigFile files that apply to
+// a given file, with closer files and later sections taking precedence
+type EditorConfig struct {
+	Files []string          `desc:"the files the settings were read from, closest last"`
+	Props map[string]string `desc:"all of the properties that apply, with lower-case names and values (except for unknown properties)"`
+}
+
+// ReadEditorConfig returns the settings from the EditorConfigFile files in
+// the directory of given file and its parents, up to one with root = true
+// -- returns nil if there are no settings for the file
+func ReadEditorConfig(filename string) (*EditorConfig, error) {
+	afn, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	dir := filepath.Dir(afn)
+	for {
+		ecf := filepath.Join(dir, EditorConfigFile)
+		if _, err := os.Stat(ecf); err == nil {
+			files = append(files, ecf)
+			if root, _ := editorConfigIsRoot(ecf); root {
+				break
+			}
+		}
+		pdir := filepath.Dir(dir)
+		if pdir == dir {
+			break
+		}
+		dir = pdir
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+	ec := &EditorConfig{Props: make(map[string]string)}
+	for i := len(files) - 1; i >= 0; i-- { // farthest first
+		if err := ec.ReadFile(files[i], afn); err != nil {
+			return nil, err
+		}
+	}
+	if len(ec.Props) == 0 {
+		return nil, nil
+	}
+	return ec, nil
+}
+
+// editorConfigIsRoot returns true if given file has root = true in its preamble
+func editorConfigIsRoot(ecf string) (bool, error) {
+	fp, err := os.Open(ecf)
+	if err != nil {
+		return false, err
+	}
+	defer fp.Close()
+	sc := bufio.NewScanner(fp)
+	for sc.Scan() {
+		ln := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(ln, "[") {
+			break
+		}
+		if k, v, ok := editorConfigProp(ln); ok && k == "root" {
+			return strings.ToLower(v) == "true", nil
+		}
+	}
+	return false, sc.Err()
+}
+
+// editorConfigProp parses a key = value line, returning the lower-case key
+func editorConfigProp(ln string) (key, val string, ok bool) {
+	if ln == "" || ln[0] == '#' || ln[0] == ';' {
+		return
+	}
+	eq := strings.IndexAny(ln, "=:")
+	if eq < 0 {
+		return
+	}
+	key = strings.ToLower(strings.TrimSpace(ln[:eq]))
+	val = strings.TrimSpace(ln[eq+1:])
+	return key, val, key != ""
+}
+
+// ReadFile reads the settings from given EditorConfigFile that apply to
+// given absolute file name, overriding any existing settings
+func (ec *EditorConfig) ReadFile(ecf, filename string) error {
+	fp, err := os.Open(ecf)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	rel, err := filepath.Rel(filepath.Dir(ecf), filename)
+	if err != nil {
+		return err
+	}
+	rel = filepath.ToSlash(rel)
+	used := false
+	match := false
+	sc := bufio.NewScanner(fp)
+	for sc.Scan() {
+		ln := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(ln, "[") && strings.HasSuffix(ln, "]") {
+			match = EditorConfigMatch(ln[1:len(ln)-1], rel)
+			continue
+		}
+		if !match {
+			continue
+		}
+		if k, v, ok := editorConfigProp(ln); ok {
+			if len(k) <= 50 && len(v) <= 255 { // limits from the spec
+				if _, known := editorConfigKnown[k]; known {
+					v = strings.ToLower(v)
+				}
+				ec.Props[k] = v
+				used = true
+			}
+		}
+	}
+	if used {
+		ec.Files = append(ec.Files, ecf)
+	}
+	return sc.Err()
+}
+
+// editorConfigKnown are the standard properties, which have case-insensitive values
+var editorConfigKnown = map[string]struct{}{
+	"indent_style": {}, "indent_size": {}, "tab_width": {}, "end_of_line": {}, "charset": {},
+	"trim_trailing_whitespace": {}, "insert_final_newline": {}, "max_line_length": {},
+}
+
+// EditorConfigMatch returns true if the glob pattern of an EditorConfigFile
+// section matches given file path, which is relative to the directory of
+// the EditorConfigFile and uses / separators
+func EditorConfigMatch(glob, rel string) bool {
+	re, err := regexp.Compile(EditorConfigRegexp(glob))
+	if err != nil {
+		return false
+	}
+	return re.MatchString(rel)
+}
+
+// EditorConfigRegexp returns the regular expression for a section glob
+// pattern: * matches any chars except /, ** any chars, ? any single char,
+// [seq] and [!seq] a char in (or not in) seq, {a,b} any of the
+// comma-separated alternatives, and {n1..n2} an integer in the range.
+// Globs without a / match the file name in any directory.
+func EditorConfigRegexp(glob string) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+	switch {
+	case strings.HasPrefix(glob, "/"):
+		glob = glob[1:]
+	case !strings.Contains(glob, "/"):
+		sb.WriteString("(?:.*/)?")
+	}
+	rn := []rune(glob)
+	depth := 0 // brace depth
+	for i := 0; i < len(rn); i++ {
+		r := rn[i]
+		switch r {
+		case '\\':
+			if i+1 < len(rn) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(string(rn[i])))
+			}
+		case '*':
+			switch {
+			case i+2 < len(rn) && rn[i+1] == '*' && rn[i+2] == '/':
+				sb.WriteString("(?:.*/)?")
+				i += 2
+			case i+1 < len(rn) && rn[i+1] == '*':
+				sb.WriteString(".*")
+				i++
+			default:
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			ed := runeIndexFrom(rn, i+1, ']')
+			if ed < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			set := rn[i+1 : ed]
+			sb.WriteString("[")
+			if len(set) > 0 && set[0] == '!' {
+				sb.WriteString("^")
+				set = set[1:]
+			}
+			for _, sr := range set {
+				if sr == '\\' || sr == '[' || sr == ']' || sr == '^' {
+					sb.WriteRune('\\')
+				}
+				sb.WriteRune(sr)
+			}
+			sb.WriteString("]")
+			i = ed
+		case '{':
+			ed := runeIndexFrom(rn, i+1, '}')
+			if ed < 0 {
+				sb.WriteString(`\{`)
+				continue
+			}
+			inner := string(rn[i+1 : ed])
+			if rs, ok := editorConfigNumRange(inner); ok {
+				sb.WriteString(rs)
+				i = ed
+				continue
+			}
+			if !strings.Contains(inner, ",") {
+				sb.WriteString(`\{`)
+				continue
+			}
+			sb.WriteString("(?:")
+			depth++
+		case '}':
+			if depth > 0 {
+				sb.WriteString(")")
+				depth--
+			} else {
+				sb.WriteString(`\}`)
+			}
+		case ',':
+			if depth > 0 {
+				sb.WriteString("|")
+			} else {
+				sb.WriteString(",")
+			}
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return sb.String()
+}
+
+// runeIndexFrom returns the index of r in rn starting at st, or -1
+func runeIndexFrom(rn []rune, st int, r rune) int {
+	for i := st; i < len(rn); i++ {
+		if rn[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// editorConfigNumRange returns a regexp for an integer range n1..n2
+func editorConfigNumRange(s string) (string, bool) {
+	dd := strings.Index(s, "..")
+	if dd < 0 {
+		return "", false
+	}
+	n1, err1 := strconv.Atoi(s[:dd])
+	n2, err2 := strconv.Atoi(s[dd+2:])
+	if err1 != nil || err2 != nil || n2 < n1 || n2-n1 > 1000 {
+		return "", false
+	}
+	alts := make([]string, 0, n2-n1+1)
+	for n := n1; n <= n2; n++ {
+		alts = append(alts, strconv.Itoa(n))
+	}
+	return "(?:" + strings.Join(alts, "|") + ")", true
+}
+
+// IntProp returns the integer value of given property, and false if it is
+// not set or not an integer
+func (ec *EditorConfig) IntProp(key string) (int, bool) {
+	v, ok := ec.Props[key]
+	if !ok {
+		return 0, false
+	}
+	iv, err := strconv.Atoi(v)
+	return iv, err == nil
+}
+
+// SetOpts sets the indentation options from the settings: indent_style,
+// and tab_width or indent_size for the TabSize
+func (ec *EditorConfig) SetOpts(opts *Opts) {
+	switch ec.Props["indent_style"] {
+	case "tab":
+		opts.SpaceIndent = false
+	case "space":
+		opts.SpaceIndent = true
+	}
+	tw, hasTw := ec.IntProp("tab_width")
+	is, hasIs := ec.IntProp("indent_size")
+	switch {
+	case opts.SpaceIndent && hasIs && is > 0:
+		opts.TabSize = is
+	case hasTw && tw > 0:
+		opts.TabSize = tw
+	case hasIs && is > 0:
+		opts.TabSize = is
+	}
+}
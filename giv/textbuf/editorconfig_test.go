@@ -0,0 +1,172 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textbuf
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestEditorConfigMatch(t *testing.T) {
+	tests := []struct {
+		glob  string
+		rel   string
+		match bool
+	}{
+		{"*", "a.go", true},
+		{"*", "sub/a.go", true},
+		{"*.go", "a.go", true},
+		{"*.go", "sub/dir/a.go", true},
+		{"*.go", "a.got", false},
+		{"*.{js,py}", "a.py", true},
+		{"*.{js,py}", "sub/a.js", true},
+		{"*.{js,py}", "a.go", false},
+		{"{package.json,.travis.yml}", ".travis.yml", true},
+		{"{a.go}", "{a.go}", true},
+		{"lib/**.js", "lib/a.js", true},
+		{"lib/**.js", "lib/sub/a.js", true},
+		{"lib/**.js", "sub/lib/a.js", false},
+		{"lib/*.js", "lib/sub/a.js", false},
+		{"/top.go", "top.go", true},
+		{"/top.go", "sub/top.go", false},
+		{"**/test/*.go", "a/b/test/x.go", true},
+		{"**/test/*.go", "test/x.go", true},
+		{"a?.go", "ab.go", true},
+		{"a?.go", "a/.go", false},
+		{"[abc].go", "b.go", true},
+		{"[abc].go", "d.go", false},
+		{"[!abc].go", "d.go", true},
+		{"[!abc].go", "a.go", false},
+		{"file{1..3}.txt", "file2.txt", true},
+		{"file{1..3}.txt", "file4.txt", false},
+		{"a\\*.go", "a*.go", true},
+		{"a\\*.go", "ab.go", false},
+		{"[.go", "[.go", true},
+		{"Makefile", "sub/Makefile", true},
+	}
+	for _, ts := range tests {
+		if m := EditorConfigMatch(ts.glob, ts.rel); m != ts.match {
+			t.Errorf("glob %q path %q: match: %v != expected: %v (regexp: %s)", ts.glob, ts.rel, m, ts.match, EditorConfigRegexp(ts.glob))
+		}
+	}
+}
+
+func TestEditorConfigProp(t *testing.T) {
+	tests := []struct {
+		ln  string
+		key string
+		val string
+		ok  bool
+	}{
+		{"indent_style = tab", "indent_style", "tab", true},
+		{"Indent_Size=4", "indent_size", "4", true},
+		{"tab_width : 8", "tab_width", "8", true},
+		{"key = a = b", "key", "a = b", true},
+		{"# comment = x", "", "", false},
+		{"; comment = x", "", "", false},
+		{"", "", "", false},
+		{"no value", "", "", false},
+		{" = x", "", "x", false},
+	}
+	for _, ts := range tests {
+		key, val, ok := editorConfigProp(ts.ln)
+		if key != ts.key || val != ts.val || ok != ts.ok {
+			t.Errorf("%q: %q %q %v != expected: %q %q %v", ts.ln, key, val, ok, ts.key, ts.val, ts.ok)
+		}
+	}
+}
+
+// writeEditorConfig writes the EditorConfigFile in given dir
+func writeEditorConfig(t *testing.T, dir, txt string) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, EditorConfigFile), []byte(txt), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadEditorConfig(t *testing.T) {
+	tdir, err := ioutil.TempDir("", "editorconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tdir)
+
+	writeEditorConfig(t, tdir, "root = true\n\n[*]\nindent_style = space\nindent_size = 2\ncustom = MixedCase\n\n[*.go]\nindent_style = Tab\n\n[*.{go,py}]\ntab_width = 8\n")
+	writeEditorConfig(t, filepath.Join(tdir, "sub"), "[*.go]\ntab_width = 4\n\n[doc/**]\nindent_size = 3\n")
+	writeEditorConfig(t, filepath.Join(tdir, "other"), "root = true\n[*.py]\nindent_size = 4\n")
+
+	tests := []struct {
+		fn    string
+		files []string
+		props map[string]string
+	}{
+		{"a.go", []string{""},
+			map[string]string{"indent_style": "tab", "indent_size": "2", "tab_width": "8", "custom": "MixedCase"}},
+		{"a.txt", []string{""},
+			map[string]string{"indent_style": "space", "indent_size": "2", "custom": "MixedCase"}},
+		{"sub/a.go", []string{"", "sub"},
+			map[string]string{"indent_style": "tab", "indent_size": "2", "tab_width": "4", "custom": "MixedCase"}},
+		{"sub/doc/x/a.md", []string{"", "sub"},
+			map[string]string{"indent_style": "space", "indent_size": "3", "custom": "MixedCase"}},
+		{"other/a.py", []string{"other"},
+			map[string]string{"indent_size": "4"}},
+		{"other/a.go", nil, nil},
+	}
+	for _, ts := range tests {
+		ec, err := ReadEditorConfig(filepath.Join(tdir, ts.fn))
+		if err != nil {
+			t.Errorf("%s: error: %v", ts.fn, err)
+			continue
+		}
+		if ts.props == nil {
+			if ec != nil {
+				t.Errorf("%s: props: %v != expected: none", ts.fn, ec.Props)
+			}
+			continue
+		}
+		if ec == nil {
+			t.Errorf("%s: props: none != expected: %v", ts.fn, ts.props)
+			continue
+		}
+		if !reflect.DeepEqual(ec.Props, ts.props) {
+			t.Errorf("%s: props: %v != expected: %v", ts.fn, ec.Props, ts.props)
+		}
+		files := make([]string, len(ts.files))
+		for i, d := range ts.files {
+			files[i] = filepath.Join(tdir, d, EditorConfigFile)
+		}
+		if !reflect.DeepEqual(ec.Files, files) {
+			t.Errorf("%s: files: %v != expected: %v", ts.fn, ec.Files, files)
+		}
+	}
+}
+
+func TestEditorConfigSetOpts(t *testing.T) {
+	tests := []struct {
+		props    map[string]string
+		spaceInd bool
+		tabSz    int
+	}{
+		{map[string]string{}, false, 4},
+		{map[string]string{"indent_style": "space", "indent_size": "2", "tab_width": "8"}, true, 2},
+		{map[string]string{"indent_style": "tab", "indent_size": "2", "tab_width": "8"}, false, 8},
+		{map[string]string{"indent_style": "tab", "indent_size": "3"}, false, 3},
+		{map[string]string{"indent_size": "tab", "tab_width": "x"}, false, 4},
+	}
+	for _, ts := range tests {
+		opts := &Opts{}
+		opts.TabSize = 4
+		ec := &EditorConfig{Props: ts.props}
+		ec.SetOpts(opts)
+		if opts.SpaceIndent != ts.spaceInd || opts.TabSize != ts.tabSz {
+			t.Errorf("%v: space indent: %v tab size: %d != expected: %v %d", ts.props, opts.SpaceIndent, opts.TabSize, ts.spaceInd, ts.tabSz)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textbuf
+
+// ElasticTabWidths computes elastic tabstops
+// (http://nickgravgaard.com/elastic-tabstops), where tabs separate the
+// text of each line into cells that are aligned as columns with the cells
+// of adjacent lines.  cells[ln] has the widths of the text of the
+// tab-terminated cells of line ln (not including the text after the last
+// tab), and the returned widths are the widths of those cells including
+// their tab: the max of each column of cells in a block of consecutive
+// lines that all have that column, plus pad, and at least minWd.
+func ElasticTabWidths(cells [][]float32, pad, minWd float32) [][]float32 {
+	nln := len(cells)
+	wds := make([][]float32, nln)
+	for ln := range cells {
+		wds[ln] = make([]float32, len(cells[ln]))
+	}
+	for c := 0; ; c++ {
+		any := false
+		ln := 0
+		for ln < nln {
+			if len(cells[ln]) <= c {
+				ln++
+				continue
+			}
+			any = true
+			st := ln
+			mx := minWd
+			for ln < nln && len(cells[ln]) > c {
+				if w := cells[ln][c] + pad; w > mx {
+					mx = w
+				}
+				ln++
+			}
+			for bl := st; bl < ln; bl++ {
+				wds[bl][c] = mx
+			}
+		}
+		if !any {
+			break
+		}
+	}
+	return wds
+}
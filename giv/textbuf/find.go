@@ -0,0 +1,169 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textbuf
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// FindOpts are the options for find and replace.  All matching is done via
+// a regexp compiled from these options, and matches never span lines.
+type FindOpts struct {
+	Find      string `desc:"text to find -- a regular expression if Regexp is set, otherwise literal text"`
+	Regexp    bool   `desc:"Find is a regular expression -- the replacement text can then refer to capture groups as $1 or ${name} -- use $$ for a literal $"`
+	UseCase   bool   `desc:"pay attention to case when matching"`
+	WholeWord bool   `desc:"only match at word boundaries"`
+}
+
+// IsSimple returns true if the options can be handled by the plain
+// (non-regexp) search functions: literal text that can match anywhere
+func (fo *FindOpts) IsSimple() bool {
+	return !fo.Regexp && !fo.WholeWord
+}
+
+// Compile returns the regexp for these options
+func (fo *FindOpts) Compile() (*regexp.Regexp, error) {
+	if fo.Find == "" {
+		return nil, errors.New("textbuf.FindOpts: find string is empty")
+	}
+	pat := fo.Find
+	if !fo.Regexp {
+		pat = regexp.QuoteMeta(pat)
+	}
+	if fo.WholeWord {
+		pat = `\b(?:` + pat + `)\b`
+	}
+	if !fo.UseCase {
+		pat = "(?i)" + pat
+	}
+	return regexp.Compile(pat)
+}
+
+// Expand returns the replacement text for the match in src at given
+// submatch indexes (from FindSubmatchIndex), expanding capture group
+// references if Regexp is set -- otherwise repl is used literally.
+func (fo *FindOpts) Expand(re *regexp.Regexp, src []byte, idx []int, repl string) []byte {
+	if !fo.Regexp {
+		return []byte(repl)
+	}
+	return re.Expand(nil, []byte(repl), src, idx)
+}
+
+// ReplaceLine replaces all matches of re within one line of text, returning
+// the new line and the number of replacements.
+func (fo *FindOpts) ReplaceLine(re *regexp.Regexp, src []byte, repl string) ([]byte, int) {
+	mi := re.FindAllSubmatchIndex(src, -1)
+	if len(mi) == 0 {
+		return src, 0
+	}
+	var b bytes.Buffer
+	lst := 0
+	for _, idx := range mi {
+		b.Write(src[lst:idx[0]])
+		b.Write(fo.Expand(re, src, idx, repl))
+		lst = idx[1]
+	}
+	b.Write(src[lst:])
+	return b.Bytes(), len(mi)
+}
+
+// ReplaceBytes replaces all matches of re within text, line by line,
+// returning the new text and the number of replacements.
+func (fo *FindOpts) ReplaceBytes(re *regexp.Regexp, src []byte, repl string) ([]byte, int) {
+	lns := bytes.Split(src, []byte("\n"))
+	cnt := 0
+	for i, ln := range lns {
+		nl, n := fo.ReplaceLine(re, ln, repl)
+		lns[i] = nl
+		cnt += n
+	}
+	if cnt == 0 {
+		return src, 0
+	}
+	return bytes.Join(lns, []byte("\n")), cnt
+}
+
+// ReplaceFile replaces all matches of re within given file, saving it in
+// place only if there were any replacements.  Returns the number of
+// replacements.
+func (fo *FindOpts) ReplaceFile(re *regexp.Regexp, filename string, repl string) (int, error) {
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return 0, err
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return 0, err
+	}
+	nb, n := fo.ReplaceBytes(re, b, repl)
+	if n == 0 {
+		return 0, nil
+	}
+	return n, ioutil.WriteFile(filename, nb, fi.Mode())
+}
+
+// FileMatches records the matches within one file
+type FileMatches struct {
+	Filename string  `desc:"full path to the file"`
+	Matches  []Match `desc:"matches within the file"`
+}
+
+// FindInFiles searches for re in each of given files, returning the
+// matches for those files that have any
+func FindInFiles(files []string, re *regexp.Regexp) []FileMatches {
+	var fms []FileMatches
+	for _, fn := range files {
+		cnt, matches := SearchFileRegexp(fn, re)
+		if cnt > 0 {
+			fms = append(fms, FileMatches{Filename: fn, Matches: matches})
+		}
+	}
+	return fms
+}
+
+// ProjectFiles returns all of the regular files within given root
+// directory, recursively, skipping hidden files and directories (starting
+// with a .) -- if exts is non-empty, only files with one of those
+// extensions (including the ., e.g., ".go") are included.
+func ProjectFiles(root string, exts []string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil // skip unreadable
+		}
+		nm := info.Name()
+		if path != root && strings.HasPrefix(nm, ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if len(exts) > 0 {
+			ext := filepath.Ext(nm)
+			has := false
+			for _, ex := range exts {
+				if ex == ext {
+					has = true
+					break
+				}
+			}
+			if !has {
+				return nil
+			}
+		}
+		files = append(files, path)
+		return nil
+	})
+	return files, err
+}
@@ -0,0 +1,280 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textbuf
+
+import (
+	"sort"
+	"unicode"
+
+	"github.com/goki/pi/lex"
+)
+
+// Fold is a region of lines that can be folded (collapsed) in a view.
+// The start line is the header of the region, which always remains visible,
+// and the lines after it through the end line (inclusive) are hidden
+// when the region is folded.
+type Fold struct {
+	StLn   int  `desc:"starting (header) line of the fold -- remains visible when folded"`
+	EdLn   int  `desc:"ending line of the fold -- inclusive"`
+	Folded bool `desc:"if true, lines after StLn through EdLn are hidden"`
+}
+
+// Contains returns true if given line is hidden by this fold when folded,
+// i.e., it is after the start line and not beyond the end line
+func (fd *Fold) Contains(ln int) bool {
+	return ln > fd.StLn && ln <= fd.EdLn
+}
+
+// Folds is a list of fold regions, sorted by starting line.  Regions
+// can be nested but never partially overlap.
+type Folds []Fold
+
+// Sort sorts the folds by starting line, and larger regions first
+// for the same starting line
+func (fs Folds) Sort() {
+	sort.Slice(fs, func(i, j int) bool {
+		if fs[i].StLn == fs[j].StLn {
+			return fs[i].EdLn > fs[j].EdLn
+		}
+		return fs[i].StLn < fs[j].StLn
+	})
+}
+
+// AtLine returns the index of the fold that starts at given line, or -1 if none
+func (fs Folds) AtLine(ln int) int {
+	idx := sort.Search(len(fs), func(i int) bool {
+		return fs[i].StLn >= ln
+	})
+	if idx < len(fs) && fs[idx].StLn == ln {
+		return idx
+	}
+	return -1
+}
+
+// Innermost returns the index of the innermost fold that contains the given
+// line, including as its start line, or -1 if none
+func (fs Folds) Innermost(ln int) int {
+	fi := -1
+	for i := range fs {
+		fd := &fs[i]
+		if fd.StLn > ln {
+			break
+		}
+		if ln <= fd.EdLn {
+			fi = i // later ones are nested inside earlier ones
+		}
+	}
+	return fi
+}
+
+// Children returns the indexes of the folds directly nested within fold at given index
+func (fs Folds) Children(fi int) []int {
+	par := fs[fi]
+	var ch []int
+	lastEd := -1
+	for i := fi + 1; i < len(fs); i++ {
+		fd := &fs[i]
+		if fd.StLn > par.EdLn {
+			break
+		}
+		if fd.StLn <= lastEd {
+			continue // nested in a previous child
+		}
+		ch = append(ch, i)
+		lastEd = fd.EdLn
+	}
+	return ch
+}
+
+// HasFolded returns true if any of the folds are folded
+func (fs Folds) HasFolded() bool {
+	for i := range fs {
+		if fs[i].Folded {
+			return true
+		}
+	}
+	return false
+}
+
+// SetAll sets the folded state of all folds
+func (fs Folds) SetAll(folded bool) {
+	for i := range fs {
+		fs[i].Folded = folded
+	}
+}
+
+// Hidden returns a slice with a true value for each of nLines that is
+// hidden by a folded region -- returns nil if nothing is folded
+func (fs Folds) Hidden(nLines int) []bool {
+	if !fs.HasFolded() {
+		return nil
+	}
+	hid := make([]bool, nLines)
+	for i := range fs {
+		fd := &fs[i]
+		if !fd.Folded {
+			continue
+		}
+		for ln := fd.StLn + 1; ln <= fd.EdLn && ln < nLines; ln++ {
+			hid[ln] = true
+		}
+	}
+	return hid
+}
+
+// CopyFolded copies the Folded state from the old set of folds,
+// for folds that start on the same line, e.g., after re-computing folds
+func (fs Folds) CopyFolded(old Folds) {
+	for _, of := range old {
+		if !of.Folded {
+			continue
+		}
+		if fi := fs.AtLine(of.StLn); fi >= 0 {
+			fs[fi].Folded = true
+		}
+	}
+}
+
+// LinesInserted updates line numbers for n lines inserted after given line
+func (fs Folds) LinesInserted(stLn, n int) {
+	for i := range fs {
+		fd := &fs[i]
+		if fd.StLn > stLn {
+			fd.StLn += n
+		}
+		if fd.EdLn >= stLn {
+			fd.EdLn += n
+		}
+	}
+}
+
+// LinesDeleted updates line numbers for lines deleted from stLn up to
+// edLn (exclusive), removing any folds whose start line was deleted
+func (fs *Folds) LinesDeleted(stLn, edLn int) {
+	n := edLn - stLn
+	nf := (*fs)[:0]
+	for _, fd := range *fs {
+		if fd.StLn > stLn && fd.StLn < edLn {
+			continue
+		}
+		if fd.StLn >= edLn {
+			fd.StLn -= n
+		}
+		if fd.EdLn >= edLn {
+			fd.EdLn -= n
+		} else if fd.EdLn > stLn {
+			fd.EdLn = stLn
+		}
+		if fd.EdLn > fd.StLn {
+			nf = append(nf, fd)
+		}
+	}
+	*fs = nf
+}
+
+// blankLine returns true if line is empty or all whitespace
+func blankLine(ln []rune) bool {
+	for _, r := range ln {
+		if !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// FoldsFromIndent computes fold regions based on indentation: each
+// non-blank line that is followed by lines with greater indentation starts
+// a fold extending through the last of those more-indented lines.
+// Blank lines are included in a fold only if followed by more-indented lines.
+func FoldsFromIndent(lines [][]rune, tabSz int) Folds {
+	nln := len(lines)
+	inds := make([]int, nln)
+	for ln := 0; ln < nln; ln++ {
+		if blankLine(lines[ln]) {
+			inds[ln] = -1
+			continue
+		}
+		inds[ln], _ = lex.LineIndent(lines[ln], tabSz)
+	}
+	var fs Folds
+	type open struct{ ln, ind int }
+	var stack []open
+	lastNb := -1 // last non-blank line
+	for ln := 0; ln <= nln; ln++ {
+		ind := -1
+		if ln < nln {
+			ind = inds[ln]
+			if ind < 0 {
+				continue
+			}
+		}
+		for len(stack) > 0 && (ln == nln || ind <= stack[len(stack)-1].ind) {
+			op := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if lastNb > op.ln {
+				fs = append(fs, Fold{StLn: op.ln, EdLn: lastNb})
+			}
+		}
+		if ln == nln {
+			break
+		}
+		stack = append(stack, open{ln, ind})
+		lastNb = ln
+	}
+	fs.Sort()
+	return fs
+}
+
+// FoldsFromTags computes fold regions based on lexer group punctuation
+// tokens (braces, brackets, parens) in the syntax highlighting tags for
+// each line: a group that opens on one line and closes on a later one
+// defines a fold from the opening line through the line before the
+// closing one, so that the closing line remains visible.
+func FoldsFromTags(tags []lex.Line) Folds {
+	var fs Folds
+	var stack []int
+	for ln, tl := range tags {
+		for _, lx := range tl {
+			tok := lx.Tok.Tok
+			switch {
+			case tok.IsPunctGpLeft():
+				stack = append(stack, ln)
+			case tok.IsPunctGpRight():
+				if len(stack) == 0 {
+					continue
+				}
+				st := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				ed := ln - 1
+				if ed > st && (len(fs) == 0 || fs[len(fs)-1].StLn != st) {
+					fs = append(fs, Fold{StLn: st, EdLn: ed})
+				}
+			}
+		}
+	}
+	fs.Sort()
+	// remove duplicates, which can come from multiple groups on the same line
+	nf := fs[:0]
+	for _, fd := range fs {
+		if len(nf) > 0 && fd.StLn == nf[len(nf)-1].StLn {
+			continue
+		}
+		nf = append(nf, fd)
+	}
+	return nf
+}
+
+// HasGroupTags returns true if there are any group punctuation tokens
+// (braces etc) in the tags, indicating that FoldsFromTags can be used.
+func HasGroupTags(tags []lex.Line) bool {
+	for _, tl := range tags {
+		for _, lx := range tl {
+			if lx.Tok.Tok.IsPunctGpLeft() {
+				return true
+			}
+		}
+	}
+	return false
+}
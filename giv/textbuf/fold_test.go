@@ -0,0 +1,112 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textbuf
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/goki/pi/lex"
+	"github.com/goki/pi/token"
+)
+
+// testLines splits text into lines of runes
+func testLines(txt string) [][]rune {
+	sl := strings.Split(txt, "\n")
+	lns := make([][]rune, len(sl))
+	for i, s := range sl {
+		lns[i] = []rune(s)
+	}
+	return lns
+}
+
+func TestFoldsFromIndent(t *testing.T) {
+	tests := []struct {
+		txt string
+		fs  Folds
+	}{
+		{"a\nb\nc", nil},
+		{"func a() {\n\tx := 1\n\tif x {\n\t\ty()\n\t}\n}\n\nb", Folds{{StLn: 0, EdLn: 4}, {StLn: 2, EdLn: 3}}},
+		{"def f():\n    a\n\n    b\n\nx", Folds{{StLn: 0, EdLn: 3}}},
+		{"a\n  b\n    c\n  d\n    e", Folds{{StLn: 0, EdLn: 4}, {StLn: 1, EdLn: 2}, {StLn: 3, EdLn: 4}}},
+		{"\n\n  a", nil},
+	}
+	for _, ts := range tests {
+		fs := FoldsFromIndent(testLines(ts.txt), 2)
+		if !reflect.DeepEqual(fs, ts.fs) {
+			t.Errorf("%q folds: %v != expected: %v", ts.txt, fs, ts.fs)
+		}
+	}
+}
+
+// testTags returns the tags for lines with given group punctuation: each
+// string has the brace characters of a line
+func testTags(lns ...string) []lex.Line {
+	tags := make([]lex.Line, len(lns))
+	for ln, s := range lns {
+		for i, c := range s {
+			tok := token.PunctGpLBrace
+			switch c {
+			case '}':
+				tok = token.PunctGpRBrace
+			case '(':
+				tok = token.PunctGpLParen
+			case ')':
+				tok = token.PunctGpRParen
+			}
+			tags[ln] = append(tags[ln], lex.NewLex(token.KeyToken{Tok: tok}, i, i+1))
+		}
+	}
+	return tags
+}
+
+func TestFoldsFromTags(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []lex.Line
+		fs   Folds
+	}{
+		{"none", testTags("", "", ""), nil},
+		{"single line", testTags("{}", "()", ""), Folds{}},
+		{"nested", testTags("{", "{", "", "}", "}"), Folds{{StLn: 0, EdLn: 3}, {StLn: 1, EdLn: 2}}},
+		{"close next line", testTags("{", "}"), Folds{}},
+		{"same start", testTags("({", "", "})"), Folds{{StLn: 0, EdLn: 1}}},
+		{"unmatched close", testTags("}", "{", "", "}"), Folds{{StLn: 1, EdLn: 2}}},
+	}
+	for _, ts := range tests {
+		fs := FoldsFromTags(ts.tags)
+		if len(fs) == 0 && len(ts.fs) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(fs, ts.fs) {
+			t.Errorf("%s folds: %v != expected: %v", ts.name, fs, ts.fs)
+		}
+	}
+}
+
+func TestFoldsLinesEdited(t *testing.T) {
+	fs := Folds{{StLn: 0, EdLn: 4}, {StLn: 2, EdLn: 3, Folded: true}, {StLn: 6, EdLn: 8}}
+	fs.LinesInserted(1, 2)
+	exp := Folds{{StLn: 0, EdLn: 6}, {StLn: 4, EdLn: 5, Folded: true}, {StLn: 8, EdLn: 10}}
+	if !reflect.DeepEqual(fs, exp) {
+		t.Errorf("inserted folds: %v != expected: %v", fs, exp)
+	}
+	fs.LinesDeleted(3, 6) // deletes the start line of the nested fold
+	exp = Folds{{StLn: 0, EdLn: 3}, {StLn: 5, EdLn: 7}}
+	if !reflect.DeepEqual(fs, exp) {
+		t.Errorf("deleted folds: %v != expected: %v", fs, exp)
+	}
+	hid := fs.Hidden(8)
+	if hid != nil {
+		t.Errorf("hidden lines with nothing folded: %v != nil", hid)
+	}
+	fs[1].Folded = true
+	hid = fs.Hidden(8)
+	exph := []bool{false, false, false, false, false, false, true, true}
+	if !reflect.DeepEqual(hid, exph) {
+		t.Errorf("hidden lines: %v != expected: %v", hid, exph)
+	}
+}
@@ -0,0 +1,320 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textbuf
+
+import (
+	"bytes"
+	"io"
+	"sort"
+
+	"github.com/goki/ki/ints"
+)
+
+// piece is a span of bytes in one of the PieceTable buffers
+type piece struct {
+	add bool // true if in the add buffer, else the original
+	off int  // byte offset into the buffer
+	len int  // number of bytes
+	nls int  // number of newlines in the span
+}
+
+// PieceTable is a piece-table representation of a text, used for large
+// files: the original bytes are never copied or modified, and all inserted
+// text is appended to a separate add buffer, with the text being the
+// sequence of pieces of either buffer.  Edits only change the list of
+// pieces, so their cost does not depend on the size of the text.
+// The offsets of the newlines in each buffer are indexed, along with the
+// starting offset and line of each piece, so finding the start of a line
+// or the piece at an offset are binary searches.
+type PieceTable struct {
+	Orig   []byte `desc:"original text, which is never modified"`
+	Add    []byte `desc:"all text that has been inserted, in order"`
+	pieces []piece
+	stOffs []int // starting byte offset of each piece in the text
+	stNls  []int // number of newlines before each piece in the text
+	origNl []int // offsets of the newlines in Orig
+	addNl  []int // offsets of the newlines in Add
+	len    int
+	nls    int
+}
+
+// NewPieceTable returns a new PieceTable for given original text, which is
+// used directly and must not be modified after this call
+func NewPieceTable(orig []byte) *PieceTable {
+	pt := &PieceTable{Orig: orig}
+	pt.origNl = appendNewlines(nil, orig, 0)
+	if len(orig) > 0 {
+		pc := piece{off: 0, len: len(orig), nls: len(pt.origNl)}
+		pt.pieces = []piece{pc}
+		pt.len = pc.len
+		pt.nls = pc.nls
+	}
+	pt.index(0)
+	return pt
+}
+
+// appendNewlines appends the offsets of the newlines in b, plus given base
+// offset, to nl
+func appendNewlines(nl []int, b []byte, base int) []int {
+	for i := 0; ; {
+		ni := bytes.IndexByte(b[i:], '\n')
+		if ni < 0 {
+			return nl
+		}
+		i += ni
+		nl = append(nl, base+i)
+		i++
+	}
+}
+
+// buf returns the bytes of given piece
+func (pt *PieceTable) buf(pc piece) []byte {
+	if pc.add {
+		return pt.Add[pc.off : pc.off+pc.len]
+	}
+	return pt.Orig[pc.off : pc.off+pc.len]
+}
+
+// newlines returns the newline index of the buffer of given piece
+func (pt *PieceTable) newlines(pc piece) []int {
+	if pc.add {
+		return pt.addNl
+	}
+	return pt.origNl
+}
+
+// countNewlines returns the number of newlines in the buffer of given piece
+// between given buffer offsets
+func (pt *PieceTable) countNewlines(pc piece, st, ed int) int {
+	nl := pt.newlines(pc)
+	return sort.SearchInts(nl, ed) - sort.SearchInts(nl, st)
+}
+
+// index updates the starting offsets and lines of the pieces from index i on
+func (pt *PieceTable) index(i int) {
+	np := len(pt.pieces)
+	if cap(pt.stOffs) < np {
+		so := make([]int, np, 2*np)
+		copy(so, pt.stOffs)
+		pt.stOffs = so
+		sn := make([]int, np, 2*np)
+		copy(sn, pt.stNls)
+		pt.stNls = sn
+	}
+	pt.stOffs = pt.stOffs[:np]
+	pt.stNls = pt.stNls[:np]
+	off, nls := 0, 0
+	if i > 0 {
+		pv := pt.pieces[i-1]
+		off = pt.stOffs[i-1] + pv.len
+		nls = pt.stNls[i-1] + pv.nls
+	}
+	for ; i < np; i++ {
+		pt.stOffs[i] = off
+		pt.stNls[i] = nls
+		off += pt.pieces[i].len
+		nls += pt.pieces[i].nls
+	}
+}
+
+// Len returns the total number of bytes in the text
+func (pt *PieceTable) Len() int {
+	return pt.len
+}
+
+// NumNewlines returns the total number of newlines in the text
+func (pt *PieceTable) NumNewlines() int {
+	return pt.nls
+}
+
+// find returns the index of the piece containing byte offset off, and the
+// offset within that piece -- returns len(pieces), 0 for the end of the text
+func (pt *PieceTable) find(off int) (int, int) {
+	if off >= pt.len {
+		return len(pt.pieces), 0
+	}
+	i := sort.Search(len(pt.pieces), func(i int) bool { return pt.stOffs[i] > off }) - 1
+	return i, off - pt.stOffs[i]
+}
+
+// split splits the piece at index i at offset po within it, returning the
+// index of the piece that now starts at that offset -- the index must be
+// updated from i on after this
+func (pt *PieceTable) split(i, po int) int {
+	if po == 0 {
+		return i
+	}
+	pc := pt.pieces[i]
+	lf := piece{add: pc.add, off: pc.off, len: po, nls: pt.countNewlines(pc, pc.off, pc.off+po)}
+	rt := piece{add: pc.add, off: pc.off + po, len: pc.len - po, nls: pc.nls - lf.nls}
+	pt.pieces = append(pt.pieces, piece{})
+	copy(pt.pieces[i+2:], pt.pieces[i+1:])
+	pt.pieces[i] = lf
+	pt.pieces[i+1] = rt
+	return i + 1
+}
+
+// Insert inserts given text at byte offset off, which is clipped to the
+// valid range
+func (pt *PieceTable) Insert(off int, txt []byte) {
+	if len(txt) == 0 {
+		return
+	}
+	if off < 0 {
+		off = 0
+	}
+	if off > pt.len {
+		off = pt.len
+	}
+	anl := len(pt.addNl)
+	pt.addNl = appendNewlines(pt.addNl, txt, len(pt.Add))
+	nls := len(pt.addNl) - anl
+	i, po := pt.find(off)
+	// typing extends the previous piece if it ends at the end of the add buffer
+	if po == 0 && i > 0 {
+		pv := &pt.pieces[i-1]
+		if pv.add && pv.off+pv.len == len(pt.Add) {
+			pt.Add = append(pt.Add, txt...)
+			pv.len += len(txt)
+			pv.nls += nls
+			pt.len += len(txt)
+			pt.nls += nls
+			pt.index(i)
+			return
+		}
+	}
+	npc := piece{add: true, off: len(pt.Add), len: len(txt), nls: nls}
+	pt.Add = append(pt.Add, txt...)
+	si := i
+	if i < len(pt.pieces) {
+		i = pt.split(i, po)
+	}
+	pt.pieces = append(pt.pieces, piece{})
+	copy(pt.pieces[i+1:], pt.pieces[i:])
+	pt.pieces[i] = npc
+	pt.len += npc.len
+	pt.nls += nls
+	pt.index(si)
+}
+
+// Delete deletes n bytes starting at byte offset off, which are clipped
+// to the valid range
+func (pt *PieceTable) Delete(off, n int) {
+	if off < 0 {
+		n += off
+		off = 0
+	}
+	if off+n > pt.len {
+		n = pt.len - off
+	}
+	if n <= 0 {
+		return
+	}
+	i, po := pt.find(off)
+	st := pt.split(i, po)
+	pt.index(i)
+	j, jo := pt.find(off + n)
+	ed := len(pt.pieces)
+	if j < len(pt.pieces) {
+		ed = pt.split(j, jo)
+	}
+	for _, pc := range pt.pieces[st:ed] {
+		pt.len -= pc.len
+		pt.nls -= pc.nls
+	}
+	pt.pieces = append(pt.pieces[:st], pt.pieces[ed:]...)
+	pt.index(i)
+}
+
+// LineStart returns the byte offset of the start of given line (0 based),
+// which is the offset just after the ln'th newline -- returns Len() if
+// there are not that many lines
+func (pt *PieceTable) LineStart(ln int) int {
+	if ln <= 0 {
+		return 0
+	}
+	if ln > pt.nls {
+		return pt.len
+	}
+	// the piece with the ln'th newline is the last one starting before it
+	i := sort.Search(len(pt.pieces), func(i int) bool { return pt.stNls[i] >= ln }) - 1
+	pc := pt.pieces[i]
+	nl := pt.newlines(pc)
+	ni := sort.SearchInts(nl, pc.off) + ln - pt.stNls[i] - 1
+	return pt.stOffs[i] + nl[ni] - pc.off + 1
+}
+
+// LineEnd returns the byte offset of the end of given line, not including
+// the newline
+func (pt *PieceTable) LineEnd(ln int) int {
+	if ln+1 > pt.nls {
+		return pt.len
+	}
+	return pt.LineStart(ln+1) - 1
+}
+
+// Slice returns a copy of the bytes from st to ed byte offsets
+func (pt *PieceTable) Slice(st, ed int) []byte {
+	if st < 0 {
+		st = 0
+	}
+	if ed > pt.len {
+		ed = pt.len
+	}
+	if ed <= st {
+		return nil
+	}
+	out := make([]byte, 0, ed-st)
+	i, po := pt.find(st)
+	for ; i < len(pt.pieces) && len(out) < ed-st; i++ {
+		b := pt.buf(pt.pieces[i])[po:]
+		if n := ed - st - len(out); len(b) > n {
+			b = b[:n]
+		}
+		out = append(out, b...)
+		po = 0
+	}
+	return out
+}
+
+// MaxLineLen returns the number of bytes in the longest line, not
+// including the newline
+func (pt *PieceTable) MaxLineLen() int {
+	mx, st := 0, 0
+	for ln := 1; ln <= pt.nls; ln++ {
+		ed := pt.LineStart(ln)
+		mx = ints.MaxInt(mx, ed-1-st)
+		st = ed
+	}
+	return ints.MaxInt(mx, pt.len-st)
+}
+
+// Line returns a copy of the bytes of given line, without the newline
+func (pt *PieceTable) Line(ln int) []byte {
+	return pt.Slice(pt.LineStart(ln), pt.LineEnd(ln))
+}
+
+// Bytes returns a copy of the entire text
+func (pt *PieceTable) Bytes() []byte {
+	out := make([]byte, 0, pt.len)
+	for _, pc := range pt.pieces {
+		out = append(out, pt.buf(pc)...)
+	}
+	return out
+}
+
+// WriteTo writes the entire text to given writer, piece by piece,
+// without making a copy of it
+func (pt *PieceTable) WriteTo(w io.Writer) (int64, error) {
+	var tot int64
+	for _, pc := range pt.pieces {
+		n, err := w.Write(pt.buf(pc))
+		tot += int64(n)
+		if err != nil {
+			return tot, err
+		}
+	}
+	return tot, nil
+}
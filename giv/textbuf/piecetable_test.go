@@ -0,0 +1,112 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textbuf
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// checkPieces checks the text, lines and line starts of given piece table
+// against the expected text
+func checkPieces(t *testing.T, name string, pt *PieceTable, txt []byte) {
+	t.Helper()
+	if got := pt.Bytes(); !bytes.Equal(got, txt) {
+		t.Errorf("%s: text: %q != expected: %q", name, got, txt)
+		return
+	}
+	if pt.Len() != len(txt) {
+		t.Errorf("%s: len: %d != expected: %d", name, pt.Len(), len(txt))
+	}
+	nls := bytes.Count(txt, []byte("\n"))
+	if pt.NumNewlines() != nls {
+		t.Errorf("%s: newlines: %d != expected: %d", name, pt.NumNewlines(), nls)
+	}
+	lns := bytes.Split(txt, []byte("\n"))
+	mx := 0
+	for _, lt := range lns {
+		if len(lt) > mx {
+			mx = len(lt)
+		}
+	}
+	if pt.MaxLineLen() != mx {
+		t.Errorf("%s: max line len: %d != expected: %d", name, pt.MaxLineLen(), mx)
+	}
+	st := 0
+	for ln, lt := range lns {
+		if ls := pt.LineStart(ln); ls != st {
+			t.Errorf("%s: line %d start: %d != expected: %d", name, ln, ls, st)
+		}
+		if l := pt.Line(ln); !bytes.Equal(l, lt) {
+			t.Errorf("%s: line %d: %q != expected: %q", name, ln, l, lt)
+		}
+		st += len(lt) + 1
+	}
+	if ls := pt.LineStart(len(lns)); ls != len(txt) {
+		t.Errorf("%s: start past last line: %d != expected: %d", name, ls, len(txt))
+	}
+}
+
+func TestPieceTable(t *testing.T) {
+	tests := []struct {
+		name string
+		orig string
+		edit func(pt *PieceTable)
+		txt  string
+	}{
+		{"empty", "", func(pt *PieceTable) {}, ""},
+		{"orig", "ab\ncd\n\nef", func(pt *PieceTable) {}, "ab\ncd\n\nef"},
+		{"insert empty", "", func(pt *PieceTable) { pt.Insert(0, []byte("x\ny")) }, "x\ny"},
+		{"insert start", "ab\ncd", func(pt *PieceTable) { pt.Insert(0, []byte("0\n")) }, "0\nab\ncd"},
+		{"insert mid", "ab\ncd", func(pt *PieceTable) { pt.Insert(4, []byte("X\nY")) }, "ab\ncX\nYd"},
+		{"insert end", "ab\ncd", func(pt *PieceTable) { pt.Insert(100, []byte("\n")) }, "ab\ncd\n"},
+		{"typing", "ab\ncd", func(pt *PieceTable) {
+			pt.Insert(2, []byte("x"))
+			pt.Insert(3, []byte("y"))
+			pt.Insert(4, []byte("\n"))
+		}, "abxy\n\ncd"},
+		{"delete line", "ab\ncd\nef", func(pt *PieceTable) { pt.Delete(3, 3) }, "ab\nef"},
+		{"delete across pieces", "ab\ncd\nef", func(pt *PieceTable) {
+			pt.Insert(4, []byte("XX\nXX"))
+			pt.Delete(1, 8)
+		}, "ad\nef"},
+		{"delete clipped", "ab\ncd", func(pt *PieceTable) { pt.Delete(-2, 3); pt.Delete(2, 100) }, "b\n"},
+		{"delete all", "ab\ncd", func(pt *PieceTable) { pt.Delete(0, 5); pt.Insert(0, []byte("z")) }, "z"},
+	}
+	for _, ts := range tests {
+		pt := NewPieceTable([]byte(ts.orig))
+		ts.edit(pt)
+		checkPieces(t, ts.name, pt, []byte(ts.txt))
+	}
+}
+
+// TestPieceTableRandom checks the piece table against the same random
+// edits made to a byte slice
+func TestPieceTableRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	txt := []byte("package main\n\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n")
+	pt := NewPieceTable(append([]byte(nil), txt...))
+	ins := [][]byte{[]byte("a"), []byte("\n"), []byte("xy\nz"), []byte("\n\n"), []byte("λ")}
+	for i := 0; i < 500; i++ {
+		off := rnd.Intn(len(txt) + 1)
+		if rnd.Intn(3) > 0 || len(txt) == 0 {
+			it := ins[rnd.Intn(len(ins))]
+			pt.Insert(off, it)
+			txt = append(txt[:off], append(append([]byte(nil), it...), txt[off:]...)...)
+		} else {
+			n := rnd.Intn(8)
+			pt.Delete(off, n)
+			if off+n > len(txt) {
+				n = len(txt) - off
+			}
+			txt = append(txt[:off], txt[off+n:]...)
+		}
+		if i%50 == 0 {
+			checkPieces(t, "random", pt, txt)
+		}
+	}
+	checkPieces(t, "random", pt, txt)
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/mat32"
+)
+
+// ElasticBlock returns the range of lines, including st through ed, that
+// needs to be laid out together for elastic tabstops: extended through all
+// adjacent lines that contain tabs
+func (tv *TextView) ElasticBlock(st, ed int) (int, int) {
+	hasTab := func(ln int) bool {
+		for _, r := range tv.Buf.Line(ln) {
+			if r == '\t' {
+				return true
+			}
+		}
+		return false
+	}
+	for st > 0 && hasTab(st-1) {
+		st--
+	}
+	nln := tv.NLines
+	for ed < nln-1 && hasTab(ed+1) {
+		ed++
+	}
+	return st, ed
+}
+
+// LayoutElasticTabs adjusts the layout of lines st through ed (inclusive)
+// for elastic tabstops, where the tab-separated cells of adjacent lines are
+// aligned in columns (see textbuf.ElasticTabWidths) -- lines that are
+// wrapped are not included.  Returns the max width of the adjusted lines.
+func (tv *TextView) LayoutElasticTabs(st, ed int) float32 {
+	ch := tv.Sty.Font.Face.Metrics.Ch
+	cells := make([][]float32, ed-st+1)
+	for ln := st; ln <= ed; ln++ {
+		rn := tv.LineRender(ln)
+		if len(rn.Spans) != 1 {
+			continue
+		}
+		sp := &rn.Spans[0]
+		cst := float32(0)
+		for i, r := range sp.Text {
+			if r == '\t' && i < len(sp.Render) {
+				cells[ln-st] = append(cells[ln-st], sp.Render[i].RelPos.X-cst)
+				cst = sp.Render[i].RelPosAfterLR()
+			}
+		}
+	}
+	wds := textbuf.ElasticTabWidths(cells, ch, float32(tv.Sty.Text.TabSize)*ch)
+	mxwd := float32(0)
+	for ln := st; ln <= ed; ln++ {
+		cw := wds[ln-st]
+		if len(cw) == 0 {
+			continue
+		}
+		rn := tv.LineRender(ln)
+		sp := &rn.Spans[0]
+		shift := float32(0)
+		cst := float32(0)
+		c := 0
+		for i, r := range sp.Text {
+			rr := &sp.Render[i]
+			rr.RelPos.X += shift
+			if r == '\t' && c < len(cw) {
+				nxt := cst + cw[c]
+				shift += nxt - rr.RelPosAfterLR()
+				rr.Size.X = nxt - rr.RelPos.X
+				cst = nxt
+				c++
+			}
+		}
+		sp.LastPos.X += shift
+		rn.Size.X += shift
+		mxwd = mat32.Max(mxwd, rn.Size.X)
+	}
+	return mxwd
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"path/filepath"
+	"regexp"
+
+	"github.com/goki/gi/giv/textbuf"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//  TextBuf find / replace
+
+// FindAll returns all of the matches in the buffer for given find options,
+// or an error if the options do not compile into a valid regexp.
+// Column positions are in runes.
+func (tb *TextBuf) FindAll(fo *textbuf.FindOpts) ([]textbuf.Match, error) {
+	re, err := fo.Compile()
+	if err != nil {
+		return nil, err
+	}
+	_, matches := tb.SearchRegexp(re)
+	return matches, nil
+}
+
+// ReplaceMatch replaces the match of re at given region (in runes, as
+// returned by FindAll, adjusted for any intervening edits) with repl,
+// expanding capture group references if fo.Regexp is set.  The region must
+// be within one line.  Returns nil if re no longer matches there.
+func (tb *TextBuf) ReplaceMatch(reg textbuf.Region, re *regexp.Regexp, fo *textbuf.FindOpts, repl string) *textbuf.Edit {
+	ln := reg.Start.Ln
+	if !tb.IsValidLine(ln) || reg.End.Ln != ln {
+		return nil
+	}
+	tb.LinesMu.RLock()
+	rn := tb.LineImpl(ln)
+	if reg.Start.Ch > len(rn) || reg.End.Ch > len(rn) {
+		tb.LinesMu.RUnlock()
+		return nil
+	}
+	src := []byte(string(rn))
+	bst := len(string(rn[:reg.Start.Ch]))
+	bed := len(string(rn[:reg.End.Ch]))
+	tb.LinesMu.RUnlock()
+	for _, idx := range re.FindAllSubmatchIndex(src, -1) {
+		if idx[0] != bst || idx[1] != bed {
+			continue
+		}
+		rep := fo.Expand(re, src, idx, repl)
+		return tb.ReplaceText(reg.Start, reg.End, reg.Start, string(rep), EditSignal, ReplaceNoMatchCase)
+	}
+	return nil
+}
+
+// ReplaceAll replaces all of the matches for given find options with repl,
+// as one undoable action -- see ReplaceMatch.  Returns the number of
+// replacements.
+func (tb *TextBuf) ReplaceAll(fo *textbuf.FindOpts, repl string) (int, error) {
+	re, err := fo.Compile()
+	if err != nil {
+		return 0, err
+	}
+	_, matches := tb.SearchRegexp(re)
+	if len(matches) == 0 {
+		return 0, nil
+	}
+	bufUpdt, winUpdt, autoSave := tb.BatchUpdateStart()
+	cnt := 0
+	for mi := len(matches) - 1; mi >= 0; mi-- { // from end so positions are unaffected
+		if tb.ReplaceMatch(matches[mi].Reg, re, fo, repl) != nil {
+			cnt++
+		}
+	}
+	tb.Undos.NewGroup()
+	tb.BatchUpdateEnd(bufUpdt, winUpdt, autoSave)
+	return cnt, nil
+}
+
+// TextBufReplaceInFiles replaces all matches for given find options with
+// repl across a set of files, e.g., from textbuf.ProjectFiles.  If bufFor
+// is non-nil, it is called for each file to return any TextBuf that has the
+// file open, in which case the replacement is done in the buffer, so it can
+// be undone and is not overwritten by the buffer contents -- otherwise the
+// file is modified directly on disk.  Returns the total number of
+// replacements, and the first error encountered, if any, after attempting
+// all the files.
+func TextBufReplaceInFiles(files []string, fo *textbuf.FindOpts, repl string, bufFor func(fn string) *TextBuf) (int, error) {
+	re, err := fo.Compile()
+	if err != nil {
+		return 0, err
+	}
+	cnt := 0
+	var ferr error
+	for _, fn := range files {
+		var tb *TextBuf
+		if bufFor != nil {
+			if afn, err := filepath.Abs(fn); err == nil {
+				fn = afn
+			}
+			tb = bufFor(fn)
+		}
+		var n int
+		if tb != nil {
+			n, err = tb.ReplaceAll(fo, repl)
+		} else {
+			n, err = fo.ReplaceFile(re, fn, repl)
+		}
+		cnt += n
+		if err != nil && ferr == nil {
+			ferr = err
+		}
+	}
+	return cnt, ferr
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//  TextView find
+
+// FindMatchesOpts finds the matches for given find options, and updates
+// highlights for all of them -- returns false if none found, including if
+// the options are not valid, e.g., while a regexp is still being typed.
+func (tv *TextView) FindMatchesOpts(fo *textbuf.FindOpts) ([]textbuf.Match, bool) {
+	if fo.IsSimple() {
+		return tv.FindMatches(fo.Find, fo.UseCase, false)
+	}
+	matches, err := tv.Buf.FindAll(fo)
+	if err != nil || len(matches) == 0 {
+		tv.Highlights = nil
+		tv.RenderAllLines()
+		return nil, false
+	}
+	hi := make([]textbuf.Region, len(matches))
+	for i, m := range matches {
+		hi[i] = m.Reg
+		if i > TextViewMaxFindHighlights {
+			break
+		}
+	}
+	tv.Highlights = hi
+	tv.RenderAllLines()
+	return matches, true
+}
@@ -0,0 +1,259 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"image"
+
+	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/mat32"
+	"github.com/goki/pi/lex"
+)
+
+// TextFoldMarkers are the markers shown next to line numbers for the start
+// line of a fold region, for the unfolded and folded state respectively
+var TextFoldMarkers = [2]string{"▾", "▸"}
+
+///////////////////////////////////////////////////////////////////////////////
+//  TextBuf folding
+
+// UpdateFolds recomputes the Folds regions, from the syntax highlighting
+// tags if they contain group punctuation (braces etc), and otherwise from
+// indentation, preserving the folded state of existing regions.
+// Must be called under LinesMu and MarkupMu lock -- locks FoldsMu.
+func (tb *TextBuf) UpdateFolds() {
+	var fs textbuf.Folds
+	if tb.Opts.CodeFolding {
+		if textbuf.HasGroupTags(tb.HiTags) {
+			fs = textbuf.FoldsFromTags(tb.HiTags)
+		} else {
+			fs = textbuf.FoldsFromIndent(tb.Lines, tb.Opts.TabSize)
+		}
+	}
+	tb.FoldsMu.Lock()
+	fs.CopyFolded(tb.Folds)
+	tb.Folds = fs
+	tb.FoldsMu.Unlock()
+}
+
+// FoldsIfNeeded computes the Folds if they have not been yet, e.g., for
+// buffers without syntax highlighting, where markup does not update them.
+func (tb *TextBuf) FoldsIfNeeded() {
+	tb.FoldsMu.RLock()
+	has := tb.Folds != nil
+	tb.FoldsMu.RUnlock()
+	if !tb.Opts.CodeFolding || has || tb.NLines == 0 {
+		return
+	}
+	tb.LinesMu.RLock()
+	tb.MarkupMu.Lock()
+	tb.UpdateFolds()
+	tb.MarkupMu.Unlock()
+	tb.LinesMu.RUnlock()
+}
+
+// FoldSet sets the folded state of the innermost fold region containing
+// given line -- returns false if there is no such region or it is already
+// in that state.  Views are refreshed if there was a change.
+func (tb *TextBuf) FoldSet(ln int, folded bool) bool {
+	tb.FoldsIfNeeded()
+	tb.FoldsMu.Lock()
+	fi := tb.Folds.Innermost(ln)
+	if fi < 0 || tb.Folds[fi].Folded == folded {
+		tb.FoldsMu.Unlock()
+		return false
+	}
+	tb.Folds[fi].Folded = folded
+	tb.FoldsMu.Unlock()
+	tb.RefreshViews()
+	return true
+}
+
+// FoldToggle toggles the folded state of the innermost fold region
+// containing given line -- returns false if there is no such region.
+func (tb *TextBuf) FoldToggle(ln int) bool {
+	tb.FoldsIfNeeded()
+	tb.FoldsMu.RLock()
+	fi := tb.Folds.Innermost(ln)
+	folded := fi >= 0 && tb.Folds[fi].Folded
+	tb.FoldsMu.RUnlock()
+	if fi < 0 {
+		return false
+	}
+	return tb.FoldSet(ln, !folded)
+}
+
+// FoldCycle cycles the fold region containing given line through three
+// states: folded, unfolded with its directly nested regions folded, and
+// fully unfolded including all nested regions.
+func (tb *TextBuf) FoldCycle(ln int) bool {
+	tb.FoldsIfNeeded()
+	tb.FoldsMu.Lock()
+	fi := tb.Folds.Innermost(ln)
+	if fi < 0 {
+		tb.FoldsMu.Unlock()
+		return false
+	}
+	fd := &tb.Folds[fi]
+	ch := tb.Folds.Children(fi)
+	chFolded := false
+	for _, ci := range ch {
+		if tb.Folds[ci].Folded {
+			chFolded = true
+			break
+		}
+	}
+	switch {
+	case !fd.Folded && !chFolded:
+		fd.Folded = true
+	case fd.Folded && len(ch) > 0:
+		fd.Folded = false
+		for _, ci := range ch {
+			tb.Folds[ci].Folded = true
+		}
+	default:
+		fd.Folded = false
+		for i := fi + 1; i < len(tb.Folds) && tb.Folds[i].StLn <= fd.EdLn; i++ {
+			tb.Folds[i].Folded = false
+		}
+	}
+	tb.FoldsMu.Unlock()
+	tb.RefreshViews()
+	return true
+}
+
+// FoldAll sets all fold regions to given folded state
+func (tb *TextBuf) FoldAll(folded bool) {
+	tb.FoldsIfNeeded()
+	tb.FoldsMu.Lock()
+	tb.Folds.SetAll(folded)
+	tb.FoldsMu.Unlock()
+	tb.RefreshViews()
+}
+
+// UnfoldLine ensures that given line is not hidden within a folded region,
+// unfolding any regions that contain it -- returns true if anything was unfolded.
+func (tb *TextBuf) UnfoldLine(ln int) bool {
+	tb.FoldsMu.Lock()
+	got := false
+	for i := range tb.Folds {
+		fd := &tb.Folds[i]
+		if fd.StLn >= ln {
+			break
+		}
+		if fd.Folded && fd.Contains(ln) {
+			fd.Folded = false
+			got = true
+		}
+	}
+	tb.FoldsMu.Unlock()
+	if got {
+		tb.RefreshViews()
+	}
+	return got
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//  TextView folding
+
+// UpdateFoldHidden updates the FoldHidden lines from the buffer Folds
+func (tv *TextView) UpdateFoldHidden() {
+	tv.Buf.FoldsMu.RLock()
+	tv.FoldHidden = tv.Buf.Folds.Hidden(tv.NLines)
+	tv.Buf.FoldsMu.RUnlock()
+}
+
+// IsLineHidden returns true if given line is hidden within a folded region
+func (tv *TextView) IsLineHidden(ln int) bool {
+	return ln >= 0 && ln < len(tv.FoldHidden) && tv.FoldHidden[ln]
+}
+
+// LineSizeY returns the rendered height of given line, which is 0 for
+// lines hidden within a folded region, and always LineHeight in
+// large-file mode
+func (tv *TextView) LineSizeY(ln int) float32 {
+	if tv.IsLineHidden(ln) {
+		return 0
+	}
+	if tv.LargeRenders != nil {
+		return tv.LineHeight
+	}
+	return mat32.Max(tv.LineRender(ln).Size.Y, tv.LineHeight)
+}
+
+// FoldMarker returns the fold marker to display next to the line number for
+// given line, or empty string if no fold region starts there
+func (tv *TextView) FoldMarker(ln int) string {
+	if tv.Buf == nil || !tv.Buf.Opts.CodeFolding {
+		return ""
+	}
+	tv.Buf.FoldsMu.RLock()
+	defer tv.Buf.FoldsMu.RUnlock()
+	fi := tv.Buf.Folds.AtLine(ln)
+	if fi < 0 {
+		return ""
+	}
+	if tv.Buf.Folds[fi].Folded {
+		return TextFoldMarkers[1]
+	}
+	return TextFoldMarkers[0]
+}
+
+// FoldAtCursor folds the innermost region containing the cursor,
+// moving the cursor to the start line of that region
+func (tv *TextView) FoldAtCursor() {
+	if tv.Buf == nil {
+		return
+	}
+	ln := tv.CursorPos.Ln
+	if tv.Buf.FoldSet(ln, true) {
+		tv.FoldCursorToVisible()
+	}
+}
+
+// UnfoldAtCursor unfolds the innermost region containing the cursor
+func (tv *TextView) UnfoldAtCursor() {
+	if tv.Buf == nil {
+		return
+	}
+	tv.Buf.FoldSet(tv.CursorPos.Ln, false)
+}
+
+// FoldCycleAtCursor cycles the fold state of the region containing the
+// cursor -- see TextBuf.FoldCycle
+func (tv *TextView) FoldCycleAtCursor() {
+	if tv.Buf == nil {
+		return
+	}
+	if tv.Buf.FoldCycle(tv.CursorPos.Ln) {
+		tv.FoldCursorToVisible()
+	}
+}
+
+// FoldCursorToVisible moves the cursor up to the nearest line that is
+// not hidden within a folded region
+func (tv *TextView) FoldCursorToVisible() {
+	ln := tv.CursorPos.Ln
+	if !tv.IsLineHidden(ln) {
+		return
+	}
+	for ln > 0 && tv.IsLineHidden(ln) {
+		ln--
+	}
+	tv.SetCursorShow(lex.Pos{Ln: ln, Ch: tv.Buf.LineLen(ln)})
+}
+
+// FoldMarkerAt returns the line with a fold marker at given point relative
+// to the upper left of the view (as used in PixelToCursor), or -1 if the
+// point is not within the line number area on a fold start line
+func (tv *TextView) FoldMarkerAt(pt image.Point, pos lex.Pos) int {
+	if !tv.HasLineNos() || pt.X >= int(tv.LineNoOff) {
+		return -1
+	}
+	if tv.FoldMarker(pos.Ln) == "" {
+		return -1
+	}
+	return pos.Ln
+}
@@ -0,0 +1,707 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"unicode"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/ki/ints"
+	"github.com/goki/ki/kit"
+	"github.com/goki/pi/lex"
+)
+
+// TextViewKeyMode handles keys for a TextView editing mode (see
+// gi.EditModes), prior to the standard key processing
+type TextViewKeyMode interface {
+	// KeyInput is called for each key event, with the key function from the
+	// active KeyMap -- it can process the event itself (calling SetProcessed
+	// on it), or return the key function to use for standard processing
+	KeyInput(tv *TextView, kt *key.ChordEvent, kf gi.KeyFuns) gi.KeyFuns
+
+	// ModeName returns the name of the current state of the mode, e.g., for
+	// display in a status bar -- empty if there is nothing to show
+	ModeName() string
+}
+
+// TextViewKeyModes has the functions that create a new TextViewKeyMode for
+// each gi.EditModes value -- EditModeStd has none, and uses the standard
+// key processing.  Entries can be replaced to customize the modes.
+var TextViewKeyModes = map[gi.EditModes]func() TextViewKeyMode{
+	gi.EditModeVim:   func() TextViewKeyMode { return &VimKeyMode{} },
+	gi.EditModeEmacs: func() TextViewKeyMode { return &EmacsKeyMode{} },
+}
+
+// CurEditMode returns the editing mode in effect for this view: its own
+// EditMode if set, and otherwise the one from its buffer's editor prefs
+func (tv *TextView) CurEditMode() gi.EditModes {
+	if tv.EditMode != gi.EditModeDefault {
+		return tv.EditMode
+	}
+	if tv.Buf != nil && tv.Buf.Opts.EditMode != gi.EditModeDefault {
+		return tv.Buf.Opts.EditMode
+	}
+	return gi.EditModeStd
+}
+
+// SetEditMode sets the editing mode for this view, overriding the prefs --
+// EditModeDefault returns to using the prefs
+func (tv *TextView) SetEditMode(mode gi.EditModes) {
+	tv.EditMode = mode
+	tv.KeyMode = nil
+	tv.KeyModeSig()
+}
+
+// CurKeyMode returns the TextViewKeyMode for the current editing mode,
+// creating it as needed -- nil for standard key processing
+func (tv *TextView) CurKeyMode() TextViewKeyMode {
+	mode := tv.CurEditMode()
+	if tv.KeyMode != nil && tv.keyModeFor == mode {
+		return tv.KeyMode
+	}
+	tv.KeyMode = nil
+	tv.keyModeFor = mode
+	if fn, ok := TextViewKeyModes[mode]; ok && fn != nil {
+		tv.KeyMode = fn()
+	}
+	return tv.KeyMode
+}
+
+// KeyModeName returns the name of the current state of the editing mode,
+// e.g., NORMAL or INSERT for vim -- empty for standard mode
+func (tv *TextView) KeyModeName() string {
+	km := tv.CurKeyMode()
+	if km == nil {
+		return ""
+	}
+	return km.ModeName()
+}
+
+// KeyModeSig sends the TextViewEditMode signal, for a change in the editing
+// mode or its state
+func (tv *TextView) KeyModeSig() {
+	tv.TextViewSig.Emit(tv.This(), int64(TextViewEditMode), tv.KeyModeName())
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//  Emacs
+
+// EmacsKeyMode uses the emacs KeyMap for the platform (MacEmacs or
+// LinuxEmacs) in place of the active KeyMap, falling back on the active
+// KeyMap for chords it does not have
+type EmacsKeyMode struct {
+	Map *gi.KeyMap `desc:"the emacs key map"`
+}
+
+func (em *EmacsKeyMode) KeyInput(tv *TextView, kt *key.ChordEvent, kf gi.KeyFuns) gi.KeyFuns {
+	if em.Map == nil {
+		mapnm := gi.KeyMapName("LinuxEmacs")
+		if oswin.TheApp != nil && oswin.TheApp.Platform() == oswin.MacOS {
+			mapnm = "MacEmacs"
+		}
+		em.Map, _, _ = gi.AvailKeyMaps.MapByName(mapnm)
+		if em.Map == nil {
+			return kf
+		}
+	}
+	if ekf, ok := (*em.Map)[kt.Chord()]; ok {
+		return ekf
+	}
+	return kf
+}
+
+func (em *EmacsKeyMode) ModeName() string {
+	return "Emacs"
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//  Vim
+
+// VimStates are the states of the VimKeyMode
+type VimStates int32
+
+const (
+	// VimNormal is normal mode, where keys are commands
+	VimNormal VimStates = iota
+
+	// VimInsert is insert mode, where keys insert text as usual
+	VimInsert
+
+	// VimVisual is visual mode, where movement extends the selection
+	VimVisual
+
+	// VimVisualLine is visual mode selecting whole lines
+	VimVisualLine
+
+	VimStatesN
+)
+
+//go:generate stringer -type=VimStates
+
+var KiT_VimStates = kit.Enums.AddEnum(VimStatesN, kit.NotBitFlag, nil)
+
+// VimKeyMode is a modal subset of vim.  In normal and visual modes, keys
+// without Control, Meta or Alt are vim commands, supporting counts for
+// most commands:
+//
+//	movement: h j k l w b e 0 ^ $ gg G, Enter, Backspace
+//	insert: i a I A o O s C, and c with a motion or cc
+//	delete / yank: x X D J, and d, y with a motion, dd, yy
+//	paste: p P -- from the last yank or delete, otherwise the clipboard
+//	undo: u, Control+r
+//	visual: v V, then d x y c > < on the selection
+//	indent: >> <<
+//	search: / starts interactive search, n goes to the next match
+//
+// Escape returns to normal mode.  Other keys have their standard function.
+type VimKeyMode struct {
+	State    VimStates `desc:"current state"`
+	Count    int       `desc:"count typed so far for the next command -- 0 if none"`
+	Op       rune      `desc:"pending operator (d, c, y, g, >, <) waiting for its motion -- 0 if none"`
+	Reg      []byte    `desc:"text of the last yank or delete"`
+	LineWise bool      `desc:"Reg holds whole lines, which are pasted as lines"`
+}
+
+func (vm *VimKeyMode) ModeName() string {
+	switch vm.State {
+	case VimInsert:
+		return "INSERT"
+	case VimVisual:
+		return "VISUAL"
+	case VimVisualLine:
+		return "VISUAL LINE"
+	}
+	return "NORMAL"
+}
+
+// SetState sets the state, updating the selection as needed
+func (vm *VimKeyMode) SetState(tv *TextView, st VimStates) {
+	prv := vm.State
+	vm.State = st
+	vm.Count = 0
+	vm.Op = 0
+	switch st {
+	case VimVisual, VimVisualLine:
+		if prv != VimVisual && prv != VimVisualLine {
+			tv.SelectMode = true
+			tv.SelectStart = tv.CursorPos
+		}
+		vm.UpdateSelect(tv)
+	case VimInsert:
+		tv.Buf.Undos.NewGroup()
+		if prv == VimVisual || prv == VimVisualLine {
+			tv.SelectReset()
+		}
+	default:
+		if prv == VimVisual || prv == VimVisualLine {
+			tv.SelectReset()
+		}
+		if prv == VimInsert {
+			tv.Buf.Undos.NewGroup()
+		}
+	}
+	if prv != st {
+		tv.KeyModeSig()
+	}
+}
+
+// UpdateSelect updates the selection for the visual modes, after the
+// cursor has moved
+func (vm *VimKeyMode) UpdateSelect(tv *TextView) {
+	tv.SelectRegUpdate(tv.CursorPos)
+	if vm.State == VimVisualLine {
+		tv.SelectReg.Start.Ch = 0
+		tv.SelectReg.End = vm.LineEnd(tv, tv.SelectReg.End.Ln)
+	}
+	tv.RenderSelectLines()
+}
+
+// LineEnd returns the position after the end of given line, including
+// its newline if there is one
+func (vm *VimKeyMode) LineEnd(tv *TextView, ln int) lex.Pos {
+	if ln+1 < tv.Buf.NumLines() {
+		return lex.Pos{Ln: ln + 1}
+	}
+	return lex.Pos{Ln: ln, Ch: tv.Buf.LineLen(ln)}
+}
+
+// LinesRegion returns the region for n whole lines starting at given line
+// -- for the last lines of the buffer, the newline before them is included
+func (vm *VimKeyMode) LinesRegion(tv *TextView, ln, n int) textbuf.Region {
+	nln := tv.Buf.NumLines()
+	edln := ln + n - 1
+	if edln >= nln {
+		edln = nln - 1
+	}
+	reg := textbuf.Region{Start: lex.Pos{Ln: ln}, End: vm.LineEnd(tv, edln)}
+	if edln == nln-1 && ln > 0 {
+		reg.Start = lex.Pos{Ln: ln - 1, Ch: tv.Buf.LineLen(ln - 1)}
+	}
+	return reg
+}
+
+// Yank saves given text to Reg and the clipboard
+func (vm *VimKeyMode) Yank(tv *TextView, txt []byte, lines bool) {
+	if lines && (len(txt) == 0 || txt[len(txt)-1] != '\n') {
+		if len(txt) > 0 && txt[0] == '\n' { // region from end of prev line
+			txt = txt[1:]
+		}
+		txt = append(txt, '\n')
+	}
+	vm.Reg = txt
+	vm.LineWise = lines
+	TextViewClipHistAdd(txt)
+	if win := tv.ParentWindow(); win != nil {
+		oswin.TheApp.ClipBoard(win.OSWin).Write(mimedata.NewTextBytes(txt))
+	}
+}
+
+// YankRegion yanks given region
+func (vm *VimKeyMode) YankRegion(tv *TextView, reg textbuf.Region, lines bool) {
+	tbe := tv.Buf.Region(reg.Start, reg.End)
+	if tbe == nil {
+		return
+	}
+	vm.Yank(tv, tbe.ToBytes(), lines)
+}
+
+// DeleteRegion deletes given region, saving it to Reg, and puts the
+// cursor at its start
+func (vm *VimKeyMode) DeleteRegion(tv *TextView, reg textbuf.Region, lines bool) {
+	tbe := tv.Buf.DeleteText(reg.Start, reg.End, EditSignal)
+	if tbe == nil {
+		return
+	}
+	vm.Yank(tv, tbe.ToBytes(), lines)
+	pos := reg.Start
+	if lines && pos.Ch > 0 { // deleted the last lines
+		pos = lex.Pos{Ln: pos.Ln}
+	}
+	tv.SetCursorShow(pos)
+}
+
+// Put pastes Reg, or the clipboard if nothing has been yanked, after the
+// cursor (or line for whole lines), or before if before is true
+func (vm *VimKeyMode) Put(tv *TextView, before bool, n int) {
+	if vm.Reg == nil {
+		if !before {
+			vm.ForwardInLine(tv, 1)
+		}
+		for i := 0; i < n; i++ {
+			tv.Paste()
+		}
+		return
+	}
+	txt := make([]byte, 0, n*len(vm.Reg))
+	for i := 0; i < n; i++ {
+		txt = append(txt, vm.Reg...)
+	}
+	if !vm.LineWise {
+		if !before {
+			vm.ForwardInLine(tv, 1)
+		}
+		tv.InsertAtCursor(txt)
+		return
+	}
+	ln := tv.CursorPos.Ln
+	pos := lex.Pos{Ln: ln}
+	if !before {
+		if ln+1 < tv.Buf.NumLines() {
+			pos.Ln = ln + 1
+		} else { // after last line: newline goes first
+			pos.Ch = tv.Buf.LineLen(ln)
+			txt = append([]byte("\n"), txt[:len(txt)-1]...)
+		}
+	}
+	tv.Buf.InsertText(pos, txt, EditSignal)
+	if pos.Ch > 0 {
+		pos = lex.Pos{Ln: ln + 1}
+	}
+	tv.SetCursorShow(pos)
+}
+
+// ForwardInLine moves the cursor forward by up to n chars within the line
+func (vm *VimKeyMode) ForwardInLine(tv *TextView, n int) {
+	pos := tv.CursorPos
+	pos.Ch += n
+	if ll := tv.Buf.LineLen(pos.Ln); pos.Ch > ll {
+		pos.Ch = ll
+	}
+	tv.SetCursorShow(pos)
+}
+
+// FirstNonBlank returns the position of the first non-whitespace char in line
+func (vm *VimKeyMode) FirstNonBlank(tv *TextView, ln int) lex.Pos {
+	lr := tv.Buf.Line(ln)
+	ch := 0
+	for ch < len(lr) && unicode.IsSpace(lr[ch]) {
+		ch++
+	}
+	return lex.Pos{Ln: ln, Ch: ch}
+}
+
+// Motion moves the cursor for motion key r, n times, returning false if r
+// is not a motion, and whether the motion is line-wise (for operators)
+func (vm *VimKeyMode) Motion(tv *TextView, r rune, n int, counted bool) (ok, lines bool) {
+	switch r {
+	case 'h':
+		pos := tv.CursorPos
+		pos.Ch -= n
+		if pos.Ch < 0 {
+			pos.Ch = 0
+		}
+		tv.SetCursorShow(pos)
+	case 'l', ' ':
+		vm.ForwardInLine(tv, n)
+	case 'j':
+		tv.CursorDown(n)
+		return true, true
+	case 'k':
+		tv.CursorUp(n)
+		return true, true
+	case 'w', 'e':
+		tv.CursorForwardWord(n)
+	case 'b':
+		tv.CursorBackwardWord(n)
+	case '0':
+		tv.CursorStartLine()
+	case '^':
+		tv.SetCursorShow(vm.FirstNonBlank(tv, tv.CursorPos.Ln))
+	case '$':
+		if n > 1 {
+			tv.CursorDown(n - 1)
+		}
+		tv.CursorEndLine()
+	case 'G':
+		if counted {
+			tv.JumpToLine(n)
+		} else {
+			tv.CursorEndDoc()
+		}
+		return true, true
+	default:
+		return false, false
+	}
+	return true, false
+}
+
+// Operate applies operator op to the region from org to the cursor
+func (vm *VimKeyMode) Operate(tv *TextView, op rune, org lex.Pos, lines bool) {
+	st, ed := org, tv.CursorPos
+	if ed.IsLess(st) {
+		st, ed = ed, st
+	}
+	if lines {
+		vm.OperateLines(tv, op, st.Ln, ed.Ln)
+		return
+	}
+	reg := textbuf.Region{Start: st, End: ed}
+	switch op {
+	case 'd':
+		vm.DeleteRegion(tv, reg, false)
+	case 'c':
+		vm.DeleteRegion(tv, reg, false)
+		vm.SetState(tv, VimInsert)
+	case 'y':
+		vm.YankRegion(tv, reg, false)
+		tv.SetCursorShow(st)
+	case '>', '<':
+		vm.Indent(tv, st.Ln, ed.Ln, op == '>')
+	}
+}
+
+// OperateLines applies operator op to whole lines stln through edln
+func (vm *VimKeyMode) OperateLines(tv *TextView, op rune, stln, edln int) {
+	switch op {
+	case 'd':
+		vm.DeleteRegion(tv, vm.LinesRegion(tv, stln, edln-stln+1), true)
+	case 'c': // keeps the lines themselves
+		vm.YankRegion(tv, vm.LinesRegion(tv, stln, edln-stln+1), true)
+		reg := textbuf.Region{Start: lex.Pos{Ln: stln}, End: lex.Pos{Ln: edln, Ch: tv.Buf.LineLen(edln)}}
+		tv.Buf.DeleteText(reg.Start, reg.End, EditSignal)
+		tv.SetCursorShow(reg.Start)
+		if tv.Buf.Opts.AutoIndent {
+			_, _, cpos := tv.Buf.AutoIndent(stln)
+			tv.SetCursorShow(lex.Pos{Ln: stln, Ch: cpos})
+		}
+		vm.SetState(tv, VimInsert)
+	case 'y':
+		vm.YankRegion(tv, vm.LinesRegion(tv, stln, edln-stln+1), true)
+		tv.SetCursorShow(lex.Pos{Ln: stln, Ch: tv.CursorPos.Ch})
+	case '>', '<':
+		vm.Indent(tv, stln, edln, op == '>')
+	}
+}
+
+// Indent indents lines st through ed by one level, or unindents
+func (vm *VimKeyMode) Indent(tv *TextView, st, ed int, in bool) {
+	bufUpdt, winUpdt, autoSave := tv.Buf.BatchUpdateStart()
+	defer tv.Buf.BatchUpdateEnd(bufUpdt, winUpdt, autoSave)
+	for ln := st; ln <= ed; ln++ {
+		ind, _ := lex.LineIndent(tv.Buf.Line(ln), tv.Sty.Text.TabSize)
+		if in {
+			ind++
+		} else if ind > 0 {
+			ind--
+		}
+		tv.Buf.IndentLine(ln, ind)
+	}
+	tv.SetCursorShow(vm.FirstNonBlank(tv, st))
+}
+
+// JoinLines joins n lines after the cursor line onto it, separated by a space
+func (vm *VimKeyMode) JoinLines(tv *TextView, n int) {
+	bufUpdt, winUpdt, autoSave := tv.Buf.BatchUpdateStart()
+	defer tv.Buf.BatchUpdateEnd(bufUpdt, winUpdt, autoSave)
+	ln := tv.CursorPos.Ln
+	for i := 0; i < n && ln+1 < tv.Buf.NumLines(); i++ {
+		end := lex.Pos{Ln: ln, Ch: tv.Buf.LineLen(ln)}
+		nxt := vm.FirstNonBlank(tv, ln+1)
+		tv.Buf.DeleteText(end, nxt, EditSignal)
+		if end.Ch > 0 && end.Ch < tv.Buf.LineLen(ln) {
+			tv.Buf.InsertText(end, []byte(" "), EditSignal)
+		}
+		tv.SetCursorShow(end)
+	}
+}
+
+func (vm *VimKeyMode) KeyInput(tv *TextView, kt *key.ChordEvent, kf gi.KeyFuns) gi.KeyFuns {
+	if tv.ISearch.On || tv.QReplace.On || tv.IsInactive() {
+		return kf
+	}
+	if vm.State == VimInsert {
+		if kf == gi.KeyFunAbort {
+			kt.SetProcessed()
+			tv.CancelComplete()
+			vm.SetState(tv, VimNormal)
+			if tv.CursorPos.Ch > 0 {
+				tv.CursorBackward(1)
+			}
+		}
+		return kf
+	}
+	if kt.HasAnyModifier(key.Control, key.Meta, key.Alt) {
+		if kt.Rune == 'r' && kt.HasAllModifier(key.Control) {
+			kt.SetProcessed()
+			tv.Redo()
+		}
+		return kf
+	}
+	switch kf {
+	case gi.KeyFunAbort:
+		kt.SetProcessed()
+		vm.SetState(tv, VimNormal)
+		return kf
+	case gi.KeyFunEnter:
+		kt.SetProcessed()
+		tv.CursorDown(vm.TakeCount())
+		tv.SetCursorShow(vm.FirstNonBlank(tv, tv.CursorPos.Ln))
+		vm.UpdateVisual(tv)
+		return kf
+	case gi.KeyFunBackspace:
+		kt.SetProcessed()
+		tv.CursorBackward(vm.TakeCount())
+		vm.UpdateVisual(tv)
+		return kf
+	case gi.KeyFunFocusNext, gi.KeyFunFocusPrev:
+		kt.SetProcessed() // no tab insertion
+		return kf
+	}
+	if !unicode.IsPrint(kt.Rune) {
+		if vm.State != VimNormal {
+			defer vm.UpdateVisual(tv) // after standard movement
+		}
+		return kf
+	}
+	kt.SetProcessed()
+	vm.Command(tv, kt.Rune)
+	return kf
+}
+
+// UpdateVisual updates the selection if in a visual mode
+func (vm *VimKeyMode) UpdateVisual(tv *TextView) {
+	if vm.State == VimVisual || vm.State == VimVisualLine {
+		vm.UpdateSelect(tv)
+	}
+}
+
+// TakeCount returns the count for a command (1 if none), resetting it
+func (vm *VimKeyMode) TakeCount() int {
+	n := vm.Count
+	vm.Count = 0
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+// Command executes command key r, in normal or visual mode
+func (vm *VimKeyMode) Command(tv *TextView, r rune) {
+	if r >= '1' && r <= '9' || (r == '0' && vm.Count > 0) {
+		vm.Count = vm.Count*10 + int(r-'0')
+		return
+	}
+	counted := vm.Count > 0
+	n := vm.TakeCount()
+	visual := vm.State == VimVisual || vm.State == VimVisualLine
+	if op := vm.Op; op != 0 {
+		vm.Op = 0
+		org := tv.CursorPos
+		switch {
+		case op == 'g':
+			if r == 'g' {
+				if counted {
+					tv.JumpToLine(n)
+				} else {
+					tv.CursorStartDoc()
+				}
+				vm.UpdateVisual(tv)
+			}
+		case r == op: // dd, yy, cc, >>, <<
+			vm.OperateLines(tv, op, org.Ln, ints.MinInt(org.Ln+n-1, tv.Buf.NumLines()-1))
+		case op == 'g' || r == 'g':
+			vm.Op = r
+		default:
+			if r == 'w' && op == 'c' { // cw is ce
+				r = 'e'
+			}
+			if ok, lines := vm.Motion(tv, r, n, counted); ok {
+				vm.Operate(tv, op, org, lines)
+			}
+		}
+		return
+	}
+	if ok, _ := vm.Motion(tv, r, n, counted); ok {
+		vm.UpdateVisual(tv)
+		return
+	}
+	if visual {
+		vm.VisualCommand(tv, r)
+		return
+	}
+	tv.Buf.Undos.NewGroup()
+	defer tv.Buf.Undos.NewGroup()
+	pos := tv.CursorPos
+	ll := tv.Buf.LineLen(pos.Ln)
+	switch r {
+	case 'd', 'c', 'y', 'g', '>', '<':
+		vm.Op = r
+		vm.Count = n
+		if !counted {
+			vm.Count = 0
+		}
+	case 'i':
+		vm.SetState(tv, VimInsert)
+	case 'a':
+		vm.ForwardInLine(tv, 1)
+		vm.SetState(tv, VimInsert)
+	case 'I':
+		tv.SetCursorShow(vm.FirstNonBlank(tv, pos.Ln))
+		vm.SetState(tv, VimInsert)
+	case 'A':
+		tv.CursorEndLine()
+		vm.SetState(tv, VimInsert)
+	case 'o':
+		tv.CursorEndLine()
+		vm.SetState(tv, VimInsert)
+		tv.InsertAtCursor([]byte("\n"))
+		if tv.Buf.Opts.AutoIndent {
+			_, _, cpos := tv.Buf.AutoIndent(tv.CursorPos.Ln)
+			tv.SetCursorShow(lex.Pos{Ln: tv.CursorPos.Ln, Ch: cpos})
+		}
+	case 'O':
+		tv.SetCursorShow(lex.Pos{Ln: pos.Ln})
+		vm.SetState(tv, VimInsert)
+		tv.Buf.InsertText(tv.CursorPos, []byte("\n"), EditSignal)
+		tv.SetCursorShow(lex.Pos{Ln: pos.Ln})
+		if tv.Buf.Opts.AutoIndent {
+			_, _, cpos := tv.Buf.AutoIndent(pos.Ln)
+			tv.SetCursorShow(lex.Pos{Ln: pos.Ln, Ch: cpos})
+		}
+	case 'x':
+		ed := lex.Pos{Ln: pos.Ln, Ch: ints.MinInt(pos.Ch+n, ll)}
+		vm.DeleteRegion(tv, textbuf.Region{Start: pos, End: ed}, false)
+	case 'X':
+		st := lex.Pos{Ln: pos.Ln, Ch: pos.Ch - n}
+		if st.Ch < 0 {
+			st.Ch = 0
+		}
+		vm.DeleteRegion(tv, textbuf.Region{Start: st, End: pos}, false)
+	case 's':
+		ed := lex.Pos{Ln: pos.Ln, Ch: ints.MinInt(pos.Ch+n, ll)}
+		vm.DeleteRegion(tv, textbuf.Region{Start: pos, End: ed}, false)
+		vm.SetState(tv, VimInsert)
+	case 'D', 'C':
+		vm.DeleteRegion(tv, textbuf.Region{Start: pos, End: lex.Pos{Ln: pos.Ln, Ch: ll}}, false)
+		if r == 'C' {
+			vm.SetState(tv, VimInsert)
+		}
+	case 'J':
+		vm.JoinLines(tv, n)
+	case 'p':
+		vm.Put(tv, false, n)
+	case 'P':
+		vm.Put(tv, true, n)
+	case 'u':
+		for i := 0; i < n; i++ {
+			tv.Undo()
+		}
+	case 'v':
+		vm.SetState(tv, VimVisual)
+	case 'V':
+		vm.SetState(tv, VimVisualLine)
+	case '/':
+		tv.ISearchStart()
+	case 'n':
+		tv.ISearchStart()
+		tv.ISearchStart() // restores previous search, and goes to next match
+		tv.ISearchCancel()
+	}
+}
+
+// VisualCommand executes command key r on the selection, in a visual mode
+func (vm *VimKeyMode) VisualCommand(tv *TextView, r rune) {
+	lines := vm.State == VimVisualLine
+	reg := tv.SelectReg
+	switch r {
+	case 'v', 'V':
+		st := VimVisual
+		if r == 'V' {
+			st = VimVisualLine
+		}
+		if st == vm.State {
+			vm.SetState(tv, VimNormal)
+		} else {
+			vm.State = st
+			vm.UpdateSelect(tv)
+			tv.KeyModeSig()
+		}
+	case 'd', 'x', 'c', 's', 'y', '>', '<':
+		vm.SetState(tv, VimNormal)
+		op := r
+		switch r {
+		case 'x':
+			op = 'd'
+		case 's':
+			op = 'c'
+		}
+		if lines || op == '>' || op == '<' {
+			edln := reg.End.Ln
+			if reg.End.Ch == 0 && edln > reg.Start.Ln {
+				edln--
+			}
+			vm.OperateLines(tv, op, reg.Start.Ln, edln)
+			return
+		}
+		tv.SetCursorShow(reg.Start)
+		vm.Operate(tv, op, reg.End, false)
+	case 'g':
+		vm.Op = 'g'
+	}
+}
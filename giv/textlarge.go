@@ -0,0 +1,358 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bytes"
+	"image"
+	"os"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/goki/gi/girl"
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/ki/ints"
+	"github.com/goki/mat32"
+	"github.com/goki/pi/lex"
+)
+
+// TextBufLargeFileSize is the size in bytes at or above which text is
+// handled in large-file mode: the text is only held in a textbuf.PieceTable
+// that is updated with each edit, and none of the per-line Lines,
+// LineBytes, Markup, Tags or HiTags are kept -- each line is read from the
+// pieces when it is needed (see LineImpl), and views only lay out the
+// lines that are visible.  Syntax highlighting, spelling, completion,
+// code folding and language servers are turned off.
+var TextBufLargeFileSize = 32 * 1024 * 1024
+
+// IsLargeFile returns true if the buffer is in large-file mode -- see
+// TextBufLargeFileSize
+func (tb *TextBuf) IsLargeFile() bool {
+	return tb.Pieces != nil
+}
+
+// NewLarge initializes the buffer in large-file mode for the current Txt,
+// which is used directly by the piece table and not modified afterward --
+// the text is not split into lines.  A final newline is added if the text
+// does not end with one, so the number of lines is always the number of
+// newlines.
+func (tb *TextBuf) NewLarge() {
+	tb.Defaults()
+	tb.LinesMu.Lock()
+	tb.MarkupMu.Lock()
+	tb.Undos.Reset()
+	tb.Lines = nil
+	tb.LineBytes = nil
+	tb.Tags = nil
+	tb.HiTags = nil
+	tb.Markup = nil
+	tb.ByteOffs = nil
+	tb.FoldsMu.Lock()
+	tb.Folds = nil
+	tb.FoldsMu.Unlock()
+
+	tb.PiState.SetSrc(string(tb.Filename), "", tb.Info.Sup)
+	tb.Hi.Init(&tb.Info, &tb.PiState)
+	tb.ConfigLargeFile()
+	if tb.Txt[len(tb.Txt)-1] != '\n' {
+		tb.Pieces.Insert(tb.Pieces.Len(), []byte("\n"))
+	}
+	tb.NLines = tb.Pieces.NumNewlines()
+	tb.TotalBytes = tb.Pieces.Len()
+
+	tb.MarkupMu.Unlock()
+	tb.LinesMu.Unlock()
+	tb.Refresh()
+}
+
+// ConfigLargeFile puts the buffer in large-file mode for the current Txt,
+// which is used directly by the piece table and not modified afterward.
+// Must be called under LinesMu.Lock.
+func (tb *TextBuf) ConfigLargeFile() {
+	tb.Pieces = textbuf.NewPieceTable(tb.Txt)
+	tb.Hi.Has = false
+	tb.Hi.PiLang = nil
+	tb.DeleteSpell()
+	tb.DeleteCompleter()
+	tb.Opts.SpellCorrect = false
+	tb.Opts.Completion = false
+	tb.Opts.CodeFolding = false
+	tb.Opts.ElasticTabs = false
+}
+
+// LineImpl returns the runes of given line, which must be valid -- in
+// large-file mode this is a new copy read from Pieces, otherwise the line
+// in Lines.  Must be called under LinesMu lock.
+func (tb *TextBuf) LineImpl(ln int) []rune {
+	if tb.Pieces != nil {
+		return bytes.Runes(tb.Pieces.Line(ln))
+	}
+	return tb.Lines[ln]
+}
+
+// LineLenImpl returns the number of runes in given line, which must be
+// valid.  Must be called under LinesMu lock.
+func (tb *TextBuf) LineLenImpl(ln int) int {
+	if tb.Pieces != nil {
+		return utf8.RuneCount(tb.Pieces.Line(ln))
+	}
+	return len(tb.Lines[ln])
+}
+
+// BytesLineImpl returns the bytes of given line, which must be valid --
+// in large-file mode this is a new copy read from Pieces, otherwise the
+// line in LineBytes.  Must be called under LinesMu lock.
+func (tb *TextBuf) BytesLineImpl(ln int) []byte {
+	if tb.Pieces != nil {
+		return tb.Pieces.Line(ln)
+	}
+	return tb.LineBytes[ln]
+}
+
+// LinesRange returns the runes of the lines from st up to (not including)
+// ed, with empty tags for each, for functions that work on a slice of
+// lines in large-file mode, where the lines and tags are not kept.
+// Must be called under LinesMu lock.
+func (tb *TextBuf) LinesRange(st, ed int) ([][]rune, []lex.Line) {
+	st = ints.MaxInt(st, 0)
+	ed = ints.MinInt(ed, tb.NLines)
+	if ed <= st {
+		return nil, nil
+	}
+	lns := make([][]rune, ed-st)
+	for i := range lns {
+		lns[i] = tb.LineImpl(st + i)
+	}
+	return lns, make([]lex.Line, ed-st)
+}
+
+// PiecesOff returns the byte offset in Pieces of given position, which must
+// be valid.  Must be called under LinesMu lock.
+func (tb *TextBuf) PiecesOff(pos lex.Pos) int {
+	off := tb.Pieces.LineStart(pos.Ln)
+	lb := tb.Pieces.Line(pos.Ln)
+	for i := 0; i < pos.Ch && len(lb) > 0; i++ { // invalid bytes are one rune each, as in bytes.Runes
+		_, n := utf8.DecodeRune(lb)
+		lb = lb[n:]
+		off += n
+	}
+	return off
+}
+
+// InsertTextLarge does the raw insert of new text at given starting
+// position in large-file mode, where only Pieces is updated, returning a
+// new Edit with timestamp of Now.  Must be called under LinesMu.Lock.
+func (tb *TextBuf) InsertTextLarge(st lex.Pos, text []byte) *textbuf.Edit {
+	tb.Pieces.Insert(tb.PiecesOff(st), text)
+	tb.NLines = tb.Pieces.NumNewlines()
+	ed := st
+	if li := bytes.LastIndexByte(text, '\n'); li >= 0 {
+		ed.Ln += bytes.Count(text, []byte("\n"))
+		ed.Ch = utf8.RuneCount(text[li+1:])
+	} else {
+		ed.Ch += utf8.RuneCount(text)
+	}
+	return tb.RegionImpl(st, ed)
+}
+
+// DeleteTextLarge deletes the region between given positions from Pieces
+// in large-file mode.  Must be called under LinesMu.Lock.
+func (tb *TextBuf) DeleteTextLarge(st, ed lex.Pos) {
+	so := tb.PiecesOff(st)
+	tb.Pieces.Delete(so, tb.PiecesOff(ed)-so)
+	tb.NLines = tb.Pieces.NumNewlines()
+}
+
+// SetLineLarge replaces the text of given line in Pieces in large-file
+// mode, for edits within lines that are not made at a single position,
+// e.g., rectangular edits.  Must be called under LinesMu.Lock.
+func (tb *TextBuf) SetLineLarge(ln int, txt []rune) {
+	st := tb.Pieces.LineStart(ln)
+	tb.Pieces.Delete(st, tb.Pieces.LineEnd(ln)-st)
+	tb.Pieces.Insert(st, []byte(string(txt)))
+}
+
+// AddLinesLarge adds given number of blank lines at the end of Pieces in
+// large-file mode.  Must be called under LinesMu.Lock.
+func (tb *TextBuf) AddLinesLarge(n int) {
+	if n <= 0 {
+		return
+	}
+	tb.Pieces.Insert(tb.Pieces.Len(), bytes.Repeat([]byte("\n"), n))
+	tb.NLines = tb.Pieces.NumNewlines()
+}
+
+// SearchLarge looks for a string (no regexp) one line at a time in
+// large-file mode -- see Search.  Must be called under LinesMu lock.
+func (tb *TextBuf) SearchLarge(find []byte, ignoreCase bool) (int, []textbuf.Match) {
+	cnt := 0
+	var matches []textbuf.Match
+	for ln := 0; ln < tb.NLines; ln++ {
+		n, ms := textbuf.SearchRuneLines([][]rune{tb.LineImpl(ln)}, find, ignoreCase)
+		cnt += n
+		matches = appendLineMatches(matches, ms, ln)
+	}
+	return cnt, matches
+}
+
+// SearchRegexpLarge looks for a regexp one line at a time in large-file
+// mode -- see SearchRegexp.  Must be called under LinesMu lock.
+func (tb *TextBuf) SearchRegexpLarge(re *regexp.Regexp) (int, []textbuf.Match) {
+	cnt := 0
+	var matches []textbuf.Match
+	for ln := 0; ln < tb.NLines; ln++ {
+		n, ms := textbuf.SearchByteLinesRegexp([][]byte{tb.Pieces.Line(ln)}, re)
+		cnt += n
+		matches = appendLineMatches(matches, ms, ln)
+	}
+	return cnt, matches
+}
+
+// appendLineMatches appends the matches found in a single line, for line
+// 0, to given list, moved to given line
+func appendLineMatches(matches, ms []textbuf.Match, ln int) []textbuf.Match {
+	for _, m := range ms {
+		m.Reg.Start.Ln = ln
+		m.Reg.End.Ln = ln
+		matches = append(matches, m)
+	}
+	return matches
+}
+
+// SavePieces writes the text in Pieces to given file, without making a
+// copy of the text
+func (tb *TextBuf) SavePieces(filename string) error {
+	fp, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	tb.LinesMu.RLock()
+	_, err = tb.Pieces.WriteTo(fp)
+	tb.LinesMu.RUnlock()
+	if cerr := fp.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   TextView in large-file mode
+
+// IsLaidOut returns true if the lines have been laid out, in Renders, or
+// LargeRenders in large-file mode
+func (tv *TextView) IsLaidOut() bool {
+	return tv.Renders != nil || tv.LargeRenders != nil
+}
+
+// LineRender returns the render of given line -- in large-file mode the
+// line is laid out into LargeRenders the first time it is needed
+func (tv *TextView) LineRender(ln int) *girl.Text {
+	if tv.LargeRenders == nil {
+		return &tv.Renders[ln]
+	}
+	rn, ok := tv.LargeRenders[ln]
+	if !ok {
+		rn = &girl.Text{}
+		tv.LayoutLineLarge(ln, rn)
+		tv.LargeRenders[ln] = rn
+	}
+	return rn
+}
+
+// LineOff returns the starting offset for the top of given line -- in
+// large-file mode lines are not wrapped, so all are LineHeight high
+func (tv *TextView) LineOff(ln int) float32 {
+	if tv.LargeRenders == nil {
+		return tv.Offs[ln]
+	}
+	return float32(ln) * tv.LineHeight
+}
+
+// LayoutLineLarge lays out the render of given line in large-file mode,
+// without wrapping or highlighting
+func (tv *TextView) LayoutLineLarge(ln int, rn *girl.Text) {
+	sty := &tv.Sty
+	fst := sty.Font
+	fst.BgColor.SetColor(nil)
+	tsty := sty.Text
+	tsty.WhiteSpace = gist.WhiteSpacePre
+	rn.SetHTMLPre(HTMLEscapeBytes(tv.Buf.BytesLine(ln)), &fst, &sty.Text, &sty.UnContext, tv.CSS)
+	rn.LayoutStdLR(&tsty, &sty.Font, &sty.UnContext, tv.RenderSz)
+}
+
+// LayoutAllLinesLarge starts the layout of all lines in large-file mode,
+// where lines are only laid out when they are rendered (see LineRender):
+// the size of the lines comes from the number of lines and the longest
+// line, and returns whether it is different from what it was previously
+func (tv *TextView) LayoutAllLinesLarge(inLayout bool) bool {
+	tv.Renders = nil
+	tv.Offs = nil
+	tv.LargeRenders = make(map[int]*girl.Text)
+	tv.FoldHidden = nil
+	tv.HasLinks = false
+	tv.VisSizes()
+
+	tv.Buf.LinesMu.RLock()
+	mxln := tv.Buf.Pieces.MaxLineLen()
+	tv.Buf.LinesMu.RUnlock()
+	mxwd := mat32.Max(tv.RenderSz.X, float32(mxln)*tv.Sty.Font.Face.Metrics.Ch)
+	nwSz := tv.LinesSizeLarge(mxwd)
+	if inLayout {
+		tv.LinesSize = nwSz
+		return tv.SetSize()
+	}
+	return tv.ResizeIfNeeded(nwSz)
+}
+
+// LayoutLinesLarge re-does the layout of given range of lines (end is
+// *inclusive*) after an edit in large-file mode, dropping the renders
+// of any later lines if the number of lines has changed
+func (tv *TextView) LayoutLinesLarge(st, ed int) {
+	nln := tv.Buf.NumLines()
+	for ln := range tv.LargeRenders {
+		if ln >= st && (ln <= ed || nln != tv.NLines) {
+			delete(tv.LargeRenders, ln)
+		}
+	}
+	tv.NLines = nln
+	mxwd := float32(tv.LinesSize.X)
+	for ln := st; ln <= ed && ln < nln; ln++ {
+		mxwd = mat32.Max(mxwd, tv.LineRender(ln).Size.X)
+	}
+	tv.ResizeIfNeeded(tv.LinesSizeLarge(mxwd))
+}
+
+// LinesSizeLarge returns the size of all lines in large-file mode, for
+// given max width
+func (tv *TextView) LinesSizeLarge(mxwd float32) image.Point {
+	extraHalf := tv.LineHeight * 0.5 * float32(tv.VisSize.Y)
+	return mat32.Vec2{mxwd, float32(tv.NLines)*tv.LineHeight + extraHalf}.ToPointCeil()
+}
+
+// VisibleLinesLarge returns the range of lines that are visible within
+// the VpBBox in large-file mode, for given render start position, and
+// drops the renders of lines that are more than a screen away from them
+func (tv *TextView) VisibleLinesLarge(pos mat32.Vec2) (stln, edln int) {
+	if tv.NLines == 0 || tv.LineHeight <= 0 {
+		return -1, -1
+	}
+	stln = int((float32(tv.VpBBox.Min.Y) - pos.Y) / tv.LineHeight)
+	edln = int((float32(tv.VpBBox.Max.Y) - pos.Y) / tv.LineHeight)
+	stln = ints.MaxInt(stln, 0)
+	edln = ints.MinInt(edln, tv.NLines-1)
+	if edln < stln {
+		return -1, -1
+	}
+	nvis := edln - stln + 1
+	if len(tv.LargeRenders) > 4*nvis {
+		for ln := range tv.LargeRenders {
+			if ln < stln-nvis || ln > edln+nvis {
+				delete(tv.LargeRenders, ln)
+			}
+		}
+	}
+	return
+}
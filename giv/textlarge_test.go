@@ -0,0 +1,136 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/gi/histyle"
+	"github.com/goki/pi/lex"
+)
+
+// newTestBuf returns a new buffer with given text, in large-file mode if
+// large is true
+func newTestBuf(txt string, large bool) *TextBuf {
+	lsz := TextBufLargeFileSize
+	defer func() { TextBufLargeFileSize = lsz }()
+	TextBufLargeFileSize = len(txt) + 1
+	if large {
+		TextBufLargeFileSize = 1
+	}
+	tb := &TextBuf{}
+	tb.InitName(tb, "test-buf")
+	tb.Hi.Style = histyle.StyleDefault // no styles without an app
+	tb.SetText([]byte(txt))
+	return tb
+}
+
+// checkLargeBuf checks that the large-file buffer has the same text as the
+// regular one
+func checkLargeBuf(t *testing.T, name string, lb, tb *TextBuf) {
+	t.Helper()
+	if lb.Lines != nil || lb.LineBytes != nil || lb.Markup != nil || lb.Tags != nil || lb.HiTags != nil {
+		t.Errorf("%s: large-file buffer has per-line data", name)
+	}
+	if got, want := lb.Strings(false), tb.Strings(false); !reflect.DeepEqual(got, want) {
+		t.Errorf("%s: lines: %q != expected: %q", name, got, want)
+		return
+	}
+	for ln := 0; ln < tb.NumLines(); ln++ {
+		if got, want := string(lb.Line(ln)), string(tb.Line(ln)); got != want {
+			t.Errorf("%s: line %d: %q != expected: %q", name, ln, got, want)
+		}
+		if got, want := lb.LineLen(ln), tb.LineLen(ln); got != want {
+			t.Errorf("%s: line %d len: %d != expected: %d", name, ln, got, want)
+		}
+	}
+	if got, want := string(lb.LinesToBytesCopy()), string(tb.LinesToBytesCopy()); got != want {
+		t.Errorf("%s: text: %q != expected: %q", name, got, want)
+	}
+	if got, want := lb.EndPos(), tb.EndPos(); got != want {
+		t.Errorf("%s: end pos: %v != expected: %v", name, got, want)
+	}
+}
+
+// checkMatches checks that the search matches have the same regions and
+// text, ignoring the region times
+func checkMatches(t *testing.T, name string, lm, tm []textbuf.Match) {
+	t.Helper()
+	if len(lm) != len(tm) {
+		t.Errorf("%s: %d matches != expected: %d", name, len(lm), len(tm))
+		return
+	}
+	for i := range tm {
+		if lm[i].Reg.Start != tm[i].Reg.Start || lm[i].Reg.End != tm[i].Reg.End || string(lm[i].Text) != string(tm[i].Text) {
+			t.Errorf("%s: match %d: %v %q != expected: %v %q", name, i, lm[i].Reg, lm[i].Text, tm[i].Reg, tm[i].Text)
+		}
+	}
+}
+
+func TestTextBufLarge(t *testing.T) {
+	txt := "package main\n\nfunc main() {\n\tfmt.Println(\"héllo\")\n}\n"
+	lb := newTestBuf(txt, true)
+	tb := newTestBuf(txt, false)
+	if !lb.IsLargeFile() || tb.IsLargeFile() {
+		t.Fatalf("large-file mode: %v, %v", lb.IsLargeFile(), tb.IsLargeFile())
+	}
+	checkLargeBuf(t, "open", lb, tb)
+
+	edits := []struct {
+		name string
+		edit func(b *TextBuf)
+	}{
+		{"insert", func(b *TextBuf) { b.InsertText(lex.Pos{Ln: 3, Ch: 15}, []byte("é, w"), EditSignal) }},
+		{"insert lines", func(b *TextBuf) { b.InsertText(lex.Pos{Ln: 2, Ch: 4}, []byte("x\ny\n\nz"), EditSignal) }},
+		{"delete", func(b *TextBuf) { b.DeleteText(lex.Pos{Ln: 6, Ch: 2}, lex.Pos{Ln: 6, Ch: 7}, EditSignal) }},
+		{"delete lines", func(b *TextBuf) { b.DeleteText(lex.Pos{Ln: 1, Ch: 0}, lex.Pos{Ln: 4, Ch: 1}, EditSignal) }},
+		{"append", func(b *TextBuf) { b.AppendTextLine([]byte("// end"), EditSignal) }},
+		{"delete rect", func(b *TextBuf) { b.DeleteTextRect(lex.Pos{Ln: 0, Ch: 1}, lex.Pos{Ln: 2, Ch: 3}, EditSignal) }},
+		{"insert rect", func(b *TextBuf) {
+			tbe := &textbuf.Edit{Reg: textbuf.NewRegion(1, 2, 2, 4), Rect: true, Text: [][]rune{[]rune("ab"), []rune("cd")}}
+			b.InsertTextRect(tbe, EditSignal)
+		}},
+		{"insert rect past end", func(b *TextBuf) {
+			ln := b.NumLines() - 1
+			tbe := &textbuf.Edit{Reg: textbuf.NewRegion(ln, 0, ln+2, 1), Rect: true, Text: [][]rune{[]rune("e"), []rune("f"), []rune("g")}}
+			b.InsertTextRect(tbe, EditSignal)
+		}},
+		{"undo", func(b *TextBuf) { b.Undo() }},
+		{"undo all", func(b *TextBuf) {
+			for b.Undo() != nil {
+			}
+		}},
+		{"redo", func(b *TextBuf) { b.Redo() }},
+	}
+	for _, ed := range edits {
+		ed.edit(lb)
+		ed.edit(tb)
+		checkLargeBuf(t, ed.name, lb, tb)
+	}
+
+	st, ed := lex.Pos{Ln: 0, Ch: 2}, lex.Pos{Ln: 2, Ch: 1}
+	if got, want := string(lb.Region(st, ed).ToBytes()), string(tb.Region(st, ed).ToBytes()); got != want {
+		t.Errorf("region: %q != expected: %q", got, want)
+	}
+	_, lm := lb.Search([]byte("N"), true, false)
+	_, tm := tb.Search([]byte("N"), true, false)
+	checkMatches(t, "search", lm, tm)
+	re := regexp.MustCompile(`[a-z]+\(`)
+	_, lm = lb.SearchRegexp(re)
+	_, tm = tb.SearchRegexp(re)
+	checkMatches(t, "search regexp", lm, tm)
+}
+
+func TestTextBufLargeNoFinalNewline(t *testing.T) {
+	lb := newTestBuf("ab\ncd", true)
+	tb := newTestBuf("ab\ncd", false)
+	checkLargeBuf(t, "open", lb, tb)
+	lb.InsertText(lb.EndPos(), []byte("\n"), EditSignal)
+	tb.InsertText(tb.EndPos(), []byte("\n"), EditSignal)
+	checkLargeBuf(t, "newline at end", lb, tb)
+}
@@ -0,0 +1,450 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv/lsp"
+	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/gi/histyle"
+	"github.com/goki/ki/ints"
+	"github.com/goki/pi/complete"
+	"github.com/goki/pi/filecat"
+	"github.com/goki/pi/lex"
+	"github.com/goki/pi/token"
+)
+
+// Language server support: a TextBuf can be bound to a language server
+// for its language (see TextBufLSPServers) using LSPStart, which then
+// provides completion and lookup (hover and definition) in place of the
+// Pi-based ones, and diagnostics that are shown using the histyle.LintErr
+// and LintWarn tags.  Servers are shared by all buffers for the same
+// language and root directory.
+
+// TextBufLSPServers are the language servers to use for each language --
+// the command must be on the PATH.
+var TextBufLSPServers = map[filecat.Supported]lsp.Server{
+	filecat.Go:     {Cmd: "gopls", LangID: "go"},
+	filecat.C:      {Cmd: "clangd", LangID: "cpp"},
+	filecat.Python: {Cmd: "pylsp", LangID: "python"},
+	filecat.Rust:   {Cmd: "rust-analyzer", LangID: "rust"},
+}
+
+// TextBufLSPDelayMSec is the number of milliseconds after the last edit to
+// wait before sending the new text to the language server
+var TextBufLSPDelayMSec = 500
+
+var (
+	// lspClients are the running language servers, keyed by command and root
+	lspClients = map[string]*lsp.Client{}
+
+	// lspBufs are the buffers bound to language servers, keyed by URI
+	lspBufs = map[string]*TextBuf{}
+
+	// lspMu protects lspClients and lspBufs
+	lspMu sync.Mutex
+)
+
+// TextBufLSPClient returns the running language server for given language
+// and root directory, starting it if it is not already running
+func TextBufLSPClient(sup filecat.Supported, root string) (*lsp.Client, error) {
+	srv, ok := TextBufLSPServers[sup]
+	if !ok {
+		return nil, fmt.Errorf("giv.TextBuf: no language server for: %v", sup)
+	}
+	key := srv.Cmd + ":" + root
+	lspMu.Lock()
+	cl, has := lspClients[key]
+	lspMu.Unlock()
+	if has && cl.IsRunning() {
+		return cl, nil
+	}
+	// starting waits for the initialize handshake, so it is done without the
+	// lock, which the diagnostics of other servers need meanwhile
+	ncl, err := lsp.Start(srv, root, textBufLSPDiagnostics)
+	if err != nil {
+		return nil, err
+	}
+	lspMu.Lock()
+	defer lspMu.Unlock()
+	if cl, has := lspClients[key]; has && cl.IsRunning() { // started by another caller meanwhile
+		go ncl.Shutdown()
+		return cl, nil
+	}
+	lspClients[key] = ncl
+	return ncl, nil
+}
+
+// TextBufLSPShutdown shuts down all running language servers
+func TextBufLSPShutdown() {
+	lspMu.Lock()
+	defer lspMu.Unlock()
+	for key, cl := range lspClients {
+		cl.Shutdown()
+		delete(lspClients, key)
+	}
+}
+
+// textBufLSPDiagnostics routes published diagnostics to the buffer
+func textBufLSPDiagnostics(pd *lsp.PublishDiagnosticsParams) {
+	lspMu.Lock()
+	tb, has := lspBufs[pd.URI]
+	lspMu.Unlock()
+	if has {
+		tb.LSPSetDiagnostics(pd.Diagnostics)
+	}
+}
+
+// LSPURI returns the URI used for this buffer in the language server
+func (tb *TextBuf) LSPURI() string {
+	return lsp.FileURI(string(tb.Filename))
+}
+
+// LSPStart binds this buffer to the language server for its language,
+// using given root directory for the workspace (the directory of the file
+// if empty).  Completion and lookup then use the server.
+func (tb *TextBuf) LSPStart(root string) error {
+	if tb.Filename == "" {
+		return errors.New("giv.TextBuf: LSPStart: buffer has no file name")
+	}
+	if tb.IsLargeFile() {
+		return errors.New("giv.TextBuf: LSPStart: not available in large-file mode")
+	}
+	if root == "" {
+		root = filepath.Dir(string(tb.Filename))
+	}
+	cl, err := TextBufLSPClient(tb.Info.Sup, root)
+	if err != nil {
+		return err
+	}
+	tb.LSPStop()
+	uri := tb.LSPURI()
+	lspMu.Lock()
+	lspBufs[uri] = tb
+	lspMu.Unlock()
+	tb.LSP = cl
+	tb.LSPVersion = 1
+	if err := cl.DidOpen(uri, tb.LSPVersion, string(tb.LinesToBytesCopy())); err != nil {
+		return err
+	}
+	tb.SetCompleter(tb, CompleteLSP, CompleteEditLSP, LookupLSP)
+	return nil
+}
+
+// LSPStop unbinds this buffer from its language server, if bound
+func (tb *TextBuf) LSPStop() {
+	if tb.LSP == nil {
+		return
+	}
+	tb.LSPMu.Lock()
+	if tb.LSPTimer != nil {
+		tb.LSPTimer.Stop()
+		tb.LSPTimer = nil
+	}
+	tb.LSPMu.Unlock()
+	uri := tb.LSPURI()
+	tb.LSP.DidClose(uri)
+	lspMu.Lock()
+	if lspBufs[uri] == tb {
+		delete(lspBufs, uri)
+	}
+	lspMu.Unlock()
+	tb.LSP = nil
+}
+
+// LSPChanged starts the timer for sending the new text to the language
+// server -- called whenever the buffer is changed
+func (tb *TextBuf) LSPChanged() {
+	if tb.LSP == nil {
+		return
+	}
+	tb.LSPMu.Lock()
+	defer tb.LSPMu.Unlock()
+	if tb.LSPTimer != nil {
+		tb.LSPTimer.Stop()
+	}
+	tb.LSPTimer = time.AfterFunc(time.Duration(TextBufLSPDelayMSec)*time.Millisecond, func() {
+		tb.LSPSync()
+	})
+}
+
+// LSPSync sends the current text to the language server if there are
+// changes that have not yet been sent
+func (tb *TextBuf) LSPSync() {
+	if tb.LSP == nil {
+		return
+	}
+	tb.LSPMu.Lock()
+	if tb.LSPTimer == nil {
+		tb.LSPMu.Unlock()
+		return
+	}
+	tb.LSPTimer.Stop()
+	tb.LSPTimer = nil
+	tb.LSPVersion++
+	ver := tb.LSPVersion
+	tb.LSPMu.Unlock()
+	tb.LSP.DidChange(tb.LSPURI(), ver, string(tb.LinesToBytesCopy()))
+}
+
+// LSPSaved notifies the language server that the file has been saved
+func (tb *TextBuf) LSPSaved() {
+	if tb.LSP == nil {
+		return
+	}
+	tb.LSPSync()
+	tb.LSP.DidSave(tb.LSPURI())
+}
+
+// LSPPos returns the language server position for given text position
+func (tb *TextBuf) LSPPos(pos lex.Pos) lsp.Position {
+	tb.LinesMu.RLock()
+	defer tb.LinesMu.RUnlock()
+	if pos.Ln < 0 || pos.Ln >= tb.NLines {
+		return lsp.Position{Line: pos.Ln, Character: pos.Ch}
+	}
+	return lsp.Position{Line: pos.Ln, Character: lsp.RuneToUTF16(tb.LineImpl(pos.Ln), pos.Ch)}
+}
+
+// LSPTextPos returns the text position for given language server position
+func (tb *TextBuf) LSPTextPos(lp lsp.Position) lex.Pos {
+	tb.LinesMu.RLock()
+	defer tb.LinesMu.RUnlock()
+	if lp.Line < 0 || lp.Line >= tb.NLines {
+		return lex.Pos{Ln: lp.Line, Ch: lp.Character}
+	}
+	return lex.Pos{Ln: lp.Line, Ch: lsp.UTF16ToRune(tb.LineImpl(lp.Line), lp.Character)}
+}
+
+// lspLocPos returns the position in text (runes) of the start of given
+// location, converting from the UTF-16 characters of the protocol using
+// the line of the buffer for the location if bound to the server, and
+// otherwise the line of the file, which is opened for this
+func lspLocPos(loc lsp.Location) lex.Pos {
+	lp := loc.Range.Start
+	lspMu.Lock()
+	tb, has := lspBufs[loc.URI]
+	lspMu.Unlock()
+	if has {
+		return tb.LSPTextPos(lp)
+	}
+	pos := lex.Pos{Ln: lp.Line, Ch: lp.Character}
+	txt, err := textbuf.FileBytes(lsp.URIFile(loc.URI))
+	if err != nil {
+		return pos
+	}
+	lns := bytes.Split(txt, []byte("\n"))
+	if lp.Line < len(lns) {
+		pos.Ch = lsp.UTF16ToRune(bytes.Runes(lns[lp.Line]), lp.Character)
+	}
+	return pos
+}
+
+// LSPSetDiagnostics sets the diagnostics from the language server, replacing
+// any existing LintErr and LintWarn tags with new ones for the diagnostics
+func (tb *TextBuf) LSPSetDiagnostics(diags []lsp.Diagnostic) {
+	regs := make([]textbuf.Region, len(diags))
+	for i, dg := range diags {
+		regs[i] = textbuf.Region{Start: tb.LSPTextPos(dg.Range.Start), End: tb.LSPTextPos(dg.Range.End)}
+	}
+	tb.LinesMu.RLock()
+	tb.MarkupMu.Lock()
+	tb.Diagnostics = diags
+	nln := ints.MinInt(len(tb.Tags), tb.NLines) // no tags in large-file mode
+	for ln := 0; ln < nln; ln++ {
+		if len(tb.Tags[ln]) == 0 {
+			continue
+		}
+		tgs := tb.AdjustedTags(ln)
+		tgs.DeleteToken(histyle.LintErr)
+		tgs.DeleteToken(histyle.LintWarn)
+		tb.Tags[ln] = tgs
+	}
+	for i, dg := range diags {
+		var tag token.Tokens
+		switch dg.Severity {
+		case lsp.SeverityError, 0: // unspecified is treated as an error
+			tag = histyle.LintErr
+		case lsp.SeverityWarning, lsp.SeverityInformation:
+			tag = histyle.LintWarn
+		default:
+			continue
+		}
+		reg := regs[i]
+		for ln := reg.Start.Ln; ln <= reg.End.Ln && ln < nln; ln++ {
+			if ln < 0 {
+				continue
+			}
+			sz := len(tb.Lines[ln])
+			st, ed := 0, sz
+			if ln == reg.Start.Ln {
+				st = reg.Start.Ch
+			}
+			if ln == reg.End.Ln {
+				ed = reg.End.Ch
+			}
+			if ed <= st { // empty range: mark the word or char at start
+				ed = st + 1
+				for ed < sz && isWordRune(tb.Lines[ln][ed]) {
+					ed++
+				}
+			}
+			if st >= sz {
+				if sz == 0 {
+					continue
+				}
+				st, ed = sz-1, sz
+			}
+			if ed > sz {
+				ed = sz
+			}
+			tr := lex.NewLex(token.KeyToken{Tok: tag}, st, ed)
+			tr.Time.Now()
+			tb.Tags[ln].AddSort(tr)
+		}
+	}
+	tb.MarkupMu.Unlock()
+	tb.LinesMu.RUnlock()
+	tb.ReMarkup()
+}
+
+// isWordRune returns true if r is a letter, digit or underscore
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// DiagnosticAt returns the language server diagnostic at given position,
+// if any -- positions are as of the last diagnostics received
+func (tb *TextBuf) DiagnosticAt(pos lex.Pos) (lsp.Diagnostic, bool) {
+	tb.MarkupMu.RLock()
+	diags := tb.Diagnostics
+	tb.MarkupMu.RUnlock()
+	for _, dg := range diags {
+		st := tb.LSPTextPos(dg.Range.Start)
+		ed := tb.LSPTextPos(dg.Range.End)
+		if pos.IsLess(st) || ed.IsLess(pos) {
+			continue
+		}
+		return dg, true
+	}
+	return lsp.Diagnostic{}, false
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//  Completion and Lookup
+
+// CompleteLSP gets completions from the language server -- data must be
+// the *TextBuf, bound to a server with LSPStart
+func CompleteLSP(data interface{}, text string, posLn, posCh int) (md complete.Matches) {
+	tb, ok := data.(*TextBuf)
+	if !ok || tb.LSP == nil {
+		return md
+	}
+	tb.LSPSync()
+	items, err := tb.LSP.Completion(tb.LSPURI(), tb.LSPPos(lex.Pos{Ln: posLn, Ch: posCh}))
+	if err != nil {
+		return md
+	}
+	md.Seed = gi.CompleteSeedIdent(text)
+	comps := make(complete.Completions, 0, len(items))
+	for i := range items {
+		it := &items[i]
+		desc := it.Detail
+		if doc := it.DocText(); doc != "" {
+			desc += "\n" + doc
+		}
+		comps = append(comps, complete.Completion{Text: it.Text(), Label: it.Label, Icon: it.Kind.IconName(), Desc: desc})
+	}
+	md.Matches = gi.CompleteFuzzyRank(comps, md.Seed)
+	if len(md.Matches) > gi.CompleteMaxItems {
+		md.Matches = md.Matches[:gi.CompleteMaxItems]
+	}
+	return md
+}
+
+// CompleteEditLSP uses the selected completion to edit the text
+func CompleteEditLSP(data interface{}, text string, cursorPos int, comp complete.Completion, seed string) (ed complete.Edit) {
+	return CompleteEditIdent(data, text, cursorPos, comp, seed)
+}
+
+// LookupLSP looks up the symbol at given position using the language
+// server, showing its definition if found, and otherwise its hover
+// documentation -- data must be the *TextBuf, bound to a server with LSPStart
+func LookupLSP(data interface{}, text string, posLn, posCh int) (ld complete.Lookup) {
+	tb, ok := data.(*TextBuf)
+	if !ok || tb.LSP == nil {
+		return ld
+	}
+	tb.LSPSync()
+	uri := tb.LSPURI()
+	lpos := tb.LSPPos(lex.Pos{Ln: posLn, Ch: posCh})
+	hov, _ := tb.LSP.Hover(uri, lpos)
+	locs, err := tb.LSP.Definition(uri, lpos)
+	if err == nil && len(locs) > 0 {
+		loc := locs[0]
+		fn := lsp.URIFile(loc.URI)
+		ld.SetFile(fn, loc.Range.Start.Line, loc.Range.End.Line)
+		txt := textbuf.FileRegionBytes(fn, ld.StLine, ld.EdLine, true, 10) // comments, 10 lines back max
+		prmpt := fmt.Sprintf("%v [%d:%d]", fn, ld.StLine, ld.EdLine)
+		TextViewDialog(nil, txt, DlgOpts{Title: "Lookup: " + text, Prompt: prmpt, Filename: fn, LineNos: true, Data: prmpt})
+		return ld
+	}
+	if hov != "" {
+		ld.Text = []byte(hov)
+		TextViewDialog(nil, ld.Text, DlgOpts{Title: "Lookup: " + text})
+	}
+	return ld
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//  TextView
+
+// LSPDefinition finds the definition of the symbol at the cursor using the
+// language server, moving the cursor there if it is within this buffer.
+// Returns the file and position of the definition, and false if not found.
+func (tv *TextView) LSPDefinition() (gi.FileName, lex.Pos, bool) {
+	tb := tv.Buf
+	if tb == nil || tb.LSP == nil {
+		return "", lex.PosZero, false
+	}
+	tb.LSPSync()
+	locs, err := tb.LSP.Definition(tb.LSPURI(), tb.LSPPos(tv.CursorPos))
+	if err != nil || len(locs) == 0 {
+		return "", lex.PosZero, false
+	}
+	loc := locs[0]
+	fn := gi.FileName(lsp.URIFile(loc.URI))
+	if loc.URI != tb.LSPURI() {
+		return fn, lspLocPos(loc), true
+	}
+	pos := tb.LSPTextPos(loc.Range.Start)
+	tv.SetCursorShow(pos)
+	tv.SavePosHistory(pos)
+	return fn, pos, true
+}
+
+// LSPDiagnosticAtCursor returns the message of any language server
+// diagnostic at the cursor, e.g., for display in a status bar
+func (tv *TextView) LSPDiagnosticAtCursor() string {
+	if tv.Buf == nil {
+		return ""
+	}
+	dg, ok := tv.Buf.DiagnosticAt(tv.CursorPos)
+	if !ok {
+		return ""
+	}
+	if dg.Source != "" {
+		return dg.Source + ": " + dg.Message
+	}
+	return dg.Message
+}
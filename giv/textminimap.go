@@ -0,0 +1,255 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"image/color"
+	"unicode"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ints"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+	"github.com/goki/pi/lex"
+)
+
+// TextMinimap is a miniature overview of the full text of a TextView, drawn
+// with each character as a small block colored according to the current
+// syntax highlighting style, with the region visible in the view
+// highlighted.  Clicking or dragging in the minimap scrolls the view to
+// that location.  Typically placed to the right of the TextView's layout.
+type TextMinimap struct {
+	gi.WidgetBase
+	View       *TextView `json:"-" xml:"-" desc:"the text view that this is a minimap for -- use SetView to set"`
+	LineHeight float32   `xml:"line-dots" desc:"height of each line in dots -- set from line-dots property"`
+	CharWidth  float32   `xml:"char-dots" desc:"width of each character in dots -- set from char-dots property"`
+	StLn       int       `json:"-" xml:"-" desc:"first line shown in the minimap, when the full text does not fit"`
+}
+
+var KiT_TextMinimap = kit.Types.AddType(&TextMinimap{}, TextMinimapProps)
+
+// AddNewTextMinimap adds a new minimap to given parent node, with given name.
+func AddNewTextMinimap(parent ki.Ki, name string) *TextMinimap {
+	return parent.AddNewChild(KiT_TextMinimap, name).(*TextMinimap)
+}
+
+var TextMinimapProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"width":            units.NewCh(12),
+	"min-width":        units.NewCh(8),
+	"height":           units.NewEm(10),
+	"max-height":       -1,
+	"line-dots":        2,
+	"char-dots":        1,
+	"background-color": &gi.Prefs.Colors.Background,
+}
+
+// SetView sets the text view that this is a minimap for, and connects to
+// its signals to update whenever the view is rendered
+func (mm *TextMinimap) SetView(tv *TextView) {
+	if mm.View != nil {
+		mm.View.TextViewSig.Disconnect(mm.This())
+	}
+	mm.View = tv
+	if tv != nil {
+		tv.TextViewSig.Connect(mm.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mmv := recv.Embed(KiT_TextMinimap).(*TextMinimap)
+			switch TextViewSignals(sig) {
+			case TextViewRendered, TextViewCursorMoved:
+				mmv.UpdateSig()
+			}
+		})
+	}
+	mm.UpdateSig()
+}
+
+// VisLines returns the number of lines that fit in the minimap
+func (mm *TextMinimap) VisLines() int {
+	return int(float32(mm.VpBBox.Dy()) / mm.LineHeight)
+}
+
+// ViewRange returns the first and last lines visible in the view
+func (mm *TextMinimap) ViewRange() (st, ed int) {
+	tv := mm.View
+	if tv.NLines == 0 {
+		return 0, 0
+	}
+	st = tv.FirstVisibleLine(0)
+	ed = tv.LastVisibleLine(st)
+	return
+}
+
+// UpdateStLn updates the first line shown in the minimap, scrolling in
+// proportion to the view when the full text does not fit
+func (mm *TextMinimap) UpdateStLn() {
+	nvis := mm.VisLines()
+	nln := mm.View.NLines
+	if nln <= nvis || nvis <= 0 {
+		mm.StLn = 0
+		return
+	}
+	st, ed := mm.ViewRange()
+	nview := ed - st + 1
+	if nln <= nview {
+		mm.StLn = 0
+		return
+	}
+	frac := float32(st) / float32(nln-nview)
+	mm.StLn = ints.MinInt(int(frac*float32(nln-nvis)), nln-nvis)
+}
+
+// LineAtPos returns the line at given y position in window coordinates
+func (mm *TextMinimap) LineAtPos(y int) int {
+	ln := mm.StLn + int(float32(y-mm.WinBBox.Min.Y)/mm.LineHeight)
+	return ints.MaxInt(0, ints.MinInt(ln, mm.View.NLines-1))
+}
+
+// ScrollToLine scrolls the view to put given line at the center
+func (mm *TextMinimap) ScrollToLine(ln int) {
+	tv := mm.View
+	if tv == nil || tv.NLines == 0 {
+		return
+	}
+	cpos := tv.CharStartPos(lex.Pos{Ln: ln})
+	tv.ScrollToVertCenter(int(cpos.Y + 0.5*tv.LineHeight))
+}
+
+// RenderMinimap renders the text as blocks of color
+func (mm *TextMinimap) RenderMinimap() {
+	rs, pc, sty := mm.RenderLock()
+	defer mm.RenderUnlock(rs)
+	pos := mat32.NewVec2FmPoint(mm.VpBBox.Min)
+	sz := mat32.NewVec2FmPoint(mm.VpBBox.Size())
+	tv := mm.View
+	bg := &sty.Font.BgColor
+	if tv != nil {
+		bg = &tv.Sty.Font.BgColor
+	}
+	pc.FillBox(rs, pos, sz, bg)
+	if tv == nil || tv.Buf == nil || tv.NLines == 0 {
+		return
+	}
+	mm.UpdateStLn()
+
+	// visible region of the view
+	fg := tv.Sty.Font.Color
+	vst, ved := mm.ViewRange()
+	vy := pos.Y + float32(vst-mm.StLn)*mm.LineHeight
+	pc.FillBoxColor(rs, mat32.Vec2{pos.X, vy}, mat32.Vec2{sz.X, float32(ved-vst+1) * mm.LineHeight}, fg.Clearer(85))
+
+	buf := tv.Buf
+	tabSz := ints.MaxInt(buf.Opts.TabSize, 1)
+	hs := buf.Hi.HiStyle
+	nvis := mm.VisLines()
+	buf.LinesMu.RLock()
+	buf.MarkupMu.RLock()
+	edln := ints.MinInt(mm.StLn+nvis+1, buf.NLines)
+	for ln := mm.StLn; ln < edln; ln++ {
+		if tv.IsLineHidden(ln) {
+			continue
+		}
+		y := pos.Y + float32(ln-mm.StLn)*mm.LineHeight
+		txt := buf.LineImpl(ln)
+		var tags lex.Line
+		if ln < len(buf.HiTags) {
+			tags = buf.HiTags[ln]
+		}
+		col := 0
+		ti := 0
+		for ci, r := range txt {
+			if r == '\t' {
+				col = (col/tabSz + 1) * tabSz
+				continue
+			}
+			x := pos.X + float32(col)*mm.CharWidth
+			col++
+			if x >= pos.X+sz.X {
+				break
+			}
+			if unicode.IsSpace(r) {
+				continue
+			}
+			var clr color.Color = fg
+			for ti < len(tags) && tags[ti].Ed <= ci {
+				ti++
+			}
+			if hs != nil && ti < len(tags) && tags[ti].St <= ci {
+				se := hs.Tag(tags[ti].Tok.Tok)
+				if !se.Color.IsNil() {
+					clr = se.Color
+				}
+			}
+			pc.FillBoxColor(rs, mat32.Vec2{x, y}, mat32.Vec2{mm.CharWidth, mm.LineHeight * 0.75}, clr)
+		}
+	}
+	buf.MarkupMu.RUnlock()
+	buf.LinesMu.RUnlock()
+}
+
+// MouseEvent scrolls the view to the location of the mouse
+func (mm *TextMinimap) MouseEvent() {
+	mm.ConnectEvent(oswin.MouseEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.Event)
+		mmv := recv.Embed(KiT_TextMinimap).(*TextMinimap)
+		if mmv.View == nil || me.Button != mouse.Left || me.Action != mouse.Press {
+			return
+		}
+		me.SetProcessed()
+		mmv.ScrollToLine(mmv.LineAtPos(me.Pos().Y))
+	})
+	mm.ConnectEvent(oswin.MouseDragEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.DragEvent)
+		mmv := recv.Embed(KiT_TextMinimap).(*TextMinimap)
+		if mmv.View == nil {
+			return
+		}
+		me.SetProcessed()
+		mmv.ScrollToLine(mmv.LineAtPos(me.Pos().Y))
+	})
+}
+
+func (mm *TextMinimap) Style2D() {
+	mm.Style2DWidget()
+	if lh, ok := mm.PropInherit("line-dots", ki.NoInherit, ki.TypeProps); ok {
+		if fv, ok := kit.ToFloat32(lh); ok {
+			mm.LineHeight = fv
+		}
+	}
+	if cw, ok := mm.PropInherit("char-dots", ki.NoInherit, ki.TypeProps); ok {
+		if fv, ok := kit.ToFloat32(cw); ok {
+			mm.CharWidth = fv
+		}
+	}
+	mm.LineHeight = mat32.Max(mm.LineHeight, 1)
+	mm.CharWidth = mat32.Max(mm.CharWidth, 0.5)
+}
+
+func (mm *TextMinimap) Render2D() {
+	if mm.FullReRenderIfNeeded() {
+		return
+	}
+	if mm.PushBounds() {
+		mm.This().(gi.Node2D).ConnectEvents2D()
+		mm.RenderMinimap()
+		mm.PopBounds()
+	} else {
+		mm.DisconnectAllEvents(gi.RegPri)
+	}
+}
+
+func (mm *TextMinimap) ConnectEvents2D() {
+	mm.MouseEvent()
+}
+
+func (mm *TextMinimap) Disconnect() {
+	mm.WidgetBase.Disconnect()
+	if mm.View != nil {
+		mm.View.TextViewSig.Disconnect(mm.This())
+	}
+}
@@ -0,0 +1,485 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bytes"
+	"sort"
+	"unicode"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/ki/ints"
+	"github.com/goki/mat32"
+	"github.com/goki/pi/filecat"
+	"github.com/goki/pi/lex"
+)
+
+// Multiple cursors (carets): in addition to the main CursorPos and SelectReg,
+// the TextView can have any number of additional Carets, each of which is
+// a region that is edited in parallel with the main cursor: typing,
+// backspace, delete, cut, copy and paste all apply to every caret, and
+// are undone as a single step.  A Caret with Start == End has no
+// selection, and the cursor position of a caret is always its End.
+// Alt+click adds a caret, SelectNext adds a caret at the next occurrence
+// of the selected text, and Shift+Alt+click or drag makes a column
+// (rectangular) selection, with one caret per line.
+
+///////////////////////////////////////////////////////////////////////////////
+//  Caret management
+
+// HasCarets returns true if there are additional carets beyond the main cursor
+func (tv *TextView) HasCarets() bool {
+	return len(tv.Carets) > 0
+}
+
+// CaretsReset removes all the additional carets, re-rendering if there were any
+func (tv *TextView) CaretsReset() {
+	if !tv.HasCarets() {
+		return
+	}
+	tv.Carets = nil
+	tv.RenderAllLines()
+}
+
+// MainCaret returns the main cursor and selection as a caret region
+func (tv *TextView) MainCaret() textbuf.Region {
+	if tv.HasSelection() {
+		reg := tv.SelectReg
+		if tv.CursorPos == reg.Start { // selection extends backward from cursor
+			return textbuf.Region{Start: reg.End, End: reg.Start}
+		}
+		return reg
+	}
+	return textbuf.Region{Start: tv.CursorPos, End: tv.CursorPos}
+}
+
+// caretStart returns the lower of the start / end positions of caret
+func caretStart(reg textbuf.Region) lex.Pos {
+	if reg.End.IsLess(reg.Start) {
+		return reg.End
+	}
+	return reg.Start
+}
+
+// caretEnd returns the higher of the start / end positions of caret
+func caretEnd(reg textbuf.Region) lex.Pos {
+	if reg.End.IsLess(reg.Start) {
+		return reg.Start
+	}
+	return reg.End
+}
+
+// caretSel returns the caret as a normal region with Start <= End
+func caretSel(reg textbuf.Region) textbuf.Region {
+	return textbuf.Region{Start: caretStart(reg), End: caretEnd(reg)}
+}
+
+// AllCarets returns the main caret and all additional carets, sorted in
+// order of position within the buffer with duplicates removed, along
+// with the index of the main caret in that list
+func (tv *TextView) AllCarets() ([]textbuf.Region, int) {
+	main := tv.MainCaret()
+	crs := make([]textbuf.Region, 0, len(tv.Carets)+1)
+	crs = append(crs, main)
+	for _, cr := range tv.Carets {
+		cr.Start = tv.Buf.ValidPos(cr.Start)
+		cr.End = tv.Buf.ValidPos(cr.End)
+		crs = append(crs, cr)
+	}
+	sort.SliceStable(crs, func(i, j int) bool {
+		si, sj := caretStart(crs[i]), caretStart(crs[j])
+		return si.IsLess(sj)
+	})
+	nc := crs[:0]
+	mi := 0
+	for _, cr := range crs {
+		if len(nc) > 0 && caretStart(nc[len(nc)-1]) == caretStart(cr) {
+			if cr == main {
+				nc[len(nc)-1] = cr
+				mi = len(nc) - 1
+			}
+			continue
+		}
+		if cr == main {
+			mi = len(nc)
+		}
+		nc = append(nc, cr)
+	}
+	return nc, mi
+}
+
+// SetCarets sets the carets from given list, with the caret at given index
+// becoming the main cursor and selection
+func (tv *TextView) SetCarets(crs []textbuf.Region, mi int) {
+	wupdt := tv.TopUpdateStart()
+	defer tv.TopUpdateEnd(wupdt)
+	tv.Carets = nil
+	for i, cr := range crs {
+		if i == mi {
+			continue
+		}
+		tv.Carets = append(tv.Carets, cr)
+	}
+	main := crs[mi]
+	tv.SelectReset()
+	if main.Start != main.End {
+		tv.SelectStart = main.Start
+		tv.SelectRegUpdate(main.End)
+	}
+	tv.SetCursorShow(main.End)
+	tv.SetCursorCol(tv.CursorPos)
+	tv.RenderAllLines()
+}
+
+// CaretAdd adds a new caret at given position -- if there is already a
+// caret there, it is removed instead
+func (tv *TextView) CaretAdd(pos lex.Pos) {
+	if tv.Buf == nil {
+		return
+	}
+	pos = tv.Buf.ValidPos(pos)
+	if pos == tv.CursorPos {
+		return
+	}
+	for i, cr := range tv.Carets {
+		if cr.End == pos {
+			tv.Carets = append(tv.Carets[:i], tv.Carets[i+1:]...)
+			tv.RenderAllLines()
+			return
+		}
+	}
+	tv.Carets = append(tv.Carets, textbuf.Region{Start: pos, End: pos})
+	tv.RenderAllLines()
+}
+
+// SelectNext selects the next occurrence of the currently-selected text,
+// adding a caret for the current selection, so that the selections can all
+// be edited together.  If nothing is selected, the word at the cursor is
+// selected.  Returns false if no further occurrence was found.
+func (tv *TextView) SelectNext() bool {
+	if tv.Buf == nil {
+		return false
+	}
+	wupdt := tv.TopUpdateStart()
+	defer tv.TopUpdateEnd(wupdt)
+	if !tv.HasSelection() {
+		if tv.SelectWord() {
+			tv.SetCursorShow(tv.SelectReg.End)
+			tv.RenderSelectLines()
+			return true
+		}
+		return false
+	}
+	sel := tv.Selection()
+	if sel == nil || sel.Reg.Start.Ln != sel.Reg.End.Ln { // only single-line
+		return false
+	}
+	_, matches := tv.Buf.Search(sel.ToBytes(), false, false)
+	if len(matches) == 0 {
+		return false
+	}
+	crs, _ := tv.AllCarets()
+	taken := func(reg textbuf.Region) bool {
+		for _, cr := range crs {
+			if caretStart(cr) == reg.Start {
+				return true
+			}
+		}
+		return false
+	}
+	// first untaken match after the last caret, wrapping around to the start
+	last := caretEnd(crs[len(crs)-1])
+	nxt := -1
+	for i, m := range matches {
+		if !m.Reg.Start.IsLess(last) && !taken(m.Reg) {
+			nxt = i
+			break
+		}
+	}
+	if nxt < 0 {
+		for i, m := range matches {
+			if !taken(m.Reg) {
+				nxt = i
+				break
+			}
+		}
+	}
+	if nxt < 0 {
+		return false
+	}
+	crs = append(crs, matches[nxt].Reg)
+	tv.SetCarets(crs, len(crs)-1)
+	return true
+}
+
+// ColumnSelect makes a column (rectangular) selection between the two
+// given positions, with one caret per line, each selecting the characters
+// between the two column positions (limited to the length of the line)
+func (tv *TextView) ColumnSelect(st, ed lex.Pos) {
+	if tv.Buf == nil {
+		return
+	}
+	st = tv.Buf.ValidPos(st)
+	ed = tv.Buf.ValidPos(ed)
+	stCh := ints.MinInt(st.Ch, ed.Ch)
+	edCh := ints.MaxInt(st.Ch, ed.Ch)
+	stLn := ints.MinInt(st.Ln, ed.Ln)
+	edLn := ints.MaxInt(st.Ln, ed.Ln)
+	crs := make([]textbuf.Region, 0, edLn-stLn+1)
+	mi := 0
+	for ln := stLn; ln <= edLn; ln++ {
+		sz := tv.Buf.LineLen(ln)
+		cr := textbuf.Region{Start: lex.Pos{Ln: ln, Ch: ints.MinInt(stCh, sz)}, End: lex.Pos{Ln: ln, Ch: ints.MinInt(edCh, sz)}}
+		if ln == ed.Ln {
+			mi = len(crs)
+		}
+		crs = append(crs, cr)
+	}
+	tv.SetCarets(crs, mi)
+	tv.SelectStart = st
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//  Editing
+
+// CaretsEdit applies the given edit function to each caret, including the
+// main one, from the end of the buffer backward so that each edit does not
+// affect the positions of the remaining carets.  The edit function returns
+// the new cursor position for that caret, and the edits it made, which are
+// used to adjust the positions of the carets already edited.  All the edits
+// are grouped as a single undo step.
+func (tv *TextView) CaretsEdit(fun func(idx int, reg textbuf.Region) (lex.Pos, []*textbuf.Edit)) {
+	if tv.Buf == nil {
+		return
+	}
+	crs, mi := tv.AllCarets()
+	wupdt := tv.TopUpdateStart()
+	defer tv.TopUpdateEnd(wupdt)
+	bufUpdt, winUpdt, autoSave := tv.Buf.BatchUpdateStart()
+	tv.Buf.Undos.NewGroup()
+	npos := make([]lex.Pos, len(crs))
+	for i := len(crs) - 1; i >= 0; i-- {
+		pos, tbes := fun(i, crs[i])
+		npos[i] = pos
+		for _, tbe := range tbes {
+			for j := i + 1; j < len(crs); j++ {
+				npos[j] = tbe.AdjustPos(npos[j], textbuf.AdjustPosDelStart)
+			}
+		}
+	}
+	tv.Buf.Undos.NewGroup()
+	tv.Buf.BatchUpdateEnd(bufUpdt, winUpdt, autoSave)
+	for i := range crs {
+		crs[i] = textbuf.Region{Start: npos[i], End: npos[i]}
+	}
+	tv.SetCarets(crs, mi)
+}
+
+// CaretsInsert inserts given text at each caret, replacing any selected
+// text.  If the text has the same number of lines as there are carets
+// (e.g., from a column selection copy), then each line is inserted at
+// the corresponding caret (block paste), otherwise the full text is
+// inserted at each caret.
+func (tv *TextView) CaretsInsert(txt []byte) {
+	ncr := len(tv.Carets) + 1
+	var lns [][]byte
+	if bytes.IndexByte(txt, '\n') >= 0 {
+		lns = bytes.Split(bytes.TrimSuffix(txt, []byte("\n")), []byte("\n"))
+		if len(lns) != ncr {
+			lns = nil
+		}
+	}
+	tv.CaretsEdit(func(idx int, reg textbuf.Region) (lex.Pos, []*textbuf.Edit) {
+		var tbes []*textbuf.Edit
+		sel := caretSel(reg)
+		pos := sel.Start
+		if sel.Start != sel.End {
+			if tbe := tv.Buf.DeleteText(sel.Start, sel.End, EditSignal); tbe != nil {
+				tbes = append(tbes, tbe)
+			}
+		}
+		itxt := txt
+		if lns != nil {
+			itxt = lns[idx]
+		}
+		if len(itxt) == 0 {
+			return pos, tbes
+		}
+		tbe := tv.Buf.InsertText(pos, itxt, EditSignal)
+		if tbe == nil {
+			return pos, tbes
+		}
+		return tbe.Reg.End, append(tbes, tbe)
+	})
+}
+
+// CaretsDelete deletes the selected text at each caret, or the character
+// before (if backward) or after each caret that has no selection
+func (tv *TextView) CaretsDelete(backward bool) {
+	tv.CaretsEdit(func(idx int, reg textbuf.Region) (lex.Pos, []*textbuf.Edit) {
+		sel := caretSel(reg)
+		if sel.Start == sel.End {
+			if backward {
+				if sel.Start.Ch > 0 {
+					sel.Start.Ch--
+				} else if sel.Start.Ln > 0 {
+					sel.Start = lex.Pos{Ln: sel.Start.Ln - 1, Ch: tv.Buf.LineLen(sel.Start.Ln - 1)}
+				}
+			} else {
+				if sel.End.Ch < tv.Buf.LineLen(sel.End.Ln) {
+					sel.End.Ch++
+				} else if sel.End.Ln < tv.Buf.NumLines()-1 {
+					sel.End = lex.Pos{Ln: sel.End.Ln + 1}
+				}
+			}
+		}
+		if sel.Start == sel.End {
+			return sel.Start, nil
+		}
+		tbe := tv.Buf.DeleteText(sel.Start, sel.End, EditSignal)
+		if tbe == nil {
+			return sel.Start, nil
+		}
+		return sel.Start, []*textbuf.Edit{tbe}
+	})
+}
+
+// CaretsCopy returns the selected text of each caret, one line per caret,
+// and copies it to the clipboard, if any carets have a selection
+func (tv *TextView) CaretsCopy() []byte {
+	crs, _ := tv.AllCarets()
+	var buf bytes.Buffer
+	got := false
+	for i, cr := range crs {
+		sel := caretSel(cr)
+		if sel.Start != sel.End {
+			got = true
+			if tbe := tv.Buf.Region(sel.Start, sel.End); tbe != nil {
+				buf.Write(tbe.ToBytes())
+			}
+		}
+		if i < len(crs)-1 {
+			buf.WriteByte('\n')
+		}
+	}
+	if !got {
+		return nil
+	}
+	cb := buf.Bytes()
+	TextViewClipHistAdd(cb)
+	oswin.TheApp.ClipBoard(tv.ParentWindow().OSWin).Write(mimedata.NewTextBytes(cb))
+	return cb
+}
+
+// CaretsPaste inserts the clipboard text at each caret -- see CaretsInsert
+func (tv *TextView) CaretsPaste() {
+	data := oswin.TheApp.ClipBoard(tv.ParentWindow().OSWin).Read([]string{filecat.TextPlain})
+	if data != nil {
+		tv.CaretsInsert(data.TypeData(filecat.TextPlain))
+	}
+}
+
+// CaretsKeyInput handles key input when there are multiple carets, applying
+// edits to all carets.  Any other key function (e.g., cursor movement)
+// removes the additional carets and is then processed as usual.
+// Returns true if the key was processed here.
+func (tv *TextView) CaretsKeyInput(kt *key.ChordEvent, kf gi.KeyFuns) bool {
+	if kf == gi.KeyFunAbort {
+		kt.SetProcessed()
+		tv.CaretsReset()
+		return true
+	}
+	if kf == gi.KeyFunSelectNext {
+		kt.SetProcessed()
+		tv.SelectNext()
+		return true
+	}
+	if kf == gi.KeyFunCopy {
+		kt.SetProcessed()
+		tv.CaretsCopy()
+		return true
+	}
+	if tv.IsInactive() {
+		tv.CaretsReset()
+		return false
+	}
+	switch kf {
+	case gi.KeyFunBackspace:
+		kt.SetProcessed()
+		tv.CaretsDelete(true)
+	case gi.KeyFunDelete:
+		kt.SetProcessed()
+		tv.CaretsDelete(false)
+	case gi.KeyFunCut:
+		kt.SetProcessed()
+		if tv.CaretsCopy() != nil {
+			tv.CaretsInsert(nil)
+		}
+	case gi.KeyFunPaste:
+		kt.SetProcessed()
+		tv.CaretsPaste()
+	case gi.KeyFunEnter:
+		if kt.HasAnyModifier(key.Control, key.Meta) {
+			return false
+		}
+		kt.SetProcessed()
+		tv.CaretsInsert([]byte("\n"))
+	case gi.KeyFunUndo, gi.KeyFunRedo:
+		tv.CaretsReset()
+		return false
+	case gi.KeyFunNil:
+		if !tv.IsPrintKey(kt) {
+			return false
+		}
+		kt.SetProcessed()
+		tv.CaretsInsert([]byte(string(kt.Rune)))
+	default:
+		tv.CaretsReset()
+		return false
+	}
+	return true
+}
+
+// IsPrintKey returns true if the key event is a printable character without
+// a Control or Meta modifier, which is inserted into the text
+func (tv *TextView) IsPrintKey(kt *key.ChordEvent) bool {
+	return unicode.IsPrint(kt.Rune) && !kt.HasAnyModifier(key.Control, key.Meta)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//  Rendering
+
+// RenderCarets renders the selections and cursors for the additional carets
+// -- always called within context of outer RenderLines or RenderAllLines
+func (tv *TextView) RenderCarets() {
+	if !tv.HasCarets() {
+		return
+	}
+	rs := tv.Render()
+	pc := &rs.Paint
+	clr := tv.StateStyles[TextViewActive].Font.Color
+	wd := mat32.Max(tv.CursorWidth.Dots, 2)
+	for _, cr := range tv.Carets {
+		sel := tv.Buf.AdjustReg(caretSel(cr))
+		if !sel.IsNil() && sel.Start != sel.End {
+			tv.RenderRegionBox(sel, TextViewSel)
+		}
+		spos := tv.CharStartPos(tv.Buf.ValidPos(cr.End))
+		pc.FillBoxColor(rs, spos, mat32.Vec2{wd, tv.FontHeight}, clr)
+	}
+}
+
+// ColumnSelectMouse handles Shift+Alt mouse clicks and drags for column
+// selection, from the SelectStart position to the mouse position
+func (tv *TextView) ColumnSelectMouse(newPos lex.Pos, start bool) {
+	if start && !tv.HasSelection() && !tv.HasCarets() {
+		tv.SelectStart = tv.CursorPos
+	}
+	tv.ColumnSelect(tv.SelectStart, newPos)
+}
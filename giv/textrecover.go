@@ -0,0 +1,224 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/ki/ki"
+)
+
+// Crash recovery: in addition to the Autosave option, which writes a
+// #filename# file next to the file after every change, TextBuf periodically
+// saves a recovery journal for buffers with unsaved changes, in the
+// recovery subdirectory of the app prefs directory, at the interval given by
+// RecoverySecs in the Editor prefs.  The journal is removed whenever the
+// buffer is saved, reverted or closed without saving, so any journal that
+// remains when a file is opened must be from a crash, and the user is
+// offered the chance to restore it.
+
+// TextBufRecoveryDirName is the name of the subdirectory within the app
+// prefs directory where recovery journal files are saved
+var TextBufRecoveryDirName = "recovery"
+
+// TextBufRecoveryHeader is the first line of a recovery journal file,
+// followed by the name of the file that it is for
+var TextBufRecoveryHeader = "# gogi-recovery: "
+
+// TextBufRecoveryDir returns the directory where recovery journal files are
+// saved, ensuring that it exists
+func TextBufRecoveryDir() string {
+	rdir := filepath.Join(oswin.TheApp.AppPrefsDir(), TextBufRecoveryDirName)
+	os.MkdirAll(rdir, 0755)
+	return rdir
+}
+
+// TextBufRecoveryFiles returns the recovery journal files that exist in the
+// recovery directory, mapped to the file names that they are for -- this
+// can be used by an app on startup to offer recovery of all buffers,
+// including unnamed ones.
+func TextBufRecoveryFiles() map[string]gi.FileName {
+	rdir := TextBufRecoveryDir()
+	fis, err := ioutil.ReadDir(rdir)
+	if err != nil {
+		return nil
+	}
+	rf := make(map[string]gi.FileName)
+	for _, fi := range fis {
+		if fi.IsDir() {
+			continue
+		}
+		rfn := filepath.Join(rdir, fi.Name())
+		fn, err := TextBufRecoveryFileFor(rfn)
+		if err != nil {
+			continue
+		}
+		rf[rfn] = fn
+	}
+	return rf
+}
+
+// TextBufRecoveryFileFor returns the file name that given recovery journal
+// file is for, from its header line
+func TextBufRecoveryFileFor(rfn string) (gi.FileName, error) {
+	fp, err := os.Open(rfn)
+	if err != nil {
+		return "", err
+	}
+	defer fp.Close()
+	hdr, err := bufio.NewReader(fp).ReadString('\n')
+	if err != nil || !strings.HasPrefix(hdr, TextBufRecoveryHeader) {
+		return "", fmt.Errorf("giv.TextBuf: %v is not a recovery file", rfn)
+	}
+	return gi.FileName(strings.TrimSpace(strings.TrimPrefix(hdr, TextBufRecoveryHeader))), nil
+}
+
+// RecoveryFilename returns the name of the recovery journal file for this
+// buffer, which is unique to the full path of the file
+func (tb *TextBuf) RecoveryFilename() string {
+	fn := string(tb.Filename)
+	if fn == "" {
+		fn = "new_file_" + tb.Nm
+	} else if afn, err := filepath.Abs(fn); err == nil {
+		fn = afn
+	}
+	h := fnv.New64a()
+	h.Write([]byte(fn))
+	rfn := fmt.Sprintf("%s-%016x.recover", filepath.Base(fn), h.Sum64())
+	return filepath.Join(TextBufRecoveryDir(), rfn)
+}
+
+// RecoveryOn returns true if recovery journals are being saved for this buffer
+func (tb *TextBuf) RecoveryOn() bool {
+	return !tb.NoRecovery && tb.Opts.RecoverySecs > 0 && !tb.Undos.Off
+}
+
+// RecoveryStart starts the timer for saving the recovery journal, if it
+// is not already running -- called whenever the buffer is changed
+func (tb *TextBuf) RecoveryStart() {
+	if !tb.RecoveryOn() || oswin.TheApp == nil {
+		return
+	}
+	tb.RecoveryMu.Lock()
+	defer tb.RecoveryMu.Unlock()
+	if tb.RecoveryTimer != nil {
+		return
+	}
+	tb.RecoveryTimer = time.AfterFunc(time.Duration(tb.Opts.RecoverySecs)*time.Second, func() {
+		tb.RecoveryMu.Lock()
+		tb.RecoveryTimer = nil
+		tb.RecoveryMu.Unlock()
+		tb.RecoverySave()
+	})
+}
+
+// RecoveryStop stops any pending save of the recovery journal
+func (tb *TextBuf) RecoveryStop() {
+	tb.RecoveryMu.Lock()
+	if tb.RecoveryTimer != nil {
+		tb.RecoveryTimer.Stop()
+		tb.RecoveryTimer = nil
+	}
+	tb.RecoveryMu.Unlock()
+}
+
+// RecoverySave saves the current text to the recovery journal file, if the
+// buffer has unsaved changes -- safe to call in a separate goroutine
+func (tb *TextBuf) RecoverySave() error {
+	if !tb.IsChanged() || !tb.RecoveryOn() {
+		return nil
+	}
+	var b bytes.Buffer
+	b.WriteString(TextBufRecoveryHeader + string(tb.Filename) + "\n")
+	b.Write(tb.LinesToBytesCopy())
+	rfn := tb.RecoveryFilename()
+	err := ioutil.WriteFile(rfn, b.Bytes(), 0600)
+	if err != nil {
+		log.Printf("giv.TextBuf: Could not save recovery file: %v, error: %v\n", rfn, err)
+	}
+	return err
+}
+
+// RecoveryDelete stops any pending save of the recovery journal and
+// deletes any existing recovery journal file
+func (tb *TextBuf) RecoveryDelete() {
+	tb.RecoveryStop()
+	if oswin.TheApp == nil {
+		return
+	}
+	os.Remove(tb.RecoveryFilename())
+}
+
+// RecoveryCheck returns true if a recovery journal file exists for this buffer
+func (tb *TextBuf) RecoveryCheck() bool {
+	if tb.NoRecovery || oswin.TheApp == nil {
+		return false
+	}
+	if _, err := os.Stat(tb.RecoveryFilename()); os.IsNotExist(err) {
+		return false
+	}
+	return true
+}
+
+// RecoveryRestore replaces the text of the buffer with the contents of the
+// recovery journal file, which is then deleted, marking the buffer as
+// changed as the restored text has not been saved
+func (tb *TextBuf) RecoveryRestore() error {
+	rfn := tb.RecoveryFilename()
+	b, err := ioutil.ReadFile(rfn)
+	if err != nil {
+		return err
+	}
+	nl := bytes.IndexByte(b, '\n')
+	if nl < 0 || !bytes.HasPrefix(b, []byte(TextBufRecoveryHeader)) {
+		return fmt.Errorf("giv.TextBuf: %v is not a recovery file", rfn)
+	}
+	tb.SetText(b[nl+1:])
+	tb.SetChanged()
+	tb.RecoverySave() // restored text is itself still unsaved
+	return nil
+}
+
+// RecoveryPrompt checks for a recovery journal file for this buffer, and if
+// one exists, prompts the user whether to restore it, discard it, or keep
+// it for later.  Returns true if there was a recovery file.
+func (tb *TextBuf) RecoveryPrompt() bool {
+	if !tb.RecoveryCheck() {
+		return false
+	}
+	vp := tb.ViewportFromView()
+	if vp == nil {
+		return true
+	}
+	fn := string(tb.Filename)
+	if fn == "" {
+		fn = tb.Nm
+	}
+	gi.ChoiceDialog(vp, gi.DlgOpts{Title: "Recover Unsaved Changes?",
+		Prompt: fmt.Sprintf("There are unsaved changes to file: %v from a previous session that did not exit normally.  Do you want to recover them?", fn)},
+		[]string{"Recover", "Discard", "Keep for Later"},
+		tb.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			switch sig {
+			case 0:
+				if err := tb.RecoveryRestore(); err != nil {
+					log.Println(err)
+				}
+			case 1:
+				tb.RecoveryDelete()
+			}
+		})
+	return true
+}
@@ -9,6 +9,7 @@ import (
 	"image"
 	"image/draw"
 	"log"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
@@ -24,6 +25,8 @@ import (
 	"github.com/chewxy/math32"
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/dnd"
+	"github.com/goki/gi/oswin/ime"
 	"github.com/goki/gi/oswin/key"
 	"github.com/goki/gi/oswin/mimedata"
 	"github.com/goki/gi/oswin/mouse"
@@ -35,7 +38,6 @@ import (
 	"github.com/goki/pi/filecat"
 	"github.com/goki/pi/lex"
 	"github.com/goki/pi/pi"
-	"github.com/goki/pi/token"
 )
 
 // TextView is a widget for editing multiple lines of text (as compared to
@@ -49,12 +51,15 @@ type TextView struct {
 	Buf                    *TextBuf                    `json:"-" xml:"-" desc:"the text buffer that we're editing"`
 	Placeholder            string                      `json:"-" xml:"placeholder" desc:"text that is displayed when the field is empty, in a lower-contrast manner"`
 	CursorWidth            units.Value                 `xml:"cursor-width" desc:"width of cursor -- set from cursor-width property (inherited)"`
-	NLines                 int                         `json:"-" xml:"-" desc:"number of lines in the view -- sync'd with the Buf after edits, but always reflects storage size of Renders etc"`
-	Renders                []girl.Text                 `json:"-" xml:"-" desc:"renders of the text lines, with one render per line (each line could visibly wrap-around, so these are logical lines, not display lines)"`
-	Offs                   []float32                   `json:"-" xml:"-" desc:"starting offsets for top of each line"`
+	NLines                 int                         `json:"-" xml:"-" desc:"number of lines in the view -- sync'd with the Buf after edits, but always reflects storage size of Renders etc (or the number of lines that LargeRenders can have)"`
+	Renders                []girl.Text                 `json:"-" xml:"-" desc:"renders of the text lines, with one render per line (each line could visibly wrap-around, so these are logical lines, not display lines) -- nil in large-file mode -- use LineRender to access"`
+	Offs                   []float32                   `json:"-" xml:"-" desc:"starting offsets for top of each line -- nil in large-file mode -- use LineOff to access"`
+	LargeRenders           map[int]*girl.Text          `json:"-" xml:"-" desc:"renders of the lines near the visible ones in large-file mode, where they are laid out as they are rendered -- nil otherwise -- see TextBufLargeFileSize"`
+	FoldHidden             []bool                      `json:"-" xml:"-" desc:"lines that are hidden within folded regions of the buffer -- nil if nothing is folded"`
 	LineNoDigs             int                         `json:"-" xml:"-" desc:"number of line number digits needed"`
 	LineNoOff              float32                     `json:"-" xml:"-" desc:"horizontal offset for start of text after line numbers"`
 	LineNoRender           girl.Text                   `json:"-" xml:"-" desc:"render for line numbers"`
+	IME                    gi.IMEPreedit               `copy:"-" json:"-" xml:"-" view:"-" desc:"input method (IME) composition state -- the composing text is displayed at the cursor"`
 	LinesSize              image.Point                 `json:"-" xml:"-" desc:"total size of all lines as rendered"`
 	RenderSz               mat32.Vec2                  `json:"-" xml:"-" desc:"size params to use in render call"`
 	CursorPos              lex.Pos                     `json:"-" xml:"-" desc:"current cursor position"`
@@ -64,9 +69,12 @@ type TextView struct {
 	SelectStart            lex.Pos                     `json:"-" xml:"-" desc:"starting point for selection -- will either be the start or end of selected region depending on subsequent selection."`
 	SelectReg              textbuf.Region              `json:"-" xml:"-" desc:"current selection region"`
 	PrevSelectReg          textbuf.Region              `json:"-" xml:"-" desc:"previous selection region, that was actually rendered -- needed to update render"`
+	Carets                 []textbuf.Region            `json:"-" xml:"-" desc:"additional cursors for multi-cursor editing, each with a selected region (Start == End if no selection) -- End is the cursor position"`
 	Highlights             []textbuf.Region            `json:"-" xml:"-" desc:"highlighted regions, e.g., for search results"`
 	Scopelights            []textbuf.Region            `json:"-" xml:"-" desc:"highlighted regions, specific to scope markers"`
 	SelectMode             bool                        `json:"-" xml:"-" desc:"if true, select text as cursor moves"`
+	EditMode               gi.EditModes                `desc:"editing mode for keys in this view, overriding the EditMode in the editor prefs unless Default"`
+	KeyMode                TextViewKeyMode             `json:"-" xml:"-" view:"-" desc:"handler for the current editing mode -- nil for standard -- see CurKeyMode"`
 	ForceComplete          bool                        `json:"-" xml:"-" desc:"if true, complete regardless of any disqualifying reasons"`
 	ISearch                ISearch                     `json:"-" xml:"-" desc:"interactive search data"`
 	QReplace               QReplace                    `json:"-" xml:"-" desc:"query replace data"`
@@ -82,6 +90,7 @@ type TextView struct {
 	lastRecenter           int
 	lastAutoInsert         rune
 	lastFilename           gi.FileName
+	keyModeFor             gi.EditModes
 }
 
 var KiT_TextView = kit.Types.AddType(&TextView{}, TextViewProps)
@@ -97,6 +106,21 @@ func (tv *TextView) Disconnect() {
 	tv.LinkSig.DisconnectAll()
 }
 
+// AccessInfo describes the text view for assistive technologies -- the
+// text of the buffer is served on demand through the oswin.AccessText
+// methods of the view, and only its revision and the cursor position are
+// recorded, so updates do not copy the whole buffer
+func (tv *TextView) AccessInfo(an *oswin.AccessNode) {
+	an.Role = oswin.RoleTextArea
+	if tv.Buf != nil {
+		an.Text = tv
+		an.TextRev = tv.Buf.Revision()
+		an.Caret = tv.Buf.RuneOffset(tv.CursorPos)
+	}
+	an.SetState(!tv.IsInactive(), oswin.StateEditable)
+	an.SetState(tv.IsInactive(), oswin.StateReadOnly)
+}
+
 var TextViewProps = ki.Props{
 	"EnumType:Flag":    KiT_TextViewFlags,
 	"white-space":      gist.WhiteSpacePreWrap,
@@ -149,6 +173,15 @@ const (
 	// QReplace.* members for current state
 	TextViewQReplace
 
+	// TextViewRendered is emitted after the view has been rendered, including
+	// after scrolling -- e.g., for a TextMinimap to update its view of the
+	// visible region
+	TextViewRendered
+
+	// TextViewEditMode is emitted when the editing mode, or its state, changes
+	// -- e.g., between vim normal and insert modes -- data is KeyModeName()
+	TextViewEditMode
+
 	// TextViewSignalsN is the number of TextViewSignals
 	TextViewSignalsN
 )
@@ -347,7 +380,12 @@ func (tv *TextView) SetBuf(buf *TextBuf) {
 func (tv *TextView) LinesInserted(tbe *textbuf.Edit) {
 	stln := tbe.Reg.Start.Ln + 1
 	nsz := (tbe.Reg.End.Ln - tbe.Reg.Start.Ln)
-	if stln > len(tv.Renders) { // invalid
+	if stln > tv.NLines { // invalid
+		return
+	}
+	if tv.LargeRenders != nil {
+		tv.LayoutLinesLarge(tbe.Reg.Start.Ln, tbe.Reg.End.Ln)
+		tv.RenderAllLines()
 		return
 	}
 
@@ -377,6 +415,11 @@ func (tv *TextView) LinesDeleted(tbe *textbuf.Edit) {
 	edln := tbe.Reg.End.Ln
 	dsz := edln - stln
 
+	if tv.LargeRenders != nil {
+		tv.LayoutLinesLarge(stln, stln)
+		tv.RenderAllLines()
+		return
+	}
 	tv.Renders = append(tv.Renders[:stln], tv.Renders[edln:]...)
 	tv.Offs = append(tv.Offs[:stln], tv.Offs[edln:]...)
 
@@ -399,7 +442,7 @@ func TextViewBufSigRecv(rvwki ki.Ki, sbufki ki.Ki, sig int64, data interface{})
 		tv.Refresh()
 		tv.SetCursorShow(tv.CursorPos)
 	case TextBufInsert:
-		if tv.Renders == nil { // not init yet
+		if !tv.IsLaidOut() { // not init yet
 			return
 		}
 		tbe := data.(*textbuf.Edit)
@@ -418,7 +461,7 @@ func TextViewBufSigRecv(rvwki ki.Ki, sbufki ki.Ki, sig int64, data interface{})
 			}
 		}
 	case TextBufDelete:
-		if tv.Renders == nil { // not init yet
+		if !tv.IsLaidOut() { // not init yet
 			return
 		}
 		tbe := data.(*textbuf.Edit)
@@ -520,11 +563,18 @@ func (tv *TextView) LayoutAllLines(inLayout bool) bool {
 	}
 	tv.lastFilename = tv.Buf.Filename
 
+	if tv.Buf.EditorConfig != nil { // file-specific tab width
+		tv.Sty.Text.TabSize = tv.Buf.Opts.TabSize
+	}
 	tv.Buf.Hi.TabSize = tv.Sty.Text.TabSize
 	tv.HiStyle()
 	// fmt.Printf("layout all: %v\n", tv.Nm)
 
 	tv.NLines = tv.Buf.NumLines()
+	if tv.Buf.IsLargeFile() {
+		return tv.LayoutAllLinesLarge(inLayout)
+	}
+	tv.LargeRenders = nil
 	nln := tv.NLines
 	if cap(tv.Renders) >= nln {
 		tv.Renders = tv.Renders[:nln]
@@ -548,19 +598,21 @@ func (tv *TextView) LayoutAllLines(inLayout bool) bool {
 	mxwd := sz.X // always start with our render size
 
 	tv.Buf.MarkupMu.RLock()
+	tv.UpdateFoldHidden()
 	tv.HasLinks = false
 	for ln := 0; ln < nln; ln++ {
-		tv.Renders[ln].SetHTMLPre(tv.Buf.Markup[ln], &fst, &sty.Text, &sty.UnContext, tv.CSS)
-		tv.Renders[ln].LayoutStdLR(&sty.Text, &sty.Font, &sty.UnContext, sz)
+		tv.LayoutLine(ln, &fst, sz)
 		if !tv.HasLinks && len(tv.Renders[ln].Links) > 0 {
 			tv.HasLinks = true
 		}
 		tv.Offs[ln] = off
-		lsz := mat32.Max(tv.Renders[ln].Size.Y, tv.LineHeight)
-		off += lsz
+		off += tv.LineSizeY(ln)
 		mxwd = mat32.Max(mxwd, tv.Renders[ln].Size.X)
 	}
 	tv.Buf.MarkupMu.RUnlock()
+	if tv.Buf.Opts.ElasticTabs {
+		mxwd = mat32.Max(mxwd, tv.LayoutElasticTabs(0, nln-1))
+	}
 
 	extraHalf := tv.LineHeight * 0.5 * float32(tv.VisSize.Y)
 	nwSz := mat32.Vec2{mxwd, off + extraHalf}.ToPointCeil()
@@ -572,6 +624,57 @@ func (tv *TextView) LayoutAllLines(inLayout bool) bool {
 	return tv.ResizeIfNeeded(nwSz)
 }
 
+// LayoutLine lays out the render of given line, with the indentation of
+// its continuation lines if it is wrapped -- MarkupMu must be locked
+func (tv *TextView) LayoutLine(ln int, fst *gist.Font, sz mat32.Vec2) {
+	sty := &tv.Sty
+	tsty := sty.Text
+	if ind := tv.WrapIndentChars(ln); ind > 0 {
+		tsty.WrapIndent.Dots = float32(ind) * sty.Font.Face.Metrics.Ch
+	}
+	tv.Renders[ln].SetHTMLPre(tv.Buf.Markup[ln], fst, &sty.Text, &sty.UnContext, tv.CSS)
+	tv.Renders[ln].LayoutStdLR(&tsty, &sty.Font, &sty.UnContext, sz)
+}
+
+// WrapIndentChars returns the indentation, in chars, of the continuation
+// lines of given line when it is wrapped: the indentation of the line
+// itself if the WrapIndent option is on, plus WrapHang, limited to half
+// of the view width
+func (tv *TextView) WrapIndentChars(ln int) int {
+	if tv.Buf == nil || ln >= tv.Buf.NumLines() || !tv.Sty.Text.HasWordWrap() {
+		return 0
+	}
+	opts := &tv.Buf.Opts
+	ind := 0
+	if opts.WrapIndent {
+		tabSz := ints.MaxInt(tv.Sty.Text.TabSize, 1)
+		for _, r := range tv.Buf.Line(ln) {
+			if r == '\t' {
+				ind = (ind/tabSz + 1) * tabSz
+			} else if r == ' ' {
+				ind++
+			} else {
+				break
+			}
+		}
+	}
+	ind += opts.WrapHang
+	if ch := tv.Sty.Font.Face.Metrics.Ch; ch > 0 {
+		ind = ints.MinInt(ind, int(tv.RenderSz.X/(2*ch)))
+	}
+	return ints.MaxInt(ind, 0)
+}
+
+// WrapCol returns the rune index within span si of given wrapped line
+// for the current CursorCol, which is a visual column that includes the
+// indentation of continuation lines
+func (tv *TextView) WrapCol(ln, si int) int {
+	if si == 0 {
+		return tv.CursorCol
+	}
+	return ints.MaxInt(tv.CursorCol-tv.WrapIndentChars(ln), 0)
+}
+
 // SetSize updates our size only if larger than our allocation
 func (tv *TextView) SetSize() bool {
 	sty := &tv.Sty
@@ -633,17 +736,28 @@ func (tv *TextView) LayoutLines(st, ed int, isDel bool) bool {
 	if tv.Buf == nil || tv.Buf.NumLines() == 0 {
 		return false
 	}
+	if tv.LargeRenders != nil {
+		tv.LayoutLinesLarge(st, ed)
+		return false
+	}
 	sty := &tv.Sty
 	fst := sty.Font
 	fst.BgColor.SetColor(nil)
 	mxwd := float32(tv.LinesSize.X)
 	rerend := false
+	if tv.Buf.Opts.ElasticTabs {
+		est, eed := tv.ElasticBlock(st, ed)
+		if est != st || eed != ed { // other lines can change
+			st, ed = est, eed
+			rerend = true
+		}
+	}
 
 	tv.Buf.MarkupMu.RLock()
+	tv.UpdateFoldHidden()
 	for ln := st; ln <= ed; ln++ {
 		curspans := len(tv.Renders[ln].Spans)
-		tv.Renders[ln].SetHTMLPre(tv.Buf.Markup[ln], &fst, &sty.Text, &sty.UnContext, tv.CSS)
-		tv.Renders[ln].LayoutStdLR(&sty.Text, &sty.Font, &sty.UnContext, tv.RenderSz)
+		tv.LayoutLine(ln, &fst, tv.RenderSz)
 		if !tv.HasLinks && len(tv.Renders[ln].Links) > 0 {
 			tv.HasLinks = true
 		}
@@ -654,6 +768,9 @@ func (tv *TextView) LayoutLines(st, ed int, isDel bool) bool {
 		mxwd = mat32.Max(mxwd, tv.Renders[ln].Size.X)
 	}
 	tv.Buf.MarkupMu.RUnlock()
+	if tv.Buf.Opts.ElasticTabs {
+		mxwd = mat32.Max(mxwd, tv.LayoutElasticTabs(st, ed))
+	}
 
 	// update all offsets to end of text
 	if rerend || isDel || st != ed {
@@ -664,8 +781,7 @@ func (tv *TextView) LayoutLines(st, ed int, isDel bool) bool {
 		off := tv.Offs[ofst]
 		for ln := ofst; ln < tv.NLines; ln++ {
 			tv.Offs[ln] = off
-			lsz := mat32.Max(tv.Renders[ln].Size.Y, tv.LineHeight)
-			off += lsz
+			off += tv.LineSizeY(ln)
 		}
 		extraHalf := tv.LineHeight * 0.5 * float32(tv.VisSize.Y)
 		nwSz := mat32.Vec2{mxwd, off + extraHalf}.ToPointCeil()
@@ -697,20 +813,30 @@ func (tv *TextView) ValidateCursor() {
 
 // WrappedLines returns the number of wrapped lines (spans) for given line number
 func (tv *TextView) WrappedLines(ln int) int {
-	if ln >= len(tv.Renders) {
+	if ln >= tv.NLines {
 		return 0
 	}
-	return len(tv.Renders[ln].Spans)
+	return len(tv.LineRender(ln).Spans)
 }
 
 // WrappedLineNo returns the wrapped line number (span index) and rune index
 // within that span of the given character position within line in position,
 // and false if out of range (last valid position returned in that case -- still usable).
 func (tv *TextView) WrappedLineNo(pos lex.Pos) (si, ri int, ok bool) {
-	if pos.Ln >= len(tv.Renders) {
+	if pos.Ln >= tv.NLines {
 		return 0, 0, false
 	}
-	return tv.Renders[pos.Ln].RuneSpanPos(pos.Ch)
+	return tv.LineRender(pos.Ln).RuneSpanPos(pos.Ch)
+}
+
+// IsRTLLine returns true if given line has right-to-left paragraph
+// direction (e.g., starts with Arabic or Hebrew text), in which case the
+// left and right arrow keys move the cursor in the opposite logical direction
+func (tv *TextView) IsRTLLine(ln int) bool {
+	if ln >= tv.NLines || len(tv.LineRender(ln).Spans) == 0 {
+		return false
+	}
+	return tv.LineRender(ln).Spans[0].Dir == gist.RLTB
 }
 
 // SetCursor sets a new cursor position, enforcing it in range
@@ -757,6 +883,9 @@ func (tv *TextView) SetCursor(pos lex.Pos) {
 // SetCursorShow sets a new cursor position, enforcing it in range, and shows
 // the cursor (scroll to if hidden, render)
 func (tv *TextView) SetCursorShow(pos lex.Pos) {
+	if tv.IsLineHidden(pos.Ln) && tv.Buf != nil {
+		tv.Buf.UnfoldLine(pos.Ln)
+	}
 	tv.SetCursor(pos)
 	tv.ScrollCursorToCenterIfHidden()
 	tv.RenderCursor(true)
@@ -768,7 +897,7 @@ func (tv *TextView) SetCursorCol(pos lex.Pos) {
 	if wln := tv.WrappedLines(pos.Ln); wln > 1 {
 		si, ri, ok := tv.WrappedLineNo(pos)
 		if ok && si > 0 {
-			tv.CursorCol = ri
+			tv.CursorCol = ri + tv.WrapIndentChars(pos.Ln)
 		} else {
 			tv.CursorCol = pos.Ch
 		}
@@ -944,13 +1073,14 @@ func (tv *TextView) CursorDown(steps int) {
 			si, ri, _ := tv.WrappedLineNo(pos)
 			if si < wln-1 {
 				si++
-				mxlen := ints.MinInt(len(tv.Renders[pos.Ln].Spans[si].Text), tv.CursorCol)
-				if tv.CursorCol < mxlen {
-					ri = tv.CursorCol
+				col := tv.WrapCol(pos.Ln, si)
+				mxlen := ints.MinInt(len(tv.LineRender(pos.Ln).Spans[si].Text), col)
+				if col < mxlen {
+					ri = col
 				} else {
 					ri = mxlen
 				}
-				nwc, _ := tv.Renders[pos.Ln].SpanPosToRuneIdx(si, ri)
+				nwc, _ := tv.LineRender(pos.Ln).SpanPosToRuneIdx(si, ri)
 				if si == wln-1 && ri == mxlen {
 					nwc++
 				}
@@ -961,6 +1091,9 @@ func (tv *TextView) CursorDown(steps int) {
 		}
 		if !gotwrap {
 			pos.Ln++
+			for pos.Ln < tv.NLines-1 && tv.IsLineHidden(pos.Ln) {
+				pos.Ln++
+			}
 			if pos.Ln >= tv.NLines {
 				pos.Ln = tv.NLines - 1
 				break
@@ -1087,23 +1220,26 @@ func (tv *TextView) CursorUp(steps int) {
 		if wln := tv.WrappedLines(pos.Ln); wln > 1 {
 			si, ri, _ := tv.WrappedLineNo(pos)
 			if si > 0 {
-				ri = tv.CursorCol
+				ri = tv.WrapCol(pos.Ln, si-1)
 				// fmt.Printf("up cursorcol: %v\n", tv.CursorCol)
-				nwc, _ := tv.Renders[pos.Ln].SpanPosToRuneIdx(si-1, ri)
+				nwc, _ := tv.LineRender(pos.Ln).SpanPosToRuneIdx(si-1, ri)
 				pos.Ch = nwc
 				gotwrap = true
 			}
 		}
 		if !gotwrap {
 			pos.Ln--
+			for pos.Ln > 0 && tv.IsLineHidden(pos.Ln) {
+				pos.Ln--
+			}
 			if pos.Ln < 0 {
 				pos.Ln = 0
 				break
 			}
 			if wln := tv.WrappedLines(pos.Ln); wln > 1 { // just entered end of wrapped line
 				si := wln - 1
-				ri := tv.CursorCol
-				nwc, _ := tv.Renders[pos.Ln].SpanPosToRuneIdx(si, ri)
+				ri := tv.WrapCol(pos.Ln, si)
+				nwc, _ := tv.LineRender(pos.Ln).SpanPosToRuneIdx(si, ri)
 				pos.Ch = nwc
 			} else {
 				mxlen := ints.MinInt(tv.Buf.LineLen(pos.Ln), tv.CursorCol)
@@ -1171,7 +1307,7 @@ func (tv *TextView) CursorStartLine() {
 		si, ri, _ := tv.WrappedLineNo(pos)
 		if si > 0 {
 			ri = 0
-			nwc, _ := tv.Renders[pos.Ln].SpanPosToRuneIdx(si, ri)
+			nwc, _ := tv.LineRender(pos.Ln).SpanPosToRuneIdx(si, ri)
 			pos.Ch = nwc
 			tv.CursorPos = pos
 			tv.CursorCol = ri
@@ -1216,9 +1352,9 @@ func (tv *TextView) CursorEndLine() {
 	gotwrap := false
 	if wln := tv.WrappedLines(pos.Ln); wln > 1 {
 		si, ri, _ := tv.WrappedLineNo(pos)
-		ri = len(tv.Renders[pos.Ln].Spans[si].Text) - 1
-		nwc, _ := tv.Renders[pos.Ln].SpanPosToRuneIdx(si, ri)
-		if si == len(tv.Renders[pos.Ln].Spans)-1 { // last span
+		ri = len(tv.LineRender(pos.Ln).Spans[si].Text) - 1
+		nwc, _ := tv.LineRender(pos.Ln).SpanPosToRuneIdx(si, ri)
+		if si == len(tv.LineRender(pos.Ln).Spans)-1 { // last span
 			ri++
 			nwc++
 		}
@@ -1337,7 +1473,7 @@ func (tv *TextView) CursorKill() {
 	atEnd := false
 	if wln := tv.WrappedLines(pos.Ln); wln > 1 {
 		si, ri, _ := tv.WrappedLineNo(pos)
-		llen := len(tv.Renders[pos.Ln].Spans[si].Text)
+		llen := len(tv.LineRender(pos.Ln).Spans[si].Text)
 		if si == wln-1 {
 			llen--
 		}
@@ -1366,17 +1502,16 @@ func (tv *TextView) CursorTranspose() {
 	}
 	ppos := pos
 	ppos.Ch--
-	tv.Buf.LinesMu.Lock()
-	lln := len(tv.Buf.Lines[pos.Ln])
+	lr := tv.Buf.Line(pos.Ln)
+	lln := len(lr)
 	end := false
 	if pos.Ch >= lln {
 		end = true
 		pos.Ch = lln - 1
 		ppos.Ch = lln - 2
 	}
-	chr := tv.Buf.Lines[pos.Ln][pos.Ch]
-	pchr := tv.Buf.Lines[pos.Ln][ppos.Ch]
-	tv.Buf.LinesMu.Unlock()
+	chr := lr[pos.Ch]
+	pchr := lr[ppos.Ch]
 	repl := string([]rune{chr, pchr})
 	pos.Ch++
 	tv.Buf.ReplaceText(ppos, pos, ppos, repl, EditSignal, ReplaceMatchCase)
@@ -1417,12 +1552,12 @@ func (tv *TextView) JumpToLine(ln int) {
 // FindNextLink finds next link after given position, returns false if no such links
 func (tv *TextView) FindNextLink(pos lex.Pos) (lex.Pos, textbuf.Region, bool) {
 	for ln := pos.Ln; ln < tv.NLines; ln++ {
-		if len(tv.Renders[ln].Links) == 0 {
+		if len(tv.LineRender(ln).Links) == 0 {
 			pos.Ch = 0
 			pos.Ln = ln + 1
 			continue
 		}
-		rend := &tv.Renders[ln]
+		rend := tv.LineRender(ln)
 		si, ri, _ := rend.RuneSpanPos(pos.Ch)
 		for ti := range rend.Links {
 			tl := &rend.Links[ti]
@@ -1443,7 +1578,7 @@ func (tv *TextView) FindNextLink(pos lex.Pos) (lex.Pos, textbuf.Region, bool) {
 // FindPrevLink finds previous link before given position, returns false if no such links
 func (tv *TextView) FindPrevLink(pos lex.Pos) (lex.Pos, textbuf.Region, bool) {
 	for ln := pos.Ln - 1; ln >= 0; ln-- {
-		if len(tv.Renders[ln].Links) == 0 {
+		if len(tv.LineRender(ln).Links) == 0 {
 			if ln-1 >= 0 {
 				pos.Ch = tv.Buf.LineLen(ln-1) - 2
 			} else {
@@ -1452,7 +1587,7 @@ func (tv *TextView) FindPrevLink(pos lex.Pos) (lex.Pos, textbuf.Region, bool) {
 			}
 			continue
 		}
-		rend := &tv.Renders[ln]
+		rend := tv.LineRender(ln)
 		si, ri, _ := rend.RuneSpanPos(pos.Ch)
 		nl := len(rend.Links)
 		for ti := nl - 1; ti >= 0; ti-- {
@@ -1785,15 +1920,23 @@ func (tv *TextView) ISearchCancel() {
 
 // QReplace holds all the query-replace data
 type QReplace struct {
-	On       bool            `json:"-" xml:"-" desc:"if true, in interactive search mode"`
-	Find     string          `json:"-" xml:"-" desc:"current interactive search string"`
-	Replace  string          `json:"-" xml:"-" desc:"current interactive search string"`
-	UseCase  bool            `json:"-" xml:"-" desc:"pay attention to case in isearch -- triggered by typing an upper-case letter"`
-	LexItems bool            `json:"-" xml:"-" desc:"search only as entire lexically-tagged item boundaries -- key for replacing short local variables like i"`
-	Matches  []textbuf.Match `json:"-" xml:"-" desc:"current search matches"`
-	Pos      int             `json:"-" xml:"-" desc:"position within isearch matches"`
-	PrevPos  int             `json:"-" xml:"-" desc:"position in search list from previous search"`
-	StartPos lex.Pos         `json:"-" xml:"-" desc:"starting position for search -- returns there after on cancel"`
+	On        bool            `json:"-" xml:"-" desc:"if true, in interactive search mode"`
+	Find      string          `json:"-" xml:"-" desc:"current interactive search string"`
+	Replace   string          `json:"-" xml:"-" desc:"current interactive search string"`
+	UseCase   bool            `json:"-" xml:"-" desc:"pay attention to case in isearch -- triggered by typing an upper-case letter"`
+	LexItems  bool            `json:"-" xml:"-" desc:"search only as entire lexically-tagged item boundaries -- key for replacing short local variables like i"`
+	Regexp    bool            `json:"-" xml:"-" desc:"find string is a regular expression, and replace string can refer to capture groups as $1 or ${name}"`
+	WholeWord bool            `json:"-" xml:"-" desc:"only match at word boundaries"`
+	Re        *regexp.Regexp  `json:"-" xml:"-" desc:"compiled regexp for Regexp or WholeWord modes"`
+	Matches   []textbuf.Match `json:"-" xml:"-" desc:"current search matches"`
+	Pos       int             `json:"-" xml:"-" desc:"position within isearch matches"`
+	PrevPos   int             `json:"-" xml:"-" desc:"position in search list from previous search"`
+	StartPos  lex.Pos         `json:"-" xml:"-" desc:"starting position for search -- returns there after on cancel"`
+}
+
+// FindOpts returns the find options for the current QReplace settings
+func (qr *QReplace) FindOpts() *textbuf.FindOpts {
+	return &textbuf.FindOpts{Find: qr.Find, Regexp: qr.Regexp, UseCase: qr.UseCase, WholeWord: qr.WholeWord}
 }
 
 // PrevQReplaceFinds are the previous QReplace strings
@@ -1832,10 +1975,18 @@ func QReplaceDialog(avp *gi.Viewport2D, find string, lexitems bool, opts gi.DlgO
 	tfr.ItemsFromStringList(PrevQReplaceRepls, true, 0)
 
 	lb := frame.InsertNewChild(gi.KiT_CheckBox, prIdx+3, "lexb").(*gi.CheckBox)
-	lb.SetText("Lexical Items")
+	lb.SetText(gi.T("Lexical Items"))
 	lb.SetChecked(lexitems)
 	lb.Tooltip = "search matches entire lexically tagged items -- good for finding local variable names like 'i' and not matching everything"
 
+	rb := frame.InsertNewChild(gi.KiT_CheckBox, prIdx+4, "regexp").(*gi.CheckBox)
+	rb.SetText(gi.T("Regexp"))
+	rb.Tooltip = "find is a regular expression, and replace can refer to capture groups as $1 or ${name} -- use $$ for a literal $"
+
+	wb := frame.InsertNewChild(gi.KiT_CheckBox, prIdx+5, "word").(*gi.CheckBox)
+	wb.SetText(gi.T("Whole Word"))
+	wb.Tooltip = "only match at word boundaries"
+
 	if recv != nil && fun != nil {
 		dlg.DialogSig.Connect(recv, fun)
 	}
@@ -1861,6 +2012,27 @@ func QReplaceDialogValues(dlg *gi.Dialog) (find, repl string, lexItems bool) {
 	return
 }
 
+// QReplaceDialogOpts gets the regexp and whole-word options from the dialog
+func QReplaceDialogOpts(dlg *gi.Dialog) (useRegexp, wholeWord bool) {
+	frame := dlg.Frame()
+	rb := frame.ChildByName("regexp", 4).(*gi.CheckBox)
+	useRegexp = rb.IsChecked()
+	wb := frame.ChildByName("word", 5).(*gi.CheckBox)
+	wholeWord = wb.IsChecked()
+	return
+}
+
+// QReplaceDialogHighlight highlights all the matches for the current values
+// in the dialog, as they are being entered
+func (tv *TextView) QReplaceDialogHighlight(dlg *gi.Dialog) {
+	find, _, _ := QReplaceDialogValues(dlg)
+	fo := &textbuf.FindOpts{Find: find, UseCase: lex.HasUpperCase(find)}
+	fo.Regexp, fo.WholeWord = QReplaceDialogOpts(dlg)
+	wupdt := tv.TopUpdateStart()
+	tv.FindMatchesOpts(fo)
+	tv.TopUpdateEnd(wupdt)
+}
+
 // QReplacePrompt is an emacs-style query-replace mode -- this starts the process, prompting
 // user for items to search etc
 func (tv *TextView) QReplacePrompt() {
@@ -1868,13 +2040,41 @@ func (tv *TextView) QReplacePrompt() {
 	if tv.HasSelection() {
 		find = string(tv.Selection().ToBytes())
 	}
-	QReplaceDialog(tv.Viewport, find, tv.QReplace.LexItems, gi.DlgOpts{Title: "Query-Replace", Prompt: "Enter strings for find and replace, then select Ok -- with dialog dismissed press <b>y</b> to replace current match, <b>n</b> to skip, <b>Enter</b> or <b>q</b> to quit, <b>!</b> to replace-all remaining"}, tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+	dlg := QReplaceDialog(tv.Viewport, find, tv.QReplace.LexItems, gi.DlgOpts{Title: "Query-Replace", Prompt: "Enter strings for find and replace, then select Ok -- with dialog dismissed press <b>y</b> to replace current match, <b>n</b> to skip, <b>Enter</b> or <b>q</b> to quit, <b>!</b> to replace-all remaining"}, tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 		dlg := send.(*gi.Dialog)
 		if sig == int64(gi.DialogAccepted) {
 			find, repl, lexItems := QReplaceDialogValues(dlg)
+			tv.QReplace.Regexp, tv.QReplace.WholeWord = QReplaceDialogOpts(dlg)
 			tv.QReplaceStart(find, repl, lexItems)
+		} else {
+			tv.ClearHighlights()
 		}
 	})
+	frame := dlg.Frame()
+	rb := frame.ChildByName("regexp", 4).(*gi.CheckBox)
+	rb.SetChecked(tv.QReplace.Regexp)
+	wb := frame.ChildByName("word", 5).(*gi.CheckBox)
+	wb.SetChecked(tv.QReplace.WholeWord)
+	if find != "" {
+		tv.QReplaceDialogHighlight(dlg)
+	}
+	// incrementally highlight matches as the find string and options change
+	tff := frame.ChildByName("find", 1).(*gi.ComboBox)
+	if tf, found := tff.TextField(); found {
+		tf.TextFieldSig.Connect(tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			switch gi.TextFieldSignals(sig) {
+			case gi.TextFieldInsert, gi.TextFieldBackspace, gi.TextFieldDelete, gi.TextFieldCleared:
+				tv.QReplaceDialogHighlight(dlg)
+			}
+		})
+	}
+	for _, cb := range []*gi.CheckBox{rb, wb} {
+		cb.ButtonSig.Connect(tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig == int64(gi.ButtonToggled) {
+				tv.QReplaceDialogHighlight(dlg)
+			}
+		})
+	}
 }
 
 // QReplaceStart starts query-replace using given find, replace strings
@@ -1887,6 +2087,17 @@ func (tv *TextView) QReplaceStart(find, repl string, lexItems bool) {
 	tv.QReplace.UseCase = lex.HasUpperCase(find)
 	tv.QReplace.Matches = nil
 	tv.QReplace.Pos = -1
+	tv.QReplace.Re = nil
+	if fo := tv.QReplace.FindOpts(); !fo.IsSimple() {
+		re, err := fo.Compile()
+		if err != nil {
+			gi.PromptDialog(tv.Viewport, gi.DlgOpts{Title: "Invalid Regexp", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+			tv.QReplace.On = false
+			tv.ClearHighlights()
+			return
+		}
+		tv.QReplace.Re = re
+	}
 
 	gi.StringsInsertFirstUnique(&PrevQReplaceFinds, find, gi.Prefs.Params.SavedPathsMax)
 	gi.StringsInsertFirstUnique(&PrevQReplaceRepls, repl, gi.Prefs.Params.SavedPathsMax)
@@ -1900,7 +2111,11 @@ func (tv *TextView) QReplaceStart(find, repl string, lexItems bool) {
 // QReplaceMatches finds QReplace matches -- returns true if there are any
 func (tv *TextView) QReplaceMatches() bool {
 	got := false
-	tv.QReplace.Matches, got = tv.FindMatches(tv.QReplace.Find, tv.QReplace.UseCase, tv.QReplace.LexItems)
+	if tv.QReplace.Re != nil {
+		tv.QReplace.Matches, got = tv.FindMatchesOpts(tv.QReplace.FindOpts())
+	} else {
+		tv.QReplace.Matches, got = tv.FindMatches(tv.QReplace.Find, tv.QReplace.UseCase, tv.QReplace.LexItems)
+	}
 	return got
 }
 
@@ -1946,8 +2161,12 @@ func (tv *TextView) QReplaceReplace(midx int) {
 	reg := tv.Buf.AdjustReg(m.Reg)
 	pos := reg.Start
 	// last arg is matchCase, only if not using case to match and rep is also lower case
-	matchCase := !tv.QReplace.UseCase && !lex.HasUpperCase(rep)
-	tv.Buf.ReplaceText(reg.Start, reg.End, pos, rep, EditSignal, matchCase)
+	if tv.QReplace.Re != nil {
+		tv.Buf.ReplaceMatch(reg, tv.QReplace.Re, tv.QReplace.FindOpts(), rep)
+	} else {
+		matchCase := !tv.QReplace.UseCase && !lex.HasUpperCase(rep)
+		tv.Buf.ReplaceText(reg.Start, reg.End, pos, rep, EditSignal, matchCase)
+	}
 	tv.Highlights[midx] = textbuf.RegionNil
 	tv.SetCursor(pos)
 	tv.SavePosHistory(tv.CursorPos)
@@ -2402,6 +2621,27 @@ func (tv *TextView) Copy(reset bool) *textbuf.Edit {
 	return tbe
 }
 
+// CopyHTML copies the selected text to the clipboard as HTML with the
+// syntax highlighting, in addition to plain text, e.g., for pasting into
+// documents or email
+func (tv *TextView) CopyHTML(reset bool) *textbuf.Edit {
+	tbe := tv.Selection()
+	if tbe == nil {
+		return nil
+	}
+	wupdt := tv.TopUpdateStart()
+	defer tv.TopUpdateEnd(wupdt)
+	cb := tbe.ToBytes()
+	TextViewClipHistAdd(cb)
+	hb := tv.Buf.RegionHTML(tbe.Reg.Start, tbe.Reg.End)
+	oswin.TheApp.ClipBoard(tv.ParentWindow().OSWin).Write(mimedata.NewTextPlus(string(cb), mimedata.TextHTML, hb))
+	if reset {
+		tv.SelectReset()
+	}
+	tv.SavePosHistory(tv.CursorPos)
+	return tbe
+}
+
 // Paste inserts text from the clipboard at current cursor position
 func (tv *TextView) Paste() {
 	wupdt := tv.TopUpdateStart()
@@ -2413,6 +2653,18 @@ func (tv *TextView) Paste() {
 	}
 }
 
+// DropFiles implements gi.FileDropper: the paths of files dropped from the
+// OS are inserted at the drop position, one per line
+func (tv *TextView) DropFiles(files []string, pos image.Point, mod dnd.DropMods) bool {
+	if tv.Buf == nil || tv.IsInactive() {
+		return false
+	}
+	tv.GrabFocus()
+	tv.SetCursorShow(tv.PixelToCursor(tv.PointToRelPos(pos)))
+	tv.InsertAtCursor([]byte(strings.Join(files, "\n")))
+	return true
+}
+
 // InsertAtCursor inserts given text at current cursor position
 func (tv *TextView) InsertAtCursor(txt []byte) {
 	wupdt := tv.TopUpdateStart()
@@ -2534,6 +2786,12 @@ func (tv *TextView) MakeContextMenu(m *gi.Menu) {
 			txf.Copy(true)
 		})
 	ac.SetActiveState(tv.HasSelection())
+	ac = m.AddAction(gi.ActOpts{Label: "Copy As HTML"},
+		tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			txf := recv.Embed(KiT_TextView).(*TextView)
+			txf.CopyHTML(true)
+		})
+	ac.SetActiveState(tv.HasSelection())
 	if !tv.IsInactive() {
 		ac = m.AddAction(gi.ActOpts{Label: "Cut", ShortcutKey: gi.KeyFunCut},
 			tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
@@ -2753,7 +3011,7 @@ func (tv *TextView) SpellCheck(reg *textbuf.Edit) bool {
 
 	sugs, knwn := tv.Buf.Spell.CheckWordInline(lwb)
 	if knwn {
-		tv.Buf.RemoveTag(reg.Reg.Start, token.TextSpellErr)
+		tv.Buf.RemoveTag(reg.Reg.Start, histyle.SpellErr)
 		ln := reg.Reg.Start.Ln
 		tv.LayoutLines(ln, ln, false)
 		tv.RenderLines(ln, ln)
@@ -2762,8 +3020,8 @@ func (tv *TextView) SpellCheck(reg *textbuf.Edit) bool {
 	// fmt.Printf("spell err: %s\n", wb)
 	tv.Buf.Spell.Suggest = sugs
 	tv.Buf.Spell.Word = wb
-	tv.Buf.RemoveTag(reg.Reg.Start, token.TextSpellErr)
-	tv.Buf.AddTagEdit(reg, token.TextSpellErr)
+	tv.Buf.RemoveTag(reg.Reg.Start, histyle.SpellErr)
+	tv.Buf.AddTagEdit(reg, histyle.SpellErr)
 	ln := reg.Reg.Start.Ln
 	tv.LayoutLines(ln, ln, false)
 	tv.RenderLines(ln, ln)
@@ -3003,20 +3261,23 @@ func (tv *TextView) ScrollCursorToHorizCenter() bool {
 func (tv *TextView) CharStartPos(pos lex.Pos) mat32.Vec2 {
 	spos := tv.RenderStartPos()
 	spos.X += tv.LineNoOff
-	if pos.Ln >= len(tv.Offs) {
-		if len(tv.Offs) > 0 {
-			pos.Ln = len(tv.Offs) - 1
+	if pos.Ln >= tv.NLines {
+		if tv.NLines > 0 {
+			pos.Ln = tv.NLines - 1
 		} else {
 			return spos
 		}
 	} else {
-		spos.Y += tv.Offs[pos.Ln] + mat32.FromFixed(tv.Sty.Font.Face.Face.Metrics().Descent)
+		spos.Y += tv.LineOff(pos.Ln) + mat32.FromFixed(tv.Sty.Font.Face.Face.Metrics().Descent)
 	}
-	if len(tv.Renders[pos.Ln].Spans) > 0 {
+	if len(tv.LineRender(pos.Ln).Spans) > 0 {
 		// note: Y from rune pos is baseline
-		rrp, _, _, _ := tv.Renders[pos.Ln].RuneRelPos(pos.Ch)
+		rrp, si, _, _ := tv.LineRender(pos.Ln).RuneRelPos(pos.Ch)
+		if si >= 0 && tv.LineRender(pos.Ln).Spans[si].BidiLevels != nil {
+			rrp, _, _, _ = tv.LineRender(pos.Ln).CaretRelPos(pos.Ch)
+		}
 		spos.X += rrp.X
-		spos.Y += rrp.Y - tv.Renders[pos.Ln].Spans[0].RelPos.Y // relative
+		spos.Y += rrp.Y - tv.LineRender(pos.Ln).Spans[0].RelPos.Y // relative
 	}
 	return spos
 }
@@ -3037,13 +3298,13 @@ func (tv *TextView) CharEndPos(pos lex.Pos) mat32.Vec2 {
 	// 	spos.X += tv.LineNoOff
 	// 	return spos
 	// }
-	spos.Y += tv.Offs[pos.Ln] + mat32.FromFixed(tv.Sty.Font.Face.Face.Metrics().Descent)
+	spos.Y += tv.LineOff(pos.Ln) + mat32.FromFixed(tv.Sty.Font.Face.Face.Metrics().Descent)
 	spos.X += tv.LineNoOff
-	if len(tv.Renders[pos.Ln].Spans) > 0 {
+	if len(tv.LineRender(pos.Ln).Spans) > 0 {
 		// note: Y from rune pos is baseline
-		rrp, _, _, _ := tv.Renders[pos.Ln].RuneEndPos(pos.Ch)
+		rrp, _, _, _ := tv.LineRender(pos.Ln).RuneEndPos(pos.Ch)
 		spos.X += rrp.X
-		spos.Y += rrp.Y - tv.Renders[pos.Ln].Spans[0].RelPos.Y // relative
+		spos.Y += rrp.Y - tv.LineRender(pos.Ln).Spans[0].RelPos.Y // relative
 	}
 	spos.Y += tv.LineHeight // end of that line
 	return spos
@@ -3164,7 +3425,7 @@ func (tv *TextView) RenderCursor(on bool) {
 	if !tv.This().(gi.Node2D).IsVisible() {
 		return
 	}
-	if tv.Renders == nil {
+	if !tv.IsLaidOut() {
 		return
 	}
 	tv.CursorMu.Lock()
@@ -3177,7 +3438,10 @@ func (tv *TextView) RenderCursor(on bool) {
 	} else {
 		win.InactivateSprite(sp.Name)
 	}
-	sp.Geom.Pos = tv.CharStartPos(tv.CursorPos).ToPointFloor()
+	cpos := tv.CharStartPos(tv.CursorPos)
+	cpos.X += tv.IME.CursorOff()
+	sp.Geom.Pos = cpos.ToPointFloor()
+	gi.IMEUpdate(win, !tv.IsInactive(), image.Rectangle{Min: sp.Geom.Pos, Max: sp.Geom.Pos.Add(sp.Geom.Size)})
 	win.RenderOverlays() // needs an explicit call!
 	win.UpdateSig()      // publish
 }
@@ -3242,7 +3506,7 @@ func (tv *TextView) RenderDepthBg(stln, edln int) {
 	lstdp := 0
 	for ln := stln; ln <= edln; ln++ {
 		lst := tv.CharStartPos(lex.Pos{Ln: ln}).Y // note: charstart pos includes descent
-		led := lst + math32.Max(tv.Renders[ln].Size.Y, tv.LineHeight)
+		led := lst + tv.LineSizeY(ln)
 		if int(math32.Ceil(led)) < tv.VpBBox.Min.Y {
 			continue
 		}
@@ -3280,6 +3544,7 @@ func (tv *TextView) RenderDepthBg(stln, edln int) {
 // RenderSelect renders the selection region as a selected background color
 // -- always called within context of outer RenderLines or RenderAllLines
 func (tv *TextView) RenderSelect() {
+	tv.RenderCarets()
 	if !tv.HasSelection() {
 		return
 	}
@@ -3290,15 +3555,34 @@ func (tv *TextView) RenderSelect() {
 // color -- always called within context of outer RenderLines or
 // RenderAllLines
 func (tv *TextView) RenderHighlights(stln, edln int) {
+	if len(tv.Highlights) == 0 {
+		return
+	}
+	sty := &tv.StateStyles[TextViewHighlight]
+	bg := tv.SearchMatchColor()
 	for _, reg := range tv.Highlights {
 		reg := tv.Buf.AdjustReg(reg)
 		if reg.IsNil() || (stln >= 0 && (reg.Start.Ln > edln || reg.End.Ln < stln)) {
 			continue
 		}
-		tv.RenderRegionBox(reg, TextViewHighlight)
+		tv.RenderRegionBoxSty(reg, sty, bg)
 	}
 }
 
+// SearchMatchColor returns the background color for highlighting search
+// matches: from the histyle.SearchMatch tag in the highlighting style if it
+// has a background, and otherwise the TextViewHighlight state style
+func (tv *TextView) SearchMatchColor() *gist.ColorSpec {
+	if hs := tv.Buf.Hi.HiStyle; hs != nil {
+		if se := hs.TagRaw(histyle.SearchMatch); !se.Background.IsNil() {
+			bg := &gist.ColorSpec{}
+			bg.SetColor(se.Background)
+			return bg
+		}
+	}
+	return &tv.StateStyles[TextViewHighlight].Font.BgColor
+}
+
 // RenderScopelights renders a highlight background color for regions
 // in the Scopelights list
 // -- always called within context of outer RenderLines or RenderAllLines
@@ -3379,9 +3663,20 @@ func (tv *TextView) RenderRegionBoxSty(reg textbuf.Region, sty *gist.Style, bgcl
 
 	// fmt.Printf("select: %v -- %v\n", st, ed)
 
-	stsi, _, _ := tv.WrappedLineNo(st)
-	edsi, _, _ := tv.WrappedLineNo(ed)
+	stsi, stri, _ := tv.WrappedLineNo(st)
+	edsi, edri, edok := tv.WrappedLineNo(ed)
 	if st.Ln == ed.Ln && stsi == edsi {
+		if sr := tv.LineRender(st.Ln).Spans[stsi]; sr.BidiLevels != nil {
+			if !edok {
+				edri = len(sr.Render)
+			}
+			// bidi text: range can be displayed in multiple pieces
+			for _, bx := range sr.RangeBoxesLR(stri, edri) {
+				bp := mat32.Vec2{sx + bx[0], spos.Y}
+				pc.FillBox(rs, bp, mat32.Vec2{bx[1] - bx[0], epos.Y - spos.Y}, bgclr)
+			}
+			return
+		}
 		pc.FillBox(rs, spos, epos.Sub(spos), bgclr) // same line, done
 		return
 	}
@@ -3498,9 +3793,12 @@ func (tv *TextView) RenderAllLinesInBounds() {
 	pos = tv.RenderStartPos()
 	stln := -1
 	edln := -1
-	for ln := 0; ln < tv.NLines; ln++ {
-		lst := pos.Y + tv.Offs[ln]
-		led := lst + math32.Max(tv.Renders[ln].Size.Y, tv.LineHeight)
+	if tv.LargeRenders != nil {
+		stln, edln = tv.VisibleLinesLarge(pos)
+	}
+	for ln := 0; ln < tv.NLines && tv.LargeRenders == nil; ln++ {
+		lst := pos.Y + tv.LineOff(ln)
+		led := lst + tv.LineSizeY(ln)
 		if int(math32.Ceil(led)) < tv.VpBBox.Min.Y {
 			continue
 		}
@@ -3537,16 +3835,24 @@ func (tv *TextView) RenderAllLinesInBounds() {
 		rs.Lock()
 	}
 	for ln := stln; ln <= edln; ln++ {
-		lst := pos.Y + tv.Offs[ln]
+		lst := pos.Y + tv.LineOff(ln)
 		lp := pos
 		lp.Y = lst
 		lp.X += tv.LineNoOff
-		tv.Renders[ln].Render(rs, lp) // not top pos -- already has baseline offset
+		if tv.IsLineHidden(ln) {
+			continue
+		}
+		tv.LineRender(ln).Render(rs, lp) // not top pos -- already has baseline offset
+	}
+	if tv.IME.IsActive() {
+		tv.IME.Layout(sty)
+		tv.IME.RenderAt(rs, tv.CharStartPos(tv.CursorPos), sty, tv.LineHeight)
 	}
 	rs.Unlock()
 	if tv.HasLineNos() {
 		rs.PopBounds()
 	}
+	tv.TextViewSig.Emit(tv.This(), int64(TextViewRendered), nil)
 }
 
 // RenderLineNosBoxAll renders the background for the line numbers in a darker shade
@@ -3589,7 +3895,7 @@ func (tv *TextView) RenderLineNosBox(st, ed int) {
 // and if vpUpload is true it uploads the rendered region to viewport directly
 // (only if totally separate from other updates)
 func (tv *TextView) RenderLineNo(ln int, defFill bool, vpUpload bool) {
-	if !tv.HasLineNos() || tv.Buf == nil {
+	if !tv.HasLineNos() || tv.Buf == nil || tv.IsLineHidden(ln) {
 		return
 	}
 
@@ -3632,6 +3938,9 @@ func (tv *TextView) RenderLineNo(ln int, defFill bool, vpUpload bool) {
 	lfmt := fmt.Sprintf("%d", tv.LineNoDigs)
 	lfmt = "%" + lfmt + "d"
 	lnstr := fmt.Sprintf(lfmt, ln+1)
+	if fm := tv.FoldMarker(ln); fm != "" {
+		lnstr += " " + fm
+	}
 	tv.LineNoRender.SetString(lnstr, &fst, &sty.UnContext, &sty.Text, true, 0, 0)
 	pos := mat32.Vec2{}
 	lst := tv.CharStartPos(lex.Pos{Ln: ln}).Y // note: charstart pos includes descent
@@ -3708,7 +4017,7 @@ func (tv *TextView) RenderLines(st, ed int) bool {
 	visEd := -1
 	for ln := st; ln <= ed; ln++ {
 		lst := tv.CharStartPos(lex.Pos{Ln: ln}).Y // note: charstart pos includes descent
-		led := lst + math32.Max(tv.Renders[ln].Size.Y, tv.LineHeight)
+		led := lst + tv.LineSizeY(ln)
 		if int(math32.Ceil(led)) < tv.VpBBox.Min.Y {
 			continue
 		}
@@ -3749,11 +4058,14 @@ func (tv *TextView) RenderLines(st, ed int) bool {
 			rs.Lock()
 		}
 		for ln := visSt; ln <= visEd; ln++ {
-			lst := pos.Y + tv.Offs[ln]
+			lst := pos.Y + tv.LineOff(ln)
 			lp := pos
 			lp.Y = lst
 			lp.X += tv.LineNoOff
-			tv.Renders[ln].Render(rs, lp) // not top pos -- already has baseline offset
+			if tv.IsLineHidden(ln) {
+				continue
+			}
+			tv.LineRender(ln).Render(rs, lp) // not top pos -- already has baseline offset
 		}
 		rs.Unlock()
 		if tv.HasLineNos() {
@@ -3840,7 +4152,7 @@ func (tv *TextView) PixelToCursor(pt image.Point) lex.Pos {
 		for ln := stln; ln < tv.NLines; ln++ {
 			ls := tv.CharStartPos(lex.Pos{Ln: ln}).Y - yoff
 			es := ls
-			es += math32.Max(tv.Renders[ln].Size.Y, tv.LineHeight)
+			es += tv.LineSizeY(ln)
 			if pt.Y >= int(math32.Floor(ls)) && pt.Y < int(math32.Ceil(es)) {
 				got = true
 				cln = ln
@@ -3866,26 +4178,37 @@ func (tv *TextView) PixelToCursor(pt image.Point) lex.Pos {
 
 	si := 0
 	spoff := 0
-	nspan := len(tv.Renders[cln].Spans)
+	nspan := len(tv.LineRender(cln).Spans)
 	lstY := tv.CharStartPos(lex.Pos{Ln: cln}).Y - yoff
 	if nspan > 1 {
 		si = int((float32(pt.Y) - lstY) / tv.LineHeight)
 		si = ints.MinInt(si, nspan-1)
 		si = ints.MaxInt(si, 0)
 		for i := 0; i < si; i++ {
-			spoff += len(tv.Renders[cln].Spans[i].Text)
+			spoff += len(tv.LineRender(cln).Spans[i].Text)
 		}
 		// fmt.Printf("si: %v  spoff: %v\n", si, spoff)
 	}
 
 	ri := sc
-	rsz := len(tv.Renders[cln].Spans[si].Text)
+	if si > 0 { // continuation lines are indented
+		ri = ints.MaxInt(ri-tv.WrapIndentChars(cln), 0)
+	}
+	rsz := len(tv.LineRender(cln).Spans[si].Text)
 	if rsz == 0 {
 		return lex.Pos{Ln: cln, Ch: spoff}
 	}
+	if sr := tv.LineRender(cln).Spans[si]; sr.BidiLevels != nil {
+		px := float32(pt.X) + xoff - (tv.RenderStartPos().X + tv.LineNoOff)
+		ri = sr.CaretAtPosLR(px)
+		if ri == rsz && si < nspan-1 { // end of wrapped line is start of next
+			ri--
+		}
+		return lex.Pos{Ln: cln, Ch: spoff + ri}
+	}
 	// fmt.Printf("sc: %v  rsz: %v\n", sc, rsz)
 
-	c, _ := tv.Renders[cln].SpanPosToRuneIdx(si, rsz-1) // end
+	c, _ := tv.LineRender(cln).SpanPosToRuneIdx(si, rsz-1) // end
 	rsp := math32.Floor(tv.CharStartPos(lex.Pos{Ln: cln, Ch: c}).X - xoff)
 	rep := math32.Ceil(tv.CharEndPos(lex.Pos{Ln: cln, Ch: c}).X - xoff)
 	if int(rep) < pt.X { // end of line
@@ -3899,7 +4222,7 @@ func (tv *TextView) PixelToCursor(pt image.Point) lex.Pos {
 	got := false
 	if ri < rsz {
 		for rii := ri; rii < rsz; rii++ {
-			c, _ := tv.Renders[cln].SpanPosToRuneIdx(si, rii)
+			c, _ := tv.LineRender(cln).SpanPosToRuneIdx(si, rii)
 			rsp = math32.Floor(tv.CharStartPos(lex.Pos{Ln: cln, Ch: c}).X - xoff)
 			rep = math32.Ceil(tv.CharEndPos(lex.Pos{Ln: cln, Ch: c}).X - xoff)
 			// fmt.Printf("trying c: %v for pt: %v xoff: %v rsp: %v, rep: %v\n", c, pt, xoff, rsp, rep)
@@ -3921,7 +4244,7 @@ func (tv *TextView) PixelToCursor(pt image.Point) lex.Pos {
 		ri = rsz - 1
 		// fmt.Printf("too big: %v\n", ri)
 		for rii := ri; rii >= 0; rii-- {
-			c, _ := tv.Renders[cln].SpanPosToRuneIdx(si, rii)
+			c, _ := tv.LineRender(cln).SpanPosToRuneIdx(si, rii)
 			rsp := math32.Floor(tv.CharStartPos(lex.Pos{Ln: cln, Ch: c}).X - xoff)
 			rep := math32.Ceil(tv.CharEndPos(lex.Pos{Ln: cln, Ch: c}).X - xoff)
 			// fmt.Printf("too big: trying c: %v for pt: %v rsp: %v, rep: %v\n", c, pt, rsp, rep)
@@ -4049,6 +4372,13 @@ func (tv *TextView) KeyInput(kt *key.ChordEvent) {
 		return
 	}
 
+	if km := tv.CurKeyMode(); km != nil {
+		kf = km.KeyInput(tv, kt, kf)
+		if kt.IsProcessed() {
+			return
+		}
+	}
+
 	// cancelAll cancels search, completer, and..
 	cancelAll := func() {
 		tv.CancelComplete()
@@ -4069,13 +4399,22 @@ func (tv *TextView) KeyInput(kt *key.ChordEvent) {
 
 	gotTabAI := false // got auto-indent tab this time
 
+	if tv.HasCarets() && tv.CaretsKeyInput(kt, kf) {
+		cancelAll()
+		return
+	}
+
 	// first all the keys that work for both inactive and active
 	switch kf {
 	case gi.KeyFunMoveRight:
 		cancelAll()
 		kt.SetProcessed()
 		tv.ShiftSelect(kt)
-		tv.CursorForward(1)
+		if tv.IsRTLLine(tv.CursorPos.Ln) {
+			tv.CursorBackward(1)
+		} else {
+			tv.CursorForward(1)
+		}
 		tv.ShiftSelectExtend(kt)
 		tv.ISpellKeyInput(kt)
 	case gi.KeyFunWordRight:
@@ -4088,7 +4427,11 @@ func (tv *TextView) KeyInput(kt *key.ChordEvent) {
 		cancelAll()
 		kt.SetProcessed()
 		tv.ShiftSelect(kt)
-		tv.CursorBackward(1)
+		if tv.IsRTLLine(tv.CursorPos.Ln) {
+			tv.CursorForward(1)
+		} else {
+			tv.CursorBackward(1)
+		}
 		tv.ShiftSelectExtend(kt)
 	case gi.KeyFunWordLeft:
 		cancelAll()
@@ -4151,6 +4494,22 @@ func (tv *TextView) KeyInput(kt *key.ChordEvent) {
 		kt.SetProcessed()
 		tv.ReMarkup()
 		tv.CursorRecenter()
+	case gi.KeyFunFold:
+		cancelAll()
+		kt.SetProcessed()
+		tv.FoldAtCursor()
+	case gi.KeyFunUnfold:
+		cancelAll()
+		kt.SetProcessed()
+		tv.UnfoldAtCursor()
+	case gi.KeyFunFoldCycle:
+		cancelAll()
+		kt.SetProcessed()
+		tv.FoldCycleAtCursor()
+	case gi.KeyFunSelectNext:
+		cancelAll()
+		kt.SetProcessed()
+		tv.SelectNext()
 	case gi.KeyFunSelectMode:
 		cancelAll()
 		kt.SetProcessed()
@@ -4495,14 +4854,14 @@ func (tv *TextView) OpenLink(tl *girl.TextLink) {
 // LinkAt returns link at given cursor position, if one exists there --
 // returns true and the link if there is a link, and false otherwise
 func (tv *TextView) LinkAt(pos lex.Pos) (*girl.TextLink, bool) {
-	if !(pos.Ln < len(tv.Renders) && len(tv.Renders[pos.Ln].Links) > 0) {
+	if !(pos.Ln < tv.NLines && len(tv.LineRender(pos.Ln).Links) > 0) {
 		return nil, false
 	}
 	cpos := tv.CharStartPos(pos).ToPointCeil()
 	cpos.Y += 2
 	cpos.X += 2
 	lpos := tv.CharStartPos(lex.Pos{Ln: pos.Ln})
-	rend := &tv.Renders[pos.Ln]
+	rend := tv.LineRender(pos.Ln)
 	for ti := range rend.Links {
 		tl := &rend.Links[ti]
 		tlb := tl.Bounds(rend, lpos)
@@ -4518,7 +4877,7 @@ func (tv *TextView) LinkAt(pos lex.Pos) (*girl.TextLink, bool) {
 func (tv *TextView) OpenLinkAt(pos lex.Pos) (*girl.TextLink, bool) {
 	tl, ok := tv.LinkAt(pos)
 	if ok {
-		rend := &tv.Renders[pos.Ln]
+		rend := tv.LineRender(pos.Ln)
 		st, _ := rend.SpanPosToRuneIdx(tl.StartSpan, tl.StartIdx)
 		ed, _ := rend.SpanPosToRuneIdx(tl.EndSpan, tl.EndIdx)
 		reg := textbuf.NewRegion(pos.Ln, st, pos.Ln, ed)
@@ -4546,7 +4905,18 @@ func (tv *TextView) MouseEvent(me *mouse.Event) {
 	case mouse.Left:
 		if me.Action == mouse.Press {
 			me.SetProcessed()
-			if _, got := tv.OpenLinkAt(newPos); got {
+			if me.HasAllModifier(key.Shift, key.Alt) {
+				tv.ColumnSelectMouse(newPos, true)
+				return
+			}
+			if me.HasAnyModifier(key.Alt) {
+				tv.CaretAdd(newPos)
+				return
+			}
+			tv.CaretsReset()
+			if fln := tv.FoldMarkerAt(pt, newPos); fln >= 0 {
+				tv.Buf.FoldToggle(fln)
+			} else if _, got := tv.OpenLinkAt(newPos); got {
 			} else {
 				tv.SetCursorFromMouse(pt, newPos, me.SelectMode())
 				tv.SavePosHistory(tv.CursorPos)
@@ -4604,9 +4974,9 @@ func (tv *TextView) MouseMoveEvent() {
 			return
 		}
 		pos := tv.RenderStartPos()
-		pos.Y += tv.Offs[mpos.Ln]
+		pos.Y += tv.LineOff(mpos.Ln)
 		pos.X += tv.LineNoOff
-		rend := &tvv.Renders[mpos.Ln]
+		rend := tvv.LineRender(mpos.Ln)
 		inLink := false
 		for _, tl := range rend.Links {
 			tlb := tl.Bounds(rend, pos)
@@ -4629,11 +4999,15 @@ func (tv *TextView) MouseDragEvent() {
 		me := d.(*mouse.DragEvent)
 		me.SetProcessed()
 		txf := recv.Embed(KiT_TextView).(*TextView)
+		pt := txf.PointToRelPos(me.Pos())
+		newPos := txf.PixelToCursor(pt)
+		if me.HasAllModifier(key.Shift, key.Alt) {
+			txf.ColumnSelectMouse(newPos, false)
+			return
+		}
 		if !txf.SelectMode {
 			txf.SelectModeToggle()
 		}
-		pt := txf.PointToRelPos(me.Pos())
-		newPos := txf.PixelToCursor(pt)
 		txf.SetCursorFromMouse(pt, newPos, mouse.SelectOne)
 	})
 }
@@ -4655,6 +5029,27 @@ func (tv *TextView) MouseFocusEvent() {
 	})
 }
 
+// IMEInput handles an input method composition event: the composing text
+// is displayed at the cursor until it is committed, when it is inserted
+func (tv *TextView) IMEInput(e *ime.Event) {
+	if tv.IsInactive() || tv.Buf == nil {
+		return
+	}
+	e.SetProcessed()
+	switch e.Action {
+	case ime.Preedit:
+		tv.IME.SetText(e.Text, e.Cursor)
+		tv.RenderAllLines()
+	case ime.Commit:
+		tv.IME.Clear()
+		if e.Text != "" {
+			tv.InsertAtCursor([]byte(e.Text))
+		} else {
+			tv.RenderAllLines()
+		}
+	}
+}
+
 // TextViewEvents sets connections between mouse and key events and actions
 func (tv *TextView) TextViewEvents() {
 	tv.HoverTooltipEvent()
@@ -4671,6 +5066,10 @@ func (tv *TextView) TextViewEvents() {
 		kt := d.(*key.ChordEvent)
 		txf.KeyInput(kt)
 	})
+	tv.ConnectEvent(oswin.IMEEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		txf := recv.Embed(KiT_TextView).(*TextView)
+		txf.IMEInput(d.(*ime.Event))
+	})
 	if dlg, ok := tv.Viewport.This().(*gi.Dialog); ok {
 		dlg.DialogSig.Connect(tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 			txf, _ := recv.Embed(KiT_TextView).(*TextView)
@@ -4716,6 +5115,7 @@ func (tv *TextView) StyleTextView() {
 	for i := 0; i < int(TextViewStatesN); i++ {
 		tv.StateStyles[i].CopyFrom(&tv.Sty)
 		tv.StateStyles[i].SetStyleProps(pst, tv.StyleProps(TextViewSelectors[i]), tv.Viewport)
+		gi.ApplyAppStyles(tv.This().(gi.Node2D), tv.Viewport, &tv.StateStyles[i], pst, TextViewSelectors[i])
 		gi.StyleCSS(tv.This().(gi.Node2D), tv.Viewport, &tv.StateStyles[i], tv.CSSAgg, TextViewSelectors[i])
 		tv.StateStyles[i].CopyUnitContext(&tv.Sty.UnContext)
 	}
@@ -4834,6 +5234,8 @@ func (tv *TextView) FocusChanged2D(change gi.FocusChanges) {
 	case gi.FocusLost:
 		tv.ClearFlag(int(TextViewFocusActive))
 		// tv.EditDone()
+		tv.IME.Clear()
+		gi.IMEUpdate(tv.ParentWindow(), false, image.Rectangle{})
 		tv.StopCursor() // make sure no cursor
 		tv.UpdateSig()
 		// fmt.Printf("lost focus: %v\n", tv.Nm)
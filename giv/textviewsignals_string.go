@@ -18,12 +18,14 @@ func _() {
 	_ = x[TextViewCursorMoved-2]
 	_ = x[TextViewISearch-3]
 	_ = x[TextViewQReplace-4]
-	_ = x[TextViewSignalsN-5]
+	_ = x[TextViewRendered-5]
+	_ = x[TextViewEditMode-6]
+	_ = x[TextViewSignalsN-7]
 }
 
-const _TextViewSignals_name = "TextViewDoneTextViewSelectedTextViewCursorMovedTextViewISearchTextViewQReplaceTextViewSignalsN"
+const _TextViewSignals_name = "TextViewDoneTextViewSelectedTextViewCursorMovedTextViewISearchTextViewQReplaceTextViewRenderedTextViewEditModeTextViewSignalsN"
 
-var _TextViewSignals_index = [...]uint8{0, 12, 28, 47, 62, 78, 94}
+var _TextViewSignals_index = [...]uint8{0, 12, 28, 47, 62, 78, 94, 110, 126}
 
 func (i TextViewSignals) String() string {
 	if i < 0 || i >= TextViewSignals(len(_TextViewSignals_index)-1) {
@@ -52,6 +52,10 @@ type TreeView struct {
 	WidgetSize       mat32.Vec2                  `desc:"just the size of our widget -- our alloc includes all of our children, but we only draw us"`
 	Icon             gi.IconName                 `json:"-" xml:"icon" view:"show-name" desc:"optional icon, displayed to the the left of the text label"`
 	RootView         *TreeView                   `json:"-" xml:"-" desc:"cached root of the view"`
+	undoPars         []ki.Ki                     // source nodes whose children are saved for undo, on RootView -- see SrcUndoStart
+	undoKids         []ki.Slice                  // children of undoPars before the change
+	undoCopy         ki.Ki                       // copy of the first of undoPars before an assignment to it -- see SrcUndoStartCopy
+	undoLbl          string                      // label of the change
 }
 
 var KiT_TreeView = kit.Types.AddType(&TreeView{}, nil)
@@ -68,6 +72,17 @@ func (tv *TreeView) Disconnect() {
 	tv.TreeViewSig.DisconnectAll()
 }
 
+// AccessInfo describes the tree item for assistive technologies -- its
+// children are the child items
+func (tv *TreeView) AccessInfo(an *oswin.AccessNode) {
+	an.Role = oswin.RoleTreeItem
+	an.Name = gi.AccessText(tv.Label())
+	if tv.HasChildren() {
+		an.SetState(true, oswin.StateExpandable)
+		an.SetState(!tv.IsClosed(), oswin.StateExpanded)
+	}
+}
+
 func init() {
 	kit.Types.SetProps(KiT_TreeView, TreeViewProps)
 }
@@ -238,10 +253,127 @@ func (tv *TreeView) SetChanged() {
 	if tv.RootView == nil {
 		return
 	}
+	if tv.RootView.undoPars != nil {
+		tv.RootView.saveSrcUndo()
+	}
 	tv.RootView.SetFlag(int(TreeViewFlagChanged))
 	tv.RootView.TreeViewSig.Emit(tv.RootView.This(), int64(TreeViewChanged), tv.This())
 }
 
+// SrcUndoStart saves the children of given source nodes, which are the
+// parents of the nodes added, deleted or moved by a gui action with given
+// label, e.g., Delete, before the change -- the SetChanged call after the
+// change then saves an undo record to the UndoMgr of the window, which
+// restores the saved children, and redoes the change by restoring the
+// children after it.  The nodes themselves are restored, not copies, so
+// pointers to them remain valid, and the nodes deleted by the change must
+// not be destroyed (see srcUndoDestroy).  It must be followed by a deferred
+// SrcUndoCancel, so nothing is left if the change does not reach SetChanged.
+func (tv *TreeView) SrcUndoStart(label string, pars ...ki.Ki) {
+	rv := tv.RootView
+	if rv == nil || ViewUndoMgr(tv.This().(gi.Node2D)) == nil {
+		return
+	}
+	rv.SrcUndoCancel()
+	for _, par := range pars {
+		if par == nil || par.IsDestroyed() {
+			continue
+		}
+		dup := false
+		for _, p := range rv.undoPars {
+			dup = dup || p == par
+		}
+		if !dup {
+			rv.undoPars = append(rv.undoPars, par)
+			rv.undoKids = append(rv.undoKids, append(ki.Slice(nil), *par.Children()...))
+		}
+	}
+	rv.undoLbl = label
+}
+
+// SrcUndoStartCopy is SrcUndoStart for an assignment to given source node
+// by a gui action with given label, e.g., Paste Assign, which also saves a
+// copy of the node, to restore its fields (and those of its children)
+func (tv *TreeView) SrcUndoStartCopy(label string, sn ki.Ki) {
+	if sn == nil || sn.TypeEmbeds(gi.KiT_Window) {
+		return
+	}
+	tv.SrcUndoStart(label, sn)
+	if rv := tv.RootView; rv != nil && rv.undoPars != nil {
+		rv.undoCopy = sn.Clone()
+	}
+}
+
+// SrcUndoCancel drops anything saved by SrcUndoStart that has not been
+// saved as an undo record by SetChanged
+func (tv *TreeView) SrcUndoCancel() {
+	rv := tv.RootView
+	if rv == nil {
+		return
+	}
+	rv.undoPars, rv.undoKids, rv.undoCopy, rv.undoLbl = nil, nil, nil, ""
+}
+
+// srcUndoDestroy returns whether source nodes deleted by a gui action are
+// destroyed, which is not the case when SrcUndoStart has saved them, to be
+// restored by undo
+func (tv *TreeView) srcUndoDestroy() bool {
+	return tv.RootView == nil || tv.RootView.undoPars == nil
+}
+
+// saveSrcUndo saves the undo record for the children saved by SrcUndoStart
+func (tv *TreeView) saveSrcUndo() {
+	pars, prv, pcp, lbl := tv.undoPars, tv.undoKids, tv.undoCopy, tv.undoLbl
+	tv.SrcUndoCancel()
+	nxt := make([]ki.Slice, len(pars))
+	for i, par := range pars {
+		if !par.IsDestroyed() {
+			nxt[i] = append(ki.Slice(nil), *par.Children()...)
+		}
+	}
+	var ncp ki.Ki
+	if pcp != nil && !pars[0].IsDestroyed() {
+		ncp = pars[0].Clone()
+	}
+	ViewUndoMgr(tv.This().(gi.Node2D)).Save(lbl, func() {
+		srcUndoRestore(pars, prv, pcp)
+	}, func() {
+		srcUndoRestore(pars, nxt, ncp)
+	})
+}
+
+// srcUndoRestore restores the children of given source nodes, and the copy
+// of the first one, if any, for the undo and redo of SrcUndoStart
+func srcUndoRestore(pars []ki.Ki, kids []ki.Slice, cp ki.Ki) {
+	for i, par := range pars {
+		if par.IsDestroyed() {
+			continue
+		}
+		updt := par.UpdateStart()
+		keep := make(map[ki.Ki]bool, len(kids[i]))
+		for _, k := range kids[i] {
+			keep[k] = true
+		}
+		cur := *par.Children()
+		for j := len(cur) - 1; j >= 0; j-- {
+			if !keep[cur[j]] {
+				par.DeleteChildAtIndex(j, false) // kept for redo
+			}
+		}
+		for j, k := range kids[i] {
+			if k.Parent() != par.This() {
+				par.InsertChild(k, j)
+			} else if idx, ok := k.IndexInParent(); ok && idx != j {
+				par.MoveChild(idx, j)
+			}
+		}
+		par.UpdateEnd(updt)
+	}
+	if cp != nil && !pars[0].IsDestroyed() {
+		pars[0].CopyFrom(cp)
+	}
+}
+
 // HasClosedParent returns whether this node have a closed parent? if so, don't render!
 func (tv *TreeView) HasClosedParent() bool {
 	pcol := false
@@ -262,12 +394,28 @@ func (tv *TreeView) HasClosedParent() bool {
 	return pcol
 }
 
+// TreeViewBadger is implemented by source nodes that show a badge after
+// their label in a TreeView, e.g., the version control status of a FileNode
+type TreeViewBadger interface {
+	// TreeViewBadge returns the badge for the node -- "" for none
+	TreeViewBadge() string
+}
+
 // Label returns the display label for this node, satisfying the Labeler interface
 func (tv *TreeView) Label() string {
-	if lbl, has := gi.ToLabeler(tv.SrcNode); has {
-		return lbl
+	lbl, has := gi.ToLabeler(tv.SrcNode)
+	if !has {
+		lbl = tv.SrcNode.Name()
+	}
+	if bd, ok := tv.SrcNode.(TreeViewBadger); ok {
+		if bdg := bd.TreeViewBadge(); bdg != "" {
+			lbl += "  " + bdg
+		}
+	}
+	if tv.IsLoading() {
+		lbl += TreeViewLoadingText
 	}
-	return tv.SrcNode.Name()
+	return lbl
 }
 
 // UpdateInactive updates the Inactive state based on SrcNode -- returns true if
@@ -357,6 +505,10 @@ const (
 	// be restyled on any full re-render change
 	TreeViewFlagNoTemplate
 
+	// TreeViewFlagLoading means the children of the source node are being
+	// loaded in the background -- see TreeViewLoader
+	TreeViewFlagLoading
+
 	TreeViewFlagsN
 )
 
@@ -854,6 +1006,9 @@ func (tv *TreeView) Open() {
 		if tv.HasChildren() {
 			tv.SetFullReRender()
 		}
+		if ld, ok := tv.Loader(); ok {
+			tv.LoadChildren(ld)
+		}
 		if tv.HasChildren() {
 			tv.SetClosedState(false)
 		}
@@ -906,6 +1061,36 @@ func (tv *TreeView) CloseAll() {
 	tv.TopUpdateEnd(wupdt)
 }
 
+// OpenParents opens all the parents of this node, so that it is shown
+func (tv *TreeView) OpenParents() {
+	wupdt := tv.TopUpdateStart()
+	for par := tv.TreeViewParent(); par != nil; par = par.TreeViewParent() {
+		par.Open()
+	}
+	tv.TopUpdateEnd(wupdt)
+}
+
+// FindSrcNode returns the TreeView viewing given source node, among this
+// node and its sub-nodes, or nil if not found
+func (tv *TreeView) FindSrcNode(sk ki.Ki) *TreeView {
+	var rval *TreeView
+	tv.FuncDownMeFirst(0, tv.This(), func(k ki.Ki, level int, d interface{}) bool {
+		if rval != nil {
+			return ki.Break
+		}
+		tvki := k.Embed(KiT_TreeView)
+		if tvki == nil {
+			return ki.Break
+		}
+		if tvn := tvki.(*TreeView); tvn.SrcNode == sk {
+			rval = tvn
+			return ki.Break
+		}
+		return ki.Continue
+	})
+	return rval
+}
+
 //////////////////////////////////////////////////////////////////////////////
 //    Modifying Source Tree
 
@@ -993,6 +1178,8 @@ func (tv *TreeView) SrcInsertAt(rel int, actNm string) {
 				par := tvv.SrcNode
 				dlg, _ := send.(*gi.Dialog)
 				n, typ := gi.NewKiDialogValues(dlg)
+				tvv.SrcUndoStart(gi.T(actNm), par)
+				defer tvv.SrcUndoCancel()
 				updt := par.UpdateStart()
 				var ski ki.Ki
 				for i := 0; i < n; i++ {
@@ -1031,6 +1218,8 @@ func (tv *TreeView) SrcAddChild() {
 				sk := tvv.SrcNode
 				dlg, _ := send.(*gi.Dialog)
 				n, typ := gi.NewKiDialogValues(dlg)
+				tvv.SrcUndoStart(gi.T(ttl), sk)
+				defer tvv.SrcUndoCancel()
 				updt := sk.UpdateStart()
 				var ski ki.Ki
 				for i := 0; i < n; i++ {
@@ -1067,7 +1256,9 @@ func (tv *TreeView) SrcDelete() {
 		log.Printf("TreeView %v nil SrcNode in: %v\n", ttl, tv.PathUnique())
 		return
 	}
-	sk.Delete(true)
+	tv.SrcUndoStart(gi.T("Delete Node"), sk.Parent())
+	defer tv.SrcUndoCancel()
+	sk.Delete(tv.srcUndoDestroy())
 	tv.SetChanged()
 }
 
@@ -1100,6 +1291,8 @@ func (tv *TreeView) SrcDuplicate() {
 	nm := fmt.Sprintf("%v_Copy", sk.Name())
 	nwkid := sk.Clone()
 	nwkid.SetName(nm)
+	tv.SrcUndoStart(gi.T("Duplicate"), par)
+	defer tv.SrcUndoCancel()
 	par.InsertChild(nwkid, myidx+1)
 	tvpar.SetChanged()
 	if tvk := tvpar.ChildByName("tv_"+nm, 0); tvk != nil {
@@ -1191,8 +1384,15 @@ func (tv *TreeView) Cut() {
 	tv.Copy(false)
 	sels := tv.SelectedSrcNodes()
 	tv.UnselectAll()
+	pars := make([]ki.Ki, len(sels))
+	for i, sn := range sels {
+		pars[i] = sn.Parent()
+	}
+	tv.SrcUndoStart(gi.T("Cut"), pars...)
+	defer tv.SrcUndoCancel()
+	destroy := tv.srcUndoDestroy()
 	for _, sn := range sels {
-		sn.Delete(true)
+		sn.Delete(destroy)
 	}
 	tv.SetChanged()
 }
@@ -1259,6 +1459,8 @@ func (tv *TreeView) PasteAssign(md mimedata.Mimes) {
 		log.Printf("TreeView PasteAssign nil SrcNode in: %v\n", tv.PathUnique())
 		return
 	}
+	tv.SrcUndoStartCopy(gi.T("Paste"), sk)
+	defer tv.SrcUndoCancel()
 	sk.CopyFrom(sl[0])
 	tv.SetChanged()
 }
@@ -1302,6 +1504,8 @@ func (tv *TreeView) PasteAt(md mimedata.Mimes, mod dnd.DropMods, rel int, actNm
 		return
 	}
 	myidx += rel
+	tv.SrcUndoStart(gi.T(actNm), par)
+	defer tv.SrcUndoCancel()
 	updt := par.UpdateStart()
 	sz := len(sl)
 	var ski ki.Ki
@@ -1336,6 +1540,8 @@ func (tv *TreeView) PasteChildren(md mimedata.Mimes, mod dnd.DropMods) {
 		log.Printf("TreeView PasteChildren nil SrcNode in: %v\n", tv.PathUnique())
 		return
 	}
+	tv.SrcUndoStart(gi.T("Paste"), sk)
+	defer tv.SrcUndoCancel()
 	updt := sk.UpdateStart()
 	for _, ns := range sl {
 		sk.AddChild(ns)
@@ -1417,16 +1623,23 @@ func (tv *TreeView) Dragged(de *dnd.Event) {
 		return
 	}
 	sroot := tv.RootView.SrcNode
-	md := de.Data
-	for _, d := range md {
+	var sns, pars []ki.Ki
+	for _, d := range de.Data {
 		if d.Type == filecat.TextPlain { // link
 			path := string(d.Data)
-			sn := sroot.FindPathUnique(path)
-			if sn != nil {
-				sn.Delete(true)
+			if sn := sroot.FindPathUnique(path); sn != nil {
+				sns = append(sns, sn)
+				pars = append(pars, sn.Parent())
 			}
 		}
 	}
+	tv.SrcUndoStart(gi.T("Move"), pars...)
+	defer tv.SrcUndoCancel()
+	destroy := tv.srcUndoDestroy()
+	for _, sn := range sns {
+		sn.Delete(destroy)
+	}
+	tv.SetChanged()
 }
 
 // MakeDropMenu makes the menu of options for dropping on a target
@@ -1489,20 +1702,29 @@ func (tv *TreeView) DropAssign(md mimedata.Mimes) {
 
 // DropBefore inserts object(s) from mime data before this node
 func (tv *TreeView) DropBefore(md mimedata.Mimes, mod dnd.DropMods) {
+	um := ViewUndoMgr(tv.This().(gi.Node2D))
+	um.StartGroup(gi.T("Drop"))
 	tv.PasteBefore(md, mod)
 	tv.DragNDropFinalize(mod)
+	um.EndGroup()
 }
 
 // DropAfter inserts object(s) from mime data after this node
 func (tv *TreeView) DropAfter(md mimedata.Mimes, mod dnd.DropMods) {
+	um := ViewUndoMgr(tv.This().(gi.Node2D))
+	um.StartGroup(gi.T("Drop"))
 	tv.PasteAfter(md, mod)
 	tv.DragNDropFinalize(mod)
+	um.EndGroup()
 }
 
 // DropChildren inserts object(s) from mime data at end of children of this node
 func (tv *TreeView) DropChildren(md mimedata.Mimes, mod dnd.DropMods) {
+	um := ViewUndoMgr(tv.This().(gi.Node2D))
+	um.StartGroup(gi.T("Drop"))
 	tv.PasteChildren(md, mod)
 	tv.DragNDropFinalize(mod)
+	um.EndGroup()
 }
 
 // DropCancel cancels the drop action e.g., preventing deleting of source
@@ -1663,7 +1885,7 @@ func (tv *TreeView) TreeViewEvents() {
 			tvv.Open()
 		}
 	})
-	if tv.HasChildren() {
+	if tv.HasBranch() {
 		if wb, ok := tv.BranchPart(); ok {
 			wb.ButtonSig.ConnectOnly(tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 				if sig == int64(gi.ButtonToggled) {
@@ -1742,7 +1964,7 @@ func (tv *TreeView) ConfigParts() {
 	tv.Parts.Lay = gi.LayoutHoriz
 	tv.Parts.Sty.Template = "giv.TreeView.Parts"
 	config := kit.TypeAndNameList{}
-	if tv.HasChildren() {
+	if tv.HasBranch() {
 		config.Add(gi.KiT_CheckBox, "branch")
 	}
 	if tv.Icon.IsValid() {
@@ -1751,7 +1973,7 @@ func (tv *TreeView) ConfigParts() {
 	config.Add(gi.KiT_Label, "label")
 	mods, updt := tv.Parts.ConfigChildren(config, ki.NonUniqueNames)
 	// if mods {
-	if tv.HasChildren() {
+	if tv.HasBranch() {
 		if wb, ok := tv.BranchPart(); ok {
 			wb.SetProp("#icon0", TVBranchProps)
 			wb.SetProp("#icon1", TVBranchProps)
@@ -1821,7 +2043,7 @@ func (tv *TreeView) ConfigPartsIfNeeded() {
 			lbl.SetText(ltxt)
 		}
 	}
-	if tv.HasChildren() {
+	if tv.HasBranch() {
 		if wb, ok := tv.BranchPart(); ok {
 			wb.SetChecked(!tv.IsClosed())
 		}
@@ -2009,6 +2231,7 @@ func (tv *TreeView) StyleTreeView() {
 		for i := 0; i < int(TreeViewStatesN); i++ {
 			tv.StateStyles[i].CopyFrom(&tv.Sty)
 			tv.StateStyles[i].SetStyleProps(pst, tv.StyleProps(TreeViewSelectors[i]), tv.Viewport)
+			gi.ApplyAppStyles(tv.This().(gi.Node2D), tv.Viewport, &tv.StateStyles[i], pst, TreeViewSelectors[i])
 			tv.StateStyles[i].CopyUnitContext(&tv.Sty.UnContext)
 		}
 	}
@@ -2166,8 +2389,12 @@ func (tv *TreeView) PushBounds() bool {
 		tv.ClearFullReRender()
 		return false
 	}
+	bb, ok := tv.ViewportSafe().DirtyBounds(tv.VpBBox)
+	if !ok { // outside of the dirty region being re-rendered
+		return false
+	}
 	rs := tv.Render()
-	rs.PushBounds(tv.VpBBox)
+	rs.PushBounds(bb)
 	tv.ConnectToViewport()
 	if gi.Render2DTrace {
 		fmt.Printf("Render: %v at %v\n", tv.PathUnique(), tv.VpBBox)
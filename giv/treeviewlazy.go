@@ -0,0 +1,84 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+// TreeViewLoader is an interface for source nodes whose children are only
+// loaded when the node is opened in a TreeView, so that huge trees (e.g.,
+// filesystems or remote data) do not need to be built in full up front.
+// Such nodes are shown closed, with a branch toggle, until they are opened,
+// at which point LoadChildren is called in a separate goroutine, with the
+// label of the node showing TreeViewLoadingText until it returns.
+type TreeViewLoader interface {
+	// HasChildrenToLoad returns true if the node has children that have not
+	// been loaded yet -- it should be fast, and not load anything itself
+	HasChildrenToLoad() bool
+
+	// LoadChildren loads the children of the node, adding them as Ki
+	// children of it -- it can take as long as needed, as it is called in
+	// a separate goroutine, and HasChildrenToLoad must return false after
+	LoadChildren()
+}
+
+// TreeViewLoadingText is added to the label of nodes whose children are
+// being loaded -- see TreeViewLoader
+var TreeViewLoadingText = "  ⏳ loading…"
+
+// Loader returns the SrcNode as a TreeViewLoader, and true if it has
+// children to load
+func (tv *TreeView) Loader() (TreeViewLoader, bool) {
+	ld, ok := tv.SrcNode.(TreeViewLoader)
+	if !ok || !ld.HasChildrenToLoad() {
+		return nil, false
+	}
+	return ld, true
+}
+
+// IsLoading returns whether the children of this node are being loaded
+func (tv *TreeView) IsLoading() bool {
+	return tv.HasFlag(int(TreeViewFlagLoading))
+}
+
+// HasBranch returns true if this node has children, or children to load,
+// and thus shows a branch toggle
+func (tv *TreeView) HasBranch() bool {
+	if tv.HasChildren() {
+		return true
+	}
+	_, ok := tv.Loader()
+	return ok
+}
+
+// LoadChildren starts loading the children of the source node in a separate
+// goroutine, if not already doing so -- the node is opened when done
+func (tv *TreeView) LoadChildren(ld TreeViewLoader) {
+	if tv.IsLoading() {
+		return
+	}
+	tv.SetFlag(int(TreeViewFlagLoading))
+	tv.ConfigPartsIfNeeded()
+	tv.UpdateSig()
+	go func() {
+		ld.LoadChildren()
+		tv.LoadDone()
+	}()
+}
+
+// LoadDone is called when the children of the source node have been loaded,
+// to sync the view to them and open the node
+func (tv *TreeView) LoadDone() {
+	if tv.This() == nil || tv.IsDeleted() || tv.SrcNode == nil {
+		return
+	}
+	wupdt := tv.TopUpdateStart()
+	updt := tv.UpdateStart()
+	tv.ClearFlag(int(TreeViewFlagLoading))
+	tvIdx := tv.ViewIdx
+	tv.SyncToSrc(&tvIdx, false, 0)
+	tv.SetClosedState(!tv.HasChildren())
+	tv.SetFullReRender()
+	tv.RootView.TreeViewSig.Emit(tv.RootView.This(), int64(TreeViewOpened), tv.This())
+	tv.UpdateEnd(updt)
+	tv.TopUpdateEnd(wupdt)
+}
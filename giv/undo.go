@@ -0,0 +1,285 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// UndoMax is the maximum number of records on the stack of an UndoMgr --
+// the oldest groups of records are dropped beyond that
+var UndoMax = 100
+
+// UndoRec is one record on the stack of an UndoMgr: an edit that is undone
+// and redone by calling its Undo and Redo functions
+type UndoRec struct {
+	Label string `desc:"label of the edit for menus, e.g., Delete Row, shown as Undo Delete Row"`
+	Group int    `desc:"group of the record -- all the records of a group are undone and redone together"`
+	Undo  func() `desc:"function that undoes the edit"`
+	Redo  func() `desc:"function that redoes the edit"`
+}
+
+// UndoMgr is a generic undo / redo manager for edits in views, each of which
+// is saved as an UndoRec with functions that undo and redo it -- it follows
+// the design of the textbuf.Undo manager of TextBuf, which remains specific
+// to text edits.  Each window has its own, from WindowUndoMgr, shared by the
+// StructView, TableView, SliceView and TreeView views in it, and used by the
+// Undo and Redo key functions of the window when the focus does not handle
+// them.  All methods are safe to call on a nil UndoMgr, which does nothing.
+type UndoMgr struct {
+	Off        bool       `desc:"if true, saving and using undos is turned off"`
+	Stack      []*UndoRec `desc:"undo stack of edits"`
+	Pos        int        `desc:"undo position in stack -- records from Pos on have been undone and can be redone"`
+	Group      int        `desc:"group counter"`
+	GroupLabel string     `desc:"label of the group started by StartGroup, used for all of its records"`
+	GroupDepth int        `desc:"depth of nested StartGroup calls -- records are in the same group while > 0"`
+	Mu         sync.Mutex `json:"-" xml:"-" desc:"mutex protecting all updates"`
+	undoing    bool
+}
+
+// Save saves an undo record for an edit with given label, which has just
+// been made, and can be undone and redone with given functions -- it is in
+// a new group of its own unless a group was started with StartGroup.  Any
+// records that were undone are discarded.  Nothing is saved while undoing or
+// redoing.
+func (um *UndoMgr) Save(label string, undo, redo func()) {
+	if um == nil || um.Off {
+		return
+	}
+	um.Mu.Lock()
+	defer um.Mu.Unlock()
+	if um.undoing {
+		return
+	}
+	um.Stack = um.Stack[:um.Pos]
+	if um.GroupDepth == 0 {
+		um.Group++
+	} else if um.GroupLabel != "" {
+		label = um.GroupLabel
+	}
+	um.Stack = append(um.Stack, &UndoRec{Label: label, Group: um.Group, Undo: undo, Redo: redo})
+	for len(um.Stack) > UndoMax {
+		gp := um.Stack[0].Group
+		n := 0
+		for n < len(um.Stack) && um.Stack[n].Group == gp {
+			n++
+		}
+		um.Stack = um.Stack[n:]
+	}
+	um.Pos = len(um.Stack)
+}
+
+// StartGroup starts a group of records with given label, e.g., for deleting
+// several rows, which are undone and redone together as one edit -- must be
+// followed by EndGroup.  Groups can be nested, in which case the outermost
+// one is used.
+func (um *UndoMgr) StartGroup(label string) {
+	if um == nil {
+		return
+	}
+	um.Mu.Lock()
+	if um.GroupDepth == 0 {
+		um.Group++
+		um.GroupLabel = label
+	}
+	um.GroupDepth++
+	um.Mu.Unlock()
+}
+
+// EndGroup ends the group started by StartGroup
+func (um *UndoMgr) EndGroup() {
+	if um == nil {
+		return
+	}
+	um.Mu.Lock()
+	if um.GroupDepth > 0 {
+		um.GroupDepth--
+	}
+	if um.GroupDepth == 0 {
+		um.GroupLabel = ""
+	}
+	um.Mu.Unlock()
+}
+
+// Reset clears all undo records
+func (um *UndoMgr) Reset() {
+	if um == nil {
+		return
+	}
+	um.Mu.Lock()
+	um.Stack = nil
+	um.Pos = 0
+	um.Mu.Unlock()
+}
+
+// Undo undoes the last group of records on the stack -- returns false if
+// there is nothing to undo
+func (um *UndoMgr) Undo() bool {
+	if um == nil || um.Off {
+		return false
+	}
+	um.Mu.Lock()
+	if um.undoing || um.Pos == 0 {
+		um.Mu.Unlock()
+		return false
+	}
+	var recs []*UndoRec
+	gp := um.Stack[um.Pos-1].Group
+	for um.Pos > 0 && um.Stack[um.Pos-1].Group == gp {
+		um.Pos--
+		recs = append(recs, um.Stack[um.Pos])
+	}
+	um.undoing = true
+	um.Mu.Unlock()
+	for _, rec := range recs {
+		rec.Undo()
+	}
+	um.Mu.Lock()
+	um.undoing = false
+	um.Mu.Unlock()
+	return true
+}
+
+// Redo redoes the next group of records on the stack that was undone --
+// returns false if there is nothing to redo
+func (um *UndoMgr) Redo() bool {
+	if um == nil || um.Off {
+		return false
+	}
+	um.Mu.Lock()
+	if um.undoing || um.Pos >= len(um.Stack) {
+		um.Mu.Unlock()
+		return false
+	}
+	var recs []*UndoRec
+	gp := um.Stack[um.Pos].Group
+	for um.Pos < len(um.Stack) && um.Stack[um.Pos].Group == gp {
+		recs = append(recs, um.Stack[um.Pos])
+		um.Pos++
+	}
+	um.undoing = true
+	um.Mu.Unlock()
+	for _, rec := range recs {
+		rec.Redo()
+	}
+	um.Mu.Lock()
+	um.undoing = false
+	um.Mu.Unlock()
+	return true
+}
+
+// UndoLabel returns the label of the edit that Undo undoes -- "" if none
+func (um *UndoMgr) UndoLabel() string {
+	if um == nil {
+		return ""
+	}
+	um.Mu.Lock()
+	defer um.Mu.Unlock()
+	if um.Pos == 0 {
+		return ""
+	}
+	return um.Stack[um.Pos-1].Label
+}
+
+// RedoLabel returns the label of the edit that Redo redoes -- "" if none
+func (um *UndoMgr) RedoLabel() string {
+	if um == nil {
+		return ""
+	}
+	um.Mu.Lock()
+	defer um.Mu.Unlock()
+	if um.Pos >= len(um.Stack) {
+		return ""
+	}
+	return um.Stack[um.Pos].Label
+}
+
+// WindowUndoMgr returns the UndoMgr of given window, creating it if needed
+// -- it is stored as the "undo-mgr" property of the window.  Returns nil for
+// a nil window.
+func WindowUndoMgr(win *gi.Window) *UndoMgr {
+	if win == nil {
+		return nil
+	}
+	if um, ok := win.Prop("undo-mgr").(*UndoMgr); ok {
+		return um
+	}
+	um := &UndoMgr{}
+	win.SetProp("undo-mgr", um)
+	return um
+}
+
+// ViewUndoMgr returns the UndoMgr of the window of given view -- nil if it
+// is not in a window
+func ViewUndoMgr(vw gi.Node2D) *UndoMgr {
+	if vw == nil || vw.IsDestroyed() {
+		return nil
+	}
+	return WindowUndoMgr(vw.AsNode2D().ParentWindow())
+}
+
+// SaveValueUndo saves an undo record to given UndoMgr for the edit of the
+// value of a struct field or slice element by given ValueView, just made by
+// its SetValue -- the undo and redo functions set the previous or new value
+// and then call updt to update the views of it
+func SaveValueUndo(um *UndoMgr, vv ValueView, updt func()) {
+	vvb := vv.AsValueViewBase()
+	if um == nil || !vvb.prvVal.IsValid() {
+		return
+	}
+	cur := kit.NonPtrValue(vvb.Value)
+	if !cur.CanSet() {
+		return
+	}
+	prv := vvb.prvVal
+	nv := reflect.New(cur.Type()).Elem()
+	nv.Set(cur)
+	vvb.prvVal = reflect.Value{}
+	lbl := gi.T("Edit Item")
+	if vvb.Field != nil {
+		if tl, ok := vvb.Tag("label"); ok {
+			lbl = gi.Tf("Edit %v", tl)
+		} else {
+			lbl = gi.Tf("Edit %v", vvb.Field.Name)
+		}
+	}
+	um.Save(lbl, func() {
+		cur.Set(prv)
+		updt()
+	}, func() {
+		cur.Set(nv)
+		updt()
+	})
+}
+
+// sliceInsertAt inserts given value at given index in the slice pointed to
+// by given slice pointer, for undo and redo of slice edits
+func sliceInsertAt(slptr interface{}, idx int, val reflect.Value) {
+	svl := reflect.ValueOf(slptr)
+	svnp := kit.NonPtrValue(svl)
+	sz := svnp.Len()
+	if idx < 0 || idx > sz {
+		idx = sz
+	}
+	svnp = reflect.Append(svnp, val)
+	if idx < sz {
+		reflect.Copy(svnp.Slice(idx+1, sz+1), svnp.Slice(idx, sz))
+		svnp.Index(idx).Set(val)
+	}
+	svl.Elem().Set(svnp)
+}
+
+// undoUpdtKi signals an update of given struct, if it is a Ki, after undo or
+// redo of an edit of it, e.g., to render the changes of an svg element
+func undoUpdtKi(stru interface{}) {
+	if k, ok := stru.(ki.Ki); ok && !k.IsDestroyed() {
+		k.UpdateSig()
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/goki/ki/ki"
+)
+
+// saveLog saves an undo record with given label to given UndoMgr, which
+// appends "u"+label or "r"+label to given log when undone or redone
+func saveLog(um *UndoMgr, label string, log *[]string) {
+	um.Save(label, func() {
+		*log = append(*log, "u"+label)
+	}, func() {
+		*log = append(*log, "r"+label)
+	})
+}
+
+func TestUndoMgrGroup(t *testing.T) {
+	um := &UndoMgr{}
+	var log []string
+	saveLog(um, "a", &log)
+	um.StartGroup("Group")
+	saveLog(um, "b", &log)
+	um.StartGroup("Inner") // nested groups are in the outer one
+	saveLog(um, "c", &log)
+	um.EndGroup()
+	saveLog(um, "d", &log)
+	um.EndGroup()
+	if got := um.UndoLabel(); got != "Group" {
+		t.Errorf("undo label: %q != expected: %q", got, "Group")
+	}
+	if !um.Undo() {
+		t.Fatalf("nothing to undo")
+	}
+	if exp := []string{"ud", "uc", "ub"}; !reflect.DeepEqual(log, exp) {
+		t.Errorf("group undo: %v != expected: %v", log, exp)
+	}
+	if got := um.UndoLabel(); got != "a" {
+		t.Errorf("undo label after undo: %q != expected: %q", got, "a")
+	}
+	if got := um.RedoLabel(); got != "Group" {
+		t.Errorf("redo label: %q != expected: %q", got, "Group")
+	}
+	log = nil
+	if !um.Redo() {
+		t.Fatalf("nothing to redo")
+	}
+	if exp := []string{"rb", "rc", "rd"}; !reflect.DeepEqual(log, exp) {
+		t.Errorf("group redo: %v != expected: %v", log, exp)
+	}
+	if um.Redo() {
+		t.Errorf("redo past the end of the stack")
+	}
+	log = nil
+	um.Undo()
+	um.Undo()
+	if exp := []string{"ud", "uc", "ub", "ua"}; !reflect.DeepEqual(log, exp) {
+		t.Errorf("undo all: %v != expected: %v", log, exp)
+	}
+	if um.Undo() {
+		t.Errorf("undo past the start of the stack")
+	}
+}
+
+func TestUndoMgrRedoTruncate(t *testing.T) {
+	um := &UndoMgr{}
+	var log []string
+	saveLog(um, "a", &log)
+	saveLog(um, "b", &log)
+	saveLog(um, "c", &log)
+	um.Undo()
+	um.Undo()
+	saveLog(um, "d", &log) // discards the undone b and c
+	if len(um.Stack) != 2 || um.Pos != 2 {
+		t.Errorf("stack after save: %d records at pos %d != expected: 2 at 2", len(um.Stack), um.Pos)
+	}
+	if got := um.RedoLabel(); got != "" {
+		t.Errorf("redo label after save: %q != expected: none", got)
+	}
+	if um.Redo() {
+		t.Errorf("redo of a discarded record")
+	}
+	log = nil
+	um.Undo()
+	um.Undo()
+	if exp := []string{"ud", "ua"}; !reflect.DeepEqual(log, exp) {
+		t.Errorf("undo after save: %v != expected: %v", log, exp)
+	}
+}
+
+func TestUndoMgrMax(t *testing.T) {
+	defer func(max int) { UndoMax = max }(UndoMax)
+	UndoMax = 5
+	um := &UndoMgr{}
+	var log []string
+	saveLog(um, "a", &log)
+	um.StartGroup("Group")
+	saveLog(um, "b", &log)
+	saveLog(um, "c", &log)
+	um.EndGroup()
+	for i := 0; i < 2; i++ {
+		saveLog(um, fmt.Sprint(i), &log)
+	}
+	if len(um.Stack) != 5 {
+		t.Fatalf("stack: %d records != expected: 5", len(um.Stack))
+	}
+	saveLog(um, "2", &log) // drops a
+	if len(um.Stack) != 5 || um.Stack[0].Label != "Group" {
+		t.Errorf("stack after trim: %d records from %q != expected: 5 from Group", len(um.Stack), um.Stack[0].Label)
+	}
+	saveLog(um, "3", &log) // drops all of the group, not just b
+	if len(um.Stack) != 4 || um.Pos != 4 || um.Stack[0].Label != "0" {
+		t.Errorf("stack after group trim: %d records from %q at pos %d != expected: 4 from 0 at 4", len(um.Stack), um.Stack[0].Label, um.Pos)
+	}
+	log = nil
+	for um.Undo() {
+	}
+	if exp := []string{"u3", "u2", "u1", "u0"}; !reflect.DeepEqual(log, exp) {
+		t.Errorf("undo all: %v != expected: %v", log, exp)
+	}
+}
+
+func TestUndoMgrNil(t *testing.T) {
+	var um *UndoMgr
+	um.Save("a", func() {}, func() {})
+	um.StartGroup("Group")
+	um.EndGroup()
+	um.Reset()
+	if um.Undo() || um.Redo() || um.UndoLabel() != "" || um.RedoLabel() != "" {
+		t.Errorf("nil UndoMgr did something")
+	}
+}
+
+func TestSrcUndoRestore(t *testing.T) {
+	par := &ki.Node{}
+	par.InitName(par, "par")
+	a := par.AddNewChild(ki.KiT_Node, "a")
+	b := par.AddNewChild(ki.KiT_Node, "b")
+	c := par.AddNewChild(ki.KiT_Node, "c")
+	bk := b.AddNewChild(ki.KiT_Node, "bk")
+	prv := append(ki.Slice(nil), *par.Children()...)
+	b.Delete(false) // as when deleting for undo
+	d := par.AddNewChild(ki.KiT_Node, "d")
+	par.MoveChild(2, 0)
+	nxt := append(ki.Slice(nil), *par.Children()...)
+	pars := []ki.Ki{par}
+
+	check := func(nm string, exp ...ki.Ki) {
+		t.Helper()
+		kids := *par.Children()
+		if len(kids) != len(exp) {
+			t.Fatalf("%v: %d children != expected: %d", nm, len(kids), len(exp))
+		}
+		for i, k := range kids {
+			if k != exp[i] || k.Parent() != par.This() {
+				t.Errorf("%v: child %d: %v != expected: %v", nm, i, k.Path(), exp[i].Name())
+			}
+		}
+	}
+	check("change", d, a, c)
+	srcUndoRestore(pars, []ki.Slice{prv}, nil)
+	check("undo", a, b, c)
+	if b.Child(0) != bk || bk.Parent() != b {
+		t.Errorf("undo: children of restored node not kept")
+	}
+	if d.Parent() != nil {
+		t.Errorf("undo: added node still in the tree")
+	}
+	srcUndoRestore(pars, []ki.Slice{nxt}, nil)
+	check("redo", d, a, c)
+	srcUndoRestore(pars, []ki.Slice{prv}, nil)
+	check("undo again", a, b, c)
+}
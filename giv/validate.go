@@ -0,0 +1,115 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/goki/ki/kit"
+)
+
+// Validator is an interface for a struct that validates the values of its
+// fields, in addition to any validate tags on them (see ValidateTag) --
+// StructView does not set a value of a field for which Validate returns an
+// error, and shows the error next to the field.
+type Validator interface {
+	// Validate returns an error if the current value of the given field, by
+	// name, is not valid, e.g., given the values of the other fields -- nil
+	// if it is valid.
+	Validate(field string) error
+}
+
+// ValidErrColor is the color of the error messages of fields that are not
+// valid, in StructView
+var ValidErrColor = "#F44336"
+
+// ValidateTag returns an error if the value pointed to by valPtr does not
+// satisfy the rules of the given validate tag, which is a comma-separated
+// list of: required, for a value that must not be the zero value of its
+// type; min=x and max=x, for a number that must be >= or <= x, or a string,
+// slice or map that must have at least or at most x elements (characters
+// for a string); and oneof=a b c, for a value whose string must be one of
+// the space-separated options -- e.g., `validate:"min=0,max=1"`
+func ValidateTag(tag string, valPtr interface{}) error {
+	rv := kit.NonPtrValue(reflect.ValueOf(valPtr))
+	for _, rule := range strings.Split(tag, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		nm, arg := rule, ""
+		if i := strings.Index(rule, "="); i >= 0 {
+			nm, arg = rule[:i], rule[i+1:]
+		}
+		switch nm {
+		case "required":
+			if kit.ValueIsZero(rv) {
+				return errors.New("is required")
+			}
+		case "min", "max":
+			lim, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				log.Printf("giv.ValidateTag: invalid limit in validate tag: %v, error: %v\n", tag, err)
+				continue
+			}
+			n, isLen := validateNum(rv)
+			switch {
+			case nm == "min" && n < lim:
+				if isLen {
+					return fmt.Errorf("must have at least %v %v", arg, validateLenUnit(rv))
+				}
+				return fmt.Errorf("must be at least %v", arg)
+			case nm == "max" && n > lim:
+				if isLen {
+					return fmt.Errorf("must have at most %v %v", arg, validateLenUnit(rv))
+				}
+				return fmt.Errorf("must be at most %v", arg)
+			}
+		case "oneof":
+			opts := strings.Fields(arg)
+			val := kit.ToString(rv.Interface())
+			has := false
+			for _, op := range opts {
+				if op == val {
+					has = true
+					break
+				}
+			}
+			if !has {
+				return fmt.Errorf("must be one of: %v", strings.Join(opts, ", "))
+			}
+		default:
+			log.Printf("giv.ValidateTag: unknown rule: %v in validate tag: %v\n", nm, tag)
+		}
+	}
+	return nil
+}
+
+// validateNum returns the number that min and max rules apply to for given
+// value: its length for a string, slice, array or map, else its value
+func validateNum(rv reflect.Value) (float64, bool) {
+	switch rv.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(rv.String())), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(rv.Len()), true
+	}
+	n, _ := kit.ToFloat(rv.Interface())
+	return n, false
+}
+
+// validateLenUnit returns the unit of the length of given value for messages
+func validateLenUnit(rv reflect.Value) string {
+	if rv.Kind() == reflect.String {
+		return "characters"
+	}
+	return "items"
+}
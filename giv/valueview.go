@@ -67,6 +67,21 @@ func init() {
 		vv.Init(vv)
 		return vv
 	})
+	ValueViewMapAdd(kit.LongTypeName(reflect.TypeOf(time.Duration(0))), func() ValueView {
+		vv := &DurationValueView{}
+		vv.Init(vv)
+		return vv
+	})
+	ValueViewNameAdd("progress", func() ValueView {
+		vv := &ProgressValueView{}
+		vv.Init(vv)
+		return vv
+	})
+	ValueViewNameAdd("icon", func() ValueView {
+		vv := &EnumIconValueView{}
+		vv.Init(vv)
+		return vv
+	})
 }
 
 // MapInlineLen is the number of map elements at or below which an inline
@@ -138,6 +153,22 @@ func ValueViewMapAdd(typeNm string, fun ValueViewFunc) {
 	ValueViewMap[typeNm] = fun
 }
 
+// The ValueViewNameMap connects names of views with the ValueViews that
+// present values in those views, for the view tag of a field, e.g.,
+// view:"progress" for a progress bar -- this overrides the ValueView of the
+// type of the field, and can be used for custom cell editors and viewers of
+// the columns of a TableView (see also TableView.SetColumnView).
+var ValueViewNameMap map[string]ValueViewFunc
+
+// ValueViewNameAdd adds a ValueViewFunc for a given view name, used for
+// fields with that name as their view tag, e.g., view:"progress"
+func ValueViewNameAdd(name string, fun ValueViewFunc) {
+	if ValueViewNameMap == nil {
+		ValueViewNameMap = make(map[string]ValueViewFunc)
+	}
+	ValueViewNameMap[name] = fun
+}
+
 // StructTagVal returns the value for given key in given struct tag string
 // uses reflect.StructTag Lookup method -- just a wrapper for external
 // use (e.g., in Python code)
@@ -152,7 +183,8 @@ func StructTagVal(key, tags string) string {
 // falls back on default Kind-based options.  tags are optional tags, e.g.,
 // from the field in a struct, that control the view properties -- see the gi wiki
 // for details on supported tags -- these are NOT set for the view element, only
-// used for options that affect what kind of view to create.
+// used for options that affect what kind of view to create -- a view tag with
+// a name in the ValueViewNameMap, e.g., view:"progress", takes precedence.
 // See FieldToValueView for version that takes into account the properties of the owner.
 // gopy:interface=handle
 func ToValueView(it interface{}, tags string) ValueView {
@@ -161,6 +193,13 @@ func ToValueView(it interface{}, tags string) ValueView {
 		vv.Init(&vv)
 		return &vv
 	}
+	if tags != "" {
+		if vwtag, ok := reflect.StructTag(tags).Lookup("view"); ok {
+			if vvf, has := ValueViewNameMap[vwtag]; has {
+				return vvf()
+			}
+		}
+	}
 	if vv, ok := it.(ValueViewer); ok {
 		vvo := vv.ValueView()
 		if vvo != nil {
@@ -188,6 +227,7 @@ func ToValueView(it interface{}, tags string) ValueView {
 
 	forceInline := false
 	forceNoInline := false
+	forceSlider := false
 
 	tprops := kit.Types.Properties(typ, false) // don't make
 	if tprops != nil {
@@ -207,6 +247,8 @@ func ToValueView(it interface{}, tags string) ValueView {
 				forceInline = true
 			case "no-inline":
 				forceNoInline = true
+			case "slider":
+				forceSlider = true
 			}
 		}
 	}
@@ -223,6 +265,10 @@ func ToValueView(it interface{}, tags string) ValueView {
 				vv.Init(vv)
 				return vv
 			}
+		} else if forceSlider {
+			vv := &SliderValueView{}
+			vv.Init(vv)
+			return vv
 		} else if _, ok := it.(fmt.Stringer); ok { // use stringer
 			vv := &ValueViewBase{}
 			vv.Init(vv)
@@ -237,6 +283,11 @@ func ToValueView(it interface{}, tags string) ValueView {
 		vv.Init(vv)
 		return vv
 	case vk >= reflect.Float32 && vk <= reflect.Float64:
+		if forceSlider {
+			vv := &SliderValueView{}
+			vv.Init(vv)
+			return vv
+		}
 		vv := &FloatValueView{} // handles step, min / max etc
 		vv.Init(vv)
 		return vv
@@ -251,6 +302,11 @@ func ToValueView(it interface{}, tags string) ValueView {
 			vv.Init(vv)
 			return vv
 		}
+		if nptyp.Kind() == reflect.Interface && len(InterfaceImplementers(nptyp)) > 0 {
+			vv := &InterfaceValueView{}
+			vv.Init(vv)
+			return vv
+		}
 		if kit.IfaceIsNil(it) {
 			vv := NilValueView{}
 			vv.Init(&vv)
@@ -312,6 +368,11 @@ func ToValueView(it interface{}, tags string) ValueView {
 			vv.Init(vv)
 			return vv
 		}
+		if nptyp == gist.KiT_ColorSpec {
+			vv := &ColorSpecValueView{}
+			vv.Init(vv)
+			return vv
+		}
 		nfld := kit.AllFieldsN(nptyp)
 		if nfld > 0 && !forceNoInline && (forceInline || nfld <= StructInlineLen) {
 			vv := &StructInlineValueView{}
@@ -386,6 +447,16 @@ func FieldToValueView(it interface{}, field string, fval interface{}) ValueView
 	return ToValueView(fval, "")
 }
 
+// fieldViewVal returns the value of a struct field to pass to ToValueView:
+// the field value itself, except for an interface field, where it is a
+// pointer to the field, so that the interface type is not lost
+func fieldViewVal(fval interface{}, fieldVal reflect.Value) interface{} {
+	if fieldVal.Kind() == reflect.Interface && fieldVal.CanAddr() {
+		return fieldVal.Addr().Interface()
+	}
+	return fval
+}
+
 // ValueView is an interface for managing the GUI representation of values
 // (e.g., fields, map values, slice values) in Views (StructView, MapView,
 // etc).  The different types of ValueView are for different Kinds of values
@@ -521,6 +592,8 @@ type ValueViewBase struct {
 	WidgetTyp reflect.Type         `desc:"type of widget to create -- cached during WidgetType method -- chosen based on the ValueView type and reflect.Value type -- see ValueViewer interface"`
 	Widget    gi.Node2D            `desc:"the widget used to display and edit the value in the interface -- this is created for us externally and we cache it during ConfigWidget"`
 	TmpSave   ValueView            `desc:"value view that needs to have SaveTmp called on it whenever a change is made to one of the underlying values -- pass this down to any sub-views created from a parent"`
+	ValidErr  error                `json:"-" xml:"-" desc:"if Owner is a struct, the error of the last value that was set in the widget but not in the field, because it was not valid -- see Validate"`
+	prvVal    reflect.Value        // previous value of a struct field or slice element set by SetValue, for SaveValueUndo
 }
 
 var KiT_ValueViewBase = kit.Types.AddType(&ValueViewBase{}, ValueViewBaseProps)
@@ -643,12 +716,25 @@ func (vv *ValueViewBase) SetValue(val interface{}) bool {
 	if vv.Owner != nil {
 		switch vv.OwnKind {
 		case reflect.Struct:
+			cur := kit.NonPtrValue(vv.Value)
+			prv := reflect.New(cur.Type()).Elem() // to restore if not valid
+			prv.Set(cur)
 			if kiv, ok := vv.Owner.(ki.Ki); ok {
 				rval = (kiv.SetField(vv.Field.Name, val) == nil)
 
 			} else {
 				rval = kit.SetRobust(kit.PtrValue(vv.Value).Interface(), val)
 			}
+			vv.ValidErr = nil
+			if rval {
+				if err := vv.Validate(); err != nil {
+					cur.Set(prv)
+					vv.ValidErr = err
+					rval = false
+				} else {
+					vv.prvVal = prv
+				}
+			}
 		case reflect.Map:
 			ov := kit.NonPtrValue(reflect.ValueOf(vv.Owner))
 			if vv.IsMapKey {
@@ -701,7 +787,13 @@ func (vv *ValueViewBase) SetValue(val interface{}) bool {
 				rval = true
 			}
 		case reflect.Slice:
+			cur := kit.NonPtrValue(vv.Value)
+			prv := reflect.New(cur.Type()).Elem() // for undo
+			prv.Set(cur)
 			rval = kit.SetRobust(kit.PtrValue(vv.Value).Interface(), val)
+			if rval {
+				vv.prvVal = prv
+			}
 		}
 		if updtr, ok := vv.Owner.(gi.Updater); ok {
 			// fmt.Printf("updating: %v\n", updtr)
@@ -718,6 +810,24 @@ func (vv *ValueViewBase) SetValue(val interface{}) bool {
 	return rval
 }
 
+// Validate returns an error if the current value of a struct field is not
+// valid, according to its validate tag (see ValidateTag), or the Validator
+// interface of the struct -- nil if it is valid, or not a struct field
+func (vv *ValueViewBase) Validate() error {
+	if vv.Owner == nil || vv.OwnKind != reflect.Struct || vv.Field == nil {
+		return nil
+	}
+	if vt, has := vv.Tag("validate"); has {
+		if err := ValidateTag(vt, vv.Value.Interface()); err != nil {
+			return err
+		}
+	}
+	if vr, ok := vv.Owner.(Validator); ok {
+		return vr.Validate(vv.Field.Name)
+	}
+	return nil
+}
+
 func (vv *ValueViewBase) SaveTmp() {
 	if vv.TmpSave == nil {
 		return
@@ -975,6 +1085,10 @@ func (vi *ViewIFace) KeyMapsView(maps *gi.KeyMaps) {
 	KeyMapsView(maps)
 }
 
+func (vi *ViewIFace) KeyCmdsView(cmds *gi.KeyCmds) {
+	KeyCmdsView(cmds)
+}
+
 func (vi *ViewIFace) PrefsDetView(prefs *gi.PrefsDetailed) {
 	PrefsDetView(prefs)
 }
@@ -995,6 +1109,10 @@ func (vi *ViewIFace) SetHiStyleDefault(hsty gi.HiStyleName) {
 	histyle.StyleDefault = hsty
 }
 
+func (vi *ViewIFace) HiStylePair(hsty gi.HiStyleName, dark bool) gi.HiStyleName {
+	return histyle.PairStyle(hsty, dark)
+}
+
 func (vi *ViewIFace) PrefsDetDefaults(pf *gi.PrefsDetailed) {
 	pf.TextViewClipHistMax = TextViewClipHistMax
 	pf.TextBufMaxScopeLines = TextBufMaxScopeLines
@@ -1021,6 +1139,24 @@ func (vi *ViewIFace) PrefsDbgView(prefs *gi.PrefsDebug) {
 	PrefsDbgView(prefs)
 }
 
+func (vi *ViewIFace) SettingsView(reg *gi.SettingsRegistry) {
+	SettingsView(reg)
+}
+
+func (vi *ViewIFace) WindowUndo(win *gi.Window, redo bool) bool {
+	if redo {
+		return WindowUndoMgr(win).Redo()
+	}
+	return WindowUndoMgr(win).Undo()
+}
+
+func (vi *ViewIFace) WindowUndoLabel(win *gi.Window, redo bool) string {
+	if redo {
+		return WindowUndoMgr(win).RedoLabel()
+	}
+	return WindowUndoMgr(win).UndoLabel()
+}
+
 ////////////////////////////////////////////////////////////////////////////////////////
 //  VersCtrlValueView
 
@@ -0,0 +1,40 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki/ki"
+)
+
+// AddStructWizardStep adds a step to given wizard for editing the fields of
+// given struct (a pointer) using a StructView, which is valid when all of
+// the fields are, according to their validate tags and the Validator
+// interface of the struct -- the value of the step is the struct
+func AddStructWizardStep(wz *gi.Wizard, name, title, prompt string, stru interface{}) *gi.WizardStep {
+	var sv *StructView
+	st := wz.AddStep(name, title, prompt, func(wz *gi.Wizard, lay *gi.Layout) {
+		sv = AddNewStructView(lay, "struct-view")
+		sv.Viewport = wz.Dlg.Embed(gi.KiT_Viewport2D).(*gi.Viewport2D)
+		sv.SetStruct(stru)
+		sv.SetStretchMax()
+		sv.ViewSig.Connect(wz.Dlg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			wz.UpdateValid()
+		})
+	})
+	st.Validate = func(wz *gi.Wizard) error {
+		if sv == nil {
+			return nil
+		}
+		if errs := sv.ValidErrors(); len(errs) > 0 {
+			return errs[0]
+		}
+		return nil
+	}
+	st.Value = func(wz *gi.Wizard) interface{} {
+		return stru
+	}
+	return st
+}
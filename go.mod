@@ -14,6 +14,7 @@ require (
 	github.com/chewxy/math32 v1.0.6
 	github.com/dlclark/regexp2 v1.4.0 // indirect
 	github.com/fatih/camelcase v1.0.0
+	github.com/fsnotify/fsnotify v1.4.9
 	github.com/gabriel-vasile/mimetype v1.1.2 // indirect
 	github.com/go-gl/gl v0.0.0-20190320180904-bf2b1f2f34d7
 	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20201108214237-06ea97f0c265
@@ -34,8 +35,10 @@ require (
 	github.com/srwiley/scanFT v0.0.0-20190309001647-3267585b8d6d // indirect
 	github.com/srwiley/scanx v0.0.0-20190309010443-e94503791388
 	golang.org/x/image v0.0.0-20200927104501-e162460cd6b5
+	golang.org/x/mobile v0.0.0-20200801112145-973feb4309de
 	golang.org/x/net v0.0.0-20201110031124-69a78807bb2b
-	golang.org/x/text v0.3.4 // indirect
+	golang.org/x/sys v0.7.0 // indirect
+	golang.org/x/text v0.3.4
 	golang.org/x/tools v0.0.0-20201121010211-780cb80bd7fb // indirect
 )
 
@@ -50,15 +50,69 @@ var KiT_Trilean = kit.Enums.AddEnumAltLower(TrileanN, kit.NotBitFlag, nil, "")
 func (ev Trilean) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
 func (ev *Trilean) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
 
+// Underlines are the different styles of underline that can be used in a StyleEntry
+type Underlines int32
+
+const (
+	// UnderlineSolid is a standard solid line
+	UnderlineSolid Underlines = iota
+
+	// UnderlineWavy is a wavy "squiggle" line, typically used for errors
+	UnderlineWavy
+
+	// UnderlineDotted is a dotted line
+	UnderlineDotted
+
+	// UnderlineDashed is a dashed line
+	UnderlineDashed
+
+	UnderlinesN
+)
+
+//go:generate stringer -type=Underlines
+
+var KiT_Underlines = kit.Enums.AddEnumAltLower(UnderlinesN, kit.NotBitFlag, nil, "Underline")
+
+func (ev Underlines) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *Underlines) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// Deco returns the text decoration corresponding to this underline style
+func (ul Underlines) Deco() gist.TextDecorations {
+	switch ul {
+	case UnderlineWavy:
+		return gist.DecoWavyUnderline
+	case UnderlineDotted:
+		return gist.DecoDottedUnderline
+	case UnderlineDashed:
+		return gist.DecoDashedUnderline
+	}
+	return gist.DecoUnderline
+}
+
+// CSS returns the CSS text-decoration-style name for this underline style
+func (ul Underlines) CSS() string {
+	switch ul {
+	case UnderlineWavy:
+		return "wavy"
+	case UnderlineDotted:
+		return "dotted"
+	case UnderlineDashed:
+		return "dashed"
+	}
+	return "solid"
+}
+
 // StyleEntry is one value in the map of highlight style values
 type StyleEntry struct {
-	Color      gist.Color `desc:"text color"`
-	Background gist.Color `desc:"background color"`
-	Border     gist.Color `view:"-" desc:"border color? not sure what this is -- not really used"`
-	Bold       Trilean    `desc:"bold font"`
-	Italic     Trilean    `desc:"italic font"`
-	Underline  Trilean    `desc:"underline"`
-	NoInherit  bool       `desc:"don't inherit these settings from sub-category or category levels -- otherwise everything with a Pass is inherited"`
+	Color          gist.Color `desc:"text color"`
+	Background     gist.Color `desc:"background color"`
+	Border         gist.Color `view:"-" desc:"border color? not sure what this is -- not really used"`
+	Bold           Trilean    `desc:"bold font"`
+	Italic         Trilean    `desc:"italic font"`
+	Underline      Trilean    `desc:"underline"`
+	UnderlineStyle Underlines `desc:"style of underline, if Underline is set -- wavy is used for spelling and lint errors"`
+	UnderlineColor gist.Color `desc:"color of underline, if Underline is set -- nil = use text color"`
+	NoInherit      bool       `desc:"don't inherit these settings from sub-category or category levels -- otherwise everything with a Pass is inherited"`
 }
 
 var KiT_StyleEntry = kit.Types.AddType(&StyleEntry{}, StyleEntryProps)
@@ -108,6 +162,12 @@ func (se StyleEntry) String() string {
 	if se.Underline != Pass {
 		out = append(out, se.Underline.Prefix("underline"))
 	}
+	if se.UnderlineStyle != UnderlineSolid {
+		out = append(out, "underline-style:"+se.UnderlineStyle.CSS())
+	}
+	if !se.UnderlineColor.IsNil() {
+		out = append(out, "underline-color:"+se.UnderlineColor.String())
+	}
 	if se.NoInherit {
 		out = append(out, "noinherit")
 	}
@@ -140,6 +200,12 @@ func (se StyleEntry) ToCSS() string {
 	}
 	if se.Underline == Yes {
 		styles = append(styles, "text-decoration: underline")
+		if se.UnderlineStyle != UnderlineSolid {
+			styles = append(styles, "text-decoration-style: "+se.UnderlineStyle.CSS())
+		}
+		if !se.UnderlineColor.IsNil() {
+			styles = append(styles, "text-decoration-color: "+se.UnderlineColor.String())
+		}
 	}
 	return strings.Join(styles, "; ")
 }
@@ -160,7 +226,10 @@ func (se StyleEntry) ToProps() ki.Props {
 		pr["font-style"] = gist.FontItalic
 	}
 	if se.Underline == Yes {
-		pr["text-decoration"] = 1 << uint32(gist.DecoUnderline)
+		pr["text-decoration"] = 1 << uint32(se.UnderlineStyle.Deco())
+		if !se.UnderlineColor.IsNil() {
+			pr["text-decoration-color"] = se.UnderlineColor
+		}
 	}
 	return pr
 }
@@ -186,6 +255,12 @@ func (s StyleEntry) Sub(e StyleEntry) StyleEntry {
 	if e.Underline != s.Underline {
 		out.Underline = s.Underline
 	}
+	if e.UnderlineStyle != s.UnderlineStyle {
+		out.UnderlineStyle = s.UnderlineStyle
+	}
+	if e.UnderlineColor != s.UnderlineColor {
+		out.UnderlineColor = s.UnderlineColor
+	}
 	return out
 }
 
@@ -216,6 +291,10 @@ func (s StyleEntry) Inherit(ancestors ...StyleEntry) StyleEntry {
 		}
 		if out.Underline == Pass {
 			out.Underline = ancestor.Underline
+			out.UnderlineStyle = ancestor.UnderlineStyle
+		}
+		if out.UnderlineColor.IsNil() {
+			out.UnderlineColor = ancestor.UnderlineColor
 		}
 	}
 	return out
@@ -223,7 +302,7 @@ func (s StyleEntry) Inherit(ancestors ...StyleEntry) StyleEntry {
 
 func (s StyleEntry) IsZero() bool {
 	return s.Color.IsNil() && s.Background.IsNil() && s.Border.IsNil() && s.Bold == Pass && s.Italic == Pass &&
-		s.Underline == Pass && !s.NoInherit
+		s.Underline == Pass && s.UnderlineStyle == UnderlineSolid && s.UnderlineColor.IsNil() && !s.NoInherit
 }
 
 ///////////////////////////////////////////////////////////////////////////////////
@@ -297,6 +376,38 @@ func (hs Style) ToProps() ki.Props {
 	return pr
 }
 
+// MarshalJSON writes the style with the names of its tags as keys -- the
+// tag categories defined here after token.TokensN have no name in the
+// token.Tokens enum, so they are named here (see tagKeys)
+func (hs Style) MarshalJSON() ([]byte, error) {
+	ms := make(map[string]*StyleEntry, len(hs))
+	for tk, se := range hs {
+		ms[tagKey(tk)] = se
+	}
+	return json.Marshal(ms)
+}
+
+// UnmarshalJSON reads the style written by MarshalJSON -- entries for
+// unknown tags are skipped
+func (hs *Style) UnmarshalJSON(b []byte) error {
+	var ms map[string]*StyleEntry
+	if err := json.Unmarshal(b, &ms); err != nil {
+		return err
+	}
+	if *hs == nil {
+		*hs = make(Style, len(ms))
+	}
+	for key, se := range ms {
+		tk, err := tagFromKey(key)
+		if err != nil {
+			log.Printf("histyle.Style: skipping entry: %v\n", err)
+			continue
+		}
+		(*hs)[tk] = se
+	}
+	return nil
+}
+
 // Open hi style from a JSON-formatted file.
 func (hs Style) OpenJSON(filename gi.FileName) error {
 	b, err := ioutil.ReadFile(string(filename))
@@ -323,11 +434,90 @@ func (hs Style) SaveJSON(filename gi.FileName) error {
 	return err
 }
 
+// Tag categories used by language tooling (spell checking, linting, find)
+// to mark regions of text in a buffer, on top of the lexer-generated tags.
+// These are additional tokens after the pi token.TokensN, so the lexers
+// never produce them, and they are registered in token.Names (for their
+// CSS class names) and in the category maps, in the Text category.  Styles
+// can define entries for these tags to render theme-consistent squiggles --
+// otherwise Props provides the defaults.
+const (
+	// SpellErr marks a misspelled word -- it is in the sub-category of
+	// token.TextSpellErr, so existing style entries for that apply to it
+	SpellErr = token.TokensN + 1 + iota
+
+	// LintWarn marks a region with a linter warning
+	LintWarn
+
+	// LintErr marks a region with a linter or compiler error
+	LintErr
+
+	// SearchMatch is the tag whose background color is used to highlight
+	// all of the matches of a find or replace in a TextView -- a style can
+	// define a background for it to theme the search highlighting --
+	// otherwise the Highlight color from the color prefs is used
+	SearchMatch
+)
+
+// tagNames are the CSS class names of the tag categories
+var tagNames = map[token.Tokens]string{
+	SpellErr:    "tsp",
+	LintWarn:    "tlw",
+	LintErr:     "tle",
+	SearchMatch: "tsm",
+}
+
+// tagKeys are the names of the tag categories as keys in saved styles
+var tagKeys = map[token.Tokens]string{
+	SpellErr:    "SpellErr",
+	LintWarn:    "LintWarn",
+	LintErr:     "LintErr",
+	SearchMatch: "SearchMatch",
+}
+
+// tagKey returns the key of given tag in saved styles
+func tagKey(tk token.Tokens) string {
+	if key, ok := tagKeys[tk]; ok {
+		return key
+	}
+	return tk.String()
+}
+
+// tagFromKey returns the tag for given key in saved styles
+func tagFromKey(key string) (token.Tokens, error) {
+	for tk, k := range tagKeys {
+		if k == key {
+			return tk, nil
+		}
+	}
+	var tk token.Tokens
+	err := tk.FromString(key)
+	return tk, err
+}
+
+func init() {
+	for tk, nm := range tagNames {
+		token.Names[tk] = nm
+		token.CatMap[tk] = token.Text
+		token.SubCatMap[tk] = tk
+	}
+	token.SubCatMap[SpellErr] = token.TextSpellErr
+}
+
 // TagsProps are default properties for custom tags (tokens) -- if set in style then used
 // there but otherwise we use these as a fallback -- typically not overridden
 var Props = map[token.Tokens]ki.Props{
-	token.TextSpellErr: {
-		"text-decoration": 1 << uint32(gist.DecoDottedUnderline), // bitflag!
+	SpellErr: {
+		"text-decoration":       1 << uint32(gist.DecoWavyUnderline), // bitflag!
+		"text-decoration-color": "#E02020",
+	},
+	LintWarn: {
+		"text-decoration":       1 << uint32(gist.DecoWavyUnderline),
+		"text-decoration-color": "#E0A000",
+	},
+	LintErr: {
+		"text-decoration":       1 << uint32(gist.DecoWavyUnderline),
+		"text-decoration-color": "#E02020",
 	},
 }
 
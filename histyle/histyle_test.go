@@ -0,0 +1,97 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package histyle
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/gist"
+	"github.com/goki/pi/token"
+)
+
+// testEntry returns a style entry with given color and underline settings
+func testEntry(clr string, ul Trilean, us Underlines) *StyleEntry {
+	se := &StyleEntry{Underline: ul, UnderlineStyle: us}
+	se.Color.SetString(clr, nil)
+	return se
+}
+
+func TestStyleSaveOpen(t *testing.T) {
+	hs := Style{
+		token.None:         testEntry("#101010", Pass, UnderlineSolid),
+		token.Keyword:      testEntry("#202020", Pass, UnderlineSolid),
+		token.TextSpellErr: testEntry("#303030", Yes, UnderlineWavy),
+		SpellErr:           testEntry("#E02020", Yes, UnderlineWavy),
+		LintWarn:           testEntry("#E0A000", Yes, UnderlineWavy),
+		LintErr:            testEntry("#E02021", Yes, UnderlineDotted),
+		SearchMatch:        {Background: gist.Color{R: 0xF0, G: 0xF0, A: 0xFF}},
+	}
+	dir, err := ioutil.TempDir("", "histyle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fn := gi.FileName(filepath.Join(dir, "style.histy"))
+	if err := hs.SaveJSON(fn); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(string(fn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "Tokens(") {
+		t.Errorf("saved style has unnamed tags:\n%s", b)
+	}
+	ls := Style{}
+	if err := ls.OpenJSON(fn); err != nil {
+		t.Fatal(err)
+	}
+	if len(ls) != len(hs) {
+		t.Errorf("opened style has %d entries != saved: %d", len(ls), len(hs))
+	}
+	for tk, se := range hs {
+		if le, ok := ls[tk]; !ok {
+			t.Errorf("tag %v: not in opened style", tagKey(tk))
+		} else if !reflect.DeepEqual(le, se) {
+			t.Errorf("tag %v: opened entry: %v != saved: %v", tagKey(tk), le, se)
+		}
+	}
+}
+
+func TestStyleKeys(t *testing.T) {
+	tests := []struct {
+		tk  token.Tokens
+		key string
+	}{
+		{token.None, "None"},
+		{token.TextSpellErr, "TextSpellErr"},
+		{SpellErr, "SpellErr"},
+		{LintWarn, "LintWarn"},
+		{LintErr, "LintErr"},
+		{SearchMatch, "SearchMatch"},
+	}
+	for _, ts := range tests {
+		if key := tagKey(ts.tk); key != ts.key {
+			t.Errorf("tagKey(%d): %v != expected: %v", ts.tk, key, ts.key)
+		}
+		if tk, err := tagFromKey(ts.key); err != nil || tk != ts.tk {
+			t.Errorf("tagFromKey(%v): %d, %v != expected: %d", ts.key, tk, err, ts.tk)
+		}
+	}
+	var hs Style
+	if err := json.Unmarshal([]byte(`{"Keyword": {}, "NoSuchTag": {}}`), &hs); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := hs[token.Keyword]; !ok || len(hs) != 1 {
+		t.Errorf("unknown tag not skipped: %v", hs)
+	}
+}
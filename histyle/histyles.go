@@ -39,6 +39,39 @@ var StyleDefault = gi.HiStyleName("emacs")
 // StyleNames are all the names of all the available highlighting styles
 var StyleNames []string
 
+// StylePair is a pair of corresponding light and dark highlighting styles
+type StylePair struct {
+	Light gi.HiStyleName
+	Dark  gi.HiStyleName
+}
+
+// StylePairs are the pairs of light and dark styles that are switched
+// between when the color scheme changes between light and dark -- add your
+// own custom styles here
+var StylePairs = []StylePair{
+	{"emacs", "monokai"},
+	{"solarized-light", "solarized-dark"},
+	{"paraiso-light", "paraiso-dark"},
+	{"github", "dracula"},
+	{"vs", "native"},
+	{"xcode", "fruity"},
+}
+
+// PairStyle returns the dark (or light) style paired with given style in
+// StylePairs, or the style itself if it is already dark (or light) or
+// has no pair
+func PairStyle(nm gi.HiStyleName, dark bool) gi.HiStyleName {
+	for _, sp := range StylePairs {
+		if dark && sp.Light == nm {
+			return sp.Dark
+		}
+		if !dark && sp.Dark == nm {
+			return sp.Light
+		}
+	}
+	return nm
+}
+
 // AvailStyle returns a style by name from the AvailStyles list -- if not found
 // default is used as a fallback
 func AvailStyle(nm gi.HiStyleName) *Style {
@@ -0,0 +1,270 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oswin
+
+import (
+	"image"
+
+	"github.com/goki/ki/bitflag"
+	"github.com/goki/ki/kit"
+)
+
+// AccessWindow is an optional interface for a Window of a driver that
+// bridges to the platform accessibility API (UIA on Windows,
+// NSAccessibility on macOS, AT-SPI on Linux), so that assistive
+// technologies such as screen readers can present the contents of the
+// window.  The GUI provides a tree of AccessNode elements describing its
+// widgets, and notifies of focus changes and announcements.
+//
+// The glos driver implements it on Linux (and the BSDs) with AT-SPI, when
+// accessibility is enabled on the desktop (e.g., when the Orca screen
+// reader is running).  The offscreen driver implements it by recording the
+// information, so it can be checked in tests.
+//
+// Only AT-SPI is bridged: UIA and NSAccessibility are out of scope for now,
+// and left to a separate follow-up, as they need a COM provider and an
+// Objective-C bridge respectively.  The glos windows on Windows and macOS do
+// not implement AccessWindow, so no accessibility information is computed
+// there, and the API is designed so that they can be added without changes
+// to the GUI (e.g., AccessText maps to the UIA TextPattern and the
+// NSAccessibility text attributes).
+type AccessWindow interface {
+	// AccessActive returns true if an assistive technology is currently
+	// using the accessibility information for this window -- the GUI only
+	// needs to provide it when this is true.
+	AccessActive() bool
+
+	// SetAccessTree sets the tree of accessible elements of the window,
+	// replacing any previous tree -- the tree is not modified after this
+	// call, so it can be retained as is.
+	SetAccessTree(root *AccessNode)
+
+	// AccessFocus notifies that the element with given ID now has the
+	// keyboard focus -- "" if nothing has focus.
+	AccessFocus(id string)
+
+	// AccessAnnounce asks for given message to be announced to the user
+	// (e.g., spoken by a screen reader).  If assertive, the message
+	// interrupts any current speech -- otherwise it is queued.
+	AccessAnnounce(msg string, assertive bool)
+}
+
+// AccessNode is one element of the accessibility tree of a window, which
+// typically corresponds to one widget -- see AccessWindow.
+type AccessNode struct {
+	ID      string          `desc:"unique identifier of the element within its window, which is stable across updates of the tree"`
+	Role    AccessRoles     `desc:"the kind of user interface element"`
+	Name    string          `desc:"accessible name of the element: the label text of a button, the label for a field, etc"`
+	Desc    string          `desc:"longer description of the element, e.g., its tooltip"`
+	Value   string          `desc:"current value of the element as text, e.g., the text of a text field or the value of a slider"`
+	Min     float64         `desc:"minimum value, for range elements such as sliders and spin boxes"`
+	Max     float64         `desc:"maximum value, for range elements such as sliders and spin boxes"`
+	Step    float64         `desc:"step size of value changes, for range elements such as sliders and spin boxes"`
+	States  AccessStates    `desc:"bit flags for the state of the element"`
+	Bounds  image.Rectangle `desc:"bounding box of the element in window coordinates (in raw pixels)"`
+	Text    AccessText      `json:"-" desc:"source of the text of an element with a lot of text (e.g., RoleTextArea), which is served on demand instead of being copied into Value -- nil otherwise"`
+	TextRev int64           `desc:"revision of the Text, which changes whenever the text does, so changes are detected without comparing the text"`
+	Caret   int             `desc:"character offset of the caret (cursor) within the text of a text element"`
+	Kids    []*AccessNode   `desc:"the child elements, in visual (reading) order"`
+}
+
+// AccessText is a source of the text of an accessible element, which is
+// served on demand, by range, instead of being copied into the Value of
+// the AccessNode on every update of the tree -- for elements with a lot of
+// text, such as text editors.  Offsets are in characters (runes) from the
+// start of the text, with lines separated by newlines.  The methods can be
+// called at any time, from any goroutine.
+type AccessText interface {
+	// AccessTextLen returns the number of characters in the text
+	AccessTextLen() int
+
+	// AccessTextRange returns the text between given character offsets,
+	// which are clipped to the text
+	AccessTextRange(st, ed int) string
+
+	// AccessTextLine returns the start and end character offsets of the
+	// line containing given offset, including its newline, if any
+	AccessTextLine(off int) (st, ed int)
+}
+
+// HasState returns true if given state flag is set
+func (an *AccessNode) HasState(st AccessStates) bool {
+	return bitflag.Has(int64(an.States), int(st))
+}
+
+// SetState sets given state flag to given value
+func (an *AccessNode) SetState(on bool, st AccessStates) {
+	bf := int64(an.States)
+	bitflag.SetState(&bf, on, int(st))
+	an.States = AccessStates(bf)
+}
+
+// FindID returns the element with given ID within this tree (including
+// this element), or nil if not found
+func (an *AccessNode) FindID(id string) *AccessNode {
+	if an.ID == id {
+		return an
+	}
+	for _, k := range an.Kids {
+		if f := k.FindID(id); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// Equal returns true if this tree is identical to the other one
+func (an *AccessNode) Equal(on *AccessNode) bool {
+	if an == nil || on == nil {
+		return an == on
+	}
+	if an.ID != on.ID || an.Role != on.Role || an.Name != on.Name || an.Desc != on.Desc || an.Value != on.Value || an.Min != on.Min || an.Max != on.Max || an.Step != on.Step || an.States != on.States || an.Bounds != on.Bounds || an.Text != on.Text || an.TextRev != on.TextRev || an.Caret != on.Caret || len(an.Kids) != len(on.Kids) {
+		return false
+	}
+	for i, k := range an.Kids {
+		if !k.Equal(on.Kids[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// AccessRoles are the kinds of user interface elements, as used by platform
+// accessibility APIs
+type AccessRoles int32
+
+const (
+	// RoleNone is an element with no particular role, which is typically
+	// ignored except for its children
+	RoleNone AccessRoles = iota
+
+	// RoleWindow is the window itself, at the root of the tree
+	RoleWindow
+
+	// RoleDialog is a dialog window
+	RoleDialog
+
+	// RoleGroup is a container of other elements, e.g., a frame
+	RoleGroup
+
+	// RoleLabel is static text
+	RoleLabel
+
+	// RoleImage is an image or icon
+	RoleImage
+
+	// RoleButton is a push button
+	RoleButton
+
+	// RoleCheckBox is a checkbox or toggle button, with Checked state
+	RoleCheckBox
+
+	// RoleTextField is an editable single line of text
+	RoleTextField
+
+	// RoleTextArea is editable multi-line text
+	RoleTextArea
+
+	// RoleSlider is a slider for a value in a range
+	RoleSlider
+
+	// RoleScrollBar is a scrollbar
+	RoleScrollBar
+
+	// RoleSpinBox is a numeric value with increment / decrement buttons
+	RoleSpinBox
+
+	// RoleComboBox is a button with a drop-down list of choices
+	RoleComboBox
+
+	// RoleMenuBar is a bar of menus
+	RoleMenuBar
+
+	// RoleMenu is a popup menu
+	RoleMenu
+
+	// RoleMenuItem is an item in a menu
+	RoleMenuItem
+
+	// RoleToolBar is a bar of actions
+	RoleToolBar
+
+	// RoleTabList is a set of tabs
+	RoleTabList
+
+	// RoleTab is one tab within a RoleTabList
+	RoleTab
+
+	// RoleTree is a tree of items
+	RoleTree
+
+	// RoleTreeItem is one item in a tree
+	RoleTreeItem
+
+	// RoleTable is a table of rows and columns
+	RoleTable
+
+	// RoleSplitter is a divider between resizable panels
+	RoleSplitter
+
+	// RoleProgressBar shows the progress of an operation
+	RoleProgressBar
+
+	// RoleLink is a hyperlink
+	RoleLink
+
+	AccessRolesN
+)
+
+//go:generate stringer -type=AccessRoles
+
+var KiT_AccessRoles = kit.Enums.AddEnum(AccessRolesN, kit.NotBitFlag, nil)
+
+// AccessStates are bit flags for the state of an accessible element
+type AccessStates int64
+
+const (
+	// StateFocusable means the element can receive the keyboard focus
+	StateFocusable AccessStates = iota
+
+	// StateFocused means the element has the keyboard focus
+	StateFocused
+
+	// StateDisabled means the element is inactive and cannot be used
+	StateDisabled
+
+	// StateSelected means the element is selected
+	StateSelected
+
+	// StateCheckable means the element can be checked (toggled)
+	StateCheckable
+
+	// StateChecked means the element is checked (toggled on)
+	StateChecked
+
+	// StateExpandable means the element can be expanded (e.g., a tree item)
+	StateExpandable
+
+	// StateExpanded means the element is expanded
+	StateExpanded
+
+	// StateEditable means the text of the element can be edited
+	StateEditable
+
+	// StateReadOnly means the value of the element cannot be changed
+	StateReadOnly
+
+	// StateInvisible means the element is not currently visible
+	StateInvisible
+
+	// StateHasPopup means the element opens a popup menu or dialog
+	StateHasPopup
+
+	AccessStatesN
+)
+
+//go:generate stringer -type=AccessStates
+
+var KiT_AccessStates = kit.Enums.AddEnum(AccessStatesN, kit.BitFlag, nil)
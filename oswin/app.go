@@ -11,7 +11,9 @@ package oswin
 
 import (
 	"image"
+	"image/color"
 
+	"github.com/goki/gi/oswin/audio"
 	"github.com/goki/gi/oswin/clip"
 	"github.com/goki/gi/oswin/cursor"
 	"github.com/goki/ki/kit"
@@ -113,6 +115,24 @@ type App interface {
 	// xdg-open command.
 	OpenURL(url string)
 
+	// HasFileDialog returns true if platform-native file dialogs are
+	// available -- see FileDialog.
+	HasFileDialog() bool
+
+	// FileDialog shows a platform-native file or directory chooser with
+	// given options, on top of given window (which can be nil), and waits
+	// for the user to close it, returning the full path of the chosen file,
+	// or "" if canceled.  Must not be called on the main thread.  On Linux
+	// this requires that the zenity (GTK, which uses the desktop portal
+	// where configured) or kdialog (KDE) command has been installed.
+	FileDialog(win Window, opts *FileDialogOptions) (string, error)
+
+	// IsDark returns true if the OS appearance (theme) is dark.  When this
+	// changes, a window.ThemeChanged event is sent to the first window.  On
+	// Linux this is the GNOME color-scheme setting, read using the gsettings
+	// command, or else whether the GTK theme name contains "dark".
+	IsDark() bool
+
 	// SetQuitReqFunc sets the function that is called whenever there is a
 	// request to quit the app (via a OS or a call to QuitReq() method).  That
 	// function can then adjudicate whether and when to actually call Quit.
@@ -163,6 +183,34 @@ type App interface {
 	PollEvents()
 }
 
+// Notifier is an optional interface for an App that can show native
+// notifications of the OS, e.g., for transient messages of an app whose
+// windows are not in focus.
+type Notifier interface {
+	// Notify shows a native notification with given title and message.
+	Notify(title, msg string) error
+}
+
+// ScreenColorPicker is an optional interface for an App that can pick the
+// color of any pixel on the screen, e.g., for the eyedropper of a color
+// picker, including pixels outside of the windows of the app.
+type ScreenColorPicker interface {
+	// PickScreenColor lets the user click on a pixel of the screen, and
+	// returns its color -- it blocks until the user has picked one, so it
+	// must be called on its own goroutine, and returns an error if the user
+	// canceled or the color could not be read.
+	PickScreenColor() (color.RGBA, error)
+}
+
+// AudioPlayer is an optional interface for an App that can play sound,
+// e.g., alert sounds and the sound of media -- see the audio package.
+type AudioPlayer interface {
+	// OpenAudio opens a new audio output stream for samples of given
+	// format, returning an error if audio output is not available (e.g.,
+	// none of the commands used for it on Linux is installed).
+	OpenAudio(format audio.Format) (audio.Stream, error)
+}
+
 // Platforms are all the supported platforms for OSWin
 type Platforms int32
 
@@ -176,9 +224,28 @@ const (
 	// Windows is a Microsoft Windows machine
 	Windows
 
+	// LinuxWayland is a Linux OS machine running a Wayland compositor --
+	// requires building with the wayland build tag, otherwise X11 is used,
+	// via XWayland if needed
+	LinuxWayland
+
+	// Android is a mobile device running Android -- requires building
+	// with gomobile, which uses the mobile driver
+	Android
+
+	// IOS is a mobile device (iPhone, iPad) running iOS -- requires
+	// building with gomobile, which uses the mobile driver
+	IOS
+
 	PlatformsN
 )
 
 //go:generate stringer -type=Platforms
 
 var KiT_Platforms = kit.Enums.AddEnum(PlatformsN, kit.NotBitFlag, nil)
+
+// IsMobile returns true if the platform is a mobile one (Android or iOS),
+// with a touch screen and an on-screen keyboard
+func (pl Platforms) IsMobile() bool {
+	return pl == Android || pl == IOS
+}
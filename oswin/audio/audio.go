@@ -0,0 +1,324 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package audio defines the audio output of the GoGi GUI system, for
+// playing alert sounds and the sound of media.  Sound is played by writing
+// interleaved signed 16-bit PCM samples to a Stream, opened using the
+// oswin.AudioPlayer interface of the App, if it supports audio.  The
+// volume of each stream is applied to the samples as they are written, and
+// events are sent when a stream starts playing, runs out of samples, etc.
+//
+// Drivers implement audio output by providing a Sink for the platform,
+// which NewStream wraps in a Stream, so there are no per-OS libraries
+// needed: on Linux, samples are piped to the pacat (PulseAudio / PipeWire),
+// pw-cat or aplay (ALSA) command, whichever is installed, on Windows the
+// waveOut API is used, and on Mac the AudioQueue API.
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/goki/ki/kit"
+)
+
+// Format is the format of the samples of a Stream, which are always
+// interleaved signed 16-bit integers (little-endian when in bytes)
+type Format struct {
+	SampleRate int `desc:"number of sample frames per second, e.g., 44100"`
+	Channels   int `desc:"number of channels, e.g., 2 for stereo -- samples for each channel are interleaved within each frame"`
+}
+
+// DefaultFormat is a standard CD-quality stereo format
+var DefaultFormat = Format{SampleRate: 44100, Channels: 2}
+
+// Validate returns an error if the format is not usable
+func (f Format) Validate() error {
+	if f.SampleRate < 1000 || f.SampleRate > 384000 {
+		return fmt.Errorf("audio.Format: invalid sample rate: %d", f.SampleRate)
+	}
+	if f.Channels < 1 || f.Channels > 8 {
+		return fmt.Errorf("audio.Format: invalid number of channels: %d", f.Channels)
+	}
+	return nil
+}
+
+// Duration returns the duration of given number of samples (over all
+// channels, as in a slice of interleaved samples)
+func (f Format) Duration(nsamples int) time.Duration {
+	if f.SampleRate == 0 || f.Channels == 0 {
+		return 0
+	}
+	return time.Duration(nsamples/f.Channels) * time.Second / time.Duration(f.SampleRate)
+}
+
+// NSamples returns the number of samples (over all channels) for given
+// duration
+func (f Format) NSamples(dur time.Duration) int {
+	return int(dur*time.Duration(f.SampleRate)/time.Second) * f.Channels
+}
+
+// Stream is an audio output stream, to which samples are written to play
+// them -- open one with the oswin.AudioPlayer interface of the App.
+// Streams are safe to use from multiple goroutines, but typically one
+// goroutine writes the samples, as Write blocks until they have been
+// queued for output.
+type Stream interface {
+	// Format returns the format of the samples of the stream
+	Format() Format
+
+	// Write writes given interleaved samples to the stream, blocking until
+	// they have been queued for output, which means that writes are paced
+	// by the playback.  Returns the number of samples written.
+	Write(samples []int16) (int, error)
+
+	// Volume returns the volume of the stream, from 0 (silent) to 1 (full)
+	Volume() float32
+
+	// SetVolume sets the volume of the stream, from 0 (silent) to 1 (full)
+	// -- it applies to samples written after this call
+	SetVolume(vol float32)
+
+	// Played returns the duration of the samples that have been played so
+	// far, as estimated from the time they were written -- can be used to
+	// synchronize to the playback
+	Played() time.Duration
+
+	// Drain blocks until all of the samples written have been played
+	Drain()
+
+	// SetEventFunc sets the function that is called for each event of the
+	// stream -- it may be called from a different goroutine, and must not
+	// block
+	SetEventFunc(fun func(st Stream, ev Events))
+
+	// Err returns the last error of the stream, e.g., after a StreamError
+	// event
+	Err() error
+
+	// Close closes the stream, after the samples written so far have been
+	// played -- call Drain first to wait for that
+	Close() error
+}
+
+// Events are the events of a Stream
+type Events int32
+
+const (
+	// StreamStarted is sent when the first samples are written
+	StreamStarted Events = iota
+
+	// StreamIdle is sent when all of the samples written so far have been
+	// played -- e.g., at the end of a sound
+	StreamIdle
+
+	// StreamError is sent when an error occurs writing the samples -- see
+	// Stream.Err for the error
+	StreamError
+
+	// StreamClosed is sent when the stream is closed
+	StreamClosed
+
+	EventsN
+)
+
+//go:generate stringer -type=Events
+
+var KiT_Events = kit.Enums.AddEnum(EventsN, kit.NotBitFlag, nil)
+
+// Sink is the platform-specific output of a Stream, implemented by drivers
+type Sink interface {
+	// Write writes given interleaved signed 16-bit little-endian samples,
+	// blocking until they have been queued for output
+	Write(b []byte) error
+
+	// Close closes the output, after the samples written so far have been
+	// played
+	Close() error
+}
+
+// ErrClosed is returned for writes to a closed Stream
+var ErrClosed = errors.New("audio.Stream: stream is closed")
+
+// NewStream returns a new Stream that writes its samples to given Sink,
+// applying the volume and sending the events -- for use by drivers
+func NewStream(format Format, sink Sink) Stream {
+	return &stream{format: format, sink: sink, volume: 1}
+}
+
+// stream is the Stream implementation for all drivers, on top of a Sink
+type stream struct {
+	mu      sync.Mutex
+	wmu     sync.Mutex // serializes writes to the sink
+	format  Format
+	sink    Sink
+	volume  float32
+	evFunc  func(st Stream, ev Events)
+	err     error
+	closed  bool
+	started bool
+	written time.Duration // duration of all samples written
+	start   time.Time     // estimated time at which playing started, after any idle gaps
+	end     time.Time     // estimated time at which the samples written so far end
+	idle    *time.Timer   // sends StreamIdle at end
+	buf     []byte
+}
+
+func (st *stream) Format() Format {
+	return st.format
+}
+
+func (st *stream) Volume() float32 {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.volume
+}
+
+func (st *stream) SetVolume(vol float32) {
+	if vol < 0 {
+		vol = 0
+	}
+	if vol > 1 {
+		vol = 1
+	}
+	st.mu.Lock()
+	st.volume = vol
+	st.mu.Unlock()
+}
+
+func (st *stream) SetEventFunc(fun func(st Stream, ev Events)) {
+	st.mu.Lock()
+	st.evFunc = fun
+	st.mu.Unlock()
+}
+
+func (st *stream) Err() error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.err
+}
+
+// sendEvent calls the event function, if set -- must not be called under mu
+func (st *stream) sendEvent(ev Events) {
+	st.mu.Lock()
+	fun := st.evFunc
+	st.mu.Unlock()
+	if fun != nil {
+		fun(st, ev)
+	}
+}
+
+func (st *stream) Write(samples []int16) (int, error) {
+	if len(samples) == 0 {
+		return 0, nil
+	}
+	st.wmu.Lock()
+	defer st.wmu.Unlock()
+	st.mu.Lock()
+	if st.closed {
+		st.mu.Unlock()
+		return 0, ErrClosed
+	}
+	vol := st.volume
+	first := !st.started
+	st.started = true
+	st.mu.Unlock()
+	if first {
+		st.sendEvent(StreamStarted)
+	}
+
+	if cap(st.buf) < 2*len(samples) {
+		st.buf = make([]byte, 2*len(samples))
+	}
+	b := st.buf[:2*len(samples)]
+	for i, s := range samples {
+		if vol < 1 {
+			s = int16(float32(s) * vol)
+		}
+		binary.LittleEndian.PutUint16(b[2*i:], uint16(s))
+	}
+	if err := st.sink.Write(b); err != nil {
+		st.mu.Lock()
+		st.err = err
+		st.mu.Unlock()
+		st.sendEvent(StreamError)
+		return 0, err
+	}
+
+	dur := st.format.Duration(len(samples))
+	st.mu.Lock()
+	now := time.Now()
+	if st.end.Before(now) { // was idle: restart the clock
+		st.start = now.Add(-st.written)
+		st.end = now
+	}
+	st.written += dur
+	st.end = st.end.Add(dur)
+	wait := st.end.Sub(now)
+	if st.idle == nil {
+		st.idle = time.AfterFunc(wait, st.checkIdle)
+	} else {
+		st.idle.Reset(wait)
+	}
+	st.mu.Unlock()
+	return len(samples), nil
+}
+
+// checkIdle sends StreamIdle if all of the samples written have been played
+func (st *stream) checkIdle() {
+	st.mu.Lock()
+	if st.closed {
+		st.mu.Unlock()
+		return
+	}
+	if wait := time.Until(st.end); wait > 0 { // more was written
+		st.idle.Reset(wait)
+		st.mu.Unlock()
+		return
+	}
+	st.mu.Unlock()
+	st.sendEvent(StreamIdle)
+}
+
+func (st *stream) Played() time.Duration {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if !st.started {
+		return 0
+	}
+	if rem := time.Until(st.end); rem > 0 {
+		return st.written - rem
+	}
+	return st.written
+}
+
+func (st *stream) Drain() {
+	st.mu.Lock()
+	wait := time.Until(st.end)
+	st.mu.Unlock()
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+func (st *stream) Close() error {
+	st.wmu.Lock()
+	defer st.wmu.Unlock()
+	st.mu.Lock()
+	if st.closed {
+		st.mu.Unlock()
+		return nil
+	}
+	st.closed = true
+	if st.idle != nil {
+		st.idle.Stop()
+	}
+	st.mu.Unlock()
+	err := st.sink.Close()
+	st.sendEvent(StreamClosed)
+	return err
+}
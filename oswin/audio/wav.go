@@ -0,0 +1,144 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"time"
+)
+
+// OpenWAV opens a WAV sound file, returning its format and its samples,
+// converted to 16 bits -- see DecodeWAV
+func OpenWAV(filename string) (Format, []int16, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return Format{}, nil, err
+	}
+	defer f.Close()
+	return DecodeWAV(f)
+}
+
+// WAV sample encodings
+const (
+	wavPCM        = 1
+	wavFloat      = 3
+	wavExtensible = 0xFFFE
+)
+
+// DecodeWAV decodes a WAV sound, returning its format and its samples,
+// converted to 16 bits.  The samples can be 8, 16, 24 or 32 bit integers,
+// or 32 or 64 bit floats.
+func DecodeWAV(r io.Reader) (Format, []int16, error) {
+	var fm Format
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return fm, nil, err
+	}
+	if len(b) < 12 || string(b[0:4]) != "RIFF" || string(b[8:12]) != "WAVE" {
+		return fm, nil, errors.New("audio.DecodeWAV: not a WAV file")
+	}
+	enc, bits := 0, 0
+	var data []byte
+	for p := 12; p+8 <= len(b); {
+		id := string(b[p : p+4])
+		n := int(binary.LittleEndian.Uint32(b[p+4:]))
+		p += 8
+		if n > len(b)-p {
+			n = len(b) - p // truncated files are common
+		}
+		ch := b[p : p+n]
+		p += n + n%2
+		switch id {
+		case "fmt ":
+			if n < 16 {
+				return fm, nil, errors.New("audio.DecodeWAV: invalid fmt chunk")
+			}
+			enc = int(binary.LittleEndian.Uint16(ch))
+			fm.Channels = int(binary.LittleEndian.Uint16(ch[2:]))
+			fm.SampleRate = int(binary.LittleEndian.Uint32(ch[4:]))
+			bits = int(binary.LittleEndian.Uint16(ch[14:]))
+			if enc == wavExtensible && n >= 26 {
+				enc = int(binary.LittleEndian.Uint16(ch[24:])) // start of sub-format GUID
+			}
+		case "data":
+			data = ch
+		}
+	}
+	if enc == 0 {
+		return fm, nil, errors.New("audio.DecodeWAV: missing fmt chunk")
+	}
+	if data == nil {
+		return fm, nil, errors.New("audio.DecodeWAV: missing data chunk")
+	}
+	if err := fm.Validate(); err != nil {
+		return fm, nil, err
+	}
+	switch {
+	case enc == wavPCM && (bits == 8 || bits == 16 || bits == 24 || bits == 32):
+	case enc == wavFloat && (bits == 32 || bits == 64):
+	default:
+		return fm, nil, fmt.Errorf("audio.DecodeWAV: unsupported encoding: %d with %d bits", enc, bits)
+	}
+	sz := bits / 8
+	n := len(data) / sz
+	n -= n % fm.Channels
+	samples := make([]int16, n)
+	for i := range samples {
+		s := data[i*sz:]
+		switch {
+		case enc == wavFloat && bits == 32:
+			samples[i] = floatSample(float64(math.Float32frombits(binary.LittleEndian.Uint32(s))))
+		case enc == wavFloat:
+			samples[i] = floatSample(math.Float64frombits(binary.LittleEndian.Uint64(s)))
+		case bits == 8: // unsigned
+			samples[i] = int16(int(s[0])-128) << 8
+		case bits == 16:
+			samples[i] = int16(binary.LittleEndian.Uint16(s))
+		case bits == 24:
+			samples[i] = int16(uint16(s[1]) | uint16(s[2])<<8)
+		default:
+			samples[i] = int16(binary.LittleEndian.Uint32(s) >> 16)
+		}
+	}
+	return fm, samples, nil
+}
+
+// floatSample returns the 16 bit sample for given float sample in [-1..1]
+func floatSample(v float64) int16 {
+	v = math.Max(-1, math.Min(1, v))
+	return int16(math.Round(v * math.MaxInt16))
+}
+
+// Tone returns the samples of a sine wave of given frequency in Hz,
+// duration and amplitude (0-1), in all channels of given format -- for
+// simple alert sounds.  The start and end are faded to avoid clicks.
+func Tone(format Format, freq float32, dur time.Duration, amp float32) []int16 {
+	nfr := format.NSamples(dur) / format.Channels
+	fade := format.NSamples(5*time.Millisecond) / format.Channels
+	if fade > nfr/2 {
+		fade = nfr / 2
+	}
+	samples := make([]int16, nfr*format.Channels)
+	for i := 0; i < nfr; i++ {
+		a := float64(amp)
+		switch {
+		case i < fade:
+			a *= float64(i) / float64(fade)
+		case i >= nfr-fade:
+			a *= float64(nfr-1-i) / float64(fade)
+		}
+		v := floatSample(a * math.Sin(2*math.Pi*float64(freq)*float64(i)/float64(format.SampleRate)))
+		for c := 0; c < format.Channels; c++ {
+			samples[i*format.Channels+c] = v
+		}
+	}
+	return samples
+}
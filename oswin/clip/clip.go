@@ -6,7 +6,12 @@
 // represented using mimedata type codes and []byte raw data -- multiple
 // different representations can be available -- in general when writing to
 // the clipboard, having a text/plain version in addition to a more specific
-// format is a good idea
+// format is a good idea.
+//
+// HTML (mimedata.TextHTML), RTF (mimedata.TextRTF) and images
+// (mimedata.ImagePNG) are exchanged with other applications using the
+// native clipboard flavors for them, so that e.g., screenshots can be pasted
+// in, and HTML copied out is pasted as formatted text.
 package clip
 
 import (
@@ -29,13 +34,18 @@ type Board interface {
 	// potentially have multiple types / multiple items, etc -- if first type
 	// listed is a text type, then text-based retrieval is assumed -- always
 	// put the most specific desired type first -- anything else present will
-	// be returned
+	// be returned.  If the first type is mimedata.TextHTML, mimedata.TextRTF
+	// or an image type, the native clipboard flavor for it is read, with
+	// images always returned as mimedata.ImagePNG.
 	Read(types []string) mimedata.Mimes
 
 	// Write writes given mimedata to the clipboard -- in general having a
 	// text/plain representation of the data in addition to a more specific
 	// format is a good idea for anything more complex than plain text -- if
-	// data has > 1 element, it is all encoded as a multipart MIME text string
+	// data has > 1 element, it is all encoded as a multipart MIME text string,
+	// unless it has any HTML, RTF or image elements (see mimedata.IsRich), in
+	// which case each element is written as its native clipboard flavor,
+	// with images encoded as PNG
 	Write(data mimedata.Mimes) error
 
 	// Clear clears the clipboard
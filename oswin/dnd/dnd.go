@@ -49,6 +49,11 @@ type Event struct {
 	// possible (and encouraged)
 	Data mimedata.Mimes
 
+	// Files contains the full paths of files dropped from the OS (e.g.,
+	// Finder / Explorer), for External drops -- these are also in Data as
+	// text/plain items, one per file
+	Files []string
+
 	// Source of the drop -- only available for internal DND actions
 	Source ki.Ki
 
@@ -204,6 +209,17 @@ func (ev Event) OnFocus() bool {
 	return false
 }
 
+// OnWinFocus is false because files can be dropped from the OS onto a
+// window that does not have focus
+func (ev Event) OnWinFocus() bool {
+	return false
+}
+
+// HasFiles returns true if this is an External drop of files from the OS
+func (ev *Event) HasFiles() bool {
+	return ev.Action == External && len(ev.Files) > 0
+}
+
 func (ev MoveEvent) Type() oswin.EventType {
 	return oswin.DNDMoveEvent
 }
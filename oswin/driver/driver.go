@@ -8,6 +8,12 @@
 // license that can be found in the LICENSE file.
 
 // Package driver provides the default driver for accessing a screen.
+//
+// This is the glos driver, unless built with the offscreen build tag,
+// which selects the headless offscreen driver (for tests and
+// server-side rendering), or built for js/wasm, which selects the wasm
+// driver, for running in a web browser, or built for android or ios (with
+// gomobile), which selects the mobile driver.
 package driver
 
 import "github.com/goki/gi/oswin"
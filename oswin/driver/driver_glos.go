@@ -7,6 +7,10 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+// +build !offscreen
+// +build !js
+// +build !android,!ios
+
 package driver
 
 import (
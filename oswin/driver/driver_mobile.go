@@ -0,0 +1,17 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build android ios
+// +build !offscreen
+
+package driver
+
+import (
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/driver/mobile"
+)
+
+func driverMain(f func(oswin.App)) {
+	mobile.Main(f)
+}
@@ -0,0 +1,16 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build js,wasm,!offscreen
+
+package driver
+
+import (
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/driver/wasm"
+)
+
+func driverMain(f func(oswin.App)) {
+	wasm.Main(f)
+}
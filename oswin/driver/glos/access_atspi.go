@@ -0,0 +1,1132 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux,!android dragonfly openbsd
+
+package glos
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/driver/internal/dbus"
+)
+
+// the accessibility information is provided to assistive technologies
+// (e.g., the Orca screen reader) with AT-SPI
+// (https://gitlab.gnome.org/GNOME/at-spi2-core): the app connects to the
+// accessibility bus, whose address is given by the org.a11y.Bus service of
+// the session bus, when org.a11y.Status says that accessibility is enabled,
+// embeds its application object in the registry there, and exports the
+// elements of the trees of its windows as accessible objects, with the
+// Accessible, Component, and, depending on their role, Value and Text
+// interfaces, emitting the events for the changes of the trees, the focus
+// and announcements.  Setting NO_AT_BRIDGE=1 in the environment disables it,
+// as for GTK and Qt.
+
+const (
+	atspiRootPath  = dbus.ObjectPath("/org/a11y/atspi/accessible/root")
+	atspiNullPath  = dbus.ObjectPath("/org/a11y/atspi/null")
+	atspiRegistry  = "org.a11y.atspi.Registry"
+	atspiAccIface  = "org.a11y.atspi.Accessible"
+	atspiAppIface  = "org.a11y.atspi.Application"
+	atspiCompIface = "org.a11y.atspi.Component"
+	atspiValIface  = "org.a11y.atspi.Value"
+	atspiTextIface = "org.a11y.atspi.Text"
+	atspiObjEvent  = "org.a11y.atspi.Event.Object"
+	atspiWinEvent  = "org.a11y.atspi.Event.Window"
+	atspiFocEvent  = "org.a11y.atspi.Event.Focus"
+)
+
+// atspiRoles are the AT-SPI roles (AtspiRole) for the oswin.AccessRoles
+var atspiRoles = [oswin.AccessRolesN]uint32{
+	oswin.RoleNone:        20, // filler
+	oswin.RoleWindow:      23, // frame
+	oswin.RoleDialog:      16,
+	oswin.RoleGroup:       39, // panel
+	oswin.RoleLabel:       29,
+	oswin.RoleImage:       27,
+	oswin.RoleButton:      43, // push button
+	oswin.RoleCheckBox:    7,
+	oswin.RoleTextField:   79, // entry
+	oswin.RoleTextArea:    61, // text
+	oswin.RoleSlider:      51,
+	oswin.RoleScrollBar:   48,
+	oswin.RoleSpinBox:     52, // spin button
+	oswin.RoleComboBox:    11,
+	oswin.RoleMenuBar:     34,
+	oswin.RoleMenu:        33,
+	oswin.RoleMenuItem:    35,
+	oswin.RoleToolBar:     63,
+	oswin.RoleTabList:     38, // page tab list
+	oswin.RoleTab:         37, // page tab
+	oswin.RoleTree:        65,
+	oswin.RoleTreeItem:    91,
+	oswin.RoleTable:       55,
+	oswin.RoleSplitter:    53, // split pane
+	oswin.RoleProgressBar: 42,
+	oswin.RoleLink:        88,
+}
+
+// atspiRoleNames are the names of the AT-SPI roles of the oswin.AccessRoles
+var atspiRoleNames = [oswin.AccessRolesN]string{
+	"filler", "frame", "dialog", "panel", "label", "image", "push button",
+	"check box", "entry", "text", "slider", "scroll bar", "spin button",
+	"combo box", "menu bar", "menu", "menu item", "tool bar", "page tab list",
+	"page tab", "tree", "tree item", "table", "split pane", "progress bar",
+	"link",
+}
+
+const atspiRoleApplication = 75
+
+// AT-SPI states (AtspiStateType)
+const (
+	atspiActive      = 1
+	atspiChecked     = 4
+	atspiCollapsed   = 5
+	atspiEditable    = 7
+	atspiEnabled     = 8
+	atspiExpandable  = 9
+	atspiExpanded    = 10
+	atspiFocusable   = 11
+	atspiFocused     = 12
+	atspiMultiLine   = 17
+	atspiSelectable  = 22
+	atspiSelected    = 23
+	atspiSensitive   = 24
+	atspiShowing     = 25
+	atspiSingleLine  = 26
+	atspiVisible     = 30
+	atspiCheckable   = 41
+	atspiHasPopup    = 42
+	atspiReadOnly    = 43
+	atspiStatesWords = 2
+)
+
+// atspiStateNames are the names of the states for StateChanged events
+var atspiStateNames = map[int]string{
+	atspiActive: "active", atspiChecked: "checked", atspiCollapsed: "collapsed",
+	atspiEditable: "editable", atspiEnabled: "enabled", atspiExpandable: "expandable",
+	atspiExpanded: "expanded", atspiFocusable: "focusable", atspiFocused: "focused",
+	atspiMultiLine: "multi-line", atspiSelectable: "selectable", atspiSelected: "selected",
+	atspiSensitive: "sensitive", atspiShowing: "showing", atspiSingleLine: "single-line",
+	atspiVisible: "visible", atspiCheckable: "checkable", atspiHasPopup: "has-popup",
+	atspiReadOnly: "read-only",
+}
+
+// atspiSigs are the argument signatures of the methods handled, by
+// interface and method
+var atspiSigs = map[string]string{
+	"org.freedesktop.DBus.Properties.Get":    "ss",
+	"org.freedesktop.DBus.Properties.GetAll": "s",
+	"org.freedesktop.DBus.Properties.Set":    "ssv",
+	atspiAccIface + ".GetChildAtIndex":       "i",
+	atspiAppIface + ".GetLocale":             "u",
+	atspiCompIface + ".Contains":             "iiu",
+	atspiCompIface + ".GetAccessibleAtPoint": "iiu",
+	atspiCompIface + ".GetExtents":           "u",
+	atspiCompIface + ".GetPosition":          "u",
+	atspiTextIface + ".GetText":              "ii",
+	atspiTextIface + ".GetCharacterAtOffset": "i",
+	atspiTextIface + ".GetStringAtOffset":    "iu",
+	atspiTextIface + ".GetTextAtOffset":      "iu",
+	atspiTextIface + ".GetAttributes":        "i",
+	atspiTextIface + ".GetAttributeRun":      "ib",
+}
+
+// atspiBridge is the connection to the accessibility bus, shared by all
+// windows
+type atspiBridge struct {
+	mu       sync.Mutex
+	sess     *dbus.Conn
+	conn     *dbus.Conn
+	enabled  bool
+	srEnable bool
+	dialing  bool
+	parent   dbus.Struct
+	appID    int32
+	wins     []*atspiWin
+	objs     map[dbus.ObjectPath]*atspiObj
+	lastPath int
+	focusWin *atspiWin
+}
+
+// atspiWin is the accessibility tree of a window
+type atspiWin struct {
+	w     *windowImpl
+	tree  *oswin.AccessNode
+	path  dbus.ObjectPath
+	paths map[string]dbus.ObjectPath // paths of the elements by ID, stable across updates
+	focus string
+}
+
+// atspiObj is an exported element of the tree of a window
+type atspiObj struct {
+	win    *atspiWin
+	node   *oswin.AccessNode
+	parent dbus.ObjectPath // atspiRootPath for the root of the window
+	index  int
+}
+
+// atspiEvent is an event to emit, after the lock is released
+type atspiEvent struct {
+	path   dbus.ObjectPath
+	iface  string
+	member string
+	detail string
+	d1     int32
+	d2     int32
+	data   dbus.Variant
+}
+
+var atspi = &atspiBridge{objs: make(map[dbus.ObjectPath]*atspiObj)}
+
+var atspiOnce sync.Once
+
+// start connects to the session bus to follow the org.a11y.Status
+// properties, and to the accessibility bus if it is enabled
+func (b *atspiBridge) start() {
+	if os.Getenv("NO_AT_BRIDGE") == "1" {
+		return
+	}
+	sess, err := dbus.SessionBus(b.handleSession)
+	if err != nil {
+		return // no session bus, so no assistive technologies either
+	}
+	b.mu.Lock()
+	b.sess = sess
+	b.mu.Unlock()
+	sess.AddMatch("type='signal',interface='org.freedesktop.DBus.Properties',member='PropertiesChanged',path='/org/a11y/bus'")
+	en, _ := sess.GetProperty("org.a11y.Bus", "/org/a11y/bus", "org.a11y.Status", "IsEnabled")
+	sr, _ := sess.GetProperty("org.a11y.Bus", "/org/a11y/bus", "org.a11y.Status", "ScreenReaderEnabled")
+	b.mu.Lock()
+	b.enabled, _ = en.(bool)
+	b.srEnable, _ = sr.(bool)
+	b.mu.Unlock()
+	b.connect()
+}
+
+// handleSession follows the changes of the org.a11y.Status properties
+func (b *atspiBridge) handleSession(c *dbus.Conn, m *dbus.Message) {
+	if m.Type == dbus.TypeMethodCall {
+		c.ReplyError(m, dbus.ErrUnknownObject, "no objects are exported")
+		return
+	}
+	if m.Type != dbus.TypeSignal || m.Member != "PropertiesChanged" || len(m.Body) < 2 || m.Body[0] != "org.a11y.Status" {
+		return
+	}
+	chg, _ := m.Body[1].([]dbus.DictEntry)
+	b.mu.Lock()
+	for _, de := range chg {
+		v, _ := de.Value.(dbus.Variant).Value.(bool)
+		switch de.Key {
+		case "IsEnabled":
+			b.enabled = v
+		case "ScreenReaderEnabled":
+			b.srEnable = v
+		}
+	}
+	b.mu.Unlock()
+	go b.connect()
+}
+
+// connect connects to the accessibility bus and embeds the application
+// in the registry, if accessibility is enabled and it is not connected
+func (b *atspiBridge) connect() {
+	b.mu.Lock()
+	if !(b.enabled || b.srEnable) || b.conn != nil || b.dialing || b.sess == nil {
+		b.mu.Unlock()
+		return
+	}
+	b.dialing = true
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		b.dialing = false
+		b.mu.Unlock()
+	}()
+	addr := os.Getenv("AT_SPI_BUS_ADDRESS")
+	if addr == "" {
+		rep, err := b.sess.Call("org.a11y.Bus", "/org/a11y/bus", "org.a11y.Bus", "GetAddress", "")
+		if err != nil || len(rep) == 0 {
+			return
+		}
+		addr, _ = rep[0].(string)
+	}
+	conn, err := dbus.Dial(addr, b.handle)
+	if err != nil {
+		return
+	}
+	rep, err := conn.Call(atspiRegistry, atspiRootPath, "org.a11y.atspi.Socket", "Embed", "(so)", dbus.Struct{conn.Name, atspiRootPath})
+	if err != nil || len(rep) == 0 {
+		conn.Close()
+		return
+	}
+	b.mu.Lock()
+	b.conn = conn
+	par, ok := rep[0].(dbus.Struct)
+	if !ok || len(par) != 2 {
+		par = dbus.Struct{"", atspiNullPath}
+	}
+	b.parent = par
+	b.mu.Unlock()
+}
+
+// active returns true if accessibility is enabled and the app is connected
+// to the accessibility bus -- it reconnects if the connection was lost
+func (b *atspiBridge) active() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.conn != nil && b.conn.IsClosed() {
+		b.conn = nil
+		go b.connect()
+	}
+	return b.conn != nil && (b.enabled || b.srEnable)
+}
+
+// emit emits the events, if connected
+func (b *atspiBridge) emit(evs []atspiEvent) {
+	b.mu.Lock()
+	conn := b.conn
+	b.mu.Unlock()
+	if conn == nil {
+		return
+	}
+	for _, ev := range evs {
+		conn.Emit(ev.path, ev.iface, ev.member, "siiva{sv}", ev.detail, ev.d1, ev.d2, ev.data, map[string]dbus.Variant{})
+	}
+}
+
+// objEvent returns an Object event
+func objEvent(path dbus.ObjectPath, member, detail string, d1 int32, data dbus.Variant) atspiEvent {
+	return atspiEvent{path: path, iface: atspiObjEvent, member: member, detail: detail, d1: d1, data: data}
+}
+
+// ref returns the (so) reference to the object at given path -- must be
+// called with the lock held
+func (b *atspiBridge) ref(path dbus.ObjectPath) dbus.Struct {
+	if b.conn == nil || path == atspiNullPath {
+		return dbus.Struct{"", atspiNullPath}
+	}
+	return dbus.Struct{b.conn.Name, path}
+}
+
+// refVariant returns the reference to the object at given path as a variant
+func (b *atspiBridge) refVariant(path dbus.ObjectPath) dbus.Variant {
+	return dbus.Variant{Sig: "(so)", Value: b.ref(path)}
+}
+
+// win returns the tree of given window, adding it if new -- must be
+// called with the lock held
+func (b *atspiBridge) win(w *windowImpl) *atspiWin {
+	for _, aw := range b.wins {
+		if aw.w == w {
+			return aw
+		}
+	}
+	aw := &atspiWin{w: w, paths: make(map[string]dbus.ObjectPath)}
+	b.wins = append(b.wins, aw)
+	return aw
+}
+
+// nodePath returns the path of the element with given ID, assigning a new
+// one if needed -- must be called with the lock held
+func (b *atspiBridge) nodePath(aw *atspiWin, id string) dbus.ObjectPath {
+	if p, ok := aw.paths[id]; ok {
+		return p
+	}
+	b.lastPath++
+	p := dbus.ObjectPath("/org/a11y/atspi/accessible/" + strconv.Itoa(b.lastPath))
+	aw.paths[id] = p
+	return p
+}
+
+// addObjs adds the objects for given element and its children
+func (b *atspiBridge) addObjs(aw *atspiWin, an *oswin.AccessNode, parent dbus.ObjectPath, idx int, ids map[string]bool) {
+	p := b.nodePath(aw, an.ID)
+	ids[an.ID] = true
+	b.objs[p] = &atspiObj{win: aw, node: an, parent: parent, index: idx}
+	for i, k := range an.Kids {
+		b.addObjs(aw, k, p, i, ids)
+	}
+}
+
+// removeObjs removes the objects of given window
+func (b *atspiBridge) removeObjs(aw *atspiWin) {
+	for _, p := range aw.paths {
+		if ob, ok := b.objs[p]; ok && ob.win == aw {
+			delete(b.objs, p)
+		}
+	}
+}
+
+// setTree sets the tree of given window, returning the events for the
+// changes from the previous tree
+func (b *atspiBridge) setTree(w *windowImpl, root *oswin.AccessNode) []atspiEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	aw := b.win(w)
+	old := make(map[string]*oswin.AccessNode)
+	atspiWalk(aw.tree, func(an *oswin.AccessNode) { old[an.ID] = an })
+	isNew := aw.tree == nil
+	b.removeObjs(aw)
+	aw.tree = root
+	var evs []atspiEvent
+	if root == nil {
+		aw.paths = make(map[string]dbus.ObjectPath)
+		return evs
+	}
+	ids := make(map[string]bool)
+	b.addObjs(aw, root, atspiRootPath, b.winIndex(aw), ids)
+	aw.path = aw.paths[root.ID]
+	defer func() { // paths of removed elements are needed for the events
+		for id := range aw.paths {
+			if !ids[id] {
+				delete(aw.paths, id)
+			}
+		}
+	}()
+	if isNew {
+		evs = append(evs, objEvent(atspiRootPath, "ChildrenChanged", "add", int32(b.winIndex(aw)), b.refVariant(aw.path)))
+		return evs
+	}
+	atspiWalk(root, func(an *oswin.AccessNode) {
+		on, ok := old[an.ID]
+		if !ok {
+			return
+		}
+		p := aw.paths[an.ID]
+		if an.Name != on.Name {
+			evs = append(evs, objEvent(p, "PropertyChange", "accessible-name", 0, dbus.MakeVariant(an.Name)))
+		}
+		if an.Desc != on.Desc {
+			evs = append(evs, objEvent(p, "PropertyChange", "accessible-description", 0, dbus.MakeVariant(an.Desc)))
+		}
+		if an.Value != on.Value {
+			evs = append(evs, objEvent(p, "PropertyChange", "accessible-value", 0, dbus.MakeVariant(an.Value)))
+			if atspiHasText(an) && an.Text == nil {
+				evs = append(evs, objEvent(p, "TextChanged", "insert", 0, dbus.MakeVariant(an.Value)))
+			}
+		}
+		if an.Text != nil && (an.Text != on.Text || an.TextRev != on.TextRev) {
+			// only the line of the caret is sent, as the text is served on demand
+			st, ed := an.Text.AccessTextLine(an.Caret)
+			ev := objEvent(p, "TextChanged", "insert", int32(st), dbus.MakeVariant(an.Text.AccessTextRange(st, ed)))
+			ev.d2 = int32(ed - st)
+			evs = append(evs, ev)
+		}
+		if an.Caret != on.Caret && atspiHasText(an) {
+			evs = append(evs, objEvent(p, "TextCaretMoved", "", int32(an.Caret), dbus.MakeVariant(int32(0))))
+		}
+		if an.States != on.States {
+			ost, nst := atspiStates(aw, on), atspiStates(aw, an)
+			for st, nm := range atspiStateNames {
+				if st == atspiFocused {
+					continue // sent by AccessFocus
+				}
+				was, is := ost[st/32].(uint32)&(1<<uint(st%32)) != 0, nst[st/32].(uint32)&(1<<uint(st%32)) != 0
+				if was != is {
+					d1 := int32(0)
+					if is {
+						d1 = 1
+					}
+					evs = append(evs, objEvent(p, "StateChanged", nm, d1, dbus.MakeVariant(int32(0))))
+				}
+			}
+		}
+		okids := make(map[string]bool, len(on.Kids))
+		for _, k := range on.Kids {
+			okids[k.ID] = true
+		}
+		nkids := make(map[string]bool, len(an.Kids))
+		for i, k := range an.Kids {
+			nkids[k.ID] = true
+			if !okids[k.ID] {
+				evs = append(evs, objEvent(p, "ChildrenChanged", "add", int32(i), b.refVariant(aw.paths[k.ID])))
+			}
+		}
+		for i, k := range on.Kids {
+			if !nkids[k.ID] {
+				evs = append(evs, objEvent(p, "ChildrenChanged", "remove", int32(i), b.refVariant(aw.paths[k.ID])))
+			}
+		}
+	})
+	return evs
+}
+
+// winIndex returns the index of the window among the children of the
+// application, which only has the windows with trees
+func (b *atspiBridge) winIndex(aw *atspiWin) int {
+	idx := 0
+	for _, ow := range b.wins {
+		if ow == aw {
+			return idx
+		}
+		if ow.tree != nil {
+			idx++
+		}
+	}
+	return -1
+}
+
+// treeWins returns the windows with trees, which are the children of the
+// application
+func (b *atspiBridge) treeWins() []*atspiWin {
+	var wins []*atspiWin
+	for _, aw := range b.wins {
+		if aw.tree != nil {
+			wins = append(wins, aw)
+		}
+	}
+	return wins
+}
+
+// removeWin removes the tree of given window when it is closed
+func (b *atspiBridge) removeWin(w *windowImpl) []atspiEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, aw := range b.wins {
+		if aw.w != w {
+			continue
+		}
+		var evs []atspiEvent
+		if aw.tree != nil {
+			evs = append(evs, objEvent(atspiRootPath, "ChildrenChanged", "remove", int32(b.winIndex(aw)), b.refVariant(aw.path)))
+		}
+		b.removeObjs(aw)
+		b.wins = append(b.wins[:i], b.wins[i+1:]...)
+		if b.focusWin == aw {
+			b.focusWin = nil
+		}
+		return evs
+	}
+	return nil
+}
+
+// setFocus sets the focused element of given window, returning the events
+func (b *atspiBridge) setFocus(w *windowImpl, id string) []atspiEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	aw := b.win(w)
+	var evs []atspiEvent
+	if aw.focus != "" {
+		if p, ok := aw.paths[aw.focus]; ok {
+			evs = append(evs, objEvent(p, "StateChanged", "focused", 0, dbus.MakeVariant(int32(0))))
+		}
+	}
+	aw.focus = id
+	if id == "" || aw.tree == nil {
+		return evs
+	}
+	if b.focusWin != aw {
+		if b.focusWin != nil && b.focusWin.tree != nil {
+			evs = append(evs, atspiEvent{path: b.focusWin.path, iface: atspiWinEvent, member: "Deactivate", data: dbus.MakeVariant("")})
+		}
+		b.focusWin = aw
+		evs = append(evs, atspiEvent{path: aw.path, iface: atspiWinEvent, member: "Activate", data: dbus.MakeVariant("")})
+	}
+	if p, ok := aw.paths[id]; ok {
+		evs = append(evs, atspiEvent{path: p, iface: atspiFocEvent, member: "Focus", data: dbus.MakeVariant(int32(0))})
+		evs = append(evs, objEvent(p, "StateChanged", "focused", 1, dbus.MakeVariant(int32(0))))
+	}
+	return evs
+}
+
+// announce returns the event for announcing given message
+func (b *atspiBridge) announce(w *windowImpl, msg string, assertive bool) []atspiEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	path := atspiRootPath
+	if aw := b.win(w); aw.tree != nil {
+		path = aw.path
+	}
+	pol := int32(1) // polite
+	if assertive {
+		pol = 2
+	}
+	return []atspiEvent{objEvent(path, "Announcement", "", pol, dbus.MakeVariant(msg))}
+}
+
+// atspiWalk calls fun for given element and all its descendants
+func atspiWalk(an *oswin.AccessNode, fun func(an *oswin.AccessNode)) {
+	if an == nil {
+		return
+	}
+	fun(an)
+	for _, k := range an.Kids {
+		atspiWalk(k, fun)
+	}
+}
+
+// atspiStates returns the AT-SPI state set of given element
+func atspiStates(aw *atspiWin, an *oswin.AccessNode) []interface{} {
+	var bits [atspiStatesWords]uint32
+	set := func(st int) {
+		bits[st/32] |= 1 << uint(st%32)
+	}
+	if !an.HasState(oswin.StateDisabled) {
+		set(atspiEnabled)
+		set(atspiSensitive)
+	}
+	if !an.HasState(oswin.StateInvisible) {
+		set(atspiVisible)
+		set(atspiShowing)
+	}
+	if an.HasState(oswin.StateFocusable) {
+		set(atspiFocusable)
+	}
+	if an.ID == aw.focus || an.HasState(oswin.StateFocused) {
+		set(atspiFocused)
+	}
+	if an.HasState(oswin.StateSelected) {
+		set(atspiSelectable)
+		set(atspiSelected)
+	}
+	if an.HasState(oswin.StateCheckable) {
+		set(atspiCheckable)
+	}
+	if an.HasState(oswin.StateChecked) {
+		set(atspiChecked)
+	}
+	if an.HasState(oswin.StateExpandable) {
+		set(atspiExpandable)
+		if an.HasState(oswin.StateExpanded) {
+			set(atspiExpanded)
+		} else {
+			set(atspiCollapsed)
+		}
+	}
+	if an.HasState(oswin.StateEditable) {
+		set(atspiEditable)
+	}
+	if an.HasState(oswin.StateReadOnly) {
+		set(atspiReadOnly)
+	}
+	if an.HasState(oswin.StateHasPopup) {
+		set(atspiHasPopup)
+	}
+	switch an.Role {
+	case oswin.RoleTextField:
+		set(atspiSingleLine)
+	case oswin.RoleTextArea:
+		set(atspiMultiLine)
+	case oswin.RoleWindow, oswin.RoleDialog:
+		if aw.w != nil && aw.w.IsFocus() {
+			set(atspiActive)
+		}
+	}
+	return []interface{}{bits[0], bits[1]}
+}
+
+// atspiHasValue returns true if given element has the Value interface
+func atspiHasValue(an *oswin.AccessNode) bool {
+	switch an.Role {
+	case oswin.RoleSlider, oswin.RoleScrollBar, oswin.RoleSpinBox, oswin.RoleProgressBar:
+		return true
+	}
+	return false
+}
+
+// atspiHasText returns true if given element has the Text interface
+func atspiHasText(an *oswin.AccessNode) bool {
+	switch an.Role {
+	case oswin.RoleLabel, oswin.RoleTextField, oswin.RoleTextArea:
+		return true
+	}
+	return false
+}
+
+// atspiText returns the source of the text of given element, for the Text
+// interface: its Text if any, or else its value (or name for a label)
+func atspiText(an *oswin.AccessNode) oswin.AccessText {
+	if an.Text != nil {
+		return an.Text
+	}
+	if an.Role == oswin.RoleLabel && an.Value == "" {
+		return atspiRunes(an.Name)
+	}
+	return atspiRunes(an.Value)
+}
+
+// atspiRunes is the text of an element without a Text source, which is
+// short enough to be kept in its AccessNode
+type atspiRunes []rune
+
+func (r atspiRunes) AccessTextLen() int {
+	return len(r)
+}
+
+func (r atspiRunes) AccessTextRange(st, ed int) string {
+	if ed > len(r) {
+		ed = len(r)
+	}
+	if st < 0 {
+		st = 0
+	}
+	if st >= ed {
+		return ""
+	}
+	return string(r[st:ed])
+}
+
+func (r atspiRunes) AccessTextLine(off int) (st, ed int) {
+	if off > len(r) {
+		off = len(r)
+	}
+	return atspiRange(r, off, 3)
+}
+
+// atspiIfaces returns the interfaces of given element
+func atspiIfaces(an *oswin.AccessNode) []string {
+	ifs := []string{atspiAccIface, atspiCompIface}
+	if atspiHasValue(an) {
+		ifs = append(ifs, atspiValIface)
+	}
+	if atspiHasText(an) {
+		ifs = append(ifs, atspiTextIface)
+	}
+	return ifs
+}
+
+// atspiRange returns the start and end of the unit of text around given
+// offset: granularity 0 for a character, 1 for a word, 3 for a line, and
+// the whole text otherwise (as for sentences and paragraphs)
+func atspiRange(txt []rune, off int, gran uint32) (int, int) {
+	if off < 0 || off > len(txt) {
+		return 0, 0
+	}
+	switch gran {
+	case 0:
+		if off == len(txt) {
+			return off, off
+		}
+		return off, off + 1
+	case 1, 3:
+		sep := func(r rune) bool { return r == '\n' }
+		if gran == 1 {
+			sep = unicode.IsSpace
+		}
+		st, ed := off, off
+		for st > 0 && !sep(txt[st-1]) {
+			st--
+		}
+		for ed < len(txt) && !sep(txt[ed]) {
+			ed++
+		}
+		if gran == 3 && ed < len(txt) {
+			ed++ // lines include their newline
+		}
+		return st, ed
+	}
+	return 0, len(txt)
+}
+
+// atspiTextRange returns the start and end of the unit of text around given
+// offset of the text source (see atspiRange), only getting the text of the
+// line around it -- sentences and paragraphs are taken as lines, so the
+// whole text is never needed
+func atspiTextRange(txt oswin.AccessText, off int, gran uint32) (int, int) {
+	n := txt.AccessTextLen()
+	if off < 0 || off > n {
+		return 0, 0
+	}
+	switch gran {
+	case 0:
+		if off == n {
+			return off, off
+		}
+		return off, off + 1
+	case 1:
+		lst, led := txt.AccessTextLine(off)
+		st, ed := atspiRange([]rune(txt.AccessTextRange(lst, led)), off-lst, 1)
+		return lst + st, lst + ed
+	}
+	return txt.AccessTextLine(off)
+}
+
+// atspiTextBoundary returns the granularity for a text boundary type of
+// GetTextAtOffset: char, word start / end, sentence start / end, line start
+// / end
+func atspiTextBoundary(bnd uint32) uint32 {
+	switch bnd {
+	case 0:
+		return 0
+	case 1, 2:
+		return 1
+	case 5, 6:
+		return 3
+	}
+	return 2
+}
+
+// extents returns the bounds of given element in given coordinate type:
+// 0 for the screen, 1 for the window, and 2 for the parent
+func (b *atspiBridge) extents(ob *atspiObj, ctype uint32) image.Rectangle {
+	r := ob.node.Bounds
+	switch ctype {
+	case 0:
+		if ob.win.w != nil {
+			r = r.Add(ob.win.w.Pos)
+		}
+	case 2:
+		if po, ok := b.objs[ob.parent]; ok {
+			r = r.Sub(po.node.Bounds.Min)
+		}
+	}
+	return r
+}
+
+// rectStruct returns the (iiii) extents of given rectangle
+func rectStruct(r image.Rectangle) dbus.Struct {
+	return dbus.Struct{int32(r.Min.X), int32(r.Min.Y), int32(r.Dx()), int32(r.Dy())}
+}
+
+// atspiAppName returns the name of the application
+func atspiAppName() string {
+	if oswin.TheApp != nil && oswin.TheApp.Name() != "" {
+		return oswin.TheApp.Name()
+	}
+	return filepath.Base(os.Args[0])
+}
+
+// handle handles the method calls on the accessibility bus
+func (b *atspiBridge) handle(c *dbus.Conn, m *dbus.Message) {
+	if m.Type != dbus.TypeMethodCall {
+		return
+	}
+	if sig, ok := atspiSigs[m.Iface+"."+m.Member]; ok && string(m.Sig) != sig {
+		c.ReplyError(m, dbus.ErrInvalidArgs, "invalid arguments for "+m.Member+": "+string(m.Sig))
+		return
+	}
+	switch m.Iface {
+	case "org.freedesktop.DBus.Peer":
+		if m.Member == "Ping" {
+			c.Reply(m, "")
+		} else {
+			c.ReplyError(m, dbus.ErrUnknownMethod, "unknown method: "+m.Member)
+		}
+		return
+	case "org.freedesktop.DBus.Introspectable":
+		c.Reply(m, "s", `<!DOCTYPE node PUBLIC "-//freedesktop//DTD D-BUS Object Introspection 1.0//EN" "http://www.freedesktop.org/standards/dbus/1.0/introspect.dtd"><node/>`)
+		return
+	}
+	if _, ok := atspiSigs[m.Iface+"."+m.Member]; m.Iface == dbusProps && !ok {
+		c.ReplyError(m, dbus.ErrUnknownMethod, "unknown method: "+m.Member)
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if m.Path == atspiRootPath {
+		b.handleApp(c, m)
+		return
+	}
+	ob, ok := b.objs[m.Path]
+	if !ok {
+		c.ReplyError(m, dbus.ErrUnknownObject, "unknown object: "+string(m.Path))
+		return
+	}
+	switch m.Iface {
+	case dbusProps:
+		b.handleProps(c, m, b.objProps(ob, m.Body[0].(string)))
+	case atspiAccIface:
+		kids := make([]dbus.ObjectPath, len(ob.node.Kids))
+		for i, k := range ob.node.Kids {
+			kids[i] = ob.win.paths[k.ID]
+		}
+		b.handleAccessible(c, m, kids, ob.index, atspiRoles[ob.node.Role], atspiRoleNames[ob.node.Role], atspiStates(ob.win, ob.node), atspiIfaces(ob.node))
+	case atspiCompIface:
+		b.handleComponent(c, m, ob)
+	case atspiTextIface:
+		if !atspiHasText(ob.node) {
+			c.ReplyError(m, dbus.ErrUnknownInterface, "no text interface")
+			return
+		}
+		b.handleText(c, m, atspiText(ob.node))
+	default:
+		c.ReplyError(m, dbus.ErrUnknownInterface, "unknown interface: "+m.Iface)
+	}
+}
+
+// handleProps handles the Properties methods, for given properties of the
+// interface, which are nil if the object does not have it
+func (b *atspiBridge) handleProps(c *dbus.Conn, m *dbus.Message, props map[string]dbus.Variant) {
+	if props == nil {
+		c.ReplyError(m, dbus.ErrUnknownInterface, "unknown interface")
+		return
+	}
+	switch m.Member {
+	case "Get":
+		pn := m.Body[1].(string)
+		if v, ok := props[pn]; ok {
+			c.Reply(m, "v", v)
+		} else {
+			c.ReplyError(m, dbus.ErrUnknownProperty, "unknown property: "+pn)
+		}
+	case "GetAll":
+		c.Reply(m, "a{sv}", props)
+	case "Set":
+		if m.Body[0] == atspiAppIface && m.Body[1] == "Id" {
+			if id, ok := m.Body[2].(dbus.Variant).Value.(int32); ok {
+				b.appID = id // set by the registry
+				c.Reply(m, "")
+				return
+			}
+		}
+		c.ReplyError(m, "org.freedesktop.DBus.Error.PropertyReadOnly", "property is read-only")
+	default:
+		c.ReplyError(m, dbus.ErrUnknownMethod, "unknown method: "+m.Member)
+	}
+}
+
+// objProps returns the properties of given interface of an element, or
+// nil if it does not have it
+func (b *atspiBridge) objProps(ob *atspiObj, iface string) map[string]dbus.Variant {
+	an := ob.node
+	switch iface {
+	case atspiAccIface:
+		return map[string]dbus.Variant{
+			"Name":         dbus.MakeVariant(an.Name),
+			"Description":  dbus.MakeVariant(an.Desc),
+			"Parent":       b.refVariant(ob.parent),
+			"ChildCount":   dbus.MakeVariant(int32(len(an.Kids))),
+			"Locale":       dbus.MakeVariant(""),
+			"AccessibleId": dbus.MakeVariant(an.ID),
+		}
+	case atspiValIface:
+		if !atspiHasValue(an) {
+			return nil
+		}
+		cur, _ := strconv.ParseFloat(strings.TrimSpace(an.Value), 64)
+		return map[string]dbus.Variant{
+			"MinimumValue":     dbus.MakeVariant(an.Min),
+			"MaximumValue":     dbus.MakeVariant(an.Max),
+			"MinimumIncrement": dbus.MakeVariant(an.Step),
+			"CurrentValue":     dbus.MakeVariant(cur),
+			"Text":             dbus.MakeVariant(an.Value),
+		}
+	case atspiTextIface:
+		if !atspiHasText(an) {
+			return nil
+		}
+		return map[string]dbus.Variant{
+			"CharacterCount": dbus.MakeVariant(int32(atspiText(an).AccessTextLen())),
+			"CaretOffset":    dbus.MakeVariant(int32(an.Caret)),
+		}
+	case atspiCompIface:
+		return map[string]dbus.Variant{}
+	}
+	return nil
+}
+
+// handleApp handles the method calls on the application object
+func (b *atspiBridge) handleApp(c *dbus.Conn, m *dbus.Message) {
+	wins := b.treeWins()
+	switch m.Iface {
+	case dbusProps:
+		var props map[string]dbus.Variant
+		switch m.Body[0] {
+		case atspiAccIface:
+			props = map[string]dbus.Variant{
+				"Name":         dbus.MakeVariant(atspiAppName()),
+				"Description":  dbus.MakeVariant(""),
+				"Parent":       dbus.Variant{Sig: "(so)", Value: b.parent},
+				"ChildCount":   dbus.MakeVariant(int32(len(wins))),
+				"Locale":       dbus.MakeVariant(""),
+				"AccessibleId": dbus.MakeVariant(""),
+			}
+		case atspiAppIface:
+			props = map[string]dbus.Variant{
+				"ToolkitName":  dbus.MakeVariant("GoGi"),
+				"Version":      dbus.MakeVariant(""),
+				"AtspiVersion": dbus.MakeVariant("2.1"),
+				"Id":           dbus.MakeVariant(b.appID),
+			}
+		}
+		b.handleProps(c, m, props)
+	case atspiAccIface:
+		kids := make([]dbus.ObjectPath, len(wins))
+		for i, aw := range wins {
+			kids[i] = aw.path
+		}
+		b.handleAccessible(c, m, kids, -1, atspiRoleApplication, "application", []interface{}{uint32(0), uint32(0)}, []string{atspiAccIface, atspiAppIface})
+	case atspiAppIface:
+		switch m.Member {
+		case "GetLocale":
+			c.Reply(m, "s", "")
+		default:
+			c.ReplyError(m, dbus.ErrUnknownMethod, "unknown method: "+m.Member)
+		}
+	default:
+		c.ReplyError(m, dbus.ErrUnknownInterface, "unknown interface: "+m.Iface)
+	}
+}
+
+// handleAccessible handles the Accessible methods, for an object with given
+// children, index in parent, role, role name, states and interfaces
+func (b *atspiBridge) handleAccessible(c *dbus.Conn, m *dbus.Message, kids []dbus.ObjectPath, idx int, role uint32, roleName string, states []interface{}, ifaces []string) {
+	switch m.Member {
+	case "GetChildAtIndex":
+		i := int(m.Body[0].(int32))
+		if i < 0 || i >= len(kids) {
+			c.Reply(m, "(so)", b.ref(atspiNullPath))
+			return
+		}
+		c.Reply(m, "(so)", b.ref(kids[i]))
+	case "GetChildren":
+		refs := make([]interface{}, len(kids))
+		for i, k := range kids {
+			refs[i] = b.ref(k)
+		}
+		c.Reply(m, "a(so)", refs)
+	case "GetIndexInParent":
+		c.Reply(m, "i", int32(idx))
+	case "GetRelationSet":
+		c.Reply(m, "a(ua(so))", []interface{}{})
+	case "GetRole":
+		c.Reply(m, "u", role)
+	case "GetRoleName", "GetLocalizedRoleName":
+		c.Reply(m, "s", roleName)
+	case "GetState":
+		c.Reply(m, "au", states)
+	case "GetAttributes":
+		c.Reply(m, "a{ss}", []dbus.DictEntry{{Key: "toolkit", Value: "GoGi"}})
+	case "GetApplication":
+		c.Reply(m, "(so)", b.ref(atspiRootPath))
+	case "GetInterfaces":
+		c.Reply(m, "as", ifaces)
+	default:
+		c.ReplyError(m, dbus.ErrUnknownMethod, "unknown method: "+m.Member)
+	}
+}
+
+// handleComponent handles the Component methods of an element
+func (b *atspiBridge) handleComponent(c *dbus.Conn, m *dbus.Message, ob *atspiObj) {
+	switch m.Member {
+	case "Contains":
+		pt := image.Pt(int(m.Body[0].(int32)), int(m.Body[1].(int32)))
+		c.Reply(m, "b", pt.In(b.extents(ob, m.Body[2].(uint32))))
+	case "GetAccessibleAtPoint":
+		ctype := m.Body[2].(uint32)
+		pt := image.Pt(int(m.Body[0].(int32)), int(m.Body[1].(int32)))
+		if ctype == 0 && ob.win.w != nil {
+			pt = pt.Sub(ob.win.w.Pos)
+			ctype = 1
+		}
+		if ctype == 2 {
+			pt = pt.Add(b.extents(ob, 1).Min).Sub(b.extents(ob, 2).Min)
+		}
+		hit := atspiNullPath
+		an := ob.node
+		for an != nil && pt.In(an.Bounds) {
+			hit = ob.win.paths[an.ID]
+			var next *oswin.AccessNode
+			for i := len(an.Kids) - 1; i >= 0; i-- { // later children are on top
+				if pt.In(an.Kids[i].Bounds) && !an.Kids[i].HasState(oswin.StateInvisible) {
+					next = an.Kids[i]
+					break
+				}
+			}
+			an = next
+		}
+		if hit == ob.win.paths[ob.node.ID] {
+			hit = atspiNullPath // only descendants
+		}
+		c.Reply(m, "(so)", b.ref(hit))
+	case "GetExtents":
+		c.Reply(m, "(iiii)", rectStruct(b.extents(ob, m.Body[0].(uint32))))
+	case "GetPosition":
+		r := b.extents(ob, m.Body[0].(uint32))
+		c.Reply(m, "ii", int32(r.Min.X), int32(r.Min.Y))
+	case "GetSize":
+		r := ob.node.Bounds
+		c.Reply(m, "ii", int32(r.Dx()), int32(r.Dy()))
+	case "GetLayer":
+		layer := uint32(3) // widget
+		if ob.parent == atspiRootPath {
+			layer = 7 // window
+		}
+		c.Reply(m, "u", layer)
+	case "GetMDIZOrder":
+		c.Reply(m, "n", int16(0))
+	case "GetAlpha":
+		c.Reply(m, "d", 1.0)
+	case "GrabFocus", "ScrollTo", "ScrollToPoint", "SetExtents", "SetPosition", "SetSize":
+		c.Reply(m, "b", false) // the GUI does not support these
+	default:
+		c.ReplyError(m, dbus.ErrUnknownMethod, "unknown method: "+m.Member)
+	}
+}
+
+// handleText handles the Text methods of an element with given source of
+// its text, which only gets the requested ranges of text
+func (b *atspiBridge) handleText(c *dbus.Conn, m *dbus.Message, txt oswin.AccessText) {
+	switch m.Member {
+	case "GetText":
+		st, ed := int(m.Body[0].(int32)), int(m.Body[1].(int32))
+		if n := txt.AccessTextLen(); ed < 0 || ed > n {
+			ed = n
+		}
+		if st < 0 {
+			st = 0
+		}
+		if st > ed {
+			st = ed
+		}
+		c.Reply(m, "s", txt.AccessTextRange(st, ed))
+	case "GetCharacterAtOffset":
+		off := int(m.Body[0].(int32))
+		ch := int32(0)
+		if off >= 0 {
+			for _, r := range txt.AccessTextRange(off, off+1) {
+				ch = int32(r)
+			}
+		}
+		c.Reply(m, "i", ch)
+	case "GetStringAtOffset", "GetTextAtOffset":
+		gran := m.Body[1].(uint32)
+		if m.Member == "GetTextAtOffset" {
+			gran = atspiTextBoundary(gran)
+		}
+		st, ed := atspiTextRange(txt, int(m.Body[0].(int32)), gran)
+		c.Reply(m, "sii", txt.AccessTextRange(st, ed), int32(st), int32(ed))
+	case "GetNSelections":
+		c.Reply(m, "i", int32(0))
+	case "GetAttributes", "GetAttributeRun":
+		c.Reply(m, "a{ss}ii", []dbus.DictEntry{}, int32(0), int32(txt.AccessTextLen()))
+	case "GetDefaultAttributes", "GetDefaultAttributeSet":
+		c.Reply(m, "a{ss}", []dbus.DictEntry{})
+	default:
+		c.ReplyError(m, dbus.ErrUnknownMethod, "unknown method: "+m.Member)
+	}
+}
+
+func (w *windowImpl) AccessActive() bool {
+	atspiOnce.Do(func() {
+		go atspi.start()
+	})
+	return atspi.active()
+}
+
+func (w *windowImpl) SetAccessTree(root *oswin.AccessNode) {
+	atspi.emit(atspi.setTree(w, root))
+}
+
+func (w *windowImpl) AccessFocus(id string) {
+	atspi.emit(atspi.setFocus(w, id))
+}
+
+func (w *windowImpl) AccessAnnounce(msg string, assertive bool) {
+	atspi.emit(atspi.announce(w, msg, assertive))
+}
+
+// closeAccess removes the accessibility tree of the window when it is closed
+func (w *windowImpl) closeAccess() {
+	atspi.emit(atspi.removeWin(w))
+}
@@ -0,0 +1,14 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build darwin windows
+
+package glos
+
+// the windows do not implement oswin.AccessWindow on macOS and Windows:
+// bridging to NSAccessibility and UIA is out of scope for now, and left to a
+// separate follow-up (see oswin.AccessWindow) -- see access_atspi.go for the
+// AT-SPI bridge on Linux
+func (w *windowImpl) closeAccess() {
+}
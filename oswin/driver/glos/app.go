@@ -57,7 +57,8 @@ type appImpl struct {
 	mainDone      chan struct{}
 	shareWin      *glfw.Window // a non-visible, always-present window that all windows share gl context with
 	windows       map[*glfw.Window]*windowImpl
-	oswindows     map[uintptr]*windowImpl
+	oswindows     map[uintptr]*windowImpl // by osKey of the windows
+	lastOSKey     uintptr                 // last osKey assigned to a window without an OSHandle
 	winlist       []*windowImpl
 	screens       []*oswin.Screen
 	noScreens     bool        // if all screens have been disconnected, don't do anything..
@@ -68,6 +69,7 @@ type appImpl struct {
 	quitCloseCnt  chan struct{} // counts windows to make sure all are closed before done
 	quitReqFunc   func()
 	quitCleanFunc func()
+	dark          bool // OS appearance is dark
 
 	// gl drawing programs
 	progInit bool
@@ -82,7 +84,9 @@ var mainCallback func(oswin.App)
 func Main(f func(oswin.App)) {
 	mainCallback = f
 	theApp.initGl()
+	theApp.dark = osIsDark()
 	oswin.TheApp = theApp
+	go theApp.watchTheme()
 	go func() {
 		mainCallback(theApp)
 		theApp.stopMain()
@@ -265,7 +269,12 @@ func (app *appImpl) NewWindow(opts *oswin.NewWindowOptions) (oswin.Window, error
 
 	app.mu.Lock()
 	app.windows[glw] = w
-	app.oswindows[w.OSHandle()] = w
+	w.osKey = w.OSHandle()
+	if w.osKey == 0 { // Wayland windows have no handle, and need unique keys
+		app.lastOSKey++
+		w.osKey = app.lastOSKey
+	}
+	app.oswindows[w.osKey] = w
 	app.winlist = append(app.winlist, w)
 	app.mu.Unlock()
 
@@ -279,10 +288,13 @@ func (app *appImpl) NewWindow(opts *oswin.NewWindowOptions) (oswin.Window, error
 	glw.SetPosCallback(w.moved)
 	glw.SetSizeCallback(w.winResized)
 	glw.SetFramebufferSizeCallback(w.fbResized)
+	glw.SetContentScaleCallback(w.contentScaled)
 	glw.SetCloseCallback(w.closeReq)
 	// glw.SetRefreshCallback(w.refresh)
 	glw.SetFocusCallback(w.focus)
 	glw.SetIconifyCallback(w.iconify)
+	w.initGestures()
+	w.initStylus()
 
 	glw.SetKeyCallback(w.keyEvent)
 	glw.SetCharModsCallback(w.charEvent)
@@ -318,7 +330,7 @@ func (app *appImpl) DeleteWin(w *windowImpl) {
 			break
 		}
 	}
-	delete(app.oswindows, w.OSHandle())
+	delete(app.oswindows, w.osKey)
 	delete(app.windows, w.glw)
 }
 
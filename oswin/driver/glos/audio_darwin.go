@@ -0,0 +1,157 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package glos
+
+/*
+#cgo LDFLAGS: -framework AudioToolbox -framework CoreFoundation
+#include <stdlib.h>
+#include <string.h>
+#include <AudioToolbox/AudioToolbox.h>
+
+#define GO_AUDIO_NBUFS 4
+
+// goAudio is an AudioQueue with a ring of buffers, which are marked as
+// busy while queued, and free again by the callback when played
+typedef struct {
+	AudioQueueRef       queue;
+	AudioQueueBufferRef bufs[GO_AUDIO_NBUFS];
+	volatile int        busy[GO_AUDIO_NBUFS];
+	int                 started;
+} goAudio;
+
+static void goAudioCallback(void* user, AudioQueueRef queue, AudioQueueBufferRef buf) {
+	goAudio* ga = (goAudio*)user;
+	for (int i = 0; i < GO_AUDIO_NBUFS; i++) {
+		if (ga->bufs[i] == buf) {
+			__sync_lock_release(&ga->busy[i]);
+		}
+	}
+}
+
+static goAudio* goAudioOpen(int rate, int chans, int bufSize) {
+	AudioStreamBasicDescription desc;
+	memset(&desc, 0, sizeof(desc));
+	desc.mSampleRate = rate;
+	desc.mFormatID = kAudioFormatLinearPCM;
+	desc.mFormatFlags = kLinearPCMFormatFlagIsSignedInteger | kLinearPCMFormatFlagIsPacked;
+	desc.mBytesPerPacket = 2 * chans;
+	desc.mFramesPerPacket = 1;
+	desc.mBytesPerFrame = 2 * chans;
+	desc.mChannelsPerFrame = chans;
+	desc.mBitsPerChannel = 16;
+	goAudio* ga = (goAudio*)calloc(1, sizeof(goAudio));
+	// a NULL run loop runs the callback on an internal thread of the queue
+	if (AudioQueueNewOutput(&desc, goAudioCallback, ga, NULL, kCFRunLoopCommonModes, 0, &ga->queue) != noErr) {
+		free(ga);
+		return NULL;
+	}
+	for (int i = 0; i < GO_AUDIO_NBUFS; i++) {
+		if (AudioQueueAllocateBuffer(ga->queue, bufSize, &ga->bufs[i]) != noErr) {
+			AudioQueueDispose(ga->queue, true);
+			free(ga);
+			return NULL;
+		}
+	}
+	return ga;
+}
+
+static int goAudioBusy(goAudio* ga, int i) {
+	return __sync_fetch_and_add(&ga->busy[i], 0);
+}
+
+static int goAudioEnqueue(goAudio* ga, int i, void* data, int size) {
+	AudioQueueBufferRef buf = ga->bufs[i];
+	if (size > (int)buf->mAudioDataBytesCapacity) {
+		size = buf->mAudioDataBytesCapacity;
+	}
+	memcpy(buf->mAudioData, data, size);
+	buf->mAudioDataByteSize = size;
+	__sync_lock_test_and_set(&ga->busy[i], 1);
+	OSStatus st = AudioQueueEnqueueBuffer(ga->queue, buf, 0, NULL);
+	if (st != noErr) {
+		__sync_lock_release(&ga->busy[i]);
+		return (int)st;
+	}
+	if (!ga->started) {
+		ga->started = 1;
+		return (int)AudioQueueStart(ga->queue, NULL);
+	}
+	return 0;
+}
+
+static void goAudioClose(goAudio* ga) {
+	AudioQueueDispose(ga->queue, true);
+	free(ga);
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unsafe"
+
+	"github.com/goki/gi/oswin/audio"
+)
+
+// audio output uses an AudioQueue, with a ring of buffers that are polled
+// for being done
+
+const (
+	audioNBufs       = C.GO_AUDIO_NBUFS
+	audioBufDuration = 50 * time.Millisecond
+)
+
+func (app *appImpl) OpenAudio(format audio.Format) (audio.Stream, error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	bufSize := 2 * format.NSamples(audioBufDuration)
+	ga := C.goAudioOpen(C.int(format.SampleRate), C.int(format.Channels), C.int(bufSize))
+	if ga == nil {
+		return nil, errors.New("glos: could not open AudioQueue for audio output")
+	}
+	return audio.NewStream(format, &queueSink{ga: ga, bufSize: bufSize}), nil
+}
+
+// queueSink is an audio.Sink that writes to an AudioQueue
+type queueSink struct {
+	ga      *C.goAudio
+	cur     int
+	bufSize int
+}
+
+// waitBuf waits for buffer of given index to be done playing
+func (qs *queueSink) waitBuf(i int) {
+	for C.goAudioBusy(qs.ga, C.int(i)) != 0 {
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func (qs *queueSink) Write(b []byte) error {
+	for len(b) > 0 {
+		i := qs.cur
+		qs.waitBuf(i)
+		n := len(b)
+		if n > qs.bufSize {
+			n = qs.bufSize
+		}
+		if st := C.goAudioEnqueue(qs.ga, C.int(i), unsafe.Pointer(&b[0]), C.int(n)); st != 0 {
+			return fmt.Errorf("glos: AudioQueue error: %d", int(st))
+		}
+		b = b[n:]
+		qs.cur = (i + 1) % audioNBufs
+	}
+	return nil
+}
+
+func (qs *queueSink) Close() error {
+	for i := 0; i < audioNBufs; i++ {
+		qs.waitBuf(i)
+	}
+	C.goAudioClose(qs.ga)
+	return nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package glos
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/goki/gi/oswin/audio"
+)
+
+// audio output uses the waveOut API of winmm, called directly, with a ring
+// of buffers that are polled for being done
+
+var (
+	winmm = syscall.NewLazyDLL("winmm.dll")
+
+	procWaveOutOpen            = winmm.NewProc("waveOutOpen")
+	procWaveOutClose           = winmm.NewProc("waveOutClose")
+	procWaveOutPrepareHeader   = winmm.NewProc("waveOutPrepareHeader")
+	procWaveOutUnprepareHeader = winmm.NewProc("waveOutUnprepareHeader")
+	procWaveOutWrite           = winmm.NewProc("waveOutWrite")
+	procWaveOutReset           = winmm.NewProc("waveOutReset")
+)
+
+const (
+	waveMapper      = 0xFFFFFFFF
+	waveFormatPCM   = 1
+	callbackNull    = 0
+	whdrDone        = 0x1
+	waveNBufs       = 4
+	waveBufDuration = 50 * time.Millisecond
+)
+
+type waveFormatEx struct {
+	FormatTag      uint16
+	Channels       uint16
+	SamplesPerSec  uint32
+	AvgBytesPerSec uint32
+	BlockAlign     uint16
+	BitsPerSample  uint16
+	Size           uint16
+}
+
+type waveHdr struct {
+	Data          uintptr
+	BufferLength  uint32
+	BytesRecorded uint32
+	User          uintptr
+	Flags         uint32
+	Loops         uint32
+	Next          uintptr
+	Reserved      uintptr
+}
+
+func (app *appImpl) OpenAudio(format audio.Format) (audio.Stream, error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	wf := waveFormatEx{
+		FormatTag:     waveFormatPCM,
+		Channels:      uint16(format.Channels),
+		SamplesPerSec: uint32(format.SampleRate),
+		BlockAlign:    uint16(2 * format.Channels),
+		BitsPerSample: 16,
+	}
+	wf.AvgBytesPerSec = wf.SamplesPerSec * uint32(wf.BlockAlign)
+	ws := &waveSink{bufSize: 2 * format.NSamples(waveBufDuration)}
+	r, _, _ := procWaveOutOpen.Call(uintptr(unsafe.Pointer(&ws.handle)), waveMapper, uintptr(unsafe.Pointer(&wf)), 0, 0, callbackNull)
+	if r != 0 {
+		return nil, fmt.Errorf("glos: waveOutOpen error: %d", r)
+	}
+	for i := range ws.bufs {
+		ws.bufs[i] = make([]byte, ws.bufSize)
+		ws.hdrs[i].Flags = whdrDone // free
+	}
+	return audio.NewStream(format, ws), nil
+}
+
+// waveSink is an audio.Sink that writes to a waveOut device -- the buffers
+// and headers are retained here while the device uses them
+type waveSink struct {
+	handle   uintptr
+	bufs     [waveNBufs][]byte
+	hdrs     [waveNBufs]waveHdr
+	prepared [waveNBufs]bool
+	cur      int
+	bufSize  int
+}
+
+// waitBuf waits for buffer of given index to be done playing, and
+// unprepares it
+func (ws *waveSink) waitBuf(i int) {
+	for ws.hdrs[i].Flags&whdrDone == 0 {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if ws.prepared[i] {
+		procWaveOutUnprepareHeader.Call(ws.handle, uintptr(unsafe.Pointer(&ws.hdrs[i])), unsafe.Sizeof(ws.hdrs[i]))
+		ws.prepared[i] = false
+	}
+}
+
+func (ws *waveSink) Write(b []byte) error {
+	for len(b) > 0 {
+		i := ws.cur
+		ws.waitBuf(i)
+		n := copy(ws.bufs[i], b)
+		b = b[n:]
+		ws.hdrs[i] = waveHdr{Data: uintptr(unsafe.Pointer(&ws.bufs[i][0])), BufferLength: uint32(n)}
+		hp := uintptr(unsafe.Pointer(&ws.hdrs[i]))
+		sz := unsafe.Sizeof(ws.hdrs[i])
+		if r, _, _ := procWaveOutPrepareHeader.Call(ws.handle, hp, sz); r != 0 {
+			return fmt.Errorf("glos: waveOutPrepareHeader error: %d", r)
+		}
+		ws.prepared[i] = true
+		if r, _, _ := procWaveOutWrite.Call(ws.handle, hp, sz); r != 0 {
+			return fmt.Errorf("glos: waveOutWrite error: %d", r)
+		}
+		ws.cur = (i + 1) % waveNBufs
+	}
+	return nil
+}
+
+func (ws *waveSink) Close() error {
+	for i := range ws.hdrs {
+		ws.waitBuf(i)
+	}
+	procWaveOutReset.Call(ws.handle)
+	if r, _, _ := procWaveOutClose.Call(ws.handle); r != 0 {
+		return fmt.Errorf("glos: waveOutClose error: %d", r)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux,!android dragonfly openbsd
+
+package glos
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+
+	"github.com/goki/gi/oswin/audio"
+)
+
+// audio samples are piped to the pacat (PulseAudio, and PipeWire with its
+// PulseAudio server), pw-cat (PipeWire) or aplay (ALSA) command, whichever
+// is installed, so there is no dependency on any of the sound libraries
+
+// audioCmdArgs returns the path and arguments of the command used to play
+// samples of given format from stdin, or "" if none is installed
+func audioCmdArgs(app *appImpl, format audio.Format) (string, []string) {
+	rate := strconv.Itoa(format.SampleRate)
+	chans := strconv.Itoa(format.Channels)
+	cmds := [][]string{
+		{"pacat", "--playback", "--raw", "--format=s16le", "--rate=" + rate, "--channels=" + chans, "--client-name=" + app.name},
+		{"pw-cat", "--playback", "--format=s16", "--rate=" + rate, "--channels=" + chans, "-"},
+		{"aplay", "-q", "-t", "raw", "-f", "S16_LE", "-r", rate, "-c", chans, "-"},
+	}
+	for _, args := range cmds {
+		if p, err := exec.LookPath(args[0]); err == nil {
+			return p, args[1:]
+		}
+	}
+	return "", nil
+}
+
+func (app *appImpl) OpenAudio(format audio.Format) (audio.Stream, error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	path, args := audioCmdArgs(app, format)
+	if path == "" {
+		return nil, errors.New("glos: the pacat, pw-cat or aplay command is needed for audio output")
+	}
+	cmd := exec.Command(path, args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("glos: starting audio output: %v", err)
+	}
+	return audio.NewStream(format, &cmdSink{cmd: cmd, stdin: stdin}), nil
+}
+
+// cmdSink is an audio.Sink that writes to the stdin of a command
+type cmdSink struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+}
+
+func (cs *cmdSink) Write(b []byte) error {
+	_, err := cs.stdin.Write(b)
+	return err
+}
+
+func (cs *cmdSink) Close() error {
+	cs.stdin.Close()
+	return cs.cmd.Wait() // exits after playing the rest
+}
@@ -0,0 +1,122 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux,!android,wayland
+
+package glos
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os/exec"
+	"strings"
+
+	"github.com/goki/gi/oswin/mimedata"
+)
+
+// glfw only supports text on the Wayland clipboard, so the rich types (HTML,
+// RTF, images) are read and written using the wl-paste and wl-copy commands
+// of wl-clipboard, if installed.  As for xclip on X11, only a single type
+// is offered when writing: images take precedence, then HTML, then RTF.
+
+// wlclipPath returns the path to given wl-clipboard command, or "" if not
+// installed
+func wlclipPath(cmd string) string {
+	path, err := exec.LookPath(cmd)
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// wlclipTypes returns the mime types available on the clipboard
+func wlclipTypes() []string {
+	wp := wlclipPath("wl-paste")
+	if wp == "" {
+		return nil
+	}
+	out, err := exec.Command(wp, "--no-newline", "--list-types").Output()
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(out))
+}
+
+// wlclipHasRich returns true if the clipboard has any rich data
+func wlclipHasRich() bool {
+	for _, t := range wlclipTypes() {
+		if mimedata.IsRich(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadRich reads given rich mime type from the clipboard -- images are
+// returned as PNG, converted from any other image type that is available
+func (ci *clipImpl) ReadRich(typ string) mimedata.Mimes {
+	wp := wlclipPath("wl-paste")
+	if wp == "" {
+		return nil
+	}
+	tgt := ""
+	for _, t := range wlclipTypes() {
+		if t == typ || (mimedata.IsImage(typ) && mimedata.IsImage(t)) {
+			tgt = t
+			if t == typ {
+				break
+			}
+		}
+	}
+	if tgt == "" {
+		return nil
+	}
+	out, err := exec.Command(wp, "--no-newline", "--type", tgt).Output()
+	if err != nil || len(out) == 0 {
+		return nil
+	}
+	if !mimedata.IsImage(tgt) {
+		return mimedata.NewMime(tgt, out)
+	}
+	b, err := mimedata.ToPNG(&mimedata.Data{Type: tgt, Data: out})
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+	return mimedata.NewMime(mimedata.ImagePNG, b)
+}
+
+// WriteRich writes the richest element of data to the clipboard -- see
+// notes above on the use of wl-copy
+func (ci *clipImpl) WriteRich(data mimedata.Mimes) error {
+	wc := wlclipPath("wl-copy")
+	if wc == "" {
+		return errors.New("glos: the wl-copy command (wl-clipboard) is needed to copy HTML, RTF or images to the clipboard")
+	}
+	var d *mimedata.Data
+	for _, typ := range []string{"image/", mimedata.TextHTML, mimedata.TextRTF} {
+		for _, md := range data {
+			if strings.HasPrefix(md.Type, typ) {
+				d = md
+				break
+			}
+		}
+		if d != nil {
+			break
+		}
+	}
+	typ := d.Type
+	b := d.Data
+	if mimedata.IsImage(typ) {
+		var err error
+		if b, err = mimedata.ToPNG(d); err != nil {
+			return err
+		}
+		typ = mimedata.ImagePNG
+	}
+	cmd := exec.Command(wc, "--type", typ)
+	cmd.Stdin = bytes.NewReader(b)
+	return cmd.Run() // wl-copy forks to serve the clipboard, so this returns
+}
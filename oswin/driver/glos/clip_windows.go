@@ -0,0 +1,301 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package glos
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"log"
+	"strconv"
+	"syscall"
+	"unsafe"
+
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/pi/filecat"
+	"golang.org/x/image/bmp"
+)
+
+// native win32 clipboard access for the rich types (HTML, RTF, images),
+// which glfw does not support -- plain text alone still goes through glfw
+
+var (
+	user32   = syscall.NewLazyDLL("user32.dll")
+	kernel32 = syscall.NewLazyDLL("kernel32.dll")
+
+	procOpenClipboard              = user32.NewProc("OpenClipboard")
+	procCloseClipboard             = user32.NewProc("CloseClipboard")
+	procEmptyClipboard             = user32.NewProc("EmptyClipboard")
+	procGetClipboardData           = user32.NewProc("GetClipboardData")
+	procSetClipboardData           = user32.NewProc("SetClipboardData")
+	procIsClipboardFormatAvailable = user32.NewProc("IsClipboardFormatAvailable")
+	procRegisterClipboardFormatW   = user32.NewProc("RegisterClipboardFormatW")
+	procGlobalAlloc                = kernel32.NewProc("GlobalAlloc")
+	procGlobalFree                 = kernel32.NewProc("GlobalFree")
+	procGlobalLock                 = kernel32.NewProc("GlobalLock")
+	procGlobalUnlock               = kernel32.NewProc("GlobalUnlock")
+	procGlobalSize                 = kernel32.NewProc("GlobalSize")
+)
+
+const (
+	cfDIB         = 8
+	cfUnicodeText = 13
+	gmemMoveable  = 0x0002
+)
+
+// winClipFormats are the registered clipboard format names for the rich
+// mime types
+var winClipFormats = map[string]string{
+	mimedata.TextHTML: "HTML Format",
+	mimedata.TextRTF:  "Rich Text Format",
+	mimedata.ImagePNG: "PNG",
+}
+
+// winClipFormat returns the registered clipboard format for given mime type
+func winClipFormat(typ string) uintptr {
+	nm, ok := winClipFormats[typ]
+	if !ok {
+		return 0
+	}
+	nmp, _ := syscall.UTF16PtrFromString(nm)
+	cf, _, _ := procRegisterClipboardFormatW.Call(uintptr(unsafe.Pointer(nmp)))
+	return cf
+}
+
+// winClipHasRich returns true if the clipboard has any rich data
+func winClipHasRich() bool {
+	for _, cf := range []uintptr{winClipFormat(mimedata.TextHTML), winClipFormat(mimedata.TextRTF), winClipFormat(mimedata.ImagePNG), cfDIB} {
+		if r, _, _ := procIsClipboardFormatAvailable.Call(cf); r != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// winClipOpen opens the clipboard for the context window
+func winClipOpen() error {
+	var hwnd uintptr
+	if theApp.ctxtwin != nil {
+		hwnd = theApp.ctxtwin.OSHandle()
+	}
+	if r, _, err := procOpenClipboard.Call(hwnd); r == 0 {
+		return err
+	}
+	return nil
+}
+
+// winClipGet returns the clipboard data for given format, which must be
+// called with the clipboard open -- nil if not available
+func winClipGet(cf uintptr) []byte {
+	if cf == 0 {
+		return nil
+	}
+	if r, _, _ := procIsClipboardFormatAvailable.Call(cf); r == 0 {
+		return nil
+	}
+	h, _, _ := procGetClipboardData.Call(cf)
+	if h == 0 {
+		return nil
+	}
+	sz, _, _ := procGlobalSize.Call(h)
+	p, _, _ := procGlobalLock.Call(h)
+	if p == 0 {
+		return nil
+	}
+	b := make([]byte, sz)
+	copy(b, (*[1 << 30]byte)(unsafe.Pointer(p))[:sz:sz])
+	procGlobalUnlock.Call(h)
+	return b
+}
+
+// winClipSet sets the clipboard data for given format, which must be
+// called with the clipboard open and emptied
+func winClipSet(cf uintptr, b []byte) error {
+	if cf == 0 || len(b) == 0 {
+		return nil
+	}
+	h, _, err := procGlobalAlloc.Call(gmemMoveable, uintptr(len(b)))
+	if h == 0 {
+		return err
+	}
+	p, _, err := procGlobalLock.Call(h)
+	if p == 0 {
+		procGlobalFree.Call(h)
+		return err
+	}
+	copy((*[1 << 30]byte)(unsafe.Pointer(p))[:len(b):len(b)], b)
+	procGlobalUnlock.Call(h)
+	if r, _, err := procSetClipboardData.Call(cf, h); r == 0 {
+		procGlobalFree.Call(h) // only owned by the system if set
+		return err
+	}
+	return nil
+}
+
+// ReadRich reads the native format for given rich mime type -- images are
+// returned as PNG, converted from a DIB if that is all there is
+func (ci *clipImpl) ReadRich(typ string) mimedata.Mimes {
+	if err := winClipOpen(); err != nil {
+		log.Println(err)
+		return nil
+	}
+	defer procCloseClipboard.Call()
+	if !mimedata.IsImage(typ) {
+		b := winClipGet(winClipFormat(typ))
+		if b == nil {
+			return nil
+		}
+		b = bytes.TrimRight(b, "\x00")
+		if typ == mimedata.TextHTML {
+			b = winHTMLDecode(b)
+		}
+		return mimedata.NewMime(typ, b)
+	}
+	if b := winClipGet(winClipFormat(mimedata.ImagePNG)); b != nil {
+		return mimedata.NewMime(mimedata.ImagePNG, b)
+	}
+	dib := winClipGet(cfDIB)
+	if dib == nil {
+		return nil
+	}
+	b, err := mimedata.ToPNG(&mimedata.Data{Type: "image/bmp", Data: winDIBToBMP(dib)})
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+	return mimedata.NewMime(mimedata.ImagePNG, b)
+}
+
+// WriteRich writes each element of data as its native format: text as
+// unicode text, HTML with the required header, and images as both PNG and
+// DIB -- elements of other types are ignored
+func (ci *clipImpl) WriteRich(data mimedata.Mimes) error {
+	if err := winClipOpen(); err != nil {
+		return err
+	}
+	defer procCloseClipboard.Call()
+	procEmptyClipboard.Call()
+	var errs []error
+	for _, d := range data {
+		var err error
+		switch {
+		case d.Type == filecat.TextPlain:
+			u, _ := syscall.UTF16FromString(string(d.Data))
+			b := make([]byte, 2*len(u))
+			for i, c := range u {
+				binary.LittleEndian.PutUint16(b[2*i:], c)
+			}
+			err = winClipSet(cfUnicodeText, b)
+		case d.Type == mimedata.TextHTML:
+			err = winClipSet(winClipFormat(d.Type), winHTMLEncode(d.Data))
+		case d.Type == mimedata.TextRTF:
+			err = winClipSet(winClipFormat(d.Type), d.Data)
+		case mimedata.IsImage(d.Type):
+			var b []byte
+			if b, err = mimedata.ToPNG(d); err != nil {
+				break
+			}
+			if err = winClipSet(winClipFormat(mimedata.ImagePNG), b); err != nil {
+				break
+			}
+			var dib []byte
+			if dib, err = winPNGToDIB(b); err == nil {
+				err = winClipSet(cfDIB, dib)
+			}
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// winHTMLEncode adds the header required for the HTML clipboard format,
+// with the offsets of the HTML and the fragment in it
+func winHTMLEncode(frag []byte) []byte {
+	const hdr = "Version:0.9\r\nStartHTML:%010d\r\nEndHTML:%010d\r\nStartFragment:%010d\r\nEndFragment:%010d\r\n"
+	const pre = "<html><body>\r\n<!--StartFragment-->"
+	const post = "<!--EndFragment-->\r\n</body></html>"
+	hlen := len(fmt.Sprintf(hdr, 0, 0, 0, 0))
+	sf := hlen + len(pre)
+	ef := sf + len(frag)
+	var b bytes.Buffer
+	fmt.Fprintf(&b, hdr, hlen, ef+len(post), sf, ef)
+	b.WriteString(pre)
+	b.Write(frag)
+	b.WriteString(post)
+	return b.Bytes()
+}
+
+// winHTMLDecode returns the fragment from data in the HTML clipboard format,
+// or the data as is if the header cannot be parsed
+func winHTMLDecode(b []byte) []byte {
+	off := func(key string) int {
+		i := bytes.Index(b, []byte(key+":"))
+		if i < 0 {
+			return -1
+		}
+		v := b[i+len(key)+1:]
+		if e := bytes.IndexAny(v, "\r\n"); e >= 0 {
+			v = v[:e]
+		}
+		n, err := strconv.Atoi(string(bytes.TrimSpace(v)))
+		if err != nil {
+			return -1
+		}
+		return n
+	}
+	st, ed := off("StartFragment"), off("EndFragment")
+	if st < 0 || ed < st || ed > len(b) {
+		return b
+	}
+	return b[st:ed]
+}
+
+// winDIBToBMP adds a BMP file header to a DIB (device-independent bitmap)
+func winDIBToBMP(dib []byte) []byte {
+	if len(dib) < 40 {
+		return dib
+	}
+	hsz := binary.LittleEndian.Uint32(dib[0:])
+	bpp := binary.LittleEndian.Uint16(dib[14:])
+	ncol := binary.LittleEndian.Uint32(dib[32:])
+	comp := binary.LittleEndian.Uint32(dib[16:])
+	if ncol == 0 && bpp <= 8 {
+		ncol = 1 << bpp
+	}
+	if comp == 3 && hsz == 40 { // BI_BITFIELDS masks follow the header
+		hsz += 12
+	}
+	fh := make([]byte, 14, 14+len(dib))
+	fh[0], fh[1] = 'B', 'M'
+	binary.LittleEndian.PutUint32(fh[2:], uint32(14+len(dib)))
+	binary.LittleEndian.PutUint32(fh[10:], 14+hsz+4*ncol)
+	return append(fh, dib...)
+}
+
+// winPNGToDIB converts PNG data to a DIB (device-independent bitmap), which
+// is the BMP encoding without its file header
+func winPNGToDIB(b []byte) ([]byte, error) {
+	img, _, err := image.Decode(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	var bb bytes.Buffer
+	if err := bmp.Encode(&bb, img); err != nil {
+		return nil, err
+	}
+	if bb.Len() < 14 {
+		return nil, errors.New("glos: bad bmp encoding")
+	}
+	return bb.Bytes()[14:], nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux,!android,!wayland dragonfly openbsd
+
+package glos
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os/exec"
+	"strings"
+
+	"github.com/goki/gi/oswin/mimedata"
+)
+
+// glfw only supports text on the X11 clipboard, so the rich types (HTML,
+// RTF, images) are read and written using the xclip command, if installed.
+// xclip can only offer a single type, so when writing, images take
+// precedence, then HTML, then RTF, and any plain text is not offered.
+
+// xclipPath returns the path to the xclip command, or "" if not installed
+func xclipPath() string {
+	path, err := exec.LookPath("xclip")
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// xclipTargets returns the types (targets) available on the clipboard
+func xclipTargets() []string {
+	xc := xclipPath()
+	if xc == "" {
+		return nil
+	}
+	out, err := exec.Command(xc, "-selection", "clipboard", "-t", "TARGETS", "-o").Output()
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(out))
+}
+
+// xclipHasRich returns true if the clipboard has any rich data
+func xclipHasRich() bool {
+	for _, t := range xclipTargets() {
+		if mimedata.IsRich(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadRich reads given rich mime type from the clipboard -- images are
+// returned as PNG, converted from any other image type that is available
+func (ci *clipImpl) ReadRich(typ string) mimedata.Mimes {
+	xc := xclipPath()
+	if xc == "" {
+		return nil
+	}
+	tgt := ""
+	for _, t := range xclipTargets() {
+		if t == typ || (mimedata.IsImage(typ) && mimedata.IsImage(t)) {
+			tgt = t
+			if t == typ {
+				break
+			}
+		}
+	}
+	if tgt == "" {
+		return nil
+	}
+	out, err := exec.Command(xc, "-selection", "clipboard", "-t", tgt, "-o").Output()
+	if err != nil || len(out) == 0 {
+		return nil
+	}
+	if !mimedata.IsImage(tgt) {
+		return mimedata.NewMime(tgt, out)
+	}
+	b, err := mimedata.ToPNG(&mimedata.Data{Type: tgt, Data: out})
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+	return mimedata.NewMime(mimedata.ImagePNG, b)
+}
+
+// WriteRich writes the richest element of data to the clipboard -- see
+// notes above on the use of xclip
+func (ci *clipImpl) WriteRich(data mimedata.Mimes) error {
+	xc := xclipPath()
+	if xc == "" {
+		return errors.New("glos: the xclip command is needed to copy HTML, RTF or images to the clipboard")
+	}
+	var d *mimedata.Data
+	for _, typ := range []string{"image/", mimedata.TextHTML, mimedata.TextRTF} {
+		for _, md := range data {
+			if strings.HasPrefix(md.Type, typ) {
+				d = md
+				break
+			}
+		}
+		if d != nil {
+			break
+		}
+	}
+	typ := d.Type
+	b := d.Data
+	if mimedata.IsImage(typ) {
+		var err error
+		if b, err = mimedata.ToPNG(d); err != nil {
+			return err
+		}
+		typ = mimedata.ImagePNG
+	}
+	cmd := exec.Command(xc, "-selection", "clipboard", "-t", typ, "-i")
+	cmd.Stdin = bytes.NewReader(b)
+	return cmd.Run() // xclip forks to serve the selection, so this returns
+}
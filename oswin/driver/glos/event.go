@@ -266,6 +266,7 @@ func (w *windowImpl) dropEvent(gw *glfw.Window, names []string) {
 		Where:     where,
 		Modifiers: lastMods,
 		Data:      md,
+		Files:     names,
 	}
 	event.DefaultMod()
 	event.Init()
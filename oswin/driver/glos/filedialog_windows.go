@@ -0,0 +1,198 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package glos
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/goki/gi/oswin"
+)
+
+// native file dialogs use the COM IFileOpenDialog / IFileSaveDialog
+// interfaces, called directly through their vtables
+
+var (
+	ole32   = syscall.NewLazyDLL("ole32.dll")
+	shell32 = syscall.NewLazyDLL("shell32.dll")
+
+	procCoInitializeEx              = ole32.NewProc("CoInitializeEx")
+	procCoCreateInstance            = ole32.NewProc("CoCreateInstance")
+	procCoTaskMemFree               = ole32.NewProc("CoTaskMemFree")
+	procSHCreateItemFromParsingName = shell32.NewProc("SHCreateItemFromParsingName")
+)
+
+type winGUID struct {
+	Data1 uint32
+	Data2 uint16
+	Data3 uint16
+	Data4 [8]byte
+}
+
+var (
+	clsidFileOpenDialog = winGUID{0xDC1C5A9C, 0xE88A, 0x4DDE, [8]byte{0xA5, 0xA1, 0x60, 0xF8, 0x2A, 0x20, 0xAE, 0xF7}}
+	clsidFileSaveDialog = winGUID{0xC0B4E2F3, 0xBA21, 0x4773, [8]byte{0x8D, 0xBA, 0x33, 0x5E, 0xC9, 0x46, 0xEB, 0x8B}}
+	iidFileOpenDialog   = winGUID{0xD57C7288, 0xD4AD, 0x4768, [8]byte{0xBE, 0x02, 0x9D, 0x96, 0x95, 0x32, 0xD9, 0x60}}
+	iidFileSaveDialog   = winGUID{0x84BCCD23, 0x5FDE, 0x4CDB, [8]byte{0xAE, 0xA4, 0xAF, 0x64, 0xB8, 0x3D, 0x78, 0xAB}}
+	iidShellItem        = winGUID{0x43826D1E, 0xE718, 0x42EE, [8]byte{0xBC, 0x55, 0xA1, 0xE2, 0x61, 0xC3, 0x7B, 0xFE}}
+)
+
+const (
+	coinitApartmentThreaded = 0x2
+	clsctxInprocServer      = 0x1
+	hresultCanceled         = 0x800704C7
+	sigdnFileSysPath        = 0x80058000
+
+	fosOverwritePrompt = 0x2
+	fosPickFolders     = 0x20
+	fosForceFileSystem = 0x40
+	fosPathMustExist   = 0x800
+	fosFileMustExist   = 0x1000
+
+	// IFileDialog vtable indexes
+	fdRelease      = 2
+	fdShow         = 3
+	fdSetFileTypes = 4
+	fdSetOptions   = 9
+	fdGetOptions   = 10
+	fdSetFolder    = 12
+	fdSetFileName  = 15
+	fdSetTitle     = 17
+	fdGetResult    = 20
+
+	// IShellItem vtable indexes
+	siRelease        = 2
+	siGetDisplayName = 5
+)
+
+// winFilterSpec is a COMDLG_FILTERSPEC
+type winFilterSpec struct {
+	Name *uint16
+	Spec *uint16
+}
+
+// comObj is a COM object, which starts with a pointer to its vtable
+type comObj struct {
+	vtbl *[32]uintptr
+}
+
+// comCall calls the method at given vtable index of a COM object, returning
+// the HRESULT
+func comCall(obj *comObj, idx int, args ...uintptr) uintptr {
+	a := make([]uintptr, 5)
+	copy(a, args)
+	r, _, _ := syscall.Syscall6(obj.vtbl[idx], uintptr(len(args)+1), uintptr(unsafe.Pointer(obj)), a[0], a[1], a[2], a[3], a[4])
+	return r
+}
+
+// winUTF16Ptr returns a NUL-terminated UTF-16 copy of s
+func winUTF16Ptr(s string) *uint16 {
+	p, _ := syscall.UTF16PtrFromString(s)
+	return p
+}
+
+func (app *appImpl) HasFileDialog() bool {
+	return true
+}
+
+// FileDialog shows the COM file dialog, which is run on the main thread
+// (where COM is initialized as single-threaded) as modal to given window
+func (app *appImpl) FileDialog(win oswin.Window, opts *oswin.FileDialogOptions) (string, error) {
+	var hwnd uintptr
+	if win != nil {
+		hwnd = win.OSHandle()
+	}
+	var fn string
+	var err error
+	app.RunOnMain(func() {
+		fn, err = winFileDialog(hwnd, opts)
+	})
+	return fn, err
+}
+
+// winFileDialog shows the file dialog, returning "" if canceled
+func winFileDialog(hwnd uintptr, opts *oswin.FileDialogOptions) (string, error) {
+	procCoInitializeEx.Call(0, coinitApartmentThreaded) // ok if already done
+	clsid, iid := &clsidFileOpenDialog, &iidFileOpenDialog
+	if opts.Save {
+		clsid, iid = &clsidFileSaveDialog, &iidFileSaveDialog
+	}
+	var fd *comObj
+	if hr, _, _ := procCoCreateInstance.Call(uintptr(unsafe.Pointer(clsid)), 0, clsctxInprocServer, uintptr(unsafe.Pointer(iid)), uintptr(unsafe.Pointer(&fd))); hr != 0 {
+		return "", fmt.Errorf("glos: could not create file dialog: HRESULT %#x", hr)
+	}
+	defer comCall(fd, fdRelease)
+
+	var fos uint32
+	comCall(fd, fdGetOptions, uintptr(unsafe.Pointer(&fos)))
+	fos |= fosForceFileSystem | fosPathMustExist
+	switch {
+	case opts.Dir:
+		fos |= fosPickFolders
+	case opts.Save:
+		fos |= fosOverwritePrompt
+	default:
+		fos |= fosFileMustExist
+	}
+	comCall(fd, fdSetOptions, uintptr(fos))
+
+	title := winUTF16Ptr(opts.Title)
+	if opts.Title != "" {
+		comCall(fd, fdSetTitle, uintptr(unsafe.Pointer(title)))
+	}
+	var specs []winFilterSpec
+	if len(opts.Exts) > 0 && !opts.Dir {
+		pats := make([]string, len(opts.Exts))
+		for i, ex := range opts.Exts {
+			pats[i] = "*" + ex
+		}
+		pat := strings.Join(pats, ";")
+		specs = []winFilterSpec{{winUTF16Ptr(pat), winUTF16Ptr(pat)}, {winUTF16Ptr("All Files"), winUTF16Ptr("*.*")}}
+		comCall(fd, fdSetFileTypes, uintptr(len(specs)), uintptr(unsafe.Pointer(&specs[0])))
+	}
+	dir, file := opts.DirFile()
+	if dir != "" {
+		var si *comObj
+		if hr, _, _ := procSHCreateItemFromParsingName.Call(uintptr(unsafe.Pointer(winUTF16Ptr(dir))), 0, uintptr(unsafe.Pointer(&iidShellItem)), uintptr(unsafe.Pointer(&si))); hr == 0 {
+			comCall(fd, fdSetFolder, uintptr(unsafe.Pointer(si)))
+			comCall(si, siRelease)
+		}
+	}
+	fnm := winUTF16Ptr(file)
+	if file != "" {
+		comCall(fd, fdSetFileName, uintptr(unsafe.Pointer(fnm)))
+	}
+
+	hr := comCall(fd, fdShow, hwnd)
+	runtime.KeepAlive(title)
+	runtime.KeepAlive(specs)
+	runtime.KeepAlive(fnm)
+	if uint32(hr) == hresultCanceled {
+		return "", nil
+	}
+	if hr != 0 {
+		return "", fmt.Errorf("glos: file dialog failed: HRESULT %#x", hr)
+	}
+	var item *comObj
+	if hr := comCall(fd, fdGetResult, uintptr(unsafe.Pointer(&item))); hr != 0 {
+		return "", fmt.Errorf("glos: file dialog result failed: HRESULT %#x", hr)
+	}
+	defer comCall(item, siRelease)
+	var pstr *uint16
+	if hr := comCall(item, siGetDisplayName, sigdnFileSysPath, uintptr(unsafe.Pointer(&pstr))); hr != 0 {
+		return "", fmt.Errorf("glos: file dialog path failed: HRESULT %#x", hr)
+	}
+	defer procCoTaskMemFree.Call(uintptr(unsafe.Pointer(pstr)))
+	var u []uint16
+	for p := pstr; *p != 0; p = (*uint16)(unsafe.Pointer(uintptr(unsafe.Pointer(p)) + 2)) {
+		u = append(u, *p)
+	}
+	return syscall.UTF16ToString(u), nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux,!android dragonfly openbsd
+
+package glos
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/goki/gi/oswin"
+)
+
+// there is no native file dialog on X11 or Wayland as such, so the
+// desktop's dialog is shown using the zenity (GTK, which uses
+// xdg-desktop-portal where available) or kdialog (KDE) command, whichever
+// is installed
+
+// fileDialogCmd returns the name and path of the command used for file
+// dialogs, or "" if neither is installed -- kdialog is preferred on KDE
+func fileDialogCmd() (name, path string) {
+	cmds := []string{"zenity", "kdialog"}
+	if strings.Contains(strings.ToUpper(os.Getenv("XDG_CURRENT_DESKTOP")), "KDE") {
+		cmds[0], cmds[1] = cmds[1], cmds[0]
+	}
+	for _, nm := range cmds {
+		if p, err := exec.LookPath(nm); err == nil {
+			return nm, p
+		}
+	}
+	return "", ""
+}
+
+func (app *appImpl) HasFileDialog() bool {
+	_, path := fileDialogCmd()
+	return path != ""
+}
+
+func (app *appImpl) FileDialog(win oswin.Window, opts *oswin.FileDialogOptions) (string, error) {
+	nm, path := fileDialogCmd()
+	if path == "" {
+		return "", errors.New("glos: the zenity or kdialog command is needed for native file dialogs")
+	}
+	var args []string
+	if nm == "zenity" {
+		args = zenityArgs(win, opts)
+	} else {
+		args = kdialogArgs(win, opts)
+	}
+	out, err := exec.Command(path, args...).Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && ee.ExitCode() == 1 {
+			return "", nil // canceled
+		}
+		return "", fmt.Errorf("glos: %v file dialog: %v", nm, err)
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// zenityArgs returns the zenity command arguments for given options
+func zenityArgs(win oswin.Window, opts *oswin.FileDialogOptions) []string {
+	args := []string{"--file-selection"}
+	if opts.Title != "" {
+		args = append(args, "--title="+opts.Title)
+	}
+	if opts.Save {
+		args = append(args, "--save", "--confirm-overwrite")
+	}
+	if opts.Dir {
+		args = append(args, "--directory")
+	}
+	dir, file := opts.DirFile()
+	if dir != "" {
+		fn := filepath.Join(dir, file)
+		if file == "" {
+			fn += "/" // starts in the directory, without a file name
+		}
+		args = append(args, "--filename="+fn)
+	}
+	if len(opts.Exts) > 0 && !opts.Dir {
+		pats := make([]string, len(opts.Exts))
+		for i, ex := range opts.Exts {
+			pats[i] = "*" + ex
+		}
+		pat := strings.Join(pats, " ")
+		args = append(args, "--file-filter="+pat+" | "+pat, "--file-filter=All files | *")
+	}
+	if win != nil && win.OSHandle() != 0 { // X11 only
+		args = append(args, "--modal", fmt.Sprintf("--attach=%d", win.OSHandle()))
+	}
+	return args
+}
+
+// kdialogArgs returns the kdialog command arguments for given options
+func kdialogArgs(win oswin.Window, opts *oswin.FileDialogOptions) []string {
+	var args []string
+	if opts.Title != "" {
+		args = append(args, "--title", opts.Title)
+	}
+	if win != nil && win.OSHandle() != 0 { // X11 only
+		args = append(args, "--attach", fmt.Sprintf("%d", win.OSHandle()))
+	}
+	dir, file := opts.DirFile()
+	start := filepath.Join(dir, file)
+	if start == "" {
+		start = "."
+	}
+	switch {
+	case opts.Dir:
+		args = append(args, "--getexistingdirectory", start)
+	case opts.Save:
+		args = append(args, "--getsavefilename", start)
+	default:
+		args = append(args, "--getopenfilename", start)
+	}
+	if len(opts.Exts) > 0 && !opts.Dir {
+		pats := make([]string, len(opts.Exts))
+		for i, ex := range opts.Exts {
+			pats[i] = "*" + ex
+		}
+		args = append(args, strings.Join(pats, " "))
+	}
+	return args
+}
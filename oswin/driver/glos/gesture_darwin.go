@@ -0,0 +1,54 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package glos
+
+/*
+#cgo CFLAGS: -x objective-c -Wno-deprecated-declarations
+#cgo LDFLAGS: -framework Cocoa
+#include <stdint.h>
+void addMagnifyHandler(uintptr_t winID);
+*/
+import "C"
+
+import (
+	"github.com/goki/gi/oswin/touch"
+)
+
+// trackpad pinches are received by a magnifyWithEvent: method added to the
+// content view class of GLFW, which does not report them -- two-finger
+// panning is reported as scrolling -- see gesture_darwin.m
+
+func (w *windowImpl) initGestures() {
+	w.app.RunOnMain(func() {
+		C.addMagnifyHandler(C.uintptr_t(w.OSHandle()))
+	})
+}
+
+//export magnifyFired
+func magnifyFired(id uintptr, mag C.double, phase C.int) {
+	theApp.mu.Lock()
+	w, ok := theApp.oswindows[id]
+	theApp.mu.Unlock()
+	if !ok || w == nil {
+		return
+	}
+	act := touch.Move
+	switch phase {
+	case 1:
+		act = touch.Begin
+	case 2:
+		act = touch.End
+	}
+	event := &touch.MagnifyEvent{
+		Where:         w.curMousePosPoint(w.glw),
+		Magnification: float32(1 + mag),
+		Action:        act,
+	}
+	if act != touch.Move {
+		event.Magnification = 1
+	}
+	event.Init()
+	w.Send(event)
+}
@@ -0,0 +1,30 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build darwin
+
+#include "_cgo_export.h"
+
+#import <Cocoa/Cocoa.h>
+#import <objc/runtime.h>
+
+// goMagnify is the magnifyWithEvent: method added to the content view
+// class, which forwards the magnification to Go, with the phase: 1 for
+// began, 2 for ended, and 0 otherwise
+static void goMagnify(id self, SEL cmd, NSEvent* ev) {
+    int phase = 0;
+    if (ev.phase & NSEventPhaseBegan) {
+        phase = 1;
+    } else if (ev.phase & (NSEventPhaseEnded | NSEventPhaseCancelled)) {
+        phase = 2;
+    }
+    magnifyFired((GoUintptr)[self window], ev.magnification, phase);
+}
+
+// addMagnifyHandler adds goMagnify to the class of the content view of
+// given window -- only the first call for the class has any effect
+void addMagnifyHandler(uintptr_t winID) {
+    NSWindow* win = (NSWindow*)winID;
+    class_addMethod([[win contentView] class], @selector(magnifyWithEvent:), (IMP)goMagnify, "v@:@");
+}
@@ -0,0 +1,148 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package glos
+
+import (
+	"image"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/goki/gi/oswin/touch"
+)
+
+// touch screen gestures are received as WM_GESTURE messages by a window
+// procedure that subclasses the one of GLFW, which does not report them --
+// a press and hold is sent by Windows as a right click, and trackpad
+// gestures as mouse wheel messages -- it also sends stylus events for pen
+// messages, see stylus_windows.go
+
+var (
+	procSetWindowLongPtrW      = user32.NewProc("SetWindowLongPtrW")
+	procCallWindowProcW        = user32.NewProc("CallWindowProcW")
+	procGetGestureInfo         = user32.NewProc("GetGestureInfo")
+	procCloseGestureInfoHandle = user32.NewProc("CloseGestureInfoHandle")
+	procScreenToClient         = user32.NewProc("ScreenToClient")
+)
+
+const (
+	gwlpWndProc = ^uintptr(3) // -4
+	wmGesture   = 0x0119
+	gidZoom     = 3
+	gidPan      = 4
+	gfBegin     = 0x1
+	gfEnd       = 0x4
+)
+
+type gestureInfo struct {
+	Size       uint32
+	Flags      uint32
+	ID         uint32
+	Target     uintptr
+	Location   [2]int16
+	InstanceID uint32
+	SequenceID uint32
+	Arguments  uint64
+	ExtraArgs  uint32
+}
+
+// gestureWin is the gesture state of a window
+type gestureWin struct {
+	w        *windowImpl
+	oldProc  uintptr
+	lastDist uint64      // distance between the fingers at the last zoom
+	lastPos  image.Point // position at the last pan
+}
+
+var (
+	gestureMu      sync.Mutex
+	gestureWins    = map[uintptr]*gestureWin{} // by window handle
+	gestureProcCB  uintptr
+	gestureProcOne sync.Once
+)
+
+func (w *windowImpl) initGestures() {
+	gestureProcOne.Do(func() {
+		gestureProcCB = syscall.NewCallback(gestureWndProc)
+	})
+	w.app.RunOnMain(func() {
+		hwnd := w.OSHandle()
+		gw := &gestureWin{w: w}
+		gestureMu.Lock()
+		gestureWins[hwnd] = gw
+		gestureMu.Unlock()
+		gw.oldProc, _, _ = procSetWindowLongPtrW.Call(hwnd, gwlpWndProc, gestureProcCB)
+	})
+}
+
+// gestureWndProc handles WM_GESTURE and pen messages, passing other
+// messages to the window procedure of GLFW
+func gestureWndProc(hwnd, msg, wparam, lparam uintptr) uintptr {
+	gestureMu.Lock()
+	gw := gestureWins[hwnd]
+	gestureMu.Unlock()
+	if gw == nil {
+		r, _, _ := procDefWindowProcW.Call(hwnd, msg, wparam, lparam)
+		return r
+	}
+	if msg == wmGesture && gw.gesture(hwnd, lparam) {
+		procCloseGestureInfoHandle.Call(lparam)
+		return 0
+	}
+	if msg >= wmPointerUpdate && msg <= wmPointerUp {
+		gw.pointer(hwnd, msg, wparam) // passed on for the emulated mouse messages
+	}
+	if msg == wmDestroy {
+		gestureMu.Lock()
+		delete(gestureWins, hwnd)
+		gestureMu.Unlock()
+	}
+	r, _, _ := procCallWindowProcW.Call(gw.oldProc, hwnd, msg, wparam, lparam)
+	return r
+}
+
+// gesture sends the event for a zoom or pan gesture message, returning
+// false for other gestures, which are passed on
+func (gw *gestureWin) gesture(hwnd, handle uintptr) bool {
+	gi := gestureInfo{}
+	gi.Size = uint32(unsafe.Sizeof(gi))
+	if r, _, _ := procGetGestureInfo.Call(handle, uintptr(unsafe.Pointer(&gi))); r == 0 {
+		return false
+	}
+	if gi.ID != gidZoom && gi.ID != gidPan {
+		return false
+	}
+	pt := winPoint{X: int32(gi.Location[0]), Y: int32(gi.Location[1])}
+	procScreenToClient.Call(hwnd, uintptr(unsafe.Pointer(&pt)))
+	pos := image.Point{int(pt.X), int(pt.Y)}
+	act := touch.Move
+	switch {
+	case gi.Flags&gfBegin != 0:
+		act = touch.Begin
+	case gi.Flags&gfEnd != 0:
+		act = touch.End
+	}
+	switch gi.ID {
+	case gidZoom: // Arguments is the distance between the fingers
+		event := &touch.MagnifyEvent{Where: pos, Magnification: 1, Action: act}
+		if act == touch.Move && gw.lastDist > 0 && gi.Arguments > 0 {
+			event.Magnification = float32(gi.Arguments) / float32(gw.lastDist)
+		}
+		gw.lastDist = gi.Arguments
+		event.Init()
+		gw.w.Send(event)
+	case gidPan:
+		event := &touch.PanEvent{Where: pos, Action: act}
+		if act == touch.Move {
+			event.Delta = pos.Sub(gw.lastPos)
+		}
+		gw.lastPos = pos
+		event.Init()
+		gw.w.Send(event)
+	}
+	return true
+}
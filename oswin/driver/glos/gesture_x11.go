@@ -0,0 +1,13 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux,!android dragonfly openbsd
+
+package glos
+
+// GLFW does not report touches or gestures on X11 or Wayland, where touch
+// screens emulate the mouse, and two-finger panning on trackpads is
+// reported as scrolling
+func (w *windowImpl) initGestures() {
+}
@@ -18,7 +18,9 @@ package glos
 void clipClear();
 bool clipIsEmpty();
 void clipReadText();
+void clipReadData(char* uti, char* typ, int typlen);
 void pasteWriteAddText(char* data, int dlen);
+void pasteWriteAddData(char* uti, char* data, int dlen);
 void clipWrite();
 void pushCursor(int);
 void popCursor();
@@ -35,11 +37,14 @@ void doAddSeparator(uintptr_t menuID);
 uintptr_t doMenuItemByTitle(uintptr_t menuID, char* mnm);
 uintptr_t doMenuItemByTag(uintptr_t menuID, int tag);
 void doSetMenuItemActive(uintptr_t mitmID, bool active);
+bool isDarkMode();
+char* doFileDialog(uintptr_t winID, char* title, char* dir, char* file, char* exts, bool save, bool isdir);
 */
 import "C"
 
 import (
 	"fmt"
+	"image/color"
 	"log"
 	"os/exec"
 	"os/user"
@@ -67,6 +72,26 @@ func (app *appImpl) OpenURL(url string) {
 	cmd.Run()
 }
 
+func (app *appImpl) Notify(title, msg string) error {
+	script := fmt.Sprintf("display notification %q with title %q", msg, title)
+	cmd := exec.Command("osascript", "-e", script)
+	return cmd.Run()
+}
+
+func (app *appImpl) PickScreenColor() (color.RGBA, error) {
+	// the color panel has an eyedropper for picking from the screen
+	out, err := exec.Command("osascript", "-e", "choose color").Output()
+	if err != nil {
+		return color.RGBA{}, err
+	}
+	var r, g, b int
+	_, err = fmt.Sscanf(strings.TrimSpace(string(out)), "%d, %d, %d", &r, &g, &b)
+	if err != nil {
+		return color.RGBA{}, err
+	}
+	return color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), 255}, nil
+}
+
 func (app *appImpl) FontPaths() []string {
 	return []string{"/System/Library/Fonts", "/Library/Fonts"}
 }
@@ -120,6 +145,17 @@ func (ci *clipImpl) Read(types []string) mimedata.Mimes {
 	ci.data = nil
 	curMimeData = &ci.data
 
+	for len(types) > 0 && mimedata.IsRich(types[0]) {
+		ci.ReadRich(types[0])
+		if len(ci.data) > 0 {
+			return ci.data
+		}
+		types = types[1:] // try the next type
+	}
+	if len(types) == 0 {
+		return nil
+	}
+
 	wantText := mimedata.IsText(types[0])
 
 	if wantText {
@@ -153,9 +189,89 @@ func (ci *clipImpl) WriteText(b []byte) {
 	C.free(unsafe.Pointer(cdata))
 }
 
+// clipUTIs are the pasteboard types (UTIs) for the mime types that are
+// written as separate flavors when there is any rich data
+var clipUTIs = map[string]string{
+	filecat.TextPlain: "public.utf8-plain-text",
+	mimedata.TextHTML: "public.html",
+	mimedata.TextRTF:  "public.rtf",
+	mimedata.ImagePNG: "public.png",
+	"image/tiff":      "public.tiff",
+}
+
+// ReadData reads data of given pasteboard type (UTI) into ci.data, with
+// given mime type
+func (ci *clipImpl) ReadData(uti, typ string) {
+	cuti := C.CString(uti)
+	ctyp := C.CString(typ)
+	C.clipReadData(cuti, ctyp, C.int(len(typ)))
+	C.free(unsafe.Pointer(cuti))
+	C.free(unsafe.Pointer(ctyp))
+}
+
+// ReadRich reads the native flavor for given rich mime type into ci.data --
+// images are read as PNG, converted from TIFF if that is all there is
+func (ci *clipImpl) ReadRich(typ string) {
+	if !mimedata.IsImage(typ) {
+		ci.ReadData(clipUTIs[typ], typ)
+		return
+	}
+	ci.ReadData(clipUTIs[mimedata.ImagePNG], mimedata.ImagePNG)
+	if len(ci.data) > 0 {
+		return
+	}
+	ci.ReadData(clipUTIs["image/tiff"], "image/tiff")
+	if len(ci.data) == 0 {
+		return
+	}
+	b, err := mimedata.ToPNG(ci.data[0])
+	if err != nil {
+		log.Println(err)
+		ci.data = nil
+		return
+	}
+	ci.data = mimedata.NewMime(mimedata.ImagePNG, b)
+}
+
+// WriteData adds data of given pasteboard type (UTI) to the item to write
+func (ci *clipImpl) WriteData(uti string, b []byte) {
+	sz := len(b)
+	if sz == 0 {
+		return
+	}
+	cuti := C.CString(uti)
+	cdata := C.malloc(C.size_t(sz))
+	copy((*[1 << 30]byte)(cdata)[0:sz], b)
+	C.pasteWriteAddData(cuti, (*C.char)(cdata), C.int(sz))
+	C.free(cdata)
+	C.free(unsafe.Pointer(cuti))
+}
+
+// WriteRich writes each element of data as its native flavor, with images
+// converted to PNG -- elements of other types are ignored
+func (ci *clipImpl) WriteRich(data mimedata.Mimes) {
+	for _, d := range data {
+		b := d.Data
+		typ := d.Type
+		if mimedata.IsImage(typ) {
+			var err error
+			if b, err = mimedata.ToPNG(d); err != nil {
+				log.Println(err)
+				continue
+			}
+			typ = mimedata.ImagePNG
+		}
+		if uti, ok := clipUTIs[typ]; ok {
+			ci.WriteData(uti, b)
+		}
+	}
+}
+
 func (ci *clipImpl) Write(data mimedata.Mimes) error {
 	ci.Clear()
-	if len(data) > 1 { // multipart
+	if data.HasRich() {
+		ci.WriteRich(data)
+	} else if len(data) > 1 { // multipart
 		mpd := data.ToMultipart()
 		ci.WriteText(mpd)
 	} else {
@@ -405,3 +521,55 @@ func menuFired(id uintptr, title *C.char, tilen C.int, tag C.int) {
 	}
 	go osmm.Triggered(w, tit, int(tag))
 }
+
+/////////////////////////////////////////////////////////////////
+//  Theme
+
+// osIsDark returns true if the system appearance is Dark
+func osIsDark() bool {
+	return bool(C.isDarkMode())
+}
+
+// watchTheme polls the appearance, which is simpler than observing the
+// distributed notification for it on the main thread
+func (app *appImpl) watchTheme() {
+	app.pollTheme()
+}
+
+/////////////////////////////////////////////////////////////////
+//  FileDialog
+
+func (app *appImpl) HasFileDialog() bool {
+	return true
+}
+
+// FileDialog shows an NSOpenPanel or NSSavePanel, which must run on the main
+// thread, reactivating given window after
+func (app *appImpl) FileDialog(win oswin.Window, opts *oswin.FileDialogOptions) (string, error) {
+	dir, file := opts.DirFile()
+	exts := make([]string, len(opts.Exts))
+	for i, ex := range opts.Exts {
+		exts[i] = strings.TrimPrefix(ex, ".")
+	}
+	ctitle := C.CString(opts.Title)
+	defer C.free(unsafe.Pointer(ctitle))
+	cdir := C.CString(dir)
+	defer C.free(unsafe.Pointer(cdir))
+	cfile := C.CString(file)
+	defer C.free(unsafe.Pointer(cfile))
+	cexts := C.CString(strings.Join(exts, ","))
+	defer C.free(unsafe.Pointer(cexts))
+	var wid uintptr
+	if win != nil {
+		wid = win.OSHandle()
+	}
+	var fn string
+	app.RunOnMain(func() {
+		cfn := C.doFileDialog(C.uintptr_t(wid), ctitle, cdir, cfile, cexts, C.bool(opts.Save), C.bool(opts.Dir))
+		if cfn != nil {
+			fn = C.GoString(cfn)
+			C.free(unsafe.Pointer(cfn))
+		}
+	})
+	return fn, nil
+}
@@ -35,7 +35,7 @@ static const char* mime_hdr = "MIME-Version: 1.0\nContent-type: ";
 // return true if empty
 bool pasteIsEmpty(NSPasteboard* pb) {
     NSDictionary *options = [NSDictionary dictionary];
-    NSArray *classes = [[NSArray alloc] initWithObjects:[NSString class], nil];
+    NSArray *classes = [[NSArray alloc] initWithObjects:[NSString class], [NSImage class], nil];
     bool has = [pb canReadObjectForClasses:classes options:options];
 	[classes release];
     return !has;
@@ -86,7 +86,30 @@ void pasteReadHTML(NSPasteboard* pb, char* typ, int len) {
     // [itms release];
 }
 
+// read the data of given pasteboard type (UTI, e.g., public.png), passing
+// it to addMimeData with given mime type
+void pasteReadData(NSPasteboard* pb, char* uti, char* typ, int typlen) {
+    NSString *ns_uti = [NSString stringWithUTF8String:uti];
+    NSData *dat = [pb dataForType:ns_uti];
+    if (dat == nil) {
+        return;
+    }
+    addMimeData(typ, typlen, (char*)[dat bytes], (int)[dat length]);
+}
+
 static NSMutableArray *pasteWriteItems = NULL;
+static NSPasteboardItem *pasteWriteItem = NULL;
+
+// add data of given pasteboard type (UTI) to the item to paste -- all
+// such data are different flavors of the same item
+void pasteWriteAddData(char* uti, char* data, int len) {
+    if(pasteWriteItem == NULL) {
+        pasteWriteItem = [[NSPasteboardItem alloc] init];
+    }
+    NSString *ns_uti = [NSString stringWithUTF8String:uti];
+    NSData *dat = [NSData dataWithBytes:data length:len];
+    [pasteWriteItem setData:dat forType:ns_uti];
+}
 
 // add text to the list of items to paste
 void pasteWriteAddText(char* data, int len) {
@@ -104,6 +127,15 @@ void pasteWriteAddText(char* data, int len) {
 }	
 
 void pasteWrite(NSPasteboard* pb) {
+    if(pasteWriteItem != NULL) {
+        if(pasteWriteItems == NULL) {
+            pasteWriteItems = [NSMutableArray array];
+            [pasteWriteItems retain];
+        }
+        [pasteWriteItems addObject:pasteWriteItem];
+        [pasteWriteItem release]; // pastewrite owns
+        pasteWriteItem = NULL;
+    }
     if(pasteWriteItems == NULL) {
         return;
     }
@@ -130,6 +162,14 @@ void clipReadText() {
     pasteReadText(pb);
 }
 
+void clipReadData(char* uti, char* typ, int typlen) {
+    NSPasteboard *pb = [NSPasteboard generalPasteboard];
+    if(pb == NULL) {
+        return;
+    }
+    pasteReadData(pb, uti, typ, typlen);
+}
+
 void clipWrite() {
     NSPasteboard *pb = [NSPasteboard generalPasteboard];
     if(pb == NULL) {
@@ -148,6 +188,10 @@ void clipClear() {
 		 [pasteWriteItems release];
         pasteWriteItems = NULL;
     }
+    if(pasteWriteItem != NULL) {
+        [pasteWriteItem release];
+        pasteWriteItem = NULL;
+    }
 }
 
 
@@ -399,3 +443,59 @@ void doSetMenuItemActive(uintptr_t mitmID, bool active) {
 }
 
 
+
+///////////////////////////////////////////////////////////////////////
+//   Theme
+
+// isDarkMode returns true if the system appearance is Dark -- can be called
+// from any thread
+bool isDarkMode() {
+    bool dark = false;
+    @autoreleasepool {
+        NSString* style = [[NSUserDefaults standardUserDefaults] stringForKey:@"AppleInterfaceStyle"];
+        dark = (style != nil && [style caseInsensitiveCompare:@"Dark"] == NSOrderedSame);
+    }
+    return dark;
+}
+
+///////////////////////////////////////////////////////////////////////
+//   FileDialog
+
+// doFileDialog runs a modal open or save panel, returning the chosen path,
+// which must be freed, or NULL if canceled -- exts is a comma-separated list
+// of extensions without the leading .
+char* doFileDialog(uintptr_t winID, char* title, char* dir, char* file, char* exts, bool save, bool isdir) {
+    NSSavePanel* panel;
+    if(save) {
+        panel = [NSSavePanel savePanel];
+        [panel setCanCreateDirectories:YES];
+    } else {
+        NSOpenPanel* opanel = [NSOpenPanel openPanel];
+        [opanel setCanChooseFiles:!isdir];
+        [opanel setCanChooseDirectories:isdir];
+        [opanel setAllowsMultipleSelection:NO];
+        panel = opanel;
+    }
+    if(strlen(title) > 0) {
+        [panel setMessage:[NSString stringWithUTF8String:title]];
+    }
+    if(strlen(dir) > 0) {
+        [panel setDirectoryURL:[NSURL fileURLWithPath:[NSString stringWithUTF8String:dir] isDirectory:YES]];
+    }
+    if(strlen(file) > 0) {
+        [panel setNameFieldStringValue:[NSString stringWithUTF8String:file]];
+    }
+    if(strlen(exts) > 0 && !isdir) {
+        NSString* ex = [NSString stringWithUTF8String:exts];
+        [panel setAllowedFileTypes:[ex componentsSeparatedByString:@","]];
+    }
+    NSWindow* win = (NSWindow*)winID;
+    NSModalResponse resp = [panel runModal];
+    if(win != NULL) {
+        [win makeKeyAndOrderFront:nil];
+    }
+    if(resp != NSModalResponseOK || [panel URL] == nil) {
+        return NULL;
+    }
+    return strdup([[[panel URL] path] UTF8String]);
+}
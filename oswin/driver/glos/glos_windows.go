@@ -71,12 +71,21 @@ var theClip = clipImpl{}
 func (ci *clipImpl) IsEmpty() bool {
 	str := glfw.GetClipboardString()
 	if len(str) == 0 {
-		return true
+		return !winClipHasRich()
 	}
 	return false
 }
 
 func (ci *clipImpl) Read(types []string) mimedata.Mimes {
+	for len(types) > 0 && mimedata.IsRich(types[0]) {
+		if md := ci.ReadRich(types[0]); len(md) > 0 {
+			return md
+		}
+		types = types[1:] // try the next type
+	}
+	if len(types) == 0 {
+		return nil
+	}
 	str := glfw.GetClipboardString()
 	if len(str) == 0 {
 		return nil
@@ -106,6 +115,9 @@ func (ci *clipImpl) Write(data mimedata.Mimes) error {
 		return nil
 	}
 	// w := theApp.ctxtwin
+	if data.HasRich() {
+		return ci.WriteRich(data)
+	}
 	if len(data) > 1 { // multipart
 		mpd := data.ToMultipart()
 		glfw.SetClipboardString(string(mpd))
@@ -2,11 +2,12 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// +build linux,!android dragonfly openbsd
+// +build linux,!android,!wayland dragonfly openbsd
 
 package glos
 
 import (
+	"image/color"
 	"log"
 	"os/exec"
 	"os/user"
@@ -43,6 +44,25 @@ func (app *appImpl) OpenURL(url string) {
 	cmd.Run()
 }
 
+func (app *appImpl) Notify(title, msg string) error {
+	cmd := exec.Command("notify-send", "-a", app.name, title, msg)
+	return cmd.Run()
+}
+
+func (app *appImpl) PickScreenColor() (color.RGBA, error) {
+	for _, args := range [][]string{{"xcolor", "-f", "hex"}, {"gpick", "-s", "-o"}} {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		out, err := exec.Command(args[0], args[1:]...).Output()
+		if err != nil {
+			return color.RGBA{}, err
+		}
+		return parseHexColor(out)
+	}
+	return color.RGBA{}, errNoColorPicker
+}
+
 func (app *appImpl) FontPaths() []string {
 	return []string{"/usr/share/fonts/truetype"}
 }
@@ -77,12 +97,21 @@ var theClip = clipImpl{}
 func (ci *clipImpl) IsEmpty() bool {
 	str := glfw.GetClipboardString()
 	if len(str) == 0 {
-		return true
+		return !xclipHasRich()
 	}
 	return false
 }
 
 func (ci *clipImpl) Read(types []string) mimedata.Mimes {
+	for len(types) > 0 && mimedata.IsRich(types[0]) {
+		if md := ci.ReadRich(types[0]); len(md) > 0 {
+			return md
+		}
+		types = types[1:] // try the next type
+	}
+	if len(types) == 0 {
+		return nil
+	}
 	str := glfw.GetClipboardString()
 	if len(str) == 0 {
 		return nil
@@ -112,6 +141,9 @@ func (ci *clipImpl) Write(data mimedata.Mimes) error {
 		return nil
 	}
 	// w := theApp.ctxtwin
+	if data.HasRich() {
+		return ci.WriteRich(data)
+	}
 	if len(data) > 1 { // multipart
 		mpd := data.ToMultipart()
 		glfw.SetClipboardString(string(mpd))
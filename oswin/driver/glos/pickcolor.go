@@ -0,0 +1,46 @@
+// Copyright 2018 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package glos
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// errNoColorPicker is returned by PickScreenColor when none of the tools
+// for picking a color from the screen are installed
+var errNoColorPicker = errors.New("glos: no tool for picking a color from the screen was found")
+
+// parseHexColor parses the #rrggbb color output by a color picking tool,
+// e.g., xcolor or gpick
+func parseHexColor(out []byte) (color.RGBA, error) {
+	s := strings.TrimSpace(string(out))
+	if i := strings.LastIndex(s, "\n"); i >= 0 { // last line
+		s = strings.TrimSpace(s[i+1:])
+	}
+	s = strings.TrimPrefix(s, "#")
+	if len(s) != 6 {
+		return color.RGBA{}, fmt.Errorf("glos: could not parse picked color: %q", out)
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("glos: could not parse picked color: %q", out)
+	}
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255}, nil
+}
+
+// parsePPMColor parses the color of the single pixel of a binary PPM image,
+// as output by grim for a 1x1 region
+func parsePPMColor(out []byte) (color.RGBA, error) {
+	if !bytes.HasPrefix(out, []byte("P6")) || len(out) < 3+3 {
+		return color.RGBA{}, errors.New("glos: could not parse the picked pixel")
+	}
+	px := out[len(out)-3:]
+	return color.RGBA{px[0], px[1], px[2], 255}, nil
+}
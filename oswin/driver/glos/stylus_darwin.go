@@ -0,0 +1,56 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package glos
+
+/*
+#cgo CFLAGS: -x objective-c -Wno-deprecated-declarations
+#cgo LDFLAGS: -framework Cocoa
+void addStylusMonitor();
+*/
+import "C"
+
+import (
+	"sync"
+
+	"github.com/goki/gi/oswin/stylus"
+)
+
+// pen input is received by a local monitor of tablet events, which passes
+// them on to GLFW as the mouse events that they also are -- see
+// stylus_darwin.m
+
+var stylusOnce sync.Once
+
+func (w *windowImpl) initStylus() {
+	stylusOnce.Do(func() {
+		w.app.RunOnMain(func() {
+			C.addStylusMonitor()
+		})
+	})
+}
+
+//export stylusFired
+func stylusFired(id uintptr, x, y C.double, act C.int, pressure, tiltX, tiltY C.double, eraser, barrel C.int) {
+	theApp.mu.Lock()
+	w, ok := theApp.oswindows[id]
+	theApp.mu.Unlock()
+	if !ok || w == nil {
+		return
+	}
+	event := &stylus.Event{
+		Where:     w.mousePosToPoint(float64(x), float64(y)),
+		Action:    stylus.Actions(act),
+		TiltX:     float32(tiltX),
+		TiltY:     float32(tiltY),
+		Eraser:    eraser != 0,
+		Barrel:    barrel != 0,
+		Modifiers: lastMods,
+	}
+	if event.Action == stylus.Press || event.Action == stylus.Drag {
+		event.Pressure = float32(pressure)
+	}
+	event.Init()
+	w.Send(event)
+}
@@ -0,0 +1,62 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build darwin
+
+#include "_cgo_export.h"
+
+#import <Cocoa/Cocoa.h>
+
+// stylusEraser is whether the pen in proximity of the tablet is an eraser
+static BOOL stylusEraser = NO;
+
+// stylusEvent forwards the pen data of a tablet event to Go, with the
+// position in the content view, from its top left, and the action as in
+// stylus.Actions: 0 for Press, 1 for Drag, 2 for Release and 3 for Hover
+static void stylusEvent(NSEvent* ev) {
+    NSWindow* win = [ev window];
+    if (win == nil) {
+        return;
+    }
+    NSView* view = [win contentView];
+    NSPoint pt = [view convertPoint:[ev locationInWindow] fromView:nil];
+    if (![view isFlipped]) {
+        pt.y = [view bounds].size.height - pt.y;
+    }
+    int act = 3;
+    switch ([ev type]) {
+    case NSEventTypeLeftMouseDown:
+        act = 0;
+        break;
+    case NSEventTypeLeftMouseDragged:
+        act = 1;
+        break;
+    case NSEventTypeLeftMouseUp:
+        act = 2;
+        break;
+    case NSEventTypeTabletPoint:
+        act = ([NSEvent pressedMouseButtons] & 1) ? 1 : 3;
+        break;
+    default:
+        break;
+    }
+    NSPoint tilt = [ev tilt];
+    int barrel = ([ev buttonMask] & (NSEventButtonMaskPenLowerSide | NSEventButtonMaskPenUpperSide)) != 0;
+    stylusFired((GoUintptr)win, pt.x, pt.y, act, [ev pressure], tilt.x, tilt.y, stylusEraser ? 1 : 0, barrel);
+}
+
+// addStylusMonitor adds a local monitor of the mouse events of tablets,
+// and their proximity events, which tell whether the eraser is used
+void addStylusMonitor() {
+    NSEventMask mask = NSEventMaskLeftMouseDown | NSEventMaskLeftMouseUp | NSEventMaskLeftMouseDragged |
+        NSEventMaskMouseMoved | NSEventMaskTabletPoint | NSEventMaskTabletProximity;
+    [NSEvent addLocalMonitorForEventsMatchingMask:mask handler:^NSEvent*(NSEvent* ev) {
+        if ([ev type] == NSEventTypeTabletProximity) {
+            stylusEraser = [ev isEnteringProximity] && [ev pointingDeviceType] == NSPointingDeviceTypeEraser;
+        } else if ([ev type] == NSEventTypeTabletPoint || [ev subtype] == NSEventSubtypeTabletPoint) {
+            stylusEvent(ev);
+        }
+        return ev;
+    }];
+}
@@ -0,0 +1,14 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux,!android,wayland linux,!android,noxinput dragonfly,noxinput openbsd,noxinput
+
+package glos
+
+// initStylus does nothing where pen input is not available: under Wayland,
+// where glfw does not expose the wl_surface needed for the tablet protocol,
+// and with the noxinput build tag, which leaves out the libXi dependency of
+// the X11 pen input -- the core mouse events of the pen still go to GLFW
+func (w *windowImpl) initStylus() {
+}
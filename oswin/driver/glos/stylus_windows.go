@@ -0,0 +1,115 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package glos
+
+import (
+	"image"
+	"unsafe"
+
+	"github.com/goki/gi/oswin/stylus"
+)
+
+// pen input is received as WM_POINTER messages by the window procedure of
+// gesture_windows.go, which passes them on to GLFW, so that Windows also
+// sends the mouse messages that it emulates for the pen
+
+var (
+	procGetPointerType    = user32.NewProc("GetPointerType")
+	procGetPointerPenInfo = user32.NewProc("GetPointerPenInfo")
+)
+
+const (
+	wmPointerUpdate      = 0x0245
+	wmPointerDown        = 0x0246
+	wmPointerUp          = 0x0247
+	ptPen                = 3
+	pointerFlagInContact = 0x4
+	penFlagBarrel        = 0x1
+	penFlagInverted      = 0x2
+	penFlagEraser        = 0x4
+	penMaskPressure      = 0x1
+	penMaskTiltX         = 0x4
+	penMaskTiltY         = 0x8
+)
+
+type pointerInfo struct {
+	PointerType         uint32
+	PointerID           uint32
+	FrameID             uint32
+	PointerFlags        uint32
+	SourceDevice        uintptr
+	HwndTarget          uintptr
+	PixelLocation       winPoint
+	HimetricLocation    winPoint
+	PixelLocationRaw    winPoint
+	HimetricLocationRaw winPoint
+	Time                uint32
+	HistoryCount        uint32
+	InputData           int32
+	KeyStates           uint32
+	PerformanceCount    uint64
+	ButtonChangeType    int32
+}
+
+type pointerPenInfo struct {
+	pointerInfo
+	PenFlags uint32
+	PenMask  uint32
+	Pressure uint32 // 0 to 1024
+	Rotation uint32
+	TiltX    int32 // -90 to 90 degrees
+	TiltY    int32
+}
+
+// pointer sends a stylus event for a pointer message, if it is from a pen
+func (gw *gestureWin) pointer(hwnd, msg, wparam uintptr) {
+	id := wparam & 0xffff
+	var ptype uint32
+	if r, _, _ := procGetPointerType.Call(id, uintptr(unsafe.Pointer(&ptype))); r == 0 || ptype != ptPen {
+		return
+	}
+	pi := pointerPenInfo{}
+	if r, _, _ := procGetPointerPenInfo.Call(id, uintptr(unsafe.Pointer(&pi))); r == 0 {
+		return
+	}
+	pt := pi.PixelLocation
+	procScreenToClient.Call(hwnd, uintptr(unsafe.Pointer(&pt)))
+	event := &stylus.Event{
+		Where:     image.Point{int(pt.X), int(pt.Y)},
+		Eraser:    pi.PenFlags&(penFlagEraser|penFlagInverted) != 0,
+		Barrel:    pi.PenFlags&penFlagBarrel != 0,
+		Modifiers: lastMods,
+	}
+	switch {
+	case msg == wmPointerDown:
+		event.Action = stylus.Press
+	case msg == wmPointerUp:
+		event.Action = stylus.Release
+	case pi.PointerFlags&pointerFlagInContact != 0:
+		event.Action = stylus.Drag
+	default:
+		event.Action = stylus.Hover
+	}
+	if event.Action == stylus.Press || event.Action == stylus.Drag {
+		event.Pressure = 1
+		if pi.PenMask&penMaskPressure != 0 {
+			event.Pressure = float32(pi.Pressure) / 1024
+		}
+	}
+	if pi.PenMask&penMaskTiltX != 0 {
+		event.TiltX = float32(pi.TiltX) / 90
+	}
+	if pi.PenMask&penMaskTiltY != 0 {
+		event.TiltY = float32(pi.TiltY) / 90
+	}
+	event.Init()
+	gw.w.Send(event)
+}
+
+// pen messages are handled by the window procedure of gesture_windows.go
+func (w *windowImpl) initStylus() {
+}
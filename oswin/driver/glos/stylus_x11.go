@@ -0,0 +1,396 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux,!android,!wayland,!noxinput dragonfly,!noxinput openbsd,!noxinput
+
+package glos
+
+// Pen input on X11 uses the XInput2 extension, so this file requires the
+// libXi development library (e.g., libxi-dev) in addition to the libX11 one
+// that glfw needs -- building with the noxinput tag leaves it out, in which
+// case stylus events are not sent (see stylus_none.go).
+
+/*
+#cgo LDFLAGS: -lXi -lX11
+#include <stdlib.h>
+#include <string.h>
+#include <poll.h>
+#include <X11/Xlib.h>
+#include <X11/extensions/XInput2.h>
+
+#define maxStylusDevices 16
+#define maxStylusValuators 8
+
+// stylusDevice is a pen device: a slave pointer with a pressure valuator
+typedef struct {
+	int id;
+	char name[64];
+	int pressure, tiltX, tiltY; // valuator numbers, -1 if none
+	double pressMin, pressMax, tiltXMin, tiltXMax, tiltYMin, tiltYMax;
+} stylusDevice;
+
+// stylusEvent is a decoded pen event
+typedef struct {
+	int device;
+	Window window;
+	int type;
+	int button;
+	double x, y;
+	int buttons; // bit b is set if button b is down
+	int mods;
+	int hasVal[maxStylusValuators];
+	double val[maxStylusValuators];
+} stylusEvent;
+
+static int stylusOpcode;
+static Display* stylusDpy;
+static XErrorHandler stylusPrevHandler;
+
+// stylusError ignores the errors of the pen connection, e.g., for selecting
+// on windows that have been closed, passing others to the previous handler
+static int stylusError(Display* dpy, XErrorEvent* ev) {
+	if (dpy == stylusDpy) {
+		return 0;
+	}
+	return stylusPrevHandler ? stylusPrevHandler(dpy, ev) : 0;
+}
+
+// stylusInit returns 1 if XInput 2.2 is available on given display, which
+// becomes the pen connection, selecting for device changes
+static int stylusInit(Display* dpy) {
+	int ev, err;
+	if (!XQueryExtension(dpy, "XInputExtension", &stylusOpcode, &ev, &err)) {
+		return 0;
+	}
+	int major = 2, minor = 2;
+	if (XIQueryVersion(dpy, &major, &minor) != Success) {
+		return 0;
+	}
+	stylusDpy = dpy;
+	stylusPrevHandler = XSetErrorHandler(stylusError);
+	unsigned char bits[XIMaskLen(XI_LASTEVENT)];
+	memset(bits, 0, sizeof(bits));
+	XISetMask(bits, XI_HierarchyChanged);
+	XIEventMask mask;
+	mask.deviceid = XIAllDevices;
+	mask.mask_len = sizeof(bits);
+	mask.mask = bits;
+	XISelectEvents(dpy, DefaultRootWindow(dpy), &mask, 1);
+	XFlush(dpy);
+	return 1;
+}
+
+// stylusDevices gets the pen devices, returning their number
+static int stylusDevices(Display* dpy, stylusDevice* devs) {
+	Atom press = XInternAtom(dpy, "Abs Pressure", False);
+	Atom tiltX = XInternAtom(dpy, "Abs Tilt X", False);
+	Atom tiltY = XInternAtom(dpy, "Abs Tilt Y", False);
+	int n, nd = 0;
+	XIDeviceInfo* info = XIQueryDevice(dpy, XIAllDevices, &n);
+	for (int i = 0; i < n && nd < maxStylusDevices; i++) {
+		XIDeviceInfo* di = &info[i];
+		if (di->use != XISlavePointer || !di->enabled) {
+			continue;
+		}
+		stylusDevice d;
+		memset(&d, 0, sizeof(d));
+		d.id = di->deviceid;
+		strncpy(d.name, di->name, sizeof(d.name) - 1);
+		d.pressure = d.tiltX = d.tiltY = -1;
+		for (int c = 0; c < di->num_classes; c++) {
+			if (di->classes[c]->type != XIValuatorClass) {
+				continue;
+			}
+			XIValuatorClassInfo* vi = (XIValuatorClassInfo*)di->classes[c];
+			if (vi->number >= maxStylusValuators) {
+				continue;
+			}
+			if (vi->label == press) {
+				d.pressure = vi->number;
+				d.pressMin = vi->min;
+				d.pressMax = vi->max;
+			} else if (vi->label == tiltX) {
+				d.tiltX = vi->number;
+				d.tiltXMin = vi->min;
+				d.tiltXMax = vi->max;
+			} else if (vi->label == tiltY) {
+				d.tiltY = vi->number;
+				d.tiltYMin = vi->min;
+				d.tiltYMax = vi->max;
+			}
+		}
+		if (d.pressure >= 0) {
+			devs[nd++] = d;
+		}
+	}
+	XIFreeDeviceInfo(info);
+	return nd;
+}
+
+// stylusSelect selects the events of given pen devices on given window
+static void stylusSelect(Display* dpy, Window win, stylusDevice* devs, int n) {
+	if (n == 0) {
+		return;
+	}
+	unsigned char bits[XIMaskLen(XI_LASTEVENT)];
+	memset(bits, 0, sizeof(bits));
+	XISetMask(bits, XI_ButtonPress);
+	XISetMask(bits, XI_ButtonRelease);
+	XISetMask(bits, XI_Motion);
+	XIEventMask masks[maxStylusDevices];
+	for (int i = 0; i < n; i++) {
+		masks[i].deviceid = devs[i].id;
+		masks[i].mask_len = sizeof(bits);
+		masks[i].mask = bits;
+	}
+	XISelectEvents(dpy, win, masks, n);
+	XFlush(dpy);
+}
+
+// stylusNext waits up to given milliseconds for the next event, returning
+// 1 for a pen event, which is decoded into se, 2 for a change of devices,
+// and 0 otherwise
+static int stylusNext(Display* dpy, int ms, stylusEvent* se) {
+	if (XPending(dpy) == 0) {
+		struct pollfd pfd = {ConnectionNumber(dpy), POLLIN, 0};
+		if (poll(&pfd, 1, ms) <= 0 || XPending(dpy) == 0) {
+			return 0;
+		}
+	}
+	XEvent ev;
+	XNextEvent(dpy, &ev);
+	XGenericEventCookie* cookie = &ev.xcookie;
+	if (cookie->type != GenericEvent || cookie->extension != stylusOpcode || !XGetEventData(dpy, cookie)) {
+		return 0;
+	}
+	int rc = 0;
+	switch (cookie->evtype) {
+	case XI_HierarchyChanged:
+		rc = 2;
+		break;
+	case XI_ButtonPress:
+	case XI_ButtonRelease:
+	case XI_Motion: {
+		XIDeviceEvent* de = (XIDeviceEvent*)cookie->data;
+		memset(se, 0, sizeof(*se));
+		se->device = de->deviceid;
+		se->window = de->event;
+		se->type = cookie->evtype;
+		se->button = de->detail;
+		se->x = de->event_x;
+		se->y = de->event_y;
+		for (int b = 1; b <= 3 && b < de->buttons.mask_len * 8; b++) {
+			if (XIMaskIsSet(de->buttons.mask, b)) {
+				se->buttons |= 1 << b;
+			}
+		}
+		se->mods = de->mods.effective;
+		double* vals = de->valuators.values;
+		for (int i = 0; i < de->valuators.mask_len * 8; i++) {
+			if (!XIMaskIsSet(de->valuators.mask, i)) {
+				continue;
+			}
+			if (i < maxStylusValuators) {
+				se->hasVal[i] = 1;
+				se->val[i] = *vals;
+			}
+			vals++;
+		}
+		rc = 1;
+		break;
+	}
+	}
+	XFreeEventData(dpy, cookie);
+	return rc;
+}
+*/
+import "C"
+
+import (
+	"image"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/stylus"
+)
+
+// GLFW does not report pen pressure or tilt, so pen events are read from a
+// separate connection to the X server, selecting the XInput2 events of the
+// pen devices on each window, by a goroutine that sends stylus events --
+// the core mouse events of the pen still go to GLFW
+
+// stylusX is the state of the pen connection
+var stylusX struct {
+	mu      sync.Mutex
+	started bool
+	failed  bool       // XInput2 is not available
+	pending []C.Window // windows to select pen events on
+}
+
+func (w *windowImpl) initStylus() {
+	win := C.Window(w.OSHandle())
+	stylusX.mu.Lock()
+	defer stylusX.mu.Unlock()
+	if stylusX.failed {
+		return
+	}
+	stylusX.pending = append(stylusX.pending, win)
+	if !stylusX.started {
+		stylusX.started = true
+		go stylusLoop()
+	}
+}
+
+// stylusPen is the state of a pen device: the last values of its
+// valuators, which events only have when they change
+type stylusPen struct {
+	dev    C.stylusDevice
+	eraser bool
+	vals   [C.maxStylusValuators]float64
+}
+
+// stylusDevs returns the current pen devices, keeping the state of
+// existing ones
+func stylusDevs(dpy *C.Display, old map[int]*stylusPen) map[int]*stylusPen {
+	var devs [C.maxStylusDevices]C.stylusDevice
+	n := int(C.stylusDevices(dpy, &devs[0]))
+	pens := make(map[int]*stylusPen, n)
+	for _, d := range devs[:n] {
+		id := int(d.id)
+		if p, has := old[id]; has {
+			p.dev = d
+			pens[id] = p
+			continue
+		}
+		nm := strings.ToLower(C.GoString(&d.name[0]))
+		pens[id] = &stylusPen{dev: d, eraser: strings.Contains(nm, "eraser")}
+	}
+	return pens
+}
+
+// stylusSelect selects the events of given pens on given window
+func stylusSelect(dpy *C.Display, win C.Window, pens map[int]*stylusPen) {
+	var devs [C.maxStylusDevices]C.stylusDevice
+	n := 0
+	for _, p := range pens {
+		devs[n] = p.dev
+		n++
+	}
+	C.stylusSelect(dpy, win, &devs[0], C.int(n))
+}
+
+// stylusLoop reads the pen connection, which is only used by it
+func stylusLoop() {
+	runtime.LockOSThread()
+	dpy := C.XOpenDisplay(nil)
+	if dpy == nil || C.stylusInit(dpy) == 0 {
+		if dpy != nil {
+			C.XCloseDisplay(dpy)
+		}
+		stylusX.mu.Lock()
+		stylusX.failed = true
+		stylusX.pending = nil
+		stylusX.mu.Unlock()
+		return
+	}
+	pens := stylusDevs(dpy, nil)
+	var wins []C.Window
+	se := C.stylusEvent{}
+	for {
+		stylusX.mu.Lock()
+		pend := stylusX.pending
+		stylusX.pending = nil
+		stylusX.mu.Unlock()
+		for _, win := range pend {
+			wins = append(wins, win)
+			stylusSelect(dpy, win, pens)
+		}
+		switch C.stylusNext(dpy, 100, &se) {
+		case 1:
+			if p, has := pens[int(se.device)]; has {
+				p.send(&se)
+			}
+		case 2:
+			pens = stylusDevs(dpy, pens)
+			for _, win := range wins {
+				stylusSelect(dpy, win, pens)
+			}
+		}
+	}
+}
+
+// stylusValue returns given value normalized from the range of min to max
+// to the range of lo to hi
+func stylusValue(v, min, max float64, lo, hi float32) float32 {
+	if max <= min {
+		return lo
+	}
+	return lo + (hi-lo)*float32((v-min)/(max-min))
+}
+
+// send sends the stylus event for given pen event to its window
+func (p *stylusPen) send(se *C.stylusEvent) {
+	theApp.mu.Lock()
+	w, ok := theApp.oswindows[uintptr(se.window)]
+	theApp.mu.Unlock()
+	if !ok || w == nil {
+		return
+	}
+	for i := range p.vals {
+		if se.hasVal[i] != 0 {
+			p.vals[i] = float64(se.val[i])
+		}
+	}
+	event := &stylus.Event{
+		Where:     image.Point{int(se.x), int(se.y)},
+		Eraser:    p.eraser,
+		Barrel:    se.buttons&(1<<2|1<<3) != 0,
+		Modifiers: stylusMods(int(se.mods)),
+	}
+	down := se.buttons&(1<<1) != 0
+	switch {
+	case se._type == C.XI_ButtonPress && se.button == 1:
+		event.Action = stylus.Press
+	case se._type == C.XI_ButtonRelease && se.button == 1:
+		event.Action = stylus.Release
+	case se._type == C.XI_Motion && down:
+		event.Action = stylus.Drag
+	case se._type == C.XI_Motion:
+		event.Action = stylus.Hover
+	default: // barrel buttons change Barrel of the next event
+		return
+	}
+	d := &p.dev
+	if event.Action == stylus.Press || event.Action == stylus.Drag {
+		event.Pressure = stylusValue(p.vals[d.pressure], float64(d.pressMin), float64(d.pressMax), 0, 1)
+	}
+	if d.tiltX >= 0 {
+		event.TiltX = stylusValue(p.vals[d.tiltX], float64(d.tiltXMin), float64(d.tiltXMax), -1, 1)
+	}
+	if d.tiltY >= 0 {
+		event.TiltY = stylusValue(p.vals[d.tiltY], float64(d.tiltYMin), float64(d.tiltYMax), -1, 1)
+	}
+	event.Init()
+	w.Send(event)
+}
+
+// stylusMods returns the key.Modifiers bits for X modifier state
+func stylusMods(xm int) int32 {
+	m := int32(0)
+	if xm&C.ShiftMask != 0 {
+		m |= 1 << uint32(key.Shift)
+	}
+	if xm&C.ControlMask != 0 {
+		m |= 1 << uint32(key.Control)
+	}
+	if xm&C.Mod1Mask != 0 {
+		m |= 1 << uint32(key.Alt)
+	}
+	if xm&C.Mod4Mask != 0 {
+		m |= 1 << uint32(key.Meta)
+	}
+	return m
+}
@@ -0,0 +1,48 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package glos
+
+import (
+	"time"
+
+	"github.com/goki/gi/oswin/window"
+)
+
+// ThemePollInterval is how often the OS appearance is checked for changes
+// between light and dark, on platforms without notification of changes
+var ThemePollInterval = 2 * time.Second
+
+func (app *appImpl) IsDark() bool {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	return app.dark
+}
+
+// setDark records whether the OS appearance is dark, sending a
+// window.ThemeChanged event to the first window if it has changed
+func (app *appImpl) setDark(dark bool) {
+	app.mu.Lock()
+	if dark == app.dark {
+		app.mu.Unlock()
+		return
+	}
+	app.dark = dark
+	if len(app.winlist) == 0 {
+		app.mu.Unlock()
+		return
+	}
+	fw := app.winlist[0]
+	app.mu.Unlock()
+	fw.sendWindowEvent(window.ThemeChanged)
+}
+
+// pollTheme checks the OS appearance every ThemePollInterval, for the life
+// of the app
+func (app *appImpl) pollTheme() {
+	for {
+		time.Sleep(ThemePollInterval)
+		app.setDark(osIsDark())
+	}
+}
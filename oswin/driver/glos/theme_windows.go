@@ -0,0 +1,36 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package glos
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// osIsDark returns true if apps are set to use the dark theme, in the
+// personalization settings
+func osIsDark() bool {
+	path, _ := syscall.UTF16PtrFromString(`Software\Microsoft\Windows\CurrentVersion\Themes\Personalize`)
+	var k syscall.Handle
+	if err := syscall.RegOpenKeyEx(syscall.HKEY_CURRENT_USER, path, 0, syscall.KEY_READ, &k); err != nil {
+		return false
+	}
+	defer syscall.RegCloseKey(k)
+	name, _ := syscall.UTF16PtrFromString("AppsUseLightTheme")
+	var typ, val uint32
+	n := uint32(unsafe.Sizeof(val))
+	if err := syscall.RegQueryValueEx(k, name, nil, &typ, (*byte)(unsafe.Pointer(&val)), &n); err != nil || typ != syscall.REG_DWORD {
+		return false
+	}
+	return val == 0
+}
+
+// watchTheme polls for changes in the theme -- registry change notification
+// would need a dedicated thread for little benefit
+func (app *appImpl) watchTheme() {
+	app.pollTheme()
+}
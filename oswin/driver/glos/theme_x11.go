@@ -0,0 +1,60 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux,!android dragonfly openbsd
+
+package glos
+
+import (
+	"bufio"
+	"os/exec"
+	"strings"
+)
+
+// the appearance is read from the GNOME desktop interface settings, which
+// are also followed by most other GTK-based desktops, using the gsettings
+// command, if installed
+
+// themeSchema is the gsettings schema with the appearance settings
+const themeSchema = "org.gnome.desktop.interface"
+
+// osIsDark returns true if the color-scheme setting prefers dark, or the
+// GTK theme name contains "dark" (e.g., Adwaita-dark)
+func osIsDark() bool {
+	gs, err := exec.LookPath("gsettings")
+	if err != nil {
+		return false
+	}
+	out, err := exec.Command(gs, "get", themeSchema, "color-scheme").Output()
+	if err == nil && strings.Contains(string(out), "dark") {
+		return true
+	}
+	out, err = exec.Command(gs, "get", themeSchema, "gtk-theme").Output()
+	return err == nil && strings.Contains(strings.ToLower(string(out)), "dark")
+}
+
+// watchTheme is notified of changes in the appearance settings by running
+// gsettings monitor, which outputs a "key: value" line for each change
+func (app *appImpl) watchTheme() {
+	gs, err := exec.LookPath("gsettings")
+	if err != nil {
+		return
+	}
+	cmd := exec.Command(gs, "monitor", themeSchema)
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return
+	}
+	if err := cmd.Start(); err != nil {
+		return
+	}
+	sc := bufio.NewScanner(out)
+	for sc.Scan() {
+		ln := sc.Text()
+		if strings.HasPrefix(ln, "color-scheme:") || strings.HasPrefix(ln, "gtk-theme:") {
+			app.setDark(osIsDark())
+		}
+	}
+	cmd.Wait()
+}
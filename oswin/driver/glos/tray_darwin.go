@@ -0,0 +1,200 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package glos
+
+/*
+#cgo CFLAGS: -x objective-c -Wno-deprecated-declarations
+#cgo LDFLAGS: -framework Cocoa
+#include <stdlib.h>
+#include <stdint.h>
+uintptr_t trayNew(uintptr_t trayID);
+void traySetIcon(uintptr_t ttID, void* pix, int w, int h);
+void traySetTooltip(uintptr_t ttID, char* tip);
+void traySetMenu(uintptr_t ttID, char** labels, int* flags, int n);
+void trayClose(uintptr_t ttID);
+void dockSetBadge(char* badge);
+void dockSetMenu(char** labels, int* flags, int n);
+*/
+import "C"
+
+import (
+	"image"
+	"image/draw"
+	"sync"
+	"unsafe"
+
+	"github.com/goki/gi/oswin"
+)
+
+// tray icons are status items of the menu bar, and the dock menu is
+// returned by a method added to the app delegate -- see tray_darwin.m
+
+var (
+	trayMu     sync.Mutex
+	trayIcons  = map[uintptr]*trayIconImpl{} // by id
+	trayLastID uintptr
+	dockFunc   func(item int)
+)
+
+func (app *appImpl) NewTrayIcon(icon image.Image, tooltip string) (oswin.TrayIcon, error) {
+	trayMu.Lock()
+	trayLastID++
+	ti := &trayIconImpl{app: app, id: trayLastID}
+	trayIcons[ti.id] = ti
+	trayMu.Unlock()
+	app.RunOnMain(func() {
+		ti.tt = C.trayNew(C.uintptr_t(ti.id))
+	})
+	ti.SetIcon(icon)
+	ti.SetTooltip(tooltip)
+	return ti, nil
+}
+
+// trayIconImpl is a status item, accessed on main by its target
+type trayIconImpl struct {
+	app    *appImpl
+	id     uintptr
+	tt     C.uintptr_t
+	evFunc func(ti oswin.TrayIcon, ev oswin.TrayEvents, item int)
+	closed bool
+}
+
+//export trayFired
+func trayFired(id uintptr, tag C.int) {
+	trayMu.Lock()
+	ti := trayIcons[id]
+	var fun func(ti oswin.TrayIcon, ev oswin.TrayEvents, item int)
+	if ti != nil {
+		fun = ti.evFunc
+	}
+	trayMu.Unlock()
+	if fun == nil {
+		return
+	}
+	if tag < 0 {
+		fun(ti, oswin.TrayClicked, -1)
+	} else {
+		fun(ti, oswin.TrayMenuSelected, int(tag))
+	}
+}
+
+//export dockFired
+func dockFired(tag C.int) {
+	trayMu.Lock()
+	fun := dockFunc
+	trayMu.Unlock()
+	if fun != nil {
+		fun(int(tag))
+	}
+}
+
+// trayMenuC returns the labels and flags of the items as C arrays, and the
+// function to free them
+func trayMenuC(items []oswin.TrayMenuItem) (**C.char, *C.int, func()) {
+	n := len(items)
+	if n == 0 {
+		return nil, nil, func() {}
+	}
+	lbls := (*[1 << 20]*C.char)(C.malloc(C.size_t(n) * C.size_t(unsafe.Sizeof((*C.char)(nil)))))[:n:n]
+	flags := (*[1 << 20]C.int)(C.malloc(C.size_t(n) * C.size_t(unsafe.Sizeof(C.int(0)))))[:n:n]
+	for i, it := range items {
+		lbls[i] = C.CString(it.Label)
+		flags[i] = 0
+		if it.Inactive {
+			flags[i] |= 1
+		}
+		if it.Label == "" {
+			flags[i] |= 2
+		}
+	}
+	free := func() {
+		for _, l := range lbls {
+			C.free(unsafe.Pointer(l))
+		}
+		C.free(unsafe.Pointer(&lbls[0]))
+		C.free(unsafe.Pointer(&flags[0]))
+	}
+	return &lbls[0], &flags[0], free
+}
+
+// isClosed returns whether the icon is closed -- only called on main
+func (ti *trayIconImpl) isClosed() bool {
+	return ti.closed || ti.tt == 0
+}
+
+func (ti *trayIconImpl) SetIcon(icon image.Image) {
+	b := icon.Bounds()
+	rgba := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), icon, b.Min, draw.Src)
+	if len(rgba.Pix) == 0 {
+		return
+	}
+	ti.app.RunOnMain(func() {
+		if ti.isClosed() {
+			return
+		}
+		C.traySetIcon(ti.tt, unsafe.Pointer(&rgba.Pix[0]), C.int(b.Dx()), C.int(b.Dy()))
+	})
+}
+
+func (ti *trayIconImpl) SetTooltip(tip string) {
+	ctip := C.CString(tip)
+	defer C.free(unsafe.Pointer(ctip))
+	ti.app.RunOnMain(func() {
+		if ti.isClosed() {
+			return
+		}
+		C.traySetTooltip(ti.tt, ctip)
+	})
+}
+
+func (ti *trayIconImpl) SetMenu(items []oswin.TrayMenuItem) {
+	lbls, flags, free := trayMenuC(items)
+	defer free()
+	ti.app.RunOnMain(func() {
+		if ti.isClosed() {
+			return
+		}
+		C.traySetMenu(ti.tt, lbls, flags, C.int(len(items)))
+	})
+}
+
+func (ti *trayIconImpl) SetEventFunc(fun func(ti oswin.TrayIcon, ev oswin.TrayEvents, item int)) {
+	trayMu.Lock()
+	ti.evFunc = fun
+	trayMu.Unlock()
+}
+
+func (ti *trayIconImpl) Close() {
+	trayMu.Lock()
+	delete(trayIcons, ti.id)
+	trayMu.Unlock()
+	ti.app.RunOnMain(func() {
+		if ti.isClosed() {
+			return
+		}
+		ti.closed = true
+		C.trayClose(ti.tt)
+	})
+}
+
+func (app *appImpl) SetDockBadge(badge string) {
+	cbadge := C.CString(badge)
+	defer C.free(unsafe.Pointer(cbadge))
+	app.RunOnMain(func() {
+		C.dockSetBadge(cbadge)
+	})
+}
+
+func (app *appImpl) SetDockMenu(items []oswin.TrayMenuItem, fun func(item int)) {
+	trayMu.Lock()
+	dockFunc = fun
+	trayMu.Unlock()
+	lbls, flags, free := trayMenuC(items)
+	defer free()
+	app.RunOnMain(func() {
+		C.dockSetMenu(lbls, flags, C.int(len(items)))
+	})
+}
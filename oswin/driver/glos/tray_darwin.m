@@ -0,0 +1,149 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build darwin
+
+#include "_cgo_export.h"
+
+#import <Cocoa/Cocoa.h>
+#import <objc/runtime.h>
+
+///////////////////////////////////////////////////////////////////////
+//   Status items (system tray)
+
+// TrayTarget is the target of the button and menu items of a status item,
+// which forwards clicks to Go with the id of the tray icon, and the tag of
+// the menu item, or -1 for the button
+@interface TrayTarget : NSObject
+@property uintptr_t trayID;
+@property (retain) NSStatusItem* item;
+- (void)clicked:(id)sender;
+- (void)itemFired:(id)sender;
+@end
+
+@implementation TrayTarget
+- (void)clicked:(id)sender {
+    trayFired(self.trayID, -1);
+}
+- (void)itemFired:(id)sender {
+    trayFired(self.trayID, (int)[sender tag]);
+}
+@end
+
+// trayImage returns a new image from non-premultiplied RGBA pixels, with
+// the size of the menu bar icons
+NSImage* trayImage(void* pix, int w, int h) {
+    NSBitmapImageRep* rep = [[NSBitmapImageRep alloc] initWithBitmapDataPlanes:NULL pixelsWide:w pixelsHigh:h bitsPerSample:8 samplesPerPixel:4 hasAlpha:YES isPlanar:NO colorSpaceName:NSDeviceRGBColorSpace bitmapFormat:NSBitmapFormatAlphaNonpremultiplied bytesPerRow:w*4 bitsPerPixel:32];
+    memcpy([rep bitmapData], pix, w*h*4);
+    CGFloat sz = [[NSStatusBar systemStatusBar] thickness] - 4;
+    NSImage* img = [[NSImage alloc] initWithSize:NSMakeSize(sz, sz)];
+    [img addRepresentation:rep];
+    [rep release];
+    return img;
+}
+
+// trayMenu returns a new menu with given items, whose flags are 1 for
+// inactive and 2 for separator, firing on given target
+NSMenu* trayMenu(id target, char** labels, int* flags, int n) {
+    NSMenu* men = [[NSMenu alloc] init];
+    [men setAutoenablesItems:NO];
+    for (int i = 0; i < n; i++) {
+        if (flags[i] & 2) {
+            [men addItem:[NSMenuItem separatorItem]];
+            continue;
+        }
+        NSString* title = [[NSString alloc] initWithUTF8String:labels[i]];
+        NSMenuItem* mi = [men addItemWithTitle:title action:@selector(itemFired:) keyEquivalent:@""];
+        mi.target = target;
+        mi.tag = i;
+        mi.enabled = !(flags[i] & 1);
+        [title release];
+    }
+    return men;
+}
+
+uintptr_t trayNew(uintptr_t trayID) {
+    NSStatusItem* item = [[NSStatusBar systemStatusBar] statusItemWithLength:NSSquareStatusItemLength];
+    TrayTarget* tt = [[TrayTarget alloc] init];
+    tt.trayID = trayID;
+    tt.item = item;
+    item.button.target = tt;
+    item.button.action = @selector(clicked:);
+    return (uintptr_t)tt;
+}
+
+void traySetIcon(uintptr_t ttID, void* pix, int w, int h) {
+    TrayTarget* tt = (TrayTarget*)ttID;
+    NSImage* img = trayImage(pix, w, h);
+    tt.item.button.image = img;
+    [img release];
+}
+
+void traySetTooltip(uintptr_t ttID, char* tip) {
+    TrayTarget* tt = (TrayTarget*)ttID;
+    NSString* str = [[NSString alloc] initWithUTF8String:tip];
+    tt.item.button.toolTip = str;
+    [str release];
+}
+
+void traySetMenu(uintptr_t ttID, char** labels, int* flags, int n) {
+    TrayTarget* tt = (TrayTarget*)ttID;
+    if (n == 0) {
+        tt.item.menu = nil;
+        return;
+    }
+    NSMenu* men = trayMenu(tt, labels, flags, n);
+    tt.item.menu = men;
+    [men release];
+}
+
+void trayClose(uintptr_t ttID) {
+    TrayTarget* tt = (TrayTarget*)ttID;
+    [[NSStatusBar systemStatusBar] removeStatusItem:tt.item];
+    tt.item = nil;
+    [tt release];
+}
+
+///////////////////////////////////////////////////////////////////////
+//   Dock
+
+void dockSetBadge(char* badge) {
+    NSString* str = [[NSString alloc] initWithUTF8String:badge];
+    [[NSApp dockTile] setBadgeLabel:([str length] == 0 ? nil : str)];
+    [str release];
+}
+
+// DockTarget is the target of the items of the dock menu
+@interface DockTarget : NSObject
+- (void)itemFired:(id)sender;
+@end
+
+@implementation DockTarget
+- (void)itemFired:(id)sender {
+    dockFired((int)[sender tag]);
+}
+@end
+
+static NSMenu* dockMenu = nil;
+static DockTarget* dockTarget = nil;
+
+// dockMenuImpl is added as the applicationDockMenu: method of the app
+// delegate (of glfw), which NSApp asks for the dock menu
+static NSMenu* dockMenuImpl(id self, SEL cmd, NSApplication* sender) {
+    return dockMenu;
+}
+
+void dockSetMenu(char** labels, int* flags, int n) {
+    if (dockTarget == nil) {
+        dockTarget = [[DockTarget alloc] init];
+        class_addMethod([[NSApp delegate] class], @selector(applicationDockMenu:), (IMP)dockMenuImpl, "@@:@");
+    }
+    if (dockMenu != nil) {
+        [dockMenu release];
+        dockMenu = nil;
+    }
+    if (n > 0) {
+        dockMenu = trayMenu(dockTarget, labels, flags, n);
+    }
+}
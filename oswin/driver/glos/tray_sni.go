@@ -0,0 +1,463 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux,!android dragonfly openbsd
+
+package glos
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/driver/internal/dbus"
+)
+
+// tray icons are StatusNotifierItems
+// (https://www.freedesktop.org/wiki/Specifications/StatusNotifierItem/),
+// exported on the session bus and registered with the
+// StatusNotifierWatcher of the desktop (KDE, and GNOME with the
+// AppIndicator extension), with their menus exported with the dbusmenu
+// protocol of libdbusmenu, which the hosts use to show them
+
+const (
+	sniWatcher     = "org.kde.StatusNotifierWatcher"
+	sniWatcherPath = dbus.ObjectPath("/StatusNotifierWatcher")
+	sniIface       = "org.kde.StatusNotifierItem"
+	sniPath        = dbus.ObjectPath("/StatusNotifierItem")
+	sniMenuIface   = "com.canonical.dbusmenu"
+	sniMenuPath    = dbus.ObjectPath("/MenuBar")
+	dbusProps      = "org.freedesktop.DBus.Properties"
+)
+
+// errNoSNIWatcher is returned when there is no StatusNotifierWatcher
+var errNoSNIWatcher = errors.New("glos: no StatusNotifierWatcher on the session bus")
+
+// sniCount counts the tray icons, for their unique bus names
+var sniCount int32
+
+// sniSigs are the argument signatures of the methods handled
+var sniSigs = map[string]string{
+	"Ping": "", "Introspect": "", "Get": "ss", "GetAll": "s", "Set": "ssv",
+	"Activate": "ii", "SecondaryActivate": "ii", "ContextMenu": "ii", "Scroll": "is",
+	"GetLayout": "iias", "GetGroupProperties": "aias", "GetProperty": "is",
+	"Event": "isvu", "EventGroup": "a(isvu)", "AboutToShow": "i", "AboutToShowGroup": "ai",
+}
+
+// newTraySNI returns a new StatusNotifierItem tray icon, or an error if
+// there is no session bus or StatusNotifierWatcher
+func newTraySNI(icon image.Image, tooltip string) (*traySNIImpl, error) {
+	ti := &traySNIImpl{tooltip: tooltip, pixmap: sniPixmap(icon)}
+	ti.id = filepath.Base(os.Args[0])
+	if oswin.TheApp != nil && oswin.TheApp.Name() != "" {
+		ti.id = oswin.TheApp.Name()
+	}
+	conn, err := dbus.SessionBus(ti.handle)
+	if err != nil {
+		return nil, err
+	}
+	ti.conn = conn
+	if !conn.NameHasOwner(sniWatcher) {
+		conn.Close()
+		return nil, errNoSNIWatcher
+	}
+	ti.name = fmt.Sprintf("org.kde.StatusNotifierItem-%d-%d", os.Getpid(), atomic.AddInt32(&sniCount, 1))
+	if err := conn.RequestName(ti.name); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	// register again when the watcher is restarted, e.g., with the panel
+	conn.AddMatch("type='signal',sender='org.freedesktop.DBus',interface='org.freedesktop.DBus',member='NameOwnerChanged',arg0='" + sniWatcher + "'")
+	if err := ti.register(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return ti, nil
+}
+
+// traySNIImpl is a StatusNotifierItem
+type traySNIImpl struct {
+	conn    *dbus.Conn
+	name    string
+	id      string
+	mu      sync.Mutex
+	tooltip string
+	pixmap  []interface{}
+	menu    []oswin.TrayMenuItem
+	menuRev uint32
+	evFunc  func(ti oswin.TrayIcon, ev oswin.TrayEvents, item int)
+}
+
+// register registers the item with the watcher
+func (ti *traySNIImpl) register() error {
+	_, err := ti.conn.Call(sniWatcher, sniWatcherPath, sniWatcher, "RegisterStatusNotifierItem", "s", ti.name)
+	return err
+}
+
+// sniPixmap returns the a(iiay) pixmap of the icon: its width, height, and
+// its pixels in non-premultiplied ARGB, in network byte order
+func sniPixmap(icon image.Image) []interface{} {
+	if icon == nil {
+		return []interface{}{}
+	}
+	bnd := icon.Bounds()
+	img := image.NewNRGBA(image.Rect(0, 0, bnd.Dx(), bnd.Dy()))
+	draw.Draw(img, img.Bounds(), icon, bnd.Min, draw.Src)
+	pix := make([]byte, len(img.Pix))
+	for i := 0; i < len(pix); i += 4 {
+		pix[i] = img.Pix[i+3]
+		pix[i+1] = img.Pix[i]
+		pix[i+2] = img.Pix[i+1]
+		pix[i+3] = img.Pix[i+2]
+	}
+	return []interface{}{dbus.Struct{int32(bnd.Dx()), int32(bnd.Dy()), pix}}
+}
+
+// props returns the properties of the item, for Properties.Get and GetAll
+func (ti *traySNIImpl) props() map[string]dbus.Variant {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	empty := dbus.Variant{Sig: "a(iiay)", Value: []interface{}{}}
+	return map[string]dbus.Variant{
+		"Category":            dbus.MakeVariant("ApplicationStatus"),
+		"Id":                  dbus.MakeVariant(ti.id),
+		"Title":               dbus.MakeVariant(ti.id),
+		"Status":              dbus.MakeVariant("Active"),
+		"WindowId":            dbus.MakeVariant(int32(0)),
+		"IconName":            dbus.MakeVariant(""),
+		"IconPixmap":          dbus.Variant{Sig: "a(iiay)", Value: ti.pixmap},
+		"OverlayIconName":     dbus.MakeVariant(""),
+		"OverlayIconPixmap":   empty,
+		"AttentionIconName":   dbus.MakeVariant(""),
+		"AttentionIconPixmap": empty,
+		"AttentionMovieName":  dbus.MakeVariant(""),
+		"ToolTip":             dbus.Variant{Sig: "(sa(iiay)ss)", Value: dbus.Struct{"", []interface{}{}, ti.tooltip, ""}},
+		"ItemIsMenu":          dbus.MakeVariant(false),
+		"Menu":                dbus.MakeVariant(sniMenuPath),
+	}
+}
+
+// menuProps returns the properties of the menu object
+func (ti *traySNIImpl) menuProps() map[string]dbus.Variant {
+	return map[string]dbus.Variant{
+		"Version":       dbus.MakeVariant(uint32(3)),
+		"TextDirection": dbus.MakeVariant("ltr"),
+		"Status":        dbus.MakeVariant("normal"),
+		"IconThemePath": dbus.MakeVariant([]string{}),
+	}
+}
+
+// itemProps returns the dbusmenu properties of menu item id (0 for the
+// root, and index+1 for the items), and false if there is no such item --
+// must be called with the lock held
+func (ti *traySNIImpl) itemProps(id int32) (map[string]dbus.Variant, bool) {
+	if id == 0 {
+		return map[string]dbus.Variant{"children-display": dbus.MakeVariant("submenu")}, true
+	}
+	if id < 0 || int(id) > len(ti.menu) {
+		return nil, false
+	}
+	it := ti.menu[id-1]
+	if it.Label == "" {
+		return map[string]dbus.Variant{"type": dbus.MakeVariant("separator")}, true
+	}
+	return map[string]dbus.Variant{
+		"label":   dbus.MakeVariant(it.Label),
+		"enabled": dbus.MakeVariant(!it.Inactive),
+	}, true
+}
+
+// filterProps returns the properties with given names, or all of them if
+// there are no names
+func filterProps(props map[string]dbus.Variant, names []interface{}) map[string]dbus.Variant {
+	if len(names) == 0 {
+		return props
+	}
+	fp := make(map[string]dbus.Variant)
+	for _, n := range names {
+		if v, ok := props[n.(string)]; ok {
+			fp[n.(string)] = v
+		}
+	}
+	return fp
+}
+
+// layout returns the (ia{sv}av) layout of the menu from item id, with
+// children down to given depth (-1 for all) -- must be called with the
+// lock held
+func (ti *traySNIImpl) layout(id int32, depth int32, names []interface{}) (dbus.Struct, bool) {
+	props, ok := ti.itemProps(id)
+	if !ok {
+		return nil, false
+	}
+	kids := []interface{}{}
+	if id == 0 && depth != 0 {
+		for i := range ti.menu {
+			kl, _ := ti.layout(int32(i+1), depth-1, names)
+			kids = append(kids, dbus.Variant{Sig: "(ia{sv}av)", Value: kl})
+		}
+	}
+	return dbus.Struct{id, filterProps(props, names), kids}, true
+}
+
+// handle handles the method calls and signals received on the connection
+func (ti *traySNIImpl) handle(c *dbus.Conn, m *dbus.Message) {
+	if m.Type == dbus.TypeSignal {
+		if m.Member == "NameOwnerChanged" && len(m.Body) == 3 && m.Body[0] == sniWatcher && m.Body[2] != "" {
+			go func() {
+				if err := ti.register(); err != nil {
+					log.Printf("glos.TrayIcon: %v\n", err)
+				}
+			}()
+		}
+		return
+	}
+	if sig, ok := sniSigs[m.Member]; ok && string(m.Sig) != sig {
+		c.ReplyError(m, dbus.ErrInvalidArgs, "invalid arguments for "+m.Member+": "+string(m.Sig))
+		return
+	}
+	switch {
+	case m.Iface == "org.freedesktop.DBus.Peer":
+		switch m.Member {
+		case "Ping":
+			c.Reply(m, "")
+		default:
+			c.ReplyError(m, dbus.ErrUnknownMethod, "unknown method: "+m.Member)
+		}
+	case m.Iface == "org.freedesktop.DBus.Introspectable" && m.Member == "Introspect":
+		c.Reply(m, "s", sniIntrospect(m.Path))
+	case m.Path == sniPath:
+		ti.handleItem(c, m)
+	case m.Path == sniMenuPath:
+		ti.handleMenu(c, m)
+	default:
+		c.ReplyError(m, dbus.ErrUnknownObject, "unknown object: "+string(m.Path))
+	}
+}
+
+// handleProps handles the Properties methods for an object with given
+// interface and properties
+func handleProps(c *dbus.Conn, m *dbus.Message, iface string, props map[string]dbus.Variant) {
+	if len(m.Body) == 0 || m.Body[0] != iface {
+		c.ReplyError(m, dbus.ErrUnknownInterface, "unknown interface")
+		return
+	}
+	switch m.Member {
+	case "Get":
+		pn, _ := m.Body[1].(string)
+		if v, ok := props[pn]; ok {
+			c.Reply(m, "v", v)
+		} else {
+			c.ReplyError(m, dbus.ErrUnknownProperty, "unknown property: "+pn)
+		}
+	case "GetAll":
+		c.Reply(m, "a{sv}", props)
+	case "Set":
+		c.ReplyError(m, "org.freedesktop.DBus.Error.PropertyReadOnly", "properties are read-only")
+	default:
+		c.ReplyError(m, dbus.ErrUnknownMethod, "unknown method: "+m.Member)
+	}
+}
+
+// handleItem handles the method calls on the item
+func (ti *traySNIImpl) handleItem(c *dbus.Conn, m *dbus.Message) {
+	if m.Iface == dbusProps {
+		handleProps(c, m, sniIface, ti.props())
+		return
+	}
+	switch m.Member {
+	case "Activate":
+		c.Reply(m, "")
+		ti.sendEvent(oswin.TrayClicked, -1)
+	case "SecondaryActivate", "ContextMenu", "Scroll":
+		c.Reply(m, "") // the host shows the menu itself
+	default:
+		c.ReplyError(m, dbus.ErrUnknownMethod, "unknown method: "+m.Member)
+	}
+}
+
+// handleMenu handles the dbusmenu method calls on the menu
+func (ti *traySNIImpl) handleMenu(c *dbus.Conn, m *dbus.Message) {
+	if m.Iface == dbusProps {
+		handleProps(c, m, sniMenuIface, ti.menuProps())
+		return
+	}
+	ti.mu.Lock()
+	switch m.Member {
+	case "GetLayout":
+		id, _ := m.Body[0].(int32)
+		depth, _ := m.Body[1].(int32)
+		names, _ := m.Body[2].([]interface{})
+		lay, ok := ti.layout(id, depth, names)
+		rev := ti.menuRev
+		ti.mu.Unlock()
+		if !ok {
+			c.ReplyError(m, dbus.ErrInvalidArgs, "unknown menu item")
+			return
+		}
+		c.Reply(m, "u(ia{sv}av)", rev, lay)
+	case "GetGroupProperties":
+		ids, _ := m.Body[0].([]interface{})
+		names, _ := m.Body[1].([]interface{})
+		var its []interface{}
+		if len(ids) == 0 {
+			for i := 0; i <= len(ti.menu); i++ {
+				ids = append(ids, int32(i))
+			}
+		}
+		for _, id := range ids {
+			if props, ok := ti.itemProps(id.(int32)); ok {
+				its = append(its, dbus.Struct{id, filterProps(props, names)})
+			}
+		}
+		ti.mu.Unlock()
+		c.Reply(m, "a(ia{sv})", its)
+	case "GetProperty":
+		id, _ := m.Body[0].(int32)
+		pn, _ := m.Body[1].(string)
+		props, _ := ti.itemProps(id)
+		ti.mu.Unlock()
+		if v, ok := props[pn]; ok {
+			c.Reply(m, "v", v)
+		} else {
+			c.ReplyError(m, dbus.ErrInvalidArgs, "unknown menu item property: "+pn)
+		}
+	case "Event", "EventGroup":
+		var evs []interface{}
+		if m.Member == "Event" {
+			evs = []interface{}{dbus.Struct(m.Body)}
+		} else {
+			evs, _ = m.Body[0].([]interface{})
+		}
+		var sel []int
+		errIds := []interface{}{}
+		for _, ev := range evs {
+			st := ev.(dbus.Struct)
+			id := st[0].(int32)
+			if _, ok := ti.itemProps(id); !ok {
+				errIds = append(errIds, id)
+				continue
+			}
+			if st[1] == "clicked" && id > 0 && !ti.menu[id-1].Inactive && ti.menu[id-1].Label != "" {
+				sel = append(sel, int(id-1))
+			}
+		}
+		ti.mu.Unlock()
+		if m.Member == "Event" {
+			c.Reply(m, "")
+		} else {
+			c.Reply(m, "ai", errIds)
+		}
+		for _, idx := range sel {
+			ti.sendEvent(oswin.TrayMenuSelected, idx)
+		}
+	case "AboutToShow":
+		ti.mu.Unlock()
+		c.Reply(m, "b", false)
+	case "AboutToShowGroup":
+		ti.mu.Unlock()
+		c.Reply(m, "aiai", []interface{}{}, []interface{}{})
+	default:
+		ti.mu.Unlock()
+		c.ReplyError(m, dbus.ErrUnknownMethod, "unknown method: "+m.Member)
+	}
+}
+
+// sendEvent calls the event function
+func (ti *traySNIImpl) sendEvent(ev oswin.TrayEvents, item int) {
+	ti.mu.Lock()
+	fun := ti.evFunc
+	ti.mu.Unlock()
+	if fun != nil {
+		fun(ti, ev, item)
+	}
+}
+
+// sniIntrospect returns the introspection data of given object
+func sniIntrospect(path dbus.ObjectPath) string {
+	const hdr = `<!DOCTYPE node PUBLIC "-//freedesktop//DTD D-BUS Object Introspection 1.0//EN" "http://www.freedesktop.org/standards/dbus/1.0/introspect.dtd">
+<node>
+<interface name="org.freedesktop.DBus.Introspectable"><method name="Introspect"><arg name="data" type="s" direction="out"/></method></interface>
+<interface name="org.freedesktop.DBus.Properties">
+<method name="Get"><arg name="interface" type="s" direction="in"/><arg name="property" type="s" direction="in"/><arg name="value" type="v" direction="out"/></method>
+<method name="GetAll"><arg name="interface" type="s" direction="in"/><arg name="props" type="a{sv}" direction="out"/></method>
+</interface>
+`
+	switch path {
+	case "/":
+		return hdr + `<node name="StatusNotifierItem"/><node name="MenuBar"/></node>`
+	case sniPath:
+		return hdr + `<interface name="org.kde.StatusNotifierItem">
+<property name="Category" type="s" access="read"/><property name="Id" type="s" access="read"/>
+<property name="Title" type="s" access="read"/><property name="Status" type="s" access="read"/>
+<property name="WindowId" type="i" access="read"/><property name="IconName" type="s" access="read"/>
+<property name="IconPixmap" type="a(iiay)" access="read"/><property name="OverlayIconName" type="s" access="read"/>
+<property name="OverlayIconPixmap" type="a(iiay)" access="read"/><property name="AttentionIconName" type="s" access="read"/>
+<property name="AttentionIconPixmap" type="a(iiay)" access="read"/><property name="AttentionMovieName" type="s" access="read"/>
+<property name="ToolTip" type="(sa(iiay)ss)" access="read"/><property name="ItemIsMenu" type="b" access="read"/>
+<property name="Menu" type="o" access="read"/>
+<method name="ContextMenu"><arg name="x" type="i" direction="in"/><arg name="y" type="i" direction="in"/></method>
+<method name="Activate"><arg name="x" type="i" direction="in"/><arg name="y" type="i" direction="in"/></method>
+<method name="SecondaryActivate"><arg name="x" type="i" direction="in"/><arg name="y" type="i" direction="in"/></method>
+<method name="Scroll"><arg name="delta" type="i" direction="in"/><arg name="orientation" type="s" direction="in"/></method>
+<signal name="NewIcon"/><signal name="NewToolTip"/>
+</interface></node>`
+	case sniMenuPath:
+		return hdr + `<interface name="com.canonical.dbusmenu">
+<property name="Version" type="u" access="read"/><property name="TextDirection" type="s" access="read"/>
+<property name="Status" type="s" access="read"/><property name="IconThemePath" type="as" access="read"/>
+<method name="GetLayout"><arg type="i" name="parentId" direction="in"/><arg type="i" name="recursionDepth" direction="in"/><arg type="as" name="propertyNames" direction="in"/><arg type="u" name="revision" direction="out"/><arg type="(ia{sv}av)" name="layout" direction="out"/></method>
+<method name="GetGroupProperties"><arg type="ai" name="ids" direction="in"/><arg type="as" name="propertyNames" direction="in"/><arg type="a(ia{sv})" name="properties" direction="out"/></method>
+<method name="GetProperty"><arg type="i" name="id" direction="in"/><arg type="s" name="name" direction="in"/><arg type="v" name="value" direction="out"/></method>
+<method name="Event"><arg type="i" name="id" direction="in"/><arg type="s" name="eventId" direction="in"/><arg type="v" name="data" direction="in"/><arg type="u" name="timestamp" direction="in"/></method>
+<method name="EventGroup"><arg type="a(isvu)" name="events" direction="in"/><arg type="ai" name="idErrors" direction="out"/></method>
+<method name="AboutToShow"><arg type="i" name="id" direction="in"/><arg type="b" name="needUpdate" direction="out"/></method>
+<method name="AboutToShowGroup"><arg type="ai" name="ids" direction="in"/><arg type="ai" name="updatesNeeded" direction="out"/><arg type="ai" name="idErrors" direction="out"/></method>
+<signal name="LayoutUpdated"><arg type="u" name="revision"/><arg type="i" name="parent"/></signal>
+</interface></node>`
+	}
+	return hdr + `</node>`
+}
+
+func (ti *traySNIImpl) SetIcon(icon image.Image) {
+	pm := sniPixmap(icon)
+	ti.mu.Lock()
+	ti.pixmap = pm
+	ti.mu.Unlock()
+	ti.conn.Emit(sniPath, sniIface, "NewIcon", "")
+}
+
+func (ti *traySNIImpl) SetTooltip(tip string) {
+	ti.mu.Lock()
+	ti.tooltip = tip
+	ti.mu.Unlock()
+	ti.conn.Emit(sniPath, sniIface, "NewToolTip", "")
+}
+
+func (ti *traySNIImpl) SetMenu(items []oswin.TrayMenuItem) {
+	ti.mu.Lock()
+	ti.menu = append([]oswin.TrayMenuItem(nil), items...)
+	ti.menuRev++
+	rev := ti.menuRev
+	ti.mu.Unlock()
+	ti.conn.Emit(sniMenuPath, sniMenuIface, "LayoutUpdated", "ui", rev, int32(0))
+}
+
+func (ti *traySNIImpl) SetEventFunc(fun func(ti oswin.TrayIcon, ev oswin.TrayEvents, item int)) {
+	ti.mu.Lock()
+	ti.evFunc = fun
+	ti.mu.Unlock()
+}
+
+// Close closes the connection, which removes the item from the watcher
+func (ti *traySNIImpl) Close() {
+	ti.conn.Close()
+}
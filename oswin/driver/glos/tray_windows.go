@@ -0,0 +1,407 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build windows
+
+package glos
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"runtime"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/goki/gi/oswin"
+)
+
+// tray icons use Shell_NotifyIconW of shell32, called directly, with a
+// hidden message-only window for the notifications of each icon, whose
+// message loop runs on its own locked thread
+
+var (
+	gdi32 = syscall.NewLazyDLL("gdi32.dll")
+
+	procShellNotifyIconW   = shell32.NewProc("Shell_NotifyIconW")
+	procRegisterClassExW   = user32.NewProc("RegisterClassExW")
+	procCreateWindowExW    = user32.NewProc("CreateWindowExW")
+	procDefWindowProcW     = user32.NewProc("DefWindowProcW")
+	procGetMessageW        = user32.NewProc("GetMessageW")
+	procTranslateMessage   = user32.NewProc("TranslateMessage")
+	procDispatchMessageW   = user32.NewProc("DispatchMessageW")
+	procPostMessageW       = user32.NewProc("PostMessageW")
+	procPostQuitMessage    = user32.NewProc("PostQuitMessage")
+	procCreatePopupMenu    = user32.NewProc("CreatePopupMenu")
+	procAppendMenuW        = user32.NewProc("AppendMenuW")
+	procDestroyMenu        = user32.NewProc("DestroyMenu")
+	procTrackPopupMenu     = user32.NewProc("TrackPopupMenu")
+	procGetCursorPos       = user32.NewProc("GetCursorPos")
+	procSetForegroundWin   = user32.NewProc("SetForegroundWindow")
+	procCreateIconIndirect = user32.NewProc("CreateIconIndirect")
+	procDestroyIcon        = user32.NewProc("DestroyIcon")
+	procGetModuleHandleW   = kernel32.NewProc("GetModuleHandleW")
+	procCreateDIBSection   = gdi32.NewProc("CreateDIBSection")
+	procCreateBitmap       = gdi32.NewProc("CreateBitmap")
+	procDeleteObject       = gdi32.NewProc("DeleteObject")
+)
+
+const (
+	nimAdd       = 0
+	nimModify    = 1
+	nimDelete    = 2
+	nifMessage   = 0x1
+	nifIcon      = 0x2
+	nifTip       = 0x4
+	wmNull       = 0x0
+	wmDestroy    = 0x2
+	wmClose      = 0x10
+	wmLButtonUp  = 0x202
+	wmRButtonUp  = 0x205
+	wmTrayNotify = 0x8000 + 1  // WM_APP + 1
+	hwndMessage  = ^uintptr(2) // (HWND)-3
+	mfGrayed     = 0x1
+	mfSeparator  = 0x800
+	tpmRightBtn  = 0x2
+	tpmNoNotify  = 0x80
+	tpmReturnCmd = 0x100
+)
+
+type notifyIconData struct {
+	Size            uint32
+	Wnd             uintptr
+	ID              uint32
+	Flags           uint32
+	CallbackMessage uint32
+	Icon            uintptr
+	Tip             [128]uint16
+	State           uint32
+	StateMask       uint32
+	Info            [256]uint16
+	TimeoutVersion  uint32
+	InfoTitle       [64]uint16
+	InfoFlags       uint32
+	GUIDItem        winGUID
+	BalloonIcon     uintptr
+}
+
+type wndClassEx struct {
+	Size       uint32
+	Style      uint32
+	WndProc    uintptr
+	ClsExtra   int32
+	WndExtra   int32
+	Instance   uintptr
+	Icon       uintptr
+	Cursor     uintptr
+	Background uintptr
+	MenuName   *uint16
+	ClassName  *uint16
+	IconSm     uintptr
+}
+
+type winMsg struct {
+	Wnd     uintptr
+	Message uint32
+	WParam  uintptr
+	LParam  uintptr
+	Time    uint32
+	Pt      winPoint
+	Private uint32
+}
+
+type winPoint struct {
+	X, Y int32
+}
+
+type iconInfo struct {
+	Icon  int32
+	XHot  uint32
+	YHot  uint32
+	Mask  uintptr
+	Color uintptr
+}
+
+type bitmapInfoHeader struct {
+	Size          uint32
+	Width         int32
+	Height        int32
+	Planes        uint16
+	BitCount      uint16
+	Compression   uint32
+	SizeImage     uint32
+	XPelsPerMeter int32
+	YPelsPerMeter int32
+	ClrUsed       uint32
+	ClrImportant  uint32
+}
+
+var (
+	trayClassOnce sync.Once
+	trayClassName *uint16
+	trayClassErr  error
+	trayMu        sync.Mutex
+	trayIcons     = map[uintptr]*trayIconImpl{} // by window handle
+)
+
+// trayRegisterClass registers the window class of the tray windows
+func trayRegisterClass() error {
+	trayClassOnce.Do(func() {
+		trayClassName, _ = syscall.UTF16PtrFromString("GoKiTrayIcon")
+		inst, _, _ := procGetModuleHandleW.Call(0)
+		wc := wndClassEx{WndProc: syscall.NewCallback(trayWndProc), Instance: inst, ClassName: trayClassName}
+		wc.Size = uint32(unsafe.Sizeof(wc))
+		if r, _, err := procRegisterClassExW.Call(uintptr(unsafe.Pointer(&wc))); r == 0 {
+			trayClassErr = fmt.Errorf("glos: could not register tray window class: %v", err)
+		}
+	})
+	return trayClassErr
+}
+
+// trayWndProc is the window procedure of the tray windows
+func trayWndProc(hwnd, msg, wparam, lparam uintptr) uintptr {
+	switch msg {
+	case wmTrayNotify:
+		trayMu.Lock()
+		ti := trayIcons[hwnd]
+		trayMu.Unlock()
+		if ti == nil {
+			return 0
+		}
+		switch lparam & 0xFFFF {
+		case wmLButtonUp:
+			ti.sendEvent(oswin.TrayClicked, -1)
+		case wmRButtonUp:
+			ti.showMenu()
+		}
+		return 0
+	case wmDestroy:
+		procPostQuitMessage.Call(0)
+		return 0
+	}
+	r, _, _ := procDefWindowProcW.Call(hwnd, msg, wparam, lparam)
+	return r
+}
+
+func (app *appImpl) NewTrayIcon(icon image.Image, tooltip string) (oswin.TrayIcon, error) {
+	if err := trayRegisterClass(); err != nil {
+		return nil, err
+	}
+	hicon, err := winIcon(icon)
+	if err != nil {
+		return nil, err
+	}
+	ti := &trayIconImpl{done: make(chan struct{})}
+	ti.nid.Size = uint32(unsafe.Sizeof(ti.nid))
+	ti.nid.Flags = nifMessage | nifIcon | nifTip
+	ti.nid.CallbackMessage = wmTrayNotify
+	ti.nid.Icon = hicon
+	ti.setTip(tooltip)
+	errc := make(chan error)
+	go ti.run(errc)
+	if err := <-errc; err != nil {
+		procDestroyIcon.Call(hicon)
+		return nil, err
+	}
+	return ti, nil
+}
+
+// trayIconImpl is a notification icon with its message window
+type trayIconImpl struct {
+	mu     sync.Mutex
+	hwnd   uintptr
+	nid    notifyIconData
+	items  []oswin.TrayMenuItem
+	evFunc func(ti oswin.TrayIcon, ev oswin.TrayEvents, item int)
+	closed bool
+	done   chan struct{}
+}
+
+// run creates the window and icon, and runs the message loop of the
+// window on this locked thread until the window is destroyed
+func (ti *trayIconImpl) run(errc chan error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	defer close(ti.done)
+	inst, _, _ := procGetModuleHandleW.Call(0)
+	hwnd, _, err := procCreateWindowExW.Call(0, uintptr(unsafe.Pointer(trayClassName)), 0, 0, 0, 0, 0, 0, hwndMessage, 0, inst, 0)
+	if hwnd == 0 {
+		errc <- fmt.Errorf("glos: could not create tray window: %v", err)
+		return
+	}
+	ti.mu.Lock()
+	ti.hwnd = hwnd
+	ti.nid.Wnd = hwnd
+	r, _, _ := procShellNotifyIconW.Call(nimAdd, uintptr(unsafe.Pointer(&ti.nid)))
+	ti.mu.Unlock()
+	if r == 0 {
+		procPostMessageW.Call(hwnd, wmClose, 0, 0)
+		errc <- errors.New("glos: could not add the system tray icon")
+	} else {
+		trayMu.Lock()
+		trayIcons[hwnd] = ti
+		trayMu.Unlock()
+		errc <- nil
+	}
+	var msg winMsg
+	for {
+		r, _, _ := procGetMessageW.Call(uintptr(unsafe.Pointer(&msg)), 0, 0, 0)
+		if r == 0 || int32(r) == -1 {
+			break
+		}
+		procTranslateMessage.Call(uintptr(unsafe.Pointer(&msg)))
+		procDispatchMessageW.Call(uintptr(unsafe.Pointer(&msg)))
+	}
+	trayMu.Lock()
+	delete(trayIcons, hwnd)
+	trayMu.Unlock()
+}
+
+// setTip sets the tooltip in the icon data, truncated to fit
+func (ti *trayIconImpl) setTip(tip string) {
+	u, _ := syscall.UTF16FromString(tip)
+	if len(u) > len(ti.nid.Tip) {
+		u = u[:len(ti.nid.Tip)-1]
+		u = append(u, 0)
+	}
+	ti.nid.Tip = [128]uint16{}
+	copy(ti.nid.Tip[:], u)
+}
+
+// modify updates the icon from the icon data -- must be called under mutex
+func (ti *trayIconImpl) modify() {
+	if ti.closed {
+		return
+	}
+	procShellNotifyIconW.Call(nimModify, uintptr(unsafe.Pointer(&ti.nid)))
+}
+
+func (ti *trayIconImpl) sendEvent(ev oswin.TrayEvents, item int) {
+	ti.mu.Lock()
+	fun := ti.evFunc
+	ti.mu.Unlock()
+	if fun != nil {
+		fun(ti, ev, item)
+	}
+}
+
+// showMenu shows the menu at the mouse, on the thread of the window, and
+// sends the event for the selected item
+func (ti *trayIconImpl) showMenu() {
+	ti.mu.Lock()
+	items := ti.items
+	ti.mu.Unlock()
+	if len(items) == 0 {
+		return
+	}
+	menu, _, _ := procCreatePopupMenu.Call()
+	if menu == 0 {
+		return
+	}
+	defer procDestroyMenu.Call(menu)
+	for i, it := range items {
+		if it.Label == "" {
+			procAppendMenuW.Call(menu, mfSeparator, 0, 0)
+			continue
+		}
+		var flags uintptr
+		if it.Inactive {
+			flags = mfGrayed
+		}
+		lbl, _ := syscall.UTF16PtrFromString(it.Label)
+		procAppendMenuW.Call(menu, flags, uintptr(i+1), uintptr(unsafe.Pointer(lbl)))
+	}
+	var pt winPoint
+	procGetCursorPos.Call(uintptr(unsafe.Pointer(&pt)))
+	// the window must be in the foreground for the menu to close when
+	// clicking elsewhere, and get a message after it for it to work again
+	procSetForegroundWin.Call(ti.hwnd)
+	id, _, _ := procTrackPopupMenu.Call(menu, tpmRightBtn|tpmNoNotify|tpmReturnCmd, uintptr(pt.X), uintptr(pt.Y), 0, ti.hwnd, 0)
+	procPostMessageW.Call(ti.hwnd, wmNull, 0, 0)
+	if id > 0 {
+		ti.sendEvent(oswin.TrayMenuSelected, int(id)-1)
+	}
+}
+
+func (ti *trayIconImpl) SetIcon(icon image.Image) {
+	hicon, err := winIcon(icon)
+	if err != nil {
+		return
+	}
+	ti.mu.Lock()
+	old := ti.nid.Icon
+	ti.nid.Icon = hicon
+	ti.modify()
+	ti.mu.Unlock()
+	procDestroyIcon.Call(old)
+}
+
+func (ti *trayIconImpl) SetTooltip(tip string) {
+	ti.mu.Lock()
+	ti.setTip(tip)
+	ti.modify()
+	ti.mu.Unlock()
+}
+
+func (ti *trayIconImpl) SetMenu(items []oswin.TrayMenuItem) {
+	ti.mu.Lock()
+	ti.items = append([]oswin.TrayMenuItem(nil), items...)
+	ti.mu.Unlock()
+}
+
+func (ti *trayIconImpl) SetEventFunc(fun func(ti oswin.TrayIcon, ev oswin.TrayEvents, item int)) {
+	ti.mu.Lock()
+	ti.evFunc = fun
+	ti.mu.Unlock()
+}
+
+func (ti *trayIconImpl) Close() {
+	ti.mu.Lock()
+	if ti.closed {
+		ti.mu.Unlock()
+		return
+	}
+	ti.closed = true
+	procShellNotifyIconW.Call(nimDelete, uintptr(unsafe.Pointer(&ti.nid)))
+	procPostMessageW.Call(ti.hwnd, wmClose, 0, 0)
+	ti.mu.Unlock()
+	<-ti.done
+	procDestroyIcon.Call(ti.nid.Icon)
+}
+
+// winIcon returns a new icon with given image, with its alpha channel
+func winIcon(img image.Image) (uintptr, error) {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return 0, errors.New("glos: empty tray icon image")
+	}
+	bi := bitmapInfoHeader{Width: int32(w), Height: int32(-h), Planes: 1, BitCount: 32} // top-down
+	bi.Size = uint32(unsafe.Sizeof(bi))
+	var bits unsafe.Pointer
+	hbm, _, _ := procCreateDIBSection.Call(0, uintptr(unsafe.Pointer(&bi)), 0, uintptr(unsafe.Pointer(&bits)), 0, 0)
+	if hbm == 0 || bits == nil {
+		return 0, errors.New("glos: could not create tray icon bitmap")
+	}
+	defer procDeleteObject.Call(hbm)
+	n := w * h * 4
+	pix := (*[1 << 30]byte)(bits)[:n:n]
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.NRGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+			i := (y*w + x) * 4
+			pix[i], pix[i+1], pix[i+2], pix[i+3] = c.B, c.G, c.R, c.A
+		}
+	}
+	mask, _, _ := procCreateBitmap.Call(uintptr(w), uintptr(h), 1, 1, 0) // unused with alpha
+	defer procDeleteObject.Call(mask)
+	ii := iconInfo{Icon: 1, Mask: mask, Color: hbm}
+	hicon, _, _ := procCreateIconIndirect.Call(uintptr(unsafe.Pointer(&ii)))
+	if hicon == 0 {
+		return 0, errors.New("glos: could not create tray icon")
+	}
+	return hicon, nil
+}
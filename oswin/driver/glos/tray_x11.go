@@ -0,0 +1,197 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux,!android dragonfly openbsd
+
+package glos
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/goki/gi/oswin"
+)
+
+// tray icons are StatusNotifierItems (see tray_sni.go) when the desktop has
+// a StatusNotifierWatcher, and otherwise are shown by the yad command in its
+// notification mode (for the older XEmbed system trays), which is sent
+// commands on its stdin, and runs "echo" commands for the clicks and menu
+// items, whose output is read from its stdout
+
+const (
+	trayClickMsg = "gi-tray-click"
+	trayMenuMsg  = "gi-tray-menu:"
+)
+
+func (app *appImpl) NewTrayIcon(icon image.Image, tooltip string) (oswin.TrayIcon, error) {
+	sti, serr := newTraySNI(icon, tooltip)
+	if serr == nil {
+		return sti, nil
+	}
+	path, err := exec.LookPath("yad")
+	if err != nil {
+		return nil, fmt.Errorf("glos: the system tray needs a StatusNotifierWatcher on the session bus (e.g., the KDE panel, or the AppIndicator extension of GNOME), or else the yad command for XEmbed trays: %v, and yad is not installed", serr)
+	}
+	return newTrayYad(path, icon, tooltip)
+}
+
+// newTrayYad returns a new tray icon shown by the yad command at path
+func newTrayYad(path string, icon image.Image, tooltip string) (*trayYadImpl, error) {
+	f, err := ioutil.TempFile("", "gi-tray-*.png")
+	if err != nil {
+		return nil, err
+	}
+	ti := &trayYadImpl{iconFile: f.Name()}
+	f.Close()
+	if err := ti.writeIcon(icon); err != nil {
+		os.Remove(ti.iconFile)
+		return nil, err
+	}
+	ti.cmd = exec.Command(path, "--notification", "--listen", "--no-middle",
+		"--image="+ti.iconFile, "--text="+trayText(tooltip), "--command=echo "+trayClickMsg)
+	ti.stdin, err = ti.cmd.StdinPipe()
+	if err != nil {
+		os.Remove(ti.iconFile)
+		return nil, err
+	}
+	stdout, err := ti.cmd.StdoutPipe()
+	if err != nil {
+		os.Remove(ti.iconFile)
+		return nil, err
+	}
+	if err := ti.cmd.Start(); err != nil {
+		os.Remove(ti.iconFile)
+		return nil, fmt.Errorf("glos: starting system tray icon: %v", err)
+	}
+	go ti.readEvents(stdout)
+	return ti, nil
+}
+
+// trayText returns text with the newlines that end yad commands removed
+func trayText(txt string) string {
+	return strings.Replace(txt, "\n", " ", -1)
+}
+
+// trayYadImpl is a yad notification
+type trayYadImpl struct {
+	mu       sync.Mutex
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	iconFile string
+	evFunc   func(ti oswin.TrayIcon, ev oswin.TrayEvents, item int)
+	closed   bool
+}
+
+// writeIcon writes the icon to the icon file
+func (ti *trayYadImpl) writeIcon(icon image.Image) error {
+	f, err := os.Create(ti.iconFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return png.Encode(f, icon)
+}
+
+// send sends given command to yad
+func (ti *trayYadImpl) send(cmd string) {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	if ti.closed {
+		return
+	}
+	if _, err := io.WriteString(ti.stdin, cmd+"\n"); err != nil {
+		log.Printf("glos.TrayIcon: %v\n", err)
+	}
+}
+
+// readEvents reads the output of the echo commands run for the events
+func (ti *trayYadImpl) readEvents(stdout io.Reader) {
+	sc := bufio.NewScanner(stdout)
+	for sc.Scan() {
+		ln := strings.TrimSpace(sc.Text())
+		ev := oswin.TrayClicked
+		item := -1
+		switch {
+		case ln == trayClickMsg:
+		case strings.HasPrefix(ln, trayMenuMsg):
+			idx, err := strconv.Atoi(ln[len(trayMenuMsg):])
+			if err != nil {
+				continue
+			}
+			ev = oswin.TrayMenuSelected
+			item = idx
+		default:
+			continue
+		}
+		ti.mu.Lock()
+		fun := ti.evFunc
+		ti.mu.Unlock()
+		if fun != nil {
+			fun(ti, ev, item)
+		}
+	}
+}
+
+func (ti *trayYadImpl) SetIcon(icon image.Image) {
+	ti.mu.Lock()
+	err := ti.writeIcon(icon)
+	ti.mu.Unlock()
+	if err != nil {
+		log.Printf("glos.TrayIcon: %v\n", err)
+		return
+	}
+	ti.send("icon:" + ti.iconFile)
+}
+
+func (ti *trayYadImpl) SetTooltip(tip string) {
+	ti.send("tooltip:" + trayText(tip))
+}
+
+// SetMenu sets the menu, where the items are separated by | and the label
+// and command of each by ! -- so these characters are removed from labels
+func (ti *trayYadImpl) SetMenu(items []oswin.TrayMenuItem) {
+	lbl := strings.NewReplacer("|", " ", "!", " ", "\n", " ")
+	strs := make([]string, len(items))
+	for i, it := range items {
+		switch {
+		case it.Label == "":
+			strs[i] = "" // yad separator
+		case it.Inactive:
+			strs[i] = lbl.Replace(it.Label)
+		default:
+			strs[i] = lbl.Replace(it.Label) + "!echo " + trayMenuMsg + strconv.Itoa(i)
+		}
+	}
+	ti.send("menu:" + strings.Join(strs, "|"))
+}
+
+func (ti *trayYadImpl) SetEventFunc(fun func(ti oswin.TrayIcon, ev oswin.TrayEvents, item int)) {
+	ti.mu.Lock()
+	ti.evFunc = fun
+	ti.mu.Unlock()
+}
+
+func (ti *trayYadImpl) Close() {
+	ti.send("quit")
+	ti.mu.Lock()
+	if ti.closed {
+		ti.mu.Unlock()
+		return
+	}
+	ti.closed = true
+	ti.stdin.Close()
+	ti.mu.Unlock()
+	ti.cmd.Wait()
+	os.Remove(ti.iconFile)
+}
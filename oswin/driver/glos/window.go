@@ -37,14 +37,16 @@ type windowImpl struct {
 	publishDone    chan struct{}
 	winClose       chan struct{}
 	winTex         *textureImpl
+	frame          *textureImpl // retained window contents that all drawing goes into -- see frameTex
 	mu             sync.Mutex
 	mainMenu       oswin.MainMenu
 	closeReqFunc   func(win oswin.Window)
 	closeCleanFunc func(win oswin.Window)
 	drawQuads      gpu.BufferMgr
-	fillQuads      gpu.BufferMgr
 	mouseDisabled  bool
 	resettingPos   bool
+	hidden         bool    // hidden by Hide -- only accessed on main
+	osKey          uintptr // key in app.oswindows: OSHandle, or a unique id where that is 0 (Wayland)
 }
 
 // Handle returns the driver-specific handle for this window.
@@ -181,6 +183,7 @@ outer:
 					if !w.Activate() {
 						return
 					}
+					w.present()
 					w.glw.SwapBuffers() // note: implicitly does a flush
 					// note: generally don't need this:
 					// gpu.Draw.Clear(true, true)
@@ -224,6 +227,47 @@ func (w *windowImpl) Publish() {
 	glfw.PostEmptyEvent()
 }
 
+// PublishRegions publishes the window after only the given regions have
+// been drawn since the last publish (oswin.RegionPublisher).  All drawing
+// goes into the retained frame texture (see frameTex), so only those
+// regions of it have been re-drawn, but the entire frame is presented, as
+// the contents of the window back buffer are undefined after each swap.
+func (w *windowImpl) PublishRegions(rects []image.Rectangle) {
+	w.Publish()
+}
+
+// frameTex returns the retained frame texture that all drawing on the
+// window goes into, creating it or resizing it to the window as needed --
+// the window back buffer does not retain its contents between publishes,
+// so the frame does, and is presented in full on each publish, allowing
+// callers to only draw the regions that have changed (see PublishRegions).
+// Must be called on main with the window activated.
+func (w *windowImpl) frameTex() *textureImpl {
+	if w.frame == nil {
+		w.frame = &textureImpl{name: "WinFrame", size: w.PxSize}
+		w.frame.Activate(0)
+	} else {
+		w.frame.SetSize(w.PxSize) // no-op if same size
+	}
+	return w.frame
+}
+
+// present draws the retained frame texture onto the window back buffer,
+// prior to swapping it to the front.
+// Must be called on main with the window activated.
+func (w *windowImpl) present() {
+	if w.frame == nil {
+		return
+	}
+	gpu.TheGPU.RenderToWindow()
+	gpu.Draw.Viewport(image.Rectangle{Max: w.PxSize})
+	if w.drawQuads == nil {
+		w.drawQuads = theApp.drawQuadsBuff()
+	}
+	fr := w.frame.Bounds()
+	theApp.draw(w.Size(), mat32.Mat3{1, 0, 0, 0, 1, 0, 0, 0, 1}, w.frame, fr, oswin.Src, nil, w.drawQuads, true) // true = dest has botZero
+}
+
 // PublishTex draws the current WinTex texture to the window and then
 // calls Publish() -- this is the typical update call.
 func (w *windowImpl) PublishTex() {
@@ -289,13 +333,7 @@ func (w *windowImpl) Draw(src2dst mat32.Mat3, src oswin.Texture, sr image.Rectan
 		if !w.Activate() {
 			return
 		}
-		gpu.TheGPU.RenderToWindow()
-		gpu.Draw.Viewport(image.Rectangle{Max: w.PxSize})
-		if w.drawQuads == nil {
-			w.drawQuads = theApp.drawQuadsBuff()
-		}
-		sz := w.Size()
-		theApp.draw(sz, src2dst, src, sr, op, opts, w.drawQuads, true) // true = dest has botZero
+		w.frameTex().Draw(src2dst, src, sr, op, opts)
 	})
 }
 
@@ -307,13 +345,7 @@ func (w *windowImpl) DrawUniform(src2dst mat32.Mat3, src color.Color, sr image.R
 		if !w.Activate() {
 			return
 		}
-		gpu.TheGPU.RenderToWindow()
-		gpu.Draw.Viewport(image.Rectangle{Max: w.PxSize})
-		if w.fillQuads == nil {
-			w.fillQuads = theApp.fillQuadsBuff()
-		}
-		sz := w.Size()
-		theApp.drawUniform(sz, src2dst, src, sr, op, opts, w.fillQuads, true) // true = dest has botZero
+		w.frameTex().DrawUniform(src2dst, src, sr, op, opts)
 	})
 }
 
@@ -339,13 +371,7 @@ func (w *windowImpl) Fill(dr image.Rectangle, src color.Color, op draw.Op) {
 		if !w.Activate() {
 			return
 		}
-		gpu.TheGPU.RenderToWindow()
-		gpu.Draw.Viewport(image.Rectangle{Max: w.PxSize})
-		if w.fillQuads == nil {
-			w.fillQuads = theApp.fillQuadsBuff()
-		}
-		sz := w.Size()
-		theApp.fillRect(sz, dr, src, op, w.fillQuads, true) // true = dest has botZero
+		w.frameTex().Fill(dr, src, op)
 	})
 }
 
@@ -481,7 +507,12 @@ func (w *windowImpl) Raise() {
 		if w.glw == nil { // by time we got to main, could be diff
 			return
 		}
-		if bitflag.HasAtomic(&w.Flag, int(oswin.Minimized)) {
+		if w.hidden {
+			w.hidden = false
+			w.glw.Show()
+			w.iconify(w.glw, false)
+			w.glw.Focus()
+		} else if bitflag.HasAtomic(&w.Flag, int(oswin.Minimized)) {
 			w.glw.Restore()
 		} else {
 			w.glw.Focus()
@@ -502,6 +533,21 @@ func (w *windowImpl) Minimize() {
 	})
 }
 
+func (w *windowImpl) Hide() {
+	if w.IsClosed() {
+		return
+	}
+	// note: anything run on main only doesn't need lock -- implicit lock
+	w.app.RunOnMain(func() {
+		if w.glw == nil || w.hidden { // by time we got to main, could be diff
+			return
+		}
+		w.hidden = true
+		w.glw.Hide() // no iconify callback for hiding
+		w.iconify(w.glw, true)
+	})
+}
+
 func (w *windowImpl) SetCloseReqFunc(fun func(win oswin.Window)) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
@@ -539,19 +585,20 @@ func (w *windowImpl) Close() {
 	// fmt.Printf("sending close event to window: %v\n", w.Nm)
 	w.sendWindowEvent(window.Close)
 	theApp.DeleteWin(w)
+	w.closeAccess()
 	w.app.RunOnMain(func() {
 		if w.winTex != nil {
 			w.winTex.Delete()
 			w.winTex = nil
 		}
+		if w.frame != nil {
+			w.frame.Delete()
+			w.frame = nil
+		}
 		if w.drawQuads != nil {
 			w.drawQuads.Delete()
 			w.drawQuads = nil
 		}
-		if w.fillQuads != nil {
-			w.fillQuads.Delete()
-			w.fillQuads = nil
-		}
 		w.glw.Destroy()
 		w.glw = nil // marks as closed for all other calls
 	})
@@ -607,6 +654,13 @@ func (w *windowImpl) getScreen() *oswin.Screen {
 			log.Printf("glos getScreen: could not find screen of name: %v\n", mon.GetName())
 			sc = theApp.screens[0]
 		}
+	} else if sc = w.screenAtCenter(); sc != nil {
+		if sc.Name != w.scrnName {
+			if monitorDebug {
+				log.Printf("glos window: %v getScreen(): moved to screen: %v\n", w.Nm, sc.Name)
+			}
+			w.LogDPI = sc.LogicalDPI
+		}
 	} else {
 		sc = theApp.ScreenByName(w.scrnName)
 		got := false
@@ -641,12 +695,63 @@ func (w *windowImpl) getScreen() *oswin.Screen {
 	return sc
 }
 
+// screenAtCenter returns the screen containing the center of the window,
+// or nil if none does (always on Wayland) -- the window geometry is in the
+// same virtual desktop coordinates as the screen Geometry
+func (w *windowImpl) screenAtCenter() *oswin.Screen {
+	if theApp.Platform() == oswin.LinuxWayland {
+		return nil // window positions are not available
+	}
+	x, y := w.glw.GetPos()
+	wd, ht := w.glw.GetSize()
+	ctr := image.Point{x + wd/2, y + ht/2}
+	for _, sc := range theApp.screens {
+		if ctr.In(sc.Geometry) {
+			return sc
+		}
+	}
+	return nil
+}
+
+// scale returns the current scale-determining parameters of the window
+func (w *windowImpl) scale() (ldpi, pdpi, dpr float32) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.LogDPI, w.PhysDPI, w.DevPixRatio
+}
+
+// sendScaleChange sends a window.ScaleChange event if the scale of the
+// window has changed from given previous values
+func (w *windowImpl) sendScaleChange(ldpi, pdpi, dpr float32) {
+	nldpi, npdpi, ndpr := w.scale()
+	if ldpi == 0 || (nldpi == ldpi && npdpi == pdpi && ndpr == dpr) {
+		return
+	}
+	if monitorDebug {
+		log.Printf("glos window: %v scale change: logical dpi: %v -> %v, dev pix ratio: %v -> %v\n", w.Nm, ldpi, nldpi, dpr, ndpr)
+	}
+	w.sendWindowEvent(window.ScaleChange)
+}
+
 func (w *windowImpl) moved(gw *glfw.Window, x, y int) {
+	ldpi, pdpi, dpr := w.scale()
 	w.mu.Lock()
 	w.Pos = image.Point{x, y}
 	w.mu.Unlock()
 	w.getScreen()
 	w.sendWindowEvent(window.Move)
+	w.sendScaleChange(ldpi, pdpi, dpr)
+}
+
+// contentScaled is called when the content scale (device pixel ratio) of
+// the window changes, which happens when moving to a screen with a
+// different scale, or when the scale of the screen is changed by the user
+func (w *windowImpl) contentScaled(gw *glfw.Window, x, y float32) {
+	if monitorDebug {
+		log.Printf("glos window: %v content scale: %v\n", w.Nm, x)
+	}
+	theApp.getScreens() // screen scale may have changed
+	w.updtGeom()
 }
 
 func (w *windowImpl) winResized(gw *glfw.Window, width, height int) {
@@ -654,6 +759,7 @@ func (w *windowImpl) winResized(gw *glfw.Window, width, height int) {
 }
 
 func (w *windowImpl) updtGeom() {
+	ldpi, pdpi, dpr := w.scale()
 	w.mu.Lock()
 	cscx, _ := w.glw.GetContentScale()
 	// curDevPixRatio := w.DevPixRatio
@@ -691,6 +797,7 @@ func (w *windowImpl) updtGeom() {
 		}
 	}
 	w.sendWindowEvent(window.Resize) // this will not get processed until the end
+	w.sendScaleChange(ldpi, pdpi, dpr)
 }
 
 func (w *windowImpl) fbResized(gw *glfw.Window, width, height int) {
@@ -0,0 +1,526 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package dbus is a minimal client for the D-Bus message bus
+// (https://dbus.freedesktop.org/doc/dbus-specification.html), as used by
+// the drivers on Linux for the desktop services that are only available
+// over D-Bus, e.g., the StatusNotifierItem system tray and the AT-SPI
+// accessibility bridge.  It supports method calls, replies, signals and
+// exporting objects over unix socket connections to the session bus, or
+// a bus at a given address, with EXTERNAL authentication.
+package dbus
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CallTimeout is the maximum time to wait for the reply to a method call
+var CallTimeout = 10 * time.Second
+
+// ErrClosed is returned for calls on a connection that has been closed
+var ErrClosed = errors.New("dbus: connection closed")
+
+// MsgTypes are the types of messages
+type MsgTypes byte
+
+const (
+	TypeInvalid MsgTypes = iota
+	TypeMethodCall
+	TypeMethodReturn
+	TypeError
+	TypeSignal
+)
+
+// message flags
+const (
+	FlagNoReplyExpected = 0x1
+	FlagNoAutoStart     = 0x2
+)
+
+// header field codes
+const (
+	fieldPath        = 1
+	fieldInterface   = 2
+	fieldMember      = 3
+	fieldErrorName   = 4
+	fieldReplySerial = 5
+	fieldDestination = 6
+	fieldSender      = 7
+	fieldSignature   = 8
+)
+
+// maxMessageSize is the maximum size of a message, from the specification
+const maxMessageSize = 1 << 27
+
+// Message is a D-Bus message
+type Message struct {
+	Type        MsgTypes
+	Flags       byte
+	Serial      uint32
+	Path        ObjectPath
+	Iface       string
+	Member      string
+	ErrName     string
+	ReplySerial uint32
+	Dest        string
+	Sender      string
+	Sig         Signature
+	Body        []interface{}
+}
+
+// Error is an error reply to a method call
+type Error struct {
+	Name string        `desc:"the error name, e.g., org.freedesktop.DBus.Error.UnknownMethod"`
+	Body []interface{} `desc:"the body of the error, which typically starts with a message string"`
+}
+
+func (er *Error) Error() string {
+	if len(er.Body) > 0 {
+		if msg, ok := er.Body[0].(string); ok {
+			return "dbus: " + er.Name + ": " + msg
+		}
+	}
+	return "dbus: " + er.Name
+}
+
+// standard error names
+const (
+	ErrUnknownMethod    = "org.freedesktop.DBus.Error.UnknownMethod"
+	ErrUnknownObject    = "org.freedesktop.DBus.Error.UnknownObject"
+	ErrUnknownInterface = "org.freedesktop.DBus.Error.UnknownInterface"
+	ErrUnknownProperty  = "org.freedesktop.DBus.Error.UnknownProperty"
+	ErrInvalidArgs      = "org.freedesktop.DBus.Error.InvalidArgs"
+	ErrFailed           = "org.freedesktop.DBus.Error.Failed"
+)
+
+// encode returns the wire encoding of the message
+func (m *Message) encode() ([]byte, error) {
+	body := &encoder{}
+	if err := body.values(string(m.Sig), m.Body); err != nil {
+		return nil, err
+	}
+	var flds []interface{}
+	addFld := func(code byte, sig string, v interface{}) {
+		flds = append(flds, Struct{code, Variant{Sig: Signature(sig), Value: v}})
+	}
+	if m.Path != "" {
+		addFld(fieldPath, "o", m.Path)
+	}
+	if m.Iface != "" {
+		addFld(fieldInterface, "s", m.Iface)
+	}
+	if m.Member != "" {
+		addFld(fieldMember, "s", m.Member)
+	}
+	if m.ErrName != "" {
+		addFld(fieldErrorName, "s", m.ErrName)
+	}
+	if m.ReplySerial != 0 {
+		addFld(fieldReplySerial, "u", m.ReplySerial)
+	}
+	if m.Dest != "" {
+		addFld(fieldDestination, "s", m.Dest)
+	}
+	if m.Sender != "" {
+		addFld(fieldSender, "s", m.Sender)
+	}
+	if m.Sig != "" {
+		addFld(fieldSignature, "g", m.Sig)
+	}
+	hdr := &encoder{}
+	hdr.buf = append(hdr.buf, 'l', byte(m.Type), m.Flags, 1)
+	hdr.uint32(uint32(len(body.buf)))
+	hdr.uint32(m.Serial)
+	if err := hdr.value("a(yv)", flds); err != nil {
+		return nil, err
+	}
+	hdr.align(8)
+	return append(hdr.buf, body.buf...), nil
+}
+
+// readMessage reads the next message
+func readMessage(rd io.Reader) (*Message, error) {
+	fix := make([]byte, 16)
+	if _, err := io.ReadFull(rd, fix); err != nil {
+		return nil, err
+	}
+	var order binary.ByteOrder
+	switch fix[0] {
+	case 'l':
+		order = binary.LittleEndian
+	case 'B':
+		order = binary.BigEndian
+	default:
+		return nil, errors.New("dbus: invalid byte order in message")
+	}
+	blen := order.Uint32(fix[4:])
+	flen := order.Uint32(fix[12:]) // length of the header fields array
+	hlen := 16 + int(flen)
+	if hlen%8 != 0 {
+		hlen += 8 - hlen%8
+	}
+	if int(blen)+hlen > maxMessageSize {
+		return nil, errors.New("dbus: message too long")
+	}
+	buf := make([]byte, hlen+int(blen))
+	copy(buf, fix)
+	if _, err := io.ReadFull(rd, buf[16:]); err != nil {
+		return nil, err
+	}
+	m := &Message{Type: MsgTypes(fix[1]), Flags: fix[2], Serial: order.Uint32(fix[8:])}
+	d := &decoder{buf: buf[:16+flen], off: 12, order: order}
+	fv, err := d.value("a(yv)", 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fv.([]interface{}) {
+		st := f.(Struct)
+		v := st[1].(Variant).Value
+		switch st[0].(byte) {
+		case fieldPath:
+			m.Path, _ = v.(ObjectPath)
+		case fieldInterface:
+			m.Iface, _ = v.(string)
+		case fieldMember:
+			m.Member, _ = v.(string)
+		case fieldErrorName:
+			m.ErrName, _ = v.(string)
+		case fieldReplySerial:
+			m.ReplySerial, _ = v.(uint32)
+		case fieldDestination:
+			m.Dest, _ = v.(string)
+		case fieldSender:
+			m.Sender, _ = v.(string)
+		case fieldSignature:
+			m.Sig, _ = v.(Signature)
+		}
+	}
+	bd := &decoder{buf: buf[hlen:], order: order}
+	if m.Body, err = bd.values(string(m.Sig)); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// HandlerFunc is called for method calls and signals received on a
+// connection, in its reading goroutine -- it must not make method calls on
+// the connection itself (which would wait forever for the reply), but it
+// can reply and emit signals
+type HandlerFunc func(c *Conn, m *Message)
+
+// Conn is a connection to a message bus
+type Conn struct {
+	Name    string      `desc:"unique name of the connection on the bus"`
+	Handler HandlerFunc `desc:"function called for the method calls and signals received -- method calls get an UnknownMethod error if nil"`
+	conn    net.Conn
+	rd      *bufio.Reader
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	serial  uint32
+	pending map[uint32]chan *Message
+	closed  bool
+}
+
+// SessionBusAddress returns the address of the session bus, from the
+// DBUS_SESSION_BUS_ADDRESS environment variable, or the default socket of
+// the user if not set
+func SessionBusAddress() string {
+	if addr := os.Getenv("DBUS_SESSION_BUS_ADDRESS"); addr != "" {
+		return addr
+	}
+	if rd := os.Getenv("XDG_RUNTIME_DIR"); rd != "" {
+		return "unix:path=" + rd + "/bus"
+	}
+	return ""
+}
+
+// SessionBus returns a new connection to the session bus
+func SessionBus(handler HandlerFunc) (*Conn, error) {
+	addr := SessionBusAddress()
+	if addr == "" {
+		return nil, errors.New("dbus: no session bus address")
+	}
+	return Dial(addr, handler)
+}
+
+// dialAddress connects to one server address, e.g., unix:path=/run/bus
+func dialAddress(addr string) (net.Conn, error) {
+	ci := strings.Index(addr, ":")
+	if ci < 0 {
+		return nil, fmt.Errorf("dbus: invalid address: %q", addr)
+	}
+	trans := addr[:ci]
+	kv := make(map[string]string)
+	for _, p := range strings.Split(addr[ci+1:], ",") {
+		ei := strings.Index(p, "=")
+		if ei < 0 {
+			continue
+		}
+		v, err := url.PathUnescape(p[ei+1:])
+		if err != nil {
+			return nil, fmt.Errorf("dbus: invalid address: %q", addr)
+		}
+		kv[p[:ei]] = v
+	}
+	switch trans {
+	case "unix":
+		switch {
+		case kv["path"] != "":
+			return net.Dial("unix", kv["path"])
+		case kv["abstract"] != "":
+			return net.Dial("unix", "@"+kv["abstract"])
+		}
+	case "tcp":
+		return net.Dial("tcp", net.JoinHostPort(kv["host"], kv["port"]))
+	}
+	return nil, fmt.Errorf("dbus: unsupported address: %q", addr)
+}
+
+// Dial connects to the bus at given address (which can have several
+// alternatives, separated by ;), authenticates, and registers with the bus,
+// calling given handler for the method calls and signals received
+func Dial(addr string, handler HandlerFunc) (*Conn, error) {
+	var nc net.Conn
+	var err error
+	for _, a := range strings.Split(addr, ";") {
+		if a == "" {
+			continue
+		}
+		if nc, err = dialAddress(a); err == nil {
+			break
+		}
+	}
+	if nc == nil {
+		if err == nil {
+			err = fmt.Errorf("dbus: invalid address: %q", addr)
+		}
+		return nil, err
+	}
+	c := &Conn{Handler: handler, conn: nc, rd: bufio.NewReader(nc), pending: make(map[uint32]chan *Message)}
+	if err := c.auth(); err != nil {
+		nc.Close()
+		return nil, err
+	}
+	go c.readLoop()
+	rep, err := c.Call("org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "Hello", "")
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	if len(rep) > 0 {
+		c.Name, _ = rep[0].(string)
+	}
+	return c, nil
+}
+
+// auth does the EXTERNAL authentication with the uid of the process
+func (c *Conn) auth() error {
+	c.conn.SetDeadline(time.Now().Add(CallTimeout))
+	defer c.conn.SetDeadline(time.Time{})
+	uid := hex.EncodeToString([]byte(strconv.Itoa(os.Getuid())))
+	if _, err := io.WriteString(c.conn, "\x00AUTH EXTERNAL "+uid+"\r\n"); err != nil {
+		return err
+	}
+	ln, err := c.rd.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(ln, "OK ") {
+		return fmt.Errorf("dbus: authentication failed: %s", strings.TrimSpace(ln))
+	}
+	_, err = io.WriteString(c.conn, "BEGIN\r\n")
+	return err
+}
+
+// send sends given message, setting its serial
+func (c *Conn) send(m *Message) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+	if m.Serial == 0 {
+		c.serial++
+		m.Serial = c.serial
+	}
+	c.mu.Unlock()
+	b, err := m.encode()
+	if err != nil {
+		return err
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err = c.conn.Write(b)
+	return err
+}
+
+// Call calls given method with given arguments of given signature, and
+// waits for the reply, returning its body, or an *Error for an error reply
+func (c *Conn) Call(dest string, path ObjectPath, iface, member, sig string, args ...interface{}) ([]interface{}, error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, ErrClosed
+	}
+	c.serial++
+	m := &Message{Type: TypeMethodCall, Serial: c.serial, Dest: dest, Path: path, Iface: iface, Member: member, Sig: Signature(sig), Body: args}
+	rch := make(chan *Message, 1)
+	c.pending[m.Serial] = rch
+	c.mu.Unlock()
+	if err := c.send(m); err != nil {
+		c.forget(m.Serial)
+		return nil, err
+	}
+	select {
+	case rm := <-rch:
+		if rm == nil {
+			return nil, ErrClosed
+		}
+		if rm.Type == TypeError {
+			return nil, &Error{Name: rm.ErrName, Body: rm.Body}
+		}
+		return rm.Body, nil
+	case <-time.After(CallTimeout):
+		c.forget(m.Serial)
+		return nil, fmt.Errorf("dbus: timeout waiting for reply to %v.%v", iface, member)
+	}
+}
+
+// forget removes a pending call
+func (c *Conn) forget(serial uint32) {
+	c.mu.Lock()
+	delete(c.pending, serial)
+	c.mu.Unlock()
+}
+
+// Reply sends the reply to given method call, with given values of given
+// signature -- nothing is sent if the caller does not expect a reply
+func (c *Conn) Reply(call *Message, sig string, vals ...interface{}) error {
+	if call.Flags&FlagNoReplyExpected != 0 {
+		return nil
+	}
+	return c.send(&Message{Type: TypeMethodReturn, Flags: FlagNoReplyExpected, ReplySerial: call.Serial, Dest: call.Sender, Sig: Signature(sig), Body: vals})
+}
+
+// ReplyError sends an error reply to given method call, with given error
+// name and message
+func (c *Conn) ReplyError(call *Message, name, msg string) error {
+	if call.Flags&FlagNoReplyExpected != 0 {
+		return nil
+	}
+	return c.send(&Message{Type: TypeError, Flags: FlagNoReplyExpected, ReplySerial: call.Serial, Dest: call.Sender, ErrName: name, Sig: "s", Body: []interface{}{msg}})
+}
+
+// Emit emits a signal from given object, with given values of given signature
+func (c *Conn) Emit(path ObjectPath, iface, member, sig string, vals ...interface{}) error {
+	return c.send(&Message{Type: TypeSignal, Flags: FlagNoReplyExpected, Path: path, Iface: iface, Member: member, Sig: Signature(sig), Body: vals})
+}
+
+// RequestName requests given well-known name on the bus for this
+// connection, returning an error if it could not become its owner
+func (c *Conn) RequestName(name string) error {
+	rep, err := c.Call("org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "RequestName", "su", name, uint32(0x4)) // do not queue
+	if err != nil {
+		return err
+	}
+	if len(rep) == 0 || rep[0] != uint32(1) { // primary owner
+		return fmt.Errorf("dbus: could not own name: %v", name)
+	}
+	return nil
+}
+
+// NameHasOwner returns true if given name is owned on the bus, e.g., to
+// check whether a service is running
+func (c *Conn) NameHasOwner(name string) bool {
+	rep, err := c.Call("org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "NameHasOwner", "s", name)
+	if err != nil || len(rep) == 0 {
+		return false
+	}
+	has, _ := rep[0].(bool)
+	return has
+}
+
+// AddMatch adds a match rule for the signals to receive, e.g.,
+// type='signal',interface='org.kde.StatusNotifierWatcher'
+func (c *Conn) AddMatch(rule string) error {
+	_, err := c.Call("org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "AddMatch", "s", rule)
+	return err
+}
+
+// GetProperty returns the value of given property of given object
+func (c *Conn) GetProperty(dest string, path ObjectPath, iface, prop string) (interface{}, error) {
+	rep, err := c.Call(dest, path, "org.freedesktop.DBus.Properties", "Get", "ss", iface, prop)
+	if err != nil {
+		return nil, err
+	}
+	if len(rep) == 0 {
+		return nil, fmt.Errorf("dbus: no value for property: %v", prop)
+	}
+	return rep[0].(Variant).Value, nil
+}
+
+// readLoop reads and dispatches messages until the connection is closed
+func (c *Conn) readLoop() {
+	for {
+		m, err := readMessage(c.rd)
+		if err != nil {
+			c.Close()
+			return
+		}
+		switch m.Type {
+		case TypeMethodReturn, TypeError:
+			c.mu.Lock()
+			rch, ok := c.pending[m.ReplySerial]
+			delete(c.pending, m.ReplySerial)
+			c.mu.Unlock()
+			if ok {
+				rch <- m
+			}
+		case TypeMethodCall:
+			if c.Handler == nil {
+				c.ReplyError(m, ErrUnknownMethod, "no objects are exported")
+				continue
+			}
+			c.Handler(c, m)
+		case TypeSignal:
+			if c.Handler != nil {
+				c.Handler(c, m)
+			}
+		}
+	}
+}
+
+// Close closes the connection, and returns ErrClosed for all pending calls
+func (c *Conn) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	c.closed = true
+	c.conn.Close()
+	for serial, rch := range c.pending {
+		rch <- nil
+		delete(c.pending, serial)
+	}
+}
+
+// IsClosed returns true if the connection has been closed
+func (c *Conn) IsClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
@@ -0,0 +1,207 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dbus
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		sig  string
+		vals []interface{}
+		dec  []interface{}
+	}{
+		{"ybnqiuxtd", []interface{}{byte(1), true, int16(-2), uint16(3), int32(-4), uint32(5), int64(-6), uint64(7), 8.5}, nil},
+		{"sog", []interface{}{"héllo", ObjectPath("/a/b"), Signature("a{sv}")}, nil},
+		{"i", []interface{}{3}, []interface{}{int32(3)}},
+		{"as", []interface{}{[]string{"a", "", "bc"}}, []interface{}{[]interface{}{"a", "", "bc"}}},
+		{"as", []interface{}{[]string(nil)}, []interface{}{[]interface{}{}}},
+		{"ay", []interface{}{[]byte("xyz")}, nil},
+		{"(yv)", []interface{}{Struct{byte(1), MakeVariant(uint32(2))}}, nil},
+		{"a(ix)", []interface{}{[]interface{}{Struct{int32(1), int64(2)}, Struct{int32(3), int64(4)}}}, nil},
+		{"a{sv}", []interface{}{map[string]Variant{"b": MakeVariant("x"), "a": MakeVariant(true)}},
+			[]interface{}{[]DictEntry{{"a", MakeVariant(true)}, {"b", MakeVariant("x")}}}},
+		{"a{ia{sv}}", []interface{}{[]DictEntry{{int32(1), []DictEntry{{"k", MakeVariant(int64(1))}}}}}, nil},
+		{"v", []interface{}{Variant{Sig: "a(iiay)", Value: []interface{}{Struct{int32(1), int32(1), []byte{0, 1, 2, 3}}}}}, nil},
+		{"(ia(ia{sv}av))", []interface{}{Struct{int32(0), []interface{}{}}}, nil},
+	}
+	for _, ts := range tests {
+		b, err := Marshal(ts.sig, ts.vals...)
+		if err != nil {
+			t.Errorf("%s: marshal error: %v", ts.sig, err)
+			continue
+		}
+		vals, err := Unmarshal(b, ts.sig)
+		if err != nil {
+			t.Errorf("%s: unmarshal error: %v", ts.sig, err)
+			continue
+		}
+		dec := ts.dec
+		if dec == nil {
+			dec = ts.vals
+		}
+		if !reflect.DeepEqual(vals, dec) {
+			t.Errorf("%s: %#v != expected: %#v", ts.sig, vals, dec)
+		}
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	tests := []struct {
+		sig  string
+		vals []interface{}
+	}{
+		{"", []interface{}{int32(1)}},
+		{"i", nil},
+		{"i", []interface{}{"x"}},
+		{"a", []interface{}{[]byte{}}},
+		{"(i", []interface{}{Struct{int32(1)}}},
+		{"(ii)", []interface{}{Struct{int32(1)}}},
+		{"a{vs}", []interface{}{[]DictEntry{}}},
+		{"o", []interface{}{3}},
+	}
+	for _, ts := range tests {
+		if _, err := Marshal(ts.sig, ts.vals...); err == nil {
+			t.Errorf("%s %v: no error", ts.sig, ts.vals)
+		}
+	}
+	if _, err := Unmarshal([]byte{1, 0, 0}, "u"); err == nil {
+		t.Errorf("short data: no error")
+	}
+	if _, err := Unmarshal([]byte{9, 0, 0, 0, 'a', 0}, "s"); err == nil {
+		t.Errorf("short string: no error")
+	}
+}
+
+func TestMessage(t *testing.T) {
+	m := &Message{Type: TypeMethodCall, Serial: 7, Path: "/p", Iface: "a.b", Member: "M", Dest: "a.c", Sig: "su", Body: []interface{}{"x", uint32(2)}}
+	b, err := m.encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rm, err := readMessage(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rm, m) {
+		t.Errorf("message: %+v != expected: %+v", rm, m)
+	}
+}
+
+// startBus starts a private bus daemon, returning its address, or skips
+// the test if dbus-daemon is not available
+func startBus(t *testing.T) (string, func()) {
+	t.Helper()
+	if _, err := exec.LookPath("dbus-daemon"); err != nil {
+		t.Skip("dbus-daemon not found")
+	}
+	cmd := exec.Command("dbus-daemon", "--session", "--nofork", "--print-address")
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		t.Skipf("dbus-daemon: %v", err)
+	}
+	addr, err := bufio.NewReader(out).ReadString('\n')
+	if err != nil {
+		cmd.Process.Kill()
+		t.Skipf("dbus-daemon: %v", err)
+	}
+	return strings.TrimSpace(addr), func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}
+}
+
+func TestConn(t *testing.T) {
+	addr, stop := startBus(t)
+	defer stop()
+
+	sigs := make(chan *Message, 1)
+	srv, err := Dial(addr, func(c *Conn, m *Message) {
+		if m.Type == TypeSignal {
+			if m.Member == "Ping" {
+				sigs <- m
+			}
+			return
+		}
+		switch {
+		case m.Path != "/obj":
+			c.ReplyError(m, ErrUnknownObject, "no object")
+		case m.Member == "Add":
+			a, _ := m.Body[0].(int32)
+			b, _ := m.Body[1].(int32)
+			c.Reply(m, "i", a+b)
+		default:
+			c.ReplyError(m, ErrUnknownMethod, "no method: "+m.Member)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	if !strings.HasPrefix(srv.Name, ":") {
+		t.Errorf("unique name: %q", srv.Name)
+	}
+	if err := srv.RequestName("org.goki.Test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.AddMatch("type='signal',interface='org.goki.Test'"); err != nil {
+		t.Fatal(err)
+	}
+
+	cli, err := Dial(addr, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	if !cli.NameHasOwner("org.goki.Test") || cli.NameHasOwner("org.goki.None") {
+		t.Errorf("NameHasOwner is wrong")
+	}
+	if err := cli.RequestName("org.goki.Test"); err == nil {
+		t.Errorf("RequestName of an owned name: no error")
+	}
+	rep, err := cli.Call("org.goki.Test", "/obj", "org.goki.Test", "Add", "ii", int32(2), int32(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rep, []interface{}{int32(5)}) {
+		t.Errorf("Add: %v != expected: 5", rep)
+	}
+	_, err = cli.Call("org.goki.Test", "/obj", "org.goki.Test", "Sub", "")
+	if er, ok := err.(*Error); !ok || er.Name != ErrUnknownMethod {
+		t.Errorf("Sub: error: %v != expected: %v", err, ErrUnknownMethod)
+	}
+	_, err = cli.Call("org.goki.Test", "/none", "org.goki.Test", "Add", "ii", int32(2), int32(3))
+	if er, ok := err.(*Error); !ok || er.Name != ErrUnknownObject {
+		t.Errorf("unknown object: error: %v != expected: %v", err, ErrUnknownObject)
+	}
+	v, err := cli.GetProperty("org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "Features")
+	if err != nil {
+		t.Error(err)
+	} else if _, ok := v.([]interface{}); !ok {
+		t.Errorf("Features: %#v", v)
+	}
+	if err := cli.Emit("/obj", "org.goki.Test", "Ping", "s", "hi"); err != nil {
+		t.Fatal(err)
+	}
+	m := <-sigs
+	if m.Sender != cli.Name || !reflect.DeepEqual(m.Body, []interface{}{"hi"}) {
+		t.Errorf("signal: %+v", m)
+	}
+	cli.Close()
+	if _, err := cli.Call("org.goki.Test", "/obj", "org.goki.Test", "Add", "ii", int32(2), int32(3)); err != ErrClosed {
+		t.Errorf("call on closed connection: error: %v != expected: %v", err, ErrClosed)
+	}
+}
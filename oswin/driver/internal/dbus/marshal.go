@@ -0,0 +1,614 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dbus
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// ObjectPath is a D-Bus object path (type o)
+type ObjectPath string
+
+// Signature is a D-Bus type signature (type g)
+type Signature string
+
+// Variant is a value with its own signature (type v)
+type Variant struct {
+	Sig   Signature
+	Value interface{}
+}
+
+// MakeVariant returns a variant for a value of one of the basic types,
+// a []string, []byte, []int32, or map[string]Variant, with its signature
+// -- returns a variant with an empty signature for other types, which
+// must be made directly
+func MakeVariant(v interface{}) Variant {
+	var sig string
+	switch v.(type) {
+	case byte:
+		sig = "y"
+	case bool:
+		sig = "b"
+	case int16:
+		sig = "n"
+	case uint16:
+		sig = "q"
+	case int32, int:
+		sig = "i"
+	case uint32:
+		sig = "u"
+	case int64:
+		sig = "x"
+	case uint64:
+		sig = "t"
+	case float64:
+		sig = "d"
+	case string:
+		sig = "s"
+	case ObjectPath:
+		sig = "o"
+	case Signature:
+		sig = "g"
+	case Variant:
+		sig = "v"
+	case []string:
+		sig = "as"
+	case []byte:
+		sig = "ay"
+	case []int32:
+		sig = "ai"
+	case map[string]Variant:
+		sig = "a{sv}"
+	}
+	return Variant{Sig: Signature(sig), Value: v}
+}
+
+// Struct is the value of a struct type: its fields in order
+type Struct []interface{}
+
+// DictEntry is an entry of a dict (an array of dict entries, a{kv})
+type DictEntry struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// Values are represented as follows, both for encoding and as decoded:
+// byte (y), bool (b), int16 (n), uint16 (q), int32 (i), uint32 (u),
+// int64 (x), uint64 (t), float64 (d), string (s), ObjectPath (o),
+// Signature (g), Variant (v), Struct for structs, []byte for ay,
+// []DictEntry for dicts, and []interface{} for other arrays.  For
+// encoding, int is also accepted for i, string for o and g, []string,
+// []int32 and []ObjectPath for those arrays, and map[string]Variant for
+// a{sv}.  Unix file descriptors (h) are not supported.
+
+// ErrSignature is returned for invalid signatures
+var ErrSignature = errors.New("dbus: invalid signature")
+
+// nextType returns the first complete type of sig, and the rest of it
+func nextType(sig string) (string, string, error) {
+	if sig == "" {
+		return "", "", ErrSignature
+	}
+	switch sig[0] {
+	case 'y', 'b', 'n', 'q', 'i', 'u', 'x', 't', 'd', 's', 'o', 'g', 'v', 'h':
+		return sig[:1], sig[1:], nil
+	case 'a':
+		et, rest, err := nextType(sig[1:])
+		if err != nil {
+			return "", "", err
+		}
+		return "a" + et, rest, nil
+	case '(', '{':
+		cl := byte(')')
+		if sig[0] == '{' {
+			cl = '}'
+		}
+		rest := sig[1:]
+		n := 0
+		for {
+			if rest == "" {
+				return "", "", ErrSignature
+			}
+			if rest[0] == cl {
+				break
+			}
+			_, r, err := nextType(rest)
+			if err != nil {
+				return "", "", err
+			}
+			rest = r
+			n++
+		}
+		if n == 0 || (cl == '}' && (n != 2 || strings.IndexByte("ybnqiuxtdsogh", sig[1]) < 0)) {
+			return "", "", ErrSignature // dict entries have a basic key type and a value
+		}
+		ln := len(sig) - len(rest) + 1
+		return sig[:ln], sig[ln:], nil
+	}
+	return "", "", ErrSignature
+}
+
+// splitTypes returns the complete types of sig
+func splitTypes(sig string) ([]string, error) {
+	var tys []string
+	for sig != "" {
+		t, rest, err := nextType(sig)
+		if err != nil {
+			return nil, err
+		}
+		tys = append(tys, t)
+		sig = rest
+	}
+	return tys, nil
+}
+
+// alignOf returns the alignment of values of given type
+func alignOf(t string) int {
+	switch t[0] {
+	case 'n', 'q':
+		return 2
+	case 'b', 'i', 'u', 's', 'o', 'a', 'h':
+		return 4
+	case 'x', 't', 'd', '(', '{':
+		return 8
+	}
+	return 1 // y, g, v
+}
+
+// encoder marshals values in little-endian byte order
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) align(n int) {
+	for len(e.buf)%n != 0 {
+		e.buf = append(e.buf, 0)
+	}
+}
+
+func (e *encoder) uint32(v uint32) {
+	e.align(4)
+	e.buf = append(e.buf, 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(e.buf[len(e.buf)-4:], v)
+}
+
+func (e *encoder) uint64(v uint64) {
+	e.align(8)
+	e.buf = append(e.buf, 0, 0, 0, 0, 0, 0, 0, 0)
+	binary.LittleEndian.PutUint64(e.buf[len(e.buf)-8:], v)
+}
+
+func (e *encoder) string(s string) {
+	e.uint32(uint32(len(s)))
+	e.buf = append(e.buf, s...)
+	e.buf = append(e.buf, 0)
+}
+
+func (e *encoder) signature(s string) {
+	e.buf = append(e.buf, byte(len(s)))
+	e.buf = append(e.buf, s...)
+	e.buf = append(e.buf, 0)
+}
+
+// values encodes the values for given signature
+func (e *encoder) values(sig string, vals []interface{}) error {
+	tys, err := splitTypes(sig)
+	if err != nil {
+		return err
+	}
+	if len(tys) != len(vals) {
+		return fmt.Errorf("dbus: %d values for signature %q", len(vals), sig)
+	}
+	for i, t := range tys {
+		if err := e.value(t, vals[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// typeErr returns the error for a value that does not match its type
+func typeErr(t string, v interface{}) error {
+	return fmt.Errorf("dbus: cannot encode %T as %q", v, t)
+}
+
+// value encodes a value of given complete type
+func (e *encoder) value(t string, v interface{}) error {
+	switch t[0] {
+	case 'y':
+		b, ok := v.(byte)
+		if !ok {
+			return typeErr(t, v)
+		}
+		e.buf = append(e.buf, b)
+	case 'b':
+		b, ok := v.(bool)
+		if !ok {
+			return typeErr(t, v)
+		}
+		if b {
+			e.uint32(1)
+		} else {
+			e.uint32(0)
+		}
+	case 'n', 'q':
+		var u uint16
+		switch x := v.(type) {
+		case int16:
+			u = uint16(x)
+		case uint16:
+			u = x
+		default:
+			return typeErr(t, v)
+		}
+		e.align(2)
+		e.buf = append(e.buf, byte(u), byte(u>>8))
+	case 'i', 'u':
+		var u uint32
+		switch x := v.(type) {
+		case int32:
+			u = uint32(x)
+		case uint32:
+			u = x
+		case int:
+			u = uint32(int32(x))
+		default:
+			return typeErr(t, v)
+		}
+		e.uint32(u)
+	case 'x', 't':
+		var u uint64
+		switch x := v.(type) {
+		case int64:
+			u = uint64(x)
+		case uint64:
+			u = x
+		default:
+			return typeErr(t, v)
+		}
+		e.uint64(u)
+	case 'd':
+		f, ok := v.(float64)
+		if !ok {
+			return typeErr(t, v)
+		}
+		e.uint64(math.Float64bits(f))
+	case 's', 'o':
+		switch x := v.(type) {
+		case string:
+			e.string(x)
+		case ObjectPath:
+			e.string(string(x))
+		default:
+			return typeErr(t, v)
+		}
+	case 'g':
+		switch x := v.(type) {
+		case string:
+			e.signature(x)
+		case Signature:
+			e.signature(string(x))
+		default:
+			return typeErr(t, v)
+		}
+	case 'v':
+		vr, ok := v.(Variant)
+		if !ok {
+			return typeErr(t, v)
+		}
+		if _, rest, err := nextType(string(vr.Sig)); err != nil || rest != "" {
+			return ErrSignature
+		}
+		e.signature(string(vr.Sig))
+		return e.value(string(vr.Sig), vr.Value)
+	case 'a':
+		return e.array(t, v)
+	case '(':
+		st, ok := v.(Struct)
+		if !ok {
+			return typeErr(t, v)
+		}
+		e.align(8)
+		return e.values(t[1:len(t)-1], st)
+	case '{':
+		de, ok := v.(DictEntry)
+		if !ok {
+			return typeErr(t, v)
+		}
+		e.align(8)
+		return e.values(t[1:len(t)-1], []interface{}{de.Key, de.Value})
+	default:
+		return typeErr(t, v)
+	}
+	return nil
+}
+
+// array encodes an array of given type
+func (e *encoder) array(t string, v interface{}) error {
+	et := t[1:]
+	var elems []interface{}
+	switch x := v.(type) {
+	case []byte:
+		if et != "y" {
+			return typeErr(t, v)
+		}
+		e.uint32(uint32(len(x)))
+		e.buf = append(e.buf, x...)
+		return nil
+	case []interface{}:
+		elems = x
+	case []string:
+		for _, s := range x {
+			elems = append(elems, s)
+		}
+	case []int32:
+		for _, i := range x {
+			elems = append(elems, i)
+		}
+	case []ObjectPath:
+		for _, p := range x {
+			elems = append(elems, p)
+		}
+	case []DictEntry:
+		for _, de := range x {
+			elems = append(elems, de)
+		}
+	case map[string]Variant:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			elems = append(elems, DictEntry{Key: k, Value: x[k]})
+		}
+	default:
+		if v != nil {
+			return typeErr(t, v)
+		}
+	}
+	e.uint32(0) // length, set below
+	lnoff := len(e.buf) - 4
+	e.align(alignOf(et))
+	st := len(e.buf)
+	for _, el := range elems {
+		if err := e.value(et, el); err != nil {
+			return err
+		}
+	}
+	binary.LittleEndian.PutUint32(e.buf[lnoff:], uint32(len(e.buf)-st))
+	return nil
+}
+
+// decoder unmarshals values in given byte order
+type decoder struct {
+	buf   []byte
+	off   int
+	order binary.ByteOrder
+}
+
+// errShort is returned for truncated data
+var errShort = errors.New("dbus: message data too short")
+
+func (d *decoder) align(n int) error {
+	for d.off%n != 0 {
+		if d.off >= len(d.buf) {
+			return errShort
+		}
+		d.off++
+	}
+	return nil
+}
+
+func (d *decoder) next(n int) ([]byte, error) {
+	if d.off+n > len(d.buf) || n < 0 {
+		return nil, errShort
+	}
+	b := d.buf[d.off : d.off+n]
+	d.off += n
+	return b, nil
+}
+
+func (d *decoder) uint32() (uint32, error) {
+	if err := d.align(4); err != nil {
+		return 0, err
+	}
+	b, err := d.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return d.order.Uint32(b), nil
+}
+
+func (d *decoder) uint64() (uint64, error) {
+	if err := d.align(8); err != nil {
+		return 0, err
+	}
+	b, err := d.next(8)
+	if err != nil {
+		return 0, err
+	}
+	return d.order.Uint64(b), nil
+}
+
+func (d *decoder) string() (string, error) {
+	n, err := d.uint32()
+	if err != nil {
+		return "", err
+	}
+	b, err := d.next(int(n) + 1)
+	if err != nil {
+		return "", err
+	}
+	return string(b[:n]), nil
+}
+
+func (d *decoder) signature() (string, error) {
+	b, err := d.next(1)
+	if err != nil {
+		return "", err
+	}
+	s, err := d.next(int(b[0]) + 1)
+	if err != nil {
+		return "", err
+	}
+	return string(s[:b[0]]), nil
+}
+
+// values decodes the values of given signature
+func (d *decoder) values(sig string) ([]interface{}, error) {
+	tys, err := splitTypes(sig)
+	if err != nil {
+		return nil, err
+	}
+	vals := make([]interface{}, len(tys))
+	for i, t := range tys {
+		if vals[i], err = d.value(t, 0); err != nil {
+			return nil, err
+		}
+	}
+	return vals, nil
+}
+
+// maxDepth is the maximum nesting of containers, as in the specification
+const maxDepth = 64
+
+// value decodes a value of given complete type
+func (d *decoder) value(t string, depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, ErrSignature
+	}
+	switch t[0] {
+	case 'y':
+		b, err := d.next(1)
+		if err != nil {
+			return nil, err
+		}
+		return b[0], nil
+	case 'b':
+		u, err := d.uint32()
+		return u != 0, err
+	case 'n', 'q':
+		if err := d.align(2); err != nil {
+			return nil, err
+		}
+		b, err := d.next(2)
+		if err != nil {
+			return nil, err
+		}
+		u := d.order.Uint16(b)
+		if t[0] == 'n' {
+			return int16(u), nil
+		}
+		return u, nil
+	case 'i':
+		u, err := d.uint32()
+		return int32(u), err
+	case 'u', 'h':
+		return d.uint32()
+	case 'x':
+		u, err := d.uint64()
+		return int64(u), err
+	case 't':
+		return d.uint64()
+	case 'd':
+		u, err := d.uint64()
+		return math.Float64frombits(u), err
+	case 's':
+		return d.string()
+	case 'o':
+		s, err := d.string()
+		return ObjectPath(s), err
+	case 'g':
+		s, err := d.signature()
+		return Signature(s), err
+	case 'v':
+		s, err := d.signature()
+		if err != nil {
+			return nil, err
+		}
+		if _, rest, err := nextType(s); err != nil || rest != "" {
+			return nil, ErrSignature
+		}
+		v, err := d.value(s, depth+1)
+		return Variant{Sig: Signature(s), Value: v}, err
+	case 'a':
+		n, err := d.uint32()
+		if err != nil {
+			return nil, err
+		}
+		et := t[1:]
+		if err := d.align(alignOf(et)); err != nil {
+			return nil, err
+		}
+		ed := d.off + int(n)
+		if ed > len(d.buf) {
+			return nil, errShort
+		}
+		if et == "y" {
+			b, _ := d.next(int(n))
+			return append([]byte(nil), b...), nil
+		}
+		if et[0] == '{' {
+			var des []DictEntry
+			for d.off < ed {
+				v, err := d.value(et, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				des = append(des, v.(DictEntry))
+			}
+			return des, nil
+		}
+		elems := []interface{}{}
+		for d.off < ed {
+			v, err := d.value(et, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, v)
+		}
+		return elems, nil
+	case '(', '{':
+		if err := d.align(8); err != nil {
+			return nil, err
+		}
+		tys, err := splitTypes(t[1 : len(t)-1])
+		if err != nil {
+			return nil, err
+		}
+		st := make(Struct, len(tys))
+		for i, ft := range tys {
+			if st[i], err = d.value(ft, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		if t[0] == '{' {
+			return DictEntry{Key: st[0], Value: st[1]}, nil
+		}
+		return st, nil
+	}
+	return nil, ErrSignature
+}
+
+// Marshal returns the little-endian encoding of given values of given
+// signature, starting at an 8-byte aligned offset (as for a message body)
+func Marshal(sig string, vals ...interface{}) ([]byte, error) {
+	e := &encoder{}
+	err := e.values(sig, vals)
+	return e.buf, err
+}
+
+// Unmarshal decodes the values of given signature from the little-endian
+// encoding, which starts at an 8-byte aligned offset
+func Unmarshal(b []byte, sig string) ([]interface{}, error) {
+	d := &decoder{buf: b, order: binary.LittleEndian}
+	return d.values(sig)
+}
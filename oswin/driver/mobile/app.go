@@ -0,0 +1,587 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build android ios
+
+// Package mobile provides an oswin driver for Android and iOS, based on
+// golang.org/x/mobile, so that gi applications can be built with gomobile
+// (e.g., gomobile build -target=android) and run with the same widget code
+// as on the desktop.
+//
+// There is a single full-screen OS window, sized to the display and
+// resized when it is rotated: each oswin window fills it, and only the most
+// recently opened (top) one is shown and gets the input -- dialogs and
+// menus should be popups in the main window (see gi.DialogsSepWindow,
+// which gi turns off for mobile platforms).  All drawing is done in
+// software, as in the offscreen driver, and each published frame is
+// uploaded to an OpenGL ES texture and drawn to the display.
+//
+// Touches are sent as touch.Events, along with the pinch-zoom, pan and
+// long-press gestures recognized from them (touch.Gestures), and the
+// first touch is also sent as the left mouse button, so that all widgets
+// work without changes.  The on-screen keyboard is shown while a text
+// editing widget has the focus (see ime.Window), and the characters typed
+// on it are sent as key events -- on iOS, via a hidden text input view,
+// as x/mobile does not handle keyboard input there.
+//
+// The oswin/gpu interfaces are not available, so gi3d scenes cannot be
+// rendered, and there are no file dialogs, tray or audio, and the
+// clipboard is private to the app.
+//
+// The driver is selected by the oswin/driver package when building for
+// android or ios, and uses golang.org/x/mobile, which is required in the
+// go.mod of gi -- the apps are packaged with gomobile build.
+package mobile
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/clip"
+	"github.com/goki/gi/oswin/cursor"
+	"github.com/goki/gi/oswin/window"
+	"golang.org/x/mobile/app"
+	"golang.org/x/mobile/event/key"
+	"golang.org/x/mobile/event/lifecycle"
+	"golang.org/x/mobile/event/paint"
+	"golang.org/x/mobile/event/size"
+	"golang.org/x/mobile/event/touch"
+	"golang.org/x/mobile/exp/gl/glutil"
+	"golang.org/x/mobile/geom"
+	"golang.org/x/mobile/gl"
+)
+
+var theApp = &appImpl{
+	winlist:      make([]*windowImpl, 0),
+	screens:      make([]*oswin.Screen, 0),
+	name:         "GoGi",
+	quitCloseCnt: make(chan struct{}),
+}
+
+type appImpl struct {
+	mu            sync.Mutex
+	mapp          app.App // the x/mobile app, which sends the events
+	winlist       []*windowImpl
+	screens       []*oswin.Screen
+	ctxtwin       *windowImpl // context window, dynamically set, for e.g., pointer and other methods
+	name          string
+	about         string
+	quitting      bool          // set to true when quitting and closing windows
+	quitCloseCnt  chan struct{} // counts windows to make sure all are closed before done
+	quitReqFunc   func()
+	quitCleanFunc func()
+	stopOnce      sync.Once
+	started       bool // mainCallback has been started, after the first size event
+
+	// drawing of published frames on the display -- only used in the event
+	// loop of the x/mobile app
+	glctx  gl.Context     // nil when the app is not visible
+	images *glutil.Images // makes the image textures
+	image  *glutil.Image  // texture that the frames are uploaded to
+	sz     size.Event     // current size of the display
+}
+
+var mainCallback func(oswin.App)
+
+// funcRun is a function run in the event loop of the x/mobile app, via
+// RunOnMain
+type funcRun struct {
+	f    func()
+	done chan bool
+}
+
+// stopEvent ends the event loop of the x/mobile app
+type stopEvent struct{}
+
+// Main runs the app, calling f in a separate goroutine once the size of the
+// display is known -- when f returns, the app ends automatically.
+func Main(f func(oswin.App)) {
+	mainCallback = f
+	oswin.TheApp = theApp
+	app.Main(func(a app.App) {
+		theApp.mapp = a
+		for e := range a.Events() {
+			switch e := a.Filter(e).(type) {
+			case lifecycle.Event:
+				theApp.lifecycle(e)
+			case size.Event:
+				theApp.resized(e)
+			case paint.Event:
+				theApp.paint()
+			case touch.Event:
+				if w := theApp.topWin(); w != nil {
+					w.touchEvent(e)
+				}
+			case key.Event:
+				if w := theApp.topWin(); w != nil {
+					w.keyEvent(e)
+				}
+			case funcRun:
+				e.f()
+				if e.done != nil {
+					e.done <- true
+				}
+			case stopEvent:
+				theApp.releaseGL()
+				return
+			}
+		}
+	})
+}
+
+// lifecycle handles the change of the stage of the app: the windows are
+// minimized while it is not visible, which is when drawing is possible,
+// and have the focus while it is focused
+func (app *appImpl) lifecycle(e lifecycle.Event) {
+	switch e.Crosses(lifecycle.StageVisible) {
+	case lifecycle.CrossOn:
+		app.glctx, _ = e.DrawContext.(gl.Context)
+		if app.glctx != nil {
+			app.images = glutil.NewImages(app.glctx)
+		}
+		app.forWins(func(w *windowImpl) { w.setMinimized(false) })
+		app.mapp.Send(paint.Event{})
+	case lifecycle.CrossOff:
+		app.releaseGL()
+		app.forWins(func(w *windowImpl) { w.setMinimized(true) })
+	}
+	switch e.Crosses(lifecycle.StageFocused) {
+	case lifecycle.CrossOn:
+		if w := app.topWin(); w != nil {
+			w.setFocus(true)
+		}
+	case lifecycle.CrossOff:
+		app.forWins(func(w *windowImpl) { w.setFocus(false) })
+	}
+	if e.To == lifecycle.StageDead && !app.quitting {
+		go app.QuitClean()
+	}
+}
+
+// releaseGL releases the textures, when the app is no longer visible
+func (app *appImpl) releaseGL() {
+	if app.image != nil {
+		app.image.Release()
+		app.image = nil
+	}
+	if app.images != nil {
+		app.images.Release()
+		app.images = nil
+	}
+	app.glctx = nil
+}
+
+// resized updates the screen and the size of all the windows from a size
+// event, and starts the mainCallback on the first one
+func (app *appImpl) resized(e size.Event) {
+	app.sz = e
+	app.getScreens(e)
+	app.forWins(func(w *windowImpl) { w.SetPixSize(e.Size()) })
+	if !app.started {
+		app.started = true
+		go func() {
+			mainCallback(app)
+			app.stopMain()
+		}()
+	}
+}
+
+// paint draws the last frame published by the top window to the display
+func (app *appImpl) paint() {
+	if app.glctx == nil {
+		return
+	}
+	app.glctx.ClearColor(0, 0, 0, 1)
+	app.glctx.Clear(gl.COLOR_BUFFER_BIT)
+	w := app.topWin()
+	if w == nil {
+		app.mapp.Publish()
+		return
+	}
+	w.mu.Lock()
+	frame := w.frame
+	if frame == nil {
+		w.mu.Unlock()
+		app.mapp.Publish()
+		return
+	}
+	fsz := frame.Rect.Size()
+	if app.image == nil || app.image.RGBA.Rect.Size() != fsz {
+		if app.image != nil {
+			app.image.Release()
+		}
+		app.image = app.images.NewImage(fsz.X, fsz.Y)
+	}
+	copy(app.image.RGBA.Pix, frame.Pix)
+	w.mu.Unlock()
+	app.image.Upload()
+	sz := app.sz
+	app.image.Draw(sz, geomPt(sz, 0, 0), geomPt(sz, fsz.X, 0), geomPt(sz, 0, fsz.Y), app.image.RGBA.Rect)
+	app.mapp.Publish()
+}
+
+// geomPt returns the position on the display of given pixel position
+func geomPt(sz size.Event, x, y int) geom.Point {
+	return geom.Point{X: geom.Pt(float32(x) / sz.PixelsPerPt), Y: geom.Pt(float32(y) / sz.PixelsPerPt)}
+}
+
+// topWin returns the top window, which is shown and gets the input, or nil
+// if there are none
+func (app *appImpl) topWin() *windowImpl {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	if len(app.winlist) == 0 {
+		return nil
+	}
+	return app.winlist[len(app.winlist)-1]
+}
+
+// forWins calls given function on each window, outside of the mutex
+func (app *appImpl) forWins(fun func(w *windowImpl)) {
+	app.mu.Lock()
+	wins := make([]*windowImpl, len(app.winlist))
+	copy(wins, app.winlist)
+	app.mu.Unlock()
+	for _, w := range wins {
+		fun(w)
+	}
+}
+
+// RunOnMain runs given function in the event loop of the x/mobile app
+func (app *appImpl) RunOnMain(f func()) {
+	if app.mapp == nil {
+		f()
+	} else {
+		done := make(chan bool)
+		app.mapp.Send(funcRun{f: f, done: done})
+		<-done
+	}
+}
+
+// GoRunOnMain runs given function in the event loop of the x/mobile app and
+// returns immediately
+func (app *appImpl) GoRunOnMain(f func()) {
+	if app.mapp == nil {
+		go f()
+		return
+	}
+	app.mapp.Send(funcRun{f: f, done: nil})
+}
+
+// SendEmptyEvent is a no-op, as the events are sent by x/mobile
+func (app *appImpl) SendEmptyEvent() {
+}
+
+// PollEvents is a no-op, as the events are sent by x/mobile
+func (app *appImpl) PollEvents() {
+}
+
+// stopMain stops the event loop and thus terminates the app
+func (app *appImpl) stopMain() {
+	app.stopOnce.Do(func() { app.mapp.Send(stopEvent{}) })
+}
+
+// getScreens sets the single screen from given size event -- window
+// units are density-independent pixels, 1/160 inch, as on Android
+func (app *appImpl) getScreens(e size.Event) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	pdpi := e.PixelsPerPt * 72 // points are 1/72 inch
+	dpr := pdpi / 160
+	if dpr < 1 {
+		dpr = 1
+	}
+	psz := e.Size()
+	sc := &oswin.Screen{
+		Name:             "Display",
+		Geometry:         image.Rectangle{Max: image.Point{int(float32(psz.X) / dpr), int(float32(psz.Y) / dpr)}},
+		DevicePixelRatio: dpr,
+		PixSize:          psz,
+		PhysicalSize:     image.Point{int(25.4 * float32(psz.X) / pdpi), int(25.4 * float32(psz.Y) / pdpi)},
+		PhysicalDPI:      pdpi,
+		LogicalDPI:       pdpi,
+		Depth:            32,
+		RefreshRate:      60,
+	}
+	if len(app.screens) == 0 {
+		app.screens = []*oswin.Screen{sc}
+	} else {
+		*app.screens[0] = *sc // keep the pointer held by the windows
+	}
+}
+
+////////////////////////////////////////////////////////
+//  Window
+
+// NewWindow makes a new window, which fills the display and is shown on
+// top of the others
+func (app *appImpl) NewWindow(opts *oswin.NewWindowOptions) (oswin.Window, error) {
+	if len(app.winlist) == 0 && oswin.InitScreenLogicalDPIFunc != nil {
+		oswin.InitScreenLogicalDPIFunc()
+	}
+	sc := app.screens[0]
+
+	if opts == nil {
+		opts = &oswin.NewWindowOptions{}
+	}
+	opts.Fixup()
+
+	w := &windowImpl{
+		app: app,
+		WindowBase: oswin.WindowBase{
+			Titl:        opts.GetTitle(),
+			Flag:        opts.Flags,
+			WnSize:      sc.Geometry.Size(),
+			PxSize:      sc.PixSize,
+			DevPixRatio: sc.DevicePixelRatio,
+			PhysDPI:     sc.PhysicalDPI,
+			LogDPI:      sc.LogicalDPI,
+		},
+	}
+	w.winTex = &textureImpl{name: "WinTex", size: w.PxSize}
+	w.winTex.Activate(0)
+	w.back = &textureImpl{name: "Back", size: w.PxSize}
+	w.back.Activate(0)
+	w.gestures.Send = w.Send
+
+	app.mu.Lock()
+	for _, ow := range app.winlist {
+		ow.setFocus(false)
+	}
+	app.winlist = append(app.winlist, w)
+	app.mu.Unlock()
+	w.setFocus(true)
+
+	w.sendWindowEvent(window.Paint)
+	w.sendWindowEvent(window.Paint)
+
+	return w, nil
+}
+
+// DeleteWin removes given window, showing the one under it
+func (app *appImpl) DeleteWin(w *windowImpl) {
+	app.mu.Lock()
+	for i, wl := range app.winlist {
+		if wl == w {
+			app.winlist = append(app.winlist[:i], app.winlist[i+1:]...)
+			break
+		}
+	}
+	if app.ctxtwin == w {
+		app.ctxtwin = nil
+	}
+	app.mu.Unlock()
+	if tw := app.topWin(); tw != nil {
+		tw.setFocus(true)
+		tw.sendWindowEvent(window.Paint)
+	}
+}
+
+func (app *appImpl) NScreens() int {
+	return len(app.screens)
+}
+
+func (app *appImpl) Screen(scrN int) *oswin.Screen {
+	sz := len(app.screens)
+	if scrN < sz {
+		return app.screens[scrN]
+	}
+	return nil
+}
+
+func (app *appImpl) ScreenByName(name string) *oswin.Screen {
+	for _, sc := range app.screens {
+		if sc.Name == name {
+			return sc
+		}
+	}
+	return nil
+}
+
+func (app *appImpl) NoScreens() bool {
+	return false
+}
+
+func (app *appImpl) NWindows() int {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	return len(app.winlist)
+}
+
+func (app *appImpl) Window(win int) oswin.Window {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	sz := len(app.winlist)
+	if win < sz {
+		return app.winlist[win]
+	}
+	return nil
+}
+
+func (app *appImpl) WindowByName(name string) oswin.Window {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	for _, win := range app.winlist {
+		if win.Name() == name {
+			return win
+		}
+	}
+	return nil
+}
+
+func (app *appImpl) WindowInFocus() oswin.Window {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	for _, win := range app.winlist {
+		if win.IsFocus() {
+			return win
+		}
+	}
+	return nil
+}
+
+func (app *appImpl) ContextWindow() oswin.Window {
+	app.mu.Lock()
+	cw := app.ctxtwin
+	app.mu.Unlock()
+	return cw
+}
+
+func (app *appImpl) NewTexture(win oswin.Window, size image.Point) oswin.Texture {
+	tx := &textureImpl{size: size}
+	tx.Activate(0)
+	return tx
+}
+
+func (app *appImpl) Platform() oswin.Platforms {
+	return platform
+}
+
+func (app *appImpl) Name() string {
+	return app.name
+}
+
+func (app *appImpl) SetName(name string) {
+	app.name = name
+}
+
+func (app *appImpl) About() string {
+	return app.about
+}
+
+func (app *appImpl) SetAbout(about string) {
+	app.about = about
+}
+
+// PrefsDir returns the user config directory of the app, which is private
+// to it on mobile platforms
+func (app *appImpl) PrefsDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return os.TempDir()
+	}
+	return dir
+}
+
+func (app *appImpl) GoGiPrefsDir() string {
+	pdir := filepath.Join(app.PrefsDir(), "GoGi")
+	os.MkdirAll(pdir, 0755)
+	return pdir
+}
+
+func (app *appImpl) AppPrefsDir() string {
+	pdir := filepath.Join(app.PrefsDir(), app.Name())
+	os.MkdirAll(pdir, 0755)
+	return pdir
+}
+
+// FontPaths returns the font directory of the platform
+func (app *appImpl) FontPaths() []string {
+	return fontPaths
+}
+
+// OpenURL is not supported, as there is no portable way to start another
+// app
+func (app *appImpl) OpenURL(url string) {
+}
+
+func (app *appImpl) HasFileDialog() bool {
+	return false
+}
+
+func (app *appImpl) FileDialog(win oswin.Window, opts *oswin.FileDialogOptions) (string, error) {
+	return "", nil
+}
+
+// IsDark returns false, as the dark mode of the platform is not available
+// via x/mobile
+func (app *appImpl) IsDark() bool {
+	return false
+}
+
+func (app *appImpl) ClipBoard(win oswin.Window) clip.Board {
+	app.mu.Lock()
+	app.ctxtwin, _ = win.(*windowImpl)
+	app.mu.Unlock()
+	return &theClip
+}
+
+func (app *appImpl) Cursor(win oswin.Window) cursor.Cursor {
+	app.mu.Lock()
+	app.ctxtwin, _ = win.(*windowImpl)
+	app.mu.Unlock()
+	return &theCursor
+}
+
+func (app *appImpl) SetQuitReqFunc(fun func()) {
+	app.quitReqFunc = fun
+}
+
+func (app *appImpl) SetQuitCleanFunc(fun func()) {
+	app.quitCleanFunc = fun
+}
+
+func (app *appImpl) QuitReq() {
+	if app.quitting {
+		return
+	}
+	if app.quitReqFunc != nil {
+		app.quitReqFunc()
+	} else {
+		app.Quit()
+	}
+}
+
+func (app *appImpl) IsQuitting() bool {
+	return app.quitting
+}
+
+func (app *appImpl) QuitClean() {
+	app.quitting = true
+	if app.quitCleanFunc != nil {
+		app.quitCleanFunc()
+	}
+	app.mu.Lock()
+	nwin := len(app.winlist)
+	for i := nwin - 1; i >= 0; i-- {
+		win := app.winlist[i]
+		go win.Close()
+	}
+	app.mu.Unlock()
+	for i := 0; i < nwin; i++ {
+		<-app.quitCloseCnt
+	}
+}
+
+// Quit closes all the windows and ends the app
+func (app *appImpl) Quit() {
+	if app.quitting {
+		return
+	}
+	app.QuitClean()
+	app.stopMain()
+}
@@ -0,0 +1,127 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build android ios
+
+package mobile
+
+import (
+	"sync"
+
+	"github.com/goki/gi/oswin/cursor"
+	"github.com/goki/gi/oswin/mimedata"
+)
+
+/////////////////////////////////////////////////////////////////
+//   Clipboard
+
+// clipImpl is an in-memory clipboard, private to the app -- the clipboards
+// of the platforms are only accessible via their Java / Objective-C APIs
+type clipImpl struct {
+	mu   sync.Mutex
+	data mimedata.Mimes
+}
+
+var theClip = clipImpl{}
+
+func (ci *clipImpl) IsEmpty() bool {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	return len(ci.data) == 0
+}
+
+// Read returns the data of the first of given types that is on the
+// clipboard, or all of the data if types is empty
+func (ci *clipImpl) Read(types []string) mimedata.Mimes {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	if len(types) == 0 {
+		return ci.data
+	}
+	for _, typ := range types {
+		for _, d := range ci.data {
+			if d.Type == typ {
+				return mimedata.Mimes{d}
+			}
+		}
+	}
+	return nil
+}
+
+func (ci *clipImpl) Write(data mimedata.Mimes) error {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	ci.data = data
+	return nil
+}
+
+func (ci *clipImpl) Clear() {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	ci.data = nil
+}
+
+//////////////////////////////////////////////////////
+//  Cursor
+
+// cursorImpl just records the cursor state, as there is no cursor on a
+// touch screen
+type cursorImpl struct {
+	cursor.CursorBase
+	mu sync.Mutex
+}
+
+var theCursor = cursorImpl{CursorBase: cursor.CursorBase{Vis: true}}
+
+func (c *cursorImpl) Set(sh cursor.Shapes) {
+	c.mu.Lock()
+	c.Cur = sh
+	c.mu.Unlock()
+}
+
+func (c *cursorImpl) Push(sh cursor.Shapes) {
+	c.mu.Lock()
+	c.PushStack(sh)
+	c.mu.Unlock()
+}
+
+func (c *cursorImpl) Pop() {
+	c.mu.Lock()
+	c.PopStack()
+	c.mu.Unlock()
+}
+
+func (c *cursorImpl) Hide() {
+	c.mu.Lock()
+	c.Vis = false
+	c.mu.Unlock()
+}
+
+func (c *cursorImpl) Show() {
+	c.mu.Lock()
+	c.Vis = true
+	c.mu.Unlock()
+}
+
+func (c *cursorImpl) PushIfNot(sh cursor.Shapes) bool {
+	c.mu.Lock()
+	if c.Cur == sh {
+		c.mu.Unlock()
+		return false
+	}
+	c.mu.Unlock()
+	c.Push(sh)
+	return true
+}
+
+func (c *cursorImpl) PopIf(sh cursor.Shapes) bool {
+	c.mu.Lock()
+	if c.Cur == sh {
+		c.mu.Unlock()
+		c.Pop()
+		return true
+	}
+	c.mu.Unlock()
+	return false
+}
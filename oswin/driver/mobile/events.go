@@ -0,0 +1,223 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build android ios
+
+package mobile
+
+import (
+	"image"
+	"time"
+
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/oswin/touch"
+	mkey "golang.org/x/mobile/event/key"
+	mtouch "golang.org/x/mobile/event/touch"
+)
+
+// the x/mobile events of the top window are translated into oswin events:
+// each touch is sent as a touch.Event, followed by any gestures recognized
+// from the touches, and a single touch is also sent as the left mouse
+// button, so that widgets work as with a mouse -- when a second touch
+// begins, the mouse button is released, and only the gestures apply until
+// all touches have ended.  The key events of hardware keyboards, and of the
+// on-screen keyboard on Android, are sent as key events, with ChordEvents
+// for shortcuts and typed characters, as for the glos driver.
+
+// touchEvent sends the events for a touch event
+func (w *windowImpl) touchEvent(e mtouch.Event) {
+	where := image.Point{int(e.X), int(e.Y)}
+	seq := touch.Sequence(e.Sequence)
+	act := touch.Move
+	switch e.Type {
+	case mtouch.TypeBegin:
+		act = touch.Begin
+	case mtouch.TypeEnd:
+		act = touch.End
+	}
+	event := &touch.Event{
+		Where:    where,
+		Sequence: seq,
+		Action:   act,
+	}
+	event.Init()
+	w.Send(event)
+	w.gestures.Touch(event)
+
+	w.mu.Lock()
+	from := w.mousePos
+	switch act {
+	case touch.Begin:
+		w.nTouches++
+		if w.nTouches == 1 {
+			w.mouseSeq = seq
+			w.mouseOn = true
+			w.mousePos = where
+			w.mu.Unlock()
+			w.mouseButton(where, true)
+			return
+		}
+		if w.mouseOn { // now a gesture
+			w.mouseOn = false
+			w.mu.Unlock()
+			w.mouseButton(from, false)
+			return
+		}
+	case touch.Move:
+		if w.mouseOn && seq == w.mouseSeq {
+			w.mousePos = where
+			w.mu.Unlock()
+			w.mouseDrag(where, from)
+			return
+		}
+	case touch.End:
+		if w.nTouches > 0 {
+			w.nTouches--
+		}
+		if w.mouseOn && seq == w.mouseSeq {
+			w.mouseOn = false
+			w.mousePos = where
+			w.mu.Unlock()
+			w.mouseButton(where, false)
+			return
+		}
+	}
+	w.mu.Unlock()
+}
+
+// mouseButton sends the mouse.Event for the press or release of the left
+// button by a touch
+func (w *windowImpl) mouseButton(where image.Point, press bool) {
+	act := mouse.Release
+	if press {
+		act = mouse.Press
+		now := time.Now().UnixNano()
+		w.mu.Lock()
+		if time.Duration(now-w.mouseClickT) < time.Duration(mouse.DoubleClickMSec)*time.Millisecond {
+			act = mouse.DoubleClick
+		}
+		w.mouseClickT = now
+		w.mu.Unlock()
+	}
+	event := &mouse.Event{
+		Where:  where,
+		Button: mouse.Left,
+		Action: act,
+	}
+	event.Init()
+	w.Send(event)
+}
+
+// mouseDrag sends the mouse.DragEvent for the move of a touch that is the
+// left mouse button
+func (w *windowImpl) mouseDrag(where, from image.Point) {
+	event := &mouse.DragEvent{
+		MoveEvent: mouse.MoveEvent{
+			Event: mouse.Event{
+				Where:  where,
+				Button: mouse.Left,
+				Action: mouse.Drag,
+			},
+			From: from,
+		},
+	}
+	event.Init()
+	w.Send(event)
+}
+
+// mobileMods returns the key.Modifiers bits for given x/mobile modifiers
+func mobileMods(mm mkey.Modifiers) int32 {
+	m := int32(0)
+	if mm&mkey.ModShift != 0 {
+		key.SetModifierBits(&m, key.Shift)
+	}
+	if mm&mkey.ModControl != 0 {
+		key.SetModifierBits(&m, key.Control)
+	}
+	if mm&mkey.ModAlt != 0 {
+		key.SetModifierBits(&m, key.Alt)
+	}
+	if mm&mkey.ModMeta != 0 {
+		key.SetModifierBits(&m, key.Meta)
+	}
+	return m
+}
+
+// keyEvent sends the key.Event for a key event, and for a press, the
+// key.ChordEvent for a shortcut, or for the character typed -- the codes of
+// x/mobile are the same as the key.Codes, both being USB HID codes.  Keys
+// of the on-screen keyboard may only have a character, with no press or
+// release (DirNone), which is sent as a press.
+func (w *windowImpl) keyEvent(e mkey.Event) {
+	ec := key.Codes(e.Code)
+	mods := mobileMods(e.Modifiers)
+	act := key.Press
+	if e.Direction == mkey.DirRelease {
+		act = key.Release
+	}
+	rn := e.Rune
+	if rn < 0 {
+		rn = 0
+	}
+	if e.Direction != mkey.DirNone || ec != key.CodeUnknown {
+		event := &key.Event{
+			Code:      ec,
+			Rune:      rn,
+			Modifiers: mods,
+			Action:    act,
+		}
+		event.Init()
+		w.Send(event)
+	}
+	if act != key.Press {
+		return
+	}
+	_, mapped := key.CodeRuneMap[ec]
+	if ec != key.CodeUnknown && ec < key.CodeLeftControl && (key.HasAnyModifierBits(mods, key.Control, key.Meta) || !mapped || ec == key.CodeTab) {
+		w.sendChord(ec, 0, mods)
+		return
+	}
+	if rn > 0 {
+		w.sendChord(key.CodeUnknown, rn, mods)
+	}
+}
+
+// sendChord sends a key.ChordEvent for given key code or typed character
+func (w *windowImpl) sendChord(ec key.Codes, rn rune, mods int32) {
+	if ec != key.CodeUnknown {
+		rn = key.CodeRuneMap[ec]
+	}
+	che := &key.ChordEvent{
+		Event: key.Event{
+			Code:      ec,
+			Rune:      rn,
+			Modifiers: mods,
+			Action:    key.Press,
+		},
+	}
+	che.Init()
+	w.Send(che)
+}
+
+// typeText sends the key events for text typed on the on-screen keyboard,
+// for platforms that deliver it as text rather than key events (iOS) --
+// newlines are sent as the return key
+func (w *windowImpl) typeText(text string) {
+	for _, rn := range text {
+		if rn == '\n' || rn == '\r' {
+			w.keyEvent(mkey.Event{Code: mkey.CodeReturnEnter, Direction: mkey.DirPress})
+			w.keyEvent(mkey.Event{Code: mkey.CodeReturnEnter, Direction: mkey.DirRelease})
+			continue
+		}
+		w.sendChord(key.CodeUnknown, rn, 0)
+	}
+}
+
+// typeBackspace sends the key events for the delete key of the on-screen
+// keyboard, for platforms that deliver it as an edit (iOS)
+func (w *windowImpl) typeBackspace() {
+	w.keyEvent(mkey.Event{Code: mkey.CodeDeleteBackspace, Direction: mkey.DirPress})
+	w.keyEvent(mkey.Event{Code: mkey.CodeDeleteBackspace, Direction: mkey.DirRelease})
+}
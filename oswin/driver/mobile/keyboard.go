@@ -0,0 +1,40 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build android ios
+
+package mobile
+
+import (
+	"image"
+
+	"github.com/goki/gi/oswin/ime"
+)
+
+// the on-screen keyboard is shown while text input is active, as set by
+// the GUI via the ime.Window interface when a text editing widget gets or
+// loses the keyboard focus -- showKeyboard is implemented for each
+// platform, in keyboard_android.go and keyboard_ios.go
+
+// SetIMEActive shows the on-screen keyboard if on, and hides it otherwise
+func (w *windowImpl) SetIMEActive(on bool) {
+	w.mu.Lock()
+	changed := w.imeActive != on
+	w.imeActive = on
+	w.mu.Unlock()
+	if changed {
+		showKeyboard(on)
+	}
+}
+
+// SetIMECaret records the text cursor, which is not used by the on-screen
+// keyboards, as they do not overlap the text
+func (w *windowImpl) SetIMECaret(caret image.Rectangle) {
+	w.mu.Lock()
+	w.imeCaret = caret
+	w.mu.Unlock()
+}
+
+// check for interface implementation
+var _ ime.Window = &windowImpl{}
@@ -0,0 +1,76 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build android
+
+package mobile
+
+/*
+#include <jni.h>
+#include <stdlib.h>
+
+// showKeyboard shows or hides the on-screen keyboard via the
+// InputMethodManager of the activity
+static void showKeyboard(uintptr_t jniEnv, uintptr_t ctx, int show) {
+	JNIEnv* env = (JNIEnv*)jniEnv;
+	jobject act = (jobject)ctx;
+	jclass actCls = (*env)->GetObjectClass(env, act);
+	jmethodID getSys = (*env)->GetMethodID(env, actCls, "getSystemService", "(Ljava/lang/String;)Ljava/lang/Object;");
+	jstring svc = (*env)->NewStringUTF(env, "input_method");
+	jobject imm = (*env)->CallObjectMethod(env, act, getSys, svc);
+	jclass immCls = (*env)->GetObjectClass(env, imm);
+	if (show) {
+		jmethodID toggle = (*env)->GetMethodID(env, immCls, "toggleSoftInput", "(II)V");
+		(*env)->CallVoidMethod(env, imm, toggle, 2, 0); // SHOW_FORCED
+	} else {
+		jmethodID getWin = (*env)->GetMethodID(env, actCls, "getWindow", "()Landroid/view/Window;");
+		jobject win = (*env)->CallObjectMethod(env, act, getWin);
+		jclass winCls = (*env)->GetObjectClass(env, win);
+		jmethodID getDecor = (*env)->GetMethodID(env, winCls, "getDecorView", "()Landroid/view/View;");
+		jobject view = (*env)->CallObjectMethod(env, win, getDecor);
+		jclass viewCls = (*env)->GetObjectClass(env, view);
+		jmethodID getToken = (*env)->GetMethodID(env, viewCls, "getWindowToken", "()Landroid/os/IBinder;");
+		jobject token = (*env)->CallObjectMethod(env, view, getToken);
+		jmethodID hide = (*env)->GetMethodID(env, immCls, "hideSoftInputFromWindow", "(Landroid/os/IBinder;I)Z");
+		(*env)->CallBooleanMethod(env, imm, hide, token, 0);
+		(*env)->DeleteLocalRef(env, token);
+		(*env)->DeleteLocalRef(env, viewCls);
+		(*env)->DeleteLocalRef(env, view);
+		(*env)->DeleteLocalRef(env, winCls);
+		(*env)->DeleteLocalRef(env, win);
+	}
+	(*env)->DeleteLocalRef(env, immCls);
+	(*env)->DeleteLocalRef(env, imm);
+	(*env)->DeleteLocalRef(env, svc);
+	(*env)->DeleteLocalRef(env, actCls);
+}
+*/
+import "C"
+
+import (
+	"log"
+
+	"github.com/goki/gi/oswin"
+	"golang.org/x/mobile/app"
+)
+
+const platform = oswin.Android
+
+var fontPaths = []string{"/system/fonts"}
+
+// showKeyboard shows or hides the on-screen keyboard -- the characters
+// typed on it are sent as key events by x/mobile
+func showKeyboard(show bool) {
+	sh := C.int(0)
+	if show {
+		sh = 1
+	}
+	err := app.RunOnJVM(func(vm, jniEnv, ctx uintptr) error {
+		C.showKeyboard(C.uintptr_t(jniEnv), C.uintptr_t(ctx), sh)
+		return nil
+	})
+	if err != nil {
+		log.Printf("oswin/driver/mobile: showKeyboard: %v\n", err)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build ios
+
+package mobile
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework UIKit -framework Foundation
+
+void showKeyboard(int show);
+*/
+import "C"
+
+import (
+	"github.com/goki/gi/oswin"
+)
+
+const platform = oswin.IOS
+
+var fontPaths = []string{"/System/Library/Fonts"}
+
+// showKeyboard shows or hides the on-screen keyboard, by making a hidden
+// text input view the first responder (see keyboard_ios.m) -- the text
+// typed on it is sent to the window with the focus as key events
+func showKeyboard(show bool) {
+	sh := C.int(0)
+	if show {
+		sh = 1
+	}
+	C.showKeyboard(sh)
+}
+
+// keyboardInsert is called by the text input view with the text typed
+//
+//export keyboardInsert
+func keyboardInsert(text *C.char) {
+	if w := theApp.topWin(); w != nil {
+		w.typeText(C.GoString(text))
+	}
+}
+
+// keyboardDelete is called by the text input view for the delete key
+//
+//export keyboardDelete
+func keyboardDelete() {
+	if w := theApp.topWin(); w != nil {
+		w.typeBackspace()
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build ios
+
+#import <UIKit/UIKit.h>
+#include "_cgo_export.h"
+
+// GoGiKeyInput is a hidden view that receives the text typed on the
+// on-screen keyboard while it is the first responder, passing it to Go
+@interface GoGiKeyInput : UIView <UIKeyInput>
+@end
+
+@implementation GoGiKeyInput
+- (BOOL)canBecomeFirstResponder {
+	return YES;
+}
+
+- (BOOL)hasText {
+	return YES; // so that delete is always sent
+}
+
+- (void)insertText:(NSString *)text {
+	keyboardInsert((char *)[text UTF8String]);
+}
+
+- (void)deleteBackward {
+	keyboardDelete();
+}
+@end
+
+static GoGiKeyInput *keyInput = nil;
+
+void showKeyboard(int show) {
+	dispatch_async(dispatch_get_main_queue(), ^{
+		if (keyInput == nil) {
+			keyInput = [[GoGiKeyInput alloc] initWithFrame:CGRectZero];
+			[[UIApplication sharedApplication].keyWindow addSubview:keyInput];
+		}
+		if (show) {
+			[keyInput becomeFirstResponder];
+		} else {
+			[keyInput resignFirstResponder];
+		}
+	});
+}
@@ -0,0 +1,236 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build android ios
+
+package mobile
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"os"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/driver/internal/drawer"
+	"github.com/goki/mat32"
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+)
+
+// textureImpl is a texture held entirely in memory as an image.RGBA,
+// which all drawing is done into in software, as in the offscreen driver.
+// Y = 0 is always at the top, regardless of BotZero, which only matters for
+// the GPU.
+type textureImpl struct {
+	name    string
+	size    image.Point
+	botZero bool
+	img     *image.RGBA
+}
+
+// Name returns the name of the texture (filename without extension
+// by default)
+func (tx *textureImpl) Name() string {
+	return tx.name
+}
+
+// SetName sets the name of the texture
+func (tx *textureImpl) SetName(name string) {
+	tx.name = name
+}
+
+// Open loads texture image from file.
+// format inferred from filename -- JPEG and PNG
+// supported by default.
+func (tx *textureImpl) Open(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	im, _, err := image.Decode(file)
+	if err != nil {
+		return err
+	}
+	return tx.SetImage(im)
+}
+
+// Image returns the current image, as an *image.RGBA
+func (tx *textureImpl) Image() image.Image {
+	if tx.img == nil {
+		return nil
+	}
+	return tx.img
+}
+
+// GrabImage returns the current contents of the texture, which is the
+// same as Image.  Returned image points to single internal image.RGBA
+// used for this texture -- copy before modifying and to retain values.
+func (tx *textureImpl) GrabImage() image.Image {
+	return tx.Image()
+}
+
+// ImageFlipY flips the Y axis from a source image.RGBA into a dest.
+// both must be the same size else it panics.
+func (tx *textureImpl) ImageFlipY(dest, src *image.RGBA) {
+	if dest.Rect.Size() != src.Rect.Size() {
+		panic("ImageFlipY image sizes are not the same")
+	}
+	sz := dest.Rect.Size()
+	rsz := sz.X * 4
+	for y := 0; y < sz.Y; y++ {
+		sy := (y - src.Rect.Min.Y) * src.Stride
+		dy := (sz.Y - y - 1 - dest.Rect.Min.Y) * dest.Stride
+		srow := src.Pix[sy : sy+rsz]
+		drow := dest.Pix[dy : dy+rsz]
+		copy(drow, srow)
+	}
+}
+
+// SetImage sets entire contents of the Texture from given image
+// (including setting the size of the texture from that of the img).
+// The image is always copied.
+func (tx *textureImpl) SetImage(img image.Image) error {
+	sz := img.Bounds().Size()
+	tx.img = image.NewRGBA(image.Rectangle{Max: sz})
+	tx.size = sz
+	draw.Draw(tx.img, tx.img.Rect, img, img.Bounds().Min, draw.Src)
+	return nil
+}
+
+// SetSubImage copies the sub-Image defined by src and sr to the texture,
+// such that sr.Min in src-space aligns with dp in dst-space.
+// The textures's contents are overwritten; the draw operator
+// is implicitly draw.Src.
+func (tx *textureImpl) SetSubImage(dp image.Point, src image.Image, sr image.Rectangle) error {
+	tx.Activate(0)
+	dr := sr.Sub(sr.Min).Add(dp)
+	draw.Draw(tx.img, dr, src, sr.Min, draw.Src)
+	return nil
+}
+
+// Size returns the size of the image
+func (tx *textureImpl) Size() image.Point {
+	return tx.size
+}
+
+func (tx *textureImpl) Bounds() image.Rectangle {
+	if tx == nil {
+		return image.ZR
+	}
+	return image.Rectangle{Max: tx.size}
+}
+
+// BotZero returns true if this texture has the Y=0 pixels at the bottom
+// of the image -- this is only recorded, and has no effect here.
+func (tx *textureImpl) BotZero() bool {
+	return tx.botZero
+}
+
+// SetBotZero sets whether this texture has the Y=0 pixels at the bottom
+// of the image -- this is only recorded, and has no effect here.
+func (tx *textureImpl) SetBotZero(botzero bool) {
+	tx.botZero = botzero
+}
+
+// SetSize sets the size of the texture -- existing contents are lost.
+func (tx *textureImpl) SetSize(size image.Point) {
+	if tx.size == size {
+		return
+	}
+	tx.size = size
+	if tx.img != nil {
+		tx.img = image.NewRGBA(image.Rectangle{Max: size})
+	}
+}
+
+// Activate allocates the image for the texture if not already done --
+// the texNo is ignored.
+func (tx *textureImpl) Activate(texNo int) {
+	if tx.img == nil || tx.img.Rect.Size() != tx.size {
+		tx.img = image.NewRGBA(image.Rectangle{Max: tx.size})
+	}
+}
+
+// IsActive returns true if the texture image has been allocated
+func (tx *textureImpl) IsActive() bool {
+	return tx.img != nil
+}
+
+// Handle returns 0 as there is no GPU texture
+func (tx *textureImpl) Handle() uint32 {
+	return 0
+}
+
+// Transfer is a no-op, as there is no GPU to transfer to
+func (tx *textureImpl) Transfer(texNo int) bool {
+	return false
+}
+
+// Delete frees the texture image
+func (tx *textureImpl) Delete() {
+	tx.img = nil
+}
+
+// ActivateFramebuffer is a no-op -- there are no GPU framebuffers
+func (tx *textureImpl) ActivateFramebuffer() {
+}
+
+// DeActivateFramebuffer is a no-op -- there are no GPU framebuffers
+func (tx *textureImpl) DeActivateFramebuffer() {
+}
+
+// DeleteFramebuffer is a no-op -- there are no GPU framebuffers
+func (tx *textureImpl) DeleteFramebuffer() {
+}
+
+// FrameDepthAt returns an error, as there is no depth buffer
+func (tx *textureImpl) FrameDepthAt(x, y int) (float32, error) {
+	return 0, errors.New("mobile Texture: FrameDepthAt is not supported")
+}
+
+////////////////////////////////////////////////
+//   Drawer
+
+// drawImage draws src image into the texture, transformed by src2dst,
+// using a simple draw.Draw for integer translations and otherwise a
+// bilinear transform
+func (tx *textureImpl) drawImage(src2dst mat32.Mat3, src image.Image, sr image.Rectangle, op draw.Op) {
+	tx.Activate(0)
+	m := src2dst
+	if m[0] == 1 && m[1] == 0 && m[3] == 0 && m[4] == 1 && m[6] == mat32.Floor(m[6]) && m[7] == mat32.Floor(m[7]) {
+		dp := image.Point{int(m[6]), int(m[7])}
+		draw.Draw(tx.img, sr.Add(dp), src, sr.Min, op)
+		return
+	}
+	aff := f64.Aff3{float64(m[0]), float64(m[3]), float64(m[6]), float64(m[1]), float64(m[4]), float64(m[7])}
+	xdraw.ApproxBiLinear.Transform(tx.img, aff, src, sr, xdraw.Op(op), nil)
+}
+
+func (tx *textureImpl) Draw(src2dst mat32.Mat3, src oswin.Texture, sr image.Rectangle, op draw.Op, opts *oswin.DrawOptions) {
+	img := src.Image()
+	if img == nil {
+		return
+	}
+	tx.drawImage(src2dst, img, sr, op)
+}
+
+func (tx *textureImpl) DrawUniform(src2dst mat32.Mat3, src color.Color, sr image.Rectangle, op draw.Op, opts *oswin.DrawOptions) {
+	tx.drawImage(src2dst, image.NewUniform(src), sr, op)
+}
+
+func (tx *textureImpl) Copy(dp image.Point, src oswin.Texture, sr image.Rectangle, op draw.Op, opts *oswin.DrawOptions) {
+	drawer.Copy(tx, dp, src, sr, op, opts)
+}
+
+func (tx *textureImpl) Scale(dr image.Rectangle, src oswin.Texture, sr image.Rectangle, op draw.Op, opts *oswin.DrawOptions) {
+	drawer.Scale(tx, dr, src, sr, op, opts)
+}
+
+func (tx *textureImpl) Fill(dr image.Rectangle, src color.Color, op draw.Op) {
+	tx.Activate(0)
+	draw.Draw(tx.img, dr, image.NewUniform(src), image.ZP, op)
+}
@@ -0,0 +1,439 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build android ios
+
+package mobile
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"sync"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/driver/internal/drawer"
+	"github.com/goki/gi/oswin/driver/internal/event"
+	"github.com/goki/gi/oswin/touch"
+	"github.com/goki/gi/oswin/window"
+	"github.com/goki/ki/bitflag"
+	"github.com/goki/mat32"
+	"golang.org/x/mobile/event/paint"
+)
+
+type windowImpl struct {
+	oswin.WindowBase
+	event.Deque
+	app            *appImpl
+	mu             sync.Mutex
+	runMu          sync.Mutex   // serializes RunOnWin functions
+	winTex         *textureImpl // WinTex, drawn by the user
+	back           *textureImpl // back buffer, drawn by the Drawer methods
+	frame          *image.RGBA  // last published frame, drawn to the display
+	closed         bool
+	closeReqFunc   func(win oswin.Window)
+	closeCleanFunc func(win oswin.Window)
+
+	// on-screen keyboard state, as set by the GUI -- see keyboard.go
+	imeActive bool
+	imeCaret  image.Rectangle
+
+	// touch state for the events -- see events.go
+	mouseSeq    touch.Sequence // touch that is sent as the left mouse button
+	mouseOn     bool           // mouseSeq is down
+	mousePos    image.Point
+	mouseClickT int64 // unix nanosec time of last press, for double-click
+	nTouches    int   // number of touches that are down
+	gestures    touch.Gestures
+}
+
+// Handle returns the driver-specific handle for this window, which is
+// the window itself.
+func (w *windowImpl) Handle() interface{} {
+	return w
+}
+
+// OSHandle returns 0 as the OS window is not accessible via x/mobile
+func (w *windowImpl) OSHandle() uintptr {
+	return 0
+}
+
+func (w *windowImpl) MainMenu() oswin.MainMenu {
+	return nil
+}
+
+func (w *windowImpl) IsClosed() bool {
+	if w == nil {
+		return true
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.closed
+}
+
+func (w *windowImpl) IsVisible() bool {
+	if w == nil {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return !w.closed && w.winTex != nil && !w.IsMinimized()
+}
+
+// Activate returns true if the window is open -- there is no gpu context
+// to activate.
+func (w *windowImpl) Activate() bool {
+	return !w.IsClosed()
+}
+
+// DeActivate is a no-op, as there is no gpu context
+func (w *windowImpl) DeActivate() {
+}
+
+// for sending window.Event's
+func (w *windowImpl) sendWindowEvent(act window.Actions) {
+	winEv := window.Event{
+		Action: act,
+	}
+	winEv.Init()
+	w.Send(&winEv)
+}
+
+// NextEvent implements the oswin.EventDeque interface.
+func (w *windowImpl) NextEvent() oswin.Event {
+	e := w.Deque.NextEvent()
+	return e
+}
+
+// RunOnWin runs given function, serialized with all other functions run
+// on the window.
+func (w *windowImpl) RunOnWin(f func()) {
+	if w.IsClosed() {
+		return
+	}
+	w.runMu.Lock()
+	f()
+	w.runMu.Unlock()
+}
+
+// GoRunOnWin runs given function via RunOnWin and returns immediately
+func (w *windowImpl) GoRunOnWin(f func()) {
+	if w.IsClosed() {
+		return
+	}
+	go w.RunOnWin(f)
+}
+
+// Publish copies the back buffer to the frame shown on the display, and
+// has it drawn by the event loop of the x/mobile app, which does all of
+// the OpenGL drawing
+func (w *windowImpl) Publish() {
+	if !w.IsVisible() {
+		return
+	}
+	w.mu.Lock()
+	src := w.back.img
+	if w.closed || src == nil {
+		w.mu.Unlock()
+		return
+	}
+	if w.frame == nil || w.frame.Rect != src.Rect {
+		w.frame = image.NewRGBA(src.Rect)
+	}
+	copy(w.frame.Pix, src.Pix)
+	w.mu.Unlock()
+	w.app.mapp.Send(paint.Event{})
+}
+
+// PublishRegions is the same as Publish, as the whole frame is drawn to the
+// display each time
+func (w *windowImpl) PublishRegions(rects []image.Rectangle) {
+	w.Publish()
+}
+
+// PublishTex draws the current WinTex texture to the window and then
+// calls Publish() -- this is the typical update call.
+func (w *windowImpl) PublishTex() {
+	if !w.IsVisible() {
+		return
+	}
+	w.Copy(image.ZP, w.winTex, w.winTex.Bounds(), oswin.Src, nil)
+	w.Publish()
+}
+
+// SendEmptyEvent sends an empty, blank event to this window, which just has
+// the effect of pushing the system along during cases when the window
+// event loop needs to be "pinged" to get things moving along..
+func (w *windowImpl) SendEmptyEvent() {
+	if w.IsClosed() {
+		return
+	}
+	oswin.SendCustomEvent(w, nil)
+}
+
+// WinTex() returns the current Texture of the same size as the window that
+// is typically used to update the window contents.
+// Use the various Drawer and SetSubImage methods to update this Texture, and
+// then call PublishTex() to update the window.
+// This Texture is automatically resized when the window is resized, and
+// when that occurs, existing contents are lost -- a full update of the
+// Texture at the current size is required at that point.
+func (w *windowImpl) WinTex() oswin.Texture {
+	return w.winTex
+}
+
+// SetWinTexSubImage calls SetSubImage on WinTex with given parameters.
+func (w *windowImpl) SetWinTexSubImage(dp image.Point, src image.Image, sr image.Rectangle) error {
+	if !w.IsVisible() {
+		return nil
+	}
+	return w.winTex.SetSubImage(dp, src, sr)
+}
+
+////////////////////////////////////////////////
+//   Drawer wrappers
+
+func (w *windowImpl) Draw(src2dst mat32.Mat3, src oswin.Texture, sr image.Rectangle, op draw.Op, opts *oswin.DrawOptions) {
+	if !w.IsVisible() {
+		return
+	}
+	w.back.Draw(src2dst, src, sr, op, opts)
+}
+
+func (w *windowImpl) DrawUniform(src2dst mat32.Mat3, src color.Color, sr image.Rectangle, op draw.Op, opts *oswin.DrawOptions) {
+	if !w.IsVisible() {
+		return
+	}
+	w.back.DrawUniform(src2dst, src, sr, op, opts)
+}
+
+func (w *windowImpl) Copy(dp image.Point, src oswin.Texture, sr image.Rectangle, op draw.Op, opts *oswin.DrawOptions) {
+	if !w.IsVisible() {
+		return
+	}
+	drawer.Copy(w, dp, src, sr, op, opts)
+}
+
+func (w *windowImpl) Scale(dr image.Rectangle, src oswin.Texture, sr image.Rectangle, op draw.Op, opts *oswin.DrawOptions) {
+	if !w.IsVisible() {
+		return
+	}
+	drawer.Scale(w, dr, src, sr, op, opts)
+}
+
+func (w *windowImpl) Fill(dr image.Rectangle, src color.Color, op draw.Op) {
+	if !w.IsVisible() {
+		return
+	}
+	w.back.Fill(dr, src, op)
+}
+
+////////////////////////////////////////////////////////////
+//  Geom etc
+
+func (w *windowImpl) Screen() *oswin.Screen {
+	return w.app.screens[0]
+}
+
+func (w *windowImpl) Size() image.Point {
+	return w.PxSize
+}
+
+func (w *windowImpl) WinSize() image.Point {
+	return w.WnSize
+}
+
+func (w *windowImpl) Position() image.Point {
+	return image.ZP
+}
+
+func (w *windowImpl) PhysicalDPI() float32 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.PhysDPI
+}
+
+func (w *windowImpl) LogicalDPI() float32 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.LogDPI
+}
+
+func (w *windowImpl) SetLogicalDPI(dpi float32) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.LogDPI = dpi
+}
+
+func (w *windowImpl) SetTitle(title string) {
+	w.Titl = title
+}
+
+// SetSize sets the size of the window in window units -- the window
+// always fills the display, so this only has an effect when called by the
+// driver for a new size of the display
+func (w *windowImpl) SetSize(sz image.Point) {
+	sc := w.app.screens[0]
+	w.SetPixSize(image.Point{int(float32(sz.X) * sc.DevicePixelRatio), int(float32(sz.Y) * sc.DevicePixelRatio)})
+}
+
+// SetPixSize sets the size of the window in pixels, sending a
+// window.Resize event if it has changed -- the window always fills the
+// display, so this only has an effect when called by the driver for a new
+// size of the display
+func (w *windowImpl) SetPixSize(sz image.Point) {
+	if w.IsClosed() {
+		return
+	}
+	sc := w.app.screens[0]
+	if sz != sc.PixSize {
+		return
+	}
+	w.mu.Lock()
+	if sz == w.PxSize && w.DevPixRatio == sc.DevicePixelRatio {
+		w.mu.Unlock()
+		return
+	}
+	w.DevPixRatio = sc.DevicePixelRatio
+	w.PhysDPI = sc.PhysicalDPI
+	w.WnSize = sc.Geometry.Size()
+	w.PxSize = sz
+	w.winTex.SetSize(w.PxSize)
+	w.back.SetSize(w.PxSize)
+	w.mu.Unlock()
+	w.sendWindowEvent(window.Resize)
+}
+
+// SetPos is a no-op, as the window always fills the display
+func (w *windowImpl) SetPos(pos image.Point) {
+}
+
+func (w *windowImpl) SetGeom(pos image.Point, sz image.Point) {
+	w.SetSize(sz)
+}
+
+// Raise shows the window on top of the others, and gives it the focus
+func (w *windowImpl) Raise() {
+	if w.IsClosed() {
+		return
+	}
+	w.app.mu.Lock()
+	for i, wl := range w.app.winlist {
+		if wl == w {
+			w.app.winlist = append(w.app.winlist[:i], w.app.winlist[i+1:]...)
+			break
+		}
+	}
+	for _, ow := range w.app.winlist {
+		ow.setFocus(false)
+	}
+	w.app.winlist = append(w.app.winlist, w)
+	w.app.mu.Unlock()
+	w.setFocus(true)
+	w.sendWindowEvent(window.Paint)
+}
+
+// Minimize is a no-op, as the app as a whole is minimized by the user
+func (w *windowImpl) Minimize() {
+}
+
+// Hide is a no-op, as the app as a whole is hidden by the user
+func (w *windowImpl) Hide() {
+}
+
+// setMinimized sets the minimized state of the window, when the app
+// becomes invisible or visible, sending a window.Minimize event or a
+// window.Paint event for redrawing, respectively
+func (w *windowImpl) setMinimized(min bool) {
+	if min == w.IsMinimized() {
+		return
+	}
+	if min {
+		bitflag.SetAtomic(&w.Flag, int(oswin.Minimized))
+		w.sendWindowEvent(window.Minimize)
+	} else {
+		bitflag.ClearAtomic(&w.Flag, int(oswin.Minimized))
+		w.sendWindowEvent(window.Paint)
+	}
+}
+
+// setFocus sets the focus state of the window, sending a window.Focus or
+// DeFocus event if it has changed
+func (w *windowImpl) setFocus(focus bool) {
+	if focus == w.IsFocus() {
+		return
+	}
+	if focus {
+		bitflag.SetAtomic(&w.Flag, int(oswin.Focus))
+		w.sendWindowEvent(window.Focus)
+	} else {
+		bitflag.ClearAtomic(&w.Flag, int(oswin.Focus))
+		w.sendWindowEvent(window.DeFocus)
+	}
+}
+
+func (w *windowImpl) SetCloseReqFunc(fun func(win oswin.Window)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closeReqFunc = fun
+}
+
+func (w *windowImpl) SetCloseCleanFunc(fun func(win oswin.Window)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closeCleanFunc = fun
+}
+
+func (w *windowImpl) CloseReq() {
+	if theApp.quitting {
+		w.Close()
+	}
+	if w.closeReqFunc != nil {
+		w.closeReqFunc(w)
+	} else {
+		w.Close()
+	}
+}
+
+func (w *windowImpl) CloseClean() {
+	if w.closeCleanFunc != nil {
+		w.closeCleanFunc(w)
+	}
+}
+
+// Close closes the window, showing the one under it
+func (w *windowImpl) Close() {
+	if w.IsClosed() {
+		return
+	}
+	w.CloseClean()
+	w.sendWindowEvent(window.Close)
+	w.mu.Lock()
+	ime := w.imeActive
+	w.mu.Unlock()
+	if ime {
+		w.SetIMEActive(false)
+	}
+	theApp.DeleteWin(w)
+	w.mu.Lock()
+	w.closed = true
+	w.winTex.Delete()
+	w.back.Delete()
+	w.frame = nil
+	w.mu.Unlock()
+	if theApp.quitting {
+		theApp.quitCloseCnt <- struct{}{}
+	}
+}
+
+// SetMousePos records the mouse position, as there is no pointer to move
+func (w *windowImpl) SetMousePos(x, y float64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.mousePos = image.Point{int(x), int(y)}
+}
+
+// SetCursorEnabled is a no-op, as there is no cursor
+func (w *windowImpl) SetCursorEnabled(enabled, raw bool) {
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package offscreen
+
+import (
+	"github.com/goki/gi/oswin"
+)
+
+// the offscreen driver records the accessibility information provided by
+// the GUI, so it can be checked without an assistive technology -- it is
+// only active after SetAccessActive, as if a screen reader were running
+
+func (w *windowImpl) AccessActive() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.accessActive
+}
+
+func (w *windowImpl) SetAccessTree(root *oswin.AccessNode) {
+	w.mu.Lock()
+	w.accessTree = root
+	w.mu.Unlock()
+}
+
+func (w *windowImpl) AccessFocus(id string) {
+	w.mu.Lock()
+	w.accessFocus = id
+	w.mu.Unlock()
+}
+
+func (w *windowImpl) AccessAnnounce(msg string, assertive bool) {
+	w.mu.Lock()
+	w.accessAnnounce = append(w.accessAnnounce, msg)
+	w.mu.Unlock()
+}
+
+// SetAccessActive sets whether an assistive technology is simulated to be
+// using the accessibility information of given window
+func SetAccessActive(win oswin.Window, active bool) {
+	w, ok := win.(*windowImpl)
+	if !ok {
+		return
+	}
+	w.mu.Lock()
+	w.accessActive = active
+	w.mu.Unlock()
+}
+
+// AccessTree returns the last accessibility tree set for given window, or
+// nil if none
+func AccessTree(win oswin.Window) *oswin.AccessNode {
+	w, ok := win.(*windowImpl)
+	if !ok {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.accessTree
+}
+
+// AccessFocused returns the ID of the accessible element that last got the
+// focus in given window
+func AccessFocused(win oswin.Window) string {
+	w, ok := win.(*windowImpl)
+	if !ok {
+		return ""
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.accessFocus
+}
+
+// AccessAnnouncements returns the messages announced for given window, in
+// order, and clears them
+func AccessAnnouncements(win oswin.Window) []string {
+	w, ok := win.(*windowImpl)
+	if !ok {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	msgs := w.accessAnnounce
+	w.accessAnnounce = nil
+	return msgs
+}
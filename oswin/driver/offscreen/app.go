@@ -0,0 +1,476 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package offscreen provides a headless oswin driver that renders windows
+// to in-memory images, without any display or GPU.  It is used for
+// automated (CI) screenshot tests and for server-side rendering of gi
+// scenes.
+//
+// Select it in place of the default glos driver by building with the
+// offscreen build tag, or call offscreen.Main directly.  Synthetic mouse
+// and key events can be injected with MouseClick, KeyChord, TypeText etc,
+// and the most recently published frame of a window is available via
+// Frame, WaitFrame and SaveFrame.
+//
+// All drawing is done in software, so the oswin/gpu interfaces are not
+// available, and gi3d scenes cannot be rendered.
+package offscreen
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/audio"
+	"github.com/goki/gi/oswin/clip"
+	"github.com/goki/gi/oswin/cursor"
+	"github.com/goki/gi/oswin/window"
+)
+
+// ScreenSize is the size in pixels of the single virtual screen -- must be
+// set before Main is called
+var ScreenSize = image.Point{1920, 1080}
+
+// ScreenDPI is the physical DPI of the virtual screen -- must be set before
+// Main is called
+var ScreenDPI = float32(96)
+
+// DevicePixelRatio is the device pixel ratio of the virtual screen, e.g.,
+// 2 to render as on a "retina" display -- must be set before Main is called
+var DevicePixelRatio = float32(1)
+
+// Platform is the platform reported by the app, which defaults to that of
+// the host, so that key chords and shortcuts are the same as normal --
+// set to a fixed value for output that is identical on all hosts
+var Platform = hostPlatform()
+
+// hostPlatform returns the platform of the host
+func hostPlatform() oswin.Platforms {
+	switch runtime.GOOS {
+	case "darwin":
+		return oswin.MacOS
+	case "windows":
+		return oswin.Windows
+	}
+	return oswin.LinuxX11
+}
+
+var theApp = &appImpl{
+	winlist:      make([]*windowImpl, 0),
+	screens:      make([]*oswin.Screen, 0),
+	name:         "GoGi",
+	quitCloseCnt: make(chan struct{}),
+}
+
+type appImpl struct {
+	mu            sync.Mutex
+	mainQueue     chan funcRun
+	mainDone      chan struct{}
+	winlist       []*windowImpl
+	screens       []*oswin.Screen
+	ctxtwin       *windowImpl // context window, dynamically set, for e.g., pointer and other methods
+	name          string
+	about         string
+	quitting      bool          // set to true when quitting and closing windows
+	quitCloseCnt  chan struct{} // counts windows to make sure all are closed before done
+	quitReqFunc   func()
+	quitCleanFunc func()
+	dark          bool // appearance is dark
+	stopOnce      sync.Once
+	pickCh        chan color.RGBA // pending PickScreenColor -- see input.go
+	audio         []int16         // captured audio samples -- see audio.go
+	audioFormat   audio.Format    // format of last audio stream written to
+	trays         []*trayIconImpl // open tray icons -- see tray.go
+	dockBadge     string
+	dockItems     []oswin.TrayMenuItem
+	dockFunc      func(item int)
+}
+
+var mainCallback func(oswin.App)
+
+// Main runs the app, calling f in a separate goroutine -- when f returns,
+// the app ends automatically.  Unlike other drivers, Main need not be
+// called on the main thread, so it can be called from within a test.
+func Main(f func(oswin.App)) {
+	mainCallback = f
+	theApp.getScreens()
+	theApp.mainQueue = make(chan funcRun)
+	theApp.mainDone = make(chan struct{})
+	oswin.TheApp = theApp
+	go func() {
+		mainCallback(theApp)
+		theApp.stopMain()
+	}()
+	theApp.mainLoop()
+}
+
+type funcRun struct {
+	f    func()
+	done chan bool
+}
+
+// RunOnMain runs given function on main thread
+func (app *appImpl) RunOnMain(f func()) {
+	if app.mainQueue == nil {
+		f()
+	} else {
+		done := make(chan bool)
+		app.mainQueue <- funcRun{f: f, done: done}
+		<-done
+	}
+}
+
+// GoRunOnMain runs given function on main thread and returns immediately
+func (app *appImpl) GoRunOnMain(f func()) {
+	go func() {
+		app.mainQueue <- funcRun{f: f, done: nil}
+	}()
+}
+
+// SendEmptyEvent is a no-op, as there are no OS events to wait for
+func (app *appImpl) SendEmptyEvent() {
+}
+
+// PollEvents is a no-op, as there are no OS events to poll
+func (app *appImpl) PollEvents() {
+}
+
+// mainLoop runs functions sent to the main thread until the app ends
+func (app *appImpl) mainLoop() {
+	for {
+		select {
+		case <-app.mainDone:
+			return
+		case f := <-app.mainQueue:
+			f.f()
+			if f.done != nil {
+				f.done <- true
+			}
+		}
+	}
+}
+
+// stopMain stops the main loop and thus terminates the app -- can be
+// called more than once, e.g., by Quit and then when the Main function
+// returns, as Main can be called from a test that continues running
+func (app *appImpl) stopMain() {
+	app.stopOnce.Do(func() { close(app.mainDone) })
+}
+
+// getScreens sets the single virtual screen from ScreenSize etc
+func (app *appImpl) getScreens() {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	dpr := DevicePixelRatio
+	if dpr < 1 {
+		dpr = 1
+	}
+	geom := image.Rectangle{Max: image.Point{int(float32(ScreenSize.X) / dpr), int(float32(ScreenSize.Y) / dpr)}}
+	pdpi := ScreenDPI * dpr
+	sc := &oswin.Screen{
+		Name:             "Offscreen",
+		Geometry:         geom,
+		DevicePixelRatio: dpr,
+		PixSize:          ScreenSize,
+		PhysicalSize:     image.Point{int(25.4 * float32(ScreenSize.X) / pdpi), int(25.4 * float32(ScreenSize.Y) / pdpi)},
+		PhysicalDPI:      pdpi,
+		LogicalDPI:       pdpi,
+		Depth:            32,
+		RefreshRate:      60,
+	}
+	app.screens = []*oswin.Screen{sc}
+}
+
+////////////////////////////////////////////////////////
+//  Window
+
+func (app *appImpl) NewWindow(opts *oswin.NewWindowOptions) (oswin.Window, error) {
+	if len(app.winlist) == 0 && oswin.InitScreenLogicalDPIFunc != nil {
+		oswin.InitScreenLogicalDPIFunc()
+	}
+	sc := app.screens[0]
+
+	if opts == nil {
+		opts = &oswin.NewWindowOptions{}
+	}
+	opts.Fixup()
+
+	w := &windowImpl{
+		app: app,
+		WindowBase: oswin.WindowBase{
+			Titl:        opts.GetTitle(),
+			Flag:        opts.Flags,
+			Pos:         opts.Pos,
+			WnSize:      opts.Size,
+			PxSize:      image.Point{int(float32(opts.Size.X) * sc.DevicePixelRatio), int(float32(opts.Size.Y) * sc.DevicePixelRatio)},
+			DevPixRatio: sc.DevicePixelRatio,
+			PhysDPI:     sc.PhysicalDPI,
+			LogDPI:      sc.LogicalDPI,
+		},
+		published: make(chan struct{}),
+	}
+	w.winTex = &textureImpl{name: "WinTex", size: w.PxSize}
+	w.winTex.Activate(0)
+	w.back = &textureImpl{name: "Back", size: w.PxSize}
+	w.back.Activate(0)
+
+	app.mu.Lock()
+	for _, ow := range app.winlist {
+		ow.setFocus(false)
+	}
+	app.winlist = append(app.winlist, w)
+	app.mu.Unlock()
+	w.setFocus(true)
+
+	w.sendWindowEvent(window.Paint)
+	w.sendWindowEvent(window.Paint)
+
+	return w, nil
+}
+
+func (app *appImpl) DeleteWin(w *windowImpl) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	for i, wl := range app.winlist {
+		if wl == w {
+			app.winlist = append(app.winlist[:i], app.winlist[i+1:]...)
+			break
+		}
+	}
+	if app.ctxtwin == w {
+		app.ctxtwin = nil
+	}
+}
+
+func (app *appImpl) NScreens() int {
+	return len(app.screens)
+}
+
+func (app *appImpl) Screen(scrN int) *oswin.Screen {
+	sz := len(app.screens)
+	if scrN < sz {
+		return app.screens[scrN]
+	}
+	return nil
+}
+
+func (app *appImpl) ScreenByName(name string) *oswin.Screen {
+	for _, sc := range app.screens {
+		if sc.Name == name {
+			return sc
+		}
+	}
+	return nil
+}
+
+func (app *appImpl) NoScreens() bool {
+	return false
+}
+
+func (app *appImpl) NWindows() int {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	return len(app.winlist)
+}
+
+func (app *appImpl) Window(win int) oswin.Window {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	sz := len(app.winlist)
+	if win < sz {
+		return app.winlist[win]
+	}
+	return nil
+}
+
+func (app *appImpl) WindowByName(name string) oswin.Window {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	for _, win := range app.winlist {
+		if win.Name() == name {
+			return win
+		}
+	}
+	return nil
+}
+
+func (app *appImpl) WindowInFocus() oswin.Window {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	for _, win := range app.winlist {
+		if win.IsFocus() {
+			return win
+		}
+	}
+	return nil
+}
+
+func (app *appImpl) ContextWindow() oswin.Window {
+	app.mu.Lock()
+	cw := app.ctxtwin
+	app.mu.Unlock()
+	return cw
+}
+
+func (app *appImpl) NewTexture(win oswin.Window, size image.Point) oswin.Texture {
+	tx := &textureImpl{size: size}
+	tx.Activate(0)
+	return tx
+}
+
+func (app *appImpl) Platform() oswin.Platforms {
+	return Platform
+}
+
+func (app *appImpl) Name() string {
+	return app.name
+}
+
+func (app *appImpl) SetName(name string) {
+	app.name = name
+}
+
+func (app *appImpl) About() string {
+	return app.about
+}
+
+func (app *appImpl) SetAbout(about string) {
+	app.about = about
+}
+
+// PrefsDir returns the user config directory, as for a regular app, so
+// that the same preferences are used
+func (app *appImpl) PrefsDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return os.TempDir()
+	}
+	return dir
+}
+
+func (app *appImpl) GoGiPrefsDir() string {
+	pdir := filepath.Join(app.PrefsDir(), "GoGi")
+	os.MkdirAll(pdir, 0755)
+	return pdir
+}
+
+func (app *appImpl) AppPrefsDir() string {
+	pdir := filepath.Join(app.PrefsDir(), app.Name())
+	os.MkdirAll(pdir, 0755)
+	return pdir
+}
+
+func (app *appImpl) FontPaths() []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"/System/Library/Fonts", "/Library/Fonts"}
+	case "windows":
+		return []string{"C:\\Windows\\Fonts"}
+	}
+	return []string{"/usr/share/fonts/truetype"}
+}
+
+// OpenURL is a no-op, as there is no browser to open it in
+func (app *appImpl) OpenURL(url string) {
+}
+
+func (app *appImpl) HasFileDialog() bool {
+	return false
+}
+
+func (app *appImpl) FileDialog(win oswin.Window, opts *oswin.FileDialogOptions) (string, error) {
+	return "", nil
+}
+
+func (app *appImpl) IsDark() bool {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	return app.dark
+}
+
+// SetDark sets whether the appearance is dark, sending a
+// window.ThemeChanged event to the first window if it has changed,
+// as when the OS appearance changes
+func SetDark(dark bool) {
+	app := theApp
+	app.mu.Lock()
+	if dark == app.dark {
+		app.mu.Unlock()
+		return
+	}
+	app.dark = dark
+	if len(app.winlist) == 0 {
+		app.mu.Unlock()
+		return
+	}
+	fw := app.winlist[0]
+	app.mu.Unlock()
+	fw.sendWindowEvent(window.ThemeChanged)
+}
+
+func (app *appImpl) ClipBoard(win oswin.Window) clip.Board {
+	app.mu.Lock()
+	app.ctxtwin, _ = win.(*windowImpl)
+	app.mu.Unlock()
+	return &theClip
+}
+
+func (app *appImpl) Cursor(win oswin.Window) cursor.Cursor {
+	app.mu.Lock()
+	app.ctxtwin, _ = win.(*windowImpl)
+	app.mu.Unlock()
+	return &theCursor
+}
+
+func (app *appImpl) SetQuitReqFunc(fun func()) {
+	app.quitReqFunc = fun
+}
+
+func (app *appImpl) SetQuitCleanFunc(fun func()) {
+	app.quitCleanFunc = fun
+}
+
+func (app *appImpl) QuitReq() {
+	if app.quitting {
+		return
+	}
+	if app.quitReqFunc != nil {
+		app.quitReqFunc()
+	} else {
+		app.Quit()
+	}
+}
+
+func (app *appImpl) IsQuitting() bool {
+	return app.quitting
+}
+
+func (app *appImpl) QuitClean() {
+	app.quitting = true
+	if app.quitCleanFunc != nil {
+		app.quitCleanFunc()
+	}
+	app.mu.Lock()
+	nwin := len(app.winlist)
+	for i := nwin - 1; i >= 0; i-- {
+		win := app.winlist[i]
+		go win.Close()
+	}
+	app.mu.Unlock()
+	for i := 0; i < nwin; i++ {
+		<-app.quitCloseCnt
+	}
+}
+
+func (app *appImpl) Quit() {
+	if app.quitting {
+		return
+	}
+	app.QuitClean()
+	app.stopMain()
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package offscreen
+
+import (
+	"github.com/goki/gi/oswin/audio"
+)
+
+// audio output is captured instead of being played, so it can be checked
+// with AudioSamples
+
+func (app *appImpl) OpenAudio(format audio.Format) (audio.Stream, error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	return audio.NewStream(format, &captureSink{app: app, format: format}), nil
+}
+
+// captureSink is an audio.Sink that captures the samples
+type captureSink struct {
+	app    *appImpl
+	format audio.Format
+}
+
+func (cs *captureSink) Write(b []byte) error {
+	cs.app.mu.Lock()
+	defer cs.app.mu.Unlock()
+	cs.app.audioFormat = cs.format
+	for i := 0; i+1 < len(b); i += 2 {
+		cs.app.audio = append(cs.app.audio, int16(uint16(b[i])|uint16(b[i+1])<<8))
+	}
+	return nil
+}
+
+func (cs *captureSink) Close() error {
+	return nil
+}
+
+// AudioSamples returns the samples written to all of the audio streams so
+// far (with their volume applied), and the format of the last stream
+// written to
+func AudioSamples() ([]int16, audio.Format) {
+	app := theApp
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	samples := make([]int16, len(app.audio))
+	copy(samples, app.audio)
+	return samples, app.audioFormat
+}
+
+// ClearAudio clears the audio samples captured so far
+func ClearAudio() {
+	app := theApp
+	app.mu.Lock()
+	app.audio = nil
+	app.mu.Unlock()
+}
@@ -0,0 +1,123 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package offscreen
+
+import (
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"time"
+
+	"github.com/goki/gi/oswin"
+)
+
+// Frame returns a copy of the last frame published to given window, or
+// nil if nothing has been published yet.
+func Frame(win oswin.Window) *image.RGBA {
+	w, ok := win.(*windowImpl)
+	if !ok {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.frame == nil {
+		return nil
+	}
+	fr := image.NewRGBA(w.frame.Rect)
+	copy(fr.Pix, w.frame.Pix)
+	return fr
+}
+
+// NFrames returns the number of frames that have been published to given
+// window
+func NFrames(win oswin.Window) int {
+	w, ok := win.(*windowImpl)
+	if !ok {
+		return 0
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.nFrames
+}
+
+// LastDamage returns the regions of given window that were updated by the
+// last frame published -- nil if the whole window was updated
+func LastDamage(win oswin.Window) []image.Rectangle {
+	w, ok := win.(*windowImpl)
+	if !ok {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.damage == nil {
+		return nil
+	}
+	dmg := make([]image.Rectangle, len(w.damage))
+	copy(dmg, w.damage)
+	return dmg
+}
+
+// WaitFrame waits for the next frame to be published to given window, up
+// to given timeout, returning a copy of it, or nil if no frame was
+// published in time (or the window was closed).  Typically called after
+// injecting events, to get the resulting update.
+func WaitFrame(win oswin.Window, timeout time.Duration) *image.RGBA {
+	w, ok := win.(*windowImpl)
+	if !ok {
+		return nil
+	}
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	pub := w.published
+	w.mu.Unlock()
+	select {
+	case <-pub:
+	case <-time.After(timeout):
+		return nil
+	}
+	if w.IsClosed() {
+		return nil
+	}
+	return Frame(w)
+}
+
+// WaitIdle waits until no new frame has been published to given window
+// for the given idle duration, or until timeout, returning a copy of the
+// last frame -- this is the final rendered state after a series of
+// updates, e.g., after the window is first opened.
+func WaitIdle(win oswin.Window, idle, timeout time.Duration) *image.RGBA {
+	end := time.Now().Add(timeout)
+	for {
+		left := time.Until(end)
+		if left <= 0 {
+			break
+		}
+		if left > idle {
+			left = idle
+		}
+		if WaitFrame(win, left) == nil {
+			break
+		}
+	}
+	return Frame(win)
+}
+
+// SaveFrame saves the last frame published to given window as a PNG file
+func SaveFrame(win oswin.Window, filename string) error {
+	fr := Frame(win)
+	if fr == nil {
+		return errors.New("offscreen.SaveFrame: no frame has been published")
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return png.Encode(f, fr)
+}
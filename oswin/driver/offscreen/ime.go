@@ -0,0 +1,68 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package offscreen
+
+import (
+	"image"
+	"unicode/utf8"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/ime"
+)
+
+// the offscreen driver simulates a platform input method: IMEPreedit and
+// IMECommit send the events that an IME sends while composing text, and
+// the text cursor set by the GUI is recorded for checking
+
+func (w *windowImpl) SetIMEActive(on bool) {
+	w.mu.Lock()
+	w.imeActive = on
+	w.mu.Unlock()
+}
+
+func (w *windowImpl) SetIMECaret(caret image.Rectangle) {
+	w.mu.Lock()
+	w.imeCaret = caret
+	w.mu.Unlock()
+}
+
+// IMEPreedit sends an ime.Preedit event with given composing text, with
+// the cursor at its end -- empty text ends the composition
+func IMEPreedit(win oswin.Window, text string) {
+	event := &ime.Event{Action: ime.Preedit, Text: text, Cursor: utf8.RuneCountInString(text)}
+	event.Init()
+	win.Send(event)
+}
+
+// IMECommit sends an ime.Commit event with given final composed text
+func IMECommit(win oswin.Window, text string) {
+	event := &ime.Event{Action: ime.Commit, Text: text}
+	event.Init()
+	win.Send(event)
+}
+
+// IMEActive returns whether text input with an input method has been
+// enabled for given window by the GUI
+func IMEActive(win oswin.Window) bool {
+	w, ok := win.(*windowImpl)
+	if !ok {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.imeActive
+}
+
+// IMECaret returns the text cursor bounding box last set for given window
+// by the GUI, in window pixels
+func IMECaret(win oswin.Window) image.Rectangle {
+	w, ok := win.(*windowImpl)
+	if !ok {
+		return image.Rectangle{}
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.imeCaret
+}
@@ -0,0 +1,407 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package offscreen
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/oswin/stylus"
+	"github.com/goki/gi/oswin/touch"
+)
+
+// These functions inject synthetic input events into a window, in the same
+// form as they are sent by the glos driver for real input (touch events are
+// only sent by this driver, and stylus events are followed by emulated
+// mouse events, as on the platforms).  Positions are
+// in window pixels.  Any other event can be sent with the window Send
+// method.
+
+// MouseMove moves the mouse to given position, sending a mouse.MoveEvent,
+// or a mouse.DragEvent if a button is pressed
+func MouseMove(win oswin.Window, pos image.Point) {
+	w, ok := win.(*windowImpl)
+	if !ok {
+		return
+	}
+	w.mu.Lock()
+	from := w.mousePos
+	w.mousePos = pos
+	press, but := w.mousePress, w.mouseBut
+	w.mu.Unlock()
+	if press {
+		event := &mouse.DragEvent{
+			MoveEvent: mouse.MoveEvent{
+				Event: mouse.Event{
+					Where:  pos,
+					Button: but,
+					Action: mouse.Drag,
+				},
+				From: from,
+			},
+		}
+		event.Init()
+		w.Send(event)
+		return
+	}
+	event := &mouse.MoveEvent{
+		Event: mouse.Event{
+			Where:  pos,
+			Button: mouse.NoButton,
+			Action: mouse.Move,
+		},
+		From: from,
+	}
+	event.Init()
+	w.Send(event)
+}
+
+// sendMouseButton sends a mouse.Event with given button action at given
+// position, moving the mouse there first if needed
+func sendMouseButton(w *windowImpl, pos image.Point, but mouse.Buttons, act mouse.Actions) {
+	if pickMouseButton(w, pos, act) {
+		return
+	}
+	w.mu.Lock()
+	moved := pos != w.mousePos
+	w.mu.Unlock()
+	if moved {
+		MouseMove(w, pos)
+	}
+	w.mu.Lock()
+	w.mousePress = act != mouse.Release
+	w.mouseBut = but
+	w.mu.Unlock()
+	event := &mouse.Event{
+		Where:  pos,
+		Button: but,
+		Action: act,
+	}
+	event.Init()
+	w.Send(event)
+}
+
+// PickScreenColor satisfies the oswin.ScreenColorPicker interface: the
+// next mouse press, in any window, picks the color of the pixel at its
+// position in the last frame published to that window, instead of being
+// sent to the window, as is its release.
+func (app *appImpl) PickScreenColor() (color.RGBA, error) {
+	ch := make(chan color.RGBA, 1)
+	app.mu.Lock()
+	app.pickCh = ch
+	app.mu.Unlock()
+	return <-ch, nil
+}
+
+// pickMouseButton picks the color for a pending PickScreenColor, if the
+// given mouse action is a press, returning true if the action was used for
+// picking, and should not be sent
+func pickMouseButton(w *windowImpl, pos image.Point, act mouse.Actions) bool {
+	if act == mouse.Release {
+		w.mu.Lock()
+		picked := w.mousePicked
+		w.mousePicked = false
+		w.mu.Unlock()
+		return picked
+	}
+	theApp.mu.Lock()
+	ch := theApp.pickCh
+	theApp.pickCh = nil
+	theApp.mu.Unlock()
+	if ch == nil {
+		return false
+	}
+	var clr color.RGBA
+	if fr := Frame(w); fr != nil {
+		clr = fr.RGBAAt(pos.X, pos.Y)
+	}
+	w.mu.Lock()
+	w.mousePicked = true
+	w.mu.Unlock()
+	ch <- clr
+	return true
+}
+
+// MousePress presses given mouse button at given position
+func MousePress(win oswin.Window, pos image.Point, but mouse.Buttons) {
+	if w, ok := win.(*windowImpl); ok {
+		sendMouseButton(w, pos, but, mouse.Press)
+	}
+}
+
+// MouseRelease releases given mouse button at given position
+func MouseRelease(win oswin.Window, pos image.Point, but mouse.Buttons) {
+	if w, ok := win.(*windowImpl); ok {
+		sendMouseButton(w, pos, but, mouse.Release)
+	}
+}
+
+// MouseClick presses and releases given mouse button at given position
+func MouseClick(win oswin.Window, pos image.Point, but mouse.Buttons) {
+	MousePress(win, pos, but)
+	MouseRelease(win, pos, but)
+}
+
+// MouseDoubleClick clicks given mouse button twice at given position, the
+// second press being a mouse.DoubleClick
+func MouseDoubleClick(win oswin.Window, pos image.Point, but mouse.Buttons) {
+	w, ok := win.(*windowImpl)
+	if !ok {
+		return
+	}
+	MouseClick(w, pos, but)
+	sendMouseButton(w, pos, but, mouse.DoubleClick)
+	sendMouseButton(w, pos, but, mouse.Release)
+}
+
+// MouseDrag drags with given mouse button from one position to another, in
+// given number of steps (at least 1)
+func MouseDrag(win oswin.Window, from, to image.Point, but mouse.Buttons, steps int) {
+	MousePress(win, from, but)
+	if steps < 1 {
+		steps = 1
+	}
+	d := to.Sub(from)
+	for i := 1; i <= steps; i++ {
+		MouseMove(win, from.Add(d.Mul(i).Div(steps)))
+	}
+	MouseRelease(win, to, but)
+}
+
+// MouseScroll scrolls the mouse wheel by given delta at given position --
+// positive Y scrolls down
+func MouseScroll(win oswin.Window, pos image.Point, delta image.Point) {
+	w, ok := win.(*windowImpl)
+	if !ok {
+		return
+	}
+	event := &mouse.ScrollEvent{
+		Event: mouse.Event{
+			Where:  pos,
+			Action: mouse.Scroll,
+		},
+		Delta: delta,
+	}
+	event.Init()
+	w.Send(event)
+}
+
+// Touch sends a touch.Event with given action (Begin, Move or End) for the
+// touch of given sequence at given position, followed by any gesture events
+// recognized from the touches, as by touch.Gestures -- a LongPressEvent is
+// sent touch.LongPressTime after a touch that is held in place begins.
+func Touch(win oswin.Window, seq touch.Sequence, pos image.Point, act touch.Actions) {
+	w, ok := win.(*windowImpl)
+	if !ok {
+		return
+	}
+	event := &touch.Event{
+		Where:    pos,
+		Sequence: seq,
+		Action:   act,
+	}
+	event.Init()
+	w.Send(event)
+	w.mu.Lock()
+	if w.gestures.Send == nil {
+		w.gestures.Send = w.Send
+	}
+	w.mu.Unlock()
+	w.gestures.Touch(event)
+}
+
+// Pinch pinches two touches, on either side of given center horizontally,
+// from given distance apart to another, in given number of steps (at least
+// 1), sending a pinch-zoom gesture
+func Pinch(win oswin.Window, ctr image.Point, from, to int, steps int) {
+	if steps < 1 {
+		steps = 1
+	}
+	pts := func(dist int) (image.Point, image.Point) {
+		return ctr.Sub(image.Pt(dist/2, 0)), ctr.Add(image.Pt(dist-dist/2, 0))
+	}
+	a, b := pts(from)
+	Touch(win, 0, a, touch.Begin)
+	Touch(win, 1, b, touch.Begin)
+	for i := 1; i <= steps; i++ {
+		a, b = pts(from + (to-from)*i/steps)
+		Touch(win, 0, a, touch.Move)
+		Touch(win, 1, b, touch.Move)
+	}
+	Touch(win, 0, a, touch.End)
+	Touch(win, 1, b, touch.End)
+}
+
+// TouchPan moves two touches, 20 pixels apart horizontally, with their
+// center going from one position to another, in given number of steps (at
+// least 1), sending a two-finger pan gesture
+func TouchPan(win oswin.Window, from, to image.Point, steps int) {
+	if steps < 1 {
+		steps = 1
+	}
+	off := image.Pt(10, 0)
+	Touch(win, 0, from.Sub(off), touch.Begin)
+	Touch(win, 1, from.Add(off), touch.Begin)
+	d := to.Sub(from)
+	pos := from
+	for i := 1; i <= steps; i++ {
+		pos = from.Add(d.Mul(i).Div(steps))
+		Touch(win, 0, pos.Sub(off), touch.Move)
+		Touch(win, 1, pos.Add(off), touch.Move)
+	}
+	Touch(win, 0, pos.Sub(off), touch.End)
+	Touch(win, 1, pos.Add(off), touch.End)
+}
+
+// Stylus sends given stylus.Event, e.g., with the pressure and tilt of the
+// pen, followed by the mouse event that platforms emulate for the pen: a
+// Left button press for Press, release for Release, and a mouse move
+// otherwise.
+func Stylus(win oswin.Window, ev *stylus.Event) {
+	w, ok := win.(*windowImpl)
+	if !ok {
+		return
+	}
+	ev.Init()
+	w.Send(ev)
+	switch ev.Action {
+	case stylus.Press:
+		MousePress(w, ev.Where, mouse.Left)
+	case stylus.Release:
+		MouseRelease(w, ev.Where, mouse.Left)
+	default:
+		MouseMove(w, ev.Where)
+	}
+}
+
+// StylusStroke draws a pen stroke from one position to another, in given
+// number of steps (at least 1), with the pressure going linearly from one
+// value to another -- the Press is at the first pressure, and the Release
+// has no pressure.
+func StylusStroke(win oswin.Window, from, to image.Point, fromPress, toPress float32, steps int) {
+	if steps < 1 {
+		steps = 1
+	}
+	Stylus(win, &stylus.Event{Where: from, Action: stylus.Press, Pressure: fromPress})
+	d := to.Sub(from)
+	for i := 1; i <= steps; i++ {
+		p := fromPress + (toPress-fromPress)*float32(i)/float32(steps)
+		Stylus(win, &stylus.Event{Where: from.Add(d.Mul(i).Div(steps)), Action: stylus.Drag, Pressure: p})
+	}
+	Stylus(win, &stylus.Event{Where: to, Action: stylus.Release})
+}
+
+var (
+	codeNamesOnce sync.Once
+	codeNames     map[string]key.Codes // code names without Code prefix
+	runeCodes     map[rune]key.Codes   // reverse of key.CodeRuneMap
+)
+
+// initCodeNames initializes the codeNames and runeCodes maps
+func initCodeNames() {
+	codeNames = make(map[string]key.Codes)
+	for c := key.CodeUnknown; c <= key.CodeRightMeta; c++ {
+		nm := c.String()
+		if strings.HasPrefix(nm, "Code") {
+			codeNames[strings.TrimPrefix(nm, "Code")] = c
+		}
+	}
+	runeCodes = make(map[rune]key.Codes)
+	for c, r := range key.CodeRuneMap {
+		runeCodes[r] = c
+	}
+}
+
+// KeyChord presses and releases the key for given chord, e.g.,
+// "Control+S", "ReturnEnter" or "a", sending the key.Event and
+// key.ChordEvent events as for a real key press.
+func KeyChord(win oswin.Window, ch key.Chord) error {
+	w, ok := win.(*windowImpl)
+	if !ok {
+		return nil
+	}
+	codeNamesOnce.Do(initCodeNames)
+	mods, cs := key.ModsFmString(string(ch))
+	var code key.Codes
+	var rn rune
+	rs := []rune(cs)
+	if len(rs) == 1 {
+		rn = unicode.ToLower(rs[0])
+		code = runeCodes[rn]
+	} else if c, has := codeNames[cs]; has {
+		code = c
+		rn = key.CodeRuneMap[c]
+	} else {
+		return fmt.Errorf("offscreen.KeyChord: key not recognized in chord: %v", ch)
+	}
+	if rn != 0 && len(rs) == 1 && key.HasAnyModifierBits(mods, key.Shift) {
+		rn = unicode.ToUpper(rn)
+	}
+	sendKey(w, code, rn, mods, key.Press)
+	_, mapped := key.CodeRuneMap[code]
+	switch {
+	case key.CodeIsModifier(code):
+	case key.HasAnyModifierBits(mods, key.Control, key.Meta) || !mapped || code == key.CodeTab:
+		sendChord(w, code, rn, mods)
+	default: // as for a char event
+		sendChord(w, 0, rn, mods)
+	}
+	sendKey(w, code, rn, mods, key.Release)
+	return nil
+}
+
+// TypeText types given text, sending the key press and release events and
+// a key.ChordEvent for each rune, as for text typed on a real keyboard --
+// runes without a key code are sent with a zero code.
+func TypeText(win oswin.Window, text string) {
+	w, ok := win.(*windowImpl)
+	if !ok {
+		return
+	}
+	codeNamesOnce.Do(initCodeNames)
+	for _, r := range text {
+		var mods int32
+		if unicode.IsUpper(r) {
+			key.SetModifierBits(&mods, key.Shift)
+		}
+		code := runeCodes[unicode.ToLower(r)]
+		sendKey(w, code, r, mods, key.Press)
+		sendChord(w, 0, r, mods)
+		sendKey(w, code, r, mods, key.Release)
+	}
+}
+
+// sendKey sends a key.Event
+func sendKey(w *windowImpl, code key.Codes, rn rune, mods int32, act key.Actions) {
+	event := &key.Event{
+		Code:      code,
+		Rune:      rn,
+		Modifiers: mods,
+		Action:    act,
+	}
+	event.Init()
+	w.Send(event)
+}
+
+// sendChord sends a key.ChordEvent
+func sendChord(w *windowImpl, code key.Codes, rn rune, mods int32) {
+	event := &key.ChordEvent{
+		Event: key.Event{
+			Code:      code,
+			Rune:      rn,
+			Modifiers: mods,
+			Action:    key.Press,
+		},
+	}
+	event.Init()
+	w.Send(event)
+}
@@ -0,0 +1,160 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package offscreen
+
+import (
+	"image"
+	"sync"
+
+	"github.com/goki/gi/oswin"
+)
+
+// the system tray and dock are simulated: their state is recorded, so it
+// can be checked with TrayIcons and DockState, and their events are sent
+// by ClickTray, SelectTrayItem and SelectDockItem
+
+func (app *appImpl) NewTrayIcon(icon image.Image, tooltip string) (oswin.TrayIcon, error) {
+	ti := &trayIconImpl{icon: icon, tooltip: tooltip}
+	app.mu.Lock()
+	app.trays = append(app.trays, ti)
+	app.mu.Unlock()
+	return ti, nil
+}
+
+// trayIconImpl is a simulated tray icon
+type trayIconImpl struct {
+	mu      sync.Mutex
+	icon    image.Image
+	tooltip string
+	items   []oswin.TrayMenuItem
+	evFunc  func(ti oswin.TrayIcon, ev oswin.TrayEvents, item int)
+}
+
+func (ti *trayIconImpl) SetIcon(icon image.Image) {
+	ti.mu.Lock()
+	ti.icon = icon
+	ti.mu.Unlock()
+}
+
+func (ti *trayIconImpl) SetTooltip(tip string) {
+	ti.mu.Lock()
+	ti.tooltip = tip
+	ti.mu.Unlock()
+}
+
+func (ti *trayIconImpl) SetMenu(items []oswin.TrayMenuItem) {
+	ti.mu.Lock()
+	ti.items = append([]oswin.TrayMenuItem(nil), items...)
+	ti.mu.Unlock()
+}
+
+func (ti *trayIconImpl) SetEventFunc(fun func(ti oswin.TrayIcon, ev oswin.TrayEvents, item int)) {
+	ti.mu.Lock()
+	ti.evFunc = fun
+	ti.mu.Unlock()
+}
+
+func (ti *trayIconImpl) Close() {
+	app := theApp
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	for i, t := range app.trays {
+		if t == ti {
+			app.trays = append(app.trays[:i], app.trays[i+1:]...)
+			return
+		}
+	}
+}
+
+// sendEvent calls the event function of the icon
+func (ti *trayIconImpl) sendEvent(ev oswin.TrayEvents, item int) {
+	ti.mu.Lock()
+	fun := ti.evFunc
+	ti.mu.Unlock()
+	if fun != nil {
+		fun(ti, ev, item)
+	}
+}
+
+// TrayIcons returns the tray icons that have not been closed
+func TrayIcons() []oswin.TrayIcon {
+	app := theApp
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	tis := make([]oswin.TrayIcon, len(app.trays))
+	for i, ti := range app.trays {
+		tis[i] = ti
+	}
+	return tis
+}
+
+// TrayState returns the current image, tooltip and menu items of given
+// tray icon
+func TrayState(ti oswin.TrayIcon) (icon image.Image, tooltip string, items []oswin.TrayMenuItem) {
+	t := ti.(*trayIconImpl)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.icon, t.tooltip, append([]oswin.TrayMenuItem(nil), t.items...)
+}
+
+// ClickTray clicks on given tray icon, sending a TrayClicked event
+func ClickTray(ti oswin.TrayIcon) {
+	ti.(*trayIconImpl).sendEvent(oswin.TrayClicked, -1)
+}
+
+// SelectTrayItem selects the menu item of given index of given tray icon,
+// sending a TrayMenuSelected event -- returns false, without sending it,
+// if there is no such item, or it is inactive or a separator
+func SelectTrayItem(ti oswin.TrayIcon, item int) bool {
+	t := ti.(*trayIconImpl)
+	t.mu.Lock()
+	ok := trayItemOk(t.items, item)
+	t.mu.Unlock()
+	if ok {
+		t.sendEvent(oswin.TrayMenuSelected, item)
+	}
+	return ok
+}
+
+// trayItemOk returns whether the item of given index can be selected
+func trayItemOk(items []oswin.TrayMenuItem, item int) bool {
+	return item >= 0 && item < len(items) && items[item].Label != "" && !items[item].Inactive
+}
+
+func (app *appImpl) SetDockBadge(badge string) {
+	app.mu.Lock()
+	app.dockBadge = badge
+	app.mu.Unlock()
+}
+
+func (app *appImpl) SetDockMenu(items []oswin.TrayMenuItem, fun func(item int)) {
+	app.mu.Lock()
+	app.dockItems = append([]oswin.TrayMenuItem(nil), items...)
+	app.dockFunc = fun
+	app.mu.Unlock()
+}
+
+// DockState returns the current badge and menu items of the dock icon
+func DockState() (badge string, items []oswin.TrayMenuItem) {
+	app := theApp
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	return app.dockBadge, append([]oswin.TrayMenuItem(nil), app.dockItems...)
+}
+
+// SelectDockItem selects the dock menu item of given index -- returns
+// false, without selecting it, if there is no such item, or it is inactive
+// or a separator
+func SelectDockItem(item int) bool {
+	app := theApp
+	app.mu.Lock()
+	ok := trayItemOk(app.dockItems, item)
+	fun := app.dockFunc
+	app.mu.Unlock()
+	if ok && fun != nil {
+		fun(item)
+	}
+	return ok
+}
@@ -0,0 +1,451 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package offscreen
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"sync"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/driver/internal/drawer"
+	"github.com/goki/gi/oswin/driver/internal/event"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/oswin/touch"
+	"github.com/goki/gi/oswin/window"
+	"github.com/goki/ki/bitflag"
+	"github.com/goki/mat32"
+)
+
+type windowImpl struct {
+	oswin.WindowBase
+	event.Deque
+	app            *appImpl
+	mu             sync.Mutex
+	runMu          sync.Mutex        // serializes RunOnWin functions
+	winTex         *textureImpl      // WinTex, drawn by the user
+	back           *textureImpl      // back buffer, drawn by the Drawer methods
+	frame          *image.RGBA       // last published frame
+	nFrames        int               // number of frames published
+	damage         []image.Rectangle // regions updated by last publish -- nil if all
+	published      chan struct{}     // closed and renewed when a frame is published
+	closed         bool
+	closeReqFunc   func(win oswin.Window)
+	closeCleanFunc func(win oswin.Window)
+
+	// accessibility info, recorded for checking -- see access.go
+	accessActive   bool
+	accessTree     *oswin.AccessNode
+	accessFocus    string
+	accessAnnounce []string
+
+	// input method state, as set by the GUI -- see ime.go
+	imeActive bool
+	imeCaret  image.Rectangle
+
+	// mouse state for synthesized events
+	mousePos    image.Point
+	mouseBut    mouse.Buttons
+	mousePress  bool
+	mouseClickT int64 // unix nanosec time of last press, for double-click
+	mousePicked bool  // the last press picked a color, so its release is not sent
+	mods        int32
+
+	// recognizes gestures in synthesized touch events -- see input.go
+	gestures touch.Gestures
+}
+
+// Handle returns the driver-specific handle for this window, which is
+// the window itself.
+func (w *windowImpl) Handle() interface{} {
+	return w
+}
+
+// OSHandle returns 0 as there is no OS window
+func (w *windowImpl) OSHandle() uintptr {
+	return 0
+}
+
+func (w *windowImpl) MainMenu() oswin.MainMenu {
+	return nil
+}
+
+func (w *windowImpl) IsClosed() bool {
+	if w == nil {
+		return true
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.closed
+}
+
+func (w *windowImpl) IsVisible() bool {
+	if w == nil {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return !w.closed && w.winTex != nil && !w.IsMinimized()
+}
+
+// Activate returns true if the window is open -- there is no gpu context
+// to activate.
+func (w *windowImpl) Activate() bool {
+	return !w.IsClosed()
+}
+
+// DeActivate is a no-op, as there is no gpu context
+func (w *windowImpl) DeActivate() {
+}
+
+// for sending window.Event's
+func (w *windowImpl) sendWindowEvent(act window.Actions) {
+	winEv := window.Event{
+		Action: act,
+	}
+	winEv.Init()
+	w.Send(&winEv)
+}
+
+// NextEvent implements the oswin.EventDeque interface.
+func (w *windowImpl) NextEvent() oswin.Event {
+	e := w.Deque.NextEvent()
+	return e
+}
+
+// RunOnWin runs given function, serialized with all other functions run
+// on the window.
+func (w *windowImpl) RunOnWin(f func()) {
+	if w.IsClosed() {
+		return
+	}
+	w.runMu.Lock()
+	f()
+	w.runMu.Unlock()
+}
+
+// GoRunOnWin runs given function via RunOnWin and returns immediately
+func (w *windowImpl) GoRunOnWin(f func()) {
+	if w.IsClosed() {
+		return
+	}
+	go w.RunOnWin(f)
+}
+
+// Publish copies the back buffer into the frame returned by Frame, and
+// signals any WaitFrame calls
+func (w *windowImpl) Publish() {
+	if !w.IsVisible() {
+		return
+	}
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	src := w.back.img
+	if w.frame == nil || w.frame.Rect != src.Rect {
+		w.frame = image.NewRGBA(src.Rect)
+	}
+	copy(w.frame.Pix, src.Pix)
+	w.damage = nil
+	w.nFrames++
+	close(w.published)
+	w.published = make(chan struct{})
+	w.mu.Unlock()
+}
+
+// PublishRegions publishes just the given regions of the back buffer,
+// retaining the rest of the last published frame.
+func (w *windowImpl) PublishRegions(rects []image.Rectangle) {
+	if !w.IsVisible() {
+		return
+	}
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	src := w.back.img
+	if w.frame == nil || w.frame.Rect != src.Rect {
+		w.mu.Unlock()
+		w.Publish()
+		return
+	}
+	w.damage = make([]image.Rectangle, 0, len(rects))
+	for _, r := range rects {
+		r = r.Intersect(src.Rect)
+		if r.Empty() {
+			continue
+		}
+		draw.Draw(w.frame, r, src, r.Min, draw.Src)
+		w.damage = append(w.damage, r)
+	}
+	w.nFrames++
+	close(w.published)
+	w.published = make(chan struct{})
+	w.mu.Unlock()
+}
+
+// PublishTex draws the current WinTex texture to the window and then
+// calls Publish() -- this is the typical update call.
+func (w *windowImpl) PublishTex() {
+	if !w.IsVisible() {
+		return
+	}
+	w.Copy(image.ZP, w.winTex, w.winTex.Bounds(), oswin.Src, nil)
+	w.Publish()
+}
+
+// SendEmptyEvent sends an empty, blank event to this window, which just has
+// the effect of pushing the system along during cases when the window
+// event loop needs to be "pinged" to get things moving along..
+func (w *windowImpl) SendEmptyEvent() {
+	if w.IsClosed() {
+		return
+	}
+	oswin.SendCustomEvent(w, nil)
+}
+
+// WinTex() returns the current Texture of the same size as the window that
+// is typically used to update the window contents.
+// Use the various Drawer and SetSubImage methods to update this Texture, and
+// then call PublishTex() to update the window.
+// This Texture is automatically resized when the window is resized, and
+// when that occurs, existing contents are lost -- a full update of the
+// Texture at the current size is required at that point.
+func (w *windowImpl) WinTex() oswin.Texture {
+	return w.winTex
+}
+
+// SetWinTexSubImage calls SetSubImage on WinTex with given parameters.
+func (w *windowImpl) SetWinTexSubImage(dp image.Point, src image.Image, sr image.Rectangle) error {
+	if !w.IsVisible() {
+		return nil
+	}
+	return w.winTex.SetSubImage(dp, src, sr)
+}
+
+////////////////////////////////////////////////
+//   Drawer wrappers
+
+func (w *windowImpl) Draw(src2dst mat32.Mat3, src oswin.Texture, sr image.Rectangle, op draw.Op, opts *oswin.DrawOptions) {
+	if !w.IsVisible() {
+		return
+	}
+	w.back.Draw(src2dst, src, sr, op, opts)
+}
+
+func (w *windowImpl) DrawUniform(src2dst mat32.Mat3, src color.Color, sr image.Rectangle, op draw.Op, opts *oswin.DrawOptions) {
+	if !w.IsVisible() {
+		return
+	}
+	w.back.DrawUniform(src2dst, src, sr, op, opts)
+}
+
+func (w *windowImpl) Copy(dp image.Point, src oswin.Texture, sr image.Rectangle, op draw.Op, opts *oswin.DrawOptions) {
+	if !w.IsVisible() {
+		return
+	}
+	drawer.Copy(w, dp, src, sr, op, opts)
+}
+
+func (w *windowImpl) Scale(dr image.Rectangle, src oswin.Texture, sr image.Rectangle, op draw.Op, opts *oswin.DrawOptions) {
+	if !w.IsVisible() {
+		return
+	}
+	drawer.Scale(w, dr, src, sr, op, opts)
+}
+
+func (w *windowImpl) Fill(dr image.Rectangle, src color.Color, op draw.Op) {
+	if !w.IsVisible() {
+		return
+	}
+	w.back.Fill(dr, src, op)
+}
+
+////////////////////////////////////////////////////////////
+//  Geom etc
+
+func (w *windowImpl) Screen() *oswin.Screen {
+	return w.app.screens[0]
+}
+
+func (w *windowImpl) Size() image.Point {
+	return w.PxSize
+}
+
+func (w *windowImpl) WinSize() image.Point {
+	return w.WnSize
+}
+
+func (w *windowImpl) Position() image.Point {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.Pos
+}
+
+func (w *windowImpl) PhysicalDPI() float32 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.PhysDPI
+}
+
+func (w *windowImpl) LogicalDPI() float32 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.LogDPI
+}
+
+func (w *windowImpl) SetLogicalDPI(dpi float32) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.LogDPI = dpi
+}
+
+func (w *windowImpl) SetTitle(title string) {
+	w.Titl = title
+}
+
+// SetSize sets the size of the window in window manager (standard pixel)
+// units, sending a window.Resize event
+func (w *windowImpl) SetSize(sz image.Point) {
+	if w.IsClosed() {
+		return
+	}
+	w.mu.Lock()
+	w.WnSize = sz
+	w.PxSize = image.Point{int(float32(sz.X) * w.DevPixRatio), int(float32(sz.Y) * w.DevPixRatio)}
+	w.winTex.SetSize(w.PxSize)
+	w.back.SetSize(w.PxSize)
+	w.mu.Unlock()
+	w.sendWindowEvent(window.Resize)
+}
+
+func (w *windowImpl) SetPixSize(sz image.Point) {
+	sz.X = int(float32(sz.X) / w.DevPixRatio)
+	sz.Y = int(float32(sz.Y) / w.DevPixRatio)
+	w.SetSize(sz)
+}
+
+func (w *windowImpl) SetPos(pos image.Point) {
+	if w.IsClosed() {
+		return
+	}
+	w.mu.Lock()
+	w.Pos = pos
+	w.mu.Unlock()
+	w.sendWindowEvent(window.Move)
+}
+
+func (w *windowImpl) SetGeom(pos image.Point, sz image.Point) {
+	w.SetSize(sz)
+	w.SetPos(pos)
+}
+
+func (w *windowImpl) Raise() {
+	if w.IsClosed() {
+		return
+	}
+	if bitflag.HasAtomic(&w.Flag, int(oswin.Minimized)) {
+		bitflag.ClearAtomic(&w.Flag, int(oswin.Minimized))
+		w.sendWindowEvent(window.Minimize)
+	}
+	w.app.mu.Lock()
+	for _, ow := range w.app.winlist {
+		if ow != w {
+			ow.setFocus(false)
+		}
+	}
+	w.app.mu.Unlock()
+	w.setFocus(true)
+}
+
+func (w *windowImpl) Minimize() {
+	if w.IsClosed() {
+		return
+	}
+	bitflag.SetAtomic(&w.Flag, int(oswin.Minimized))
+	w.setFocus(false)
+	w.sendWindowEvent(window.Minimize)
+}
+
+// Hide is the same as Minimize, as there is nothing to show
+func (w *windowImpl) Hide() {
+	w.Minimize()
+}
+
+// setFocus sets the focus state of the window, sending a window.Focus or
+// DeFocus event if it has changed
+func (w *windowImpl) setFocus(focus bool) {
+	if focus == w.IsFocus() {
+		return
+	}
+	if focus {
+		bitflag.SetAtomic(&w.Flag, int(oswin.Focus))
+		w.sendWindowEvent(window.Focus)
+	} else {
+		bitflag.ClearAtomic(&w.Flag, int(oswin.Focus))
+		w.sendWindowEvent(window.DeFocus)
+	}
+}
+
+func (w *windowImpl) SetCloseReqFunc(fun func(win oswin.Window)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closeReqFunc = fun
+}
+
+func (w *windowImpl) SetCloseCleanFunc(fun func(win oswin.Window)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closeCleanFunc = fun
+}
+
+func (w *windowImpl) CloseReq() {
+	if theApp.quitting {
+		w.Close()
+	}
+	if w.closeReqFunc != nil {
+		w.closeReqFunc(w)
+	} else {
+		w.Close()
+	}
+}
+
+func (w *windowImpl) CloseClean() {
+	if w.closeCleanFunc != nil {
+		w.closeCleanFunc(w)
+	}
+}
+
+func (w *windowImpl) Close() {
+	if w.IsClosed() {
+		return
+	}
+	w.CloseClean()
+	w.sendWindowEvent(window.Close)
+	theApp.DeleteWin(w)
+	w.mu.Lock()
+	w.closed = true
+	w.winTex.Delete()
+	w.back.Delete()
+	close(w.published) // release any WaitFrame
+	w.mu.Unlock()
+	if theApp.quitting {
+		theApp.quitCloseCnt <- struct{}{}
+	}
+}
+
+func (w *windowImpl) SetMousePos(x, y float64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.mousePos = image.Point{int(x), int(y)}
+}
+
+// SetCursorEnabled is a no-op, as there is no cursor
+func (w *windowImpl) SetCursorEnabled(enabled, raw bool) {
+}
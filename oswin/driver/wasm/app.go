@@ -0,0 +1,495 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build js,wasm
+
+// Package wasm provides an oswin driver for WebAssembly programs running
+// in a web browser, so that gi applications can be compiled with
+// GOOS=js GOARCH=wasm and run with the same widget code as on the desktop.
+//
+// Each window is rendered to its own HTML canvas element: the first window
+// fills the browser page and is resized with it, and any others are shown
+// on top of it at their position.  All drawing is done in software, as in
+// the offscreen driver, and each published frame is copied to the canvas
+// (only the updated regions, via PublishRegions).  Keyboard and pointer
+// events of the DOM are translated into oswin events, and the clipboard is
+// synchronized with that of the browser via the copy and paste events and
+// the asynchronous clipboard API.
+//
+// The oswin/gpu interfaces are not available, so gi3d scenes cannot be
+// rendered, and there are no native file dialogs, tray or audio.  The
+// platform is reported as that of the host of the browser, so that key
+// chords and shortcuts are the same as for a desktop app on that host.
+//
+// The driver is selected by the oswin/driver package when building for
+// js/wasm.  The page that loads the program (with the wasm_exec.js support
+// file of the Go distribution) needs no other elements -- the canvases are
+// added to its body.
+package wasm
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall/js"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/clip"
+	"github.com/goki/gi/oswin/cursor"
+	"github.com/goki/gi/oswin/window"
+)
+
+var theApp = &appImpl{
+	winlist:      make([]*windowImpl, 0),
+	screens:      make([]*oswin.Screen, 0),
+	name:         "GoGi",
+	quitCloseCnt: make(chan struct{}),
+}
+
+type appImpl struct {
+	mu            sync.Mutex
+	mainQueue     chan funcRun
+	mainDone      chan struct{}
+	winlist       []*windowImpl
+	screens       []*oswin.Screen
+	ctxtwin       *windowImpl // context window, dynamically set, for e.g., pointer and other methods
+	name          string
+	about         string
+	quitting      bool          // set to true when quitting and closing windows
+	quitCloseCnt  chan struct{} // counts windows to make sure all are closed before done
+	quitReqFunc   func()
+	quitCleanFunc func()
+	stopOnce      sync.Once
+	platform      oswin.Platforms
+	topZ          int       // z-index of the canvas of the top window
+	funcs         []js.Func // DOM event handlers of the app, see initEvents
+}
+
+var mainCallback func(oswin.App)
+
+// Main runs the app, calling f in a separate goroutine -- when f returns,
+// the app ends automatically, and the program exits.
+func Main(f func(oswin.App)) {
+	mainCallback = f
+	theApp.platform = hostPlatform()
+	theApp.getScreens()
+	theApp.mainQueue = make(chan funcRun)
+	theApp.mainDone = make(chan struct{})
+	theApp.initEvents()
+	oswin.TheApp = theApp
+	go func() {
+		mainCallback(theApp)
+		theApp.stopMain()
+	}()
+	theApp.mainLoop()
+}
+
+// hostPlatform returns the platform of the host of the browser, from the
+// navigator platform and user agent
+func hostPlatform() oswin.Platforms {
+	nav := js.Global().Get("navigator")
+	pl := strings.ToLower(nav.Get("platform").String() + " " + nav.Get("userAgent").String())
+	switch {
+	case strings.Contains(pl, "mac"), strings.Contains(pl, "iphone"), strings.Contains(pl, "ipad"):
+		return oswin.MacOS
+	case strings.Contains(pl, "win"):
+		return oswin.Windows
+	}
+	return oswin.LinuxX11
+}
+
+type funcRun struct {
+	f    func()
+	done chan bool
+}
+
+// RunOnMain runs given function on main thread
+func (app *appImpl) RunOnMain(f func()) {
+	if app.mainQueue == nil {
+		f()
+	} else {
+		done := make(chan bool)
+		app.mainQueue <- funcRun{f: f, done: done}
+		<-done
+	}
+}
+
+// GoRunOnMain runs given function on main thread and returns immediately
+func (app *appImpl) GoRunOnMain(f func()) {
+	go func() {
+		app.mainQueue <- funcRun{f: f, done: nil}
+	}()
+}
+
+// SendEmptyEvent is a no-op, as the DOM events are sent by the browser
+func (app *appImpl) SendEmptyEvent() {
+}
+
+// PollEvents is a no-op, as the DOM events are sent by the browser
+func (app *appImpl) PollEvents() {
+}
+
+// mainLoop runs functions sent to the main thread until the app ends
+func (app *appImpl) mainLoop() {
+	for {
+		select {
+		case <-app.mainDone:
+			app.releaseEvents()
+			return
+		case f := <-app.mainQueue:
+			f.f()
+			if f.done != nil {
+				f.done <- true
+			}
+		}
+	}
+}
+
+// stopMain stops the main loop and thus terminates the app
+func (app *appImpl) stopMain() {
+	app.stopOnce.Do(func() { close(app.mainDone) })
+}
+
+// devicePixelRatio returns the device pixel ratio of the browser, at least 1
+func devicePixelRatio() float32 {
+	dpr := float32(js.Global().Get("devicePixelRatio").Float())
+	if dpr < 1 {
+		dpr = 1
+	}
+	return dpr
+}
+
+// pageSize returns the size of the browser page in CSS pixels
+func pageSize() image.Point {
+	win := js.Global()
+	return image.Point{win.Get("innerWidth").Int(), win.Get("innerHeight").Int()}
+}
+
+// getScreens sets the single screen from the size of the browser page,
+// which is all the space that windows can use
+func (app *appImpl) getScreens() {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	dpr := devicePixelRatio()
+	geom := image.Rectangle{Max: pageSize()}
+	psz := image.Point{int(float32(geom.Max.X) * dpr), int(float32(geom.Max.Y) * dpr)}
+	pdpi := 96 * dpr // CSS pixels are 1/96 inch
+	sc := &oswin.Screen{
+		Name:             "Browser",
+		Geometry:         geom,
+		DevicePixelRatio: dpr,
+		PixSize:          psz,
+		PhysicalSize:     image.Point{int(25.4 * float32(psz.X) / pdpi), int(25.4 * float32(psz.Y) / pdpi)},
+		PhysicalDPI:      pdpi,
+		LogicalDPI:       pdpi,
+		Depth:            32,
+		RefreshRate:      60,
+	}
+	if len(app.screens) == 0 {
+		app.screens = []*oswin.Screen{sc}
+	} else {
+		*app.screens[0] = *sc // keep the pointer held by the windows
+	}
+}
+
+// pageResized updates the screen and resizes the first window to the new
+// size of the browser page
+func (app *appImpl) pageResized() {
+	app.getScreens()
+	app.mu.Lock()
+	var fw *windowImpl
+	if len(app.winlist) > 0 {
+		fw = app.winlist[0]
+	}
+	app.mu.Unlock()
+	if fw != nil {
+		fw.SetSize(pageSize())
+	}
+}
+
+////////////////////////////////////////////////////////
+//  Window
+
+func (app *appImpl) NewWindow(opts *oswin.NewWindowOptions) (oswin.Window, error) {
+	if len(app.winlist) == 0 && oswin.InitScreenLogicalDPIFunc != nil {
+		oswin.InitScreenLogicalDPIFunc()
+	}
+	sc := app.screens[0]
+
+	if opts == nil {
+		opts = &oswin.NewWindowOptions{}
+	}
+	opts.Fixup()
+
+	app.mu.Lock()
+	first := len(app.winlist) == 0
+	app.mu.Unlock()
+	if first { // fills the page
+		opts.Pos = image.ZP
+		opts.Size = sc.Geometry.Size()
+	}
+
+	w := &windowImpl{
+		app: app,
+		WindowBase: oswin.WindowBase{
+			Titl:        opts.GetTitle(),
+			Flag:        opts.Flags,
+			Pos:         opts.Pos,
+			WnSize:      opts.Size,
+			PxSize:      image.Point{int(float32(opts.Size.X) * sc.DevicePixelRatio), int(float32(opts.Size.Y) * sc.DevicePixelRatio)},
+			DevPixRatio: sc.DevicePixelRatio,
+			PhysDPI:     sc.PhysicalDPI,
+			LogDPI:      sc.LogicalDPI,
+		},
+	}
+	w.winTex = &textureImpl{name: "WinTex", size: w.PxSize}
+	w.winTex.Activate(0)
+	w.back = &textureImpl{name: "Back", size: w.PxSize}
+	w.back.Activate(0)
+	w.newCanvas()
+	if first {
+		js.Global().Get("document").Set("title", w.Titl)
+	}
+
+	app.mu.Lock()
+	for _, ow := range app.winlist {
+		ow.setFocus(false)
+	}
+	app.winlist = append(app.winlist, w)
+	app.mu.Unlock()
+	w.canvas.Call("focus")
+	w.setFocus(true)
+
+	w.sendWindowEvent(window.Paint)
+	w.sendWindowEvent(window.Paint)
+
+	return w, nil
+}
+
+func (app *appImpl) DeleteWin(w *windowImpl) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	for i, wl := range app.winlist {
+		if wl == w {
+			app.winlist = append(app.winlist[:i], app.winlist[i+1:]...)
+			break
+		}
+	}
+	if app.ctxtwin == w {
+		app.ctxtwin = nil
+	}
+}
+
+func (app *appImpl) NScreens() int {
+	return len(app.screens)
+}
+
+func (app *appImpl) Screen(scrN int) *oswin.Screen {
+	sz := len(app.screens)
+	if scrN < sz {
+		return app.screens[scrN]
+	}
+	return nil
+}
+
+func (app *appImpl) ScreenByName(name string) *oswin.Screen {
+	for _, sc := range app.screens {
+		if sc.Name == name {
+			return sc
+		}
+	}
+	return nil
+}
+
+func (app *appImpl) NoScreens() bool {
+	return false
+}
+
+func (app *appImpl) NWindows() int {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	return len(app.winlist)
+}
+
+func (app *appImpl) Window(win int) oswin.Window {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	sz := len(app.winlist)
+	if win < sz {
+		return app.winlist[win]
+	}
+	return nil
+}
+
+func (app *appImpl) WindowByName(name string) oswin.Window {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	for _, win := range app.winlist {
+		if win.Name() == name {
+			return win
+		}
+	}
+	return nil
+}
+
+func (app *appImpl) WindowInFocus() oswin.Window {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	for _, win := range app.winlist {
+		if win.IsFocus() {
+			return win
+		}
+	}
+	return nil
+}
+
+func (app *appImpl) ContextWindow() oswin.Window {
+	app.mu.Lock()
+	cw := app.ctxtwin
+	app.mu.Unlock()
+	return cw
+}
+
+func (app *appImpl) NewTexture(win oswin.Window, size image.Point) oswin.Texture {
+	tx := &textureImpl{size: size}
+	tx.Activate(0)
+	return tx
+}
+
+func (app *appImpl) Platform() oswin.Platforms {
+	return app.platform
+}
+
+func (app *appImpl) Name() string {
+	return app.name
+}
+
+func (app *appImpl) SetName(name string) {
+	app.name = name
+}
+
+func (app *appImpl) About() string {
+	return app.about
+}
+
+func (app *appImpl) SetAbout(about string) {
+	app.about = about
+}
+
+// PrefsDir returns the temporary directory, as there is no user config
+// directory in the browser -- the prefs are only kept while the page is
+// open, unless the filesystem is provided by the page
+func (app *appImpl) PrefsDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return os.TempDir()
+	}
+	return dir
+}
+
+func (app *appImpl) GoGiPrefsDir() string {
+	pdir := filepath.Join(app.PrefsDir(), "GoGi")
+	os.MkdirAll(pdir, 0755)
+	return pdir
+}
+
+func (app *appImpl) AppPrefsDir() string {
+	pdir := filepath.Join(app.PrefsDir(), app.Name())
+	os.MkdirAll(pdir, 0755)
+	return pdir
+}
+
+// FontPaths returns no paths, as there are no font files in the browser --
+// the fonts built into girl are used
+func (app *appImpl) FontPaths() []string {
+	return nil
+}
+
+// OpenURL opens given URL in a new browser tab
+func (app *appImpl) OpenURL(url string) {
+	js.Global().Call("open", url, "_blank")
+}
+
+func (app *appImpl) HasFileDialog() bool {
+	return false
+}
+
+func (app *appImpl) FileDialog(win oswin.Window, opts *oswin.FileDialogOptions) (string, error) {
+	return "", nil
+}
+
+// IsDark returns whether the browser prefers a dark color scheme
+func (app *appImpl) IsDark() bool {
+	mm := js.Global().Get("matchMedia")
+	if mm.Type() != js.TypeFunction {
+		return false
+	}
+	return js.Global().Call("matchMedia", "(prefers-color-scheme: dark)").Get("matches").Bool()
+}
+
+func (app *appImpl) ClipBoard(win oswin.Window) clip.Board {
+	app.mu.Lock()
+	app.ctxtwin, _ = win.(*windowImpl)
+	app.mu.Unlock()
+	return &theClip
+}
+
+func (app *appImpl) Cursor(win oswin.Window) cursor.Cursor {
+	app.mu.Lock()
+	app.ctxtwin, _ = win.(*windowImpl)
+	app.mu.Unlock()
+	return &theCursor
+}
+
+func (app *appImpl) SetQuitReqFunc(fun func()) {
+	app.quitReqFunc = fun
+}
+
+func (app *appImpl) SetQuitCleanFunc(fun func()) {
+	app.quitCleanFunc = fun
+}
+
+func (app *appImpl) QuitReq() {
+	if app.quitting {
+		return
+	}
+	if app.quitReqFunc != nil {
+		app.quitReqFunc()
+	} else {
+		app.Quit()
+	}
+}
+
+func (app *appImpl) IsQuitting() bool {
+	return app.quitting
+}
+
+func (app *appImpl) QuitClean() {
+	app.quitting = true
+	if app.quitCleanFunc != nil {
+		app.quitCleanFunc()
+	}
+	app.mu.Lock()
+	nwin := len(app.winlist)
+	for i := nwin - 1; i >= 0; i-- {
+		win := app.winlist[i]
+		go win.Close()
+	}
+	app.mu.Unlock()
+	for i := 0; i < nwin; i++ {
+		<-app.quitCloseCnt
+	}
+}
+
+// Quit closes all the windows, removing their canvases from the page, and
+// ends the program
+func (app *appImpl) Quit() {
+	if app.quitting {
+		return
+	}
+	app.QuitClean()
+	app.stopMain()
+}
@@ -0,0 +1,199 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build js,wasm
+
+package wasm
+
+import (
+	"sync"
+	"syscall/js"
+
+	"github.com/goki/gi/oswin/cursor"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/pi/filecat"
+)
+
+/////////////////////////////////////////////////////////////////
+//   Clipboard
+
+// clipImpl is the clipboard of the app, which is synchronized with that of
+// the browser for text: text written to it is written to the browser
+// clipboard with the asynchronous clipboard API, and text pasted into the
+// page, e.g., with Control+V, replaces its contents.  Browsers only allow
+// the clipboard to be read directly in response to a paste, so other data
+// is only available within the app.
+type clipImpl struct {
+	mu   sync.Mutex
+	data mimedata.Mimes
+}
+
+var theClip = clipImpl{}
+
+func (ci *clipImpl) IsEmpty() bool {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	return len(ci.data) == 0
+}
+
+// Read returns the data of the first of given types that is on the
+// clipboard, or all of the data if types is empty
+func (ci *clipImpl) Read(types []string) mimedata.Mimes {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	if len(types) == 0 {
+		return ci.data
+	}
+	for _, typ := range types {
+		for _, d := range ci.data {
+			if d.Type == typ {
+				return mimedata.Mimes{d}
+			}
+		}
+	}
+	return nil
+}
+
+// Write sets the data of the clipboard, also writing any text to the
+// browser clipboard
+func (ci *clipImpl) Write(data mimedata.Mimes) error {
+	ci.mu.Lock()
+	ci.data = data
+	ci.mu.Unlock()
+	for _, d := range data {
+		if d.Type == filecat.TextPlain {
+			cb := js.Global().Get("navigator").Get("clipboard")
+			if cb.Truthy() {
+				cb.Call("writeText", string(d.Data))
+			}
+			break
+		}
+	}
+	return nil
+}
+
+func (ci *clipImpl) Clear() {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	ci.data = nil
+}
+
+// setText sets the clipboard to given text pasted into the page, unless it
+// is the same as the text already on it, which retains any other data
+func (ci *clipImpl) setText(text string) {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	for _, d := range ci.data {
+		if d.Type == filecat.TextPlain && string(d.Data) == text {
+			return
+		}
+	}
+	ci.data = mimedata.NewText(text)
+}
+
+//////////////////////////////////////////////////////
+//  Cursor
+
+// cursorImpl sets the CSS cursor of the canvases of the windows
+type cursorImpl struct {
+	cursor.CursorBase
+	mu sync.Mutex
+}
+
+var theCursor = cursorImpl{CursorBase: cursor.CursorBase{Vis: true}}
+
+// cssCursors are the names of the CSS cursors for the cursor.Shapes
+var cssCursors = map[cursor.Shapes]string{
+	cursor.Arrow:        "default",
+	cursor.Cross:        "crosshair",
+	cursor.DragCopy:     "copy",
+	cursor.DragMove:     "move",
+	cursor.DragLink:     "alias",
+	cursor.HandPointing: "pointer",
+	cursor.HandOpen:     "grab",
+	cursor.HandClosed:   "grabbing",
+	cursor.Help:         "help",
+	cursor.IBeam:        "text",
+	cursor.Not:          "not-allowed",
+	cursor.UpDown:       "ns-resize",
+	cursor.LeftRight:    "ew-resize",
+	cursor.UpRight:      "nesw-resize",
+	cursor.UpLeft:       "nwse-resize",
+	cursor.AllArrows:    "all-scroll",
+	cursor.Wait:         "wait",
+}
+
+// setCSS sets the cursor of all the canvases to the current one -- must be
+// called under the mutex
+func (c *cursorImpl) setCSS() {
+	css := "none"
+	if c.Vis {
+		css = cssCursors[c.Cur]
+		if css == "" {
+			css = "default"
+		}
+	}
+	theApp.mu.Lock()
+	for _, w := range theApp.winlist {
+		w.canvas.Get("style").Set("cursor", css)
+	}
+	theApp.mu.Unlock()
+}
+
+func (c *cursorImpl) Set(sh cursor.Shapes) {
+	c.mu.Lock()
+	c.Cur = sh
+	c.setCSS()
+	c.mu.Unlock()
+}
+
+func (c *cursorImpl) Push(sh cursor.Shapes) {
+	c.mu.Lock()
+	c.PushStack(sh)
+	c.setCSS()
+	c.mu.Unlock()
+}
+
+func (c *cursorImpl) Pop() {
+	c.mu.Lock()
+	c.PopStack()
+	c.setCSS()
+	c.mu.Unlock()
+}
+
+func (c *cursorImpl) Hide() {
+	c.mu.Lock()
+	c.Vis = false
+	c.setCSS()
+	c.mu.Unlock()
+}
+
+func (c *cursorImpl) Show() {
+	c.mu.Lock()
+	c.Vis = true
+	c.setCSS()
+	c.mu.Unlock()
+}
+
+func (c *cursorImpl) PushIfNot(sh cursor.Shapes) bool {
+	c.mu.Lock()
+	if c.Cur == sh {
+		c.mu.Unlock()
+		return false
+	}
+	c.mu.Unlock()
+	c.Push(sh)
+	return true
+}
+
+func (c *cursorImpl) PopIf(sh cursor.Shapes) bool {
+	c.mu.Lock()
+	if c.Cur == sh {
+		c.mu.Unlock()
+		c.Pop()
+		return true
+	}
+	c.mu.Unlock()
+	return false
+}
@@ -0,0 +1,303 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build js,wasm
+
+package wasm
+
+import (
+	"image"
+	"syscall/js"
+	"time"
+	"unicode/utf8"
+
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mouse"
+)
+
+// the DOM events of the canvas of each window are translated into oswin
+// events in the same form as they are sent by the glos driver: pointer
+// events (for the mouse, pen and touches, which the browser emulates as a
+// mouse) are sent as mouse events, and the keydown / keyup events as key
+// events, with ChordEvents for shortcuts and typed characters.
+
+// addListener adds a handler for given DOM event to given target, recording
+// the function so it can be released
+func addListener(funcs *[]js.Func, target js.Value, event string, fun func(ev js.Value)) {
+	jf := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		fun(args[0])
+		return nil
+	})
+	*funcs = append(*funcs, jf)
+	target.Call("addEventListener", event, jf)
+}
+
+// initEvents adds the handlers for the events of the page as a whole
+func (app *appImpl) initEvents() {
+	addListener(&app.funcs, js.Global(), "resize", func(ev js.Value) {
+		app.pageResized()
+	})
+	addListener(&app.funcs, js.Global().Get("document"), "paste", func(ev js.Value) {
+		cd := ev.Get("clipboardData")
+		if cd.Truthy() {
+			theClip.setText(cd.Call("getData", "text/plain").String())
+		}
+	})
+}
+
+// releaseEvents releases the handlers of initEvents, when the app ends
+func (app *appImpl) releaseEvents() {
+	for _, jf := range app.funcs {
+		jf.Release()
+	}
+	app.funcs = nil
+}
+
+// initEvents adds the handlers for the events of the canvas of the window
+func (w *windowImpl) initEvents() {
+	cv := w.canvas
+	addListener(&w.funcs, cv, "pointerdown", func(ev js.Value) {
+		ev.Call("preventDefault")
+		cv.Call("focus") // prevented by preventDefault
+		cv.Call("setPointerCapture", ev.Get("pointerId"))
+		w.pointerButton(ev, true)
+	})
+	addListener(&w.funcs, cv, "pointerup", func(ev js.Value) {
+		ev.Call("preventDefault")
+		w.pointerButton(ev, false)
+	})
+	addListener(&w.funcs, cv, "pointermove", func(ev js.Value) {
+		w.pointerMove(ev)
+	})
+	addListener(&w.funcs, cv, "wheel", func(ev js.Value) {
+		ev.Call("preventDefault")
+		w.wheel(ev)
+	})
+	addListener(&w.funcs, cv, "contextmenu", func(ev js.Value) {
+		ev.Call("preventDefault")
+	})
+	addListener(&w.funcs, cv, "keydown", func(ev js.Value) {
+		w.keyEvent(ev, key.Press)
+	})
+	addListener(&w.funcs, cv, "keyup", func(ev js.Value) {
+		w.keyEvent(ev, key.Release)
+	})
+	addListener(&w.funcs, cv, "focus", func(ev js.Value) {
+		w.app.mu.Lock()
+		for _, ow := range w.app.winlist {
+			if ow != w {
+				ow.setFocus(false)
+			}
+		}
+		w.app.mu.Unlock()
+		w.setFocus(true)
+	})
+	addListener(&w.funcs, cv, "blur", func(ev js.Value) {
+		w.setFocus(false)
+	})
+}
+
+// releaseEvents releases the handlers of initEvents, when the window is
+// closed
+func (w *windowImpl) releaseEvents() {
+	for _, jf := range w.funcs {
+		jf.Release()
+	}
+	w.funcs = nil
+}
+
+// domMods returns the key.Modifiers bits of given DOM event
+func domMods(ev js.Value) int32 {
+	m := int32(0)
+	if ev.Get("shiftKey").Bool() {
+		key.SetModifierBits(&m, key.Shift)
+	}
+	if ev.Get("ctrlKey").Bool() {
+		key.SetModifierBits(&m, key.Control)
+	}
+	if ev.Get("altKey").Bool() {
+		key.SetModifierBits(&m, key.Alt)
+	}
+	if ev.Get("metaKey").Bool() {
+		key.SetModifierBits(&m, key.Meta)
+	}
+	return m
+}
+
+// pointerPos returns the position of given pointer event in window pixels,
+// updating the mouse position -- if the pointer is locked (see
+// SetCursorEnabled), the position is moved by the movement of the event
+func (w *windowImpl) pointerPos(ev js.Value) (pos, from image.Point) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	from = w.mousePos
+	if js.Global().Get("document").Get("pointerLockElement").Equal(w.canvas) {
+		d := image.Point{ev.Get("movementX").Int(), ev.Get("movementY").Int()}
+		pos = from.Add(d.Mul(int(w.DevPixRatio)))
+	} else {
+		x := ev.Get("clientX").Float() - float64(w.Pos.X)
+		y := ev.Get("clientY").Float() - float64(w.Pos.Y)
+		pos = image.Point{int(float64(w.DevPixRatio) * x), int(float64(w.DevPixRatio) * y)}
+	}
+	w.mousePos = pos
+	return
+}
+
+// pointerButton sends the mouse.Event for a pointerdown or pointerup event
+func (w *windowImpl) pointerButton(ev js.Value, press bool) {
+	where, _ := w.pointerPos(ev)
+	mods := domMods(ev)
+	but := mouse.Left
+	switch ev.Get("button").Int() {
+	case 1:
+		but = mouse.Middle
+	case 2:
+		but = mouse.Right
+	}
+	if key.HasAnyModifierBits(mods, key.Control) {
+		but = mouse.Right
+	}
+	act := mouse.Release
+	if press {
+		act = mouse.Press
+	}
+	now := time.Now().UnixNano()
+	w.mu.Lock()
+	if press {
+		if time.Duration(now-w.mouseClickT) < time.Duration(mouse.DoubleClickMSec)*time.Millisecond {
+			act = mouse.DoubleClick
+		}
+		w.mouseClickT = now
+	}
+	w.mousePress = press
+	w.mouseBut = but
+	w.mu.Unlock()
+	event := &mouse.Event{
+		Where:     where,
+		Button:    but,
+		Action:    act,
+		Modifiers: mods,
+	}
+	event.Init()
+	w.Send(event)
+}
+
+// pointerMove sends the mouse.MoveEvent, or DragEvent if a button is
+// pressed, for a pointermove event
+func (w *windowImpl) pointerMove(ev js.Value) {
+	where, from := w.pointerPos(ev)
+	mods := domMods(ev)
+	w.mu.Lock()
+	press, but := w.mousePress, w.mouseBut
+	w.mu.Unlock()
+	if press {
+		event := &mouse.DragEvent{
+			MoveEvent: mouse.MoveEvent{
+				Event: mouse.Event{
+					Where:     where,
+					Button:    but,
+					Action:    mouse.Drag,
+					Modifiers: mods,
+				},
+				From: from,
+			},
+		}
+		event.Init()
+		w.Send(event)
+		return
+	}
+	event := &mouse.MoveEvent{
+		Event: mouse.Event{
+			Where:     where,
+			Button:    mouse.NoButton,
+			Action:    mouse.Move,
+			Modifiers: mods,
+		},
+		From: from,
+	}
+	event.Init()
+	w.Send(event)
+}
+
+// wheel sends the mouse.ScrollEvent for a wheel event -- the deltas of the
+// browser are in pixels, lines or pages, and a notch of the wheel is
+// typically 100 pixels, which is scrolled as for the glos driver
+func (w *windowImpl) wheel(ev js.Value) {
+	dx, dy := ev.Get("deltaX").Float(), ev.Get("deltaY").Float()
+	switch ev.Get("deltaMode").Int() {
+	case 1: // lines
+		dx *= 16
+		dy *= 16
+	case 2: // pages
+		dx *= float64(w.WnSize.X)
+		dy *= float64(w.WnSize.Y)
+	}
+	sc := 4 * float64(mouse.ScrollWheelSpeed) / 100
+	w.mu.Lock()
+	where := w.mousePos
+	w.mu.Unlock()
+	event := &mouse.ScrollEvent{
+		Event: mouse.Event{
+			Where:     where,
+			Action:    mouse.Scroll,
+			Modifiers: domMods(ev),
+		},
+		Delta: image.Point{int(dx * sc), int(dy * sc)},
+	}
+	event.Init()
+	w.Send(event)
+}
+
+// keyEvent sends the key.Event for a keydown or keyup event, and for a
+// keydown, the key.ChordEvent for a shortcut, or for the character typed --
+// the default action of the browser is prevented, except for pasting, so
+// that the paste event updates the clipboard (see clip.go)
+func (w *windowImpl) keyEvent(ev js.Value, act key.Actions) {
+	mods := domMods(ev)
+	ec := domKeyCodes[ev.Get("code").String()]
+	rn, mapped := key.CodeRuneMap[ec]
+	paste := ec == key.CodeV && key.HasAnyModifierBits(mods, key.Control, key.Meta)
+	if !paste {
+		ev.Call("preventDefault")
+	}
+	event := &key.Event{
+		Code:      ec,
+		Rune:      rn,
+		Modifiers: mods,
+		Action:    act,
+	}
+	event.Init()
+	w.Send(event)
+	if act != key.Press {
+		return
+	}
+	if ec < key.CodeLeftControl && (key.HasAnyModifierBits(mods, key.Control, key.Meta) || !mapped || ec == key.CodeTab) {
+		che := &key.ChordEvent{
+			Event: key.Event{
+				Code:      ec,
+				Rune:      rn,
+				Modifiers: mods,
+				Action:    act,
+			},
+		}
+		che.Init()
+		w.Send(che)
+		return
+	}
+	// the key of the event is the character typed, or the name of the key
+	ks := ev.Get("key").String()
+	if utf8.RuneCountInString(ks) != 1 {
+		return
+	}
+	ch, _ := utf8.DecodeRuneInString(ks)
+	che := &key.ChordEvent{
+		Event: key.Event{
+			Rune:      ch,
+			Modifiers: mods,
+			Action:    act,
+		},
+	}
+	che.Init()
+	w.Send(che)
+}
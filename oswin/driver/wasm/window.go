@@ -0,0 +1,498 @@
+// Copyright 2020 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build js,wasm
+
+package wasm
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"sync"
+	"syscall/js"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/driver/internal/drawer"
+	"github.com/goki/gi/oswin/driver/internal/event"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/oswin/window"
+	"github.com/goki/ki/bitflag"
+	"github.com/goki/mat32"
+)
+
+type windowImpl struct {
+	oswin.WindowBase
+	event.Deque
+	app            *appImpl
+	mu             sync.Mutex
+	runMu          sync.Mutex   // serializes RunOnWin functions
+	winTex         *textureImpl // WinTex, drawn by the user
+	back           *textureImpl // back buffer, drawn by the Drawer methods
+	closed         bool
+	closeReqFunc   func(win oswin.Window)
+	closeCleanFunc func(win oswin.Window)
+
+	// the canvas element and its 2d context, and the pixels copied to it
+	canvas  js.Value
+	ctx     js.Value
+	pixBuf  js.Value  // Uint8Array that the back buffer is copied into
+	imgData js.Value  // ImageData on pixBuf, put into the canvas
+	funcs   []js.Func // DOM event handlers of the canvas, see events.go
+
+	// mouse state for the events -- see events.go
+	mousePos    image.Point
+	mouseBut    mouse.Buttons
+	mousePress  bool
+	mouseClickT int64 // unix nanosec time of last press, for double-click
+}
+
+// newCanvas makes the canvas for the window and adds it to the page, on
+// top of the others
+func (w *windowImpl) newCanvas() {
+	doc := js.Global().Get("document")
+	cv := doc.Call("createElement", "canvas")
+	st := cv.Get("style")
+	st.Set("position", "fixed")
+	st.Set("outline", "none")
+	st.Set("touchAction", "none") // pointer events for touches instead of scrolling
+	cv.Set("tabIndex", 0)         // can have keyboard focus
+	w.canvas = cv
+	w.ctx = cv.Call("getContext", "2d", map[string]interface{}{"alpha": false})
+	w.setCanvasGeom()
+	w.app.mu.Lock()
+	w.app.topZ++
+	st.Set("zIndex", w.app.topZ)
+	w.app.mu.Unlock()
+	body := doc.Get("body")
+	body.Get("style").Set("margin", "0")
+	body.Get("style").Set("overflow", "hidden")
+	body.Call("appendChild", cv)
+	w.initEvents()
+}
+
+// setCanvasGeom sets the position and size of the canvas from those of the
+// window, and makes a new pixel buffer for it if the size has changed --
+// must be called under the mutex, or before the window is used
+func (w *windowImpl) setCanvasGeom() {
+	st := w.canvas.Get("style")
+	st.Set("left", fmt.Sprintf("%dpx", w.Pos.X))
+	st.Set("top", fmt.Sprintf("%dpx", w.Pos.Y))
+	st.Set("width", fmt.Sprintf("%dpx", w.WnSize.X))
+	st.Set("height", fmt.Sprintf("%dpx", w.WnSize.Y))
+	if w.canvas.Get("width").Int() == w.PxSize.X && w.canvas.Get("height").Int() == w.PxSize.Y && !w.pixBuf.IsUndefined() {
+		return
+	}
+	w.canvas.Set("width", w.PxSize.X)
+	w.canvas.Set("height", w.PxSize.Y)
+	n := 4 * w.PxSize.X * w.PxSize.Y
+	if n == 0 {
+		w.pixBuf = js.Undefined()
+		w.imgData = js.Undefined()
+		return
+	}
+	w.pixBuf = js.Global().Get("Uint8Array").New(n)
+	clamped := js.Global().Get("Uint8ClampedArray").New(w.pixBuf.Get("buffer"))
+	w.imgData = js.Global().Get("ImageData").New(clamped, w.PxSize.X, w.PxSize.Y)
+}
+
+// Handle returns the driver-specific handle for this window, which is
+// the js.Value of its canvas element.
+func (w *windowImpl) Handle() interface{} {
+	return w.canvas
+}
+
+// OSHandle returns 0 as there is no OS window
+func (w *windowImpl) OSHandle() uintptr {
+	return 0
+}
+
+func (w *windowImpl) MainMenu() oswin.MainMenu {
+	return nil
+}
+
+func (w *windowImpl) IsClosed() bool {
+	if w == nil {
+		return true
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.closed
+}
+
+func (w *windowImpl) IsVisible() bool {
+	if w == nil {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return !w.closed && w.winTex != nil && !w.IsMinimized()
+}
+
+// Activate returns true if the window is open -- there is no gpu context
+// to activate.
+func (w *windowImpl) Activate() bool {
+	return !w.IsClosed()
+}
+
+// DeActivate is a no-op, as there is no gpu context
+func (w *windowImpl) DeActivate() {
+}
+
+// for sending window.Event's
+func (w *windowImpl) sendWindowEvent(act window.Actions) {
+	winEv := window.Event{
+		Action: act,
+	}
+	winEv.Init()
+	w.Send(&winEv)
+}
+
+// NextEvent implements the oswin.EventDeque interface.
+func (w *windowImpl) NextEvent() oswin.Event {
+	e := w.Deque.NextEvent()
+	return e
+}
+
+// RunOnWin runs given function, serialized with all other functions run
+// on the window.
+func (w *windowImpl) RunOnWin(f func()) {
+	if w.IsClosed() {
+		return
+	}
+	w.runMu.Lock()
+	f()
+	w.runMu.Unlock()
+}
+
+// GoRunOnWin runs given function via RunOnWin and returns immediately
+func (w *windowImpl) GoRunOnWin(f func()) {
+	if w.IsClosed() {
+		return
+	}
+	go w.RunOnWin(f)
+}
+
+// Publish copies the back buffer to the canvas
+func (w *windowImpl) Publish() {
+	w.PublishRegions(nil)
+}
+
+// PublishRegions copies just the given regions of the back buffer to the
+// canvas, retaining the rest of the last published frame -- all of it if
+// rects is nil.
+func (w *windowImpl) PublishRegions(rects []image.Rectangle) {
+	if !w.IsVisible() {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	src := w.back.img
+	if w.closed || src == nil || w.imgData.IsUndefined() || src.Rect.Size() != w.PxSize {
+		return
+	}
+	if rects == nil {
+		js.CopyBytesToJS(w.pixBuf, src.Pix)
+		w.ctx.Call("putImageData", w.imgData, 0, 0)
+		return
+	}
+	for _, r := range rects {
+		r = r.Intersect(src.Rect)
+		if r.Empty() {
+			continue
+		}
+		// copy the rows of the region in one call, then put just the region
+		st := src.PixOffset(src.Rect.Min.X, r.Min.Y)
+		ed := src.PixOffset(src.Rect.Min.X, r.Max.Y)
+		js.CopyBytesToJS(w.pixBuf.Call("subarray", st, ed), src.Pix[st:ed])
+		w.ctx.Call("putImageData", w.imgData, 0, 0, r.Min.X, r.Min.Y, r.Dx(), r.Dy())
+	}
+}
+
+// PublishTex draws the current WinTex texture to the window and then
+// calls Publish() -- this is the typical update call.
+func (w *windowImpl) PublishTex() {
+	if !w.IsVisible() {
+		return
+	}
+	w.Copy(image.ZP, w.winTex, w.winTex.Bounds(), oswin.Src, nil)
+	w.Publish()
+}
+
+// SendEmptyEvent sends an empty, blank event to this window, which just has
+// the effect of pushing the system along during cases when the window
+// event loop needs to be "pinged" to get things moving along..
+func (w *windowImpl) SendEmptyEvent() {
+	if w.IsClosed() {
+		return
+	}
+	oswin.SendCustomEvent(w, nil)
+}
+
+// WinTex() returns the current Texture of the same size as the window that
+// is typically used to update the window contents.
+// Use the various Drawer and SetSubImage methods to update this Texture, and
+// then call PublishTex() to update the window.
+// This Texture is automatically resized when the window is resized, and
+// when that occurs, existing contents are lost -- a full update of the
+// Texture at the current size is required at that point.
+func (w *windowImpl) WinTex() oswin.Texture {
+	return w.winTex
+}
+
+// SetWinTexSubImage calls SetSubImage on WinTex with given parameters.
+func (w *windowImpl) SetWinTexSubImage(dp image.Point, src image.Image, sr image.Rectangle) error {
+	if !w.IsVisible() {
+		return nil
+	}
+	return w.winTex.SetSubImage(dp, src, sr)
+}
+
+////////////////////////////////////////////////
+//   Drawer wrappers
+
+func (w *windowImpl) Draw(src2dst mat32.Mat3, src oswin.Texture, sr image.Rectangle, op draw.Op, opts *oswin.DrawOptions) {
+	if !w.IsVisible() {
+		return
+	}
+	w.back.Draw(src2dst, src, sr, op, opts)
+}
+
+func (w *windowImpl) DrawUniform(src2dst mat32.Mat3, src color.Color, sr image.Rectangle, op draw.Op, opts *oswin.DrawOptions) {
+	if !w.IsVisible() {
+		return
+	}
+	w.back.DrawUniform(src2dst, src, sr, op, opts)
+}
+
+func (w *windowImpl) Copy(dp image.Point, src oswin.Texture, sr image.Rectangle, op draw.Op, opts *oswin.DrawOptions) {
+	if !w.IsVisible() {
+		return
+	}
+	drawer.Copy(w, dp, src, sr, op, opts)
+}
+
+func (w *windowImpl) Scale(dr image.Rectangle, src oswin.Texture, sr image.Rectangle, op draw.Op, opts *oswin.DrawOptions) {
+	if !w.IsVisible() {
+		return
+	}
+	drawer.Scale(w, dr, src, sr, op, opts)
+}
+
+func (w *windowImpl) Fill(dr image.Rectangle, src color.Color, op draw.Op) {
+	if !w.IsVisible() {
+		return
+	}
+	w.back.Fill(dr, src, op)
+}
+
+////////////////////////////////////////////////////////////
+//  Geom etc
+
+func (w *windowImpl) Screen() *oswin.Screen {
+	return w.app.screens[0]
+}
+
+func (w *windowImpl) Size() image.Point {
+	return w.PxSize
+}
+
+func (w *windowImpl) WinSize() image.Point {
+	return w.WnSize
+}
+
+func (w *windowImpl) Position() image.Point {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.Pos
+}
+
+func (w *windowImpl) PhysicalDPI() float32 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.PhysDPI
+}
+
+func (w *windowImpl) LogicalDPI() float32 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.LogDPI
+}
+
+func (w *windowImpl) SetLogicalDPI(dpi float32) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.LogDPI = dpi
+}
+
+// SetTitle sets the title of the window, which is the title of the page
+// for the first window
+func (w *windowImpl) SetTitle(title string) {
+	w.Titl = title
+	w.app.mu.Lock()
+	first := len(w.app.winlist) > 0 && w.app.winlist[0] == w
+	w.app.mu.Unlock()
+	if first {
+		js.Global().Get("document").Set("title", title)
+	}
+}
+
+// SetSize sets the size of the window in CSS pixel units, sending a
+// window.Resize event -- the first window is always the size of the page,
+// and is resized when the page is
+func (w *windowImpl) SetSize(sz image.Point) {
+	if w.IsClosed() {
+		return
+	}
+	w.mu.Lock()
+	dpr := devicePixelRatio()
+	w.DevPixRatio = dpr
+	w.PhysDPI = 96 * dpr
+	w.WnSize = sz
+	w.PxSize = image.Point{int(float32(sz.X) * dpr), int(float32(sz.Y) * dpr)}
+	w.winTex.SetSize(w.PxSize)
+	w.back.SetSize(w.PxSize)
+	w.setCanvasGeom()
+	w.mu.Unlock()
+	w.sendWindowEvent(window.Resize)
+}
+
+func (w *windowImpl) SetPixSize(sz image.Point) {
+	sz.X = int(float32(sz.X) / w.DevPixRatio)
+	sz.Y = int(float32(sz.Y) / w.DevPixRatio)
+	w.SetSize(sz)
+}
+
+func (w *windowImpl) SetPos(pos image.Point) {
+	if w.IsClosed() {
+		return
+	}
+	w.mu.Lock()
+	w.Pos = pos
+	w.setCanvasGeom()
+	w.mu.Unlock()
+	w.sendWindowEvent(window.Move)
+}
+
+func (w *windowImpl) SetGeom(pos image.Point, sz image.Point) {
+	w.SetSize(sz)
+	w.SetPos(pos)
+}
+
+// Raise shows the canvas of the window on top of the others, and gives it
+// the keyboard focus
+func (w *windowImpl) Raise() {
+	if w.IsClosed() {
+		return
+	}
+	if bitflag.HasAtomic(&w.Flag, int(oswin.Minimized)) {
+		bitflag.ClearAtomic(&w.Flag, int(oswin.Minimized))
+		w.canvas.Get("style").Set("display", "block")
+		w.sendWindowEvent(window.Minimize)
+	}
+	w.app.mu.Lock()
+	w.app.topZ++
+	w.canvas.Get("style").Set("zIndex", w.app.topZ)
+	w.app.mu.Unlock()
+	w.canvas.Call("focus")
+}
+
+// Minimize hides the canvas of the window
+func (w *windowImpl) Minimize() {
+	if w.IsClosed() {
+		return
+	}
+	bitflag.SetAtomic(&w.Flag, int(oswin.Minimized))
+	w.canvas.Get("style").Set("display", "none")
+	w.setFocus(false)
+	w.sendWindowEvent(window.Minimize)
+}
+
+// Hide is the same as Minimize
+func (w *windowImpl) Hide() {
+	w.Minimize()
+}
+
+// setFocus sets the focus state of the window, sending a window.Focus or
+// DeFocus event if it has changed
+func (w *windowImpl) setFocus(focus bool) {
+	if focus == w.IsFocus() {
+		return
+	}
+	if focus {
+		bitflag.SetAtomic(&w.Flag, int(oswin.Focus))
+		w.sendWindowEvent(window.Focus)
+	} else {
+		bitflag.ClearAtomic(&w.Flag, int(oswin.Focus))
+		w.sendWindowEvent(window.DeFocus)
+	}
+}
+
+func (w *windowImpl) SetCloseReqFunc(fun func(win oswin.Window)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closeReqFunc = fun
+}
+
+func (w *windowImpl) SetCloseCleanFunc(fun func(win oswin.Window)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closeCleanFunc = fun
+}
+
+func (w *windowImpl) CloseReq() {
+	if theApp.quitting {
+		w.Close()
+	}
+	if w.closeReqFunc != nil {
+		w.closeReqFunc(w)
+	} else {
+		w.Close()
+	}
+}
+
+func (w *windowImpl) CloseClean() {
+	if w.closeCleanFunc != nil {
+		w.closeCleanFunc(w)
+	}
+}
+
+// Close closes the window, removing its canvas from the page
+func (w *windowImpl) Close() {
+	if w.IsClosed() {
+		return
+	}
+	w.CloseClean()
+	w.sendWindowEvent(window.Close)
+	theApp.DeleteWin(w)
+	w.mu.Lock()
+	w.closed = true
+	w.winTex.Delete()
+	w.back.Delete()
+	w.releaseEvents()
+	w.canvas.Call("remove")
+	w.mu.Unlock()
+	if theApp.quitting {
+		theApp.quitCloseCnt <- struct{}{}
+	}
+}
+
+// SetMousePos records the mouse position, as the browser does not allow
+// the pointer to be moved
+func (w *windowImpl) SetMousePos(x, y float64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.mousePos = image.Point{int(x), int(y)}
+}
+
+// SetCursorEnabled locks the pointer to the canvas if not enabled, e.g.,
+// for raw mouse movements in 3D views
+func (w *windowImpl) SetCursorEnabled(enabled, raw bool) {
+	if enabled {
+		js.Global().Get("document").Call("exitPointerLock")
+	} else {
+		w.canvas.Call("requestPointerLock")
+	}
+}
@@ -91,6 +91,18 @@ const (
 	// RotateEvent is a touch-based rotate event
 	RotateEvent
 
+	// PanEvent is a touch-based two-finger pan event -- on trackpads,
+	// two-finger panning is reported as MouseScrollEvent instead
+	PanEvent
+
+	// LongPressEvent is a touch-based long-press event (a touch held in
+	// place), the touch equivalent of a right mouse click
+	LongPressEvent
+
+	// StylusEvent is a pen / stylus event from a drawing tablet or pen
+	// display, with pressure and tilt -- see the stylus package
+	StylusEvent
+
 	// WindowEvent reports any changes in the window size, orientation,
 	// iconify, close, open, paint -- these are all "internal" events
 	// from OS to GUI system, and not sent to widgets
@@ -119,6 +131,10 @@ const (
 	// DNDFocusEvent is for Enter / Exit events of the DND into / out of a given widget
 	DNDFocusEvent
 
+	// IMEEvent is for input method editor (IME) composition of text, e.g.,
+	// for CJK input -- see the ime package
+	IMEEvent
+
 	// CustomEventType is a user-defined event with a data interface{} field
 	CustomEventType
 
@@ -24,21 +24,25 @@ func _() {
 	_ = x[TouchEvent-8]
 	_ = x[MagnifyEvent-9]
 	_ = x[RotateEvent-10]
-	_ = x[WindowEvent-11]
-	_ = x[WindowResizeEvent-12]
-	_ = x[WindowPaintEvent-13]
-	_ = x[WindowShowEvent-14]
-	_ = x[WindowFocusEvent-15]
-	_ = x[DNDEvent-16]
-	_ = x[DNDMoveEvent-17]
-	_ = x[DNDFocusEvent-18]
-	_ = x[CustomEventType-19]
-	_ = x[EventTypeN-20]
+	_ = x[PanEvent-11]
+	_ = x[LongPressEvent-12]
+	_ = x[StylusEvent-13]
+	_ = x[WindowEvent-14]
+	_ = x[WindowResizeEvent-15]
+	_ = x[WindowPaintEvent-16]
+	_ = x[WindowShowEvent-17]
+	_ = x[WindowFocusEvent-18]
+	_ = x[DNDEvent-19]
+	_ = x[DNDMoveEvent-20]
+	_ = x[DNDFocusEvent-21]
+	_ = x[IMEEvent-22]
+	_ = x[CustomEventType-23]
+	_ = x[EventTypeN-24]
 }
 
-const _EventType_name = "MouseEventMouseMoveEventMouseDragEventMouseScrollEventMouseFocusEventMouseHoverEventKeyEventKeyChordEventTouchEventMagnifyEventRotateEventWindowEventWindowResizeEventWindowPaintEventWindowShowEventWindowFocusEventDNDEventDNDMoveEventDNDFocusEventCustomEventTypeEventTypeN"
+const _EventType_name = "MouseEventMouseMoveEventMouseDragEventMouseScrollEventMouseFocusEventMouseHoverEventKeyEventKeyChordEventTouchEventMagnifyEventRotateEventPanEventLongPressEventStylusEventWindowEventWindowResizeEventWindowPaintEventWindowShowEventWindowFocusEventDNDEventDNDMoveEventDNDFocusEventIMEEventCustomEventTypeEventTypeN"
 
-var _EventType_index = [...]uint16{0, 10, 24, 38, 54, 69, 84, 92, 105, 115, 127, 138, 149, 166, 182, 197, 213, 221, 233, 246, 261, 271}
+var _EventType_index = [...]uint16{0, 10, 24, 38, 54, 69, 84, 92, 105, 115, 127, 138, 146, 160, 171, 182, 199, 215, 230, 246, 254, 266, 279, 287, 302, 312}
 
 func (i EventType) String() string {
 	if i < 0 || i >= EventType(len(_EventType_index)-1) {
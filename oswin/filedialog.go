@@ -0,0 +1,61 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oswin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileDialogOptions are the options for a platform-native file dialog --
+// see App.FileDialog
+type FileDialogOptions struct {
+
+	// Title of the dialog
+	Title string
+
+	// Filename is the initial file (or directory) -- if it is an existing
+	// directory, the dialog starts there without an initial file
+	Filename string
+
+	// Exts are the file extensions (including the .) that are shown -- all
+	// files are shown if empty
+	Exts []string
+
+	// Save means a new file name can be entered, with confirmation if it
+	// already exists -- otherwise an existing file must be chosen
+	Save bool
+
+	// Dir means a directory is chosen instead of a file
+	Dir bool
+}
+
+// SetExts sets the Exts from a comma-separated list of extensions, as used
+// in gi file dialogs
+func (fo *FileDialogOptions) SetExts(exts string) {
+	fo.Exts = nil
+	for _, ex := range strings.Split(exts, ",") {
+		ex = strings.TrimSpace(ex)
+		if ex == "" {
+			continue
+		}
+		if !strings.HasPrefix(ex, ".") {
+			ex = "." + ex
+		}
+		fo.Exts = append(fo.Exts, ex)
+	}
+}
+
+// DirFile returns the initial directory and file from Filename
+func (fo *FileDialogOptions) DirFile() (dir, file string) {
+	if fo.Filename == "" {
+		return "", ""
+	}
+	if fi, err := os.Stat(fo.Filename); err == nil && fi.IsDir() {
+		return fo.Filename, ""
+	}
+	return filepath.Split(fo.Filename)
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ime defines input method editor (IME) events for the GoGi GUI
+// system.  An input method composes text from multiple keystrokes, e.g.,
+// for Chinese, Japanese and Korean (CJK) text.  While composing, the
+// platform IME sends Preedit events with the current composing (preedit)
+// text, which the text editing widget with the focus displays at its
+// cursor (underlined), without it being part of the edited text.  When
+// the user accepts the composition, a Commit event provides the final text
+// to insert -- the key events for the keystrokes consumed by the IME are
+// not sent.
+//
+// Drivers that support input methods implement the Window interface, so
+// the GUI can tell the IME where the text cursor is, for positioning its
+// candidate window.  Currently only the offscreen driver sends these
+// events (see offscreen.IMEPreedit and IMECommit) -- the glos driver
+// receives only the committed text from GLFW, as regular key events.
+package ime
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/ki/kit"
+)
+
+// ime.Event is an input method composition event, sent to the widget with
+// the keyboard focus
+type Event struct {
+	oswin.EventBase
+
+	// Action is Preedit for an update of the composing text, or Commit for
+	// the final text
+	Action Actions
+
+	// Text is the current composing text for Preedit (empty when the
+	// composition has ended or been canceled), or the text to insert for
+	// Commit
+	Text string
+
+	// Cursor is the position of the cursor within the composing Text for
+	// Preedit, in runes
+	Cursor int
+}
+
+// Actions are the kinds of IME events
+type Actions int32
+
+const (
+	// Preedit updates the composing text, which is displayed at the cursor
+	// but is not yet part of the edited text -- empty text ends the
+	// composition
+	Preedit Actions = iota
+
+	// Commit provides the final composed text, to be inserted at the
+	// cursor -- this also ends the composition
+	Commit
+
+	ActionsN
+)
+
+//go:generate stringer -type=Actions
+
+var KiT_Actions = kit.Enums.AddEnum(ActionsN, kit.NotBitFlag, nil)
+
+// Window is an optional interface for an oswin.Window of a driver that
+// supports platform input methods
+type Window interface {
+	// SetIMEActive sets whether text input with an input method is enabled
+	// -- true when a text editing widget has the keyboard focus
+	SetIMEActive(on bool)
+
+	// SetIMECaret sets the bounding box of the text cursor, in window
+	// pixels, where the IME positions its composition and candidate windows
+	SetIMECaret(caret image.Rectangle)
+}
+
+/////////////////////////////////////////////////////////////////
+// oswin.Event interface
+
+func (ev Event) Type() oswin.EventType {
+	return oswin.IMEEvent
+}
+
+func (ev Event) HasPos() bool {
+	return false
+}
+
+func (ev Event) Pos() image.Point {
+	return image.ZP
+}
+
+func (ev Event) OnFocus() bool {
+	return true
+}
+
+func (ev Event) String() string {
+	return fmt.Sprintf("Type: %v  Action: %v  Text: %q  Cursor: %v  Time: %v", ev.Type(), ev.Action, ev.Text, ev.Cursor, ev.Time())
+}
+
+// check for interface implementation
+var _ oswin.Event = &Event{}
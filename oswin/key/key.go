@@ -72,6 +72,21 @@ type Event struct {
 // generally appropriate for most uses
 type ChordEvent struct {
 	Event
+
+	// Prefix holds the chords of a multi-key sequence that were typed prior
+	// to this one, separated by spaces -- set by the gui when they start a
+	// sequence, and included in the Chord of this event
+	Prefix Chord
+}
+
+// Chord returns the chord of the event, as for Event.Chord, preceded by any
+// Prefix chords of a multi-key sequence, separated by spaces
+func (e *ChordEvent) Chord() Chord {
+	ch := e.Event.Chord()
+	if e.Prefix != "" {
+		return e.Prefix + " " + ch
+	}
+	return ch
 }
 
 func (ev Event) String() string {
@@ -166,7 +181,7 @@ func (ch Chord) Decode() (r rune, mods int32, err error) {
 func (ch Chord) Shortcut() string {
 	cs := strings.Replace(string(ch), "Control+", "^", -1) // ⌃ doesn't look as good
 	switch oswin.TheApp.Platform() {
-	case oswin.MacOS:
+	case oswin.MacOS, oswin.IOS:
 		cs = strings.Replace(cs, "Shift+", "⇧", -1)
 		cs = strings.Replace(cs, "Meta+", "⌘", -1)
 		cs = strings.Replace(cs, "Alt+", "⌥", -1)
@@ -184,7 +199,7 @@ func (ch Chord) Shortcut() string {
 // OSShortcut translates Command into either Control or Meta depending on platform
 func (ch Chord) OSShortcut() Chord {
 	sc := string(ch)
-	if oswin.TheApp.Platform() == oswin.MacOS {
+	if pl := oswin.TheApp.Platform(); pl == oswin.MacOS || pl == oswin.IOS {
 		sc = strings.Replace(sc, "Command+", "Meta+", -1)
 	} else {
 		sc = strings.Replace(sc, "Command+", "Control+", -1)
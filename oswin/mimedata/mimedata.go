@@ -16,6 +16,10 @@ import (
 	"bytes"
 	"encoding/base64"
 	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
 	"io"
 	"io/ioutil"
 	"log"
@@ -25,6 +29,8 @@ import (
 	"strings"
 
 	"github.com/goki/pi/filecat"
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
 )
 
 const (
@@ -253,4 +259,83 @@ func FromMultipart(body []byte, boundary string) Mimes {
 	return mi
 }
 
-// todo: image, etc extractors
+////////////////////////////////////////////////////////////////////////////////
+//    Rich text and images
+
+const (
+	// TextHTML is the MIME type for HTML text, e.g., for "copy as HTML"
+	TextHTML = "text/html"
+
+	// TextRTF is the MIME type for rich text format, as used on the X11
+	// clipboard (filecat uses application/rtf for files)
+	TextRTF = "text/rtf"
+
+	// ImagePNG is the MIME type for PNG images -- all images are written
+	// to and read from the system clipboard in this format
+	ImagePNG = "image/png"
+)
+
+// IsImage returns true if type is any of the image/ types
+func IsImage(typ string) bool {
+	return strings.HasPrefix(typ, "image/")
+}
+
+// IsRich returns true if type is one that the system clipboard supports
+// natively in addition to plain text: TextHTML, TextRTF or an image, which
+// are written as separate flavors instead of in a multipart MIME string
+func IsRich(typ string) bool {
+	return typ == TextHTML || typ == TextRTF || IsImage(typ)
+}
+
+// HasRich returns true if any of the data is of a rich type -- see IsRich
+func (mi Mimes) HasRich() bool {
+	for _, d := range mi {
+		if IsRich(d.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewImage returns a Mimes representation of the image as a single
+// ImagePNG Data
+func NewImage(img image.Image) (Mimes, error) {
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		return nil, err
+	}
+	return NewMime(ImagePNG, b.Bytes()), nil
+}
+
+// ToPNG returns the data of given image Data in PNG format, converting it
+// if it is another image type
+func ToPNG(d *Data) ([]byte, error) {
+	if d.Type == ImagePNG {
+		return d.Data, nil
+	}
+	img, _, err := image.Decode(bytes.NewReader(d.Data))
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Image returns the first image in the data, decoded, or nil if there is
+// none or it could not be decoded (PNG, JPEG, GIF, BMP and TIFF are
+// supported)
+func (mi Mimes) Image() image.Image {
+	for _, d := range mi {
+		if !IsImage(d.Type) {
+			continue
+		}
+		img, _, err := image.Decode(bytes.NewReader(d.Data))
+		if err == nil {
+			return img
+		}
+	}
+	return nil
+}
@@ -16,12 +16,15 @@ func _() {
 	_ = x[MacOS-0]
 	_ = x[LinuxX11-1]
 	_ = x[Windows-2]
-	_ = x[PlatformsN-3]
+	_ = x[LinuxWayland-3]
+	_ = x[Android-4]
+	_ = x[IOS-5]
+	_ = x[PlatformsN-6]
 }
 
-const _Platforms_name = "MacOSLinuxX11WindowsPlatformsN"
+const _Platforms_name = "MacOSLinuxX11WindowsLinuxWaylandAndroidIOSPlatformsN"
 
-var _Platforms_index = [...]uint8{0, 5, 13, 20, 30}
+var _Platforms_index = [...]uint8{0, 5, 13, 20, 32, 39, 42, 52}
 
 func (i Platforms) String() string {
 	if i < 0 || i >= Platforms(len(_Platforms_index)-1) {
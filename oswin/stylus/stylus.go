@@ -0,0 +1,118 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package stylus defines events for pen / stylus input from drawing tablets
+// and pen displays, for the GoGi GUI system, with the pressure and tilt of
+// the pen, and whether its eraser end is being used.
+//
+// Stylus events are sent in addition to the mouse events that the platform
+// emulates for the pen, so widgets that do not handle them work as with a
+// mouse -- a drawing widget can connect to oswin.StylusEvent, and mark the
+// events as processed, to get pressure-sensitive strokes, e.g., by setting
+// the StrokeStyle.Width of its girl.Paint from the Pressure of each Drag.
+package stylus
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/ki/kit"
+)
+
+// stylus.Event is a pen / stylus event, for each change in its position,
+// contact with the surface, pressure or tilt
+type Event struct {
+	oswin.EventBase
+
+	// Where is the pen location, in raw display dots (raw, actual pixels)
+	Where image.Point
+
+	// Action is Press when the pen touches the surface, Drag when it moves
+	// or changes pressure or tilt while touching it, Release when it is
+	// lifted, and Hover when it moves above the surface
+	Action Actions
+
+	// Pressure is the pressure of the pen on the surface, from 0 to 1 --
+	// 0 for Hover and Release, and 1 while touching for pens that do not
+	// report pressure
+	Pressure float32
+
+	// TiltX is the tilt of the pen along the X axis, from -1 (tilted to the
+	// left) to 1 (tilted to the right), 0 being perpendicular to the surface
+	TiltX float32
+
+	// TiltY is the tilt of the pen along the Y axis, from -1 (tilted away
+	// from the user) to 1 (tilted toward the user)
+	TiltY float32
+
+	// Eraser is whether the eraser end of the pen is being used
+	Eraser bool
+
+	// Barrel is whether a button on the barrel of the pen is pressed
+	Barrel bool
+
+	// Modifiers is a bitmask representing a set of modifier keys:
+	// key.ModShift, key.ModAlt, etc. -- bit positions are key.Modifiers
+	Modifiers int32
+}
+
+// Actions taken with the pen
+type Actions int32
+
+const (
+	// Press is the pen touching the surface
+	Press Actions = iota
+
+	// Drag is the pen moving, or changing pressure or tilt, while touching
+	// the surface
+	Drag
+
+	// Release is the pen being lifted from the surface
+	Release
+
+	// Hover is the pen moving above the surface, within range of the
+	// tablet
+	Hover
+
+	ActionsN
+)
+
+//go:generate stringer -type=Actions
+
+var KiT_Actions = kit.Enums.AddEnum(ActionsN, kit.NotBitFlag, nil)
+
+// SetModifiers sets the bitflags based on a list of key.Modifiers
+func (e *Event) SetModifiers(mods ...key.Modifiers) {
+	key.SetModifierBits(&e.Modifiers, mods...)
+}
+
+// HasAnyModifier tests whether any of given modifier(s) were set
+func (e *Event) HasAnyModifier(mods ...key.Modifiers) bool {
+	return key.HasAnyModifierBits(e.Modifiers, mods...)
+}
+
+func (ev Event) Type() oswin.EventType {
+	return oswin.StylusEvent
+}
+
+func (ev Event) HasPos() bool {
+	return true
+}
+
+func (ev Event) Pos() image.Point {
+	return ev.Where
+}
+
+func (ev Event) OnFocus() bool {
+	return false
+}
+
+func (ev Event) String() string {
+	return fmt.Sprintf("Type: %v Action: %v  Pos: %v  Pressure: %v  Tilt: %v, %v  Eraser: %v  Barrel: %v  Mods: %v Time: %v", ev.Type(), ev.Action, ev.Where, ev.Pressure, ev.TiltX, ev.TiltY, ev.Eraser, ev.Barrel, key.ModsString(ev.Modifiers), ev.Time())
+}
+
+// check for interface implementation
+var _ oswin.Event = &Event{}
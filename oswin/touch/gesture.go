@@ -0,0 +1,137 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package touch
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/goki/gi/oswin"
+)
+
+// MagnifyEvent is a pinch-zoom gesture, from two touches on a touch screen
+// moving apart or together, or a pinch on a trackpad.  A gesture is a
+// sequence of events starting with a Begin and ending with an End.
+type MagnifyEvent struct {
+	oswin.EventBase
+
+	// Where is the center of the gesture, in raw display dots
+	Where image.Point
+
+	// Magnification is the multiplicative scale factor since the last event
+	// of the gesture: > 1 for zooming in (spreading the fingers apart), and
+	// 1 for Begin and End
+	Magnification float32
+
+	// Action is Begin, Move or End
+	Action Actions
+}
+
+func (ev MagnifyEvent) Type() oswin.EventType {
+	return oswin.MagnifyEvent
+}
+
+func (ev MagnifyEvent) HasPos() bool {
+	return true
+}
+
+func (ev MagnifyEvent) Pos() image.Point {
+	return ev.Where
+}
+
+func (ev MagnifyEvent) OnFocus() bool {
+	return false
+}
+
+// OnWinFocus is false, as for scrolling, so the window need not have focus
+func (ev MagnifyEvent) OnWinFocus() bool {
+	return false
+}
+
+func (ev MagnifyEvent) String() string {
+	return fmt.Sprintf("Type: %v Action: %v  Pos: %v  Magnification: %v  Time: %v", ev.Type(), ev.Action, ev.Where, ev.Magnification, ev.Time())
+}
+
+// PanEvent is a two-finger pan gesture on a touch screen -- on trackpads,
+// panning with two fingers is reported by the platform as mouse scrolling.
+// A gesture is a sequence of events starting with a Begin and ending with
+// an End.
+type PanEvent struct {
+	oswin.EventBase
+
+	// Where is the center of the fingers, in raw display dots
+	Where image.Point
+
+	// Delta is the movement of the fingers since the last event of the
+	// gesture, in raw display dots -- content being panned moves along with
+	// the fingers, in the opposite direction of scrolling
+	Delta image.Point
+
+	// Action is Begin, Move or End
+	Action Actions
+}
+
+func (ev PanEvent) Type() oswin.EventType {
+	return oswin.PanEvent
+}
+
+func (ev PanEvent) HasPos() bool {
+	return true
+}
+
+func (ev PanEvent) Pos() image.Point {
+	return ev.Where
+}
+
+func (ev PanEvent) OnFocus() bool {
+	return false
+}
+
+// OnWinFocus is false, as for scrolling, so the window need not have focus
+func (ev PanEvent) OnWinFocus() bool {
+	return false
+}
+
+func (ev PanEvent) String() string {
+	return fmt.Sprintf("Type: %v Action: %v  Pos: %v  Delta: %v  Time: %v", ev.Type(), ev.Action, ev.Where, ev.Delta, ev.Time())
+}
+
+// LongPressEvent is a long-press gesture: a single touch held in place for
+// LongPressTime -- it is the touch equivalent of a right mouse click, so
+// if no widget processes it, gi.Window sends a right mouse press and
+// release at its position, opening context menus.
+type LongPressEvent struct {
+	oswin.EventBase
+
+	// Where is the position of the touch, in raw display dots
+	Where image.Point
+}
+
+func (ev LongPressEvent) Type() oswin.EventType {
+	return oswin.LongPressEvent
+}
+
+func (ev LongPressEvent) HasPos() bool {
+	return true
+}
+
+func (ev LongPressEvent) Pos() image.Point {
+	return ev.Where
+}
+
+func (ev LongPressEvent) OnFocus() bool {
+	return false
+}
+
+func (ev LongPressEvent) String() string {
+	return fmt.Sprintf("Type: %v  Pos: %v  Time: %v", ev.Type(), ev.Where, ev.Time())
+}
+
+// check for interface implementation
+var (
+	_ oswin.Event = &MagnifyEvent{}
+	_ oswin.Event = &PanEvent{}
+	_ oswin.Event = &LongPressEvent{}
+)
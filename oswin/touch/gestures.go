@@ -0,0 +1,202 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package touch
+
+import (
+	"image"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/oswin"
+)
+
+var (
+	// LongPressTime is how long a single touch must be held in place for a
+	// LongPressEvent
+	LongPressTime = 500 * time.Millisecond
+
+	// GestureSlop is how far touches must move, in raw display dots, to
+	// start a pinch-zoom or pan gesture, or to cancel a long press
+	GestureSlop = float32(10)
+)
+
+// Gestures recognizes the pinch-zoom, two-finger pan and long-press
+// gestures in the touch events of a window, for drivers whose platform
+// reports touch points but not gestures.  The driver passes each touch
+// event to Touch, after sending it to the window, and Send is called with
+// each gesture event.  Pinch-zoom and pan use the first two touches, and
+// can happen at the same time.
+type Gestures struct {
+	// Send is called with each gesture event, e.g., to send it to the
+	// window -- long presses are sent from a timer goroutine
+	Send func(ev oswin.Event)
+
+	mu         sync.Mutex
+	pts        map[Sequence]image.Point // current positions of touches
+	seqs       []Sequence               // touches in order of Begin
+	lastDist   float32                  // distance between first two at last magnify event
+	lastCtr    image.Point              // center of first two at last pan event
+	magnifying bool
+	panning    bool
+	pressGen   int // generation of long press timer, so stale ones are ignored
+	pressing   bool
+	pressAt    image.Point
+}
+
+// Touch processes a touch event, calling Send with any gesture events
+func (g *Gestures) Touch(ev *Event) {
+	g.mu.Lock()
+	if g.pts == nil {
+		g.pts = make(map[Sequence]image.Point)
+	}
+	var evs []oswin.Event
+	_, has := g.pts[ev.Sequence]
+	switch ev.Action {
+	case Begin:
+		if has {
+			break
+		}
+		g.pts[ev.Sequence] = ev.Where
+		g.seqs = append(g.seqs, ev.Sequence)
+		switch len(g.seqs) {
+		case 1:
+			g.startPress(ev.Where)
+		case 2:
+			g.cancelPress()
+			g.startTwo()
+		}
+	case Move:
+		if !has {
+			break
+		}
+		g.pts[ev.Sequence] = ev.Where
+		if g.pressing && pointDist(ev.Where, g.pressAt) > GestureSlop {
+			g.cancelPress()
+		}
+		if len(g.seqs) >= 2 && (ev.Sequence == g.seqs[0] || ev.Sequence == g.seqs[1]) {
+			evs = g.moveTwo()
+		}
+	case End:
+		if !has {
+			break
+		}
+		g.cancelPress()
+		two := len(g.seqs) >= 2 && (ev.Sequence == g.seqs[0] || ev.Sequence == g.seqs[1])
+		if two {
+			evs = g.endTwo()
+		}
+		delete(g.pts, ev.Sequence)
+		for i, s := range g.seqs {
+			if s == ev.Sequence {
+				g.seqs = append(g.seqs[:i], g.seqs[i+1:]...)
+				break
+			}
+		}
+		if two && len(g.seqs) >= 2 { // next pair takes over
+			g.startTwo()
+		}
+	}
+	g.mu.Unlock()
+	for _, gev := range evs {
+		g.send(gev)
+	}
+}
+
+// send initializes the event and calls Send with it
+func (g *Gestures) send(ev oswin.Event) {
+	if g.Send == nil {
+		return
+	}
+	ev.Init()
+	g.Send(ev)
+}
+
+// pointDist returns the distance between two points
+func pointDist(a, b image.Point) float32 {
+	d := a.Sub(b)
+	return float32(math.Hypot(float64(d.X), float64(d.Y)))
+}
+
+// twoPoints returns the distance between the first two touches and their
+// center -- must be called under mutex
+func (g *Gestures) twoPoints() (float32, image.Point) {
+	a := g.pts[g.seqs[0]]
+	b := g.pts[g.seqs[1]]
+	return pointDist(a, b), a.Add(b).Div(2)
+}
+
+// startTwo starts tracking the first two touches -- must be called under
+// mutex
+func (g *Gestures) startTwo() {
+	g.lastDist, g.lastCtr = g.twoPoints()
+}
+
+// moveTwo returns the gesture events for a move of one of the first two
+// touches, starting gestures when they have moved far enough -- must be
+// called under mutex
+func (g *Gestures) moveTwo() []oswin.Event {
+	var evs []oswin.Event
+	d, c := g.twoPoints()
+	if !g.magnifying && math.Abs(float64(d-g.lastDist)) > float64(GestureSlop) {
+		g.magnifying = true
+		evs = append(evs, &MagnifyEvent{Where: c, Magnification: 1, Action: Begin})
+	}
+	if !g.panning && pointDist(c, g.lastCtr) > GestureSlop {
+		g.panning = true
+		evs = append(evs, &PanEvent{Where: c, Action: Begin})
+	}
+	if g.magnifying && g.lastDist > 0 && d > 0 && d != g.lastDist {
+		evs = append(evs, &MagnifyEvent{Where: c, Magnification: d / g.lastDist, Action: Move})
+		g.lastDist = d
+	}
+	if g.panning && c != g.lastCtr {
+		evs = append(evs, &PanEvent{Where: c, Delta: c.Sub(g.lastCtr), Action: Move})
+		g.lastCtr = c
+	}
+	return evs
+}
+
+// endTwo returns the events ending any gestures of the first two touches
+// -- must be called under mutex
+func (g *Gestures) endTwo() []oswin.Event {
+	var evs []oswin.Event
+	_, c := g.twoPoints()
+	if g.magnifying {
+		evs = append(evs, &MagnifyEvent{Where: c, Magnification: 1, Action: End})
+		g.magnifying = false
+	}
+	if g.panning {
+		evs = append(evs, &PanEvent{Where: c, Action: End})
+		g.panning = false
+	}
+	return evs
+}
+
+// startPress starts the timer for a long press at given position -- must
+// be called under mutex
+func (g *Gestures) startPress(pos image.Point) {
+	g.pressGen++
+	gen := g.pressGen
+	g.pressing = true
+	g.pressAt = pos
+	time.AfterFunc(LongPressTime, func() {
+		g.mu.Lock()
+		if !g.pressing || gen != g.pressGen {
+			g.mu.Unlock()
+			return
+		}
+		g.pressing = false
+		at := g.pressAt
+		g.mu.Unlock()
+		g.send(&LongPressEvent{Where: at})
+	})
+}
+
+// cancelPress cancels any pending long press -- must be called under mutex
+func (g *Gestures) cancelPress() {
+	g.pressing = false
+	g.pressGen++
+}
@@ -8,7 +8,11 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// Package touch defines an event for touch input, for the GoGi GUI system.
+// Package touch defines events for touch input, for the GoGi GUI system:
+// an Event for each touch point, and the MagnifyEvent (pinch-zoom),
+// PanEvent (two-finger pan) and LongPressEvent gestures, which drivers
+// either get from the platform, or synthesize from the touch points with
+// Gestures.
 package touch
 
 // The best source on android input events is the NDK: include/android/input.h
@@ -95,46 +99,3 @@ func (ev Event) OnFocus() bool {
 
 // check for interface implementation
 var _ oswin.Event = &Event{}
-
-// todo: what about these higher-level abstractions of touch-like events?
-
-// // MagnifyEvent is used to represent a magnification gesture.
-// type MagnifyEvent struct {
-// 	GestureEvent
-// 	Magnification float64 // the multiplicative scale factor
-// }
-
-// func (ev MagnifyEvent) EventType() EventType {
-// 	return MagnifyEventType
-// }
-
-// // check for interface implementation
-// var _ Event = &MagnifyEvent{}
-
-// ////////////////////////////////////////////
-
-// // RotateEvent is used to represent a rotation gesture.
-// type RotateEvent struct {
-// 	GestureEvent
-// 	Rotation float64 // measured in degrees; positive == clockwise
-// }
-
-// func (ev RotateEvent) EventType() EventType {
-// 	return RotateEventType
-// }
-
-// // check for interface implementation
-// var _ Event = &RotateEvent{}
-
-// // Scroll Event is used to represent a scrolling gesture.
-// type ScrollEvent struct {
-// 	GestureEvent
-// 	Delta image.Point
-// }
-
-// func (ev ScrollEvent) EventType() EventType {
-// 	return ScrollEventType
-// }
-
-// // check for interface implementation
-// var _ Event = &ScrollEvent{}
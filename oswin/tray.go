@@ -0,0 +1,91 @@
+// Copyright (c) 2020, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oswin
+
+import (
+	"image"
+
+	"github.com/goki/ki/kit"
+)
+
+// SystemTray is an optional interface for an App that can show icons in the
+// system tray (the notification area of Windows and Linux desktops, and the
+// status items at the right of the menu bar on the Mac), e.g., for apps that
+// keep running in the background, with their windows hidden -- see
+// Window.Hide.
+type SystemTray interface {
+	// NewTrayIcon adds a new icon to the system tray, with given image
+	// (which should be small and square, e.g., 32x32) and tooltip, returning
+	// an error if there is no system tray -- on Linux the icon is a
+	// StatusNotifierItem, which needs a StatusNotifierWatcher on the session
+	// D-Bus (e.g., the KDE panel, or the AppIndicator extension of GNOME),
+	// and otherwise the yad command is used for the older XEmbed trays, so
+	// the error names both when neither is available.
+	NewTrayIcon(icon image.Image, tooltip string) (TrayIcon, error)
+}
+
+// TrayIcon is an icon in the system tray, which can have a menu -- on
+// Windows and Linux clicking on the icon sends a TrayClicked event and the
+// menu is shown by right-clicking, whereas on the Mac clicking shows the
+// menu if there is one, and otherwise sends TrayClicked.
+type TrayIcon interface {
+	// SetIcon sets the image of the icon.
+	SetIcon(icon image.Image)
+
+	// SetTooltip sets the tooltip shown when hovering over the icon.
+	SetTooltip(tip string)
+
+	// SetMenu sets the items of the menu of the icon -- nil for no menu.
+	SetMenu(items []TrayMenuItem)
+
+	// SetEventFunc sets the function called for events of the icon, with
+	// the index of the selected menu item for TrayMenuSelected -- it is
+	// called on another goroutine than the event loops of the windows.
+	SetEventFunc(fun func(ti TrayIcon, ev TrayEvents, item int))
+
+	// Close removes the icon from the system tray.
+	Close()
+}
+
+// TrayMenuItem is an item of the menu of a TrayIcon or the dock icon
+type TrayMenuItem struct {
+	// Label is the text of the item -- an empty label is a separator.
+	Label string
+
+	// Inactive items are shown grayed out, and cannot be selected (with yad
+	// they are shown as normal, but do nothing when selected).
+	Inactive bool
+}
+
+// TrayEvents are the events of a TrayIcon
+type TrayEvents int32
+
+const (
+	// TrayClicked is sent when the icon is clicked.
+	TrayClicked TrayEvents = iota
+
+	// TrayMenuSelected is sent when an item of the menu is selected.
+	TrayMenuSelected
+
+	TrayEventsN
+)
+
+//go:generate stringer -type=TrayEvents
+
+var KiT_TrayEvents = kit.Enums.AddEnum(TrayEventsN, kit.NotBitFlag, nil)
+
+// DockIcon is an optional interface for an App whose icon is shown in a
+// dock while it runs (currently only on the Mac), with a badge and menu.
+type DockIcon interface {
+	// SetDockBadge sets the badge shown on the dock icon, e.g., a count of
+	// unread messages -- "" for no badge.
+	SetDockBadge(badge string)
+
+	// SetDockMenu sets the items of the menu of the dock icon, shown above
+	// the standard items of the dock, with the function called with the
+	// index of the selected item, on another goroutine than the event loops
+	// of the windows -- nil items for no menu.
+	SetDockMenu(items []TrayMenuItem, fun func(item int))
+}
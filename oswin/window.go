@@ -91,14 +91,20 @@ type Window interface {
 	SetGeom(pos image.Point, sz image.Point)
 
 	// Raise requests that the window be at the top of the stack of windows,
-	// and receive focus.  If it is iconified, it will be de-iconified.  This
-	// is the only supported mechanism for de-iconifying.
+	// and receive focus.  If it is iconified or hidden, it will be
+	// de-iconified or shown again.  This is the only supported mechanism for
+	// de-iconifying.
 	Raise()
 
 	// Minimize requests that the window be iconified, making it no longer
 	// visible or active -- rendering should not occur for minimized windows.
 	Minimize()
 
+	// Hide hides the window entirely, including from the taskbar or dock,
+	// e.g., for minimizing to the system tray (see SystemTray) -- it is
+	// flagged as Minimized while hidden, and shown again by Raise.
+	Hide()
+
 	// PhysicalDPI is the physical dots per inch of the window, for generating
 	// true-to-physical-size output, for example -- see the gi/units package for
 	// translating into various other units.
@@ -254,7 +260,8 @@ type Window interface {
 	Handle() interface{}
 
 	// OSHandle returns the OS-specific underlying window handle:
-	// MacOS: NSWindow*, Windows:  HWND, LinuxX11: X11Window
+	// MacOS: NSWindow*, Windows:  HWND, LinuxX11: X11Window, LinuxWayland: 0
+	// (glfw does not expose the wl_surface)
 	OSHandle() uintptr
 
 	// Sets the mouse position to given values
@@ -270,6 +277,18 @@ type Window interface {
 	Drawer
 }
 
+// RegionPublisher is an optional interface for a Window that retains the
+// contents it has already published, so it can publish just the regions
+// of the window that have changed, which is much more efficient for small
+// changes such as a blinking cursor or hover highlight.
+type RegionPublisher interface {
+	// PublishRegions is like Publish, but only the given regions of the
+	// window, which must contain everything that has been drawn since the
+	// last Publish, are updated -- the rest of the window retains its
+	// previously published contents.
+	PublishRegions(rects []image.Rectangle)
+}
+
 // WindowBase provides a base-level implementation of the generic data aspects
 // of the window, including maintaining the current window size and dpi
 type WindowBase struct {
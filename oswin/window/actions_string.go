@@ -22,12 +22,14 @@ func _() {
 	_ = x[Paint-6]
 	_ = x[Show-7]
 	_ = x[ScreenUpdate-8]
-	_ = x[ActionsN-9]
+	_ = x[ThemeChanged-9]
+	_ = x[ScaleChange-10]
+	_ = x[ActionsN-11]
 }
 
-const _Actions_name = "CloseMinimizeResizeMoveFocusDeFocusPaintShowScreenUpdateActionsN"
+const _Actions_name = "CloseMinimizeResizeMoveFocusDeFocusPaintShowScreenUpdateThemeChangedScaleChangeActionsN"
 
-var _Actions_index = [...]uint8{0, 5, 13, 19, 23, 28, 35, 40, 44, 56, 64}
+var _Actions_index = [...]uint8{0, 5, 13, 19, 23, 28, 35, 40, 44, 56, 68, 79, 87}
 
 func (i Actions) String() string {
 	if i < 0 || i >= Actions(len(_Actions_index)-1) {
@@ -73,6 +73,17 @@ const (
 	// and it should then perform any necessary updating
 	ScreenUpdate
 
+	// ThemeChanged occurs when the OS appearance changes between light and
+	// dark (see oswin.App.IsDark).  Like ScreenUpdate, this event is sent to
+	// the first window on the list of active windows.
+	ThemeChanged
+
+	// ScaleChange means that the window has moved to a screen with a
+	// different DPI or device pixel ratio (scale), or the scale of its screen
+	// has changed -- the window LogicalDPI and PhysicalDPI and its Screen have
+	// the new values.  Requires re-styling and a redraw at the new scale.
+	ScaleChange
+
 	ActionsN
 )
 
@@ -27,5 +27,9 @@ interface for drawing.
 The Path element uses a compiled bytecode version of the Data path for
 increased speed.
 
+SVG drawings can be saved back out as standard SVG with SVG.SaveXML /
+WriteXML, and SaveSceneXML / WriteSceneXML export a rendered gi 2D scene,
+with the svg drawings within it preserved as vectors.
+
 */
 package svg
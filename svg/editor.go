@@ -6,12 +6,15 @@ package svg
 
 import (
 	"fmt"
+	"image"
 
 	"github.com/goki/gi/gi"
+	"github.com/goki/gi/gist"
 	"github.com/goki/gi/giv"
 	"github.com/goki/gi/oswin"
 	"github.com/goki/gi/oswin/cursor"
 	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/oswin/touch"
 	"github.com/goki/ki/ki"
 	"github.com/goki/ki/kit"
 	"github.com/goki/mat32"
@@ -23,6 +26,7 @@ type Editor struct {
 	Trans         mat32.Vec2 `desc:"view translation offset (from dragging)"`
 	Scale         float32    `desc:"view scaling (from zooming)"`
 	SetDragCursor bool       `view:"-" desc:"has dragging cursor been set yet?"`
+	bound         []ki.Ki    // elements that we are bound to for editing, see EditElem
 }
 
 var KiT_Editor = kit.Types.AddType(&Editor{}, EditorProps)
@@ -86,6 +90,25 @@ func (svg *Editor) EditorEvents() {
 		ssvg.SetFullReRender()
 		ssvg.UpdateSig()
 	})
+	svg.ConnectEvent(oswin.MagnifyEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*touch.MagnifyEvent)
+		me.SetProcessed()
+		ssvg := recv.Embed(KiT_Editor).(*Editor)
+		if me.Action != touch.Move {
+			return
+		}
+		ssvg.ZoomAt(me.Where, me.Magnification)
+	})
+	svg.ConnectEvent(oswin.PanEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*touch.PanEvent)
+		me.SetProcessed()
+		ssvg := recv.Embed(KiT_Editor).(*Editor)
+		ssvg.Trans.X += float32(me.Delta.X)
+		ssvg.Trans.Y += float32(me.Delta.Y)
+		ssvg.SetTransform()
+		ssvg.SetFullReRender()
+		ssvg.UpdateSig()
+	})
 	svg.ConnectEvent(oswin.MouseEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
 		me := d.(*mouse.Event)
 		ssvg := recv.Embed(KiT_Editor).(*Editor)
@@ -97,7 +120,7 @@ func (svg *Editor) EditorEvents() {
 		if me.Action == mouse.Release && me.Button == mouse.Right {
 			me.SetProcessed()
 			if obj != nil {
-				giv.StructViewDialog(ssvg.Viewport, obj, giv.DlgOpts{Title: "SVG Element View"}, nil, nil)
+				ssvg.EditElemMenu(obj, me.Where)
 			}
 		}
 	})
@@ -108,12 +131,112 @@ func (svg *Editor) EditorEvents() {
 		obj := ssvg.FirstContainingPoint(me.Where, true)
 		if obj != nil {
 			pos := me.Where
-			ttxt := fmt.Sprintf("element name: %v -- use right mouse click to edit", obj.Name())
+			ttxt := fmt.Sprintf("element name: %v -- use right mouse click to edit it, or its fill or stroke", obj.Name())
 			gi.PopupTooltip(obj.Name(), pos.X, pos.Y, svg.ViewportSafe(), ttxt)
 		}
 	})
 }
 
+// EditElem opens a StructViewDialog for editing given element -- the editor
+// is bound to the element (see giv.BindModel), so that it renders the
+// changes from edits in the dialog, and their undo and redo via the
+// giv.UndoMgr of the window.
+func (svg *Editor) EditElem(obj ki.Ki) {
+	bound := false
+	for _, b := range svg.bound {
+		if b == obj {
+			bound = true
+			break
+		}
+	}
+	if !bound {
+		giv.BindModel(obj, svg.This().(giv.BoundView))
+		svg.bound = append(svg.bound, obj)
+	}
+	giv.StructViewDialog(svg.Viewport, obj, giv.DlgOpts{Title: "SVG Element View"}, nil, nil)
+}
+
+// EditElemMenu pops up a menu at given position for editing given element,
+// with EditElem, or its fill or stroke, with EditPaint
+func (svg *Editor) EditElemMenu(obj ki.Ki, pos image.Point) {
+	var men gi.Menu
+	men.AddAction(gi.ActOpts{Label: "Edit Element...", Tooltip: "edit all the fields of the element"}, svg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		recv.Embed(KiT_Editor).(*Editor).EditElem(obj)
+	})
+	men.AddAction(gi.ActOpts{Label: "Edit Fill...", Tooltip: "edit the fill of the element, as a solid color or a gradient"}, svg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		recv.Embed(KiT_Editor).(*Editor).EditPaint(obj, "fill")
+	})
+	men.AddAction(gi.ActOpts{Label: "Edit Stroke...", Tooltip: "edit the stroke of the element, as a solid color or a gradient"}, svg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		recv.Embed(KiT_Editor).(*Editor).EditPaint(obj, "stroke")
+	})
+	gi.PopupMenu(men, pos.X, pos.Y, svg.ViewportSafe(), "svg-edit-menu")
+}
+
+// EditPaint opens a giv.GradientViewDialog for editing the "fill" or
+// "stroke" (prop) of given element, as a solid color or a gradient, which
+// is set as the property of the element when accepted -- see SetPaintProp
+func (svg *Editor) EditPaint(obj ki.Ki, prop string) {
+	nbi := obj.Embed(KiT_NodeBase)
+	if nbi == nil {
+		return
+	}
+	nb := nbi.(*NodeBase)
+	cs := &nb.Pnt.FillStyle.Color
+	title := "Edit Fill"
+	if prop == "stroke" {
+		cs = &nb.Pnt.StrokeStyle.Color
+		title = "Edit Stroke"
+	}
+	giv.GradientViewDialog(svg.Viewport, cs, giv.DlgOpts{Title: title, Prompt: obj.Name()}, svg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig != int64(gi.DialogAccepted) {
+			return
+		}
+		ddlg := send.Embed(gi.KiT_Dialog).(*gi.Dialog)
+		ncs := giv.GradientViewDialogValue(ddlg)
+		recv.Embed(KiT_Editor).(*Editor).SetPaintProp(obj, prop, &ncs)
+	})
+}
+
+// SetPaintProp sets the "fill" or "stroke" (prop) property of given element
+// from given color spec: a solid color is set as its hex spec, or none, and
+// a gradient is set in a gi.Gradient in the Defs, named for the element and
+// the property, e.g., rect1-fill, which the property refers to by url
+func (svg *Editor) SetPaintProp(obj ki.Ki, prop string, cs *gist.ColorSpec) {
+	switch {
+	case cs.Source == gist.SolidColor || cs.Gradient == nil:
+		if cs.Color.IsNil() {
+			obj.SetProp(prop, "none")
+		} else {
+			obj.SetProp(prop, giv.ColorHexString(cs.Color))
+		}
+	default:
+		gnm := obj.Name() + "-" + prop
+		gr, ok := svg.Defs.ChildByName(gnm, 0).(*gi.Gradient)
+		if !ok {
+			gr = gi.AddNewGradient(&svg.Defs, gnm)
+		}
+		gr.Grad.CopyFrom(cs)
+		obj.SetProp(prop, "url(#"+gnm+")")
+	}
+	svg.SetFullReRender()
+	svg.UpdateSig()
+}
+
+// ModelChanged renders the changes of an element edited by EditElem, per
+// giv.BoundView
+func (svg *Editor) ModelChanged(field string) {
+	svg.SetFullReRender()
+	svg.UpdateSig()
+}
+
+func (svg *Editor) Disconnect() {
+	svg.SVG.Disconnect()
+	for _, b := range svg.bound {
+		giv.UnbindModel(b, svg.This().(giv.BoundView))
+	}
+	svg.bound = nil
+}
+
 func (svg *Editor) ConnectEvents2D() {
 	svg.EditorEvents()
 }
@@ -130,6 +253,22 @@ func (svg *Editor) InitScale() {
 	}
 }
 
+// ZoomAt multiplies Scale by given factor, keeping the drawing fixed at
+// given position in the window, e.g., the center of a pinch-zoom gesture
+func (svg *Editor) ZoomAt(pos image.Point, factor float32) {
+	svg.InitScale()
+	ns := svg.Scale * factor
+	if ns <= 0 {
+		return
+	}
+	pt := mat32.NewVec2FmPoint(pos.Sub(svg.WinBBox.Min))
+	svg.Trans = pt.Sub(pt.Sub(svg.Trans).MulScalar(factor))
+	svg.Scale = ns
+	svg.SetTransform()
+	svg.SetFullReRender()
+	svg.UpdateSig()
+}
+
 // SetTransform sets the transform based on Trans and Scale values
 func (svg *Editor) SetTransform() {
 	svg.InitScale()
@@ -0,0 +1,302 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki/ki"
+)
+
+// IconPackSep separates the name of an IconPack from the names of its icons,
+// e.g., fa:github for the github icon of the fa pack
+const IconPackSep = ":"
+
+// IconPack is a set of SVG icons loaded at runtime from a directory or from
+// embedded assets, e.g., Material or FontAwesome icons, which are named with
+// the Name of the pack, e.g., fa:github, so they never clash with the
+// built-in icons or those of other packs.  Icons can have size-specific
+// variants, in sub-directories named by their size in pixels, e.g., 16,
+// 16x16 or 16px, or in files with an @ suffix, e.g., github@16.svg, of
+// which the one closest to the size at which an icon is rendered is used.
+// If Recolor is set, the colors of the icons are removed when they are
+// loaded, so that they are drawn in the colors of the theme, like the
+// built-in icons.  See OpenIconPack and OpenIconPackFromAssetDir.
+type IconPack struct {
+	Name    string  `desc:"name of the pack, which is the namespace of its icons"`
+	Path    string  `desc:"path of the directory, or of the assets, that the icons are loaded from"`
+	Recolor bool    `desc:"remove the fill and stroke colors of the icons, so that they are drawn in the colors of the theme"`
+	Icons   IconSet `desc:"icons of the pack, by their name without the name of the pack -- size-specific variants are in the Variants of each icon"`
+}
+
+// IconPacks are the icon packs that have been opened, by name
+var IconPacks = map[string]*IconPack{}
+
+// iconPacksMu protects IconPacks
+var iconPacksMu sync.RWMutex
+
+// OpenIconPack opens the .svg icons in the given directory, and its
+// sub-directories, as an IconPack of given name, replacing any pack of that
+// name -- the icons are only read when they are first used.
+func OpenIconPack(name, dir string, recolor bool) (*IconPack, error) {
+	ip := &IconPack{Name: name, Path: dir, Recolor: recolor, Icons: make(IconSet)}
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.ToLower(filepath.Ext(p)) != ".svg" {
+			return nil
+		}
+		rp, _ := filepath.Rel(dir, p)
+		ip.addIcon(filepath.ToSlash(rp), p)
+		return nil
+	})
+	if err != nil {
+		err = fmt.Errorf("svg.OpenIconPack: error opening icon pack %v from %q: %v", name, dir, err)
+		log.Println(err)
+		return nil, err
+	}
+	ip.sortVariants()
+	ip.register()
+	return ip, nil
+}
+
+// OpenIconPackFromAssetDir opens the .svg icons at given path of embedded
+// assets, using Asset and AssetDir funcs as generated by e.g.,
+// https://github.com/shuLhan/go-bindata, as an IconPack of given name,
+// replacing any pack of that name -- sub-directories are only included
+// for size-specific variants, and all the icons are read right away.
+func OpenIconPackFromAssetDir(name, dir string, recolor bool, dirFunc func(path string) ([]string, error), assetFunc func(name string) ([]byte, error)) (*IconPack, error) {
+	ip := &IconPack{Name: name, Path: dir, Recolor: recolor, Icons: make(IconSet)}
+	fnms, err := dirFunc(dir)
+	if err != nil {
+		err = fmt.Errorf("svg.OpenIconPackFromAssetDir: error opening icon pack %v from %q: %v", name, dir, err)
+		log.Println(err)
+		return nil, err
+	}
+	var lasterr error
+	for i := 0; i < len(fnms); i++ { // size sub-directories are appended
+		fnm := fnms[i]
+		if _, sz := iconVariantSize(fnm); sz > 0 {
+			if subs, err := dirFunc(path.Join(dir, fnm)); err == nil {
+				for _, sfn := range subs {
+					fnms = append(fnms, path.Join(fnm, sfn))
+				}
+				continue
+			}
+		}
+		if strings.ToLower(path.Ext(fnm)) != ".svg" {
+			continue
+		}
+		b, err := assetFunc(path.Join(dir, fnm))
+		if err != nil {
+			lasterr = err
+			log.Println(err)
+			continue
+		}
+		ic := ip.addIcon(fnm, fnm)
+		err = ic.ReadXML(bytes.NewBuffer(b))
+		if err != nil && err != io.EOF {
+			lasterr = err
+			log.Println(err)
+			continue
+		}
+		ip.recolorIcon(ic)
+	}
+	ip.sortVariants()
+	ip.register()
+	return ip, lasterr
+}
+
+// register adds the pack to IconPacks, and updates gi.CurIconList
+func (ip *IconPack) register() {
+	iconPacksMu.Lock()
+	IconPacks[ip.Name] = ip
+	iconPacksMu.Unlock()
+	if gi.TheIconMgr != nil {
+		gi.CurIconList = gi.TheIconMgr.IconList(true)
+	}
+}
+
+// CloseIconPack removes the IconPack of given name -- icons that are already
+// showing are not affected
+func CloseIconPack(name string) {
+	iconPacksMu.Lock()
+	delete(IconPacks, name)
+	iconPacksMu.Unlock()
+	if gi.TheIconMgr != nil {
+		gi.CurIconList = gi.TheIconMgr.IconList(true)
+	}
+}
+
+// IconPackByName returns the IconPack of given name -- nil if none
+func IconPackByName(name string) *IconPack {
+	iconPacksMu.RLock()
+	defer iconPacksMu.RUnlock()
+	return IconPacks[name]
+}
+
+// SplitIconPackName splits given icon name into the name of its IconPack
+// and the name of the icon in the pack -- pack is empty for other icons
+func SplitIconPackName(iconName string) (pack, name string) {
+	if i := strings.Index(iconName, IconPackSep); i > 0 {
+		return iconName[:i], iconName[i+len(IconPackSep):]
+	}
+	return "", iconName
+}
+
+// Icon returns the icon of given name in the pack, without the name of the
+// pack, reading it if it has not been read yet -- nil if none
+func (ip *IconPack) Icon(name string) *Icon {
+	ic, ok := ip.Icons[name]
+	if !ok {
+		return nil
+	}
+	ip.openIcon(ic)
+	return ic
+}
+
+// IconList returns the names of the icons in the pack, including the name of
+// the pack
+func (ip *IconPack) IconList() []gi.IconName {
+	il := make([]gi.IconName, 0, len(ip.Icons))
+	for nm := range ip.Icons {
+		il = append(il, gi.IconName(ip.Name+IconPackSep+nm))
+	}
+	return il
+}
+
+// addIcon adds an icon, or a size-specific variant of one, from the file at
+// given path relative to the pack, read from given file name
+func (ip *IconPack) addIcon(rpath, fname string) *Icon {
+	dir, fn := path.Split(rpath)
+	bfn := fn[:len(fn)-len(path.Ext(fn))]
+	nm, sz := iconVariantSize(bfn)
+	var dirs []string
+	for _, d := range strings.Split(strings.Trim(dir, "/"), "/") {
+		if d == "" {
+			continue
+		}
+		if _, dsz := iconVariantSize(d); dsz > 0 {
+			if sz == 0 {
+				sz = dsz
+			}
+			continue
+		}
+		dirs = append(dirs, strings.ToLower(d))
+	}
+	nm = strings.ToLower(nm)
+	if len(dirs) > 0 {
+		nm = strings.Join(dirs, "-") + "-" + nm
+	}
+	ic := &Icon{}
+	ic.InitName(ic, nm)
+	ic.Filename = fname
+	ic.Size = sz
+	ic.pack = ip
+	if sz == 0 {
+		if bic, has := ip.Icons[nm]; has { // variants came first
+			ic.Variants = bic.Variants
+		}
+		ip.Icons[nm] = ic
+		return ic
+	}
+	bic, has := ip.Icons[nm]
+	if !has {
+		bic = &Icon{}
+		bic.InitName(bic, nm)
+		ip.Icons[nm] = bic
+	}
+	bic.Variants = append(bic.Variants, ic)
+	return ic
+}
+
+// sortVariants sorts the Variants of the icons by size, and uses the
+// largest variant as the icon for those without a scalable version
+func (ip *IconPack) sortVariants() {
+	for nm, ic := range ip.Icons {
+		if len(ic.Variants) == 0 {
+			continue
+		}
+		sort.Slice(ic.Variants, func(i, j int) bool {
+			return ic.Variants[i].Size < ic.Variants[j].Size
+		})
+		if ic.Filename == "" && !ic.HasChildren() {
+			lv := ic.Variants[len(ic.Variants)-1]
+			lv.Variants = ic.Variants
+			ip.Icons[nm] = lv
+		}
+	}
+}
+
+// openIcon reads the given icon of the pack from its file, if it has not
+// been read yet
+func (ip *IconPack) openIcon(ic *Icon) {
+	if ic.HasChildren() || ic.Filename == "" {
+		return
+	}
+	err := ic.OpenXML(ic.Filename)
+	if err != nil && err != io.EOF {
+		log.Println(err)
+		return
+	}
+	ip.recolorIcon(ic)
+}
+
+// recolorIcon removes the fill and stroke colors of the elements of given
+// icon, if Recolor is set -- "none" is kept, so that outlines stay unfilled,
+// and elements that are not filled and have no stroke, e.g., the bounding
+// boxes of Material icons, get no stroke, so that they stay invisible
+func (ip *IconPack) recolorIcon(ic *Icon) {
+	if !ip.Recolor {
+		return
+	}
+	isNone := func(pv interface{}) bool {
+		ps, ok := pv.(string)
+		return ok && strings.TrimSpace(ps) == "none"
+	}
+	ic.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		if k != ic.This() && isNone(k.Prop("fill")) && k.Prop("stroke") == nil {
+			k.SetProp("stroke", "none")
+		}
+		for _, pnm := range []string{"fill", "stroke", "color"} {
+			if pv := k.Prop(pnm); pv != nil && !isNone(pv) {
+				k.DeleteProp(pnm)
+			}
+		}
+		return ki.Continue
+	})
+}
+
+// iconVariantSize returns the name and the size in pixels of a size-specific
+// variant of an icon, from a name like 16, 16x16, 16px or name@16 -- size is
+// 0 if it is not a size
+func iconVariantSize(nm string) (string, int) {
+	if i := strings.LastIndex(nm, "@"); i > 0 {
+		if sz, err := strconv.Atoi(strings.TrimSuffix(nm[i+1:], "px")); err == nil && sz > 0 {
+			return nm[:i], sz
+		}
+		return nm, 0
+	}
+	snm := strings.TrimSuffix(strings.ToLower(nm), "px")
+	if i := strings.Index(snm, "x"); i > 0 && snm[:i] == snm[i+1:] {
+		snm = snm[:i]
+	}
+	if sz, err := strconv.Atoi(snm); err == nil && sz > 0 {
+		return nm, sz
+	}
+	return nm, 0
+}
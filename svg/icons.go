@@ -39,6 +39,9 @@ func init() {
 type Icon struct {
 	SVG
 	Filename string      `desc:"file name with full path for icon if loaded from file"`
+	Size     int         `desc:"size in pixels that this icon is designed for, if it is a size-specific variant from an IconPack -- 0 if it is scalable"`
+	Variants []*Icon     `json:"-" xml:"-" view:"-" desc:"size-specific variants of the icon from an IconPack, in order of increasing Size -- the one closest to the size of the icon is rendered"`
+	pack     *IconPack   // pack of the icon, if from an IconPack, for reading it when used
 	Rendered bool        `copy:"-" json:"-" xml:"-" desc:"we have already rendered at RenderedSize -- doesn't re-render at same size -- if the paint params change, set this to false to re-render"`
 	RendSize image.Point `copy:"-" json:"-" xml:"-" desc:"size at which we previously rendered"`
 }
@@ -59,6 +62,8 @@ func (ic *Icon) CopyFieldsFrom(frm interface{}) {
 	fr := frm.(*Icon)
 	ic.SVG.CopyFieldsFrom(&fr.SVG)
 	ic.Filename = fr.Filename
+	ic.Size = fr.Size
+	ic.Variants = fr.Variants
 }
 
 // CopyFromIcon copies from a source icon, typically one from a library --
@@ -86,6 +91,10 @@ func (ic *Icon) Size2D(iter int) {
 }
 
 func (ic *Icon) Layout2D(parBBox image.Rectangle, iter int) bool {
+	if len(ic.Variants) > 0 {
+		sz := ic.LayState.Alloc.Size
+		ic.SetVariant(ic.VariantForSize(int(mat32.Max(sz.X, sz.Y))))
+	}
 	ic.StyMu.RLock()
 	needSty := ic.Sty.Font.Size.Val == 0
 	ic.StyMu.RUnlock()
@@ -97,6 +106,43 @@ func (ic *Icon) Layout2D(parBBox image.Rectangle, iter int) bool {
 	return redo
 }
 
+// VariantForSize returns the size-specific variant of the icon that is
+// closest to given size in pixels: the smallest one that is at least that
+// big, or else the biggest one -- nil if the icon has no Variants
+func (ic *Icon) VariantForSize(sz int) *Icon {
+	if len(ic.Variants) == 0 {
+		return nil
+	}
+	for _, v := range ic.Variants {
+		if v.Size >= sz {
+			return v
+		}
+	}
+	return ic.Variants[len(ic.Variants)-1]
+}
+
+// SetVariant sets the drawing of the icon to that of given size-specific
+// variant, if it is not already showing it
+func (ic *Icon) SetVariant(v *Icon) {
+	if v == nil || (v.Size == ic.Size && ic.HasChildren()) {
+		return
+	}
+	if v.pack != nil {
+		v.pack.openIcon(v)
+	}
+	updt := ic.UpdateStart()
+	ic.DeleteChildren(ki.DestroyKids)
+	for _, k := range v.Kids {
+		ic.AddChild(k.Clone())
+	}
+	ic.ViewBox = v.ViewBox
+	ic.Size = v.Size
+	ic.Init2DTree()
+	ic.Style2DTree()
+	ic.Rendered = false
+	ic.UpdateEnd(updt)
+}
+
 // NeedsReRender tests whether the last render parameters (size, color) have changed or not
 func (ic *Icon) NeedsReRender() bool {
 	if ic.NeedsFullReRender() || !ic.Rendered || ic.RendSize != ic.Geom.Size {
@@ -143,6 +189,14 @@ func (im *IconMgr) IsValid(iconName string) bool {
 	if gi.IconName(iconName).IsNil() {
 		return false
 	}
+	if pk, nm := SplitIconPackName(iconName); pk != "" {
+		ip := IconPackByName(pk)
+		if ip == nil {
+			return false
+		}
+		_, ok := ip.Icons[nm]
+		return ok
+	}
 	if _, ok := (*CurIconSet)[iconName]; ok {
 		return true
 	}
@@ -153,7 +207,8 @@ func (im *IconMgr) IsValid(iconName string) bool {
 }
 
 // IconByName is main function to get icon by name -- looks in CurIconSet and
-// falls back to DefaultIconSet if not found there -- returns error and logs a
+// falls back to DefaultIconSet if not found there, or in the IconPack for
+// names with the name of a pack, e.g., fa:github -- returns error and logs a
 // message if not found
 func (im *IconMgr) IconByName(name string) (*Icon, error) {
 	if gi.IconName(name).IsNil() {
@@ -163,6 +218,9 @@ func (im *IconMgr) IconByName(name string) (*Icon, error) {
 		err := fmt.Errorf("svg.IconMgr.IconByName -- icon name not found in CurIconSet or DefaultIconSet: %v\n", name)
 		return nil, err
 	}
+	if pk, nm := SplitIconPackName(name); pk != "" {
+		return IconPackByName(pk).Icon(nm), nil
+	}
 	ic, ok := (*CurIconSet)[name]
 	if !ok {
 		ic = (*DefaultIconSet)[name]
@@ -186,7 +244,28 @@ func (im *IconMgr) SetIcon(ic *gi.Icon, iconName string) error {
 }
 
 func (im *IconMgr) IconList(alphaSort bool) []gi.IconName {
-	return CurIconSet.IconList(alphaSort)
+	il := CurIconSet.IconList(false)
+	iconPacksMu.RLock()
+	for _, ip := range IconPacks {
+		il = append(il, ip.IconList()...)
+	}
+	iconPacksMu.RUnlock()
+	if alphaSort {
+		sort.Slice(il, func(i, j int) bool {
+			return il[i] < il[j]
+		})
+	}
+	return il
+}
+
+func (im *IconMgr) OpenIconPack(name, path string, recolor bool) error {
+	_, err := OpenIconPack(name, path, recolor)
+	return err
+}
+
+func (im *IconMgr) OpenIconPackFromAssetDir(name, path string, recolor bool, dirFunc func(path string) ([]string, error), assetFunc func(name string) ([]byte, error)) error {
+	_, err := OpenIconPackFromAssetDir(name, path, recolor, dirFunc, assetFunc)
+	return err
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////
@@ -11,12 +11,18 @@
 package svg
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"image/color"
+	"image/png"
 	"io"
 	"log"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/goki/gi/gi"
@@ -24,6 +30,7 @@ import (
 	"github.com/goki/gi/units"
 	"github.com/goki/ki/ki"
 	"github.com/goki/mat32"
+	"github.com/srwiley/rasterx"
 	"golang.org/x/net/html/charset"
 )
 
@@ -526,7 +533,7 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 						szx, err = mat32.ParseFloat32(attr.Value)
 					case "markerHeight":
 						szy, err = mat32.ParseFloat32(attr.Value)
-					case "matrixUnits":
+					case "markerUnits", "matrixUnits":
 						if attr.Value == "strokeWidth" {
 							mrk.Units = StrokeWidth
 						} else {
@@ -693,9 +700,13 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 			case inDesc:
 				curSvg.Desc += trspc
 			case inTspn && curTspn != nil:
-				curTspn.Text = trspc
+				if trspc != "" { // ignore formatting whitespace around tspans
+					curTspn.Text = trspc
+				}
 			case inTxt && curTxt != nil:
-				curTxt.Text = trspc
+				if trspc != "" {
+					curTxt.Text = trspc
+				}
 			case inCSS && curCSS != nil:
 				curCSS.ParseString(trspc)
 				cp := curCSS.CSSProps()
@@ -711,3 +722,562 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 	}
 	return nil
 }
+
+/////////////////////////////////////////////////////////////////////////////
+//   Writing
+
+// SaveXML saves the svg to a standard XML-formatted SVG file, see WriteXML
+func (svg *SVG) SaveXML(filename string) error {
+	fp, err := os.Create(filename)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer fp.Close()
+	return svg.WriteXML(fp, true)
+}
+
+// WriteXML writes the svg as standard XML-formatted SVG output to
+// io.Writer, including the xml header -- this is the inverse of ReadXML,
+// writing all of the svg elements that it reads, with gradients and other
+// defs, text and transforms, so it can be used for vector export of any
+// svg drawing.  Props are written as attributes.  Element ids are written
+// for all nodes that have been given a name other than the default one
+// used for their element by ReadXML.  All errors are logged and also
+// returned.
+func (svg *SVG) WriteXML(writer io.Writer, indent bool) error {
+	_, err := io.WriteString(writer, xml.Header)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	enc := xml.NewEncoder(writer)
+	if indent {
+		enc.Indent("", "  ")
+	}
+	err = svg.MarshalXML(enc, xml.StartElement{})
+	if err == nil {
+		err = enc.Flush()
+	}
+	if err == nil {
+		_, err = io.WriteString(writer, "\n")
+	}
+	if err != nil {
+		log.Printf("svg.WriteXML: %v\n", err)
+	}
+	return err
+}
+
+// MarshalXML marshals the svg as a top-level svg element using
+// xml.Encoder -- the start element is ignored
+func (svg *SVG) MarshalXML(enc *xml.Encoder, se xml.StartElement) error {
+	st := xml.StartElement{Name: xml.Name{Space: "http://www.w3.org/2000/svg", Local: "svg"}}
+	st.Attr = append(st.Attr, xmlAttr("version", "1.1"))
+	return svg.marshalXMLSVG(enc, st)
+}
+
+// marshalXMLSVG writes the svg element with given start element, and all
+// of its contents
+func (svg *SVG) marshalXMLSVG(enc *xml.Encoder, st xml.StartElement) error {
+	if svg.ViewBox.Size != mat32.Vec2Zero {
+		vb := &svg.ViewBox
+		st.Attr = append(st.Attr, xmlAttr("width", xmlFloat(vb.Size.X)), xmlAttr("height", xmlFloat(vb.Size.Y)),
+			xmlAttr("viewBox", xmlFloats(vb.Min.X, vb.Min.Y, vb.Size.X, vb.Size.Y)))
+	}
+	st.Attr = xmlStdAttrs(svg.This().(gi.Node2D), "svg", st.Attr)
+	if err := enc.EncodeToken(st); err != nil {
+		return err
+	}
+	if err := svg.marshalXMLContents(enc); err != nil {
+		return err
+	}
+	return enc.EncodeToken(st.End())
+}
+
+// marshalXMLContents writes the title, desc, defs and elements of the svg
+func (svg *SVG) marshalXMLContents(enc *xml.Encoder) error {
+	if svg.Title != "" {
+		if err := xmlTextElement(enc, "title", nil, svg.Title); err != nil {
+			return err
+		}
+	}
+	if svg.Desc != "" {
+		if err := xmlTextElement(enc, "desc", nil, svg.Desc); err != nil {
+			return err
+		}
+	}
+	if svg.Defs.HasChildren() {
+		dst := xml.StartElement{Name: xml.Name{Local: "defs"}}
+		if err := enc.EncodeToken(dst); err != nil {
+			return err
+		}
+		if err := marshalXMLKids(enc, &svg.Defs); err != nil {
+			return err
+		}
+		if err := enc.EncodeToken(dst.End()); err != nil {
+			return err
+		}
+	}
+	return marshalXMLKids(enc, svg.This())
+}
+
+// marshalXMLKids writes all the children of given node
+func marshalXMLKids(enc *xml.Encoder, par ki.Ki) error {
+	for _, kid := range *par.Children() {
+		if err := marshalXMLNode(enc, kid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// marshalXMLNode writes given svg node, and all of its children, as the
+// corresponding svg element -- nodes that have no svg representation
+// (e.g., MetaData2D and Flow elements) are skipped.
+func marshalXMLNode(enc *xml.Encoder, k ki.Ki) error {
+	var st xml.StartElement
+	switch nd := k.(type) {
+	case *SVG:
+		return nd.marshalXMLSVG(enc, xml.StartElement{Name: xml.Name{Local: "svg"}})
+	case *Group:
+		st = xmlStart("g", nd, "g")
+	case *Rect:
+		attrs := []xml.Attr{xmlAttr("x", xmlFloat(nd.Pos.X)), xmlAttr("y", xmlFloat(nd.Pos.Y)),
+			xmlAttr("width", xmlFloat(nd.Size.X)), xmlAttr("height", xmlFloat(nd.Size.Y))}
+		if nd.Radius.X != 0 {
+			attrs = append(attrs, xmlAttr("rx", xmlFloat(nd.Radius.X)))
+		}
+		if nd.Radius.Y != 0 {
+			attrs = append(attrs, xmlAttr("ry", xmlFloat(nd.Radius.Y)))
+		}
+		st = xmlStart("rect", nd, "rect", attrs...)
+	case *Circle:
+		st = xmlStart("circle", nd, "circle", xmlAttr("cx", xmlFloat(nd.Pos.X)), xmlAttr("cy", xmlFloat(nd.Pos.Y)),
+			xmlAttr("r", xmlFloat(nd.Radius)))
+	case *Ellipse:
+		st = xmlStart("ellipse", nd, "ellipse", xmlAttr("cx", xmlFloat(nd.Pos.X)), xmlAttr("cy", xmlFloat(nd.Pos.Y)),
+			xmlAttr("rx", xmlFloat(nd.Radii.X)), xmlAttr("ry", xmlFloat(nd.Radii.Y)))
+	case *Line:
+		st = xmlStart("line", nd, "line", xmlAttr("x1", xmlFloat(nd.Start.X)), xmlAttr("y1", xmlFloat(nd.Start.Y)),
+			xmlAttr("x2", xmlFloat(nd.End.X)), xmlAttr("y2", xmlFloat(nd.End.Y)))
+	case *Polygon:
+		st = xmlStart("polygon", nd, "polygon", xmlAttr("points", xmlPoints(nd.Points)))
+	case *Polyline:
+		st = xmlStart("polyline", nd, "polyline", xmlAttr("points", xmlPoints(nd.Points)))
+	case *Path:
+		st = xmlStart("path", nd, "path", xmlAttr("d", PathDataString(nd.Data)))
+	case *Text:
+		return marshalXMLText(enc, nd)
+	case *ClipPath:
+		st = xmlStart("clipPath", nd, "clip-path")
+	case *Marker:
+		st = xmlStart("marker", nd, "marker", xmlAttr("refX", xmlFloat(nd.RefPos.X)), xmlAttr("refY", xmlFloat(nd.RefPos.Y)),
+			xmlAttr("markerWidth", xmlFloat(nd.Size.X)), xmlAttr("markerHeight", xmlFloat(nd.Size.Y)))
+		if nd.Units == UserSpaceOnUse {
+			st.Attr = append(st.Attr, xmlAttr("markerUnits", "userSpaceOnUse"))
+		}
+		if nd.ViewBox.Size != mat32.Vec2Zero {
+			vb := &nd.ViewBox
+			st.Attr = append(st.Attr, xmlAttr("viewBox", xmlFloats(vb.Min.X, vb.Min.Y, vb.Size.X, vb.Size.Y)))
+		}
+		if nd.Orient != "" {
+			st.Attr = append(st.Attr, xmlAttr("orient", nd.Orient))
+		}
+	case *Filter:
+		if nd.FilterType != "filter" && !strings.HasPrefix(nd.FilterType, "fe") {
+			return nil // e.g., inkscape path-effect
+		}
+		st = xmlStart(nd.FilterType, nd, nd.FilterType)
+	case *gi.Gradient:
+		return marshalXMLGradient(enc, nd)
+	case *gi.StyleSheet:
+		if nd.Sheet == nil {
+			return nil
+		}
+		return xmlTextElement(enc, "style", []xml.Attr{xmlAttr("type", "text/css")}, nd.Sheet.String())
+	default:
+		return nil
+	}
+	if err := enc.EncodeToken(st); err != nil {
+		return err
+	}
+	if err := marshalXMLKids(enc, k); err != nil {
+		return err
+	}
+	return enc.EncodeToken(st.End())
+}
+
+// marshalXMLText writes a text or tspan element, including its tspans
+func marshalXMLText(enc *xml.Encoder, txt *Text) error {
+	nm := "text"
+	defNm := "txt"
+	if _, istxt := txt.Parent().(*Text); istxt {
+		nm = "tspan"
+		defNm = "tspan"
+	}
+	st := xmlStart(nm, txt, defNm)
+	if len(txt.CharPosX) > 0 {
+		st.Attr = append(st.Attr, xmlAttr("x", xmlFloats(txt.CharPosX...)))
+	} else {
+		st.Attr = append(st.Attr, xmlAttr("x", xmlFloat(txt.Pos.X)))
+	}
+	if len(txt.CharPosY) > 0 {
+		st.Attr = append(st.Attr, xmlAttr("y", xmlFloats(txt.CharPosY...)))
+	} else {
+		st.Attr = append(st.Attr, xmlAttr("y", xmlFloat(txt.Pos.Y)))
+	}
+	if len(txt.CharPosDX) > 0 {
+		st.Attr = append(st.Attr, xmlAttr("dx", xmlFloats(txt.CharPosDX...)))
+	}
+	if len(txt.CharPosDY) > 0 {
+		st.Attr = append(st.Attr, xmlAttr("dy", xmlFloats(txt.CharPosDY...)))
+	}
+	if len(txt.CharRots) > 0 {
+		st.Attr = append(st.Attr, xmlAttr("rotate", xmlFloats(txt.CharRots...)))
+	}
+	if txt.TextLength > 0 {
+		st.Attr = append(st.Attr, xmlAttr("textLength", xmlFloat(txt.TextLength)))
+		if txt.AdjustGlyphs {
+			st.Attr = append(st.Attr, xmlAttr("lengthAdjust", "spacingAndGlyphs"))
+		}
+	}
+	if err := enc.EncodeToken(st); err != nil {
+		return err
+	}
+	if txt.Text != "" {
+		if err := enc.EncodeToken(xml.CharData(txt.Text)); err != nil {
+			return err
+		}
+	}
+	if err := marshalXMLKids(enc, txt); err != nil {
+		return err
+	}
+	return enc.EncodeToken(st.End())
+}
+
+// marshalXMLGradient writes a linearGradient or radialGradient element,
+// with its stops
+func marshalXMLGradient(enc *xml.Encoder, gr *gi.Gradient) error {
+	g := gr.Grad.Gradient
+	if g == nil || gr.Grad.Source == gist.SolidColor {
+		return nil
+	}
+	var st xml.StartElement
+	pt := g.Points
+	if gr.Grad.Source == gist.RadialGradient {
+		st = xmlStart("radialGradient", gr, "rad-grad", xmlAttr("cx", xmlFloat64(pt[0])), xmlAttr("cy", xmlFloat64(pt[1])),
+			xmlAttr("fx", xmlFloat64(pt[2])), xmlAttr("fy", xmlFloat64(pt[3])), xmlAttr("r", xmlFloat64(pt[4])))
+	} else {
+		st = xmlStart("linearGradient", gr, "lin-grad", xmlAttr("x1", xmlFloat64(pt[0])), xmlAttr("y1", xmlFloat64(pt[1])),
+			xmlAttr("x2", xmlFloat64(pt[2])), xmlAttr("y2", xmlFloat64(pt[3])))
+	}
+	if g.Units == rasterx.UserSpaceOnUse {
+		st.Attr = append(st.Attr, xmlAttr("gradientUnits", "userSpaceOnUse"))
+	}
+	switch g.Spread {
+	case rasterx.ReflectSpread:
+		st.Attr = append(st.Attr, xmlAttr("spreadMethod", "reflect"))
+	case rasterx.RepeatSpread:
+		st.Attr = append(st.Attr, xmlAttr("spreadMethod", "repeat"))
+	}
+	if m := g.Matrix; m != rasterx.Identity && m != (rasterx.Matrix2D{}) {
+		st.Attr = append(st.Attr, xmlAttr("gradientTransform", "matrix("+xmlFloats(float32(m.A), float32(m.B),
+			float32(m.C), float32(m.D), float32(m.E), float32(m.F))+")"))
+	}
+	if err := enc.EncodeToken(st); err != nil {
+		return err
+	}
+	for _, s := range g.Stops {
+		clr := color.NRGBAModel.Convert(s.StopColor).(color.NRGBA)
+		op := s.Opacity * float64(clr.A) / 255
+		clr.A = 0xFF
+		sst := xml.StartElement{Name: xml.Name{Local: "stop"}}
+		sst.Attr = append(sst.Attr, xmlAttr("offset", xmlFloat64(s.Offset)), xmlAttr("stop-color", xmlColor(clr)))
+		if op < 1 {
+			sst.Attr = append(sst.Attr, xmlAttr("stop-opacity", xmlFloat64(op)))
+		}
+		if err := enc.EncodeToken(sst); err != nil {
+			return err
+		}
+		if err := enc.EncodeToken(sst.End()); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(st.End())
+}
+
+// xmlStart returns the start element for given element name and node,
+// with given element-specific attributes followed by the standard ones
+func xmlStart(name string, nd gi.Node2D, defNm string, attrs ...xml.Attr) xml.StartElement {
+	return xml.StartElement{Name: xml.Name{Local: name}, Attr: xmlStdAttrs(nd, defNm, attrs)}
+}
+
+// xmlStdAttrs appends the standard attributes for given node to attrs:
+// id (unless the name is the given default one), class, transform and the
+// props -- props already in attrs, and those that have no simple string
+// representation, are skipped.
+func xmlStdAttrs(nd gi.Node2D, defNm string, attrs []xml.Attr) []xml.Attr {
+	nb := nd.AsNode2D()
+	nel := len(attrs)
+	if nm := nb.Name(); nm != "" && nm != defNm {
+		attrs = append(attrs, xmlAttr("id", nm))
+	}
+	if nb.Class != "" {
+		attrs = append(attrs, xmlAttr("class", nb.Class))
+	}
+	if _, has := nb.Props["transform"]; !has {
+		if sn, ok := nd.(interface{ AsSVGNode() *NodeBase }); ok {
+			xf := sn.AsSVGNode().Pnt.XForm
+			if xf != mat32.Identity2D() && xf != (mat32.Mat2{}) {
+				attrs = append(attrs, xmlAttr("transform", xmlMatrix(xf)))
+			}
+		}
+	}
+	if len(nb.Props) == 0 {
+		return attrs
+	}
+	keys := make([]string, 0, len(nb.Props))
+	for key := range nb.Props {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if key == "xmlns" || strings.HasPrefix(key, "xmlns:") || key == "version" {
+			continue // written for the top-level svg as needed
+		}
+		if xmlHasAttr(attrs[:nel], key) {
+			continue
+		}
+		if val, ok := xmlPropString(nb.Props[key]); ok {
+			attrs = append(attrs, xmlAttr(key, val))
+		}
+	}
+	return attrs
+}
+
+// xmlHasAttr returns true if attrs has an attribute of given name
+func xmlHasAttr(attrs []xml.Attr, name string) bool {
+	for _, a := range attrs {
+		if a.Name.Local == name {
+			return true
+		}
+	}
+	return false
+}
+
+// xmlPropString returns the svg attribute string for given property value,
+// and false if it has no simple representation
+func xmlPropString(val interface{}) (string, bool) {
+	switch v := val.(type) {
+	case string:
+		return v, true
+	case float32:
+		return xmlFloat(v), true
+	case float64:
+		return xmlFloat64(v), true
+	case int:
+		return strconv.Itoa(v), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case units.Value:
+		return v.String(), true
+	case *units.Value:
+		return v.String(), true
+	case gist.Color:
+		return xmlColor(v), true
+	case *gist.Color:
+		return xmlColor(*v), true
+	case color.Color:
+		return xmlColor(v), true
+	case mat32.Mat2:
+		return xmlMatrix(v), true
+	case fmt.Stringer:
+		return v.String(), true
+	}
+	return "", false
+}
+
+// xmlAttr returns an xml.Attr with given name and value
+func xmlAttr(name, val string) xml.Attr {
+	return xml.Attr{Name: xml.Name{Local: name}, Value: val}
+}
+
+// xmlTextElement writes an element containing just given text
+func xmlTextElement(enc *xml.Encoder, name string, attrs []xml.Attr, text string) error {
+	st := xml.StartElement{Name: xml.Name{Local: name}, Attr: attrs}
+	if err := enc.EncodeToken(st); err != nil {
+		return err
+	}
+	if err := enc.EncodeToken(xml.CharData(text)); err != nil {
+		return err
+	}
+	return enc.EncodeToken(st.End())
+}
+
+// xmlFloat returns the shortest string representation of given value
+func xmlFloat(v float32) string {
+	return strconv.FormatFloat(float64(v), 'g', -1, 32)
+}
+
+// xmlFloat64 returns the shortest string representation of given value,
+// at float32 precision
+func xmlFloat64(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 32)
+}
+
+// xmlFloats returns the values separated by spaces
+func xmlFloats(vs ...float32) string {
+	strs := make([]string, len(vs))
+	for i, v := range vs {
+		strs[i] = xmlFloat(v)
+	}
+	return strings.Join(strs, " ")
+}
+
+// xmlPoints returns the points as x,y pairs separated by spaces
+func xmlPoints(pts []mat32.Vec2) string {
+	strs := make([]string, len(pts))
+	for i, p := range pts {
+		strs[i] = xmlFloat(p.X) + "," + xmlFloat(p.Y)
+	}
+	return strings.Join(strs, " ")
+}
+
+// xmlMatrix returns the svg matrix transform for given matrix
+func xmlMatrix(m mat32.Mat2) string {
+	return "matrix(" + xmlFloats(m.XX, m.YX, m.XY, m.YY, m.X0, m.Y0) + ")"
+}
+
+// xmlColor returns the #rrggbb hex code for given color, with an alpha
+// component (#rrggbbaa) if it is not opaque
+func xmlColor(c color.Color) string {
+	nc := color.NRGBAModel.Convert(c).(color.NRGBA)
+	if nc.A == 0xFF {
+		return fmt.Sprintf("#%02x%02x%02x", nc.R, nc.G, nc.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", nc.R, nc.G, nc.B, nc.A)
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Scene export
+
+// SaveSceneXML saves the rendered image of given gi 2D scene node to an
+// SVG file, see WriteSceneXML
+func SaveSceneXML(node gi.Node2D, filename string) error {
+	fp, err := os.Create(filename)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer fp.Close()
+	return WriteSceneXML(fp, node, true)
+}
+
+// WriteSceneXML writes the rendered image of given gi 2D scene node (e.g.,
+// a Frame containing widgets, plots and diagrams) as standard SVG output
+// to io.Writer.  The raster image of the node, from gi.GrabWidgetImage, is
+// embedded as a PNG image, and every svg.SVG drawing within it is written
+// as vector graphics over the top of that, at its rendered position and
+// with its rendering transform, so that these drawings are preserved as
+// vectors.  As for GrabWidgetImage, call from within window event
+// processing, after the scene has been rendered.
+func WriteSceneXML(writer io.Writer, node gi.Node2D, indent bool) error {
+	img := gi.GrabWidgetImage(node)
+	if img == nil {
+		err := fmt.Errorf("svg.WriteSceneXML: node is not visible: %v", node.Path())
+		log.Println(err)
+		return err
+	}
+	var pb bytes.Buffer
+	if err := png.Encode(&pb, img); err != nil {
+		log.Println(err)
+		return err
+	}
+	_, err := io.WriteString(writer, xml.Header)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	enc := xml.NewEncoder(writer)
+	if indent {
+		enc.Indent("", "  ")
+	}
+	err = marshalXMLScene(enc, node, img.Rect.Size().X, img.Rect.Size().Y, pb.Bytes())
+	if err == nil {
+		err = enc.Flush()
+	}
+	if err == nil {
+		_, err = io.WriteString(writer, "\n")
+	}
+	if err != nil {
+		log.Printf("svg.WriteSceneXML: %v\n", err)
+	}
+	return err
+}
+
+// marshalXMLScene writes the scene svg for node, with given size and png
+// image data
+func marshalXMLScene(enc *xml.Encoder, node gi.Node2D, wd, ht int, pngData []byte) error {
+	st := xml.StartElement{Name: xml.Name{Space: "http://www.w3.org/2000/svg", Local: "svg"}}
+	st.Attr = append(st.Attr, xmlAttr("xmlns:xlink", "http://www.w3.org/1999/xlink"), xmlAttr("version", "1.1"),
+		xmlAttr("width", strconv.Itoa(wd)), xmlAttr("height", strconv.Itoa(ht)))
+	if err := enc.EncodeToken(st); err != nil {
+		return err
+	}
+	ist := xml.StartElement{Name: xml.Name{Local: "image"}}
+	ist.Attr = append(ist.Attr, xmlAttr("x", "0"), xmlAttr("y", "0"), xmlAttr("width", strconv.Itoa(wd)),
+		xmlAttr("height", strconv.Itoa(ht)), xmlAttr("xlink:href", "data:image/png;base64,"+base64.StdEncoding.EncodeToString(pngData)))
+	if err := enc.EncodeToken(ist); err != nil {
+		return err
+	}
+	if err := enc.EncodeToken(ist.End()); err != nil {
+		return err
+	}
+	nb := node.AsNode2D()
+	nb.BBoxMu.RLock()
+	orig := nb.WinBBox.Min
+	nb.BBoxMu.RUnlock()
+	var err error
+	node.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		svg, ok := k.(*SVG)
+		if !ok {
+			return ki.Continue
+		}
+		svg.BBoxMu.RLock()
+		bb := svg.WinBBox
+		svg.BBoxMu.RUnlock()
+		if bb.Empty() {
+			return ki.Break // not visible -- don't go into it
+		}
+		pos := bb.Min.Sub(orig)
+		sz := svg.Geom.Size
+		sst := xml.StartElement{Name: xml.Name{Local: "svg"}}
+		sst.Attr = append(sst.Attr, xmlAttr("x", strconv.Itoa(pos.X)), xmlAttr("y", strconv.Itoa(pos.Y)),
+			xmlAttr("width", strconv.Itoa(sz.X)), xmlAttr("height", strconv.Itoa(sz.Y)))
+		gst := xml.StartElement{Name: xml.Name{Local: "g"}}
+		if xf := svg.Pnt.XForm; xf != mat32.Identity2D() && xf != (mat32.Mat2{}) {
+			gst.Attr = append(gst.Attr, xmlAttr("transform", xmlMatrix(xf)))
+		}
+		if nm := svg.Name(); nm != "svg" {
+			gst.Attr = append(gst.Attr, xmlAttr("id", nm))
+		}
+		if err = enc.EncodeToken(sst); err != nil {
+			return ki.Break
+		}
+		if err = enc.EncodeToken(gst); err != nil {
+			return ki.Break
+		}
+		if err = svg.marshalXMLContents(enc); err != nil {
+			return ki.Break
+		}
+		if err = enc.EncodeToken(gst.End()); err != nil {
+			return ki.Break
+		}
+		err = enc.EncodeToken(sst.End())
+		return ki.Break // contents already written
+	})
+	if err != nil {
+		return err
+	}
+	return enc.EncodeToken(st.End())
+}
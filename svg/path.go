@@ -9,6 +9,7 @@ import (
 	"log"
 	"math"
 	"strconv"
+	"strings"
 	"unicode"
 
 	"github.com/chewxy/math32"
@@ -808,3 +809,24 @@ func PathDataParse(d string) ([]PathData, error) {
 	return pd, nil
 	// todo: add some error checking..
 }
+
+// PathDataString returns the standard SVG string representation of the
+// compiled path data, as used in the d attribute of a path element
+func PathDataString(data []PathData) string {
+	var sb strings.Builder
+	sz := len(data)
+	for i := 0; i < sz; {
+		cmd, n := PathDataNextCmd(data, &i)
+		if sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(strings.TrimPrefix(cmd.String(), "Pc"))
+		for np := 0; np < n && i < sz; np++ {
+			if np > 0 {
+				sb.WriteByte(',')
+			}
+			sb.WriteString(strconv.FormatFloat(float64(PathDataNext(data, &i)), 'g', -1, 32))
+		}
+	}
+	return sb.String()
+}